@@ -19,8 +19,10 @@ import (
 	"github.com/opentofu/opentofu/internal/command/cliconfig"
 	"github.com/opentofu/opentofu/internal/command/views"
 	"github.com/opentofu/opentofu/internal/command/webbrowser"
+	"github.com/opentofu/opentofu/internal/getmodules"
 	"github.com/opentofu/opentofu/internal/getproviders"
 	pluginDiscovery "github.com/opentofu/opentofu/internal/plugin/discovery"
+	"github.com/opentofu/opentofu/internal/providercache"
 	"github.com/opentofu/opentofu/internal/terminal"
 )
 
@@ -29,6 +31,10 @@ import (
 // that assume that OpenTofu is being run from a command prompt.
 const runningInAutomationEnvName = "TF_IN_AUTOMATION"
 
+// ciModeEnvValue is the value of the runningInAutomationEnvName environment
+// variable which selects CI mode, like the -ci option.
+const ciModeEnvValue = "strict"
+
 // commands is the mapping of all the available OpenTofu commands.
 var commands map[string]cli.CommandFactory
 
@@ -57,13 +63,18 @@ func initCommands(
 	originalWorkingDir string,
 	streams *terminal.Streams,
 	config *cliconfig.Config,
+	colorTheme *views.Theme,
 	services *disco.Disco,
 	providerSrc getproviders.Source,
+	providerTrustedKeys []*getproviders.TrustedSigningKeys,
+	moduleSigningKeys []*getmodules.OCISigningKeys,
 	providerDevOverrides map[addrs.Provider]getproviders.PackageLocalDir,
+	providerScopedDevOverrides []*cliconfig.ScopedDevOverrides,
 	unmanagedProviders map[addrs.Provider]*plugin.ReattachConfig,
+	ciMode bool,
 ) {
 	var inAutomation bool
-	if v := os.Getenv(runningInAutomationEnvName); v != "" {
+	if v := os.Getenv(runningInAutomationEnvName); v != "" || ciMode {
 		inAutomation = true
 	}
 
@@ -84,12 +95,17 @@ func initCommands(
 
 	wd := workingDir(originalWorkingDir, os.Getenv("TF_DATA_DIR"))
 
+	// The CLI configuration was already validated, so we can ignore errors.
+	downloadBandwidth, _ := cliconfig.ParseBandwidth(config.ProviderDownloadBandwidth)
+	providerDownloadLimits := providercache.NewDownloadLimits(config.ProviderDownloadConcurrency, downloadBandwidth)
+
 	meta := command.Meta{
 		WorkingDir: wd,
 		Streams:    streams,
-		View:       views.NewView(streams).SetRunningInAutomation(inAutomation),
+		View:       views.NewView(streams).SetRunningInAutomation(inAutomation).SetDeterministic(ciMode).SetTheme(colorTheme),
 
-		Color:            true,
+		Color:            !ciMode,
+		ColorTheme:       colorTheme,
 		GlobalPluginDirs: globalPluginDirs(),
 		Ui:               Ui,
 
@@ -97,17 +113,28 @@ func initCommands(
 		BrowserLauncher: webbrowser.NewNativeLauncher(),
 
 		RunningInAutomation: inAutomation,
+		CIMode:              ciMode,
 		CLIConfigDir:        configDir,
 		PluginCacheDir:      config.PluginCacheDir,
 
 		PluginCacheMayBreakDependencyLockFile: config.PluginCacheMayBreakDependencyLockFile,
+		PluginTransport:                       config.PluginTransport,
+		PluginSocketDir:                       config.PluginSocketDir,
+		ProviderDownloadLimits:                providerDownloadLimits,
+		ProviderFunctionCacheDir:              config.ProviderFunctionCacheDir,
+		ProvisionerPlugins:                    config.ProvisionerPlugins,
 
 		ShutdownCh:    makeShutdownCh(),
 		CallerContext: ctx,
 
-		ProviderSource:       providerSrc,
-		ProviderDevOverrides: providerDevOverrides,
-		UnmanagedProviders:   unmanagedProviders,
+		ProviderSource:             providerSrc,
+		ProviderTrustedKeys:        providerTrustedKeys,
+		ModuleSigningKeys:          moduleSigningKeys,
+		ModuleAliases:              config.ModuleAliases,
+		PartialModulePackages:      config.PartialModulePackages,
+		ProviderDevOverrides:       providerDevOverrides,
+		ProviderScopedDevOverrides: providerScopedDevOverrides,
+		UnmanagedProviders:         unmanagedProviders,
 
 		AllowExperimentalFeatures: experimentsAreAllowed(),
 	}
@@ -125,6 +152,24 @@ func initCommands(
 			}, nil
 		},
 
+		"bundle": func() (cli.Command, error) {
+			return &command.BundleCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"bundle create": func() (cli.Command, error) {
+			return &command.BundleCreateCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"bundle verify": func() (cli.Command, error) {
+			return &command.BundleVerifyCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"console": func() (cli.Command, error) {
 			return &command.ConsoleCommand{
 				Meta: meta,
@@ -221,6 +266,12 @@ func initCommands(
 			}, nil
 		},
 
+		"metadata dump": func() (cli.Command, error) {
+			return &command.MetadataDumpCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"metadata functions": func() (cli.Command, error) {
 			return &command.MetadataFunctionsCommand{
 				Meta: meta,
@@ -257,12 +308,30 @@ func initCommands(
 			}, nil
 		},
 
+		"providers outdated": func() (cli.Command, error) {
+			return &command.ProvidersOutdatedCommand{
+				Meta: meta,
+			}, nil
+		},
+
+		"providers overrides": func() (cli.Command, error) {
+			return &command.ProvidersOverridesCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"providers schema": func() (cli.Command, error) {
 			return &command.ProvidersSchemaCommand{
 				Meta: meta,
 			}, nil
 		},
 
+		"proxy": func() (cli.Command, error) {
+			return &command.ProxyCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"push": func() (cli.Command, error) {
 			return &command.PushCommand{
 				Meta: meta,
@@ -293,12 +362,24 @@ func initCommands(
 			}, nil
 		},
 
+		"timings": func() (cli.Command, error) {
+			return &command.TimingsCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"validate": func() (cli.Command, error) {
 			return &command.ValidateCommand{
 				Meta: meta,
 			}, nil
 		},
 
+		"vendor": func() (cli.Command, error) {
+			return &command.VendorCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"version": func() (cli.Command, error) {
 			return &command.VersionCommand{
 				Meta:              meta,
@@ -21,9 +21,12 @@ import (
 	"github.com/mitchellh/cli"
 	"github.com/mitchellh/colorstring"
 	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/command"
 	"github.com/opentofu/opentofu/internal/command/cliconfig"
 	"github.com/opentofu/opentofu/internal/command/format"
+	"github.com/opentofu/opentofu/internal/command/views"
 	"github.com/opentofu/opentofu/internal/didyoumean"
+	"github.com/opentofu/opentofu/internal/getproviders"
 	"github.com/opentofu/opentofu/internal/httpclient"
 	"github.com/opentofu/opentofu/internal/logging"
 	"github.com/opentofu/opentofu/internal/terminal"
@@ -87,6 +90,16 @@ func realMain() int {
 		defer otelSpan.End()
 	}
 
+	// The -ci option, or TF_IN_AUTOMATION=strict, selects CI mode. That
+	// affects the log format, so we check for it before anything else.
+	ciMode, cliArgs := extractCIOption(os.Args[1:])
+	if os.Getenv(runningInAutomationEnvName) == ciModeEnvValue {
+		ciMode = true
+	}
+	if ciMode {
+		enableCIMode()
+	}
+
 	tmpLogPath := os.Getenv(envTmpLogPath)
 	if tmpLogPath != "" {
 		f, err := os.OpenFile(tmpLogPath, os.O_RDWR|os.O_APPEND, 0666)
@@ -111,6 +124,9 @@ func realMain() int {
 	if experimentsAreAllowed() {
 		log.Printf("[INFO] This build of OpenTofu allows using experimental features")
 	}
+	if ciMode {
+		log.Printf("[INFO] Running in CI mode")
+	}
 
 	streams, err := terminal.Init()
 	if err != nil {
@@ -199,7 +215,52 @@ func realMain() int {
 			// We continue to run anyway, because most commands don't do provider installation.
 		}
 	}
+	providerTrustedKeys, diags := providerTrustedKeys(config.ProviderSigningKeys)
+	if len(diags) > 0 {
+		Ui.Error("There are some problems with the provider_signing_keys configuration:")
+		for _, diag := range diags {
+			earlyColor := &colorstring.Colorize{
+				Colors:  colorstring.DefaultColors,
+				Disable: true, // Disable color to be conservative until we know better
+				Reset:   true,
+			}
+			Ui.Error(format.Diagnostic(diag, nil, earlyColor, 78))
+		}
+		if diags.HasErrors() {
+			// An exclusive block with a problem would otherwise let the
+			// registry's keys sign the providers that it covers, so we
+			// won't continue without the user fixing it.
+			Ui.Error("OpenTofu can't verify provider packages as intended until the above problems are fixed.\n\n")
+			return 1
+		}
+	}
+	moduleSigningKeys, diags := moduleSigningKeys(config.ModuleSigningKeys)
+	if len(diags) > 0 {
+		Ui.Error("There are some problems with the module_signing_keys configuration:")
+		for _, diag := range diags {
+			earlyColor := &colorstring.Colorize{
+				Colors:  colorstring.DefaultColors,
+				Disable: true, // Disable color to be conservative until we know better
+				Reset:   true,
+			}
+			Ui.Error(format.Diagnostic(diag, nil, earlyColor, 78))
+		}
+		if diags.HasErrors() {
+			// A block with a problem would otherwise let unsigned module
+			// packages be installed from the repositories it covers.
+			Ui.Error("OpenTofu can't verify module packages as intended until the above problems are fixed.\n\n")
+			return 1
+		}
+	}
+	providerSrc = getproviders.NewTrustedKeysSource(providerSrc, providerTrustedKeys)
 	providerDevOverrides := providerDevOverrides(config.ProviderInstallation)
+	providerScopedDevOverrides := providerScopedDevOverrides(config.ProviderInstallation)
+
+	colorTheme, err := views.NewTheme(config.ColorTheme, config.ColorPalette)
+	if err != nil {
+		// A nil theme selects the default colors, so we can continue.
+		Ui.Error(fmt.Sprintf("Invalid color theme in the CLI configuration: %s\n\nOpenTofu will use the default color theme instead.\n", err))
+	}
 
 	// The user can declare that certain providers are being managed on
 	// OpenTofu's behalf using this environment variable. This is used
@@ -215,7 +276,7 @@ func realMain() int {
 
 	// Get the command line args.
 	binName := filepath.Base(os.Args[0])
-	args := os.Args[1:]
+	args := cliArgs
 
 	originalWd, err := os.Getwd()
 	if err != nil {
@@ -240,13 +301,39 @@ func realMain() int {
 		}
 	}
 
+	// A project-level CLI configuration file in the working directory or one
+	// of its parents can provide defaults for the rest of the CLI
+	// configuration, for the environment, and for some command line options.
+	// We look for it only after handling -chdir, because the project is the
+	// one containing the overridden working directory.
+	projectConfig, diags := cliconfig.LoadProjectConfig(".")
+	if len(diags) > 0 {
+		Ui.Error("There are some problems with the project configuration:")
+		for _, diag := range diags {
+			earlyColor := &colorstring.Colorize{
+				Colors:  colorstring.DefaultColors,
+				Disable: true, // Disable color to be conservative until we know better
+				Reset:   true,
+			}
+			Ui.Error(format.Diagnostic(diag, nil, earlyColor, 78))
+		}
+		if diags.HasErrors() {
+			Ui.Error("As a result of the above problems, OpenTofu may not behave as intended.\n\n")
+		}
+	}
+	if err := projectConfig.SetEnvironment(); err != nil {
+		Ui.Error(err.Error())
+		return 1
+	}
+	config = config.MergeProject(projectConfig)
+
 	// In tests, Commands may already be set to provide mock commands
 	if commands == nil {
 		// Commands get to hold on to the original working directory here,
 		// in case they need to refer back to it for any special reason, though
 		// they should primarily be working with the override working directory
 		// that we've now switched to above.
-		initCommands(ctx, originalWd, streams, config, services, providerSrc, providerDevOverrides, unmanagedProviders)
+		initCommands(ctx, originalWd, streams, config, colorTheme, services, providerSrc, providerTrustedKeys, moduleSigningKeys, providerDevOverrides, providerScopedDevOverrides, unmanagedProviders, ciMode)
 	}
 
 	// Attempt to ensure the config directory exists.
@@ -285,6 +372,13 @@ func realMain() int {
 		return 1
 	}
 
+	// Prefix the args with the defaults from the project configuration, so
+	// that the options given explicitly or in the environment override them.
+	if extra := projectConfig.Args(cliRunner.Subcommand()); len(extra) > 0 {
+		log.Printf("[INFO] Project configuration args: %q", extra)
+		args = insertArgs(cliRunner.Subcommand(), args, extra)
+	}
+
 	// We shortcut "--version" and "-v" to just show the version
 	for _, arg := range args {
 		if arg == "-v" || arg == "-version" || arg == "--version" {
@@ -372,6 +466,12 @@ func mergeEnvArgs(envName string, cmd string, args []string) ([]string, error) {
 			envName, err)
 	}
 
+	return insertArgs(cmd, args, extra), nil
+}
+
+// insertArgs returns a copy of args with the extra args inserted immediately
+// after the given subcommand.
+func insertArgs(cmd string, args []string, extra []string) []string {
 	// Find the command to look for in the args. If there is a space,
 	// we need to find the last part.
 	search := cmd
@@ -399,7 +499,7 @@ func mergeEnvArgs(envName string, cmd string, args []string) ([]string, error) {
 	copy(newArgs, args[:idx])
 	copy(newArgs[idx:], extra)
 	copy(newArgs[len(extra)+idx:], args[idx:])
-	return newArgs, nil
+	return newArgs
 }
 
 // parse information on reattaching to unmanaged providers out of a
@@ -500,6 +600,40 @@ func extractChdirOption(args []string) (string, []string, error) {
 	return argValue, newArgs, nil
 }
 
+// extractCIOption removes a -ci option from the given arguments, if present
+// before the subcommand, and returns whether it was present.
+func extractCIOption(args []string) (bool, []string) {
+	const argName = "-ci"
+
+	for i, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			// Like -chdir, the -ci option is a subcommand-agnostic one, so
+			// it must appear before any subcommand argument.
+			break
+		}
+		if arg == argName {
+			newArgs := make([]string, 0, len(args)-1)
+			newArgs = append(newArgs, args[:i]...)
+			newArgs = append(newArgs, args[i+1:]...)
+			return true, newArgs
+		}
+	}
+	return false, args
+}
+
+// enableCIMode sets up the parts of CI mode which apply to the whole
+// process: JSON logs, no interactive input, and no version checks by
+// plugins. The rest is handled by the commands, via command.Meta.
+func enableCIMode() {
+	logging.SetJSONFormat()
+
+	// Setting these in our own environment also sets them for any provider
+	// or provisioner plugins we start, some of which make checkpoint calls
+	// to check for newer versions of themselves unless disabled.
+	os.Setenv(command.InputModeEnvVar, "0")
+	os.Setenv("CHECKPOINT_DISABLE", "1")
+}
+
 // Creates the the configuration directory.
 // `configDir` should refer to `~/.terraform.d` or its equivalent
 // on non-UNIX platforms.
@@ -5,12 +5,16 @@ package main
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
 
+	"github.com/hashicorp/go-plugin"
 	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/addrs"
 )
 
 func TestMain_cliArgsFromEnv(t *testing.T) {
@@ -257,6 +261,140 @@ func TestMain_cliArgsFromEnvAdvanced(t *testing.T) {
 	}
 }
 
+func TestMain_projectConfig(t *testing.T) {
+	// Restore original CLI args
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	// Set up test command and restore that
+	commands = make(map[string]cli.CommandFactory)
+	defer func() {
+		commands = nil
+	}()
+	testCommand := &testCommandCLI{}
+	commands["plan"] = func() (cli.Command, error) {
+		return testCommand, nil
+	}
+
+	td := t.TempDir()
+	if err := os.Mkdir(filepath.Join(td, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(td, "env"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	project := `
+var_files    = ["common.tfvars"]
+parallelism  = 4
+plan_summary = "module"
+
+environment = {
+  TF_VAR_project_env = "from-project"
+}
+`
+	if err := os.WriteFile(filepath.Join(td, "tofu.project.hcl"), []byte(project), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(td, "common.tfvars"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TF_VAR_project_env", "")
+	os.Unsetenv("TF_VAR_project_env")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	os.Args = []string{oldArgs[0], "-chdir=" + filepath.Join(td, "env"), "plan", "-parallelism=2"}
+	if exit := realMain(); exit != 0 {
+		t.Fatalf("unexpected exit status %d; want 0", exit)
+	}
+
+	want := []string{
+		"-var-file=" + filepath.Join(td, "common.tfvars"),
+		"-parallelism=4",
+		"-summary=module",
+		"-parallelism=2",
+	}
+	if !reflect.DeepEqual(testCommand.Args, want) {
+		t.Fatalf("wrong args\ngot:  %#v\nwant: %#v", testCommand.Args, want)
+	}
+	if got := os.Getenv("TF_VAR_project_env"); got != "from-project" {
+		t.Fatalf("wrong environment variable value %q", got)
+	}
+}
+
+func TestMain_ciMode(t *testing.T) {
+	// Restore original CLI args
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	// Set up test command and restore that
+	commands = make(map[string]cli.CommandFactory)
+	defer func() {
+		commands = nil
+	}()
+	testCommand := &testCommandCLI{}
+	commands["plan"] = func() (cli.Command, error) {
+		return testCommand, nil
+	}
+
+	tests := map[string]struct {
+		args    []string
+		env     string
+		wantCI  bool
+		wantCmd []string
+	}{
+		"option": {
+			[]string{"-ci", "plan", "-out=tfplan"},
+			"",
+			true,
+			[]string{"-out=tfplan"},
+		},
+		"environment": {
+			[]string{"plan"},
+			"strict",
+			true,
+			[]string{},
+		},
+		"option after the subcommand": {
+			// This is passed on to the subcommand, which will reject it.
+			[]string{"plan", "-ci"},
+			"",
+			false,
+			[]string{"-ci"},
+		},
+		"automation without strict": {
+			[]string{"plan"},
+			"1",
+			false,
+			[]string{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(runningInAutomationEnvName, test.env)
+			t.Setenv("TF_INPUT", "")
+			t.Setenv("CHECKPOINT_DISABLE", "")
+
+			os.Args = append([]string{oldArgs[0]}, test.args...)
+			if exit := realMain(); exit != 0 {
+				t.Fatalf("unexpected exit status %d; want 0", exit)
+			}
+
+			if !reflect.DeepEqual(testCommand.Args, test.wantCmd) {
+				t.Errorf("wrong args\ngot:  %#v\nwant: %#v", testCommand.Args, test.wantCmd)
+			}
+			gotCI := os.Getenv("TF_INPUT") == "0" && os.Getenv("CHECKPOINT_DISABLE") == "1"
+			if gotCI != test.wantCI {
+				t.Errorf("wrong CI mode %t; want %t", gotCI, test.wantCI)
+			}
+		})
+	}
+}
+
 // verify that we output valid autocomplete results
 func TestMain_autoComplete(t *testing.T) {
 	// Restore original CLI args
@@ -297,6 +435,31 @@ func (c *testCommandCLI) Run(args []string) int {
 func (c *testCommandCLI) Synopsis() string { return "" }
 func (c *testCommandCLI) Help() string     { return "" }
 
+func TestParseReattachProviders(t *testing.T) {
+	in := `{"registry.opentofu.org/example/test":{"Protocol":"grpc","ProtocolVersion":6,"Pid":4567,"Test":true,"Addr":{"Network":"unix","String":"/tmp/plugin123"}}}`
+	got, err := parseReattachProviders(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[addrs.Provider]*plugin.ReattachConfig{
+		addrs.MustParseProviderSourceString("example/test"): {
+			Protocol:        plugin.ProtocolGRPC,
+			ProtocolVersion: 6,
+			Pid:             4567,
+			Test:            true,
+			Addr:            &net.UnixAddr{Net: "unix", Name: "/tmp/plugin123"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	if _, err := parseReattachProviders(`{"example/test":{"Addr":{"Network":"udp","String":"localhost:1234"}}}`); err == nil {
+		t.Fatal("expected error for an unsupported network")
+	}
+}
+
 func TestWarnOutput(t *testing.T) {
 	mock := cli.NewMockUi()
 	wrapped := &ui{mock}
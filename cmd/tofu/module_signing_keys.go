@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+
+	"github.com/opentofu/opentofu/internal/command/cliconfig"
+	"github.com/opentofu/opentofu/internal/getmodules"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// moduleSigningKeys returns the signing keys that the CLI configuration's
+// module_signing_keys blocks trust to sign module packages in OCI
+// repositories.
+func moduleSigningKeys(configs map[string]*cliconfig.ConfigModuleSigningKeys) ([]*getmodules.OCISigningKeys, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	// We sort the patterns so that the keys are always tried in the same
+	// order.
+	patterns := make([]string, 0, len(configs))
+	for pattern := range configs {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	var ret []*getmodules.OCISigningKeys
+	for _, pattern := range patterns {
+		config := configs[pattern]
+		trusted := &getmodules.OCISigningKeys{
+			Pattern: pattern,
+		}
+
+		armors := config.GPGPublicKeys
+		for _, filename := range config.GPGPublicKeyFiles {
+			src, err := os.ReadFile(filename)
+			if err != nil {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Failed to read module signing key",
+					fmt.Sprintf("Cannot read the key file %s for the module_signing_keys %q block: %s.", filename, pattern, err),
+				))
+				continue
+			}
+			armors = append(armors, string(src))
+		}
+		for _, armor := range armors {
+			keys, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armor))
+			if err != nil {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid module signing key",
+					fmt.Sprintf("The module_signing_keys %q block has an invalid key: %s.", pattern, err),
+				))
+				continue
+			}
+			trusted.Keys = append(trusted.Keys, keys...)
+		}
+		if len(trusted.Keys) > 0 {
+			ret = append(ret, trusted)
+		}
+	}
+	return ret, diags
+}
@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/apparentlymart/go-userdirs/userdirs"
 	"github.com/hashicorp/terraform-svchost/disco"
@@ -16,6 +17,7 @@ import (
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/command/cliconfig"
 	"github.com/opentofu/opentofu/internal/getproviders"
+	"github.com/opentofu/opentofu/internal/ociclient"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
@@ -221,6 +223,11 @@ func providerSourceForCLIConfigLocation(loc cliconfig.ProviderInstallationLocati
 		}
 		return getproviders.NewHTTPMirrorSource(url, services.CredentialsSource()), nil
 
+	case cliconfig.ProviderInstallationOCIMirror:
+		return getproviders.NewMemoizeSource(
+			getproviders.NewOCIRegistrySource(string(loc), ociclient.NewDockerCredentialsSource()),
+		), nil
+
 	default:
 		// We should not get here because the set of cases above should
 		// be comprehensive for all of the
@@ -239,3 +246,73 @@ func providerDevOverrides(configs []*cliconfig.ProviderInstallation) map[addrs.P
 	// ignore any additional configurations in here.
 	return configs[0].DevOverrides
 }
+
+func providerScopedDevOverrides(configs []*cliconfig.ProviderInstallation) []*cliconfig.ScopedDevOverrides {
+	if len(configs) == 0 {
+		return nil
+	}
+	return configs[0].ScopedDevOverrides
+}
+
+// providerTrustedKeys returns the signing keys that the CLI configuration's
+// provider_signing_keys blocks trust to sign provider packages.
+func providerTrustedKeys(configs map[string]*cliconfig.ConfigProviderSigningKeys) ([]*getproviders.TrustedSigningKeys, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	// We sort the patterns so that the keys are always tried in the same
+	// order.
+	patterns := make([]string, 0, len(configs))
+	for pattern := range configs {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	var ret []*getproviders.TrustedSigningKeys
+	for _, pattern := range patterns {
+		config := configs[pattern]
+		providers, err := getproviders.ParseMultiSourceMatchingPatterns([]string{pattern})
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid provider signing keys pattern",
+				fmt.Sprintf("The provider_signing_keys %q block has an invalid provider matching pattern: %s.", pattern, err),
+			))
+			continue
+		}
+		trusted := &getproviders.TrustedSigningKeys{
+			Providers: providers,
+			Exclusive: config.Exclusive,
+		}
+
+		origin := fmt.Sprintf("provider_signing_keys %q", pattern)
+		armors := config.GPGPublicKeys
+		for _, filename := range config.GPGPublicKeyFiles {
+			src, err := os.ReadFile(filename)
+			if err != nil {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Failed to read provider signing key",
+					fmt.Sprintf("Cannot read the key file %s for the provider_signing_keys %q block: %s.", filename, pattern, err),
+				))
+				continue
+			}
+			armors = append(armors, string(src))
+		}
+		for _, armor := range armors {
+			key, err := getproviders.ParseSigningKey(armor, origin)
+			if err != nil {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid provider signing key",
+					fmt.Sprintf("The provider_signing_keys %q block has an invalid key: %s.", pattern, err),
+				))
+				continue
+			}
+			trusted.Keys = append(trusted.Keys, key)
+		}
+		if len(trusted.Keys) > 0 {
+			ret = append(ret, trusted)
+		}
+	}
+	return ret, diags
+}
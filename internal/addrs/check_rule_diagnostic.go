@@ -1,6 +1,10 @@
 package addrs
 
-import "github.com/opentofu/opentofu/internal/tfdiags"
+import (
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
 
 // DiagnosticExtraCheckRule provides an interface for diagnostic ExtraInfo to
 // retrieve an embedded CheckRule from within a tfdiags.Diagnostic.
@@ -31,6 +35,13 @@ func DiagnosticOriginatesFromCheckRule(diag tfdiags.Diagnostic) (CheckRule, bool
 // It also implements the tfdiags.DiagnosticExtraDoNotConsolidate interface, to
 // stop diagnostics created by check blocks being consolidated.
 //
+// It also implements the tfdiags.DiagnosticExtraAddress interface, so that
+// the UI can say which object's check rule the diagnostic is about.
+//
+// It also implements the tfdiags.DiagnosticExtraValuePath interface, so that
+// the UI can show the full paths of the values that the check rule refers to
+// through "self" or through the name of the variable it validates.
+//
 // It also implements the tfdiags.DiagnosticExtraUnwrapper interface, as nested
 // data blocks will attach this struct but do want to lose any extra info
 // embedded in the original diagnostic.
@@ -43,6 +54,8 @@ type CheckRuleDiagnosticExtra struct {
 var (
 	_ DiagnosticExtraCheckRule                = (*CheckRuleDiagnosticExtra)(nil)
 	_ tfdiags.DiagnosticExtraDoNotConsolidate = (*CheckRuleDiagnosticExtra)(nil)
+	_ tfdiags.DiagnosticExtraAddress          = (*CheckRuleDiagnosticExtra)(nil)
+	_ tfdiags.DiagnosticExtraValuePath        = (*CheckRuleDiagnosticExtra)(nil)
 	_ tfdiags.DiagnosticExtraUnwrapper        = (*CheckRuleDiagnosticExtra)(nil)
 	_ tfdiags.DiagnosticExtraWrapper          = (*CheckRuleDiagnosticExtra)(nil)
 )
@@ -68,3 +81,27 @@ func (c *CheckRuleDiagnosticExtra) DoNotConsolidateDiagnostic() bool {
 func (c *CheckRuleDiagnosticExtra) DiagnosticOriginatesFromCheckRule() CheckRule {
 	return c.CheckRule
 }
+
+func (c *CheckRuleDiagnosticExtra) DiagnosticAddress() string {
+	if c.CheckRule.Container == nil {
+		return ""
+	}
+	return c.CheckRule.Container.String()
+}
+
+func (c *CheckRuleDiagnosticExtra) DiagnosticValuePath(traversal hcl.Traversal) (string, int) {
+	switch container := c.CheckRule.Container.(type) {
+	case AbsInputVariableInstance:
+		if len(traversal) < 2 || traversal.RootName() != "var" {
+			return "", 0
+		}
+		if attr, ok := traversal[1].(hcl.TraverseAttr); ok && attr.Name == container.Variable.Name {
+			return container.String(), 2
+		}
+	case AbsResourceInstance:
+		if traversal.RootName() == "self" {
+			return container.String(), 1
+		}
+	}
+	return "", 0
+}
@@ -106,3 +106,112 @@ func TestDiagnosticOriginatesFromCheckRule_Passes(t *testing.T) {
 		t.Errorf("second diag did originate from check rule but this it did not")
 	}
 }
+
+func TestCheckRuleDiagnosticExtra_Address(t *testing.T) {
+	variable := AbsInputVariableInstance{
+		Module: RootModuleInstance.Child("network", IntKey(1)),
+		Variable: InputVariable{
+			Name: "cidr",
+		},
+	}
+
+	var diags tfdiags.Diagnostics
+	diags = diags.Append(&hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Invalid value for variable",
+		Detail:   "The CIDR block is too small.",
+		Extra: &CheckRuleDiagnosticExtra{
+			CheckRule: NewCheckRule(variable, InputValidation, 0),
+		},
+	})
+	diags = diags.Append(tfdiags.Override(tfdiags.Sourceless(
+		tfdiags.Error,
+		"original error",
+		"this is an error",
+	), tfdiags.Warning, func() tfdiags.DiagnosticExtraWrapper {
+		return &CheckRuleDiagnosticExtra{
+			CheckRule: NewCheckRule(AbsCheck{
+				Module: RootModuleInstance,
+				Check: Check{
+					Name: "check",
+				},
+			}, CheckDataResource, 0),
+		}
+	}))
+
+	if got, want := diags[0].Description().Address, "module.network[1].var.cidr"; got != want {
+		t.Errorf("wrong address for first diag\ngot:  %s\nwant: %s", got, want)
+	}
+	if got, want := diags[1].Description().Address, "check.check"; got != want {
+		t.Errorf("wrong address for second diag\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestCheckRuleDiagnosticExtra_ValuePath(t *testing.T) {
+	variable := AbsInputVariableInstance{
+		Module: RootModuleInstance.Child("network", IntKey(1)),
+		Variable: InputVariable{
+			Name: "cidr",
+		},
+	}
+	resource := Resource{
+		Mode: ManagedResourceMode,
+		Type: "test_instance",
+		Name: "web",
+	}.Instance(IntKey(0)).Absolute(RootModuleInstance)
+
+	tests := map[string]struct {
+		rule       CheckRule
+		traversal  hcl.Traversal
+		wantPrefix string
+		wantSteps  int
+	}{
+		"variable": {
+			rule: NewCheckRule(variable, InputValidation, 0),
+			traversal: hcl.Traversal{
+				hcl.TraverseRoot{Name: "var"},
+				hcl.TraverseAttr{Name: "cidr"},
+				hcl.TraverseAttr{Name: "prefix"},
+			},
+			wantPrefix: "module.network[1].var.cidr",
+			wantSteps:  2,
+		},
+		"other variable": {
+			rule: NewCheckRule(variable, InputValidation, 0),
+			traversal: hcl.Traversal{
+				hcl.TraverseRoot{Name: "var"},
+				hcl.TraverseAttr{Name: "other"},
+			},
+		},
+		"self": {
+			rule: NewCheckRule(resource, ResourcePostcondition, 0),
+			traversal: hcl.Traversal{
+				hcl.TraverseRoot{Name: "self"},
+				hcl.TraverseAttr{Name: "ami"},
+			},
+			wantPrefix: "test_instance.web[0]",
+			wantSteps:  1,
+		},
+		"other resource": {
+			rule: NewCheckRule(resource, ResourcePrecondition, 0),
+			traversal: hcl.Traversal{
+				hcl.TraverseRoot{Name: "test_instance"},
+				hcl.TraverseAttr{Name: "other"},
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var diags tfdiags.Diagnostics
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Check failed",
+				Extra:    &CheckRuleDiagnosticExtra{CheckRule: test.rule},
+			})
+			prefix, steps := tfdiags.DiagnosticValuePath(diags[0], test.traversal)
+			if prefix != test.wantPrefix || steps != test.wantSteps {
+				t.Errorf("wrong result\ngot:  %q, %d\nwant: %q, %d", prefix, steps, test.wantPrefix, test.wantSteps)
+			}
+		})
+	}
+}
@@ -12,9 +12,9 @@ import (
 	tfaddr "github.com/opentofu/registry-address"
 )
 
-// ModuleSource is the general type for all three of the possible module source
+// ModuleSource is the general type for all four of the possible module source
 // address types. The concrete implementations of this are ModuleSourceLocal,
-// ModuleSourceRegistry, and ModuleSourceRemote.
+// ModuleSourceAlias, ModuleSourceRegistry, and ModuleSourceRemote.
 type ModuleSource interface {
 	// String returns a full representation of the address, including any
 	// additional components that are typically implied by omission in
@@ -38,6 +38,7 @@ type ModuleSource interface {
 }
 
 var _ ModuleSource = ModuleSourceLocal("")
+var _ ModuleSource = ModuleSourceAlias{}
 var _ ModuleSource = ModuleSourceRegistry{}
 var _ ModuleSource = ModuleSourceRemote{}
 
@@ -51,10 +52,12 @@ var moduleSourceLocalPrefixes = []string{
 // ParseModuleSource parses a module source address as given in the "source"
 // argument inside a "module" block in the configuration.
 //
-// For historical reasons this syntax is a bit overloaded, supporting three
+// For historical reasons this syntax is a bit overloaded, supporting four
 // different address types:
 //   - Local paths starting with either ./ or ../, which are special because
 //     OpenTofu considers them to belong to the same "package" as the caller.
+//   - Module alias paths starting with alias::, which refer to a directory
+//     named in the project configuration rather than relative to the caller.
 //   - Module registry addresses, given as either NAMESPACE/NAME/SYSTEM or
 //     HOST/NAMESPACE/NAME/SYSTEM, in which case the remote registry serves
 //     as an indirection over the third address type that follows.
@@ -80,6 +83,14 @@ func ParseModuleSource(raw string) (ModuleSource, error) {
 		return localAddr, nil
 	}
 
+	if strings.HasPrefix(raw, moduleSourceAliasPrefix) {
+		aliasAddr, err := parseModuleSourceAlias(raw)
+		if err != nil {
+			return nil, err
+		}
+		return aliasAddr, nil
+	}
+
 	// For historical reasons, whether an address is a registry
 	// address is defined only by whether it can be successfully
 	// parsed as one, and anything else must fall through to be
@@ -191,6 +202,81 @@ func (s ModuleSourceLocal) ForDisplay() string {
 	return string(s)
 }
 
+const moduleSourceAliasPrefix = "alias::"
+
+// ModuleSourceAlias is a ModuleSource representing a path within a directory
+// which has a name, called a module alias, in the project configuration.
+//
+// Module aliases allow the modules of a large repository to call each other
+// without long relative paths that must all be updated when directories are
+// reorganized. Like local paths, module alias paths refer to directories on
+// the local filesystem, but each aliased directory is a separate module
+// package, because it isn't relative to the caller.
+//
+// The module installer resolves module aliases to directories when
+// installing modules, so other subsystems use the directory recorded in the
+// module manifest and don't need the project configuration.
+type ModuleSourceAlias struct {
+	// Alias is the name of the module alias.
+	Alias string
+
+	// Path is a normalized relative path within the aliased directory using
+	// forward slashes, or an empty string to refer to the aliased directory
+	// itself. It never starts with "../".
+	Path string
+}
+
+func parseModuleSourceAlias(raw string) (ModuleSourceAlias, error) {
+	rest := strings.ReplaceAll(strings.TrimPrefix(raw, moduleSourceAliasPrefix), `\`, "/")
+	name, subPath, _ := strings.Cut(rest, "/")
+	if !ValidModuleAliasName(name) {
+		return ModuleSourceAlias{}, fmt.Errorf("module alias name %q is invalid: must start with a letter and contain only letters, digits, dashes, and underscores", name)
+	}
+
+	var clean string
+	if subPath != "" {
+		clean = path.Clean(subPath)
+		if clean == "." {
+			clean = ""
+		}
+		if clean == ".." || strings.HasPrefix(clean, "../") || path.IsAbs(clean) {
+			return ModuleSourceAlias{}, fmt.Errorf("module alias path %q must be within the directory of the alias %q", subPath, name)
+		}
+	}
+
+	return ModuleSourceAlias{Alias: name, Path: clean}, nil
+}
+
+// ValidModuleAliasName returns true if the given string is a valid name for
+// a module alias.
+func ValidModuleAliasName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && (r >= '0' && r <= '9' || r == '-' || r == '_'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (s ModuleSourceAlias) moduleSource() {}
+
+func (s ModuleSourceAlias) String() string {
+	if s.Path == "" {
+		return moduleSourceAliasPrefix + s.Alias
+	}
+	return moduleSourceAliasPrefix + s.Alias + "/" + s.Path
+}
+
+func (s ModuleSourceAlias) ForDisplay() string {
+	return s.String()
+}
+
 // ModuleSourceRegistry is a ModuleSource representing a module listed in a
 // OpenTofu module registry.
 //
@@ -54,6 +54,28 @@ func TestParseModuleSource(t *testing.T) {
 			want:  ModuleSourceLocal("./nope/nope/why/please/don't"),
 		},
 
+		// Module aliases
+		"alias root": {
+			input: "alias::networking",
+			want:  ModuleSourceAlias{Alias: "networking"},
+		},
+		"alias with path": {
+			input: "alias::networking/vpc",
+			want:  ModuleSourceAlias{Alias: "networking", Path: "vpc"},
+		},
+		"alias with path non-normalized": {
+			input: `alias::networking/nope/..\vpc//public/`,
+			want:  ModuleSourceAlias{Alias: "networking", Path: "vpc/public"},
+		},
+		"alias with invalid name": {
+			input:   "alias::1networking/vpc",
+			wantErr: `module alias name "1networking" is invalid: must start with a letter and contain only letters, digits, dashes, and underscores`,
+		},
+		"alias with path escaping the alias": {
+			input:   "alias::networking/vpc/../../other",
+			wantErr: `module alias path "vpc/../../other" must be within the directory of the alias "networking"`,
+		},
+
 		// Registry addresses
 		// (NOTE: There is another test function TestParseModuleSourceRegistry
 		// which tests this situation more exhaustively, so this is just a
@@ -222,6 +244,20 @@ func TestParseModuleSource(t *testing.T) {
 			},
 		},
 
+		"OCI repository": {
+			input: "oci://ghcr.io/example/modules/vpc?tag=1.0.0",
+			want: ModuleSourceRemote{
+				Package: ModulePackage("oci://ghcr.io/example/modules/vpc?tag=1.0.0"),
+			},
+		},
+		"OCI repository, subdir": {
+			input: "oci://ghcr.io/example/modules/network//vpc?tag=1.0.0",
+			want: ModuleSourceRemote{
+				Package: ModulePackage("oci://ghcr.io/example/modules/network?tag=1.0.0"),
+				Subdir:  "vpc",
+			},
+		},
+
 		"Amazon S3 bucket implied, archive object": {
 			input: "s3-eu-west-1.amazonaws.com/examplecorp-terraform-modules/vpc.zip",
 			want: ModuleSourceRemote{
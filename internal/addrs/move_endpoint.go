@@ -54,6 +54,33 @@ func (e *MoveEndpoint) String() string {
 	return e.relSubject.String()
 }
 
+// InModuleCall returns a copy of the receiver with the given module call,
+// without an instance key, added to the start of its relative address. The
+// result is therefore relative to the module that declares that call, rather
+// than to the called module.
+func (e *MoveEndpoint) InModuleCall(name string) *MoveEndpoint {
+	step := ModuleInstanceStep{Name: name, InstanceKey: NoKey}
+
+	var relSubject AbsMoveable
+	switch subject := e.relSubject.(type) {
+	case ModuleInstance:
+		relSubject = append(ModuleInstance{step}, subject...)
+	case AbsResourceInstance:
+		relSubject = AbsResourceInstance{
+			Module:   append(ModuleInstance{step}, subject.Module...),
+			Resource: subject.Resource,
+		}
+	default:
+		// ParseMoveEndpoint only produces the two types above
+		panic(fmt.Sprintf("unsupported relative subject type %T", subject))
+	}
+
+	return &MoveEndpoint{
+		SourceRange: e.SourceRange,
+		relSubject:  relSubject,
+	}
+}
+
 func (e *MoveEndpoint) Equal(other *MoveEndpoint) bool {
 	switch {
 	case (e == nil) != (other == nil):
@@ -633,3 +633,33 @@ func TestMoveEndpointConfigMoveable(t *testing.T) {
 		})
 	}
 }
+
+func TestMoveEndpointInModuleCall(t *testing.T) {
+	tests := map[string]string{
+		`foo.bar`:                   `module.call.foo.bar`,
+		`foo.bar["a"]`:              `module.call.foo.bar["a"]`,
+		`module.boop.foo.bar`:       `module.call.module.boop.foo.bar`,
+		`module.boop`:               `module.call.module.boop`,
+		`module.boop[1].module.bap`: `module.call.module.boop[1].module.bap`,
+	}
+
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			traversal, hclDiags := hclsyntax.ParseTraversalAbs([]byte(input), "", hcl.InitialPos)
+			if hclDiags.HasErrors() {
+				t.Fatalf("syntax error: %s", hclDiags.Error())
+			}
+			ep, diags := ParseMoveEndpoint(traversal)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected error: %s", diags.Err().Error())
+			}
+
+			if got := ep.InModuleCall("call").String(); got != want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+			}
+			if got := ep.String(); got != input {
+				t.Errorf("receiver was modified\ngot:  %s\nwant: %s", got, input)
+			}
+		})
+	}
+}
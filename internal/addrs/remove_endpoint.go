@@ -0,0 +1,140 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package addrs
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// RemoveEndpoint is to ConfigMoveable what MoveEndpoint is to AbsMoveable:
+// a wrapping struct that captures the result of decoding an HCL traversal
+// representing a relative path from the current module to a resource or
+// module call that has been removed from the configuration.
+//
+// Unlike MoveEndpoint, a RemoveEndpoint never includes instance keys,
+// because a "removed" statement always applies to all instances of the
+// object it refers to.
+type RemoveEndpoint struct {
+	// SourceRange is the location of the physical endpoint address
+	// in configuration, if this RemoveEndpoint was decoded from a
+	// configuration expression.
+	SourceRange tfdiags.SourceRange
+
+	// RelSubject is either a ConfigResource or a Module, giving the address
+	// of the removed object relative to the module where the endpoint was
+	// declared.
+	RelSubject ConfigMoveable
+}
+
+func (e *RemoveEndpoint) String() string {
+	switch subject := e.RelSubject.(type) {
+	case ConfigResource:
+		return subject.String()
+	case Module:
+		return subject.String()
+	default:
+		// No other types should be possible
+		panic(fmt.Sprintf("unsupported ConfigMoveable type %T", subject))
+	}
+}
+
+// ConfigMoveable returns the absolute address of the removed object, given
+// the address of the module where the endpoint was declared.
+func (e *RemoveEndpoint) ConfigMoveable(baseModule Module) ConfigMoveable {
+	switch subject := e.RelSubject.(type) {
+	case ConfigResource:
+		return ConfigResource{
+			Module:   joinModule(baseModule, subject.Module),
+			Resource: subject.Resource,
+		}
+	case Module:
+		return joinModule(baseModule, subject)
+	default:
+		// No other types should be possible
+		panic(fmt.Sprintf("unsupported ConfigMoveable type %T", subject))
+	}
+}
+
+func joinModule(base, rel Module) Module {
+	ret := make(Module, 0, len(base)+len(rel))
+	ret = append(ret, base...)
+	return append(ret, rel...)
+}
+
+// ParseRemoveEndpoint attempts to interpret the given traversal as a
+// "remove endpoint" address, which is a relative path from the module
+// containing the traversal to a managed resource or a module call that
+// has been removed from the configuration.
+//
+// Error diagnostics are returned if the traversal contains instance keys,
+// refers to a data resource, or is otherwise invalid.
+func ParseRemoveEndpoint(traversal hcl.Traversal) (*RemoveEndpoint, tfdiags.Diagnostics) {
+	path, remain, diags := parseModuleInstancePrefix(traversal)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	rng := tfdiags.SourceRangeFromHCL(traversal.SourceRange())
+
+	for _, step := range path {
+		if step.InstanceKey != NoKey {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid address",
+				Detail:   "A removed object address must not include module instance keys, because it applies to all instances of the module.",
+				Subject:  traversal.SourceRange().Ptr(),
+			})
+			return nil, diags
+		}
+	}
+
+	if len(remain) == 0 {
+		if len(path) == 0 {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid address",
+				Detail:   "A removed object address must refer to a resource or a module call.",
+				Subject:  traversal.SourceRange().Ptr(),
+			})
+			return nil, diags
+		}
+		return &RemoveEndpoint{
+			RelSubject:  path.Module(),
+			SourceRange: rng,
+		}, diags
+	}
+
+	riAddr, moreDiags := parseResourceInstanceUnderModule(path, remain)
+	diags = diags.Append(moreDiags)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	if riAddr.Resource.Resource.Mode == DataResourceMode {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid address",
+			Detail:   "A removed object address must not refer to a data resource, because OpenTofu removes data resources from the state automatically.",
+			Subject:  traversal.SourceRange().Ptr(),
+		})
+		return nil, diags
+	}
+	if riAddr.Resource.Key != NoKey {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid address",
+			Detail:   "A removed object address must not include a resource instance key, because it applies to all instances of the resource.",
+			Subject:  traversal.SourceRange().Ptr(),
+		})
+		return nil, diags
+	}
+
+	return &RemoveEndpoint{
+		RelSubject:  riAddr.ConfigResource(),
+		SourceRange: rng,
+	}, diags
+}
@@ -0,0 +1,129 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package addrs
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func TestParseRemoveEndpoint(t *testing.T) {
+	tests := []struct {
+		Input   string
+		WantRel ConfigMoveable
+		WantErr string
+	}{
+		{
+			`foo.bar`,
+			ConfigResource{
+				Module: RootModule,
+				Resource: Resource{
+					Mode: ManagedResourceMode,
+					Type: "foo",
+					Name: "bar",
+				},
+			},
+			``,
+		},
+		{
+			`module.boop.foo.bar`,
+			ConfigResource{
+				Module: Module{"boop"},
+				Resource: Resource{
+					Mode: ManagedResourceMode,
+					Type: "foo",
+					Name: "bar",
+				},
+			},
+			``,
+		},
+		{
+			`module.boop`,
+			Module{"boop"},
+			``,
+		},
+		{
+			`module.boop.module.beep`,
+			Module{"boop", "beep"},
+			``,
+		},
+		{
+			`foo.bar[0]`,
+			nil,
+			`Invalid address: A removed object address must not include a resource instance key, because it applies to all instances of the resource.`,
+		},
+		{
+			`module.boop[0].foo.bar`,
+			nil,
+			`Invalid address: A removed object address must not include module instance keys, because it applies to all instances of the module.`,
+		},
+		{
+			`module.boop["a"]`,
+			nil,
+			`Invalid address: A removed object address must not include module instance keys, because it applies to all instances of the module.`,
+		},
+		{
+			`data.foo.bar`,
+			nil,
+			`Invalid address: A removed object address must not refer to a data resource, because OpenTofu removes data resources from the state automatically.`,
+		},
+		{
+			`foo`,
+			nil,
+			`Invalid address: Resource specification must include a resource type and name.`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Input, func(t *testing.T) {
+			traversal, hclDiags := hclsyntax.ParseTraversalAbs([]byte(test.Input), "", hcl.InitialPos)
+			if hclDiags.HasErrors() {
+				// We're not trying to test the HCL parser here, so any
+				// failures at this point are likely to be bugs in the
+				// test case itself.
+				t.Fatalf("syntax error: %s", hclDiags.Error())
+			}
+
+			removeEp, diags := ParseRemoveEndpoint(traversal)
+
+			switch {
+			case test.WantErr != "":
+				if !diags.HasErrors() {
+					t.Fatalf("unexpected success\nwant error: %s", test.WantErr)
+				}
+				gotErr := diags.Err().Error()
+				if gotErr != test.WantErr {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", gotErr, test.WantErr)
+				}
+			default:
+				if diags.HasErrors() {
+					t.Fatalf("unexpected error: %s", diags.Err().Error())
+				}
+				if diff := cmp.Diff(test.WantRel, removeEp.RelSubject); diff != "" {
+					t.Errorf("wrong result\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestRemoveEndpointConfigMoveable(t *testing.T) {
+	ep := &RemoveEndpoint{
+		RelSubject: ConfigResource{
+			Module:   Module{"child"},
+			Resource: Resource{Mode: ManagedResourceMode, Type: "foo", Name: "bar"},
+		},
+	}
+	got := ep.ConfigMoveable(Module{"parent"})
+	want := ConfigResource{
+		Module:   Module{"parent", "child"},
+		Resource: Resource{Mode: ManagedResourceMode, Type: "foo", Name: "bar"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
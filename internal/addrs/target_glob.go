@@ -0,0 +1,170 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package addrs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// TargetGlob is a Targetable that selects the resources and modules whose
+// addresses match a pattern, in which each "*" matches any sequence of
+// characters within a single name or instance key, such as "module.app_*",
+// "aws_instance.*" or `aws_instance.web["prod-*"]`.
+//
+// Like other targetable addresses, a pattern contains everything within
+// the objects it matches, so "module.app_*" also selects all of the
+// resources in the matching modules.
+type TargetGlob struct {
+	targetable
+	pattern string
+
+	// instances matches the addresses of module and resource instances,
+	// while config matches the addresses of whole modules and resources,
+	// which have no instance keys. config is nil if the pattern selects
+	// only some of the instances of the objects it matches.
+	instances *regexp.Regexp
+	config    *regexp.Regexp
+}
+
+var _ Targetable = TargetGlob{}
+
+// IsTargetGlob returns true if the given string is a pattern to be parsed
+// with ParseTargetGlob rather than an address to be parsed with
+// ParseTargetStr.
+func IsTargetGlob(str string) bool {
+	return strings.Contains(str, "*")
+}
+
+// ParseTargetGlob parses the given pattern, which must be a valid target
+// address once each "*" is replaced with a name or instance key.
+func ParseTargetGlob(str string) (TargetGlob, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	// We check the syntax of the pattern by parsing it as an ordinary
+	// target address with each "*" replaced with something that is valid
+	// wherever a "*" is allowed.
+	example := strings.ReplaceAll(str, "[*]", "[0]")
+	example = strings.ReplaceAll(example, "*", "x")
+	if _, moreDiags := ParseTargetStr(example); moreDiags.HasErrors() {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid address pattern",
+			fmt.Sprintf("The pattern %q is not a valid resource or module address with some parts replaced by \"*\": %s", str, moreDiags.Err()),
+		))
+		return TargetGlob{}, diags
+	}
+
+	instances, config := targetGlobPatterns(str)
+	ret := TargetGlob{
+		pattern:   str,
+		instances: regexp.MustCompile(instances),
+	}
+	if config != "" {
+		ret.config = regexp.MustCompile(config)
+	}
+	return ret, diags
+}
+
+// targetGlobPatterns returns the regular expressions for the instances
+// and the config fields of a TargetGlob with the given pattern.
+func targetGlobPatterns(pattern string) (instances, config string) {
+	var inst, conf strings.Builder
+	wholeObjects := true
+	inKey, inQuote := false, false
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		var re string
+		switch {
+		case c == '*' && inQuote:
+			re = `[^"]*`
+		case c == '*' && inKey:
+			re = `[^\]]*`
+		case c == '*':
+			re = `[^.\[\]]*`
+		default:
+			re = regexp.QuoteMeta(string(c))
+		}
+		inst.WriteString(re)
+
+		switch {
+		case c == '"' && inKey:
+			inQuote = !inQuote
+		case c == '[' && !inQuote:
+			inKey = true
+			if !strings.HasPrefix(pattern[i:], "[*]") {
+				wholeObjects = false
+			}
+		case c == ']' && !inQuote:
+			inKey = false
+			continue
+		}
+		if !inKey {
+			conf.WriteString(re)
+		}
+	}
+
+	// An address also contains everything nested inside it, which
+	// always begins with either an instance key or another step.
+	const suffix = `(?:$|[.\[])`
+	instances = "^" + inst.String() + suffix
+	if wholeObjects {
+		config = "^" + conf.String() + suffix
+	}
+	return instances, config
+}
+
+// TargetContains implements Targetable, returning true if the given address
+// matches the pattern or is nested inside an object that does.
+//
+// A pattern contains a module or resource address with no instance keys
+// only if the pattern matches all of its instances.
+func (g TargetGlob) TargetContains(other Targetable) bool {
+	switch other.(type) {
+	case ConfigResource, Module:
+		return g.config != nil && g.config.MatchString(other.String())
+	default:
+		return g.instances.MatchString(other.String())
+	}
+}
+
+// Config returns a pattern that matches the modules and resources that
+// have at least one instance matching the receiver, by ignoring its
+// instance keys.
+func (g TargetGlob) Config() TargetGlob {
+	var b strings.Builder
+	inKey, inQuote := false, false
+	for i := 0; i < len(g.pattern); i++ {
+		c := g.pattern[i]
+		switch {
+		case c == '"' && inKey:
+			inQuote = !inQuote
+		case c == '[' && !inQuote:
+			inKey = true
+		case c == ']' && !inQuote:
+			inKey = false
+			continue
+		}
+		if !inKey {
+			b.WriteByte(c)
+		}
+	}
+	instances, config := targetGlobPatterns(b.String())
+	return TargetGlob{
+		pattern:   b.String(),
+		instances: regexp.MustCompile(instances),
+		config:    regexp.MustCompile(config),
+	}
+}
+
+func (g TargetGlob) AddrType() TargetableAddrType {
+	return TargetGlobAddrType
+}
+
+func (g TargetGlob) String() string {
+	return g.pattern
+}
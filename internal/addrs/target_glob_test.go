@@ -0,0 +1,77 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package addrs
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTargetGlobContains(t *testing.T) {
+	for _, test := range []struct {
+		pattern string
+		other   Targetable
+		expect  bool
+	}{
+		{`module.app_*`, mustParseTarget("module.app_east"), true},
+		{`module.app_*`, mustParseTarget("module.app_east.aws_instance.web"), true},
+		{`module.app_*`, mustParseTarget(`module.app_east["a"].aws_instance.web[0]`), true},
+		{`module.app_*`, mustParseTarget("module.db"), false},
+		{`module.app_*`, Module{"app_east", "child"}, true},
+		{`aws_instance.*`, mustParseTarget("aws_instance.web[1]"), true},
+		{`aws_instance.*`, mustParseTarget("module.app.aws_instance.web"), false},
+		{`aws_instance.*`, ConfigResource{Resource: Resource{Mode: ManagedResourceMode, Type: "aws_instance", Name: "web"}}, true},
+		{`aws_instance.web*`, mustParseTarget("aws_instance.webserver"), true},
+		{`aws_instance.web*`, mustParseTarget("aws_instance.db"), false},
+		{`data.aws_ami.*`, mustParseTarget("data.aws_ami.ubuntu"), true},
+		{`data.aws_ami.*`, mustParseTarget("aws_ami.ubuntu"), false},
+		{`aws_instance.web[*]`, mustParseTarget("aws_instance.web[2]"), true},
+		{`aws_instance.web[*]`, ConfigResource{Resource: Resource{Mode: ManagedResourceMode, Type: "aws_instance", Name: "web"}}, true},
+		{`aws_instance.web["prod-*"]`, mustParseTarget(`aws_instance.web["prod-a.b"]`), true},
+		{`aws_instance.web["prod-*"]`, mustParseTarget(`aws_instance.web["dev-a"]`), false},
+		{`aws_instance.web["prod-*"]`, ConfigResource{Resource: Resource{Mode: ManagedResourceMode, Type: "aws_instance", Name: "web"}}, false},
+		{`module.app[*].aws_instance.*`, mustParseTarget("module.app[3].aws_instance.web"), true},
+		{`module.app[*].aws_instance.*`, mustParseTarget("module.app[3].aws_s3_bucket.logs"), false},
+	} {
+		t.Run(fmt.Sprintf("%s contains %s", test.pattern, test.other), func(t *testing.T) {
+			glob, diags := ParseTargetGlob(test.pattern)
+			if diags.HasErrors() {
+				t.Fatal(diags.Err())
+			}
+			if got := glob.TargetContains(test.other); got != test.expect {
+				t.Fatalf("wrong result %t; want %t", got, test.expect)
+			}
+		})
+	}
+}
+
+func TestTargetGlobConfig(t *testing.T) {
+	glob, diags := ParseTargetGlob(`module.app["a*"].aws_instance.web[*]`)
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	cfg := ConfigResource{
+		Module:   Module{"app"},
+		Resource: Resource{Mode: ManagedResourceMode, Type: "aws_instance", Name: "web"},
+	}
+	if glob.TargetContains(cfg) {
+		t.Errorf("pattern with specific instance keys contains the whole resource")
+	}
+	if got, want := glob.Config().String(), "module.app.aws_instance.web"; got != want {
+		t.Errorf("wrong config pattern %q; want %q", got, want)
+	}
+	if !glob.Config().TargetContains(cfg) {
+		t.Errorf("config pattern doesn't contain the whole resource")
+	}
+}
+
+func TestParseTargetGlob_invalid(t *testing.T) {
+	for _, pattern := range []string{"*", "module.*.", "aws_instance", "aws_instance.web[*"} {
+		t.Run(pattern, func(t *testing.T) {
+			if _, diags := ParseTargetGlob(pattern); !diags.HasErrors() {
+				t.Fatalf("pattern %q was accepted", pattern)
+			}
+		})
+	}
+}
@@ -40,4 +40,5 @@ const (
 	AbsResourceAddrType
 	ModuleAddrType
 	ModuleInstanceAddrType
+	TargetGlobAddrType
 )
@@ -22,8 +22,10 @@ import (
 	"github.com/opentofu/opentofu/internal/configs/configload"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/depsfile"
+	"github.com/opentofu/opentofu/internal/getmodules"
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/plans/planfile"
+	"github.com/opentofu/opentofu/internal/policy"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/states/statemgr"
 	"github.com/opentofu/opentofu/internal/tfdiags"
@@ -169,6 +171,19 @@ type Local interface {
 	LocalRun(*Operation) (*LocalRun, statemgr.Full, tfdiags.Diagnostics)
 }
 
+// ModuleSourceStorage is an optional interface for backends which store
+// state in an object storage service that module packages can also be
+// installed from. Module sources for that service which have the
+// "credentials=backend" argument are installed using the backend's
+// configured client, including its credentials, assumed roles and custom
+// endpoints, rather than only the ambient credentials.
+type ModuleSourceStorage interface {
+	// ModuleSourceStorage returns the go-getter scheme of the module
+	// sources for the backend's service, like "s3", and a client for it,
+	// or a nil client if the backend isn't configured yet.
+	ModuleSourceStorage() (string, getmodules.ObjectStorage)
+}
+
 // LocalRun represents the assortment of objects that we can collect or
 // calculate from an Operation object, which we can then use for local
 // operations.
@@ -240,10 +255,14 @@ type Operation struct {
 	//
 	// PlanOutBackend is the backend to store with the plan. This is the
 	// backend that will be used when applying the plan.
-	PlanId         string
-	PlanRefresh    bool   // PlanRefresh will do a refresh before a plan
-	PlanOutPath    string // PlanOutPath is the path to save the plan
-	PlanOutBackend *plans.Backend
+	//
+	// PlanOutEncryption, if set, causes the plan saved at PlanOutPath to be
+	// encrypted.
+	PlanId            string
+	PlanRefresh       bool   // PlanRefresh will do a refresh before a plan
+	PlanOutPath       string // PlanOutPath is the path to save the plan
+	PlanOutBackend    *plans.Backend
+	PlanOutEncryption *planfile.Encryption
 
 	// ConfigDir is the path to the directory containing the configuration's
 	// root module.
@@ -271,14 +290,42 @@ type Operation struct {
 	// plan and apply arguments but may not work for all backends.
 	PlanFile *planfile.WrappedPlanFile
 
+	// RefinePlanFile, if set, is a saved plan that a plan operation refines
+	// instead of planning everything from scratch. See tofu.PlanOpts.Refine.
+	RefinePlanFile *planfile.WrappedPlanFile
+
 	// The options below are more self-explanatory and affect the runtime
 	// behavior of the operation.
 	PlanMode     plans.Mode
 	AutoApprove  bool
 	Targets      []addrs.Targetable
+	Excludes     []addrs.Targetable
 	ForceReplace []addrs.AbsResourceInstance
 	Variables    map[string]UnparsedVariableValue
 
+	// DriftSelection selects which of the changes made outside of OpenTofu
+	// a refresh-only plan accepts. See tofu.PlanOpts.DriftSelection.
+	DriftSelection *plans.DriftSelection
+
+	// AllowDeferral allows the plan to defer the changes for resources
+	// whose instances can't be determined until apply. See
+	// tofu.PlanOpts.DeferralAllowed.
+	AllowDeferral bool
+
+	// Stage, if not the root module, limits the plan to the resources in
+	// the given module call. See tofu.PlanOpts.Stage.
+	Stage addrs.Module
+
+	// StageManifestPath, if set, is the stage manifest in which an apply of
+	// a plan for a stage records that the stage has been applied.
+	StageManifestPath string
+
+	// ValidationCacheDir is the directory where backends that run operations
+	// locally may record the validations that passed, so that later
+	// operations with the same configuration can skip them. If it is empty,
+	// no cache is used.
+	ValidationCacheDir string
+
 	// Some operations use root module variables only opportunistically or
 	// don't need them at all. If this flag is set, the backend must treat
 	// all variables as optional and provide an unknown value for any required
@@ -307,10 +354,38 @@ type Operation struct {
 	// in, which controls which named state is used.
 	Workspace string
 
+	// StateSource, if set, is used instead of the state of Workspace as the
+	// prior state for the operation. The state it manages is not locked, so
+	// this is valid only for speculative plans which will not be saved or
+	// applied, and for simulated applies which write their state to a copy
+	// that nothing else uses.
+	StateSource statemgr.Full
+
+	// Resume, if set, causes an apply of a saved plan file to record its
+	// progress if it fails partway, and optionally continues a previous
+	// apply of the same plan which failed partway.
+	Resume *ApplyResume
+
+	// Policies are evaluated against the plan before it can be saved or
+	// applied. A plan which violates any policy at the deny level is
+	// treated as if planning failed.
+	Policies []*policy.Policy
+
 	// GenerateConfigOut tells the operation both that it should generate config
 	// for unmatched import targets and where any generated config should be
 	// written to.
 	GenerateConfigOut string
+
+	// GenerateImports tells a plan operation to also write draft import
+	// blocks and configuration into GenerateConfigOut for the existing
+	// remote objects that the providers can list for the managed resource
+	// types in the configuration, but which OpenTofu doesn't manage yet.
+	GenerateImports bool
+
+	// BlastRadius tells a destroy plan operation to summarize the objects
+	// that the plan destroys, in the rendered plan and in the JSON
+	// representation of the plan that policies and hooks receive.
+	BlastRadius bool
 }
 
 // HasConfig returns true if and only if the operation has a ConfigDir value
@@ -362,6 +437,30 @@ func (o *Operation) ReportResult(op *RunningOperation, diags tfdiags.Diagnostics
 	}
 }
 
+// ApplyResume describes how an apply of a saved plan file records and
+// resumes its progress after a partial failure.
+type ApplyResume struct {
+	// ManifestPath is where a resume manifest is written if the apply fails
+	// partway. Any existing file at this path is removed if the apply
+	// succeeds.
+	ManifestPath string
+
+	// PlanPath and PlanHash identify the saved plan file being applied, and
+	// are recorded in the resume manifest.
+	PlanPath string
+	PlanHash string
+
+	// Previous, if set, is the manifest written by an earlier apply of the
+	// same plan file. Only the changes that were still pending at the end
+	// of that apply will be applied.
+	Previous *planfile.ResumeManifest
+
+	// BundlePath, if set, is where a resume bundle is written if the apply
+	// fails partway, in addition to the resume manifest. The bundle can be
+	// used to resume the apply in another working directory.
+	BundlePath string
+}
+
 // RunningOperation is the result of starting an operation.
 type RunningOperation struct {
 	// For implementers of a backend, this context should not wrap the
@@ -390,6 +489,13 @@ type RunningOperation struct {
 	// the exit status because the plan value is not available at that point.
 	PlanEmpty bool
 
+	// PlanActions is populated after a Plan operation completes to record
+	// which resource instance change actions the plan includes. Like
+	// PlanEmpty, this is only used in the CLI to determine the exit status.
+	// It is nil if the backend cannot determine the actions, in which case
+	// callers must treat the actions of a non-empty plan as unknown.
+	PlanActions map[plans.Action]struct{}
+
 	// State is the final state after the operation completed. Persisting
 	// this state is managed by the backend. This should only be read
 	// after the operation completes to avoid read/write races.
@@ -6,6 +6,7 @@
 package init
 
 import (
+	"sort"
 	"sync"
 
 	"github.com/hashicorp/terraform-svchost/disco"
@@ -90,6 +91,24 @@ func Backend(name string) backend.InitFn {
 	return backends[name]
 }
 
+// Names returns the sorted names of all of the available backends, excluding
+// the "cloud" backend which is selected using a cloud block rather than a
+// backend block and so is an implementation detail.
+func Names() []string {
+	backendsLock.Lock()
+	defer backendsLock.Unlock()
+
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		if name == "cloud" {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Set sets a new backend in the list of backends. If f is nil then the
 // backend will be removed from the map. If this backend already exists
 // then it will be overwritten.
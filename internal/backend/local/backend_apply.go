@@ -13,8 +13,10 @@ import (
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/command/views"
+	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/logging"
 	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/plans/planfile"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/states/statefile"
 	"github.com/opentofu/opentofu/internal/states/statemgr"
@@ -51,6 +53,19 @@ func (b *Local) opApply(
 	stateHook := new(StateHook)
 	op.Hooks = append(op.Hooks, stateHook)
 
+	// When applying a saved plan we track which changes were applied, so
+	// that the apply can be resumed if it fails partway.
+	var progressHook *resumeHook
+	if op.Resume != nil && op.PlanFile != nil {
+		progressHook = newResumeHook()
+		op.Hooks = append(op.Hooks, progressHook)
+	}
+
+	// The pre_resource_apply hooks run as part of the apply itself, so we
+	// register them before the configuration that declares them is loaded.
+	hooks := newRunHooks(op, "apply")
+	op.Hooks = append(op.Hooks, hooks)
+
 	// Get our context
 	lr, _, opState, contextDiags := b.localRun(op)
 	diags = diags.Append(contextDiags)
@@ -73,6 +88,13 @@ func (b *Local) opApply(
 	// operation.
 	runningOp.State = lr.InputState
 
+	hooks.configure(lr)
+	defer func() {
+		if diags.HasErrors() {
+			op.View.Diagnostics(hooks.runOnFailure(diags))
+		}
+	}()
+
 	schemas, moreDiags := lr.Core.Schemas(lr.Config, lr.InputState)
 	diags = diags.Append(moreDiags)
 	if moreDiags.HasErrors() {
@@ -88,6 +110,13 @@ func (b *Local) opApply(
 	// If we weren't given a plan, then we refresh/plan
 	if op.PlanFile == nil {
 		// Perform the plan
+		moreDiags = hooks.run(configs.HookPrePlan, hookContext{})
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			op.ReportResult(runningOp, diags)
+			return
+		}
+
 		log.Printf("[INFO] backend/local: apply calling Plan")
 		plan, moreDiags = lr.Core.Plan(lr.Config, lr.InputState, lr.PlanOpts)
 		diags = diags.Append(moreDiags)
@@ -106,11 +135,32 @@ func (b *Local) opApply(
 			return
 		}
 
+		moreDiags = hooks.enrichPlan(lr, plan, schemas)
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			op.ReportResult(runningOp, diags)
+			return
+		}
+
 		trivialPlan := !plan.CanApply()
 		hasUI := op.UIOut != nil && op.UIIn != nil
 		mustConfirm := hasUI && !op.AutoApprove && !trivialPlan
 		op.View.Plan(plan, schemas)
 
+		moreDiags = checkPolicies(op, lr, plan, schemas)
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			op.ReportResult(runningOp, diags)
+			return
+		}
+
+		moreDiags = hooks.runWithPlan(configs.HookPostPlan, lr, plan, schemas)
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			op.ReportResult(runningOp, diags)
+			return
+		}
+
 		if testHookStopPlanApply != nil {
 			testHookStopPlanApply()
 		}
@@ -222,11 +272,25 @@ func (b *Local) opApply(
 				op.View.PlannedChange(change)
 			}
 		}
+
+		// The policies might have changed since the plan was saved, so we
+		// evaluate them again before applying it.
+		moreDiags = checkPolicies(op, lr, plan, schemas)
+		diags = diags.Append(moreDiags)
+		if moreDiags.HasErrors() {
+			op.ReportResult(runningOp, diags)
+			return
+		}
 	}
 
 	// Set up our hook for continuous state updates
 	stateHook.StateMgr = opState
 
+	var resumable []planfile.ResumeObject
+	if progressHook != nil {
+		resumable = resumableObjects(plan)
+	}
+
 	// Start to apply in a goroutine so that we can be interrupted.
 	var applyState *states.State
 	var applyDiags tfdiags.Diagnostics
@@ -242,6 +306,7 @@ func (b *Local) opApply(
 		return
 	}
 	diags = diags.Append(applyDiags)
+	diags = diags.Append(hooks.applyWarnings())
 
 	// Even on error with an empty state, the state value should not be nil.
 	// Return early here to prevent corrupting any existing state.
@@ -268,11 +333,29 @@ func (b *Local) opApply(
 		return
 	}
 
+	if progressHook != nil {
+		// An apply which was interrupted may have stopped before applying
+		// all of the changes without returning any errors.
+		failed := applyDiags.HasErrors() || stopCtx.Err() != nil
+		diags = diags.Append(b.recordApplyProgress(op, resumable, opState, progressHook, failed))
+	}
+
 	if applyDiags.HasErrors() {
 		op.ReportResult(runningOp, diags)
 		return
 	}
 
+	if !plan.Stage.IsRoot() && op.StageManifestPath != "" {
+		diags = diags.Append(b.recordStage(op, plan.Stage, opState))
+	}
+
+	moreDiags = hooks.runWithPlan(configs.HookPostApply, lr, plan, schemas)
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		op.ReportResult(runningOp, diags)
+		return
+	}
+
 	// If we've accumulated any warnings along the way then we'll show them
 	// here just before we show the summary and next steps. If we encountered
 	// errors then we would've returned early at some other point above.
@@ -0,0 +1,244 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/plans/planfile"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/statemgr"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// resumeHook is a hook that records which planned changes were applied
+// successfully, so that a resume manifest can be written if the apply fails
+// partway.
+type resumeHook struct {
+	tofu.NilHook
+	sync.Mutex
+
+	// succeeded is keyed by the string representation of a
+	// planfile.ResumeObject, while failed is keyed by resource instance
+	// address because a failure can leave any of the objects of a resource
+	// instance in an unexpected state.
+	succeeded map[string]bool
+	failed    map[string]bool
+}
+
+var _ tofu.Hook = (*resumeHook)(nil)
+
+func newResumeHook() *resumeHook {
+	return &resumeHook{
+		succeeded: make(map[string]bool),
+		failed:    make(map[string]bool),
+	}
+}
+
+func (h *resumeHook) PostApply(addr addrs.AbsResourceInstance, gen states.Generation, _ cty.Value, err error) (tofu.HookAction, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	if err != nil {
+		h.failed[addr.String()] = true
+		return tofu.HookActionContinue, nil
+	}
+	obj := planfile.ResumeObject{Addr: addr}
+	if dk, ok := gen.(states.DeposedKey); ok {
+		obj.DeposedKey = dk
+	}
+	h.succeeded[obj.String()] = true
+	return tofu.HookActionContinue, nil
+}
+
+// manifest returns a resume manifest describing the progress of applying
+// the given planned changes, or nil if there are no remaining changes to
+// apply.
+func (h *resumeHook) manifest(planned []planfile.ResumeObject, resume *backend.ApplyResume, stateMeta statemgr.SnapshotMeta) *planfile.ResumeManifest {
+	h.Lock()
+	defer h.Unlock()
+
+	ret := &planfile.ResumeManifest{
+		PlanPath: resume.PlanPath,
+		PlanHash: resume.PlanHash,
+		Lineage:  stateMeta.Lineage,
+		Serial:   stateMeta.Serial,
+	}
+	if resume.Previous != nil {
+		ret.Completed = append(ret.Completed, resume.Previous.Completed...)
+	}
+	for _, obj := range planned {
+		if h.succeeded[obj.String()] && !h.failed[obj.Addr.String()] {
+			ret.Completed = append(ret.Completed, obj)
+		} else {
+			ret.Pending = append(ret.Pending, obj)
+		}
+	}
+	if len(ret.Pending) == 0 {
+		return nil
+	}
+	return ret
+}
+
+// resumableObjects returns the objects with planned changes in the given
+// plan which are tracked in resume manifests.
+//
+// This must be called before applying the plan, because OpenTofu Core
+// removes the changes from the plan as it applies them.
+func resumableObjects(plan *plans.Plan) []planfile.ResumeObject {
+	var ret []planfile.ResumeObject
+	for _, change := range plan.Changes.Resources {
+		if resumableChange(change) {
+			ret = append(ret, planfile.ResumeObject{Addr: change.Addr, DeposedKey: change.DeposedKey})
+		}
+	}
+	return ret
+}
+
+// recordApplyProgress writes a resume manifest, and a resume bundle if one
+// was requested, if the apply of a saved plan failed partway, or removes any
+// stale manifest if it succeeded.
+func (b *Local) recordApplyProgress(op *backend.Operation, planned []planfile.ResumeObject, stateMgr statemgr.Full, hook *resumeHook, failed bool) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	if !failed {
+		if err := os.Remove(op.Resume.ManifestPath); err != nil && !os.IsNotExist(err) {
+			log.Printf("[WARN] backend/local: failed to remove resume manifest %s: %s", op.Resume.ManifestPath, err)
+		}
+		return diags
+	}
+
+	sm, ok := stateMgr.(statemgr.PersistentMeta)
+	if !ok {
+		// Without the snapshot metadata we can't check that nothing else
+		// changed the state before resuming.
+		return diags
+	}
+	manifest := hook.manifest(planned, op.Resume, sm.StateSnapshotMeta())
+	if manifest == nil {
+		return diags
+	}
+
+	if err := manifest.Save(op.Resume.ManifestPath); err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Failed to record apply progress",
+			fmt.Sprintf("OpenTofu could not write the resume manifest %s, so this apply cannot be resumed: %s.", op.Resume.ManifestPath, err),
+		))
+		return diags
+	}
+
+	detail := fmt.Sprintf(
+		"%d of the planned changes were applied and %d were not. After resolving the errors, run \"tofu apply -resume\" to apply only the remaining changes from the saved plan %s, or create a new plan.",
+		len(manifest.Completed), len(manifest.Pending), op.Resume.PlanPath,
+	)
+	if op.Resume.BundlePath != "" {
+		if err := planfile.WriteResumeBundle(op.Resume.BundlePath, manifest, op.DependencyLocks); err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Failed to write resume bundle",
+				fmt.Sprintf("OpenTofu could not write the resume bundle %s, so this apply can only be resumed in this working directory: %s.", op.Resume.BundlePath, err),
+			))
+		} else {
+			detail += fmt.Sprintf("\n\nThe resume bundle %s can be used to resume the apply in another working directory, by running \"tofu apply -resume-bundle=%s\" there.", op.Resume.BundlePath, op.Resume.BundlePath)
+		}
+	}
+	diags = diags.Append(tfdiags.Sourceless(
+		tfdiags.Warning,
+		"Apply can be resumed",
+		detail,
+	))
+	return diags
+}
+
+// resumePlan returns a copy of the given plan which includes only the
+// changes that were still pending at the end of the partial apply described
+// by the given manifest, and which applies them to the given current state.
+//
+// It returns error diagnostics if any of the pending changes no longer
+// apply cleanly, because the failed apply left their objects in a different
+// state than the plan was created against.
+func resumePlan(plan *plans.Plan, current *states.State, manifest *planfile.ResumeManifest) (*plans.Plan, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	pending := make(map[string]bool, len(manifest.Pending))
+	for _, obj := range manifest.Pending {
+		pending[obj.String()] = true
+	}
+
+	var changed []string
+	var resources []*plans.ResourceInstanceChangeSrc
+	for _, change := range plan.Changes.Resources {
+		if !resumableChange(change) {
+			resources = append(resources, change)
+			continue
+		}
+		obj := planfile.ResumeObject{Addr: change.Addr, DeposedKey: change.DeposedKey}
+		if !pending[obj.String()] {
+			continue
+		}
+		if !sameStateObject(resumeObjectSrc(plan.PriorState, obj), resumeObjectSrc(current, obj)) {
+			changed = append(changed, obj.String())
+		}
+		resources = append(resources, change)
+	}
+
+	if len(changed) > 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Cannot resume apply",
+			fmt.Sprintf(
+				"The following objects were changed by the failed apply, so their planned changes can no longer be applied safely:\n  - %s\n\nCreate a new plan instead.",
+				strings.Join(changed, "\n  - "),
+			),
+		))
+		return nil, diags
+	}
+
+	changes := *plan.Changes
+	changes.Resources = resources
+	ret := *plan
+	ret.Changes = &changes
+	ret.PriorState = current.DeepCopy()
+	return &ret, diags
+}
+
+// resumableChange returns true if the given change is tracked in resume
+// manifests. Data resources are always read again on resume, and no-op
+// changes have nothing to apply.
+func resumableChange(change *plans.ResourceInstanceChangeSrc) bool {
+	return change.Addr.Resource.Resource.Mode == addrs.ManagedResourceMode && change.Action != plans.NoOp
+}
+
+func resumeObjectSrc(state *states.State, obj planfile.ResumeObject) *states.ResourceInstanceObjectSrc {
+	if state == nil {
+		return nil
+	}
+	is := state.ResourceInstance(obj.Addr)
+	if is == nil {
+		return nil
+	}
+	if obj.DeposedKey == states.NotDeposed {
+		return is.Current
+	}
+	return is.Deposed[obj.DeposedKey]
+}
+
+func sameStateObject(a, b *states.ResourceInstanceObjectSrc) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Status == b.Status && a.SchemaVersion == b.SchemaVersion && bytes.Equal(a.AttrsJSON, b.AttrsJSON)
+}
@@ -0,0 +1,84 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/plans/planfile"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+func TestResumePlan(t *testing.T) {
+	foo := mustResourceInstanceAddr("test_instance.foo")
+	bar := mustResourceInstanceAddr("test_instance.bar")
+	baz := mustResourceInstanceAddr("test_instance.baz")
+	provider := addrs.AbsProviderConfig{
+		Provider: addrs.NewDefaultProvider("test"),
+		Module:   addrs.RootModule,
+	}
+
+	// The plan creates foo and bar and updates baz.
+	priorState := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(baz, &states.ResourceInstanceObjectSrc{
+			Status:    states.ObjectReady,
+			AttrsJSON: []byte(`{"id":"baz","ami":"old"}`),
+		}, provider)
+	})
+	plan := &plans.Plan{
+		Changes: &plans.Changes{
+			Resources: []*plans.ResourceInstanceChangeSrc{
+				{Addr: foo, ChangeSrc: plans.ChangeSrc{Action: plans.Create}},
+				{Addr: bar, ChangeSrc: plans.ChangeSrc{Action: plans.Create}},
+				{Addr: baz, ChangeSrc: plans.ChangeSrc{Action: plans.Update}},
+			},
+		},
+		PriorState: priorState,
+	}
+	manifest := &planfile.ResumeManifest{
+		Completed: []planfile.ResumeObject{{Addr: foo}},
+		Pending:   []planfile.ResumeObject{{Addr: bar}, {Addr: baz}},
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		current := priorState.DeepCopy()
+		current.SyncWrapper().SetResourceInstanceCurrent(foo, &states.ResourceInstanceObjectSrc{
+			Status:    states.ObjectReady,
+			AttrsJSON: []byte(`{"id":"foo"}`),
+		}, provider)
+
+		got, diags := resumePlan(plan, current, manifest)
+		if diags.HasErrors() {
+			t.Fatalf("unexpected errors: %s", diags.Err())
+		}
+		if len(got.Changes.Resources) != 2 || !got.Changes.Resources[0].Addr.Equal(bar) || !got.Changes.Resources[1].Addr.Equal(baz) {
+			t.Fatalf("wrong remaining changes: %#v", got.Changes.Resources)
+		}
+		if got.PriorState.ResourceInstance(foo) == nil {
+			t.Fatalf("resumed plan does not apply to the current state")
+		}
+		if len(plan.Changes.Resources) != 3 {
+			t.Fatalf("original plan was modified")
+		}
+	})
+
+	t.Run("changed by failed apply", func(t *testing.T) {
+		current := priorState.DeepCopy()
+		current.SyncWrapper().SetResourceInstanceCurrent(baz, &states.ResourceInstanceObjectSrc{
+			Status:    states.ObjectTainted,
+			AttrsJSON: []byte(`{"id":"baz","ami":"new"}`),
+		}, provider)
+
+		_, diags := resumePlan(plan, current, manifest)
+		if !diags.HasErrors() {
+			t.Fatalf("succeeded; want error")
+		}
+		if got, want := diags.Err().Error(), "test_instance.baz"; !strings.Contains(got, want) {
+			t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+	})
+}
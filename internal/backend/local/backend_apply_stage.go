@@ -0,0 +1,56 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/plans/planfile"
+	"github.com/opentofu/opentofu/internal/states/statemgr"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// recordStage records in the stage manifest that the given stage was
+// successfully applied in the operation's workspace.
+func (b *Local) recordStage(op *backend.Operation, stage addrs.Module, stateMgr statemgr.Full) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	manifest, err := planfile.LoadStageManifest(op.StageManifestPath)
+	if os.IsNotExist(err) {
+		manifest, err = &planfile.StageManifest{}, nil
+	}
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Failed to record applied stage",
+			fmt.Sprintf("OpenTofu could not read the stage manifest %s, so it does not record that the stage %s was applied: %s.", op.StageManifestPath, stage, err),
+		))
+		return diags
+	}
+
+	record := planfile.StageRecord{
+		Workspace: op.Workspace,
+		Stage:     stage,
+		AppliedAt: time.Now(),
+	}
+	if sm, ok := stateMgr.(statemgr.PersistentMeta); ok {
+		meta := sm.StateSnapshotMeta()
+		record.Lineage = meta.Lineage
+		record.Serial = meta.Serial
+	}
+	manifest.Record(record)
+
+	if err := manifest.Save(op.StageManifestPath); err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Failed to record applied stage",
+			fmt.Sprintf("OpenTofu could not write the stage manifest %s, so it does not record that the stage %s was applied: %s.", op.StageManifestPath, stage, err),
+		))
+	}
+	return diags
+}
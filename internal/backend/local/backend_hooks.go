@@ -0,0 +1,341 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/command/jsonplan"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/httpclient"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/statefile"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// runHooks runs the hooks configured in the root module's hooks block during
+// an operation. It is also a tofu.Hook, which runs the pre_resource_apply
+// hooks before each change to a managed resource instance is applied.
+type runHooks struct {
+	tofu.NilHook
+
+	operation string
+	workspace string
+
+	// config is nil until the configuration has been loaded, which happens
+	// after the tofu.Hook has been registered.
+	config *configs.Hooks
+
+	mu sync.Mutex
+	// warnings are the failures of pre_resource_apply hooks whose error
+	// policy is "warn", which are reported once the apply has completed.
+	warnings tfdiags.Diagnostics
+}
+
+var _ tofu.Hook = (*runHooks)(nil)
+
+func newRunHooks(op *backend.Operation, operation string) *runHooks {
+	return &runHooks{
+		operation: operation,
+		workspace: op.Workspace,
+	}
+}
+
+// configure sets the hooks to run from the root module of the given
+// operation's configuration.
+func (h *runHooks) configure(lr *backend.LocalRun) {
+	if lr.Config != nil && lr.Config.Module != nil {
+		h.config = lr.Config.Module.Hooks
+	}
+}
+
+// hookContext is the JSON document describing an event, which hooks receive
+// on their standard input or in the body of a webhook request.
+type hookContext struct {
+	Event     configs.HookEvent `json:"event"`
+	Operation string            `json:"operation"`
+	Workspace string            `json:"workspace"`
+
+	// Plan is the JSON representation of the plan, for the post_plan and
+	// post_apply events.
+	Plan json.RawMessage `json:"plan,omitempty"`
+
+	// Resource is the change about to be applied, for the
+	// pre_resource_apply event.
+	Resource *hookResource `json:"resource,omitempty"`
+
+	// Errors are the errors that made the operation fail, for the
+	// on_failure event.
+	Errors []hookError `json:"errors,omitempty"`
+}
+
+type hookResource struct {
+	Address string   `json:"address"`
+	Deposed string   `json:"deposed,omitempty"`
+	Actions []string `json:"actions"`
+}
+
+type hookError struct {
+	Summary string `json:"summary"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// run runs the hooks for the given event one at a time, passing each the
+// given context. It stops at the first hook which fails with the "fail"
+// error policy, returning an error for it.
+func (h *runHooks) run(event configs.HookEvent, hc hookContext) tfdiags.Diagnostics {
+	return h.runWithResponses(event, hc, nil)
+}
+
+// runWithResponses is like run, but also calls the given function, if any,
+// with the response of each hook that succeeds: what a command hook writes to
+// its standard output, or the body of a webhook's response. If the function
+// returns an error then the hook is considered to have failed.
+func (h *runHooks) runWithResponses(event configs.HookEvent, hc hookContext, handle func(response []byte) error) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	hooks := h.config.ForEvent(event)
+	if len(hooks) == 0 {
+		return diags
+	}
+
+	hc.Event = event
+	hc.Operation = h.operation
+	hc.Workspace = h.workspace
+	input, err := json.Marshal(hc)
+	if err != nil {
+		return diags.Append(fmt.Errorf("failed to encode the context for the %s hooks: %w", event, err))
+	}
+
+	for _, hook := range hooks {
+		log.Printf("[INFO] backend/local: running %s hook at %s", event, hook.DeclRange)
+		response, output, err := runHook(hook, input)
+		if err == nil && handle != nil {
+			err = handle(response)
+		}
+		if err == nil {
+			continue
+		}
+		detail := fmt.Sprintf("The %s hook failed: %s.", event, err)
+		if output != "" {
+			detail += "\n\nThe hook command's output was:\n" + output
+		}
+
+		severity := tfdiags.Error
+		if hook.OnError == configs.HookErrorWarn {
+			severity = tfdiags.Warning
+		}
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: severity.ToHCL(),
+			Summary:  "Hook failed",
+			Detail:   detail,
+			Subject:  hook.DeclRange.Ptr(),
+		})
+		if severity == tfdiags.Error {
+			break
+		}
+	}
+	return diags
+}
+
+// runHook runs a single hook with the given input, returning its response,
+// or an error if it fails or doesn't complete within its timeout. For command
+// hooks, it also returns the output of the command if it failed.
+func runHook(hook *configs.Hook, input []byte) ([]byte, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), hook.Timeout)
+	defer cancel()
+
+	var response []byte
+	var output string
+	var err error
+	if hook.URL != "" {
+		response, err = runHookWebhook(ctx, hook.URL, input)
+	} else {
+		response, output, err = runHookCommand(ctx, hook.Command, input)
+	}
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, output, fmt.Errorf("it didn't complete within %s", hook.Timeout)
+	}
+	return response, output, err
+}
+
+func runHookCommand(ctx context.Context, command []string, input []byte) ([]byte, string, error) {
+	var stdout bytes.Buffer
+	output := &hookOutput{}
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = io.MultiWriter(&stdout, output)
+	cmd.Stderr = output
+	// The command might start other processes which keep its output open
+	// after it was killed, so we don't wait for them indefinitely.
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	log.Printf("[TRACE] backend/local: output of hook command %q:\n%s", command[0], output.String())
+	if err != nil {
+		return nil, strings.TrimSpace(output.String()), fmt.Errorf("%s: %w", command[0], err)
+	}
+	return stdout.Bytes(), "", nil
+}
+
+// hookOutput collects both the standard output and standard error of a hook
+// command, which are copied from separate goroutines.
+type hookOutput struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (o *hookOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.buf.Write(p)
+}
+
+func (o *hookOutput) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.buf.String()
+}
+
+// maxHookResponseSize is the largest webhook response body that we read.
+const maxHookResponseSize = 16 << 20
+
+func runHookWebhook(ctx context.Context, url string, input []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(input))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpclient.New().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHookResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[TRACE] backend/local: response from hook webhook %s: %s\n%s", url, resp.Status, body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%s responded with %s", url, resp.Status)
+	}
+	return body, nil
+}
+
+// runWithPlan runs the hooks for the given event, passing them the JSON
+// representation of the given plan.
+func (h *runHooks) runWithPlan(event configs.HookEvent, lr *backend.LocalRun, plan *plans.Plan, schemas *tofu.Schemas) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	if len(h.config.ForEvent(event)) == 0 {
+		return diags
+	}
+
+	planJSON, diags := hookPlanJSON(event, lr, plan, schemas)
+	if diags.HasErrors() {
+		return diags
+	}
+	return h.run(event, hookContext{Plan: planJSON})
+}
+
+func hookPlanJSON(event configs.HookEvent, lr *backend.LocalRun, plan *plans.Plan, schemas *tofu.Schemas) (json.RawMessage, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	planJSON, err := jsonplan.Marshal(lr.Config, plan, &statefile.File{State: plan.PriorState}, schemas)
+	if err != nil {
+		return nil, diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to run hooks",
+			fmt.Sprintf("The plan could not be prepared for the %s hooks: %s.", event, err),
+		))
+	}
+	return planJSON, diags
+}
+
+// runOnFailure runs the on_failure hooks for an operation which failed with
+// the given diagnostics.
+func (h *runHooks) runOnFailure(diags tfdiags.Diagnostics) tfdiags.Diagnostics {
+	var errs []hookError
+	for _, diag := range diags {
+		if diag.Severity() != tfdiags.Error {
+			continue
+		}
+		desc := diag.Description()
+		errs = append(errs, hookError{
+			Summary: desc.Summary,
+			Detail:  desc.Detail,
+		})
+	}
+	return h.run(configs.HookOnFailure, hookContext{Errors: errs})
+}
+
+// PreApply runs the pre_resource_apply hooks. A failed hook with the "fail"
+// error policy makes the change to the resource instance fail.
+func (h *runHooks) PreApply(addr addrs.AbsResourceInstance, gen states.Generation, action plans.Action, priorState, plannedNewState cty.Value) (tofu.HookAction, error) {
+	// Data sources are also read using this hook, but they aren't changes
+	// to infrastructure.
+	if action == plans.Read || action == plans.NoOp {
+		return tofu.HookActionContinue, nil
+	}
+
+	resource := &hookResource{
+		Address: addr.String(),
+		Actions: hookActions(action),
+	}
+	if dk, ok := gen.(states.DeposedKey); ok {
+		resource.Deposed = dk.String()
+	}
+
+	diags := h.run(configs.HookPreResourceApply, hookContext{Resource: resource})
+	if diags.HasErrors() {
+		return tofu.HookActionHalt, diags.Err()
+	}
+	h.mu.Lock()
+	h.warnings = h.warnings.Append(diags)
+	h.mu.Unlock()
+	return tofu.HookActionContinue, nil
+}
+
+// applyWarnings returns the failures of the pre_resource_apply hooks whose
+// error policy is "warn".
+func (h *runHooks) applyWarnings() tfdiags.Diagnostics {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.warnings
+}
+
+// hookActions returns the actions of a change as they appear in the JSON
+// representation of a plan.
+func hookActions(action plans.Action) []string {
+	switch action {
+	case plans.Create:
+		return []string{"create"}
+	case plans.Update:
+		return []string{"update"}
+	case plans.Delete:
+		return []string{"delete"}
+	case plans.CreateThenDelete:
+		return []string{"create", "delete"}
+	case plans.DeleteThenCreate:
+		return []string{"delete", "create"}
+	default:
+		return []string{strings.ToLower(action.String())}
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+func TestRunHooks_command(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test hooks are shell commands")
+	}
+
+	out := filepath.Join(t.TempDir(), "context.json")
+	h := &runHooks{
+		operation: "apply",
+		workspace: "default",
+		config: &configs.Hooks{
+			Hooks: []*configs.Hook{
+				{
+					Event:   configs.HookPreResourceApply,
+					Command: []string{"sh", "-c", `cat > "$0"`, out},
+					Timeout: time.Minute,
+					OnError: configs.HookErrorFail,
+				},
+			},
+		},
+	}
+
+	addr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "test_instance",
+		Name: "foo",
+	}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance)
+	action, err := h.PreApply(addr, states.CurrentGen, plans.DeleteThenCreate, cty.NullVal(cty.DynamicPseudoType), cty.NullVal(cty.DynamicPseudoType))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if action != tofu.HookActionContinue {
+		t.Fatalf("wrong hook action %v", action)
+	}
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"event":"pre_resource_apply","operation":"apply","workspace":"default","resource":{"address":"test_instance.foo","actions":["delete","create"]}}`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("wrong hook context\n%s", diff)
+	}
+}
+
+func TestRunHooks_errorPolicy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test hooks are shell commands")
+	}
+
+	out := filepath.Join(t.TempDir(), "ran")
+	failing := func(policy configs.HookErrorPolicy) *configs.Hook {
+		return &configs.Hook{
+			Event:   configs.HookPrePlan,
+			Command: []string{"sh", "-c", "echo not ready; exit 1"},
+			Timeout: time.Minute,
+			OnError: policy,
+		}
+	}
+	succeeding := &configs.Hook{
+		Event:   configs.HookPrePlan,
+		Command: []string{"touch", out},
+		Timeout: time.Minute,
+		OnError: configs.HookErrorFail,
+	}
+
+	t.Run("warn", func(t *testing.T) {
+		h := &runHooks{config: &configs.Hooks{
+			Hooks: []*configs.Hook{failing(configs.HookErrorWarn), succeeding},
+		}}
+		diags := h.run(configs.HookPrePlan, hookContext{})
+		if diags.HasErrors() || len(diags) != 1 {
+			t.Fatalf("expected one warning, got: %s", diags.ErrWithWarnings())
+		}
+		if got, want := diags[0].Description().Detail, "not ready"; !strings.Contains(got, want) {
+			t.Errorf("warning detail doesn't include the command's output %q:\n%s", want, got)
+		}
+		if _, err := os.Stat(out); err != nil {
+			t.Errorf("the next hook didn't run: %s", err)
+		}
+		os.Remove(out)
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		h := &runHooks{config: &configs.Hooks{
+			Hooks: []*configs.Hook{failing(configs.HookErrorFail), succeeding},
+		}}
+		diags := h.run(configs.HookPrePlan, hookContext{})
+		if !diags.HasErrors() {
+			t.Fatal("expected an error")
+		}
+		if _, err := os.Stat(out); err == nil {
+			t.Error("the next hook ran after a hook failed")
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		h := &runHooks{config: &configs.Hooks{
+			Hooks: []*configs.Hook{{
+				Event:   configs.HookPrePlan,
+				Command: []string{"sleep", "10"},
+				Timeout: 100 * time.Millisecond,
+				OnError: configs.HookErrorFail,
+			}},
+		}}
+		diags := h.run(configs.HookPrePlan, hookContext{})
+		if got, want := diags.Err().Error(), "didn't complete within 100ms"; !strings.Contains(got, want) {
+			t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+		}
+	})
+}
+
+func TestRunHooks_webhook(t *testing.T) {
+	var got hookContext
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("invalid request body: %s", err)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	h := &runHooks{
+		operation: "plan",
+		workspace: "default",
+		config: &configs.Hooks{
+			Hooks: []*configs.Hook{{
+				Event:   configs.HookOnFailure,
+				URL:     server.URL,
+				Timeout: time.Minute,
+				OnError: configs.HookErrorFail,
+			}},
+		},
+	}
+
+	var opDiags tfdiags.Diagnostics
+	opDiags = opDiags.Append(tfdiags.Sourceless(tfdiags.Error, "Planning failed", "Something went wrong."))
+	diags := h.runOnFailure(opDiags)
+	if got, want := diags.Err().Error(), "responded with 503 Service Unavailable"; !strings.Contains(got, want) {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+
+	want := hookContext{
+		Event:     configs.HookOnFailure,
+		Operation: "plan",
+		Workspace: "default",
+		Errors: []hookError{
+			{Summary: "Planning failed", Detail: "Something went wrong."},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong hook context\n%s", diff)
+	}
+}
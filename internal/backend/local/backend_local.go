@@ -13,7 +13,9 @@ import (
 	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configload"
+	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/plans/planfile"
+	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/states/statemgr"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/opentofu/opentofu/internal/tofu"
@@ -40,15 +42,23 @@ func (b *Local) localRun(op *backend.Operation) (*backend.LocalRun, *configload.
 	var diags tfdiags.Diagnostics
 
 	// Get the latest state.
-	log.Printf("[TRACE] backend/local: requesting state manager for workspace %q", op.Workspace)
-	s, err := b.StateMgr(op.Workspace)
-	if err != nil {
-		diags = diags.Append(fmt.Errorf("error loading state: %w", err))
-		return nil, nil, nil, diags
-	}
-	log.Printf("[TRACE] backend/local: requesting state lock for workspace %q", op.Workspace)
-	if diags := op.StateLocker.Lock(s, op.Type.String()); diags.HasErrors() {
-		return nil, nil, nil, diags
+	var s statemgr.Full
+	if op.StateSource != nil {
+		// An explicit state source is only ever read, so we don't lock it.
+		log.Printf("[TRACE] backend/local: using explicit state source instead of workspace %q", op.Workspace)
+		s = op.StateSource
+	} else {
+		log.Printf("[TRACE] backend/local: requesting state manager for workspace %q", op.Workspace)
+		var err error
+		s, err = b.StateMgr(op.Workspace)
+		if err != nil {
+			diags = diags.Append(fmt.Errorf("error loading state: %w", err))
+			return nil, nil, nil, diags
+		}
+		log.Printf("[TRACE] backend/local: requesting state lock for workspace %q", op.Workspace)
+		if diags := op.StateLocker.Lock(s, op.Type.String()); diags.HasErrors() {
+			return nil, nil, nil, diags
+		}
 	}
 
 	defer func() {
@@ -92,7 +102,7 @@ func (b *Local) localRun(op *backend.Operation) (*backend.LocalRun, *configload.
 			stateMeta = &m
 		}
 		log.Printf("[TRACE] backend/local: populating backend.LocalRun from plan file")
-		ret, configSnap, ctxDiags = b.localRunForPlanFile(op, lp, ret, &coreOpts, stateMeta)
+		ret, configSnap, ctxDiags = b.localRunForPlanFile(op, lp, ret, &coreOpts, s.State(), stateMeta)
 		if ctxDiags.HasErrors() {
 			diags = diags.Append(ctxDiags)
 			return nil, nil, nil, diags
@@ -197,10 +207,23 @@ func (b *Local) localRunDirect(op *backend.Operation, run *backend.LocalRun, cor
 	planOpts := &tofu.PlanOpts{
 		Mode:               op.PlanMode,
 		Targets:            op.Targets,
+		Excludes:           op.Excludes,
 		ForceReplace:       op.ForceReplace,
 		SetVariables:       variables,
 		SkipRefresh:        op.Type != backend.OperationTypeRefresh && !op.PlanRefresh,
 		GenerateConfigPath: op.GenerateConfigOut,
+		GenerateImports:    op.GenerateImports,
+		DeferralAllowed:    op.AllowDeferral,
+		Stage:              op.Stage,
+		DriftSelection:     op.DriftSelection,
+	}
+	if op.RefinePlanFile != nil {
+		refinement, refineDiags := refinePlan(op, configSnap)
+		diags = diags.Append(refineDiags)
+		if refineDiags.HasErrors() {
+			return nil, nil, diags
+		}
+		planOpts.Refine = refinement
 	}
 	run.PlanOpts = planOpts
 
@@ -208,6 +231,7 @@ func (b *Local) localRunDirect(op *backend.Operation, run *backend.LocalRun, cor
 	// snapshot, from the previous run.
 	run.InputState = s.State()
 
+	coreOpts.ValidationCache = validationCache(op, config, configSnap)
 	tfCtx, moreDiags := tofu.NewContext(coreOpts)
 	diags = diags.Append(moreDiags)
 	if moreDiags.HasErrors() {
@@ -217,7 +241,7 @@ func (b *Local) localRunDirect(op *backend.Operation, run *backend.LocalRun, cor
 	return run, configSnap, diags
 }
 
-func (b *Local) localRunForPlanFile(op *backend.Operation, pf *planfile.Reader, run *backend.LocalRun, coreOpts *tofu.ContextOpts, currentStateMeta *statemgr.SnapshotMeta) (*backend.LocalRun, *configload.Snapshot, tfdiags.Diagnostics) {
+func (b *Local) localRunForPlanFile(op *backend.Operation, pf *planfile.Reader, run *backend.LocalRun, coreOpts *tofu.ContextOpts, currentState *states.State, currentStateMeta *statemgr.SnapshotMeta) (*backend.LocalRun, *configload.Snapshot, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 
 	const errSummary = "Invalid plan file"
@@ -294,7 +318,23 @@ func (b *Local) localRunForPlanFile(op *backend.Operation, pf *planfile.Reader,
 		return nil, snap, diags
 	}
 
-	if currentStateMeta != nil {
+	var resumeFrom *planfile.ResumeManifest
+	if op.Resume != nil {
+		resumeFrom = op.Resume.Previous
+	}
+
+	if resumeFrom != nil {
+		// When resuming an apply that failed partway, the state has already
+		// been changed by that apply, so we instead require that nothing
+		// else has changed it since.
+		if currentStateMeta != nil && (currentStateMeta.Lineage != resumeFrom.Lineage || currentStateMeta.Serial != resumeFrom.Serial) {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Cannot resume apply",
+				"The state was changed by another operation after the saved plan was partially applied, so the remaining changes can no longer be applied safely. Create a new plan instead.",
+			))
+		}
+	} else if currentStateMeta != nil {
 		// If the caller sets this, we require that the stored prior state
 		// has the same metadata, which is an extra safety check that nothing
 		// has changed since the plan was created. (All of the "real-world"
@@ -337,11 +377,32 @@ func (b *Local) localRunForPlanFile(op *backend.Operation, pf *planfile.Reader,
 		))
 		return nil, snap, diags
 	}
+	if resumeFrom != nil {
+		var resumeDiags tfdiags.Diagnostics
+		plan, resumeDiags = resumePlan(plan, currentState, resumeFrom)
+		diags = diags.Append(resumeDiags)
+		if resumeDiags.HasErrors() {
+			return nil, snap, diags
+		}
+		// The remaining changes apply to the state as it was left by the
+		// partial apply, rather than to the prior state in the plan file.
+		run.InputState = plan.PriorState
+	}
+
+	// Ephemeral root module variables are not saved in the plan, so their
+	// values must be provided again when applying it.
+	moreDiags = setEphemeralVariableValues(plan, op.Variables, config.Module.Variables)
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, snap, diags
+	}
+
 	// When we're applying a saved plan, we populate Plan instead of PlanOpts,
 	// because a plan object incorporates the subset of data from PlanOps that
 	// we need to apply the plan.
 	run.Plan = plan
 
+	coreOpts.ValidationCache = validationCache(op, config, snap)
 	tfCtx, moreDiags := tofu.NewContext(coreOpts)
 	diags = diags.Append(moreDiags)
 	if moreDiags.HasErrors() {
@@ -351,6 +412,51 @@ func (b *Local) localRunForPlanFile(op *backend.Operation, pf *planfile.Reader,
 	return run, snap, diags
 }
 
+// setEphemeralVariableValues adds the values of any ephemeral root module
+// variables in the given map to the given plan, which must be a plan read
+// from a plan file, since a saved plan never includes those values.
+//
+// A saved plan already includes the values of all of the other variables, so
+// it's an error to set any of them explicitly with -var or -var-file. Values
+// from automatically-loaded files and environment variables are ignored, as
+// they always have been when applying a saved plan.
+func setEphemeralVariableValues(plan *plans.Plan, vv map[string]backend.UnparsedVariableValue, decls map[string]*configs.Variable) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	for name, rv := range vv {
+		mode := configs.VariableParseLiteral
+		decl, declared := decls[name]
+		if declared {
+			mode = decl.ParsingMode
+		}
+
+		val, valDiags := rv.ParseVariableValue(mode)
+		diags = diags.Append(valDiags)
+		if valDiags.HasErrors() {
+			continue
+		}
+
+		if !declared || !decl.Ephemeral {
+			switch val.SourceType {
+			case tofu.ValueFromCLIArg, tofu.ValueFromNamedFile:
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Can't set variables when applying a saved plan",
+					fmt.Sprintf("The variable %q cannot be set when applying a saved plan file, because a saved plan includes the values of all variables that were set when it was created, except for ephemeral variables.", name),
+				))
+			}
+			continue
+		}
+
+		if plan.EphemeralVariableValues == nil {
+			plan.EphemeralVariableValues = make(map[string]cty.Value)
+		}
+		plan.EphemeralVariableValues[name] = val.Value
+	}
+
+	return diags
+}
+
 // interactiveCollectVariables attempts to complete the given existing
 // map of variables by interactively prompting for any variables that are
 // declared as required but not yet present.
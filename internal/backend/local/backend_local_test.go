@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/zclconf/go-cty/cty"
@@ -15,6 +16,7 @@ import (
 	"github.com/opentofu/opentofu/internal/command/arguments"
 	"github.com/opentofu/opentofu/internal/command/clistate"
 	"github.com/opentofu/opentofu/internal/command/views"
+	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configload"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/initwd"
@@ -95,7 +97,7 @@ func TestLocalRun_cloudPlan(t *testing.T) {
 
 	planPath := "./testdata/plan-bookmark/bookmark.json"
 
-	planFile, err := planfile.OpenWrapped(planPath)
+	planFile, err := planfile.OpenWrapped(planPath, nil)
 	if err != nil {
 		t.Fatalf("unexpected error reading planfile: %s", err)
 	}
@@ -181,7 +183,7 @@ func TestLocalRun_stalePlan(t *testing.T) {
 	if err := planfile.Create(planPath, planfileArgs); err != nil {
 		t.Fatalf("unexpected error writing planfile: %s", err)
 	}
-	planFile, err := planfile.OpenWrapped(planPath)
+	planFile, err := planfile.OpenWrapped(planPath, nil)
 	if err != nil {
 		t.Fatalf("unexpected error reading planfile: %s", err)
 	}
@@ -275,3 +277,61 @@ func (s *stateStorageThatFailsRefresh) RefreshState() error {
 func (s *stateStorageThatFailsRefresh) PersistState(schemas *tofu.Schemas) error {
 	return fmt.Errorf("unimplemented")
 }
+
+func TestSetEphemeralVariableValues(t *testing.T) {
+	decls := map[string]*configs.Variable{
+		"token": {
+			Name:        "token",
+			Type:        cty.String,
+			ParsingMode: configs.VariableParseLiteral,
+			Ephemeral:   true,
+		},
+		"region": {
+			Name:        "region",
+			Type:        cty.String,
+			ParsingMode: configs.VariableParseLiteral,
+		},
+	}
+
+	t.Run("ephemeral variable", func(t *testing.T) {
+		plan := &plans.Plan{}
+		diags := setEphemeralVariableValues(plan, map[string]backend.UnparsedVariableValue{
+			"token":  testUnparsedVariableValue{"secret", tofu.ValueFromCLIArg},
+			"region": testUnparsedVariableValue{"us-east-1", tofu.ValueFromAutoFile},
+		}, decls)
+		if diags.HasErrors() {
+			t.Fatalf("unexpected error: %s", diags.Err())
+		}
+		if got, want := plan.EphemeralVariableValues["token"], cty.StringVal("secret"); !got.RawEquals(want) {
+			t.Errorf("wrong value for token\ngot:  %#v\nwant: %#v", got, want)
+		}
+		if _, ok := plan.EphemeralVariableValues["region"]; ok {
+			t.Errorf("non-ephemeral variable was set")
+		}
+	})
+
+	t.Run("non-ephemeral variable", func(t *testing.T) {
+		plan := &plans.Plan{}
+		diags := setEphemeralVariableValues(plan, map[string]backend.UnparsedVariableValue{
+			"region": testUnparsedVariableValue{"us-east-1", tofu.ValueFromCLIArg},
+		}, decls)
+		if !diags.HasErrors() {
+			t.Fatal("succeeded; want error")
+		}
+		if got, want := diags.Err().Error(), "Can't set variables when applying a saved plan"; !strings.Contains(got, want) {
+			t.Errorf("wrong error\ngot:  %s\nwant: message containing %q", got, want)
+		}
+	})
+}
+
+type testUnparsedVariableValue struct {
+	value      string
+	sourceType tofu.ValueSourceType
+}
+
+func (v testUnparsedVariableValue) ParseVariableValue(mode configs.VariableParsingMode) (*tofu.InputValue, tfdiags.Diagnostics) {
+	return &tofu.InputValue{
+		Value:      cty.StringVal(v.value),
+		SourceType: v.sourceType,
+	}, nil
+}
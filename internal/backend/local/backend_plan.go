@@ -8,8 +8,10 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"strings"
 
 	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/genconfig"
 	"github.com/opentofu/opentofu/internal/logging"
 	"github.com/opentofu/opentofu/internal/plans"
@@ -97,6 +99,21 @@ func (b *Local) opPlan(
 	// resulting state is always just the input state.
 	runningOp.State = lr.InputState
 
+	hooks := newRunHooks(op, "plan")
+	hooks.configure(lr)
+	defer func() {
+		if diags.HasErrors() {
+			op.View.Diagnostics(hooks.runOnFailure(diags))
+		}
+	}()
+
+	moreDiags := hooks.run(configs.HookPrePlan, hookContext{})
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		op.ReportResult(runningOp, diags)
+		return
+	}
+
 	// Perform the plan in a goroutine so we can be interrupted
 	var plan *plans.Plan
 	var planDiags tfdiags.Diagnostics
@@ -132,9 +149,51 @@ func (b *Local) opPlan(
 
 	// Record whether this plan includes any side-effects that could be applied.
 	runningOp.PlanEmpty = !plan.CanApply()
+	runningOp.PlanActions = make(map[plans.Action]struct{})
+	if plan.Changes != nil {
+		for _, rc := range plan.Changes.Resources {
+			if rc.Action != plans.NoOp {
+				runningOp.PlanActions[rc.Action] = struct{}{}
+			}
+		}
+	}
+
+	// We need the schemas both to evaluate policies and to render the plan.
+	schemas, moreDiags := lr.Core.Schemas(lr.Config, lr.InputState)
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		op.ReportResult(runningOp, diags)
+		return
+	}
+
+	// The blast radius is analyzed before the policies are evaluated, so
+	// that they can check it.
+	if op.BlastRadius {
+		plan.BlastRadius = plans.NewBlastRadius(plan.Changes, plan.PriorState)
+	}
+
+	// Policies are evaluated only for complete plans, and a plan which
+	// violates a policy must not be saved because it can't be applied. The
+	// plan is enriched first, so that policies can check what the
+	// plan_enrichment hooks added, and a failure to enrich it rejects it too.
+	var policyDiags tfdiags.Diagnostics
+	if !diags.HasErrors() {
+		policyDiags = hooks.enrichPlan(lr, plan, schemas)
+		if !policyDiags.HasErrors() {
+			policyDiags = policyDiags.Append(checkPolicies(op, lr, plan, schemas))
+		}
+		diags = diags.Append(policyDiags)
+	}
+
+	// A failed post_plan hook rejects the plan in the same way as a policy.
+	if !diags.HasErrors() {
+		moreDiags := hooks.runWithPlan(configs.HookPostPlan, lr, plan, schemas)
+		policyDiags = policyDiags.Append(moreDiags)
+		diags = diags.Append(moreDiags)
+	}
 
 	// Save the plan to disk
-	if path := op.PlanOutPath; path != "" {
+	if path := op.PlanOutPath; path != "" && !policyDiags.HasErrors() {
 		if op.PlanOutBackend == nil {
 			// This is always a bug in the operation caller; it's not valid
 			// to set PlanOutPath without also setting PlanOutBackend.
@@ -170,6 +229,7 @@ func (b *Local) opPlan(
 			StateFile:            plannedStateFile,
 			Plan:                 plan,
 			DependencyLocks:      op.DependencyLocks,
+			Encryption:           op.PlanOutEncryption,
 		})
 		if err != nil {
 			diags = diags.Append(tfdiags.Sourceless(
@@ -184,12 +244,6 @@ func (b *Local) opPlan(
 
 	// Render the plan, if we produced one.
 	// (This might potentially be a partial plan with Errored set to true)
-	schemas, moreDiags := lr.Core.Schemas(lr.Config, lr.InputState)
-	diags = diags.Append(moreDiags)
-	if moreDiags.HasErrors() {
-		op.ReportResult(runningOp, diags)
-		return
-	}
 
 	// Write out any generated config, before we render the plan.
 	wroteConfig, moreDiags := maybeWriteGeneratedConfig(plan, op.GenerateConfigOut)
@@ -208,7 +262,7 @@ func (b *Local) opPlan(
 	// creating it.
 	op.ReportResult(runningOp, diags)
 
-	if !runningOp.PlanEmpty {
+	if !runningOp.PlanEmpty && !policyDiags.HasErrors() {
 		if wroteConfig {
 			op.View.PlanNextStep(op.PlanOutPath, op.GenerateConfigOut)
 		} else {
@@ -240,6 +294,41 @@ func maybeWriteGeneratedConfig(plan *plans.Plan, out string) (wroteConfig bool,
 				return false, diags.Append(moreDiags)
 			}
 		}
+
+		for _, dr := range plan.DiscoveredResources {
+			change := genconfig.Change{
+				Addr:            dr.Addr.String(),
+				ImportID:        dr.ImportID,
+				GeneratedConfig: dr.GeneratedConfig,
+			}
+
+			var moreDiags tfdiags.Diagnostics
+			var wroteThis bool
+			writer, wroteThis, moreDiags = change.MaybeWriteConfig(writer, out)
+			if moreDiags.HasErrors() {
+				return false, diags.Append(moreDiags)
+			}
+			wroteConfig = wroteConfig || wroteThis
+		}
+	}
+
+	if len(plan.DiscoveredResources) > 0 && wroteConfig {
+		var list strings.Builder
+		for _, dr := range plan.DiscoveredResources {
+			fmt.Fprintf(&list, "\n  - %s (ID %q)", dr.Addr, dr.ImportID)
+		}
+		objects := "objects"
+		if len(plan.DiscoveredResources) == 1 {
+			objects = "object"
+		}
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Found objects that OpenTofu doesn't manage",
+			fmt.Sprintf(
+				"The providers listed %d existing %s that OpenTofu doesn't manage yet. Draft import blocks and configuration to adopt them were written to %s:%s\n\nReview them, and move the ones that OpenTofu should manage into your configuration.",
+				len(plan.DiscoveredResources), objects, out, list.String(),
+			),
+		))
 	}
 
 	if wroteConfig {
@@ -0,0 +1,104 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// planEnrichment is the JSON document that plan_enrichment hooks respond
+// with. All of its properties are optional, so that estimators can provide
+// only the information they know about.
+type planEnrichment struct {
+	CostEstimate *struct {
+		Currency  string `json:"currency"`
+		Resources []struct {
+			Address          string   `json:"address"`
+			MonthlyCostDelta *float64 `json:"monthly_cost_delta"`
+		} `json:"resources"`
+	} `json:"cost_estimate"`
+}
+
+// enrichPlan runs the plan_enrichment hooks for the given plan, which must
+// have been created without errors, and adds the information they respond
+// with to the plan.
+func (h *runHooks) enrichPlan(lr *backend.LocalRun, plan *plans.Plan, schemas *tofu.Schemas) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	if len(h.config.ForEvent(configs.HookPlanEnrichment)) == 0 {
+		return diags
+	}
+
+	planJSON, diags := hookPlanJSON(configs.HookPlanEnrichment, lr, plan, schemas)
+	if diags.HasErrors() {
+		return diags
+	}
+	return h.runWithResponses(configs.HookPlanEnrichment, hookContext{Plan: planJSON}, func(response []byte) error {
+		estimate, err := decodeCostEstimate(response, plan)
+		if err != nil {
+			return fmt.Errorf("invalid response: %w", err)
+		}
+		if estimate == nil {
+			return nil
+		}
+		if plan.CostEstimate != nil {
+			return errors.New("another plan_enrichment hook already provided a cost estimate")
+		}
+		plan.CostEstimate = estimate
+		return nil
+	})
+}
+
+// decodeCostEstimate decodes the cost estimate from a plan_enrichment hook's
+// response, returning nil if the response doesn't include one.
+func decodeCostEstimate(response []byte, plan *plans.Plan) (*plans.CostEstimate, error) {
+	if len(bytes.TrimSpace(response)) == 0 {
+		return nil, nil
+	}
+	var raw planEnrichment
+	if err := json.Unmarshal(response, &raw); err != nil {
+		return nil, err
+	}
+	if raw.CostEstimate == nil {
+		return nil, nil
+	}
+
+	if raw.CostEstimate.Currency == "" {
+		return nil, errors.New("the cost estimate has no currency")
+	}
+	ret := &plans.CostEstimate{
+		Currency: raw.CostEstimate.Currency,
+	}
+	seen := make(map[string]bool)
+	for _, rc := range raw.CostEstimate.Resources {
+		addr, diags := addrs.ParseAbsResourceInstanceStr(rc.Address)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("the cost estimate includes the invalid resource instance address %q", rc.Address)
+		}
+		if plan.Changes.ResourceInstance(addr) == nil {
+			return nil, fmt.Errorf("the cost estimate includes %s, which has no planned change", addr)
+		}
+		if seen[addr.String()] {
+			return nil, fmt.Errorf("the cost estimate includes %s more than once", addr)
+		}
+		seen[addr.String()] = true
+		if rc.MonthlyCostDelta == nil {
+			return nil, fmt.Errorf("the cost estimate for %s has no monthly_cost_delta", addr)
+		}
+		ret.Resources = append(ret.Resources, plans.ResourceCostEstimate{
+			Addr:             addr,
+			MonthlyCostDelta: *rc.MonthlyCostDelta,
+		})
+	}
+	return ret, nil
+}
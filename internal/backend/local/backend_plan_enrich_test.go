@@ -0,0 +1,96 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"testing"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/plans"
+)
+
+func TestDecodeCostEstimate(t *testing.T) {
+	addr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "test_instance",
+		Name: "foo",
+	}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance)
+	plan := &plans.Plan{
+		Changes: &plans.Changes{
+			Resources: []*plans.ResourceInstanceChangeSrc{
+				{
+					Addr:        addr,
+					PrevRunAddr: addr,
+					ChangeSrc: plans.ChangeSrc{
+						Action: plans.Create,
+					},
+				},
+			},
+		},
+	}
+
+	testCases := map[string]struct {
+		response  string
+		wantDelta float64
+		wantNil   bool
+		wantErr   string
+	}{
+		"empty": {
+			response: "\n",
+			wantNil:  true,
+		},
+		"no cost estimate": {
+			response: `{"other": true}`,
+			wantNil:  true,
+		},
+		"valid": {
+			response:  `{"cost_estimate": {"currency": "EUR", "resources": [{"address": "test_instance.foo", "monthly_cost_delta": -3.25}]}}`,
+			wantDelta: -3.25,
+		},
+		"invalid JSON": {
+			response: `cost: 3`,
+			wantErr:  "invalid character 'c' looking for beginning of value",
+		},
+		"no currency": {
+			response: `{"cost_estimate": {"resources": []}}`,
+			wantErr:  "the cost estimate has no currency",
+		},
+		"no planned change": {
+			response: `{"cost_estimate": {"currency": "USD", "resources": [{"address": "test_instance.bar", "monthly_cost_delta": 1}]}}`,
+			wantErr:  "the cost estimate includes test_instance.bar, which has no planned change",
+		},
+		"duplicate": {
+			response: `{"cost_estimate": {"currency": "USD", "resources": [{"address": "test_instance.foo", "monthly_cost_delta": 1}, {"address": "test_instance.foo", "monthly_cost_delta": 1}]}}`,
+			wantErr:  "the cost estimate includes test_instance.foo more than once",
+		},
+		"no delta": {
+			response: `{"cost_estimate": {"currency": "USD", "resources": [{"address": "test_instance.foo"}]}}`,
+			wantErr:  "the cost estimate for test_instance.foo has no monthly_cost_delta",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, err := decodeCostEstimate([]byte(tc.response), plan)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("wrong error\ngot:  %v\nwant: %s", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.wantNil {
+				if got != nil {
+					t.Fatalf("unexpected cost estimate: %#v", got)
+				}
+				return
+			}
+			if len(got.Resources) != 1 || got.Resources[0].MonthlyCostDelta != tc.wantDelta {
+				t.Fatalf("wrong cost estimate: %#v", got)
+			}
+		})
+	}
+}
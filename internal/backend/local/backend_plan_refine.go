@@ -0,0 +1,288 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/configs/configload"
+	"github.com/opentofu/opentofu/internal/depsfile"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// refinePlan prepares to refine the saved plan given in the operation, for
+// a new plan of the configuration in the given snapshot.
+//
+// Only changes to resource and data blocks can be refined, because those
+// are the only changes we can trace to the resources they affect. If
+// anything else changed since the saved plan was created, refinePlan returns
+// nil along with a warning, and the new plan is created from scratch.
+//
+// The configuration snapshot doesn't include the other files that functions
+// such as file and templatefile read, so resources that call those
+// functions are always planned again, and a plan can't be refined if
+// anything else calls them.
+func refinePlan(op *backend.Operation, snap *configload.Snapshot) (*tofu.PlanRefinement, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	pf, ok := op.RefinePlanFile.Local()
+	if !ok {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid plan file",
+			"Only local plan files can be refined.",
+		))
+		return nil, diags
+	}
+	plan, err := pf.ReadPlan()
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid plan file",
+			fmt.Sprintf("Failed to read the plan to refine: %s.", err),
+		))
+		return nil, diags
+	}
+	savedSnap, err := pf.ReadConfigSnapshot()
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid plan file",
+			fmt.Sprintf("Failed to read the configuration snapshot from the plan to refine: %s.", err),
+		))
+		return nil, diags
+	}
+	savedLocks, moreDiags := pf.ReadDependencyLocks()
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+
+	changed, reason := changedResources(savedSnap, snap)
+	if reason == "" && !sameProviderVersions(savedLocks, op.DependencyLocks) {
+		reason = "The selected provider versions have changed."
+	}
+	if reason != "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Saved plan can't be refined",
+			fmt.Sprintf("%s OpenTofu will create a new plan from scratch instead.", reason),
+		))
+		return nil, diags
+	}
+
+	return &tofu.PlanRefinement{
+		Plan:             plan,
+		ChangedResources: changed,
+	}, diags
+}
+
+// changedResources compares two configuration snapshots and returns the
+// resources whose blocks were added, removed or changed between them, or
+// which read files. If anything other than resource and data blocks changed
+// or reads files, it instead returns a description of why the changes can't
+// be traced to resources.
+func changedResources(old, new *configload.Snapshot) ([]addrs.ConfigResource, string) {
+	var ret []addrs.ConfigResource
+
+	keys := make([]string, 0, len(new.Modules))
+	for key := range new.Modules {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if len(old.Modules) != len(new.Modules) {
+		return nil, "The module calls in the configuration have changed."
+	}
+
+	for _, key := range keys {
+		oldMod, newMod := old.Modules[key], new.Modules[key]
+		if oldMod == nil || oldMod.SourceAddr != newMod.SourceAddr || (oldMod.Version == nil) != (newMod.Version == nil) || (oldMod.Version != nil && !oldMod.Version.Equal(newMod.Version)) {
+			return nil, "The module calls in the configuration have changed."
+		}
+
+		oldBlocks, ok := snapshotModuleBlocks(oldMod)
+		if !ok {
+			return nil, "The configuration in the saved plan is invalid."
+		}
+		newBlocks, ok := snapshotModuleBlocks(newMod)
+		if !ok {
+			// Planning will fail anyway, so we leave it to report why.
+			return nil, "The configuration is invalid."
+		}
+		desc := "the root module"
+		if key != "" {
+			desc = "module." + strings.ReplaceAll(key, ".", ".module.")
+		}
+		if strings.Join(oldBlocks.other, "\x00") != strings.Join(newBlocks.other, "\x00") {
+			return nil, fmt.Sprintf("The configuration of %s has changed in ways other than its resource and data blocks.", desc)
+		}
+		if newBlocks.otherReadsFiles {
+			return nil, fmt.Sprintf("The configuration of %s reads files outside of its resource and data blocks, and OpenTofu can't tell whether their content has changed.", desc)
+		}
+
+		var module addrs.Module
+		if key != "" {
+			module = addrs.Module(strings.Split(key, "."))
+		}
+		for addr, src := range newBlocks.resources {
+			if oldBlocks.resources[addr] != src || newBlocks.readsFiles[addr] {
+				ret = append(ret, addr.InModule(module))
+			}
+		}
+		for addr := range oldBlocks.resources {
+			if _, exists := newBlocks.resources[addr]; !exists {
+				ret = append(ret, addr.InModule(module))
+			}
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].String() < ret[j].String()
+	})
+	return ret, ""
+}
+
+type snapshotBlocks struct {
+	// resources are the source code of the resource and data blocks.
+	resources map[addrs.Resource]string
+
+	// other is the source code of everything else, in a canonical order.
+	other []string
+
+	// readsFiles records the resource and data blocks that call functions
+	// which read files, and otherReadsFiles is true if anything else does.
+	readsFiles      map[addrs.Resource]bool
+	otherReadsFiles bool
+}
+
+// fileFunctions are the names of the functions that read files, whose
+// results can change without any change to the configuration.
+var fileFunctions = map[string]bool{
+	"file":             true,
+	"filebase64":       true,
+	"filebase64sha256": true,
+	"filebase64sha512": true,
+	"fileexists":       true,
+	"filemd5":          true,
+	"fileset":          true,
+	"filesha1":         true,
+	"filesha256":       true,
+	"filesha512":       true,
+	"templatefile":     true,
+}
+
+// callsFileFunction returns true if the given syntax node calls any of the
+// functions that read files.
+func callsFileFunction(node hclsyntax.Node) bool {
+	var found bool
+	hclsyntax.VisitAll(node, func(node hclsyntax.Node) hcl.Diagnostics {
+		if call, ok := node.(*hclsyntax.FunctionCallExpr); ok && fileFunctions[strings.TrimPrefix(call.Name, "core::")] {
+			found = true
+		}
+		return nil
+	})
+	return found
+}
+
+// snapshotModuleBlocks splits the source code of the given module into its
+// resource and data blocks and everything else. It returns false if the
+// source code can't be parsed.
+//
+// Only the native syntax files that aren't override files are split up,
+// while the other files are included as a whole.
+func snapshotModuleBlocks(mod *configload.SnapshotModule) (snapshotBlocks, bool) {
+	ret := snapshotBlocks{
+		resources:  make(map[addrs.Resource]string),
+		readsFiles: make(map[addrs.Resource]bool),
+	}
+
+	for filename, src := range mod.Files {
+		if !splittableConfigFile(filename) {
+			ret.other = append(ret.other, fmt.Sprintf("file %s\n%s", filename, src))
+			continue
+		}
+
+		f, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+		if diags.HasErrors() {
+			return ret, false
+		}
+		body := f.Body.(*hclsyntax.Body)
+		for _, attr := range body.Attributes {
+			ret.other = append(ret.other, blockSource(src, attr.Range()))
+			ret.otherReadsFiles = ret.otherReadsFiles || callsFileFunction(attr)
+		}
+		for _, block := range body.Blocks {
+			var mode addrs.ResourceMode
+			switch block.Type {
+			case "resource":
+				mode = addrs.ManagedResourceMode
+			case "data":
+				mode = addrs.DataResourceMode
+			}
+			if mode == addrs.InvalidResourceMode || len(block.Labels) != 2 {
+				ret.other = append(ret.other, blockSource(src, block.Range()))
+				ret.otherReadsFiles = ret.otherReadsFiles || callsFileFunction(block)
+				continue
+			}
+			addr := addrs.Resource{
+				Mode: mode,
+				Type: block.Labels[0],
+				Name: block.Labels[1],
+			}
+			if _, exists := ret.resources[addr]; exists {
+				// Duplicate blocks are an error that planning will report.
+				return ret, false
+			}
+			ret.resources[addr] = blockSource(src, block.Range())
+			ret.readsFiles[addr] = callsFileFunction(block)
+		}
+	}
+
+	sort.Strings(ret.other)
+	return ret, true
+}
+
+func splittableConfigFile(filename string) bool {
+	var base string
+	switch {
+	case strings.HasSuffix(filename, ".tf"):
+		base = strings.TrimSuffix(filename, ".tf")
+	case strings.HasSuffix(filename, ".tofu"):
+		base = strings.TrimSuffix(filename, ".tofu")
+	default:
+		return false
+	}
+	return base != "override" && !strings.HasSuffix(base, "_override")
+}
+
+func blockSource(src []byte, rng hcl.Range) string {
+	return string(src[rng.Start.Byte:rng.End.Byte])
+}
+
+// sameProviderVersions returns true if the given dependency locks select the
+// same versions of the same providers.
+func sameProviderVersions(a, b *depsfile.Locks) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	aProviders, bProviders := a.AllProviders(), b.AllProviders()
+	if len(aProviders) != len(bProviders) {
+		return false
+	}
+	for addr, lock := range aProviders {
+		other, ok := bProviders[addr]
+		if !ok || other.Version() != lock.Version() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/opentofu/opentofu/internal/configs/configload"
+)
+
+func TestChangedResources(t *testing.T) {
+	snapshot := func(root, child string) *configload.Snapshot {
+		return &configload.Snapshot{
+			Modules: map[string]*configload.SnapshotModule{
+				"": {
+					Dir:   ".",
+					Files: map[string][]byte{"main.tf": []byte(root)},
+				},
+				"child": {
+					Dir:        "child",
+					SourceAddr: "./child",
+					Files:      map[string][]byte{"main.tf": []byte(child)},
+				},
+			},
+		}
+	}
+
+	const root = `
+module "child" {
+  source = "./child"
+}
+
+resource "test_object" "a" {
+  value = "a"
+}
+
+data "test_data_source" "b" {
+  id = "b"
+}
+`
+	const child = `
+resource "test_object" "c" {
+  value = "c"
+}
+`
+
+	testCases := map[string]struct {
+		root, child string
+		// oldRoot replaces root in the saved snapshot, if set.
+		oldRoot    string
+		want       []string
+		wantReason string
+	}{
+		"unchanged": {
+			root:  root,
+			child: child,
+		},
+		"moved blocks": {
+			root:  strings.Replace(root, `module "child" {`, "\n\nmodule \"child\" {", 1),
+			child: child,
+		},
+		"changed resources": {
+			root:  strings.Replace(root, `id = "b"`, `id = "bb"`, 1),
+			child: strings.Replace(child, `value = "c"`, `value = "cc"`, 1),
+			want:  []string{"data.test_data_source.b", "module.child.test_object.c"},
+		},
+		"added and removed resources": {
+			root:  strings.Replace(root, `resource "test_object" "a"`, `resource "test_object" "d"`, 1),
+			child: child,
+			want:  []string{"test_object.a", "test_object.d"},
+		},
+		"changed module call": {
+			root:       strings.Replace(root, `source = "./child"`, "source = \"./child\"\n  count = 2", 1),
+			child:      child,
+			wantReason: "The configuration of the root module has changed",
+		},
+		"resource reading a template file": {
+			// The template file isn't in the snapshot, so it might have
+			// been edited although the configuration is unchanged.
+			oldRoot: strings.Replace(root, `value = "a"`, `value = templatefile("a.tftpl", {})`, 1),
+			root:    strings.Replace(root, `value = "a"`, `value = templatefile("a.tftpl", {})`, 1),
+			child:   child,
+			want:    []string{"test_object.a"},
+		},
+		"local value reading a file": {
+			oldRoot:    root + "\nlocals {\n  x = file(\"x.txt\")\n}\n",
+			root:       root + "\nlocals {\n  x = file(\"x.txt\")\n}\n",
+			child:      child,
+			wantReason: "The configuration of the root module reads files",
+		},
+		"changed child module": {
+			root:       root,
+			child:      child + "\nlocals {\n  x = 1\n}\n",
+			wantReason: "The configuration of module.child has changed",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			oldRoot := root
+			if tc.oldRoot != "" {
+				oldRoot = tc.oldRoot
+			}
+			changed, reason := changedResources(snapshot(oldRoot, child), snapshot(tc.root, tc.child))
+			if tc.wantReason != "" {
+				if !strings.Contains(reason, tc.wantReason) {
+					t.Fatalf("wrong reason\n got: %s\nwant: %s", reason, tc.wantReason)
+				}
+				return
+			}
+			if reason != "" {
+				t.Fatalf("unexpected reason: %s", reason)
+			}
+			var got []string
+			for _, addr := range changed {
+				got = append(got, addr.String())
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("wrong changed resources\n%s", diff)
+			}
+		})
+	}
+}
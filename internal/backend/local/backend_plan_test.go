@@ -7,9 +7,11 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/opentofu/opentofu/internal/addrs"
@@ -542,6 +544,90 @@ func TestLocal_planRefreshFalse(t *testing.T) {
 	}
 }
 
+func TestLocal_planPrePlanHookFailed(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test hook is a shell command")
+	}
+
+	b := TestLocal(t)
+	p := TestLocalProvider(t, b, "test", planFixtureSchema())
+
+	op, configCleanup, done := testOperationPlan(t, "./testdata/plan-hooks")
+	defer configCleanup()
+
+	run, err := b.Operation(context.Background(), op)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	<-run.Done()
+	if run.Result == backend.OperationSuccess {
+		t.Fatal("plan operation succeeded; want failure")
+	}
+
+	if p.PlanResourceChangeCalled {
+		t.Fatal("PlanResourceChange should not be called")
+	}
+
+	if got, want := done(t).Stderr(), "The pre_plan hook failed: false: exit status 1."; !strings.Contains(got, want) {
+		t.Fatalf("missing hook error\ngot:\n%s\nwant: %s", got, want)
+	}
+}
+
+func TestLocal_planEnrichment(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test hook is a shell command")
+	}
+
+	b := TestLocal(t)
+	TestLocalProvider(t, b, "test", planFixtureSchema())
+
+	planPath := filepath.Join(t.TempDir(), "plan.tfplan")
+
+	op, configCleanup, done := testOperationPlan(t, "./testdata/plan-enrichment")
+	defer configCleanup()
+	op.PlanOutPath = planPath
+	cfg := cty.ObjectVal(map[string]cty.Value{
+		"path": cty.StringVal(b.StatePath),
+	})
+	cfgRaw, err := plans.NewDynamicValue(cfg, cfg.Type())
+	if err != nil {
+		t.Fatal(err)
+	}
+	op.PlanOutBackend = &plans.Backend{
+		// Just a placeholder so that we can generate a valid plan file.
+		Type:   "local",
+		Config: cfgRaw,
+	}
+
+	run, err := b.Operation(context.Background(), op)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	<-run.Done()
+	output := done(t)
+	if run.Result != backend.OperationSuccess {
+		t.Fatalf("plan operation failed:\n%s", output.Stderr())
+	}
+
+	if got, want := output.Stdout(), "Estimated monthly cost change: +12.50 USD\n  test_instance.foo  +12.50 USD\n"; !strings.Contains(got, want) {
+		t.Errorf("missing cost estimate\ngot:\n%s\nwant: %s", got, want)
+	}
+
+	plan := testReadPlan(t, planPath)
+	want := &plans.CostEstimate{
+		Currency: "USD",
+		Resources: []plans.ResourceCostEstimate{
+			{
+				Addr:             addrs.Resource{Mode: addrs.ManagedResourceMode, Type: "test_instance", Name: "foo"}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+				MonthlyCostDelta: 12.5,
+			},
+		},
+	}
+	if diff := cmp.Diff(want, plan.CostEstimate); diff != "" {
+		t.Errorf("wrong cost estimate in the saved plan\n%s", diff)
+	}
+}
+
 func TestLocal_planDestroy(t *testing.T) {
 	b := TestLocal(t)
 
@@ -839,7 +925,7 @@ func testPlanState_tainted() *states.State {
 func testReadPlan(t *testing.T, path string) *plans.Plan {
 	t.Helper()
 
-	p, err := planfile.Open(path)
+	p, err := planfile.Open(path, nil)
 	if err != nil {
 		t.Fatalf("err: %s", err)
 	}
@@ -0,0 +1,39 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"fmt"
+
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/command/jsonplan"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/policy"
+	"github.com/opentofu/opentofu/internal/states/statefile"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// checkPolicies evaluates the policies of the given operation against the
+// JSON representation of the given plan, returning error diagnostics if the
+// plan must not be saved or applied.
+func checkPolicies(op *backend.Operation, lr *backend.LocalRun, plan *plans.Plan, schemas *tofu.Schemas) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	if len(op.Policies) == 0 {
+		return diags
+	}
+
+	planJSON, err := jsonplan.Marshal(lr.Config, plan, &statefile.File{State: plan.PriorState}, schemas)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to evaluate policies",
+			fmt.Sprintf("The plan could not be prepared for policy evaluation: %s.", err),
+		))
+		return diags
+	}
+
+	_, moreDiags := policy.Evaluate(op.Policies, planJSON)
+	return diags.Append(moreDiags)
+}
@@ -0,0 +1,113 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/configs/configload"
+	"github.com/opentofu/opentofu/internal/depsfile"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// validationCache returns the validation cache for the given operation, or nil if the
+// operation doesn't use one.
+//
+// The cache is keyed by a fingerprint of the configuration source code and
+// of the locked versions of the providers it uses. We can't fingerprint the
+// providers that don't come from the dependency lock file, such as those
+// with development overrides, so we don't use the cache at all when the
+// configuration uses any of them.
+func validationCache(op *backend.Operation, config *configs.Config, snap *configload.Snapshot) *tofu.ValidationCache {
+	if op.ValidationCacheDir == "" || op.DependencyLocks == nil || snap == nil {
+		return nil
+	}
+	fingerprint, ok := validationCacheFingerprint(config, snap, op.DependencyLocks)
+	if !ok {
+		log.Printf("[TRACE] backend/local: not using the validation cache because some providers are not locked")
+		return nil
+	}
+	return tofu.NewValidationCache(op.ValidationCacheDir, fingerprint, configReadsFiles(snap))
+}
+
+// configReadsFiles returns true if any module in the given snapshot might
+// call a function that reads files, whose results the fingerprint doesn't
+// cover. Files that can't be parsed as native syntax, such as JSON files,
+// are assumed to read files if they mention any such function at all.
+func configReadsFiles(snap *configload.Snapshot) bool {
+	for _, mod := range snap.Modules {
+		for filename, src := range mod.Files {
+			if !strings.HasSuffix(filename, ".tf") && !strings.HasSuffix(filename, ".tofu") {
+				for name := range fileFunctions {
+					if bytes.Contains(src, []byte(name+"(")) {
+						return true
+					}
+				}
+				continue
+			}
+			file, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+			if diags.HasErrors() || callsFileFunction(file.Body.(*hclsyntax.Body)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func validationCacheFingerprint(config *configs.Config, snap *configload.Snapshot, locks *depsfile.Locks) (string, bool) {
+	h := sha256.New()
+
+	keys := make([]string, 0, len(snap.Modules))
+	for key := range snap.Modules {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		mod := snap.Modules[key]
+		fmt.Fprintf(h, "module=%q dir=%q source=%q\x00", key, mod.Dir, mod.SourceAddr)
+		if mod.Version != nil {
+			fmt.Fprintf(h, "version=%s\x00", mod.Version)
+		}
+
+		filenames := make([]string, 0, len(mod.Files))
+		for filename := range mod.Files {
+			filenames = append(filenames, filename)
+		}
+		sort.Strings(filenames)
+		for _, filename := range filenames {
+			src := mod.Files[filename]
+			fmt.Fprintf(h, "file=%q size=%d\x00", filename, len(src))
+			h.Write(src)
+		}
+	}
+
+	for _, addr := range config.ProviderTypes() {
+		if addr.IsBuiltIn() {
+			// Built-in providers are part of OpenTofu itself, so they are
+			// covered by the OpenTofu version in the cache keys.
+			continue
+		}
+		lock := locks.Provider(addr)
+		if lock == nil || locks.ProviderIsOverridden(addr) {
+			return "", false
+		}
+		fmt.Fprintf(h, "provider=%s version=%s\x00", addr, lock.Version())
+		for _, hash := range lock.AllHashes() {
+			fmt.Fprintf(h, "hash=%s\x00", hash)
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), true
+}
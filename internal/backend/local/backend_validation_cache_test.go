@@ -0,0 +1,125 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package local
+
+import (
+	"testing"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs/configload"
+	"github.com/opentofu/opentofu/internal/depsfile"
+	"github.com/opentofu/opentofu/internal/getproviders"
+)
+
+func TestValidationCacheFingerprint(t *testing.T) {
+	provider := addrs.NewDefaultProvider("test")
+
+	fingerprint := func(src string, locks *depsfile.Locks) (string, bool) {
+		t.Helper()
+		snap := &configload.Snapshot{
+			Modules: map[string]*configload.SnapshotModule{
+				"": {
+					Dir:   ".",
+					Files: map[string][]byte{"main.tf": []byte(src)},
+				},
+			},
+		}
+		config, diags := configload.NewLoaderFromSnapshot(snap).LoadConfig(".")
+		if diags.HasErrors() {
+			t.Fatal(diags.Error())
+		}
+		return validationCacheFingerprint(config, snap, locks)
+	}
+
+	locks := depsfile.NewLocks()
+	locks.SetProvider(provider, getproviders.MustParseVersion("1.0.0"), nil, nil)
+
+	const src = `
+resource "test_instance" "foo" {
+}
+`
+	fp1, ok := fingerprint(src, locks)
+	if !ok {
+		t.Fatal("no fingerprint for locked provider")
+	}
+	if fp2, _ := fingerprint(src, locks); fp1 != fp2 {
+		t.Errorf("fingerprint is not stable")
+	}
+	if fp2, _ := fingerprint(src+"\n", locks); fp1 == fp2 {
+		t.Errorf("fingerprint doesn't depend on the configuration")
+	}
+
+	upgraded := depsfile.NewLocks()
+	upgraded.SetProvider(provider, getproviders.MustParseVersion("1.1.0"), nil, nil)
+	if fp2, _ := fingerprint(src, upgraded); fp1 == fp2 {
+		t.Errorf("fingerprint doesn't depend on the provider versions")
+	}
+
+	if _, ok := fingerprint(src, depsfile.NewLocks()); ok {
+		t.Errorf("fingerprint for unlocked provider")
+	}
+	locks.SetProviderOverridden(provider)
+	if _, ok := fingerprint(src, locks); ok {
+		t.Errorf("fingerprint for overridden provider")
+	}
+}
+
+func TestConfigReadsFiles(t *testing.T) {
+	tests := map[string]struct {
+		filename string
+		src      string
+		want     bool
+	}{
+		"no functions": {
+			"main.tf",
+			`resource "test_instance" "foo" {
+  value = upper("foo")
+}
+`,
+			false,
+		},
+		"file": {
+			"main.tf",
+			`resource "test_instance" "foo" {
+  value = file("${path.module}/foo.txt")
+}
+`,
+			true,
+		},
+		"namespaced templatefile": {
+			"main.tofu",
+			`locals {
+  foo = core::templatefile("foo.tftpl", {})
+}
+`,
+			true,
+		},
+		"json": {
+			"main.tf.json",
+			`{"locals": {"foo": "${file(\"foo.txt\")}"}}`,
+			true,
+		},
+		"invalid": {
+			"main.tf",
+			`resource "test_instance" {`,
+			true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			snap := &configload.Snapshot{
+				Modules: map[string]*configload.SnapshotModule{
+					"": {
+						Dir:   ".",
+						Files: map[string][]byte{test.filename: []byte(test.src)},
+					},
+				},
+			}
+			if got := configReadsFiles(snap); got != test.want {
+				t.Errorf("wrong result %t; want %t", got, test.want)
+			}
+		})
+	}
+}
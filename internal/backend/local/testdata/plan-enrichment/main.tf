@@ -0,0 +1,17 @@
+terraform {
+  hooks {
+    plan_enrichment {
+      command = [
+        "sh", "-c",
+        <<-EOT
+          cat >/dev/null
+          echo '{"cost_estimate": {"currency": "USD", "resources": [{"address": "test_instance.foo", "monthly_cost_delta": 12.5}]}}'
+        EOT
+      ]
+    }
+  }
+}
+
+resource "test_instance" "foo" {
+  ami = "bar"
+}
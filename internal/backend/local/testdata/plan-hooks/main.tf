@@ -0,0 +1,11 @@
+terraform {
+  hooks {
+    pre_plan {
+      command = ["false"]
+    }
+  }
+}
+
+resource "test_instance" "foo" {
+  ami = "bar"
+}
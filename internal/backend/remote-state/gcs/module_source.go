@@ -0,0 +1,56 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package gcs
+
+import (
+	"context"
+	"io"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/getmodules"
+)
+
+var _ backend.ModuleSourceStorage = (*Backend)(nil)
+
+// ModuleSourceStorage returns the backend's storage client, so that "gcs::"
+// module sources can use the backend's credentials, impersonated service
+// account and custom endpoint.
+func (b *Backend) ModuleSourceStorage() (string, getmodules.ObjectStorage) {
+	if b.storageClient == nil {
+		return "gcs", nil
+	}
+	return "gcs", moduleSourceStorage{client: b.storageClient}
+}
+
+type moduleSourceStorage struct {
+	client *storage.Client
+}
+
+func (s moduleSourceStorage) ListObjects(ctx context.Context, bucket, prefix string) ([]string, error) {
+	var keys []string
+	objects := s.client.Bucket(bucket).Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := objects.Next()
+		if err == iterator.Done {
+			return keys, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, attrs.Name)
+	}
+}
+
+func (s moduleSourceStorage) GetObject(ctx context.Context, bucket, key string, w io.Writer) error {
+	r, err := s.client.Bucket(bucket).Object(key).NewReader(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	_, err = io.Copy(w, r)
+	return err
+}
@@ -0,0 +1,61 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package s3
+
+import (
+	"context"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/opentofu/opentofu/internal/backend"
+	"github.com/opentofu/opentofu/internal/getmodules"
+)
+
+var _ backend.ModuleSourceStorage = (*Backend)(nil)
+
+// ModuleSourceStorage returns the backend's S3 client, so that "s3::" module
+// sources can use the backend's credentials, assumed role and endpoint.
+func (b *Backend) ModuleSourceStorage() (string, getmodules.ObjectStorage) {
+	if b.s3Client == nil {
+		return "s3", nil
+	}
+	return "s3", moduleSourceStorage{client: b.s3Client}
+}
+
+type moduleSourceStorage struct {
+	client *s3.Client
+}
+
+func (s moduleSourceStorage) ListObjects(ctx context.Context, bucket, prefix string) ([]string, error) {
+	var keys []string
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range page.Contents {
+			keys = append(keys, aws.ToString(obj.Key))
+		}
+	}
+	return keys, nil
+}
+
+func (s moduleSourceStorage) GetObject(ctx context.Context, bucket, key string, w io.Writer) error {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return err
+	}
+	defer out.Body.Close()
+	_, err = io.Copy(w, out.Body)
+	return err
+}
@@ -54,6 +54,42 @@ func (b *Remote) opApply(stopCtx, cancelCtx context.Context, op *backend.Operati
 		))
 	}
 
+	if !op.Stage.IsRoot() {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Planning stages is currently not supported",
+			`The "remote" backend does not support planning a single stage of the `+
+				`configuration at this time.`,
+		))
+	}
+
+	if len(op.Excludes) != 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Resource exclusion is currently not supported",
+			`The "remote" backend does not support excluding resources `+
+				`at this time.`,
+		))
+	}
+
+	if len(op.Policies) != 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Policies are not supported with remote execution",
+			`The "remote" backend can't evaluate policies when it runs operations `+
+				`remotely. Evaluate them locally instead, by selecting local execution.`,
+		))
+	}
+
+	if !op.DriftSelection.Empty() {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Selecting drift is currently not supported",
+			`The "remote" backend does not support accepting or rejecting individual `+
+				`changes made outside of OpenTofu at this time.`,
+		))
+	}
+
 	if op.PlanFile != nil {
 		diags = diags.Append(tfdiags.Sourceless(
 			tfdiags.Error,
@@ -67,6 +67,14 @@ func (p *Provider) ConfigureProvider(providers.ConfigureProviderRequest) provide
 }
 
 // ReadDataSource returns the data source's current state.
+// ListResources is not supported by this provider, which doesn't declare
+// the ListResources server capability.
+func (p *Provider) ListResources(req providers.ListResourcesRequest) providers.ListResourcesResponse {
+	var resp providers.ListResourcesResponse
+	resp.Diagnostics = resp.Diagnostics.Append(fmt.Errorf("unsupported resource type %q for listing", req.TypeName))
+	return resp
+}
+
 func (p *Provider) ReadDataSource(req providers.ReadDataSourceRequest) providers.ReadDataSourceResponse {
 	// call function
 	var res providers.ReadDataSourceResponse
@@ -135,6 +143,14 @@ func (p *Provider) ValidateResourceConfig(req providers.ValidateResourceConfigRe
 	return validateDataStoreResourceConfig(req)
 }
 
+// CallFunction is used to call a provider function. The tofu provider does
+// not offer any functions.
+func (p *Provider) CallFunction(req providers.CallFunctionRequest) providers.CallFunctionResponse {
+	return providers.CallFunctionResponse{
+		Error: fmt.Errorf("unknown function %q", req.Name),
+	}
+}
+
 // Close is a noop for this provider, since it's run in-process.
 func (p *Provider) Close() error {
 	return nil
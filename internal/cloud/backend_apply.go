@@ -56,6 +56,42 @@ func (b *Cloud) opApply(stopCtx, cancelCtx context.Context, op *backend.Operatio
 		))
 	}
 
+	if !op.Stage.IsRoot() {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Planning stages is currently not supported",
+			`Cloud backend does not support planning a single stage of the `+
+				`configuration at this time.`,
+		))
+	}
+
+	if len(op.Excludes) != 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Resource exclusion is currently not supported",
+			`Cloud backend does not support excluding resources `+
+				`at this time.`,
+		))
+	}
+
+	if len(op.Policies) != 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Policies are not supported with remote execution",
+			`Cloud backend can't evaluate policies when it runs operations `+
+				`remotely. Evaluate them locally instead, by selecting local execution.`,
+		))
+	}
+
+	if !op.DriftSelection.Empty() {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Selecting drift is currently not supported",
+			`Cloud backend does not support accepting or rejecting individual `+
+				`changes made outside of OpenTofu at this time.`,
+		))
+	}
+
 	if op.PlanFile.IsLocal() {
 		diags = diags.Append(tfdiags.Sourceless(
 			tfdiags.Error,
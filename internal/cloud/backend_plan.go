@@ -56,6 +56,69 @@ func (b *Cloud) opPlan(stopCtx, cancelCtx context.Context, op *backend.Operation
 		))
 	}
 
+	if !op.Stage.IsRoot() {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Planning stages is currently not supported",
+			`Cloud backend does not support planning a single stage of the `+
+				`configuration at this time.`,
+		))
+	}
+
+	if len(op.Excludes) != 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Resource exclusion is currently not supported",
+			`Cloud backend does not support excluding resources `+
+				`at this time.`,
+		))
+	}
+
+	if len(op.Policies) != 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Policies are not supported with remote execution",
+			`Cloud backend can't evaluate policies when it runs operations `+
+				`remotely. Evaluate them locally instead, by selecting local execution.`,
+		))
+	}
+
+	if !op.DriftSelection.Empty() {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Selecting drift is currently not supported",
+			`Cloud backend does not support accepting or rejecting individual `+
+				`changes made outside of OpenTofu at this time.`,
+		))
+	}
+
+	if op.GenerateImports {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Generating import blocks is currently not supported",
+			`Cloud backend does not support generating import blocks for `+
+				`existing objects at this time.`,
+		))
+	}
+
+	if op.BlastRadius {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Blast radius reports are currently not supported",
+			`Cloud backend does not support reporting the blast radius of `+
+				`destroy plans at this time.`,
+		))
+	}
+
+	if op.RefinePlanFile != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Refining a saved plan is currently not supported",
+			`Cloud backend does not support refining a saved plan `+
+				`at this time.`,
+		))
+	}
+
 	if op.PlanFile != nil {
 		diags = diags.Append(tfdiags.Sourceless(
 			tfdiags.Error,
@@ -29,6 +29,7 @@ import (
 	"github.com/opentofu/opentofu/internal/initwd"
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/plans/planfile"
+	"github.com/opentofu/opentofu/internal/policy"
 	"github.com/opentofu/opentofu/internal/states/statemgr"
 	"github.com/opentofu/opentofu/internal/terminal"
 	"github.com/opentofu/opentofu/internal/tofu"
@@ -331,6 +332,33 @@ func TestCloud_planWithParallelism(t *testing.T) {
 	}
 }
 
+func TestCloud_planWithPolicies(t *testing.T) {
+	b, bCleanup := testBackendWithName(t)
+	defer bCleanup()
+
+	op, configCleanup, done := testOperationPlan(t, "./testdata/plan")
+	defer configCleanup()
+
+	op.Policies = []*policy.Policy{{Name: "test", Level: policy.LevelDeny}}
+	op.Workspace = testBackendSingleWorkspaceName
+
+	run, err := b.Operation(context.Background(), op)
+	if err != nil {
+		t.Fatalf("error starting operation: %v", err)
+	}
+
+	<-run.Done()
+	output := done(t)
+	if run.Result == backend.OperationSuccess {
+		t.Fatal("expected plan operation to fail")
+	}
+
+	errOutput := output.Stderr()
+	if !strings.Contains(errOutput, "Policies are not supported with remote execution") {
+		t.Fatalf("expected a policies error, got: %v", errOutput)
+	}
+}
+
 func TestCloud_planWithPlan(t *testing.T) {
 	b, bCleanup := testBackendWithName(t)
 	defer bCleanup()
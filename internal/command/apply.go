@@ -5,13 +5,23 @@ package command
 
 import (
 	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/opentofu/opentofu/internal/backend"
+	backendLocal "github.com/opentofu/opentofu/internal/backend/local"
 	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/command/timings"
 	"github.com/opentofu/opentofu/internal/command/views"
 	"github.com/opentofu/opentofu/internal/plans/planfile"
+	"github.com/opentofu/opentofu/internal/simulate"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/statemgr"
 	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
 )
 
 // ApplyCommand is a Command implementation that applies a Terraform
@@ -64,15 +74,36 @@ func (c *ApplyCommand) Run(rawArgs []string) int {
 		return 1
 	}
 
+	// If we're resuming a failed apply then the plan file is the one
+	// recorded by that apply.
+	planPath := args.PlanPath
+	var resumeFrom *planfile.ResumeManifest
+	switch {
+	case args.Resume:
+		resumeFrom, diags = c.loadResumeManifest()
+	case args.ResumeBundle != "":
+		resumeFrom, diags = c.loadResumeBundle(args.ResumeBundle)
+	}
+	if diags.HasErrors() {
+		view.Diagnostics(diags)
+		return 1
+	}
+	if resumeFrom != nil {
+		planPath = resumeFrom.PlanPath
+	}
+
 	// Attempt to load the plan file, if specified
-	planFile, diags := c.LoadPlanFile(args.PlanPath)
+	planFile, diags := c.LoadPlanFile(planPath)
 	if diags.HasErrors() {
 		view.Diagnostics(diags)
 		return 1
 	}
 
-	// Check for invalid combination of plan file and variable overrides
-	if planFile != nil && !args.Vars.Empty() {
+	// Check for invalid combination of plan file and variable overrides.
+	// Local plan files can still accept values for ephemeral variables,
+	// which the local backend checks once it has loaded the configuration
+	// from the plan.
+	if planFile != nil && planFile.IsCloud() && !args.Vars.Empty() {
 		diags = diags.Append(tfdiags.Sourceless(
 			tfdiags.Error,
 			"Can't set variables when applying a saved plan",
@@ -82,6 +113,18 @@ func (c *ApplyCommand) Run(rawArgs []string) int {
 		return 1
 	}
 
+	// In CI mode there's nobody to answer the approval prompt, so the
+	// approval must be given up front instead.
+	if c.CIMode && planFile == nil && !args.AutoApprove {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Approval required in CI mode",
+			"OpenTofu is running in CI mode, so it cannot prompt for approval before making changes. Apply a saved plan file created by \"tofu plan -out=FILE\", or use the -auto-approve option.",
+		))
+		view.Diagnostics(diags)
+		return 1
+	}
+
 	// FIXME: the -input flag value is needed to initialize the backend and the
 	// operation, but there is no clear path to pass this value down, so we
 	// continue to mutate the Meta object state for now.
@@ -94,6 +137,34 @@ func (c *ApplyCommand) Run(rawArgs []string) int {
 	// clear path to pass this value down, so we continue to mutate the Meta
 	// object state for now.
 	c.Meta.parallelism = args.Operation.Parallelism
+	c.Meta.concurrencyLimits = tofu.ConcurrencyLimits{
+		Providers:     args.Operation.ProviderConcurrency,
+		ResourceTypes: args.Operation.ResourceConcurrency,
+		ProviderRates: args.Operation.ProviderRateLimits,
+	}
+	c.Meta.refreshParallelism = tofu.RefreshParallelism{
+		Default:   args.Operation.RefreshParallelism,
+		Providers: args.Operation.ProviderRefreshParallelism,
+	}
+
+	// A simulated apply replaces the providers and provisioners with
+	// simulated ones, which are also passed down through the Meta object.
+	if args.Simulate {
+		var responses *simulate.Responses
+		if args.SimulateResponses != "" {
+			responses, err = simulate.LoadResponses(args.SimulateResponses)
+			if err != nil {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Failed to load simulated responses",
+					fmt.Sprintf("Cannot read %s: %s.", args.SimulateResponses, err),
+				))
+				view.Diagnostics(diags)
+				return 1
+			}
+		}
+		c.Meta.simulation = simulate.New(responses)
+	}
 
 	// Prepare the backend, passing the plan file if present, and the
 	// backend-specific arguments
@@ -104,9 +175,46 @@ func (c *ApplyCommand) Run(rawArgs []string) int {
 		return 1
 	}
 
+	// Earlier applies in this working directory tell us how long the
+	// changes are likely to take, and this apply adds to that history.
+	history, err := timings.Load(c.DataDir())
+	if err != nil {
+		// The estimates are only a guide, so we'll just start afresh.
+		log.Printf("[WARN] Failed to load apply timings: %s", err)
+	}
+	view.Estimates(history, args.Operation.Parallelism)
+
+	// Interleaved messages from many concurrent changes are hard to follow
+	// in CI logs, so CI mode groups them by module unless asked otherwise.
+	progress := args.Progress
+	if progress == "" && c.CIMode {
+		progress = arguments.ApplyProgressGrouped
+	}
+	if progress == arguments.ApplyProgressGrouped {
+		view.GroupProgress(os.Getenv("GITHUB_ACTIONS") == "true")
+	}
+
 	// Build the operation request
 	opReq, opDiags := c.OperationRequest(be, view, args.ViewType, planFile, args.Operation, args.AutoApprove)
 	diags = diags.Append(opDiags)
+	if opReq != nil && !args.Simulate {
+		// Simulated changes take no time, so they'd spoil the estimates.
+		opReq.Hooks = append(opReq.Hooks, timings.NewRecorder(history))
+	}
+	if opReq != nil && planFile.IsLocal() {
+		opReq.Resume = c.applyResume(planPath, resumeFrom, args.BundleOut)
+	}
+	if opReq != nil {
+		var policyDiags tfdiags.Diagnostics
+		opReq.Policies, policyDiags = c.operationPolicies(args.PolicyPaths)
+		diags = diags.Append(policyDiags)
+	}
+	var sim *simulationOutput
+	if opReq != nil && args.Simulate {
+		var simDiags tfdiags.Diagnostics
+		sim, simDiags = c.prepareSimulation(be, opReq, args.SimulateOut)
+		diags = diags.Append(simDiags)
+	}
 
 	// Collect variable value and add them to the operation request
 	diags = diags.Append(c.GatherVariables(opReq, args.Vars))
@@ -128,7 +236,23 @@ func (c *ApplyCommand) Run(rawArgs []string) int {
 		return 1
 	}
 
+	// We record the durations even if the apply failed, since the changes
+	// that succeeded are still representative.
+	if err := history.Save(); err != nil {
+		log.Printf("[WARN] Failed to save apply timings: %s", err)
+	}
+
+	if sim != nil {
+		if err := sim.Trace.WriteFile(sim.TracePath); err != nil {
+			diags = diags.Append(fmt.Errorf("Failed to write the simulated operation trace: %w", err))
+		}
+	}
+
 	if op.Result != backend.OperationSuccess {
+		if sim != nil {
+			view.Diagnostics(diags)
+			view.Simulation(sim.StatePath, sim.TracePath)
+		}
 		return op.Result.ExitStatus()
 	}
 
@@ -138,8 +262,12 @@ func (c *ApplyCommand) Run(rawArgs []string) int {
 		view.ResourceCount(args.State.StateOutPath)
 		if !c.Destroy && op.State != nil {
 			view.Outputs(op.State.RootModule().OutputValues)
+			view.CheckResults(op.State.CheckResults)
 		}
 	}
+	if sim != nil {
+		view.Simulation(sim.StatePath, sim.TracePath)
+	}
 
 	view.Diagnostics(diags)
 
@@ -150,6 +278,83 @@ func (c *ApplyCommand) Run(rawArgs []string) int {
 	return 0
 }
 
+// simulationOutput describes where a simulated apply writes its results.
+type simulationOutput struct {
+	StatePath string
+	TracePath string
+	Trace     *simulate.Trace
+}
+
+// prepareSimulation changes the given apply operation so that it writes the
+// resulting state and a trace of its operations to the given directory,
+// instead of changing the state of the current workspace. The simulation
+// starts from a copy of the workspace's current state.
+func (c *ApplyCommand) prepareSimulation(be backend.Enhanced, opReq *backend.Operation, dir string) (*simulationOutput, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	// Remote operations would use the real providers on the remote system,
+	// so we can only simulate when running locally.
+	if _, ok := be.(*backendLocal.Local); !ok {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Simulation not supported",
+			"The -simulate option is supported only when OpenTofu runs operations locally.",
+		))
+		return nil, diags
+	}
+
+	if dir == "" {
+		dir = filepath.Join(c.DataDir(), "simulation")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		diags = diags.Append(fmt.Errorf("Failed to create the simulation output directory: %w", err))
+		return nil, diags
+	}
+
+	workspace, err := c.Workspace()
+	if err != nil {
+		diags = diags.Append(fmt.Errorf("Failed to select workspace: %w", err))
+		return nil, diags
+	}
+	realMgr, err := be.StateMgr(workspace)
+	if err != nil {
+		diags = diags.Append(fmt.Errorf("Failed to load state: %w", err))
+		return nil, diags
+	}
+	if err := realMgr.RefreshState(); err != nil {
+		diags = diags.Append(fmt.Errorf("Failed to load state: %w", err))
+		return nil, diags
+	}
+	state := realMgr.State()
+	if state == nil {
+		state = states.NewState()
+	}
+
+	ret := &simulationOutput{
+		StatePath: filepath.Join(dir, "terraform.tfstate"),
+		TracePath: filepath.Join(dir, "trace.json"),
+		Trace:     simulate.NewTrace(),
+	}
+	simMgr := statemgr.NewFilesystem(ret.StatePath)
+	if err := simMgr.WriteState(state); err != nil {
+		diags = diags.Append(fmt.Errorf("Failed to write the simulated state: %w", err))
+		return nil, diags
+	}
+	if err := simMgr.PersistState(nil); err != nil {
+		diags = diags.Append(fmt.Errorf("Failed to write the simulated state: %w", err))
+		return nil, diags
+	}
+
+	opReq.StateSource = simMgr
+	opReq.Hooks = append(opReq.Hooks, ret.Trace)
+	opReq.StageManifestPath = filepath.Join(dir, stageManifestFilename)
+
+	// Nothing real will change, so there's nothing to approve.
+	opReq.AutoApprove = true
+
+	return ret, diags
+}
+
 func (c *ApplyCommand) LoadPlanFile(path string) (*planfile.WrappedPlanFile, tfdiags.Diagnostics) {
 	var planFile *planfile.WrappedPlanFile
 	var diags tfdiags.Diagnostics
@@ -194,6 +399,138 @@ func (c *ApplyCommand) LoadPlanFile(path string) (*planfile.WrappedPlanFile, tfd
 	return planFile, diags
 }
 
+// applyResumeManifestFilename is the name of the file in the data directory
+// which records the progress of an apply of a saved plan that failed partway.
+const applyResumeManifestFilename = "apply-resume.json"
+
+// resumeBundleDir is the name of the directory in the data directory where
+// the saved plan file from a resume bundle is extracted.
+const resumeBundleDir = "resume-bundle"
+
+// stageManifestFilename is the name of the file in the data directory which
+// records the stages that have been applied in each workspace.
+const stageManifestFilename = "stages.json"
+
+// loadResumeManifest loads the resume manifest written by an earlier apply
+// which failed partway, and checks that its saved plan file is unchanged.
+func (c *ApplyCommand) loadResumeManifest() (*planfile.ResumeManifest, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	manifestPath := filepath.Join(c.DataDir(), applyResumeManifestFilename)
+	manifest, err := planfile.LoadResumeManifest(manifestPath)
+	if os.IsNotExist(err) {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"No apply to resume",
+			"There is no record of an apply of a saved plan that failed partway in this working directory, so there is nothing to resume.",
+		))
+		return nil, diags
+	}
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read resume manifest",
+			fmt.Sprintf("Cannot read %s: %s.", manifestPath, err),
+		))
+		return nil, diags
+	}
+
+	hash, err := planfile.Hash(manifest.PlanPath)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read saved plan",
+			fmt.Sprintf("Cannot read the saved plan file %q that was being applied: %s.", manifest.PlanPath, err),
+		))
+		return nil, diags
+	}
+	if hash != manifest.PlanHash {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Saved plan has changed",
+			fmt.Sprintf("The saved plan file %q has been modified since it was partially applied, so the apply cannot be resumed. Create a new plan instead.", manifest.PlanPath),
+		))
+		return nil, diags
+	}
+
+	return manifest, diags
+}
+
+// loadResumeBundle extracts the resume bundle at the given path into the
+// data directory, and returns its resume manifest, which refers to the
+// extracted saved plan file.
+//
+// The dependency lock file from the bundle is written to the working
+// directory if there isn't one yet, so that the providers can be installed
+// at the versions the plan was created with. If there is one already, it
+// must select the same provider versions as the bundle.
+func (c *ApplyCommand) loadResumeBundle(bundlePath string) (*planfile.ResumeManifest, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	manifest, bundleLocks, err := planfile.ExtractResumeBundle(bundlePath, filepath.Join(c.DataDir(), resumeBundleDir))
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to read resume bundle",
+			fmt.Sprintf("Cannot read the resume bundle %s: %s.", bundlePath, err),
+		))
+		return nil, diags
+	}
+
+	if _, err := os.Stat(dependencyLockFilename); os.IsNotExist(err) {
+		diags = diags.Append(c.replaceLockedDependencies(bundleLocks))
+		return manifest, diags
+	}
+
+	locks, moreDiags := c.lockedDependencies()
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+	var mismatched []string
+	for addr, bundleLock := range bundleLocks.AllProviders() {
+		lock := locks.Provider(addr)
+		if lock == nil || lock.Version() != bundleLock.Version() {
+			mismatched = append(mismatched, fmt.Sprintf("%s v%s", addr.ForDisplay(), bundleLock.Version()))
+		}
+	}
+	if len(mismatched) > 0 {
+		sort.Strings(mismatched)
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Inconsistent dependency lock file",
+			fmt.Sprintf(
+				"The saved plan in the resume bundle was created with provider versions that the dependency lock file in this working directory doesn't select:\n  - %s\n\nThe lock file must select the same provider versions to resume the apply. Remove %s to restore the lock file from the bundle, and then run \"tofu init\" to install the providers.",
+				strings.Join(mismatched, "\n  - "), dependencyLockFilename,
+			),
+		))
+		return nil, diags
+	}
+
+	return manifest, diags
+}
+
+// applyResume returns the settings for recording the progress of applying
+// the saved plan file at the given path, so that the apply can be resumed
+// if it fails partway. If bundlePath is set, a resume bundle is also
+// written there if the apply fails partway.
+func (c *ApplyCommand) applyResume(planPath string, previous *planfile.ResumeManifest, bundlePath string) *backend.ApplyResume {
+	hash, err := planfile.Hash(planPath)
+	if err != nil {
+		// We already read the plan file successfully, so this is unlikely.
+		// The apply can still go ahead; it just won't be resumable.
+		log.Printf("[WARN] Failed to hash plan file %s, so apply won't be resumable: %s", planPath, err)
+		return nil
+	}
+	return &backend.ApplyResume{
+		ManifestPath: filepath.Join(c.DataDir(), applyResumeManifestFilename),
+		PlanPath:     planPath,
+		PlanHash:     hash,
+		Previous:     previous,
+		BundlePath:   bundlePath,
+	}
+}
+
 func (c *ApplyCommand) PrepareBackend(planFile *planfile.WrappedPlanFile, args *arguments.State, viewType arguments.ViewType) (backend.Enhanced, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 
@@ -271,7 +608,12 @@ func (c *ApplyCommand) OperationRequest(
 	opReq.PlanFile = planFile
 	opReq.PlanRefresh = args.Refresh
 	opReq.Targets = args.Targets
+	opReq.Excludes = args.Excludes
+	opReq.DriftSelection = args.DriftSelection
 	opReq.ForceReplace = args.ForceReplace
+	opReq.AllowDeferral = args.AllowDeferral
+	opReq.Stage = args.Stage
+	opReq.StageManifestPath = filepath.Join(c.DataDir(), stageManifestFilename)
 	opReq.Type = backend.OperationTypeApply
 	opReq.View = view.Operation()
 
@@ -348,6 +690,10 @@ Options:
                          accompanied by errors, show them in a more compact
                          form that includes only the summary messages.
 
+  -explain               Include detailed steps to resolve recognized errors,
+                         such as expired credentials, in the hints shown after
+                         them.
+
   -destroy               Destroy OpenTofu-managed infrastructure.
                          The command "tofu destroy" is a convenience alias
                          for this option.
@@ -365,6 +711,77 @@ Options:
   -parallelism=n         Limit the number of parallel resource operations.
                          Defaults to 10.
 
+  -resume                Continue applying the saved plan file from an
+                         earlier apply which failed partway, applying only
+                         the changes which were not yet applied.
+
+  -resume-bundle=path    Continue applying the saved plan file in a resume
+                         bundle written by -bundle-out, which may have come
+                         from another working directory or machine, in the
+                         same way as -resume.
+
+  -bundle-out=path       If the apply of a saved plan fails partway or is
+                         interrupted, also write a resume bundle to the
+                         given path, containing the saved plan, the progress
+                         of the apply, and the dependency lock file.
+
+  -policy=path           Evaluate the policies in the given policy file, or
+                         in the .tfpolicy.hcl files in the given directory,
+                         before applying. This flag can be used
+                         multiple times.
+
+  -progress=grouped      Report the progress of the changes grouped by
+                         module, with a periodic count of the changes in
+                         progress, instead of a line for each change as it
+                         happens. This is the default in CI mode. Use
+                         -progress=lines to report each change instead.
+
+  -provider-concurrency=SOURCE=n
+                         Limit the number of parallel operations for
+                         resources belonging to the given provider, such as
+                         "hashicorp/aws=4". This flag can be used multiple
+                         times.
+
+  -provider-rate-limit=SOURCE=n
+                         Limit the number of operations per second that begin
+                         for resources belonging to the given provider, such as
+                         "hashicorp/aws=10". This flag can be used multiple
+                         times.
+
+  -resource-concurrency=TYPE=n
+                         Limit the number of parallel operations for
+                         resources of the given type, such as
+                         "aws_instance=2". This flag can be used multiple
+                         times.
+
+  -refresh-parallelism=[SOURCE=]n
+                         Limit the number of parallel reads of existing
+                         objects while refreshing, for each provider
+                         configuration or only those of the given provider.
+                         Defaults to 10, separately from -parallelism. This
+                         flag can be used multiple times.
+
+  -simulate              Rehearse the apply using simulated providers, which
+                         use the real providers' schemas but never call any
+                         remote APIs, and don't run provisioners. The
+                         resulting state and a trace of the operations are
+                         written to a separate directory, leaving the real
+                         state unchanged.
+
+  -simulate-out=path     Directory to write the results of -simulate to.
+                         Defaults to "simulation" in the data directory.
+
+  -simulate-responses=path
+                         JSON file of canned responses for -simulate, giving
+                         the values of computed attributes, or an error to
+                         fail with, for each resource type.
+
+  -stage=module.NAME     Apply only the changes for the resources in the
+                         given module call, after checking that the rest of
+                         the configuration is up-to-date. Applied stages are
+                         recorded in the working directory. This flag cannot
+                         be used with a saved plan or with -target.
+
   -state=path            Path to read and save state (unless state-out
                          is specified). Defaults to "terraform.tfstate".
 
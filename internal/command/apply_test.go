@@ -23,6 +23,7 @@ import (
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/plans/planfile"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/states/statemgr"
@@ -67,6 +68,103 @@ func TestApply(t *testing.T) {
 		t.Fatal("state should not be nil")
 	}
 }
+
+func TestApply_policyDeny(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("apply"), td)
+	defer testChdir(t, td)()
+
+	policy := `
+policy "no_instances" {
+  resource_types = ["test_instance"]
+  actions        = ["create"]
+  condition      = "false"
+  error_message  = "Instances must not be created here."
+}
+`
+	if err := os.WriteFile("main.tfpolicy.hcl", []byte(policy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := applyFixtureProvider()
+	view, done := testView(t)
+	c := &ApplyCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-auto-approve", "-policy=main.tfpolicy.hcl"})
+	output := done(t)
+	if code != 1 {
+		t.Fatalf("wrong exit code %d; want 1\n%s", code, output.All())
+	}
+	if got := output.Stderr(); !strings.Contains(got, "Policy violation") {
+		t.Fatalf("missing policy violation in output:\n%s", got)
+	}
+	if p.ApplyResourceChangeCalled {
+		t.Fatal("changes were applied despite the policy violation")
+	}
+}
+
+func TestApply_simulate(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("apply"), td)
+	defer testChdir(t, td)()
+
+	responses := `{"resources": {"test_instance": {"values": {"id": "i-canned"}}}}`
+	if err := os.WriteFile("responses.json", []byte(responses), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := applyFixtureProvider()
+	view, done := testView(t)
+	c := &ApplyCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-simulate", "-simulate-responses=responses.json", "-simulate-out=sim"})
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("wrong exit code %d\n%s", code, output.All())
+	}
+	if !strings.Contains(output.Stdout(), "This was a simulation") {
+		t.Errorf("missing simulation note in output:\n%s", output.Stdout())
+	}
+	if p.ConfigureProviderCalled || p.PlanResourceChangeCalled || p.ApplyResourceChangeCalled {
+		t.Fatal("the real provider was used during the simulation")
+	}
+	if _, err := os.Stat(DefaultStateFilename); !os.IsNotExist(err) {
+		t.Fatalf("the workspace state was written during the simulation: %v", err)
+	}
+
+	state := testStateRead(t, filepath.Join("sim", "terraform.tfstate"))
+	is := state.ResourceInstance(addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "test_instance",
+		Name: "foo",
+	}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance))
+	if is == nil || is.Current == nil {
+		t.Fatalf("test_instance.foo is missing from the simulated state:\n%s", state)
+	}
+	if got, want := string(is.Current.AttrsJSON), `"id": "i-canned"`; !strings.Contains(got, want) {
+		t.Errorf("wrong simulated object\ngot:  %s\nwant: %s", got, want)
+	}
+
+	trace, err := os.ReadFile(filepath.Join("sim", "trace.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(trace), `"address": "test_instance.foo",
+      "action": "create"`; !strings.Contains(got, want) {
+		t.Errorf("wrong trace\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
 func TestApply_conditionalSensitive(t *testing.T) {
 	// Create a temporary working directory that is empty
 	td := t.TempDir()
@@ -170,6 +268,64 @@ func TestApply_approveNo(t *testing.T) {
 	}
 }
 
+func TestApply_ciModeRequiresApproval(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("apply"), td)
+	defer testChdir(t, td)()
+
+	statePath := testTempFile(t)
+
+	p := applyFixtureProvider()
+	view, done := testView(t)
+	c := &ApplyCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+			CIMode:           true,
+		},
+	}
+
+	code := c.Run([]string{"-state", statePath})
+	output := done(t)
+	if code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, output.Stdout())
+	}
+	if got, want := output.Stderr(), "Approval required in CI mode"; !strings.Contains(got, want) {
+		t.Fatalf("expected error to include %q, but was:\n%s", want, got)
+	}
+	if p.ApplyResourceChangeCalled {
+		t.Fatal("apply should not be called")
+	}
+}
+
+func TestApply_ciModeGroupedProgress(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("apply"), td)
+	defer testChdir(t, td)()
+	t.Setenv("GITHUB_ACTIONS", "")
+
+	statePath := testTempFile(t)
+
+	p := applyFixtureProvider()
+	view, done := testView(t)
+	c := &ApplyCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+			CIMode:           true,
+		},
+	}
+
+	code := c.Run([]string{"-state", statePath, "-auto-approve"})
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, output.Stderr())
+	}
+	if got, want := output.Stdout(), "Root module: 1 change complete after"; !strings.Contains(got, want) {
+		t.Fatalf("expected output to include %q, but was:\n%s", want, got)
+	}
+}
+
 func TestApply_approveYes(t *testing.T) {
 	// Create a temporary working directory that is empty
 	td := t.TempDir()
@@ -549,6 +705,297 @@ func TestApply_error(t *testing.T) {
 	}
 }
 
+func TestApply_resume(t *testing.T) {
+	// Create a temporary working directory that is empty
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("apply-error"), td)
+	defer testChdir(t, td)()
+
+	var lock sync.Mutex
+	var applied []string
+	failBar := true
+	p := testProvider()
+	p.GetProviderSchemaResponse = &providers.GetProviderSchemaResponse{
+		ResourceTypes: map[string]providers.Schema{
+			"test_instance": {
+				Block: &configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"id":    {Type: cty.String, Optional: true, Computed: true},
+						"ami":   {Type: cty.String, Optional: true},
+						"error": {Type: cty.Bool, Optional: true},
+					},
+				},
+			},
+		},
+	}
+	p.PlanResourceChangeFn = func(req providers.PlanResourceChangeRequest) (resp providers.PlanResourceChangeResponse) {
+		s := req.ProposedNewState.AsValueMap()
+		s["id"] = cty.UnknownVal(cty.String)
+		resp.PlannedState = cty.ObjectVal(s)
+		return
+	}
+	p.ApplyResourceChangeFn = func(req providers.ApplyResourceChangeRequest) (resp providers.ApplyResourceChangeResponse) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		s := req.PlannedState.AsValueMap()
+		isBar := s["error"].True()
+		if isBar && failBar {
+			resp.Diagnostics = resp.Diagnostics.Append(fmt.Errorf("error"))
+			resp.NewState = cty.NullVal(req.PlannedState.Type())
+			return
+		}
+		if isBar {
+			applied = append(applied, "bar")
+		} else {
+			applied = append(applied, "foo")
+		}
+		s["id"] = cty.StringVal("foo")
+		resp.NewState = cty.ObjectVal(s)
+		return
+	}
+
+	planView, planDone := testView(t)
+	pc := &PlanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             planView,
+		},
+	}
+	if code := pc.Run([]string{"-out=tfplan"}); code != 0 {
+		t.Fatalf("plan failed: %d\n\n%s", code, planDone(t).Stderr())
+	}
+	planDone(t)
+
+	runApply := func(args ...string) (int, string) {
+		view, done := testView(t)
+		c := &ApplyCommand{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(p),
+				View:             view,
+			},
+		}
+		code := c.Run(args)
+		output := done(t)
+		return code, output.All()
+	}
+
+	// The first apply fails partway, recording its progress.
+	code, output := runApply("tfplan")
+	if code != 1 {
+		t.Fatalf("wrong exit code %d; want 1\n%s", code, output)
+	}
+	if !strings.Contains(output, "Apply can be resumed") {
+		t.Fatalf("missing resume hint in output:\n%s", output)
+	}
+	manifestPath := filepath.Join(DefaultDataDir, applyResumeManifestFilename)
+	manifest, err := planfile.LoadResumeManifest(manifestPath)
+	if err != nil {
+		t.Fatalf("failed to load resume manifest: %s", err)
+	}
+	if got, want := len(manifest.Completed), 1; got != want {
+		t.Fatalf("wrong number of completed changes %d; want %d", got, want)
+	}
+	if got, want := manifest.Pending[0].String(), "test_instance.bar"; len(manifest.Pending) != 1 || got != want {
+		t.Fatalf("wrong pending changes %s; want [%s]", manifest.Pending, want)
+	}
+
+	// Resuming applies only the remaining change.
+	failBar = false
+	applied = nil
+	code, output = runApply("-resume")
+	if code != 0 {
+		t.Fatalf("wrong exit code %d; want 0\n%s", code, output)
+	}
+	if diff := cmp.Diff([]string{"bar"}, applied); diff != "" {
+		t.Fatalf("wrong changes applied on resume\n%s", diff)
+	}
+	if _, err := os.Stat(manifestPath); !os.IsNotExist(err) {
+		t.Fatalf("resume manifest was not removed after successful apply")
+	}
+	state := testStateRead(t, DefaultStateFilename)
+	if got, want := len(state.RootModule().Resources), 2; got != want {
+		t.Fatalf("wrong number of resources in state %d; want %d", got, want)
+	}
+
+	// There's nothing left to resume.
+	code, output = runApply("-resume")
+	if code != 1 || !strings.Contains(output, "No apply to resume") {
+		t.Fatalf("wrong result %d for resuming again\n%s", code, output)
+	}
+}
+
+func TestApply_resumeBundle(t *testing.T) {
+	// Create a temporary working directory that is empty
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("apply-error"), td)
+	defer testChdir(t, td)()
+
+	var lock sync.Mutex
+	var applied []string
+	failBar := true
+	p := testProvider()
+	p.GetProviderSchemaResponse = &providers.GetProviderSchemaResponse{
+		ResourceTypes: map[string]providers.Schema{
+			"test_instance": {
+				Block: &configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"id":    {Type: cty.String, Optional: true, Computed: true},
+						"ami":   {Type: cty.String, Optional: true},
+						"error": {Type: cty.Bool, Optional: true},
+					},
+				},
+			},
+		},
+	}
+	p.PlanResourceChangeFn = func(req providers.PlanResourceChangeRequest) (resp providers.PlanResourceChangeResponse) {
+		s := req.ProposedNewState.AsValueMap()
+		s["id"] = cty.UnknownVal(cty.String)
+		resp.PlannedState = cty.ObjectVal(s)
+		return
+	}
+	p.ApplyResourceChangeFn = func(req providers.ApplyResourceChangeRequest) (resp providers.ApplyResourceChangeResponse) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		s := req.PlannedState.AsValueMap()
+		isBar := s["error"].True()
+		if isBar && failBar {
+			resp.Diagnostics = resp.Diagnostics.Append(fmt.Errorf("error"))
+			resp.NewState = cty.NullVal(req.PlannedState.Type())
+			return
+		}
+		if isBar {
+			applied = append(applied, "bar")
+		} else {
+			applied = append(applied, "foo")
+		}
+		s["id"] = cty.StringVal("foo")
+		resp.NewState = cty.ObjectVal(s)
+		return
+	}
+
+	planView, planDone := testView(t)
+	pc := &PlanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             planView,
+		},
+	}
+	if code := pc.Run([]string{"-out=tfplan"}); code != 0 {
+		t.Fatalf("plan failed: %d\n\n%s", code, planDone(t).Stderr())
+	}
+	planDone(t)
+
+	runApply := func(args ...string) (int, string) {
+		view, done := testView(t)
+		c := &ApplyCommand{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(p),
+				View:             view,
+			},
+		}
+		code := c.Run(args)
+		output := done(t)
+		return code, output.All()
+	}
+
+	// The first apply fails partway, writing a resume bundle.
+	bundlePath := filepath.Join(t.TempDir(), "resume.zip")
+	code, output := runApply("-bundle-out="+bundlePath, "tfplan")
+	if code != 1 {
+		t.Fatalf("wrong exit code %d; want 1\n%s", code, output)
+	}
+	if !strings.Contains(output, "-resume-bundle="+bundlePath) {
+		t.Fatalf("missing resume bundle hint in output:\n%s", output)
+	}
+
+	// The apply is resumed in a different working directory, which shares
+	// the same state.
+	other := t.TempDir()
+	testCopyDir(t, testFixturePath("apply-error"), other)
+	stateSrc, err := os.ReadFile(DefaultStateFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(other, DefaultStateFilename), stateSrc, 0600); err != nil {
+		t.Fatal(err)
+	}
+	defer testChdir(t, other)()
+
+	failBar = false
+	applied = nil
+	code, output = runApply("-resume-bundle=" + bundlePath)
+	if code != 0 {
+		t.Fatalf("wrong exit code %d; want 0\n%s", code, output)
+	}
+	if diff := cmp.Diff([]string{"bar"}, applied); diff != "" {
+		t.Fatalf("wrong changes applied on resume\n%s", diff)
+	}
+	state := testStateRead(t, DefaultStateFilename)
+	if got, want := len(state.RootModule().Resources), 2; got != want {
+		t.Fatalf("wrong number of resources in state %d; want %d", got, want)
+	}
+	if _, err := os.Stat(dependencyLockFilename); err != nil {
+		t.Fatalf("dependency lock file was not restored from the bundle: %s", err)
+	}
+
+	// The bundle records the state snapshot written by the failed apply, so
+	// it can't be used again once the state has changed.
+	code, output = runApply("-resume-bundle=" + bundlePath)
+	if code != 1 || !strings.Contains(output, "Cannot resume apply") {
+		t.Fatalf("wrong result %d for resuming again\n%s", code, output)
+	}
+}
+
+func TestApply_stage(t *testing.T) {
+	// Create a temporary working directory that is empty
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("apply-stage"), td)
+	defer testChdir(t, td)()
+
+	p := applyFixtureProvider()
+	view, done := testView(t)
+	c := &ApplyCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-auto-approve", "-stage=module.app"})
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("wrong exit code %d; want 0\n%s", code, output.All())
+	}
+
+	state := testStateRead(t, DefaultStateFilename)
+	if mod := state.Module(addrs.RootModuleInstance.Child("app", addrs.NoKey)); mod == nil || mod.Resources["test_instance.foo"] == nil {
+		t.Fatalf("module.app.test_instance.foo was not created")
+	}
+	if len(state.RootModule().Resources) != 0 {
+		t.Fatalf("test_instance.base was created outside of the stage")
+	}
+
+	manifest, err := planfile.LoadStageManifest(filepath.Join(DefaultDataDir, stageManifestFilename))
+	if err != nil {
+		t.Fatalf("failed to load stage manifest: %s", err)
+	}
+	if got, want := len(manifest.Stages), 1; got != want {
+		t.Fatalf("wrong number of stages %d; want %d", got, want)
+	}
+	record := manifest.Stages[0]
+	if got, want := record.Stage.String(), "module.app"; got != want {
+		t.Errorf("wrong stage %s; want %s", got, want)
+	}
+	if got, want := record.Workspace, "default"; got != want {
+		t.Errorf("wrong workspace %q; want %q", got, want)
+	}
+	if record.Serial == 0 || record.Lineage == "" {
+		t.Errorf("missing state snapshot in record: %#v", record)
+	}
+}
+
 func TestApply_input(t *testing.T) {
 	// Create a temporary working directory that is empty
 	td := t.TempDir()
@@ -683,6 +1130,8 @@ func TestApply_noArgs(t *testing.T) {
 }
 
 func TestApply_plan(t *testing.T) {
+	defer testChdir(t, t.TempDir())()
+
 	// Disable test mode so input would be asked
 	test = false
 	defer func() { test = true }()
@@ -724,6 +1173,8 @@ func TestApply_plan(t *testing.T) {
 }
 
 func TestApply_plan_backup(t *testing.T) {
+	defer testChdir(t, t.TempDir())()
+
 	statePath := testTempFile(t)
 	backupPath := testTempFile(t)
 
@@ -764,6 +1215,8 @@ func TestApply_plan_backup(t *testing.T) {
 }
 
 func TestApply_plan_noBackup(t *testing.T) {
+	defer testChdir(t, t.TempDir())()
+
 	planPath := applyFixturePlanFile(t)
 	statePath := testTempFile(t)
 
@@ -928,6 +1381,8 @@ func TestApply_planWithVarFile(t *testing.T) {
 }
 
 func TestApply_planVars(t *testing.T) {
+	defer testChdir(t, t.TempDir())()
+
 	planPath := applyFixturePlanFile(t)
 	statePath := testTempFile(t)
 
@@ -27,10 +27,69 @@ type Apply struct {
 	// PlanPath contains an optional path to a stored plan file
 	PlanPath string
 
+	// Resume continues an apply of a saved plan file which failed partway,
+	// applying only the changes which were not yet applied.
+	Resume bool
+
+	// ResumeBundle is the path of a resume bundle written by an apply which
+	// failed partway, possibly in another working directory. The apply of
+	// the saved plan file in the bundle continues the same way as for
+	// Resume.
+	ResumeBundle string
+
+	// BundleOut is a path to write a resume bundle to if the apply of a
+	// saved plan fails partway, so that it can be resumed elsewhere.
+	BundleOut string
+
+	// PolicyPaths are policy files or directories containing
+	// policy files, whose policies are evaluated against the plan before
+	// it is applied.
+	PolicyPaths []string
+
+	// Progress selects how the progress of the changes is reported in the
+	// human-readable output, or is empty to use the default for the
+	// environment OpenTofu is running in.
+	Progress ApplyProgress
+
+	// Simulate applies the changes using simulated providers, which use the
+	// schemas of the real providers but don't change any real
+	// infrastructure. The resulting state and a trace of the operations are
+	// written to SimulateOut instead of changing the workspace's state.
+	Simulate bool
+
+	// SimulateResponses is an optional path to a JSON file of canned
+	// responses for the simulated providers.
+	SimulateResponses string
+
+	// SimulateOut is the directory where a simulated apply writes its
+	// results, or empty to use the default.
+	SimulateOut string
+
 	// ViewType specifies which output format to use
 	ViewType ViewType
 }
 
+// appliesSavedPlan returns true if the arguments apply a saved plan file,
+// either given directly or recorded by an earlier apply being resumed.
+func (apply *Apply) appliesSavedPlan() bool {
+	return apply.PlanPath != "" || apply.Resume || apply.ResumeBundle != ""
+}
+
+// ApplyProgress is a way of reporting the progress of the changes during an
+// apply.
+type ApplyProgress string
+
+const (
+	// ApplyProgressLines reports the start and completion of each change as
+	// it happens, and periodically reports each change still in progress.
+	ApplyProgressLines ApplyProgress = "lines"
+
+	// ApplyProgressGrouped reports the changes in each module together once
+	// they are all complete, and periodically reports a count of the
+	// changes in progress.
+	ApplyProgressGrouped ApplyProgress = "grouped"
+)
+
 // ParseApply processes CLI arguments, returning an Apply value and errors.
 // If errors are encountered, an Apply value is still returned representing
 // the best effort interpretation of the arguments.
@@ -44,7 +103,15 @@ func ParseApply(args []string) (*Apply, tfdiags.Diagnostics) {
 
 	cmdFlags := extendedFlagSet("apply", apply.State, apply.Operation, apply.Vars)
 	cmdFlags.BoolVar(&apply.AutoApprove, "auto-approve", false, "auto-approve")
-	cmdFlags.BoolVar(&apply.InputEnabled, "input", true, "input")
+	cmdFlags.BoolVar(&apply.InputEnabled, "input", InputEnabledDefault(), "input")
+	cmdFlags.BoolVar(&apply.Resume, "resume", false, "resume")
+	cmdFlags.StringVar(&apply.ResumeBundle, "resume-bundle", "", "resume-bundle")
+	cmdFlags.StringVar(&apply.BundleOut, "bundle-out", "", "bundle-out")
+	cmdFlags.Var((*flagStringSlice)(&apply.PolicyPaths), "policy", "policy")
+	cmdFlags.StringVar((*string)(&apply.Progress), "progress", "", "progress")
+	cmdFlags.BoolVar(&apply.Simulate, "simulate", false, "simulate")
+	cmdFlags.StringVar(&apply.SimulateResponses, "simulate-responses", "", "simulate-responses")
+	cmdFlags.StringVar(&apply.SimulateOut, "simulate-out", "", "simulate-out")
 
 	var json bool
 	cmdFlags.BoolVar(&json, "json", false, "json")
@@ -71,6 +138,62 @@ func ParseApply(args []string) (*Apply, tfdiags.Diagnostics) {
 		))
 	}
 
+	if apply.Resume && apply.PlanPath != "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible apply options",
+			"The -resume option continues applying the saved plan file recorded by the failed apply, so it cannot be used with a plan file argument.",
+		))
+	}
+
+	switch {
+	case apply.ResumeBundle != "" && apply.PlanPath != "":
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible apply options",
+			"The -resume-bundle option continues applying the saved plan file in the bundle, so it cannot be used with a plan file argument.",
+		))
+	case apply.ResumeBundle != "" && apply.Resume:
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible apply options",
+			"The -resume and -resume-bundle options cannot be used together.",
+		))
+	}
+
+	if apply.BundleOut != "" && !apply.appliesSavedPlan() {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible apply options",
+			"Only the apply of a saved plan can be resumed, so the -bundle-out option can only be used with a plan file argument, -resume, or -resume-bundle.",
+		))
+	}
+
+	switch {
+	case !apply.Simulate && (apply.SimulateResponses != "" || apply.SimulateOut != ""):
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible apply options",
+			"The -simulate-responses and -simulate-out options can only be used together with -simulate.",
+		))
+	case apply.Simulate && apply.appliesSavedPlan():
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible apply options",
+			"A simulated apply creates its plan using the simulated providers, so the -simulate option cannot be used when applying a saved plan.",
+		))
+	}
+
+	switch apply.Progress {
+	case "", ApplyProgressLines, ApplyProgressGrouped:
+	default:
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid progress option",
+			fmt.Sprintf("The -progress option must be either \"lines\" or \"grouped\", not %q.", apply.Progress),
+		))
+	}
+
 	// JSON view currently does not support input, so we disable it here.
 	if json {
 		apply.InputEnabled = false
@@ -79,7 +202,7 @@ func ParseApply(args []string) (*Apply, tfdiags.Diagnostics) {
 	// JSON view cannot confirm apply, so we require either a plan file or
 	// auto-approve to be specified. We intentionally fail here rather than
 	// override auto-approve, which would be dangerous.
-	if json && apply.PlanPath == "" && !apply.AutoApprove {
+	if json && !apply.appliesSavedPlan() && !apply.AutoApprove {
 		diags = diags.Append(tfdiags.Sourceless(
 			tfdiags.Error,
 			"Plan file or auto-approve required",
@@ -89,6 +212,14 @@ func ParseApply(args []string) (*Apply, tfdiags.Diagnostics) {
 
 	diags = diags.Append(apply.Operation.Parse())
 
+	if apply.appliesSavedPlan() && !apply.Operation.Stage.IsRoot() {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible apply options",
+			"A saved plan records the stage it was created for, so the -stage option cannot be used when applying a saved plan.",
+		))
+	}
+
 	switch {
 	case json:
 		apply.ViewType = ViewJSON
@@ -140,6 +271,14 @@ func ParseApplyDestroy(args []string) (*Apply, tfdiags.Diagnostics) {
 		))
 	}
 
+	if apply.Resume || apply.ResumeBundle != "" || apply.BundleOut != "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid resume option",
+			"The -resume, -resume-bundle, and -bundle-out options are not valid for \"tofu destroy\", because they apply only to saved plan files.",
+		))
+	}
+
 	// NOTE: It's also invalid to have apply.PlanPath set in this codepath,
 	// but we don't check that in here because we'll return a different error
 	// message depending on whether the given path seems to refer to a saved
@@ -50,6 +50,39 @@ func TestParseApply_basicValid(t *testing.T) {
 				},
 			},
 		},
+		"resume": {
+			[]string{"-resume"},
+			&Apply{
+				AutoApprove:  false,
+				InputEnabled: true,
+				Resume:       true,
+				ViewType:     ViewHuman,
+				State:        &State{Lock: true},
+				Vars:         &Vars{},
+				Operation: &Operation{
+					PlanMode:    plans.NormalMode,
+					Parallelism: 10,
+					Refresh:     true,
+				},
+			},
+		},
+		"resume bundle": {
+			[]string{"-resume-bundle=resume.zip", "-bundle-out=next.zip"},
+			&Apply{
+				AutoApprove:  false,
+				InputEnabled: true,
+				ResumeBundle: "resume.zip",
+				BundleOut:    "next.zip",
+				ViewType:     ViewHuman,
+				State:        &State{Lock: true},
+				Vars:         &Vars{},
+				Operation: &Operation{
+					PlanMode:    plans.NormalMode,
+					Parallelism: 10,
+					Refresh:     true,
+				},
+			},
+		},
 		"destroy mode": {
 			[]string{"-destroy"},
 			&Apply{
@@ -165,6 +198,92 @@ func TestParseApply_tooManyArguments(t *testing.T) {
 	}
 }
 
+func TestParseApply_resumeWithPlanPath(t *testing.T) {
+	_, diags := ParseApply([]string{"-resume", "saved.tfplan"})
+	if len(diags) == 0 {
+		t.Fatal("expected diags but got none")
+	}
+	if got, want := diags.Err().Error(), "cannot be used with a plan file argument"; !strings.Contains(got, want) {
+		t.Fatalf("wrong diags\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestParseApply_resumeBundleInvalid(t *testing.T) {
+	testCases := map[string]struct {
+		args []string
+		want string
+	}{
+		"with plan path": {
+			[]string{"-resume-bundle=resume.zip", "saved.tfplan"},
+			"cannot be used with a plan file argument",
+		},
+		"with resume": {
+			[]string{"-resume-bundle=resume.zip", "-resume"},
+			"The -resume and -resume-bundle options cannot be used together.",
+		},
+		"bundle out without saved plan": {
+			[]string{"-bundle-out=resume.zip"},
+			"the -bundle-out option can only be used with a plan file argument",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, diags := ParseApply(tc.args)
+			if len(diags) == 0 {
+				t.Fatal("expected diags but got none")
+			}
+			if got := diags.Err().Error(); !strings.Contains(got, tc.want) {
+				t.Fatalf("wrong diags\n got: %s\nwant: %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseApply_progress(t *testing.T) {
+	got, diags := ParseApply([]string{"-progress=grouped"})
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diags: %s", diags.Err())
+	}
+	if got.Progress != ApplyProgressGrouped {
+		t.Errorf("wrong progress %q; want %q", got.Progress, ApplyProgressGrouped)
+	}
+
+	_, diags = ParseApply([]string{"-progress=fancy"})
+	if got, want := diags.Err().Error(), `must be either "lines" or "grouped", not "fancy"`; !strings.Contains(got, want) {
+		t.Fatalf("wrong diags\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestParseApply_simulate(t *testing.T) {
+	got, diags := ParseApply([]string{"-simulate", "-simulate-responses=responses.json", "-simulate-out=rehearsal"})
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diags: %s", diags.Err())
+	}
+	if !got.Simulate || got.SimulateResponses != "responses.json" || got.SimulateOut != "rehearsal" {
+		t.Errorf("wrong simulate options: %t, %q, %q", got.Simulate, got.SimulateResponses, got.SimulateOut)
+	}
+
+	_, diags = ParseApply([]string{"-simulate-out=rehearsal"})
+	if got, want := diags.Err().Error(), "can only be used together with -simulate"; !strings.Contains(got, want) {
+		t.Fatalf("wrong diags\n got: %s\nwant: %s", got, want)
+	}
+
+	_, diags = ParseApply([]string{"-simulate", "saved.tfplan"})
+	if got, want := diags.Err().Error(), "cannot be used when applying a saved plan"; !strings.Contains(got, want) {
+		t.Fatalf("wrong diags\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestParseApply_stageWithPlanPath(t *testing.T) {
+	_, diags := ParseApply([]string{"-stage=module.network", "saved.tfplan"})
+	if len(diags) == 0 {
+		t.Fatal("expected diags but got none")
+	}
+	if got, want := diags.Err().Error(), "Incompatible apply options"; !strings.Contains(got, want) {
+		t.Fatalf("wrong diags\n got: %s\nwant: %s", got, want)
+	}
+}
+
 func TestParseApply_targets(t *testing.T) {
 	foobarbaz, _ := addrs.ParseTargetStr("foo_bar.baz")
 	boop, _ := addrs.ParseTargetStr("module.boop")
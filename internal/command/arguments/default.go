@@ -6,8 +6,23 @@ package arguments
 import (
 	"flag"
 	"io"
+	"os"
+	"strconv"
 )
 
+// InputModeEnvVar is the environment variable that, if set to "false" or
+// "0", causes commands to behave as if the -input=false option was given.
+const InputModeEnvVar = "TF_INPUT"
+
+// InputEnabledDefault returns the default value of the -input option, which
+// is true unless the InputModeEnvVar environment variable disables it.
+func InputEnabledDefault() bool {
+	if v, err := strconv.ParseBool(os.Getenv(InputModeEnvVar)); err == nil {
+		return v
+	}
+	return true
+}
+
 // defaultFlagSet creates a FlagSet with the common settings to override
 // the flag package's noisy defaults.
 func defaultFlagSet(name string) *flag.FlagSet {
@@ -6,10 +6,14 @@ package arguments
 import (
 	"flag"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/plans"
@@ -67,6 +71,11 @@ type Operation struct {
 	// their dependencies.
 	Targets []addrs.Targetable
 
+	// Excludes allow limiting an operation to everything except a set of
+	// resource addresses and the objects that depend on them. Each address
+	// may contain "*" wildcards, in which case it is an addrs.TargetGlob.
+	Excludes []addrs.Targetable
+
 	// ForceReplace addresses cause OpenTofu to force a particular set of
 	// resource instances to generate "replace" actions in any plan where they
 	// would normally have generated "no-op" or "update" actions.
@@ -79,13 +88,56 @@ type Operation struct {
 	// learn a use-case for broader matching.
 	ForceReplace []addrs.AbsResourceInstance
 
+	// AllowDeferral allows the plan to defer the changes for resources whose
+	// instances can't be determined until apply, instead of returning an
+	// error.
+	AllowDeferral bool
+
+	// Stage, if not the root module, limits the operation to the resources
+	// in a single module call, so that a large configuration can be rolled
+	// out one module at a time.
+	Stage addrs.Module
+
+	// ProviderConcurrency and ResourceConcurrency limit the number of
+	// concurrent operations for resources belonging to particular providers
+	// or of particular managed resource types, in addition to Parallelism.
+	// These take precedence over any limits set in the configuration.
+	ProviderConcurrency map[addrs.Provider]int
+	ResourceConcurrency map[string]int
+
+	// ProviderRateLimits limit the number of operations per second that may
+	// start for resources belonging to particular providers, taking
+	// precedence over any rate limits set in the configuration.
+	ProviderRateLimits map[addrs.Provider]float64
+
+	// RefreshParallelism and ProviderRefreshParallelism limit the number of
+	// concurrent reads of remote objects for each provider configuration
+	// while refreshing, separately from Parallelism. A RefreshParallelism
+	// of zero selects the default limit.
+	RefreshParallelism         int
+	ProviderRefreshParallelism map[addrs.Provider]int
+
+	// DriftSelection selects which of the changes made outside of OpenTofu
+	// a refresh-only plan accepts into the state. It is nil unless the
+	// -accept-drift or -reject-drift options were used.
+	DriftSelection *plans.DriftSelection
+
 	// These private fields are used only temporarily during decoding. Use
 	// method Parse to populate the exported fields from these, validating
 	// the raw values in the process.
 	targetsRaw      []string
+	excludesRaw     []string
 	forceReplaceRaw []string
 	destroyRaw      bool
 	refreshOnlyRaw  bool
+	stageRaw        string
+
+	providerConcurrencyRaw []string
+	resourceConcurrencyRaw []string
+	providerRateLimitRaw   []string
+	refreshParallelismRaw  []string
+	acceptDriftRaw         []string
+	rejectDriftRaw         []string
 }
 
 // Parse must be called on Operation after initial flag parse. This processes
@@ -120,6 +172,52 @@ func (o *Operation) Parse() tfdiags.Diagnostics {
 		o.Targets = append(o.Targets, target.Subject)
 	}
 
+	o.Excludes = nil
+	for _, raw := range o.excludesRaw {
+		if addrs.IsTargetGlob(raw) {
+			glob, globDiags := addrs.ParseTargetGlob(raw)
+			if globDiags.HasErrors() {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					fmt.Sprintf("Invalid exclude %q", raw),
+					globDiags[0].Description().Detail,
+				))
+				continue
+			}
+			o.Excludes = append(o.Excludes, glob)
+			continue
+		}
+
+		traversal, syntaxDiags := hclsyntax.ParseTraversalAbs([]byte(raw), "", hcl.Pos{Line: 1, Column: 1})
+		if syntaxDiags.HasErrors() {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				fmt.Sprintf("Invalid exclude %q", raw),
+				syntaxDiags[0].Detail,
+			))
+			continue
+		}
+
+		exclude, excludeDiags := addrs.ParseTarget(traversal)
+		if excludeDiags.HasErrors() {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				fmt.Sprintf("Invalid exclude %q", raw),
+				excludeDiags[0].Description().Detail,
+			))
+			continue
+		}
+
+		o.Excludes = append(o.Excludes, exclude.Subject)
+	}
+	if len(o.targetsRaw) > 0 && len(o.excludesRaw) > 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible target and exclude options",
+			"The -target and -exclude options are mutually-exclusive.",
+		))
+	}
+
 	for _, raw := range o.forceReplaceRaw {
 		traversal, syntaxDiags := hclsyntax.ParseTraversalAbs([]byte(raw), "", hcl.Pos{Line: 1, Column: 1})
 		if syntaxDiags.HasErrors() {
@@ -153,6 +251,170 @@ func (o *Operation) Parse() tfdiags.Diagnostics {
 		o.ForceReplace = append(o.ForceReplace, addr)
 	}
 
+	o.Stage = nil
+	if o.stageRaw != "" {
+		stage, stageDiags := addrs.ParseModuleInstanceStr(o.stageRaw)
+		switch {
+		case stageDiags.HasErrors():
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				fmt.Sprintf("Invalid stage %q", o.stageRaw),
+				stageDiags[0].Description().Detail,
+			))
+		case stage.IsRoot() || stage.Module().String() != stage.String():
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				fmt.Sprintf("Invalid stage %q", o.stageRaw),
+				"A stage must be a module call, such as module.network, without any instance keys.",
+			))
+		case len(o.targetsRaw) > 0:
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Incompatible stage and target options",
+				"The -stage and -target options are mutually-exclusive.",
+			))
+		default:
+			o.Stage = stage.Module()
+		}
+	}
+
+	o.ProviderConcurrency = nil
+	for _, raw := range o.providerConcurrencyRaw {
+		name, limit, ok := parseConcurrencyLimit(raw)
+		if !ok {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				fmt.Sprintf("Invalid provider concurrency limit %q", raw),
+				"The -provider-concurrency option requires a provider source address and a whole number greater than zero, like -provider-concurrency=hashicorp/aws=4.",
+			))
+			continue
+		}
+		provider, providerDiags := addrs.ParseProviderSourceString(name)
+		if providerDiags.HasErrors() {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				fmt.Sprintf("Invalid provider concurrency limit %q", raw),
+				providerDiags[0].Description().Detail,
+			))
+			continue
+		}
+		if o.ProviderConcurrency == nil {
+			o.ProviderConcurrency = make(map[addrs.Provider]int)
+		}
+		o.ProviderConcurrency[provider] = limit
+	}
+
+	o.ResourceConcurrency = nil
+	for _, raw := range o.resourceConcurrencyRaw {
+		name, limit, ok := parseConcurrencyLimit(raw)
+		if !ok || !hclsyntax.ValidIdentifier(name) {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				fmt.Sprintf("Invalid resource concurrency limit %q", raw),
+				"The -resource-concurrency option requires a managed resource type name and a whole number greater than zero, like -resource-concurrency=aws_instance=2.",
+			))
+			continue
+		}
+		if o.ResourceConcurrency == nil {
+			o.ResourceConcurrency = make(map[string]int)
+		}
+		o.ResourceConcurrency[name] = limit
+	}
+
+	o.ProviderRateLimits = nil
+	for _, raw := range o.providerRateLimitRaw {
+		name, rawRate, ok := strings.Cut(raw, "=")
+		rate, err := strconv.ParseFloat(rawRate, 64)
+		if !ok || name == "" || err != nil || !(rate > 0) || math.IsInf(rate, 0) {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				fmt.Sprintf("Invalid provider rate limit %q", raw),
+				"The -provider-rate-limit option requires a provider source address and a number of operations per second greater than zero, like -provider-rate-limit=hashicorp/aws=10.",
+			))
+			continue
+		}
+		provider, providerDiags := addrs.ParseProviderSourceString(name)
+		if providerDiags.HasErrors() {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				fmt.Sprintf("Invalid provider rate limit %q", raw),
+				providerDiags[0].Description().Detail,
+			))
+			continue
+		}
+		if o.ProviderRateLimits == nil {
+			o.ProviderRateLimits = make(map[addrs.Provider]float64)
+		}
+		o.ProviderRateLimits[provider] = rate
+	}
+
+	o.RefreshParallelism = 0
+	o.ProviderRefreshParallelism = nil
+	for _, raw := range o.refreshParallelismRaw {
+		if !strings.Contains(raw, "=") {
+			limit, err := strconv.Atoi(raw)
+			if err != nil || limit < 1 {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					fmt.Sprintf("Invalid refresh parallelism %q", raw),
+					"The -refresh-parallelism option requires a whole number greater than zero, optionally preceded by a provider source address, like -refresh-parallelism=20 or -refresh-parallelism=hashicorp/aws=4.",
+				))
+				continue
+			}
+			o.RefreshParallelism = limit
+			continue
+		}
+		name, limit, ok := parseConcurrencyLimit(raw)
+		if !ok {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				fmt.Sprintf("Invalid refresh parallelism %q", raw),
+				"The -refresh-parallelism option requires a whole number greater than zero, optionally preceded by a provider source address, like -refresh-parallelism=20 or -refresh-parallelism=hashicorp/aws=4.",
+			))
+			continue
+		}
+		provider, providerDiags := addrs.ParseProviderSourceString(name)
+		if providerDiags.HasErrors() {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				fmt.Sprintf("Invalid refresh parallelism %q", raw),
+				providerDiags[0].Description().Detail,
+			))
+			continue
+		}
+		if o.ProviderRefreshParallelism == nil {
+			o.ProviderRefreshParallelism = make(map[addrs.Provider]int)
+		}
+		o.ProviderRefreshParallelism[provider] = limit
+	}
+
+	o.DriftSelection = nil
+	if len(o.acceptDriftRaw) > 0 || len(o.rejectDriftRaw) > 0 {
+		selection := &plans.DriftSelection{}
+		for _, raw := range o.acceptDriftRaw {
+			sel, selDiags := parseDriftSelector("-accept-drift", raw)
+			diags = diags.Append(selDiags)
+			if !selDiags.HasErrors() {
+				selection.Accept = append(selection.Accept, sel)
+			}
+		}
+		for _, raw := range o.rejectDriftRaw {
+			sel, selDiags := parseDriftSelector("-reject-drift", raw)
+			diags = diags.Append(selDiags)
+			if !selDiags.HasErrors() {
+				selection.Reject = append(selection.Reject, sel)
+			}
+		}
+		if !o.refreshOnlyRaw {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Incompatible drift selection options",
+				"The -accept-drift and -reject-drift options can only be used together with -refresh-only.",
+			))
+		}
+		o.DriftSelection = selection
+	}
+
 	// If you add a new possible value for o.PlanMode here, consider also
 	// adding a specialized error message for it in ParseApplyDestroy.
 	switch {
@@ -180,6 +442,90 @@ func (o *Operation) Parse() tfdiags.Diagnostics {
 	return diags
 }
 
+// parseDriftSelector parses the address given to the -accept-drift or
+// -reject-drift option, which is a module, resource or resource instance
+// address, optionally followed by the path of an attribute of a resource's
+// objects, like aws_instance.example.tags["Name"].
+func parseDriftSelector(option, raw string) (plans.DriftSelector, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	var sel plans.DriftSelector
+
+	traversal, syntaxDiags := hclsyntax.ParseTraversalAbs([]byte(raw), "", hcl.Pos{Line: 1, Column: 1})
+	if syntaxDiags.HasErrors() {
+		return sel, diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			fmt.Sprintf("Invalid %s address %q", option, raw),
+			syntaxDiags[0].Detail,
+		))
+	}
+
+	target, targetDiags := addrs.ParseTarget(traversal)
+	if !targetDiags.HasErrors() {
+		sel.Target = target.Subject
+		return sel, diags
+	}
+
+	// The address might be followed by an attribute path, in which case the
+	// longest prefix that is a valid address must be a resource or resource
+	// instance, and the remainder must start with an attribute name.
+	for i := len(traversal) - 1; i > 0; i-- {
+		prefix, prefixDiags := addrs.ParseTarget(traversal[:i])
+		if prefixDiags.HasErrors() {
+			continue
+		}
+		switch prefix.Subject.(type) {
+		case addrs.AbsResource, addrs.AbsResourceInstance:
+		default:
+			continue
+		}
+		path, ok := driftSelectorPath(traversal[i:])
+		if !ok {
+			break
+		}
+		sel.Target = prefix.Subject
+		sel.Path = path
+		return sel, diags
+	}
+
+	return sel, diags.Append(tfdiags.Sourceless(
+		tfdiags.Error,
+		fmt.Sprintf("Invalid %s address %q", option, raw),
+		targetDiags[0].Description().Detail,
+	))
+}
+
+func driftSelectorPath(traversal hcl.Traversal) (cty.Path, bool) {
+	if _, ok := traversal[0].(hcl.TraverseAttr); !ok {
+		return nil, false
+	}
+	var path cty.Path
+	for _, step := range traversal {
+		switch step := step.(type) {
+		case hcl.TraverseAttr:
+			path = path.GetAttr(step.Name)
+		case hcl.TraverseIndex:
+			path = path.Index(step.Key)
+		default:
+			return nil, false
+		}
+	}
+	return path, true
+}
+
+// parseConcurrencyLimit splits a raw NAME=LIMIT argument, returning false if
+// it is not in that form or if the limit is not a positive whole number.
+func parseConcurrencyLimit(raw string) (string, int, bool) {
+	name, rawLimit, ok := strings.Cut(raw, "=")
+	if !ok || name == "" {
+		return "", 0, false
+	}
+	limit, err := strconv.Atoi(rawLimit)
+	if err != nil || limit < 1 {
+		return "", 0, false
+	}
+	return name, limit, true
+}
+
 // Vars describes arguments which specify non-default variable values. This
 // interfce is unfortunately obscure, because the order of the CLI arguments
 // determines the final value of the gathered variables. In future it might be
@@ -228,7 +574,16 @@ func extendedFlagSet(name string, state *State, operation *Operation, vars *Vars
 		f.BoolVar(&operation.destroyRaw, "destroy", false, "destroy")
 		f.BoolVar(&operation.refreshOnlyRaw, "refresh-only", false, "refresh-only")
 		f.Var((*flagStringSlice)(&operation.targetsRaw), "target", "target")
+		f.Var((*flagStringSlice)(&operation.excludesRaw), "exclude", "exclude")
 		f.Var((*flagStringSlice)(&operation.forceReplaceRaw), "replace", "replace")
+		f.BoolVar(&operation.AllowDeferral, "allow-deferral", false, "allow-deferral")
+		f.StringVar(&operation.stageRaw, "stage", "", "stage")
+		f.Var((*flagStringSlice)(&operation.providerConcurrencyRaw), "provider-concurrency", "provider-concurrency")
+		f.Var((*flagStringSlice)(&operation.resourceConcurrencyRaw), "resource-concurrency", "resource-concurrency")
+		f.Var((*flagStringSlice)(&operation.providerRateLimitRaw), "provider-rate-limit", "provider-rate-limit")
+		f.Var((*flagStringSlice)(&operation.refreshParallelismRaw), "refresh-parallelism", "refresh-parallelism")
+		f.Var((*flagStringSlice)(&operation.acceptDriftRaw), "accept-drift", "accept-drift")
+		f.Var((*flagStringSlice)(&operation.rejectDriftRaw), "reject-drift", "reject-drift")
 	}
 
 	// Gather all -var and -var-file arguments into one heterogenous structure
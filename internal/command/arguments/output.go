@@ -4,6 +4,9 @@
 package arguments
 
 import (
+	"fmt"
+	"regexp"
+
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
@@ -17,10 +20,19 @@ type Output struct {
 	// be loaded.
 	StatePath string
 
-	// ViewType specifies which output format to use: human, JSON, or "raw".
+	// ViewType specifies which output format to use: human, JSON, "raw",
+	// or one of the environment-oriented formats dotenv, shell, or YAML.
 	ViewType ViewType
+
+	// Prefix is an optional string prepended to each variable name when
+	// using the dotenv or shell formats.
+	Prefix string
 }
 
+// outputPrefixPattern matches strings that can start a valid environment
+// variable name.
+var outputPrefixPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
 // ParseOutput processes CLI arguments, returning an Output value and errors.
 // If errors are encountered, an Output value is still returned representing
 // the best effort interpretation of the arguments.
@@ -29,10 +41,12 @@ func ParseOutput(args []string) (*Output, tfdiags.Diagnostics) {
 	output := &Output{}
 
 	var jsonOutput, rawOutput bool
-	var statePath string
+	var statePath, format, prefix string
 	cmdFlags := defaultFlagSet("output")
 	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
 	cmdFlags.BoolVar(&rawOutput, "raw", false, "raw")
+	cmdFlags.StringVar(&format, "format", "", "format")
+	cmdFlags.StringVar(&prefix, "prefix", "", "prefix")
 	cmdFlags.StringVar(&statePath, "state", "", "path")
 
 	if err := cmdFlags.Parse(args); err != nil {
@@ -64,6 +78,56 @@ func ParseOutput(args []string) (*Output, tfdiags.Diagnostics) {
 		rawOutput = false
 	}
 
+	var formatType ViewType
+	switch format {
+	case "":
+		// No explicit format, so -json or -raw decide below.
+	case "dotenv":
+		formatType = ViewDotenv
+	case "shell":
+		formatType = ViewShell
+	case "yaml":
+		formatType = ViewYAML
+	default:
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid output format",
+			fmt.Sprintf("The -format option must be one of \"dotenv\", \"shell\", or \"yaml\", not %q.", format),
+		))
+	}
+
+	if formatType != ViewNone && (jsonOutput || rawOutput) {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid output format",
+			"The -format option cannot be used together with the -raw or -json options.",
+		))
+
+		// As above, fall back to the default format.
+		formatType = ViewNone
+		jsonOutput = false
+		rawOutput = false
+	}
+
+	if prefix != "" {
+		switch {
+		case formatType != ViewDotenv && formatType != ViewShell:
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid output prefix",
+				"The -prefix option is only supported with -format=dotenv or -format=shell.",
+			))
+		case !outputPrefixPattern.MatchString(prefix):
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid output prefix",
+				fmt.Sprintf("The -prefix option must start with a letter or underscore and contain only letters, digits, and underscores, so %q cannot be used.", prefix),
+			))
+		default:
+			output.Prefix = prefix
+		}
+	}
+
 	output.StatePath = statePath
 
 	if len(args) > 0 {
@@ -79,6 +143,8 @@ func ParseOutput(args []string) (*Output, tfdiags.Diagnostics) {
 	}
 
 	switch {
+	case formatType != ViewNone:
+		output.ViewType = formatType
 	case jsonOutput:
 		output.ViewType = ViewJSON
 	case rawOutput:
@@ -48,6 +48,26 @@ func TestParseOutput_valid(t *testing.T) {
 				StatePath: "foobar.tfstate",
 			},
 		},
+		"dotenv with prefix": {
+			[]string{"-format=dotenv", "-prefix=TF_OUT_"},
+			&Output{
+				ViewType: ViewDotenv,
+				Prefix:   "TF_OUT_",
+			},
+		},
+		"shell": {
+			[]string{"-format=shell", "foo"},
+			&Output{
+				Name:     "foo",
+				ViewType: ViewShell,
+			},
+		},
+		"yaml": {
+			[]string{"-format=yaml"},
+			&Output{
+				ViewType: ViewYAML,
+			},
+		},
 	}
 
 	for name, tc := range testCases {
@@ -114,6 +134,58 @@ func TestParseOutput_invalid(t *testing.T) {
 				),
 			},
 		},
+		"unsupported format": {
+			[]string{"-format=toml"},
+			&Output{
+				ViewType: ViewHuman,
+			},
+			tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid output format",
+					`The -format option must be one of "dotenv", "shell", or "yaml", not "toml".`,
+				),
+			},
+		},
+		"format and json specified": {
+			[]string{"-format=yaml", "-json"},
+			&Output{
+				ViewType: ViewHuman,
+			},
+			tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid output format",
+					"The -format option cannot be used together with the -raw or -json options.",
+				),
+			},
+		},
+		"prefix without env format": {
+			[]string{"-format=yaml", "-prefix=TF_"},
+			&Output{
+				ViewType: ViewYAML,
+			},
+			tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid output prefix",
+					"The -prefix option is only supported with -format=dotenv or -format=shell.",
+				),
+			},
+		},
+		"invalid prefix": {
+			[]string{"-format=shell", "-prefix=1-"},
+			&Output{
+				ViewType: ViewShell,
+			},
+			tfdiags.Diagnostics{
+				tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid output prefix",
+					`The -prefix option must start with a letter or underscore and contain only letters, digits, and underscores, so "1-" cannot be used.`,
+				),
+			},
+		},
 		"too many arguments": {
 			[]string{"-raw", "-state=foo.tfstate", "bar", "baz"},
 			&Output{
@@ -4,9 +4,17 @@
 package arguments
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
+// ExitCodeFailOn is the exit status for a successful plan which includes at
+// least one of the change actions selected using the -fail-on option.
+const ExitCodeFailOn = 3
+
 // Plan represents the command-line arguments for the plan command.
 type Plan struct {
 	// State, Operation, and Vars are the common extended flags
@@ -18,6 +26,16 @@ type Plan struct {
 	// changes, and success with no changes.
 	DetailedExitCode bool
 
+	// FailOn lists the resource instance change actions which cause the
+	// command to exit with status ExitCodeFailOn if the plan includes any
+	// of them, so that automation can distinguish between different kinds
+	// of change without inspecting the plan.
+	FailOn []plans.Action
+
+	// Summary controls how the proposed changes are presented in
+	// human-readable output.
+	Summary *PlanSummary
+
 	// InputEnabled is used to disable interactive input for unspecified
 	// variable and backend config values. Default is true.
 	InputEnabled bool
@@ -25,11 +43,39 @@ type Plan struct {
 	// OutPath contains an optional path to store the plan file
 	OutPath string
 
+	// StateSource, if set, selects an alternate state to use as the prior
+	// state for a speculative plan, instead of the current workspace's state.
+	StateSource *StateSource
+
+	// PolicyPaths are policy files or directories containing
+	// policy files, whose policies are evaluated against the plan.
+	PolicyPaths []string
+
+	// Watch enables watch mode, in which the plan command keeps running and
+	// creates a new speculative plan each time the configuration or
+	// variable definitions files change.
+	Watch bool
+
+	// RefinePath, if set, is the path of a saved plan file to refine,
+	// reusing its changes for the resources unaffected by what changed since.
+	RefinePath string
+
 	// GenerateConfigPath tells OpenTofu that config should be generated for
 	// unmatched import target paths and which path the generated file should
 	// be written to.
 	GenerateConfigPath string
 
+	// GenerateImports asks the providers to list the existing objects of the
+	// managed resource types in the configuration, and writes draft import
+	// blocks and configuration for the ones that OpenTofu doesn't manage
+	// into the file at GenerateConfigPath.
+	GenerateImports bool
+
+	// BlastRadius asks for a summary of the objects that a destroy plan
+	// destroys, including those likely to lose data and the other modules
+	// which depend on them.
+	BlastRadius bool
+
 	// ViewType specifies which output format to use
 	ViewType ViewType
 }
@@ -43,13 +89,27 @@ func ParsePlan(args []string) (*Plan, tfdiags.Diagnostics) {
 		State:     &State{},
 		Operation: &Operation{},
 		Vars:      &Vars{},
+		Summary:   &PlanSummary{},
 	}
 
 	cmdFlags := extendedFlagSet("plan", plan.State, plan.Operation, plan.Vars)
+	plan.Summary.addFlags(cmdFlags)
 	cmdFlags.BoolVar(&plan.DetailedExitCode, "detailed-exitcode", false, "detailed-exitcode")
-	cmdFlags.BoolVar(&plan.InputEnabled, "input", true, "input")
+	cmdFlags.BoolVar(&plan.InputEnabled, "input", InputEnabledDefault(), "input")
 	cmdFlags.StringVar(&plan.OutPath, "out", "", "out")
 	cmdFlags.StringVar(&plan.GenerateConfigPath, "generate-config-out", "", "generate-config-out")
+	cmdFlags.BoolVar(&plan.GenerateImports, "generate-imports", false, "generate-imports")
+	cmdFlags.StringVar(&plan.RefinePath, "refine", "", "refine")
+	cmdFlags.BoolVar(&plan.BlastRadius, "blast-radius", false, "blast-radius")
+
+	cmdFlags.BoolVar(&plan.Watch, "watch", false, "watch")
+	cmdFlags.Var((*flagStringSlice)(&plan.PolicyPaths), "policy", "policy")
+
+	var stateSourceRaw string
+	cmdFlags.StringVar(&stateSourceRaw, "state-source", "", "state-source")
+
+	var failOnRaw []string
+	cmdFlags.Var((*flagStringSlice)(&failOnRaw), "fail-on", "fail-on")
 
 	var json bool
 	cmdFlags.BoolVar(&json, "json", false, "json")
@@ -73,12 +133,105 @@ func ParsePlan(args []string) (*Plan, tfdiags.Diagnostics) {
 	}
 
 	diags = diags.Append(plan.Operation.Parse())
+	diags = diags.Append(plan.Summary.Parse())
+
+	failOn, failOnDiags := parseFailOn(failOnRaw)
+	diags = diags.Append(failOnDiags)
+	plan.FailOn = failOn
+
+	if stateSourceRaw != "" {
+		stateSource, stateSourceDiags := parseStateSource(stateSourceRaw)
+		diags = diags.Append(stateSourceDiags)
+		plan.StateSource = stateSource
+
+		if plan.OutPath != "" {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Incompatible plan options",
+				"A plan created with -state-source is speculative and cannot be saved with -out, because it is not based on the current workspace's state.",
+			))
+		}
+	}
+
+	if plan.RefinePath != "" {
+		switch {
+		case plan.Operation.PlanMode != plans.NormalMode:
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Incompatible plan options",
+				"A saved plan can be refined only in the normal planning mode.",
+			))
+		case plan.StateSource != nil:
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Incompatible plan options",
+				"A saved plan cannot be refined with -state-source, because the saved plan is based on the current workspace's state.",
+			))
+		}
+	}
+
+	if plan.GenerateImports {
+		switch {
+		case plan.GenerateConfigPath == "":
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid generate-imports flag",
+				"The -generate-imports option requires -generate-config-out, to select the file that the import blocks and configuration are written to.",
+			))
+		case plan.Operation.PlanMode != plans.NormalMode:
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid generate-imports flag",
+				"Import blocks can only be generated during a normal plan operation, and not during a refresh-only or destroy plan.",
+			))
+		}
+	}
+
+	if plan.BlastRadius && plan.Operation.PlanMode != plans.DestroyMode {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid blast-radius flag",
+			"The -blast-radius option summarizes the objects that a destroy plan destroys, so it can only be used together with -destroy.",
+		))
+	}
+
+	if plan.Watch {
+		var incompatible []string
+		if plan.OutPath != "" {
+			incompatible = append(incompatible, "-out")
+		}
+		if plan.DetailedExitCode {
+			incompatible = append(incompatible, "-detailed-exitcode")
+		}
+		if len(plan.FailOn) > 0 {
+			incompatible = append(incompatible, "-fail-on")
+		}
+		if plan.GenerateConfigPath != "" {
+			incompatible = append(incompatible, "-generate-config-out")
+		}
+		if plan.RefinePath != "" {
+			incompatible = append(incompatible, "-refine")
+		}
+		if len(incompatible) > 0 {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Incompatible plan options",
+				fmt.Sprintf("The -watch option creates a new speculative plan each time the configuration changes, so it cannot be used with %s.", strings.Join(incompatible, ", ")),
+			))
+		}
+	}
 
 	// JSON view currently does not support input, so we disable it here
 	if json {
 		plan.InputEnabled = false
 	}
 
+	// Watch mode runs unattended between changes, so it cannot prompt for
+	// input either.
+	if plan.Watch {
+		plan.InputEnabled = false
+	}
+
 	switch {
 	case json:
 		plan.ViewType = ViewJSON
@@ -88,3 +241,50 @@ func ParsePlan(args []string) (*Plan, tfdiags.Diagnostics) {
 
 	return plan, diags
 }
+
+// failOnActions maps the change classes accepted by the -fail-on option to
+// the plan actions that belong to each.
+var failOnActions = map[string][]plans.Action{
+	"create":  {plans.Create},
+	"update":  {plans.Update},
+	"replace": {plans.DeleteThenCreate, plans.CreateThenDelete},
+	"destroy": {plans.Delete},
+}
+
+// parseFailOn decodes the raw values of any -fail-on options, each of which
+// is a comma-separated list of change classes.
+func parseFailOn(raws []string) ([]plans.Action, tfdiags.Diagnostics) {
+	return parseChangeClasses("-fail-on", raws, failOnActions, "create, update, replace, destroy")
+}
+
+// parseChangeClasses decodes the raw values of a repeatable option whose
+// values are comma-separated lists of the names of change classes, using
+// the given mapping from change class names to plan actions. validNames
+// lists the valid names for use in error messages.
+func parseChangeClasses(option string, raws []string, classes map[string][]plans.Action, validNames string) ([]plans.Action, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	var ret []plans.Action
+	seen := make(map[string]bool)
+
+	for _, raw := range raws {
+		for _, name := range strings.Split(raw, ",") {
+			name = strings.TrimSpace(name)
+			actions, ok := classes[name]
+			if !ok {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					fmt.Sprintf("Invalid %s value", option),
+					fmt.Sprintf("Unsupported change class %q. The %s option accepts a comma-separated list of the following: %s.", name, option, validNames),
+				))
+				continue
+			}
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			ret = append(ret, actions...)
+		}
+	}
+
+	return ret, diags
+}
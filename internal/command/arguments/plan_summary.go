@@ -0,0 +1,81 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package arguments
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// PlanSummary describes arguments which control how the proposed changes in
+// a plan are presented in human-readable output.
+type PlanSummary struct {
+	// ByModule replaces the individual resource changes with a summary of
+	// the number of changes of each kind in each module call.
+	ByModule bool
+
+	// ExpandModules lists module calls whose individual resource changes
+	// should be shown in full even when ByModule is set.
+	ExpandModules []addrs.Module
+
+	// These private fields are used only temporarily during decoding. Use
+	// method Parse to populate the exported fields from these, validating
+	// the raw values in the process.
+	summaryRaw string
+	expandRaw  []string
+}
+
+// addFlags registers the flags for the plan summary arguments on the given
+// FlagSet.
+func (s *PlanSummary) addFlags(f *flag.FlagSet) {
+	f.StringVar(&s.summaryRaw, "summary", "", "summary")
+	f.Var((*flagStringSlice)(&s.expandRaw), "expand", "expand")
+}
+
+// Parse must be called on PlanSummary after initial flag parse. This
+// processes the raw flags into the exported fields, returning diagnostics
+// if they are invalid.
+func (s *PlanSummary) Parse() tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	switch s.summaryRaw {
+	case "":
+		s.ByModule = false
+	case "module":
+		s.ByModule = true
+	default:
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid summary mode",
+			fmt.Sprintf("Unsupported summary mode %q. The only supported mode is \"module\".", s.summaryRaw),
+		))
+	}
+
+	s.ExpandModules = nil
+	for _, raw := range s.expandRaw {
+		addr, addrDiags := addrs.ParseModuleInstanceStr(raw)
+		if addrDiags.HasErrors() || addr.IsRoot() {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				fmt.Sprintf("Invalid module address %q", raw),
+				"The -expand option requires the address of a module call, such as module.example.",
+			))
+			continue
+		}
+		s.ExpandModules = append(s.ExpandModules, addr.Module())
+	}
+
+	if len(s.ExpandModules) > 0 && !s.ByModule {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible summary options",
+			"The -expand option can only be used with -summary=module.",
+		))
+	}
+
+	return diags
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
 func TestParsePlan_basicValid(t *testing.T) {
@@ -27,6 +28,7 @@ func TestParsePlan_basicValid(t *testing.T) {
 				ViewType:         ViewHuman,
 				State:            &State{Lock: true},
 				Vars:             &Vars{},
+				Summary:          &PlanSummary{},
 				Operation: &Operation{
 					PlanMode:    plans.NormalMode,
 					Parallelism: 10,
@@ -43,6 +45,7 @@ func TestParsePlan_basicValid(t *testing.T) {
 				ViewType:         ViewHuman,
 				State:            &State{Lock: true},
 				Vars:             &Vars{},
+				Summary:          &PlanSummary{},
 				Operation: &Operation{
 					PlanMode:    plans.DestroyMode,
 					Parallelism: 10,
@@ -50,6 +53,24 @@ func TestParsePlan_basicValid(t *testing.T) {
 				},
 			},
 		},
+		"allow deferral": {
+			[]string{"-allow-deferral"},
+			&Plan{
+				DetailedExitCode: false,
+				InputEnabled:     true,
+				OutPath:          "",
+				ViewType:         ViewHuman,
+				State:            &State{Lock: true},
+				Vars:             &Vars{},
+				Summary:          &PlanSummary{},
+				Operation: &Operation{
+					PlanMode:      plans.NormalMode,
+					Parallelism:   10,
+					Refresh:       true,
+					AllowDeferral: true,
+				},
+			},
+		},
 		"JSON view disables input": {
 			[]string{"-json"},
 			&Plan{
@@ -59,6 +80,7 @@ func TestParsePlan_basicValid(t *testing.T) {
 				ViewType:         ViewJSON,
 				State:            &State{Lock: true},
 				Vars:             &Vars{},
+				Summary:          &PlanSummary{},
 				Operation: &Operation{
 					PlanMode:    plans.NormalMode,
 					Parallelism: 10,
@@ -68,7 +90,7 @@ func TestParsePlan_basicValid(t *testing.T) {
 		},
 	}
 
-	cmpOpts := cmpopts.IgnoreUnexported(Operation{}, Vars{}, State{})
+	cmpOpts := cmpopts.IgnoreUnexported(Operation{}, Vars{}, State{}, PlanSummary{})
 
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
@@ -158,6 +180,459 @@ func TestParsePlan_targets(t *testing.T) {
 	}
 }
 
+func TestParsePlan_excludes(t *testing.T) {
+	testCases := map[string]struct {
+		args    []string
+		want    []string
+		wantErr string
+	}{
+		"no excludes by default": {
+			args: nil,
+			want: nil,
+		},
+		"resource and module": {
+			args: []string{"-exclude=foo_bar.baz", "-exclude", "module.boop"},
+			want: []string{"foo_bar.baz", "module.boop"},
+		},
+		"glob": {
+			args: []string{"-exclude=module.app[*].foo_bar.*"},
+			want: []string{"module.app[*].foo_bar.*"},
+		},
+		"invalid exclude": {
+			args:    []string{"-exclude=data[0].foo"},
+			wantErr: "A data source name is required",
+		},
+		"invalid glob": {
+			args:    []string{"-exclude=foo_bar.*.baz"},
+			wantErr: `Invalid exclude "foo_bar.*.baz"`,
+		},
+		"with target": {
+			args:    []string{"-exclude=foo_bar.baz", "-target=foo_bar.boop"},
+			wantErr: "Incompatible target and exclude options",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ParsePlan(tc.args)
+			if len(diags) > 0 {
+				if tc.wantErr == "" {
+					t.Fatalf("unexpected diags: %v", diags)
+				} else if got := diags.Err().Error(); !strings.Contains(got, tc.wantErr) {
+					t.Fatalf("wrong diags\n got: %s\nwant: %s", got, tc.wantErr)
+				}
+				return
+			}
+			if tc.wantErr != "" {
+				t.Fatalf("succeeded; want error %q", tc.wantErr)
+			}
+			var gotStrs []string
+			for _, addr := range got.Operation.Excludes {
+				gotStrs = append(gotStrs, addr.String())
+			}
+			if !cmp.Equal(gotStrs, tc.want) {
+				t.Fatalf("unexpected result\n%s", cmp.Diff(gotStrs, tc.want))
+			}
+		})
+	}
+}
+
+func TestParsePlan_concurrency(t *testing.T) {
+	testCases := map[string]struct {
+		args          []string
+		wantProviders map[addrs.Provider]int
+		wantResources map[string]int
+		wantErr       string
+	}{
+		"no limits by default": {
+			args: nil,
+		},
+		"provider and resource limits": {
+			args: []string{
+				"-provider-concurrency=hashicorp/aws=4",
+				"-provider-concurrency", "example.com/foo/bar=1",
+				"-resource-concurrency=aws_instance=2",
+			},
+			wantProviders: map[addrs.Provider]int{
+				addrs.NewDefaultProvider("aws"):                4,
+				addrs.NewProvider("example.com", "foo", "bar"): 1,
+			},
+			wantResources: map[string]int{
+				"aws_instance": 2,
+			},
+		},
+		"missing limit": {
+			args:    []string{"-resource-concurrency=aws_instance"},
+			wantErr: `Invalid resource concurrency limit "aws_instance"`,
+		},
+		"zero limit": {
+			args:    []string{"-provider-concurrency=hashicorp/aws=0"},
+			wantErr: `Invalid provider concurrency limit "hashicorp/aws=0"`,
+		},
+		"invalid provider": {
+			args:    []string{"-provider-concurrency=not a provider=1"},
+			wantErr: `Invalid provider concurrency limit "not a provider=1"`,
+		},
+		"invalid resource type": {
+			args:    []string{"-resource-concurrency=aws-instance.foo=1"},
+			wantErr: `Invalid resource concurrency limit "aws-instance.foo=1"`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ParsePlan(tc.args)
+			if len(diags) > 0 {
+				if tc.wantErr == "" {
+					t.Fatalf("unexpected diags: %v", diags)
+				} else if got := diags.Err().Error(); !strings.Contains(got, tc.wantErr) {
+					t.Fatalf("wrong diags\n got: %s\nwant: %s", got, tc.wantErr)
+				}
+				return
+			}
+			if tc.wantErr != "" {
+				t.Fatalf("succeeded; want error %q", tc.wantErr)
+			}
+			if !cmp.Equal(got.Operation.ProviderConcurrency, tc.wantProviders) {
+				t.Fatalf("unexpected provider limits\n%s", cmp.Diff(got.Operation.ProviderConcurrency, tc.wantProviders))
+			}
+			if !cmp.Equal(got.Operation.ResourceConcurrency, tc.wantResources) {
+				t.Fatalf("unexpected resource limits\n%s", cmp.Diff(got.Operation.ResourceConcurrency, tc.wantResources))
+			}
+		})
+	}
+}
+
+func TestParsePlan_providerRateLimit(t *testing.T) {
+	testCases := map[string]struct {
+		args    []string
+		want    map[addrs.Provider]float64
+		wantErr string
+	}{
+		"no limits by default": {
+			args: nil,
+		},
+		"rate limits": {
+			args: []string{
+				"-provider-rate-limit=hashicorp/aws=10",
+				"-provider-rate-limit", "example.com/foo/bar=0.5",
+			},
+			want: map[addrs.Provider]float64{
+				addrs.NewDefaultProvider("aws"):                10,
+				addrs.NewProvider("example.com", "foo", "bar"): 0.5,
+			},
+		},
+		"missing limit": {
+			args:    []string{"-provider-rate-limit=hashicorp/aws"},
+			wantErr: `Invalid provider rate limit "hashicorp/aws"`,
+		},
+		"zero limit": {
+			args:    []string{"-provider-rate-limit=hashicorp/aws=0"},
+			wantErr: `Invalid provider rate limit "hashicorp/aws=0"`,
+		},
+		"invalid provider": {
+			args:    []string{"-provider-rate-limit=not a provider=1"},
+			wantErr: `Invalid provider rate limit "not a provider=1"`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ParsePlan(tc.args)
+			if len(diags) > 0 {
+				if tc.wantErr == "" {
+					t.Fatalf("unexpected diags: %v", diags)
+				} else if got := diags.Err().Error(); !strings.Contains(got, tc.wantErr) {
+					t.Fatalf("wrong diags\n got: %s\nwant: %s", got, tc.wantErr)
+				}
+				return
+			}
+			if tc.wantErr != "" {
+				t.Fatalf("succeeded; want error %q", tc.wantErr)
+			}
+			if !cmp.Equal(got.Operation.ProviderRateLimits, tc.want) {
+				t.Fatalf("unexpected provider rate limits\n%s", cmp.Diff(got.Operation.ProviderRateLimits, tc.want))
+			}
+		})
+	}
+}
+
+func TestParsePlan_refreshParallelism(t *testing.T) {
+	testCases := map[string]struct {
+		args          []string
+		wantDefault   int
+		wantProviders map[addrs.Provider]int
+		wantErr       string
+	}{
+		"no limits by default": {
+			args: nil,
+		},
+		"default and provider limits": {
+			args: []string{
+				"-refresh-parallelism=20",
+				"-refresh-parallelism", "hashicorp/aws=4",
+			},
+			wantDefault: 20,
+			wantProviders: map[addrs.Provider]int{
+				addrs.NewDefaultProvider("aws"): 4,
+			},
+		},
+		"zero limit": {
+			args:    []string{"-refresh-parallelism=0"},
+			wantErr: `Invalid refresh parallelism "0"`,
+		},
+		"invalid provider": {
+			args:    []string{"-refresh-parallelism=not a provider=1"},
+			wantErr: `Invalid refresh parallelism "not a provider=1"`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ParsePlan(tc.args)
+			if len(diags) > 0 {
+				if tc.wantErr == "" {
+					t.Fatalf("unexpected diags: %v", diags)
+				} else if got := diags.Err().Error(); !strings.Contains(got, tc.wantErr) {
+					t.Fatalf("wrong diags\n got: %s\nwant: %s", got, tc.wantErr)
+				}
+				return
+			}
+			if tc.wantErr != "" {
+				t.Fatalf("succeeded; want error %q", tc.wantErr)
+			}
+			if got.Operation.RefreshParallelism != tc.wantDefault {
+				t.Fatalf("wrong default limit %d; want %d", got.Operation.RefreshParallelism, tc.wantDefault)
+			}
+			if !cmp.Equal(got.Operation.ProviderRefreshParallelism, tc.wantProviders) {
+				t.Fatalf("unexpected provider limits\n%s", cmp.Diff(got.Operation.ProviderRefreshParallelism, tc.wantProviders))
+			}
+		})
+	}
+}
+
+func TestParsePlan_stage(t *testing.T) {
+	testCases := map[string]struct {
+		args    []string
+		want    addrs.Module
+		wantErr string
+	}{
+		"root by default": {
+			args: nil,
+			want: addrs.RootModule,
+		},
+		"module call": {
+			args: []string{"-stage=module.network"},
+			want: addrs.Module{"network"},
+		},
+		"nested module call": {
+			args: []string{"-stage", "module.network.module.subnets"},
+			want: addrs.Module{"network", "subnets"},
+		},
+		"module instance": {
+			args:    []string{"-stage=module.network[0]"},
+			wantErr: `Invalid stage "module.network[0]"`,
+		},
+		"resource": {
+			args:    []string{"-stage=aws_instance.foo"},
+			wantErr: `Invalid stage "aws_instance.foo"`,
+		},
+		"with target": {
+			args:    []string{"-stage=module.network", "-target=aws_instance.foo"},
+			wantErr: "Incompatible stage and target options",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ParsePlan(tc.args)
+			if len(diags) > 0 {
+				if tc.wantErr == "" {
+					t.Fatalf("unexpected diags: %v", diags)
+				} else if got := diags.Err().Error(); !strings.Contains(got, tc.wantErr) {
+					t.Fatalf("wrong diags\n got: %s\nwant: %s", got, tc.wantErr)
+				}
+				return
+			}
+			if tc.wantErr != "" {
+				t.Fatalf("succeeded; want error %q", tc.wantErr)
+			}
+			if !got.Operation.Stage.Equal(tc.want) {
+				t.Fatalf("wrong stage %s; want %s", got.Operation.Stage, tc.want)
+			}
+		})
+	}
+}
+
+func TestParsePlan_driftSelection(t *testing.T) {
+	testCases := map[string]struct {
+		args       []string
+		wantAccept []string
+		wantReject []string
+		wantErr    string
+	}{
+		"none": {
+			args: []string{"-refresh-only"},
+		},
+		"resources and modules": {
+			args:       []string{"-refresh-only", "-accept-drift=aws_instance.foo", "-accept-drift=module.network", "-reject-drift=aws_instance.bar[0]"},
+			wantAccept: []string{"aws_instance.foo", "module.network"},
+			wantReject: []string{"aws_instance.bar[0]"},
+		},
+		"attributes": {
+			args:       []string{"-refresh-only", "-accept-drift=aws_instance.foo.tags", `-reject-drift=module.network.aws_vpc.main["a"].tags["Name"]`},
+			wantAccept: []string{"aws_instance.foo.tags"},
+			wantReject: []string{`module.network.aws_vpc.main["a"].tags["Name"]`},
+		},
+		"attribute of module": {
+			args:    []string{"-refresh-only", "-accept-drift=module.network.name"},
+			wantErr: `Invalid -accept-drift address "module.network.name"`,
+		},
+		"without refresh-only": {
+			args:    []string{"-reject-drift=aws_instance.foo"},
+			wantErr: "Incompatible drift selection options",
+		},
+	}
+
+	selectors := func(sels []plans.DriftSelector) []string {
+		var ret []string
+		for _, sel := range sels {
+			ret = append(ret, sel.Target.String()+tfdiags.FormatCtyPath(sel.Path))
+		}
+		return ret
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ParsePlan(tc.args)
+			if len(diags) > 0 {
+				if tc.wantErr == "" {
+					t.Fatalf("unexpected diags: %v", diags)
+				} else if got := diags.Err().Error(); !strings.Contains(got, tc.wantErr) {
+					t.Fatalf("wrong diags\n got: %s\nwant: %s", got, tc.wantErr)
+				}
+				return
+			}
+			if tc.wantErr != "" {
+				t.Fatalf("succeeded; want error %q", tc.wantErr)
+			}
+			if tc.wantAccept == nil && tc.wantReject == nil {
+				if got.Operation.DriftSelection != nil {
+					t.Fatalf("unexpected drift selection %#v", got.Operation.DriftSelection)
+				}
+				return
+			}
+			if diff := cmp.Diff(tc.wantAccept, selectors(got.Operation.DriftSelection.Accept)); diff != "" {
+				t.Errorf("wrong accepted drift\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantReject, selectors(got.Operation.DriftSelection.Reject)); diff != "" {
+				t.Errorf("wrong rejected drift\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParsePlan_failOn(t *testing.T) {
+	testCases := map[string]struct {
+		args    []string
+		want    []plans.Action
+		wantErr string
+	}{
+		"none by default": {
+			args: nil,
+			want: nil,
+		},
+		"single class": {
+			args: []string{"-fail-on=destroy"},
+			want: []plans.Action{plans.Delete},
+		},
+		"several classes": {
+			args: []string{"-fail-on=create, replace", "-fail-on=replace"},
+			want: []plans.Action{plans.Create, plans.DeleteThenCreate, plans.CreateThenDelete},
+		},
+		"invalid class": {
+			args:    []string{"-fail-on=update,nope"},
+			want:    []plans.Action{plans.Update},
+			wantErr: `Unsupported change class "nope"`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ParsePlan(tc.args)
+			if len(diags) > 0 {
+				if tc.wantErr == "" {
+					t.Fatalf("unexpected diags: %v", diags)
+				} else if got := diags.Err().Error(); !strings.Contains(got, tc.wantErr) {
+					t.Fatalf("wrong diags\n got: %s\nwant: %s", got, tc.wantErr)
+				}
+			} else if tc.wantErr != "" {
+				t.Fatalf("succeeded; want error %q", tc.wantErr)
+			}
+			if !cmp.Equal(got.FailOn, tc.want) {
+				t.Fatalf("unexpected result\n%s", cmp.Diff(got.FailOn, tc.want))
+			}
+		})
+	}
+}
+
+func TestParsePlan_summary(t *testing.T) {
+	testCases := map[string]struct {
+		args       []string
+		wantModule bool
+		wantExpand []addrs.Module
+		wantErr    string
+	}{
+		"full plan by default": {
+			args: nil,
+		},
+		"module summary": {
+			args:       []string{"-summary=module"},
+			wantModule: true,
+		},
+		"module summary with expanded modules": {
+			args:       []string{"-summary=module", "-expand=module.network[0]", "-expand", "module.db.module.replica"},
+			wantModule: true,
+			wantExpand: []addrs.Module{{"network"}, {"db", "replica"}},
+		},
+		"unsupported mode": {
+			args:    []string{"-summary=resource"},
+			wantErr: `Unsupported summary mode "resource"`,
+		},
+		"invalid module address": {
+			args:       []string{"-summary=module", "-expand=aws_instance.foo"},
+			wantModule: true,
+			wantErr:    `Invalid module address "aws_instance.foo"`,
+		},
+		"expand without summary": {
+			args:       []string{"-expand=module.db"},
+			wantExpand: []addrs.Module{{"db"}},
+			wantErr:    "The -expand option can only be used with -summary=module.",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ParsePlan(tc.args)
+			if len(diags) > 0 {
+				if tc.wantErr == "" {
+					t.Fatalf("unexpected diags: %v", diags)
+				} else if got := diags.Err().Error(); !strings.Contains(got, tc.wantErr) {
+					t.Fatalf("wrong diags\n got: %s\nwant: %s", got, tc.wantErr)
+				}
+			} else if tc.wantErr != "" {
+				t.Fatalf("succeeded; want error %q", tc.wantErr)
+			}
+			if got.Summary.ByModule != tc.wantModule {
+				t.Errorf("wrong ByModule %t; want %t", got.Summary.ByModule, tc.wantModule)
+			}
+			if !cmp.Equal(got.Summary.ExpandModules, tc.wantExpand) {
+				t.Errorf("unexpected expanded modules\n%s", cmp.Diff(got.Summary.ExpandModules, tc.wantExpand))
+			}
+		})
+	}
+}
+
 func TestParsePlan_vars(t *testing.T) {
 	testCases := map[string]struct {
 		args []string
@@ -208,3 +683,195 @@ func TestParsePlan_vars(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePlan_stateSource(t *testing.T) {
+	testCases := map[string]struct {
+		args    []string
+		want    *StateSource
+		wantErr string
+	}{
+		"none by default": {
+			args: nil,
+			want: nil,
+		},
+		"workspace": {
+			args: []string{"-state-source=workspace:prod"},
+			want: &StateSource{Type: StateSourceWorkspace, Value: "prod"},
+		},
+		"file with colon in path": {
+			args: []string{"-state-source=file:C:/snapshots/prod.tfstate"},
+			want: &StateSource{Type: StateSourceFile, Value: "C:/snapshots/prod.tfstate"},
+		},
+		"backend config": {
+			args: []string{"-state-source=backend-config:prod.tfbackend"},
+			want: &StateSource{Type: StateSourceBackendConfig, Value: "prod.tfbackend"},
+		},
+		"missing value": {
+			args:    []string{"-state-source=workspace"},
+			wantErr: `"workspace" is not a valid state source`,
+		},
+		"unsupported type": {
+			args:    []string{"-state-source=url:https://example.com"},
+			wantErr: `Unsupported state source type "url"`,
+		},
+		"with out": {
+			args:    []string{"-state-source=workspace:prod", "-out=tfplan"},
+			want:    &StateSource{Type: StateSourceWorkspace, Value: "prod"},
+			wantErr: "cannot be saved with -out",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ParsePlan(tc.args)
+			if len(diags) > 0 {
+				if tc.wantErr == "" {
+					t.Fatalf("unexpected diags: %v", diags)
+				} else if got := diags.Err().Error(); !strings.Contains(got, tc.wantErr) {
+					t.Fatalf("wrong diags\n got: %s\nwant: %s", got, tc.wantErr)
+				}
+			} else if tc.wantErr != "" {
+				t.Fatalf("succeeded; want error %q", tc.wantErr)
+			}
+			if !cmp.Equal(got.StateSource, tc.want) {
+				t.Fatalf("unexpected result\n%s", cmp.Diff(got.StateSource, tc.want))
+			}
+		})
+	}
+}
+
+func TestParsePlan_watch(t *testing.T) {
+	testCases := map[string]struct {
+		args    []string
+		wantErr string
+	}{
+		"watch": {
+			args: []string{"-watch"},
+		},
+		"with out": {
+			args:    []string{"-watch", "-out=tfplan"},
+			wantErr: "cannot be used with -out",
+		},
+		"with detailed exit code and fail-on": {
+			args:    []string{"-watch", "-detailed-exitcode", "-fail-on=destroy"},
+			wantErr: "cannot be used with -detailed-exitcode, -fail-on",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ParsePlan(tc.args)
+			if len(diags) > 0 {
+				if tc.wantErr == "" {
+					t.Fatalf("unexpected diags: %v", diags)
+				} else if got := diags.Err().Error(); !strings.Contains(got, tc.wantErr) {
+					t.Fatalf("wrong diags\n got: %s\nwant: %s", got, tc.wantErr)
+				}
+			} else if tc.wantErr != "" {
+				t.Fatalf("succeeded; want error %q", tc.wantErr)
+			}
+			if !got.Watch {
+				t.Fatal("expected Watch to be set")
+			}
+			if got.InputEnabled {
+				t.Fatal("expected input to be disabled in watch mode")
+			}
+		})
+	}
+}
+
+func TestParsePlan_refine(t *testing.T) {
+	testCases := map[string]struct {
+		args    []string
+		wantErr string
+	}{
+		"refine": {
+			args: []string{"-refine=tfplan", "-out=tfplan"},
+		},
+		"destroy mode": {
+			args:    []string{"-refine=tfplan", "-destroy"},
+			wantErr: "only in the normal planning mode",
+		},
+		"with state source": {
+			args:    []string{"-refine=tfplan", "-state-source=workspace:prod"},
+			wantErr: "cannot be refined with -state-source",
+		},
+		"with watch": {
+			args:    []string{"-refine=tfplan", "-watch"},
+			wantErr: "cannot be used with -refine",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ParsePlan(tc.args)
+			if len(diags) > 0 {
+				if tc.wantErr == "" {
+					t.Fatalf("unexpected diags: %v", diags)
+				} else if got := diags.Err().Error(); !strings.Contains(got, tc.wantErr) {
+					t.Fatalf("wrong diags\n got: %s\nwant: %s", got, tc.wantErr)
+				}
+			} else if tc.wantErr != "" {
+				t.Fatalf("succeeded; want error %q", tc.wantErr)
+			}
+			if got, want := got.RefinePath, "tfplan"; got != want {
+				t.Fatalf("wrong refine path %q; want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestParsePlan_generateImports(t *testing.T) {
+	testCases := map[string]struct {
+		args    []string
+		wantErr string
+	}{
+		"generate imports": {
+			args: []string{"-generate-imports", "-generate-config-out=generated.tf"},
+		},
+		"without generate-config-out": {
+			args:    []string{"-generate-imports"},
+			wantErr: "requires -generate-config-out",
+		},
+		"destroy mode": {
+			args:    []string{"-generate-imports", "-generate-config-out=generated.tf", "-destroy"},
+			wantErr: "only be generated during a normal plan operation",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, diags := ParsePlan(tc.args)
+			if len(diags) > 0 {
+				if tc.wantErr == "" {
+					t.Fatalf("unexpected diags: %v", diags)
+				} else if got := diags.Err().Error(); !strings.Contains(got, tc.wantErr) {
+					t.Fatalf("wrong diags\n got: %s\nwant: %s", got, tc.wantErr)
+				}
+			} else if tc.wantErr != "" {
+				t.Fatalf("succeeded; want error %q", tc.wantErr)
+			}
+			if !got.GenerateImports {
+				t.Fatal("GenerateImports should be set")
+			}
+		})
+	}
+}
+
+func TestParsePlan_blastRadius(t *testing.T) {
+	got, diags := ParsePlan([]string{"-destroy", "-blast-radius"})
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diags: %v", diags)
+	}
+	if !got.BlastRadius {
+		t.Fatal("BlastRadius should be set")
+	}
+
+	_, diags = ParsePlan([]string{"-blast-radius"})
+	if len(diags) == 0 {
+		t.Fatal("succeeded; want error")
+	}
+	if got, want := diags.Err().Error(), "can only be used together with -destroy"; !strings.Contains(got, want) {
+		t.Fatalf("wrong diags\n got: %s\nwant: %s", got, want)
+	}
+}
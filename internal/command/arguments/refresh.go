@@ -34,7 +34,7 @@ func ParseRefresh(args []string) (*Refresh, tfdiags.Diagnostics) {
 	}
 
 	cmdFlags := extendedFlagSet("refresh", refresh.State, refresh.Operation, refresh.Vars)
-	cmdFlags.BoolVar(&refresh.InputEnabled, "input", true, "input")
+	cmdFlags.BoolVar(&refresh.InputEnabled, "input", InputEnabledDefault(), "input")
 
 	var json bool
 	cmdFlags.BoolVar(&json, "json", false, "json")
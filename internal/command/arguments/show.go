@@ -4,6 +4,13 @@
 package arguments
 
 import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
@@ -15,6 +22,104 @@ type Show struct {
 
 	// ViewType specifies which output format to use: human, JSON, or "raw".
 	ViewType ViewType
+
+	// Summary controls how the proposed changes in a plan file are
+	// presented in human-readable output.
+	Summary *PlanSummary
+
+	// Filter selects the resource instances to show.
+	Filter *ShowFilter
+}
+
+// ShowFilter represents the options of the show command which select a
+// subset of the resource instances in a state or plan to show.
+//
+// A resource instance is shown if it matches at least one of the given
+// values for each kind of filter that is set.
+type ShowFilter struct {
+	// Targets are resource and module addresses, as for the -target option
+	// of the plan command.
+	Targets []addrs.Targetable
+
+	// Modules are module paths, such as module.network, which match the
+	// resource instances in all instances of the module and of its
+	// descendant modules.
+	Modules []addrs.Module
+
+	// Actions are the change actions to show, when showing a plan.
+	Actions []plans.Action
+
+	targetsRaw []string
+	modulesRaw []string
+	onlyRaw    []string
+}
+
+// showOnlyActions maps the change classes accepted by the -only option to the
+// plan actions that belong to each.
+var showOnlyActions = map[string][]plans.Action{
+	"create":  {plans.Create},
+	"update":  {plans.Update},
+	"replace": {plans.DeleteThenCreate, plans.CreateThenDelete},
+	"destroy": {plans.Delete},
+	"read":    {plans.Read},
+	"no-op":   {plans.NoOp},
+}
+
+// Empty returns true if the filter shows everything.
+func (f *ShowFilter) Empty() bool {
+	return f == nil || (len(f.Targets) == 0 && len(f.Modules) == 0 && len(f.Actions) == 0)
+}
+
+// Parse must be called on ShowFilter after initial flag parse. This
+// processes the raw flags into the exported fields, returning diagnostics if
+// they are invalid.
+func (f *ShowFilter) Parse() tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	f.Targets = nil
+	for _, raw := range f.targetsRaw {
+		traversal, syntaxDiags := hclsyntax.ParseTraversalAbs([]byte(raw), "", hcl.Pos{Line: 1, Column: 1})
+		if syntaxDiags.HasErrors() {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				fmt.Sprintf("Invalid target %q", raw),
+				syntaxDiags[0].Detail,
+			))
+			continue
+		}
+
+		target, targetDiags := addrs.ParseTarget(traversal)
+		if targetDiags.HasErrors() {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				fmt.Sprintf("Invalid target %q", raw),
+				targetDiags[0].Description().Detail,
+			))
+			continue
+		}
+
+		f.Targets = append(f.Targets, target.Subject)
+	}
+
+	f.Modules = nil
+	for _, raw := range f.modulesRaw {
+		addr, addrDiags := addrs.ParseModuleInstanceStr(raw)
+		if addrDiags.HasErrors() || addr.IsRoot() {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				fmt.Sprintf("Invalid module address %q", raw),
+				"The -module option requires the address of a module call, such as module.example.",
+			))
+			continue
+		}
+		f.Modules = append(f.Modules, addr.Module())
+	}
+
+	actions, actionsDiags := parseChangeClasses("-only", f.onlyRaw, showOnlyActions, "create, update, replace, destroy, read, no-op")
+	diags = diags.Append(actionsDiags)
+	f.Actions = actions
+
+	return diags
 }
 
 // ParseShow processes CLI arguments, returning a Show value and errors.
@@ -23,12 +128,18 @@ type Show struct {
 func ParseShow(args []string) (*Show, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 	show := &Show{
-		Path: "",
+		Path:    "",
+		Summary: &PlanSummary{},
+		Filter:  &ShowFilter{},
 	}
 
 	var jsonOutput bool
 	cmdFlags := defaultFlagSet("show")
 	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
+	show.Summary.addFlags(cmdFlags)
+	cmdFlags.Var((*flagStringSlice)(&show.Filter.targetsRaw), "target", "target")
+	cmdFlags.Var((*flagStringSlice)(&show.Filter.modulesRaw), "module", "module")
+	cmdFlags.Var((*flagStringSlice)(&show.Filter.onlyRaw), "only", "only")
 
 	if err := cmdFlags.Parse(args); err != nil {
 		diags = diags.Append(tfdiags.Sourceless(
@@ -51,6 +162,9 @@ func ParseShow(args []string) (*Show, tfdiags.Diagnostics) {
 		show.Path = args[0]
 	}
 
+	diags = diags.Append(show.Summary.Parse())
+	diags = diags.Append(show.Filter.Parse())
+
 	switch {
 	case jsonOutput:
 		show.ViewType = ViewJSON
@@ -8,6 +8,9 @@ import (
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
@@ -21,6 +24,8 @@ func TestParseShow_valid(t *testing.T) {
 			&Show{
 				Path:     "",
 				ViewType: ViewHuman,
+				Summary:  &PlanSummary{},
+				Filter:   &ShowFilter{},
 			},
 		},
 		"json": {
@@ -28,6 +33,8 @@ func TestParseShow_valid(t *testing.T) {
 			&Show{
 				Path:     "",
 				ViewType: ViewJSON,
+				Summary:  &PlanSummary{},
+				Filter:   &ShowFilter{},
 			},
 		},
 		"path": {
@@ -35,6 +42,8 @@ func TestParseShow_valid(t *testing.T) {
 			&Show{
 				Path:     "foo",
 				ViewType: ViewJSON,
+				Summary:  &PlanSummary{},
+				Filter:   &ShowFilter{},
 			},
 		},
 	}
@@ -45,7 +54,7 @@ func TestParseShow_valid(t *testing.T) {
 			if len(diags) > 0 {
 				t.Fatalf("unexpected diags: %v", diags)
 			}
-			if *got != *tc.want {
+			if !reflect.DeepEqual(got, tc.want) {
 				t.Fatalf("unexpected result\n got: %#v\nwant: %#v", got, tc.want)
 			}
 		})
@@ -63,6 +72,8 @@ func TestParseShow_invalid(t *testing.T) {
 			&Show{
 				Path:     "",
 				ViewType: ViewHuman,
+				Summary:  &PlanSummary{},
+				Filter:   &ShowFilter{},
 			},
 			tfdiags.Diagnostics{
 				tfdiags.Sourceless(
@@ -77,6 +88,8 @@ func TestParseShow_invalid(t *testing.T) {
 			&Show{
 				Path:     "bar",
 				ViewType: ViewJSON,
+				Summary:  &PlanSummary{},
+				Filter:   &ShowFilter{},
 			},
 			tfdiags.Diagnostics{
 				tfdiags.Sourceless(
@@ -91,7 +104,7 @@ func TestParseShow_invalid(t *testing.T) {
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
 			got, gotDiags := ParseShow(tc.args)
-			if *got != *tc.want {
+			if !reflect.DeepEqual(got, tc.want) {
 				t.Fatalf("unexpected result\n got: %#v\nwant: %#v", got, tc.want)
 			}
 			if !reflect.DeepEqual(gotDiags, tc.wantDiags) {
@@ -100,3 +113,72 @@ func TestParseShow_invalid(t *testing.T) {
 		})
 	}
 }
+
+func TestParseShow_filter(t *testing.T) {
+	got, diags := ParseShow([]string{
+		"-target=aws_instance.web",
+		"-target=module.db",
+		"-module=module.network[0].module.subnets",
+		"-only=create,destroy",
+		"-only=replace",
+		"foo",
+	})
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diags: %v", diags)
+	}
+
+	wantTargets := []string{"aws_instance.web", "module.db"}
+	if len(got.Filter.Targets) != len(wantTargets) {
+		t.Fatalf("wrong targets %s", got.Filter.Targets)
+	}
+	for i, want := range wantTargets {
+		if got := got.Filter.Targets[i].String(); got != want {
+			t.Errorf("wrong target %d %q; want %q", i, got, want)
+		}
+	}
+
+	wantModules := []addrs.Module{{"network", "subnets"}}
+	if !reflect.DeepEqual(got.Filter.Modules, wantModules) {
+		t.Errorf("wrong modules %#v; want %#v", got.Filter.Modules, wantModules)
+	}
+
+	wantActions := []plans.Action{plans.Create, plans.Delete, plans.DeleteThenCreate, plans.CreateThenDelete}
+	if !reflect.DeepEqual(got.Filter.Actions, wantActions) {
+		t.Errorf("wrong actions %#v; want %#v", got.Filter.Actions, wantActions)
+	}
+	if got.Filter.Empty() {
+		t.Errorf("filter should not be empty")
+	}
+}
+
+func TestParseShow_filterInvalid(t *testing.T) {
+	testCases := map[string]struct {
+		args        []string
+		wantSummary string
+	}{
+		"invalid target": {
+			[]string{"-target=aws_instance"},
+			`Invalid target "aws_instance"`,
+		},
+		"invalid module": {
+			[]string{"-module=aws_instance.web"},
+			`Invalid module address "aws_instance.web"`,
+		},
+		"invalid change": {
+			[]string{"-only=create,rename"},
+			"Invalid -only value",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, diags := ParseShow(tc.args)
+			if len(diags) != 1 {
+				t.Fatalf("wrong number of diagnostics %d: %s", len(diags), diags.Err())
+			}
+			if got := diags[0].Description().Summary; got != tc.wantSummary {
+				t.Errorf("wrong summary %q; want %q", got, tc.wantSummary)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package arguments
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// StateSourceType is the kind of alternate state a speculative plan can be
+// created against.
+type StateSourceType string
+
+const (
+	// StateSourceWorkspace selects the state of another workspace in the
+	// currently-configured backend.
+	StateSourceWorkspace StateSourceType = "workspace"
+
+	// StateSourceFile selects a local state snapshot file.
+	StateSourceFile StateSourceType = "file"
+
+	// StateSourceBackendConfig selects the state of the current workspace
+	// in the backend described by the current backend configuration with
+	// the settings from a partial backend configuration file merged in.
+	StateSourceBackendConfig StateSourceType = "backend-config"
+)
+
+// StateSource describes an alternate source for the prior state of a plan,
+// given using the -state-source option.
+type StateSource struct {
+	Type StateSourceType
+
+	// Value is the workspace name or file path, depending on Type.
+	Value string
+}
+
+// parseStateSource decodes the raw value of the -state-source option, which
+// has the form TYPE:VALUE.
+func parseStateSource(raw string) (*StateSource, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	typ, value, ok := strings.Cut(raw, ":")
+	if !ok || value == "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid -state-source value",
+			fmt.Sprintf("The value %q is not a valid state source. Use workspace:NAME, file:PATH, or backend-config:PATH.", raw),
+		))
+		return nil, diags
+	}
+
+	switch t := StateSourceType(typ); t {
+	case StateSourceWorkspace, StateSourceFile, StateSourceBackendConfig:
+		return &StateSource{Type: t, Value: value}, diags
+	default:
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid -state-source value",
+			fmt.Sprintf("Unsupported state source type %q. The supported types are workspace, file, and backend-config.", typ),
+		))
+		return nil, diags
+	}
+}
@@ -13,6 +13,12 @@ const (
 	ViewHuman ViewType = 'H'
 	ViewJSON  ViewType = 'J'
 	ViewRaw   ViewType = 'R'
+
+	// The following view types are supported only by the output command,
+	// for producing files that other tools can consume directly.
+	ViewDotenv ViewType = 'D'
+	ViewShell  ViewType = 'S'
+	ViewYAML   ViewType = 'Y'
 )
 
 func (vt ViewType) String() string {
@@ -25,6 +31,12 @@ func (vt ViewType) String() string {
 		return "json"
 	case ViewRaw:
 		return "raw"
+	case ViewDotenv:
+		return "dotenv"
+	case ViewShell:
+		return "shell"
+	case ViewYAML:
+		return "yaml"
 	default:
 		return "unknown"
 	}
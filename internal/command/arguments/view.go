@@ -13,6 +13,10 @@ type View struct {
 	// level of noise when multiple instances of the same warning are raised
 	// for a configuration.
 	CompactWarnings bool
+
+	// Explain is used to include detailed remediation steps in the hints
+	// shown for recognized error diagnostics.
+	Explain bool
 }
 
 // ParseView processes CLI arguments, returning a View value and a
@@ -30,6 +34,8 @@ func ParseView(args []string) (*View, []string) {
 			common.NoColor = true
 		case "-compact-warnings":
 			common.CompactWarnings = true
+		case "-explain":
+			common.Explain = true
 		default:
 			// Unsupported argument: move left to the current position, and
 			// increment the index.
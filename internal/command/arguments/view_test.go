@@ -45,6 +45,11 @@ func TestParseView(t *testing.T) {
 			&View{NoColor: true, CompactWarnings: true},
 			[]string{"-foo", "-baz"},
 		},
+		"explain": {
+			[]string{"-foo", "-explain", "-baz"},
+			&View{Explain: true},
+			[]string{"-foo", "-baz"},
+		},
 		"both, resulting in empty args": {
 			[]string{"-no-color", "-compact-warnings"},
 			&View{NoColor: true, CompactWarnings: true},
@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// BundleCommand is a Command implementation that just shows help for
+// the subcommands nested below it.
+type BundleCommand struct {
+	Meta
+}
+
+func (c *BundleCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+func (c *BundleCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] bundle <subcommand> [options] [args]
+
+  This command has subcommands for creating and verifying dependency bundles.
+
+  A dependency bundle is a single archive containing the provider packages,
+  remote module packages, and dependency lock file that a configuration
+  needs, which "tofu init -from-bundle" can install from on a network
+  without access to provider and module registries.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *BundleCommand) Synopsis() string {
+	return "Create and verify dependency bundles"
+}
+
+// extractDependencyBundle verifies the dependency bundle in the given file
+// and extracts it into a new temporary directory, which the caller must
+// remove. The directory has the same layout as a vendor directory.
+//
+// If the current working directory has no dependency lock file, the bundle's
+// lock file is copied there, so that the bundled providers are selected.
+func (m *Meta) extractDependencyBundle(filename string, trustedKeyFiles []string) (string, tfdiags.Diagnostics) {
+	r, signer, diags := openDependencyBundle(filename, trustedKeyFiles)
+	if diags.HasErrors() {
+		return "", diags
+	}
+	defer r.Close()
+	if signer != nil {
+		m.Ui.Output(fmt.Sprintf("The dependency bundle is signed by %s.", bundleSignerString(signer)))
+	}
+
+	dir, err := os.MkdirTemp("", "tofu-bundle")
+	if err != nil {
+		return "", diags.Append(fmt.Errorf("failed to create a temporary directory: %w", err))
+	}
+	if err := r.ExtractTo(dir); err != nil {
+		os.RemoveAll(dir)
+		return "", diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid dependency bundle",
+			fmt.Sprintf("Could not extract the dependency bundle %s: %s.", filename, err),
+		))
+	}
+
+	lockSrc, err := os.ReadFile(filepath.Join(dir, dependencyLockFilename))
+	if err == nil {
+		if _, statErr := os.Stat(dependencyLockFilename); os.IsNotExist(statErr) {
+			err = os.WriteFile(dependencyLockFilename, lockSrc, 0644)
+		}
+	} else if os.IsNotExist(err) {
+		err = nil
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", diags.Append(fmt.Errorf("failed to install the dependency lock file from the bundle: %w", err))
+	}
+	return dir, diags
+}
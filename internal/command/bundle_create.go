@@ -0,0 +1,152 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+
+	"github.com/opentofu/opentofu/internal/depsbundle"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// BundleCreateCommand is a Command implementation that creates a dependency
+// bundle for the configuration in the current working directory.
+type BundleCreateCommand struct {
+	Meta
+}
+
+func (c *BundleCreateCommand) Synopsis() string {
+	return "Create a dependency bundle for the configuration"
+}
+
+func (c *BundleCreateCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("bundle create")
+	var optPlatforms FlagStringSlice
+	var optSigningKey string
+	cmdFlags.Var(&optPlatforms, "platform", "target platform")
+	cmdFlags.StringVar(&optSigningKey, "signing-key", "", "signing key file")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	args = cmdFlags.Args()
+	if len(args) != 1 {
+		c.Ui.Error("The bundle create command expects the filename of the bundle to create.\n")
+		cmdFlags.Usage()
+		return 1
+	}
+	filename := args[0]
+
+	platforms, diags := parseVendorPlatforms(optPlatforms)
+	var signer *openpgp.Entity
+	if optSigningKey != "" {
+		keyring, err := depsbundle.ReadKeyRing(optSigningKey)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid signing key",
+				fmt.Sprintf("Failed to read the signing key given in the -signing-key option: %s.", err),
+			))
+		} else {
+			for _, entity := range keyring {
+				if entity.PrivateKey != nil {
+					signer = entity
+					break
+				}
+			}
+			if signer == nil {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid signing key",
+					fmt.Sprintf("The file %s given in the -signing-key option doesn't contain a private key.", optSigningKey),
+				))
+			}
+		}
+	}
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Creating the bundle can be cancelled by SIGINT and similar.
+	ctx, done := c.InterruptibleContext(c.CommandContext())
+	defer done()
+
+	// The bundle contains the same files as a vendor directory, along with
+	// the dependency lock file.
+	dir, err := os.MkdirTemp("", "tofu-bundle")
+	if err != nil {
+		diags = diags.Append(fmt.Errorf("failed to create a temporary directory: %w", err))
+		c.showDiagnostics(diags)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+
+	diags = diags.Append(c.vendorDependencies(ctx, dir, platforms))
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+	lockSrc, err := os.ReadFile(dependencyLockFilename)
+	switch {
+	case err == nil:
+		err = os.WriteFile(filepath.Join(dir, dependencyLockFilename), lockSrc, 0644)
+	case os.IsNotExist(err):
+		// A configuration without providers has no lock file.
+		err = nil
+	}
+	if err != nil {
+		diags = diags.Append(fmt.Errorf("failed to add the dependency lock file to the bundle: %w", err))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	if err := depsbundle.Create(filename, dir, signer); err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to create bundle",
+			fmt.Sprintf("Could not create the bundle %s: %s.", filename, err),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	c.showDiagnostics(diags)
+	if signer != nil {
+		c.Ui.Output(fmt.Sprintf("\nCreated the dependency bundle %s, signed with key %s.", filename, signer.PrimaryKey.KeyIdString()))
+	} else {
+		c.Ui.Output(fmt.Sprintf("\nCreated the unsigned dependency bundle %s.", filename))
+	}
+	return 0
+}
+
+func (c *BundleCreateCommand) Help() string {
+	return `
+Usage: tofu [global options] bundle create [options] FILE
+
+  Creates a dependency bundle containing the provider packages selected in
+  the dependency lock file, the remote module packages installed by
+  "tofu init", and the dependency lock file itself, so that
+  "tofu init -from-bundle=FILE" can initialize the configuration on a
+  network without access to provider and module registries.
+
+Options:
+
+  -platform=os_arch   Choose which target platform to include provider
+                      packages for. By default OpenTofu will include the
+                      packages for the platform where you run this command.
+                      Use this flag multiple times to include packages for
+                      multiple target systems.
+
+  -signing-key=FILE   Sign the bundle with the ASCII-armored OpenPGP private
+                      key in the given file. The key must not be protected
+                      by a passphrase.
+`
+}
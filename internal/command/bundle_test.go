@@ -0,0 +1,144 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/mitchellh/cli"
+)
+
+func TestBundle_createVerifyAndInit(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("init-offline-module"), td)
+	defer testChdir(t, td)()
+
+	// Simulate a previous "tofu init" that installed the remote module.
+	manifest := `{"Modules":[{"Key":"","Source":"","Dir":"."},{"Key":"remote","Source":"registry.opentofu.org/hashicorp/module-installer-acctest/aws","Version":"0.0.1","Dir":".terraform/modules/remote"}]}`
+	if err := os.MkdirAll(filepath.Join(".terraform", "modules", "remote"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(".terraform", "modules", "modules.json"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(".terraform", "modules", "remote", "main.tf"), []byte(`output "bundled" { value = true }`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	keyDir := t.TempDir()
+	privateKey, publicKey := filepath.Join(keyDir, "private.asc"), filepath.Join(keyDir, "public.asc")
+	testBundleKeyFiles(t, privateKey, publicKey)
+	bundle := filepath.Join(t.TempDir(), "deps.zip")
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	cc := &BundleCreateCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+			View:             view,
+		},
+	}
+	if code := cc.Run([]string{"-signing-key=" + privateKey, bundle}); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	ui = new(cli.MockUi)
+	view, _ = testView(t)
+	vc := &BundleVerifyCommand{
+		Meta: Meta{
+			Ui:   ui,
+			View: view,
+		},
+	}
+	if code := vc.Run([]string{"-trusted-key=" + publicKey, bundle}); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+	if got := ui.OutputWriter.String(); !strings.Contains(got, `is valid and signed by "Test <test@example.com>"`) {
+		t.Errorf("wrong output:\n%s", got)
+	}
+
+	// With the installed modules removed, init must install the module from
+	// the bundle without using the network.
+	if err := os.RemoveAll(".terraform"); err != nil {
+		t.Fatal(err)
+	}
+	ui = new(cli.MockUi)
+	view, _ = testView(t)
+	ic := &InitCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+			View:             view,
+		},
+	}
+	if code := ic.Run([]string{"-backend=false", "-from-bundle=" + bundle, "-bundle-trusted-key=" + publicKey}); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+	src, err := os.ReadFile(filepath.Join(".terraform", "modules", "remote", "main.tf"))
+	if err != nil || !strings.Contains(string(src), "bundled") {
+		t.Fatalf("bundled module wasn't installed: %s", err)
+	}
+
+	// A bundle signed by an untrusted key is rejected.
+	otherPrivate, otherPublic := filepath.Join(keyDir, "other-private.asc"), filepath.Join(keyDir, "other-public.asc")
+	testBundleKeyFiles(t, otherPrivate, otherPublic)
+	ui = new(cli.MockUi)
+	view, _ = testView(t)
+	vc = &BundleVerifyCommand{
+		Meta: Meta{
+			Ui:   ui,
+			View: view,
+		},
+	}
+	if code := vc.Run([]string{"-trusted-key=" + otherPublic, bundle}); code == 0 {
+		t.Fatal("expected error")
+	}
+	if got := ui.ErrorWriter.String(); !strings.Contains(got, "not valid for any of the trusted keys") {
+		t.Errorf("wrong error:\n%s", got)
+	}
+}
+
+// testBundleKeyFiles generates an OpenPGP key and writes its private and
+// public keys to the given files.
+func testBundleKeyFiles(t *testing.T, privateFile, publicFile string) {
+	t.Helper()
+	entity, err := openpgp.NewEntity("Test", "", "test@example.com", &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []struct {
+		name      string
+		blockType string
+		private   bool
+	}{
+		{privateFile, openpgp.PrivateKeyType, true},
+		{publicFile, openpgp.PublicKeyType, false},
+	} {
+		f, err := os.Create(file.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := armor.Encode(f, file.blockType, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if file.private {
+			err = entity.SerializePrivate(w, nil)
+		} else {
+			err = entity.Serialize(w)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Close()
+		f.Close()
+	}
+}
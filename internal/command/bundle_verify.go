@@ -0,0 +1,134 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+
+	"github.com/opentofu/opentofu/internal/depsbundle"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// BundleVerifyCommand is a Command implementation that verifies the contents
+// and signature of a dependency bundle.
+type BundleVerifyCommand struct {
+	Meta
+}
+
+func (c *BundleVerifyCommand) Synopsis() string {
+	return "Verify a dependency bundle"
+}
+
+func (c *BundleVerifyCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("bundle verify")
+	var optTrustedKeys FlagStringSlice
+	cmdFlags.Var(&optTrustedKeys, "trusted-key", "trusted key file")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	args = cmdFlags.Args()
+	if len(args) != 1 {
+		c.Ui.Error("The bundle verify command expects the filename of the bundle to verify.\n")
+		cmdFlags.Usage()
+		return 1
+	}
+
+	r, signer, diags := openDependencyBundle(args[0], optTrustedKeys)
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+	defer r.Close()
+
+	c.showDiagnostics(diags)
+	switch {
+	case signer != nil:
+		c.Ui.Output(fmt.Sprintf("The dependency bundle %s is valid and signed by %s.", args[0], bundleSignerString(signer)))
+	case r.Signed():
+		c.Ui.Output(fmt.Sprintf("The dependency bundle %s is valid.", args[0]))
+	default:
+		c.Ui.Output(fmt.Sprintf("The dependency bundle %s is valid but unsigned.", args[0]))
+	}
+	return 0
+}
+
+// openDependencyBundle opens and verifies the dependency bundle in the given
+// file, using the trusted public keys in the given key files to verify its
+// signature. The caller must close the returned reader if there are no
+// errors.
+func openDependencyBundle(filename string, trustedKeyFiles []string) (*depsbundle.Reader, *openpgp.Entity, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	var keyring openpgp.EntityList
+	for _, keyFile := range trustedKeyFiles {
+		keys, err := depsbundle.ReadKeyRing(keyFile)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid trusted key",
+				fmt.Sprintf("Failed to read a trusted key for the bundle: %s.", err),
+			))
+			continue
+		}
+		keyring = append(keyring, keys...)
+	}
+	if diags.HasErrors() {
+		return nil, nil, diags
+	}
+
+	r, err := depsbundle.Open(filename)
+	if err != nil {
+		return nil, nil, diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid dependency bundle",
+			fmt.Sprintf("Could not read the dependency bundle %s: %s.", filename, err),
+		))
+	}
+	signer, err := r.Verify(keyring)
+	if err != nil {
+		r.Close()
+		return nil, nil, diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid dependency bundle",
+			fmt.Sprintf("The dependency bundle %s failed verification: %s.", filename, err),
+		))
+	}
+	if r.Signed() && signer == nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Bundle signature not verified",
+			fmt.Sprintf("The dependency bundle %s is signed, but its signature was not verified because no trusted keys were given.", filename),
+		))
+	}
+	return r, signer, diags
+}
+
+// bundleSignerString describes the key that signed a bundle.
+func bundleSignerString(entity *openpgp.Entity) string {
+	for name := range entity.Identities {
+		return fmt.Sprintf("%q (key ID %s)", name, entity.PrimaryKey.KeyIdString())
+	}
+	return fmt.Sprintf("key ID %s", entity.PrimaryKey.KeyIdString())
+}
+
+func (c *BundleVerifyCommand) Help() string {
+	return `
+Usage: tofu [global options] bundle verify [options] FILE
+
+  Verifies that a dependency bundle created by "tofu bundle create" contains
+  exactly the files recorded in its manifest, unmodified.
+
+Options:
+
+  -trusted-key=FILE   Also verify that the bundle is signed by one of the
+                      ASCII-armored OpenPGP public keys in the given file.
+                      Use this flag multiple times to trust keys in several
+                      files.
+`
+}
@@ -0,0 +1,50 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cliconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// bandwidthUnits are the units that a bandwidth can be given in, with the
+// number of bytes in each.
+var bandwidthUnits = map[string]int64{
+	"":    1,
+	"B":   1,
+	"KB":  1000,
+	"MB":  1000 * 1000,
+	"GB":  1000 * 1000 * 1000,
+	"KiB": 1 << 10,
+	"MiB": 1 << 20,
+	"GiB": 1 << 30,
+}
+
+// ParseBandwidth parses a bandwidth like "20MB/s" or "512KiB", returning
+// the number of bytes per second. The "/s" suffix is optional, and a number
+// without a unit is a number of bytes.
+func ParseBandwidth(s string) (int64, error) {
+	s = strings.TrimSuffix(strings.TrimSpace(s), "/s")
+	numEnd := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if numEnd == -1 {
+		numEnd = len(s)
+	}
+	num, unit := s[:numEnd], strings.TrimSpace(s[numEnd:])
+	multiplier, ok := bandwidthUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unsupported unit %q; must be B, KB, MB, GB, KiB, MiB, or GiB", unit)
+	}
+	value, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("must be a number of bytes per second, optionally with a unit, like \"20MB/s\"")
+	}
+	ret := int64(value * float64(multiplier))
+	if ret <= 0 {
+		return 0, fmt.Errorf("must be greater than zero")
+	}
+	return ret, nil
+}
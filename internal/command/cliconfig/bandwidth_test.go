@@ -0,0 +1,34 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cliconfig
+
+import (
+	"testing"
+)
+
+func TestParseBandwidth(t *testing.T) {
+	tests := map[string]int64{
+		"1000":      1000,
+		"20MB/s":    20000000,
+		"512KiB":    512 * 1024,
+		"1.5 GB/s":  1500000000,
+		" 2MiB/s  ": 2 * 1024 * 1024,
+	}
+	for input, want := range tests {
+		got, err := ParseBandwidth(input)
+		if err != nil {
+			t.Errorf("unexpected error for %q: %s", input, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("wrong result for %q: got %d, want %d", input, got, want)
+		}
+	}
+
+	for _, input := range []string{"", "fast", "20Mbps", "0MB/s", "MB"} {
+		if _, err := ParseBandwidth(input); err == nil {
+			t.Errorf("no error for %q", input)
+		}
+	}
+}
@@ -17,18 +17,51 @@ import (
 	"io/fs"
 	"log"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 
 	svchost "github.com/hashicorp/terraform-svchost"
 
+	"github.com/opentofu/opentofu/internal/getproviders"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
 const pluginCacheDirEnvVar = "TF_PLUGIN_CACHE_DIR"
 const pluginCacheMayBreakLockFileEnvVar = "TF_PLUGIN_CACHE_MAY_BREAK_DEPENDENCY_LOCK_FILE"
+const pluginTransportEnvVar = "TF_PLUGIN_TRANSPORT"
+const pluginSocketDirEnvVar = "TF_PLUGIN_SOCKET_DIR"
+const providerDownloadConcurrencyEnvVar = "TF_PROVIDER_DOWNLOAD_CONCURRENCY"
+const providerDownloadBandwidthEnvVar = "TF_PROVIDER_DOWNLOAD_BANDWIDTH"
+const providerFunctionCacheDirEnvVar = "TF_PROVIDER_FUNCTION_CACHE_DIR"
+
+// The valid values of the plugin_transport setting. PluginTransportAuto lets
+// each plugin choose how it listens for connections, while
+// PluginTransportUnix requires plugins to listen on a unix domain socket,
+// rather than on a loopback TCP port.
+const (
+	PluginTransportAuto = "auto"
+	PluginTransportUnix = "unix"
+)
+
+// colorCodePattern matches the parameter list of an SGR escape sequence, such
+// as "31" or "38;5;208".
+var colorCodePattern = regexp.MustCompile(`^[0-9]+(;[0-9]+)*$`)
+
+// ValidColorCode returns true if the given string is a plausible parameter
+// list for an SGR escape sequence, such as "31" or "38;5;208", as accepted in
+// the "color_palette" block.
+func ValidColorCode(code string) bool {
+	return colorCodePattern.MatchString(code)
+}
+
+// sha256Pattern matches a hex-encoded SHA256 checksum.
+var sha256Pattern = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
 
 // Config is the structure of the configuration for the OpenTofu CLI.
 //
@@ -53,16 +86,78 @@ type Config struct {
 	// over the requirements of the dependency lock file.
 	PluginCacheMayBreakDependencyLockFile bool `hcl:"plugin_cache_may_break_dependency_lock_file"`
 
+	// PluginTransport selects how OpenTofu connects to the provider and
+	// provisioner plugins it starts, as one of the PluginTransport
+	// constants. The empty string is the same as PluginTransportAuto.
+	//
+	// PluginSocketDir, if set, is the directory where plugins create their
+	// unix domain sockets, instead of the system's temporary directory.
+	PluginTransport string `hcl:"plugin_transport"`
+	PluginSocketDir string `hcl:"plugin_socket_dir"`
+
+	// ProviderDownloadConcurrency, if greater than zero, is the maximum
+	// number of provider packages that the OpenTofu processes on this
+	// computer download at the same time.
+	//
+	// ProviderDownloadBandwidth, if set, is the maximum rate at which each
+	// OpenTofu process downloads provider packages, such as "20MB/s". Use
+	// ParseBandwidth to get the number of bytes per second.
+	ProviderDownloadConcurrency int    `hcl:"provider_download_concurrency"`
+	ProviderDownloadBandwidth   string `hcl:"provider_download_bandwidth"`
+
+	// ProviderFunctionCacheDir, if set, enables caching the results of
+	// provider-defined function calls in this directory, so that they can
+	// be reused by later runs with the same provider versions.
+	ProviderFunctionCacheDir string `hcl:"provider_function_cache_dir"`
+
 	Hosts map[string]*ConfigHost `hcl:"host"`
 
+	// ColorTheme selects one of the built-in color themes for human-oriented
+	// output, such as "colorblind" or "high-contrast". ColorPalette can
+	// then override individual colors of the selected theme, mapping color
+	// names like "red" to terminal SGR codes like "38;5;208".
+	ColorTheme   string            `hcl:"color_theme"`
+	ColorPalette map[string]string `hcl:"color_palette"`
+
 	Credentials        map[string]map[string]interface{}   `hcl:"credentials"`
 	CredentialsHelpers map[string]*ConfigCredentialsHelper `hcl:"credentials_helper"`
 
+	// OIDC configures hosts whose credentials are obtained by exchanging an
+	// identity token from a CI system's OIDC provider, keyed by hostname.
+	OIDC map[string]*ConfigOIDC `hcl:"oidc"`
+
+	// ProviderSigningKeys are OpenPGP public keys that the user trusts to
+	// sign provider packages, keyed by the provider matching pattern, such
+	// as "registry.example.com/example/*", that selects which providers'
+	// packages they may sign.
+	ProviderSigningKeys map[string]*ConfigProviderSigningKeys `hcl:"provider_signing_keys"`
+
+	// ModuleSigningKeys are OpenPGP public keys that the user trusts to sign
+	// module packages in OCI repositories, keyed by a pattern like
+	// "ghcr.io/example/modules/*" that selects the repositories whose
+	// packages they must sign.
+	ModuleSigningKeys map[string]*ConfigModuleSigningKeys `hcl:"module_signing_keys"`
+
+	// ProvisionerPlugins are provisioner plugins that the user installed,
+	// keyed by the name that configurations use for the provisioner.
+	ProvisionerPlugins map[string]*ConfigProvisionerPlugin `hcl:"provisioner_plugin"`
+
 	// ProviderInstallation represents any provider_installation blocks
 	// in the configuration. Only one of these is allowed across the whole
 	// configuration, but we decode into a slice here so that we can handle
 	// that validation at validation time rather than initial decode time.
 	ProviderInstallation []*ProviderInstallation
+
+	// ModuleAliases are the directories that module alias source addresses
+	// refer to, keyed by alias name. They can be set only in the project
+	// configuration, because their paths are relative to the project root,
+	// and so MergeProject sets them.
+	ModuleAliases map[string]string `hcl:"-"`
+
+	// PartialModulePackages makes module installation fetch only the
+	// directories of module packages that are used. Like ModuleAliases, it
+	// can be set only in the project configuration.
+	PartialModulePackages bool `hcl:"-"`
 }
 
 // ConfigHost is the structure of the "host" nested block within the CLI
@@ -78,6 +173,53 @@ type ConfigCredentialsHelper struct {
 	Args []string `hcl:"args"`
 }
 
+// ConfigOIDC is the structure of the "oidc" nested block within the CLI
+// configuration.
+type ConfigOIDC struct {
+	// TokenEnv is the name of an environment variable, and TokenFile is the
+	// path of a file, that contains the identity token to exchange. If
+	// neither is set, the token is requested from GitHub Actions.
+	TokenEnv  string `hcl:"token_env"`
+	TokenFile string `hcl:"token_file"`
+
+	// Audience is the audience to request an identity token for from
+	// GitHub Actions, which defaults to the hostname.
+	Audience string `hcl:"audience"`
+}
+
+// ConfigProviderSigningKeys is the structure of the "provider_signing_keys"
+// nested block within the CLI configuration.
+type ConfigProviderSigningKeys struct {
+	// GPGPublicKeys are ASCII-armored OpenPGP public keys, and
+	// GPGPublicKeyFiles are the paths of files containing them.
+	GPGPublicKeys     []string `hcl:"gpg_public_keys"`
+	GPGPublicKeyFiles []string `hcl:"gpg_public_key_files"`
+
+	// Exclusive, if set, makes OpenTofu trust only these keys to sign the
+	// providers' packages, ignoring the keys that the registry returns.
+	Exclusive bool `hcl:"exclusive"`
+}
+
+// ConfigModuleSigningKeys is the structure of the "module_signing_keys"
+// nested block within the CLI configuration.
+type ConfigModuleSigningKeys struct {
+	// GPGPublicKeys are ASCII-armored OpenPGP public keys, and
+	// GPGPublicKeyFiles are the paths of files containing them.
+	GPGPublicKeys     []string `hcl:"gpg_public_keys"`
+	GPGPublicKeyFiles []string `hcl:"gpg_public_key_files"`
+}
+
+// ConfigProvisionerPlugin is the structure of the "provisioner_plugin" nested
+// block within the CLI configuration.
+type ConfigProvisionerPlugin struct {
+	// Path is the path of the plugin's executable file.
+	Path string `hcl:"path"`
+
+	// SHA256, if set, is the hex-encoded SHA256 checksum that the
+	// executable file must have for OpenTofu to run it.
+	SHA256 string `hcl:"sha256"`
+}
+
 // BuiltinConfig is the built-in defaults for the configuration. These
 // can be overridden by user configurations.
 var BuiltinConfig Config
@@ -188,6 +330,9 @@ func loadConfigFile(path string) (*Config, tfdiags.Diagnostics) {
 	if result.PluginCacheDir != "" {
 		result.PluginCacheDir = os.ExpandEnv(result.PluginCacheDir)
 	}
+	if result.ProviderFunctionCacheDir != "" {
+		result.ProviderFunctionCacheDir = os.ExpandEnv(result.ProviderFunctionCacheDir)
+	}
 
 	return result, diags
 }
@@ -250,6 +395,29 @@ func envConfig(env map[string]string) *Config {
 		config.PluginCacheMayBreakDependencyLockFile = true
 	}
 
+	if envTransport := env[pluginTransportEnvVar]; envTransport != "" {
+		config.PluginTransport = envTransport
+	}
+	if envSocketDir := env[pluginSocketDirEnvVar]; envSocketDir != "" {
+		config.PluginSocketDir = envSocketDir
+	}
+
+	if envConcurrency := env[providerDownloadConcurrencyEnvVar]; envConcurrency != "" {
+		concurrency, err := strconv.Atoi(envConcurrency)
+		if err != nil || concurrency < 0 {
+			log.Printf("[WARN] Ignoring invalid %s value %q: must be a whole number", providerDownloadConcurrencyEnvVar, envConcurrency)
+		} else {
+			config.ProviderDownloadConcurrency = concurrency
+		}
+	}
+	if envBandwidth := env[providerDownloadBandwidthEnvVar]; envBandwidth != "" {
+		config.ProviderDownloadBandwidth = envBandwidth
+	}
+
+	if envFunctionCacheDir := env[providerFunctionCacheDirEnvVar]; envFunctionCacheDir != "" {
+		config.ProviderFunctionCacheDir = envFunctionCacheDir
+	}
+
 	return config
 }
 
@@ -306,6 +474,22 @@ func (c *Config) Validate() tfdiags.Diagnostics {
 		}
 	}
 
+	// Check that all "oidc" blocks have valid hostnames and select at most
+	// one source of identity tokens.
+	for givenHost, oidc := range c.OIDC {
+		_, err := svchost.ForComparison(givenHost)
+		if err != nil {
+			diags = diags.Append(
+				fmt.Errorf("The oidc %q block has an invalid hostname: %w", givenHost, err),
+			)
+		}
+		if oidc.TokenEnv != "" && oidc.TokenFile != "" {
+			diags = diags.Append(
+				fmt.Errorf("The oidc %q block can't set both token_env and token_file", givenHost),
+			)
+		}
+	}
+
 	// Should have zero or one "credentials_helper" blocks
 	if len(c.CredentialsHelpers) > 1 {
 		diags = diags.Append(
@@ -313,6 +497,56 @@ func (c *Config) Validate() tfdiags.Diagnostics {
 		)
 	}
 
+	// Check that all "provider_signing_keys" blocks have valid provider
+	// matching patterns and at least one key.
+	for pattern, keys := range c.ProviderSigningKeys {
+		if _, err := getproviders.ParseMultiSourceMatchingPatterns([]string{pattern}); err != nil {
+			diags = diags.Append(
+				fmt.Errorf("The provider_signing_keys %q block has an invalid provider matching pattern: %w", pattern, err),
+			)
+		}
+		if len(keys.GPGPublicKeys) == 0 && len(keys.GPGPublicKeyFiles) == 0 {
+			diags = diags.Append(
+				fmt.Errorf("The provider_signing_keys %q block must set gpg_public_keys or gpg_public_key_files", pattern),
+			)
+		}
+	}
+
+	// Check that all "module_signing_keys" blocks have valid repository
+	// patterns and at least one key.
+	for pattern, keys := range c.ModuleSigningKeys {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			diags = diags.Append(
+				fmt.Errorf("The module_signing_keys %q block has an invalid repository pattern", pattern),
+			)
+		}
+		if len(keys.GPGPublicKeys) == 0 && len(keys.GPGPublicKeyFiles) == 0 {
+			diags = diags.Append(
+				fmt.Errorf("The module_signing_keys %q block must set gpg_public_keys or gpg_public_key_files", pattern),
+			)
+		}
+	}
+
+	// Check that all "provisioner_plugin" blocks have a valid name, a path,
+	// and a plausible checksum if any.
+	for name, plugin := range c.ProvisionerPlugins {
+		if !hclsyntax.ValidIdentifier(name) {
+			diags = diags.Append(
+				fmt.Errorf("The provisioner_plugin block label %q is not a valid provisioner name", name),
+			)
+		}
+		if plugin.Path == "" {
+			diags = diags.Append(
+				fmt.Errorf("The provisioner_plugin %q block must set path", name),
+			)
+		}
+		if plugin.SHA256 != "" && !sha256Pattern.MatchString(plugin.SHA256) {
+			diags = diags.Append(
+				fmt.Errorf("The provisioner_plugin %q block has invalid sha256 %q: must be 64 hexadecimal digits", name, plugin.SHA256),
+			)
+		}
+	}
+
 	// Should have zero or one "provider_installation" blocks
 	if len(c.ProviderInstallation) > 1 {
 		diags = diags.Append(
@@ -320,6 +554,49 @@ func (c *Config) Validate() tfdiags.Diagnostics {
 		)
 	}
 
+	// Check that all of the "color_palette" values are plausible SGR codes.
+	// Whether the color names are valid depends on the selected theme, so
+	// that is checked only when the theme is instantiated.
+	for name, code := range c.ColorPalette {
+		if !ValidColorCode(code) {
+			diags = diags.Append(
+				fmt.Errorf("The color_palette entry %q has invalid value %q: must be a semicolon-separated list of SGR parameters, such as \"38;5;208\"", name, code),
+			)
+		}
+	}
+
+	switch c.PluginTransport {
+	case "", PluginTransportAuto, PluginTransportUnix:
+	default:
+		diags = diags.Append(
+			fmt.Errorf("The plugin_transport setting has invalid value %q: must be either %q or %q", c.PluginTransport, PluginTransportAuto, PluginTransportUnix),
+		)
+	}
+	if c.PluginSocketDir != "" {
+		if info, err := os.Stat(c.PluginSocketDir); err != nil {
+			diags = diags.Append(
+				fmt.Errorf("The specified plugin socket dir %s cannot be opened: %w", c.PluginSocketDir, err),
+			)
+		} else if !info.IsDir() {
+			diags = diags.Append(
+				fmt.Errorf("The specified plugin socket dir %s is not a directory", c.PluginSocketDir),
+			)
+		}
+	}
+
+	if c.ProviderDownloadConcurrency < 0 {
+		diags = diags.Append(
+			fmt.Errorf("The provider_download_concurrency setting must not be negative"),
+		)
+	}
+	if c.ProviderDownloadBandwidth != "" {
+		if _, err := ParseBandwidth(c.ProviderDownloadBandwidth); err != nil {
+			diags = diags.Append(
+				fmt.Errorf("The provider_download_bandwidth setting has invalid value %q: %w", c.ProviderDownloadBandwidth, err),
+			)
+		}
+	}
+
 	if c.PluginCacheDir != "" {
 		_, err := os.Stat(c.PluginCacheDir)
 		if err != nil {
@@ -328,6 +605,17 @@ func (c *Config) Validate() tfdiags.Diagnostics {
 			)
 		}
 	}
+	if c.ProviderFunctionCacheDir != "" {
+		if info, err := os.Stat(c.ProviderFunctionCacheDir); err != nil {
+			diags = diags.Append(
+				fmt.Errorf("The specified provider function cache dir %s cannot be opened: %w", c.ProviderFunctionCacheDir, err),
+			)
+		} else if !info.IsDir() {
+			diags = diags.Append(
+				fmt.Errorf("The specified provider function cache dir %s is not a directory", c.ProviderFunctionCacheDir),
+			)
+		}
+	}
 
 	return diags
 }
@@ -362,6 +650,28 @@ func (c *Config) Merge(c2 *Config) *Config {
 		result.PluginCacheDir = c2.PluginCacheDir
 	}
 
+	result.PluginTransport = c.PluginTransport
+	if result.PluginTransport == "" {
+		result.PluginTransport = c2.PluginTransport
+	}
+	result.PluginSocketDir = c.PluginSocketDir
+	if result.PluginSocketDir == "" {
+		result.PluginSocketDir = c2.PluginSocketDir
+	}
+
+	result.ProviderDownloadConcurrency = c.ProviderDownloadConcurrency
+	if result.ProviderDownloadConcurrency == 0 {
+		result.ProviderDownloadConcurrency = c2.ProviderDownloadConcurrency
+	}
+	result.ProviderDownloadBandwidth = c.ProviderDownloadBandwidth
+	if result.ProviderDownloadBandwidth == "" {
+		result.ProviderDownloadBandwidth = c2.ProviderDownloadBandwidth
+	}
+	result.ProviderFunctionCacheDir = c.ProviderFunctionCacheDir
+	if result.ProviderFunctionCacheDir == "" {
+		result.ProviderFunctionCacheDir = c2.ProviderFunctionCacheDir
+	}
+
 	if c.PluginCacheMayBreakDependencyLockFile || c2.PluginCacheMayBreakDependencyLockFile {
 		// This setting saturates to "on"; once either configuration sets it,
 		// there is no way to override it back to off again.
@@ -378,6 +688,21 @@ func (c *Config) Merge(c2 *Config) *Config {
 		}
 	}
 
+	result.ColorTheme = c.ColorTheme
+	if result.ColorTheme == "" {
+		result.ColorTheme = c2.ColorTheme
+	}
+
+	if (len(c.ColorPalette) + len(c2.ColorPalette)) > 0 {
+		result.ColorPalette = make(map[string]string)
+		for name, code := range c.ColorPalette {
+			result.ColorPalette[name] = code
+		}
+		for name, code := range c2.ColorPalette {
+			result.ColorPalette[name] = code
+		}
+	}
+
 	if (len(c.Credentials) + len(c2.Credentials)) > 0 {
 		result.Credentials = make(map[string]map[string]interface{})
 		for host, creds := range c.Credentials {
@@ -391,6 +716,46 @@ func (c *Config) Merge(c2 *Config) *Config {
 		}
 	}
 
+	if (len(c.OIDC) + len(c2.OIDC)) > 0 {
+		result.OIDC = make(map[string]*ConfigOIDC)
+		for host, oidc := range c.OIDC {
+			result.OIDC[host] = oidc
+		}
+		for host, oidc := range c2.OIDC {
+			result.OIDC[host] = oidc
+		}
+	}
+
+	if (len(c.ProviderSigningKeys) + len(c2.ProviderSigningKeys)) > 0 {
+		result.ProviderSigningKeys = make(map[string]*ConfigProviderSigningKeys)
+		for pattern, keys := range c.ProviderSigningKeys {
+			result.ProviderSigningKeys[pattern] = keys
+		}
+		for pattern, keys := range c2.ProviderSigningKeys {
+			result.ProviderSigningKeys[pattern] = keys
+		}
+	}
+
+	if (len(c.ModuleSigningKeys) + len(c2.ModuleSigningKeys)) > 0 {
+		result.ModuleSigningKeys = make(map[string]*ConfigModuleSigningKeys)
+		for pattern, keys := range c.ModuleSigningKeys {
+			result.ModuleSigningKeys[pattern] = keys
+		}
+		for pattern, keys := range c2.ModuleSigningKeys {
+			result.ModuleSigningKeys[pattern] = keys
+		}
+	}
+
+	if (len(c.ProvisionerPlugins) + len(c2.ProvisionerPlugins)) > 0 {
+		result.ProvisionerPlugins = make(map[string]*ConfigProvisionerPlugin)
+		for name, plugin := range c.ProvisionerPlugins {
+			result.ProvisionerPlugins[name] = plugin
+		}
+		for name, plugin := range c2.ProvisionerPlugins {
+			result.ProvisionerPlugins[name] = plugin
+		}
+	}
+
 	if (len(c.CredentialsHelpers) + len(c2.CredentialsHelpers)) > 0 {
 		result.CredentialsHelpers = make(map[string]*ConfigCredentialsHelper)
 		for name, helper := range c.CredentialsHelpers {
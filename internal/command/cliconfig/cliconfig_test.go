@@ -115,6 +115,24 @@ func TestEnvConfig(t *testing.T) {
 			},
 			&Config{},
 		},
+		"TF_PLUGIN_TRANSPORT and TF_PLUGIN_SOCKET_DIR": {
+			map[string]string{
+				"TF_PLUGIN_TRANSPORT":  "unix",
+				"TF_PLUGIN_SOCKET_DIR": "/run/tofu",
+			},
+			&Config{
+				PluginTransport: "unix",
+				PluginSocketDir: "/run/tofu",
+			},
+		},
+		"TF_PROVIDER_FUNCTION_CACHE_DIR": {
+			map[string]string{
+				"TF_PROVIDER_FUNCTION_CACHE_DIR": "/var/cache/tofu-functions",
+			},
+			&Config{
+				ProviderFunctionCacheDir: "/var/cache/tofu-functions",
+			},
+		},
 		"TF_PLUGIN_CACHE_DIR and TF_PLUGIN_CACHE_MAY_BREAK_DEPENDENCY_LOCK_FILE": {
 			map[string]string{
 				"TF_PLUGIN_CACHE_DIR":                            "beep",
@@ -268,6 +286,75 @@ func TestLoadConfig_credentials(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_colorTheme(t *testing.T) {
+	got, diags := loadConfigFile(filepath.Join(fixtureDir, "color-theme"))
+	if len(diags) != 0 {
+		t.Fatalf("%s", diags.Err())
+	}
+
+	want := &Config{
+		ColorTheme: "colorblind",
+		ColorPalette: map[string]string{
+			"red":    "38;5;202",
+			"yellow": "1;33",
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %swant: %s", spew.Sdump(got), spew.Sdump(want))
+	}
+}
+
+func TestLoadConfig_providerSigningKeys(t *testing.T) {
+	got, diags := loadConfigFile(filepath.Join(fixtureDir, "provider-signing-keys"))
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Err().Error())
+	}
+
+	want := map[string]*ConfigProviderSigningKeys{
+		"registry.example.com/example/*": {
+			GPGPublicKeyFiles: []string{"/etc/tofu/example.asc"},
+			Exclusive:         true,
+		},
+	}
+	if diff := cmp.Diff(want, got.ProviderSigningKeys); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
+
+func TestLoadConfig_moduleSigningKeys(t *testing.T) {
+	got, diags := loadConfigFile(filepath.Join(fixtureDir, "module-signing-keys"))
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Err().Error())
+	}
+
+	want := map[string]*ConfigModuleSigningKeys{
+		"ghcr.io/example/modules/*": {
+			GPGPublicKeyFiles: []string{"/etc/tofu/modules.asc"},
+		},
+	}
+	if diff := cmp.Diff(want, got.ModuleSigningKeys); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
+
+func TestLoadConfig_provisionerPlugins(t *testing.T) {
+	got, diags := loadConfigFile(filepath.Join(fixtureDir, "provisioner-plugins"))
+	if diags.HasErrors() {
+		t.Fatalf("unexpected diagnostics: %s", diags.Err().Error())
+	}
+
+	want := map[string]*ConfigProvisionerPlugin{
+		"ansible": {
+			Path:   "/usr/local/libexec/tofu/terraform-provisioner-ansible",
+			SHA256: "5b0da8e5b26ad4d2dfe1e1c6d7ab3a1e5bd0cd0a3c7d20ce7a3b5b5e1b3dfc5a",
+		},
+	}
+	if diff := cmp.Diff(want, got.ProvisionerPlugins); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
+
 func TestConfigValidate(t *testing.T) {
 	tests := map[string]struct {
 		Config    *Config
@@ -334,6 +421,82 @@ func TestConfigValidate(t *testing.T) {
 			},
 			1, // no more than one credentials_helper block allowed
 		},
+		"oidc good": {
+			&Config{
+				OIDC: map[string]*ConfigOIDC{
+					"example.com": {TokenEnv: "CI_JOB_JWT"},
+				},
+			},
+			0,
+		},
+		"oidc with bad hostname": {
+			&Config{
+				OIDC: map[string]*ConfigOIDC{
+					"example..com": {},
+				},
+			},
+			1, // invalid hostname
+		},
+		"oidc with two token sources": {
+			&Config{
+				OIDC: map[string]*ConfigOIDC{
+					"example.com": {TokenEnv: "CI_JOB_JWT", TokenFile: "token"},
+				},
+			},
+			1, // token_env and token_file are mutually exclusive
+		},
+		"provider_signing_keys good": {
+			&Config{
+				ProviderSigningKeys: map[string]*ConfigProviderSigningKeys{
+					"registry.example.com/example/*": {
+						GPGPublicKeyFiles: []string{"/etc/tofu/example.asc"},
+					},
+				},
+			},
+			0,
+		},
+		"provider_signing_keys bad": {
+			&Config{
+				ProviderSigningKeys: map[string]*ConfigProviderSigningKeys{
+					"registry.example.com/*/example": {},
+				},
+			},
+			2, // invalid provider matching pattern, and no keys
+		},
+		"module_signing_keys good": {
+			&Config{
+				ModuleSigningKeys: map[string]*ConfigModuleSigningKeys{
+					"ghcr.io/example/modules/*": {
+						GPGPublicKeys: []string{"-----BEGIN PGP PUBLIC KEY BLOCK-----"},
+					},
+				},
+			},
+			0,
+		},
+		"module_signing_keys bad": {
+			&Config{
+				ModuleSigningKeys: map[string]*ConfigModuleSigningKeys{
+					"ghcr.io/example/[modules": {},
+				},
+			},
+			2, // invalid repository pattern, and no keys
+		},
+		"provisioner_plugin good": {
+			&Config{
+				ProvisionerPlugins: map[string]*ConfigProvisionerPlugin{
+					"ansible": {Path: "/usr/local/bin/terraform-provisioner-ansible"},
+				},
+			},
+			0,
+		},
+		"provisioner_plugin bad": {
+			&Config{
+				ProvisionerPlugins: map[string]*ConfigProvisionerPlugin{
+					"not valid": {SHA256: "abc"},
+				},
+			},
+			3, // invalid name, no path, and invalid checksum
+		},
 		"provider_installation good none": {
 			&Config{
 				ProviderInstallation: nil,
@@ -357,12 +520,68 @@ func TestConfigValidate(t *testing.T) {
 			},
 			1, // no more than one provider_installation block allowed
 		},
+		"color_palette good": {
+			&Config{
+				ColorPalette: map[string]string{
+					"red":   "31",
+					"green": "38;5;33",
+				},
+			},
+			0,
+		},
+		"color_palette with bad code": {
+			&Config{
+				ColorPalette: map[string]string{
+					"red":   "orange",
+					"green": "38;5;",
+				},
+			},
+			2, // The color_palette entry %q has invalid value %q
+		},
+		"plugin_transport unix": {
+			&Config{
+				PluginTransport: "unix",
+			},
+			0,
+		},
+		"plugin_transport invalid": {
+			&Config{
+				PluginTransport: "npipe",
+			},
+			1, // The plugin_transport setting has invalid value
+		},
+		"plugin_socket_dir does not exist": {
+			&Config{
+				PluginSocketDir: "fake",
+			},
+			1, // The specified plugin socket dir %s cannot be opened
+		},
+		"provider download limits": {
+			&Config{
+				ProviderDownloadConcurrency: 2,
+				ProviderDownloadBandwidth:   "20MB/s",
+			},
+			0,
+		},
+		"provider download limits invalid": {
+			&Config{
+				ProviderDownloadConcurrency: -1,
+				ProviderDownloadBandwidth:   "fast",
+			},
+			2, // must not be negative, and invalid bandwidth
+		},
 		"plugin_cache_dir does not exist": {
 			&Config{
 				PluginCacheDir: "fake",
 			},
 			1, // The specified plugin cache dir %s cannot be opened
 		},
+		"provider_function_cache_dir does not exist": {
+			&Config{
+				ProviderFunctionCacheDir: "fake",
+			},
+			1, // The specified provider function cache dir %s cannot be opened
+		},
 	}
 
 	for name, test := range tests {
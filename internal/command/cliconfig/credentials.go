@@ -56,7 +56,7 @@ func (c *Config) CredentialsSource(helperPlugins pluginDiscovery.PluginMetaSet)
 
 		selected := available.Newest()
 
-		helperSource := svcauth.HelperProgramCredentialsSource(selected.Path, givenConfig.Args...)
+		helperSource := newHelperProgramCredentialsSource(selected.Path, givenConfig.Args...)
 		helper = svcauth.CachingCredentialsSource(helperSource) // cached because external operation may be slow/expensive
 		helperType = givenType
 
@@ -114,6 +114,7 @@ func (c *Config) credentialsSource(helperType string, helper svcauth.Credentials
 		credentialsFilePath: credentialsFilePath,
 		helper:              helper,
 		helperType:          helperType,
+		oidc:                newOIDCCredentialsSource(c.OIDC, c.Hosts),
 	}
 }
 
@@ -224,6 +225,10 @@ type CredentialsSource struct {
 	// helperType is the name of the type of credentials helper that is
 	// referenced in "helper", or the empty string if "helper" is nil.
 	helperType string
+
+	// oidc obtains credentials for the hosts that have "oidc" blocks in the
+	// CLI config by exchanging identity tokens for them.
+	oidc *oidcCredentialsSource
 }
 
 // Assertion that credentialsSource implements CredentialsSource
@@ -235,20 +240,44 @@ func (s *CredentialsSource) ForHost(host svchost.Hostname) (svcauth.HostCredenti
 		return envCreds, nil
 	}
 
+	// Then, an access token exchanged for an identity token, if the CLI
+	// config has an oidc block for the host
+	if s.oidc != nil {
+		creds, err := s.oidc.ForHost(host)
+		if creds != nil || err != nil {
+			return creds, err
+		}
+	}
+
 	// Then, any credentials block present in the CLI config
 	v, ok := s.configured[host]
 	if ok {
-		return svcauth.HostCredentialsFromObject(v), nil
+		return s.refreshable(host, hostCredentialsFromObject(v)), nil
 	}
 
 	// And finally, the credentials helper
 	if s.helper != nil {
-		return s.helper.ForHost(host)
+		creds, err := s.helper.ForHost(host)
+		if err != nil {
+			return nil, err
+		}
+		return s.refreshable(host, creds), nil
 	}
 
 	return nil, nil
 }
 
+// refreshable arranges for OAuth credentials to be stored again for the
+// given host after they are refreshed, and returns the credentials.
+func (s *CredentialsSource) refreshable(host svchost.Hostname, creds svcauth.HostCredentials) svcauth.HostCredentials {
+	if oauthCreds, ok := creds.(*OAuthCredentials); ok {
+		oauthCreds.store = func(new *OAuthCredentials) error {
+			return s.StoreForHost(host, new)
+		}
+	}
+	return creds
+}
+
 func (s *CredentialsSource) StoreForHost(host svchost.Hostname, credentials svcauth.HostCredentialsWritable) error {
 	return s.updateHostCredentials(host, credentials)
 }
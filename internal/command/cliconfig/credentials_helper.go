@@ -0,0 +1,67 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cliconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+
+	svchost "github.com/hashicorp/terraform-svchost"
+	svcauth "github.com/hashicorp/terraform-svchost/auth"
+)
+
+// helperProgramCredentialsSource is a credentials source which uses an
+// external credentials helper program, as svcauth.HelperProgramCredentialsSource
+// does, except that it also recognizes refreshable OAuth credentials in the
+// helper's response.
+type helperProgramCredentialsSource struct {
+	svcauth.CredentialsSource
+
+	executable string
+	args       []string
+}
+
+var _ svcauth.CredentialsSource = (*helperProgramCredentialsSource)(nil)
+
+func newHelperProgramCredentialsSource(executable string, args ...string) *helperProgramCredentialsSource {
+	return &helperProgramCredentialsSource{
+		CredentialsSource: svcauth.HelperProgramCredentialsSource(executable, args...),
+		executable:        executable,
+		args:              args,
+	}
+}
+
+func (s *helperProgramCredentialsSource) ForHost(host svchost.Hostname) (svcauth.HostCredentials, error) {
+	args := make([]string, 0, len(s.args)+3)
+	args = append(args, s.executable)
+	args = append(args, s.args...)
+	args = append(args, "get", string(host))
+
+	var outBuf, errBuf bytes.Buffer
+	cmd := exec.Cmd{
+		Path:   s.executable,
+		Args:   args,
+		Stdout: &outBuf,
+		Stderr: &errBuf,
+	}
+	err := cmd.Run()
+	if _, isExitErr := err.(*exec.ExitError); isExitErr {
+		errText := errBuf.String()
+		if errText == "" {
+			// Shouldn't happen for a well-behaved helper program
+			return nil, fmt.Errorf("error in %s, but it produced no error message", s.executable)
+		}
+		return nil, fmt.Errorf("error in %s: %s", s.executable, errText)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to run %s: %w", s.executable, err)
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(outBuf.Bytes(), &m); err != nil {
+		return nil, fmt.Errorf("malformed output from %s: %w", s.executable, err)
+	}
+	return hostCredentialsFromMap(m), nil
+}
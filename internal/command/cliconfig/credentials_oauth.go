@@ -0,0 +1,190 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cliconfig
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/oauth2"
+
+	svcauth "github.com/hashicorp/terraform-svchost/auth"
+
+	"github.com/opentofu/opentofu/internal/httpclient"
+)
+
+// oauthRefreshMargin is how long before its expiry time we consider an
+// access token to have expired, to allow for clock skew and for the time
+// taken by the request that uses it.
+const oauthRefreshMargin = 30 * time.Second
+
+// OAuthCredentials are host credentials obtained from an OAuth token
+// endpoint along with a refresh token, which allows OpenTofu to obtain a new
+// access token when the current one expires without the user logging in
+// again.
+//
+// When stored, OAuthCredentials include the access token in the "token"
+// attribute just as for a static token, so that other software which reads
+// the stored credentials can still use the access token while it is valid.
+type OAuthCredentials struct {
+	AccessToken  string
+	RefreshToken string
+	Expiry       time.Time
+
+	// TokenURL and ClientID are the token endpoint and client ID to use when
+	// refreshing the access token.
+	TokenURL string
+	ClientID string
+
+	mu sync.Mutex
+
+	// store saves the credentials after they have been refreshed, if set.
+	store func(*OAuthCredentials) error
+}
+
+var _ svcauth.HostCredentialsWritable = (*OAuthCredentials)(nil)
+
+// NewOAuthCredentials returns the credentials to store for the given token,
+// issued by the given token endpoint to the given client. If the token
+// cannot be refreshed then the result is just the access token.
+func NewOAuthCredentials(token *oauth2.Token, tokenURL, clientID string) svcauth.HostCredentialsWritable {
+	if token.RefreshToken == "" || tokenURL == "" || clientID == "" {
+		return svcauth.HostCredentialsToken(token.AccessToken)
+	}
+	return &OAuthCredentials{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		Expiry:       token.Expiry,
+		TokenURL:     tokenURL,
+		ClientID:     clientID,
+	}
+}
+
+// PrepareRequest sets the Authorization header of the given request to use
+// the access token, refreshing it first if it has expired.
+func (c *OAuthCredentials) PrepareRequest(req *http.Request) {
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token())
+}
+
+// Token returns the access token, refreshing it first if it has expired.
+//
+// If refreshing fails then Token returns the expired access token, so that
+// the request that uses it fails with the usual error for invalid
+// credentials and the user can log in again.
+func (c *OAuthCredentials) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.Expiry.IsZero() || time.Now().Add(oauthRefreshMargin).Before(c.Expiry) {
+		return c.AccessToken
+	}
+
+	config := &oauth2.Config{
+		ClientID: c.ClientID,
+		Endpoint: oauth2.Endpoint{
+			TokenURL:  c.TokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+	}
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, httpclient.New())
+	token, err := config.TokenSource(ctx, &oauth2.Token{
+		AccessToken:  c.AccessToken,
+		RefreshToken: c.RefreshToken,
+		Expiry:       c.Expiry,
+	}).Token()
+	if err != nil {
+		log.Printf("[ERROR] Failed to refresh the access token from %s: %s", c.TokenURL, err)
+		return c.AccessToken
+	}
+	log.Printf("[DEBUG] Refreshed the access token from %s", c.TokenURL)
+
+	c.AccessToken = token.AccessToken
+	c.Expiry = token.Expiry
+	if token.RefreshToken != "" {
+		c.RefreshToken = token.RefreshToken
+	}
+	if c.store != nil {
+		if err := c.store(c); err != nil {
+			log.Printf("[ERROR] Failed to save the refreshed access token: %s", err)
+		}
+	}
+	return c.AccessToken
+}
+
+// ToStore returns a credentials object with the access token in the "token"
+// attribute and the other attributes needed to refresh it.
+func (c *OAuthCredentials) ToStore() cty.Value {
+	attrs := map[string]cty.Value{
+		"token":         cty.StringVal(c.AccessToken),
+		"refresh_token": cty.StringVal(c.RefreshToken),
+		"token_url":     cty.StringVal(c.TokenURL),
+		"client_id":     cty.StringVal(c.ClientID),
+	}
+	if !c.Expiry.IsZero() {
+		attrs["token_expiry"] = cty.StringVal(c.Expiry.UTC().Format(time.RFC3339))
+	}
+	return cty.ObjectVal(attrs)
+}
+
+// hostCredentialsFromObject is like svcauth.HostCredentialsFromObject, but
+// also recognizes stored OAuthCredentials.
+func hostCredentialsFromObject(obj cty.Value) svcauth.HostCredentials {
+	if obj.IsNull() || !obj.IsKnown() || !obj.Type().IsObjectType() {
+		return nil
+	}
+
+	attrs := make(map[string]interface{})
+	for name, v := range obj.AsValueMap() {
+		if v.IsKnown() && !v.IsNull() && v.Type() == cty.String {
+			attrs[name] = v.AsString()
+		}
+	}
+	if creds := oauthCredentialsFromMap(attrs); creds != nil {
+		return creds
+	}
+	return svcauth.HostCredentialsFromObject(obj)
+}
+
+// hostCredentialsFromMap is like svcauth.HostCredentialsFromMap, but also
+// recognizes stored OAuthCredentials.
+func hostCredentialsFromMap(m map[string]interface{}) svcauth.HostCredentials {
+	if creds := oauthCredentialsFromMap(m); creds != nil {
+		return creds
+	}
+	return svcauth.HostCredentialsFromMap(m)
+}
+
+func oauthCredentialsFromMap(m map[string]interface{}) *OAuthCredentials {
+	token, _ := m["token"].(string)
+	refreshToken, _ := m["refresh_token"].(string)
+	tokenURL, _ := m["token_url"].(string)
+	clientID, _ := m["client_id"].(string)
+	if token == "" || refreshToken == "" || tokenURL == "" || clientID == "" {
+		return nil
+	}
+
+	creds := &OAuthCredentials{
+		AccessToken:  token,
+		RefreshToken: refreshToken,
+		TokenURL:     tokenURL,
+		ClientID:     clientID,
+	}
+	if raw, ok := m["token_expiry"].(string); ok {
+		expiry, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			// We'll just refresh the token when it's next used, then.
+			log.Printf("[WARN] Invalid token_expiry in stored credentials: %s", err)
+			expiry = time.Unix(0, 0)
+		}
+		creds.Expiry = expiry
+	}
+	return creds
+}
@@ -0,0 +1,123 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cliconfig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	svchost "github.com/hashicorp/terraform-svchost"
+	svcauth "github.com/hashicorp/terraform-svchost/auth"
+	"golang.org/x/oauth2"
+)
+
+func TestOAuthCredentials(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if err := req.ParseForm(); err != nil {
+			resp.WriteHeader(500)
+			return
+		}
+		if req.Form.Get("grant_type") != "refresh_token" || req.Form.Get("refresh_token") != "refresh-token" || req.Form.Get("client_id") != "tofu" {
+			resp.Header().Set("Content-Type", "application/json")
+			resp.WriteHeader(400)
+			resp.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		resp.Header().Set("Content-Type", "application/json")
+		resp.Write([]byte(`{"access_token":"refreshed-token","token_type":"bearer","refresh_token":"new-refresh-token","expires_in":3600}`))
+	}))
+	defer s.Close()
+
+	credentialsFile := filepath.Join(t.TempDir(), "credentials.tfrc.json")
+	host := svchost.Hostname("example.com")
+
+	t.Run("without refresh token", func(t *testing.T) {
+		creds := NewOAuthCredentials(&oauth2.Token{AccessToken: "static-token"}, s.URL, "tofu")
+		if _, ok := creds.(svcauth.HostCredentialsToken); !ok {
+			t.Fatalf("wrong credentials type %T; want svcauth.HostCredentialsToken", creds)
+		}
+	})
+
+	t.Run("valid access token", func(t *testing.T) {
+		src := EmptyCredentialsSourceForTests(credentialsFile)
+		err := src.StoreForHost(host, NewOAuthCredentials(&oauth2.Token{
+			AccessToken:  "good-token",
+			RefreshToken: "refresh-token",
+			Expiry:       time.Now().Add(time.Hour),
+		}, s.URL, "tofu"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		creds, err := src.ForHost(host)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := creds.(*OAuthCredentials); !ok {
+			t.Fatalf("wrong credentials type %T; want *OAuthCredentials", creds)
+		}
+		if got, want := creds.Token(), "good-token"; got != want {
+			t.Errorf("wrong token %q; want %q", got, want)
+		}
+	})
+
+	t.Run("expired access token", func(t *testing.T) {
+		src := EmptyCredentialsSourceForTests(credentialsFile)
+		err := src.StoreForHost(host, NewOAuthCredentials(&oauth2.Token{
+			AccessToken:  "expired-token",
+			RefreshToken: "refresh-token",
+			Expiry:       time.Now().Add(-time.Minute),
+		}, s.URL, "tofu"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		creds, err := src.ForHost(host)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, _ := http.NewRequest("GET", "http://example.com/", nil)
+		creds.PrepareRequest(req)
+		if got, want := req.Header.Get("Authorization"), "Bearer refreshed-token"; got != want {
+			t.Errorf("wrong Authorization header %q; want %q", got, want)
+		}
+
+		// The refreshed credentials are stored for later use.
+		raw, err := os.ReadFile(credentialsFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stored struct {
+			Credentials map[string]map[string]interface{} `json:"credentials"`
+		}
+		if err := json.Unmarshal(raw, &stored); err != nil {
+			t.Fatal(err)
+		}
+		oauthCreds, ok := hostCredentialsFromMap(stored.Credentials["example.com"]).(*OAuthCredentials)
+		if !ok {
+			t.Fatalf("stored credentials are not OAuth credentials:\n%s", raw)
+		}
+		if oauthCreds.AccessToken != "refreshed-token" || oauthCreds.RefreshToken != "new-refresh-token" {
+			t.Errorf("wrong stored credentials %#v", oauthCreds)
+		}
+	})
+
+	t.Run("failed refresh", func(t *testing.T) {
+		creds := &OAuthCredentials{
+			AccessToken:  "expired-token",
+			RefreshToken: "revoked",
+			Expiry:       time.Now().Add(-time.Minute),
+			TokenURL:     s.URL,
+			ClientID:     "tofu",
+		}
+		if got, want := creds.Token(), "expired-token"; got != want {
+			t.Errorf("wrong token %q; want %q", got, want)
+		}
+	})
+}
@@ -0,0 +1,226 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cliconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	svchost "github.com/hashicorp/terraform-svchost"
+	svcauth "github.com/hashicorp/terraform-svchost/auth"
+	"github.com/hashicorp/terraform-svchost/disco"
+
+	"github.com/opentofu/opentofu/internal/httpclient"
+)
+
+// oidcServiceID is the service discovery ID of the token exchange endpoint
+// that a host uses to issue access tokens in exchange for identity tokens
+// from OIDC providers that it trusts, like those of CI systems.
+const oidcServiceID = "oidc.v1"
+
+// The parameters of an OAuth 2.0 token exchange request, as defined by
+// RFC 8693.
+const (
+	oidcGrantType          = "urn:ietf:params:oauth:grant-type:token-exchange"
+	oidcSubjectTokenType   = "urn:ietf:params:oauth:token-type:id_token"
+	oidcRequestedTokenType = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+// oidcCredentialsSource obtains access tokens for the hosts configured in
+// "oidc" blocks by exchanging identity tokens at each host's token exchange
+// endpoint, and remembers them until they expire.
+type oidcCredentialsSource struct {
+	configs map[svchost.Hostname]*ConfigOIDC
+
+	// services finds the token exchange endpoints. It has no credentials
+	// source, because the discovery document must be readable before we
+	// have any credentials for the host.
+	services *disco.Disco
+
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	tokens map[svchost.Hostname]*oidcToken
+}
+
+type oidcToken struct {
+	accessToken string
+	expiry      time.Time
+}
+
+func newOIDCCredentialsSource(configs map[string]*ConfigOIDC, hosts map[string]*ConfigHost) *oidcCredentialsSource {
+	s := &oidcCredentialsSource{
+		configs:    make(map[svchost.Hostname]*ConfigOIDC),
+		services:   disco.New(),
+		httpClient: httpclient.New(),
+		tokens:     make(map[svchost.Hostname]*oidcToken),
+	}
+	for userHost, config := range configs {
+		host, err := svchost.ForComparison(userHost)
+		if err != nil {
+			// We expect the config was already validated by the time we get
+			// here, so we'll just ignore invalid hostnames.
+			continue
+		}
+		s.configs[host] = config
+	}
+	for userHost, config := range hosts {
+		host, err := svchost.ForComparison(userHost)
+		if err != nil {
+			continue
+		}
+		s.services.ForceHostServices(host, config.Services)
+	}
+	return s
+}
+
+// ForHost returns an access token for the given host if it has an "oidc"
+// block, exchanging a new identity token for it if there's no unexpired
+// token already.
+func (s *oidcCredentialsSource) ForHost(host svchost.Hostname) (svcauth.HostCredentials, error) {
+	config, ok := s.configs[host]
+	if !ok {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if token, ok := s.tokens[host]; ok && (token.expiry.IsZero() || time.Now().Add(oauthRefreshMargin).Before(token.expiry)) {
+		return svcauth.HostCredentialsToken(token.accessToken), nil
+	}
+
+	tokenURL, err := s.services.DiscoverServiceURL(host, oidcServiceID)
+	if err != nil {
+		return nil, fmt.Errorf("%s doesn't support OIDC token exchange: %w", host.ForDisplay(), err)
+	}
+	idToken, err := s.identityToken(host, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get an identity token for %s: %w", host.ForDisplay(), err)
+	}
+	token, err := s.exchange(tokenURL, idToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange an identity token for an access token for %s: %w", host.ForDisplay(), err)
+	}
+	log.Printf("[DEBUG] Exchanged an identity token for an access token for %s", host.ForDisplay())
+	s.tokens[host] = token
+	return svcauth.HostCredentialsToken(token.accessToken), nil
+}
+
+// identityToken returns the identity token to exchange for the given host.
+func (s *oidcCredentialsSource) identityToken(host svchost.Hostname, config *ConfigOIDC) (string, error) {
+	switch {
+	case config.TokenEnv != "":
+		token := strings.TrimSpace(os.Getenv(config.TokenEnv))
+		if token == "" {
+			return "", fmt.Errorf("the environment variable %s is not set", config.TokenEnv)
+		}
+		return token, nil
+	case config.TokenFile != "":
+		src, err := os.ReadFile(config.TokenFile)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(src)), nil
+	}
+
+	// GitHub Actions issues identity tokens to jobs with the "id-token:
+	// write" permission, from an endpoint given in the environment.
+	requestURL := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL")
+	requestToken := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+	if requestURL == "" || requestToken == "" {
+		return "", fmt.Errorf("the oidc block sets neither token_env nor token_file, and this is not a GitHub Actions job with the id-token: write permission")
+	}
+	u, err := url.Parse(requestURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid ACTIONS_ID_TOKEN_REQUEST_URL: %w", err)
+	}
+	audience := config.Audience
+	if audience == "" {
+		audience = host.String()
+	}
+	query := u.Query()
+	query.Set("audience", audience)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+requestToken)
+	var body struct {
+		Value string `json:"value"`
+	}
+	if err := s.doJSON(req, &body); err != nil {
+		return "", err
+	}
+	if body.Value == "" {
+		return "", fmt.Errorf("GitHub Actions returned no identity token")
+	}
+	return body.Value, nil
+}
+
+// exchange exchanges the given identity token for an access token at the
+// given token exchange endpoint.
+func (s *oidcCredentialsSource) exchange(tokenURL *url.URL, idToken string) (*oidcToken, error) {
+	form := url.Values{
+		"grant_type":           {oidcGrantType},
+		"subject_token":        {idToken},
+		"subject_token_type":   {oidcSubjectTokenType},
+		"requested_token_type": {oidcRequestedTokenType},
+	}
+	req, err := http.NewRequest("POST", tokenURL.String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := s.doJSON(req, &body); err != nil {
+		return nil, err
+	}
+	if body.AccessToken == "" {
+		return nil, fmt.Errorf("the token endpoint returned no access token")
+	}
+	token := &oidcToken{accessToken: body.AccessToken}
+	if body.ExpiresIn > 0 {
+		token.expiry = time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+func (s *oidcCredentialsSource) doJSON(req *http.Request, into interface{}) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var errBody struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}
+		if json.NewDecoder(io.LimitReader(resp.Body, 1024*1024)).Decode(&errBody) == nil && errBody.Error != "" {
+			if errBody.Description != "" {
+				return fmt.Errorf("%s returned %s: %s: %s", req.URL.Host, resp.Status, errBody.Error, errBody.Description)
+			}
+			return fmt.Errorf("%s returned %s: %s", req.URL.Host, resp.Status, errBody.Error)
+		}
+		return fmt.Errorf("%s returned %s", req.URL.Host, resp.Status)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1024*1024)).Decode(into); err != nil {
+		return fmt.Errorf("invalid response from %s: %w", req.URL.Host, err)
+	}
+	return nil
+}
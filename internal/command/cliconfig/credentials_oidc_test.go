@@ -0,0 +1,132 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cliconfig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	svchost "github.com/hashicorp/terraform-svchost"
+)
+
+func TestOIDCCredentialsSource(t *testing.T) {
+	var exchanges int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/github-token":
+			if req.Header.Get("Authorization") != "Bearer request-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{
+				"value": "id-token-for-" + req.URL.Query().Get("audience"),
+			})
+		case "/oidc/token":
+			if err := req.ParseForm(); err != nil {
+				t.Fatal(err)
+			}
+			if got := req.PostForm.Get("grant_type"); got != oidcGrantType {
+				t.Errorf("wrong grant_type %q", got)
+			}
+			subject := req.PostForm.Get("subject_token")
+			if !strings.HasPrefix(subject, "id-token") {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{
+					"error":             "invalid_grant",
+					"error_description": "untrusted issuer",
+				})
+				return
+			}
+			exchanges++
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token": "access-for-" + subject,
+				"token_type":   "Bearer",
+				"expires_in":   3600,
+			})
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("id-token-from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_OIDC_TOKEN", "id-token-from-env")
+	t.Setenv("BAD_OIDC_TOKEN", "bad")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", server.URL+"/github-token?api-version=2.0")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "request-token")
+
+	services := map[string]interface{}{oidcServiceID: server.URL + "/oidc/token"}
+	src := newOIDCCredentialsSource(
+		map[string]*ConfigOIDC{
+			"env.example.com":     {TokenEnv: "TEST_OIDC_TOKEN"},
+			"file.example.com":    {TokenFile: tokenFile},
+			"github.example.com":  {Audience: "tofu"},
+			"bad.example.com":     {TokenEnv: "BAD_OIDC_TOKEN"},
+			"unset.example.com":   {TokenEnv: "UNSET_OIDC_TOKEN"},
+			"nodisco.example.com": {TokenEnv: "TEST_OIDC_TOKEN"},
+		},
+		map[string]*ConfigHost{
+			"env.example.com":     {Services: services},
+			"file.example.com":    {Services: services},
+			"github.example.com":  {Services: services},
+			"bad.example.com":     {Services: services},
+			"unset.example.com":   {Services: services},
+			"nodisco.example.com": {Services: map[string]interface{}{}},
+		},
+	)
+
+	tokenFor := func(t *testing.T, host svchost.Hostname) (string, error) {
+		t.Helper()
+		creds, err := src.ForHost(host)
+		if err != nil || creds == nil {
+			return "", err
+		}
+		return creds.Token(), nil
+	}
+
+	for host, want := range map[svchost.Hostname]string{
+		"env.example.com":    "access-for-id-token-from-env",
+		"file.example.com":   "access-for-id-token-from-file",
+		"github.example.com": "access-for-id-token-for-tofu",
+	} {
+		got, err := tokenFor(t, host)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %s", host, err)
+		} else if got != want {
+			t.Errorf("wrong token for %s %q; want %q", host, got, want)
+		}
+	}
+
+	// The tokens are remembered until they expire.
+	before := exchanges
+	if _, err := tokenFor(t, "env.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if exchanges != before {
+		t.Errorf("exchanged a new token while the previous one was still valid")
+	}
+
+	for host, wantErr := range map[svchost.Hostname]string{
+		"bad.example.com":     "invalid_grant: untrusted issuer",
+		"unset.example.com":   "the environment variable UNSET_OIDC_TOKEN is not set",
+		"nodisco.example.com": "doesn't support OIDC token exchange",
+	} {
+		_, err := tokenFor(t, host)
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("wrong error for %s: %v; want an error containing %q", host, err, wantErr)
+		}
+	}
+
+	if got, err := tokenFor(t, "other.example.com"); got != "" || err != nil {
+		t.Errorf("unexpected result for a host without an oidc block: %q, %v", got, err)
+	}
+}
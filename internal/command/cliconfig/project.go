@@ -0,0 +1,332 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cliconfig
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// projectConfigFilenames are the names of the files that can contain a
+// project-level CLI configuration, in order of preference.
+var projectConfigFilenames = []string{"tofu.project.hcl", ".tofurc"}
+
+// ProjectConfig is the structure of a project-level CLI configuration file,
+// which sets defaults for all of the commands run in a particular project,
+// such as a version control repository.
+//
+// The project configuration is layered under the user-level CLI
+// configuration: settings which exist in both take their values from the
+// user-level configuration, and the command line options derived from the
+// project configuration are overridden by options given explicitly.
+type ProjectConfig struct {
+	// Filename is the path of the file the configuration was loaded from.
+	// Relative paths within the configuration are relative to the
+	// directory containing this file, which is the project root.
+	Filename string `hcl:"-"`
+
+	// VarFiles are variable definitions files to load before any given
+	// with the -var-file option.
+	VarFiles []string `hcl:"var_files"`
+
+	// Parallelism is the default for the -parallelism option, or zero to
+	// use the built-in default.
+	Parallelism int `hcl:"parallelism"`
+
+	// PlanSummary is the default for the -summary option of the plan
+	// command, which selects how proposed changes are presented.
+	PlanSummary string `hcl:"plan_summary"`
+
+	// Environment sets environment variables for OpenTofu and the plugins it
+	// runs, unless they are already set.
+	Environment map[string]string `hcl:"environment"`
+
+	// ModuleAliases are the directories that module source addresses like
+	// "alias::networking/vpc" refer to, keyed by alias name.
+	ModuleAliases map[string]string `hcl:"module_aliases"`
+
+	// PartialModulePackages makes module installation fetch only the
+	// directories of module packages that the project uses, where the
+	// packages' sources support that.
+	PartialModulePackages bool `hcl:"partial_module_packages"`
+}
+
+// projectArgCommands are the commands that accept each of the command line
+// options that a project configuration can provide defaults for.
+var projectArgCommands = map[string][]string{
+	"-var-file":    {"apply", "console", "destroy", "import", "plan", "refresh"},
+	"-parallelism": {"apply", "destroy", "import", "plan", "refresh"},
+	"-summary":     {"plan"},
+}
+
+// FindProjectConfig searches for a project-level CLI configuration file in
+// the given directory and then its parent directories, stopping at the root
+// of a git repository or of the filesystem. It returns an empty string if
+// there is no project configuration file.
+//
+// A file which is the user-level CLI configuration file, such as the
+// ".tofurc" file in the user's home directory, is not a project
+// configuration file.
+func FindProjectConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	userFiles := make(map[string]bool)
+	if f, err := configFile(); err == nil {
+		f, _ = filepath.Abs(f)
+		userFiles[f] = true
+	}
+	if f := cliConfigFileOverride(); f != "" {
+		f, _ = filepath.Abs(f)
+		userFiles[f] = true
+	}
+
+	for {
+		for _, name := range projectConfigFilenames {
+			candidate := filepath.Join(dir, name)
+			if userFiles[candidate] {
+				continue
+			}
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				return candidate, nil
+			}
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return "", nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// LoadProjectConfig finds and loads the project-level CLI configuration for
+// the given working directory, returning nil if there is none.
+func LoadProjectConfig(dir string) (*ProjectConfig, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	filename, err := FindProjectConfig(dir)
+	if err != nil {
+		diags = diags.Append(fmt.Errorf("Error finding the project configuration: %w", err))
+		return nil, diags
+	}
+	if filename == "" {
+		return nil, diags
+	}
+
+	config, moreDiags := loadProjectConfigFile(filename)
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		return nil, diags
+	}
+	diags = diags.Append(config.Validate())
+	return config, diags
+}
+
+func loadProjectConfigFile(path string) (*ProjectConfig, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	result := &ProjectConfig{Filename: path}
+
+	log.Printf("Loading project CLI configuration from %s", path)
+
+	d, err := os.ReadFile(path)
+	if err != nil {
+		diags = diags.Append(fmt.Errorf("Error reading %s: %w", path, err))
+		return result, diags
+	}
+
+	obj, err := hcl.Parse(string(d))
+	if err != nil {
+		diags = diags.Append(fmt.Errorf("Error parsing %s: %w", path, err))
+		return result, diags
+	}
+
+	if err := hcl.DecodeObject(result, obj); err != nil {
+		diags = diags.Append(fmt.Errorf("Error parsing %s: %w", path, err))
+		return result, diags
+	}
+
+	root := filepath.Dir(path)
+	for i, f := range result.VarFiles {
+		result.VarFiles[i] = projectPath(root, f)
+	}
+	for name, dir := range result.ModuleAliases {
+		result.ModuleAliases[name] = projectPath(root, dir)
+	}
+
+	return result, diags
+}
+
+// projectPath resolves a path from a project configuration relative to the
+// project root.
+func projectPath(root, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(root, path)
+}
+
+// Validate checks for errors in the project configuration that cannot be
+// detected just by HCL decoding, returning any problems as diagnostics.
+func (c *ProjectConfig) Validate() tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	if c == nil {
+		return diags
+	}
+
+	if c.Parallelism < 0 {
+		diags = diags.Append(
+			fmt.Errorf("The parallelism setting in %s must be a positive number", c.Filename),
+		)
+	}
+
+	switch c.PlanSummary {
+	case "", "module":
+	default:
+		diags = diags.Append(
+			fmt.Errorf("The plan_summary setting in %s has invalid value %q: the only supported mode is \"module\"", c.Filename, c.PlanSummary),
+		)
+	}
+
+	envNames := make([]string, 0, len(c.Environment))
+	for name := range c.Environment {
+		envNames = append(envNames, name)
+	}
+	sort.Strings(envNames)
+	for _, name := range envNames {
+		if !projectEnvironmentAllowed(name) {
+			diags = diags.Append(
+				fmt.Errorf("The environment setting in %s cannot set %s: only input variables (TF_VAR_*) and %s can be set from the project configuration", c.Filename, name, strings.Join(projectEnvironmentNames, ", ")),
+			)
+		}
+	}
+
+	names := make([]string, 0, len(c.ModuleAliases))
+	for name := range c.ModuleAliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !addrs.ValidModuleAliasName(name) {
+			diags = diags.Append(
+				fmt.Errorf("The module_aliases setting in %s has invalid alias name %q: must start with a letter and contain only letters, digits, dashes, and underscores", c.Filename, name),
+			)
+		}
+	}
+
+	return diags
+}
+
+// Args returns the command line options that the project configuration
+// provides defaults for and which the given command accepts, to be inserted
+// before any options given explicitly.
+func (c *ProjectConfig) Args(cmd string) []string {
+	if c == nil {
+		return nil
+	}
+
+	var ret []string
+	if projectArgAccepted("-var-file", cmd) {
+		for _, f := range c.VarFiles {
+			ret = append(ret, "-var-file="+f)
+		}
+	}
+	if c.Parallelism > 0 && projectArgAccepted("-parallelism", cmd) {
+		ret = append(ret, "-parallelism="+strconv.Itoa(c.Parallelism))
+	}
+	if c.PlanSummary != "" && projectArgAccepted("-summary", cmd) {
+		ret = append(ret, "-summary="+c.PlanSummary)
+	}
+	return ret
+}
+
+func projectArgAccepted(arg, cmd string) bool {
+	for _, name := range projectArgCommands[arg] {
+		if name == cmd {
+			return true
+		}
+	}
+	return false
+}
+
+// projectEnvironmentNames are the environment variables other than input
+// variables that the project configuration can set.
+//
+// The project configuration comes from the repository being worked on, which
+// may not be trusted, and OpenTofu, git, and the plugins and provisioners
+// that OpenTofu runs all inherit its environment variables. Variables like
+// PATH, LD_PRELOAD or GIT_SSH_COMMAND would let it run arbitrary programs,
+// so only variables which merely select OpenTofu's behavior are allowed.
+var projectEnvironmentNames = []string{"TF_IN_AUTOMATION", "TF_INPUT"}
+
+// projectEnvironmentAllowed returns true if the project configuration can
+// set the environment variable with the given name.
+func projectEnvironmentAllowed(name string) bool {
+	if strings.HasPrefix(name, "TF_VAR_") && len(name) > len("TF_VAR_") {
+		return true
+	}
+	for _, allowed := range projectEnvironmentNames {
+		if name == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// SetEnvironment sets each of the environment variables from the project
+// configuration which are not already set, so that explicitly-set
+// environment variables take precedence. It ignores any variable that the
+// project configuration is not allowed to set, which Validate reports.
+func (c *ProjectConfig) SetEnvironment() error {
+	if c == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(c.Environment))
+	for name := range c.Environment {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !projectEnvironmentAllowed(name) {
+			continue
+		}
+		if _, ok := os.LookupEnv(name); ok {
+			continue
+		}
+		if err := os.Setenv(name, c.Environment[name]); err != nil {
+			return fmt.Errorf("failed to set %s from the project configuration: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// MergeProject returns a new configuration with the settings from the given
+// project configuration filled in wherever the receiver leaves them unset.
+func (c *Config) MergeProject(project *ProjectConfig) *Config {
+	result := *c
+	if project == nil {
+		return &result
+	}
+	result.ModuleAliases = project.ModuleAliases
+	result.PartialModulePackages = project.PartialModulePackages
+	return &result
+}
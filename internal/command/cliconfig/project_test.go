@@ -0,0 +1,220 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cliconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFindProjectConfig(t *testing.T) {
+	td := t.TempDir()
+	t.Setenv("HOME", td)
+	t.Setenv("TF_CLI_CONFIG_FILE", "")
+
+	repo := filepath.Join(td, "repo")
+	sub := filepath.Join(repo, "stacks", "network")
+	for _, dir := range []string{sub, filepath.Join(repo, ".git")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The user-level CLI configuration in the home directory is not a
+	// project configuration file, and in any case we stop searching at the
+	// root of the repository.
+	writeTestFile(t, filepath.Join(td, ".tofurc"), "")
+
+	got, err := FindProjectConfig(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "" {
+		t.Fatalf("unexpected project configuration %s", got)
+	}
+
+	writeTestFile(t, filepath.Join(repo, ".tofurc"), "")
+	got, err = FindProjectConfig(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(repo, ".tofurc"); got != want {
+		t.Fatalf("wrong project configuration %s; want %s", got, want)
+	}
+
+	writeTestFile(t, filepath.Join(repo, "tofu.project.hcl"), "")
+	got, err = FindProjectConfig(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(repo, "tofu.project.hcl"); got != want {
+		t.Fatalf("wrong project configuration %s; want %s", got, want)
+	}
+}
+
+func TestLoadProjectConfig(t *testing.T) {
+	td := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(td, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(td, "tofu.project.hcl"), `
+var_files    = ["common.tfvars", "/etc/tofu/site.tfvars"]
+parallelism  = 20
+plan_summary = "module"
+
+environment = {
+  TF_IN_AUTOMATION = "1"
+}
+
+module_aliases = {
+  networking = "modules/networking"
+  shared     = "/srv/modules"
+}
+
+partial_module_packages = true
+`)
+
+	got, diags := LoadProjectConfig(td)
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	want := &ProjectConfig{
+		Filename:    filepath.Join(td, "tofu.project.hcl"),
+		VarFiles:    []string{filepath.Join(td, "common.tfvars"), "/etc/tofu/site.tfvars"},
+		Parallelism: 20,
+		PlanSummary: "module",
+		Environment: map[string]string{"TF_IN_AUTOMATION": "1"},
+		ModuleAliases: map[string]string{
+			"networking": filepath.Join(td, "modules", "networking"),
+			"shared":     "/srv/modules",
+		},
+		PartialModulePackages: true,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("wrong result\n%s", diff)
+	}
+
+	if diff := cmp.Diff([]string{
+		"-var-file=" + filepath.Join(td, "common.tfvars"),
+		"-var-file=/etc/tofu/site.tfvars",
+		"-parallelism=20",
+		"-summary=module",
+	}, got.Args("plan")); diff != "" {
+		t.Fatalf("wrong args for plan\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{
+		"-var-file=" + filepath.Join(td, "common.tfvars"),
+		"-var-file=/etc/tofu/site.tfvars",
+	}, got.Args("console")); diff != "" {
+		t.Fatalf("wrong args for console\n%s", diff)
+	}
+	if args := got.Args("init"); len(args) != 0 {
+		t.Fatalf("unexpected args for init: %#v", args)
+	}
+
+	// The plugin cache directory comes only from the user-level
+	// configuration, so that a repository can't choose which provider
+	// packages are installed from it.
+	if got := (&Config{PluginCacheDir: "/user/cache"}).MergeProject(got); got.PluginCacheDir != "/user/cache" {
+		t.Fatalf("wrong plugin cache dir %s", got.PluginCacheDir)
+	}
+	if got := (&Config{}).MergeProject(got); got.ModuleAliases["networking"] != filepath.Join(td, "modules", "networking") {
+		t.Fatalf("wrong module aliases %#v", got.ModuleAliases)
+	}
+	if got := (&Config{}).MergeProject(got); !got.PartialModulePackages {
+		t.Fatalf("partial module packages not enabled")
+	}
+}
+
+func TestLoadProjectConfig_invalid(t *testing.T) {
+	td := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(td, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(td, "tofu.project.hcl"), `
+parallelism  = -1
+plan_summary = "tree"
+
+module_aliases = {
+  "1st" = "modules"
+}
+
+environment = {
+  TF_VAR_region = "eu-west-1"
+  LD_PRELOAD    = "./evil.so"
+}
+`)
+
+	_, diags := LoadProjectConfig(td)
+	if got, want := len(diags), 4; got != want {
+		t.Fatalf("wrong number of diagnostics %d; want %d\n%s", got, want, diags.Err())
+	}
+}
+
+func TestProjectConfigValidate_environment(t *testing.T) {
+	c := &ProjectConfig{
+		Filename: "tofu.project.hcl",
+		Environment: map[string]string{
+			"TF_VAR_region":    "eu-west-1",
+			"TF_IN_AUTOMATION": "1",
+			"GIT_SSH_COMMAND":  "./evil.sh",
+		},
+	}
+	diags := c.Validate()
+	if got, want := len(diags), 1; got != want {
+		t.Fatalf("wrong number of diagnostics %d; want %d\n%s", got, want, diags.Err())
+	}
+	want := "The environment setting in tofu.project.hcl cannot set GIT_SSH_COMMAND: only input variables (TF_VAR_*) and TF_IN_AUTOMATION, TF_INPUT can be set from the project configuration"
+	if got := diags[0].Description().Summary; got != want {
+		t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestProjectConfigSetEnvironment(t *testing.T) {
+	for _, name := range []string{"TF_VAR_region", "TF_IN_AUTOMATION", "GIT_SSH_COMMAND", "TF_CLI_CONFIG_FILE", "TF_VAR_"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	t.Setenv("TF_INPUT", "1")
+
+	c := &ProjectConfig{
+		Environment: map[string]string{
+			"TF_VAR_region":      "eu-west-1",
+			"TF_IN_AUTOMATION":   "1",
+			"TF_INPUT":           "0",
+			"GIT_SSH_COMMAND":    "./evil.sh",
+			"TF_CLI_CONFIG_FILE": "./evil.tfrc",
+			"TF_VAR_":            "x",
+		},
+	}
+	if err := c.SetEnvironment(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{
+		"TF_VAR_region":    "eu-west-1",
+		"TF_IN_AUTOMATION": "1",
+		// Variables that are already set take precedence.
+		"TF_INPUT": "1",
+	} {
+		if got := os.Getenv(name); got != want {
+			t.Errorf("wrong value for %s %q; want %q", name, got, want)
+		}
+	}
+	for _, name := range []string{"GIT_SSH_COMMAND", "TF_CLI_CONFIG_FILE", "TF_VAR_"} {
+		if got, ok := os.LookupEnv(name); ok {
+			t.Errorf("disallowed variable %s was set to %q", name, got)
+		}
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
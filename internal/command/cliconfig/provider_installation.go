@@ -6,9 +6,11 @@ package cliconfig
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/hashicorp/hcl"
 	hclast "github.com/hashicorp/hcl/hcl/ast"
+	"golang.org/x/exp/slices"
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/getproviders"
@@ -36,6 +38,47 @@ type ProviderInstallation struct {
 	// providers, because they are still subject to version constraints and
 	// checksum verification.
 	DevOverrides map[addrs.Provider]getproviders.PackageLocalDir
+
+	// ScopedDevOverrides are development overrides that apply only in
+	// particular working directories or workspaces, so that a developer
+	// can't accidentally use a local build of a provider elsewhere.
+	ScopedDevOverrides []*ScopedDevOverrides
+}
+
+// ScopedDevOverrides is the content of a dev_overrides block that has
+// working_dirs or workspaces arguments limiting where it applies.
+type ScopedDevOverrides struct {
+	// WorkingDirs, if not empty, are the directories where the overrides
+	// apply. The overrides also apply in subdirectories of these
+	// directories.
+	WorkingDirs []string
+
+	// Workspaces, if not empty, are the names of the workspaces where the
+	// overrides apply.
+	Workspaces []string
+
+	Overrides map[addrs.Provider]getproviders.PackageLocalDir
+}
+
+// Applies returns true if the overrides apply when OpenTofu runs in the given
+// working directory with the given workspace selected.
+func (s *ScopedDevOverrides) Applies(workingDir, workspace string) bool {
+	if len(s.Workspaces) > 0 && !slices.Contains(s.Workspaces, workspace) {
+		return false
+	}
+	if len(s.WorkingDirs) == 0 {
+		return true
+	}
+	for _, dir := range s.WorkingDirs {
+		rel, err := filepath.Rel(dir, workingDir)
+		if err != nil {
+			continue
+		}
+		if rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
 }
 
 // decodeProviderInstallationFromConfig uses the HCL AST API directly to
@@ -213,6 +256,33 @@ func decodeProviderInstallationFromConfig(hclFile *hclast.File) ([]*ProviderInst
 				location = ProviderInstallationNetworkMirror(bodyContent.URL)
 				include = bodyContent.Include
 				exclude = bodyContent.Exclude
+			case "oci_mirror":
+				type BodyContent struct {
+					RepositoryTemplate string   `hcl:"repository_template"`
+					Include            []string `hcl:"include"`
+					Exclude            []string `hcl:"exclude"`
+				}
+				var bodyContent BodyContent
+				err := hcl.DecodeObject(&bodyContent, methodBody)
+				if err != nil {
+					diags = diags.Append(tfdiags.Sourceless(
+						tfdiags.Error,
+						"Invalid provider_installation method block",
+						fmt.Sprintf("Invalid %s block at %s: %s.", methodTypeStr, block.Pos(), err),
+					))
+					continue
+				}
+				if bodyContent.RepositoryTemplate == "" {
+					diags = diags.Append(tfdiags.Sourceless(
+						tfdiags.Error,
+						"Invalid provider_installation method block",
+						fmt.Sprintf("Invalid %s block at %s: \"repository_template\" argument is required.", methodTypeStr, block.Pos()),
+					))
+					continue
+				}
+				location = ProviderInstallationOCIMirror(bodyContent.RepositoryTemplate)
+				include = bodyContent.Include
+				exclude = bodyContent.Exclude
 			case "dev_overrides":
 				if len(pi.Methods) > 0 {
 					// We require dev_overrides to appear first if it's present,
@@ -228,13 +298,43 @@ func decodeProviderInstallationFromConfig(hclFile *hclast.File) ([]*ProviderInst
 					continue
 				}
 
-				// The content of a dev_overrides block is a mapping from
-				// provider source addresses to local filesystem paths. To get
-				// our decoding started, we'll use the normal HCL decoder to
-				// populate a map of strings and then decode further from
-				// that.
+				// A dev_overrides block can optionally have working_dirs and
+				// workspaces arguments that limit where its overrides apply.
+				// Those names can't be confused with provider source
+				// addresses because provider type names can't contain
+				// underscores.
+				type ScopeContent struct {
+					WorkingDirs []string `hcl:"working_dirs"`
+					Workspaces  []string `hcl:"workspaces"`
+				}
+				var scopeContent ScopeContent
+				scopeBody := &hclast.ObjectType{List: &hclast.ObjectList{}}
+				overridesBody := &hclast.ObjectType{List: &hclast.ObjectList{}}
+				for _, item := range methodBody.List.Items {
+					switch item.Keys[0].Token.Value() {
+					case "working_dirs", "workspaces":
+						scopeBody.List.Add(item)
+					default:
+						overridesBody.List.Add(item)
+					}
+				}
+				err := hcl.DecodeObject(&scopeContent, scopeBody)
+				if err != nil {
+					diags = diags.Append(tfdiags.Sourceless(
+						tfdiags.Error,
+						"Invalid provider_installation method block",
+						fmt.Sprintf("Invalid %s block at %s: %s.", methodTypeStr, block.Pos(), err),
+					))
+					continue
+				}
+
+				// The rest of the content of a dev_overrides block is a
+				// mapping from provider source addresses to local filesystem
+				// paths. To get our decoding started, we'll use the normal
+				// HCL decoder to populate a map of strings and then decode
+				// further from that.
 				var rawItems map[string]string
-				err := hcl.DecodeObject(&rawItems, methodBody)
+				err = hcl.DecodeObject(&rawItems, overridesBody)
 				if err != nil {
 					diags = diags.Append(tfdiags.Sourceless(
 						tfdiags.Error,
@@ -244,6 +344,19 @@ func decodeProviderInstallationFromConfig(hclFile *hclast.File) ([]*ProviderInst
 					continue
 				}
 
+				overrides := devOverrides
+				if len(scopeContent.WorkingDirs) > 0 || len(scopeContent.Workspaces) > 0 {
+					scoped := &ScopedDevOverrides{
+						Workspaces: scopeContent.Workspaces,
+						Overrides:  make(map[addrs.Provider]getproviders.PackageLocalDir),
+					}
+					for _, dir := range scopeContent.WorkingDirs {
+						scoped.WorkingDirs = append(scoped.WorkingDirs, filepath.Clean(dir))
+					}
+					pi.ScopedDevOverrides = append(pi.ScopedDevOverrides, scoped)
+					overrides = scoped.Overrides
+				}
+
 				for rawAddr, rawPath := range rawItems {
 					addr, moreDiags := addrs.ParseProviderSourceString(rawAddr)
 					if moreDiags.HasErrors() {
@@ -255,7 +368,7 @@ func decodeProviderInstallationFromConfig(hclFile *hclast.File) ([]*ProviderInst
 						continue
 					}
 					dirPath := filepath.Clean(rawPath)
-					devOverrides[addr] = getproviders.PackageLocalDir(dirPath)
+					overrides[addr] = getproviders.PackageLocalDir(dirPath)
 				}
 
 				continue // We won't add anything to pi.Methods for this one
@@ -301,6 +414,7 @@ type ProviderInstallationMethod struct {
 //   - [ProviderInstallationDirect]:                 install from the provider's origin registry
 //   - [ProviderInstallationFilesystemMirror] (dir): install from a local filesystem mirror
 //   - [ProviderInstallationNetworkMirror] (host):   install from a network mirror
+//   - [ProviderInstallationOCIMirror] (template):   install from repositories in an OCI registry
 type ProviderInstallationLocation interface {
 	providerInstallationLocation()
 }
@@ -339,3 +453,15 @@ func (i ProviderInstallationNetworkMirror) providerInstallationLocation() {}
 func (i ProviderInstallationNetworkMirror) GoString() string {
 	return fmt.Sprintf("cliconfig.ProviderInstallationNetworkMirror(%q)", i)
 }
+
+// ProviderInstallationOCIMirror is a ProviderInstallationSourceLocation
+// representing installation from repositories in an OCI artifact registry.
+// The string value is the template for the address of each provider's
+// repository, exactly as written in the configuration.
+type ProviderInstallationOCIMirror string
+
+func (i ProviderInstallationOCIMirror) providerInstallationLocation() {}
+
+func (i ProviderInstallationOCIMirror) GoString() string {
+	return fmt.Sprintf("cliconfig.ProviderInstallationOCIMirror(%q)", i)
+}
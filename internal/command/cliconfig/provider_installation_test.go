@@ -36,6 +36,10 @@ func TestLoadConfig_providerInstallation(t *testing.T) {
 							{
 								Location: ProviderInstallationFilesystemMirror("/tmp/example2"),
 							},
+							{
+								Location: ProviderInstallationOCIMirror("ghcr.io/example/terraform-provider-${type}"),
+								Include:  []string{"example.net/*/*"},
+							},
 							{
 								Location: ProviderInstallationDirect,
 								Exclude:  []string{"example.com/*/*"},
@@ -46,6 +50,15 @@ func TestLoadConfig_providerInstallation(t *testing.T) {
 							addrs.MustParseProviderSourceString("hashicorp/boop"):  getproviders.PackageLocalDir(filepath.FromSlash("/tmp/boop")),
 							addrs.MustParseProviderSourceString("hashicorp/blorp"): getproviders.PackageLocalDir(filepath.FromSlash("/tmp/blorp")),
 						},
+						ScopedDevOverrides: []*ScopedDevOverrides{
+							{
+								WorkingDirs: []string{filepath.FromSlash("/tmp/infra-dev")},
+								Workspaces:  []string{"dev"},
+								Overrides: map[addrs.Provider]getproviders.PackageLocalDir{
+									addrs.MustParseProviderSourceString("hashicorp/zap"): getproviders.PackageLocalDir(filepath.FromSlash("/tmp/zap")),
+								},
+							},
+						},
 					},
 				},
 			}
@@ -57,6 +70,30 @@ func TestLoadConfig_providerInstallation(t *testing.T) {
 	}
 }
 
+func TestScopedDevOverrides(t *testing.T) {
+	scoped := &ScopedDevOverrides{
+		WorkingDirs: []string{filepath.FromSlash("/home/dev/infra")},
+		Workspaces:  []string{"dev", "test"},
+	}
+	tests := []struct {
+		workingDir string
+		workspace  string
+		want       bool
+	}{
+		{"/home/dev/infra", "dev", true},
+		{"/home/dev/infra/staging", "test", true},
+		{"/home/dev/infra", "prod", false},
+		{"/home/dev/infra-prod", "dev", false},
+		{"/home/dev", "dev", false},
+	}
+	for _, test := range tests {
+		workingDir := filepath.FromSlash(test.workingDir)
+		if got := scoped.Applies(workingDir, test.workspace); got != test.want {
+			t.Errorf("wrong result for %s in workspace %q: got %t, want %t", workingDir, test.workspace, got, test.want)
+		}
+	}
+}
+
 func TestLoadConfig_providerInstallationErrors(t *testing.T) {
 	_, diags := loadConfigFile(filepath.Join(fixtureDir, "provider-installation-errors"))
 	want := `7 problems:
@@ -274,7 +274,7 @@ func testFileEquals(t *testing.T, got, want string) {
 func testReadPlan(t *testing.T, path string) *plans.Plan {
 	t.Helper()
 
-	f, err := planfile.Open(path)
+	f, err := planfile.Open(path, nil)
 	if err != nil {
 		t.Fatalf("error opening plan file %q: %s", path, err)
 	}
@@ -27,6 +27,7 @@ func (c *ConsoleCommand) modeInteractive(session *repl.Session, ui cli.Ui) int {
 		InterruptPrompt:   "^C",
 		EOFPrompt:         "exit",
 		HistorySearchFold: true,
+		AutoComplete:      consoleCompleter{session},
 		Stdin:             os.Stdin,
 		Stdout:            os.Stdout,
 		Stderr:            os.Stderr,
@@ -65,3 +66,23 @@ func (c *ConsoleCommand) modeInteractive(session *repl.Session, ui cli.Ui) int {
 
 	return 0
 }
+
+// consoleCompleter completes function names in the interactive console.
+type consoleCompleter struct {
+	session *repl.Session
+}
+
+func (c consoleCompleter) Do(line []rune, pos int) ([][]rune, int) {
+	prefix := string(line[:pos])
+	names, length := c.session.CompleteFunctionName(prefix, len(prefix))
+
+	// Readline expects only the part of each candidate after what has
+	// already been typed.
+	suffixes := make([][]rune, len(names))
+	for i, name := range names {
+		suffixes[i] = []rune(name[length:] + "(")
+	}
+	// Function names are ASCII, so the length of the partial name is the
+	// same in bytes and runes.
+	return suffixes, length
+}
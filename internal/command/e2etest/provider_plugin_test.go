@@ -78,6 +78,12 @@ func TestProviderProtocols(t *testing.T) {
 		t.Fatalf("wrong output:\nstdout:%s\nstderr%s", stdout, stderr)
 	}
 
+	for _, want := range []string{`function-proto5 = "proto5"`, `function-proto6 = "proto6"`} {
+		if !strings.Contains(stdout, want) {
+			t.Fatalf("output does not contain %q:\nstdout:%s\nstderr%s", want, stdout, stderr)
+		}
+	}
+
 	/// DESTROY
 	stdout, stderr, err = tf.Run("destroy", "-auto-approve")
 	if err != nil {
@@ -18,3 +18,11 @@ resource "simple_resource" "test-proto5" {
 resource "simple_resource" "test-proto6" {
   provider = simple6
 }
+
+output "function-proto5" {
+  value = provider::simple5::noop("proto5")
+}
+
+output "function-proto6" {
+  value = provider::simple6::noop("proto6")
+}
@@ -70,6 +70,12 @@ func (p *providerServer) ApplyResourceChange(ctx context.Context, req *proto.App
 	return p.ProviderServer.ApplyResourceChange(ctx, req)
 }
 
+// CallChunked serves chunked calls through the methods above, rather than
+// those of the wrapped server.
+func (p *providerServer) CallChunked(stream proto.Provider_CallChunkedServer) error {
+	return tfplugin.ServeChunked(p, stream)
+}
+
 func (p *providerServer) PlanResourceChangeCalled() bool {
 	p.Lock()
 	defer p.Unlock()
@@ -119,6 +125,12 @@ func (p *providerServer5) ApplyResourceChange(ctx context.Context, req *proto5.A
 	return p.ProviderServer.ApplyResourceChange(ctx, req)
 }
 
+// CallChunked serves chunked calls through the methods above, rather than
+// those of the wrapped server.
+func (p *providerServer5) CallChunked(stream proto5.Provider_CallChunkedServer) error {
+	return tfplugin5.ServeChunked(p, stream)
+}
+
 func (p *providerServer5) PlanResourceChangeCalled() bool {
 	p.Lock()
 	defer p.Unlock()
@@ -17,6 +17,7 @@ import (
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/mitchellh/cli"
+	"gopkg.in/yaml.v3"
 
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/tfdiags"
@@ -31,6 +32,8 @@ var (
 		".tf",
 		".tfvars",
 		".tftest.hcl",
+		".tf.yaml",
+		".tofu.yaml",
 	}
 )
 
@@ -44,6 +47,17 @@ type FmtCommand struct {
 	check     bool
 	recursive bool
 	input     io.Reader // STDIN if nil
+
+	// rules, if not nil, are the additional formatting rules loaded from
+	// a formatting rules file.
+	rules *fmtRules
+}
+
+// fmtStage is the result of one stage of formatting, which is either the
+// canonical formatting or one of the additional formatting rules.
+type fmtStage struct {
+	rule   string
+	result []byte
 }
 
 func (c *FmtCommand) Run(args []string) int {
@@ -58,6 +72,8 @@ func (c *FmtCommand) Run(args []string) int {
 	cmdFlags.BoolVar(&c.diff, "diff", false, "diff")
 	cmdFlags.BoolVar(&c.check, "check", false, "check")
 	cmdFlags.BoolVar(&c.recursive, "recursive", false, "recursive")
+	var rulesPath string
+	cmdFlags.StringVar(&rulesPath, "config", "", "config")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
@@ -66,6 +82,13 @@ func (c *FmtCommand) Run(args []string) int {
 
 	args = cmdFlags.Args()
 
+	rules, rulesDiags := c.loadFmtRules(rulesPath)
+	if rulesDiags.HasErrors() {
+		c.showDiagnostics(rulesDiags)
+		return 2
+	}
+	c.rules = rules
+
 	var paths []string
 	if len(args) == 0 {
 		paths = []string{"."}
@@ -158,7 +181,7 @@ func (c *FmtCommand) fmt(paths []string, stdin io.Reader, stdout io.Writer) tfdi
 			}
 
 			if !fmtd {
-				diags = diags.Append(fmt.Errorf("Only .tf, .tfvars, and .tftest.hcl files can be processed with tofu fmt"))
+				diags = diags.Append(fmt.Errorf("Only .tf, .tfvars, .tftest.hcl, .tf.yaml, and .tofu.yaml files can be processed with tofu fmt"))
 				continue
 			}
 		}
@@ -182,16 +205,27 @@ func (c *FmtCommand) processFile(path string, r io.Reader, w io.Writer, isStdout
 	// diagnostic errors can include the source code snippet
 	c.registerSynthConfigSource(path, src)
 
-	// File must be parseable as HCL native syntax before we'll try to format
-	// it. If not, the formatter is likely to make drastic changes that would
-	// be hard for the user to undo.
-	_, syntaxDiags := hclsyntax.ParseConfig(src, path, hcl.Pos{Line: 1, Column: 1})
-	if syntaxDiags.HasErrors() {
-		diags = diags.Append(syntaxDiags)
-		return diags
-	}
+	var stages []fmtStage
+	if configs.IsYAMLConfigFile(path) {
+		result, yamlDiags := c.formatYAMLSourceCode(src, path)
+		diags = diags.Append(yamlDiags)
+		if yamlDiags.HasErrors() {
+			return diags
+		}
+		stages = []fmtStage{{"canonical", result}}
+	} else {
+		// File must be parseable as HCL native syntax before we'll try to format
+		// it. If not, the formatter is likely to make drastic changes that would
+		// be hard for the user to undo.
+		_, syntaxDiags := hclsyntax.ParseConfig(src, path, hcl.Pos{Line: 1, Column: 1})
+		if syntaxDiags.HasErrors() {
+			diags = diags.Append(syntaxDiags)
+			return diags
+		}
 
-	result := c.formatSourceCode(src, path)
+		stages = c.formatStages(src, path)
+	}
+	result := stages[len(stages)-1].result
 
 	if !bytes.Equal(src, result) {
 		// Something was changed
@@ -205,7 +239,25 @@ func (c *FmtCommand) processFile(path string, r io.Reader, w io.Writer, isStdout
 				return diags
 			}
 		}
-		if c.diff {
+		if c.diff && c.check && c.rules != nil {
+			// With additional rules, a check reports the changes for each
+			// rule separately so that each violation can be understood
+			// on its own.
+			prev := src
+			for _, stage := range stages {
+				if bytes.Equal(prev, stage.result) {
+					continue
+				}
+				diff, err := bytesDiff(prev, stage.result, path)
+				if err != nil {
+					diags = diags.Append(fmt.Errorf("Failed to generate diff for %s: %w", path, err))
+					return diags
+				}
+				fmt.Fprintf(w, "%s: violates rule %q\n", path, stage.rule)
+				w.Write(diff)
+				prev = stage.result
+			}
+		} else if c.diff {
 			diff, err := bytesDiff(src, result, path)
 			if err != nil {
 				diags = diags.Append(fmt.Errorf("Failed to generate diff for %s: %w", path, err))
@@ -284,6 +336,24 @@ func (c *FmtCommand) processDir(path string, stdout io.Writer) tfdiags.Diagnosti
 	return diags
 }
 
+// formatStages applies the canonical formatting and then each of the
+// additional formatting rules in turn, returning the result of each stage.
+// The result of the last stage is the fully-formatted source code.
+func (c *FmtCommand) formatStages(src []byte, filename string) []fmtStage {
+	result := c.formatSourceCode(src, filename)
+	if c.rules == nil {
+		return []fmtStage{{"canonical", result}}
+	}
+
+	result = c.rules.alignEquals(result)
+	stages := []fmtStage{{"canonical", result}}
+	for _, rule := range c.rules.enabled() {
+		result = rule.apply(result)
+		stages = append(stages, fmtStage{rule.name, result})
+	}
+	return stages
+}
+
 // formatSourceCode is the formatting logic itself, applied to each file that
 // is selected (directly or indirectly) on the command line.
 func (c *FmtCommand) formatSourceCode(src []byte, filename string) []byte {
@@ -300,6 +370,38 @@ func (c *FmtCommand) formatSourceCode(src []byte, filename string) []byte {
 	return f.Bytes()
 }
 
+// formatYAMLSourceCode formats a configuration file written in the YAML
+// syntax by re-encoding its document with a consistent indentation. Comments
+// and the style of each value, such as quoting, are preserved. The
+// additional formatting rules apply only to the native syntax.
+func (c *FmtCommand) formatYAMLSourceCode(src []byte, filename string) ([]byte, hcl.Diagnostics) {
+	root, diags := configs.ParseYAMLNode(src, filename)
+	if diags.HasErrors() || root == nil {
+		return src, diags
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to format YAML",
+			Detail:   fmt.Sprintf("The file %s could not be formatted: %s.", filename, err),
+		})
+		return src, diags
+	}
+	if err := enc.Close(); err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to format YAML",
+			Detail:   fmt.Sprintf("The file %s could not be formatted: %s.", filename, err),
+		})
+		return src, diags
+	}
+	return buf.Bytes(), diags
+}
+
 func (c *FmtCommand) formatBody(body *hclwrite.Body, inBlocks []string) {
 	attrs := body.Attributes()
 	for name, attr := range attrs {
@@ -550,7 +652,8 @@ Usage: tofu [global options] fmt [options] [target...]
 
   Rewrites all OpenTofu configuration files to a canonical format. All
   configuration files (.tf), variables files (.tfvars), and testing files 
-  (.tftest.hcl) are updated. JSON files (.tf.json, .tfvars.json, or 
+  (.tftest.hcl) are updated. YAML configuration files (.tf.yaml or
+  .tofu.yaml) are re-indented. JSON files (.tf.json, .tfvars.json, or 
   .tftest.json) are not modified.
 
   By default, fmt scans the current directory for configuration files. If you
@@ -559,8 +662,8 @@ Usage: tofu [global options] fmt [options] [target...]
   file. If you provide a single dash ("-"), then fmt will read from standard
   input (STDIN).
 
-  The content must be in the OpenTofu language native syntax; JSON is not
-  supported.
+  Content read from standard input must be in the OpenTofu language native
+  syntax; JSON is not supported.
 
 Options:
 
@@ -579,6 +682,11 @@ Options:
 
   -recursive     Also process files in subdirectories. By default, only the
                  given directory (or current directory) is processed.
+
+  -config=path   Read additional formatting rules from the given file,
+                 instead of from .tofufmt.hcl in the current directory. When
+                 rules are in use, -check together with -diff shows the
+                 changes needed for each rule separately.
 `
 	return strings.TrimSpace(helpText)
 }
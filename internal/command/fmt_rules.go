@@ -0,0 +1,455 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// fmtRulesFilename is the name of the file in the current working directory
+// which configures additional formatting rules for "tofu fmt", unless the
+// -config option selects a different file.
+const fmtRulesFilename = ".tofufmt.hcl"
+
+// The supported values for the attribute_order formatting rule.
+const (
+	fmtAttributeOrderNone          = "none"
+	fmtAttributeOrderAlphabetical  = "alphabetical"
+	fmtAttributeOrderMetaArguments = "meta_arguments"
+)
+
+// fmtMetaArguments are the meta-arguments which the "meta_arguments"
+// attribute order places before all other arguments, in this order.
+var fmtMetaArguments = []string{"source", "version", "count", "for_each", "provider"}
+
+// fmtRules is the decoded form of a formatting rules file, which extends
+// the canonical formatting style with additional rules.
+type fmtRules struct {
+	// AlignEquals controls whether the equals signs of consecutive
+	// attributes are aligned, as in the canonical style. If false, each
+	// equals sign is separated from the attribute name by a single space.
+	AlignEquals bool
+
+	// AttributeOrder is one of the fmtAttributeOrder constants, selecting
+	// how consecutive attributes in the same block are ordered.
+	AttributeOrder string
+
+	// MaxBlankLines is the maximum number of consecutive blank lines, or -1
+	// to leave blank lines unchanged. When enabled, blank lines are also
+	// removed at the start and end of files and blocks.
+	MaxBlankLines int
+
+	// ListWrapThreshold, if greater than zero, is the maximum number of
+	// elements in a list written on a single line. Longer lists are written
+	// with one element per line.
+	ListWrapThreshold int
+}
+
+// fmtRule is a single formatting rule which can be applied to source code
+// that is already in the canonical style.
+type fmtRule struct {
+	name  string
+	apply func(src []byte) []byte
+}
+
+var fmtRulesSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "align_equals"},
+		{Name: "attribute_order"},
+		{Name: "max_blank_lines"},
+		{Name: "list_wrap_threshold"},
+	},
+}
+
+// loadFmtRules reads the formatting rules from the given file. If the
+// filename is empty then the rules are read from the default file in the
+// current working directory if it exists, and otherwise loadFmtRules
+// returns nil to select only the canonical style.
+func (c *FmtCommand) loadFmtRules(filename string) (*fmtRules, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	if filename == "" {
+		if _, err := os.Stat(fmtRulesFilename); err != nil {
+			return nil, diags
+		}
+		filename = fmtRulesFilename
+	}
+
+	body, bodyDiags := c.loadHCLFile(filename)
+	diags = diags.Append(bodyDiags)
+	if bodyDiags.HasErrors() {
+		return nil, diags
+	}
+
+	content, hclDiags := body.Content(fmtRulesSchema)
+	diags = diags.Append(hclDiags)
+	if hclDiags.HasErrors() {
+		return nil, diags
+	}
+
+	rules := &fmtRules{
+		AlignEquals:    true,
+		AttributeOrder: fmtAttributeOrderNone,
+		MaxBlankLines:  -1,
+	}
+	if attr, ok := content.Attributes["align_equals"]; ok {
+		diags = diags.Append(gohcl.DecodeExpression(attr.Expr, nil, &rules.AlignEquals))
+	}
+	if attr, ok := content.Attributes["attribute_order"]; ok {
+		hclDiags := gohcl.DecodeExpression(attr.Expr, nil, &rules.AttributeOrder)
+		diags = diags.Append(hclDiags)
+		if !hclDiags.HasErrors() {
+			switch rules.AttributeOrder {
+			case fmtAttributeOrderNone, fmtAttributeOrderAlphabetical, fmtAttributeOrderMetaArguments:
+			default:
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid attribute order",
+					Detail:   fmt.Sprintf("The attribute_order rule must be %q, %q, or %q.", fmtAttributeOrderNone, fmtAttributeOrderAlphabetical, fmtAttributeOrderMetaArguments),
+					Subject:  attr.Expr.Range().Ptr(),
+				})
+			}
+		}
+	}
+	for name, target := range map[string]*int{
+		"max_blank_lines":     &rules.MaxBlankLines,
+		"list_wrap_threshold": &rules.ListWrapThreshold,
+	} {
+		attr, ok := content.Attributes[name]
+		if !ok {
+			continue
+		}
+		hclDiags := gohcl.DecodeExpression(attr.Expr, nil, target)
+		diags = diags.Append(hclDiags)
+		if !hclDiags.HasErrors() && *target < 0 {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid formatting rule",
+				Detail:   fmt.Sprintf("The %s rule must not be negative.", name),
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+		}
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return rules, diags
+}
+
+// enabled returns the rules which are applied after the canonical
+// formatting, in the order they must be applied.
+func (r *fmtRules) enabled() []fmtRule {
+	// Removing blank lines can join groups of attributes, so blank lines
+	// must be normalized before attributes are ordered.
+	var ret []fmtRule
+	if r.MaxBlankLines >= 0 {
+		ret = append(ret, fmtRule{"max_blank_lines", r.normalizeBlankLines})
+	}
+	if r.ListWrapThreshold > 0 {
+		ret = append(ret, fmtRule{"list_wrap_threshold", r.wrapLists})
+	}
+	if r.AttributeOrder != fmtAttributeOrderNone {
+		ret = append(ret, fmtRule{"attribute_order", r.orderAttributes})
+	}
+	return ret
+}
+
+// reformat restores the canonical formatting of source code which was
+// changed by a rule, taking into account the align_equals rule.
+func (r *fmtRules) reformat(src []byte) []byte {
+	return r.alignEquals(hclwrite.Format(src))
+}
+
+// alignEquals implements the align_equals rule, which is part of the
+// canonical formatting stage because the canonical style always aligns
+// equals signs.
+func (r *fmtRules) alignEquals(src []byte) []byte {
+	if r.AlignEquals {
+		return src
+	}
+
+	tokens, diags := hclsyntax.LexConfig(src, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return src
+	}
+
+	var buf bytes.Buffer
+	pos := 0
+	for i := 1; i < len(tokens); i++ {
+		tok, prev := tokens[i], tokens[i-1]
+		if tok.Type != hclsyntax.TokenEqual || prev.Range.End.Line != tok.Range.Start.Line {
+			continue
+		}
+		gap := src[prev.Range.End.Byte:tok.Range.Start.Byte]
+		if len(gap) <= 1 || len(bytes.Trim(gap, " ")) != 0 {
+			continue
+		}
+		buf.Write(src[pos:prev.Range.End.Byte])
+		buf.WriteByte(' ')
+		pos = tok.Range.Start.Byte
+	}
+	buf.Write(src[pos:])
+	return buf.Bytes()
+}
+
+// orderAttributes implements the attribute_order rule. Only attributes on
+// consecutive lines are reordered, so that any blank lines and comments
+// between attributes continue to separate the same groups of attributes.
+func (r *fmtRules) orderAttributes(src []byte) []byte {
+	file, diags := hclsyntax.ParseConfig(src, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return src
+	}
+	lines := fmtSplitLines(src)
+	replacements := make(map[int][]fmtAttributeLines)
+
+	_ = hclsyntax.VisitAll(file.Body.(*hclsyntax.Body), func(node hclsyntax.Node) hcl.Diagnostics {
+		body, ok := node.(*hclsyntax.Body)
+		if !ok {
+			return nil
+		}
+		attrs := make([]*hclsyntax.Attribute, 0, len(body.Attributes))
+		for _, attr := range body.Attributes {
+			attrs = append(attrs, attr)
+		}
+		sort.Slice(attrs, func(i, j int) bool {
+			return attrs[i].SrcRange.Start.Byte < attrs[j].SrcRange.Start.Byte
+		})
+
+		var group []fmtAttributeLines
+		groupStart, groupEnd := 0, 0
+		flush := func() {
+			if len(group) > 1 {
+				replacements[groupStart] = r.sortAttributes(group)
+			}
+			group = nil
+		}
+		for _, attr := range attrs {
+			rng := attr.SrcRange
+			if !fmtOwnsLines(src, rng) {
+				flush()
+				continue
+			}
+			if len(group) == 0 || rng.Start.Line != groupEnd+1 {
+				flush()
+				groupStart = rng.Start.Line
+			}
+			groupEnd = rng.End.Line
+			group = append(group, fmtAttributeLines{attr.Name, lines[rng.Start.Line-1 : rng.End.Line]})
+		}
+		flush()
+		return nil
+	})
+
+	var buf bytes.Buffer
+	for i := 0; i < len(lines); {
+		group, ok := replacements[i+1]
+		if !ok {
+			buf.WriteString(lines[i])
+			i++
+			continue
+		}
+		for _, e := range group {
+			for _, line := range e.lines {
+				buf.WriteString(line)
+			}
+			i += len(e.lines)
+		}
+	}
+	return r.reformat(buf.Bytes())
+}
+
+// fmtAttributeLines is an attribute along with the lines of source code
+// that define it.
+type fmtAttributeLines struct {
+	name  string
+	lines []string
+}
+
+// sortAttributes returns the given entries in the order selected by the
+// attribute_order rule.
+func (r *fmtRules) sortAttributes(entries []fmtAttributeLines) []fmtAttributeLines {
+	ret := append(entries[:0:0], entries...)
+	rank := func(name string) int {
+		if r.AttributeOrder != fmtAttributeOrderMetaArguments {
+			return 0
+		}
+		for i, meta := range fmtMetaArguments {
+			if name == meta {
+				return i - len(fmtMetaArguments)
+			}
+		}
+		if name == "depends_on" {
+			return 1
+		}
+		return 0
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		ri, rj := rank(ret[i].name), rank(ret[j].name)
+		if ri != rj {
+			return ri < rj
+		}
+		if r.AttributeOrder == fmtAttributeOrderAlphabetical {
+			return ret[i].name < ret[j].name
+		}
+		return false
+	})
+	return ret
+}
+
+// wrapLists implements the list_wrap_threshold rule.
+func (r *fmtRules) wrapLists(src []byte) []byte {
+	// Wrapping a list can move nested lists onto their own lines, so we
+	// repeat until there's nothing more to wrap. Each round wraps at least
+	// one level of nesting, so the number of rounds is bounded anyway.
+	for round := 0; round < 10; round++ {
+		next := r.wrapOuterLists(src)
+		if bytes.Equal(next, src) {
+			break
+		}
+		src = next
+	}
+	return src
+}
+
+func (r *fmtRules) wrapOuterLists(src []byte) []byte {
+	file, diags := hclsyntax.ParseConfig(src, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return src
+	}
+	tokens, _ := hclsyntax.LexConfig(src, "", hcl.InitialPos)
+
+	var wrap []*hclsyntax.TupleConsExpr
+	_ = hclsyntax.VisitAll(file.Body.(*hclsyntax.Body), func(node hclsyntax.Node) hcl.Diagnostics {
+		tuple, ok := node.(*hclsyntax.TupleConsExpr)
+		if !ok {
+			return nil
+		}
+		rng := tuple.SrcRange
+		if rng.Start.Line != rng.End.Line || len(tuple.Exprs) <= r.ListWrapThreshold {
+			return nil
+		}
+		for _, other := range wrap {
+			if other.SrcRange.Start.Byte <= rng.Start.Byte && rng.End.Byte <= other.SrcRange.End.Byte {
+				return nil
+			}
+		}
+		for _, tok := range tokens {
+			// Comments can't be preserved when moving the elements, so
+			// we leave such lists alone.
+			if tok.Type == hclsyntax.TokenComment && rng.Start.Byte <= tok.Range.Start.Byte && tok.Range.End.Byte <= rng.End.Byte {
+				return nil
+			}
+		}
+		wrap = append(wrap, tuple)
+		return nil
+	})
+	if len(wrap) == 0 {
+		return src
+	}
+
+	var buf bytes.Buffer
+	pos := 0
+	for _, tuple := range wrap {
+		buf.Write(src[pos:tuple.SrcRange.Start.Byte])
+		buf.WriteString("[\n")
+		for _, expr := range tuple.Exprs {
+			buf.Write(expr.Range().SliceBytes(src))
+			buf.WriteString(",\n")
+		}
+		buf.WriteString("]")
+		pos = tuple.SrcRange.End.Byte
+	}
+	buf.Write(src[pos:])
+	return r.reformat(buf.Bytes())
+}
+
+// normalizeBlankLines implements the max_blank_lines rule. Blank lines
+// within heredoc templates are never changed.
+func (r *fmtRules) normalizeBlankLines(src []byte) []byte {
+	tokens, diags := hclsyntax.LexConfig(src, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return src
+	}
+	inHeredoc := make(map[int]bool)
+	heredoc := false
+	for _, tok := range tokens {
+		switch tok.Type {
+		case hclsyntax.TokenOHeredoc:
+			heredoc = true
+		case hclsyntax.TokenCHeredoc:
+			heredoc = false
+		default:
+			if heredoc {
+				for line := tok.Range.Start.Line; line <= tok.Range.End.Line; line++ {
+					inHeredoc[line] = true
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	blanks := 0
+	prev := ""
+	for i, line := range fmtSplitLines(src) {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" && !inHeredoc[i+1] {
+			blanks++
+			continue
+		}
+		switch {
+		case prev == "":
+			// Start of the file
+		case strings.HasSuffix(prev, "{"), strings.HasSuffix(prev, "["), strings.HasSuffix(prev, "("):
+			// Start of a block or a multi-line expression
+		case strings.HasPrefix(trimmed, "}"), strings.HasPrefix(trimmed, "]"), strings.HasPrefix(trimmed, ")"):
+			// End of a block or a multi-line expression
+		default:
+			for n := 0; n < blanks && n < r.MaxBlankLines; n++ {
+				buf.WriteString("\n")
+			}
+		}
+		blanks = 0
+		prev = trimmed
+		buf.WriteString(line)
+	}
+	return buf.Bytes()
+}
+
+// fmtSplitLines splits the given source code into lines, each including
+// its line terminator if any.
+func fmtSplitLines(src []byte) []string {
+	return strings.SplitAfter(string(src), "\n")
+}
+
+// fmtOwnsLines returns true if the given range starts at the beginning of a
+// line, ignoring indentation, and ends at the end of a line, ignoring any
+// trailing comment, so that its lines can be moved as a unit.
+func fmtOwnsLines(src []byte, rng hcl.Range) bool {
+	lineStart := rng.Start.Byte
+	for lineStart > 0 && src[lineStart-1] != '\n' {
+		lineStart--
+	}
+	if len(bytes.TrimSpace(src[lineStart:rng.Start.Byte])) != 0 {
+		return false
+	}
+	lineEnd := rng.End.Byte
+	for lineEnd < len(src) && src[lineEnd] != '\n' {
+		lineEnd++
+	}
+	rest := string(bytes.TrimSpace(src[rng.End.Byte:lineEnd]))
+	return rest == "" || strings.HasPrefix(rest, "#") || strings.HasPrefix(rest, "//")
+}
@@ -197,6 +197,77 @@ a = 1 +
 	}
 }
 
+func TestFmt_yaml(t *testing.T) {
+	tempDir := testTempDir(t)
+
+	src := `# comment
+resource:
+    null_resource:
+        example:
+          triggers: {a:   b}
+`
+	want := `# comment
+resource:
+  null_resource:
+    example:
+      triggers: {a: b}
+`
+
+	path := filepath.Join(tempDir, "main.tf.yaml")
+	err := os.WriteFile(path, []byte(src), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ui := new(cli.MockUi)
+	c := &FmtCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+		},
+	}
+
+	args := []string{tempDir}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("wrong exit code. errors: \n%s", ui.ErrorWriter.String())
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Fatalf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFmt_yamlSyntaxError(t *testing.T) {
+	tempDir := testTempDir(t)
+
+	err := os.WriteFile(filepath.Join(tempDir, "invalid.tf.yaml"), []byte("a: [b\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ui := new(cli.MockUi)
+	c := &FmtCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+		},
+	}
+
+	args := []string{tempDir}
+	if code := c.Run(args); code != 2 {
+		t.Fatalf("wrong exit code. errors: \n%s", ui.ErrorWriter.String())
+	}
+
+	expected := "Invalid YAML syntax"
+	if actual := ui.ErrorWriter.String(); !strings.Contains(actual, expected) {
+		t.Fatalf("expected:\n%s\n\nto include: %q", actual, expected)
+	}
+}
+
 func TestFmt_snippetInError(t *testing.T) {
 	tempDir := testTempDir(t)
 
@@ -460,6 +531,133 @@ func TestFmt_checkStdin(t *testing.T) {
 	}
 }
 
+func TestFmt_rules(t *testing.T) {
+	tests := map[string]struct {
+		rules string
+		input string
+		want  string
+	}{
+		"align_equals": {
+			rules: `align_equals = false`,
+			input: "a = 1\nbbb = 2\n",
+			want:  "a = 1\nbbb = 2\n",
+		},
+		"attribute_order alphabetical": {
+			rules: `attribute_order = "alphabetical"`,
+			input: "resource \"a\" \"b\" {\n  zone = 1\n  ami  = 2 # comment\n\n  tags = {}\n  name = 3\n}\n",
+			want:  "resource \"a\" \"b\" {\n  ami  = 2 # comment\n  zone = 1\n\n  name = 3\n  tags = {}\n}\n",
+		},
+		"attribute_order meta_arguments": {
+			rules: `attribute_order = "meta_arguments"`,
+			input: "module \"a\" {\n  name       = 1\n  depends_on = []\n  count      = 2\n  source     = \"./a\"\n  ami        = 3\n}\n",
+			want:  "module \"a\" {\n  source     = \"./a\"\n  count      = 2\n  name       = 1\n  ami        = 3\n  depends_on = []\n}\n",
+		},
+		"list_wrap_threshold": {
+			rules: `list_wrap_threshold = 2`,
+			input: "a = [1, 2]\nb = [1, 2, [3, 4, 5]]\nc = [1, 2, 3 /* keep */]\n",
+			want:  "a = [1, 2]\nb = [\n  1,\n  2,\n  [\n    3,\n    4,\n    5,\n  ],\n]\nc = [1, 2, 3 /* keep */]\n",
+		},
+		"max_blank_lines": {
+			rules: `max_blank_lines = 1`,
+			input: "\n\na = 1\n\n\n\nb = <<EOT\nx\n\n\ny\nEOT\nblock {\n\n  c = 1\n\n}\n\n",
+			want:  "a = 1\n\nb = <<EOT\nx\n\n\ny\nEOT\nblock {\n  c = 1\n}\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			rulesFile := filepath.Join(dir, "rules.hcl")
+			if err := os.WriteFile(rulesFile, []byte(test.rules), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			ui := cli.NewMockUi()
+			c := &FmtCommand{
+				Meta: Meta{
+					testingOverrides: metaOverridesForProvider(testProvider()),
+					Ui:               ui,
+				},
+				input: strings.NewReader(test.input),
+			}
+			if code := c.Run([]string{"-config=" + rulesFile, "-"}); code != 0 {
+				t.Fatalf("fmt command was unsuccessful:\n%s", ui.ErrorWriter.String())
+			}
+
+			if diff := cmp.Diff(test.want, ui.OutputWriter.String()); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+
+			// Formatting must be idempotent, or a check would never pass.
+			ui = cli.NewMockUi()
+			c.Ui = ui
+			c.input = strings.NewReader(test.want)
+			if code := c.Run([]string{"-config=" + rulesFile, "-check", "-"}); code != 0 {
+				t.Fatalf("formatted result does not pass the check")
+			}
+		})
+	}
+}
+
+func TestFmt_rulesCheckDiff(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, fmtRulesFilename), []byte("attribute_order = \"alphabetical\"\nmax_blank_lines = 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte("b  = 1\na = 2\n\nc = 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer testChdir(t, dir)()
+
+	ui := cli.NewMockUi()
+	c := &FmtCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+		},
+	}
+	if code := c.Run([]string{"-check", "-diff"}); code != 3 {
+		t.Fatalf("wrong exit code %d; want 3\n%s", code, ui.ErrorWriter.String())
+	}
+
+	got := ui.OutputWriter.String()
+	for _, want := range []string{
+		"main.tf: violates rule \"canonical\"\n",
+		"-b  = 1\n+b = 1\n",
+		"main.tf: violates rule \"max_blank_lines\"\n",
+		" a = 2\n-\n c = 0\n",
+		"main.tf: violates rule \"attribute_order\"\n",
+		"-b = 1\n a = 2\n+b = 1\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not include %q\n%s", want, got)
+		}
+	}
+}
+
+func TestFmt_rulesInvalid(t *testing.T) {
+	dir := t.TempDir()
+	rulesFile := filepath.Join(dir, "rules.hcl")
+	if err := os.WriteFile(rulesFile, []byte(`attribute_order = "random"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ui := cli.NewMockUi()
+	c := &FmtCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+		},
+		input: strings.NewReader("a = 1\n"),
+	}
+	if code := c.Run([]string{"-config=" + rulesFile, "-"}); code != 2 {
+		t.Fatalf("wrong exit code %d; want 2", code)
+	}
+	if got, want := ui.ErrorWriter.String(), "Invalid attribute order"; !strings.Contains(got, want) {
+		t.Fatalf("missing error %q\n%s", want, got)
+	}
+}
+
 var fmtFixture = struct {
 	filename      string
 	input, golden []byte
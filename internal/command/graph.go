@@ -28,6 +28,9 @@ func (c *GraphCommand) Run(args []string) int {
 	var moduleDepth int
 	var verbose bool
 	var planPath string
+	var focus string
+	var depth int
+	var excludeDataSources bool
 
 	args = c.Meta.process(args)
 	cmdFlags := c.Meta.defaultFlagSet("graph")
@@ -36,6 +39,9 @@ func (c *GraphCommand) Run(args []string) int {
 	cmdFlags.IntVar(&moduleDepth, "module-depth", -1, "module-depth")
 	cmdFlags.BoolVar(&verbose, "verbose", false, "verbose")
 	cmdFlags.StringVar(&planPath, "plan", "", "plan")
+	cmdFlags.StringVar(&focus, "focus", "", "focus")
+	cmdFlags.IntVar(&depth, "depth", -1, "depth")
+	cmdFlags.BoolVar(&excludeDataSources, "exclude-data-sources", false, "exclude-data-sources")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
@@ -66,6 +72,16 @@ func (c *GraphCommand) Run(args []string) int {
 
 	var diags tfdiags.Diagnostics
 
+	if depth >= 0 && focus == "" {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid graph depth",
+			"The -depth=... option limits the graph to the objects near the one selected by -focus=..., so it can only be used together with -focus.",
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
 	backendConfig, backendDiags := c.loadBackendConfig(configPath)
 	diags = diags.Append(backendDiags)
 	if diags.HasErrors() {
@@ -171,6 +187,19 @@ func (c *GraphCommand) Run(args []string) int {
 		return 1
 	}
 
+	if excludeDataSources {
+		graphExcludeDataSources(g)
+	}
+	if focus != "" && !graphFocus(g, focus, depth) {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Graph focus not found",
+			fmt.Sprintf("The -focus=... option must be the address of a resource or module, or the name of another object in the graph, but %q doesn't match anything in the %s graph.", focus, graphTypeStr),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
 	graphStr, err := tofu.GraphDot(g, &dag.DotOpts{
 		DrawCycles: drawCycles,
 		MaxDepth:   moduleDepth,
@@ -217,6 +246,18 @@ Options:
                    plan-destroy, or apply. By default OpenTofu chooses
 				   "plan", or "apply" if you also set the -plan=... option.
 
+  -focus=addr      Only show the objects that the given resource or module
+                   depends on and the objects that depend on it, rather
+                   than the whole graph.
+
+  -depth=n         Used with -focus, only show the objects that are within
+                   n dependency steps of the focused object.
+
+  -exclude-data-sources
+                   Leave data resources out of the graph, showing the
+                   dependencies that pass through them as direct
+                   dependencies instead.
+
   -module-depth=n  (deprecated) In prior versions of OpenTofu, specified the
 				   depth of modules to show in the output.
 `
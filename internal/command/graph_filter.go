@@ -0,0 +1,163 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/dag"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// graphExcludeDataSources removes the data resources from the given graph.
+// The dependencies between the remaining nodes which passed through a data
+// resource are kept, by connecting each of its dependents directly to each
+// of its dependencies.
+func graphExcludeDataSources(g *tofu.Graph) {
+	for _, v := range g.Vertices() {
+		rn, ok := v.(tofu.GraphNodeConfigResource)
+		if !ok || rn.ResourceAddr().Resource.Mode != addrs.DataResourceMode {
+			continue
+		}
+		for _, up := range g.UpEdges(v) {
+			for _, down := range g.DownEdges(v) {
+				g.Connect(dag.BasicEdge(up, down))
+			}
+		}
+		g.Remove(v)
+	}
+	g.TransitiveReduction()
+}
+
+// graphFocus reduces the given graph to the subgraph induced by the nodes
+// matching the given address, the nodes they depend on, and the nodes which
+// depend on them. If depth is not negative then only the nodes within that
+// many dependency steps of a matching node are kept, counting only the nodes
+// that appear in the graph output.
+//
+// The address can be a resource address, with or without an instance key,
+// or a module address to focus on all of the resources in that module. Other
+// nodes, such as those for variables, outputs, and providers, can be
+// selected by their names as shown in the graph output.
+//
+// graphFocus returns false, and leaves the graph unchanged, if no nodes
+// match the address.
+func graphFocus(g *tofu.Graph, focus string, depth int) bool {
+	match := graphFocusMatcher(focus)
+
+	var start []dag.Vertex
+	for _, v := range g.Vertices() {
+		if match(v) {
+			start = append(start, v)
+		}
+	}
+	if len(start) == 0 {
+		return false
+	}
+
+	keep := make(dag.Set)
+	for _, v := range start {
+		keep.Add(v)
+	}
+	graphFocusWalk(g, start, depth, keep, g.DownEdges)
+	graphFocusWalk(g, start, depth, keep, g.UpEdges)
+
+	for _, v := range g.Vertices() {
+		if !keep.Include(v) {
+			g.Remove(v)
+		}
+	}
+	return true
+}
+
+// graphFocusWalk adds to keep the nodes reachable from the given start nodes
+// by following the edges returned by next, within the given depth.
+//
+// Only nodes which appear in the graph output count towards the depth, so
+// that the internal nodes between them don't make the depth unpredictable.
+func graphFocusWalk(g *tofu.Graph, start []dag.Vertex, depth int, keep dag.Set, next func(dag.Vertex) dag.Set) {
+	type step struct {
+		v     dag.Vertex
+		steps int
+	}
+
+	best := make(map[interface{}]int)
+	queue := make([]step, 0, len(start))
+	for _, v := range start {
+		best[dag.VertexName(v)] = 0
+		queue = append(queue, step{v, 0})
+	}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, raw := range next(current.v) {
+			v := raw.(dag.Vertex)
+			steps := current.steps
+			if _, visible := v.(dag.GraphNodeDotter); visible {
+				steps++
+			}
+			if depth >= 0 && steps > depth {
+				continue
+			}
+			if prev, seen := best[dag.VertexName(v)]; seen && prev <= steps {
+				continue
+			}
+			best[dag.VertexName(v)] = steps
+			keep.Add(v)
+			queue = append(queue, step{v, steps})
+		}
+	}
+}
+
+// graphFocusMatcher returns a function which reports whether a node of the
+// graph matches the given focus address.
+func graphFocusMatcher(focus string) func(dag.Vertex) bool {
+	target, diags := addrs.ParseTargetStr(focus)
+	if diags.HasErrors() {
+		return func(v dag.Vertex) bool {
+			return graphNodeDisplayName(v) == focus
+		}
+	}
+
+	var module addrs.Module
+	var resource *addrs.ConfigResource
+	switch subject := target.Subject.(type) {
+	case addrs.AbsResourceInstance:
+		addr := subject.ContainingResource().Config()
+		resource = &addr
+	case addrs.AbsResource:
+		addr := subject.Config()
+		resource = &addr
+	case addrs.ModuleInstance:
+		module = subject.Module()
+	case addrs.Module:
+		module = subject
+	}
+
+	return func(v dag.Vertex) bool {
+		rn, ok := v.(tofu.GraphNodeConfigResource)
+		if !ok {
+			// Nodes that aren't for resources can still be selected by name.
+			return graphNodeDisplayName(v) == focus
+		}
+		addr := rn.ResourceAddr()
+		if resource != nil {
+			return addr.Equal(*resource)
+		}
+		return addr.Module.Equal(module) || strings.HasPrefix(addr.Module.String()+".", module.String()+".")
+	}
+}
+
+// graphNodeDisplayName returns the name of the given node as it appears in
+// the graph output, without any annotations such as " (expand)".
+func graphNodeDisplayName(v dag.Vertex) string {
+	name := dag.VertexName(v)
+	if i := strings.Index(name, " ("); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
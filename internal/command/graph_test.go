@@ -12,8 +12,11 @@ import (
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tofu"
 )
 
 func TestGraph(t *testing.T) {
@@ -160,3 +163,125 @@ func TestGraph_plan(t *testing.T) {
 		t.Fatalf("doesn't look like digraph: %s", output)
 	}
 }
+
+func TestGraph_focus(t *testing.T) {
+	tests := map[string]struct {
+		args    []string
+		want    []string
+		wantNot []string
+	}{
+		"resource": {
+			[]string{"-focus=test_instance.c"},
+			[]string{"data.test_data_source.a", "test_instance.b", "test_instance.c", "test_instance.d"},
+			[]string{"test_instance.unrelated"},
+		},
+		"resource instance": {
+			[]string{"-focus=test_instance.c[0]"},
+			[]string{"test_instance.b", "test_instance.c", "test_instance.d"},
+			[]string{"test_instance.unrelated"},
+		},
+		"depth": {
+			[]string{"-focus=test_instance.c", "-depth=1"},
+			[]string{"test_instance.b", "test_instance.c", "test_instance.d"},
+			[]string{"data.test_data_source.a", "test_instance.unrelated"},
+		},
+		"exclude data sources": {
+			[]string{"-exclude-data-sources"},
+			[]string{"test_instance.b", "test_instance.unrelated"},
+			[]string{"data.test_data_source.a"},
+		},
+		"focus and exclude data sources": {
+			[]string{"-focus=test_instance.b", "-exclude-data-sources"},
+			[]string{"test_instance.b", "test_instance.c", "test_instance.d"},
+			[]string{"data.test_data_source.a", "test_instance.unrelated"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			td := t.TempDir()
+			testCopyDir(t, testFixturePath("graph-focus"), td)
+			defer testChdir(t, td)()
+
+			ui := new(cli.MockUi)
+			c := &GraphCommand{
+				Meta: Meta{
+					testingOverrides: metaOverridesForProvider(graphFixtureProvider()),
+					Ui:               ui,
+				},
+			}
+
+			if code := c.Run(test.args); code != 0 {
+				t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+			}
+
+			output := ui.OutputWriter.String()
+			for _, want := range test.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("output doesn't include %s:\n%s", want, output)
+				}
+			}
+			for _, wantNot := range test.wantNot {
+				if strings.Contains(output, wantNot) {
+					t.Errorf("output includes %s:\n%s", wantNot, output)
+				}
+			}
+		})
+	}
+}
+
+func TestGraph_focusInvalid(t *testing.T) {
+	tests := map[string]struct {
+		args    []string
+		wantErr string
+	}{
+		"not found": {
+			[]string{"-focus=test_instance.missing"},
+			"Graph focus not found",
+		},
+		"depth without focus": {
+			[]string{"-depth=1"},
+			"Invalid graph depth",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			td := t.TempDir()
+			testCopyDir(t, testFixturePath("graph-focus"), td)
+			defer testChdir(t, td)()
+
+			ui := new(cli.MockUi)
+			c := &GraphCommand{
+				Meta: Meta{
+					testingOverrides: metaOverridesForProvider(graphFixtureProvider()),
+					Ui:               ui,
+				},
+			}
+
+			if code := c.Run(test.args); code != 1 {
+				t.Fatalf("wrong exit code %d; want 1\n%s", code, ui.OutputWriter.String())
+			}
+			if got := ui.ErrorWriter.String(); !strings.Contains(got, test.wantErr) {
+				t.Fatalf("wrong error; want %q\n%s", test.wantErr, got)
+			}
+		})
+	}
+}
+
+// graphFixtureProvider returns a mock provider which supports both the
+// resource type from applyFixtureSchema and a data source, for testing
+// graphs with data resources.
+func graphFixtureProvider() *tofu.MockProvider {
+	p := applyFixtureProvider()
+	p.GetProviderSchemaResponse.DataSources = map[string]providers.Schema{
+		"test_data_source": {
+			Block: &configschema.Block{
+				Attributes: map[string]*configschema.Attribute{
+					"id": {Type: cty.String, Required: true},
+				},
+			},
+		},
+	}
+	return p
+}
@@ -0,0 +1,181 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package hints recognizes error diagnostics describing common problems,
+// such as expired credentials or a state lock held by another operation,
+// and annotates them with concrete remediation steps.
+//
+// The diagnostics themselves often come from providers or other external
+// systems, and so we can only recognize them heuristically by matching
+// patterns in their text. A hint is therefore phrased as a likely cause and
+// remedy, never as a certainty.
+package hints
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// Hint describes how to resolve a recognized problem.
+type Hint struct {
+	// Name identifies the hint, for logging and testing.
+	Name string
+
+	// Summary is a single sentence describing the most likely cause and
+	// remedy, which is shown after every matching diagnostic.
+	Summary string
+
+	// Steps are detailed remediation steps, which are shown only when the
+	// user runs OpenTofu with the -explain option.
+	Steps []string
+
+	// DocURL is a link to documentation about the problem.
+	DocURL string
+}
+
+type rule struct {
+	// patterns are matched against the summary and detail of a diagnostic,
+	// and the rule matches if any of the patterns does.
+	patterns []*regexp.Regexp
+
+	hint Hint
+}
+
+var rules = []rule{
+	{
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)\bExpiredToken(Exception)?\b`),
+			regexp.MustCompile(`(?i)\btoken (has |is )?expired\b`),
+			regexp.MustCompile(`(?i)\bsecurity token included in the request is (expired|invalid)\b`),
+			regexp.MustCompile(`(?i)\bInvalidClientTokenId\b`),
+			regexp.MustCompile(`(?i)\bcredentials? (have|has) expired\b`),
+			regexp.MustCompile(`(?i)\bexpired (access |refresh |session )?(token|credentials)\b`),
+		},
+		hint: Hint{
+			Name:    "expired-credentials",
+			Summary: "The credentials used by a provider or backend have probably expired. Refresh them and then run this command again.",
+			Steps: []string{
+				`Refresh your credentials using the tool you obtained them with, such as "aws sso login", "gcloud auth application-default login" or "az login".`,
+				"Check that no stale credentials are set in environment variables, such as AWS_ACCESS_KEY_ID and AWS_SESSION_TOKEN, which take precedence over a refreshed profile.",
+				"If OpenTofu runs in automation, check that the job obtains fresh short-lived credentials for each run and that they last longer than the run itself.",
+			},
+			DocURL: "https://opentofu.org/docs/language/providers/configuration/",
+		},
+	},
+	{
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`^Error acquiring the state lock$`),
+		},
+		hint: Hint{
+			Name:    "state-lock-held",
+			Summary: "Another OpenTofu operation is probably using this state. Wait for it to complete and then run this command again.",
+			Steps: []string{
+				`Check the lock information above to find out who holds the lock, and for which operation.`,
+				`If that operation is still running, wait for it to complete. Use the -lock-timeout option, such as "-lock-timeout=5m", to wait for the lock automatically.`,
+				`If the operation that held the lock was interrupted and can no longer release it, run "tofu force-unlock LOCK_ID" with the ID from the lock information. Do this only if you're sure that no other operation is running.`,
+			},
+			DocURL: "https://opentofu.org/docs/language/state/locking/",
+		},
+	},
+	{
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`doesn't match any of the checksums (previously )?recorded in the dependency lock file`),
+			regexp.MustCompile(`checksum list has unexpected SHA-256 hash`),
+		},
+		hint: Hint{
+			Name:    "checksum-mismatch",
+			Summary: "The provider package doesn't match the checksums in the dependency lock file, either because they don't cover this platform or because the package has changed.",
+			Steps: []string{
+				`If you install providers from a mirror or use more than one platform, run "tofu providers lock" with a -platform option for each platform you use, to record the checksums for all of them.`,
+				`If you have deliberately changed the provider version or its source, run "tofu init -upgrade" to select a new version and record its checksums.`,
+				"Otherwise, the package may have been modified after it was published. Check the integrity of your provider mirror or plugin cache before using it.",
+			},
+			DocURL: "https://opentofu.org/docs/language/files/dependency-lock/#checksum-verification",
+		},
+	},
+	{
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`no available releases match the given constraints`),
+			regexp.MustCompile(`does not match configured version constraint`),
+		},
+		hint: Hint{
+			Name:    "version-constraint-conflict",
+			Summary: "The version constraints for a provider can't all be satisfied, or no longer match the version selected in the dependency lock file.",
+			Steps: []string{
+				`Run "tofu providers" to see the version constraints that each module declares for each provider.`,
+				"Relax any constraints that conflict with each other, such as an exact version in one module and a newer minimum version in another.",
+				`If the constraints changed since the dependency lock file was created, run "tofu init -upgrade" to select a new version that matches them.`,
+			},
+			DocURL: "https://opentofu.org/docs/language/expressions/version-constraints/",
+		},
+	},
+}
+
+// For returns the hint for the given diagnostic, or nil if there is none.
+//
+// Only error diagnostics can have hints.
+func For(diag tfdiags.Diagnostic) *Hint {
+	if diag.Severity() != tfdiags.Error {
+		return nil
+	}
+	desc := diag.Description()
+	for _, r := range rules {
+		for _, pattern := range r.patterns {
+			if pattern.MatchString(desc.Summary) || pattern.MatchString(desc.Detail) {
+				hint := r.hint
+				return &hint
+			}
+		}
+	}
+	return nil
+}
+
+// Annotate returns the given diagnostics with the hints for any of them
+// appended to their details. If explain is true then the hints include the
+// detailed remediation steps.
+func Annotate(diags tfdiags.Diagnostics, explain bool) tfdiags.Diagnostics {
+	var ret tfdiags.Diagnostics
+	for _, diag := range diags {
+		if hint := For(diag); hint != nil {
+			diag = hintedDiagnostic{Diagnostic: diag, text: hint.text(explain)}
+		}
+		ret = append(ret, diag)
+	}
+	return ret
+}
+
+func (h *Hint) text(explain bool) string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Hint: %s", h.Summary)
+	if !explain {
+		buf.WriteString(" Run OpenTofu again with the -explain option for the steps to resolve this.")
+		return buf.String()
+	}
+	buf.WriteString("\n")
+	for i, step := range h.Steps {
+		fmt.Fprintf(&buf, "\n%d. %s", i+1, step)
+	}
+	if h.DocURL != "" {
+		fmt.Fprintf(&buf, "\n\nFor more information, see %s", h.DocURL)
+	}
+	return buf.String()
+}
+
+// hintedDiagnostic is a diagnostic with a hint appended to its detail.
+type hintedDiagnostic struct {
+	tfdiags.Diagnostic
+	text string
+}
+
+func (d hintedDiagnostic) Description() tfdiags.Description {
+	desc := d.Diagnostic.Description()
+	if desc.Detail == "" {
+		desc.Detail = d.text
+	} else {
+		desc.Detail = desc.Detail + "\n\n" + d.text
+	}
+	return desc
+}
@@ -0,0 +1,114 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package hints
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+func TestFor(t *testing.T) {
+	tests := map[string]struct {
+		diag tfdiags.Diagnostic
+		want string
+	}{
+		"expired AWS credentials": {
+			tfdiags.Sourceless(
+				tfdiags.Error,
+				"configuring Terraform AWS Provider",
+				"operation error STS: GetCallerIdentity, https response error StatusCode: 403, api error ExpiredToken: The security token included in the request is expired",
+			),
+			"expired-credentials",
+		},
+		"expired refresh token": {
+			tfdiags.Sourceless(tfdiags.Error, "Failed to get token", "oauth2: refresh token has expired"),
+			"expired-credentials",
+		},
+		"state lock": {
+			tfdiags.Sourceless(tfdiags.Error, "Error acquiring the state lock", "Error message: resource temporarily unavailable"),
+			"state-lock-held",
+		},
+		"checksum mismatch": {
+			tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to install provider",
+				"Error while installing hashicorp/aws v5.0.0: the current package for registry.opentofu.org/hashicorp/aws 5.0.0 doesn't match any of the checksums previously recorded in the dependency lock file",
+			),
+			"checksum-mismatch",
+		},
+		"no matching versions": {
+			tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to query available provider packages",
+				"Could not retrieve the list of available versions for provider hashicorp/aws: no available releases match the given constraints ~> 4.0, >= 5.0.0",
+			),
+			"version-constraint-conflict",
+		},
+		"locked version outside constraints": {
+			tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to query available provider packages",
+				"locked provider registry.opentofu.org/hashicorp/aws 4.0.0 does not match configured version constraint >= 5.0.0; must use tofu init -upgrade to allow selection of new versions",
+			),
+			"version-constraint-conflict",
+		},
+		"unrecognized error": {
+			tfdiags.Sourceless(tfdiags.Error, "Unsupported argument", `An argument named "foo" is not expected here.`),
+			"",
+		},
+		"warning": {
+			tfdiags.Sourceless(tfdiags.Warning, "Error acquiring the state lock", ""),
+			"",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got string
+			if hint := For(test.diag); hint != nil {
+				got = hint.Name
+			}
+			if got != test.want {
+				t.Fatalf("wrong hint %q; want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	var diags tfdiags.Diagnostics
+	diags = diags.Append(tfdiags.Sourceless(tfdiags.Error, "Error acquiring the state lock", "Error message: lock held"))
+	diags = diags.Append(errors.New("something else"))
+
+	got := Annotate(diags, false)
+	if len(got) != 2 {
+		t.Fatalf("wrong number of diagnostics %d", len(got))
+	}
+	detail := got[0].Description().Detail
+	if !strings.HasPrefix(detail, "Error message: lock held\n\nHint: Another OpenTofu operation") {
+		t.Fatalf("wrong detail:\n%s", detail)
+	}
+	if !strings.Contains(detail, "-explain option") || strings.Contains(detail, "force-unlock") {
+		t.Fatalf("summary hint should refer to -explain rather than including steps:\n%s", detail)
+	}
+	if got[1].Description().Detail != "" {
+		t.Fatalf("unexpected hint for unrecognized diagnostic: %s", got[1].Description().Detail)
+	}
+
+	got = Annotate(diags, true)
+	detail = got[0].Description().Detail
+	for _, want := range []string{"1. Check the lock information", "tofu force-unlock LOCK_ID", "https://opentofu.org/docs/language/state/locking/"} {
+		if !strings.Contains(detail, want) {
+			t.Fatalf("explained hint does not include %q:\n%s", want, detail)
+		}
+	}
+
+	// The original diagnostics are not modified.
+	if diags[0].Description().Detail != "Error message: lock held" {
+		t.Fatalf("original diagnostic was modified")
+	}
+}
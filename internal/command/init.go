@@ -7,6 +7,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"strings"
@@ -22,11 +24,13 @@ import (
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/backend"
 	backendInit "github.com/opentofu/opentofu/internal/backend/init"
+	backendLocal "github.com/opentofu/opentofu/internal/backend/local"
 	"github.com/opentofu/opentofu/internal/cloud"
 	"github.com/opentofu/opentofu/internal/command/arguments"
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/getproviders"
+	"github.com/opentofu/opentofu/internal/plugin/discovery"
 	"github.com/opentofu/opentofu/internal/providercache"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/tfdiags"
@@ -38,12 +42,16 @@ import (
 // module and clones it to the working directory.
 type InitCommand struct {
 	Meta
+
+	// fromBundle is the dependency bundle given in -from-bundle, if any,
+	// which is extracted into vendorDir.
+	fromBundle string
 }
 
 func (c *InitCommand) Run(args []string) int {
-	var flagFromModule, flagLockfile, testsDirectory string
-	var flagBackend, flagCloud, flagGet, flagUpgrade bool
-	var flagPluginPath FlagStringSlice
+	var flagFromModule, flagFromBundle, flagLockfile, testsDirectory string
+	var flagBackend, flagCloud, flagGet, flagUpgrade, flagVendor bool
+	var flagPluginPath, flagBundleTrustedKeys FlagStringSlice
 	flagConfigExtra := newRawFlags("-backend-config")
 
 	args = c.Meta.process(args)
@@ -59,6 +67,10 @@ func (c *InitCommand) Run(args []string) int {
 	cmdFlags.BoolVar(&c.reconfigure, "reconfigure", false, "reconfigure")
 	cmdFlags.BoolVar(&c.migrateState, "migrate-state", false, "migrate state")
 	cmdFlags.BoolVar(&flagUpgrade, "upgrade", false, "")
+	cmdFlags.BoolVar(&c.offline, "offline", false, "offline")
+	cmdFlags.BoolVar(&flagVendor, "vendor", true, "install from the vendor directory")
+	cmdFlags.StringVar(&flagFromBundle, "from-bundle", "", "install from the given dependency bundle")
+	cmdFlags.Var(&flagBundleTrustedKeys, "bundle-trusted-key", "trusted key for the dependency bundle")
 	cmdFlags.Var(&flagPluginPath, "plugin-dir", "plugin directory")
 	cmdFlags.StringVar(&flagLockfile, "lockfile", "", "Set a dependency lockfile mode")
 	cmdFlags.BoolVar(&c.Meta.ignoreRemoteVersion, "ignore-remote-version", false, "continue even if remote and local OpenTofu versions are incompatible")
@@ -122,6 +134,18 @@ func (c *InitCommand) Run(args []string) int {
 	if flagFromModule != "" {
 		src := flagFromModule
 
+		if c.offline {
+			if addr, err := addrs.ParseModuleSource(src); err != nil || !isLocalModuleSource(addr) {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Module not available offline",
+					fmt.Sprintf("The -from-module option can only copy a module from a local directory in offline mode, but %q is a remote source address.", src),
+				))
+				c.showDiagnostics(diags)
+				return 1
+			}
+		}
+
 		empty, err := configs.IsEmptyDir(path)
 		if err != nil {
 			c.Ui.Error(fmt.Sprintf("Error validating destination directory: %s", err))
@@ -172,6 +196,48 @@ func (c *InitCommand) Run(args []string) int {
 		return 0
 	}
 
+	// A dependency bundle created by "tofu bundle create", or a vendor
+	// directory created by "tofu vendor", replaces the usual sources of
+	// providers and remote modules.
+	switch {
+	case flagFromBundle != "" && flagUpgrade:
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Cannot upgrade bundled dependencies",
+			"The -upgrade and -from-bundle options are mutually-exclusive, because a dependency bundle contains only the providers and modules selected when it was created.",
+		))
+		c.showDiagnostics(diags)
+		return 1
+	case flagFromBundle != "":
+		bundleDir, bundleDiags := c.extractDependencyBundle(flagFromBundle, flagBundleTrustedKeys)
+		diags = diags.Append(bundleDiags)
+		if bundleDiags.HasErrors() {
+			c.showDiagnostics(diags)
+			return 1
+		}
+		defer os.RemoveAll(bundleDir)
+		c.vendorDir = bundleDir
+		c.fromBundle = flagFromBundle
+		c.Ui.Output(c.Colorize().Color(fmt.Sprintf("[reset][bold]Using providers and modules[reset] from the dependency bundle %s\n", flagFromBundle)))
+		header = true
+	case flagVendor:
+		c.vendorDir = vendorDir(path)
+		if c.vendorDir == "" {
+			break
+		}
+		if flagUpgrade {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Cannot upgrade vendored dependencies",
+				fmt.Sprintf("This configuration's providers and modules are installed from %s, so they cannot be upgraded.\n\nTo upgrade them, run \"tofu init -upgrade -vendor=false\" and then run \"tofu vendor\" again.", c.vendorDir),
+			))
+			c.showDiagnostics(diags)
+			return 1
+		}
+		c.Ui.Output(c.Colorize().Color(fmt.Sprintf("[reset][bold]Using vendored providers and modules[reset] from %s\n", c.vendorDir)))
+		header = true
+	}
+
 	// Load just the root module to begin backend and module initialization
 	rootModEarly, earlyConfDiags := c.loadSingleModuleWithTests(path, testsDirectory)
 
@@ -195,6 +261,13 @@ func (c *InitCommand) Run(args []string) int {
 	var backendOutput bool
 
 	switch {
+	case c.offline && flagBackend && !offlineBackend(rootModEarly, flagCloud):
+		backDiags = offlineBackendDiags(rootModEarly, flagCloud)
+	case c.offline && !flagBackend:
+		// The previously-initialized backend might need network access to
+		// read the latest state, so in offline mode we rely only on the
+		// configuration to find the required providers.
+		log.Printf("[TRACE] init: skipping previously-initialized backend in offline mode")
 	case flagCloud && rootModEarly.CloudConfig != nil:
 		back, backendOutput, backDiags = c.initCloud(ctx, rootModEarly, flagConfigExtra)
 	case flagBackend:
@@ -231,6 +304,16 @@ func (c *InitCommand) Run(args []string) int {
 		}
 
 		state = sMgr.State()
+
+		// Module sources can ask to use the credentials of an object
+		// storage backend, which may be wrapped by the local backend.
+		var stateBackend backend.Backend = back
+		if l, ok := back.(*backendLocal.Local); ok && l.Backend != nil {
+			stateBackend = l.Backend
+		}
+		if storage, ok := stateBackend.(backend.ModuleSourceStorage); ok {
+			c.moduleSourceBackend = storage
+		}
 	}
 
 	if flagGet {
@@ -313,6 +396,21 @@ func (c *InitCommand) Run(args []string) int {
 		header = true
 	}
 
+	provisionersOutput, provisionerDiags := c.getProvisioners()
+	diags = diags.Append(provisionerDiags)
+	if provisionerDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+	if provisionersOutput {
+		header = true
+	}
+
+	// Recording the capabilities of the installed providers lets later
+	// commands explain when the configuration relies on a feature that a
+	// provider doesn't support.
+	diags = diags.Append(c.probeProviderCapabilities())
+
 	// If we outputted information, then we need to output a newline
 	// so that our success message is nicely spaced out from prior text.
 	if header {
@@ -375,6 +473,17 @@ func (c *InitCommand) getModules(ctx context.Context, path, testsDir string, ear
 		ShowLocalPaths: true,
 	}
 
+	if c.vendorDir != "" {
+		if err := c.restoreVendoredModules(c.vendorDir); err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to install vendored modules",
+				fmt.Sprintf("Could not install the modules in %s: %s.", c.vendorDir, err),
+			))
+			return true, true, diags
+		}
+	}
+
 	installAbort, installDiags := c.installModules(ctx, path, testsDir, upgrade, false, hooks)
 	diags = diags.Append(installDiags)
 
@@ -509,6 +618,78 @@ the backend configuration is present and valid.
 	return back, true, diags
 }
 
+// isLocalModuleSource returns true if the given module source address refers
+// to a directory on the local filesystem.
+func isLocalModuleSource(addr addrs.ModuleSource) bool {
+	_, ok := addr.(addrs.ModuleSourceLocal)
+	return ok
+}
+
+// offlineBackend returns true if the backend selected by the given root
+// module can be initialized without network access.
+func offlineBackend(root *configs.Module, cloud bool) bool {
+	if cloud && root.CloudConfig != nil {
+		return false
+	}
+	return root.Backend == nil || root.Backend.Type == "local"
+}
+
+// offlineBackendDiags returns an error explaining that the backend selected
+// by the given root module cannot be initialized in offline mode.
+func offlineBackendDiags(root *configs.Module, cloud bool) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	if cloud && root.CloudConfig != nil {
+		return diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Backend not available offline",
+			Detail:   "The cloud backend must contact a remote service when it is initialized, which is not possible in offline mode.\n\nTo install only providers and modules, run \"tofu init -offline -backend=false\".",
+			Subject:  root.CloudConfig.DeclRange.Ptr(),
+		})
+	}
+	return diags.Append(&hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Backend not available offline",
+		Detail:   fmt.Sprintf("The %q backend must contact a remote service when it is initialized, which is not possible in offline mode.\n\nTo install only providers and modules, run \"tofu init -offline -backend=false\".", root.Backend.Type),
+		Subject:  root.Backend.TypeRange.Ptr(),
+	})
+}
+
+// getProvisioners installs verified copies of the provisioner plugins that
+// the CLI configuration declares with a checksum, so that a plugin that
+// doesn't match its checksum is reported now rather than when a provisioner
+// runs. This method outputs its own Ui.
+func (c *InitCommand) getProvisioners() (output bool, diags tfdiags.Diagnostics) {
+	var names []string
+	for name, cfg := range c.ProvisionerPlugins {
+		if cfg.SHA256 != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return false, nil
+	}
+	sort.Strings(names)
+
+	c.Ui.Output(c.Colorize().Color("\n[reset][bold]Initializing provisioner plugins..."))
+	for _, name := range names {
+		meta := discovery.PluginMeta{
+			Name:    name,
+			Version: "0.0.0",
+			Path:    c.ProvisionerPlugins[name].Path,
+		}
+		if _, err := installProvisionerPlugin(meta, c.ProvisionerPlugins[name].SHA256, c.provisionerCacheDir()); err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to install provisioner plugin",
+				fmt.Sprintf("Error while installing provisioner %q: %s.", name, err),
+			))
+			continue
+		}
+		c.Ui.Info(fmt.Sprintf("- Installed provisioner %q from %s (verified checksum)", name, meta.Path))
+	}
+	return true, diags
+}
+
 // Load the complete module tree, and fetch any missing providers.
 // This method outputs its own Ui.
 func (c *InitCommand) getProviders(ctx context.Context, config *configs.Config, state *states.State, upgrade bool, pluginDirs []string, flagLockfile string) (output, abort bool, diags tfdiags.Diagnostics) {
@@ -554,11 +735,21 @@ func (c *InitCommand) getProviders(ctx context.Context, config *configs.Config,
 	}
 
 	var inst *providercache.Installer
-	if len(pluginDirs) == 0 {
+	switch {
+	case len(pluginDirs) == 0 && c.vendorDir != "":
+		// Vendored providers are installed only from the vendor directory,
+		// which has the same layout as a packed filesystem mirror.
+		inst = c.providerInstallerCustomSource(getproviders.NewFilesystemMirrorSource(filepath.Join(c.vendorDir, "providers")))
+	case len(pluginDirs) == 0 && c.offline:
+		// In offline mode we use only the local parts of the usual sources.
+		inst = c.providerInstallerCustomSource(c.providerOfflineInstallSource())
+	case len(pluginDirs) == 0:
 		// By default we use a source that looks for providers in all of the
-		// standard locations, possibly customized by the user in CLI config.
-		inst = c.providerInstaller()
-	} else {
+		// standard locations, possibly customized by the user in CLI config,
+		// except for any providers that the configuration says to install
+		// from an OCI registry.
+		inst = c.providerInstallerCustomSource(c.providerSourceWithOCI(c.providerInstallSource(), config.OCIProviders()))
+	default:
 		// If the user passes at least one -plugin-dir then that circumvents
 		// the usual sources and forces OpenTofu to consult only the given
 		// directories. Anything not available in one of those directories
@@ -619,6 +810,34 @@ func (c *InitCommand) getProviders(ctx context.Context, config *configs.Config,
 			c.Ui.Info(fmt.Sprintf("- Installing %s v%s...", provider.ForDisplay(), version))
 		},
 		QueryPackagesFailure: func(provider addrs.Provider, err error) {
+			if c.vendorDir != "" {
+				if _, canceled := err.(getproviders.ErrRequestCanceled); !canceled {
+					if c.fromBundle != "" {
+						diags = diags.Append(tfdiags.Sourceless(
+							tfdiags.Error,
+							"Provider not bundled",
+							fmt.Sprintf(errProviderNotBundled, provider.ForDisplay(), c.fromBundle, err, getproviders.CurrentPlatform),
+						))
+						return
+					}
+					diags = diags.Append(tfdiags.Sourceless(
+						tfdiags.Error,
+						"Provider not vendored",
+						fmt.Sprintf(errProviderNotVendored, provider.ForDisplay(), c.vendorDir, err, getproviders.CurrentPlatform),
+					))
+				}
+				return
+			}
+			if c.offline {
+				if _, canceled := err.(getproviders.ErrRequestCanceled); !canceled {
+					diags = diags.Append(tfdiags.Sourceless(
+						tfdiags.Error,
+						"Provider not available offline",
+						fmt.Sprintf(errProviderNotAvailableOffline, provider.ForDisplay(), err),
+					))
+				}
+				return
+			}
 			switch errorTy := err.(type) {
 			case getproviders.ErrProviderNotFound:
 				sources := errorTy.Sources
@@ -721,6 +940,13 @@ func (c *InitCommand) getProviders(ctx context.Context, config *configs.Config,
 				),
 			))
 		},
+		QueryPackagesDeprecated: func(provider addrs.Provider, version getproviders.Version, notice *getproviders.VersionNotice) {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Deprecated provider version",
+				fmt.Sprintf("The publisher of %s has deprecated version %s: %s\n\nConsider changing the version constraints to select a newer version, and then run tofu init -upgrade.", provider.ForDisplay(), version, notice),
+			))
+		},
 		LinkFromCacheFailure: func(provider addrs.Provider, version getproviders.Version, err error) {
 			diags = diags.Append(tfdiags.Sourceless(
 				tfdiags.Error,
@@ -814,6 +1040,11 @@ func (c *InitCommand) getProviders(ctx context.Context, config *configs.Config,
 			}
 			if keyID != "" {
 				keyID = c.Colorize().Color(fmt.Sprintf(", key ID [reset][bold]%s[reset]", keyID))
+				if authResult.KeyOrigin != "" {
+					// The key is one that the CLI configuration trusts,
+					// rather than one from the registry.
+					keyID += fmt.Sprintf(" from %s", authResult.KeyOrigin)
+				}
 			}
 
 			if authResult != nil && authResult.SigningSkipped() {
@@ -1155,6 +1386,11 @@ Options:
 
   -no-color               If specified, output won't contain any color.
 
+  -offline                Don't use the network. Providers are installed only
+                          from filesystem mirrors and the plugin cache
+                          directory, remote modules must already be installed,
+                          and only the local backend can be initialized.
+
   -plugin-dir             Directory containing plugin binaries. This overrides all
                           default search paths for plugins, and prevents the
                           automatic installation of plugins. This flag can be used
@@ -1185,6 +1421,21 @@ Options:
                           test command will search for test files in the current directory and
                           in the one specified by the flag.
 
+  -from-bundle=FILE       Install providers and remote modules only from the
+                          dependency bundle created by "tofu bundle create"
+                          in the given file, without using the network. If
+                          there is no dependency lock file, the bundle's lock
+                          file is used.
+
+  -bundle-trusted-key=FILE  Verify that the dependency bundle given in
+                          -from-bundle is signed by one of the ASCII-armored
+                          OpenPGP public keys in the given file. Use this flag
+                          multiple times to trust keys in several files.
+
+  -vendor=false           Ignore the "vendor" directory created by "tofu vendor",
+                          and install providers and modules from their usual
+                          sources.
+
 `
 	return strings.TrimSpace(helpText)
 }
@@ -1287,3 +1538,15 @@ The current .terraform.lock.hcl file only includes checksums for %s, so OpenTofu
 To calculate additional checksums for another platform, run:
   tofu providers lock -platform=linux_amd64
 (where linux_amd64 is the platform to generate)`
+
+const errProviderNotVendored = `OpenTofu can install %s only from the vendor directory %s, but no suitable package was found there: %s.
+
+To vendor this provider, run "tofu vendor -platform=%s". To install providers from their usual sources instead, run "tofu init -vendor=false".`
+
+const errProviderNotBundled = `OpenTofu can install %s only from the dependency bundle %s, but no suitable package was found there: %s.
+
+To include this provider, create the bundle again with "tofu bundle create -platform=%s".`
+
+const errProviderNotAvailableOffline = `OpenTofu is running in offline mode, so it can install %s only from a filesystem mirror or the plugin cache directory, but no suitable package was found there: %s.
+
+To make this provider available offline, run "tofu providers mirror" on a machine with network access and copy the result into a filesystem mirror directory, or run "tofu init" with network access while the plugin cache directory is enabled.`
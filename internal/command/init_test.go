@@ -3,6 +3,8 @@ package command
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -19,11 +21,13 @@ import (
 	"github.com/hashicorp/go-version"
 
 	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/command/cliconfig"
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/depsfile"
 	"github.com/opentofu/opentofu/internal/getproviders"
 	"github.com/opentofu/opentofu/internal/providercache"
+	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/states/statefile"
 	"github.com/opentofu/opentofu/internal/states/statemgr"
@@ -51,6 +55,66 @@ func TestInit_empty(t *testing.T) {
 	}
 }
 
+func TestInit_provisionerPlugins(t *testing.T) {
+	td := t.TempDir()
+	defer testChdir(t, td)()
+	if err := os.WriteFile("main.tf", []byte("locals {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	execFile := filepath.Join(td, "terraform-provisioner-example")
+	content := []byte("#!/bin/sh\n")
+	if err := os.WriteFile(execFile, content, 0755); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(content)
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		ui := new(cli.MockUi)
+		view, _ := testView(t)
+		c := &InitCommand{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(testProvider()),
+				Ui:               ui,
+				View:             view,
+				ProvisionerPlugins: map[string]*cliconfig.ConfigProvisionerPlugin{
+					"example": {Path: execFile, SHA256: strings.Repeat("0", 64)},
+				},
+			},
+		}
+		if code := c.Run(nil); code != 1 {
+			t.Fatalf("wrong exit status %d; want 1\n%s", code, ui.OutputWriter.String())
+		}
+		if got, want := ui.ErrorWriter.String(), "Failed to install provisioner plugin"; !strings.Contains(got, want) {
+			t.Fatalf("error output doesn't mention %q:\n%s", want, got)
+		}
+	})
+
+	t.Run("checksum matches", func(t *testing.T) {
+		ui := new(cli.MockUi)
+		view, _ := testView(t)
+		c := &InitCommand{
+			Meta: Meta{
+				testingOverrides: metaOverridesForProvider(testProvider()),
+				Ui:               ui,
+				View:             view,
+				ProvisionerPlugins: map[string]*cliconfig.ConfigProvisionerPlugin{
+					"example": {Path: execFile, SHA256: hex.EncodeToString(sum[:])},
+				},
+			},
+		}
+		if code := c.Run(nil); code != 0 {
+			t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+		}
+		if got, want := ui.OutputWriter.String(), `Installed provisioner "example"`; !strings.Contains(got, want) {
+			t.Fatalf("output doesn't mention %q:\n%s", want, got)
+		}
+		installed := filepath.Join(DefaultDataDir, "provisioners", "example", hex.EncodeToString(sum[:]), "terraform-provisioner-example")
+		if _, err := os.Stat(installed); err != nil {
+			t.Fatalf("plugin wasn't installed: %s", err)
+		}
+	})
+}
+
 func TestInit_multipleArgs(t *testing.T) {
 	// Create a temporary working directory that is empty
 	td := t.TempDir()
@@ -2809,6 +2873,10 @@ Could not retrieve the list of available versions for provider
 hashicorp/test: no available releases match the given constraints 1.0.1,
 1.0.2
 
+Hint: The version constraints for a provider can't all be satisfied, or no
+longer match the version selected in the dependency lock file. Run OpenTofu
+again with the -explain option for the steps to resolve this.
+
 `
 	if diff := cmp.Diff(got, want); len(diff) > 0 {
 		t.Fatalf("wrong error message: \ngot:\n%s\nwant:\n%s\ndiff:\n%s", got, want, diff)
@@ -2990,3 +3058,103 @@ func expectedPackageInstallPath(name, version string, exe bool) string {
 		baseDir, fmt.Sprintf("registry.opentofu.org/hashicorp/%s/%s/%s", name, version, platform),
 	))
 }
+
+func TestInit_offline(t *testing.T) {
+	cases := map[string]struct {
+		fixture string
+		wantErr string
+	}{
+		"remote backend": {
+			fixture: "init-backend-http",
+			wantErr: "Backend not available offline",
+		},
+		"remote module": {
+			fixture: "init-offline-module",
+			wantErr: "Module not available offline",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			td := t.TempDir()
+			testCopyDir(t, testFixturePath(tc.fixture), td)
+			defer testChdir(t, td)()
+
+			ui := new(cli.MockUi)
+			view, _ := testView(t)
+			c := &InitCommand{
+				Meta: Meta{
+					testingOverrides: metaOverridesForProvider(testProvider()),
+					Ui:               ui,
+					View:             view,
+				},
+			}
+
+			if code := c.Run([]string{"-offline"}); code == 0 {
+				t.Fatalf("expected error, got output: \n%s", ui.OutputWriter.String())
+			}
+			if got := ui.ErrorWriter.String(); !strings.Contains(got, tc.wantErr) {
+				t.Fatalf("expected error %q, got:\n%s", tc.wantErr, got)
+			}
+		})
+	}
+}
+
+func TestInit_providerCapabilities(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("init-provider-lock-file"), td)
+	defer testChdir(t, td)()
+
+	p := testProvider()
+	p.GetProviderSchemaResponse = &providers.GetProviderSchemaResponse{
+		ServerCapabilities: providers.ServerCapabilities{
+			PlanDestroy: true,
+		},
+		Functions: map[string]providers.FunctionDecl{
+			"upper": {ReturnType: cty.String},
+		},
+	}
+	providerSource, close := newMockProviderSource(t, map[string][]string{
+		"test": {"1.2.3"},
+	})
+	defer close()
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	m := Meta{
+		testingOverrides: metaOverridesForProvider(p),
+		Ui:               ui,
+		View:             view,
+		ProviderSource:   providerSource,
+	}
+	c := &InitCommand{
+		Meta: m,
+	}
+	if code := c.Run([]string{"-backend=false"}); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	got := c.recordedProviderCapabilities()
+	want := map[addrs.Provider]providers.Capabilities{
+		addrs.NewDefaultProvider("test"): {
+			PlanDestroy: true,
+			Functions:   []string{"upper"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong recorded capabilities\n%s", diff)
+	}
+
+	// The record no longer applies once the provider is upgraded.
+	lockSrc, err := os.ReadFile(dependencyLockFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lockSrc = bytes.ReplaceAll(lockSrc, []byte(`"1.2.3"`), []byte(`"1.2.4"`))
+	if err := os.WriteFile(dependencyLockFilename, lockSrc, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.recordedProviderCapabilities(); len(got) != 0 {
+		t.Errorf("stale capabilities were used: %#v", got)
+	}
+}
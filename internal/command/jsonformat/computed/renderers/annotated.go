@@ -0,0 +1,117 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package renderers
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/opentofu/opentofu/internal/command/jsonformat/computed"
+	"github.com/opentofu/opentofu/internal/plans"
+)
+
+var _ computed.DiffRenderer = (*annotatedRenderer)(nil)
+
+// Annotated returns a copy of the given diff that renders the given comment
+// on its own line before the diff itself, in the same way as warnings.
+func Annotated(diff computed.Diff, comment string) computed.Diff {
+	return computed.NewDiff(&annotatedRenderer{
+		inner:   diff.Renderer,
+		comment: comment,
+	}, diff.Action, diff.Replace)
+}
+
+// AnnotateBlock returns a copy of the given diff for a block where each of
+// the attributes and nested block types named in the given map are
+// annotated with the corresponding comment. For a nested block type with
+// more than one block, only the first changed block is annotated.
+//
+// If the given diff is not for a block, it is returned unchanged.
+func AnnotateBlock(diff computed.Diff, comments map[string]string) computed.Diff {
+	block, ok := diff.Renderer.(*blockRenderer)
+	if !ok || len(comments) == 0 {
+		return diff
+	}
+
+	attributes := make(map[string]computed.Diff, len(block.attributes))
+	for key, attribute := range block.attributes {
+		if comment, ok := comments[key]; ok {
+			attribute = Annotated(attribute, comment)
+		}
+		attributes[key] = attribute
+	}
+
+	blocks := block.blocks
+	blocks.SingleBlocks = make(map[string]computed.Diff, len(block.blocks.SingleBlocks))
+	for key, single := range block.blocks.SingleBlocks {
+		if comment, ok := comments[key]; ok {
+			single = Annotated(single, comment)
+		}
+		blocks.SingleBlocks[key] = single
+	}
+	annotateFirst := func(diffs []computed.Diff, comment string) []computed.Diff {
+		ret := make([]computed.Diff, len(diffs))
+		copy(ret, diffs)
+		for i, diff := range ret {
+			if diff.Action != plans.NoOp {
+				ret[i] = Annotated(diff, comment)
+				break
+			}
+		}
+		return ret
+	}
+	blocks.ListBlocks = make(map[string][]computed.Diff, len(block.blocks.ListBlocks))
+	for key, list := range block.blocks.ListBlocks {
+		if comment, ok := comments[key]; ok {
+			list = annotateFirst(list, comment)
+		}
+		blocks.ListBlocks[key] = list
+	}
+	blocks.SetBlocks = make(map[string][]computed.Diff, len(block.blocks.SetBlocks))
+	for key, set := range block.blocks.SetBlocks {
+		if comment, ok := comments[key]; ok {
+			set = annotateFirst(set, comment)
+		}
+		blocks.SetBlocks[key] = set
+	}
+	blocks.MapBlocks = make(map[string]map[string]computed.Diff, len(block.blocks.MapBlocks))
+	for key, mapBlocks := range block.blocks.MapBlocks {
+		if comment, ok := comments[key]; ok {
+			var mapKeys []string
+			for mapKey := range mapBlocks {
+				mapKeys = append(mapKeys, mapKey)
+			}
+			sort.Strings(mapKeys)
+
+			annotated := make(map[string]computed.Diff, len(mapBlocks))
+			for mapKey, mapBlock := range mapBlocks {
+				annotated[mapKey] = mapBlock
+			}
+			for _, mapKey := range mapKeys {
+				if mapBlocks[mapKey].Action != plans.NoOp {
+					annotated[mapKey] = Annotated(mapBlocks[mapKey], comment)
+					break
+				}
+			}
+			mapBlocks = annotated
+		}
+		blocks.MapBlocks[key] = mapBlocks
+	}
+
+	return computed.NewDiff(Block(attributes, blocks), diff.Action, diff.Replace)
+}
+
+type annotatedRenderer struct {
+	inner   computed.DiffRenderer
+	comment string
+}
+
+func (renderer annotatedRenderer) RenderHuman(diff computed.Diff, indent int, opts computed.RenderHumanOpts) string {
+	return renderer.inner.RenderHuman(diff, indent, opts)
+}
+
+func (renderer annotatedRenderer) WarningsHuman(diff computed.Diff, indent int, opts computed.RenderHumanOpts) []string {
+	warnings := renderer.inner.WarningsHuman(diff, indent, opts)
+	return append(warnings, fmt.Sprintf("  # (%s)", renderer.comment))
+}
@@ -4,7 +4,12 @@
 package jsonformat
 
 import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
 	"github.com/opentofu/opentofu/internal/command/jsonformat/computed"
+	"github.com/opentofu/opentofu/internal/command/jsonformat/computed/renderers"
 	"github.com/opentofu/opentofu/internal/command/jsonformat/differ"
 	"github.com/opentofu/opentofu/internal/command/jsonformat/structured"
 	"github.com/opentofu/opentofu/internal/command/jsonformat/structured/attribute_path"
@@ -58,7 +63,7 @@ func precomputeDiffs(plan Plan, mode plans.Mode) diffs {
 		structuredChange := structured.FromJsonChange(change.Change, attribute_path.AlwaysMatcher())
 		diffs.changes = append(diffs.changes, diff{
 			change: change,
-			diff:   differ.ComputeDiffForBlock(structuredChange, schema.Block),
+			diff:   renderers.AnnotateBlock(differ.ComputeDiffForBlock(structuredChange, schema.Block), changeCauseComments(change)),
 		})
 	}
 
@@ -70,6 +75,33 @@ func precomputeDiffs(plan Plan, mode plans.Mode) diffs {
 	return diffs
 }
 
+// changeCauseComments returns the comments explaining the cause of the
+// change to each attribute of the given resource change, keyed by the
+// attribute name.
+func changeCauseComments(change jsonplan.ResourceChange) map[string]string {
+	if len(change.ChangeCauses) == 0 {
+		return nil
+	}
+	comments := make(map[string]string, len(change.ChangeCauses))
+	for _, cause := range change.ChangeCauses {
+		var location string
+		if cause.Filename != "" {
+			location = fmt.Sprintf(" at %s:%d", filepath.Base(cause.Filename), cause.Line)
+		}
+		switch cause.Cause {
+		case jsonplan.ChangeCauseConfig:
+			comments[cause.Attribute] = "changed in configuration" + location
+		case jsonplan.ChangeCauseUpstream:
+			comments[cause.Attribute] = fmt.Sprintf("depends on %s, which has changes pending", strings.Join(cause.Upstream, ", "))
+		case jsonplan.ChangeCauseDrift:
+			comments[cause.Attribute] = "changed outside of OpenTofu"
+		case jsonplan.ChangeCauseReplacement:
+			comments[cause.Attribute] = "changes because the object must be replaced"
+		}
+	}
+	return comments
+}
+
 type diffs struct {
 	drift   []diff
 	changes []diff
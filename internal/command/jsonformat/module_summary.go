@@ -0,0 +1,151 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonformat
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/command/format"
+	"github.com/opentofu/opentofu/internal/command/jsonplan"
+	"github.com/opentofu/opentofu/internal/plans"
+)
+
+// moduleChangeCounts tallies the proposed changes within a single module
+// call, using the same categories as the overall "Plan:" summary line.
+type moduleChangeCounts struct {
+	module addrs.Module
+
+	importing int
+	add       int
+	change    int
+	destroy   int
+}
+
+func (c *moduleChangeCounts) count(diff diff) {
+	if diff.Importing() {
+		c.importing++
+	}
+	switch jsonplan.UnmarshalActions(diff.change.Change.Actions) {
+	case plans.Create:
+		c.add++
+	case plans.Update:
+		c.change++
+	case plans.Delete:
+		c.destroy++
+	case plans.DeleteThenCreate, plans.CreateThenDelete:
+		c.add++
+		c.destroy++
+	}
+}
+
+func (c *moduleChangeCounts) empty() bool {
+	return c.importing == 0 && c.add == 0 && c.change == 0 && c.destroy == 0
+}
+
+func (c *moduleChangeCounts) String() string {
+	var parts []string
+	if c.importing > 0 {
+		parts = append(parts, fmt.Sprintf("%d to import", c.importing))
+	}
+	if c.add > 0 {
+		parts = append(parts, fmt.Sprintf("%d to add", c.add))
+	}
+	if c.change > 0 {
+		parts = append(parts, fmt.Sprintf("%d to change", c.change))
+	}
+	if c.destroy > 0 {
+		parts = append(parts, fmt.Sprintf("%d to destroy", c.destroy))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// changeModule returns the address of the module call containing the
+// resource instance that the given diff belongs to, discarding any instance
+// keys so that all instances of a module call are grouped together.
+func changeModule(diff diff) addrs.Module {
+	if diff.change.ModuleAddress == "" {
+		return addrs.RootModule
+	}
+	addr, diags := addrs.ParseModuleInstanceStr(diff.change.ModuleAddress)
+	if diags.HasErrors() {
+		// Should never happen for a plan produced by OpenTofu, but we'll
+		// just treat the change as belonging to the root module if so.
+		return addrs.RootModule
+	}
+	return addr.Module()
+}
+
+// expandModule returns true if the changes for the given module should be
+// rendered in full even though the renderer is summarizing changes by module.
+func (renderer Renderer) expandModule(module addrs.Module) bool {
+	for _, expand := range renderer.ExpandModules {
+		if expand.TargetContains(module) {
+			return true
+		}
+	}
+	return false
+}
+
+// summarizeModuleChanges aggregates the given changes by module call,
+// returning the counts for each module that has at least one change ordered
+// by module address.
+func summarizeModuleChanges(changes []diff) []*moduleChangeCounts {
+	byModule := make(map[string]*moduleChangeCounts)
+	for _, change := range changes {
+		module := changeModule(change)
+		key := module.String()
+		counts, ok := byModule[key]
+		if !ok {
+			counts = &moduleChangeCounts{module: module}
+			byModule[key] = counts
+		}
+		counts.count(change)
+	}
+
+	ret := make([]*moduleChangeCounts, 0, len(byModule))
+	for _, counts := range byModule {
+		if !counts.empty() {
+			ret = append(ret, counts)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].module.String() < ret[j].module.String()
+	})
+	return ret
+}
+
+// renderHumanModuleSummary prints one line per module call summarizing the
+// changes proposed within it.
+func renderHumanModuleSummary(renderer Renderer, changes []diff) {
+	summary := summarizeModuleChanges(changes)
+	if len(summary) == 0 {
+		return
+	}
+
+	names := make([]string, len(summary))
+	width := 0
+	for i, counts := range summary {
+		names[i] = counts.module.String()
+		if counts.module.IsRoot() {
+			names[i] = "(root module)"
+		}
+		if len(names[i]) > width {
+			width = len(names[i])
+		}
+	}
+
+	renderer.Streams.Println()
+	for i, counts := range summary {
+		renderer.Streams.Printf(renderer.Colorize.Color("  [bold]%-*s[reset]  %s\n"), width, names[i], counts.String())
+	}
+
+	if len(renderer.ExpandModules) == 0 && !renderer.RunningInAutomation {
+		renderer.Streams.Println(format.WordWrap(
+			"\nTo show the individual changes for a module, add the option -expand=ADDRESS, such as -expand=module.example.",
+			renderer.Streams.Stdout.Columns()))
+	}
+}
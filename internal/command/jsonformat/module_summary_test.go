@@ -0,0 +1,138 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonformat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mitchellh/colorstring"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/command/jsonplan"
+	"github.com/opentofu/opentofu/internal/command/jsonprovider"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/terminal"
+)
+
+func TestRenderHuman_SummarizeModules(t *testing.T) {
+	color := &colorstring.Colorize{Colors: colorstring.DefaultColors, Disable: true}
+
+	schemas := map[string]*jsonprovider.Provider{
+		"test": {
+			ResourceSchemas: map[string]*jsonprovider.Schema{
+				"test_resource": {
+					Block: &jsonprovider.Block{
+						Attributes: map[string]*jsonprovider.Attribute{
+							"id": {
+								AttributeType: marshalJson(t, "string"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	change := func(module, name string, actions []string) jsonplan.ResourceChange {
+		address := "test_resource." + name
+		if module != "" {
+			address = module + "." + address
+		}
+		var before, after interface{}
+		if actions[0] != "create" {
+			before = map[string]interface{}{"id": name}
+		}
+		if actions[len(actions)-1] != "delete" {
+			after = map[string]interface{}{"id": name}
+		}
+		return jsonplan.ResourceChange{
+			Address:       address,
+			ModuleAddress: module,
+			Mode:          "managed",
+			Type:          "test_resource",
+			Name:          name,
+			ProviderName:  "test",
+			Change: jsonplan.Change{
+				Actions: actions,
+				Before:  marshalJson(t, before),
+				After:   marshalJson(t, after),
+			},
+		}
+	}
+
+	plan := Plan{
+		ResourceChanges: []jsonplan.ResourceChange{
+			change("", "root", []string{"create"}),
+			change("module.network[0]", "a", []string{"create"}),
+			change("module.network[1]", "a", []string{"create"}),
+			change("module.network[1]", "b", []string{"delete", "create"}),
+			change("module.db", "main", []string{"delete"}),
+		},
+		ProviderSchemas: schemas,
+	}
+
+	tcs := map[string]struct {
+		expand []addrs.Module
+		output string
+	}{
+		"summary only": {
+			output: `
+OpenTofu will perform the following actions:
+
+  (root module)   1 to add
+  module.db       1 to destroy
+  module.network  3 to add, 1 to destroy
+
+To show the individual changes for a module, add the option -expand=ADDRESS,
+such as -expand=module.example.
+
+Plan: 4 to add, 0 to change, 2 to destroy.
+`,
+		},
+		"expanded module": {
+			expand: []addrs.Module{{"db"}},
+			output: `
+OpenTofu will perform the following actions:
+
+  (root module)   1 to add
+  module.db       1 to destroy
+  module.network  3 to add, 1 to destroy
+
+  # module.db.test_resource.main will be destroyed
+  - resource "test_resource" "main" {
+      - id = "main" -> null
+    }
+
+Plan: 4 to add, 0 to change, 2 to destroy.
+`,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			streams, done := terminal.StreamsForTesting(t)
+
+			renderer := Renderer{
+				Colorize:         color,
+				Streams:          streams,
+				SummarizeModules: true,
+				ExpandModules:    tc.expand,
+			}
+			plan.renderHuman(renderer, plans.NormalMode)
+
+			got := done(t).Stdout()
+
+			// The header above the actions varies with the actions present,
+			// so we'll only compare from the list of actions onwards.
+			if i := strings.Index(got, "\nOpenTofu will perform the following actions:"); i >= 0 {
+				got = got[i:]
+			}
+			if diff := cmp.Diff(tc.output, got); len(diff) > 0 {
+				t.Errorf("unexpected output\ngot:\n%s\nwant:\n%s\ndiff:\n%s", got, tc.output, diff)
+			}
+		})
+	}
+}
@@ -30,6 +30,8 @@ type Plan struct {
 	ResourceChanges    []jsonplan.ResourceChange  `json:"resource_changes"`
 	ResourceDrift      []jsonplan.ResourceChange  `json:"resource_drift"`
 	RelevantAttributes []jsonplan.ResourceAttr    `json:"relevant_attributes"`
+	CostEstimate       *jsonplan.CostEstimate     `json:"cost_estimate,omitempty"`
+	BlastRadius        *jsonplan.BlastRadius      `json:"blast_radius,omitempty"`
 
 	ProviderFormatVersion string                            `json:"provider_format_version"`
 	ProviderSchemas       map[string]*jsonprovider.Provider `json:"provider_schemas"`
@@ -211,7 +213,14 @@ func (plan Plan) renderHuman(renderer Renderer, mode plans.Mode, opts ...plans.Q
 			renderer.Streams.Printf("\nOpenTofu will perform the following actions:\n")
 		}
 
+		if renderer.SummarizeModules {
+			renderHumanModuleSummary(renderer, changes)
+		}
+
 		for _, change := range changes {
+			if renderer.SummarizeModules && !renderer.expandModule(changeModule(change)) {
+				continue
+			}
 			diff, render := renderHumanDiff(renderer, change, proposedChange)
 			if render {
 				fmt.Fprintln(renderer.Streams.Stdout.File)
@@ -233,6 +242,13 @@ func (plan Plan) renderHuman(renderer Renderer, mode plans.Mode, opts ...plans.Q
 				counts[plans.Update],
 				counts[plans.Delete]+counts[plans.DeleteThenCreate]+counts[plans.CreateThenDelete])
 		}
+
+		if plan.CostEstimate != nil {
+			renderHumanCostEstimate(renderer, plan.CostEstimate)
+		}
+		if plan.BlastRadius != nil {
+			renderHumanBlastRadius(renderer, plan.BlastRadius)
+		}
 	}
 
 	if len(outputs) > 0 {
@@ -276,6 +292,84 @@ func renderHumanDiffOutputs(renderer Renderer, outputs map[string]computed.Diff)
 	return strings.Join(rendered, "\n")
 }
 
+// renderHumanCostEstimate renders the estimated change in the monthly cost of
+// the infrastructure, followed by the changes for each resource instance.
+func renderHumanCostEstimate(renderer Renderer, estimate *jsonplan.CostEstimate) {
+	renderer.Streams.Printf(
+		renderer.Colorize.Color("\n[bold]Estimated monthly cost change:[reset] %s\n"),
+		formatCostDelta(estimate.TotalMonthlyCostDelta, estimate.Currency))
+
+	var addrMaxLen int
+	for _, rc := range estimate.Resources {
+		if len(rc.Address) > addrMaxLen {
+			addrMaxLen = len(rc.Address)
+		}
+	}
+	for _, rc := range estimate.Resources {
+		renderer.Streams.Printf("  %-*s  %s\n", addrMaxLen, rc.Address, formatCostDelta(rc.MonthlyCostDelta, estimate.Currency))
+	}
+}
+
+// renderHumanBlastRadius renders the summary of the objects that the plan
+// destroys, by category and by module, along with the objects which are
+// likely to lose data.
+func renderHumanBlastRadius(renderer Renderer, radius *jsonplan.BlastRadius) {
+	renderer.Streams.Printf(
+		renderer.Colorize.Color("\n[bold]Blast radius:[reset] %d to destroy, %d may lose data.\n"),
+		radius.Destroyed, radius.DataLoss)
+	if radius.Destroyed == 0 {
+		return
+	}
+
+	categories := make([]string, 0, len(radius.Categories))
+	for category := range radius.Categories {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	for i, category := range categories {
+		categories[i] = fmt.Sprintf("%s %d", category, radius.Categories[category])
+	}
+	renderer.Streams.Printf("  By category: %s\n", strings.Join(categories, ", "))
+
+	if radius.DataLoss > 0 {
+		renderer.Streams.Print(renderer.Colorize.Color("  [bold]May lose data:[reset]\n"))
+		for _, rc := range radius.Resources {
+			if !rc.DataLoss {
+				continue
+			}
+			addr := rc.Address
+			if rc.Deposed != "" {
+				addr = fmt.Sprintf("%s (deposed object %s)", addr, rc.Deposed)
+			}
+			renderer.Streams.Printf("    - %s (%s)\n", addr, rc.Category)
+		}
+	}
+
+	renderer.Streams.Print("  By module:\n")
+	for _, mod := range radius.Modules {
+		line := fmt.Sprintf("    %s: %d to destroy", blastRadiusModuleName(mod.Address), mod.Destroyed)
+		if len(mod.DependentModules) > 0 {
+			dependents := make([]string, len(mod.DependentModules))
+			for i, addr := range mod.DependentModules {
+				dependents[i] = blastRadiusModuleName(addr)
+			}
+			line += ", depended on by " + strings.Join(dependents, ", ")
+		}
+		renderer.Streams.Println(line)
+	}
+}
+
+func blastRadiusModuleName(addr string) string {
+	if addr == "" {
+		return "(root module)"
+	}
+	return addr
+}
+
+func formatCostDelta(delta float64, currency string) string {
+	return fmt.Sprintf("%+.2f %s", delta, currency)
+}
+
 func renderHumanDiffDrift(renderer Renderer, diffs diffs, mode plans.Mode) bool {
 	var drs []diff
 
@@ -497,6 +591,31 @@ func resourceChangeComment(resource jsonplan.ResourceChange, action plans.Action
 	if resource.Change.Importing != nil && (action == plans.CreateThenDelete || action == plans.DeleteThenCreate) {
 		buf.WriteString("  # [reset][yellow]Warning: this will destroy the imported resource[reset]\n")
 	}
+	switch resource.DriftStatus {
+	case jsonplan.DriftRejected:
+		buf.WriteString("  # [reset](this change will not be recorded in the state)\n")
+	case jsonplan.DriftPartial:
+		var accepted, partial, rejected []string
+		for _, attr := range resource.DriftAttributes {
+			switch attr.Status {
+			case jsonplan.DriftAccepted:
+				accepted = append(accepted, attr.Attribute)
+			case jsonplan.DriftPartial:
+				partial = append(partial, attr.Attribute)
+			case jsonplan.DriftRejected:
+				rejected = append(rejected, attr.Attribute)
+			}
+		}
+		if len(accepted) > 0 {
+			buf.WriteString(fmt.Sprintf("  # [reset](changes to %s will be recorded in the state)\n", strings.Join(accepted, ", ")))
+		}
+		if len(partial) > 0 {
+			buf.WriteString(fmt.Sprintf("  # [reset](only some changes to %s will be recorded in the state)\n", strings.Join(partial, ", ")))
+		}
+		if len(rejected) > 0 {
+			buf.WriteString(fmt.Sprintf("  # [reset](changes to %s will not be recorded in the state)\n", strings.Join(rejected, ", ")))
+		}
+	}
 
 	return buf.String()
 }
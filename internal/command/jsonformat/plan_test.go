@@ -13,6 +13,7 @@ import (
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/command/jsonformat/computed/renderers"
 	"github.com/opentofu/opentofu/internal/command/jsonformat/differ"
 	"github.com/opentofu/opentofu/internal/command/jsonformat/structured"
 	"github.com/opentofu/opentofu/internal/command/jsonformat/structured/attribute_path"
@@ -5891,6 +5892,148 @@ func TestResourceChange_actionReason(t *testing.T) {
 	runTestCases(t, testCases)
 }
 
+func TestResourceChange_changeCauses(t *testing.T) {
+	schema := &configschema.Block{
+		Attributes: map[string]*configschema.Attribute{
+			"id":     {Type: cty.String, Computed: true},
+			"ami":    {Type: cty.String, Optional: true},
+			"subnet": {Type: cty.String, Optional: true},
+			"tags":   {Type: cty.Map(cty.String), Optional: true},
+		},
+		BlockTypes: map[string]*configschema.NestedBlock{
+			"disk": {
+				Block: configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"size": {Type: cty.Number, Optional: true},
+					},
+				},
+				Nesting: configschema.NestingList,
+			},
+		},
+	}
+
+	testCases := map[string]testCase{
+		"update": {
+			Action: plans.Update,
+			Mode:   addrs.ManagedResourceMode,
+			Before: cty.ObjectVal(map[string]cty.Value{
+				"id":     cty.StringVal("i-02ae66f368e8518a9"),
+				"ami":    cty.StringVal("ami-BEFORE"),
+				"subnet": cty.StringVal("subnet-BEFORE"),
+				"tags":   cty.MapVal(map[string]cty.Value{"env": cty.StringVal("test")}),
+				"disk": cty.ListVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{"size": cty.NumberIntVal(10)}),
+					cty.ObjectVal(map[string]cty.Value{"size": cty.NumberIntVal(20)}),
+				}),
+			}),
+			After: cty.ObjectVal(map[string]cty.Value{
+				"id":     cty.StringVal("i-02ae66f368e8518a9"),
+				"ami":    cty.StringVal("ami-AFTER"),
+				"subnet": cty.StringVal("subnet-AFTER"),
+				"tags":   cty.MapValEmpty(cty.String),
+				"disk": cty.ListVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{"size": cty.NumberIntVal(10)}),
+					cty.ObjectVal(map[string]cty.Value{"size": cty.NumberIntVal(30)}),
+				}),
+			}),
+			Schema:          schema,
+			RequiredReplace: cty.NewPathSet(),
+			ChangeCauses: []plans.AttributeChangeCause{
+				{
+					Attribute: "ami",
+					Cause:     plans.ChangeCauseConfig,
+					Filename:  "testdata/main.tf",
+					Line:      3,
+				},
+				{
+					Attribute: "disk",
+					Cause:     plans.ChangeCauseConfig,
+					Filename:  "testdata/main.tf",
+					Line:      6,
+				},
+				{
+					Attribute: "subnet",
+					Cause:     plans.ChangeCauseUpstream,
+					Filename:  "testdata/main.tf",
+					Line:      4,
+					Upstream: []addrs.AbsResource{
+						addrs.Resource{
+							Mode: addrs.ManagedResourceMode,
+							Type: "test_subnet",
+							Name: "a",
+						}.Absolute(addrs.RootModuleInstance),
+					},
+				},
+				{
+					Attribute: "tags",
+					Cause:     plans.ChangeCauseDrift,
+				},
+			},
+			ExpectedOutput: `  # test_instance.example will be updated in-place
+  ~ resource "test_instance" "example" {
+      # (changed in configuration at main.tf:3)
+      ~ ami    = "ami-BEFORE" -> "ami-AFTER"
+        id     = "i-02ae66f368e8518a9"
+      # (depends on test_subnet.a, which has changes pending)
+      ~ subnet = "subnet-BEFORE" -> "subnet-AFTER"
+      # (changed outside of OpenTofu)
+      ~ tags   = {
+          - "env" = "test" -> null
+        }
+
+      # (changed in configuration at main.tf:6)
+      ~ disk {
+          ~ size = 20 -> 30
+        }
+
+        # (1 unchanged block hidden)
+    }`,
+		},
+		"replace": {
+			Action:       plans.DeleteThenCreate,
+			ActionReason: plans.ResourceInstanceReplaceBecauseCannotUpdate,
+			Mode:         addrs.ManagedResourceMode,
+			Before: cty.ObjectVal(map[string]cty.Value{
+				"id":     cty.StringVal("i-02ae66f368e8518a9"),
+				"ami":    cty.StringVal("ami-BEFORE"),
+				"subnet": cty.NullVal(cty.String),
+				"tags":   cty.NullVal(cty.Map(cty.String)),
+				"disk":   cty.ListValEmpty(cty.Object(map[string]cty.Type{"size": cty.Number})),
+			}),
+			After: cty.ObjectVal(map[string]cty.Value{
+				"id":     cty.UnknownVal(cty.String),
+				"ami":    cty.StringVal("ami-AFTER"),
+				"subnet": cty.NullVal(cty.String),
+				"tags":   cty.NullVal(cty.Map(cty.String)),
+				"disk":   cty.ListValEmpty(cty.Object(map[string]cty.Type{"size": cty.Number})),
+			}),
+			Schema:          schema,
+			RequiredReplace: cty.NewPathSet(cty.GetAttrPath("ami")),
+			ChangeCauses: []plans.AttributeChangeCause{
+				{
+					Attribute: "ami",
+					Cause:     plans.ChangeCauseConfig,
+					Filename:  "main.tf",
+					Line:      3,
+				},
+				{
+					Attribute: "id",
+					Cause:     plans.ChangeCauseReplacement,
+				},
+			},
+			ExpectedOutput: `  # test_instance.example must be replaced
+-/+ resource "test_instance" "example" {
+      # (changed in configuration at main.tf:3)
+      ~ ami = "ami-BEFORE" -> "ami-AFTER" # forces replacement
+      # (changes because the object must be replaced)
+      ~ id  = "i-02ae66f368e8518a9" -> (known after apply)
+    }`,
+		},
+	}
+
+	runTestCases(t, testCases)
+}
+
 func TestResourceChange_sensitiveVariable(t *testing.T) {
 	testCases := map[string]testCase{
 		"creation": {
@@ -6916,6 +7059,7 @@ type testCase struct {
 	After           cty.Value
 	Schema          *configschema.Block
 	RequiredReplace cty.PathSet
+	ChangeCauses    []plans.AttributeChangeCause
 	ExpectedOutput  string
 	PrevRunAddr     addrs.AbsResourceInstance
 }
@@ -6984,6 +7128,7 @@ func runTestCases(t *testing.T, testCases map[string]testCase) {
 				},
 				ActionReason:    tc.ActionReason,
 				RequiredReplace: tc.RequiredReplace,
+				ChangeCauses:    tc.ChangeCauses,
 			}
 
 			tfschemas := &tofu.Schemas{
@@ -7013,7 +7158,7 @@ func runTestCases(t *testing.T, testCases map[string]testCase) {
 			renderer := Renderer{Colorize: color}
 			diff := diff{
 				change: jsonchanges[0],
-				diff:   differ.ComputeDiffForBlock(change, jsonschemas[jsonchanges[0].ProviderName].ResourceSchemas[jsonchanges[0].Type].Block),
+				diff:   renderers.AnnotateBlock(differ.ComputeDiffForBlock(change, jsonschemas[jsonchanges[0].ProviderName].ResourceSchemas[jsonchanges[0].Type].Block), changeCauseComments(jsonchanges[0])),
 			}
 			output, _ := renderHumanDiff(renderer, diff, proposedChange)
 			if diff := cmp.Diff(output, tc.ExpectedOutput); diff != "" {
@@ -7116,6 +7261,26 @@ func TestOutputChanges(t *testing.T) {
   ~ b = (sensitive value)
   ~ c = false -> true`,
 		},
+		"sensitive attribute changed": {
+			[]*plans.OutputChangeSrc{
+				outputChange(
+					"foo",
+					cty.ObjectVal(map[string]cty.Value{
+						"user":     cty.StringVal("admin"),
+						"password": cty.StringVal("hunter2").Mark(marks.Sensitive),
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"user":     cty.StringVal("root"),
+						"password": cty.StringVal("correct-horse-battery-staple").Mark(marks.Sensitive),
+					}),
+					false,
+				),
+			},
+			`  ~ foo = {
+      ~ password = (sensitive value)
+      ~ user     = "admin" -> "root"
+    }`,
+		},
 	}
 
 	for name, tc := range testCases {
@@ -7309,6 +7474,51 @@ func testSchemaPlus(nesting configschema.NestingMode) *configschema.Block {
 	}
 }
 
+func TestResourceChangeComment_driftStatus(t *testing.T) {
+	tcs := map[string]struct {
+		resource jsonplan.ResourceChange
+		want     string
+	}{
+		"accepted": {
+			resource: jsonplan.ResourceChange{
+				Address:     "test_instance.a",
+				DriftStatus: jsonplan.DriftAccepted,
+			},
+			want: "[bold]  # test_instance.a[reset] has changed\n",
+		},
+		"rejected": {
+			resource: jsonplan.ResourceChange{
+				Address:     "test_instance.a",
+				DriftStatus: jsonplan.DriftRejected,
+			},
+			want: "[bold]  # test_instance.a[reset] has changed\n  # [reset](this change will not be recorded in the state)\n",
+		},
+		"partial": {
+			resource: jsonplan.ResourceChange{
+				Address:     "test_instance.a",
+				DriftStatus: jsonplan.DriftPartial,
+				DriftAttributes: []jsonplan.DriftAttribute{
+					{Attribute: "ami", Status: jsonplan.DriftAccepted},
+					{Attribute: "id", Status: jsonplan.DriftRejected},
+					{Attribute: "tags", Status: jsonplan.DriftPartial},
+				},
+			},
+			want: "[bold]  # test_instance.a[reset] has changed\n" +
+				"  # [reset](changes to ami will be recorded in the state)\n" +
+				"  # [reset](only some changes to tags will be recorded in the state)\n" +
+				"  # [reset](changes to id will not be recorded in the state)\n",
+		},
+	}
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			got := resourceChangeComment(tc.resource, plans.Update, detectedDrift)
+			if got != tc.want {
+				t.Errorf("wrong result\ngot:  %q\nwant: %q", got, tc.want)
+			}
+		})
+	}
+}
+
 func marshalJson(t *testing.T, data interface{}) json.RawMessage {
 	result, err := json.Marshal(data)
 	if err != nil {
@@ -10,6 +10,7 @@ import (
 	"github.com/mitchellh/colorstring"
 	ctyjson "github.com/zclconf/go-cty/cty/json"
 
+	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/command/format"
 	"github.com/opentofu/opentofu/internal/command/jsonformat/computed"
 	"github.com/opentofu/opentofu/internal/command/jsonformat/differ"
@@ -80,6 +81,15 @@ type Renderer struct {
 	Colorize *colorstring.Colorize
 
 	RunningInAutomation bool
+
+	// SummarizeModules causes RenderHumanPlan to show a summary of the
+	// proposed changes for each module call instead of each individual
+	// resource change, which is easier to read for very large plans.
+	SummarizeModules bool
+
+	// ExpandModules lists module calls whose individual resource changes
+	// should still be shown in full when SummarizeModules is set.
+	ExpandModules []addrs.Module
 }
 
 func (renderer Renderer) RenderHumanPlan(plan Plan, mode plans.Mode, opts ...plans.Quality) {
@@ -54,11 +54,36 @@ func Marshal(f map[string]function.Function) ([]byte, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 	signatures := newFunctions()
 
+	sigs, sigDiags := MarshalSignatures(f)
+	diags = diags.Append(sigDiags)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	signatures.Signatures = sigs
+
+	ret, err := json.Marshal(signatures)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to serialize functions",
+			err.Error(),
+		))
+		return nil, diags
+	}
+	return ret, nil
+}
+
+// MarshalSignatures returns the signatures of the given functions, keyed by
+// function name, for callers that embed them in a larger JSON document.
+func MarshalSignatures(f map[string]function.Function) (map[string]*FunctionSignature, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	signatures := make(map[string]*FunctionSignature, len(f))
+
 	for name, v := range f {
 		if name == "can" {
-			signatures.Signatures[name] = marshalCan(v)
+			signatures[name] = marshalCan(v)
 		} else if name == "try" {
-			signatures.Signatures[name] = marshalTry(v)
+			signatures[name] = marshalTry(v)
 		} else {
 			signature, err := marshalFunction(v)
 			if err != nil {
@@ -68,24 +93,14 @@ func Marshal(f map[string]function.Function) ([]byte, tfdiags.Diagnostics) {
 					err.Error(),
 				))
 			}
-			signatures.Signatures[name] = signature
+			signatures[name] = signature
 		}
 	}
 
 	if diags.HasErrors() {
 		return nil, diags
 	}
-
-	ret, err := json.Marshal(signatures)
-	if err != nil {
-		diags = diags.Append(tfdiags.Sourceless(
-			tfdiags.Error,
-			"Failed to serialize functions",
-			err.Error(),
-		))
-		return nil, diags
-	}
-	return ret, nil
+	return signatures, nil
 }
 
 func marshalFunction(f function.Function) (*FunctionSignature, error) {
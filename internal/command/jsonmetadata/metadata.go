@@ -0,0 +1,206 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package jsonmetadata implements the JSON representation of the full
+// language schema available to a configuration, as exported by the
+// "tofu metadata dump" command.
+package jsonmetadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/opentofu/opentofu/internal/command/jsonfunction"
+	"github.com/opentofu/opentofu/internal/command/jsonprovider"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// FormatVersion represents the version of the json format and will be
+// incremented for any change to this format that requires changes to a
+// consuming parser.
+const FormatVersion = "1.0"
+
+// metadata is the top-level object returned when dumping the language schema.
+type metadata struct {
+	FormatVersion      string                                     `json:"format_version"`
+	ProviderSchemas    map[string]*jsonprovider.Provider          `json:"provider_schemas,omitempty"`
+	BackendSchemas     map[string]*jsonprovider.Block             `json:"backend_schemas,omitempty"`
+	RootModule         *module                                    `json:"root_module,omitempty"`
+	FunctionSignatures map[string]*jsonfunction.FunctionSignature `json:"function_signatures,omitempty"`
+}
+
+// module describes the contract of a module: the input variables it accepts,
+// the output values it exports and the contracts of the modules it calls.
+type module struct {
+	Variables   map[string]*variable   `json:"variables,omitempty"`
+	Outputs     map[string]*output     `json:"outputs,omitempty"`
+	ModuleCalls map[string]*moduleCall `json:"module_calls,omitempty"`
+}
+
+type variable struct {
+	// Type is the ctyjson representation of the variable's type constraint,
+	// including any optional object attributes.
+	Type        json.RawMessage `json:"type"`
+	Default     json.RawMessage `json:"default,omitempty"`
+	Required    bool            `json:"required,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Sensitive   bool            `json:"sensitive,omitempty"`
+	Ephemeral   bool            `json:"ephemeral,omitempty"`
+	Nullable    bool            `json:"nullable"`
+	Deprecated  string          `json:"deprecated,omitempty"`
+}
+
+type output struct {
+	Description string `json:"description,omitempty"`
+	Sensitive   bool   `json:"sensitive,omitempty"`
+}
+
+type moduleCall struct {
+	Source string  `json:"source,omitempty"`
+	Module *module `json:"module,omitempty"`
+}
+
+// Marshal returns the JSON representation of the language schema available
+// to the given configuration: the schemas of the providers it requires, the
+// schemas of the given backends, the contracts of its modules and the
+// signatures of the given functions.
+func Marshal(config *configs.Config, schemas *tofu.Schemas, backends map[string]*configschema.Block, funcs map[string]function.Function) ([]byte, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	ret := &metadata{
+		FormatVersion:   FormatVersion,
+		ProviderSchemas: jsonprovider.MarshalForRenderer(schemas),
+	}
+
+	if len(backends) > 0 {
+		ret.BackendSchemas = make(map[string]*jsonprovider.Block, len(backends))
+		for name, schema := range backends {
+			ret.BackendSchemas[name] = jsonprovider.MarshalBlock(schema)
+		}
+	}
+
+	if config != nil {
+		mod, err := marshalModule(config)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to serialize module contracts",
+				err.Error(),
+			))
+			return nil, diags
+		}
+		ret.RootModule = mod
+	}
+
+	sigs, sigDiags := jsonfunction.MarshalSignatures(funcs)
+	diags = diags.Append(sigDiags)
+	if sigDiags.HasErrors() {
+		return nil, diags
+	}
+	ret.FunctionSignatures = sigs
+
+	out, err := json.Marshal(ret)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to serialize metadata",
+			err.Error(),
+		))
+		return nil, diags
+	}
+	return out, diags
+}
+
+func marshalModule(c *configs.Config) (*module, error) {
+	ret := &module{}
+
+	if len(c.Module.Variables) > 0 {
+		ret.Variables = make(map[string]*variable, len(c.Module.Variables))
+		for name, v := range c.Module.Variables {
+			mv, err := marshalVariable(v)
+			if err != nil {
+				return nil, fmt.Errorf("variable %q: %w", name, err)
+			}
+			ret.Variables[name] = mv
+		}
+	}
+
+	if len(c.Module.Outputs) > 0 {
+		ret.Outputs = make(map[string]*output, len(c.Module.Outputs))
+		for name, o := range c.Module.Outputs {
+			ret.Outputs[name] = &output{
+				Description: o.Description,
+				Sensitive:   o.Sensitive,
+			}
+		}
+	}
+
+	if len(c.Module.ModuleCalls) > 0 {
+		names := make([]string, 0, len(c.Module.ModuleCalls))
+		for name := range c.Module.ModuleCalls {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		ret.ModuleCalls = make(map[string]*moduleCall, len(names))
+		for _, name := range names {
+			mc := c.Module.ModuleCalls[name]
+			call := &moduleCall{
+				// As with the "tofu show -json" representation, we echo
+				// back exactly what the user wrote for the source address.
+				Source: mc.SourceAddrRaw,
+			}
+			// The child config may be absent if the module hasn't been
+			// installed yet, in which case we can only report the source.
+			if child := c.Children[name]; child != nil {
+				mod, err := marshalModule(child)
+				if err != nil {
+					return nil, fmt.Errorf("module %q: %w", name, err)
+				}
+				call.Module = mod
+			}
+			ret.ModuleCalls[name] = call
+		}
+	}
+
+	return ret, nil
+}
+
+func marshalVariable(v *configs.Variable) (*variable, error) {
+	ty := v.ConstraintType
+	if ty == cty.NilType {
+		ty = v.Type
+	}
+	if ty == cty.NilType {
+		ty = cty.DynamicPseudoType
+	}
+	tyJSON, err := ctyjson.MarshalType(ty)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &variable{
+		Type:        tyJSON,
+		Required:    v.Default == cty.NilVal,
+		Description: v.Description,
+		Sensitive:   v.Sensitive,
+		Ephemeral:   v.Ephemeral,
+		Nullable:    v.Nullable,
+		Deprecated:  v.Deprecated,
+	}
+	if v.Default != cty.NilVal {
+		ret.Default, err = ctyjson.Marshal(v.Default, v.Default.Type())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
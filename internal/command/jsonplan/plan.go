@@ -18,6 +18,7 @@ import (
 	"github.com/opentofu/opentofu/internal/command/jsonconfig"
 	"github.com/opentofu/opentofu/internal/command/jsonstate"
 	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/lang/marks"
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/states/statefile"
@@ -29,7 +30,7 @@ import (
 // incremented for any change to this format that requires changes to a
 // consuming parser.
 const (
-	FormatVersion = "1.2"
+	FormatVersion = "1.4"
 
 	ResourceInstanceReplaceBecauseCannotUpdate    = "replace_because_cannot_update"
 	ResourceInstanceReplaceBecauseTainted         = "replace_because_tainted"
@@ -44,6 +45,15 @@ const (
 	ResourceInstanceReadBecauseConfigUnknown      = "read_because_config_unknown"
 	ResourceInstanceReadBecauseDependencyPending  = "read_because_dependency_pending"
 	ResourceInstanceReadBecauseCheckNested        = "read_because_check_nested"
+
+	ChangeCauseConfig      = "config"
+	ChangeCauseUpstream    = "upstream"
+	ChangeCauseDrift       = "drift"
+	ChangeCauseReplacement = "replacement"
+
+	DriftAccepted = "accepted"
+	DriftRejected = "rejected"
+	DriftPartial  = "partial"
 )
 
 // Plan is the top-level representation of the json format of a plan. It includes
@@ -64,6 +74,18 @@ type Plan struct {
 	Checks             json.RawMessage   `json:"checks,omitempty"`
 	Timestamp          string            `json:"timestamp,omitempty"`
 	Errored            bool              `json:"errored"`
+	// DeferredResources are the addresses of the resources whose changes
+	// were deferred to a later plan.
+	DeferredResources []string `json:"deferred_resources,omitempty"`
+	// DeferredResourceInstances are the addresses of the individual
+	// resource instances whose changes were deferred to a later plan.
+	DeferredResourceInstances []string `json:"deferred_resource_instances,omitempty"`
+	// CostEstimate is the estimated change in the cost of the
+	// infrastructure, if an external estimator provided one.
+	CostEstimate *CostEstimate `json:"cost_estimate,omitempty"`
+	// BlastRadius summarizes the objects that the plan destroys, if it
+	// was requested when creating the plan.
+	BlastRadius *BlastRadius `json:"blast_radius,omitempty"`
 }
 
 func newPlan() *Plan {
@@ -150,6 +172,47 @@ type Importing struct {
 	ID string `json:"id,omitempty"`
 }
 
+// CostEstimate is the representation of the estimated change in the cost of
+// the infrastructure if the plan is applied.
+type CostEstimate struct {
+	Currency              string                 `json:"currency"`
+	TotalMonthlyCostDelta float64                `json:"total_monthly_cost_delta"`
+	Resources             []ResourceCostEstimate `json:"resources"`
+}
+
+// ResourceCostEstimate is the estimated change in the monthly cost of a
+// single resource instance.
+type ResourceCostEstimate struct {
+	Address          string  `json:"address"`
+	MonthlyCostDelta float64 `json:"monthly_cost_delta"`
+}
+
+// BlastRadius is the representation of the summary of the objects that a
+// plan destroys.
+type BlastRadius struct {
+	Destroyed  int                   `json:"destroyed"`
+	DataLoss   int                   `json:"data_loss"`
+	Categories map[string]int        `json:"categories"`
+	Resources  []BlastRadiusResource `json:"resources"`
+	Modules    []BlastRadiusModule   `json:"modules"`
+}
+
+// BlastRadiusResource is a resource instance object that a plan destroys.
+type BlastRadiusResource struct {
+	Address  string `json:"address"`
+	Deposed  string `json:"deposed,omitempty"`
+	Category string `json:"category"`
+	DataLoss bool   `json:"data_loss"`
+}
+
+// BlastRadiusModule summarizes the objects that a plan destroys in a single
+// module instance. The address of the root module is empty.
+type BlastRadiusModule struct {
+	Address          string   `json:"address"`
+	Destroyed        int      `json:"destroyed"`
+	DependentModules []string `json:"dependent_modules"`
+}
+
 type Output struct {
 	Sensitive bool            `json:"sensitive"`
 	Type      json.RawMessage `json:"type,omitempty"`
@@ -203,6 +266,11 @@ func MarshalForRenderer(
 		if err != nil {
 			return nil, nil, nil, nil, err
 		}
+		if p.UIMode == plans.RefreshOnlyMode {
+			if err := marshalDriftDecisions(p, output.ResourceDrift, schemas); err != nil {
+				return nil, nil, nil, nil, err
+			}
+		}
 	}
 
 	if err := output.marshalRelevantAttrs(p); err != nil {
@@ -224,6 +292,14 @@ func MarshalForLog(
 	output.TerraformVersion = version.String()
 	output.Timestamp = p.Timestamp.Format(time.RFC3339)
 	output.Errored = p.Errored
+	for _, addr := range p.DeferredResources {
+		output.DeferredResources = append(output.DeferredResources, addr.String())
+	}
+	for _, addr := range p.DeferredResourceInstances {
+		output.DeferredResourceInstances = append(output.DeferredResourceInstances, addr.String())
+	}
+	output.CostEstimate = MarshalCostEstimate(p.CostEstimate)
+	output.BlastRadius = MarshalBlastRadius(p.BlastRadius)
 
 	err := output.marshalPlanVariables(p.VariableValues, config.Module.Variables)
 	if err != nil {
@@ -256,6 +332,11 @@ func MarshalForLog(
 		if err != nil {
 			return nil, fmt.Errorf("error in marshaling resource drift: %w", err)
 		}
+		if p.UIMode == plans.RefreshOnlyMode {
+			if err := marshalDriftDecisions(p, output.ResourceDrift, schemas); err != nil {
+				return nil, fmt.Errorf("error in marshaling resource drift: %w", err)
+			}
+		}
 	}
 
 	if err := output.marshalRelevantAttrs(p); err != nil {
@@ -312,6 +393,64 @@ func Marshal(
 	return json.Marshal(output)
 }
 
+// MarshalCostEstimate returns the JSON representation of the given cost
+// estimate, which may be nil.
+func MarshalCostEstimate(estimate *plans.CostEstimate) *CostEstimate {
+	if estimate == nil {
+		return nil
+	}
+	ret := &CostEstimate{
+		Currency:              estimate.Currency,
+		TotalMonthlyCostDelta: estimate.TotalMonthlyCostDelta(),
+		Resources:             make([]ResourceCostEstimate, 0, len(estimate.Resources)),
+	}
+	for _, rc := range estimate.Resources {
+		ret.Resources = append(ret.Resources, ResourceCostEstimate{
+			Address:          rc.Addr.String(),
+			MonthlyCostDelta: rc.MonthlyCostDelta,
+		})
+	}
+	return ret
+}
+
+// MarshalBlastRadius returns the JSON representation of the given blast
+// radius, which may be nil.
+func MarshalBlastRadius(radius *plans.BlastRadius) *BlastRadius {
+	if radius == nil {
+		return nil
+	}
+	ret := &BlastRadius{
+		Destroyed:  len(radius.Resources),
+		DataLoss:   len(radius.DataLoss()),
+		Categories: make(map[string]int),
+		Resources:  make([]BlastRadiusResource, 0, len(radius.Resources)),
+		Modules:    make([]BlastRadiusModule, 0, len(radius.Modules)),
+	}
+	for category, count := range radius.Categories() {
+		ret.Categories[string(category)] = count
+	}
+	for _, rc := range radius.Resources {
+		ret.Resources = append(ret.Resources, BlastRadiusResource{
+			Address:  rc.Addr.String(),
+			Deposed:  string(rc.DeposedKey),
+			Category: string(rc.Category),
+			DataLoss: rc.DataLoss,
+		})
+	}
+	for _, mod := range radius.Modules {
+		jm := BlastRadiusModule{
+			Address:          mod.Addr.String(),
+			Destroyed:        mod.Destroyed,
+			DependentModules: make([]string, 0, len(mod.DependentModules)),
+		}
+		for _, addr := range mod.DependentModules {
+			jm.DependentModules = append(jm.DependentModules, addr.String())
+		}
+		ret.Modules = append(ret.Modules, jm)
+	}
+	return ret
+}
+
 func (p *Plan) marshalPlanVariables(vars map[string]plans.DynamicValue, decls map[string]*configs.Variable) error {
 	p.Variables = make(Variables, len(vars))
 
@@ -413,6 +552,7 @@ func MarshalResourceChanges(resources []*plans.ResourceInstanceChangeSrc, schema
 		if err != nil {
 			return nil, err
 		}
+
 		// We drop the marks from the change, as decoding is only an
 		// intermediate step to re-encode the values as json
 		changeV.Before, _ = changeV.Before.UnmarkDeep()
@@ -551,6 +691,32 @@ func MarshalResourceChanges(resources []*plans.ResourceInstanceChangeSrc, schema
 			return nil, fmt.Errorf("resource %s has an unsupported action reason %s", r.Address, rc.ActionReason)
 		}
 
+		for _, cause := range rc.ChangeCauses {
+			c := ChangeCause{
+				Attribute: cause.Attribute,
+				Filename:  cause.Filename,
+				Line:      cause.Line,
+			}
+			switch cause.Cause {
+			case plans.ChangeCauseNone:
+				continue
+			case plans.ChangeCauseConfig:
+				c.Cause = ChangeCauseConfig
+			case plans.ChangeCauseUpstream:
+				c.Cause = ChangeCauseUpstream
+			case plans.ChangeCauseDrift:
+				c.Cause = ChangeCauseDrift
+			case plans.ChangeCauseReplacement:
+				c.Cause = ChangeCauseReplacement
+			default:
+				return nil, fmt.Errorf("resource %s has an unsupported change cause %s for %q", r.Address, cause.Cause, cause.Attribute)
+			}
+			for _, addr := range cause.Upstream {
+				c.Upstream = append(c.Upstream, addr.String())
+			}
+			r.ChangeCauses = append(r.ChangeCauses, c)
+		}
+
 		ret = append(ret, r)
 
 	}
@@ -585,6 +751,22 @@ func MarshalOutputChanges(changes *plans.Changes) (map[string]Change, error) {
 		if err != nil {
 			return nil, err
 		}
+
+		// The plan records whether a whole output was or is marked sensitive,
+		// in which case BeforeSensitive and AfterSensitive are both true.
+		// Otherwise, an output value with sensitive attributes has sensitive
+		// marks only on those parts of the value, and other output values
+		// have no sensitive marks at all.
+		beforeSensitive, afterSensitive := cty.True, cty.True
+		if !oc.Sensitive {
+			beforeSensitive, afterSensitive = cty.False, cty.False
+			if changeV.Before != cty.NilVal && marks.Contains(changeV.Before, marks.Sensitive) {
+				beforeSensitive = jsonstate.SensitiveAsBool(changeV.Before)
+			}
+			if changeV.After != cty.NilVal && marks.Contains(changeV.After, marks.Sensitive) {
+				afterSensitive = jsonstate.SensitiveAsBool(changeV.After)
+			}
+		}
 		// We drop the marks from the change, as decoding is only an
 		// intermediate step to re-encode the values as json
 		changeV.Before, _ = changeV.Before.UnmarkDeep()
@@ -620,15 +802,11 @@ func MarshalOutputChanges(changes *plans.Changes) (map[string]Change, error) {
 			}
 		}
 
-		// The only information we have in the plan about output sensitivity is
-		// a boolean which is true if the output was or is marked sensitive. As
-		// a result, BeforeSensitive and AfterSensitive will be identical, and
-		// either false or true.
-		outputSensitive := cty.False
-		if oc.Sensitive {
-			outputSensitive = cty.True
+		bs, err := ctyjson.Marshal(beforeSensitive, beforeSensitive.Type())
+		if err != nil {
+			return nil, err
 		}
-		sensitive, err := ctyjson.Marshal(outputSensitive, outputSensitive.Type())
+		as, err := ctyjson.Marshal(afterSensitive, afterSensitive.Type())
 		if err != nil {
 			return nil, err
 		}
@@ -640,8 +818,8 @@ func MarshalOutputChanges(changes *plans.Changes) (map[string]Change, error) {
 			Before:          json.RawMessage(before),
 			After:           json.RawMessage(after),
 			AfterUnknown:    a,
-			BeforeSensitive: json.RawMessage(sensitive),
-			AfterSensitive:  json.RawMessage(sensitive),
+			BeforeSensitive: json.RawMessage(bs),
+			AfterSensitive:  json.RawMessage(as),
 
 			// Just to be explicit, outputs cannot be imported so this is always
 			// nil.
@@ -672,6 +850,95 @@ func (p *Plan) marshalPlannedValues(changes *plans.Changes, schemas *tofu.Schema
 	return nil
 }
 
+// marshalDriftDecisions records in the given resource drift of a
+// refresh-only plan whether applying the plan accepts each of the changes
+// into the state, which it decides by comparing them with the plan's prior
+// state. The changes that aren't accepted are left for a later plan to
+// correct.
+func marshalDriftDecisions(p *plans.Plan, drift []ResourceChange, schemas *tofu.Schemas) error {
+	if p.PriorState == nil {
+		return nil
+	}
+
+	byAddr := make(map[string]*ResourceChange, len(drift))
+	for i := range drift {
+		byAddr[drift[i].Address] = &drift[i]
+	}
+
+	for _, dr := range p.DriftedResources {
+		r := byAddr[dr.Addr.String()]
+		if r == nil || (dr.Action != plans.Update && dr.Action != plans.Delete) {
+			continue
+		}
+
+		var prior *states.ResourceInstanceObjectSrc
+		if is := p.PriorState.ResourceInstance(dr.Addr); is != nil {
+			prior = is.Current
+		}
+		if dr.Action == plans.Delete {
+			r.DriftStatus = DriftAccepted
+			if prior != nil {
+				r.DriftStatus = DriftRejected
+			}
+			continue
+		}
+		if prior == nil {
+			// Should never happen, because the object still exists.
+			continue
+		}
+
+		schema, _ := schemas.ResourceTypeConfig(
+			dr.ProviderAddr.Provider,
+			dr.Addr.Resource.Resource.Mode,
+			dr.Addr.Resource.Resource.Type,
+		)
+		if schema == nil {
+			return fmt.Errorf("no schema found for %s (in provider %s)", r.Address, dr.ProviderAddr.Provider)
+		}
+		ty := schema.ImpliedType()
+		change, err := dr.Decode(ty)
+		if err != nil {
+			return err
+		}
+		priorObj, err := prior.Decode(ty)
+		if err != nil {
+			return err
+		}
+		before, _ := change.Before.UnmarkDeep()
+		after, _ := change.After.UnmarkDeep()
+		priorVal, _ := priorObj.Value.UnmarkDeep()
+
+		r.DriftStatus = driftStatus(before, after, priorVal)
+		names := make([]string, 0, len(ty.AttributeTypes()))
+		for name := range ty.AttributeTypes() {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			b, a := before.GetAttr(name), after.GetAttr(name)
+			if b.RawEquals(a) {
+				continue
+			}
+			r.DriftAttributes = append(r.DriftAttributes, DriftAttribute{
+				Attribute: name,
+				Status:    driftStatus(b, a, priorVal.GetAttr(name)),
+			})
+		}
+	}
+	return nil
+}
+
+func driftStatus(before, after, prior cty.Value) string {
+	switch {
+	case prior.RawEquals(after):
+		return DriftAccepted
+	case prior.RawEquals(before):
+		return DriftRejected
+	default:
+		return DriftPartial
+	}
+}
+
 func (p *Plan) marshalRelevantAttrs(plan *plans.Plan) error {
 	for _, ra := range plan.RelevantAttributes {
 		addr := ra.Resource.String()
@@ -92,4 +92,52 @@ type ResourceChange struct {
 	// information should be resilient to encountering unrecognized values
 	// and treat them as an unspecified reason.
 	ActionReason string `json:"action_reason,omitempty"`
+
+	// ChangeCauses explain why each of the top-level attributes and nested
+	// block types that differ between Change.Before and Change.After is
+	// changing. Like ActionReason, this is only for display purposes, and
+	// consumers should be resilient to encountering unrecognized causes.
+	ChangeCauses []ChangeCause `json:"change_causes,omitempty"`
+
+	// DriftStatus is set only for the resource drift of a refresh-only plan,
+	// and is "accepted" if applying the plan records the change in the
+	// state, "rejected" if it doesn't, or "partial" if it records only some
+	// of the changes to the object's attributes.
+	DriftStatus string `json:"drift_status,omitempty"`
+
+	// DriftAttributes describe whether applying a refresh-only plan records
+	// the changes to each of the top-level attributes and nested block types
+	// that differ between Change.Before and Change.After.
+	DriftAttributes []DriftAttribute `json:"drift_attributes,omitempty"`
+}
+
+// DriftAttribute is the status of the change made outside of OpenTofu to a
+// single attribute or nested block type of a resource instance object.
+type DriftAttribute struct {
+	// Attribute is the name of the attribute or nested block type.
+	Attribute string `json:"attribute"`
+
+	// Status is one of "accepted", "rejected" or "partial", like
+	// ResourceChange.DriftStatus.
+	Status string `json:"status"`
+}
+
+// ChangeCause is the explanation for a change to a single attribute or
+// nested block type of a resource instance object.
+type ChangeCause struct {
+	// Attribute is the name of the attribute or nested block type.
+	Attribute string `json:"attribute"`
+
+	// Cause is one of "config", "upstream", "drift" or "replacement".
+	Cause string `json:"cause"`
+
+	// Filename and Line locate the configuration of the attribute, for the
+	// "config" and "upstream" causes.
+	Filename string `json:"filename,omitempty"`
+	Line     int    `json:"line,omitempty"`
+
+	// Upstream contains the addresses of the resources referenced by the
+	// configuration of the attribute which have changes pending, for the
+	// "upstream" cause.
+	Upstream []string `json:"upstream,omitempty"`
 }
@@ -20,6 +20,7 @@ type Attribute struct {
 	Optional            bool            `json:"optional,omitempty"`
 	Computed            bool            `json:"computed,omitempty"`
 	Sensitive           bool            `json:"sensitive,omitempty"`
+	Ephemeral           bool            `json:"ephemeral,omitempty"`
 }
 
 type NestedType struct {
@@ -44,6 +45,7 @@ func marshalAttribute(attr *configschema.Attribute) *Attribute {
 		Optional:        attr.Optional,
 		Computed:        attr.Computed,
 		Sensitive:       attr.Sensitive,
+		Ephemeral:       attr.Ephemeral,
 		Deprecated:      attr.Deprecated,
 	}
 
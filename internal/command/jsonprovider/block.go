@@ -81,3 +81,10 @@ func nestingModeString(mode configschema.NestingMode) string {
 		return "invalid"
 	}
 }
+
+// MarshalBlock returns the JSON representation of the given configuration
+// schema block, for schemas that don't belong to a provider such as those
+// of the built-in backends.
+func MarshalBlock(configBlock *configschema.Block) *Block {
+	return marshalBlock(configBlock)
+}
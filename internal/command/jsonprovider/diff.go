@@ -0,0 +1,132 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonprovider
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// SchemaDiff is the top-level object returned when comparing two exports of
+// provider schemas.
+type SchemaDiff struct {
+	FormatVersion string         `json:"format_version"`
+	Changes       []SchemaChange `json:"changes"`
+}
+
+// SchemaChange describes a single difference between two exports of provider
+// schemas.
+type SchemaChange struct {
+	// Path is the sequence of object property names that lead to the
+	// changed value, starting from the top-level "provider_schemas" object.
+	Path []string `json:"path"`
+
+	// Action is "create" if the value is new, "delete" if it was removed,
+	// and "update" if it changed.
+	Action string `json:"action"`
+
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// Diff returns the differences between the old and new schemas, in order of
+// their paths.
+//
+// The differences are reported for the most specific object properties that
+// differ, so a new attribute is reported as a single change with the whole
+// attribute as its After value, while a change to whether an existing
+// attribute is required is reported as a change to just its "required"
+// property.
+func Diff(old, new *Providers) (*SchemaDiff, error) {
+	oldVal, err := genericJSON(old.Schemas)
+	if err != nil {
+		return nil, err
+	}
+	newVal, err := genericJSON(new.Schemas)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &SchemaDiff{
+		FormatVersion: FormatVersion,
+		Changes:       []SchemaChange{},
+	}
+	err = diffJSON([]string{"provider_schemas"}, oldVal, newVal, &ret.Changes)
+	return ret, err
+}
+
+func diffJSON(path []string, old, new interface{}, changes *[]SchemaChange) error {
+	oldObj, oldIsObj := old.(map[string]interface{})
+	newObj, newIsObj := new.(map[string]interface{})
+	if !oldIsObj || !newIsObj {
+		if reflect.DeepEqual(old, new) {
+			return nil
+		}
+		change := SchemaChange{
+			Path:   path,
+			Action: "update",
+		}
+		var err error
+		if change.Before, err = json.Marshal(old); err != nil {
+			return err
+		}
+		if change.After, err = json.Marshal(new); err != nil {
+			return err
+		}
+		*changes = append(*changes, change)
+		return nil
+	}
+
+	names := make([]string, 0, len(oldObj)+len(newObj))
+	for name := range oldObj {
+		names = append(names, name)
+	}
+	for name := range newObj {
+		if _, exists := oldObj[name]; !exists {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		childPath := make([]string, len(path)+1)
+		copy(childPath, path)
+		childPath[len(path)] = name
+
+		oldChild, inOld := oldObj[name]
+		newChild, inNew := newObj[name]
+		switch {
+		case !inOld:
+			after, err := json.Marshal(newChild)
+			if err != nil {
+				return err
+			}
+			*changes = append(*changes, SchemaChange{Path: childPath, Action: "create", After: after})
+		case !inNew:
+			before, err := json.Marshal(oldChild)
+			if err != nil {
+				return err
+			}
+			*changes = append(*changes, SchemaChange{Path: childPath, Action: "delete", Before: before})
+		default:
+			if err := diffJSON(childPath, oldChild, newChild, changes); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// genericJSON returns v as the generic values that encoding/json produces
+// when unmarshaling into an empty interface.
+func genericJSON(v interface{}) (interface{}, error) {
+	src, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var ret interface{}
+	err = json.Unmarshal(src, &ret)
+	return ret, err
+}
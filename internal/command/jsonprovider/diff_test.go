@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonprovider
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/providers"
+)
+
+func TestDiff(t *testing.T) {
+	oldSchema := testProvider()
+	newSchema := testProvider()
+	newSchema.Provider.Block = &configschema.Block{
+		Attributes: map[string]*configschema.Attribute{
+			"region": {Type: cty.String, Optional: true},
+		},
+	}
+	newSchema.ResourceTypes = map[string]providers.Schema{
+		"test_instance": oldSchema.ResourceTypes["test_instance"],
+		"test_other":    {Block: &configschema.Block{}},
+	}
+
+	old := &Providers{Schemas: map[string]*Provider{"test": marshalProvider(oldSchema)}}
+	new := &Providers{Schemas: map[string]*Provider{"test": marshalProvider(newSchema)}}
+	delete(new.Schemas["test"].DataSourceSchemas["test_data_source"].Block.Attributes, "ami")
+	got, err := Diff(old, new)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gotChanges []string
+	for _, change := range got.Changes {
+		src, err := json.Marshal(change)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotChanges = append(gotChanges, string(src))
+	}
+	want := []string{
+		`{"path":["provider_schemas","test","data_source_schemas","test_data_source","block","attributes","ami"],"action":"delete","before":{"description_kind":"plain","optional":true,"type":"string"}}`,
+		`{"path":["provider_schemas","test","provider","block","attributes","region","optional"],"action":"create","after":true}`,
+		`{"path":["provider_schemas","test","provider","block","attributes","region","required"],"action":"delete","before":true}`,
+		`{"path":["provider_schemas","test","resource_schemas","test_other"],"action":"create","after":{"block":{"description_kind":"plain"},"version":0}}`,
+	}
+	if diff := cmp.Diff(want, gotChanges); diff != "" {
+		t.Errorf("wrong changes\n%s", diff)
+	}
+
+	got, err = Diff(old, old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Changes) != 0 {
+		t.Errorf("unexpected changes between identical schemas: %#v", got.Changes)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonprovider
+
+import (
+	"fmt"
+	"path"
+)
+
+// Filter removes from p the schemas of the providers whose addresses aren't
+// in providerAddrs, and the schemas of the resource types and data sources
+// whose names don't match any of typePatterns, which use the syntax of
+// path.Match. A nil or empty filter doesn't remove anything.
+//
+// If typePatterns isn't empty, Filter also removes the providers that have no
+// matching resource types or data sources.
+func Filter(p *Providers, providerAddrs []string, typePatterns []string) error {
+	for _, pattern := range typePatterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid type pattern %q: %w", pattern, err)
+		}
+	}
+	matchType := func(name string) bool {
+		for _, pattern := range typePatterns {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	wantProvider := make(map[string]bool, len(providerAddrs))
+	for _, addr := range providerAddrs {
+		wantProvider[addr] = true
+	}
+
+	for addr, provider := range p.Schemas {
+		if len(wantProvider) > 0 && !wantProvider[addr] {
+			delete(p.Schemas, addr)
+			continue
+		}
+		if len(typePatterns) == 0 {
+			continue
+		}
+		for name := range provider.ResourceSchemas {
+			if !matchType(name) {
+				delete(provider.ResourceSchemas, name)
+			}
+		}
+		for name := range provider.DataSourceSchemas {
+			if !matchType(name) {
+				delete(provider.DataSourceSchemas, name)
+			}
+		}
+		if len(provider.ResourceSchemas) == 0 && len(provider.DataSourceSchemas) == 0 {
+			delete(p.Schemas, addr)
+		}
+	}
+	return nil
+}
+
+// Compact removes the documentation from all of the schemas in p, which
+// makes up most of its size and isn't needed to generate code or check
+// policies.
+func Compact(p *Providers) {
+	for _, provider := range p.Schemas {
+		compactSchema(provider.Provider)
+		for _, schema := range provider.ResourceSchemas {
+			compactSchema(schema)
+		}
+		for _, schema := range provider.DataSourceSchemas {
+			compactSchema(schema)
+		}
+	}
+}
+
+func compactSchema(schema *Schema) {
+	if schema != nil {
+		compactBlock(schema.Block)
+	}
+}
+
+func compactBlock(block *Block) {
+	if block == nil {
+		return
+	}
+	block.Description = ""
+	block.DescriptionKind = ""
+	compactAttributes(block.Attributes)
+	for _, blockType := range block.BlockTypes {
+		compactBlock(blockType.Block)
+	}
+}
+
+func compactAttributes(attrs map[string]*Attribute) {
+	for _, attr := range attrs {
+		attr.Description = ""
+		attr.DescriptionKind = ""
+		if attr.AttributeNestedType != nil {
+			compactAttributes(attr.AttributeNestedType.Attributes)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonprovider
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFilter(t *testing.T) {
+	schemas := func() *Providers {
+		return &Providers{
+			FormatVersion: FormatVersion,
+			Schemas: map[string]*Provider{
+				"registry.opentofu.org/hashicorp/test": marshalProvider(testProvider()),
+				"registry.opentofu.org/hashicorp/other": {
+					Provider:          &Schema{},
+					ResourceSchemas:   map[string]*Schema{"other_thing": {}},
+					DataSourceSchemas: map[string]*Schema{},
+				},
+			},
+		}
+	}
+	names := func(p *Providers) map[string][]string {
+		ret := make(map[string][]string)
+		for addr, provider := range p.Schemas {
+			ret[addr] = []string{}
+			for name := range provider.ResourceSchemas {
+				ret[addr] = append(ret[addr], "resource."+name)
+			}
+			for name := range provider.DataSourceSchemas {
+				ret[addr] = append(ret[addr], "data."+name)
+			}
+			sort.Strings(ret[addr])
+		}
+		return ret
+	}
+
+	tests := map[string]struct {
+		providers []string
+		types     []string
+		want      map[string][]string
+	}{
+		"provider": {
+			providers: []string{"registry.opentofu.org/hashicorp/other"},
+			want: map[string][]string{
+				"registry.opentofu.org/hashicorp/other": {"resource.other_thing"},
+			},
+		},
+		"type pattern": {
+			types: []string{"test_*"},
+			want: map[string][]string{
+				"registry.opentofu.org/hashicorp/test": {"data.test_data_source", "resource.test_instance"},
+			},
+		},
+		"provider and type": {
+			providers: []string{"registry.opentofu.org/hashicorp/other"},
+			types:     []string{"test_instance"},
+			want:      map[string][]string{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := schemas()
+			if err := Filter(p, test.providers, test.types); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.want, names(p)); diff != "" {
+				t.Errorf("wrong result\n%s", diff)
+			}
+		})
+	}
+
+	if err := Filter(schemas(), nil, []string{"["}); err == nil {
+		t.Error("invalid pattern was accepted")
+	}
+}
+
+func TestCompact(t *testing.T) {
+	p := &Providers{
+		Schemas: map[string]*Provider{
+			"test": marshalProvider(testProvider()),
+		},
+	}
+	Compact(p)
+
+	attr := p.Schemas["test"].ResourceSchemas["test_instance"].Block.Attributes["id"]
+	if attr.Description != "" || attr.DescriptionKind != "" {
+		t.Errorf("description wasn't removed: %#v", attr)
+	}
+	if !attr.Optional || !attr.Computed {
+		t.Errorf("attribute details were removed: %#v", attr)
+	}
+}
@@ -48,10 +48,16 @@ func MarshalForRenderer(s *tofu.Schemas) map[string]*Provider {
 }
 
 func Marshal(s *tofu.Schemas) ([]byte, error) {
+	ret, err := json.Marshal(NewProviders(s))
+	return ret, err
+}
+
+// NewProviders returns the public structured JSON representation of the given
+// schemas, for callers that need to filter or compare it before marshaling.
+func NewProviders(s *tofu.Schemas) *Providers {
 	providers := newProviders()
 	providers.Schemas = MarshalForRenderer(s)
-	ret, err := json.Marshal(providers)
-	return ret, err
+	return providers
 }
 
 func marshalProvider(tps providers.ProviderSchema) *Provider {
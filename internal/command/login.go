@@ -44,7 +44,9 @@ type LoginCommand struct {
 // Run implements cli.Command.
 func (c *LoginCommand) Run(args []string) int {
 	args = c.Meta.process(args)
+	var device bool
 	cmdFlags := c.Meta.extendedFlagSet("login")
+	cmdFlags.BoolVar(&device, "device", false, "device")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		return 1
@@ -175,14 +177,29 @@ func (c *LoginCommand) Run(args []string) int {
 		return 1
 	}
 
-	var token svcauth.HostCredentialsToken
+	var token svcauth.HostCredentialsWritable
 	var tokenDiags tfdiags.Diagnostics
 
 	// Prefer OpenTofu login if available
 	if clientConfig != nil {
 		var oauthToken *oauth2.Token
+		var tokenURL string
+		if clientConfig.TokenURL != nil {
+			tokenURL = clientConfig.TokenURL.String()
+		}
 
+		supportsDevice := clientConfig.SupportedGrantTypes.Has(oauthDeviceCodeGrant)
 		switch {
+		case device && !supportsDevice:
+			tokenDiags = tokenDiags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Host does not support device login",
+				fmt.Sprintf("The given hostname %q does not allow the OAuth device authorization grant. Run \"tofu login\" without the -device option to use another login method.", dispHostname),
+			))
+		case supportsDevice && (device || !clientConfig.SupportedGrantTypes.Has(disco.OAuthAuthzCodeGrant)):
+			// The device grant works without a browser on this computer, so
+			// we use it when requested or when it's the only option.
+			oauthToken, tokenURL, tokenDiags = c.interactiveGetTokenByDevice(hostname, host, credsCtx, clientConfig)
 		case clientConfig.SupportedGrantTypes.Has(disco.OAuthAuthzCodeGrant):
 			// We prefer an OAuth code grant if the server supports it.
 			oauthToken, tokenDiags = c.interactiveGetTokenByCode(hostname, credsCtx, clientConfig)
@@ -199,7 +216,9 @@ func (c *LoginCommand) Run(args []string) int {
 			))
 		}
 		if oauthToken != nil {
-			token = svcauth.HostCredentialsToken(oauthToken.AccessToken)
+			// If the host issued a refresh token then we store it too, so
+			// that the access token can be refreshed when it expires.
+			token = cliconfig.NewOAuthCredentials(oauthToken, tokenURL, clientConfig.ID)
 		}
 	} else if tfeservice != nil {
 		token, tokenDiags = c.interactiveGetTokenByUI(hostname, credsCtx, tfeservice)
@@ -336,7 +355,7 @@ func (c *LoginCommand) Help() string {
 	}
 
 	helpText := fmt.Sprintf(`
-Usage: tofu [global options] login [hostname]
+Usage: tofu [global options] login [options] [hostname]
 
   Retrieves an authentication token for the given hostname, if it supports
   automatic login, and saves it in a credentials file in your home directory.
@@ -344,6 +363,13 @@ Usage: tofu [global options] login [hostname]
   If not overridden by credentials helper settings in the CLI configuration,
   the credentials will be written to the following local file:
       %s
+
+Options:
+
+  -device             Log in using the OAuth device authorization flow, which
+                      shows a code to enter in a web browser on any device.
+                      This is the default if the host supports no other
+                      login method.
 `, defaultFile)
 	return strings.TrimSpace(helpText)
 }
@@ -0,0 +1,270 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	svchost "github.com/hashicorp/terraform-svchost"
+	"github.com/hashicorp/terraform-svchost/disco"
+	"golang.org/x/oauth2"
+
+	"github.com/opentofu/opentofu/internal/httpclient"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// oauthDeviceCodeGrant is the keyword a host uses in the grant_types of its
+// login.v1 service to indicate that it supports the OAuth device
+// authorization grant, as defined in RFC 8628.
+const oauthDeviceCodeGrant = disco.OAuthGrantType("device_code")
+
+// oauthDeviceCodeGrantURN is the grant_type value to use in token requests
+// for the device authorization grant.
+const oauthDeviceCodeGrantURN = "urn:ietf:params:oauth:grant-type:device_code"
+
+// defaultDevicePollInterval is how long we wait between token requests when
+// the device authorization response doesn't specify an interval.
+const defaultDevicePollInterval = 5 * time.Second
+
+// oidcProviderConfig is the subset of an OpenID Connect provider's
+// discovery document that the device authorization grant uses.
+type oidcProviderConfig struct {
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+}
+
+// deviceAuthorization is a device authorization response, as defined in
+// RFC 8628 section 3.2.
+type deviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                *int   `json:"interval"`
+}
+
+// deviceTokenResponse is a successful or error response from the token
+// endpoint, as defined in RFC 6749 section 5.
+type deviceTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// interactiveGetTokenByDevice obtains a token using the OAuth device
+// authorization grant. The user completes the login on any device with a web
+// browser, so this works even where OpenTofu can't start a browser or
+// receive a callback on localhost.
+//
+// The device authorization and token endpoints come from the OpenID Connect
+// discovery document of the issuer given in the host's oidc.v1 service.
+// The returned URL is the token endpoint, for use when refreshing the token.
+func (c *LoginCommand) interactiveGetTokenByDevice(hostname svchost.Hostname, host *disco.Host, credsCtx *loginCredentialsContext, clientConfig *disco.OAuthClient) (*oauth2.Token, string, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	issuer, err := host.ServiceURL("oidc.v1")
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Host does not support device login",
+			fmt.Sprintf("The given hostname %q allows the device authorization grant, but does not advertise an OpenID Connect issuer in its oidc.v1 service: %s.", hostname.ForDisplay(), err),
+		))
+		return nil, "", diags
+	}
+
+	client := httpclient.New()
+	provider, err := fetchOIDCProviderConfig(client, issuer)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to discover device login endpoints",
+			fmt.Sprintf("OpenTofu could not read the OpenID Connect configuration of %s: %s.", issuer, err),
+		))
+		return nil, "", diags
+	}
+
+	confirm, confirmDiags := c.interactiveContextConsent(hostname, oauthDeviceCodeGrant, credsCtx)
+	diags = diags.Append(confirmDiags)
+	if !confirm {
+		diags = diags.Append(errors.New("Login cancelled"))
+		return nil, "", diags
+	}
+
+	authz, err := requestDeviceAuthorization(client, provider.DeviceAuthorizationEndpoint, clientConfig)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to start device login",
+			fmt.Sprintf("The remote server did not accept the device authorization request: %s.", err),
+		))
+		return nil, "", diags
+	}
+
+	c.Ui.Output(fmt.Sprintf("To log in to %s, open the following URL in a web browser on any device:\n    %s\n", hostname.ForDisplay(), authz.VerificationURI))
+	c.Ui.Output(fmt.Sprintf("and enter the code:\n    %s\n", authz.UserCode))
+	if authz.VerificationURIComplete != "" && c.BrowserLauncher != nil {
+		if err := c.BrowserLauncher.OpenURL(authz.VerificationURIComplete); err != nil {
+			log.Printf("[DEBUG] login: can't open browser: %s", err)
+		}
+	}
+	c.Ui.Output("OpenTofu will now wait for the host to signal that login was successful.\n")
+
+	interval := defaultDevicePollInterval
+	if authz.Interval != nil {
+		interval = time.Duration(*authz.Interval) * time.Second
+	}
+	var deadline time.Time
+	if authz.ExpiresIn > 0 {
+		deadline = time.Now().Add(time.Duration(authz.ExpiresIn) * time.Second)
+	}
+
+	for {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Device login expired",
+				"The login was not completed before the code expired. Run \"tofu login\" again to get a new code.",
+			))
+			return nil, "", diags
+		}
+		time.Sleep(interval)
+
+		resp, err := requestDeviceToken(client, provider.TokenEndpoint, clientConfig.ID, authz.DeviceCode)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to obtain auth token",
+				fmt.Sprintf("The remote server did not assign an auth token: %s.", err),
+			))
+			return nil, "", diags
+		}
+
+		switch resp.Error {
+		case "":
+			token := &oauth2.Token{
+				AccessToken:  resp.AccessToken,
+				TokenType:    resp.TokenType,
+				RefreshToken: resp.RefreshToken,
+			}
+			if resp.ExpiresIn > 0 {
+				token.Expiry = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+			}
+			return token, provider.TokenEndpoint, diags
+		case "authorization_pending":
+			log.Printf("[TRACE] login: device authorization is still pending")
+		case "slow_down":
+			interval += 5 * time.Second
+			log.Printf("[TRACE] login: server asked us to slow down; now polling every %s", interval)
+		case "access_denied":
+			diags = diags.Append(errors.New("Login cancelled: the request was denied"))
+			return nil, "", diags
+		case "expired_token":
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Device login expired",
+				"The login was not completed before the code expired. Run \"tofu login\" again to get a new code.",
+			))
+			return nil, "", diags
+		default:
+			msg := resp.Error
+			if resp.ErrorDescription != "" {
+				msg = fmt.Sprintf("%s (%s)", resp.Error, resp.ErrorDescription)
+			}
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to obtain auth token",
+				fmt.Sprintf("The remote server did not assign an auth token: %s.", msg),
+			))
+			return nil, "", diags
+		}
+	}
+}
+
+func fetchOIDCProviderConfig(client *http.Client, issuer *url.URL) (*oidcProviderConfig, error) {
+	configURL := strings.TrimSuffix(issuer.String(), "/") + "/.well-known/openid-configuration"
+	resp, err := client.Get(configURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", configURL, resp.Status)
+	}
+
+	var config oidcProviderConfig
+	if err := json.NewDecoder(resp.Body).Decode(&config); err != nil {
+		return nil, fmt.Errorf("invalid response from %s: %w", configURL, err)
+	}
+	if config.DeviceAuthorizationEndpoint == "" {
+		return nil, errors.New("the issuer does not support the device authorization grant")
+	}
+	if config.TokenEndpoint == "" {
+		return nil, errors.New("the issuer does not specify a token endpoint")
+	}
+	return &config, nil
+}
+
+func requestDeviceAuthorization(client *http.Client, endpoint string, clientConfig *disco.OAuthClient) (*deviceAuthorization, error) {
+	form := url.Values{"client_id": {clientConfig.ID}}
+	if len(clientConfig.Scopes) > 0 {
+		form.Set("scope", strings.Join(clientConfig.Scopes, " "))
+	}
+	resp, err := client.PostForm(endpoint, form)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", endpoint, resp.Status)
+	}
+
+	var authz deviceAuthorization
+	if err := json.Unmarshal(body, &authz); err != nil {
+		return nil, fmt.Errorf("invalid response from %s: %w", endpoint, err)
+	}
+	if authz.DeviceCode == "" || authz.UserCode == "" || authz.VerificationURI == "" {
+		return nil, fmt.Errorf("incomplete response from %s", endpoint)
+	}
+	return &authz, nil
+}
+
+func requestDeviceToken(client *http.Client, endpoint, clientID, deviceCode string) (*deviceTokenResponse, error) {
+	resp, err := client.PostForm(endpoint, url.Values{
+		"grant_type":  {oauthDeviceCodeGrantURN},
+		"device_code": {deviceCode},
+		"client_id":   {clientID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Pending and other errors are reported as 400 Bad Request responses
+	// with an error code in the body, so we check the body before the status.
+	var ret deviceTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ret); err != nil {
+		return nil, fmt.Errorf("%s returned %s", endpoint, resp.Status)
+	}
+	if ret.Error == "" && (resp.StatusCode != http.StatusOK || ret.AccessToken == "") {
+		return nil, fmt.Errorf("%s returned %s without an access token", endpoint, resp.Status)
+	}
+	return &ret, nil
+}
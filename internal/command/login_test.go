@@ -74,6 +74,15 @@ func TestLogin(t *testing.T) {
 					"scopes": []interface{}{"app1.full_access", "app2.read_only"},
 				},
 			})
+			svcs.ForceHostServices(svchost.Hostname("device.example.com"), map[string]interface{}{
+				"login.v1": map[string]interface{}{
+					// For this fake hostname we'll use the device authorization
+					// flow, with endpoints discovered from the OIDC issuer.
+					"client":      "anything-goes",
+					"grant_types": []interface{}{"device_code"},
+				},
+				"oidc.v1": s.URL,
+			})
 			svcs.ForceHostServices(svchost.Hostname("app.terraform.io"), map[string]interface{}{
 				// This represents Terraform Cloud, which does not yet support the
 				// login API, but does support its own bespoke tokens API.
@@ -165,6 +174,54 @@ func TestLogin(t *testing.T) {
 		}
 	}))
 
+	t.Run("device.example.com with device authorization flow", loginTestCase(func(t *testing.T, c *LoginCommand, ui *cli.MockUi) {
+		// Enter "yes" at the consent prompt.
+		defer testInputMap(t, map[string]string{
+			"approve": "yes",
+		})()
+		status := c.Run([]string{"device.example.com"})
+		if status != 0 {
+			t.Fatalf("unexpected error code %d\nstderr:\n%s", status, ui.ErrorWriter.String())
+		}
+
+		credsSrc := c.Services.CredentialsSource()
+		creds, err := credsSrc.ForHost(svchost.Hostname("device.example.com"))
+		if err != nil {
+			t.Errorf("failed to retrieve credentials: %s", err)
+		}
+		oauthCreds, ok := creds.(*cliconfig.OAuthCredentials)
+		if !ok {
+			t.Fatalf("wrong credentials type %T; want *cliconfig.OAuthCredentials", creds)
+		}
+		if got, want := oauthCreds.Token(), "good-token"; got != want {
+			t.Errorf("wrong token %q; want %q", got, want)
+		}
+		if got, want := oauthCreds.RefreshToken, "refresh-token"; got != want {
+			t.Errorf("wrong refresh token %q; want %q", got, want)
+		}
+		if got, want := oauthCreds.TokenURL, s.URL+"/token"; got != want {
+			t.Errorf("wrong token URL %q; want %q", got, want)
+		}
+
+		output := ui.OutputWriter.String()
+		for _, want := range []string{"ABCD-EFGH", s.URL + "/activate", "OpenTofu has obtained and saved an API token."} {
+			if !strings.Contains(output, want) {
+				t.Errorf("expected output to contain %q, but was:\n%s", want, output)
+			}
+		}
+	}))
+
+	t.Run("example.com with -device", loginTestCase(func(t *testing.T, c *LoginCommand, ui *cli.MockUi) {
+		status := c.Run([]string{"-device", "example.com"})
+		if status != 1 {
+			t.Fatalf("unexpected error code %d\nstderr:\n%s", status, ui.ErrorWriter.String())
+		}
+
+		if got, want := ui.ErrorWriter.String(), "Host does not support device login"; !strings.Contains(got, want) {
+			t.Fatalf("missing expected error message\nwant: %s\nfull output:\n%s", want, got)
+		}
+	}))
+
 	t.Run("example.com results in no scopes", loginTestCase(func(t *testing.T, c *LoginCommand, ui *cli.MockUi) {
 
 		host, _ := c.Services.Discover("example.com")
@@ -26,16 +26,21 @@ import (
 	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/backend/local"
 	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/command/cliconfig"
 	"github.com/opentofu/opentofu/internal/command/format"
+	"github.com/opentofu/opentofu/internal/command/hints"
 	"github.com/opentofu/opentofu/internal/command/views"
 	"github.com/opentofu/opentofu/internal/command/webbrowser"
 	"github.com/opentofu/opentofu/internal/command/workdir"
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configload"
+	"github.com/opentofu/opentofu/internal/getmodules"
 	"github.com/opentofu/opentofu/internal/getproviders"
 	legacy "github.com/opentofu/opentofu/internal/legacy/tofu"
+	"github.com/opentofu/opentofu/internal/providercache"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/provisioners"
+	"github.com/opentofu/opentofu/internal/simulate"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/terminal"
 	"github.com/opentofu/opentofu/internal/tfdiags"
@@ -73,9 +78,10 @@ type Meta struct {
 
 	View *views.View
 
-	Color            bool     // True if output should be colored
-	GlobalPluginDirs []string // Additional paths to search for plugins
-	Ui               cli.Ui   // Ui for output
+	Color            bool         // True if output should be colored
+	ColorTheme       *views.Theme // Colors used when output is colored; nil for the default
+	GlobalPluginDirs []string     // Additional paths to search for plugins
+	Ui               cli.Ui       // Ui for output
 
 	// Services provides access to remote endpoint information for
 	// 'tofu-native' services running at a specific user-facing hostname.
@@ -94,6 +100,13 @@ type Meta struct {
 	// the specific commands being run.
 	RunningInAutomation bool
 
+	// CIMode is set when OpenTofu is run with the -ci global option, or
+	// with TF_IN_AUTOMATION=strict. It implies RunningInAutomation, and also
+	// makes some commands stricter about needing a human: "tofu plan"
+	// returns detailed exit codes, and "tofu apply" refuses to prompt for
+	// approval.
+	CIMode bool
+
 	// CLIConfigDir is the directory from which CLI configuration files were
 	// read by the caller and the directory where any changes to CLI
 	// configuration files by commands should be made.
@@ -106,6 +119,11 @@ type Meta struct {
 	// into the given directory.
 	PluginCacheDir string
 
+	// ProviderFunctionCacheDir, if non-empty, enables caching the results
+	// of provider function calls into the given directory, so that they
+	// can be reused by later runs.
+	ProviderFunctionCacheDir string
+
 	// PluginCacheMayBreakDependencyLockFile is a temporary CLI configuration-based
 	// opt out for the behavior of only using the plugin cache dir if its
 	// contents match checksums recorded in the dependency lock file.
@@ -122,6 +140,21 @@ type Meta struct {
 	// longer any compelling reasons for folks to not lock their dependencies.
 	PluginCacheMayBreakDependencyLockFile bool
 
+	// PluginTransport and PluginSocketDir control how OpenTofu connects to
+	// the provider and provisioner plugins it starts. See
+	// cliconfig.Config for the meaning of each.
+	PluginTransport string
+	PluginSocketDir string
+
+	// ProvisionerPlugins are the provisioner plugins that the CLI
+	// configuration declares, which take precedence over the provisioners
+	// found in the plugin directories.
+	ProvisionerPlugins map[string]*cliconfig.ConfigProvisionerPlugin
+
+	// ProviderDownloadLimits, if not nil, limits the concurrency and
+	// bandwidth of provider package downloads.
+	ProviderDownloadLimits *providercache.DownloadLimits
+
 	// ProviderSource allows determining the available versions of a provider
 	// and determines where a distribution package for a particular
 	// provider version can be obtained.
@@ -143,6 +176,24 @@ type Meta struct {
 	// When this channel is closed, the command will be cancelled.
 	ShutdownCh <-chan struct{}
 
+	// ProviderTrustedKeys are the signing keys that the CLI configuration
+	// trusts to sign provider packages. ProviderSource already uses them,
+	// and commands that make their own provider sources should use them too.
+	ProviderTrustedKeys []*getproviders.TrustedSigningKeys
+
+	// ModuleSigningKeys are the signing keys that the CLI configuration
+	// trusts to sign module packages in OCI repositories.
+	ModuleSigningKeys []*getmodules.OCISigningKeys
+
+	// ModuleAliases are the directories that module alias source addresses
+	// refer to, keyed by alias name, from the project configuration.
+	ModuleAliases map[string]string
+
+	// PartialModulePackages makes module installation fetch only the
+	// directories of module packages that are used, from the project
+	// configuration.
+	PartialModulePackages bool
+
 	// ProviderDevOverrides are providers where we ignore the lock file, the
 	// configured version constraints, and the local cache directory and just
 	// always use exactly the path specified. This is intended to allow
@@ -151,6 +202,12 @@ type Meta struct {
 	// checksums they have.
 	ProviderDevOverrides map[addrs.Provider]getproviders.PackageLocalDir
 
+	// ProviderScopedDevOverrides are development overrides that apply only
+	// in particular working directories or workspaces. Where one of these
+	// applies, it takes precedence over ProviderDevOverrides. Use
+	// Meta.providerDevOverrides to find the overrides that are in effect.
+	ProviderScopedDevOverrides []*cliconfig.ScopedDevOverrides
+
 	// UnmanagedProviders are a set of providers that exist as processes
 	// predating OpenTofu, which OpenTofu should use but not worry about the
 	// lifecycle of.
@@ -160,6 +217,22 @@ type Meta struct {
 	// just trusting that someone else did it before running OpenTofu.
 	UnmanagedProviders map[addrs.Provider]*plugin.ReattachConfig
 
+	// InProcessProviders are providers that run inside the OpenTofu process,
+	// implementing providers.Interface directly rather than being served by
+	// a plugin process, so that tests can run plans and applies against
+	// their own provider implementations.
+	//
+	// Because this package is internal, only code within the OpenTofu
+	// module, such as this package's tests, can set it. Tools outside of the
+	// module run providers in their own process and pass them to OpenTofu
+	// with the TF_REATTACH_PROVIDERS environment variable instead, which
+	// populates UnmanagedProviders.
+	//
+	// As with UnmanagedProviders, OpenTofu doesn't install these providers
+	// or record them in the dependency lock file, and they take precedence
+	// over all other ways of obtaining the same providers.
+	InProcessProviders map[addrs.Provider]providers.Factory
+
 	// AllowExperimentalFeatures controls whether a command that embeds this
 	// Meta is permitted to make use of experimental OpenTofu features.
 	//
@@ -195,6 +268,11 @@ type Meta struct {
 	// Private: do not set these
 	//----------------------------------------------------------
 
+	// providerInstances is the pool of provider instances that the
+	// phases of the command share. Use the providerPool method to access
+	// it.
+	providerInstances *providerPool
+
 	// configLoader is a shared configuration loader that is used by
 	// LoadConfig and other commands that access configuration files.
 	// It is initialized on first use.
@@ -233,6 +311,12 @@ type Meta struct {
 	// parallelism is used to control the number of concurrent operations
 	// allowed when walking the graph
 	//
+	// concurrencyLimits further limits concurrent operations for particular
+	// providers or resource types
+	//
+	// simulation, if set, replaces the providers and provisioners with
+	// simulated ones, for "tofu apply -simulate".
+	//
 	// provider is to specify specific resource providers
 	//
 	// stateLock is set to false to disable state locking
@@ -248,22 +332,41 @@ type Meta struct {
 	// migrateState confirms the user wishes to migrate from the prior backend
 	// configuration to a new configuration.
 	//
+	// offline prevents init from using the network, so that providers and
+	// modules can come only from the local filesystem.
+	//
+	// vendorDir, if not empty, is the vendor directory created by "tofu
+	// vendor" that init installs providers and remote modules from.
+	//
 	// compactWarnings (-compact-warnings) selects a more compact presentation
 	// of warnings in the output when they are not accompanied by errors.
-	statePath        string
-	stateOutPath     string
-	backupPath       string
-	parallelism      int
-	stateLock        bool
-	stateLockTimeout time.Duration
-	forceInitCopy    bool
-	reconfigure      bool
-	migrateState     bool
-	compactWarnings  bool
+	//
+	// explain (-explain) includes detailed remediation steps in the hints
+	// shown for recognized error diagnostics.
+	statePath          string
+	stateOutPath       string
+	backupPath         string
+	parallelism        int
+	concurrencyLimits  tofu.ConcurrencyLimits
+	refreshParallelism tofu.RefreshParallelism
+	simulation         *simulate.Simulation
+	stateLock          bool
+	stateLockTimeout   time.Duration
+	forceInitCopy      bool
+	reconfigure        bool
+	migrateState       bool
+	offline            bool
+	vendorDir          string
+	compactWarnings    bool
+	explain            bool
 
 	// Used with commands which write state to allow users to write remote
 	// state even if the remote and local OpenTofu versions don't match.
 	ignoreRemoteVersion bool
+
+	// moduleSourceBackend is the initialized state backend, if init has
+	// configured one whose credentials module sources can ask to use.
+	moduleSourceBackend backend.ModuleSourceStorage
 }
 
 type testingOverrides struct {
@@ -292,14 +395,8 @@ func (m *Meta) StateOutPath() string {
 
 // Colorize returns the colorization structure for a command.
 func (m *Meta) Colorize() *colorstring.Colorize {
-	colors := make(map[string]string)
-	for k, v := range colorstring.DefaultColors {
-		colors[k] = v
-	}
-	colors["purple"] = "38;5;57"
-
 	return &colorstring.Colorize{
-		Colors:  colors,
+		Colors:  m.ColorTheme.Colors(),
 		Disable: !m.color,
 		Reset:   true,
 	}
@@ -330,7 +427,7 @@ const (
 	// InputModeEnvVar is the environment variable that, if set to "false" or
 	// "0", causes tofu commands to behave as if the `-input=false` flag was
 	// specified.
-	InputModeEnvVar = "TF_INPUT"
+	InputModeEnvVar = arguments.InputModeEnvVar
 )
 
 // InputMode returns the type of input we should ask for in the form of
@@ -528,6 +625,8 @@ func (m *Meta) contextOpts() (*tofu.ContextOpts, error) {
 
 	opts.UIInput = m.UIInput()
 	opts.Parallelism = m.parallelism
+	opts.ConcurrencyLimits = m.concurrencyLimits
+	opts.RefreshParallelism = m.refreshParallelism
 
 	// If testingOverrides are set, we'll skip the plugin discovery process
 	// and just work with what we've been given, thus allowing the tests
@@ -541,6 +640,19 @@ func (m *Meta) contextOpts() (*tofu.ContextOpts, error) {
 		opts.Providers = providerFactories
 		opts.Provisioners = m.provisionerFactories()
 	}
+	opts.ProviderCapabilities = m.recordedProviderCapabilities()
+	if cache := m.providerFunctionCache(); cache != nil {
+		opts.ProviderFunctionCache = cache
+	}
+	if m.simulation != nil {
+		opts.Providers = m.simulation.Providers(opts.Providers)
+		opts.Provisioners = m.simulation.Provisioners(opts.Provisioners)
+	} else if m.testingOverrides == nil {
+		// The provider pool keeps the instances for each provider
+		// configuration apart, so it needs to know which configuration
+		// each instance is for.
+		opts.ProviderConfigFactories = m.providerPool().configFactories()
+	}
 
 	opts.Meta = &tofu.ContextMeta{
 		Env:                workspace,
@@ -578,7 +690,7 @@ func (m *Meta) ignoreRemoteVersionFlagSet(n string) *flag.FlagSet {
 func (m *Meta) extendedFlagSet(n string) *flag.FlagSet {
 	f := m.defaultFlagSet(n)
 
-	f.BoolVar(&m.input, "input", true, "input")
+	f.BoolVar(&m.input, "input", arguments.InputEnabledDefault(), "input")
 	f.Var((*FlagStringSlice)(&m.targetFlags), "target", "resource to target")
 	f.BoolVar(&m.compactWarnings, "compact-warnings", false, "use compact warnings")
 
@@ -597,7 +709,7 @@ func (m *Meta) extendedFlagSet(n string) *flag.FlagSet {
 	return f
 }
 
-// process will process any -no-color entries out of the arguments. This
+// process will process any -no-color and -explain entries out of the arguments. This
 // will potentially modify the args in-place. It will return the resulting
 // slice, and update the Meta and Ui.
 func (m *Meta) process(args []string) []string {
@@ -611,10 +723,13 @@ func (m *Meta) process(args []string) []string {
 	m.color = m.Color
 	i := 0 // output index
 	for _, v := range args {
-		if v == "-no-color" {
+		switch v {
+		case "-no-color":
 			m.color = false
 			m.Color = false
-		} else {
+		case "-explain":
+			m.explain = true
+		default:
 			// copy and increment index
 			args[i] = v
 			i++
@@ -639,6 +754,7 @@ func (m *Meta) process(args []string) []string {
 		m.View.Configure(&arguments.View{
 			CompactWarnings: m.compactWarnings,
 			NoColor:         !m.Color,
+			Explain:         m.explain,
 		})
 	}
 
@@ -694,6 +810,7 @@ func (m *Meta) showDiagnostics(vals ...interface{}) {
 	outputWidth := m.ErrorColumns()
 
 	diags = diags.ConsolidateWarnings(1)
+	diags = hints.Annotate(diags, m.explain)
 
 	// Since warning messages are generally competing
 	if m.compactWarnings {
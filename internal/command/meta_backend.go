@@ -13,6 +13,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -437,7 +438,7 @@ func (m *Meta) Operation(b backend.Backend, vt arguments.ViewType) *backend.Oper
 		log.Printf("[WARN] Failed to load dependency locks while preparing backend operation (ignored): %s", diags.Err().Error())
 	}
 
-	return &backend.Operation{
+	op := &backend.Operation{
 		PlanOutBackend:  planOutBackend,
 		Targets:         m.targets,
 		UIIn:            m.UIInput(),
@@ -446,8 +447,21 @@ func (m *Meta) Operation(b backend.Backend, vt arguments.ViewType) *backend.Oper
 		StateLocker:     stateLocker,
 		DependencyLocks: depLocks,
 	}
+	if enableValidationCache {
+		op.ValidationCacheDir = filepath.Join(m.DataDir(), validationCacheDirName)
+	}
+	return op
 }
 
+// validationCacheDirName is the name of the directory in the data directory
+// where backends that run operations locally record the validations that
+// passed, so that later operations can skip them.
+const validationCacheDirName = "validation-cache"
+
+// The TF_VALIDATION_CACHE environment variable opts in to recording the
+// validations that passed in the data directory.
+var enableValidationCache = os.Getenv("TF_VALIDATION_CACHE") != ""
+
 // backendConfig returns the local configuration for the backend
 func (m *Meta) backendConfig(opts *BackendOpts) (*configs.Backend, int, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
@@ -202,6 +202,15 @@ func (m *Meta) installModules(ctx context.Context, rootDir, testsDir string, upg
 	}
 
 	inst := initwd.NewModuleInstaller(m.modulesDir(), loader, m.registryClient())
+	// Vendored modules are restored before installation, so the
+	// installer must not fetch anything that isn't vendored.
+	inst.SetOffline(m.offline || m.vendorDir != "")
+	inst.SetOCISigningKeys(m.ModuleSigningKeys)
+	inst.SetModuleAliases(m.ModuleAliases)
+	inst.SetPartialPackages(m.PartialModulePackages)
+	if m.moduleSourceBackend != nil {
+		inst.SetBackendStorage(m.moduleSourceBackend.ModuleSourceStorage())
+	}
 
 	_, moreDiags := inst.InstallModules(ctx, rootDir, testsDir, upgrade, installErrsOnly, hooks)
 	diags = diags.Append(moreDiags)
@@ -237,7 +246,7 @@ func (m *Meta) initDirFromModule(ctx context.Context, targetDir string, addr str
 	}
 
 	targetDir = m.normalizePath(targetDir)
-	moreDiags := initwd.DirFromModule(ctx, loader, targetDir, m.modulesDir(), addr, m.registryClient(), hooks)
+	moreDiags := initwd.DirFromModule(ctx, loader, targetDir, m.modulesDir(), addr, m.registryClient(), m.ModuleSigningKeys, hooks)
 	diags = diags.Append(moreDiags)
 	if ctx.Err() == context.Canceled {
 		m.showDiagnostics(diags)
@@ -68,7 +68,8 @@ func (m *Meta) replaceLockedDependencies(new *depsfile.Locks) tfdiags.Diagnostic
 
 // annotateDependencyLocksWithOverrides modifies the given Locks object in-place
 // to track as overridden any provider address that's subject to testing
-// overrides, development overrides, or "unmanaged provider" status.
+// overrides, development overrides, "unmanaged provider" status, or
+// in-process provider status.
 //
 // This is just an implementation detail of the lockedDependencies method,
 // not intended for use anywhere else.
@@ -77,7 +78,7 @@ func (m *Meta) annotateDependencyLocksWithOverrides(ret *depsfile.Locks) *depsfi
 		return ret
 	}
 
-	for addr := range m.ProviderDevOverrides {
+	for addr := range m.providerDevOverrides() {
 		log.Printf("[DEBUG] Provider %s is overridden by dev_overrides", addr)
 		ret.SetProviderOverridden(addr)
 	}
@@ -85,6 +86,10 @@ func (m *Meta) annotateDependencyLocksWithOverrides(ret *depsfile.Locks) *depsfi
 		log.Printf("[DEBUG] Provider %s is overridden as an \"unmanaged provider\"", addr)
 		ret.SetProviderOverridden(addr)
 	}
+	for addr := range m.InProcessProviders {
+		log.Printf("[DEBUG] Provider %s is overridden as an in-process provider", addr)
+		ret.SetProviderOverridden(addr)
+	}
 	if m.testingOverrides != nil {
 		for addr := range m.testingOverrides.Providers {
 			log.Printf("[DEBUG] Provider %s is overridden in Meta.testingOverrides", addr)
@@ -4,6 +4,8 @@
 package command
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"strconv"
 
@@ -46,5 +48,26 @@ func (m *Meta) PlanFile(path string) (*planfile.WrappedPlanFile, error) {
 		return nil, nil
 	}
 
-	return planfile.OpenWrapped(path)
+	return m.openPlanFile(path)
+}
+
+// openPlanFile opens the plan file at the given path using the plan
+// encryption settings from the environment, if any.
+func (m *Meta) openPlanFile(path string) (*planfile.WrappedPlanFile, error) {
+	pf, err := planfile.OpenWrapped(path, m.PlanFileEncryption())
+	if errors.Is(err, planfile.ErrMissingEncryptionKey) {
+		return nil, fmt.Errorf("%w; set the %s environment variable to the passphrase the plan was encrypted with", err, PlanEncryptionKeyEnvVar)
+	}
+	return pf, err
+}
+
+// PlanEncryptionKeyEnvVar is the name of the environment variable which
+// provides a passphrase for encrypting saved plan files, and for decrypting
+// them again when they are applied or shown.
+const PlanEncryptionKeyEnvVar = "TF_PLAN_ENCRYPTION_KEY"
+
+// PlanFileEncryption returns the settings for encrypting and decrypting
+// saved plan files, or nil if plan files should not be encrypted.
+func (m *Meta) PlanFileEncryption() *planfile.Encryption {
+	return planfile.NewEncryption(os.Getenv(PlanEncryptionKeyEnvVar))
 }
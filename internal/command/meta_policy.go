@@ -0,0 +1,48 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"github.com/opentofu/opentofu/internal/policy"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// operationPolicies loads the policies to evaluate for a plan or apply
+// operation from the given paths, selected using the -policy option.
+//
+// Policies are never loaded implicitly, so that a policy file that happens
+// to be in the working directory can't change what a plan may do without
+// the operator asking for it.
+//
+// The local backend evaluates the policies, including when another backend
+// runs the operation locally on its behalf. Backends which run operations
+// remotely reject operations with policies, rather than ignoring them.
+func (m *Meta) operationPolicies(paths []string) ([]*policy.Policy, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	if len(paths) == 0 {
+		return nil, diags
+	}
+
+	loader := policy.NewLoader()
+	var policies []*policy.Policy
+	for _, path := range paths {
+		more, hclDiags := loader.LoadPath(m.normalizePath(path))
+		diags = diags.Append(hclDiags)
+		policies = append(policies, more...)
+	}
+
+	// Register the policy sources so that diagnostics about policies,
+	// including violations, can include source snippets.
+	for filename, src := range loader.Sources() {
+		m.registerSynthConfigSource(filename, src)
+	}
+
+	if diags.HasErrors() || len(policies) == 0 {
+		return nil, diags
+	}
+
+	policies, hclDiags := policy.Dedupe(policies)
+	diags = diags.Append(hclDiags)
+	return policies, diags
+}
@@ -9,15 +9,17 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"os/exec"
+	"sort"
 	"strings"
 
 	plugin "github.com/hashicorp/go-plugin"
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	terraformProvider "github.com/opentofu/opentofu/internal/builtin/providers/tf"
+	"github.com/opentofu/opentofu/internal/command/cliconfig"
 	"github.com/opentofu/opentofu/internal/getproviders"
 	"github.com/opentofu/opentofu/internal/logging"
+	"github.com/opentofu/opentofu/internal/ociclient"
 	tfplugin "github.com/opentofu/opentofu/internal/plugin"
 	tfplugin6 "github.com/opentofu/opentofu/internal/plugin6"
 	"github.com/opentofu/opentofu/internal/providercache"
@@ -72,11 +74,17 @@ func (m *Meta) providerInstallerCustomSource(source getproviders.Source) *provid
 		builtinProviderTypes = append(builtinProviderTypes, ty)
 	}
 	inst.SetBuiltInProviderTypes(builtinProviderTypes)
-	unmanagedProviderTypes := make(map[addrs.Provider]struct{}, len(m.UnmanagedProviders))
+	unmanagedProviderTypes := make(map[addrs.Provider]struct{}, len(m.UnmanagedProviders)+len(m.InProcessProviders))
 	for ty := range m.UnmanagedProviders {
 		unmanagedProviderTypes[ty] = struct{}{}
 	}
+	// In-process providers are like unmanaged providers as far as the
+	// installer is concerned: there's nothing for it to install.
+	for ty := range m.InProcessProviders {
+		unmanagedProviderTypes[ty] = struct{}{}
+	}
 	inst.SetUnmanagedProviderTypes(unmanagedProviderTypes)
+	inst.SetDownloadLimits(m.ProviderDownloadLimits)
 	return inst
 }
 
@@ -99,6 +107,35 @@ func (m *Meta) providerCustomLocalDirectorySource(dirs []string) getproviders.So
 	return ret
 }
 
+// providerOfflineInstallSource produces a provider source that consults only
+// the filesystem mirrors from the usual installation sources, along with the
+// global plugin cache directory if one is configured, so that provider
+// installation never needs network access.
+//
+// This is used to implement the -offline option for "tofu init".
+func (m *Meta) providerOfflineInstallSource() getproviders.Source {
+	var ret getproviders.MultiSource
+	switch source := m.providerInstallSource().(type) {
+	case getproviders.MultiSource:
+		for _, selector := range source {
+			if _, ok := selector.Source.(*getproviders.FilesystemMirrorSource); ok {
+				ret = append(ret, selector)
+			}
+		}
+	case *getproviders.FilesystemMirrorSource:
+		ret = append(ret, getproviders.MultiSourceSelector{Source: source})
+	}
+
+	// The global cache directory has the same layout as a filesystem
+	// mirror, so previously-cached packages are available too.
+	if cacheDir := m.providerGlobalCacheDir(); cacheDir != nil {
+		ret = append(ret, getproviders.MultiSourceSelector{
+			Source: getproviders.NewFilesystemMirrorSource(cacheDir.BasePath()),
+		})
+	}
+	return ret
+}
+
 // providerLocalCacheDir returns an object representing the
 // configuration-specific local cache directory. This is the
 // only location consulted for provider plugin packages for OpenTofu
@@ -124,6 +161,9 @@ func (m *Meta) providerLocalCacheDir() *providercache.Dir {
 // configured and new packages should be downloaded directly into individual
 // configuration-specific cache directories.
 //
+// The global cache directory is often shared by many working directories,
+// so it's safe for concurrent "tofu init" runs to install into it.
+//
 // Only one object returned from this method should be live at any time,
 // because objects inside contain caches that must be maintained properly.
 func (m *Meta) providerGlobalCacheDir() *providercache.Dir {
@@ -131,7 +171,7 @@ func (m *Meta) providerGlobalCacheDir() *providercache.Dir {
 	if dir == "" {
 		return nil // cache disabled
 	}
-	return providercache.NewDir(dir)
+	return providercache.NewSharedDir(dir)
 }
 
 // providerInstallSource returns an object that knows how to consult one or
@@ -158,6 +198,98 @@ func (m *Meta) providerInstallSource() getproviders.Source {
 	return m.ProviderSource
 }
 
+// providerSourceWithOCI returns a source which installs the given providers
+// from the repositories in OCI registries that their addresses refer to, as
+// required by the oci:// source addresses that the configuration gives for
+// them, and all other providers from the given source.
+func (m *Meta) providerSourceWithOCI(source getproviders.Source, ociProviders []addrs.Provider) getproviders.Source {
+	if len(ociProviders) == 0 {
+		return source
+	}
+	patterns := getproviders.MultiSourceMatchingPatterns(ociProviders)
+	return getproviders.MultiSource{
+		{
+			Source: getproviders.NewTrustedKeysSource(
+				getproviders.NewMemoizeSource(
+					getproviders.NewOCIRegistrySource("${hostname}/${namespace}/${type}", ociclient.NewDockerCredentialsSource()),
+				),
+				m.ProviderTrustedKeys,
+			),
+			Include: patterns,
+		},
+		{
+			Source:  source,
+			Exclude: patterns,
+		},
+	}
+}
+
+// providerDevOverride is a provider development override that is in effect
+// for the current command.
+type providerDevOverride struct {
+	Provider addrs.Provider
+	Dir      getproviders.PackageLocalDir
+
+	// Scope is the scoped dev_overrides block that the override belongs to,
+	// or nil if it applies in all working directories and workspaces.
+	Scope *cliconfig.ScopedDevOverrides
+}
+
+// activeProviderDevOverrides returns the provider development overrides
+// that are in effect in the current working directory and workspace,
+// sorted by provider address.
+//
+// Overrides in a scoped dev_overrides block that applies take precedence
+// over overrides that aren't scoped.
+func (m *Meta) activeProviderDevOverrides() []providerDevOverride {
+	active := make(map[addrs.Provider]providerDevOverride, len(m.ProviderDevOverrides))
+	for addr, dir := range m.ProviderDevOverrides {
+		active[addr] = providerDevOverride{Provider: addr, Dir: dir}
+	}
+	if len(m.ProviderScopedDevOverrides) > 0 {
+		wd, err := os.Getwd()
+		if err != nil {
+			log.Printf("[ERROR] Can't determine the working directory for scoped provider development overrides: %s", err)
+		}
+		workspace, err := m.Workspace()
+		if err != nil {
+			log.Printf("[ERROR] Can't determine the workspace for scoped provider development overrides: %s", err)
+		}
+		for _, scope := range m.ProviderScopedDevOverrides {
+			if !scope.Applies(wd, workspace) {
+				continue
+			}
+			for addr, dir := range scope.Overrides {
+				active[addr] = providerDevOverride{Provider: addr, Dir: dir, Scope: scope}
+			}
+		}
+	}
+
+	ret := make([]providerDevOverride, 0, len(active))
+	for _, override := range active {
+		ret = append(ret, override)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Provider.LessThan(ret[j].Provider)
+	})
+	return ret
+}
+
+// providerDevOverrides returns the provider development overrides that are
+// in effect in the current working directory and workspace, as a map from
+// provider address to the local directory containing the provider.
+func (m *Meta) providerDevOverrides() map[addrs.Provider]getproviders.PackageLocalDir {
+	overrides := m.activeProviderDevOverrides()
+	if len(overrides) == 0 {
+		return nil
+	}
+	ret := make(map[addrs.Provider]getproviders.PackageLocalDir, len(overrides))
+	for _, override := range overrides {
+		ret[override.Provider] = override.Dir
+	}
+	return ret
+}
+
 // providerDevOverrideInitWarnings returns a diagnostics that contains at
 // least one warning if and only if there is at least one provider development
 // override in effect. If not, the result is always empty. The result never
@@ -167,13 +299,14 @@ func (m *Meta) providerInstallSource() getproviders.Source {
 // may differ from what's expected due to the development overrides. For
 // other commands, providerDevOverrideRuntimeWarnings should be used.
 func (m *Meta) providerDevOverrideInitWarnings() tfdiags.Diagnostics {
-	if len(m.ProviderDevOverrides) == 0 {
+	overrides := m.activeProviderDevOverrides()
+	if len(overrides) == 0 {
 		return nil
 	}
 	var detailMsg strings.Builder
 	detailMsg.WriteString("The following provider development overrides are set in the CLI configuration:\n")
-	for addr, path := range m.ProviderDevOverrides {
-		detailMsg.WriteString(fmt.Sprintf(" - %s in %s\n", addr.ForDisplay(), path))
+	for _, override := range overrides {
+		detailMsg.WriteString(fmt.Sprintf(" - %s in %s\n", override.Provider.ForDisplay(), override.Dir))
 	}
 	detailMsg.WriteString("\nSkip tofu init when using provider development overrides. It is not necessary and may error unexpectedly.")
 	return tfdiags.Diagnostics{
@@ -199,13 +332,14 @@ func (m *Meta) providerDevOverrideInitWarnings() tfdiags.Diagnostics {
 // See providerDevOverrideInitWarnings for warnings specific to the init
 // command.
 func (m *Meta) providerDevOverrideRuntimeWarnings() tfdiags.Diagnostics {
-	if len(m.ProviderDevOverrides) == 0 {
+	overrides := m.activeProviderDevOverrides()
+	if len(overrides) == 0 {
 		return nil
 	}
 	var detailMsg strings.Builder
 	detailMsg.WriteString("The following provider development overrides are set in the CLI configuration:\n")
-	for addr, path := range m.ProviderDevOverrides {
-		detailMsg.WriteString(fmt.Sprintf(" - %s in %s\n", addr.ForDisplay(), path))
+	for _, override := range overrides {
+		detailMsg.WriteString(fmt.Sprintf(" - %s in %s\n", override.Provider.ForDisplay(), override.Dir))
 	}
 	detailMsg.WriteString("\nThe behavior may therefore not match any released version of the provider and applying changes may cause the state to become incompatible with published releases.")
 	return tfdiags.Diagnostics{
@@ -268,8 +402,11 @@ func (m *Meta) providerFactories() (map[addrs.Provider]providers.Factory, error)
 	// Unmanaged providers take precedence over overridden providers because
 	// overrides are typically a "session-level" setting while unmanaged
 	// providers are typically scoped to a single unattended command.
-	devOverrideProviders := m.ProviderDevOverrides
+	// In-process providers, which a test harness or an embedding tool
+	// registers for a single command, take precedence over both.
+	devOverrideProviders := m.providerDevOverrides()
 	unmanagedProviders := m.UnmanagedProviders
+	inProcessProviders := m.InProcessProviders
 
 	factories := make(map[addrs.Provider]providers.Factory, len(providerLocks)+len(internalFactories)+len(unmanagedProviders))
 	for name, factory := range internalFactories {
@@ -319,14 +456,25 @@ func (m *Meta) providerFactories() (map[addrs.Provider]providers.Factory, error)
 				continue
 			}
 		}
-		factories[provider] = providerFactory(cached)
+		factory := crashRecoveringProviderFactory(cached, m.providerCrashBundleDir(), providerFactory(cached, m.pluginTransport()))
+		factories[provider] = m.providerPool().factory(provider, factory)
 	}
 	for provider, localDir := range devOverrideProviders {
-		factories[provider] = devOverrideProviderFactory(provider, localDir)
+		meta := &providercache.CachedProvider{
+			Provider:   provider,
+			Version:    getproviders.UnspecifiedVersion,
+			PackageDir: string(localDir),
+		}
+		factory := crashRecoveringProviderFactory(meta, m.providerCrashBundleDir(), devOverrideProviderFactory(provider, localDir, m.pluginTransport()))
+		factories[provider] = m.providerPool().factory(provider, factory)
 	}
 	for provider, reattach := range unmanagedProviders {
 		factories[provider] = unmanagedProviderFactory(provider, reattach)
 	}
+	for provider, factory := range inProcessProviders {
+		log.Printf("[DEBUG] Provider %s runs in-process", provider)
+		factories[provider] = factory
+	}
 
 	var err error
 	if len(errs) > 0 {
@@ -345,8 +493,9 @@ func (m *Meta) internalProviders() map[string]providers.Factory {
 
 // providerFactory produces a provider factory that runs up the executable
 // file in the given cache package and uses go-plugin to implement
-// providers.Interface against it.
-func providerFactory(meta *providercache.CachedProvider) providers.Factory {
+// providers.Interface against it, connecting to it using the given
+// transport.
+func providerFactory(meta *providercache.CachedProvider, transport pluginTransport) providers.Factory {
 	return func() (providers.Interface, error) {
 		execFile, err := meta.ExecutableFile()
 		if err != nil {
@@ -358,9 +507,10 @@ func providerFactory(meta *providercache.CachedProvider) providers.Factory {
 			Logger:           logging.NewProviderLogger(""),
 			AllowedProtocols: []plugin.Protocol{plugin.ProtocolGRPC},
 			Managed:          true,
-			Cmd:              exec.Command(execFile),
+			Cmd:              transport.command(execFile),
 			AutoMTLS:         enableProviderAutoMTLS,
 			VersionedPlugins: tfplugin.VersionedPlugins,
+			GRPCDialOptions:  tfplugin.GRPCDialOptions,
 			SyncStdout:       logging.PluginOutputMonitor(fmt.Sprintf("%s:stdout", meta.Provider)),
 			SyncStderr:       logging.PluginOutputMonitor(fmt.Sprintf("%s:stderr", meta.Provider)),
 		}
@@ -370,6 +520,9 @@ func providerFactory(meta *providercache.CachedProvider) providers.Factory {
 		if err != nil {
 			return nil, err
 		}
+		if err := transport.check(client, meta.Provider.String()); err != nil {
+			return nil, err
+		}
 
 		raw, err := rpcClient.Dispense(tfplugin.ProviderPluginName)
 		if err != nil {
@@ -395,7 +548,7 @@ func providerFactory(meta *providercache.CachedProvider) providers.Factory {
 	}
 }
 
-func devOverrideProviderFactory(provider addrs.Provider, localDir getproviders.PackageLocalDir) providers.Factory {
+func devOverrideProviderFactory(provider addrs.Provider, localDir getproviders.PackageLocalDir, transport pluginTransport) providers.Factory {
 	// A dev override is essentially a synthetic cache entry for our purposes
 	// here, so that's how we'll construct it. The providerFactory function
 	// doesn't actually care about the version, so we can leave it
@@ -405,7 +558,7 @@ func devOverrideProviderFactory(provider addrs.Provider, localDir getproviders.P
 		Provider:   provider,
 		Version:    getproviders.UnspecifiedVersion,
 		PackageDir: string(localDir),
-	})
+	}, transport)
 }
 
 // unmanagedProviderFactory produces a provider factory that uses the passed
@@ -419,6 +572,7 @@ func unmanagedProviderFactory(provider addrs.Provider, reattach *plugin.Reattach
 			AllowedProtocols: []plugin.Protocol{plugin.ProtocolGRPC},
 			Managed:          false,
 			Reattach:         reattach,
+			GRPCDialOptions:  tfplugin.GRPCDialOptions,
 			SyncStdout:       logging.PluginOutputMonitor(fmt.Sprintf("%s:stdout", provider)),
 			SyncStderr:       logging.PluginOutputMonitor(fmt.Sprintf("%s:stderr", provider)),
 		}
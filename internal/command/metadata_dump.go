@@ -0,0 +1,147 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/zclconf/go-cty/cty/function"
+
+	"github.com/opentofu/opentofu/internal/backend"
+	backendInit "github.com/opentofu/opentofu/internal/backend/init"
+	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/command/jsonmetadata"
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/lang"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// MetadataDumpCommand is a Command implementation that prints out the full
+// language schema available to the current configuration.
+type MetadataDumpCommand struct {
+	Meta
+}
+
+func (c *MetadataDumpCommand) Help() string {
+	return metadataDumpCommandHelp
+}
+
+func (c *MetadataDumpCommand) Synopsis() string {
+	return "Show the full language schema for the configuration"
+}
+
+func (c *MetadataDumpCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("metadata dump")
+	var jsonOutput bool
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "produce JSON output")
+
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+
+	if !jsonOutput {
+		c.Ui.Error(
+			"The `tofu metadata dump` command requires the `-json` flag.\n")
+		cmdFlags.Usage()
+		return 1
+	}
+
+	// Check for user-supplied plugin path
+	var err error
+	if c.pluginPath, err = c.loadPluginPath(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error loading plugin path: %s", err))
+		return 1
+	}
+
+	var diags tfdiags.Diagnostics
+
+	// Load the backend
+	b, backendDiags := c.Backend(nil)
+	diags = diags.Append(backendDiags)
+	if backendDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// We require a local backend
+	local, ok := b.(backend.Local)
+	if !ok {
+		c.showDiagnostics(diags) // in case of any warnings in here
+		c.Ui.Error(ErrUnsupportedLocalOp)
+		return 1
+	}
+
+	// This is a read-only command
+	c.ignoreRemoteVersionConflict(b)
+
+	// we expect that the config dir is the cwd
+	cwd, err := os.Getwd()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error getting cwd: %s", err))
+		return 1
+	}
+
+	// Build the operation
+	opReq := c.Operation(b, arguments.ViewJSON)
+	opReq.ConfigDir = cwd
+	opReq.ConfigLoader, err = c.initConfigLoader()
+	opReq.AllowUnsetVariables = true
+	if err != nil {
+		diags = diags.Append(err)
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Get the context
+	lr, _, ctxDiags := local.LocalRun(opReq)
+	diags = diags.Append(ctxDiags)
+	if ctxDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	schemas, moreDiags := lr.Core.Schemas(lr.Config, lr.InputState)
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	backendSchemas := make(map[string]*configschema.Block)
+	for _, name := range backendInit.Names() {
+		f := backendInit.Backend(name)
+		backendSchemas[name] = f().ConfigSchema()
+	}
+
+	funcs := make(map[string]function.Function)
+	for k, v := range (&lang.Scope{}).Functions() {
+		if isIgnoredFunction(k) {
+			continue
+		}
+		funcs[k] = v
+	}
+
+	jsonMetadata, marshalDiags := jsonmetadata.Marshal(lr.Config, schemas, backendSchemas, funcs)
+	diags = diags.Append(marshalDiags)
+	if marshalDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+	c.Ui.Output(string(jsonMetadata))
+
+	return 0
+}
+
+const metadataDumpCommandHelp = `
+Usage: tofu [global options] metadata dump -json
+
+  Prints out a json representation of the full language schema available
+  to the current configuration: the schemas of the providers it uses, the
+  schemas of the available backends, the input variables and output values
+  of each of its modules, and the signatures of the available functions.
+`
@@ -0,0 +1,142 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mitchellh/cli"
+)
+
+func TestMetadataDump_error(t *testing.T) {
+	ui := new(cli.MockUi)
+	c := &MetadataDumpCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+
+	// This test will always error because it's missing the -json flag
+	if code := c.Run(nil); code != 1 {
+		t.Fatalf("expected error, got:\n%s", ui.OutputWriter.String())
+	}
+}
+
+func TestMetadataDump_output(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("metadata-dump"), td)
+	defer testChdir(t, td)()
+
+	providerSource, close := newMockProviderSource(t, map[string][]string{
+		"test": {"1.2.3"},
+	})
+	defer close()
+
+	p := providersSchemaFixtureProvider()
+	ui := new(cli.MockUi)
+	m := Meta{
+		testingOverrides: metaOverridesForProvider(p),
+		Ui:               ui,
+		ProviderSource:   providerSource,
+	}
+
+	ic := &InitCommand{
+		Meta: m,
+	}
+	if code := ic.Run([]string{}); code != 0 {
+		t.Fatalf("init failed\n%s", ui.ErrorWriter)
+	}
+
+	// flush the init output from the mock ui
+	ui.OutputWriter.Reset()
+
+	c := &MetadataDumpCommand{Meta: m}
+	if code := c.Run([]string{"-json"}); code != 0 {
+		t.Fatalf("wrong exit status %d; want 0\nstderr: %s", code, ui.ErrorWriter.String())
+	}
+
+	var got struct {
+		FormatVersion      string                     `json:"format_version"`
+		ProviderSchemas    map[string]interface{}     `json:"provider_schemas"`
+		BackendSchemas     map[string]json.RawMessage `json:"backend_schemas"`
+		RootModule         json.RawMessage            `json:"root_module"`
+		FunctionSignatures map[string]interface{}     `json:"function_signatures"`
+	}
+	if err := json.Unmarshal(ui.OutputWriter.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON output: %s\n%s", err, ui.OutputWriter.String())
+	}
+
+	if got.FormatVersion != "1.0" {
+		t.Errorf("wrong format version %q", got.FormatVersion)
+	}
+	if _, ok := got.ProviderSchemas["registry.opentofu.org/hashicorp/test"]; !ok {
+		t.Errorf("missing test provider schema; got %#v", got.ProviderSchemas)
+	}
+	for _, name := range []string{"local", "s3"} {
+		if _, ok := got.BackendSchemas[name]; !ok {
+			t.Errorf("missing %q backend schema", name)
+		}
+	}
+	if _, ok := got.BackendSchemas["cloud"]; ok {
+		t.Errorf("unexpected schema for the cloud backend")
+	}
+	for _, name := range []string{"abs", "try"} {
+		if _, ok := got.FunctionSignatures[name]; !ok {
+			t.Errorf("missing signature for function %q", name)
+		}
+	}
+	for _, name := range ignoredFunctions {
+		if _, ok := got.FunctionSignatures[name]; ok {
+			t.Errorf("unexpected signature for ignored function %q", name)
+		}
+	}
+
+	var gotModule, wantModule interface{}
+	if err := json.Unmarshal(got.RootModule, &gotModule); err != nil {
+		t.Fatal(err)
+	}
+	wantJSON := `{
+		"variables": {
+			"settings": {
+				"type": ["object", {"name": "string", "size": "number"}, ["size"]],
+				"required": true,
+				"description": "Settings for the test instance.",
+				"nullable": true
+			},
+			"region": {
+				"type": "dynamic",
+				"default": "us-east-1",
+				"nullable": true
+			}
+		},
+		"outputs": {
+			"id": {
+				"description": "The instance id.",
+				"sensitive": true
+			}
+		},
+		"module_calls": {
+			"child": {
+				"source": "./child",
+				"module": {
+					"variables": {
+						"enabled": {
+							"type": "bool",
+							"default": true,
+							"nullable": false
+						}
+					}
+				}
+			}
+		}
+	}`
+	if err := json.Unmarshal([]byte(wantJSON), &wantModule); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(wantModule, gotModule); diff != "" {
+		t.Errorf("wrong module contracts\n%s", diff)
+	}
+}
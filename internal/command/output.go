@@ -32,7 +32,7 @@ func (c *OutputCommand) Run(rawArgs []string) int {
 		return 1
 	}
 
-	view := views.NewOutput(args.ViewType, c.View)
+	view := views.NewOutput(args.ViewType, args.Prefix, c.View)
 
 	// Fetch data from state
 	outputs, diags := c.Outputs(args.StatePath)
@@ -117,6 +117,15 @@ Options:
                    converted to a string, will print the raw
                    string directly, rather than a human-oriented
                    representation of the value.
+
+  -format=FORMAT   Print the outputs in a format that other tools can
+                   load directly. FORMAT is one of "dotenv" for a
+                   dotenv file, "shell" for POSIX shell export
+                   commands, or "yaml". Cannot be combined with -json
+                   or -raw.
+
+  -prefix=PREFIX   With -format=dotenv or -format=shell, prepend
+                   PREFIX to each variable name.
 `
 	return strings.TrimSpace(helpText)
 }
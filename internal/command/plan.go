@@ -5,12 +5,17 @@ package command
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/opentofu/opentofu/internal/backend"
+	backendLocal "github.com/opentofu/opentofu/internal/backend/local"
 	"github.com/opentofu/opentofu/internal/command/arguments"
 	"github.com/opentofu/opentofu/internal/command/views"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states/statemgr"
 	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
 )
 
 // PlanCommand is a Command implementation that compares a Terraform
@@ -35,7 +40,7 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 
 	// Instantiate the view, even if there are flag errors, so that we render
 	// diagnostics according to the desired view
-	view := views.NewPlan(args.ViewType, c.View)
+	view := views.NewPlan(args.ViewType, args.Summary, c.View)
 
 	if diags.HasErrors() {
 		view.Diagnostics(diags)
@@ -56,6 +61,13 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 	// continue to mutate the Meta object state for now.
 	c.Meta.input = args.InputEnabled
 
+	// In CI mode the exit code reports whether there are changes, so that
+	// pipelines don't each need to remember -detailed-exitcode. Watch mode
+	// doesn't exit after each plan, so it's left as is.
+	if c.CIMode && !args.Watch {
+		args.DetailedExitCode = true
+	}
+
 	// FIXME: the -parallelism flag is used to control the concurrency of
 	// Terraform operations. At the moment, this value is used both to
 	// initialize the backend via the ContextOpts field inside CLIOpts, and to
@@ -63,9 +75,27 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 	// clear path to pass this value down, so we continue to mutate the Meta
 	// object state for now.
 	c.Meta.parallelism = args.Operation.Parallelism
+	c.Meta.concurrencyLimits = tofu.ConcurrencyLimits{
+		Providers:     args.Operation.ProviderConcurrency,
+		ResourceTypes: args.Operation.ResourceConcurrency,
+		ProviderRates: args.Operation.ProviderRateLimits,
+	}
+	c.Meta.refreshParallelism = tofu.RefreshParallelism{
+		Default:   args.Operation.RefreshParallelism,
+		Providers: args.Operation.ProviderRefreshParallelism,
+	}
 
 	diags = diags.Append(c.providerDevOverrideRuntimeWarnings())
 
+	if args.Watch {
+		return c.watch(args, view, diags)
+	}
+	return c.plan(args, view, diags)
+}
+
+// plan creates a single plan using the given arguments, and returns the
+// command's exit status.
+func (c *PlanCommand) plan(args *arguments.Plan, view views.Plan, diags tfdiags.Diagnostics) int {
 	// Prepare the backend with the backend-specific arguments
 	be, beDiags := c.PrepareBackend(args.State, args.ViewType)
 	diags = diags.Append(beDiags)
@@ -81,6 +111,40 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 		view.Diagnostics(diags)
 		return 1
 	}
+	opReq.GenerateImports = args.GenerateImports
+	opReq.BlastRadius = args.BlastRadius
+
+	if args.StateSource != nil {
+		stateMgr, stateDiags := c.stateSourceMgr(be, args.StateSource)
+		diags = diags.Append(stateDiags)
+		if diags.HasErrors() {
+			view.Diagnostics(diags)
+			return 1
+		}
+		opReq.StateSource = stateMgr
+	}
+
+	if args.RefinePath != "" {
+		pf, err := c.openPlanFile(args.RefinePath)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to load the plan to refine",
+				fmt.Sprintf("Failed to read plan from plan file: %s.", err),
+			))
+			view.Diagnostics(diags)
+			return 1
+		}
+		opReq.RefinePlanFile = pf
+	}
+
+	policies, policyDiags := c.operationPolicies(args.PolicyPaths)
+	diags = diags.Append(policyDiags)
+	if diags.HasErrors() {
+		view.Diagnostics(diags)
+		return 1
+	}
+	opReq.Policies = policies
 
 	// Collect variable value and add them to the operation request
 	diags = diags.Append(c.GatherVariables(opReq, args.Vars))
@@ -106,6 +170,21 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 	if op.Result != backend.OperationSuccess {
 		return op.Result.ExitStatus()
 	}
+	if len(args.FailOn) > 0 && !op.PlanEmpty {
+		match, known := planIncludesAnyAction(op, args.FailOn)
+		if !known {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Cannot check the plan for -fail-on",
+				"The backend did not report which kinds of change the plan includes, so OpenTofu cannot tell whether it includes any of the changes selected using the -fail-on option. Remove -fail-on and use -detailed-exitcode to detect any changes, or inspect the saved plan using \"tofu show -json\".",
+			))
+			view.Diagnostics(diags)
+			return 1
+		}
+		if match {
+			return arguments.ExitCodeFailOn
+		}
+	}
 	if args.DetailedExitCode && !op.PlanEmpty {
 		return 2
 	}
@@ -113,6 +192,131 @@ func (c *PlanCommand) Run(rawArgs []string) int {
 	return op.Result.ExitStatus()
 }
 
+// planIncludesAnyAction returns true if the plan produced by the given
+// operation includes at least one of the given change actions.
+//
+// The second result is false if the backend could not report which actions
+// the plan includes, in which case the first result is meaningless.
+func planIncludesAnyAction(op *backend.RunningOperation, actions []plans.Action) (match, known bool) {
+	if op.PlanActions == nil {
+		return false, false
+	}
+	for _, action := range actions {
+		if _, ok := op.PlanActions[action]; ok {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+// stateSourceMgr returns a state manager for the alternate state selected
+// using the -state-source option. The returned state manager is used only to
+// read the state, and so it is never locked.
+func (c *PlanCommand) stateSourceMgr(be backend.Enhanced, src *arguments.StateSource) (statemgr.Full, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	// Remote operations read the state on the remote system, so we can only
+	// substitute a different state when running locally.
+	if _, ok := be.(*backendLocal.Local); !ok {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"State source not supported",
+			"The -state-source option is supported only when OpenTofu runs operations locally.",
+		))
+		return nil, diags
+	}
+
+	switch src.Type {
+	case arguments.StateSourceWorkspace:
+		workspaces, err := be.Workspaces()
+		if err != nil {
+			diags = diags.Append(fmt.Errorf("Failed to list workspaces: %w", err))
+			return nil, diags
+		}
+		found := false
+		for _, name := range workspaces {
+			if name == src.Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Workspace does not exist",
+				fmt.Sprintf("The -state-source option refers to workspace %q, which does not exist in the configured backend.", src.Value),
+			))
+			return nil, diags
+		}
+		stateMgr, err := be.StateMgr(src.Value)
+		if err != nil {
+			diags = diags.Append(fmt.Errorf("Failed to load state for workspace %q: %w", src.Value, err))
+			return nil, diags
+		}
+		return stateMgr, diags
+
+	case arguments.StateSourceFile:
+		if _, err := os.Stat(src.Value); err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to read state file",
+				fmt.Sprintf("The -state-source option refers to state file %q, which cannot be read: %s.", src.Value, err),
+			))
+			return nil, diags
+		}
+		return statemgr.NewFilesystem(src.Value), diags
+
+	case arguments.StateSourceBackendConfig:
+		backendConfig, configDiags := c.loadBackendConfig(".")
+		diags = diags.Append(configDiags)
+		if configDiags.HasErrors() {
+			return nil, diags
+		}
+		if backendConfig == nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"No backend configuration",
+				"The backend-config state source requires a backend block in the root module, whose settings are combined with those in the given file.",
+			))
+			return nil, diags
+		}
+		override, fileDiags := c.loadHCLFile(src.Value)
+		diags = diags.Append(fileDiags)
+		if fileDiags.HasErrors() {
+			return nil, diags
+		}
+		config, _, configDiags := c.backendConfig(&BackendOpts{
+			Config:         backendConfig,
+			ConfigOverride: override,
+		})
+		diags = diags.Append(configDiags)
+		if configDiags.HasErrors() {
+			return nil, diags
+		}
+		b, _, initDiags := c.backendInitFromConfig(config)
+		diags = diags.Append(initDiags)
+		if initDiags.HasErrors() {
+			return nil, diags
+		}
+		workspace, err := c.Workspace()
+		if err != nil {
+			diags = diags.Append(fmt.Errorf("Failed to select workspace: %w", err))
+			return nil, diags
+		}
+		stateMgr, err := b.StateMgr(workspace)
+		if err != nil {
+			diags = diags.Append(fmt.Errorf("Failed to load state for workspace %q: %w", workspace, err))
+			return nil, diags
+		}
+		return stateMgr, diags
+
+	default:
+		// Should not get here, because the arguments package validates
+		// the state source type.
+		panic(fmt.Sprintf("unsupported state source type %q", src.Type))
+	}
+}
+
 func (c *PlanCommand) PrepareBackend(args *arguments.State, viewType arguments.ViewType) (backend.Enhanced, tfdiags.Diagnostics) {
 	// FIXME: we need to apply the state arguments to the meta object here
 	// because they are later used when initializing the backend. Carving a
@@ -155,9 +359,14 @@ func (c *PlanCommand) OperationRequest(
 	opReq.Hooks = view.Hooks()
 	opReq.PlanRefresh = args.Refresh
 	opReq.PlanOutPath = planOutPath
+	opReq.PlanOutEncryption = c.PlanFileEncryption()
 	opReq.GenerateConfigOut = generateConfigOut
 	opReq.Targets = args.Targets
+	opReq.Excludes = args.Excludes
+	opReq.DriftSelection = args.DriftSelection
 	opReq.ForceReplace = args.ForceReplace
+	opReq.AllowDeferral = args.AllowDeferral
+	opReq.Stage = args.Stage
 	opReq.Type = backend.OperationTypePlan
 	opReq.View = view.Operation()
 
@@ -210,6 +419,12 @@ Plan Customization Options:
   can also use these options when you run "tofu apply" without passing
   it a saved plan, in order to plan and apply in a single command.
 
+  -allow-deferral     Instead of returning an error, defer the changes for
+                      resources whose count or for_each arguments depend on
+                      values that won't be known until apply, along with
+                      everything that depends on them. Run another plan
+                      after applying to plan the deferred changes.
+
   -destroy            Select the "destroy" planning mode, which creates a plan
                       to destroy all objects currently managed by this
                       OpenTofu configuration instead of the usual behavior.
@@ -219,6 +434,18 @@ Plan Customization Options:
                       most recent OpenTofu apply but does not propose any
                       actions to undo any changes made outside of OpenTofu.
 
+  -accept-drift=address
+                      In refresh-only mode, record in the state only the
+                      changes made outside of OpenTofu to the given module,
+                      resource, resource instance, or attribute of a resource
+                      instance. You can use this option multiple times.
+
+  -reject-drift=address
+                      In refresh-only mode, don't record in the state the
+                      changes made outside of OpenTofu to the given address,
+                      leaving them for a later plan to correct. You can use
+                      this option multiple times.
+
   -refresh=false      Skip checking for external changes to remote objects
                       while creating the plan. This can potentially make
                       planning faster, but at the expense of possibly planning
@@ -230,6 +457,14 @@ Plan Customization Options:
                       OpenTofu will plan to replace it instead. You can use
                       this option multiple times to replace more than one object.
 
+  -exclude=resource   Limit the planning operation to everything except the
+                      given module, resource, or resource instance and all
+                      of the objects that depend on it. The address may
+                      use "*" as a wildcard. You can use this option
+                      multiple times to exclude more than one object. This
+                      cannot be used with -target, and is for exceptional
+                      use only.
+
   -target=resource    Limit the planning operation to only the given module,
                       resource, or resource instance and all of its
                       dependencies. You can use this option multiple times to
@@ -247,16 +482,32 @@ Plan Customization Options:
 
 Other Options:
 
+  -blast-radius              With -destroy, summarize the objects that the
+                             plan destroys by category and by module, list
+                             the ones likely to lose data, such as databases
+                             and storage buckets, and include the summary in
+                             the JSON plan given to policies and hooks.
+
   -compact-warnings          If OpenTofu produces any warnings that are not
                              accompanied by errors, shows them in a more compact
                              form that includes only the summary messages.
 
+  -explain                   Include detailed steps to resolve recognized
+                             errors, such as expired credentials, in the hints
+                             shown after them.
+
   -detailed-exitcode         Return detailed exit codes when the command exits.
                              This will change the meaning of exit codes to:
                              0 - Succeeded, diff is empty (no changes)
                              1 - Errored
                              2 - Succeeded, there is a diff
 
+  -fail-on=create,update,replace,destroy
+                             Exit with status 3 if the plan includes any
+                             change of the given classes, so that automation
+                             can tell destructive changes apart from others.
+                             Takes precedence over -detailed-exitcode.
+
   -generate-config-out=path  (Experimental) If import blocks are present in
                              configuration, instructs OpenTofu to generate HCL
                              for any imported resources not already present. The
@@ -264,6 +515,13 @@ Other Options:
                              which must not already exist. OpenTofu may still
                              attempt to write configuration if the plan errors.
 
+  -generate-imports          (Experimental) With -generate-config-out, also
+                             ask the providers to list the existing objects
+                             of the resource types in the configuration, and
+                             write draft import blocks and configuration for
+                             the ones OpenTofu doesn't manage yet. Only some
+                             providers can list objects.
+
   -input=true                Ask for input for variables if not directly set.
 
   -lock=false                Don't hold a state lock during the operation. This
@@ -280,6 +538,70 @@ Other Options:
   -parallelism=n             Limit the number of concurrent operations. Defaults
                              to 10.
 
+  -provider-concurrency=SOURCE=n
+                             Limit the number of concurrent operations for
+                             resources belonging to the given provider, such
+                             as "hashicorp/aws=4". This flag can be used
+                             multiple times.
+
+  -provider-rate-limit=SOURCE=n
+                             Limit the number of operations per second that
+                             begin for resources belonging to the given
+                             provider, such as "hashicorp/aws=10". This flag
+                             can be used multiple times.
+
+  -resource-concurrency=TYPE=n
+                             Limit the number of concurrent operations for
+                             resources of the given type, such as
+                             "aws_instance=2". This flag can be used multiple
+                             times.
+
+  -refresh-parallelism=[SOURCE=]n
+                             Limit the number of concurrent reads of existing
+                             objects while refreshing, for each provider
+                             configuration or only those of the given
+                             provider. Defaults to 10, separately from
+                             -parallelism. This flag can be used multiple
+                             times.
+
+  -refine=path               Refine the saved plan in the given plan file,
+                             reusing its changes for the resources that
+                             aren't affected by changes to the configuration
+                             or state since it was created. Only local
+                             operations in the normal planning mode support
+                             this.
+
+  -stage=module.NAME         Plan only the changes for the resources in the
+                             given module call, after checking that the rest
+                             of the configuration is up-to-date. This flag
+                             cannot be used with -target.
+
+  -summary=module            Instead of showing each individual resource
+                             change, summarize the number of changes of each
+                             kind in each module call.
+
+  -expand=module.name        With -summary=module, also show the individual
+                             changes for the given module call. This flag can
+                             be used multiple times.
+
+  -state-source=TYPE:VALUE   Create a speculative plan against an alternate
+                             prior state, without switching workspaces or
+                             locking any state. TYPE is "workspace" with a
+                             workspace name, "file" with the path of a state
+                             snapshot, or "backend-config" with the path of a
+                             partial backend configuration file. Cannot be
+                             used with -out.
+
+  -policy=path               Evaluate the policies in the given policy file,
+                             or in the .tfpolicy.hcl files in the given
+                             directory. This flag can be used multiple times.
+
+  -watch                     Keep running, and create a new speculative plan
+                             each time a configuration file or variable
+                             definitions file changes, until interrupted.
+                             Disables interactive input. Cannot be used with
+                             -out, -detailed-exitcode, or -fail-on.
+
   -state=statefile           A legacy option used for the local backend only.
                              See the local backend's documentation for more
                              information.
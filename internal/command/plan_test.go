@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -19,10 +20,12 @@ import (
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/backend"
 	backendinit "github.com/opentofu/opentofu/internal/backend/init"
 	"github.com/opentofu/opentofu/internal/checks"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/plans/planfile"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/tfdiags"
@@ -50,6 +53,35 @@ func TestPlan(t *testing.T) {
 		t.Fatalf("bad: %d\n\n%s", code, output.Stderr())
 	}
 }
+
+func TestPlan_inProcessProviders(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("plan"), td)
+	defer testChdir(t, td)()
+
+	// There's no dependency lock file or installed provider here, so the
+	// plan can only succeed by using the in-process provider.
+	p := planFixtureProvider()
+	view, done := testView(t)
+	c := &PlanCommand{
+		Meta: Meta{
+			InProcessProviders: map[addrs.Provider]providers.Factory{
+				addrs.NewDefaultProvider("test"): providers.FactoryFixed(p),
+			},
+			View: view,
+		},
+	}
+
+	code := c.Run(nil)
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, output.Stderr())
+	}
+	if !p.PlanResourceChangeCalled {
+		t.Fatal("in-process provider was not used")
+	}
+}
+
 func TestPlan_conditionalSensitive(t *testing.T) {
 	td := t.TempDir()
 	testCopyDir(t, testFixturePath("apply-plan-conditional-sensitive"), td)
@@ -108,6 +140,39 @@ func TestPlan_lockedState(t *testing.T) {
 	}
 }
 
+func TestPlan_lockedStateExplain(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("plan"), td)
+	defer testChdir(t, td)()
+
+	unlock, err := testLockState(t, testDataDir, filepath.Join(td, DefaultStateFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+
+	p := planFixtureProvider()
+	view, done := testView(t)
+	c := &PlanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-explain"})
+	if code == 0 {
+		t.Fatal("expected error", done(t).Stdout())
+	}
+
+	output := done(t).Stderr()
+	for _, want := range []string{"Hint: Another OpenTofu operation", "force-unlock"} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("output does not include %q:\n%s", want, output)
+		}
+	}
+}
+
 func TestPlan_plan(t *testing.T) {
 	testCwd(t)
 
@@ -183,6 +248,69 @@ func TestPlan_destroy(t *testing.T) {
 	}
 }
 
+func TestPlan_destroyBlastRadius(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("plan"), td)
+	defer testChdir(t, td)()
+
+	originalState := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(
+			addrs.Resource{
+				Mode: addrs.ManagedResourceMode,
+				Type: "test_instance",
+				Name: "foo",
+			}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"id":"bar"}`),
+				Status:    states.ObjectReady,
+			},
+			addrs.AbsProviderConfig{
+				Provider: addrs.NewDefaultProvider("test"),
+				Module:   addrs.RootModule,
+			},
+		)
+	})
+	statePath := testStateFile(t, originalState)
+
+	// Policies can gate on the blast radius in the JSON plan.
+	policySrc := `
+policy "limited_destroy" {
+  condition     = "plan.blast_radius.destroyed < 1"
+  error_message = "This plan destroys too many objects."
+}
+`
+	if err := os.WriteFile("main.tfpolicy.hcl", []byte(policySrc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := planFixtureProvider()
+	view, done := testView(t)
+	c := &PlanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-destroy", "-blast-radius", "-policy=main.tfpolicy.hcl", "-state", statePath})
+	output := done(t)
+	if code != 1 {
+		t.Fatalf("wrong exit code %d; want 1\n%s", code, output.All())
+	}
+	got := output.All()
+	for _, want := range []string{
+		"Blast radius:",
+		"1 to destroy, 0 may lose data.",
+		"By category: compute 1",
+		"(root module): 1 to destroy",
+		"This plan destroys too many objects.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output is missing %q\n%s", want, got)
+		}
+	}
+}
+
 func TestPlan_noState(t *testing.T) {
 	td := t.TempDir()
 	testCopyDir(t, testFixturePath("plan"), td)
@@ -258,6 +386,80 @@ func TestPlan_generatedConfigPath(t *testing.T) {
 	testFileEquals(t, genPath, filepath.Join(td, "generated.tf.expected"))
 }
 
+func TestPlan_generateImports(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("plan-import-config-gen"), td)
+	defer testChdir(t, td)()
+
+	// Only the types of the resources in the configuration are listed.
+	err := os.WriteFile(filepath.Join(td, "existing.tf"), []byte(`resource "test_instance" "existing" {}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	genPath := filepath.Join(td, "generated.tf")
+
+	p := planFixtureProvider()
+	view, done := testView(t)
+
+	c := &PlanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+
+	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
+		ImportedResources: []providers.ImportedResource{
+			{
+				TypeName: "test_instance",
+				State: cty.ObjectVal(map[string]cty.Value{
+					"id": cty.StringVal("bar"),
+				}),
+				Private: nil,
+			},
+		},
+	}
+	p.GetProviderSchemaResponse.ServerCapabilities.ListResources = true
+	p.ListResourcesResponse = &providers.ListResourcesResponse{
+		Resources: []providers.ListedResource{
+			{ID: "bar"},
+			{ID: "baz"},
+		},
+	}
+
+	args := []string{
+		"-generate-config-out", genPath,
+		"-generate-imports",
+	}
+	code := c.Run(args)
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, output.Stderr())
+	}
+
+	src, err := os.ReadFile(genPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(src)
+	for _, want := range []string{
+		`resource "test_instance" "foo" {`,
+		"import {\n  to = test_instance.baz\n  id = \"baz\"\n}",
+		`resource "test_instance" "baz" {`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("generated config is missing %q\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "test_instance.bar") {
+		t.Errorf("generated config includes the object that is already being imported\n%s", got)
+	}
+	if !strings.Contains(output.All(), "Found objects that OpenTofu doesn't manage") {
+		t.Errorf("missing warning about the discovered objects\n%s", output.All())
+	}
+}
+
 func TestPlan_outPath(t *testing.T) {
 	td := t.TempDir()
 	testCopyDir(t, testFixturePath("plan"), td)
@@ -290,6 +492,55 @@ func TestPlan_outPath(t *testing.T) {
 	testReadPlan(t, outPath) // will call t.Fatal itself if the file cannot be read
 }
 
+func TestPlan_outPathEncrypted(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("plan"), td)
+	defer testChdir(t, td)()
+
+	outPath := filepath.Join(td, "test.plan")
+
+	p := planFixtureProvider()
+	view, done := testView(t)
+	c := &PlanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+		},
+	}
+
+	t.Setenv(PlanEncryptionKeyEnvVar, "s3cr3t")
+	code := c.Run([]string{"-out", outPath})
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, output.Stderr())
+	}
+
+	raw, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !planfile.IsEncrypted(raw) {
+		t.Fatal("plan file is not encrypted")
+	}
+
+	pf, err := c.PlanFile(outPath)
+	if err != nil {
+		t.Fatalf("failed to open encrypted plan file: %s", err)
+	}
+	if _, ok := pf.Local(); !ok {
+		t.Fatal("encrypted plan file is not a local plan file")
+	}
+
+	t.Setenv(PlanEncryptionKeyEnvVar, "")
+	_, err = c.PlanFile(outPath)
+	if err == nil {
+		t.Fatal("opened encrypted plan file without a key")
+	}
+	if got, want := err.Error(), PlanEncryptionKeyEnvVar; !strings.Contains(got, want) {
+		t.Errorf("error does not mention %s: %s", want, got)
+	}
+}
+
 func TestPlan_outPathNoChange(t *testing.T) {
 	td := t.TempDir()
 	testCopyDir(t, testFixturePath("plan"), td)
@@ -1121,6 +1372,28 @@ func TestPlan_varFileWithDecls(t *testing.T) {
 	}
 }
 
+func TestPlan_ciModeDetailedExitcode(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("plan"), td)
+	defer testChdir(t, td)()
+
+	p := planFixtureProvider()
+	view, done := testView(t)
+	c := &PlanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+			CIMode:           true,
+		},
+	}
+
+	code := c.Run([]string{})
+	output := done(t)
+	if code != 2 {
+		t.Fatalf("wrong exit status %d; want 2\nstderr: %s", code, output.Stderr())
+	}
+}
+
 func TestPlan_detailedExitcode(t *testing.T) {
 	td := t.TempDir()
 	testCopyDir(t, testFixturePath("plan"), td)
@@ -1159,6 +1432,257 @@ func TestPlan_detailedExitcode(t *testing.T) {
 	})
 }
 
+func TestPlan_failOn(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("plan"), td)
+	defer testChdir(t, td)()
+
+	tests := map[string]struct {
+		args []string
+		want int
+	}{
+		"matching class": {
+			[]string{"-fail-on=create"},
+			3,
+		},
+		"matching class with detailed exit code": {
+			[]string{"-detailed-exitcode", "-fail-on=replace,create"},
+			3,
+		},
+		"no matching class": {
+			[]string{"-fail-on=destroy"},
+			0,
+		},
+		"no matching class with detailed exit code": {
+			[]string{"-detailed-exitcode", "-fail-on=update", "-fail-on=destroy"},
+			2,
+		},
+		"invalid class": {
+			[]string{"-fail-on=delete"},
+			1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := planFixtureProvider()
+			view, done := testView(t)
+			c := &PlanCommand{
+				Meta: Meta{
+					testingOverrides: metaOverridesForProvider(p),
+					View:             view,
+				},
+			}
+
+			code := c.Run(test.args)
+			output := done(t)
+			if code != test.want {
+				t.Fatalf("wrong exit status %d; want %d\n\n%s", code, test.want, output.Stderr())
+			}
+		})
+	}
+}
+
+func TestPlanIncludesAnyAction(t *testing.T) {
+	tests := map[string]struct {
+		planActions map[plans.Action]struct{}
+		actions     []plans.Action
+		wantMatch   bool
+		wantKnown   bool
+	}{
+		"match": {
+			map[plans.Action]struct{}{plans.Create: {}, plans.Delete: {}},
+			[]plans.Action{plans.Update, plans.Delete},
+			true,
+			true,
+		},
+		"no match": {
+			map[plans.Action]struct{}{plans.Create: {}},
+			[]plans.Action{plans.Delete},
+			false,
+			true,
+		},
+		"unknown actions": {
+			nil,
+			[]plans.Action{plans.Delete},
+			false,
+			false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			op := &backend.RunningOperation{PlanActions: test.planActions}
+			gotMatch, gotKnown := planIncludesAnyAction(op, test.actions)
+			if gotMatch != test.wantMatch || gotKnown != test.wantKnown {
+				t.Fatalf("wrong result (%t, %t); want (%t, %t)", gotMatch, gotKnown, test.wantMatch, test.wantKnown)
+			}
+		})
+	}
+}
+
+func TestPlan_policy(t *testing.T) {
+	tests := map[string]struct {
+		level    string
+		args     []string
+		want     int
+		wantDiag string
+		wantPlan bool
+	}{
+		"deny": {
+			level:    "deny",
+			args:     []string{"-out=tfplan", "-policy=policies/main.tfpolicy.hcl"},
+			want:     1,
+			wantDiag: "Policy violation",
+		},
+		"warn": {
+			level:    "warn",
+			args:     []string{"-out=tfplan", "-policy=policies/main.tfpolicy.hcl"},
+			want:     0,
+			wantDiag: "Policy warning",
+			wantPlan: true,
+		},
+		"policy directory": {
+			level:    "deny",
+			args:     []string{"-policy=policies"},
+			want:     1,
+			wantDiag: "Policy violation",
+		},
+		"not loaded implicitly": {
+			level:    "deny",
+			args:     []string{"-out=tfplan"},
+			want:     0,
+			wantPlan: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			td := t.TempDir()
+			testCopyDir(t, testFixturePath("plan"), td)
+			defer testChdir(t, td)()
+
+			src := fmt.Sprintf(`
+policy "no_bar_ami" {
+  level          = %q
+  resource_types = ["test_instance"]
+  condition      = "resource.change.after.ami != 'bar'"
+  error_message  = "Instances must not use the bar AMI."
+}
+`, test.level)
+			if err := os.Mkdir("policies", 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join("policies", "main.tfpolicy.hcl"), []byte(src), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			p := planFixtureProvider()
+			view, done := testView(t)
+			c := &PlanCommand{
+				Meta: Meta{
+					testingOverrides: metaOverridesForProvider(p),
+					View:             view,
+				},
+			}
+
+			code := c.Run(test.args)
+			output := done(t)
+			if code != test.want {
+				t.Fatalf("wrong exit code %d; want %d\n%s", code, test.want, output.All())
+			}
+			got := output.All()
+			if test.wantDiag == "" && strings.Contains(got, "no_bar_ami") {
+				t.Fatalf("unexpected policy diagnostic in output:\n%s", got)
+			}
+			if test.wantDiag != "" && (!strings.Contains(got, test.wantDiag) || !strings.Contains(got, `"no_bar_ami", for test_instance.foo`)) {
+				t.Fatalf("missing policy diagnostic in output:\n%s", got)
+			}
+			if _, err := os.Stat("tfplan"); (err == nil) != test.wantPlan {
+				t.Fatalf("wrong plan file existence; want %t", test.wantPlan)
+			}
+		})
+	}
+}
+
+func TestPlan_stateSource(t *testing.T) {
+	wantPrior := cty.ObjectVal(map[string]cty.Value{
+		"id":  cty.StringVal("bar"),
+		"ami": cty.NullVal(cty.String),
+		"network_interface": cty.ListValEmpty(cty.Object(map[string]cty.Type{
+			"device_index": cty.String,
+			"description":  cty.String,
+		})),
+	})
+
+	tests := map[string]struct {
+		args      func(statePath string) []string
+		want      int
+		wantPrior bool
+		wantErr   string
+	}{
+		"file": {
+			args:      func(statePath string) []string { return []string{"-state-source=file:" + statePath} },
+			wantPrior: true,
+		},
+		"workspace": {
+			args:      func(string) []string { return []string{"-state-source=workspace:prod"} },
+			wantPrior: true,
+		},
+		"missing file": {
+			args:    func(string) []string { return []string{"-state-source=file:nonexistent.tfstate"} },
+			want:    1,
+			wantErr: "Failed to read state file",
+		},
+		"missing workspace": {
+			args:    func(string) []string { return []string{"-state-source=workspace:staging"} },
+			want:    1,
+			wantErr: "Workspace does not exist",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			td := t.TempDir()
+			testCopyDir(t, testFixturePath("plan"), td)
+			defer testChdir(t, td)()
+
+			statePath := testStateFile(t, testState())
+			testStateFileWorkspaceDefault(t, "prod", testState())
+
+			p := planFixtureProvider()
+			view, done := testView(t)
+			c := &PlanCommand{
+				Meta: Meta{
+					testingOverrides: metaOverridesForProvider(p),
+					View:             view,
+				},
+			}
+
+			code := c.Run(test.args(statePath))
+			output := done(t)
+			if code != test.want {
+				t.Fatalf("wrong exit status %d; want %d\n\n%s", code, test.want, output.Stderr())
+			}
+			if test.wantErr != "" && !strings.Contains(output.Stderr(), test.wantErr) {
+				t.Fatalf("wrong error\ngot:  %s\nwant: %s", output.Stderr(), test.wantErr)
+			}
+			if !test.wantPrior {
+				return
+			}
+
+			// The plan must be based on the alternate state, even though the
+			// current workspace has no state at all.
+			if got := p.PlanResourceChangeRequest.PriorState; !wantPrior.RawEquals(got) {
+				t.Fatalf("wrong prior state\ngot:  %#v\nwant: %#v", got, wantPrior)
+			}
+			if _, err := os.Stat(DefaultStateFilename); !os.IsNotExist(err) {
+				t.Fatalf("plan created state for the current workspace")
+			}
+		})
+	}
+}
+
 func TestPlan_detailedExitcode_emptyDiff(t *testing.T) {
 	td := t.TempDir()
 	testCopyDir(t, testFixturePath("plan-emptydiff"), td)
@@ -1753,3 +2277,66 @@ foo = "bar"
 variable "nope" {
 }
 `
+
+func TestPlan_watch(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("plan"), td)
+	defer testChdir(t, td)()
+
+	defer func(interval time.Duration) { planWatchInterval = interval }(planWatchInterval)
+	planWatchInterval = 10 * time.Millisecond
+
+	var plans atomic.Int32
+	p := planFixtureProvider()
+	p.PlanResourceChangeFn = func(req providers.PlanResourceChangeRequest) providers.PlanResourceChangeResponse {
+		plans.Add(1)
+		return providers.PlanResourceChangeResponse{PlannedState: req.ProposedNewState}
+	}
+
+	shutdownCh := make(chan struct{})
+	view, done := testView(t)
+	c := &PlanCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(p),
+			View:             view,
+			ShutdownCh:       shutdownCh,
+		},
+	}
+
+	waitForPlans := func(n int32) {
+		t.Helper()
+		deadline := time.Now().Add(10 * time.Second)
+		for plans.Load() < n {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %d planned changes; got %d", n, plans.Load())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	codeCh := make(chan int)
+	go func() {
+		codeCh <- c.Run([]string{"-watch"})
+	}()
+
+	waitForPlans(1)
+	extra := `resource "test_instance" "extra" {}`
+	if err := os.WriteFile("extra.tf", []byte(extra), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// The second plan includes both the original and the new resource.
+	waitForPlans(3)
+	// Give the second plan a moment to finish, so that the interrupt stops
+	// watching rather than the plan itself.
+	time.Sleep(500 * time.Millisecond)
+
+	shutdownCh <- struct{}{}
+	code := <-codeCh
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("wrong exit code %d; want 0\noutput:\n%s", code, output.All())
+	}
+	if got, want := output.Stdout(), "Changes detected in extra.tf"; !strings.Contains(got, want) {
+		t.Fatalf("missing change notification %q in output:\n%s", want, got)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/command/views"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// planWatchInterval is how often watch mode checks for changes to the
+// watched files. It is a variable so that tests can reduce it.
+var planWatchInterval = 500 * time.Millisecond
+
+// watch implements the -watch option, creating a new speculative plan each
+// time the configuration or variable definitions files change until the
+// command is interrupted.
+func (c *PlanCommand) watch(args *arguments.Plan, view views.Plan, diags tfdiags.Diagnostics) int {
+	// An interrupt during a plan stops that plan as usual, but it also stops
+	// watching, so we relay interrupts to the operation while remembering
+	// that we've seen one.
+	var interrupted atomic.Bool
+	shutdownCh := c.ShutdownCh
+	relayCh := make(chan struct{}, 1)
+	c.ShutdownCh = relayCh
+	defer func() { c.ShutdownCh = shutdownCh }()
+	go func() {
+		for range shutdownCh {
+			interrupted.Store(true)
+			select {
+			case relayCh <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	varFiles := watchVarFiles(args.Vars)
+	snapshot := watchSnapshot(".", varFiles)
+	code := c.plan(args, view, diags)
+
+	for !interrupted.Load() {
+		view.WatchWaiting()
+
+		var changed []string
+		for len(changed) == 0 {
+			select {
+			case <-relayCh:
+				return code
+			case <-time.After(planWatchInterval):
+			}
+			next := watchSnapshot(".", varFiles)
+			changed = watchChangedFiles(snapshot, next)
+			snapshot = next
+		}
+
+		log.Printf("[TRACE] PlanCommand: watched files changed: %s", strings.Join(changed, ", "))
+		view.WatchChanged(changed)
+		code = c.plan(args, view, nil)
+	}
+
+	return code
+}
+
+// watchVarFiles returns the variable definitions files selected by the
+// given arguments, which are watched along with the configuration files.
+func watchVarFiles(vars *arguments.Vars) []string {
+	var ret []string
+	for _, arg := range vars.All() {
+		if arg.Name == "-var-file" {
+			ret = append(ret, arg.Value)
+		}
+	}
+	return ret
+}
+
+// watchFileState records the attributes of a watched file that we use to
+// detect changes.
+type watchFileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// watchSnapshot returns the current state of all of the watched files in
+// the given configuration directory, including files in subdirectories
+// which might contain local modules, along with the given additional files.
+//
+// Directories whose names start with a period, such as .terraform, are
+// skipped because they contain installed dependencies rather than
+// configuration being edited.
+func watchSnapshot(dir string, extraFiles []string) map[string]watchFileState {
+	ret := make(map[string]watchFileState)
+
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Files can be removed while we're walking, and a missing
+			// file is recorded as a change when compared with an earlier
+			// snapshot anyway.
+			return nil
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !watchedFileName(d.Name()) {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			ret[path] = watchFileState{modTime: info.ModTime(), size: info.Size()}
+		}
+		return nil
+	})
+
+	for _, path := range extraFiles {
+		if info, err := os.Stat(path); err == nil {
+			ret[path] = watchFileState{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+
+	return ret
+}
+
+// watchedFileName returns true if a file with the given name could affect
+// the result of a plan.
+func watchedFileName(name string) bool {
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+	for _, suffix := range []string{".tf", ".tf.json", ".tf.yaml", ".tofu.yaml", ".tfvars", ".tfvars.json"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// watchChangedFiles returns the sorted paths of the files which were added,
+// removed, or modified between the two given snapshots.
+func watchChangedFiles(prev, next map[string]watchFileState) []string {
+	var ret []string
+	for path, state := range next {
+		if prevState, ok := prev[path]; !ok || !prevState.modTime.Equal(state.modTime) || prevState.size != state.size {
+			ret = append(ret, path)
+		}
+	}
+	for path := range prev {
+		if _, ok := next[path]; !ok {
+			ret = append(ret, path)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
@@ -0,0 +1,92 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os/exec"
+	"runtime"
+
+	plugin "github.com/hashicorp/go-plugin"
+
+	"github.com/opentofu/opentofu/internal/command/cliconfig"
+)
+
+// pluginTransport describes how OpenTofu connects to the provider and
+// provisioner plugins that it starts.
+//
+// Each plugin chooses for itself whether to listen on a unix domain socket or
+// on a loopback TCP port, and tells OpenTofu which during the plugin
+// handshake, so OpenTofu can't choose for it. Instead, OpenTofu tells plugins
+// where to create their sockets, and if unix domain sockets are required it
+// refuses to use a plugin that listens on a TCP port, rather than letting it
+// fail in an environment where it can't bind one.
+type pluginTransport struct {
+	// requireUnix is true if plugins must listen on a unix domain socket.
+	// It's never true on Windows, where the plugin SDKs always listen on a
+	// TCP port, because the plugin handshake has no way to describe named
+	// pipes.
+	requireUnix bool
+
+	// socketDir, if set, is the directory where plugins should create their
+	// sockets.
+	socketDir string
+}
+
+// pluginTransport returns the transport that the CLI configuration selects
+// for plugins.
+func (m *Meta) pluginTransport() pluginTransport {
+	return newPluginTransport(m.PluginTransport, m.PluginSocketDir, runtime.GOOS)
+}
+
+func newPluginTransport(transport, socketDir, goos string) pluginTransport {
+	requireUnix := transport == cliconfig.PluginTransportUnix
+	if requireUnix && goos == "windows" {
+		log.Printf("[WARN] plugin_transport = %q has no effect on Windows, where plugins always listen on a TCP port", cliconfig.PluginTransportUnix)
+		requireUnix = false
+	}
+	return pluginTransport{
+		requireUnix: requireUnix,
+		socketDir:   socketDir,
+	}
+}
+
+// command returns the command to start the plugin executable at the given
+// path.
+func (t pluginTransport) command(execFile string) *exec.Cmd {
+	cmd := exec.Command(execFile)
+	if t.socketDir != "" {
+		// Plugins built with go-plugin v1.5.0 or later create their sockets
+		// in the directory named by this environment variable. go-plugin
+		// adds the rest of our environment when it starts the plugin.
+		cmd.Env = []string{"PLUGIN_UNIX_SOCKET_DIR=" + t.socketDir}
+	}
+	return cmd
+}
+
+// check returns an error, and stops the plugin, if the plugin that the given
+// client started isn't using the required transport.
+func (t pluginTransport) check(client *plugin.Client, name string) error {
+	reattach := client.ReattachConfig()
+	if reattach == nil {
+		return nil
+	}
+	if err := t.checkAddr(reattach.Addr, name); err != nil {
+		client.Kill()
+		return err
+	}
+	return nil
+}
+
+func (t pluginTransport) checkAddr(addr net.Addr, name string) error {
+	if !t.requireUnix || addr == nil || addr.Network() == "unix" {
+		return nil
+	}
+	return fmt.Errorf(
+		"the plugin for %s is listening on %s address %s, but the CLI configuration requires plugins to use unix domain sockets (plugin_transport = %q); the plugin must be rebuilt with a plugin SDK that supports unix domain sockets on this platform",
+		name, addr.Network(), addr, cliconfig.PluginTransportUnix,
+	)
+}
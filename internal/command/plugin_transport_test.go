@@ -0,0 +1,51 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPluginTransport(t *testing.T) {
+	tcpAddr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1234}
+	unixAddr := &net.UnixAddr{Net: "unix", Name: "/tmp/plugin123"}
+
+	t.Run("auto", func(t *testing.T) {
+		m := &Meta{}
+		transport := m.pluginTransport()
+		if err := transport.checkAddr(tcpAddr, "test"); err != nil {
+			t.Errorf("unexpected error for TCP: %s", err)
+		}
+		if err := transport.checkAddr(unixAddr, "test"); err != nil {
+			t.Errorf("unexpected error for unix domain socket: %s", err)
+		}
+		if cmd := transport.command("provider"); cmd.Env != nil {
+			t.Errorf("unexpected environment %q", cmd.Env)
+		}
+	})
+	t.Run("unix", func(t *testing.T) {
+		transport := newPluginTransport("unix", "/run/tofu", "linux")
+		if err := transport.checkAddr(tcpAddr, "test"); err == nil {
+			t.Errorf("plugin listening on TCP was accepted")
+		}
+		if err := transport.checkAddr(unixAddr, "test"); err != nil {
+			t.Errorf("unexpected error for unix domain socket: %s", err)
+		}
+		want := []string{"PLUGIN_UNIX_SOCKET_DIR=/run/tofu"}
+		if diff := cmp.Diff(want, transport.command("provider").Env); diff != "" {
+			t.Errorf("wrong environment\n%s", diff)
+		}
+	})
+	t.Run("unix on windows", func(t *testing.T) {
+		// Plugins can't listen on anything but TCP ports on Windows, so
+		// requiring unix domain sockets there would reject every plugin.
+		transport := newPluginTransport("unix", "", "windows")
+		if err := transport.checkAddr(tcpAddr, "test"); err != nil {
+			t.Errorf("unexpected error for TCP: %s", err)
+		}
+	})
+}
@@ -4,11 +4,15 @@
 package command
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"log"
-	"os/exec"
+	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 
 	plugin "github.com/hashicorp/go-plugin"
 	"github.com/kardianos/osext"
@@ -122,18 +126,145 @@ func (m *Meta) provisionerFactories() map[string]provisioners.Factory {
 		// valid versions and that there's at least one meta.
 		newest := metas.Newest()
 
-		factories[name] = provisionerFactory(newest)
+		factories[name] = provisionerFactory(newest, m.pluginTransport())
+	}
+
+	// Provisioner plugins declared in the CLI configuration take precedence
+	// over all of the others.
+	transport := m.pluginTransport()
+	for name, cfg := range m.ProvisionerPlugins {
+		meta := discovery.PluginMeta{
+			Name:    name,
+			Version: "0.0.0",
+			Path:    cfg.Path,
+		}
+		factories[name] = verifiedProvisionerFactory(meta, cfg.SHA256, m.provisionerCacheDir(), func(meta discovery.PluginMeta) provisioners.Factory {
+			return provisionerFactory(meta, transport)
+		})
 	}
 
 	return factories
 }
 
-func provisionerFactory(meta discovery.PluginMeta) provisioners.Factory {
+// provisionerCacheDir returns the directory where OpenTofu keeps verified
+// copies of the provisioner plugins that the CLI configuration declares with
+// a checksum.
+//
+// This is deliberately not one of the plugin directories, so that the copies
+// are never found by plugin discovery.
+func (m *Meta) provisionerCacheDir() string {
+	return filepath.Join(m.DataDir(), "provisioners")
+}
+
+// verifiedProvisionerFactory returns a factory for the provisioner plugin
+// described by meta that only runs the plugin if its executable file has the
+// given hex-encoded SHA256 checksum. If the checksum is empty then it returns
+// the factory that newFactory returns for meta, which runs the executable
+// without verifying it.
+//
+// To make sure that the file OpenTofu runs is the file that it verified, the
+// factory doesn't run the executable at meta.Path. Instead it runs a copy of
+// it that installProvisionerPlugin verified while copying it into cacheDir.
+func verifiedProvisionerFactory(meta discovery.PluginMeta, wantSHA256, cacheDir string, newFactory func(discovery.PluginMeta) provisioners.Factory) provisioners.Factory {
+	if wantSHA256 == "" {
+		return newFactory(meta)
+	}
+	return func() (provisioners.Interface, error) {
+		path, err := installProvisionerPlugin(meta, wantSHA256, cacheDir)
+		if err != nil {
+			return nil, err
+		}
+		verified := meta
+		verified.Path = path
+		return newFactory(verified)()
+	}
+}
+
+// installProvisionerPlugin copies the executable file of the provisioner
+// plugin described by meta into cacheDir, unless it's already there, and
+// returns the path of the copy.
+//
+// The copy is only kept if it has the given hex-encoded SHA256 checksum,
+// which is calculated from the same bytes that are written to the copy. The
+// copies are kept in a directory named after their checksum, so a copy that
+// is already in cacheDir is verified again before it's used, in case it was
+// modified since it was installed.
+func installProvisionerPlugin(meta discovery.PluginMeta, wantSHA256, cacheDir string) (string, error) {
+	wantSHA256 = strings.ToLower(wantSHA256)
+	dir := filepath.Join(cacheDir, meta.Name, wantSHA256)
+	path := filepath.Join(dir, filepath.Base(meta.Path))
+
+	if f, err := os.Open(path); err == nil {
+		got, err := fileSHA256(f)
+		f.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to read the installed copy of provisioner %q plugin: %w", meta.Name, err)
+		}
+		if got != wantSHA256 {
+			return "", fmt.Errorf(
+				"the installed copy of provisioner %q plugin at %s has SHA256 checksum %s, but the provisioner_plugin block in the CLI configuration requires %s; remove it and run \"tofu init\" to install the plugin again",
+				meta.Name, path, got, wantSHA256,
+			)
+		}
+		log.Printf("[TRACE] Verified checksum of the installed copy of provisioner %q plugin at %s", meta.Name, path)
+		return path, nil
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to open the installed copy of provisioner %q plugin: %w", meta.Name, err)
+	}
+
+	src, err := os.Open(meta.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open provisioner %q plugin: %w", meta.Name, err)
+	}
+	defer src.Close()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory for provisioner %q plugin: %w", meta.Name, err)
+	}
+	tmp, err := os.CreateTemp(dir, ".install-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to install provisioner %q plugin: %w", meta.Name, err)
+	}
+	defer os.Remove(tmp.Name()) // has no effect once the copy is renamed into place
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h), src)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to install provisioner %q plugin: %w", meta.Name, err)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != wantSHA256 {
+		return "", fmt.Errorf(
+			"the provisioner %q plugin at %s has SHA256 checksum %s, but the provisioner_plugin block in the CLI configuration requires %s",
+			meta.Name, meta.Path, got, wantSHA256,
+		)
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return "", fmt.Errorf("failed to install provisioner %q plugin: %w", meta.Name, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to install provisioner %q plugin: %w", meta.Name, err)
+	}
+	log.Printf("[TRACE] Installed a verified copy of provisioner %q plugin from %s at %s", meta.Name, meta.Path, path)
+	return path, nil
+}
+
+func fileSHA256(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func provisionerFactory(meta discovery.PluginMeta, transport pluginTransport) provisioners.Factory {
 	return func() (provisioners.Interface, error) {
 		cfg := &plugin.ClientConfig{
-			Cmd:              exec.Command(meta.Path),
+			Cmd:              transport.command(meta.Path),
 			HandshakeConfig:  tfplugin.Handshake,
 			VersionedPlugins: tfplugin.VersionedPlugins,
+			GRPCDialOptions:  tfplugin.GRPCDialOptions,
 			Managed:          true,
 			Logger:           logging.NewLogger("provisioner"),
 			AllowedProtocols: []plugin.Protocol{plugin.ProtocolGRPC},
@@ -142,6 +273,12 @@ func provisionerFactory(meta discovery.PluginMeta) provisioners.Factory {
 			SyncStderr:       logging.PluginOutputMonitor(fmt.Sprintf("%s:stderr", meta.Name)),
 		}
 		client := plugin.NewClient(cfg)
+		if _, err := client.Start(); err != nil {
+			return nil, err
+		}
+		if err := transport.check(client, fmt.Sprintf("provisioner %q", meta.Name)); err != nil {
+			return nil, err
+		}
 		return newProvisionerClient(client)
 	}
 }
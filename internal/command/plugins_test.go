@@ -4,9 +4,18 @@
 package command
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/opentofu/opentofu/internal/command/cliconfig"
+	"github.com/opentofu/opentofu/internal/plugin/discovery"
+	"github.com/opentofu/opentofu/internal/provisioners"
+	"github.com/opentofu/opentofu/internal/tofu"
 )
 
 func TestPluginPath(t *testing.T) {
@@ -45,3 +54,118 @@ func TestInternalProviders(t *testing.T) {
 		t.Errorf("didn't find terraform_remote_state in internal \"terraform\" provider")
 	}
 }
+
+func TestProvisionerFactories_cliConfig(t *testing.T) {
+	td := t.TempDir()
+	execFile := filepath.Join(td, "terraform-provisioner-example")
+	if err := os.WriteFile(execFile, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	m := Meta{
+		ProvisionerPlugins: map[string]*cliconfig.ConfigProvisionerPlugin{
+			"example": {Path: execFile},
+		},
+	}
+	factories := m.provisionerFactories()
+	if _, ok := factories["example"]; !ok {
+		t.Error("no factory for the provisioner declared in the CLI configuration")
+	}
+	if _, ok := factories["local-exec"]; !ok {
+		t.Error("no factory for the built-in local-exec provisioner")
+	}
+}
+
+func TestVerifiedProvisionerFactory(t *testing.T) {
+	td := t.TempDir()
+	cacheDir := filepath.Join(td, "cache")
+	execFile := filepath.Join(td, "terraform-provisioner-example")
+	content := []byte("#!/bin/sh\n")
+	if err := os.WriteFile(execFile, content, 0755); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(content)
+	wantSHA256 := hex.EncodeToString(sum[:])
+
+	meta := discovery.PluginMeta{Name: "example", Version: "0.0.0", Path: execFile}
+	var startedPath string
+	newFactory := func(meta discovery.PluginMeta) provisioners.Factory {
+		return func() (provisioners.Interface, error) {
+			startedPath = meta.Path
+			return &tofu.MockProvisioner{}, nil
+		}
+	}
+
+	t.Run("checksum matches", func(t *testing.T) {
+		startedPath = ""
+		if _, err := verifiedProvisionerFactory(meta, wantSHA256, cacheDir, newFactory)(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		wantPath := filepath.Join(cacheDir, "example", wantSHA256, "terraform-provisioner-example")
+		if startedPath != wantPath {
+			t.Fatalf("started the plugin at %q; want the verified copy at %q", startedPath, wantPath)
+		}
+		got, err := os.ReadFile(wantPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(content) {
+			t.Errorf("verified copy has the wrong content %q", got)
+		}
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		startedPath = ""
+		wrongSHA256 := strings.Repeat("0", 64)
+		_, err := verifiedProvisionerFactory(meta, wrongSHA256, cacheDir, newFactory)()
+		if err == nil || !strings.Contains(err.Error(), "SHA256 checksum "+wantSHA256) {
+			t.Fatalf("wrong error for checksum mismatch: %v", err)
+		}
+		if startedPath != "" {
+			t.Error("plugin with the wrong checksum was started")
+		}
+		if _, err := os.Stat(filepath.Join(cacheDir, "example", wrongSHA256, "terraform-provisioner-example")); !os.IsNotExist(err) {
+			t.Errorf("plugin with the wrong checksum was installed: %v", err)
+		}
+	})
+
+	t.Run("source modified after installation", func(t *testing.T) {
+		// Once the plugin is installed, changes to the original executable
+		// don't affect what runs.
+		if err := os.WriteFile(execFile, []byte("#!/bin/sh\necho modified\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		startedPath = ""
+		if _, err := verifiedProvisionerFactory(meta, wantSHA256, cacheDir, newFactory)(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if startedPath == execFile {
+			t.Fatal("started the modified original executable")
+		}
+	})
+
+	t.Run("installed copy modified", func(t *testing.T) {
+		installed := filepath.Join(cacheDir, "example", wantSHA256, "terraform-provisioner-example")
+		if err := os.WriteFile(installed, []byte("#!/bin/sh\necho modified\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		startedPath = ""
+		_, err := verifiedProvisionerFactory(meta, wantSHA256, cacheDir, newFactory)()
+		if err == nil || !strings.Contains(err.Error(), "installed copy") {
+			t.Fatalf("wrong error for modified installed copy: %v", err)
+		}
+		if startedPath != "" {
+			t.Error("modified installed copy was started")
+		}
+	})
+
+	t.Run("no checksum", func(t *testing.T) {
+		startedPath = ""
+		if _, err := verifiedProvisionerFactory(meta, "", cacheDir, newFactory)(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if startedPath != execFile {
+			t.Fatalf("started the plugin at %q; want %q", startedPath, execFile)
+		}
+	})
+}
@@ -0,0 +1,163 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// providerCapabilitiesFilename is the name of the file, in the data
+// directory, where "tofu init" records the capabilities of the providers it
+// installed.
+const providerCapabilitiesFilename = "provider-capabilities.json"
+
+type providerCapabilitiesFile struct {
+	FormatVersion string `json:"format_version"`
+
+	// Providers maps the address of each provider to its capabilities.
+	Providers map[string]providerCapabilitiesRecord `json:"providers"`
+}
+
+type providerCapabilitiesRecord struct {
+	// Version is the version of the provider that was probed, which must
+	// match the version selected in the dependency lock file for the
+	// record to be used.
+	Version string `json:"version"`
+
+	PlanDestroy               bool     `json:"plan_destroy"`
+	GetProviderSchemaOptional bool     `json:"get_provider_schema_optional"`
+	Functions                 []string `json:"functions"`
+}
+
+// probeProviderCapabilities starts each of the providers selected in the
+// dependency lock file to find out which optional protocol features it
+// supports, and records them in the data directory for later commands.
+//
+// A provider that can't be probed is just left out of the record, with a
+// warning, because the commands that use it will report the problem anyway.
+func (m *Meta) probeProviderCapabilities() tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	locks, moreDiags := m.lockedDependencies()
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		return diags
+	}
+	var factories map[addrs.Provider]providers.Factory
+	if m.testingOverrides != nil {
+		factories = m.testingOverrides.Providers
+	} else {
+		var err error
+		factories, err = m.providerFactories()
+		if err != nil {
+			return diags.Append(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Failed to probe provider capabilities",
+				fmt.Sprintf("OpenTofu could not start the installed providers to record their capabilities: %s.", err),
+			))
+		}
+	}
+
+	file := providerCapabilitiesFile{
+		FormatVersion: "1.0",
+		Providers:     make(map[string]providerCapabilitiesRecord),
+	}
+	for addr, lock := range locks.AllProviders() {
+		factory, ok := factories[addr]
+		if !ok {
+			continue
+		}
+		caps, err := probeProviderCapabilities(factory)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Failed to probe provider capabilities",
+				fmt.Sprintf("OpenTofu could not record the capabilities of provider %s: %s.\n\nOpenTofu will find out the provider's capabilities each time it is used instead.", addr.ForDisplay(), err),
+			))
+			continue
+		}
+		log.Printf("[TRACE] probeProviderCapabilities: %s v%s has capabilities %#v", addr, lock.Version(), caps)
+		file.Providers[addr.String()] = providerCapabilitiesRecord{
+			Version:                   lock.Version().String(),
+			PlanDestroy:               caps.PlanDestroy,
+			GetProviderSchemaOptional: caps.GetProviderSchemaOptional,
+			Functions:                 caps.Functions,
+		}
+	}
+
+	src, err := json.MarshalIndent(file, "", "  ")
+	if err == nil {
+		err = os.WriteFile(filepath.Join(m.DataDir(), providerCapabilitiesFilename), src, 0644)
+	}
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Failed to record provider capabilities",
+			fmt.Sprintf("OpenTofu could not save the capabilities of the installed providers: %s.", err),
+		))
+	}
+	return diags
+}
+
+func probeProviderCapabilities(factory providers.Factory) (providers.Capabilities, error) {
+	provider, err := factory()
+	if err != nil {
+		return providers.Capabilities{}, err
+	}
+	defer provider.Close()
+	resp := provider.GetProviderSchema()
+	if resp.Diagnostics.HasErrors() {
+		return providers.Capabilities{}, resp.Diagnostics.Err()
+	}
+	return resp.Capabilities(), nil
+}
+
+// recordedProviderCapabilities returns the capabilities that "tofu init"
+// recorded for the providers whose versions still match the dependency
+// lock file.
+func (m *Meta) recordedProviderCapabilities() map[addrs.Provider]providers.Capabilities {
+	src, err := os.ReadFile(filepath.Join(m.DataDir(), providerCapabilitiesFilename))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("[WARN] Failed to read the recorded provider capabilities: %s", err)
+		}
+		return nil
+	}
+	var file providerCapabilitiesFile
+	if err := json.Unmarshal(src, &file); err != nil {
+		log.Printf("[WARN] Ignoring invalid recorded provider capabilities: %s", err)
+		return nil
+	}
+	locks, diags := m.lockedDependencies()
+	if diags.HasErrors() {
+		return nil
+	}
+
+	ret := make(map[addrs.Provider]providers.Capabilities)
+	for addrStr, record := range file.Providers {
+		addr, diags := addrs.ParseProviderSourceString(addrStr)
+		if diags.HasErrors() {
+			continue
+		}
+		lock := locks.Provider(addr)
+		if lock == nil || lock.Version().String() != record.Version {
+			// The provider was upgraded since it was probed.
+			continue
+		}
+		ret[addr] = providers.Capabilities{
+			PlanDestroy:               record.PlanDestroy,
+			GetProviderSchemaOptional: record.GetProviderSchemaOptional,
+			Functions:                 record.Functions,
+		}
+	}
+	return ret
+}
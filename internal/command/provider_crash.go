@@ -0,0 +1,591 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	plugin "github.com/hashicorp/go-plugin"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/getproviders"
+	"github.com/opentofu/opentofu/internal/lang/marks"
+	"github.com/opentofu/opentofu/internal/logging"
+	tfplugin "github.com/opentofu/opentofu/internal/plugin"
+	tfplugin6 "github.com/opentofu/opentofu/internal/plugin6"
+	"github.com/opentofu/opentofu/internal/providercache"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/version"
+)
+
+// The TF_PROVIDER_CRASH_RETRIES environment variable sets how many times
+// OpenTofu replaces a provider plugin process that crashed with a new one,
+// for each provider instance. Calls that don't change any remote objects
+// are retried on the new process.
+var providerCrashRetries = parseProviderCrashRetries(os.Getenv("TF_PROVIDER_CRASH_RETRIES"))
+
+func parseProviderCrashRetries(s string) int {
+	if s == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		log.Printf("[WARN] Ignoring invalid TF_PROVIDER_CRASH_RETRIES value %q", s)
+		return 0
+	}
+	return n
+}
+
+// providerCrashExitTimeout is how long to wait for a provider plugin process
+// that stopped responding to exit, so that its crash output is complete.
+var providerCrashExitTimeout = 2 * time.Second
+
+// providerCrashBundleDir returns the directory where the diagnostics bundles
+// of provider plugins that crash are saved.
+func (m *Meta) providerCrashBundleDir() string {
+	return filepath.Join(m.DataDir(), "provider-crashes")
+}
+
+// crashRecoveringProvider wraps a provider plugin so that if its process
+// crashes, it saves a diagnostics bundle describing the crash and, if
+// allowed, replaces the process with a new one and retries the call that
+// crashed it unless that call might have changed a remote object.
+type crashRecoveringProvider struct {
+	meta      *providercache.CachedProvider
+	factory   providers.Factory
+	bundleDir string
+
+	mu      sync.Mutex
+	current providers.Interface
+	// dead is true if current crashed and couldn't be replaced.
+	dead bool
+	// configured is the request that most recently configured the
+	// provider successfully, which is repeated to configure a replacement.
+	configured *providers.ConfigureProviderRequest
+	restarts   int
+	// schema is the provider's schema, once it was loaded successfully,
+	// which tells which values to redact from a diagnostics bundle.
+	schema *providers.GetProviderSchemaResponse
+}
+
+var _ providers.Interface = (*crashRecoveringProvider)(nil)
+
+// crashRecoveringProviderFactory wraps the given factory for the provider in
+// the given package so that the instances it returns recover from crashes.
+func crashRecoveringProviderFactory(meta *providercache.CachedProvider, bundleDir string, factory providers.Factory) providers.Factory {
+	return func() (providers.Interface, error) {
+		instance, err := factory()
+		if err != nil {
+			return nil, err
+		}
+		return &crashRecoveringProvider{
+			meta:      meta,
+			factory:   factory,
+			bundleDir: bundleDir,
+			current:   instance,
+		}, nil
+	}
+}
+
+// providerCrashRequest describes a call that crashed a provider, for the
+// diagnostics bundle.
+type providerCrashRequest struct {
+	Method   string
+	TypeName string
+	Values   map[string]cty.Value
+}
+
+// providerCrashBundle is the content of a diagnostics bundle.
+type providerCrashBundle struct {
+	Time         time.Time                  `json:"time"`
+	TofuVersion  string                     `json:"tofu_version"`
+	Platform     string                     `json:"platform"`
+	Provider     string                     `json:"provider"`
+	Version      string                     `json:"version,omitempty"`
+	Executable   string                     `json:"executable,omitempty"`
+	Method       string                     `json:"method"`
+	TypeName     string                     `json:"type_name,omitempty"`
+	Request      map[string]json.RawMessage `json:"request,omitempty"`
+	PluginOutput []string                   `json:"plugin_output"`
+}
+
+func (p *crashRecoveringProvider) instance() providers.Interface {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.current
+}
+
+// crashed reports whether the provider crashed and can't be used any more.
+func (p *crashRecoveringProvider) crashed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.dead
+}
+
+// providerCrashed returns true if the given provider instance crashed and
+// can't be used any more.
+func providerCrashed(provider providers.Interface) bool {
+	p, ok := provider.(*crashRecoveringProvider)
+	return ok && p.crashed()
+}
+
+// recoverCall makes a call to the provider, handling a crash as described for
+// crashRecoveringProvider. retry is true if the call can be repeated safely,
+// because it doesn't change any remote objects.
+func recoverCall[Resp any](p *crashRecoveringProvider, req providerCrashRequest, retry bool, call func(providers.Interface) Resp, diagsOf func(*Resp) *tfdiags.Diagnostics) Resp {
+	var warnings tfdiags.Diagnostics
+	for {
+		instance := p.instance()
+		resp := call(instance)
+		diags := diagsOf(&resp)
+		if !providers.PluginCrashedIn(*diags) {
+			*diags = warnings.Append(*diags)
+			return resp
+		}
+
+		bundle := p.saveCrashBundle(instance, req)
+		restarted := p.restart(instance)
+		if !restarted || !retry {
+			*diags = warnings.Append(p.crashError(req, bundle, restarted))
+			return resp
+		}
+		log.Printf("[WARN] Retrying %s call to %s, which crashed", req.Method, p.meta.Provider)
+		warnings = warnings.Append(p.crashWarning(req, bundle))
+	}
+}
+
+// restart replaces the given crashed instance with a new one, if it's still
+// the current instance and the retry limit allows, and returns true if the
+// provider has a working instance again.
+func (p *crashRecoveringProvider) restart(crashed providers.Interface) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.current != crashed {
+		// Another call that the crash interrupted already replaced it.
+		return !p.dead
+	}
+	if p.dead {
+		return false
+	}
+	p.dead = true
+	if err := crashed.Close(); err != nil {
+		log.Printf("[TRACE] Failed to close crashed %s plugin: %s", p.meta.Provider, err)
+	}
+	if p.restarts >= providerCrashRetries {
+		return false
+	}
+	p.restarts++
+
+	log.Printf("[WARN] Starting a new %s plugin to replace one that crashed", p.meta.Provider)
+	instance, err := p.factory()
+	if err != nil {
+		log.Printf("[ERROR] Failed to start a new %s plugin: %s", p.meta.Provider, err)
+		return false
+	}
+	if p.configured != nil {
+		resp := instance.ConfigureProvider(*p.configured)
+		if resp.Diagnostics.HasErrors() {
+			log.Printf("[ERROR] Failed to configure a new %s plugin: %s", p.meta.Provider, resp.Diagnostics.Err())
+			instance.Close()
+			return false
+		}
+	}
+	p.current = instance
+	p.dead = false
+	return true
+}
+
+// saveCrashBundle writes a diagnostics bundle describing a crash of the
+// given instance while handling the given request, and returns its path, or
+// an empty string if it couldn't be saved.
+func (p *crashRecoveringProvider) saveCrashBundle(instance providers.Interface, req providerCrashRequest) string {
+	waitForPluginExit(instance, providerCrashExitTimeout)
+
+	bundle := providerCrashBundle{
+		Time:        time.Now().UTC(),
+		TofuVersion: version.String(),
+		Platform:    getproviders.CurrentPlatform.String(),
+		Provider:    p.meta.Provider.String(),
+		Method:      req.Method,
+		TypeName:    req.TypeName,
+	}
+	if p.meta.Version != getproviders.UnspecifiedVersion {
+		bundle.Version = p.meta.Version.String()
+	}
+	if execFile, err := p.meta.ExecutableFile(); err == nil {
+		bundle.Executable = execFile
+		bundle.PluginOutput = logging.PluginPanic(filepath.Base(execFile))
+	}
+	if len(req.Values) > 0 {
+		bundle.Request = make(map[string]json.RawMessage, len(req.Values))
+		for name, val := range req.Values {
+			bundle.Request[name] = crashBundleValue(val, p.valueSchema(req, name))
+		}
+	}
+
+	src, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		log.Printf("[ERROR] Failed to encode provider crash bundle: %s", err)
+		return ""
+	}
+	if err := os.MkdirAll(p.bundleDir, 0700); err != nil {
+		log.Printf("[ERROR] Failed to create %s: %s", p.bundleDir, err)
+		return ""
+	}
+	f, err := os.CreateTemp(p.bundleDir, fmt.Sprintf("%s-*.json", bundle.Time.Format("20060102T150405Z")))
+	if err != nil {
+		log.Printf("[ERROR] Failed to create provider crash bundle: %s", err)
+		return ""
+	}
+	defer f.Close()
+	if _, err := f.Write(append(src, '\n')); err != nil {
+		log.Printf("[ERROR] Failed to write provider crash bundle %s: %s", f.Name(), err)
+		return ""
+	}
+	return f.Name()
+}
+
+// valueSchema returns the schema of the named value of the given request,
+// or nil if it's unknown.
+func (p *crashRecoveringProvider) valueSchema(req providerCrashRequest, name string) *configschema.Block {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.schema == nil {
+		return nil
+	}
+	switch {
+	case name == "provider_meta":
+		return p.schema.ProviderMeta.Block
+	case name != "config":
+		return p.schema.ResourceTypes[req.TypeName].Block
+	case req.Method == "ValidateProviderConfig" || req.Method == "ConfigureProvider":
+		return p.schema.Provider.Block
+	case req.Method == "ValidateDataResourceConfig" || req.Method == "ReadDataSource":
+		return p.schema.DataSources[req.TypeName].Block
+	default:
+		return p.schema.ResourceTypes[req.TypeName].Block
+	}
+}
+
+// crashBundleValue returns the JSON representation of the given value for a
+// diagnostics bundle, with unknown values replaced by null. Marked values,
+// and the values of attributes that the given schema, if any, declares as
+// sensitive or ephemeral, are replaced by a placeholder.
+func crashBundleValue(val cty.Value, schema *configschema.Block) json.RawMessage {
+	if val == cty.NilVal || val.IsNull() {
+		return json.RawMessage("null")
+	}
+	val, pvm := val.UnmarkDeepWithPaths()
+	val = cty.UnknownAsNull(val)
+	if schema != nil && len(val.Type().TestConformance(schema.ImpliedType())) == 0 {
+		pvm = append(pvm, schema.ValueMarks(val, nil)...)
+		pvm = append(pvm, schema.EphemeralMarks(val, nil)...)
+	}
+	if len(pvm) > 0 {
+		val = crashBundleRedact(val, nil, pvm)
+	}
+	src, err := ctyjson.SimpleJSONValue{Value: val}.MarshalJSON()
+	if err != nil {
+		return json.RawMessage(strconv.Quote(fmt.Sprintf("<invalid value: %s>", err)))
+	}
+	return src
+}
+
+// crashBundleRedact returns the given value with the values at the given
+// marked paths replaced by a placeholder string. Because that changes their
+// types, collections are returned as tuples and objects, which have the same
+// JSON representation.
+func crashBundleRedact(val cty.Value, path cty.Path, pvm []cty.PathValueMarks) cty.Value {
+	for _, m := range pvm {
+		if m.Path.Equals(path) {
+			if _, ok := m.Marks[marks.Ephemeral]; ok {
+				return cty.StringVal("(ephemeral value)")
+			}
+			return cty.StringVal("(sensitive value)")
+		}
+	}
+	if val.IsNull() {
+		return val
+	}
+
+	ty := val.Type()
+	switch {
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		var elems []cty.Value
+		for it := val.ElementIterator(); it.Next(); {
+			key, elem := it.Element()
+			if ty.IsSetType() {
+				key = elem
+			}
+			elems = append(elems, crashBundleRedact(elem, path.Index(key), pvm))
+		}
+		return cty.TupleVal(elems)
+	case ty.IsMapType() || ty.IsObjectType():
+		attrs := make(map[string]cty.Value)
+		for it := val.ElementIterator(); it.Next(); {
+			key, elem := it.Element()
+			elemPath := path.Index(key)
+			if ty.IsObjectType() {
+				elemPath = path.GetAttr(key.AsString())
+			}
+			attrs[key.AsString()] = crashBundleRedact(elem, elemPath, pvm)
+		}
+		return cty.ObjectVal(attrs)
+	default:
+		return val
+	}
+}
+
+// waitForPluginExit waits up to the given time for the process of the given
+// provider plugin to exit, so that all of its output has been received.
+func waitForPluginExit(instance providers.Interface, timeout time.Duration) {
+	var client *plugin.Client
+	switch instance := instance.(type) {
+	case *tfplugin.GRPCProvider:
+		client = instance.PluginClient
+	case *tfplugin6.GRPCProvider:
+		client = instance.PluginClient
+	}
+	if client == nil {
+		return
+	}
+	for deadline := time.Now().Add(timeout); !client.Exited() && time.Now().Before(deadline); {
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func (p *crashRecoveringProvider) crashError(req providerCrashRequest, bundle string, restarted bool) tfdiags.Diagnostic {
+	detail := fmt.Sprintf("The plugin for provider %s crashed while handling the %s call.", p.meta.Provider, req.Method)
+	if req.Method == "ApplyResourceChange" {
+		detail += " The provider might have partially changed the remote object before it crashed, so OpenTofu treats an object that it was updating as tainted, to be replaced in the next run, and doesn't track an object that it was creating, which you might need to import."
+	}
+	if restarted {
+		detail += " OpenTofu started a new plugin process for the provider's other calls."
+	}
+	detail += crashBundleDetail(bundle)
+	return tfdiags.WholeContainingBodyWithExtra(tfdiags.Error, "Provider plugin crashed", detail, providers.PluginCrashed{})
+}
+
+func (p *crashRecoveringProvider) crashWarning(req providerCrashRequest, bundle string) tfdiags.Diagnostic {
+	detail := fmt.Sprintf("The plugin for provider %s crashed while handling the %s call, so OpenTofu started a new plugin process and retried the call.", p.meta.Provider, req.Method)
+	detail += crashBundleDetail(bundle)
+	return tfdiags.Sourceless(tfdiags.Warning, "Provider plugin crashed and was restarted", detail)
+}
+
+func crashBundleDetail(bundle string) string {
+	if bundle == "" {
+		return "\n\nThis is always a bug in the provider, which should be reported in the provider's own issue tracker."
+	}
+	return fmt.Sprintf("\n\nThis is always a bug in the provider, which should be reported in the provider's own issue tracker. OpenTofu saved the plugin's crash output and the request that crashed it in %s. Sensitive attributes are redacted from the request, but it might still include other confidential values, so review the file before sharing it.", bundle)
+}
+
+func (p *crashRecoveringProvider) GetProviderSchema() providers.GetProviderSchemaResponse {
+	resp := recoverCall(p, providerCrashRequest{Method: "GetProviderSchema"}, true,
+		func(instance providers.Interface) providers.GetProviderSchemaResponse {
+			return instance.GetProviderSchema()
+		},
+		func(resp *providers.GetProviderSchemaResponse) *tfdiags.Diagnostics { return &resp.Diagnostics },
+	)
+	if !resp.Diagnostics.HasErrors() {
+		p.mu.Lock()
+		p.schema = &resp
+		p.mu.Unlock()
+	}
+	return resp
+}
+
+func (p *crashRecoveringProvider) ValidateProviderConfig(req providers.ValidateProviderConfigRequest) providers.ValidateProviderConfigResponse {
+	return recoverCall(p, providerCrashRequest{
+		Method: "ValidateProviderConfig",
+		Values: map[string]cty.Value{"config": req.Config},
+	}, true,
+		func(instance providers.Interface) providers.ValidateProviderConfigResponse {
+			return instance.ValidateProviderConfig(req)
+		},
+		func(resp *providers.ValidateProviderConfigResponse) *tfdiags.Diagnostics { return &resp.Diagnostics },
+	)
+}
+
+func (p *crashRecoveringProvider) ValidateResourceConfig(req providers.ValidateResourceConfigRequest) providers.ValidateResourceConfigResponse {
+	return recoverCall(p, providerCrashRequest{
+		Method:   "ValidateResourceConfig",
+		TypeName: req.TypeName,
+		Values:   map[string]cty.Value{"config": req.Config},
+	}, true,
+		func(instance providers.Interface) providers.ValidateResourceConfigResponse {
+			return instance.ValidateResourceConfig(req)
+		},
+		func(resp *providers.ValidateResourceConfigResponse) *tfdiags.Diagnostics { return &resp.Diagnostics },
+	)
+}
+
+func (p *crashRecoveringProvider) ValidateDataResourceConfig(req providers.ValidateDataResourceConfigRequest) providers.ValidateDataResourceConfigResponse {
+	return recoverCall(p, providerCrashRequest{
+		Method:   "ValidateDataResourceConfig",
+		TypeName: req.TypeName,
+		Values:   map[string]cty.Value{"config": req.Config},
+	}, true,
+		func(instance providers.Interface) providers.ValidateDataResourceConfigResponse {
+			return instance.ValidateDataResourceConfig(req)
+		},
+		func(resp *providers.ValidateDataResourceConfigResponse) *tfdiags.Diagnostics {
+			return &resp.Diagnostics
+		},
+	)
+}
+
+func (p *crashRecoveringProvider) UpgradeResourceState(req providers.UpgradeResourceStateRequest) providers.UpgradeResourceStateResponse {
+	return recoverCall(p, providerCrashRequest{
+		Method:   "UpgradeResourceState",
+		TypeName: req.TypeName,
+	}, true,
+		func(instance providers.Interface) providers.UpgradeResourceStateResponse {
+			return instance.UpgradeResourceState(req)
+		},
+		func(resp *providers.UpgradeResourceStateResponse) *tfdiags.Diagnostics { return &resp.Diagnostics },
+	)
+}
+
+func (p *crashRecoveringProvider) ConfigureProvider(req providers.ConfigureProviderRequest) providers.ConfigureProviderResponse {
+	resp := recoverCall(p, providerCrashRequest{
+		Method: "ConfigureProvider",
+		Values: map[string]cty.Value{"config": req.Config},
+	}, true,
+		func(instance providers.Interface) providers.ConfigureProviderResponse {
+			return instance.ConfigureProvider(req)
+		},
+		func(resp *providers.ConfigureProviderResponse) *tfdiags.Diagnostics { return &resp.Diagnostics },
+	)
+	if !resp.Diagnostics.HasErrors() {
+		p.mu.Lock()
+		p.configured = &req
+		p.mu.Unlock()
+	}
+	return resp
+}
+
+func (p *crashRecoveringProvider) Stop() error {
+	return p.instance().Stop()
+}
+
+func (p *crashRecoveringProvider) ReadResource(req providers.ReadResourceRequest) providers.ReadResourceResponse {
+	return recoverCall(p, providerCrashRequest{
+		Method:   "ReadResource",
+		TypeName: req.TypeName,
+		Values: map[string]cty.Value{
+			"prior_state":   req.PriorState,
+			"provider_meta": req.ProviderMeta,
+		},
+	}, true,
+		func(instance providers.Interface) providers.ReadResourceResponse {
+			return instance.ReadResource(req)
+		},
+		func(resp *providers.ReadResourceResponse) *tfdiags.Diagnostics { return &resp.Diagnostics },
+	)
+}
+
+func (p *crashRecoveringProvider) PlanResourceChange(req providers.PlanResourceChangeRequest) providers.PlanResourceChangeResponse {
+	return recoverCall(p, providerCrashRequest{
+		Method:   "PlanResourceChange",
+		TypeName: req.TypeName,
+		Values: map[string]cty.Value{
+			"prior_state":        req.PriorState,
+			"proposed_new_state": req.ProposedNewState,
+			"config":             req.Config,
+			"provider_meta":      req.ProviderMeta,
+		},
+	}, true,
+		func(instance providers.Interface) providers.PlanResourceChangeResponse {
+			return instance.PlanResourceChange(req)
+		},
+		func(resp *providers.PlanResourceChangeResponse) *tfdiags.Diagnostics { return &resp.Diagnostics },
+	)
+}
+
+func (p *crashRecoveringProvider) ApplyResourceChange(req providers.ApplyResourceChangeRequest) providers.ApplyResourceChangeResponse {
+	// The provider might have changed the remote object before it crashed,
+	// so applying the change again could apply it twice.
+	return recoverCall(p, providerCrashRequest{
+		Method:   "ApplyResourceChange",
+		TypeName: req.TypeName,
+		Values: map[string]cty.Value{
+			"prior_state":   req.PriorState,
+			"planned_state": req.PlannedState,
+			"config":        req.Config,
+			"provider_meta": req.ProviderMeta,
+		},
+	}, false,
+		func(instance providers.Interface) providers.ApplyResourceChangeResponse {
+			return instance.ApplyResourceChange(req)
+		},
+		func(resp *providers.ApplyResourceChangeResponse) *tfdiags.Diagnostics { return &resp.Diagnostics },
+	)
+}
+
+func (p *crashRecoveringProvider) ImportResourceState(req providers.ImportResourceStateRequest) providers.ImportResourceStateResponse {
+	return recoverCall(p, providerCrashRequest{
+		Method:   "ImportResourceState",
+		TypeName: req.TypeName,
+		Values:   map[string]cty.Value{"id": cty.StringVal(req.ID)},
+	}, true,
+		func(instance providers.Interface) providers.ImportResourceStateResponse {
+			return instance.ImportResourceState(req)
+		},
+		func(resp *providers.ImportResourceStateResponse) *tfdiags.Diagnostics { return &resp.Diagnostics },
+	)
+}
+
+func (p *crashRecoveringProvider) ListResources(req providers.ListResourcesRequest) providers.ListResourcesResponse {
+	return recoverCall(p, providerCrashRequest{
+		Method:   "ListResources",
+		TypeName: req.TypeName,
+	}, true,
+		func(instance providers.Interface) providers.ListResourcesResponse {
+			return instance.ListResources(req)
+		},
+		func(resp *providers.ListResourcesResponse) *tfdiags.Diagnostics { return &resp.Diagnostics },
+	)
+}
+
+func (p *crashRecoveringProvider) ReadDataSource(req providers.ReadDataSourceRequest) providers.ReadDataSourceResponse {
+	return recoverCall(p, providerCrashRequest{
+		Method:   "ReadDataSource",
+		TypeName: req.TypeName,
+		Values: map[string]cty.Value{
+			"config":        req.Config,
+			"provider_meta": req.ProviderMeta,
+		},
+	}, true,
+		func(instance providers.Interface) providers.ReadDataSourceResponse {
+			return instance.ReadDataSource(req)
+		},
+		func(resp *providers.ReadDataSourceResponse) *tfdiags.Diagnostics { return &resp.Diagnostics },
+	)
+}
+
+func (p *crashRecoveringProvider) CallFunction(req providers.CallFunctionRequest) providers.CallFunctionResponse {
+	// Function calls report errors without diagnostics, so we can't
+	// recognize a crash.
+	return p.instance().CallFunction(req)
+}
+
+func (p *crashRecoveringProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.dead {
+		// The crashed instance was already closed.
+		return nil
+	}
+	return p.current.Close()
+}
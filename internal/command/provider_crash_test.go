@@ -0,0 +1,159 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/getproviders"
+	"github.com/opentofu/opentofu/internal/lang/marks"
+	"github.com/opentofu/opentofu/internal/providercache"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+func TestCrashRecoveringProvider(t *testing.T) {
+	oldRetries := providerCrashRetries
+	providerCrashRetries = 1
+	t.Cleanup(func() {
+		providerCrashRetries = oldRetries
+	})
+
+	crash := func() tfdiags.Diagnostics {
+		var diags tfdiags.Diagnostics
+		return diags.Append(tfdiags.WholeContainingBodyWithExtra(tfdiags.Error, "Plugin did not respond", "", providers.PluginCrashed{}))
+	}
+	var started []*tofu.MockProvider
+	factory := func() (providers.Interface, error) {
+		p := testProvider()
+		if len(started) == 0 {
+			// The first instance crashes on every call.
+			p.ReadResourceFn = func(providers.ReadResourceRequest) providers.ReadResourceResponse {
+				return providers.ReadResourceResponse{Diagnostics: crash()}
+			}
+			p.ApplyResourceChangeFn = func(providers.ApplyResourceChangeRequest) providers.ApplyResourceChangeResponse {
+				return providers.ApplyResourceChangeResponse{Diagnostics: crash()}
+			}
+		}
+		started = append(started, p)
+		return p, nil
+	}
+	bundleDir := t.TempDir()
+	meta := &providercache.CachedProvider{
+		Provider:   addrs.NewDefaultProvider("test"),
+		Version:    getproviders.MustParseVersion("1.0.0"),
+		PackageDir: t.TempDir(),
+	}
+	provider, err := crashRecoveringProviderFactory(meta, bundleDir, factory)()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := providers.ConfigureProviderRequest{
+		TerraformVersion: "1.0.0",
+		Config:           cty.ObjectVal(map[string]cty.Value{"region": cty.StringVal("a")}),
+	}
+	if resp := provider.ConfigureProvider(config); resp.Diagnostics.HasErrors() {
+		t.Fatal(resp.Diagnostics.Err())
+	}
+
+	// A read is retried on a new, configured, instance.
+	prior := cty.ObjectVal(map[string]cty.Value{"id": cty.StringVal("foo")})
+	resp := provider.ReadResource(providers.ReadResourceRequest{TypeName: "test_instance", PriorState: prior})
+	if resp.Diagnostics.HasErrors() {
+		t.Fatalf("unexpected errors: %s", resp.Diagnostics.Err())
+	}
+	if len(resp.Diagnostics) != 1 || resp.Diagnostics[0].Severity() != tfdiags.Warning {
+		t.Errorf("expected a warning about the crash, got %#v", resp.Diagnostics)
+	}
+	if !resp.NewState.RawEquals(prior) {
+		t.Errorf("wrong new state %#v", resp.NewState)
+	}
+	if len(started) != 2 {
+		t.Fatalf("started %d instances; want 2", len(started))
+	}
+	if !started[1].ConfigureProviderCalled || !started[1].ConfigureProviderRequest.Config.RawEquals(config.Config) {
+		t.Error("the new instance wasn't configured like the crashed one")
+	}
+	if !started[0].CloseCalled {
+		t.Error("the crashed instance wasn't closed")
+	}
+
+	bundles, err := filepath.Glob(filepath.Join(bundleDir, "*.json"))
+	if err != nil || len(bundles) != 1 {
+		t.Fatalf("wrong bundles %v: %v", bundles, err)
+	}
+	src, err := os.ReadFile(bundles[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bundle providerCrashBundle
+	if err := json.Unmarshal(src, &bundle); err != nil {
+		t.Fatal(err)
+	}
+	if bundle.Provider != "registry.opentofu.org/hashicorp/test" || bundle.Version != "1.0.0" || bundle.Method != "ReadResource" || bundle.TypeName != "test_instance" {
+		t.Errorf("wrong bundle\n%s", src)
+	}
+	var gotPrior map[string]string
+	if err := json.Unmarshal(bundle.Request["prior_state"], &gotPrior); err != nil || gotPrior["id"] != "foo" {
+		t.Errorf("wrong prior_state in bundle\n%s", src)
+	}
+
+	// The retry limit is reached, so the next crash is reported, and a
+	// change isn't applied again in case the crash happened after the
+	// provider changed the remote object.
+	started[1].ApplyResourceChangeFn = func(providers.ApplyResourceChangeRequest) providers.ApplyResourceChangeResponse {
+		return providers.ApplyResourceChangeResponse{Diagnostics: crash()}
+	}
+	applyResp := provider.ApplyResourceChange(providers.ApplyResourceChangeRequest{TypeName: "test_instance"})
+	if !providers.PluginCrashedIn(applyResp.Diagnostics) {
+		t.Fatalf("expected a crash error, got %#v", applyResp.Diagnostics)
+	}
+	if len(started) != 2 {
+		t.Errorf("started %d instances; want 2", len(started))
+	}
+	if !providerCrashed(provider) {
+		t.Error("provider isn't reported as crashed")
+	}
+}
+
+func TestCrashBundleValue(t *testing.T) {
+	schema := &configschema.Block{
+		Attributes: map[string]*configschema.Attribute{
+			"id":       {Type: cty.String, Computed: true},
+			"password": {Type: cty.String, Optional: true, Sensitive: true},
+			"token":    {Type: cty.String, Optional: true, Ephemeral: true},
+			"tags":     {Type: cty.List(cty.String), Optional: true},
+		},
+	}
+	val := cty.ObjectVal(map[string]cty.Value{
+		"id":       cty.UnknownVal(cty.String),
+		"password": cty.StringVal("hunter2"),
+		"token":    cty.StringVal("abc123"),
+		"tags": cty.ListVal([]cty.Value{
+			cty.StringVal("public"),
+			cty.StringVal("secret").Mark(marks.Sensitive),
+		}),
+	})
+
+	got := string(crashBundleValue(val, schema))
+	want := `{"id":null,"password":"(sensitive value)","tags":["public","(sensitive value)"],"token":"(ephemeral value)"}`
+	if got != want {
+		t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+	}
+
+	// Without a schema, only the marked values are redacted.
+	got = string(crashBundleValue(val, nil))
+	want = `{"id":null,"password":"hunter2","tags":["public","(sensitive value)"],"token":"abc123"}`
+	if got != want {
+		t.Errorf("wrong result without schema\ngot:  %s\nwant: %s", got, want)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/getproviders"
+	"github.com/opentofu/opentofu/internal/replacefile"
+)
+
+// providerFunctionDiskCache is a tofu.ProviderFunctionCache that keeps the
+// results of provider function calls in a directory, so that they can be
+// reused by later runs.
+//
+// Results are stored in one file for each provider version, so only the
+// providers whose versions are selected in the dependency lock file are
+// cached, and upgrading a provider starts again with an empty cache.
+type providerFunctionDiskCache struct {
+	dir      string
+	versions map[addrs.Provider]getproviders.Version
+
+	mu sync.Mutex
+	// loaded are the results read from disk, keyed by provider address and
+	// then by cache key. added are the results recorded since the cache was
+	// last saved.
+	loaded map[addrs.Provider]map[string]json.RawMessage
+	added  map[addrs.Provider]map[string]json.RawMessage
+}
+
+type providerFunctionCacheFile struct {
+	// Results maps each cache key to the result, encoded as JSON along with
+	// its type.
+	Results map[string]json.RawMessage `json:"results"`
+}
+
+func newProviderFunctionDiskCache(dir string, versions map[addrs.Provider]getproviders.Version) *providerFunctionDiskCache {
+	return &providerFunctionDiskCache{
+		dir:      dir,
+		versions: versions,
+		loaded:   make(map[addrs.Provider]map[string]json.RawMessage),
+		added:    make(map[addrs.Provider]map[string]json.RawMessage),
+	}
+}
+
+// providerFunctionCache returns the persistent cache of provider function
+// results to use, or nil if no cache directory is configured.
+func (m *Meta) providerFunctionCache() *providerFunctionDiskCache {
+	if m.ProviderFunctionCacheDir == "" {
+		return nil
+	}
+	locks, diags := m.lockedDependencies()
+	if diags.HasErrors() {
+		return nil
+	}
+	versions := make(map[addrs.Provider]getproviders.Version)
+	for addr, lock := range locks.AllProviders() {
+		versions[addr] = lock.Version()
+	}
+	return newProviderFunctionDiskCache(m.ProviderFunctionCacheDir, versions)
+}
+
+func (c *providerFunctionDiskCache) filename(addr addrs.Provider) (string, bool) {
+	version, ok := c.versions[addr]
+	if !ok {
+		return "", false
+	}
+	return filepath.Join(c.dir, addr.Hostname.ForDisplay(), addr.Namespace, addr.Type, version.String()+".json"), true
+}
+
+func (c *providerFunctionDiskCache) Get(addr addrs.Provider, key string) (cty.Value, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	results, ok := c.loaded[addr]
+	if !ok {
+		results = c.load(addr)
+		c.loaded[addr] = results
+	}
+	src, ok := results[key]
+	if !ok {
+		return cty.NilVal, false
+	}
+	result, err := ctyjson.Unmarshal(src, cty.DynamicPseudoType)
+	if err != nil {
+		log.Printf("[WARN] Ignoring invalid cached result of a %s function: %s", addr, err)
+		return cty.NilVal, false
+	}
+	return result, true
+}
+
+func (c *providerFunctionDiskCache) Put(addr addrs.Provider, key string, result cty.Value) {
+	if _, ok := c.versions[addr]; !ok || !result.IsWhollyKnown() || result.ContainsMarked() {
+		return
+	}
+	src, err := ctyjson.Marshal(result, cty.DynamicPseudoType)
+	if err != nil {
+		log.Printf("[WARN] Not caching the result of a %s function: %s", addr, err)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.added[addr] == nil {
+		c.added[addr] = make(map[string]json.RawMessage)
+	}
+	c.added[addr][key] = src
+}
+
+// Save writes the results added since the last save into the cache
+// directory, merging them with any results that other runs have written
+// there in the meantime.
+func (c *providerFunctionDiskCache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for addr, added := range c.added {
+		filename, _ := c.filename(addr)
+		results := c.load(addr)
+		for key, src := range added {
+			results[key] = src
+		}
+		src, err := json.Marshal(providerFunctionCacheFile{Results: results})
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(filename), os.ModePerm); err != nil {
+			return fmt.Errorf("failed to create the cache directory for %s: %w", addr, err)
+		}
+		if err := replacefile.AtomicWriteFile(filename, src, 0644); err != nil {
+			return fmt.Errorf("failed to save the cached results of %s functions: %w", addr, err)
+		}
+		c.loaded[addr] = results
+		delete(c.added, addr)
+	}
+	return nil
+}
+
+// load reads the cached results for the given provider, returning an empty
+// set of results if there are none or they can't be read.
+func (c *providerFunctionDiskCache) load(addr addrs.Provider) map[string]json.RawMessage {
+	results := make(map[string]json.RawMessage)
+	filename, ok := c.filename(addr)
+	if !ok {
+		return results
+	}
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("[WARN] Failed to read the cached results of %s functions: %s", addr, err)
+		}
+		return results
+	}
+	var file providerFunctionCacheFile
+	if err := json.Unmarshal(src, &file); err != nil {
+		log.Printf("[WARN] Ignoring invalid cached results of %s functions in %s: %s", addr, filename, err)
+		return results
+	}
+	for key, result := range file.Results {
+		results[key] = result
+	}
+	return results
+}
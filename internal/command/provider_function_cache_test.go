@@ -0,0 +1,68 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/getproviders"
+)
+
+func TestProviderFunctionDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	locked := addrs.NewDefaultProvider("test")
+	unlocked := addrs.NewDefaultProvider("other")
+	v1 := map[addrs.Provider]getproviders.Version{
+		locked: getproviders.MustParseVersion("1.0.0"),
+	}
+	result := cty.ObjectVal(map[string]cty.Value{
+		"name":  cty.StringVal("hello"),
+		"count": cty.NumberIntVal(2),
+	})
+
+	cache := newProviderFunctionDiskCache(dir, v1)
+	if _, ok := cache.Get(locked, "parse a"); ok {
+		t.Fatal("empty cache has a result")
+	}
+	cache.Put(locked, "parse a", result)
+	cache.Put(unlocked, "parse a", result)
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another run with the same provider version reuses the saved result,
+	// and merges its own results with it.
+	cache = newProviderFunctionDiskCache(dir, v1)
+	got, ok := cache.Get(locked, "parse a")
+	if !ok {
+		t.Fatal("saved result is not cached")
+	}
+	if !got.RawEquals(result) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, result)
+	}
+	if _, ok := cache.Get(unlocked, "parse a"); ok {
+		t.Error("result of a provider without a locked version was cached")
+	}
+	cache.Put(locked, "parse b", cty.StringVal("b"))
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+	cache = newProviderFunctionDiskCache(dir, v1)
+	for _, key := range []string{"parse a", "parse b"} {
+		if _, ok := cache.Get(locked, key); !ok {
+			t.Errorf("result for %q is not cached", key)
+		}
+	}
+
+	// Results of other versions of the provider are not used.
+	cache = newProviderFunctionDiskCache(dir, map[addrs.Provider]getproviders.Version{
+		locked: getproviders.MustParseVersion("2.0.0"),
+	})
+	if _, ok := cache.Get(locked, "parse a"); ok {
+		t.Error("result of a different provider version was used")
+	}
+}
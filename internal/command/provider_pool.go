@@ -0,0 +1,289 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"log"
+	"os"
+	"sync"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/providers"
+)
+
+// The TF_DISABLE_PROVIDER_POOLING environment variable makes OpenTofu start
+// a new provider plugin process each time it needs a provider instance, as
+// it did before provider pooling, in case a provider misbehaves when reused.
+var enableProviderPooling = os.Getenv("TF_DISABLE_PROVIDER_POOLING") == ""
+
+// providerPool keeps the provider plugin instances that a command has
+// finished with, so that later phases of the same command, such as the
+// apply phase after the plan phase, can reuse them rather than starting new
+// plugin processes.
+//
+// Instances used with a provider configuration are only reused with the
+// same provider configuration, so that instances configured for different
+// configurations of the same provider, such as with different aliases or in
+// different modules, are never mixed up. A reused instance remembers the
+// configuration it was last configured with, and isn't configured again
+// with an identical configuration, which saves repeating whatever work the
+// provider does to authenticate. Instances used for anything else, such as
+// reading schemas, are kept separately.
+//
+// Idle instances are left running until OpenTofu exits, when go-plugin
+// stops all of the plugin processes that it started.
+type providerPool struct {
+	mu sync.Mutex
+
+	// idle are the idle instances, keyed by the string representation of
+	// the address of the provider configuration they were used with, or of
+	// the provider itself for instances used without a configuration.
+	idle map[string][]*pooledProviderInstance
+
+	// factories are the factories passed to factory, which configFactories
+	// also uses.
+	factories map[addrs.Provider]providers.Factory
+}
+
+func newProviderPool() *providerPool {
+	return &providerPool{
+		idle:      make(map[string][]*pooledProviderInstance),
+		factories: make(map[addrs.Provider]providers.Factory),
+	}
+}
+
+// providerPool returns the pool of provider instances for the current
+// command.
+func (m *Meta) providerPool() *providerPool {
+	if m.providerInstances == nil {
+		m.providerInstances = newProviderPool()
+	}
+	return m.providerInstances
+}
+
+// factory wraps the given factory for the given provider so that it reuses
+// an idle instance from the pool if there is one, and so that closing the
+// instances it returns returns them to the pool. The instances are for uses
+// that don't involve a provider configuration; configFactories returns the
+// factories for those that do.
+func (p *providerPool) factory(addr addrs.Provider, factory providers.Factory) providers.Factory {
+	if !enableProviderPooling {
+		return factory
+	}
+	p.mu.Lock()
+	p.factories[addr] = factory
+	p.mu.Unlock()
+	return func() (providers.Interface, error) {
+		return p.acquire(addr.String(), factory)
+	}
+}
+
+// configFactories returns the factories for instances of each of the
+// providers passed to factory for use with particular provider
+// configurations.
+func (p *providerPool) configFactories() map[addrs.Provider]providers.ConfigFactory {
+	if !enableProviderPooling {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ret := make(map[addrs.Provider]providers.ConfigFactory, len(p.factories))
+	for provider, factory := range p.factories {
+		factory := factory
+		ret[provider] = func(addr addrs.AbsProviderConfig) (providers.Interface, error) {
+			return p.acquire(addr.String(), factory)
+		}
+	}
+	return ret
+}
+
+func (p *providerPool) acquire(key string, factory providers.Factory) (providers.Interface, error) {
+	p.mu.Lock()
+	var instance *pooledProviderInstance
+	if idle := p.idle[key]; len(idle) > 0 {
+		instance = idle[len(idle)-1]
+		p.idle[key] = idle[:len(idle)-1]
+	}
+	p.mu.Unlock()
+
+	if instance != nil {
+		log.Printf("[TRACE] providerPool: reusing an instance for %s", key)
+	} else {
+		provider, err := factory()
+		if err != nil {
+			return nil, err
+		}
+		instance = &pooledProviderInstance{Interface: provider}
+	}
+	return &pooledProvider{
+		pooledProviderInstance: instance,
+		pool:                   p,
+		key:                    key,
+	}, nil
+}
+
+func (p *providerPool) release(key string, instance *pooledProviderInstance) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.idle[key] = append(p.idle[key], instance)
+}
+
+// pooledProviderInstance is a provider instance that the pool manages.
+type pooledProviderInstance struct {
+	providers.Interface
+
+	// configured is the most recent request that configured the instance
+	// successfully, if any.
+	configured *providers.ConfigureProviderRequest
+}
+
+// pooledProvider is a use of a pooled instance, from when the pool provides
+// it until the user closes it and all of the calls made through it have
+// returned.
+type pooledProvider struct {
+	*pooledProviderInstance
+	pool *providerPool
+	key  string
+
+	mu       sync.Mutex
+	calls    int
+	stopped  bool
+	closed   bool
+	released bool
+}
+
+var _ providers.Interface = (*pooledProvider)(nil)
+
+// begin records the start of a call to the instance, and returns a function
+// that records its end.
+//
+// OpenTofu can stop waiting for a call, such as when a change exceeds its
+// operation timeout, and close the provider while the call is still
+// running. The instance is then only returned to the pool once the call
+// returns, so that it can't be used for anything else in the meantime.
+func (p *pooledProvider) begin() func() {
+	p.mu.Lock()
+	p.calls++
+	p.mu.Unlock()
+	return func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.calls--
+		if p.calls == 0 && p.closed {
+			p.finishClose()
+		}
+	}
+}
+
+func (p *pooledProvider) GetProviderSchema() providers.GetProviderSchemaResponse {
+	defer p.begin()()
+	return p.Interface.GetProviderSchema()
+}
+
+func (p *pooledProvider) ValidateProviderConfig(req providers.ValidateProviderConfigRequest) providers.ValidateProviderConfigResponse {
+	defer p.begin()()
+	return p.Interface.ValidateProviderConfig(req)
+}
+
+func (p *pooledProvider) ValidateResourceConfig(req providers.ValidateResourceConfigRequest) providers.ValidateResourceConfigResponse {
+	defer p.begin()()
+	return p.Interface.ValidateResourceConfig(req)
+}
+
+func (p *pooledProvider) ValidateDataResourceConfig(req providers.ValidateDataResourceConfigRequest) providers.ValidateDataResourceConfigResponse {
+	defer p.begin()()
+	return p.Interface.ValidateDataResourceConfig(req)
+}
+
+func (p *pooledProvider) UpgradeResourceState(req providers.UpgradeResourceStateRequest) providers.UpgradeResourceStateResponse {
+	defer p.begin()()
+	return p.Interface.UpgradeResourceState(req)
+}
+
+func (p *pooledProvider) ConfigureProvider(req providers.ConfigureProviderRequest) providers.ConfigureProviderResponse {
+	defer p.begin()()
+	if prev := p.configured; prev != nil && prev.TerraformVersion == req.TerraformVersion && prev.Config.RawEquals(req.Config) {
+		log.Printf("[TRACE] providerPool: %s is already configured with the same configuration", p.key)
+		return providers.ConfigureProviderResponse{}
+	}
+	resp := p.Interface.ConfigureProvider(req)
+	if resp.Diagnostics.HasErrors() {
+		p.configured = nil
+	} else {
+		p.configured = &req
+	}
+	return resp
+}
+
+func (p *pooledProvider) ReadResource(req providers.ReadResourceRequest) providers.ReadResourceResponse {
+	defer p.begin()()
+	return p.Interface.ReadResource(req)
+}
+
+func (p *pooledProvider) PlanResourceChange(req providers.PlanResourceChangeRequest) providers.PlanResourceChangeResponse {
+	defer p.begin()()
+	return p.Interface.PlanResourceChange(req)
+}
+
+func (p *pooledProvider) ApplyResourceChange(req providers.ApplyResourceChangeRequest) providers.ApplyResourceChangeResponse {
+	defer p.begin()()
+	return p.Interface.ApplyResourceChange(req)
+}
+
+func (p *pooledProvider) ImportResourceState(req providers.ImportResourceStateRequest) providers.ImportResourceStateResponse {
+	defer p.begin()()
+	return p.Interface.ImportResourceState(req)
+}
+
+func (p *pooledProvider) ListResources(req providers.ListResourcesRequest) providers.ListResourcesResponse {
+	defer p.begin()()
+	return p.Interface.ListResources(req)
+}
+
+func (p *pooledProvider) ReadDataSource(req providers.ReadDataSourceRequest) providers.ReadDataSourceResponse {
+	defer p.begin()()
+	return p.Interface.ReadDataSource(req)
+}
+
+func (p *pooledProvider) CallFunction(req providers.CallFunctionRequest) providers.CallFunctionResponse {
+	defer p.begin()()
+	return p.Interface.CallFunction(req)
+}
+
+func (p *pooledProvider) Stop() error {
+	// OpenTofu makes no more calls to a provider after stopping it, and
+	// the provider might not be usable any more, so we won't reuse it.
+	p.mu.Lock()
+	p.stopped = true
+	p.mu.Unlock()
+	return p.Interface.Stop()
+}
+
+func (p *pooledProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	if p.calls > 0 {
+		log.Printf("[TRACE] providerPool: %s was closed with %d calls still running", p.key, p.calls)
+		return nil
+	}
+	return p.finishClose()
+}
+
+// finishClose returns the instance to the pool, or closes it if it can't
+// be reused. The caller must hold p.mu, and there must be no calls running.
+func (p *pooledProvider) finishClose() error {
+	if p.released {
+		return nil
+	}
+	p.released = true
+	if p.stopped || providerCrashed(p.Interface) {
+		return p.Interface.Close()
+	}
+	p.pool.release(p.key, p.pooledProviderInstance)
+	return nil
+}
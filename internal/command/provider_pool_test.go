@@ -0,0 +1,172 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+func TestProviderPool(t *testing.T) {
+	addr := addrs.NewDefaultProvider("test")
+	var started []*tofu.MockProvider
+	var configureCalls int
+	factory := func() (providers.Interface, error) {
+		p := testProvider()
+		p.ConfigureProviderFn = func(providers.ConfigureProviderRequest) providers.ConfigureProviderResponse {
+			configureCalls++
+			return providers.ConfigureProviderResponse{}
+		}
+		started = append(started, p)
+		return p, nil
+	}
+	pool := newProviderPool()
+	pooled := pool.factory(addr, factory)
+	config := providers.ConfigureProviderRequest{
+		TerraformVersion: "1.0.0",
+		Config:           cty.ObjectVal(map[string]cty.Value{"region": cty.StringVal("a")}),
+	}
+
+	// The first phase starts an instance and configures it.
+	first, err := pooled()
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.ConfigureProvider(config)
+	if err := first.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second phase reuses the instance, which is already configured.
+	second, err := pooled()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second.ConfigureProvider(config)
+	if len(started) != 1 {
+		t.Fatalf("started %d instances; want 1", len(started))
+	}
+	if configureCalls != 1 {
+		t.Fatalf("configured %d times; want 1", configureCalls)
+	}
+	if started[0].CloseCalled {
+		t.Fatal("pooled instance was closed")
+	}
+
+	// While the second phase is using the instance, another use needs a
+	// new instance.
+	third, err := pooled()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(started) != 2 {
+		t.Fatalf("started %d instances; want 2", len(started))
+	}
+	third.Close()
+
+	// A different configuration configures the instance again.
+	config.Config = cty.ObjectVal(map[string]cty.Value{"region": cty.StringVal("b")})
+	second.ConfigureProvider(config)
+	if configureCalls != 2 {
+		t.Fatalf("configured %d times; want 2", configureCalls)
+	}
+
+	// A stopped instance isn't reused.
+	second.Stop()
+	second.Close()
+	second.Close()
+	if !started[0].CloseCalled {
+		t.Fatal("stopped instance was not closed")
+	}
+	if len(pool.idle[addr.String()]) != 1 {
+		t.Fatalf("pool has %d idle instances; want 1", len(pool.idle[addr.String()]))
+	}
+}
+
+func TestProviderPool_configs(t *testing.T) {
+	addr := addrs.NewDefaultProvider("test")
+	var started int
+	pool := newProviderPool()
+	pool.factory(addr, func() (providers.Interface, error) {
+		started++
+		return testProvider(), nil
+	})
+	configFactory := pool.configFactories()[addr]
+	defaultConfig := addrs.AbsProviderConfig{Provider: addr, Module: addrs.RootModule}
+	aliasConfig := addrs.AbsProviderConfig{Provider: addr, Module: addrs.RootModule, Alias: "other"}
+
+	first, err := configFactory(defaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Close()
+
+	// An instance used with one configuration isn't reused for another
+	// configuration of the same provider.
+	other, err := configFactory(aliasConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.Close()
+	if started != 2 {
+		t.Fatalf("started %d instances; want 2", started)
+	}
+
+	// Each configuration gets back its own instance.
+	again, err := configFactory(defaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.(*pooledProvider).pooledProviderInstance != first.(*pooledProvider).pooledProviderInstance {
+		t.Fatal("configuration didn't get its own instance back")
+	}
+	again.Close()
+	if started != 2 {
+		t.Fatalf("started %d instances; want 2", started)
+	}
+}
+
+func TestProviderPool_abandonedCall(t *testing.T) {
+	addr := addrs.NewDefaultProvider("test")
+	p := testProvider()
+	p.ConfigureProviderCalled = true
+	callStarted := make(chan struct{})
+	finishCall := make(chan struct{})
+	p.ApplyResourceChangeFn = func(providers.ApplyResourceChangeRequest) providers.ApplyResourceChangeResponse {
+		close(callStarted)
+		<-finishCall
+		return providers.ApplyResourceChangeResponse{}
+	}
+	pool := newProviderPool()
+	pooled := pool.factory(addr, providers.FactoryFixed(p))
+
+	instance, err := pooled()
+	if err != nil {
+		t.Fatal(err)
+	}
+	callDone := make(chan struct{})
+	go func() {
+		instance.ApplyResourceChange(providers.ApplyResourceChangeRequest{})
+		close(callDone)
+	}()
+	<-callStarted
+
+	// The caller gave up waiting for the call and closed the provider, but
+	// the instance isn't reused until the call returns.
+	instance.Close()
+	if got := len(pool.idle[addr.String()]); got != 0 {
+		t.Fatalf("instance was returned to the pool with a call still running")
+	}
+
+	close(finishCall)
+	<-callDone
+	if got := len(pool.idle[addr.String()]); got != 1 {
+		t.Fatalf("pool has %d idle instances after the call returned; want 1", got)
+	}
+}
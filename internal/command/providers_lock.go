@@ -4,9 +4,12 @@
 package command
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"net/url"
 	"os"
+	"sync"
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/depsfile"
@@ -23,6 +26,10 @@ const (
 	providersLockChangeTypeNewHashes   providersLockChangeType = "providersLockChangeTypeNewHashes"
 )
 
+// providersLockParallelism is the number of provider packages that
+// "tofu providers lock" fetches concurrently.
+const providersLockParallelism = 8
+
 // ProvidersLockCommand is a Command implementation that implements the
 // "tofu providers lock" command, which creates or updates the current
 // configuration's dependency lock file using information from upstream
@@ -42,9 +49,11 @@ func (c *ProvidersLockCommand) Run(args []string) int {
 	var optPlatforms FlagStringSlice
 	var fsMirrorDir string
 	var netMirrorURL string
+	var check bool
 	cmdFlags.Var(&optPlatforms, "platform", "target platform")
 	cmdFlags.StringVar(&fsMirrorDir, "fs-mirror", "", "filesystem mirror directory")
 	cmdFlags.StringVar(&netMirrorURL, "net-mirror", "", "network mirror base URL")
+	cmdFlags.BoolVar(&check, "check", false, "check")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
@@ -97,9 +106,11 @@ func (c *ProvidersLockCommand) Run(args []string) int {
 	// subsequent "tofu init" calls can then verify the local mirror
 	// against the upstream checksums.
 	var source getproviders.Source
+	var sourceDesc string
 	switch {
 	case fsMirrorDir != "":
 		source = getproviders.NewFilesystemMirrorSource(fsMirrorDir)
+		sourceDesc = "fs-mirror:" + fsMirrorDir
 	case netMirrorURL != "":
 		u, err := url.Parse(netMirrorURL)
 		if err != nil || u.Scheme != "https" {
@@ -112,12 +123,15 @@ func (c *ProvidersLockCommand) Run(args []string) int {
 			return 1
 		}
 		source = getproviders.NewHTTPMirrorSource(u, c.Services.CredentialsSource())
+		sourceDesc = "net-mirror:" + netMirrorURL
 	default:
 		// With no special options we consult upstream registries directly,
 		// because that gives us the most information to produce as complete
 		// and portable as possible a lock entry.
 		source = getproviders.NewRegistrySource(c.Services)
+		sourceDesc = "registry"
 	}
+	source = getproviders.NewTrustedKeysSource(source, c.ProviderTrustedKeys)
 
 	config, confDiags := c.loadConfig(".")
 	diags = diags.Append(confDiags)
@@ -184,78 +198,78 @@ func (c *ProvidersLockCommand) Run(args []string) int {
 	// but will verify the packages against the hashes we found upstream.
 
 	// Because our Installer abstraction is a per-platform idea, we'll
-	// instantiate one for each of the platforms the user requested, and then
-	// merge all of the generated locks together at the end.
+	// instantiate one for each combination of provider and platform, run
+	// them concurrently, and then merge all of the generated locks together
+	// at the end. All of the installers share a memoizing source, so that we
+	// query the available versions and package metadata only once and
+	// select consistent versions across the platforms.
+	//
+	// We record each result in a progress file as soon as we have it, so
+	// that re-running the command after a failure or interruption doesn't
+	// fetch the same packages again.
+	progress, err := loadProvidersLockProgress(c.DataDir(), sourceDesc)
+	if err != nil {
+		// The progress file is only an optimization, so we'll just start
+		// over if we can't read it.
+		log.Printf("[WARN] Ignoring providers lock progress: %s", err)
+	}
+	source = getproviders.NewMemoizeSource(source)
+
+	// We'll use any results from the progress file first, and then fetch
+	// the remaining packages concurrently.
+	type lockJob struct {
+		provider    addrs.Provider
+		constraints getproviders.VersionConstraints
+		platform    getproviders.Platform
+	}
+	var jobs []lockJob
 	updatedLocks := map[getproviders.Platform]*depsfile.Locks{}
 	selectedVersions := map[addrs.Provider]getproviders.Version{}
 	for _, platform := range platforms {
-		tempDir, err := os.MkdirTemp("", "terraform-providers-lock")
-		if err != nil {
-			diags = diags.Append(tfdiags.Sourceless(
-				tfdiags.Error,
-				"Could not create temporary directory",
-				fmt.Sprintf("Failed to create a temporary directory for staging the requested provider packages: %s.", err),
-			))
-			break
+		updatedLocks[platform] = depsfile.NewLocks()
+	}
+	for provider, constraints := range reqs {
+		var lockedVersion getproviders.Version
+		if oldLock := oldLocks.Provider(provider); oldLock != nil {
+			lockedVersion = oldLock.Version()
 		}
-		defer os.RemoveAll(tempDir)
-
-		evts := &providercache.InstallerEvents{
-			// Our output from this command is minimal just to show that
-			// we're making progress, rather than just silently hanging.
-			FetchPackageBegin: func(provider addrs.Provider, version getproviders.Version, loc getproviders.PackageLocation) {
-				c.Ui.Output(fmt.Sprintf("- Fetching %s %s for %s...", provider.ForDisplay(), version, platform))
-				if prevVersion, exists := selectedVersions[provider]; exists && version != prevVersion {
-					// This indicates a weird situation where we ended up
-					// selecting a different version for one platform than
-					// for another. We won't be able to merge the result
-					// in that case, so we'll generate an error.
-					//
-					// This could potentially happen if there's a provider
-					// we've not previously recorded in the lock file and
-					// the available versions change while we're running. To
-					// avoid that would require pre-locking all of the
-					// providers, which is complicated to do with the building
-					// blocks we have here, and so we'll wait to do it only
-					// if this situation arises often in practice.
-					diags = diags.Append(tfdiags.Sourceless(
-						tfdiags.Error,
-						"Inconsistent provider versions",
-						fmt.Sprintf(
-							"The version constraint for %s selected inconsistent versions for different platforms, which is unexpected.\n\nThe upstream registry may have changed its available versions during OpenTofu's work. If so, re-running this command may produce a successful result.",
-							provider,
-						),
-					))
-				}
-				selectedVersions[provider] = version
-			},
-			FetchPackageSuccess: func(provider addrs.Provider, version getproviders.Version, localDir string, auth *getproviders.PackageAuthenticationResult) {
-				var keyID string
-				if auth != nil && auth.Signed() {
-					keyID = auth.KeyID
-				}
-				if keyID != "" {
-					keyID = c.Colorize().Color(fmt.Sprintf(", key ID [reset][bold]%s[reset]", keyID))
-				}
-				c.Ui.Output(fmt.Sprintf("- Retrieved %s %s for %s (%s%s)", provider.ForDisplay(), version, platform, auth, keyID))
-			},
+
+		for _, platform := range platforms {
+			if lock := progress.Lock(provider, platform, constraints, lockedVersion); lock != nil {
+				c.Ui.Output(fmt.Sprintf("- Using previously-retrieved %s %s for %s", provider.ForDisplay(), lock.Version(), platform))
+				updatedLocks[platform].SetProvider(provider, lock.Version(), lock.VersionConstraints(), lock.AllHashes())
+				selectedVersions[provider] = lock.Version()
+				continue
+			}
+			jobs = append(jobs, lockJob{provider, constraints, platform})
 		}
-		ctx := evts.OnContext(ctx)
+	}
 
-		dir := providercache.NewDirWithPlatform(tempDir, platform)
-		installer := providercache.NewInstaller(dir, source)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, providersLockParallelism)
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job lockJob) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
 
-		newLocks, err := installer.EnsureProviderVersions(ctx, oldLocks, reqs, providercache.InstallNewProvidersForce)
-		if err != nil {
-			diags = diags.Append(tfdiags.Sourceless(
-				tfdiags.Error,
-				"Could not retrieve providers for locking",
-				fmt.Sprintf("OpenTofu failed to fetch the requested providers for %s in order to calculate their checksums: %s.", platform, err),
-			))
-			break
-		}
-		updatedLocks[platform] = newLocks
+			lock, moreDiags := c.lockProvider(ctx, source, oldLocks, job.provider, job.constraints, job.platform, &mu, selectedVersions)
+
+			mu.Lock()
+			defer mu.Unlock()
+			diags = diags.Append(moreDiags)
+			if lock == nil {
+				return
+			}
+			updatedLocks[job.platform].SetProvider(job.provider, lock.Version(), lock.VersionConstraints(), lock.AllHashes())
+			if err := progress.Record(job.platform, lock); err != nil {
+				log.Printf("[WARN] Failed to record providers lock progress: %s", err)
+			}
+		}(job)
 	}
+	wg.Wait()
 
 	// If we have any error diagnostics from installation then we won't
 	// proceed to merging and updating the lock file on disk.
@@ -328,6 +342,27 @@ func (c *ProvidersLockCommand) Run(args []string) int {
 		newLocks.SetProvider(provider, version, constraints, hashes)
 	}
 
+	// The progress file has served its purpose once we have all of the
+	// results, regardless of whether we'll write them to the lock file.
+	if err := progress.Remove(); err != nil {
+		log.Printf("[WARN] Failed to remove providers lock progress: %s", err)
+	}
+
+	if check {
+		if madeAnyChange {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Dependency lock file is incomplete",
+				"The dependency lock file does not include all of the checksums for the selected providers and platforms, as listed above. Run \"tofu providers lock\" without the -check option to update it.",
+			))
+			c.showDiagnostics(diags)
+			return 1
+		}
+		c.showDiagnostics(diags)
+		c.Ui.Output(c.Colorize().Color("\n[bold][green]Success![reset] [bold]The lock file includes all of the checksums for the selected providers and platforms.[reset]"))
+		return 0
+	}
+
 	moreDiags = c.replaceLockedDependencies(newLocks)
 	diags = diags.Append(moreDiags)
 
@@ -345,6 +380,88 @@ func (c *ProvidersLockCommand) Run(args []string) int {
 	return 0
 }
 
+// lockProvider installs a single provider for a single platform into a
+// temporary directory, returning the resulting lock with the checksums for
+// that platform's package.
+//
+// lockProvider may be called concurrently, and so it holds the given mutex
+// while it produces output or updates selectedVersions.
+func (c *ProvidersLockCommand) lockProvider(ctx context.Context, source getproviders.Source, oldLocks *depsfile.Locks, provider addrs.Provider, constraints getproviders.VersionConstraints, platform getproviders.Platform, mu *sync.Mutex, selectedVersions map[addrs.Provider]getproviders.Version) (*depsfile.ProviderLock, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	tempDir, err := os.MkdirTemp("", "terraform-providers-lock")
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Could not create temporary directory",
+			fmt.Sprintf("Failed to create a temporary directory for staging the requested provider packages: %s.", err),
+		))
+		return nil, diags
+	}
+	defer os.RemoveAll(tempDir)
+
+	evts := &providercache.InstallerEvents{
+		// Our output from this command is minimal just to show that
+		// we're making progress, rather than just silently hanging.
+		FetchPackageBegin: func(provider addrs.Provider, version getproviders.Version, loc getproviders.PackageLocation) {
+			mu.Lock()
+			defer mu.Unlock()
+			c.Ui.Output(fmt.Sprintf("- Fetching %s %s for %s...", provider.ForDisplay(), version, platform))
+			if prevVersion, exists := selectedVersions[provider]; exists && version != prevVersion {
+				// This indicates a weird situation where we ended up
+				// selecting a different version for one platform than
+				// for another. We won't be able to merge the result
+				// in that case, so we'll generate an error.
+				//
+				// Because all of the installers share a memoizing source,
+				// this could now happen only if the results recorded in the
+				// progress file were for a version which is no longer the
+				// newest matching the version constraints.
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Inconsistent provider versions",
+					fmt.Sprintf(
+						"The version constraint for %s selected inconsistent versions for different platforms, which is unexpected.\n\nThe upstream registry may have changed its available versions during OpenTofu's work. If so, re-running this command may produce a successful result.",
+						provider,
+					),
+				))
+			}
+			selectedVersions[provider] = version
+		},
+		FetchPackageSuccess: func(provider addrs.Provider, version getproviders.Version, localDir string, auth *getproviders.PackageAuthenticationResult) {
+			var keyID string
+			if auth != nil && auth.Signed() {
+				keyID = auth.KeyID
+			}
+			if keyID != "" {
+				keyID = c.Colorize().Color(fmt.Sprintf(", key ID [reset][bold]%s[reset]", keyID))
+				if auth.KeyOrigin != "" {
+					keyID += fmt.Sprintf(" from %s", auth.KeyOrigin)
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			c.Ui.Output(fmt.Sprintf("- Retrieved %s %s for %s (%s%s)", provider.ForDisplay(), version, platform, auth, keyID))
+		},
+	}
+	ctx = evts.OnContext(ctx)
+
+	dir := providercache.NewDirWithPlatform(tempDir, platform)
+	installer := providercache.NewInstaller(dir, source)
+	installer.SetDownloadLimits(c.ProviderDownloadLimits)
+
+	newLocks, err := installer.EnsureProviderVersions(ctx, oldLocks, getproviders.Requirements{provider: constraints}, providercache.InstallNewProvidersForce)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Could not retrieve providers for locking",
+			fmt.Sprintf("OpenTofu failed to fetch the requested providers for %s in order to calculate their checksums: %s.", platform, err),
+		))
+		return nil, diags
+	}
+	return newLocks.Provider(provider), diags
+}
+
 func (c *ProvidersLockCommand) Help() string {
 	return `
 Usage: tofu [global options] providers lock [options] [providers...]
@@ -369,6 +486,11 @@ Usage: tofu [global options] providers lock [options] [providers...]
 
 Options:
 
+  -check             Check that the lock file already includes all of the
+                     checksums for the selected providers and platforms,
+                     without modifying it. Exits with a nonzero status if
+                     any are missing.
+
   -fs-mirror=dir     Consult the given filesystem mirror directory instead
                      of the origin registry for each of the given providers.
 
@@ -0,0 +1,137 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/depsfile"
+	"github.com/opentofu/opentofu/internal/getproviders"
+)
+
+// providersLockProgressFilename is the name of the file in the data directory
+// where "tofu providers lock" records the checksums it has already obtained,
+// so that a run that fails or is interrupted partway can be resumed without
+// fetching the same packages again.
+const providersLockProgressFilename = "providers-lock-progress.json"
+
+// providersLockProgress is the content of the progress file. Each of the
+// results is for one combination of provider and platform.
+//
+// The progress file is valid only for the same package source, and is
+// removed after the command has completed successfully.
+type providersLockProgress struct {
+	Source  string                      `json:"source"`
+	Results []providersLockProgressItem `json:"results"`
+
+	filename string
+}
+
+type providersLockProgressItem struct {
+	Provider    string   `json:"provider"`
+	Platform    string   `json:"platform"`
+	Version     string   `json:"version"`
+	Constraints string   `json:"constraints"`
+	Hashes      []string `json:"hashes"`
+}
+
+// loadProvidersLockProgress reads the progress file from the given data
+// directory, returning an empty progress if there is no progress file or if
+// it was recorded for a different package source.
+func loadProvidersLockProgress(dataDir, source string) (*providersLockProgress, error) {
+	ret := &providersLockProgress{
+		Source:   source,
+		filename: filepath.Join(dataDir, providersLockProgressFilename),
+	}
+
+	src, err := os.ReadFile(ret.filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return ret, nil
+	}
+	if err != nil {
+		return ret, err
+	}
+
+	var prev providersLockProgress
+	if err := json.Unmarshal(src, &prev); err != nil {
+		return ret, fmt.Errorf("invalid progress file %s: %w", ret.filename, err)
+	}
+	if prev.Source == source {
+		ret.Results = prev.Results
+	}
+	return ret, nil
+}
+
+// Lock returns the previously-obtained lock for the given provider and
+// platform, or nil if there is none which is consistent with the given
+// version constraints and, if it is set, the given already-locked version.
+func (p *providersLockProgress) Lock(provider addrs.Provider, platform getproviders.Platform, constraints getproviders.VersionConstraints, lockedVersion getproviders.Version) *depsfile.ProviderLock {
+	for _, item := range p.Results {
+		if item.Provider != provider.String() || item.Platform != platform.String() {
+			continue
+		}
+		if item.Constraints != getproviders.VersionConstraintsString(constraints) {
+			return nil
+		}
+		version, err := getproviders.ParseVersion(item.Version)
+		if err != nil || (lockedVersion != getproviders.UnspecifiedVersion && !version.Same(lockedVersion)) {
+			return nil
+		}
+		hashes := make([]getproviders.Hash, 0, len(item.Hashes))
+		for _, raw := range item.Hashes {
+			hash, err := getproviders.ParseHash(raw)
+			if err != nil {
+				return nil
+			}
+			hashes = append(hashes, hash)
+		}
+		return depsfile.NewProviderLock(provider, version, constraints, hashes)
+	}
+	return nil
+}
+
+// Record adds the lock obtained for the given platform to the progress and
+// saves the progress file.
+func (p *providersLockProgress) Record(platform getproviders.Platform, lock *depsfile.ProviderLock) error {
+	item := providersLockProgressItem{
+		Provider:    lock.Provider().String(),
+		Platform:    platform.String(),
+		Version:     lock.Version().String(),
+		Constraints: getproviders.VersionConstraintsString(lock.VersionConstraints()),
+	}
+	for _, hash := range lock.AllHashes() {
+		item.Hashes = append(item.Hashes, hash.String())
+	}
+
+	results := p.Results[:0:0]
+	for _, existing := range p.Results {
+		if existing.Provider != item.Provider || existing.Platform != item.Platform {
+			results = append(results, existing)
+		}
+	}
+	p.Results = append(results, item)
+
+	src, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p.filename), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(p.filename, src, 0644)
+}
+
+// Remove deletes the progress file, once it is no longer needed.
+func (p *providersLockProgress) Remove() error {
+	err := os.Remove(p.filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
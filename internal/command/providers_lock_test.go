@@ -112,6 +112,130 @@ func runProviderLockGenericTest(t *testing.T, testDirectory, expected string) {
 	}
 }
 
+// setupProvidersLockMultiPlatform prepares the basic fixture in a temporary
+// working directory, with the fixture's package in the filesystem mirror for
+// each of the given platforms.
+func setupProvidersLockMultiPlatform(t *testing.T, platforms ...string) {
+	t.Helper()
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("providers-lock/basic"), td)
+	t.Cleanup(testChdir(t, td))
+
+	versionDir := filepath.Join(td, "fs-mirror/registry.opentofu.org/hashicorp/test/1.0.0")
+	for _, platform := range platforms {
+		testCopyDir(t, filepath.Join(versionDir, "os_arch"), filepath.Join(versionDir, platform))
+	}
+	if err := os.RemoveAll(filepath.Join(versionDir, "os_arch")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProvidersLock_check(t *testing.T) {
+	setupProvidersLockMultiPlatform(t, "linux_amd64", "darwin_arm64")
+
+	args := []string{"-fs-mirror=fs-mirror", "-platform=linux_amd64", "-platform=darwin_arm64"}
+	newCommand := func(ui *cli.MockUi) *ProvidersLockCommand {
+		return &ProvidersLockCommand{
+			Meta: Meta{
+				Ui:               ui,
+				testingOverrides: metaOverridesForProvider(testProvider()),
+			},
+		}
+	}
+
+	ui := new(cli.MockUi)
+	if code := newCommand(ui).Run(append([]string{"-check"}, args...)); code != 1 {
+		t.Fatalf("wrong exit code %d; want 1\n%s", code, ui.OutputWriter.String())
+	}
+	if got := ui.ErrorWriter.String(); !strings.Contains(got, "Dependency lock file is incomplete") {
+		t.Fatalf("missing error\n%s", got)
+	}
+	if _, err := os.Stat(".terraform.lock.hcl"); !os.IsNotExist(err) {
+		t.Fatalf("lock file was created with -check")
+	}
+
+	ui = new(cli.MockUi)
+	if code := newCommand(ui).Run(args); code != 0 {
+		t.Fatalf("wrong exit code %d; want 0\n%s", code, ui.ErrorWriter.String())
+	}
+	for _, platform := range []string{"linux_amd64", "darwin_arm64"} {
+		want := "- Retrieved hashicorp/test 1.0.0 for " + platform
+		if got := ui.OutputWriter.String(); !strings.Contains(got, want) {
+			t.Fatalf("output does not include %q\n%s", want, got)
+		}
+	}
+
+	ui = new(cli.MockUi)
+	if code := newCommand(ui).Run(append([]string{"-check"}, args...)); code != 0 {
+		t.Fatalf("wrong exit code %d; want 0\n%s", code, ui.ErrorWriter.String())
+	}
+	if got := ui.OutputWriter.String(); !strings.Contains(got, "The lock file includes all of the checksums") {
+		t.Fatalf("missing success message\n%s", got)
+	}
+}
+
+func TestProvidersLock_resume(t *testing.T) {
+	setupProvidersLockMultiPlatform(t, "linux_amd64", "darwin_arm64")
+
+	// A previous run already retrieved the package for one of the platforms
+	// before it failed.
+	progress := `{
+  "source": "fs-mirror:fs-mirror",
+  "results": [
+    {
+      "provider": "registry.opentofu.org/hashicorp/test",
+      "platform": "darwin_arm64",
+      "version": "1.0.0",
+      "constraints": "",
+      "hashes": ["h1:previous"]
+    }
+  ]
+}`
+	if err := os.MkdirAll(".terraform", 0755); err != nil {
+		t.Fatal(err)
+	}
+	progressFile := filepath.Join(".terraform", providersLockProgressFilename)
+	if err := os.WriteFile(progressFile, []byte(progress), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ui := new(cli.MockUi)
+	c := &ProvidersLockCommand{
+		Meta: Meta{
+			Ui:               ui,
+			testingOverrides: metaOverridesForProvider(testProvider()),
+		},
+	}
+	code := c.Run([]string{"-fs-mirror=fs-mirror", "-platform=linux_amd64", "-platform=darwin_arm64"})
+	if code != 0 {
+		t.Fatalf("wrong exit code %d; want 0\n%s", code, ui.ErrorWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+	if !strings.Contains(output, "- Using previously-retrieved hashicorp/test 1.0.0 for darwin_arm64") {
+		t.Fatalf("previous result was not used\n%s", output)
+	}
+	if strings.Contains(output, "- Fetching hashicorp/test 1.0.0 for darwin_arm64") {
+		t.Fatalf("package was fetched again\n%s", output)
+	}
+	if !strings.Contains(output, "- Fetching hashicorp/test 1.0.0 for linux_amd64") {
+		t.Fatalf("remaining package was not fetched\n%s", output)
+	}
+
+	lockfile, err := os.ReadFile(".terraform.lock.hcl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"h1:previous"`, `"h1:7MjN4eFisdTv4tlhXH5hL4QQd39Jy4baPhFxwAd/EFE="`} {
+		if !strings.Contains(string(lockfile), want) {
+			t.Fatalf("lock file does not include %s\n%s", want, lockfile)
+		}
+	}
+	if _, err := os.Stat(progressFile); !os.IsNotExist(err) {
+		t.Fatalf("progress file was not removed after success")
+	}
+}
+
 func TestProvidersLock_args(t *testing.T) {
 
 	t.Run("mirror collision", func(t *testing.T) {
@@ -4,6 +4,7 @@
 package command
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -13,6 +14,7 @@ import (
 	"github.com/apparentlymart/go-versions/versions"
 	"github.com/hashicorp/go-getter"
 
+	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/getproviders"
 	"github.com/opentofu/opentofu/internal/httpclient"
 	"github.com/opentofu/opentofu/internal/tfdiags"
@@ -108,8 +110,9 @@ func (c *ProvidersMirrorCommand) Run(args []string) int {
 	// for every provider so that it can be used to update a local mirror
 	// directory without needing to first disable that local mirror
 	// in the CLI configuration.
-	source := getproviders.NewMemoizeSource(
-		getproviders.NewRegistrySource(c.Services),
+	source := getproviders.NewTrustedKeysSource(
+		getproviders.NewMemoizeSource(getproviders.NewRegistrySource(c.Services)),
+		c.ProviderTrustedKeys,
 	)
 
 	// Providers from registries always use HTTP, so we don't need the full
@@ -170,77 +173,11 @@ func (c *ProvidersMirrorCommand) Run(args []string) int {
 		}
 		for _, platform := range platforms {
 			c.Ui.Output(fmt.Sprintf("  - Downloading package for %s...", platform.String()))
-			meta, err := source.PackageMeta(ctx, provider, selected, platform)
-			if err != nil {
-				diags = diags.Append(tfdiags.Sourceless(
-					tfdiags.Error,
-					"Provider release not available",
-					fmt.Sprintf("Failed to download %s v%s for %s: %s.", provider.String(), selected.String(), platform.String(), err),
-				))
-				continue
-			}
-			urlStr, ok := meta.Location.(getproviders.PackageHTTPURL)
-			if !ok {
-				// We don't expect to get non-HTTP locations here because we're
-				// using the registry source, so this seems like a bug in the
-				// registry source.
-				diags = diags.Append(tfdiags.Sourceless(
-					tfdiags.Error,
-					"Provider release not available",
-					fmt.Sprintf("Failed to download %s v%s for %s: OpenTofu's provider registry client returned unexpected location type %T. This is a bug in OpenTofu.", provider.String(), selected.String(), platform.String(), meta.Location),
-				))
-				continue
-			}
-			urlObj, err := url.Parse(string(urlStr))
-			if err != nil {
-				// We don't expect to get non-HTTP locations here because we're
-				// using the registry source, so this seems like a bug in the
-				// registry source.
-				diags = diags.Append(tfdiags.Sourceless(
-					tfdiags.Error,
-					"Invalid URL for provider release",
-					fmt.Sprintf("The origin registry for %s returned an invalid URL for v%s on %s: %s.", provider.String(), selected.String(), platform.String(), err),
-				))
-				continue
-			}
-			// targetPath is the path where we ultimately want to place the
-			// downloaded archive, but we'll place it initially at stagingPath
-			// so we can verify its checksums and signatures before making
-			// it discoverable to mirror clients. (stagingPath intentionally
-			// does not follow the filesystem mirror file naming convention.)
-			targetPath := meta.PackedFilePath(outputDir)
-			stagingPath := filepath.Join(filepath.Dir(targetPath), "."+filepath.Base(targetPath))
-			err = httpGetter.GetFile(stagingPath, urlObj)
-			if err != nil {
-				diags = diags.Append(tfdiags.Sourceless(
-					tfdiags.Error,
-					"Cannot download provider release",
-					fmt.Sprintf("Failed to download %s v%s for %s: %s.", provider.String(), selected.String(), platform.String(), err),
-				))
-				continue
-			}
-			if meta.Authentication != nil {
-				result, err := meta.Authentication.AuthenticatePackage(getproviders.PackageLocalArchive(stagingPath))
-				if err != nil {
-					diags = diags.Append(tfdiags.Sourceless(
-						tfdiags.Error,
-						"Invalid provider package",
-						fmt.Sprintf("Failed to authenticate %s v%s for %s: %s.", provider.String(), selected.String(), platform.String(), err),
-					))
-					continue
-				}
+			_, result, moreDiags := mirrorProviderPackage(ctx, source, httpGetter, provider, selected, platform, outputDir)
+			diags = diags.Append(moreDiags)
+			if result != nil {
 				c.Ui.Output(fmt.Sprintf("  - Package authenticated: %s", result))
 			}
-			os.Remove(targetPath) // okay if it fails because we're going to try to rename over it next anyway
-			err = os.Rename(stagingPath, targetPath)
-			if err != nil {
-				diags = diags.Append(tfdiags.Sourceless(
-					tfdiags.Error,
-					"Cannot download provider release",
-					fmt.Sprintf("Failed to place %s package into mirror directory: %s.", provider.String(), err),
-				))
-				continue
-			}
 		}
 	}
 
@@ -351,6 +288,90 @@ func (c *ProvidersMirrorCommand) Run(args []string) int {
 	return 0
 }
 
+// mirrorProviderPackage downloads the package for the given version of a
+// provider on the given platform from the given source, which must return
+// HTTP locations, and places it into the packed layout of a filesystem mirror
+// in outputDir after authenticating it.
+//
+// It returns the path of the package file and the result of authenticating it,
+// which is nil if the source didn't return a way to authenticate it.
+func mirrorProviderPackage(ctx context.Context, source getproviders.Source, httpGetter getter.HttpGetter, provider addrs.Provider, version getproviders.Version, platform getproviders.Platform, outputDir string) (string, *getproviders.PackageAuthenticationResult, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	meta, err := source.PackageMeta(ctx, provider, version, platform)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Provider release not available",
+			fmt.Sprintf("Failed to download %s v%s for %s: %s.", provider.String(), version.String(), platform.String(), err),
+		))
+		return "", nil, diags
+	}
+	urlStr, ok := meta.Location.(getproviders.PackageHTTPURL)
+	if !ok {
+		// We don't expect to get non-HTTP locations here because we're
+		// using the registry source, so this seems like a bug in the
+		// registry source.
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Provider release not available",
+			fmt.Sprintf("Failed to download %s v%s for %s: OpenTofu's provider registry client returned unexpected location type %T. This is a bug in OpenTofu.", provider.String(), version.String(), platform.String(), meta.Location),
+		))
+		return "", nil, diags
+	}
+	urlObj, err := url.Parse(string(urlStr))
+	if err != nil {
+		// We don't expect to get non-HTTP locations here because we're
+		// using the registry source, so this seems like a bug in the
+		// registry source.
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid URL for provider release",
+			fmt.Sprintf("The origin registry for %s returned an invalid URL for v%s on %s: %s.", provider.String(), version.String(), platform.String(), err),
+		))
+		return "", nil, diags
+	}
+	// targetPath is the path where we ultimately want to place the
+	// downloaded archive, but we'll place it initially at stagingPath
+	// so we can verify its checksums and signatures before making
+	// it discoverable to mirror clients. (stagingPath intentionally
+	// does not follow the filesystem mirror file naming convention.)
+	targetPath := meta.PackedFilePath(outputDir)
+	stagingPath := filepath.Join(filepath.Dir(targetPath), "."+filepath.Base(targetPath))
+	err = httpGetter.GetFile(stagingPath, urlObj)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Cannot download provider release",
+			fmt.Sprintf("Failed to download %s v%s for %s: %s.", provider.String(), version.String(), platform.String(), err),
+		))
+		return "", nil, diags
+	}
+	var result *getproviders.PackageAuthenticationResult
+	if meta.Authentication != nil {
+		result, err = meta.Authentication.AuthenticatePackage(getproviders.PackageLocalArchive(stagingPath))
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid provider package",
+				fmt.Sprintf("Failed to authenticate %s v%s for %s: %s.", provider.String(), version.String(), platform.String(), err),
+			))
+			return "", nil, diags
+		}
+	}
+	os.Remove(targetPath) // okay if it fails because we're going to try to rename over it next anyway
+	err = os.Rename(stagingPath, targetPath)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Cannot download provider release",
+			fmt.Sprintf("Failed to place %s package into mirror directory: %s.", provider.String(), err),
+		))
+		return "", nil, diags
+	}
+	return targetPath, result, diags
+}
+
 func (c *ProvidersMirrorCommand) Help() string {
 	return `
 Usage: tofu [global options] providers mirror [options] <target-dir>
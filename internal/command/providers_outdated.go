@@ -0,0 +1,254 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/apparentlymart/go-versions/versions"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/depsfile"
+	"github.com/opentofu/opentofu/internal/getproviders"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// ProvidersOutdatedCommand is a Command implementation that implements the
+// "tofu providers outdated" command, which compares the provider versions
+// selected in the dependency lock file with the versions available from the
+// provider installation sources.
+type ProvidersOutdatedCommand struct {
+	Meta
+}
+
+func (c *ProvidersOutdatedCommand) Synopsis() string {
+	return "Show providers that have newer versions available"
+}
+
+// providerOutdatedEntry describes the available upgrades for one of the
+// providers in the dependency lock file.
+type providerOutdatedEntry struct {
+	Provider addrs.Provider
+	Locked   getproviders.Version
+
+	// Wanted is the newest available version that the configuration's
+	// version constraints allow, which "tofu init -upgrade" would select.
+	Wanted getproviders.Version
+
+	// Latest is the newest available release, regardless of the version
+	// constraints.
+	Latest getproviders.Version
+
+	// Notes are indications that upgrading might need attention, such as
+	// a new major version or warnings from the registry.
+	Notes []string
+}
+
+func (c *ProvidersOutdatedCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("providers outdated")
+	var outFile string
+	cmdFlags.StringVar(&outFile, "out", "", "out")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	if len(cmdFlags.Args()) > 0 {
+		c.Ui.Error("The providers outdated command expects no arguments.\n")
+		cmdFlags.Usage()
+		return 1
+	}
+
+	var diags tfdiags.Diagnostics
+
+	config, confDiags := c.loadConfig(".")
+	diags = diags.Append(confDiags)
+	if confDiags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+	reqs, hclDiags := config.ProviderRequirements()
+	diags = diags.Append(hclDiags)
+	locks, moreDiags := c.lockedDependencies()
+	diags = diags.Append(moreDiags)
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	ctx, done := c.InterruptibleContext(c.CommandContext())
+	defer done()
+
+	// We use the same installation sources that "tofu init" would use, so
+	// that the versions we report are the ones that an upgrade could
+	// actually select.
+	source := c.providerInstallSource()
+	proposed := locks.DeepCopy()
+
+	providerLocks := locks.AllProviders()
+	addrList := make([]addrs.Provider, 0, len(providerLocks))
+	for addr := range providerLocks {
+		if locks.ProviderIsOverridden(addr) {
+			continue
+		}
+		addrList = append(addrList, addr)
+	}
+	sort.Slice(addrList, func(i, j int) bool {
+		return addrList[i].LessThan(addrList[j])
+	})
+
+	var entries []providerOutdatedEntry
+	for _, addr := range addrList {
+		constraints, required := reqs[addr]
+		if !required {
+			// The provider is in the lock file but the configuration no
+			// longer needs it, so "tofu init" will remove it rather than
+			// upgrade it.
+			continue
+		}
+		available, warnings, err := source.AvailableVersions(ctx, addr)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Failed to query available provider versions",
+				fmt.Sprintf("Could not retrieve the list of available versions for provider %s: %s.", addr.ForDisplay(), err),
+			))
+			continue
+		}
+
+		statuses, err := getproviders.SourceVersionStatuses(ctx, source, addr)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Failed to query provider version statuses",
+				fmt.Sprintf("Could not retrieve which versions of provider %s are deprecated or yanked: %s.", addr.ForDisplay(), err),
+			))
+		}
+		// Yanked versions can't be selected, so they can't be upgrades.
+		selectable := make(getproviders.VersionList, 0, len(available))
+		for _, v := range available {
+			if statuses[v].Yanked == nil {
+				selectable = append(selectable, v)
+			}
+		}
+
+		entry := providerOutdatedEntry{
+			Provider: addr,
+			Locked:   providerLocks[addr].Version(),
+			Wanted:   selectable.NewestInSet(versions.MeetingConstraints(constraints)),
+			Latest:   selectable.NewestInSet(versions.Released),
+			Notes:    warnings,
+		}
+		lockedStatus := statuses[entry.Locked]
+		switch {
+		case lockedStatus.Yanked != nil:
+			entry.Notes = append(entry.Notes, fmt.Sprintf("the locked version has been yanked: %s", lockedStatus.Yanked))
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Locked provider version has been yanked",
+				fmt.Sprintf("The publisher of %s has yanked version %s, which the dependency lock file selects, so tofu init will fail until another version is selected: %s", addr.ForDisplay(), entry.Locked, lockedStatus.Yanked),
+			))
+		case lockedStatus.Deprecated != nil:
+			entry.Notes = append(entry.Notes, fmt.Sprintf("the locked version is deprecated: %s", lockedStatus.Deprecated))
+		}
+		if entry.Wanted.Same(versions.Unspecified) {
+			// No available version meets the constraints.
+			entry.Wanted = entry.Locked
+		}
+		if entry.Latest.Same(versions.Unspecified) {
+			// There are only prereleases.
+			entry.Latest = entry.Wanted
+		}
+		if entry.Latest.Major > entry.Locked.Major {
+			entry.Notes = append(entry.Notes, fmt.Sprintf("v%d is a new major version, which may include breaking changes", entry.Latest.Major))
+		}
+		if entry.Latest.GreaterThan(entry.Wanted) {
+			entry.Notes = append(entry.Notes, "the version constraints don't allow the latest version")
+		}
+		if entry.Wanted.GreaterThan(entry.Locked) {
+			if deprecated := statuses[entry.Wanted].Deprecated; deprecated != nil {
+				entry.Notes = append(entry.Notes, fmt.Sprintf("the wanted version is deprecated: %s", deprecated))
+			}
+		}
+		if !entry.Wanted.GreaterThan(entry.Locked) && !entry.Latest.GreaterThan(entry.Locked) &&
+			lockedStatus.Yanked == nil && lockedStatus.Deprecated == nil {
+			continue
+		}
+		entries = append(entries, entry)
+
+		if outFile != "" && entry.Wanted.GreaterThan(entry.Locked) {
+			// The proposed lock file includes whatever checksums the source
+			// can tell us without downloading the package, such as those
+			// from a registry's signed checksums document.
+			var hashes []getproviders.Hash
+			meta, err := source.PackageMeta(ctx, addr, entry.Wanted, getproviders.CurrentPlatform)
+			if err != nil {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Warning,
+					"Failed to retrieve provider checksums",
+					fmt.Sprintf("Could not retrieve the checksums for provider %s %s, so the proposed lock file has no checksums for it: %s.", addr.ForDisplay(), entry.Wanted, err),
+				))
+			} else {
+				hashes = meta.AcceptableHashes()
+			}
+			proposed.SetProvider(addr, entry.Wanted, constraints, hashes)
+		}
+	}
+
+	c.showDiagnostics(diags)
+
+	if len(entries) == 0 {
+		c.Ui.Output("All providers in the dependency lock file are at their latest versions.")
+	} else {
+		var buf bytes.Buffer
+		w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PROVIDER\tLOCKED\tWANTED\tLATEST\tNOTES")
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Provider.ForDisplay(), e.Locked, e.Wanted, e.Latest, strings.Join(e.Notes, "; "))
+		}
+		w.Flush()
+		c.Ui.Output(strings.TrimRight(buf.String(), "\n"))
+	}
+
+	if outFile != "" {
+		moreDiags := depsfile.SaveLocksToFile(proposed, outFile)
+		if moreDiags.HasErrors() {
+			c.showDiagnostics(moreDiags)
+			return 1
+		}
+		c.Ui.Output(fmt.Sprintf("\nWrote a proposed dependency lock file to %s. To use it, review it and then replace %s with it.", outFile, dependencyLockFilename))
+	}
+
+	return 0
+}
+
+func (c *ProvidersOutdatedCommand) Help() string {
+	return providersOutdatedCommandHelp
+}
+
+const providersOutdatedCommandHelp = `
+Usage: tofu [global options] providers outdated [options]
+
+  Compares the provider versions selected in the dependency lock file with
+  the versions available from the provider installation sources, and lists
+  the providers that have newer versions available.
+
+  For each provider, WANTED is the newest version that the configuration's
+  version constraints allow, which "tofu init -upgrade" would select, and
+  LATEST is the newest release. NOTES highlights upgrades that might need
+  attention, such as new major versions, and any warnings from the registry,
+  including locked versions that the publisher has deprecated or yanked.
+  Yanked versions are never WANTED or LATEST.
+
+Options:
+
+  -out=path   Write a proposed dependency lock file that selects the WANTED
+              version of each provider to the given path, for review. The
+              current dependency lock file is not changed.
+`
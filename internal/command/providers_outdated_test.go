@@ -0,0 +1,157 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/depsfile"
+	"github.com/opentofu/opentofu/internal/getproviders"
+)
+
+func TestProvidersOutdated(t *testing.T) {
+	td := t.TempDir()
+	defer testChdir(t, td)()
+
+	config := `
+terraform {
+  required_providers {
+    test = {
+      source  = "hashicorp/test"
+      version = "~> 1.0"
+    }
+  }
+}
+`
+	if err := os.WriteFile("main.tf", []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	addr := addrs.NewDefaultProvider("test")
+	locks := depsfile.NewLocks()
+	locks.SetProvider(addr, getproviders.MustParseVersion("1.2.3"), getproviders.MustParseVersionConstraints("~> 1.0"), nil)
+	if diags := depsfile.SaveLocksToFile(locks, dependencyLockFilename); diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+
+	source, close := newMockProviderSource(t, map[string][]string{
+		"hashicorp/test": {"1.2.3", "1.3.0", "2.0.0"},
+	})
+	defer close()
+
+	ui := cli.NewMockUi()
+	c := &ProvidersOutdatedCommand{
+		Meta: Meta{
+			Ui:             ui,
+			ProviderSource: source,
+		},
+	}
+	if code := c.Run([]string{"-out=proposed.lock.hcl"}); code != 0 {
+		t.Fatalf("unexpected failure\n%s", ui.ErrorWriter.String())
+	}
+
+	got := ui.OutputWriter.String()
+	for _, want := range []string{
+		"hashicorp/test  1.2.3   1.3.0   2.0.0",
+		"v2 is a new major version",
+		"the version constraints don't allow the latest version",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output doesn't contain %q\n%s", want, got)
+		}
+	}
+
+	proposed, diags := depsfile.LoadLocksFromFile("proposed.lock.hcl")
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	if got, want := proposed.Provider(addr).Version(), getproviders.MustParseVersion("1.3.0"); got != want {
+		t.Errorf("wrong proposed version %s; want %s", got, want)
+	}
+	current, diags := depsfile.LoadLocksFromFile(dependencyLockFilename)
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	if got, want := current.Provider(addr).Version(), getproviders.MustParseVersion("1.2.3"); got != want {
+		t.Errorf("current lock file was changed to %s; want %s", got, want)
+	}
+}
+
+func TestProvidersOutdated_versionStatuses(t *testing.T) {
+	td := t.TempDir()
+	defer testChdir(t, td)()
+
+	config := `
+terraform {
+  required_providers {
+    test = {
+      source  = "hashicorp/test"
+      version = "~> 1.0"
+    }
+  }
+}
+`
+	if err := os.WriteFile("main.tf", []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	addr := addrs.NewDefaultProvider("test")
+	locks := depsfile.NewLocks()
+	locks.SetProvider(addr, getproviders.MustParseVersion("1.2.3"), getproviders.MustParseVersionConstraints("~> 1.0"), nil)
+	if diags := depsfile.SaveLocksToFile(locks, dependencyLockFilename); diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+
+	source, close := newMockProviderSource(t, map[string][]string{
+		"hashicorp/test": {"1.2.3", "1.3.0", "1.4.0"},
+	})
+	defer close()
+
+	ui := cli.NewMockUi()
+	c := &ProvidersOutdatedCommand{
+		Meta: Meta{
+			Ui: ui,
+			ProviderSource: providersOutdatedStatusSource{
+				Source: source,
+				statuses: getproviders.VersionStatuses{
+					getproviders.MustParseVersion("1.2.3"): {
+						Deprecated: &getproviders.VersionNotice{Reason: "upgrade to 1.3.0"},
+					},
+					getproviders.MustParseVersion("1.4.0"): {
+						Yanked: &getproviders.VersionNotice{Reason: "broken"},
+					},
+				},
+			},
+		},
+	}
+	if code := c.Run(nil); code != 0 {
+		t.Fatalf("unexpected failure\n%s", ui.ErrorWriter.String())
+	}
+
+	got := ui.OutputWriter.String()
+	for _, want := range []string{
+		// The yanked 1.4.0 is neither wanted nor latest.
+		"hashicorp/test  1.2.3   1.3.0   1.3.0",
+		"the locked version is deprecated: upgrade to 1.3.0",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output doesn't contain %q\n%s", want, got)
+		}
+	}
+}
+
+// providersOutdatedStatusSource is a provider source that reports fixed
+// version statuses.
+type providersOutdatedStatusSource struct {
+	getproviders.Source
+	statuses getproviders.VersionStatuses
+}
+
+func (s providersOutdatedStatusSource) VersionStatuses(context.Context, addrs.Provider) (getproviders.VersionStatuses, error) {
+	return s.statuses, nil
+}
@@ -0,0 +1,91 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// ProvidersOverridesCommand is a Command implementation that prints out the
+// provider development overrides that are in effect in the current working
+// directory and workspace.
+type ProvidersOverridesCommand struct {
+	Meta
+}
+
+func (c *ProvidersOverridesCommand) Help() string {
+	return providersOverridesCommandHelp
+}
+
+func (c *ProvidersOverridesCommand) Synopsis() string {
+	return "Show the provider development overrides in effect"
+}
+
+func (c *ProvidersOverridesCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("providers overrides")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	if len(cmdFlags.Args()) != 0 {
+		var diags tfdiags.Diagnostics
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Unexpected argument",
+			"The providers overrides command does not expect any positional arguments.",
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	workspace, err := c.Workspace()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error selecting workspace: %s", err))
+		return 1
+	}
+
+	overrides := c.activeProviderDevOverrides()
+	if len(overrides) == 0 {
+		c.Ui.Output(fmt.Sprintf("No provider development overrides are in effect in this working directory with workspace %q selected.", workspace))
+		return 0
+	}
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("The following provider development overrides are in effect in this working directory with workspace %q selected:\n\n", workspace))
+	for _, override := range overrides {
+		out.WriteString(fmt.Sprintf(" - %s in %s\n", override.Provider.ForDisplay(), override.Dir))
+		if scope := override.Scope; scope != nil {
+			var conditions []string
+			if len(scope.WorkingDirs) > 0 {
+				conditions = append(conditions, "working directories "+strings.Join(scope.WorkingDirs, ", "))
+			}
+			if len(scope.Workspaces) > 0 {
+				conditions = append(conditions, "workspaces "+strings.Join(scope.Workspaces, ", "))
+			}
+			out.WriteString(fmt.Sprintf("   (only in %s)\n", strings.Join(conditions, " and ")))
+		}
+	}
+	out.WriteString("\nOpenTofu uses these local builds of the providers instead of the versions selected in the dependency lock file.")
+	c.Ui.Output(out.String())
+	return 0
+}
+
+const providersOverridesCommandHelp = `
+Usage: tofu [global options] providers overrides
+
+  Prints the provider development overrides from the CLI configuration that
+  are in effect in the current working directory with the current workspace
+  selected.
+
+  Development overrides make OpenTofu use a local build of a provider instead
+  of the version selected in the dependency lock file. Overrides can be set
+  for all working directories, or limited to particular working directories
+  or workspaces using the working_dirs and workspaces arguments of a
+  dev_overrides block.
+`
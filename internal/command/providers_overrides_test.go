@@ -0,0 +1,90 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/command/cliconfig"
+	"github.com/opentofu/opentofu/internal/getproviders"
+)
+
+func TestProvidersOverrides(t *testing.T) {
+	td := t.TempDir()
+	defer testChdir(t, td)()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fooAddr := addrs.MustParseProviderSourceString("example.com/test/foo")
+	barAddr := addrs.MustParseProviderSourceString("example.com/test/bar")
+	meta := Meta{
+		ProviderDevOverrides: map[addrs.Provider]getproviders.PackageLocalDir{
+			fooAddr: "/tmp/foo",
+		},
+		ProviderScopedDevOverrides: []*cliconfig.ScopedDevOverrides{
+			{
+				WorkingDirs: []string{wd},
+				Workspaces:  []string{"dev"},
+				Overrides: map[addrs.Provider]getproviders.PackageLocalDir{
+					fooAddr: "/tmp/foo-dev",
+					barAddr: "/tmp/bar-dev",
+				},
+			},
+		},
+	}
+
+	t.Run("default workspace", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		m := meta
+		m.Ui = ui
+		c := &ProvidersOverridesCommand{Meta: m}
+		if code := c.Run(nil); code != 0 {
+			t.Fatalf("unexpected failure\n%s", ui.ErrorWriter.String())
+		}
+		got := ui.OutputWriter.String()
+		if !strings.Contains(got, "example.com/test/foo in /tmp/foo\n") {
+			t.Errorf("global override is missing from output\n%s", got)
+		}
+		if strings.Contains(got, "bar") {
+			t.Errorf("override scoped to another workspace is in output\n%s", got)
+		}
+	})
+	t.Run("scoped workspace", func(t *testing.T) {
+		t.Setenv(WorkspaceNameEnvVar, "dev")
+		ui := cli.NewMockUi()
+		m := meta
+		m.Ui = ui
+		c := &ProvidersOverridesCommand{Meta: m}
+		if code := c.Run(nil); code != 0 {
+			t.Fatalf("unexpected failure\n%s", ui.ErrorWriter.String())
+		}
+		got := ui.OutputWriter.String()
+		for _, want := range []string{
+			"example.com/test/bar in /tmp/bar-dev\n",
+			"example.com/test/foo in /tmp/foo-dev\n",
+			"(only in working directories " + wd + " and workspaces dev)",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("output doesn't contain %q\n%s", want, got)
+			}
+		}
+	})
+	t.Run("none", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		c := &ProvidersOverridesCommand{Meta: Meta{Ui: ui}}
+		if code := c.Run(nil); code != 0 {
+			t.Fatalf("unexpected failure\n%s", ui.ErrorWriter.String())
+		}
+		if got := ui.OutputWriter.String(); !strings.Contains(got, "No provider development overrides are in effect") {
+			t.Errorf("wrong output\n%s", got)
+		}
+	})
+}
@@ -4,9 +4,12 @@
 package command
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/backend"
 	"github.com/opentofu/opentofu/internal/command/arguments"
 	"github.com/opentofu/opentofu/internal/command/jsonprovider"
@@ -30,8 +33,14 @@ func (c *ProvidersSchemaCommand) Synopsis() string {
 func (c *ProvidersSchemaCommand) Run(args []string) int {
 	args = c.Meta.process(args)
 	cmdFlags := c.Meta.defaultFlagSet("providers schema")
-	var jsonOutput bool
+	var jsonOutput, compact bool
+	var providerFilter, typeFilter FlagStringSlice
+	var diffPath string
 	cmdFlags.BoolVar(&jsonOutput, "json", false, "produce JSON output")
+	cmdFlags.Var(&providerFilter, "provider", "provider source address")
+	cmdFlags.Var(&typeFilter, "type", "resource type or data source pattern")
+	cmdFlags.BoolVar(&compact, "compact", false, "omit documentation")
+	cmdFlags.StringVar(&diffPath, "diff", "", "path to previous output")
 
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
@@ -46,6 +55,26 @@ func (c *ProvidersSchemaCommand) Run(args []string) int {
 		return 1
 	}
 
+	var providerAddrs []string
+	for _, raw := range providerFilter {
+		addr, diags := addrs.ParseProviderSourceString(raw)
+		if diags.HasErrors() {
+			c.Ui.Error(fmt.Sprintf("Invalid provider address %q for -provider: %s", raw, diags.Err()))
+			return 1
+		}
+		providerAddrs = append(providerAddrs, addr.String())
+	}
+
+	var oldSchemas *jsonprovider.Providers
+	if diffPath != "" {
+		var err error
+		oldSchemas, err = c.loadProviderSchemasForDiff(diffPath)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to read the schemas to compare with: %s", err))
+			return 1
+		}
+	}
+
 	// Check for user-supplied plugin path
 	var err error
 	if c.pluginPath, err = c.loadPluginPath(); err != nil {
@@ -107,7 +136,31 @@ func (c *ProvidersSchemaCommand) Run(args []string) int {
 		return 1
 	}
 
-	jsonSchemas, err := jsonprovider.Marshal(schemas)
+	// The schemas we compare with get the same filtering, so that only the
+	// differences in the schemas we're interested in are reported.
+	newSchemas := jsonprovider.NewProviders(schemas)
+	for _, s := range []*jsonprovider.Providers{newSchemas, oldSchemas} {
+		if s == nil {
+			continue
+		}
+		if err := jsonprovider.Filter(s, providerAddrs, typeFilter); err != nil {
+			c.Ui.Error(fmt.Sprintf("Invalid -type option: %s", err))
+			return 1
+		}
+		if compact {
+			jsonprovider.Compact(s)
+		}
+	}
+	var output interface{} = newSchemas
+	if oldSchemas != nil {
+		output, err = jsonprovider.Diff(oldSchemas, newSchemas)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to compare provider schemas: %s", err))
+			return 1
+		}
+	}
+
+	jsonSchemas, err := json.Marshal(output)
 	if err != nil {
 		c.Ui.Error(fmt.Sprintf("Failed to marshal provider schemas to json: %s", err))
 		return 1
@@ -117,9 +170,47 @@ func (c *ProvidersSchemaCommand) Run(args []string) int {
 	return 0
 }
 
+// loadProviderSchemasForDiff reads the output of an earlier run of this
+// command, to compare the current schemas with.
+func (c *ProvidersSchemaCommand) loadProviderSchemasForDiff(path string) (*jsonprovider.Providers, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ret jsonprovider.Providers
+	if err := json.Unmarshal(src, &ret); err != nil {
+		return nil, fmt.Errorf("%s is not valid provider schema JSON: %w", path, err)
+	}
+	if !strings.HasPrefix(ret.FormatVersion, "1.") {
+		return nil, fmt.Errorf("%s has unsupported format version %q", path, ret.FormatVersion)
+	}
+	if ret.Schemas == nil {
+		ret.Schemas = make(map[string]*jsonprovider.Provider)
+	}
+	return &ret, nil
+}
+
 const providersSchemaCommandHelp = `
-Usage: tofu [global options] providers schema -json
+Usage: tofu [global options] providers schema -json [options]
 
   Prints out a json representation of the schemas for all providers used 
   in the current configuration.
+
+Options:
+
+  -provider=ADDR      Include only the schemas of the provider with the given
+                      source address, such as hashicorp/aws. Use this option
+                      multiple times to include several providers.
+
+  -type=PATTERN       Include only the resource types and data sources whose
+                      names match the given pattern, in which * matches any
+                      sequence of characters. Use this option multiple times
+                      to include several patterns.
+
+  -compact            Omit descriptions from the schemas.
+
+  -diff=FILE          Instead of the schemas, print the changes since the
+                      schemas in FILE, which was saved from an earlier run of
+                      this command. The same -provider, -type and -compact
+                      options are applied to the schemas in FILE.
 `
@@ -9,6 +9,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -103,6 +104,58 @@ func TestProvidersSchema_output(t *testing.T) {
 	}
 }
 
+func TestProvidersSchema_filterAndDiff(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("providers-schema/basic"), td)
+	defer testChdir(t, td)()
+
+	providerSource, close := newMockProviderSource(t, map[string][]string{
+		"test": {"1.2.3"},
+	})
+	defer close()
+
+	ui := new(cli.MockUi)
+	m := Meta{
+		testingOverrides: metaOverridesForProvider(providersSchemaFixtureProvider()),
+		Ui:               ui,
+		ProviderSource:   providerSource,
+	}
+	if code := (&InitCommand{Meta: m}).Run(nil); code != 0 {
+		t.Fatalf("init failed\n%s", ui.ErrorWriter)
+	}
+
+	run := func(args ...string) string {
+		t.Helper()
+		ui.OutputWriter.Reset()
+		pc := &ProvidersSchemaCommand{Meta: m}
+		if code := pc.Run(append([]string{"-json"}, args...)); code != 0 {
+			t.Fatalf("wrong exit status %d; want 0\nstderr: %s", code, ui.ErrorWriter.String())
+		}
+		return strings.TrimSpace(ui.OutputWriter.String())
+	}
+
+	if got, want := run("-type=other_*"), `{"format_version":"1.0"}`; got != want {
+		t.Errorf("wrong output for a type filter that matches nothing\ngot:  %s\nwant: %s", got, want)
+	}
+
+	compact := run("-compact", "-provider=hashicorp/test")
+	if strings.Contains(compact, "description_kind") {
+		t.Errorf("compact output includes descriptions\n%s", compact)
+	}
+
+	// Compare with an earlier version of the schema that had no "ami"
+	// attribute, and that was saved with descriptions.
+	old := strings.Replace(run(), `"ami":{"type":"string","description_kind":"plain","optional":true},`, "", 1)
+	if err := os.WriteFile("old.json", []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got := run("-compact", "-diff=old.json")
+	want := `{"format_version":"1.0","changes":[{"path":["provider_schemas","registry.opentofu.org/hashicorp/test","resource_schemas","test_instance","block","attributes","ami"],"action":"create","after":{"optional":true,"type":"string"}}]}`
+	if got != want {
+		t.Errorf("wrong diff\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
 type providerSchemas struct {
 	FormatVersion string                    `json:"format_version"`
 	Schemas       map[string]providerSchema `json:"provider_schemas"`
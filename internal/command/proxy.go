@@ -0,0 +1,259 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	svchost "github.com/hashicorp/terraform-svchost"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/registryproxy"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// ProxyCommand is a Command implementation that runs a caching pull-through
+// proxy for the module and provider registry protocols, so that many
+// machines can share one cache of the modules and providers they install.
+type ProxyCommand struct {
+	Meta
+}
+
+func (c *ProxyCommand) Synopsis() string {
+	return "Run a caching proxy for module and provider registries"
+}
+
+func (c *ProxyCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("proxy")
+	var listenAddr string
+	var optHosts FlagStringSlice
+	var maxAge time.Duration
+	var tlsCert, tlsKey, clientTokensFile string
+	var allowCredentials bool
+	cmdFlags.StringVar(&listenAddr, "listen", "127.0.0.1:8080", "address to listen on")
+	cmdFlags.StringVar(&tlsCert, "tls-cert", "", "TLS certificate file")
+	cmdFlags.StringVar(&tlsKey, "tls-key", "", "TLS private key file")
+	cmdFlags.StringVar(&clientTokensFile, "client-tokens-file", "", "file of tokens that clients must present")
+	cmdFlags.BoolVar(&allowCredentials, "allow-credentials", false, "allow proxying hosts with credentials")
+	cmdFlags.Var(&optHosts, "host", "upstream registry host")
+	cmdFlags.DurationVar(&maxAge, "versions-max-age", registryproxy.DefaultVersionsMaxAge, "how long to cache version lists")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+
+	var diags tfdiags.Diagnostics
+
+	args = cmdFlags.Args()
+	if len(args) != 1 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"No cache directory specified",
+			"The proxy command requires the directory to cache modules and providers in as a command-line argument.",
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+	cacheDir := args[0]
+
+	var hosts []svchost.Hostname
+	if len(optHosts) == 0 {
+		hosts = []svchost.Hostname{addrs.DefaultProviderRegistryHost}
+	}
+	for _, given := range optHosts {
+		host, err := svchost.ForComparison(given)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid registry hostname",
+				fmt.Sprintf("The string %q given in the -host option is not a valid hostname: %s.", given, err),
+			))
+			continue
+		}
+		hosts = append(hosts, host)
+	}
+	if maxAge <= 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid versions maximum age",
+			"The -versions-max-age option must be a positive duration, such as \"5m\".",
+		))
+	}
+	if (tlsCert == "") != (tlsKey == "") {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incomplete TLS configuration",
+			"The -tls-cert and -tls-key options must be used together.",
+		))
+	}
+	var clientTokens []string
+	if clientTokensFile != "" {
+		src, err := os.ReadFile(clientTokensFile)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Failed to read client tokens",
+				fmt.Sprintf("Cannot read the file given in the -client-tokens-file option: %s.", err),
+			))
+		}
+		for _, line := range strings.Split(string(src), "\n") {
+			if token := strings.TrimSpace(line); token != "" {
+				clientTokens = append(clientTokens, token)
+			}
+		}
+		if err == nil && len(clientTokens) == 0 {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"No client tokens",
+				fmt.Sprintf("The file %s given in the -client-tokens-file option contains no tokens.", clientTokensFile),
+			))
+		}
+	}
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	proxy, err := registryproxy.New(registryproxy.Config{
+		Dir:              cacheDir,
+		Services:         c.Services,
+		Hosts:            hosts,
+		VersionsMaxAge:   maxAge,
+		AllowCredentials: allowCredentials,
+		ClientTokens:     clientTokens,
+	})
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to start registry proxy",
+			err.Error(),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Failed to start registry proxy",
+			fmt.Sprintf("Cannot listen on %s: %s.", listenAddr, err),
+		))
+		c.showDiagnostics(diags)
+		return 1
+	}
+	server := &http.Server{
+		Handler:           proxy,
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+	served := make(chan error, 1)
+	go func() {
+		if tlsCert != "" {
+			served <- server.ServeTLS(listener, tlsCert, tlsKey)
+		} else {
+			served <- server.Serve(listener)
+		}
+	}()
+
+	scheme := "http"
+	if tlsCert != "" {
+		scheme = "https"
+	}
+	baseURL := scheme + "://" + listener.Addr().String()
+	c.Ui.Output(fmt.Sprintf("OpenTofu registry proxy listening on %s, caching in %s.\n", baseURL, cacheDir))
+	c.Ui.Output("To install modules and providers through this proxy, add the following to the\nOpenTofu CLI configuration:\n")
+	for _, host := range hosts {
+		c.Ui.Output(proxyHostBlock(baseURL, host))
+	}
+	if len(clientTokens) != 0 {
+		c.Ui.Output("Each machine must also configure one of the client tokens as the credentials\nfor each of these hosts, in a \"credentials\" block.\n")
+	}
+	if allowCredentials && len(clientTokens) == 0 {
+		c.Ui.Warn("Warning: the proxy serves modules and providers fetched with this machine's\ncredentials to any client that can reach it. Use -client-tokens-file, or make\nsure that only trusted clients can reach the proxy.\n")
+	}
+	c.Ui.Output("Press Ctrl-C to stop the proxy.")
+
+	ctx, done := c.InterruptibleContext(c.CommandContext())
+	defer done()
+
+	select {
+	case <-ctx.Done():
+		// Give requests in progress a little while to finish.
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			c.Ui.Error(fmt.Sprintf("Error stopping registry proxy: %s", err))
+			return 1
+		}
+		return 0
+	case err := <-served:
+		if !errors.Is(err, http.ErrServerClosed) {
+			c.Ui.Error(fmt.Sprintf("Error running registry proxy: %s", err))
+		}
+		return 1
+	}
+}
+
+// proxyHostBlock returns a CLI configuration "host" block that points the
+// given registry host at the proxy with the given base URL.
+func proxyHostBlock(baseURL string, host svchost.Hostname) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "host %q {\n", host.ForDisplay())
+	fmt.Fprintf(&b, "  services = {\n")
+	fmt.Fprintf(&b, "    \"modules.v1\"   = \"%s/v1/modules/%s/\",\n", baseURL, host)
+	fmt.Fprintf(&b, "    \"providers.v1\" = \"%s/v1/providers/%s/\",\n", baseURL, host)
+	fmt.Fprintf(&b, "  }\n")
+	fmt.Fprintf(&b, "}\n")
+	return b.String()
+}
+
+func (c *ProxyCommand) Help() string {
+	return `
+Usage: tofu [global options] proxy [options] <cache-dir>
+
+  Runs a caching pull-through proxy for the module and provider registry
+  protocols, so that many machines, such as the workers of a build farm, can
+  share one local cache of the modules and providers they install instead of
+  each downloading them from the upstream registries.
+
+  The proxy keeps its cache in the given directory. The metadata and packages
+  of each module and provider version are cached indefinitely, while the
+  lists of available versions are refreshed periodically.
+
+  When it starts, the proxy prints the "host" blocks to add to the OpenTofu
+  CLI configuration of each machine that should use it.
+
+Options:
+
+  -listen=addr             The address to listen on. Defaults to
+                           127.0.0.1:8080.
+
+  -tls-cert=file           Serve HTTPS using the certificate and private key
+  -tls-key=file            in the given PEM files, instead of plain HTTP.
+
+  -client-tokens-file=file Require clients to present one of the tokens in
+                           the given file, one per line.
+
+  -allow-credentials       Allow proxying hosts that this machine has
+                           credentials for. The proxy serves whatever it can
+                           fetch with those credentials to its clients.
+
+  -host=hostname           An upstream registry host to proxy. Use this
+                           option multiple times to proxy several registries.
+                           Defaults to registry.opentofu.org.
+
+  -versions-max-age=5m     How long to use a cached list of the available
+                           versions of a module or provider before asking
+                           the upstream registry for it again.
+`
+}
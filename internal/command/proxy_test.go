@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+// More thorough tests of the proxy itself are in the registryproxy package.
+func TestProxy(t *testing.T) {
+	t.Run("missing arg error", func(t *testing.T) {
+		ui := new(cli.MockUi)
+		c := &ProxyCommand{
+			Meta: Meta{Ui: ui},
+		}
+		code := c.Run([]string{})
+		if code != 1 {
+			t.Fatalf("wrong exit code. expected 1, got %d", code)
+		}
+
+		got := ui.ErrorWriter.String()
+		if !strings.Contains(got, "Error: No cache directory specified") {
+			t.Fatalf("missing directory error from output, got:\n%s\n", got)
+		}
+	})
+
+	t.Run("invalid host", func(t *testing.T) {
+		ui := new(cli.MockUi)
+		c := &ProxyCommand{
+			Meta: Meta{Ui: ui},
+		}
+		code := c.Run([]string{"-host=not a host", t.TempDir()})
+		if code != 1 {
+			t.Fatalf("wrong exit code. expected 1, got %d", code)
+		}
+
+		got := ui.ErrorWriter.String()
+		if !strings.Contains(got, "Error: Invalid registry hostname") {
+			t.Fatalf("missing hostname error from output, got:\n%s\n", got)
+		}
+	})
+
+	t.Run("incomplete TLS configuration", func(t *testing.T) {
+		ui := new(cli.MockUi)
+		c := &ProxyCommand{
+			Meta: Meta{Ui: ui},
+		}
+		code := c.Run([]string{"-tls-cert=cert.pem", t.TempDir()})
+		if code != 1 {
+			t.Fatalf("wrong exit code. expected 1, got %d", code)
+		}
+
+		got := ui.ErrorWriter.String()
+		if !strings.Contains(got, "Error: Incomplete TLS configuration") {
+			t.Fatalf("missing TLS error from output, got:\n%s\n", got)
+		}
+	})
+
+	t.Run("interrupted", func(t *testing.T) {
+		// The proxy runs until it's interrupted, so we interrupt it before
+		// it starts to check only that it starts and stops cleanly.
+		shutdownCh := make(chan struct{})
+		close(shutdownCh)
+		ui := new(cli.MockUi)
+		c := &ProxyCommand{
+			Meta: Meta{Ui: ui, ShutdownCh: shutdownCh},
+		}
+		code := c.Run([]string{"-listen=127.0.0.1:0", "-host=registry.example.com", t.TempDir()})
+		if code != 0 {
+			t.Fatalf("wrong exit code. expected 0, got %d\n%s", code, ui.ErrorWriter.String())
+		}
+
+		got := ui.OutputWriter.String()
+		for _, want := range []string{
+			`host "registry.example.com" {`,
+			`"modules.v1"   = "http://127.0.0.1:`,
+			`/v1/providers/registry.example.com/",`,
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("output does not contain %q:\n%s", want, got)
+			}
+		}
+	})
+}
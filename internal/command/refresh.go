@@ -11,6 +11,7 @@ import (
 	"github.com/opentofu/opentofu/internal/command/arguments"
 	"github.com/opentofu/opentofu/internal/command/views"
 	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/opentofu/opentofu/internal/tofu"
 )
 
 // RefreshCommand is a cli.Command implementation that refreshes the state
@@ -65,6 +66,15 @@ func (c *RefreshCommand) Run(rawArgs []string) int {
 	// clear path to pass this value down, so we continue to mutate the Meta
 	// object state for now.
 	c.Meta.parallelism = args.Operation.Parallelism
+	c.Meta.concurrencyLimits = tofu.ConcurrencyLimits{
+		Providers:     args.Operation.ProviderConcurrency,
+		ResourceTypes: args.Operation.ResourceConcurrency,
+		ProviderRates: args.Operation.ProviderRateLimits,
+	}
+	c.Meta.refreshParallelism = tofu.RefreshParallelism{
+		Default:   args.Operation.RefreshParallelism,
+		Providers: args.Operation.ProviderRefreshParallelism,
+	}
 
 	// Prepare the backend with the backend-specific arguments
 	be, beDiags := c.PrepareBackend(args.State, args.ViewType)
@@ -144,6 +154,7 @@ func (c *RefreshCommand) OperationRequest(be backend.Enhanced, view views.Refres
 	opReq.ConfigDir = "."
 	opReq.Hooks = view.Hooks()
 	opReq.Targets = args.Targets
+	opReq.Excludes = args.Excludes
 	opReq.Type = backend.OperationTypeRefresh
 	opReq.View = view.Operation()
 
@@ -208,6 +219,33 @@ Options:
 
   -parallelism=n      Limit the number of concurrent operations. Defaults to 10.
 
+  -provider-concurrency=SOURCE=n
+                      Limit the number of concurrent operations for resources
+                      belonging to the given provider, such as
+                      "hashicorp/aws=4". This flag can be used multiple times.
+
+  -provider-rate-limit=SOURCE=n
+                      Limit the number of operations per second that begin for
+                      resources belonging to the given provider, such as
+                      "hashicorp/aws=10". This flag can be used multiple
+                      times.
+
+  -resource-concurrency=TYPE=n
+                      Limit the number of concurrent operations for resources
+                      of the given type, such as "aws_instance=2". This flag
+                      can be used multiple times.
+
+  -refresh-parallelism=[SOURCE=]n
+                      Limit the number of concurrent reads of existing objects
+                      while refreshing, for each provider configuration or
+                      only those of the given provider. Defaults to 10, and is
+                      separate from -parallelism. This flag can be used
+                      multiple times.
+
+  -exclude=resource   Resource to exclude. Operation will be limited to all
+                      resources except this resource and the resources that
+                      depend on it. This flag can be used multiple times.
+
   -target=resource    Resource to target. Operation will be limited to this
                       resource and its dependencies. This flag can be used
                       multiple times.
@@ -64,7 +64,7 @@ func (c *ShowCommand) Run(rawArgs []string) int {
 	c.viewType = args.ViewType
 
 	// Set up view
-	view := views.NewShow(args.ViewType, c.View)
+	view := views.NewShow(args.ViewType, args.Summary, c.View)
 
 	// Check for user-supplied plugin path
 	var err error
@@ -82,6 +82,30 @@ func (c *ShowCommand) Run(rawArgs []string) int {
 		return 1
 	}
 
+	// Select the resource instances to show
+	if !args.Filter.Empty() {
+		switch {
+		case jsonPlan != nil:
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Filters not supported for cloud plans",
+				"The -target, -module and -only options cannot be used when showing a saved cloud plan.",
+			))
+		case plan == nil && len(args.Filter.Actions) > 0:
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid -only option",
+				"The -only option selects resource instances by their planned changes, and so can be used only when showing a plan.",
+			))
+		}
+		if diags.HasErrors() {
+			view.Diagnostics(diags)
+			return 1
+		}
+		plan = filterShowPlan(args.Filter, plan)
+		stateFile = filterShowStateFile(args.Filter, stateFile)
+	}
+
 	// Display the data
 	return view.Display(config, plan, jsonPlan, stateFile, schemas)
 }
@@ -98,6 +122,22 @@ Options:
   -no-color           If specified, output won't contain any color.
   -json               If specified, output the OpenTofu plan or state in
                       a machine-readable form.
+  -summary=module     When showing a plan, summarize the number of changes
+                      of each kind in each module call instead of showing
+                      each individual resource change.
+  -expand=module.name With -summary=module, also show the individual changes
+                      for the given module call. This flag can be used
+                      multiple times.
+  -target=resource    Show only the given resource instance and the changes
+                      planned for it, or the resource instances in the
+                      given module. This flag can be used multiple times.
+  -module=module.name Show only the resource instances in all instances of
+                      the given module call and of its descendants. This
+                      flag can be used multiple times.
+  -only=change        When showing a plan, show only the resource instances
+                      with the given kinds of change: create, update,
+                      replace, destroy, read, or no-op. This flag accepts a
+                      comma-separated list and can be used multiple times.
 
 `
 	return strings.TrimSpace(helpText)
@@ -261,7 +301,7 @@ func (c *ShowCommand) getPlanFromPath(path string) (*plans.Plan, *cloudplan.Remo
 	var stateFile *statefile.File
 	var config *configs.Config
 
-	pf, err := planfile.OpenWrapped(path)
+	pf, err := c.openPlanFile(path)
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
@@ -0,0 +1,150 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/statefile"
+)
+
+// showFilterMatchesAddr returns true if the given resource instance matches
+// the address and module filters of the given filter.
+func showFilterMatchesAddr(filter *arguments.ShowFilter, addr addrs.AbsResourceInstance) bool {
+	if len(filter.Targets) > 0 {
+		matched := false
+		for _, target := range filter.Targets {
+			if target.TargetContains(addr) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(filter.Modules) > 0 {
+		matched := false
+		for _, module := range filter.Modules {
+			if module.TargetContains(addr) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+// showFilterMatchesChange returns true if the given resource instance change
+// matches all of the filters of the given filter.
+func showFilterMatchesChange(filter *arguments.ShowFilter, change *plans.ResourceInstanceChangeSrc) bool {
+	if !showFilterMatchesAddr(filter, change.Addr) {
+		return false
+	}
+
+	if len(filter.Actions) > 0 {
+		for _, action := range filter.Actions {
+			if change.Action == action {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// filterShowPlan returns a copy of the given plan which includes only the
+// resource instance changes matching the given filter. The original plan is
+// not modified.
+//
+// Output value changes don't belong to any resource instance, and so are
+// omitted whenever the filter is not empty.
+func filterShowPlan(filter *arguments.ShowFilter, plan *plans.Plan) *plans.Plan {
+	if plan == nil || filter.Empty() {
+		return plan
+	}
+
+	ret := *plan
+	ret.Changes = plans.NewChanges()
+	for _, change := range plan.Changes.Resources {
+		if showFilterMatchesChange(filter, change) {
+			ret.Changes.Resources = append(ret.Changes.Resources, change)
+		}
+	}
+
+	ret.DriftedResources = nil
+	for _, change := range plan.DriftedResources {
+		if showFilterMatchesChange(filter, change) {
+			ret.DriftedResources = append(ret.DriftedResources, change)
+		}
+	}
+
+	ret.PrevRunState = filterShowState(filter, plan.PrevRunState)
+	ret.PriorState = filterShowState(filter, plan.PriorState)
+
+	return &ret
+}
+
+// filterShowStateFile returns a copy of the given state file whose state
+// includes only the resource instances matching the address and module
+// filters of the given filter. The original state file is not modified.
+func filterShowStateFile(filter *arguments.ShowFilter, file *statefile.File) *statefile.File {
+	if file == nil || filter.Empty() {
+		return file
+	}
+
+	ret := *file
+	ret.State = filterShowState(filter, file.State)
+	return &ret
+}
+
+// filterShowState returns a copy of the given state which includes only the
+// resource instances matching the address and module filters of the given
+// filter.
+//
+// Output values don't belong to any resource instance, and so are omitted
+// whenever the filter is not empty.
+func filterShowState(filter *arguments.ShowFilter, state *states.State) *states.State {
+	if state == nil || filter.Empty() {
+		return state
+	}
+
+	ret := state.DeepCopy()
+	for _, module := range ret.Modules {
+		for _, rs := range module.Resources {
+			if len(rs.Instances) == 0 {
+				// A resource with no instances can only match a filter
+				// for its whole module.
+				if !showFilterMatchesAddr(filter, rs.Addr.Instance(addrs.NoKey)) {
+					module.RemoveResource(rs.Addr.Resource)
+				}
+				continue
+			}
+			for key := range rs.Instances {
+				if !showFilterMatchesAddr(filter, rs.Addr.Instance(key)) {
+					delete(rs.Instances, key)
+				}
+			}
+			if len(rs.Instances) == 0 {
+				module.RemoveResource(rs.Addr.Resource)
+			}
+		}
+
+		for name := range module.OutputValues {
+			module.RemoveOutputValue(name)
+		}
+		if len(module.Resources) == 0 && !module.Addr.IsRoot() {
+			ret.RemoveModule(module.Addr)
+		}
+	}
+	return ret
+}
@@ -1001,6 +1001,122 @@ func TestShow_corruptStatefile(t *testing.T) {
 // showFixtureSchema returns a schema suitable for processing the configuration
 // in testdata/show. This schema should be assigned to a mock provider
 // named "test".
+func TestShow_planFiltered(t *testing.T) {
+	planPath := showFixtureMultiChangePlanFile(t)
+
+	view, done := testView(t)
+	c := &ShowCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(showFixtureProvider()),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-target=test_instance.foo", "-target=test_instance.bar", "-only=destroy", "-no-color", planPath})
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("unexpected exit status %d; want 0\ngot: %s", code, output.Stderr())
+	}
+
+	got := output.Stdout()
+	if !strings.Contains(got, "test_instance.bar will be destroyed") {
+		t.Errorf("destroyed instance is missing from output:\n%s", got)
+	}
+	for _, unwanted := range []string{"test_instance.foo", "module.child"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("output includes %s, which should be filtered out:\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestShow_planFiltered_json(t *testing.T) {
+	planPath := showFixtureMultiChangePlanFile(t)
+
+	view, done := testView(t)
+	c := &ShowCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(showFixtureProvider()),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-json", "-module=module.child", "-only=create,update", planPath})
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("unexpected exit status %d; want 0\ngot: %s", code, output.Stderr())
+	}
+
+	var got struct {
+		ResourceChanges []struct {
+			Address string `json:"address"`
+		} `json:"resource_changes"`
+	}
+	if err := json.Unmarshal([]byte(output.Stdout()), &got); err != nil {
+		t.Fatal(err)
+	}
+	var addrs []string
+	for _, change := range got.ResourceChanges {
+		addrs = append(addrs, change.Address)
+	}
+	if diff := cmp.Diff([]string{"module.child.test_instance.foo"}, addrs); diff != "" {
+		t.Errorf("wrong resource changes\n%s", diff)
+	}
+}
+
+func TestShow_stateFiltered(t *testing.T) {
+	state := testState()
+	state.EnsureModule(addrs.RootModuleInstance.Child("child", addrs.NoKey)).SetResourceInstanceCurrent(
+		addrs.Resource{
+			Mode: addrs.ManagedResourceMode,
+			Type: "test_instance",
+			Name: "baz",
+		}.Instance(addrs.NoKey),
+		&states.ResourceInstanceObjectSrc{
+			AttrsJSON: []byte(`{"id":"baz"}`),
+			Status:    states.ObjectReady,
+		},
+		addrs.AbsProviderConfig{
+			Provider: addrs.NewDefaultProvider("test"),
+			Module:   addrs.RootModule,
+		},
+	)
+	statePath := testStateFile(t, state)
+
+	view, done := testView(t)
+	c := &ShowCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(showFixtureProvider()),
+			View:             view,
+		},
+	}
+
+	code := c.Run([]string{"-module=module.child", "-no-color", statePath})
+	output := done(t)
+	if code != 0 {
+		t.Fatalf("unexpected exit status %d; want 0\ngot: %s", code, output.Stderr())
+	}
+
+	got := output.Stdout()
+	if !strings.Contains(got, "module.child.test_instance.baz") {
+		t.Errorf("module resource is missing from output:\n%s", got)
+	}
+	if strings.Contains(got, "test_instance.foo") {
+		t.Errorf("output includes root module resource, which should be filtered out:\n%s", got)
+	}
+
+	// The -only option can be used only with a plan.
+	view, done = testView(t)
+	c.View = view
+	code = c.Run([]string{"-only=create", statePath})
+	output = done(t)
+	if code != 1 {
+		t.Fatalf("unexpected exit status %d; want 1\ngot: %s", code, output.Stdout())
+	}
+	if got := output.Stderr(); !strings.Contains(got, "Invalid -only option") {
+		t.Errorf("wrong error:\n%s", got)
+	}
+}
+
 func showFixtureSchema() *providers.GetProviderSchemaResponse {
 	return &providers.GetProviderSchemaResponse{
 		Provider: providers.Schema{
@@ -1192,6 +1308,75 @@ func showFixturePlanFile(t *testing.T, action plans.Action) string {
 	)
 }
 
+// showFixtureMultiChangePlanFile creates a plan file with several changes, for
+// testing filters: test_instance.foo and module.child.test_instance.foo are
+// to be created and test_instance.bar is to be destroyed.
+func showFixtureMultiChangePlanFile(t *testing.T) string {
+	_, snap := testModuleWithSnapshot(t, "show")
+	ty := cty.Object(map[string]cty.Type{
+		"id":  cty.String,
+		"ami": cty.String,
+	})
+	val := cty.ObjectVal(map[string]cty.Value{
+		"id":  cty.UnknownVal(cty.String),
+		"ami": cty.StringVal("bar"),
+	})
+	nullRaw, err := plans.NewDynamicValue(cty.NullVal(ty), ty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valRaw, err := plans.NewDynamicValue(val, ty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priorRaw, err := plans.NewDynamicValue(cty.ObjectVal(map[string]cty.Value{
+		"id":  cty.StringVal("bar"),
+		"ami": cty.StringVal("bar"),
+	}), ty)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan := testPlan(t)
+	changes := plan.Changes.SyncWrapper()
+	for _, change := range []struct {
+		module addrs.ModuleInstance
+		name   string
+		action plans.Action
+	}{
+		{addrs.RootModuleInstance, "foo", plans.Create},
+		{addrs.RootModuleInstance, "bar", plans.Delete},
+		{addrs.RootModuleInstance.Child("child", addrs.NoKey), "foo", plans.Create},
+	} {
+		before, after := nullRaw, valRaw
+		if change.action == plans.Delete {
+			before, after = priorRaw, nullRaw
+		}
+		changes.AppendResourceInstanceChange(&plans.ResourceInstanceChangeSrc{
+			Addr: addrs.Resource{
+				Mode: addrs.ManagedResourceMode,
+				Type: "test_instance",
+				Name: change.name,
+			}.Instance(addrs.NoKey).Absolute(change.module),
+			ProviderAddr: addrs.AbsProviderConfig{
+				Provider: addrs.NewDefaultProvider("test"),
+				Module:   addrs.RootModule,
+			},
+			ChangeSrc: plans.ChangeSrc{
+				Action: change.action,
+				Before: before,
+				After:  after,
+			},
+		})
+	}
+	return testPlanFile(
+		t,
+		snap,
+		states.NewState(),
+		plan,
+	)
+}
+
 // this simplified plan struct allows us to preserve field order when marshaling
 // the command output. NOTE: we are leaving "terraform_version" out of this test
 // to avoid needing to constantly update the expected output; as a potential
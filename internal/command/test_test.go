@@ -835,6 +835,58 @@ func TestTest_NestedSetupModules(t *testing.T) {
 	}
 }
 
+func TestTest_MockModules(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath(path.Join("test", "mock_module")), td)
+	defer testChdir(t, td)()
+
+	provider := testing_command.NewProvider(nil)
+
+	providerSource, close := newMockProviderSource(t, map[string][]string{
+		"test": {"1.0.0"},
+	})
+	defer close()
+
+	streams, done := terminal.StreamsForTesting(t)
+	view := views.NewView(streams)
+	ui := new(cli.MockUi)
+
+	meta := Meta{
+		testingOverrides: metaOverridesForProvider(provider.Provider),
+		Ui:               ui,
+		View:             view,
+		Streams:          streams,
+		ProviderSource:   providerSource,
+	}
+
+	init := &InitCommand{
+		Meta: meta,
+	}
+
+	if code := init.Run(nil); code != 0 {
+		t.Fatalf("expected status code 0 but got %d: %s", code, ui.ErrorWriter)
+	}
+
+	command := &TestCommand{
+		Meta: meta,
+	}
+
+	code := command.Run(nil)
+	output := done(t)
+
+	if code != 0 {
+		t.Errorf("expected status code 0 but got %d: %s", code, output.All())
+	}
+
+	if !strings.Contains(output.Stdout(), "2 passed, 0 failed.") {
+		t.Errorf("output didn't contain expected string:\n\n%s", output.All())
+	}
+
+	if provider.ResourceCount() > 0 {
+		t.Errorf("should have deleted all resources on completion but left %s", provider.ResourceString())
+	}
+}
+
 func TestTest_StatePropagation(t *testing.T) {
 	td := t.TempDir()
 	testCopyDir(t, testFixturePath(path.Join("test", "state_propagation")), td)
@@ -917,6 +969,7 @@ OpenTofu will perform the following actions:
   # test_resource.resource will be updated in-place
   ~ resource "test_resource" "resource" {
         id    = "598318e0"
+      # (changed in configuration at main.tf:8)
       ~ value = "start" -> "update"
     }
 
@@ -932,6 +985,7 @@ OpenTofu will perform the following actions:
   # test_resource.module_resource will be updated in-place
   ~ resource "test_resource" "module_resource" {
         id    = "df6h8as9"
+      # (changed in configuration at main.tf:8)
       ~ value = "start" -> "update"
     }
 
@@ -0,0 +1,6 @@
+resource "test_instance" "base" {
+}
+
+module "app" {
+  source = "./app"
+}
@@ -0,0 +1,19 @@
+data "test_data_source" "a" {
+  id = "a"
+}
+
+resource "test_instance" "b" {
+  ami = data.test_data_source.a.id
+}
+
+resource "test_instance" "c" {
+  ami = test_instance.b.id
+}
+
+resource "test_instance" "d" {
+  ami = test_instance.c.id
+}
+
+resource "test_instance" "unrelated" {
+  ami = "unrelated"
+}
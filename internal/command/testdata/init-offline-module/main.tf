@@ -0,0 +1,4 @@
+module "remote" {
+  source  = "hashicorp/module-installer-acctest/aws"
+  version = "0.0.1"
+}
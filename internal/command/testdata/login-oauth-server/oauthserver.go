@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 )
 
 // Handler is an implementation of net/http.Handler that provides a stub
@@ -19,6 +20,8 @@ import (
 //	/authz  - authorization endpoint
 //	/token  - token endpoint
 //	/revoke - token revocation (logout) endpoint
+//	/device - device authorization endpoint
+//	/.well-known/openid-configuration - OpenID Connect discovery document
 //
 // The authorization endpoint returns HTML per normal OAuth conventions, but
 // it also includes an HTTP header X-Redirect-To giving the same URL that the
@@ -26,6 +29,10 @@ import (
 // this robotically in automated tests.
 var Handler http.Handler
 
+// devicePolls counts the token requests for each device code, so that the
+// first one can report that authorization is still pending.
+var devicePolls sync.Map
+
 type handler struct{}
 
 func (h handler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
@@ -36,6 +43,10 @@ func (h handler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 		h.serveToken(resp, req)
 	case "/revoke":
 		h.serveRevoke(resp, req)
+	case "/device":
+		h.serveDevice(resp, req)
+	case "/.well-known/openid-configuration":
+		h.serveOIDCConfig(resp, req)
 	default:
 		resp.WriteHeader(404)
 	}
@@ -164,12 +175,87 @@ func (h handler) serveToken(resp http.ResponseWriter, req *http.Request) {
 		resp.Write([]byte(`{"access_token":"good-token","token_type":"bearer"}`))
 		log.Println("/token: successful request")
 
+	case "urn:ietf:params:oauth:grant-type:device_code":
+		deviceCode := req.Form.Get("device_code")
+		if deviceCode == "denied" {
+			resp.Header().Set("Content-Type", "application/json")
+			resp.WriteHeader(400)
+			resp.Write([]byte(`{"error":"access_denied"}`))
+			return
+		}
+		polls, _ := devicePolls.LoadOrStore(deviceCode, new(int))
+		*polls.(*int)++
+		if *polls.(*int) == 1 {
+			// The first request is always still pending, as if the user
+			// hasn't yet entered the code.
+			resp.Header().Set("Content-Type", "application/json")
+			resp.WriteHeader(400)
+			resp.Write([]byte(`{"error":"authorization_pending"}`))
+			log.Println("/token: authorization pending")
+			return
+		}
+
+		resp.Header().Set("Content-Type", "application/json")
+		resp.WriteHeader(200)
+		resp.Write([]byte(`{"access_token":"good-token","token_type":"bearer","refresh_token":"refresh-token","expires_in":3600}`))
+		log.Println("/token: successful request")
+
+	case "refresh_token":
+		if req.Form.Get("refresh_token") != "refresh-token" {
+			resp.Header().Set("Content-Type", "application/json")
+			resp.WriteHeader(400)
+			resp.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+
+		resp.Header().Set("Content-Type", "application/json")
+		resp.WriteHeader(200)
+		resp.Write([]byte(`{"access_token":"refreshed-token","token_type":"bearer","expires_in":3600}`))
+		log.Println("/token: successful refresh")
+
 	default:
 		resp.WriteHeader(400)
 		log.Printf("/token: unsupported grant type %q", grantType)
 	}
 }
 
+func (h handler) serveDevice(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != "POST" {
+		resp.WriteHeader(405)
+		log.Printf("/device: unsupported request method %q", req.Method)
+		return
+	}
+	if err := req.ParseForm(); err != nil {
+		resp.WriteHeader(500)
+		log.Printf("/device: error parsing body: %s", err)
+		return
+	}
+
+	// The special client ID "denied" allows testing for the error case.
+	deviceCode := fmt.Sprintf("device-code-%p", req)
+	if req.Form.Get("client_id") == "denied" {
+		deviceCode = "denied"
+	}
+
+	verificationURI := fmt.Sprintf("http://%s/activate", req.Host)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(200)
+	fmt.Fprintf(
+		resp, `{"device_code":%q,"user_code":"ABCD-EFGH","verification_uri":%q,"expires_in":600,"interval":0}`,
+		deviceCode, verificationURI,
+	)
+}
+
+func (h handler) serveOIDCConfig(resp http.ResponseWriter, req *http.Request) {
+	base := fmt.Sprintf("http://%s", req.Host)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(200)
+	fmt.Fprintf(
+		resp, `{"issuer":%q,"device_authorization_endpoint":%q,"token_endpoint":%q}`,
+		base, base+"/device", base+"/token",
+	)
+}
+
 func (h handler) serveRevoke(resp http.ResponseWriter, req *http.Request) {
 	resp.WriteHeader(404)
 }
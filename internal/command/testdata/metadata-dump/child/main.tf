@@ -0,0 +1,5 @@
+variable "enabled" {
+  type     = bool
+  nullable = false
+  default  = true
+}
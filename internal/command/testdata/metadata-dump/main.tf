@@ -0,0 +1,24 @@
+provider "test" {
+}
+
+variable "settings" {
+  type = object({
+    name = string
+    size = optional(number, 1)
+  })
+  description = "Settings for the test instance."
+}
+
+variable "region" {
+  default = "us-east-1"
+}
+
+output "id" {
+  value       = "example"
+  description = "The instance id."
+  sensitive   = true
+}
+
+module "child" {
+  source = "./child"
+}
@@ -0,0 +1,17 @@
+variable "name" {
+  type = string
+}
+
+module "network" {
+  source = "./network"
+
+  name = var.name
+}
+
+resource "test_resource" "app" {
+  value = module.network.subnet_id
+}
+
+output "network_name" {
+  value = module.network.name
+}
@@ -0,0 +1,44 @@
+variables {
+  name = "main"
+}
+
+run "plan" {
+  command = plan
+
+  mock_module {
+    target = module.network
+    outputs = {
+      name      = "mock-${var.name}"
+      subnet_id = "subnet-1234"
+    }
+  }
+
+  assert {
+    condition     = test_resource.app.value == "subnet-1234"
+    error_message = "invalid value"
+  }
+
+  assert {
+    condition     = output.network_name == "mock-main"
+    error_message = "invalid name"
+  }
+}
+
+run "apply" {
+  mock_module {
+    target = module.network
+    outputs = {
+      subnet_id = "subnet-5678"
+    }
+  }
+
+  assert {
+    condition     = test_resource.app.value == "subnet-5678"
+    error_message = "invalid value"
+  }
+
+  assert {
+    condition     = output.network_name == null
+    error_message = "undeclared outputs should be null"
+  }
+}
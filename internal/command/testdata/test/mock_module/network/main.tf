@@ -0,0 +1,22 @@
+variable "name" {
+  type = string
+}
+
+// The network can't be read by the tests, so it must be mocked.
+data "test_data_source" "vpc" {
+  id = "missing"
+}
+
+module "subnets" {
+  source = "./subnets"
+
+  vpc_id = data.test_data_source.vpc.id
+}
+
+output "name" {
+  value = var.name
+}
+
+output "subnet_id" {
+  value = module.subnets.id
+}
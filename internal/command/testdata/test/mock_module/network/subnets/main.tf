@@ -0,0 +1,11 @@
+variable "vpc_id" {
+  type = string
+}
+
+resource "test_resource" "subnet" {
+  value = var.vpc_id
+}
+
+output "id" {
+  value = test_resource.subnet.id
+}
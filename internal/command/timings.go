@@ -0,0 +1,116 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/opentofu/opentofu/internal/command/timings"
+)
+
+// TimingsCommand is a Command implementation that reports the durations of
+// the resource changes made by earlier applies in the working directory.
+type TimingsCommand struct {
+	Meta
+}
+
+func (c *TimingsCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+
+	var jsonOutput bool
+	cmdFlags := c.Meta.defaultFlagSet("timings")
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	if len(cmdFlags.Args()) > 0 {
+		c.Ui.Error("The timings command expects no arguments.\n")
+		cmdFlags.Usage()
+		return 1
+	}
+
+	history, err := timings.Load(c.DataDir())
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to load the apply timings: %s", err))
+		return 1
+	}
+	entries := history.Entries()
+
+	if jsonOutput {
+		type entryJSON struct {
+			Address        string    `json:"address"`
+			Action         string    `json:"action"`
+			Runs           int       `json:"runs"`
+			TypicalSeconds float64   `json:"typical_seconds"`
+			LastSeconds    float64   `json:"last_seconds"`
+			LastTime       time.Time `json:"last_time"`
+		}
+		ret := make([]entryJSON, 0, len(entries))
+		for _, e := range entries {
+			ret = append(ret, entryJSON{
+				Address:        e.Addr,
+				Action:         e.Action,
+				Runs:           e.Runs,
+				TypicalSeconds: e.Typical.Round(time.Millisecond).Seconds(),
+				LastSeconds:    e.Last.Round(time.Millisecond).Seconds(),
+				LastTime:       e.LastTime,
+			})
+		}
+		out, err := json.MarshalIndent(ret, "", "  ")
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to marshal the apply timings: %s", err))
+			return 1
+		}
+		c.Ui.Output(string(out))
+		return 0
+	}
+
+	if len(entries) == 0 {
+		c.Ui.Output("No apply timings have been recorded in this working directory yet.")
+		return 0
+	}
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RESOURCE\tACTION\tTYPICAL\tLAST\tRUNS\tLAST APPLIED")
+	for _, e := range entries {
+		fmt.Fprintf(
+			w, "%s\t%s\t%s\t%s\t%d\t%s\n",
+			e.Addr, e.Action, e.Typical.Round(time.Second), e.Last.Round(time.Second), e.Runs,
+			e.LastTime.Local().Format("2006-01-02 15:04"),
+		)
+	}
+	w.Flush()
+	c.Ui.Output(strings.TrimRight(buf.String(), "\n"))
+	return 0
+}
+
+func (c *TimingsCommand) Help() string {
+	helpText := `
+Usage: tofu [global options] timings [options]
+
+  Reports how long each resource change took in the most recent applies in
+  the current working directory, slowest first.
+
+  OpenTofu records these durations during each apply, and uses them to
+  estimate how long the same changes will take in later applies.
+
+Options:
+
+  -json               Produce output in a machine-readable JSON format.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *TimingsCommand) Synopsis() string {
+	return "Show how long resource changes took in earlier applies"
+}
@@ -0,0 +1,74 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package timings
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// Recorder is a hook which records the duration of each successful create,
+// update, and delete action during an apply in a History.
+type Recorder struct {
+	tofu.NilHook
+
+	history *History
+
+	mu      sync.Mutex
+	started map[recorderKey]recorderStart
+
+	// now is the clock, which tests can override.
+	now func() time.Time
+}
+
+var _ tofu.Hook = (*Recorder)(nil)
+
+type recorderKey struct {
+	addr string
+	gen  states.Generation
+}
+
+type recorderStart struct {
+	action plans.Action
+	time   time.Time
+}
+
+// NewRecorder returns a hook which records durations in the given history.
+func NewRecorder(history *History) *Recorder {
+	return &Recorder{
+		history: history,
+		started: make(map[recorderKey]recorderStart),
+		now:     time.Now,
+	}
+}
+
+func (r *Recorder) PreApply(addr addrs.AbsResourceInstance, gen states.Generation, action plans.Action, priorState, plannedNewState cty.Value) (tofu.HookAction, error) {
+	r.mu.Lock()
+	r.started[recorderKey{addr.String(), gen}] = recorderStart{action: action, time: r.now()}
+	r.mu.Unlock()
+	return tofu.HookActionContinue, nil
+}
+
+func (r *Recorder) PostApply(addr addrs.AbsResourceInstance, gen states.Generation, newState cty.Value, err error) (tofu.HookAction, error) {
+	key := recorderKey{addr.String(), gen}
+
+	r.mu.Lock()
+	start, ok := r.started[key]
+	delete(r.started, key)
+	r.mu.Unlock()
+
+	// A failed action says little about how long a successful one takes,
+	// so we record only the successful ones.
+	if ok && err == nil {
+		r.history.Record(addr, start.action, r.now().Sub(start.time))
+	}
+	return tofu.HookActionContinue, nil
+}
@@ -0,0 +1,369 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package timings records how long each resource instance took to create,
+// update, or destroy during earlier applies in a working directory, and uses
+// those records to estimate how long the same changes will take in future.
+//
+// Estimates are only ever a guide: the time an operation takes depends on the
+// remote system, and for a resource instance that has never been applied in
+// this working directory we can only guess from other instances of the same
+// resource or of the same resource type.
+package timings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/plans"
+)
+
+// Filename is the name of the file in the data directory where the history
+// of apply durations is recorded.
+const Filename = "timings.json"
+
+// maxSamples is the number of most recent durations kept for each resource
+// instance and action.
+const maxSamples = 5
+
+// History is the record of the durations of earlier applies in a working
+// directory. It is safe for concurrent use.
+type History struct {
+	filename string
+
+	mu      sync.Mutex
+	entries map[entryKey]*entry
+	dirty   bool
+}
+
+type entryKey struct {
+	addr   string
+	action string
+}
+
+type entry struct {
+	// resource and resourceType are the less specific addresses that the
+	// entry also provides estimates for, when there is no entry for a
+	// particular resource instance.
+	resource     string
+	resourceType string
+
+	samples []time.Duration
+	last    time.Time
+}
+
+// Entry is a summary of the recorded durations for a particular action on a
+// particular resource instance.
+type Entry struct {
+	Addr   string
+	Action string
+
+	// Runs is the number of recorded durations, which is at most the
+	// number of durations kept for each entry.
+	Runs int
+
+	// Typical is the duration used for estimates, which is the median of the
+	// recorded durations.
+	Typical time.Duration
+
+	// Last is the most recently recorded duration, recorded at LastTime.
+	Last     time.Duration
+	LastTime time.Time
+}
+
+// Load reads the history from the given data directory, returning an empty
+// history if there is none yet.
+func Load(dataDir string) (*History, error) {
+	h := &History{
+		filename: filepath.Join(dataDir, Filename),
+		entries:  make(map[entryKey]*entry),
+	}
+
+	src, err := os.ReadFile(h.filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return h, err
+	}
+
+	var raw historyJSON
+	if err := json.Unmarshal(src, &raw); err != nil {
+		return h, fmt.Errorf("invalid timings file %s: %w", h.filename, err)
+	}
+	if raw.Version != historyVersion {
+		// We'll just start over with a history in the current format.
+		return h, nil
+	}
+	for _, r := range raw.Resources {
+		addr, diags := addrs.ParseAbsResourceInstanceStr(r.Address)
+		if diags.HasErrors() {
+			continue
+		}
+		e := newEntry(addr)
+		e.last = r.Last
+		for _, seconds := range r.Seconds {
+			e.samples = append(e.samples, time.Duration(seconds*float64(time.Second)))
+		}
+		h.entries[entryKey{r.Address, r.Action}] = e
+	}
+	return h, nil
+}
+
+func newEntry(addr addrs.AbsResourceInstance) *entry {
+	return &entry{
+		resource:     addr.ContainingResource().Config().String(),
+		resourceType: addr.Resource.Resource.Type,
+	}
+}
+
+// Save writes the history to the file it was loaded from, if anything has
+// been recorded since it was loaded.
+func (h *History) Save() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.dirty {
+		return nil
+	}
+
+	raw := historyJSON{Version: historyVersion}
+	for key, e := range h.entries {
+		r := resourceJSON{
+			Address: key.addr,
+			Action:  key.action,
+			Last:    e.last,
+		}
+		for _, d := range e.samples {
+			r.Seconds = append(r.Seconds, d.Round(time.Millisecond).Seconds())
+		}
+		raw.Resources = append(raw.Resources, r)
+	}
+	sort.Slice(raw.Resources, func(i, j int) bool {
+		if raw.Resources[i].Address != raw.Resources[j].Address {
+			return raw.Resources[i].Address < raw.Resources[j].Address
+		}
+		return raw.Resources[i].Action < raw.Resources[j].Action
+	})
+
+	src, err := json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(h.filename), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(h.filename, src, 0644); err != nil {
+		return err
+	}
+	h.dirty = false
+	return nil
+}
+
+// Record adds the duration of an action on the given resource instance to
+// the history. Only create, update, and delete actions are recorded.
+func (h *History) Record(addr addrs.AbsResourceInstance, action plans.Action, d time.Duration) {
+	name, ok := actionName(action)
+	if !ok {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := entryKey{addr.String(), name}
+	e, ok := h.entries[key]
+	if !ok {
+		e = newEntry(addr)
+		h.entries[key] = e
+	}
+	e.samples = append(e.samples, d)
+	if len(e.samples) > maxSamples {
+		e.samples = e.samples[len(e.samples)-maxSamples:]
+	}
+	e.last = time.Now().UTC().Round(time.Second)
+	h.dirty = true
+}
+
+// Estimate returns the expected duration of the given action on the given
+// resource instance, and false if there is no history to base an estimate
+// on.
+//
+// The estimate is based on the earlier durations of the same action on the
+// same resource instance, or if there are none then on the other instances
+// of the same resource, or if there are none of those either then on the
+// other resources of the same type.
+func (h *History) Estimate(addr addrs.AbsResourceInstance, action plans.Action) (time.Duration, bool) {
+	name, ok := actionName(action)
+	if !ok {
+		return 0, false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if e, ok := h.entries[entryKey{addr.String(), name}]; ok && len(e.samples) > 0 {
+		return median(e.samples), true
+	}
+
+	resource := addr.ContainingResource().Config().String()
+	var byResource, byType []time.Duration
+	for key, e := range h.entries {
+		if key.action != name {
+			continue
+		}
+		if e.resource == resource {
+			byResource = append(byResource, e.samples...)
+		}
+		if e.resourceType == addr.Resource.Resource.Type {
+			byType = append(byType, e.samples...)
+		}
+	}
+	switch {
+	case len(byResource) > 0:
+		return median(byResource), true
+	case len(byType) > 0:
+		return median(byType), true
+	default:
+		return 0, false
+	}
+}
+
+// EstimateChange returns the expected duration of the given planned change,
+// which for a replacement is the total of the expected durations of the
+// delete and create actions. It returns false if there is no history to
+// base an estimate on.
+func (h *History) EstimateChange(change *plans.ResourceInstanceChangeSrc) (time.Duration, bool) {
+	switch change.Action {
+	case plans.DeleteThenCreate, plans.CreateThenDelete:
+		del, delOk := h.Estimate(change.Addr, plans.Delete)
+		create, createOk := h.Estimate(change.Addr, plans.Create)
+		return del + create, delOk && createOk
+	default:
+		return h.Estimate(change.Addr, change.Action)
+	}
+}
+
+// Entries returns a summary of the history, sorted so that the entries with
+// the longest typical durations come first.
+func (h *History) Entries() []Entry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ret := make([]Entry, 0, len(h.entries))
+	for key, e := range h.entries {
+		if len(e.samples) == 0 {
+			continue
+		}
+		ret = append(ret, Entry{
+			Addr:     key.addr,
+			Action:   key.action,
+			Runs:     len(e.samples),
+			Typical:  median(e.samples),
+			Last:     e.samples[len(e.samples)-1],
+			LastTime: e.last,
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Typical != ret[j].Typical {
+			return ret[i].Typical > ret[j].Typical
+		}
+		if ret[i].Addr != ret[j].Addr {
+			return ret[i].Addr < ret[j].Addr
+		}
+		return ret[i].Action < ret[j].Action
+	})
+	return ret
+}
+
+// Total returns the expected duration of applying the given planned changes
+// with the given parallelism, along with the number of changes that the
+// estimate is based on. Changes with no history are not included in the
+// estimate.
+//
+// Because we don't know the dependencies between the changes, the total is
+// only a rough guide: it assumes that the changes are spread evenly across
+// the parallel operations, but it is never less than the longest single
+// change.
+func (h *History) Total(changes []*plans.ResourceInstanceChangeSrc, parallelism int) (time.Duration, int) {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	var sum, longest time.Duration
+	var count int
+	for _, change := range changes {
+		d, ok := h.EstimateChange(change)
+		if !ok {
+			continue
+		}
+		count++
+		sum += d
+		if d > longest {
+			longest = d
+		}
+	}
+
+	total := sum / time.Duration(parallelism)
+	if total < longest {
+		total = longest
+	}
+	return total, count
+}
+
+func actionName(action plans.Action) (string, bool) {
+	switch action {
+	case plans.Create:
+		return "create", true
+	case plans.Update:
+		return "update", true
+	case plans.Delete:
+		return "delete", true
+	default:
+		return "", false
+	}
+}
+
+func median(samples []time.Duration) time.Duration {
+	sorted := make([]time.Duration, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// FormatDuration formats an estimated duration for display, with only as
+// much precision as an estimate deserves.
+func FormatDuration(d time.Duration) string {
+	if d = d.Round(time.Second); d < time.Minute {
+		return fmt.Sprintf("%ds", int(d/time.Second))
+	}
+	if d = d.Round(time.Minute); d < time.Hour {
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	}
+	return fmt.Sprintf("%dh%dm", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
+
+const historyVersion = 1
+
+type historyJSON struct {
+	Version   int            `json:"version"`
+	Resources []resourceJSON `json:"resources"`
+}
+
+type resourceJSON struct {
+	Address string    `json:"address"`
+	Action  string    `json:"action"`
+	Seconds []float64 `json:"seconds"`
+	Last    time.Time `json:"last"`
+}
@@ -0,0 +1,162 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package timings
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+func TestHistory(t *testing.T) {
+	dataDir := t.TempDir()
+	h, err := Load(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := mustResourceInstanceAddr("module.a.aws_db_instance.main[0]")
+	for _, d := range []time.Duration{10, 12, 30, 11, 13, 14} {
+		h.Record(db, plans.Create, d*time.Minute)
+	}
+	h.Record(db, plans.Read, time.Minute)
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err = Load(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		addr   string
+		action plans.Action
+		want   time.Duration
+		wantOk bool
+	}{
+		"same instance": {
+			// Only the five most recent durations are kept, and the
+			// estimate is their median.
+			"module.a.aws_db_instance.main[0]", plans.Create, 13 * time.Minute, true,
+		},
+		"other instance": {
+			"module.a.aws_db_instance.main[1]", plans.Create, 13 * time.Minute, true,
+		},
+		"other module instance": {
+			"module.a[\"b\"].aws_db_instance.main[0]", plans.Create, 13 * time.Minute, true,
+		},
+		"same type": {
+			"aws_db_instance.replica", plans.Create, 13 * time.Minute, true,
+		},
+		"other action": {
+			"module.a.aws_db_instance.main[0]", plans.Delete, 0, false,
+		},
+		"unrecorded action": {
+			"module.a.aws_db_instance.main[0]", plans.Read, 0, false,
+		},
+		"other type": {
+			"aws_instance.web", plans.Create, 0, false,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := h.Estimate(mustResourceInstanceAddr(test.addr), test.action)
+			if got != test.want || ok != test.wantOk {
+				t.Fatalf("wrong estimate %s, %t; want %s, %t", got, ok, test.want, test.wantOk)
+			}
+		})
+	}
+
+	entries := h.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("wrong number of entries %d", len(entries))
+	}
+	if got := entries[0]; got.Addr != db.String() || got.Action != "create" || got.Runs != 5 || got.Typical != 13*time.Minute || got.Last != 14*time.Minute {
+		t.Fatalf("wrong entry %#v", got)
+	}
+}
+
+func TestHistoryTotal(t *testing.T) {
+	h, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := mustResourceInstanceAddr("aws_db_instance.main")
+	sg := mustResourceInstanceAddr("aws_security_group.main")
+	h.Record(db, plans.Create, 10*time.Minute)
+	h.Record(db, plans.Delete, 5*time.Minute)
+	h.Record(sg, plans.Update, 2*time.Minute)
+
+	changes := []*plans.ResourceInstanceChangeSrc{
+		{Addr: db, ChangeSrc: plans.ChangeSrc{Action: plans.DeleteThenCreate}},
+		{Addr: sg, ChangeSrc: plans.ChangeSrc{Action: plans.Update}},
+		{Addr: mustResourceInstanceAddr("aws_instance.web"), ChangeSrc: plans.ChangeSrc{Action: plans.Create}},
+	}
+
+	total, count := h.Total(changes, 1)
+	if total != 17*time.Minute || count != 2 {
+		t.Fatalf("wrong total %s for %d changes", total, count)
+	}
+
+	// The total is never less than the longest change.
+	total, count = h.Total(changes, 10)
+	if total != 15*time.Minute || count != 2 {
+		t.Fatalf("wrong total %s for %d changes", total, count)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	h, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := NewRecorder(h)
+	r.now = func() time.Time { return now }
+
+	ok := mustResourceInstanceAddr("test_instance.ok")
+	failed := mustResourceInstanceAddr("test_instance.failed")
+	r.PreApply(ok, states.CurrentGen, plans.Create, cty.NilVal, cty.NilVal)
+	r.PreApply(failed, states.CurrentGen, plans.Create, cty.NilVal, cty.NilVal)
+	now = now.Add(90 * time.Second)
+	r.PostApply(ok, states.CurrentGen, cty.NilVal, nil)
+	r.PostApply(failed, states.CurrentGen, cty.NilVal, errors.New("failed"))
+
+	if got, _ := h.Estimate(ok, plans.Create); got != 90*time.Second {
+		t.Errorf("wrong duration %s for successful create", got)
+	}
+	if entries := h.Entries(); len(entries) != 1 {
+		t.Errorf("failed create was recorded: %#v", entries)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		1200 * time.Millisecond:         "1s",
+		59600 * time.Millisecond:        "1m",
+		12*time.Minute + 29*time.Second: "12m",
+		12*time.Minute + 30*time.Second: "13m",
+		95 * time.Minute:                "1h35m",
+	}
+	for d, want := range tests {
+		if got := FormatDuration(d); got != want {
+			t.Errorf("wrong result for %s: %q; want %q", d, got, want)
+		}
+	}
+}
+
+func mustResourceInstanceAddr(s string) addrs.AbsResourceInstance {
+	addr, diags := addrs.ParseAbsResourceInstanceStr(s)
+	if diags.HasErrors() {
+		panic(diags.Err())
+	}
+	return addr
+}
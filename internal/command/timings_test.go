@@ -0,0 +1,78 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestTimings(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("apply"), td)
+	defer testChdir(t, td)()
+
+	ui := cli.NewMockUi()
+	c := &TimingsCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+	if code := c.Run(nil); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if got := ui.OutputWriter.String(); !strings.Contains(got, "No apply timings have been recorded") {
+		t.Fatalf("wrong output before apply:\n%s", got)
+	}
+
+	// An apply records the duration of each change.
+	view, done := testView(t)
+	apply := &ApplyCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(applyFixtureProvider()),
+			View:             view,
+		},
+	}
+	if code := apply.Run([]string{"-state", testTempFile(t), "-auto-approve"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, done(t).Stderr())
+	}
+	done(t)
+
+	ui = cli.NewMockUi()
+	c = &TimingsCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+	if code := c.Run([]string{"-json"}); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	var got []struct {
+		Address string `json:"address"`
+		Action  string `json:"action"`
+		Runs    int    `json:"runs"`
+	}
+	if err := json.Unmarshal(ui.OutputWriter.Bytes(), &got); err != nil {
+		t.Fatalf("invalid output: %s\n%s", err, ui.OutputWriter.String())
+	}
+	if len(got) != 1 || got[0].Address != "test_instance.foo" || got[0].Action != "create" || got[0].Runs != 1 {
+		t.Fatalf("wrong timings: %#v", got)
+	}
+
+	ui = cli.NewMockUi()
+	c = &TimingsCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+	if code := c.Run(nil); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if got := ui.OutputWriter.String(); !strings.Contains(got, "test_instance.foo  create") {
+		t.Fatalf("wrong output after apply:\n%s", got)
+	}
+}
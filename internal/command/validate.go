@@ -118,7 +118,8 @@ func (c *ValidateCommand) validate(dir, testDir string, noTests bool) tfdiags.Di
 				// reasonably go and fix. If it's a module being downloaded from
 				// the registry, the expectation is that the author of the
 				// module should have ran `tofu validate` themselves.
-				if _, ok := run.Module.Source.(addrs.ModuleSourceLocal); ok {
+				switch run.Module.Source.(type) {
+				case addrs.ModuleSourceLocal, addrs.ModuleSourceAlias:
 
 					if validated := validatedModules[run.Module.Source.String()]; !validated {
 
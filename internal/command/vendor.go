@@ -0,0 +1,315 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-getter"
+
+	"github.com/opentofu/opentofu/internal/copy"
+	"github.com/opentofu/opentofu/internal/getproviders"
+	"github.com/opentofu/opentofu/internal/httpclient"
+	"github.com/opentofu/opentofu/internal/modsdir"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// vendorDirName is the name of the directory, in the root module directory,
+// that "tofu vendor" copies providers and module packages into.
+const vendorDirName = "vendor"
+
+// VendorCommand is a Command implementation that copies the provider packages
+// selected in the dependency lock file and the installed module packages into
+// the vendor directory of the configuration, so that "tofu init" can install
+// them from there without using the network.
+type VendorCommand struct {
+	Meta
+}
+
+func (c *VendorCommand) Synopsis() string {
+	return "Copy the configuration's providers and modules into its repository"
+}
+
+func (c *VendorCommand) Run(args []string) int {
+	args = c.Meta.process(args)
+	cmdFlags := c.Meta.defaultFlagSet("vendor")
+	var optPlatforms FlagStringSlice
+	cmdFlags.Var(&optPlatforms, "platform", "target platform")
+	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
+	if err := cmdFlags.Parse(args); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
+		return 1
+	}
+	if len(cmdFlags.Args()) != 0 {
+		c.Ui.Error("The vendor command expects no arguments.\n")
+		cmdFlags.Usage()
+		return 1
+	}
+
+	platforms, diags := parseVendorPlatforms(optPlatforms)
+	if diags.HasErrors() {
+		c.showDiagnostics(diags)
+		return 1
+	}
+
+	// Vendoring can be cancelled by SIGINT and similar.
+	ctx, done := c.InterruptibleContext(c.CommandContext())
+	defer done()
+
+	diags = diags.Append(c.vendorDependencies(ctx, vendorDirName, platforms))
+	c.showDiagnostics(diags)
+	if diags.HasErrors() {
+		return 1
+	}
+	c.Ui.Output(fmt.Sprintf("\nThe providers and modules that this configuration uses are now in the %s directory, and \"tofu init\" will install them from there.", vendorDirName))
+	return 0
+}
+
+// parseVendorPlatforms parses the values of the -platform options of the
+// commands that vendor providers, defaulting to the current platform.
+func parseVendorPlatforms(optPlatforms []string) ([]getproviders.Platform, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	if len(optPlatforms) == 0 {
+		return []getproviders.Platform{getproviders.CurrentPlatform}, diags
+	}
+	var platforms []getproviders.Platform
+	for _, platformStr := range optPlatforms {
+		platform, err := getproviders.ParsePlatform(platformStr)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid target platform",
+				fmt.Sprintf("The string %q given in the -platform option is not a valid target platform: %s.", platformStr, err),
+			))
+			continue
+		}
+		platforms = append(platforms, platform)
+	}
+	return platforms, diags
+}
+
+// vendorDependencies saves the locked provider packages for the given
+// platforms and the installed remote module packages of the configuration in
+// the current directory into outputDir, in the layout that "tofu init" reads
+// from a vendor directory.
+func (m *Meta) vendorDependencies(ctx context.Context, outputDir string, platforms []getproviders.Platform) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	// Loading the configuration also checks that all of its modules are
+	// installed, so that we can copy them.
+	config, confDiags := m.loadConfig(".")
+	diags = diags.Append(confDiags)
+	if confDiags.HasErrors() {
+		return diags
+	}
+	reqs, moreDiags := config.ProviderRequirements()
+	diags = diags.Append(moreDiags)
+	lockedDeps, lockedDepsDiags := m.lockedDependencies()
+	diags = diags.Append(lockedDepsDiags)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	// We vendor exactly the locked provider versions, so the lock file must
+	// cover all of the providers that the configuration requires.
+	if errs := config.VerifyDependencySelections(lockedDeps); len(errs) > 0 {
+		var buf strings.Builder
+		for _, err := range errs {
+			fmt.Fprintf(&buf, "\n  - %s", err)
+		}
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Inconsistent dependency lock file",
+			fmt.Sprintf("The dependency lock file doesn't match the configuration:%s\n\nTo update the dependency lock file, run \"tofu init\" before vendoring the configuration's dependencies.", buf.String()),
+		))
+		return diags
+	}
+
+	// The vendor directory contains only what the configuration currently
+	// uses, so we start again from an empty directory each time.
+	providersDir := filepath.Join(outputDir, "providers")
+	modulesDir := filepath.Join(outputDir, "modules")
+	for _, dir := range []string{providersDir, modulesDir} {
+		if err := os.RemoveAll(dir); err != nil {
+			return diags.Append(fmt.Errorf("failed to remove the previously-vendored %s: %w", dir, err))
+		}
+	}
+
+	// As with "tofu providers mirror", we always download the packages from
+	// their origin registries, in case the usual installation sources
+	// include the vendor directory of another configuration.
+	source := getproviders.NewTrustedKeysSource(
+		getproviders.NewMemoizeSource(getproviders.NewRegistrySource(m.Services)),
+		m.ProviderTrustedKeys,
+	)
+	httpGetter := getter.HttpGetter{
+		Client:                httpclient.New(),
+		Netrc:                 true,
+		XTerraformGetDisabled: true,
+	}
+	for provider := range reqs {
+		if provider.IsBuiltIn() {
+			continue
+		}
+		lock := lockedDeps.Provider(provider)
+		m.Ui.Output(fmt.Sprintf("- Vendoring %s v%s...", provider.ForDisplay(), lock.Version()))
+		for _, platform := range platforms {
+			m.Ui.Output(fmt.Sprintf("  - Downloading package for %s...", platform.String()))
+			path, _, moreDiags := mirrorProviderPackage(ctx, source, httpGetter, provider, lock.Version(), platform, providersDir)
+			diags = diags.Append(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			// The package must also be the one that the lock file selected,
+			// because "tofu init" will verify it against the lock file.
+			ok, err := getproviders.PackageMatchesAnyHash(getproviders.PackageLocalArchive(path), lock.AllHashes())
+			if err == nil && !ok {
+				err = fmt.Errorf("the package doesn't match any of the checksums recorded in the dependency lock file")
+			}
+			if err != nil {
+				os.Remove(path)
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Invalid provider package",
+					fmt.Sprintf("Failed to verify %s v%s for %s: %s.\n\nTo record the checksums for %s, run \"tofu providers lock -platform=%s\".", provider.ForDisplay(), lock.Version(), platform, err, platform, platform),
+				))
+			}
+		}
+	}
+
+	return diags.Append(m.vendorModules(modulesDir))
+}
+
+// vendorModules copies the remote module packages installed in the working
+// directory into outputDir, along with a manifest that describes them.
+//
+// Local modules in the configuration's own directory are already part of the
+// repository, so they aren't copied.
+func (m *Meta) vendorModules(outputDir string) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	installedDir := m.modulesDir()
+	installed, err := modsdir.ReadManifestSnapshotForDir(installedDir)
+	if err != nil {
+		return diags.Append(fmt.Errorf("failed to read the installed modules manifest: %w", err))
+	}
+
+	vendored := make(modsdir.Manifest)
+	copied := make(map[string]bool)
+	for key, record := range installed {
+		rel, err := filepath.Rel(installedDir, record.Dir)
+		if key == "" || err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+
+		// The installed package containing the module might contain others
+		// too, so we copy each package only once.
+		pkg := strings.SplitN(filepath.ToSlash(rel), "/", 2)[0]
+		if !copied[pkg] {
+			m.Ui.Output(fmt.Sprintf("- Vendoring module %s from %s", key, record.SourceAddr))
+			if err := copyModulePackage(filepath.Join(outputDir, pkg), filepath.Join(installedDir, pkg)); err != nil {
+				diags = diags.Append(fmt.Errorf("failed to vendor module %s: %w", key, err))
+				continue
+			}
+			copied[pkg] = true
+		}
+		record.Dir = rel
+		vendored[key] = record
+	}
+
+	if err := os.MkdirAll(outputDir, os.ModePerm); err != nil {
+		return diags.Append(fmt.Errorf("failed to create %s: %w", outputDir, err))
+	}
+	if err := vendored.WriteSnapshotToDir(outputDir); err != nil {
+		diags = diags.Append(fmt.Errorf("failed to write the vendored modules manifest: %w", err))
+	}
+	return diags
+}
+
+// vendorDir returns the vendor directory of the configuration in the given
+// directory, or an empty string if "tofu vendor" hasn't created one.
+func vendorDir(configDir string) string {
+	dir := filepath.Join(configDir, vendorDirName)
+	if _, err := os.Stat(filepath.Join(dir, "modules", modsdir.ManifestSnapshotFilename)); err != nil {
+		return ""
+	}
+	return dir
+}
+
+// restoreVendoredModules copies the module packages in the given vendor
+// directory into the working directory and records them in its manifest of
+// installed modules, so that the module installer finds them installed.
+func (m *Meta) restoreVendoredModules(vendorDir string) error {
+	srcDir := filepath.Join(vendorDir, "modules")
+	vendored, err := modsdir.ReadManifestSnapshotForDir(srcDir)
+	if err != nil {
+		return fmt.Errorf("failed to read the vendored modules manifest: %w", err)
+	}
+
+	installedDir := m.modulesDir()
+	if err := os.MkdirAll(installedDir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create local modules directory: %w", err)
+	}
+	installed, err := modsdir.ReadManifestSnapshotForDir(installedDir)
+	if err != nil {
+		return fmt.Errorf("failed to read the installed modules manifest: %w", err)
+	}
+
+	copied := make(map[string]bool)
+	for key, record := range vendored {
+		pkg := strings.SplitN(filepath.ToSlash(record.Dir), "/", 2)[0]
+		if !copied[pkg] {
+			if err := copyModulePackage(filepath.Join(installedDir, pkg), filepath.Join(srcDir, pkg)); err != nil {
+				return fmt.Errorf("failed to install vendored module %s: %w", key, err)
+			}
+			copied[pkg] = true
+		}
+		record.Dir = filepath.Join(installedDir, record.Dir)
+		installed[key] = record
+	}
+	return installed.WriteSnapshotToDir(installedDir)
+}
+
+// copyModulePackage replaces the directory dst with a copy of the module
+// package in the directory src.
+func copyModulePackage(dst, src string) error {
+	if err := os.RemoveAll(dst); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dst, os.ModePerm); err != nil {
+		return err
+	}
+	return copy.CopyDir(dst, src)
+}
+
+func (c *VendorCommand) Help() string {
+	return `
+Usage: tofu [global options] vendor [options]
+
+  Copies the provider packages selected in the dependency lock file, and the
+  remote module packages installed by "tofu init", into the "vendor"
+  directory of the current configuration.
+
+  When a configuration has a vendor directory, "tofu init" installs its
+  providers and remote modules only from there, without using the network,
+  so that the vendor directory can be committed to version control for
+  reproducible installations.
+
+  Run this command again after changing the configuration's providers or
+  modules, and after running "tofu init -upgrade".
+
+Options:
+
+  -platform=os_arch  Choose which target platform to vendor providers for.
+                     By default OpenTofu will obtain plugin packages
+                     suitable for the platform where you run this command.
+                     Use this flag multiple times to include packages for
+                     multiple target systems.
+`
+}
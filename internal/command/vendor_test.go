@@ -0,0 +1,89 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestVendor_modules(t *testing.T) {
+	td := t.TempDir()
+	testCopyDir(t, testFixturePath("init-offline-module"), td)
+	defer testChdir(t, td)()
+
+	// Simulate a previous "tofu init" that installed the remote module.
+	manifest := `{"Modules":[{"Key":"","Source":"","Dir":"."},{"Key":"remote","Source":"registry.opentofu.org/hashicorp/module-installer-acctest/aws","Version":"0.0.1","Dir":".terraform/modules/remote"}]}`
+	if err := os.MkdirAll(filepath.Join(".terraform", "modules", "remote"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(".terraform", "modules", "modules.json"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(".terraform", "modules", "remote", "main.tf"), []byte(`output "vendored" { value = true }`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	vc := &VendorCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+			View:             view,
+		},
+	}
+	if code := vc.Run(nil); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+	if _, err := os.Stat(filepath.Join("vendor", "modules", "remote", "main.tf")); err != nil {
+		t.Fatalf("module wasn't vendored: %s", err)
+	}
+
+	// With the installed modules removed, init must install the module from
+	// the vendor directory without using the network.
+	if err := os.RemoveAll(".terraform"); err != nil {
+		t.Fatal(err)
+	}
+	ui = new(cli.MockUi)
+	view, _ = testView(t)
+	ic := &InitCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+			View:             view,
+		},
+	}
+	if code := ic.Run([]string{"-backend=false"}); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+	if got := ui.OutputWriter.String(); !strings.Contains(got, "Using vendored providers and modules") {
+		t.Errorf("output doesn't mention the vendor directory:\n%s", got)
+	}
+	src, err := os.ReadFile(filepath.Join(".terraform", "modules", "remote", "main.tf"))
+	if err != nil || !strings.Contains(string(src), "vendored") {
+		t.Fatalf("vendored module wasn't installed: %s", err)
+	}
+
+	// Upgrading vendored dependencies isn't possible.
+	ui = new(cli.MockUi)
+	view, _ = testView(t)
+	ic = &InitCommand{
+		Meta: Meta{
+			testingOverrides: metaOverridesForProvider(testProvider()),
+			Ui:               ui,
+			View:             view,
+		},
+	}
+	if code := ic.Run([]string{"-backend=false", "-upgrade"}); code == 0 {
+		t.Fatal("expected error")
+	}
+	if got := ui.ErrorWriter.String(); !strings.Contains(got, "Cannot upgrade vendored dependencies") {
+		t.Errorf("wrong error:\n%s", got)
+	}
+}
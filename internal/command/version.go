@@ -7,10 +7,14 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"log"
+	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
 
 	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/command/clistate"
 	"github.com/opentofu/opentofu/internal/depsfile"
 	"github.com/opentofu/opentofu/internal/getproviders"
 )
@@ -25,9 +29,45 @@ type VersionCommand struct {
 }
 
 type VersionOutput struct {
-	Version            string            `json:"terraform_version"`
-	Platform           string            `json:"platform"`
-	ProviderSelections map[string]string `json:"provider_selections"`
+	Version            string                         `json:"terraform_version"`
+	Platform           string                         `json:"platform"`
+	ProviderSelections map[string]string              `json:"provider_selections"`
+	ProviderLocks      map[string]VersionProviderLock `json:"provider_locks"`
+	Backend            VersionBackend                 `json:"backend"`
+	Encryption         VersionEncryption              `json:"encryption"`
+	PlatformDetails    VersionPlatform                `json:"platform_details"`
+}
+
+// VersionProviderLock describes a provider selection recorded in the
+// dependency lock file.
+type VersionProviderLock struct {
+	Version     string   `json:"version"`
+	Constraints string   `json:"constraints,omitempty"`
+	Hashes      []string `json:"hashes"`
+}
+
+// VersionBackend describes the backend the working directory was
+// initialized with, and the selected workspace.
+type VersionBackend struct {
+	Type      string `json:"type"`
+	Workspace string `json:"workspace"`
+}
+
+// VersionEncryption describes which encryption features are active.
+type VersionEncryption struct {
+	// State is always false, because this version of OpenTofu does not
+	// encrypt state itself. It's included so that tools consuming this
+	// output can rely on it being present.
+	State bool `json:"state"`
+
+	PlanFiles bool `json:"plan_files"`
+}
+
+// VersionPlatform describes the platform OpenTofu is running on.
+type VersionPlatform struct {
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	GoVersion string `json:"go_version"`
 }
 
 func (c *VersionCommand) Help() string {
@@ -38,7 +78,9 @@ Usage: tofu [global options] version [options]
 
 Options:
 
-  -json       Output the version information as a JSON object.
+  -json       Output the version information as a JSON object, including
+              the selected provider versions and checksums, the backend
+              in use, and platform details.
 `
 	return strings.TrimSpace(helpText)
 }
@@ -90,9 +132,20 @@ func (c *VersionCommand) Run(args []string) int {
 
 	if jsonOutput {
 		selectionsOutput := make(map[string]string)
+		locksOutput := make(map[string]VersionProviderLock)
 		for providerAddr, lock := range providerLocks {
 			version := lock.Version().String()
 			selectionsOutput[providerAddr.String()] = version
+
+			hashes := make([]string, 0, len(lock.AllHashes()))
+			for _, hash := range lock.AllHashes() {
+				hashes = append(hashes, hash.String())
+			}
+			locksOutput[providerAddr.String()] = VersionProviderLock{
+				Version:     version,
+				Constraints: getproviders.VersionConstraintsString(lock.VersionConstraints()),
+				Hashes:      hashes,
+			}
 		}
 
 		var versionOutput string
@@ -106,6 +159,16 @@ func (c *VersionCommand) Run(args []string) int {
 			Version:            versionOutput,
 			Platform:           c.Platform.String(),
 			ProviderSelections: selectionsOutput,
+			ProviderLocks:      locksOutput,
+			Backend:            c.backendSummary(),
+			Encryption: VersionEncryption{
+				PlanFiles: c.PlanFileEncryption() != nil,
+			},
+			PlatformDetails: VersionPlatform{
+				OS:        c.Platform.OS,
+				Arch:      c.Platform.Arch,
+				GoVersion: runtime.Version(),
+			},
 		}
 
 		jsonOutput, err := json.MarshalIndent(output, "", "  ")
@@ -130,6 +193,28 @@ func (c *VersionCommand) Run(args []string) int {
 	return 0
 }
 
+// backendSummary returns the type of the backend the working directory was
+// initialized with and the selected workspace. Like the provider selections,
+// this is best-effort: a working directory that hasn't been initialized with
+// another backend is reported as using the local backend.
+func (c *VersionCommand) backendSummary() VersionBackend {
+	ret := VersionBackend{Type: "local"}
+
+	sMgr := &clistate.LocalState{Path: filepath.Join(c.DataDir(), DefaultStateFilename)}
+	if err := sMgr.RefreshState(); err != nil {
+		log.Printf("[WARN] version: failed to read the backend configuration: %s", err)
+	} else if s := sMgr.State(); s != nil && s.Backend != nil && s.Backend.Type != "" {
+		ret.Type = s.Backend.Type
+	}
+
+	workspace, err := c.Workspace()
+	if err != nil {
+		log.Printf("[WARN] version: failed to determine the workspace: %s", err)
+	}
+	ret.Workspace = workspace
+	return ret
+}
+
 func (c *VersionCommand) Synopsis() string {
 	return "Show the current OpenTofu version"
 }
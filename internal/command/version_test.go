@@ -4,6 +4,8 @@
 package command
 
 import (
+	"fmt"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -13,6 +15,7 @@ import (
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/depsfile"
 	"github.com/opentofu/opentofu/internal/getproviders"
+	legacy "github.com/opentofu/opentofu/internal/legacy/tofu"
 )
 
 func TestVersionCommand_implements(t *testing.T) {
@@ -30,8 +33,8 @@ func TestVersion(t *testing.T) {
 	locks.SetProvider(
 		addrs.NewDefaultProvider("test2"),
 		getproviders.MustParseVersion("1.2.3"),
-		nil,
-		nil,
+		getproviders.MustParseVersionConstraints("~> 1.2"),
+		[]getproviders.Hash{"h1:2jmq4xxNgeHY8BsxsPMpXkifdtQRGqNyCJSutPb/CH0="},
 	)
 	locks.SetProvider(
 		addrs.NewDefaultProvider("test1"),
@@ -109,13 +112,27 @@ func TestVersion_json(t *testing.T) {
 	}
 
 	actual := strings.TrimSpace(ui.OutputWriter.String())
-	expected := strings.TrimSpace(`
+	expected := strings.TrimSpace(fmt.Sprintf(`
 {
   "terraform_version": "4.5.6",
   "platform": "aros_riscv64",
-  "provider_selections": {}
+  "provider_selections": {},
+  "provider_locks": {},
+  "backend": {
+    "type": "local",
+    "workspace": "default"
+  },
+  "encryption": {
+    "state": false,
+    "plan_files": false
+  },
+  "platform_details": {
+    "os": "aros",
+    "arch": "riscv64",
+    "go_version": %q
+  }
 }
-`)
+`, runtime.Version()))
 	if diff := cmp.Diff(expected, actual); diff != "" {
 		t.Fatalf("wrong output\n%s", diff)
 	}
@@ -130,8 +147,8 @@ func TestVersion_json(t *testing.T) {
 	locks.SetProvider(
 		addrs.NewDefaultProvider("test2"),
 		getproviders.MustParseVersion("1.2.3"),
-		nil,
-		nil,
+		getproviders.MustParseVersionConstraints("~> 1.2"),
+		[]getproviders.Hash{"h1:2jmq4xxNgeHY8BsxsPMpXkifdtQRGqNyCJSutPb/CH0="},
 	)
 	locks.SetProvider(
 		addrs.NewDefaultProvider("test1"),
@@ -150,21 +167,58 @@ func TestVersion_json(t *testing.T) {
 	if err := c.replaceLockedDependencies(locks); err != nil {
 		t.Fatal(err)
 	}
+
+	// We'll also initialize the working directory with a non-local backend,
+	// select a workspace, and enable plan file encryption.
+	testStateFileRemote(t, &legacy.State{
+		Backend: &legacy.BackendState{Type: "http"},
+	})
+	if err := c.SetWorkspace("staging"); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(PlanEncryptionKeyEnvVar, "secret")
+
 	if code := c.Run([]string{"-json"}); code != 0 {
 		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
 	}
 
 	actual = strings.TrimSpace(ui.OutputWriter.String())
-	expected = strings.TrimSpace(`
+	expected = strings.TrimSpace(fmt.Sprintf(`
 {
   "terraform_version": "4.5.6-foo",
   "platform": "aros_riscv64",
   "provider_selections": {
     "registry.opentofu.org/hashicorp/test1": "7.8.9-beta.2",
     "registry.opentofu.org/hashicorp/test2": "1.2.3"
+  },
+  "provider_locks": {
+    "registry.opentofu.org/hashicorp/test1": {
+      "version": "7.8.9-beta.2",
+      "hashes": []
+    },
+    "registry.opentofu.org/hashicorp/test2": {
+      "version": "1.2.3",
+      "constraints": "~\u003e 1.2",
+      "hashes": [
+        "h1:2jmq4xxNgeHY8BsxsPMpXkifdtQRGqNyCJSutPb/CH0="
+      ]
+    }
+  },
+  "backend": {
+    "type": "http",
+    "workspace": "staging"
+  },
+  "encryption": {
+    "state": false,
+    "plan_files": true
+  },
+  "platform_details": {
+    "os": "aros",
+    "arch": "riscv64",
+    "go_version": %q
   }
 }
-`)
+`, runtime.Version()))
 	if diff := cmp.Diff(expected, actual); diff != "" {
 		t.Fatalf("wrong output\n%s", diff)
 	}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/opentofu/opentofu/internal/command/arguments"
 	"github.com/opentofu/opentofu/internal/command/format"
+	"github.com/opentofu/opentofu/internal/command/timings"
 	"github.com/opentofu/opentofu/internal/command/views/json"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/tfdiags"
@@ -18,10 +19,26 @@ import (
 type Apply interface {
 	ResourceCount(stateOutPath string)
 	Outputs(outputValues map[string]*states.OutputValue)
+	CheckResults(results *states.CheckResults)
 
 	Operation() Operation
 	Hooks() []tofu.Hook
 
+	// Estimates enables estimates of how long the apply will take, based
+	// on the durations of earlier applies recorded in the given history.
+	Estimates(history *timings.History, parallelism int)
+
+	// GroupProgress groups the progress messages of the apply by module,
+	// printing the messages for each module together once all of its
+	// changes are complete, along with a periodic count of the changes in
+	// progress. If collapsible is true then each module's messages are
+	// marked as a group that GitHub Actions shows collapsed.
+	GroupProgress(collapsible bool)
+
+	// Simulation reports where a simulated apply wrote the resulting state
+	// and the trace of its operations.
+	Simulation(statePath, tracePath string)
+
 	Diagnostics(diags tfdiags.Diagnostics)
 	HelpPrompt()
 }
@@ -56,11 +73,14 @@ type ApplyHuman struct {
 	inAutomation bool
 
 	countHook *countHook
+	estimates *applyEstimates
+	progress  *moduleProgress
 }
 
 var _ Apply = (*ApplyHuman)(nil)
 
 func (v *ApplyHuman) ResourceCount(stateOutPath string) {
+	v.flushProgress()
 	if v.destroy {
 		v.view.streams.Printf(
 			v.view.colorize.Color("[reset][bold][green]\nDestroy complete! Resources: %d destroyed.\n"),
@@ -91,22 +111,62 @@ func (v *ApplyHuman) ResourceCount(stateOutPath string) {
 func (v *ApplyHuman) Outputs(outputValues map[string]*states.OutputValue) {
 	if len(outputValues) > 0 {
 		v.view.streams.Print(v.view.colorize.Color("[reset][bold][green]\nOutputs:\n\n"))
-		NewOutput(arguments.ViewHuman, v.view).Output("", outputValues)
+		NewOutput(arguments.ViewHuman, "", v.view).Output("", outputValues)
 	}
 }
 
+// CheckResults does nothing for the human-readable view, because failing
+// checks are already reported as diagnostics.
+func (v *ApplyHuman) CheckResults(results *states.CheckResults) {
+}
+
 func (v *ApplyHuman) Operation() Operation {
-	return NewOperation(arguments.ViewHuman, v.inAutomation, v.view)
+	return &OperationHuman{
+		view:         v.view,
+		inAutomation: v.inAutomation,
+		estimates:    v.estimates,
+		progress:     v.progress,
+	}
 }
 
 func (v *ApplyHuman) Hooks() []tofu.Hook {
+	uiHook := NewUiHook(v.view)
+	uiHook.estimates = v.estimates
+	uiHook.progress = v.progress
 	return []tofu.Hook{
 		v.countHook,
-		NewUiHook(v.view),
+		uiHook,
+	}
+}
+
+func (v *ApplyHuman) Estimates(history *timings.History, parallelism int) {
+	v.estimates = newApplyEstimates(history, parallelism)
+}
+
+func (v *ApplyHuman) GroupProgress(collapsible bool) {
+	v.progress = newModuleProgress(collapsible)
+}
+
+func (v *ApplyHuman) Simulation(statePath, tracePath string) {
+	v.flushProgress()
+	v.view.streams.Print(v.view.colorize.Color("[reset][bold][yellow]\nThis was a simulation, so no real infrastructure was changed.[reset]\n\n"))
+	v.view.streams.Printf("Simulated state: %s\n", statePath)
+	v.view.streams.Printf("Operation trace: %s\n", tracePath)
+}
+
+// flushProgress prints the messages for the modules whose changes didn't all
+// complete, such as because the apply failed.
+func (v *ApplyHuman) flushProgress() {
+	if v.progress == nil {
+		return
+	}
+	for _, line := range v.progress.flush() {
+		v.view.streams.Println(line)
 	}
 }
 
 func (v *ApplyHuman) Diagnostics(diags tfdiags.Diagnostics) {
+	v.flushProgress()
 	v.view.Diagnostics(diags)
 }
 
@@ -155,6 +215,13 @@ func (v *ApplyJSON) Outputs(outputValues map[string]*states.OutputValue) {
 	}
 }
 
+func (v *ApplyJSON) CheckResults(results *states.CheckResults) {
+	if results == nil || results.ConfigResults.Len() == 0 {
+		return
+	}
+	v.view.CheckResults(json.NewCheckResults(results))
+}
+
 func (v *ApplyJSON) Operation() Operation {
 	return &OperationJSON{view: v.view}
 }
@@ -166,6 +233,20 @@ func (v *ApplyJSON) Hooks() []tofu.Hook {
 	}
 }
 
+// Estimates does nothing for the JSON view, since consumers of the JSON
+// output can make their own estimates from the timestamps of the messages.
+func (v *ApplyJSON) Estimates(history *timings.History, parallelism int) {
+}
+
+// GroupProgress does nothing for the JSON view, since each message
+// identifies the resource instance it is about.
+func (v *ApplyJSON) GroupProgress(collapsible bool) {
+}
+
+func (v *ApplyJSON) Simulation(statePath, tracePath string) {
+	v.view.Log(fmt.Sprintf("Simulation complete: simulated state written to %s, operation trace written to %s", statePath, tracePath))
+}
+
 func (v *ApplyJSON) Diagnostics(diags tfdiags.Diagnostics) {
 	v.view.Diagnostics(diags)
 }
@@ -0,0 +1,93 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package views
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/command/timings"
+	"github.com/opentofu/opentofu/internal/plans"
+)
+
+// applyEstimates provides the estimated durations of the changes in an
+// apply, based on the durations of earlier applies. It collects the planned
+// changes from the operation view, so that the UI hook can announce the
+// estimated total when the apply begins.
+type applyEstimates struct {
+	history     *timings.History
+	parallelism int
+
+	mu        sync.Mutex
+	changes   []*plans.ResourceInstanceChangeSrc
+	announced bool
+}
+
+func newApplyEstimates(history *timings.History, parallelism int) *applyEstimates {
+	return &applyEstimates{
+		history:     history,
+		parallelism: parallelism,
+	}
+}
+
+// planned records a change that the apply will make.
+func (e *applyEstimates) planned(change *plans.ResourceInstanceChangeSrc) {
+	if change.Action == plans.NoOp {
+		return
+	}
+	e.mu.Lock()
+	e.changes = append(e.changes, change)
+	e.mu.Unlock()
+}
+
+// announcement returns a message describing the estimated total duration
+// of the apply the first time it is called after the planned changes have
+// been recorded, and an empty string otherwise or if there's no history to
+// base an estimate on.
+func (e *applyEstimates) announcement() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.announced || len(e.changes) == 0 {
+		return ""
+	}
+	e.announced = true
+
+	total, count := e.history.Total(e.changes, e.parallelism)
+	switch {
+	case count == 0:
+		return ""
+	case count == len(e.changes):
+		return fmt.Sprintf("Estimated time to apply: about %s, based on earlier applies.", timings.FormatDuration(total))
+	default:
+		return fmt.Sprintf(
+			"Estimated time to apply: about %s, based on earlier applies of %d of the %d changes.",
+			timings.FormatDuration(total), count, len(e.changes),
+		)
+	}
+}
+
+// estimate returns the expected duration of the given action on the given
+// resource instance, or zero if there's no history to base an estimate on.
+func (e *applyEstimates) estimate(addr addrs.AbsResourceInstance, action plans.Action) time.Duration {
+	d, ok := e.history.Estimate(addr, action)
+	if !ok {
+		return 0
+	}
+	return d
+}
+
+// progressEstimate describes how the time elapsed so far for an action
+// compares to its expected duration, for the periodic progress messages.
+func progressEstimate(elapsed, expected time.Duration) string {
+	if expected <= 0 {
+		return ""
+	}
+	if remaining := expected - elapsed; remaining >= time.Second {
+		return fmt.Sprintf(", about %s remaining", timings.FormatDuration(remaining))
+	}
+	return fmt.Sprintf(", usually takes %s", timings.FormatDuration(expected))
+}
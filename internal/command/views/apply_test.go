@@ -8,6 +8,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/checks"
 	"github.com/opentofu/opentofu/internal/command/arguments"
 	"github.com/opentofu/opentofu/internal/lang/marks"
 	"github.com/opentofu/opentofu/internal/states"
@@ -268,3 +270,75 @@ func TestApplyJSON_outputs(t *testing.T) {
 	}
 	testJSONViewOutputEquals(t, done(t).Stdout(), want)
 }
+
+func TestApplyJSON_checkResults(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	v := NewApply(arguments.ViewJSON, false, NewView(streams))
+
+	checkAddr := addrs.Check{Name: "health"}
+	objects := addrs.MakeMap[addrs.Checkable, *states.CheckResultObject]()
+	objects.Put(checkAddr.Absolute(addrs.RootModuleInstance), &states.CheckResultObject{
+		Status:          checks.StatusFail,
+		FailureMessages: []string{"endpoint is unhealthy"},
+	})
+	results := &states.CheckResults{
+		ConfigResults: addrs.MakeMap[addrs.ConfigCheckable, *states.CheckResultAggregate](),
+	}
+	results.ConfigResults.Put(checkAddr.InModule(addrs.RootModule), &states.CheckResultAggregate{
+		Status:        checks.StatusFail,
+		ObjectResults: objects,
+	})
+
+	v.CheckResults(results)
+
+	want := []map[string]interface{}{
+		{
+			"@level":   "info",
+			"@message": "Checks: 0 passed, 1 failed, 0 errored, 0 unknown",
+			"@module":  "tofu.ui",
+			"type":     "check_results",
+			"check_results": map[string]interface{}{
+				"pass":    float64(0),
+				"fail":    float64(1),
+				"error":   float64(0),
+				"unknown": float64(0),
+				"checks": []interface{}{
+					map[string]interface{}{
+						"address": map[string]interface{}{
+							"kind":       "check",
+							"name":       "health",
+							"to_display": "check.health",
+						},
+						"status": "fail",
+						"instances": []interface{}{
+							map[string]interface{}{
+								"address": map[string]interface{}{
+									"to_display": "check.health",
+								},
+								"status": "fail",
+								"problems": []interface{}{
+									map[string]interface{}{
+										"message": "endpoint is unhealthy",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	testJSONViewOutputEquals(t, done(t).Stdout(), want)
+}
+
+// CheckResults should do nothing if there are no checkable objects.
+func TestApplyJSON_checkResultsEmpty(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	v := NewApply(arguments.ViewJSON, false, NewView(streams))
+
+	v.CheckResults(&states.CheckResults{})
+
+	if got := done(t).Stdout(); strings.Contains(got, "check_results") {
+		t.Errorf("expected no check results, got: %q", got)
+	}
+}
@@ -25,11 +25,16 @@ import (
 const defaultPeriodicUiTimer = 10 * time.Second
 const maxIdLen = 80
 
+// refreshBatchSummaryMin is the smallest batch of objects being refreshed
+// whose progress is summarized rather than reported for each object.
+const refreshBatchSummaryMin = 20
+
 func NewUiHook(view *View) *UiHook {
 	return &UiHook{
 		view:            view,
 		periodicUiTimer: defaultPeriodicUiTimer,
 		resources:       make(map[string]uiResourceState),
+		refreshBatches:  make(map[string]*uiRefreshBatch),
 	}
 }
 
@@ -43,6 +48,19 @@ type UiHook struct {
 
 	resources     map[string]uiResourceState
 	resourcesLock sync.Mutex
+
+	// refreshBatches tracks the summarized batches of objects being
+	// refreshed, by the address of each object in the batch.
+	refreshBatches     map[string]*uiRefreshBatch
+	refreshBatchesLock sync.Mutex
+
+	// estimates optionally provides the expected durations of the changes
+	// during an apply, based on earlier applies.
+	estimates *applyEstimates
+
+	// progress optionally groups the progress messages of an apply by
+	// module, in place of reporting each change that is still in progress.
+	progress *moduleProgress
 }
 
 var _ tofu.Hook = (*UiHook)(nil)
@@ -54,11 +72,24 @@ type uiResourceState struct {
 	Op             uiResourceOp
 	Start          time.Time
 
+	// Expected is the expected duration of the operation, or zero if
+	// there's no estimate.
+	Expected time.Duration
+
 	DoneCh chan struct{} // To be used for cancellation
 
 	done chan struct{} // used to coordinate tests
 }
 
+// uiRefreshBatch tracks the progress of a batch of objects being refreshed
+// using the same provider configuration.
+type uiRefreshBatch struct {
+	Provider   addrs.AbsProviderConfig
+	Total      int
+	Done       int
+	LastReport time.Time
+}
+
 // uiResourceOp is an enum for operations on a resource
 type uiResourceOp byte
 
@@ -112,13 +143,26 @@ func (h *UiHook) PreApply(addr addrs.AbsResourceInstance, gen states.Generation,
 		idValue = ""
 	}
 
+	var expected time.Duration
+	if h.estimates != nil && operation != "" && action != plans.Read {
+		if msg := h.estimates.announcement(); msg != "" {
+			h.println(h.view.colorize.Color("[reset][bold]" + msg + "[reset]"))
+		}
+		expected = h.estimates.estimate(addr, action)
+	}
+
 	if operation != "" {
-		h.println(fmt.Sprintf(
+		msg := fmt.Sprintf(
 			h.view.colorize.Color("[reset][bold]%s: %s%s[reset]"),
 			dispAddr,
 			operation,
 			stateIdSuffix,
-		))
+		)
+		if h.progress == nil {
+			h.println(msg)
+		} else if h.progress.begin(addr, msg) {
+			go h.reportProgress()
+		}
 	}
 
 	key := addr.String()
@@ -128,6 +172,7 @@ func (h *UiHook) PreApply(addr addrs.AbsResourceInstance, gen states.Generation,
 		IDValue:  idValue,
 		Op:       op,
 		Start:    time.Now().Round(time.Second),
+		Expected: expected,
 		DoneCh:   make(chan struct{}),
 		done:     make(chan struct{}),
 	}
@@ -136,8 +181,9 @@ func (h *UiHook) PreApply(addr addrs.AbsResourceInstance, gen states.Generation,
 	h.resources[key] = uiState
 	h.resourcesLock.Unlock()
 
-	// Start goroutine that shows progress
-	if op != uiResourceNoOp {
+	// Start goroutine that shows progress, unless it is reported for the
+	// whole apply instead.
+	if op != uiResourceNoOp && h.progress == nil {
 		go h.stillApplying(uiState)
 	}
 
@@ -174,16 +220,31 @@ func (h *UiHook) stillApplying(state uiResourceState) {
 			idSuffix = fmt.Sprintf("%s=%s, ", state.IDKey, truncateId(state.IDValue, maxIdLen))
 		}
 
+		elapsed := time.Now().Round(time.Second).Sub(state.Start)
 		h.println(fmt.Sprintf(
-			h.view.colorize.Color("[reset][bold]%s: %s [%s%s elapsed][reset]"),
+			h.view.colorize.Color("[reset][bold]%s: %s [%s%s elapsed%s][reset]"),
 			state.DispAddr,
 			msg,
 			idSuffix,
-			time.Now().Round(time.Second).Sub(state.Start),
+			elapsed,
+			progressEstimate(elapsed, state.Expected),
 		))
 	}
 }
 
+// reportProgress periodically prints the live counter of the changes in
+// progress, until there are none.
+func (h *UiHook) reportProgress() {
+	for {
+		time.Sleep(h.periodicUiTimer)
+		msg, ok := h.progress.status()
+		if !ok {
+			return
+		}
+		h.println(h.view.colorize.Color("[reset][bold]" + msg + "[reset]"))
+	}
+}
+
 func (h *UiHook) PostApply(addr addrs.AbsResourceInstance, gen states.Generation, newState cty.Value, applyerr error) (tofu.HookAction, error) {
 	id := addr.String()
 
@@ -220,6 +281,9 @@ func (h *UiHook) PostApply(addr addrs.AbsResourceInstance, gen states.Generation
 
 	if applyerr != nil {
 		// Errors are collected and printed in ApplyCommand, no need to duplicate
+		if h.progress != nil {
+			h.printSection(h.progress.end(addr, ""))
+		}
 		return tofu.HookActionContinue, nil
 	}
 
@@ -232,13 +296,17 @@ func (h *UiHook) PostApply(addr addrs.AbsResourceInstance, gen states.Generation
 		h.view.colorize.Color("[reset][bold]%s: %s after %s%s"),
 		addrStr, msg, time.Now().Round(time.Second).Sub(state.Start), stateIdSuffix)
 
+	if h.progress != nil {
+		h.printSection(h.progress.end(addr, colorized))
+		return tofu.HookActionContinue, nil
+	}
 	h.println(colorized)
 
 	return tofu.HookActionContinue, nil
 }
 
 func (h *UiHook) PreProvisionInstanceStep(addr addrs.AbsResourceInstance, typeName string) (tofu.HookAction, error) {
-	h.println(fmt.Sprintf(
+	h.printlnFor(addr, fmt.Sprintf(
 		h.view.colorize.Color("[reset][bold]%s: Provisioning with '%s'...[reset]"),
 		addr, typeName,
 	))
@@ -261,10 +329,15 @@ func (h *UiHook) ProvisionOutput(addr addrs.AbsResourceInstance, typeName string
 		}
 	}
 
-	h.println(strings.TrimSpace(buf.String()))
+	h.printlnFor(addr, strings.TrimSpace(buf.String()))
 }
 
 func (h *UiHook) PreRefresh(addr addrs.AbsResourceInstance, gen states.Generation, priorState cty.Value) (tofu.HookAction, error) {
+	if gen == states.CurrentGen && h.refreshBatch(addr) != nil {
+		// Progress for the batch is reported as a whole instead.
+		return tofu.HookActionContinue, nil
+	}
+
 	var stateIdSuffix string
 	if k, v := format.ObjectValueID(priorState); k != "" && v != "" {
 		stateIdSuffix = fmt.Sprintf(" [%s=%s]", k, v)
@@ -281,6 +354,89 @@ func (h *UiHook) PreRefresh(addr addrs.AbsResourceInstance, gen states.Generatio
 	return tofu.HookActionContinue, nil
 }
 
+func (h *UiHook) PostRefresh(addr addrs.AbsResourceInstance, gen states.Generation, priorState cty.Value, newState cty.Value) (tofu.HookAction, error) {
+	if gen != states.CurrentGen {
+		return tofu.HookActionContinue, nil
+	}
+
+	h.refreshBatchesLock.Lock()
+	batch := h.refreshBatches[addr.String()]
+	if batch == nil {
+		h.refreshBatchesLock.Unlock()
+		return tofu.HookActionContinue, nil
+	}
+	batch.Done++
+	report := time.Since(batch.LastReport) >= h.periodicUiTimer
+	if report {
+		batch.LastReport = time.Now()
+	}
+	done, total := batch.Done, batch.Total
+	h.refreshBatchesLock.Unlock()
+
+	if report {
+		h.println(fmt.Sprintf(
+			h.view.colorize.Color("[reset][bold]%s: Still refreshing state... [%d/%d objects]"),
+			batch.Provider, done, total,
+		))
+	}
+	return tofu.HookActionContinue, nil
+}
+
+func (h *UiHook) PreRefreshBatch(provider addrs.AbsProviderConfig, instances []addrs.AbsResourceInstance) (tofu.HookAction, error) {
+	// Small batches are reported object by object, as if they were not
+	// batched at all.
+	if len(instances) < refreshBatchSummaryMin {
+		return tofu.HookActionContinue, nil
+	}
+
+	batch := &uiRefreshBatch{
+		Provider:   provider,
+		Total:      len(instances),
+		LastReport: time.Now(),
+	}
+	h.refreshBatchesLock.Lock()
+	for _, addr := range instances {
+		h.refreshBatches[addr.String()] = batch
+	}
+	h.refreshBatchesLock.Unlock()
+
+	h.println(fmt.Sprintf(
+		h.view.colorize.Color("[reset][bold]%s: Refreshing state of %d objects..."),
+		provider, len(instances),
+	))
+	return tofu.HookActionContinue, nil
+}
+
+func (h *UiHook) PostRefreshBatch(provider addrs.AbsProviderConfig, instances []addrs.AbsResourceInstance) (tofu.HookAction, error) {
+	if len(instances) < refreshBatchSummaryMin {
+		return tofu.HookActionContinue, nil
+	}
+
+	h.refreshBatchesLock.Lock()
+	batch := h.refreshBatches[instances[0].String()]
+	for _, addr := range instances {
+		delete(h.refreshBatches, addr.String())
+	}
+	h.refreshBatchesLock.Unlock()
+	if batch == nil {
+		return tofu.HookActionContinue, nil
+	}
+
+	h.println(fmt.Sprintf(
+		h.view.colorize.Color("[reset][bold]%s: Refreshed state of %d objects"),
+		provider, batch.Done,
+	))
+	return tofu.HookActionContinue, nil
+}
+
+// refreshBatch returns the summarized batch that the given object is being
+// refreshed in, or nil if its refresh is reported individually.
+func (h *UiHook) refreshBatch(addr addrs.AbsResourceInstance) *uiRefreshBatch {
+	h.refreshBatchesLock.Lock()
+	defer h.refreshBatchesLock.Unlock()
+	return h.refreshBatches[addr.String()]
+}
+
 func (h *UiHook) PreImportState(addr addrs.AbsResourceInstance, importID string) (tofu.HookAction, error) {
 	h.println(fmt.Sprintf(
 		h.view.colorize.Color("[reset][bold]%s: Importing from ID %q..."),
@@ -338,6 +494,28 @@ func (h *UiHook) println(s string) {
 	h.view.streams.Println(s)
 }
 
+// printlnFor prints a message about a change to the given resource instance,
+// unless the message is held back to print in the section for its module.
+func (h *UiHook) printlnFor(addr addrs.AbsResourceInstance, s string) {
+	if h.progress != nil && h.progress.output(addr, s) {
+		return
+	}
+	h.println(s)
+}
+
+// printSection prints the lines of a module's section of the progress
+// messages together, so that they don't interleave with other messages.
+func (h *UiHook) printSection(lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	h.viewLock.Lock()
+	defer h.viewLock.Unlock()
+	for _, line := range lines {
+		h.view.streams.Println(line)
+	}
+}
+
 // scanLines is basically copied from the Go standard library except
 // we've modified it to also fine `\r`.
 func scanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
@@ -4,6 +4,7 @@
 package views
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"testing"
@@ -11,10 +12,12 @@ import (
 
 	"strings"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/command/timings"
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
@@ -142,6 +145,179 @@ test_instance.foo: Still modifying... [id=test, 3s elapsed]
 	}
 }
 
+// Test the PreApply hook's announcement of the estimated total duration of
+// the apply and the estimated remaining time in the "still working" lines.
+func TestUiHookPreApply_estimates(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	view := NewView(streams)
+	h := NewUiHook(view)
+	h.periodicUiTimer = 1 * time.Second
+
+	addr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "test_instance",
+		Name: "foo",
+	}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance)
+	other := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "other_instance",
+		Name: "bar",
+	}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance)
+
+	history, err := timings.Load(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	history.Record(addr, plans.Create, 10*time.Minute)
+	h.estimates = newApplyEstimates(history, 10)
+	h.estimates.planned(&plans.ResourceInstanceChangeSrc{Addr: addr, ChangeSrc: plans.ChangeSrc{Action: plans.Create}})
+	h.estimates.planned(&plans.ResourceInstanceChangeSrc{Addr: other, ChangeSrc: plans.ChangeSrc{Action: plans.Create}})
+
+	priorState := cty.NullVal(cty.Object(map[string]cty.Type{
+		"id": cty.String,
+	}))
+	plannedNewState := cty.ObjectVal(map[string]cty.Value{
+		"id": cty.UnknownVal(cty.String),
+	})
+
+	action, err := h.PreApply(addr, states.CurrentGen, plans.Create, priorState, plannedNewState)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action != tofu.HookActionContinue {
+		t.Fatalf("Expected hook to continue, given: %#v", action)
+	}
+
+	time.Sleep(1100 * time.Millisecond)
+
+	// stop the background writer
+	uiState := h.resources[addr.String()]
+	close(uiState.DoneCh)
+	<-uiState.done
+
+	expectedOutput := `Estimated time to apply: about 10m, based on earlier applies of 1 of the 2 changes.
+test_instance.foo: Creating...
+test_instance.foo: Still creating... [1s elapsed, about 10m remaining]
+`
+	result := done(t)
+	output := result.Stdout()
+	if output != expectedOutput {
+		t.Fatalf("Output didn't match.\nExpected: %q\nGiven: %q", expectedOutput, output)
+	}
+}
+
+func TestUiHookApply_groupedProgress(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	view := NewView(streams)
+	h := NewUiHook(view)
+	h.periodicUiTimer = time.Hour
+	h.progress = newModuleProgress(false)
+
+	resource := func(module addrs.ModuleInstance, name string) addrs.AbsResourceInstance {
+		return addrs.Resource{
+			Mode: addrs.ManagedResourceMode,
+			Type: "test_instance",
+			Name: name,
+		}.Instance(addrs.NoKey).Absolute(module)
+	}
+	network := addrs.RootModuleInstance.Child("network", addrs.NoKey)
+	foo, bar, baz := resource(network, "foo"), resource(network, "bar"), resource(addrs.RootModuleInstance, "baz")
+	h.progress.planned(&plans.ResourceInstanceChangeSrc{Addr: foo, ChangeSrc: plans.ChangeSrc{Action: plans.Create}})
+	h.progress.planned(&plans.ResourceInstanceChangeSrc{Addr: bar, ChangeSrc: plans.ChangeSrc{Action: plans.Create}})
+	h.progress.planned(&plans.ResourceInstanceChangeSrc{Addr: baz, ChangeSrc: plans.ChangeSrc{Action: plans.Update}})
+
+	priorState := cty.NullVal(cty.Object(map[string]cty.Type{
+		"id": cty.String,
+	}))
+	plannedNewState := cty.ObjectVal(map[string]cty.Value{
+		"id": cty.UnknownVal(cty.String),
+	})
+	newState := func(id string) cty.Value {
+		return cty.ObjectVal(map[string]cty.Value{
+			"id": cty.StringVal(id),
+		})
+	}
+
+	for _, addr := range []addrs.AbsResourceInstance{foo, baz, bar} {
+		action := plans.Create
+		if addr.Equal(baz) {
+			action = plans.Update
+		}
+		if _, err := h.PreApply(addr, states.CurrentGen, action, priorState, plannedNewState); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	msg, ok := h.progress.status()
+	if !ok {
+		t.Fatal("no changes in progress")
+	}
+	if want := "Progress: 0 of 3 changes complete, 3 in progress in Root module (1), module.network (2)."; msg != want {
+		t.Errorf("wrong status\ngot:  %s\nwant: %s", msg, want)
+	}
+
+	if _, err := h.PostApply(foo, states.CurrentGen, newState("foo"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.PostApply(baz, states.CurrentGen, newState("baz"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.PostApply(bar, states.CurrentGen, cty.NilVal, errors.New("failed")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := h.progress.status(); ok {
+		t.Error("changes still in progress after they all completed")
+	}
+
+	// The sections for each module are printed once all of their changes
+	// are complete, without any interleaving.
+	expectedOutput := `Root module: 1 change complete after 0s
+  test_instance.baz: Modifying...
+  test_instance.baz: Modifications complete after 0s [id=baz]
+module.network: 2 changes complete after 0s
+  module.network.test_instance.foo: Creating...
+  module.network.test_instance.bar: Creating...
+  module.network.test_instance.foo: Creation complete after 0s [id=foo]
+`
+	output := regexp.MustCompile(`after \d+s`).ReplaceAllString(done(t).Stdout(), "after 0s")
+	if output != expectedOutput {
+		t.Fatalf("Output didn't match.\nExpected: %q\nGiven: %q", expectedOutput, output)
+	}
+}
+
+func TestModuleProgress_flush(t *testing.T) {
+	p := newModuleProgress(true)
+	addr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "test_instance",
+		Name: "foo",
+	}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance.Child("db", addrs.NoKey))
+	p.planned(&plans.ResourceInstanceChangeSrc{Addr: addr, ChangeSrc: plans.ChangeSrc{Action: plans.DeleteThenCreate}})
+
+	p.begin(addr, "module.db.test_instance.foo: Destroying...")
+	if lines := p.end(addr, "module.db.test_instance.foo: Destruction complete after 1s"); lines != nil {
+		t.Fatalf("section printed before the replacement completed: %q", lines)
+	}
+
+	// If the apply fails before the replacement is created, the section
+	// is printed at the end with what was completed.
+	got := p.flush()
+	want := []string{
+		"::group::module.db: 1 of 2 changes complete after 0s",
+		"module.db.test_instance.foo: Destroying...",
+		"module.db.test_instance.foo: Destruction complete after 1s",
+		"::endgroup::",
+	}
+	got[0] = regexp.MustCompile(`after \d+s$`).ReplaceAllString(got[0], "after 0s")
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong section\n%s", diff)
+	}
+	if got := p.flush(); len(got) != 0 {
+		t.Errorf("section printed twice: %q", got)
+	}
+}
+
 // Test the PreApply hook's destroy path, including passing a deposed key as
 // the gen argument.
 func TestUiHookPreApply_destroy(t *testing.T) {
@@ -472,6 +648,101 @@ func TestPreRefresh_noID(t *testing.T) {
 	}
 }
 
+// Test that the refresh of a large batch of objects is summarized, rather
+// than reported for each object.
+func TestRefreshBatch(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	view := NewView(streams)
+	h := NewUiHook(view)
+	h.periodicUiTimer = 0
+
+	provider := addrs.AbsProviderConfig{
+		Module:   addrs.RootModule,
+		Provider: addrs.NewDefaultProvider("test"),
+	}
+	var instances []addrs.AbsResourceInstance
+	for i := 0; i < refreshBatchSummaryMin; i++ {
+		instances = append(instances, addrs.Resource{
+			Mode: addrs.ManagedResourceMode,
+			Type: "test_instance",
+			Name: "foo",
+		}.Instance(addrs.IntKey(i)).Absolute(addrs.RootModuleInstance))
+	}
+	priorState := cty.ObjectVal(map[string]cty.Value{
+		"id": cty.StringVal("test"),
+	})
+
+	if _, err := h.PreRefreshBatch(provider, instances); err != nil {
+		t.Fatal(err)
+	}
+	for _, addr := range instances[:2] {
+		if _, err := h.PreRefresh(addr, states.CurrentGen, priorState); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := h.PostRefresh(addr, states.CurrentGen, priorState, priorState); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := h.PostRefreshBatch(provider, instances); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once the batch is complete, objects are reported individually again.
+	if _, err := h.PreRefresh(instances[0], states.CurrentGen, priorState); err != nil {
+		t.Fatal(err)
+	}
+
+	result := done(t)
+	want := `provider["registry.opentofu.org/hashicorp/test"]: Refreshing state of 20 objects...
+provider["registry.opentofu.org/hashicorp/test"]: Still refreshing state... [1/20 objects]
+provider["registry.opentofu.org/hashicorp/test"]: Still refreshing state... [2/20 objects]
+provider["registry.opentofu.org/hashicorp/test"]: Refreshed state of 2 objects
+test_instance.foo[0]: Refreshing state... [id=test]
+`
+	if got := result.Stdout(); got != want {
+		t.Fatalf("unexpected output\n got: %q\nwant: %q", got, want)
+	}
+}
+
+// Test that the refresh of a small batch of objects is still reported for
+// each object.
+func TestRefreshBatch_small(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	view := NewView(streams)
+	h := NewUiHook(view)
+
+	provider := addrs.AbsProviderConfig{
+		Module:   addrs.RootModule,
+		Provider: addrs.NewDefaultProvider("test"),
+	}
+	addr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "test_instance",
+		Name: "foo",
+	}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance)
+	priorState := cty.ObjectVal(map[string]cty.Value{
+		"id": cty.StringVal("test"),
+	})
+
+	if _, err := h.PreRefreshBatch(provider, []addrs.AbsResourceInstance{addr}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.PreRefresh(addr, states.CurrentGen, priorState); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.PostRefresh(addr, states.CurrentGen, priorState, priorState); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.PostRefreshBatch(provider, []addrs.AbsResourceInstance{addr}); err != nil {
+		t.Fatal(err)
+	}
+
+	result := done(t)
+	if got, want := result.Stdout(), "test_instance.foo: Refreshing state... [id=test]\n"; got != want {
+		t.Fatalf("unexpected output\n got: %q\nwant: %q", got, want)
+	}
+}
+
 // Test the very simple PreImportState hook.
 func TestPreImportState(t *testing.T) {
 	streams, done := terminal.StreamsForTesting(t)
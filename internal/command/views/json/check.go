@@ -0,0 +1,50 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/opentofu/opentofu/internal/checks"
+	"github.com/opentofu/opentofu/internal/command/jsonchecks"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+// CheckResults summarizes the results of the checkable objects in the
+// configuration, such as check blocks and resources with conditions, at the
+// end of an operation.
+type CheckResults struct {
+	Pass    int `json:"pass"`
+	Fail    int `json:"fail"`
+	Error   int `json:"error"`
+	Unknown int `json:"unknown"`
+
+	// Checks holds the individual results, in the same format as the
+	// "checks" property of the JSON state and plan representations.
+	Checks json.RawMessage `json:"checks"`
+}
+
+func NewCheckResults(results *states.CheckResults) *CheckResults {
+	ret := &CheckResults{
+		Checks: json.RawMessage(jsonchecks.MarshalCheckStates(results)),
+	}
+	for _, elem := range results.ConfigResults.Elems {
+		switch elem.Value.Status {
+		case checks.StatusPass:
+			ret.Pass++
+		case checks.StatusFail:
+			ret.Fail++
+		case checks.StatusError:
+			ret.Error++
+		default:
+			ret.Unknown++
+		}
+	}
+	return ret
+}
+
+func (r *CheckResults) String() string {
+	return fmt.Sprintf("Checks: %d passed, %d failed, %d errored, %d unknown", r.Pass, r.Fail, r.Error, r.Unknown)
+}
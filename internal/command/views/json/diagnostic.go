@@ -288,12 +288,18 @@ func NewDiagnostic(diag tfdiags.Diagnostic, sources map[string][]byte) *Diagnost
 							continue
 						}
 
-						traversalStr := traversalStr(traversal)
-						if _, exists := seen[traversalStr]; exists {
+						path := traversalStr(traversal)
+						if prefix, n := tfdiags.DiagnosticValuePath(diag, traversal); prefix != "" {
+							// The traversal refers to the object that the
+							// diagnostic is about through a relative name,
+							// such as "self", so we'll show its full path.
+							path = prefix + traversalStr(traversal[n:])
+						}
+						if _, exists := seen[path]; exists {
 							continue Traversals // don't show duplicates when the same variable is referenced multiple times
 						}
 						value := DiagnosticExpressionValue{
-							Traversal: traversalStr,
+							Traversal: path,
 						}
 						switch {
 						case val.HasMark(marks.Sensitive):
@@ -352,7 +358,7 @@ func NewDiagnostic(diag tfdiags.Diagnostic, sources map[string][]byte) *Diagnost
 							value.Statement = fmt.Sprintf("is %s", compactValueStr(val))
 						}
 						values = append(values, value)
-						seen[traversalStr] = struct{}{}
+						seen[path] = struct{}{}
 					}
 				}
 				sort.Slice(values, func(i, j int) bool {
@@ -16,6 +16,7 @@ const (
 	MessagePlannedChange MessageType = "planned_change"
 	MessageChangeSummary MessageType = "change_summary"
 	MessageOutputs       MessageType = "outputs"
+	MessageCheckResults  MessageType = "check_results"
 
 	// Hook-driven messages
 	MessageApplyStart        MessageType = "apply_start"
@@ -17,7 +17,7 @@ import (
 // This version describes the schema of JSON UI messages. This version must be
 // updated after making any changes to this view, the jsonHook, or any of the
 // command/views/json package.
-const JSON_UI_VERSION = "1.2"
+const JSON_UI_VERSION = "1.3"
 
 func NewJSONView(view *View) *JSONView {
 	log := hclog.New(&hclog.LoggerOptions{
@@ -71,6 +71,9 @@ func (v *JSONView) StateDump(state string) {
 }
 
 func (v *JSONView) Diagnostics(diags tfdiags.Diagnostics, metadata ...interface{}) {
+	if v.view.deterministic {
+		diags.SortDeterministic()
+	}
 	sources := v.view.configSources()
 	for _, diag := range diags {
 		diagnostic := json.NewDiagnostic(diag, sources)
@@ -126,3 +129,11 @@ func (v *JSONView) Outputs(outputs json.Outputs) {
 		"outputs", outputs,
 	)
 }
+
+func (v *JSONView) CheckResults(results *json.CheckResults) {
+	v.log.Info(
+		results.String(),
+		"type", json.MessageCheckResults,
+		"check_results", results,
+	)
+}
@@ -0,0 +1,248 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package views
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/plans"
+)
+
+// progressStatusModules is the largest number of modules with changes in
+// progress that the live counter names individually.
+const progressStatusModules = 5
+
+// moduleProgress groups the progress messages of an apply by module, so that
+// the messages from many concurrent changes don't interleave. It collects the
+// planned changes from the operation view, so that it knows when all of the
+// changes in a module are complete.
+//
+// The messages about the changes in each module are held back until all of
+// the changes in that module are complete, and then printed together as a
+// section. Meanwhile, the UI hook periodically reports a count of the
+// completed changes and of the changes in progress in each module.
+type moduleProgress struct {
+	// collapsible marks each section with the workflow commands that make
+	// GitHub Actions show it as a collapsed group in the job log.
+	collapsible bool
+
+	mu sync.Mutex
+	// steps is the number of planned steps in each module instance, by its
+	// address, and total is the number of planned steps in all of them.
+	steps    map[string]int
+	total    int
+	done     int
+	groups   map[string]*progressGroup
+	reporter bool
+}
+
+// progressGroup is the progress of the changes in a single module instance
+// whose section hasn't been printed yet.
+type progressGroup struct {
+	start    time.Time
+	done     int
+	inFlight int
+	lines    []string
+}
+
+func newModuleProgress(collapsible bool) *moduleProgress {
+	return &moduleProgress{
+		collapsible: collapsible,
+		steps:       make(map[string]int),
+		groups:      make(map[string]*progressGroup),
+	}
+}
+
+// planned records a change that the apply will make.
+func (p *moduleProgress) planned(change *plans.ResourceInstanceChangeSrc) {
+	steps := progressSteps(change.Action)
+	if steps == 0 {
+		return
+	}
+	p.mu.Lock()
+	p.steps[change.Addr.Module.String()] += steps
+	p.total += steps
+	p.mu.Unlock()
+}
+
+// progressSteps returns the number of times the UI hook announces the start
+// of the given planned action while it is applied. Replacing an object is
+// reported as destroying the old object and creating the new one.
+func progressSteps(action plans.Action) int {
+	switch action {
+	case plans.Create, plans.Update, plans.Delete, plans.Read:
+		return 1
+	case plans.DeleteThenCreate, plans.CreateThenDelete:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// begin records the start of a change to the given resource instance, along
+// with the message announcing it. It returns true if the caller must start
+// reporting the progress periodically, because no report is pending.
+func (p *moduleProgress) begin(addr addrs.AbsResourceInstance, line string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	g := p.group(addr.Module)
+	g.inFlight++
+	g.lines = append(g.lines, line)
+
+	startReporter := !p.reporter
+	p.reporter = true
+	return startReporter
+}
+
+// output records a message about a change to the given resource instance
+// that is in progress, such as the output of a provisioner. It returns false
+// if there's no change in progress in the instance's module, in which case
+// the caller should print the message immediately instead.
+func (p *moduleProgress) output(addr addrs.AbsResourceInstance, line string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	g, ok := p.groups[addr.Module.String()]
+	if !ok {
+		return false
+	}
+	g.lines = append(g.lines, line)
+	return true
+}
+
+// end records the completion of a change to the given resource instance,
+// along with the message announcing it, if any. If that completes all of the
+// planned changes in the instance's module then it returns the lines of the
+// module's section, which the caller must print.
+func (p *moduleProgress) end(addr addrs.AbsResourceInstance, line string) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := addr.Module.String()
+	g := p.group(addr.Module)
+	g.inFlight--
+	g.done++
+	p.done++
+	if line != "" {
+		g.lines = append(g.lines, line)
+	}
+
+	if g.inFlight > 0 || g.done < p.steps[key] {
+		return nil
+	}
+	delete(p.groups, key)
+	return p.section(key, g)
+}
+
+func (p *moduleProgress) group(module addrs.ModuleInstance) *progressGroup {
+	key := module.String()
+	g, ok := p.groups[key]
+	if !ok {
+		g = &progressGroup{start: time.Now().Round(time.Second)}
+		p.groups[key] = g
+	}
+	return g
+}
+
+// status returns the live counter of the changes in progress, or false if
+// there are none, in which case the caller must stop reporting the progress
+// until begin asks for it again.
+func (p *moduleProgress) status() (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var keys []string
+	inFlight := 0
+	for key, g := range p.groups {
+		if g.inFlight > 0 {
+			keys = append(keys, key)
+			inFlight += g.inFlight
+		}
+	}
+	if inFlight == 0 {
+		p.reporter = false
+		return "", false
+	}
+	sort.Strings(keys)
+
+	var modules []string
+	for i, key := range keys {
+		if i == progressStatusModules {
+			modules = append(modules, fmt.Sprintf("and %d more", len(keys)-i))
+			break
+		}
+		modules = append(modules, fmt.Sprintf("%s (%d)", progressModuleName(key), p.groups[key].inFlight))
+	}
+
+	total := p.total
+	if total < p.done+inFlight {
+		// We weren't told about all of the planned changes, such as when
+		// another view has already consumed the plan.
+		total = p.done + inFlight
+	}
+	return fmt.Sprintf(
+		"Progress: %d of %d changes complete, %d in progress in %s.",
+		p.done, total, inFlight, strings.Join(modules, ", "),
+	), true
+}
+
+// flush returns the lines of the sections of the modules whose changes
+// didn't all complete, such as because the apply failed, in the order of
+// their module addresses.
+func (p *moduleProgress) flush() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	keys := make([]string, 0, len(p.groups))
+	for key := range p.groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var ret []string
+	for _, key := range keys {
+		ret = append(ret, p.section(key, p.groups[key])...)
+		delete(p.groups, key)
+	}
+	return ret
+}
+
+func (p *moduleProgress) section(key string, g *progressGroup) []string {
+	var heading string
+	elapsed := time.Now().Round(time.Second).Sub(g.start)
+	if planned := p.steps[key]; g.done < planned {
+		heading = fmt.Sprintf("%s: %d of %d changes complete after %s", progressModuleName(key), g.done, planned, elapsed)
+	} else if g.done == 1 {
+		heading = fmt.Sprintf("%s: 1 change complete after %s", progressModuleName(key), elapsed)
+	} else {
+		heading = fmt.Sprintf("%s: %d changes complete after %s", progressModuleName(key), g.done, elapsed)
+	}
+
+	if p.collapsible {
+		ret := make([]string, 0, len(g.lines)+2)
+		ret = append(ret, "::group::"+heading)
+		ret = append(ret, g.lines...)
+		return append(ret, "::endgroup::")
+	}
+
+	ret := make([]string, 0, len(g.lines)+1)
+	ret = append(ret, heading)
+	for _, line := range g.lines {
+		ret = append(ret, "  "+strings.ReplaceAll(line, "\n", "\n  "))
+	}
+	return ret
+}
+
+func progressModuleName(key string) string {
+	if key == "" {
+		return "Root module"
+	}
+	return key
+}
@@ -56,6 +56,18 @@ type OperationHuman struct {
 	// some sort of workflow automation tool that abstracts away the
 	// exact commands that are being run.
 	inAutomation bool
+
+	// summary optionally selects a more concise presentation of the
+	// changes in a plan.
+	summary *arguments.PlanSummary
+
+	// estimates optionally collects the changes in the plan to be applied,
+	// for estimating how long the apply will take.
+	estimates *applyEstimates
+
+	// progress optionally collects the changes in the plan to be applied,
+	// for grouping the progress messages of the apply by module.
+	progress *moduleProgress
 }
 
 var _ Operation = (*OperationHuman)(nil)
@@ -92,6 +104,17 @@ func (v *OperationHuman) EmergencyDumpState(stateFile *statefile.File) error {
 }
 
 func (v *OperationHuman) Plan(plan *plans.Plan, schemas *tofu.Schemas) {
+	if v.estimates != nil {
+		for _, change := range plan.Changes.Resources {
+			v.estimates.planned(change)
+		}
+	}
+	if v.progress != nil {
+		for _, change := range plan.Changes.Resources {
+			v.progress.planned(change)
+		}
+	}
+
 	outputs, changed, drift, attrs, err := jsonplan.MarshalForRenderer(plan, schemas)
 	if err != nil {
 		v.view.streams.Eprintf("Failed to marshal plan to json: %s", err)
@@ -103,6 +126,7 @@ func (v *OperationHuman) Plan(plan *plans.Plan, schemas *tofu.Schemas) {
 		Streams:             v.view.streams,
 		RunningInAutomation: v.inAutomation,
 	}
+	applyPlanSummary(&renderer, v.summary)
 
 	jplan := jsonformat.Plan{
 		PlanFormatVersion:     jsonplan.FormatVersion,
@@ -112,6 +136,8 @@ func (v *OperationHuman) Plan(plan *plans.Plan, schemas *tofu.Schemas) {
 		ResourceDrift:         drift,
 		ProviderSchemas:       jsonprovider.MarshalForRenderer(schemas),
 		RelevantAttributes:    attrs,
+		CostEstimate:          jsonplan.MarshalCostEstimate(plan.CostEstimate),
+		BlastRadius:           jsonplan.MarshalBlastRadius(plan.BlastRadius),
 	}
 
 	// Side load some data that we can't extract from the JSON plan.
@@ -124,13 +150,45 @@ func (v *OperationHuman) Plan(plan *plans.Plan, schemas *tofu.Schemas) {
 	}
 
 	renderer.RenderHumanPlan(jplan, plan.UIMode, opts...)
+
+	if len(plan.DeferredResources) > 0 || len(plan.DeferredResourceInstances) > 0 {
+		v.view.streams.Print(format.WordWrap(
+			"\n"+strings.TrimSpace(planDeferredResources),
+			v.view.outputColumns(),
+		) + "\n\n")
+		for _, addr := range plan.DeferredResources {
+			v.view.streams.Printf("  - %s\n", addr)
+		}
+		for _, addr := range plan.DeferredResourceInstances {
+			v.view.streams.Printf("  - %s\n", addr)
+		}
+	}
+}
+
+// applyPlanSummary configures the given renderer to present plans using the
+// given summary options, which may be nil to show every resource change in
+// full.
+func applyPlanSummary(renderer *jsonformat.Renderer, summary *arguments.PlanSummary) {
+	if summary == nil {
+		return
+	}
+	renderer.SummarizeModules = summary.ByModule
+	renderer.ExpandModules = summary.ExpandModules
 }
 
 func (v *OperationHuman) PlannedChange(change *plans.ResourceInstanceChangeSrc) {
 	// PlannedChange is primarily for machine-readable output in order to
 	// get a per-resource-instance change description. We don't use it
 	// with OperationHuman because the output of Plan already includes the
-	// change details for all resource instances.
+	// change details for all resource instances. When applying a saved
+	// plan, though, this is how we learn about the changes to estimate
+	// the duration of and to group the progress messages by module.
+	if v.estimates != nil {
+		v.estimates.planned(change)
+	}
+	if v.progress != nil {
+		v.progress.planned(change)
+	}
 }
 
 // PlanNextStep gives the user some next-steps, unless we're running in an
@@ -302,6 +360,10 @@ To perform exactly these actions, run the following command to apply:
     tofu apply %q
 `
 
+const planDeferredResources = `
+Note: The changes for the following resources and resource instances were deferred, because they depend on values that won't be known until apply. Run another plan after applying this one to plan their changes:
+`
+
 const planHeaderGenConfig = `
 OpenTofu has generated configuration and written it to %s. Please review the configuration and edit it as necessary before adding it to version control.
 `
@@ -359,6 +359,44 @@ Plan: 1 to add, 0 to change, 0 to destroy.
 	}
 }
 
+func TestOperation_planDeferred(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	v := NewOperation(arguments.ViewHuman, true, NewView(streams))
+
+	plan := testPlan(t)
+	plan.DeferredResources = []addrs.AbsResource{
+		addrs.Resource{
+			Mode: addrs.ManagedResourceMode,
+			Type: "test_resource",
+			Name: "bar",
+		}.Absolute(addrs.RootModuleInstance),
+	}
+	plan.DeferredResourceInstances = []addrs.AbsResourceInstance{
+		addrs.Resource{
+			Mode: addrs.ManagedResourceMode,
+			Type: "test_resource",
+			Name: "baz",
+		}.Instance(addrs.IntKey(1)).Absolute(addrs.RootModuleInstance),
+	}
+	schemas := testSchemas()
+	v.Plan(plan, schemas)
+
+	want := `
+Plan: 1 to add, 0 to change, 0 to destroy.
+
+Note: The changes for the following resources and resource instances were
+deferred, because they depend on values that won't be known until apply. Run
+another plan after applying this one to plan their changes:
+
+  - test_resource.bar
+  - test_resource.baz[1]
+`
+
+	if got := done(t).Stdout(); !strings.HasSuffix(got, want) {
+		t.Errorf("unexpected output\ngot:\n%s\nwant suffix:\n%s", got, want)
+	}
+}
+
 func TestOperation_planWithDatasource(t *testing.T) {
 	streams, done := terminal.StreamsForTesting(t)
 	v := NewOperation(arguments.ViewHuman, true, NewView(streams))
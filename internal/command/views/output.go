@@ -7,9 +7,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"sort"
 	"strings"
 
+	ctyyaml "github.com/zclconf/go-cty-yaml"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/convert"
 	ctyjson "github.com/zclconf/go-cty/cty/json"
@@ -28,12 +30,20 @@ type Output interface {
 }
 
 // NewOutput returns an initialized Output implementation for the given ViewType.
-func NewOutput(vt arguments.ViewType, view *View) Output {
+// The prefix is prepended to variable names in the dotenv and shell formats,
+// and is ignored by all others.
+func NewOutput(vt arguments.ViewType, prefix string, view *View) Output {
 	switch vt {
 	case arguments.ViewJSON:
 		return &OutputJSON{view: view}
 	case arguments.ViewRaw:
 		return &OutputRaw{view: view}
+	case arguments.ViewDotenv:
+		return &OutputEnv{view: view, prefix: prefix, format: arguments.ViewDotenv}
+	case arguments.ViewShell:
+		return &OutputEnv{view: view, prefix: prefix, format: arguments.ViewShell}
+	case arguments.ViewYAML:
+		return &OutputYAML{view: view}
 	case arguments.ViewHuman:
 		return &OutputHuman{view: view}
 	default:
@@ -257,6 +267,187 @@ func (v *OutputJSON) Diagnostics(diags tfdiags.Diagnostics) {
 	v.view.Diagnostics(diags)
 }
 
+// The OutputEnv implementation renders outputs as environment variable
+// assignments, either in the dotenv file format or as POSIX shell "export"
+// commands, so that other tools can load them directly.
+//
+// Strings, numbers, and booleans are rendered as their string
+// representation, null values as an empty string, and values of any other
+// type using their JSON encoding. Sensitive values are included, as with the
+// JSON output format.
+type OutputEnv struct {
+	view   *View
+	prefix string
+
+	// format is either arguments.ViewDotenv or arguments.ViewShell.
+	format arguments.ViewType
+}
+
+var _ Output = (*OutputEnv)(nil)
+
+func (v *OutputEnv) Output(name string, outputs map[string]*states.OutputValue) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	names := make([]string, 0, len(outputs))
+	if name != "" {
+		if _, ok := outputs[name]; !ok {
+			diags = diags.Append(missingOutputError(name))
+			return diags
+		}
+		names = append(names, name)
+	} else {
+		for n := range outputs {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+	}
+
+	var buf strings.Builder
+	varNames := make(map[string]string, len(names))
+	for _, n := range names {
+		varName := v.prefix + envVarNameChars.ReplaceAllString(n, "_")
+		if varName[0] >= '0' && varName[0] <= '9' {
+			varName = "_" + varName
+		}
+		if other, exists := varNames[varName]; exists {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Conflicting output variable names",
+				fmt.Sprintf("The output values %q and %q would both be written to the variable %s. Rename one of the output values, or show them separately.", other, n, varName),
+			))
+			return diags
+		}
+		varNames[varName] = n
+
+		str, err := envOutputString(outputs[n].Value)
+		if err != nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Unsupported value for output",
+				fmt.Sprintf("Cannot render output value %q as %s: %s.", n, v.format, err),
+			))
+			return diags
+		}
+
+		switch v.format {
+		case arguments.ViewShell:
+			fmt.Fprintf(&buf, "export %s=%s\n", varName, shellQuote(str))
+		default:
+			fmt.Fprintf(&buf, "%s=%s\n", varName, dotenvQuote(str))
+		}
+	}
+
+	v.view.streams.Print(buf.String())
+	return diags
+}
+
+func (v *OutputEnv) Diagnostics(diags tfdiags.Diagnostics) {
+	v.view.Diagnostics(diags)
+}
+
+// envVarNameChars matches the characters in an output name which are not
+// permitted in an environment variable name.
+var envVarNameChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// envOutputString returns the string that represents the given value in an
+// environment variable.
+func envOutputString(val cty.Value) (string, error) {
+	if !val.IsWhollyKnown() {
+		return "", fmt.Errorf("value won't be known until after a successful tofu apply")
+	}
+	if val.IsNull() {
+		return "", nil
+	}
+
+	ty := val.Type()
+	if ty.IsPrimitiveType() {
+		strV, err := convert.Convert(val, cty.String)
+		if err != nil {
+			return "", err
+		}
+		return strV.AsString(), nil
+	}
+
+	jsonV, err := ctyjson.Marshal(val, ty)
+	if err != nil {
+		return "", err
+	}
+	return string(jsonV), nil
+}
+
+// dotenvQuote returns the given string as a double-quoted dotenv value,
+// escaping backslashes, double quotes, dollar signs, and line breaks.
+func dotenvQuote(s string) string {
+	return `"` + dotenvEscaper.Replace(s) + `"`
+}
+
+var dotenvEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	`$`, `\$`,
+	"\n", `\n`,
+	"\r", `\r`,
+)
+
+// shellQuote returns the given string as a single-quoted POSIX shell word,
+// within which no characters other than the single quote itself are special.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// The OutputYAML implementation renders outputs as YAML. When rendering a
+// single output, only the value is displayed. When rendering all outputs, the
+// result is a mapping from output names to their values.
+type OutputYAML struct {
+	view *View
+}
+
+var _ Output = (*OutputYAML)(nil)
+
+func (v *OutputYAML) Output(name string, outputs map[string]*states.OutputValue) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	var val cty.Value
+	if name != "" {
+		output, ok := outputs[name]
+		if !ok {
+			diags = diags.Append(missingOutputError(name))
+			return diags
+		}
+		val = output.Value
+	} else {
+		vals := make(map[string]cty.Value, len(outputs))
+		for n, os := range outputs {
+			vals[n] = os.Value
+		}
+		val = cty.ObjectVal(vals)
+	}
+
+	if !val.IsWhollyKnown() {
+		// As with raw output, this should not arise for values read from
+		// the state, but we handle it in case that changes in future.
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Unsupported value for output",
+			"Cannot render output values as YAML because some of them won't be known until after a successful tofu apply.",
+		))
+		return diags
+	}
+
+	yamlOutput, err := ctyyaml.Standard.Marshal(val)
+	if err != nil {
+		diags = diags.Append(err)
+		return diags
+	}
+
+	v.view.streams.Print(string(yamlOutput))
+	return diags
+}
+
+func (v *OutputYAML) Diagnostics(diags tfdiags.Diagnostics) {
+	v.view.Diagnostics(diags)
+}
+
 // For text and raw output modes, an empty map of outputs is considered a
 // separate and higher priority failure mode than an output not being present
 // in a non-empty map. This warning diagnostic explains how this might have
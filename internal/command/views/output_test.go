@@ -52,7 +52,7 @@ func TestOutputHuman_single(t *testing.T) {
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
 			streams, done := terminal.StreamsForTesting(t)
-			v := NewOutput(arguments.ViewHuman, NewView(streams))
+			v := NewOutput(arguments.ViewHuman, "", NewView(streams))
 
 			outputs := map[string]*states.OutputValue{
 				"foo": {Value: tc.value},
@@ -85,7 +85,7 @@ func TestOutput_sensitive(t *testing.T) {
 	for name, vt := range testCases {
 		t.Run(name, func(t *testing.T) {
 			streams, done := terminal.StreamsForTesting(t)
-			v := NewOutput(vt, NewView(streams))
+			v := NewOutput(vt, "", NewView(streams))
 
 			outputs := map[string]*states.OutputValue{
 				"foo": {
@@ -187,7 +187,7 @@ foo = <sensitive>
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
 			streams, done := terminal.StreamsForTesting(t)
-			v := NewOutput(tc.vt, NewView(streams))
+			v := NewOutput(tc.vt, "", NewView(streams))
 			diags := v.Output("", outputs)
 
 			if diags.HasErrors() {
@@ -205,7 +205,7 @@ foo = <sensitive>
 // without diagnostics.
 func TestOutputJSON_empty(t *testing.T) {
 	streams, done := terminal.StreamsForTesting(t)
-	v := NewOutput(arguments.ViewJSON, NewView(streams))
+	v := NewOutput(arguments.ViewJSON, "", NewView(streams))
 
 	diags := v.Output("", map[string]*states.OutputValue{})
 
@@ -228,7 +228,7 @@ func TestOutput_emptyWarning(t *testing.T) {
 	for name, vt := range testCases {
 		t.Run(name, func(t *testing.T) {
 			streams, done := terminal.StreamsForTesting(t)
-			v := NewOutput(vt, NewView(streams))
+			v := NewOutput(vt, "", NewView(streams))
 
 			diags := v.Output("", map[string]*states.OutputValue{})
 
@@ -281,7 +281,7 @@ func TestOutputRaw(t *testing.T) {
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			streams, done := terminal.StreamsForTesting(t)
-			v := NewOutput(arguments.ViewRaw, NewView(streams))
+			v := NewOutput(arguments.ViewRaw, "", NewView(streams))
 
 			value := values[name]
 			outputs := map[string]*states.OutputValue{
@@ -307,7 +307,7 @@ func TestOutputRaw(t *testing.T) {
 // Raw cannot render all outputs.
 func TestOutputRaw_all(t *testing.T) {
 	streams, done := terminal.StreamsForTesting(t)
-	v := NewOutput(arguments.ViewRaw, NewView(streams))
+	v := NewOutput(arguments.ViewRaw, "", NewView(streams))
 
 	outputs := map[string]*states.OutputValue{
 		"foo": {Value: cty.StringVal("secret")},
@@ -328,19 +328,143 @@ func TestOutputRaw_all(t *testing.T) {
 	}
 }
 
+// The dotenv and shell formats render all primitive values as strings and
+// other values as JSON, with quoting suitable for each format.
+func TestOutputEnv(t *testing.T) {
+	outputs := map[string]*states.OutputValue{
+		"name":      {Value: cty.StringVal("it's a \"$HOME\"\nthing")},
+		"count":     {Value: cty.NumberIntVal(3)},
+		"enabled":   {Value: cty.True},
+		"nothing":   {Value: cty.NullVal(cty.String)},
+		"db-config": {Value: cty.ObjectVal(map[string]cty.Value{"port": cty.NumberIntVal(5432)}), Sensitive: true},
+	}
+
+	testCases := map[string]struct {
+		vt     arguments.ViewType
+		prefix string
+		want   string
+	}{
+		"dotenv": {
+			arguments.ViewDotenv,
+			"",
+			`count="3"
+db_config="{\"port\":5432}"
+enabled="true"
+name="it's a \"\$HOME\"\nthing"
+nothing=""
+`,
+		},
+		"shell with prefix": {
+			arguments.ViewShell,
+			"TF_",
+			`export TF_count='3'
+export TF_db_config='{"port":5432}'
+export TF_enabled='true'
+export TF_name='it'\''s a "$HOME"
+thing'
+export TF_nothing=''
+`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			streams, done := terminal.StreamsForTesting(t)
+			v := NewOutput(tc.vt, tc.prefix, NewView(streams))
+			diags := v.Output("", outputs)
+
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags)
+			}
+
+			if got := done(t).Stdout(); got != tc.want {
+				t.Errorf("wrong result\ngot:  %q\nwant: %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// Output names which map to the same variable name are an error, rather than
+// having one silently override the other.
+func TestOutputEnv_conflict(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	v := NewOutput(arguments.ViewDotenv, "", NewView(streams))
+
+	diags := v.Output("", map[string]*states.OutputValue{
+		"a-b": {Value: cty.StringVal("one")},
+		"a_b": {Value: cty.StringVal("two")},
+	})
+
+	if !diags.HasErrors() {
+		t.Fatalf("expected error diagnostics, got %s", diags)
+	}
+	if got, want := diags[0].Description().Summary, "Conflicting output variable names"; got != want {
+		t.Errorf("unexpected diagnostics: %s", diags)
+	}
+	if got, want := done(t).Stdout(), ""; got != want {
+		t.Errorf("wrong result\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestOutputYAML(t *testing.T) {
+	outputs := map[string]*states.OutputValue{
+		"foo": {Value: cty.StringVal("secret"), Sensitive: true},
+		"bar": {Value: cty.ListVal([]cty.Value{cty.NumberIntVal(1), cty.NumberIntVal(2)})},
+	}
+
+	testCases := map[string]struct {
+		name string
+		want string
+	}{
+		"all": {
+			"",
+			`"bar":
+- 1
+- 2
+"foo": "secret"
+`,
+		},
+		"single": {
+			"bar",
+			`- 1
+- 2
+`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			streams, done := terminal.StreamsForTesting(t)
+			v := NewOutput(arguments.ViewYAML, "", NewView(streams))
+			diags := v.Output(tc.name, outputs)
+
+			if diags.HasErrors() {
+				t.Fatalf("unexpected diagnostics: %s", diags)
+			}
+
+			if got := done(t).Stdout(); got != tc.want {
+				t.Errorf("wrong result\ngot:  %q\nwant: %q", got, tc.want)
+			}
+		})
+	}
+}
+
 // All outputs render an error if a specific output is requested which is
 // missing from the map of outputs.
 func TestOutput_missing(t *testing.T) {
 	testCases := map[string]arguments.ViewType{
-		"human": arguments.ViewHuman,
-		"json":  arguments.ViewJSON,
-		"raw":   arguments.ViewRaw,
+		"human":  arguments.ViewHuman,
+		"json":   arguments.ViewJSON,
+		"raw":    arguments.ViewRaw,
+		"dotenv": arguments.ViewDotenv,
+		"shell":  arguments.ViewShell,
+		"yaml":   arguments.ViewYAML,
 	}
 
 	for name, vt := range testCases {
 		t.Run(name, func(t *testing.T) {
 			streams, done := terminal.StreamsForTesting(t)
-			v := NewOutput(vt, NewView(streams))
+			v := NewOutput(vt, "", NewView(streams))
 
 			diags := v.Output("foo", map[string]*states.OutputValue{
 				"bar": {Value: cty.StringVal("boop")},
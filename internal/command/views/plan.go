@@ -5,6 +5,7 @@ package views
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/opentofu/opentofu/internal/command/arguments"
 	"github.com/opentofu/opentofu/internal/tfdiags"
@@ -16,12 +17,18 @@ type Plan interface {
 	Operation() Operation
 	Hooks() []tofu.Hook
 
+	// WatchWaiting and WatchChanged report progress in watch mode, between
+	// the speculative plans created for each change to the watched files.
+	WatchWaiting()
+	WatchChanged(files []string)
+
 	Diagnostics(diags tfdiags.Diagnostics)
 	HelpPrompt()
 }
 
 // NewPlan returns an initialized Plan implementation for the given ViewType.
-func NewPlan(vt arguments.ViewType, view *View) Plan {
+// The summary options only affect human-readable output, and may be nil.
+func NewPlan(vt arguments.ViewType, summary *arguments.PlanSummary, view *View) Plan {
 	switch vt {
 	case arguments.ViewJSON:
 		return &PlanJSON{
@@ -31,6 +38,7 @@ func NewPlan(vt arguments.ViewType, view *View) Plan {
 		return &PlanHuman{
 			view:         view,
 			inAutomation: view.RunningInAutomation(),
+			summary:      summary,
 		}
 	default:
 		panic(fmt.Sprintf("unknown view type %v", vt))
@@ -43,12 +51,17 @@ type PlanHuman struct {
 	view *View
 
 	inAutomation bool
+	summary      *arguments.PlanSummary
 }
 
 var _ Plan = (*PlanHuman)(nil)
 
 func (v *PlanHuman) Operation() Operation {
-	return NewOperation(arguments.ViewHuman, v.inAutomation, v.view)
+	return &OperationHuman{
+		view:         v.view,
+		inAutomation: v.inAutomation,
+		summary:      v.summary,
+	}
 }
 
 func (v *PlanHuman) Hooks() []tofu.Hook {
@@ -57,6 +70,17 @@ func (v *PlanHuman) Hooks() []tofu.Hook {
 	}
 }
 
+func (v *PlanHuman) WatchWaiting() {
+	v.view.streams.Print(v.view.colorize.Color("\n[reset][bold]Watching for changes to the configuration. Press Ctrl-C to stop.\n"))
+}
+
+func (v *PlanHuman) WatchChanged(files []string) {
+	v.view.streams.Printf(
+		v.view.colorize.Color("\n[reset][bold]Changes detected in %s, planning again...\n\n"),
+		strings.Join(files, ", "),
+	)
+}
+
 func (v *PlanHuman) Diagnostics(diags tfdiags.Diagnostics) {
 	v.view.Diagnostics(diags)
 }
@@ -83,6 +107,14 @@ func (v *PlanJSON) Hooks() []tofu.Hook {
 	}
 }
 
+func (v *PlanJSON) WatchWaiting() {
+	v.view.Log("Watching for changes to the configuration")
+}
+
+func (v *PlanJSON) WatchChanged(files []string) {
+	v.view.Log(fmt.Sprintf("Changes detected in %s, planning again", strings.Join(files, ", ")))
+}
+
 func (v *PlanJSON) Diagnostics(diags tfdiags.Diagnostics) {
 	v.view.Diagnostics(diags)
 }
@@ -21,7 +21,7 @@ import (
 func TestPlanHuman_operation(t *testing.T) {
 	streams, done := terminal.StreamsForTesting(t)
 	defer done(t)
-	v := NewPlan(arguments.ViewHuman, NewView(streams).SetRunningInAutomation(true)).Operation()
+	v := NewPlan(arguments.ViewHuman, nil, NewView(streams).SetRunningInAutomation(true)).Operation()
 	if hv, ok := v.(*OperationHuman); !ok {
 		t.Fatalf("unexpected return type %t", v)
 	} else if hv.inAutomation != true {
@@ -33,7 +33,7 @@ func TestPlanHuman_operation(t *testing.T) {
 func TestPlanHuman_hooks(t *testing.T) {
 	streams, done := terminal.StreamsForTesting(t)
 	defer done(t)
-	v := NewPlan(arguments.ViewHuman, NewView(streams).SetRunningInAutomation((true)))
+	v := NewPlan(arguments.ViewHuman, nil, NewView(streams).SetRunningInAutomation((true)))
 	hooks := v.Hooks()
 
 	var uiHook *UiHook
@@ -47,6 +47,24 @@ func TestPlanHuman_hooks(t *testing.T) {
 	}
 }
 
+func TestPlanHuman_watch(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	v := NewPlan(arguments.ViewHuman, nil, NewView(streams))
+	v.WatchChanged([]string{"main.tf", "prod.tfvars"})
+	v.WatchWaiting()
+
+	got := done(t).Stdout()
+	want := `
+Changes detected in main.tf, prod.tfvars, planning again...
+
+
+Watching for changes to the configuration. Press Ctrl-C to stop.
+`
+	if got != want {
+		t.Fatalf("wrong output\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
 // Helper functions to build a trivial test plan, to exercise the plan
 // renderer.
 func testPlan(t *testing.T) *plans.Plan {
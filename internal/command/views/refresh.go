@@ -57,7 +57,7 @@ var _ Refresh = (*RefreshHuman)(nil)
 func (v *RefreshHuman) Outputs(outputValues map[string]*states.OutputValue) {
 	if len(outputValues) > 0 {
 		v.view.streams.Print(v.view.colorize.Color("[reset][bold][green]\nOutputs:\n\n"))
-		NewOutput(arguments.ViewHuman, v.view).Output("", outputValues)
+		NewOutput(arguments.ViewHuman, "", v.view).Output("", outputValues)
 	}
 }
 
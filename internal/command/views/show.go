@@ -29,19 +29,22 @@ type Show interface {
 	Diagnostics(diags tfdiags.Diagnostics)
 }
 
-func NewShow(vt arguments.ViewType, view *View) Show {
+// NewShow returns an initialized Show implementation for the given ViewType.
+// The summary options only affect human-readable output, and may be nil.
+func NewShow(vt arguments.ViewType, summary *arguments.PlanSummary, view *View) Show {
 	switch vt {
 	case arguments.ViewJSON:
 		return &ShowJSON{view: view}
 	case arguments.ViewHuman:
-		return &ShowHuman{view: view}
+		return &ShowHuman{view: view, summary: summary}
 	default:
 		panic(fmt.Sprintf("unknown view type %v", vt))
 	}
 }
 
 type ShowHuman struct {
-	view *View
+	view    *View
+	summary *arguments.PlanSummary
 }
 
 var _ Show = (*ShowHuman)(nil)
@@ -52,6 +55,7 @@ func (v *ShowHuman) Display(config *configs.Config, plan *plans.Plan, planJSON *
 		Streams:             v.view.streams,
 		RunningInAutomation: v.view.runningInAutomation,
 	}
+	applyPlanSummary(&renderer, v.summary)
 
 	// Prefer to display a pre-built JSON plan, if we got one; then, fall back
 	// to building one ourselves.
@@ -85,6 +89,7 @@ func (v *ShowHuman) Display(config *configs.Config, plan *plans.Plan, planJSON *
 			ResourceDrift:         drift,
 			ProviderSchemas:       jsonprovider.MarshalForRenderer(schemas),
 			RelevantAttributes:    attrs,
+			CostEstimate:          jsonplan.MarshalCostEstimate(plan.CostEstimate),
 		}
 
 		var opts []plans.Quality
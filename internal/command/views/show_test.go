@@ -99,7 +99,7 @@ func TestShowHuman(t *testing.T) {
 			streams, done := terminal.StreamsForTesting(t)
 			view := NewView(streams)
 			view.Configure(&arguments.View{NoColor: true})
-			v := NewShow(arguments.ViewHuman, view)
+			v := NewShow(arguments.ViewHuman, nil, view)
 
 			code := v.Display(nil, testCase.plan, testCase.jsonPlan, testCase.stateFile, testCase.schemas)
 			if code != 0 {
@@ -177,7 +177,7 @@ func TestShowJSON(t *testing.T) {
 			streams, done := terminal.StreamsForTesting(t)
 			view := NewView(streams)
 			view.Configure(&arguments.View{NoColor: true})
-			v := NewShow(arguments.ViewJSON, view)
+			v := NewShow(arguments.ViewJSON, nil, view)
 
 			schemas := &tofu.Schemas{
 				Providers: map[addrs.Provider]providers.ProviderSchema{
@@ -0,0 +1,138 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package views
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/colorstring"
+
+	"github.com/opentofu/opentofu/internal/command/cliconfig"
+)
+
+// Theme is a named mapping from the color names used in the "[name]" markup
+// of our human-oriented output to the terminal SGR codes that should be
+// emitted for them.
+//
+// All of the human views share a single colorize implementation, so
+// selecting a theme affects plan diffs, diagnostics and progress output
+// consistently.
+type Theme struct {
+	Name string
+
+	// overrides are the colors which differ from colorstring.DefaultColors.
+	overrides map[string]string
+}
+
+// DefaultThemeName is the name of the theme used when the CLI configuration
+// does not select one.
+const DefaultThemeName = "default"
+
+var builtinThemes = map[string]map[string]string{
+	DefaultThemeName: {},
+
+	// The colorblind theme replaces the red/green pairing used for
+	// destroy/create markers with an orange/blue pairing, which remains
+	// distinguishable for the most common forms of color vision deficiency.
+	"colorblind": {
+		"red":         "38;5;208",
+		"light_red":   "38;5;214",
+		"green":       "38;5;33",
+		"light_green": "38;5;39",
+		"yellow":      "38;5;220",
+	},
+
+	// The high-contrast theme uses only bright and bold variants, and
+	// raises the de-emphasized gray so that it stays legible on dark
+	// terminal backgrounds.
+	"high-contrast": {
+		"red":       "1;91",
+		"green":     "1;92",
+		"yellow":    "1;93",
+		"cyan":      "1;96",
+		"dark_gray": "37",
+		"purple":    "1;95",
+	},
+}
+
+// ThemeNames returns the names of all of the built-in themes, in
+// lexicographical order.
+func ThemeNames() []string {
+	names := make([]string, 0, len(builtinThemes))
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewTheme returns the built-in theme with the given name, with any colors
+// given in palette overriding those of the selected theme.
+//
+// An empty name selects the default theme. The keys of palette must be color
+// names that are already known to the theme, and the values must be SGR
+// parameter lists such as "38;5;208".
+func NewTheme(name string, palette map[string]string) (*Theme, error) {
+	if name == "" {
+		name = DefaultThemeName
+	}
+	base, ok := builtinThemes[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported color theme %q; must be one of: %s", name, strings.Join(ThemeNames(), ", "))
+	}
+
+	theme := &Theme{
+		Name:      name,
+		overrides: make(map[string]string, len(base)+len(palette)),
+	}
+	for k, v := range base {
+		theme.overrides[k] = v
+	}
+
+	// We sort the palette keys only so that any error we return is
+	// deterministic when there are multiple problems.
+	keys := make([]string, 0, len(palette))
+	for k := range palette {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := palette[k]
+		if _, known := baseColors()[k]; !known {
+			return nil, fmt.Errorf("unsupported color name %q in color palette", k)
+		}
+		if !cliconfig.ValidColorCode(v) {
+			return nil, fmt.Errorf("invalid value %q for color %q in color palette; must be a semicolon-separated list of SGR parameters, such as \"38;5;208\"", v, k)
+		}
+		theme.overrides[k] = v
+	}
+
+	return theme, nil
+}
+
+// Colors returns a new map suitable for use as the Colors field of a
+// colorstring.Colorize. A nil theme returns the default colors.
+func (t *Theme) Colors() map[string]string {
+	colors := baseColors()
+	if t == nil {
+		return colors
+	}
+	for k, v := range t.overrides {
+		colors[k] = v
+	}
+	return colors
+}
+
+// baseColors returns the colors available to all themes before any
+// overrides are applied.
+func baseColors() map[string]string {
+	colors := make(map[string]string, len(colorstring.DefaultColors)+1)
+	for k, v := range colorstring.DefaultColors {
+		colors[k] = v
+	}
+	colors["purple"] = "38;5;57"
+	return colors
+}
@@ -0,0 +1,108 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package views
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/opentofu/opentofu/internal/command/arguments"
+	"github.com/opentofu/opentofu/internal/terminal"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+func TestNewTheme(t *testing.T) {
+	testCases := map[string]struct {
+		name    string
+		palette map[string]string
+		want    map[string]string
+		wantErr string
+	}{
+		"default": {
+			want: map[string]string{
+				"red":    "31",
+				"green":  "32",
+				"purple": "38;5;57",
+			},
+		},
+		"colorblind": {
+			name: "colorblind",
+			want: map[string]string{
+				"red":   "38;5;208",
+				"green": "38;5;33",
+				"bold":  "1",
+			},
+		},
+		"palette overrides theme": {
+			name: "high-contrast",
+			palette: map[string]string{
+				"red": "38;5;196",
+			},
+			want: map[string]string{
+				"red":   "38;5;196",
+				"green": "1;92",
+			},
+		},
+		"unknown theme": {
+			name:    "neon",
+			wantErr: `unsupported color theme "neon"; must be one of: colorblind, default, high-contrast`,
+		},
+		"unknown color name": {
+			palette: map[string]string{
+				"mauve": "35",
+			},
+			wantErr: `unsupported color name "mauve" in color palette`,
+		},
+		"invalid color code": {
+			palette: map[string]string{
+				"red": "#ff0000",
+			},
+			wantErr: `invalid value "#ff0000" for color "red" in color palette`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			theme, err := NewTheme(tc.name, tc.palette)
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatalf("succeeded; want error %q", tc.wantErr)
+				}
+				if !strings.HasPrefix(err.Error(), tc.wantErr) {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			colors := theme.Colors()
+			for k, want := range tc.want {
+				if got := colors[k]; got != want {
+					t.Errorf("wrong code for %q: got %q, want %q", k, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestViewSetTheme(t *testing.T) {
+	streams, done := terminal.StreamsForTesting(t)
+	theme, err := NewTheme("colorblind", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	view := NewView(streams).SetTheme(theme)
+	view.Configure(&arguments.View{NoColor: false})
+
+	var diags tfdiags.Diagnostics
+	diags = diags.Append(tfdiags.Sourceless(tfdiags.Error, "Oops", "Something went wrong."))
+	view.Diagnostics(diags)
+
+	got := done(t).Stderr()
+	if want := "\x1b[38;5;208m"; !strings.Contains(got, want) {
+		t.Errorf("error diagnostic does not use themed color %q\ngot: %q", want, got)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/mitchellh/colorstring"
 	"github.com/opentofu/opentofu/internal/command/arguments"
 	"github.com/opentofu/opentofu/internal/command/format"
+	"github.com/opentofu/opentofu/internal/command/hints"
 	"github.com/opentofu/opentofu/internal/terminal"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
@@ -20,6 +21,10 @@ type View struct {
 
 	compactWarnings bool
 
+	// explain includes detailed remediation steps in the hints for error
+	// diagnostics.
+	explain bool
+
 	// When this is true it's a hint that OpenTofu is being run indirectly
 	// via a wrapper script or other automation and so we may wish to replace
 	// direct examples of commands to run with more conceptual directions.
@@ -27,6 +32,10 @@ type View struct {
 	// the messages that users are most likely to see.
 	runningInAutomation bool
 
+	// deterministic orders diagnostics fully, so that the output doesn't
+	// depend on the order in which concurrent operations reported them.
+	deterministic bool
+
 	// This unfortunate wart is required to enable rendering of diagnostics which
 	// have associated source code in the configuration. This function pointer
 	// will be dereferenced as late as possible when rendering diagnostics in
@@ -60,6 +69,28 @@ func (v *View) SetRunningInAutomation(new bool) *View {
 	return v
 }
 
+// SetDeterministic modifies the view's "deterministic" flag, which causes
+// diagnostics that would otherwise be rendered in the order they were
+// reported to be ordered by their content instead.
+//
+// For convenient use during initialization (in conjunction with NewView),
+// SetDeterministic returns the reciever after modifying it.
+func (v *View) SetDeterministic(new bool) *View {
+	v.deterministic = new
+	return v
+}
+
+// SetTheme replaces the colors used by the view's colorize implementation
+// with those of the given theme. Whether color is enabled at all is still
+// decided separately by Configure.
+//
+// For convenient use during initialization (in conjunction with NewView),
+// SetTheme returns the reciever after modifying it.
+func (v *View) SetTheme(theme *Theme) *View {
+	v.colorize.Colors = theme.Colors()
+	return v
+}
+
 func (v *View) RunningInAutomation() bool {
 	return v.runningInAutomation
 }
@@ -68,6 +99,7 @@ func (v *View) RunningInAutomation() bool {
 func (v *View) Configure(view *arguments.View) {
 	v.colorize.Disable = view.NoColor
 	v.compactWarnings = view.CompactWarnings
+	v.explain = view.Explain
 }
 
 // SetConfigSources overrides the default no-op callback with a new function
@@ -79,13 +111,18 @@ func (v *View) SetConfigSources(cb func() map[string][]byte) {
 // Diagnostics renders a set of warnings and errors in human-readable form.
 // Warnings are printed to stdout, and errors to stderr.
 func (v *View) Diagnostics(diags tfdiags.Diagnostics) {
-	diags.Sort()
+	if v.deterministic {
+		diags.SortDeterministic()
+	} else {
+		diags.Sort()
+	}
 
 	if len(diags) == 0 {
 		return
 	}
 
 	diags = diags.ConsolidateWarnings(1)
+	diags = hints.Annotate(diags, v.explain)
 
 	// Since warning messages are generally competing
 	if v.compactWarnings {
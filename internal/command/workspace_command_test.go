@@ -6,6 +6,7 @@ package command
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -465,3 +466,111 @@ func TestWorkspace_selectWithOrCreate(t *testing.T) {
 	}
 
 }
+
+func TestWorkspace_selectInteractive(t *testing.T) {
+	tests := map[string]struct {
+		args    []string
+		answers map[string]string
+		want    string
+	}{
+		"filter at prompt": {
+			[]string{"-i"},
+			map[string]string{"select-workspace": "456"},
+			"pr-456",
+		},
+		"choose by number": {
+			[]string{"-i", "pr"},
+			map[string]string{"select-workspace": "2"},
+			"pr-456",
+		},
+		"unique query": {
+			[]string{"-i", "fx"},
+			map[string]string{},
+			"feature-x",
+		},
+		"create if missing": {
+			[]string{"-i", "-create-if-missing", "pr-789"},
+			map[string]string{},
+			"pr-789",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			td := t.TempDir()
+			defer testChdir(t, td)()
+
+			for _, env := range []string{"pr-123", "pr-456", "feature-x", "staging"} {
+				ui := new(cli.MockUi)
+				view, _ := testView(t)
+				newCmd := &WorkspaceNewCommand{
+					Meta: Meta{Ui: ui, View: view},
+				}
+				if code := newCmd.Run([]string{env}); code != 0 {
+					t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter)
+				}
+			}
+
+			defer testInputMap(t, test.answers)()
+
+			ui := new(cli.MockUi)
+			view, _ := testView(t)
+			selectCmd := &WorkspaceSelectCommand{
+				Meta: Meta{Ui: ui, View: view},
+			}
+			if code := selectCmd.Run(test.args); code != 0 {
+				t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter)
+			}
+
+			current, _ := selectCmd.Workspace()
+			if current != test.want {
+				t.Fatalf("current workspace should be %q, got %q", test.want, current)
+			}
+		})
+	}
+}
+
+func TestWorkspace_selectInteractiveCancel(t *testing.T) {
+	td := t.TempDir()
+	defer testChdir(t, td)()
+
+	defer testInputMap(t, map[string]string{"select-workspace": ""})()
+
+	ui := new(cli.MockUi)
+	view, _ := testView(t)
+	selectCmd := &WorkspaceSelectCommand{
+		Meta: Meta{Ui: ui, View: view},
+	}
+	if code := selectCmd.Run([]string{"-i", "missing"}); code != 1 {
+		t.Fatalf("wrong exit code %d; want 1", code)
+	}
+	if got := ui.OutputWriter.String(); !strings.Contains(got, `No workspaces match "missing".`) {
+		t.Fatalf("wrong output:\n%s", got)
+	}
+	if got := ui.ErrorWriter.String(); !strings.Contains(got, "Workspace selection cancelled.") {
+		t.Fatalf("wrong error:\n%s", got)
+	}
+}
+
+func TestFuzzyMatchWorkspaces(t *testing.T) {
+	workspaces := []string{"default", "feature-login", "pr-1234", "pr-1299", "production"}
+
+	tests := map[string][]string{
+		"":        workspaces,
+		"pr-12":   {"pr-1234", "pr-1299"},
+		"pr1299":  {"pr-1299"},
+		"prod":    {"production"},
+		"flgn":    {"feature-login"},
+		"PR-1234": {"pr-1234"},
+		"zzz":     nil,
+	}
+
+	for query, want := range tests {
+		t.Run(query, func(t *testing.T) {
+			got := fuzzyMatchWorkspaces(query, workspaces)
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("wrong matches for %q\ngot:  %#v\nwant: %#v", query, got, want)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/mitchellh/cli"
 	"github.com/posener/complete"
 
+	"github.com/opentofu/opentofu/internal/command/arguments"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
@@ -22,9 +23,11 @@ func (c *WorkspaceSelectCommand) Run(args []string) int {
 	args = c.Meta.process(args)
 	envCommandShowWarning(c.Ui, c.LegacyName)
 
-	var orCreate bool
+	var orCreate, interactive bool
 	cmdFlags := c.Meta.defaultFlagSet("workspace select")
 	cmdFlags.BoolVar(&orCreate, "or-create", false, "create workspace if it does not exist")
+	cmdFlags.BoolVar(&orCreate, "create-if-missing", false, "create workspace if it does not exist")
+	cmdFlags.BoolVar(&interactive, "i", false, "choose the workspace interactively")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		c.Ui.Error(fmt.Sprintf("Error parsing command-line flags: %s\n", err.Error()))
@@ -32,12 +35,27 @@ func (c *WorkspaceSelectCommand) Run(args []string) int {
 	}
 
 	args = cmdFlags.Args()
-	if len(args) != 1 {
+	switch {
+	case interactive && len(args) > 1:
+		c.Ui.Error("Expected at most one argument: QUERY.\n")
+		return cli.RunResultHelp
+	case !interactive && len(args) != 1:
 		c.Ui.Error("Expected a single argument: NAME.\n")
 		return cli.RunResultHelp
 	}
 
-	configPath, err := modulePath(args[1:])
+	if interactive && !arguments.InputEnabledDefault() {
+		c.Ui.Error("The -i option requires interactive input, which is disabled by the TF_INPUT environment variable.")
+		return 1
+	}
+
+	var name string
+	if len(args) > 0 {
+		name = args[0]
+		args = args[1:]
+	}
+
+	configPath, err := modulePath(args)
 	if err != nil {
 		c.Ui.Error(err.Error())
 		return 1
@@ -76,8 +94,7 @@ func (c *WorkspaceSelectCommand) Run(args []string) int {
 	// This command will not write state
 	c.ignoreRemoteVersionConflict(b)
 
-	name := args[0]
-	if !validWorkspaceName(name) {
+	if !interactive && !validWorkspaceName(name) {
 		c.Ui.Error(fmt.Sprintf(envInvalidName, name))
 		return 1
 	}
@@ -88,6 +105,18 @@ func (c *WorkspaceSelectCommand) Run(args []string) int {
 		return 1
 	}
 
+	if interactive {
+		name, err = c.interactiveSelectWorkspace(name, states, current, orCreate)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		if !validWorkspaceName(name) {
+			c.Ui.Error(fmt.Sprintf(envInvalidName, name))
+			return 1
+		}
+	}
+
 	if name == current {
 		// already using this workspace
 		return 0
@@ -150,13 +179,24 @@ func (c *WorkspaceSelectCommand) AutocompleteFlags() complete.Flags {
 
 func (c *WorkspaceSelectCommand) Help() string {
 	helpText := `
-Usage: tofu [global options] workspace select NAME
+Usage: tofu [global options] workspace select [options] NAME
+       tofu [global options] workspace select -i [options] [QUERY]
 
   Select a different OpenTofu workspace.
 
+  With -i, choose the workspace from a list instead. The list is filtered
+  by QUERY, and by any text entered at the prompt, matching the characters
+  of the query in order so that mistyped or partial names still match.
+
 Options:
 
+    -i                  Choose the workspace interactively.
+
     -or-create=false    Create the OpenTofu workspace if it doesn't exist.
+                        With -i, the workspace is created if no workspaces
+                        match the query.
+
+    -create-if-missing  Same as -or-create.
 
 `
 	return strings.TrimSpace(helpText)
@@ -0,0 +1,151 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// maxInteractiveWorkspaces is the maximum number of workspaces listed at
+// once by "tofu workspace select -i". The user can type to filter the list
+// down to the workspace they are looking for.
+const maxInteractiveWorkspaces = 20
+
+// errWorkspaceSelectCancelled is returned by interactiveSelectWorkspace when
+// the user doesn't select a workspace.
+var errWorkspaceSelectCancelled = errors.New("Workspace selection cancelled.")
+
+// interactiveSelectWorkspace asks the user to choose one of the given
+// workspaces. The list of workspaces is filtered by the given query, and the
+// user can either choose one by its number in the list or type a new query
+// to filter the list again. If only one workspace matches a query then it is
+// chosen without asking.
+//
+// If no workspaces match a query and createIfMissing is set then the query is
+// returned as the name of the workspace to create.
+func (c *WorkspaceSelectCommand) interactiveSelectWorkspace(query string, workspaces []string, current string, createIfMissing bool) (string, error) {
+	asked := false
+	for {
+		matches := fuzzyMatchWorkspaces(query, workspaces)
+		switch {
+		case len(matches) == 1 && (asked || query != ""):
+			return matches[0], nil
+		case len(matches) == 0 && createIfMissing:
+			return query, nil
+		case len(matches) == 0:
+			c.Ui.Output(fmt.Sprintf("No workspaces match %q.\n", query))
+			matches = workspaces
+		}
+
+		for i, name := range matches {
+			if i == maxInteractiveWorkspaces {
+				c.Ui.Output(fmt.Sprintf("  ... and %d more", len(matches)-i))
+				break
+			}
+			line := fmt.Sprintf("  %2d) %s", i+1, name)
+			if name == current {
+				line += " (current)"
+			}
+			c.Ui.Output(line)
+		}
+		c.Ui.Output("")
+
+		answer, err := c.UIInput().Input(context.Background(), &tofu.InputOpts{
+			Id:          "select-workspace",
+			Query:       "Workspace to select",
+			Description: "Enter a number from the list above, or some of the workspace's name to filter the list.",
+		})
+		if err != nil {
+			return "", fmt.Errorf("Error asking for a workspace: %w", err)
+		}
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			return "", errWorkspaceSelectCancelled
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(matches) && n <= maxInteractiveWorkspaces {
+			return matches[n-1], nil
+		}
+		query = answer
+		asked = true
+	}
+}
+
+// fuzzyMatchWorkspaces returns the names of the given workspaces that match
+// the given query, best match first.
+//
+// A name matches if it contains all of the characters of the query in the
+// same order, ignoring case. Names where the characters appear together, or
+// at the start of the words in the name, are better matches.
+func fuzzyMatchWorkspaces(query string, workspaces []string) []string {
+	if query == "" {
+		return workspaces
+	}
+
+	type match struct {
+		name  string
+		score int
+	}
+	var matches []match
+	for _, name := range workspaces {
+		if score, ok := fuzzyMatchScore(query, name); ok {
+			matches = append(matches, match{name, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	var ret []string
+	for _, m := range matches {
+		ret = append(ret, m.name)
+	}
+	return ret
+}
+
+// fuzzyMatchScore returns how well the given name matches the given query,
+// and false if it doesn't match at all.
+func fuzzyMatchScore(query, name string) (int, bool) {
+	q := strings.ToLower(query)
+	n := strings.ToLower(name)
+
+	switch {
+	case n == q:
+		return 1000, true
+	case strings.HasPrefix(n, q):
+		return 500 - len(n), true
+	case strings.Contains(n, q):
+		return 250 - len(n), true
+	}
+
+	score := 0
+	prev := -1
+	for _, r := range q {
+		i := strings.IndexRune(n[prev+1:], r)
+		if i < 0 {
+			return 0, false
+		}
+		i += prev + 1
+		switch {
+		case i == prev+1:
+			score += 5
+		case i == 0 || strings.ContainsRune("-_./", rune(n[i-1])):
+			score += 3
+		default:
+			score++
+		}
+		prev = i + utf8.RuneLen(r) - 1
+	}
+	return score - len(n)/4, true
+}
@@ -5,12 +5,15 @@ package configs
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/lang"
+	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
 // CheckRule represents a configuration-defined validation rule, precondition,
@@ -37,15 +40,51 @@ type CheckRule struct {
 	// interpolation as the corresponding condition.
 	ErrorMessage hcl.Expression
 
+	// ForEach is set only for a rule declared by a "dynamic" block inside a
+	// "lifecycle" block, in which case the rule stands for one condition per
+	// element of the collection ForEach evaluates to. Iterator is the name of
+	// the temporary variable that refers to the current element from within
+	// Condition and ErrorMessage.
+	ForEach  hcl.Expression
+	Iterator string
+
 	DeclRange hcl.Range
 }
 
+// Variables returns the traversals in the rule's expressions, excluding any
+// that refer to the iterator of a rule declared by a "dynamic" block, which
+// is not an object elsewhere in the configuration.
+func (cr *CheckRule) Variables() []hcl.Traversal {
+	var ret []hcl.Traversal
+	for _, expr := range []hcl.Expression{cr.ForEach, cr.Condition, cr.ErrorMessage} {
+		if expr == nil {
+			continue
+		}
+		ret = append(ret, cr.exprVariables(expr)...)
+	}
+	return ret
+}
+
+func (cr *CheckRule) exprVariables(expr hcl.Expression) []hcl.Traversal {
+	if cr.Iterator == "" || expr == cr.ForEach {
+		return expr.Variables()
+	}
+	var ret []hcl.Traversal
+	for _, traversal := range expr.Variables() {
+		if traversal.RootName() != cr.Iterator {
+			ret = append(ret, traversal)
+		}
+	}
+	return ret
+}
+
 // validateSelfReferences looks for references in the check rule matching the
 // specified resource address, returning error diagnostics if such a reference
 // is found.
 func (cr *CheckRule) validateSelfReferences(checkType string, addr addrs.Resource) hcl.Diagnostics {
 	var diags hcl.Diagnostics
 	exprs := []hcl.Expression{
+		cr.ForEach,
 		cr.Condition,
 		cr.ErrorMessage,
 	}
@@ -53,7 +92,7 @@ func (cr *CheckRule) validateSelfReferences(checkType string, addr addrs.Resourc
 		if expr == nil {
 			continue
 		}
-		refs, _ := lang.References(addrs.ParseRef, expr.Variables())
+		refs, _ := lang.References(addrs.ParseRef, cr.exprVariables(expr))
 		for _, ref := range refs {
 			var refAddr addrs.Resource
 
@@ -132,6 +171,88 @@ func decodeCheckRuleBlock(block *hcl.Block, override bool) (*CheckRule, hcl.Diag
 	return cr, diags
 }
 
+// decodeDynamicCheckRuleBlock decodes a "dynamic" block inside a "lifecycle"
+// block, which declares a precondition or postcondition for each element of
+// a collection.
+//
+// The result is a single CheckRule whose ForEach and Iterator fields describe
+// the repetition, so that the number of rules for an object is still known
+// statically. The returned string is the type of the generated blocks, taken
+// from the dynamic block's label. The returned rule is nil if the block is
+// too invalid to decode.
+func decodeDynamicCheckRuleBlock(block *hcl.Block, override bool) (*CheckRule, string, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	blockType := block.Labels[0]
+
+	switch blockType {
+	case "precondition", "postcondition":
+	default:
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Unsupported dynamic block type",
+			Detail:   fmt.Sprintf("Only precondition and postcondition blocks can be generated dynamically in a lifecycle block, so %q is not valid here.", blockType),
+			Subject:  block.LabelRanges[0].Ptr(),
+		})
+		return nil, blockType, diags
+	}
+
+	content, moreDiags := block.Body.Content(dynamicCheckRuleBlockSchema)
+	diags = append(diags, moreDiags...)
+
+	var contentBlock *hcl.Block
+	for _, b := range content.Blocks {
+		if contentBlock != nil {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate content block",
+				Detail:   fmt.Sprintf("This dynamic block already has a content block at %s.", contentBlock.DefRange),
+				Subject:  b.DefRange.Ptr(),
+			})
+			continue
+		}
+		contentBlock = b
+	}
+	if contentBlock == nil {
+		if !moreDiags.HasErrors() {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Missing content block",
+				Detail:   fmt.Sprintf("A dynamic %s block requires a nested content block, which describes each of the generated %s blocks.", blockType, blockType),
+				Subject:  block.DefRange.Ptr(),
+			})
+		}
+		return nil, blockType, diags
+	}
+
+	// The content block is decoded as if it were a block of the generated
+	// type, but declared at the location of the dynamic block.
+	cr, moreDiags := decodeCheckRuleBlock(&hcl.Block{
+		Type:      blockType,
+		Body:      contentBlock.Body,
+		DefRange:  block.DefRange,
+		TypeRange: block.TypeRange,
+	}, override)
+	diags = append(diags, moreDiags...)
+
+	cr.Iterator = blockType
+	if attr, exists := content.Attributes["iterator"]; exists {
+		cr.Iterator = hcl.ExprAsKeyword(attr.Expr)
+		if cr.Iterator == "" {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid dynamic iterator name",
+				Detail:   "Dynamic iterator must be a single variable name.",
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+		}
+	}
+	if attr, exists := content.Attributes["for_each"]; exists {
+		cr.ForEach = attr.Expr
+	}
+
+	return cr, blockType, diags
+}
+
 var checkRuleBlockSchema = &hcl.BodySchema{
 	Attributes: []hcl.AttributeSchema{
 		{
@@ -145,6 +266,21 @@ var checkRuleBlockSchema = &hcl.BodySchema{
 	},
 }
 
+var dynamicCheckRuleBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name:     "for_each",
+			Required: true,
+		},
+		{
+			Name: "iterator",
+		},
+	},
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "content"},
+	},
+}
+
 // Check represents a configuration defined check block.
 //
 // A check block contains 0-1 data blocks, and 0-n assert blocks. The check
@@ -153,12 +289,50 @@ var checkRuleBlockSchema = &hcl.BodySchema{
 type Check struct {
 	Name string
 
+	// Severity is the severity of the diagnostics reported when the check
+	// fails during an apply operation. It defaults to tfdiags.Warning, so that
+	// failing checks don't block an apply unless the author opts in.
+	Severity tfdiags.Severity
+
+	// Retry, if set, allows OpenTofu to read the nested data resource several
+	// times during an apply operation, until it reads successfully.
+	Retry *CheckRetry
+
 	DataResource *Resource
 	Asserts      []*CheckRule
 
 	DeclRange hcl.Range
 }
 
+// CheckRetry represents a "retry" block within a check block, which controls
+// how OpenTofu retries the read of the check's nested data resource when it
+// fails during an apply operation.
+type CheckRetry struct {
+	// Attempts is the maximum number of times to read the data resource,
+	// including the first attempt.
+	Attempts int
+
+	// Interval is the time to wait after the first failed attempt. The wait
+	// doubles after each further failed attempt, up to MaxInterval.
+	Interval    time.Duration
+	MaxInterval time.Duration
+
+	DeclRange hcl.Range
+}
+
+// Delay returns the time to wait after the given number of failed attempts,
+// counting from one.
+func (r *CheckRetry) Delay(failures int) time.Duration {
+	delay := r.Interval
+	for i := 1; i < failures; i++ {
+		delay *= 2
+		if delay >= r.MaxInterval {
+			return r.MaxInterval
+		}
+	}
+	return delay
+}
+
 func (c Check) Addr() addrs.Check {
 	return addrs.Check{
 		Name: c.Name,
@@ -177,6 +351,7 @@ func decodeCheckBlock(block *hcl.Block, override bool) (*Check, hcl.Diagnostics)
 
 	check := &Check{
 		Name:      block.Labels[0],
+		Severity:  tfdiags.Warning,
 		DeclRange: block.DefRange,
 	}
 
@@ -206,8 +381,40 @@ func decodeCheckBlock(block *hcl.Block, override bool) (*Check, hcl.Diagnostics)
 		})
 	}
 
+	if attr, exists := content.Attributes["severity"]; exists {
+		switch hcl.ExprAsKeyword(attr.Expr) {
+		case "warning":
+			check.Severity = tfdiags.Warning
+		case "error":
+			check.Severity = tfdiags.Error
+		default:
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid \"severity\" keyword",
+				Detail:   "The \"severity\" argument requires one of the following keywords: warning or error.",
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+		}
+	}
+
 	for _, block := range content.Blocks {
 		switch block.Type {
+		case "retry":
+			if check.Retry != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate retry block",
+					Detail:   fmt.Sprintf("This check block already has a retry block at %s.", check.Retry.DeclRange),
+					Subject:  block.DefRange.Ptr(),
+				})
+				continue
+			}
+
+			retry, moreDiags := decodeCheckRetryBlock(block)
+			diags = append(diags, moreDiags...)
+			if !moreDiags.HasErrors() {
+				check.Retry = retry
+			}
 		case "data":
 
 			if check.DataResource != nil {
@@ -249,12 +456,94 @@ func decodeCheckBlock(block *hcl.Block, override bool) (*Check, hcl.Diagnostics)
 		})
 	}
 
+	if check.Retry != nil && check.DataResource == nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Retry without data resource",
+			Detail:   "A retry block controls how OpenTofu reads the nested data resource of a check block, so it requires a nested data block.",
+			Subject:  check.Retry.DeclRange.Ptr(),
+		})
+	}
+
 	return check, diags
 }
 
+func decodeCheckRetryBlock(block *hcl.Block) (*CheckRetry, hcl.Diagnostics) {
+	retry := &CheckRetry{
+		Attempts:  3,
+		Interval:  5 * time.Second,
+		DeclRange: block.DefRange,
+	}
+
+	content, diags := block.Body.Content(checkRetryBlockSchema)
+
+	if attr, exists := content.Attributes["attempts"]; exists {
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &retry.Attempts)
+		diags = append(diags, valDiags...)
+		if !valDiags.HasErrors() && retry.Attempts < 1 {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid retry attempts",
+				Detail:   "The number of attempts must be at least 1.",
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+		}
+	}
+
+	decodeDuration := func(name string, into *time.Duration) {
+		attr, exists := content.Attributes[name]
+		if !exists {
+			return
+		}
+		var raw string
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &raw)
+		diags = append(diags, valDiags...)
+		if valDiags.HasErrors() {
+			return
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("Invalid retry %s", name),
+				Detail:   fmt.Sprintf("The %q argument must be a non-negative duration string, such as \"10s\" or \"1m30s\".", name),
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+			return
+		}
+		*into = d
+	}
+	decodeDuration("interval", &retry.Interval)
+	retry.MaxInterval = retry.Interval
+	decodeDuration("max_interval", &retry.MaxInterval)
+
+	if retry.MaxInterval < retry.Interval {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid retry max_interval",
+			Detail:   "The \"max_interval\" argument must not be shorter than the \"interval\" argument.",
+			Subject:  retry.DeclRange.Ptr(),
+		})
+	}
+
+	return retry, diags
+}
+
 var checkBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "severity"},
+	},
 	Blocks: []hcl.BlockHeaderSchema{
 		{Type: "data", LabelNames: []string{"type", "name"}},
 		{Type: "assert"},
+		{Type: "retry"},
+	},
+}
+
+var checkRetryBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "attempts"},
+		{Name: "interval"},
+		{Name: "max_interval"},
 	},
 }
@@ -0,0 +1,179 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+func TestCheckBlock_severityAndRetry(t *testing.T) {
+	parser := testParser(map[string]string{
+		"checks.tf": `
+check "default" {
+  assert {
+    condition     = var.ok
+    error_message = "unreachable"
+  }
+}
+
+check "health" {
+  severity = error
+
+  retry {
+    attempts     = 5
+    interval     = "2s"
+    max_interval = "10s"
+  }
+
+  data "http" "health" {}
+
+  assert {
+    condition     = data.http.health.status_code == 200
+    error_message = "unhealthy"
+  }
+}
+`,
+	})
+	file, diags := parser.LoadConfigFile("checks.tf")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected error: %s", diags.Error())
+	}
+	if got, want := len(file.Checks), 2; got != want {
+		t.Fatalf("wrong number of checks %d; want %d", got, want)
+	}
+
+	def := file.Checks[0]
+	if def.Severity != tfdiags.Warning {
+		t.Errorf("wrong default severity %q", def.Severity)
+	}
+	if def.Retry != nil {
+		t.Errorf("unexpected retry: %#v", def.Retry)
+	}
+
+	health := file.Checks[1]
+	if health.Severity != tfdiags.Error {
+		t.Errorf("wrong severity %q", health.Severity)
+	}
+	if health.Retry == nil {
+		t.Fatal("missing retry")
+	}
+	if got, want := health.Retry.Attempts, 5; got != want {
+		t.Errorf("wrong attempts %d; want %d", got, want)
+	}
+
+	var gotDelays []time.Duration
+	for failures := 1; failures < health.Retry.Attempts; failures++ {
+		gotDelays = append(gotDelays, health.Retry.Delay(failures))
+	}
+	wantDelays := []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second}
+	if diff := cmp.Diff(wantDelays, gotDelays); diff != "" {
+		t.Errorf("wrong delays\n%s", diff)
+	}
+}
+
+func TestCheckBlock_invalid(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		want string
+	}{
+		"severity": {
+			`
+check "a" {
+  severity = fatal
+
+  assert {
+    condition     = var.ok
+    error_message = "unreachable"
+  }
+}
+`,
+			`Invalid "severity" keyword`,
+		},
+		"retry without data": {
+			`
+check "a" {
+  retry {}
+
+  assert {
+    condition     = var.ok
+    error_message = "unreachable"
+  }
+}
+`,
+			"Retry without data resource",
+		},
+		"attempts": {
+			`
+check "a" {
+  retry {
+    attempts = 0
+  }
+
+  data "http" "a" {}
+
+  assert {
+    condition     = var.ok
+    error_message = "unreachable"
+  }
+}
+`,
+			"Invalid retry attempts",
+		},
+		"interval": {
+			`
+check "a" {
+  retry {
+    interval = "soon"
+  }
+
+  data "http" "a" {}
+
+  assert {
+    condition     = var.ok
+    error_message = "unreachable"
+  }
+}
+`,
+			"Invalid retry interval",
+		},
+		"max_interval": {
+			`
+check "a" {
+  retry {
+    interval     = "10s"
+    max_interval = "5s"
+  }
+
+  data "http" "a" {}
+
+  assert {
+    condition     = var.ok
+    error_message = "unreachable"
+  }
+}
+`,
+			"Invalid retry max_interval",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			parser := testParser(map[string]string{
+				"checks.tf": test.src,
+			})
+			_, diags := parser.LoadConfigFile("checks.tf")
+			if !diags.HasErrors() {
+				t.Fatal("expected error")
+			}
+			if got := diags[0].Summary; got != test.want {
+				t.Errorf("wrong error\ngot:  %s\nwant: %s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+// Concurrency represents a "concurrency" block inside a "terraform" block in a
+// module or file, which limits how many operations OpenTofu may run at the
+// same time for resources belonging to particular providers or of particular
+// resource types, and how quickly it may start operations for resources
+// belonging to particular providers.
+//
+// These limits are in addition to the global -parallelism limit, and only the
+// settings in the root module are honored.
+type Concurrency struct {
+	// Providers maps provider local names, as declared in the module's
+	// required_providers block, to the maximum number of concurrent
+	// operations for resources belonging to that provider.
+	Providers map[string]int
+
+	// ResourceTypes maps managed resource type names to the maximum number
+	// of concurrent operations for resources of that type.
+	ResourceTypes map[string]int
+
+	// ProviderRates maps provider local names to the maximum number of
+	// operations per second that may start for resources belonging to that
+	// provider.
+	ProviderRates map[string]float64
+
+	DeclRange hcl.Range
+}
+
+func decodeConcurrencyBlock(block *hcl.Block) (*Concurrency, hcl.Diagnostics) {
+	ret := &Concurrency{
+		DeclRange: block.DefRange,
+	}
+
+	content, diags := block.Body.Content(concurrencyBlockSchema)
+
+	if attr, exists := content.Attributes["providers"]; exists {
+		limits, limitDiags := decodeConcurrencyLimits(attr)
+		diags = append(diags, limitDiags...)
+		for name := range limits {
+			diags = append(diags, checkProviderNameNormalized(name, attr.Expr.Range())...)
+		}
+		ret.Providers = limits
+	}
+
+	if attr, exists := content.Attributes["resource_types"]; exists {
+		limits, limitDiags := decodeConcurrencyLimits(attr)
+		diags = append(diags, limitDiags...)
+		for name := range limits {
+			if !hclsyntax.ValidIdentifier(name) {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid resource type name",
+					Detail:   fmt.Sprintf("%q is not a valid resource type name. %s", name, badIdentifierDetail),
+					Subject:  attr.Expr.Range().Ptr(),
+				})
+			}
+		}
+		ret.ResourceTypes = limits
+	}
+
+	if attr, exists := content.Attributes["provider_rate_limits"]; exists {
+		rates, rateDiags := decodeRateLimits(attr)
+		diags = append(diags, rateDiags...)
+		for name := range rates {
+			diags = append(diags, checkProviderNameNormalized(name, attr.Expr.Range())...)
+		}
+		ret.ProviderRates = rates
+	}
+
+	return ret, diags
+}
+
+// decodeConcurrencyLimits decodes the given attribute as a static map of
+// positive whole numbers.
+func decodeConcurrencyLimits(attr *hcl.Attribute) (map[string]int, hcl.Diagnostics) {
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	val, err := convert.Convert(val, cty.Map(cty.Number))
+	if err != nil || val.IsNull() {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid concurrency limits",
+			Detail:   fmt.Sprintf("The %q argument must be a map from names to the maximum number of concurrent operations.", attr.Name),
+			Subject:  attr.Expr.Range().Ptr(),
+		})
+		return nil, diags
+	}
+
+	ret := make(map[string]int, val.LengthInt())
+	for it := val.ElementIterator(); it.Next(); {
+		k, v := it.Element()
+		name := k.AsString()
+
+		var limit int64
+		valid := !v.IsNull()
+		if valid {
+			var acc big.Accuracy
+			limit, acc = v.AsBigFloat().Int64()
+			valid = acc == big.Exact && limit > 0 && limit <= math.MaxInt32
+		}
+		if !valid {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid concurrency limit",
+				Detail:   fmt.Sprintf("The concurrency limit for %q must be a whole number greater than zero.", name),
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+			continue
+		}
+		ret[name] = int(limit)
+	}
+
+	return ret, diags
+}
+
+// decodeRateLimits decodes the given attribute as a static map of positive
+// numbers of operations per second.
+func decodeRateLimits(attr *hcl.Attribute) (map[string]float64, hcl.Diagnostics) {
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	val, err := convert.Convert(val, cty.Map(cty.Number))
+	if err != nil || val.IsNull() {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid rate limits",
+			Detail:   fmt.Sprintf("The %q argument must be a map from names to the maximum number of operations per second.", attr.Name),
+			Subject:  attr.Expr.Range().Ptr(),
+		})
+		return nil, diags
+	}
+
+	ret := make(map[string]float64, val.LengthInt())
+	for it := val.ElementIterator(); it.Next(); {
+		k, v := it.Element()
+		name := k.AsString()
+
+		var rate float64
+		valid := !v.IsNull()
+		if valid {
+			rate, _ = v.AsBigFloat().Float64()
+			valid = rate > 0 && !math.IsInf(rate, 0)
+		}
+		if !valid {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid rate limit",
+				Detail:   fmt.Sprintf("The rate limit for %q must be a number of operations per second greater than zero.", name),
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+			continue
+		}
+		ret[name] = rate
+	}
+
+	return ret, diags
+}
+
+// ProviderLimits returns the provider concurrency limits with the provider
+// local names resolved to fully-qualified provider addresses in the context
+// of the given module.
+func (c *Concurrency) ProviderLimits(mod *Module) map[addrs.Provider]int {
+	if c == nil || len(c.Providers) == 0 {
+		return nil
+	}
+	ret := make(map[addrs.Provider]int, len(c.Providers))
+	for name, limit := range c.Providers {
+		provider := mod.ProviderForLocalConfig(addrs.LocalProviderConfig{LocalName: name})
+		ret[provider] = limit
+	}
+	return ret
+}
+
+// ProviderRateLimits returns the provider rate limits with the provider local
+// names resolved to fully-qualified provider addresses in the context of the
+// given module.
+func (c *Concurrency) ProviderRateLimits(mod *Module) map[addrs.Provider]float64 {
+	if c == nil || len(c.ProviderRates) == 0 {
+		return nil
+	}
+	ret := make(map[addrs.Provider]float64, len(c.ProviderRates))
+	for name, rate := range c.ProviderRates {
+		provider := mod.ProviderForLocalConfig(addrs.LocalProviderConfig{LocalName: name})
+		ret[provider] = rate
+	}
+	return ret
+}
+
+var concurrencyBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "providers"},
+		{Name: "resource_types"},
+		{Name: "provider_rate_limits"},
+	},
+}
@@ -0,0 +1,69 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+func TestConcurrency_ProviderLimits(t *testing.T) {
+	cfg, diags := testModuleConfigFromFile("testdata/valid-files/concurrency.tf")
+	assertNoDiagnostics(t, diags)
+
+	mod := cfg.Module
+	if mod.Concurrency == nil {
+		t.Fatal("module has no concurrency configuration")
+	}
+
+	gotProviders := mod.Concurrency.ProviderLimits(mod)
+	wantProviders := map[addrs.Provider]int{
+		addrs.NewDefaultProvider("aws"): 4,
+	}
+	if diff := cmp.Diff(wantProviders, gotProviders); diff != "" {
+		t.Errorf("wrong provider limits\n%s", diff)
+	}
+
+	wantTypes := map[string]int{
+		"aws_instance": 2,
+	}
+	if diff := cmp.Diff(wantTypes, mod.Concurrency.ResourceTypes); diff != "" {
+		t.Errorf("wrong resource type limits\n%s", diff)
+	}
+
+	gotRates := mod.Concurrency.ProviderRateLimits(mod)
+	wantRates := map[addrs.Provider]float64{
+		addrs.NewDefaultProvider("aws"): 2.5,
+	}
+	if diff := cmp.Diff(wantRates, gotRates); diff != "" {
+		t.Errorf("wrong provider rate limits\n%s", diff)
+	}
+}
+
+func TestConcurrency_duplicate(t *testing.T) {
+	parser := testParser(map[string]string{
+		"mod/a.tf": `
+terraform {
+  concurrency {
+    resource_types = { a = 1 }
+  }
+}
+`,
+		"mod/b.tf": `
+terraform {
+  concurrency {
+    resource_types = { b = 1 }
+  }
+}
+`,
+	})
+
+	_, diags := parser.LoadConfigDir("mod")
+	assertExactDiagnostics(t, diags, []string{
+		`mod/b.tf:3,3-14: Duplicate concurrency configuration; A module may have only one concurrency configuration. The concurrency limits were previously configured at mod/a.tf:3,3-14.`,
+	})
+}
@@ -10,6 +10,7 @@ import (
 
 	version "github.com/hashicorp/go-version"
 	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/depsfile"
@@ -801,6 +802,29 @@ func (c *Config) ProviderTypes() []addrs.Provider {
 	return ret
 }
 
+// OCIProviders returns the providers which the configuration or any of its
+// descendents require to be installed from an OCI registry, by giving an
+// oci:// source address in required_providers.
+func (c *Config) OCIProviders() []addrs.Provider {
+	seen := make(map[addrs.Provider]bool)
+	var ret []addrs.Provider
+	c.DeepEach(func(c *Config) {
+		if c.Module == nil || c.Module.ProviderRequirements == nil {
+			return
+		}
+		for _, rp := range c.Module.ProviderRequirements.RequiredProviders {
+			if rp.IsOCI() && !seen[rp.Type] {
+				seen[rp.Type] = true
+				ret = append(ret, rp.Type)
+			}
+		}
+	})
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].String() < ret[j].String()
+	})
+	return ret
+}
+
 // ResolveAbsProviderAddr returns the AbsProviderConfig represented by the given
 // ProviderConfig address, which must not be nil or this method will panic.
 //
@@ -953,8 +977,107 @@ func (c *Config) TransformForTest(run *TestRun, file *TestFile) (func(), hcl.Dia
 	}
 
 	c.Module.ProviderConfigs = next
+
+	var resetMocks func()
+	if run != nil {
+		var mockDiags hcl.Diagnostics
+		resetMocks, mockDiags = c.mockModulesForTest(run.MockModules)
+		diags = append(diags, mockDiags...)
+	}
+
 	return func() {
 		// Reset the original config within the returned function.
+		if resetMocks != nil {
+			resetMocks()
+		}
 		c.Module.ProviderConfigs = previous
 	}, diags
 }
+
+// mockModulesForTest replaces the modules targeted by the given mocks with
+// modules that only have the input variables of the originals and the output
+// values declared by the mocks. It returns a function that restores the
+// original modules.
+func (c *Config) mockModulesForTest(mocks []*TestMockModule) (func(), hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	// We find all the targets before replacing anything, since a mock may
+	// target a module call within another mocked module.
+	targets := make([]*Config, len(mocks))
+	for i, mock := range mocks {
+		targets[i] = c.Descendent(mock.Target)
+		if targets[i] == nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Module not found",
+				Detail:   fmt.Sprintf("The configuration under test has no module call %s to mock.", mock.Target),
+				Subject:  mock.TargetRange.Ptr(),
+			})
+		}
+	}
+
+	type original struct {
+		module   *Module
+		children map[string]*Config
+	}
+	originals := make(map[*Config]original)
+	for i, mock := range mocks {
+		target := targets[i]
+		if target == nil {
+			continue
+		}
+		if _, ok := originals[target]; !ok {
+			originals[target] = original{module: target.Module, children: target.Children}
+		}
+
+		module := originals[target].module
+		outputs := make(map[string]*Output, len(module.Outputs))
+		for name, output := range module.Outputs {
+			mocked := *output
+			mocked.Expr = hcl.StaticExpr(cty.NullVal(cty.DynamicPseudoType), output.DeclRange)
+			mocked.DependsOn = nil
+			mocked.DependsOnExpr = nil
+			mocked.Preconditions = nil
+			outputs[name] = &mocked
+		}
+		for name, expr := range mock.Outputs {
+			output, ok := outputs[name]
+			if !ok {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Undeclared output value",
+					Detail:   fmt.Sprintf("%s has no output value named %q to mock.", mock.Target, name),
+					Subject:  expr.Range().Ptr(),
+				})
+				continue
+			}
+			output.Expr = expr
+		}
+
+		target.Module = &Module{
+			SourceDir:            module.SourceDir,
+			ActiveExperiments:    module.ActiveExperiments,
+			ProviderConfigs:      make(map[string]*Provider),
+			ProviderRequirements: module.ProviderRequirements,
+			ProviderLocalNames:   module.ProviderLocalNames,
+			ProviderMetas:        make(map[addrs.Provider]*ProviderMeta),
+			Variables:            module.Variables,
+			Locals:               make(map[string]*Local),
+			Outputs:              outputs,
+			Functions:            module.Functions,
+			ModuleCalls:          make(map[string]*ModuleCall),
+			ManagedResources:     make(map[string]*Resource),
+			DataResources:        make(map[string]*Resource),
+			Checks:               make(map[string]*Check),
+			Tests:                make(map[string]*TestFile),
+		}
+		target.Children = make(map[string]*Config)
+	}
+
+	return func() {
+		for target, original := range originals {
+			target.Module = original.module
+			target.Children = original.children
+		}
+	}, diags
+}
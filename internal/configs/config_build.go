@@ -191,6 +191,24 @@ func loadModule(root *Config, req *ModuleRequest, walker ModuleWalker) (*Config,
 		})
 	}
 
+	if mod.Concurrency != nil {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Concurrency configuration ignored",
+			Detail:   "Concurrency limits apply to the entire configuration, so OpenTofu honors them only in the root module.\n\nThis is a warning rather than an error because it's sometimes convenient to temporarily call a root module as a child module for testing purposes, but this concurrency block will have no effect.",
+			Subject:  mod.Concurrency.DeclRange.Ptr(),
+		})
+	}
+
+	if mod.Hooks != nil {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Hooks configuration ignored",
+			Detail:   "Hooks run for operations on the entire configuration, so OpenTofu honors them only in the root module.\n\nThis is a warning rather than an error because it's sometimes convenient to temporarily call a root module as a child module for testing purposes, but this hooks block will have no effect.",
+			Subject:  mod.Hooks.DeclRange.Ptr(),
+		})
+	}
+
 	if len(mod.Import) > 0 {
 		diags = diags.Append(&hcl.Diagnostic{
 			Severity: hcl.DiagError,
@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"testing"
 
@@ -827,3 +828,48 @@ func TestTransformForTest(t *testing.T) {
 		})
 	}
 }
+
+func TestTransformForTest_mockModules(t *testing.T) {
+	cfg, diags := testNestedModuleConfigFromDir(t, "testdata/valid-modules/mock-module")
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	child := cfg.Children["child"]
+	original := child.Module
+
+	mockOutputs := map[string]hcl.Expression{
+		"undeclared": hcl.StaticExpr(cty.StringVal("foo"), hcl.Range{}),
+	}
+	run := &TestRun{
+		MockModules: []*TestMockModule{
+			{Target: addrs.RootModule.Child("child"), Outputs: map[string]hcl.Expression{}},
+			{Target: addrs.RootModule.Child("missing")},
+			{Target: addrs.RootModule.Child("child").Child("grandchild"), Outputs: mockOutputs},
+		},
+	}
+	reset, diags := cfg.TransformForTest(run, &TestFile{})
+
+	var summaries []string
+	for _, diag := range diags {
+		summaries = append(summaries, diag.Summary)
+	}
+	sort.Strings(summaries)
+	if diff := cmp.Diff([]string{"Module not found", "Undeclared output value"}, summaries); diff != "" {
+		t.Errorf("wrong diagnostics:\n%s", diff)
+	}
+
+	if child.Module == original {
+		t.Fatalf("module.child was not mocked")
+	}
+	if len(child.Module.ManagedResources) != 0 || len(child.Children) != 0 {
+		t.Errorf("mocked module.child still has resources or child modules")
+	}
+	if len(child.Module.Variables) != len(original.Variables) {
+		t.Errorf("mocked module.child doesn't have the variables of the original")
+	}
+
+	reset()
+	if child.Module != original || len(child.Children) == 0 {
+		t.Errorf("module.child was not restored")
+	}
+}
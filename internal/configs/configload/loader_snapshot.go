@@ -197,6 +197,22 @@ func (l *Loader) addModuleToSnapshot(snap *Snapshot, key string, dir string, sou
 		snapMod.Files[filepath.Clean(filename)] = src
 	}
 
+	// The versions file that applies to the module may be in a parent
+	// directory, which isn't part of the snapshot, so we include it in the
+	// module directory itself, where it takes precedence over any other.
+	if versionsPath := l.parser.VersionsFilePath(dir); versionsPath != "" {
+		src, exists := sources[versionsPath]
+		if !exists {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Missing source file for snapshot",
+				Detail:   fmt.Sprintf("The source code for file %s could not be found to produce a configuration snapshot.", versionsPath),
+			})
+		} else {
+			snapMod.Files[configs.VersionsFileName] = src
+		}
+	}
+
 	snap.Modules[key] = snapMod
 
 	return diags
@@ -143,3 +143,28 @@ func TestSnapshotRoundtrip(t *testing.T) {
 		t.Errorf("wrong number of module calls in child_a %d; want %d", got, want)
 	}
 }
+
+func TestSnapshotRoundtrip_versionsFile(t *testing.T) {
+	// The versions file is in a parent directory of the root module, which
+	// isn't otherwise part of the snapshot.
+	fixtureDir := filepath.Clean("testdata/versions-file/root")
+	loader, err := NewLoader(&Config{
+		ModulesDir: filepath.Join(fixtureDir, ".terraform/modules"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error from NewLoader: %s", err)
+	}
+
+	_, snap, diags := loader.LoadConfigWithSnapshot(fixtureDir)
+	assertNoDiagnostics(t, diags)
+	if _, exists := snap.Modules[""].Files["versions.tofu"]; !exists {
+		t.Fatalf("root module snapshot does not include the versions file")
+	}
+
+	config, diags := NewLoaderFromSnapshot(snap).LoadConfig(fixtureDir)
+	assertNoDiagnostics(t, diags)
+	req := config.Module.ProviderRequirements.RequiredProviders["null"]
+	if got, want := req.Requirement.Required.String(), "3.2.1"; got != want {
+		t.Errorf("wrong version constraint %q; want %q", got, want)
+	}
+}
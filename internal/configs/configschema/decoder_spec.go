@@ -184,7 +184,10 @@ func (b *Block) DecoderSpec() hcldec.Spec {
 }
 
 func (a *Attribute) decoderSpec(name string) hcldec.Spec {
-	ret := &hcldec.AttrSpec{Name: name}
+	// hcldec requires a type for every attribute, so an attribute with no
+	// schema at all, which should not happen outside of tests, accepts any
+	// value.
+	ret := &hcldec.AttrSpec{Name: name, Type: cty.DynamicPseudoType}
 	if a == nil {
 		return ret
 	}
@@ -200,7 +203,9 @@ func (a *Attribute) decoderSpec(name string) hcldec.Spec {
 		return ret
 	}
 
-	ret.Type = a.Type
+	if a.Type != cty.NilType {
+		ret.Type = a.Type
+	}
 	ret.Required = a.Required
 	return ret
 }
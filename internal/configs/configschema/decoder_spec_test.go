@@ -448,13 +448,13 @@ func TestAttributeDecoderSpec(t *testing.T) {
 		"empty": {
 			&Attribute{},
 			hcl.EmptyBody(),
-			cty.NilVal,
+			cty.NullVal(cty.DynamicPseudoType),
 			0,
 		},
 		"nil": {
 			nil,
 			hcl.EmptyBody(),
-			cty.NilVal,
+			cty.NullVal(cty.DynamicPseudoType),
 			0,
 		},
 		"optional string (null)": {
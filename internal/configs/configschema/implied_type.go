@@ -59,6 +59,22 @@ func (b *Block) ContainsSensitive() bool {
 	return false
 }
 
+// ContainsEphemeral returns true if any of the attributes of the receiving
+// block or any of its descendent blocks are marked as ephemeral.
+func (b *Block) ContainsEphemeral() bool {
+	for _, attrS := range b.Attributes {
+		if attrS.Ephemeral {
+			return true
+		}
+	}
+	for _, blockS := range b.BlockTypes {
+		if blockS.ContainsEphemeral() {
+			return true
+		}
+	}
+	return false
+}
+
 // ImpliedType returns the cty.Type that would result from decoding a Block's
 // ImpliedType and getting the resulting AttributeType.
 //
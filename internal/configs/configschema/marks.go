@@ -90,6 +90,54 @@ func (b *Block) ValueMarks(val cty.Value, path cty.Path) []cty.PathValueMarks {
 	return pvm
 }
 
+// EphemeralMarks returns a set of path value marks for a given value and
+// path, marking each attribute whose schema has the ephemeral flag set with
+// marks.Ephemeral. Nested blocks are descended (if present in the given
+// value), but attributes with nested types are not.
+func (b *Block) EphemeralMarks(val cty.Value, path cty.Path) []cty.PathValueMarks {
+	var pvm []cty.PathValueMarks
+
+	for name, attrS := range b.Attributes {
+		if attrS.Ephemeral {
+			pvm = append(pvm, cty.PathValueMarks{
+				Path:  copyAndExtendPath(path, cty.GetAttrStep{Name: name}),
+				Marks: cty.NewValueMarks(marks.Ephemeral),
+			})
+		}
+	}
+
+	if val.IsNull() {
+		return pvm
+	}
+
+	for name, blockS := range b.BlockTypes {
+		if !blockS.Block.ContainsEphemeral() {
+			continue
+		}
+
+		blockV := val.GetAttr(name)
+		if blockV.IsNull() || !blockV.IsKnown() {
+			continue
+		}
+
+		blockPath := copyAndExtendPath(path, cty.GetAttrStep{Name: name})
+
+		switch blockS.Nesting {
+		case NestingSingle, NestingGroup:
+			pvm = append(pvm, blockS.Block.EphemeralMarks(blockV, blockPath)...)
+		case NestingList, NestingMap, NestingSet:
+			for it := blockV.ElementIterator(); it.Next(); {
+				idx, blockEV := it.Element()
+				blockInstancePath := copyAndExtendPath(blockPath, cty.IndexStep{Key: idx})
+				pvm = append(pvm, blockS.Block.EphemeralMarks(blockEV, blockInstancePath)...)
+			}
+		default:
+			panic(fmt.Sprintf("unsupported nesting mode %s", blockS.Nesting))
+		}
+	}
+	return pvm
+}
+
 // ValueMarks returns a set of path value marks for a given value and path,
 // based on the sensitive flag for each attribute within the nested attribute.
 // Attributes with nested types are descended (if present in the given value).
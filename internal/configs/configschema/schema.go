@@ -78,6 +78,13 @@ type Attribute struct {
 	Sensitive bool
 
 	Deprecated bool
+
+	// Ephemeral, if set to true, indicates that the attribute's value is
+	// only used during the current run, such as a short-lived credential
+	// that the provider sends to a remote API. OpenTofu never saves the
+	// values of ephemeral attributes in a plan or state, and ephemeral
+	// values can only be assigned to ephemeral attributes.
+	Ephemeral bool
 }
 
 // Object represents the embedding of a structural object inside an Attribute.
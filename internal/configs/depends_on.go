@@ -5,6 +5,9 @@ package configs
 
 import (
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/opentofu/opentofu/internal/addrs"
 )
 
 func decodeDependsOn(attr *hcl.Attribute) ([]hcl.Traversal, hcl.Diagnostics) {
@@ -24,3 +27,110 @@ func decodeDependsOn(attr *hcl.Attribute) ([]hcl.Traversal, hcl.Diagnostics) {
 
 	return ret, diags
 }
+
+// decodeDependsOnExpr is like decodeDependsOn, but also accepts arbitrary
+// expressions that produce a collection of objects, such as conditional
+// expressions, splat expressions and for expressions, both as elements of the
+// list and in place of the whole list.
+//
+// The returned traversals are the static references to whole objects that
+// the expression could possibly depend on, which OpenTofu uses for ordering
+// in the same way as for a static list. If the attribute is not a static list
+// of references then the expression is returned too, so that its value can be
+// checked during validation; otherwise the returned expression is nil.
+func decodeDependsOnExpr(attr *hcl.Attribute) ([]hcl.Traversal, hcl.Expression, hcl.Diagnostics) {
+	var ret []hcl.Traversal
+	var diags hcl.Diagnostics
+	dynamic := false
+
+	exprs, listDiags := hcl.ExprList(attr.Expr)
+	if listDiags.HasErrors() {
+		if !isDependsOnExpr(attr.Expr) {
+			return nil, nil, listDiags
+		}
+		return dependsOnTargets(attr.Expr), attr.Expr, nil
+	}
+
+	for _, expr := range exprs {
+		expr, shimDiags := shimTraversalInString(expr, false)
+		diags = append(diags, shimDiags...)
+
+		traversal, travDiags := hcl.AbsTraversalForExpr(expr)
+		if travDiags.HasErrors() && isDependsOnExpr(expr) {
+			dynamic = true
+			ret = append(ret, dependsOnTargets(expr)...)
+			continue
+		}
+		diags = append(diags, travDiags...)
+		if len(traversal) != 0 {
+			ret = append(ret, traversal)
+		}
+	}
+
+	if dynamic {
+		return ret, attr.Expr, diags
+	}
+	return ret, nil, diags
+}
+
+// isDependsOnExpr returns true if the given expression, which isn't a single
+// static reference, can still be used to describe dependencies.
+//
+// Only native syntax expressions qualify, because in the JSON syntax every
+// depends_on element is a string containing a reference. Templates are
+// excluded so that strings that aren't valid references are reported as such,
+// rather than being treated as expressions producing strings.
+func isDependsOnExpr(expr hcl.Expression) bool {
+	switch expr.(type) {
+	case *hclsyntax.TemplateExpr, *hclsyntax.TemplateWrapExpr, *hclsyntax.LiteralValueExpr:
+		return false
+	case hclsyntax.Expression:
+		return true
+	default:
+		return false
+	}
+}
+
+// dependsOnTargets returns the references in the given expression that
+// refer to objects that can be dependencies, trimmed to refer to those whole
+// objects rather than to any of their attributes.
+//
+// References to other objects, such as input variables or local values, are
+// needed to evaluate the expression but aren't dependencies themselves.
+func dependsOnTargets(expr hcl.Expression) []hcl.Traversal {
+	var ret []hcl.Traversal
+	for _, traversal := range expr.Variables() {
+		ref, diags := addrs.ParseRef(traversal)
+		if diags.HasErrors() {
+			// Invalid references are reported when the expression is
+			// evaluated during validation.
+			continue
+		}
+
+		n := len(traversal) - len(ref.Remaining)
+		switch ref.Subject.(type) {
+		case addrs.Resource, addrs.ResourceInstance, addrs.ModuleCall, addrs.ModuleCallInstance:
+		case addrs.ModuleCallInstanceOutput:
+			// The output name is always a single attribute step after the
+			// module call, which we don't want to include.
+			n--
+		default:
+			continue
+		}
+		ret = append(ret, traversal[:n])
+	}
+	return ret
+}
+
+// dependsOnRange returns the closest source range we have for a depends_on
+// argument, or nil if the given values don't represent one.
+func dependsOnRange(deps []hcl.Traversal, expr hcl.Expression) *hcl.Range {
+	switch {
+	case expr != nil:
+		return expr.Range().Ptr()
+	case len(deps) != 0:
+		return deps[0].SourceRange().Ptr() // the first item is the closest range we have
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func TestDecodeDependsOnExpr(t *testing.T) {
+	tests := map[string]struct {
+		src      string
+		want     []string
+		wantExpr bool
+		wantErr  string
+	}{
+		"static references": {
+			src:  `[test_instance.a, module.b]`,
+			want: []string{"test_instance.a", "module.b"},
+		},
+		"splat": {
+			src:      `[test_instance.a[*]]`,
+			want:     []string{"test_instance.a"},
+			wantExpr: true,
+		},
+		"for expression": {
+			src:      `[for k, v in test_instance.a : v if k != var.skip]`,
+			want:     []string{"test_instance.a"},
+			wantExpr: true,
+		},
+		"conditional list": {
+			src:      `var.enabled ? [test_instance.a, data.test_data.b] : [module.c.out]`,
+			want:     []string{"test_instance.a", "data.test_data.b", "module.c"},
+			wantExpr: true,
+		},
+		"mixed static and dynamic elements": {
+			src:      `[module.b, values(test_instance.a)]`,
+			want:     []string{"module.b", "test_instance.a"},
+			wantExpr: true,
+		},
+		"resource instance": {
+			src:      `[local.enabled ? test_instance.a["foo"] : null]`,
+			want:     []string{`test_instance.a["foo"]`},
+			wantExpr: true,
+		},
+		"template": {
+			src:     `["${var.name}"]`,
+			wantErr: "A single static variable reference is required",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(test.src), "test.tf", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			deps, depsExpr, diags := decodeDependsOnExpr(&hcl.Attribute{Name: "depends_on", Expr: expr})
+			if test.wantErr != "" {
+				if !diags.HasErrors() {
+					t.Fatalf("succeeded; want error %q", test.wantErr)
+				}
+				if got := diags[0].Detail; !strings.HasPrefix(got, test.wantErr) {
+					t.Fatalf("wrong error\ngot:  %s\nwant: message starting with %q", got, test.wantErr)
+				}
+				return
+			}
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			var got []string
+			for _, traversal := range deps {
+				rng := traversal.SourceRange()
+				got = append(got, test.src[rng.Start.Byte:rng.End.Byte])
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("wrong references\n%s", diff)
+			}
+			if got := depsExpr != nil; got != test.wantExpr {
+				t.Errorf("wrong expression presence %t; want %t", got, test.wantExpr)
+			}
+		})
+	}
+}
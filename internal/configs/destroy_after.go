@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+// decodeDestroyAfter decodes the destroy_after lifecycle argument, which is
+// a static list of references to managed resources and module calls in the
+// same module.
+func decodeDestroyAfter(attr *hcl.Attribute) ([]*addrs.Reference, hcl.Diagnostics) {
+	var ret []*addrs.Reference
+	exprs, diags := hcl.ExprList(attr.Expr)
+
+	for _, expr := range exprs {
+		traversal, travDiags := hcl.AbsTraversalForExpr(expr)
+		diags = append(diags, travDiags...)
+		if travDiags.HasErrors() {
+			continue
+		}
+		ref, refDiags := addrs.ParseRef(traversal)
+		diags = append(diags, refDiags.ToHCL()...)
+		if refDiags.HasErrors() {
+			continue
+		}
+
+		valid := false
+		switch subject := ref.Subject.(type) {
+		case addrs.Resource:
+			valid = subject.Mode == addrs.ManagedResourceMode
+		case addrs.ResourceInstance:
+			valid = subject.Resource.Mode == addrs.ManagedResourceMode
+		case addrs.ModuleCall, addrs.ModuleCallInstance:
+			valid = true
+		}
+		if !valid || len(ref.Remaining) != 0 {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid destroy_after reference",
+				Detail:   "The destroy_after argument accepts only references to managed resources and module calls, such as aws_instance.example or module.network.",
+				Subject:  expr.Range().Ptr(),
+			})
+			continue
+		}
+		ret = append(ret, ref)
+	}
+
+	return ret, diags
+}
+
+// checkDestroyAfter checks that the destroy_after arguments of the managed
+// resources in the given module refer to resources and module calls that
+// the module declares.
+func checkDestroyAfter(mod *Module) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	for _, r := range mod.ManagedResources {
+		for _, ref := range r.Managed.DestroyAfter {
+			var exists bool
+			switch subject := ref.Subject.(type) {
+			case addrs.Resource:
+				exists = mod.ResourceByAddr(subject) != nil
+			case addrs.ResourceInstance:
+				exists = mod.ResourceByAddr(subject.Resource) != nil
+			case addrs.ModuleCall:
+				exists = mod.ModuleCalls[subject.Name] != nil
+			case addrs.ModuleCallInstance:
+				exists = mod.ModuleCalls[subject.Call.Name] != nil
+			}
+			if !exists {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Reference to undeclared object in destroy_after",
+					Detail:   fmt.Sprintf("The destroy_after argument of %s refers to %s, which is not declared in this module.", r.Addr(), ref.Subject),
+					Subject:  ref.SourceRange.ToHCL().Ptr(),
+				})
+			}
+		}
+	}
+
+	return diags
+}
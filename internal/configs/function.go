@@ -0,0 +1,203 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/lang"
+)
+
+// Function represents a "function" block in a module or file, which defines
+// a pure function that can be called from any expression in the same module.
+//
+// The result of the function is given by an expression which can refer only
+// to the function's parameters, by name, and call other functions.
+type Function struct {
+	Name        string
+	Description string
+	Params      []*FunctionParam
+	Result      hcl.Expression
+
+	DeclRange hcl.Range
+}
+
+// FunctionParam represents a "parameter" block inside a "function" block.
+type FunctionParam struct {
+	Name string
+
+	// Type is the type constraint that arguments for this parameter are
+	// converted to. It is cty.DynamicPseudoType if the parameter accepts
+	// any type.
+	Type cty.Type
+
+	DeclRange hcl.Range
+}
+
+// UserFunction returns the representation of the function that the lang
+// package uses to call it.
+func (f *Function) UserFunction() *lang.UserFunction {
+	params := make([]lang.UserFunctionParam, len(f.Params))
+	for i, p := range f.Params {
+		params[i] = lang.UserFunctionParam{
+			Name: p.Name,
+			Type: p.Type,
+		}
+	}
+	return &lang.UserFunction{
+		Description: f.Description,
+		Params:      params,
+		Result:      f.Result,
+	}
+}
+
+// UserFunctions returns the functions defined in the module, in the form
+// that the lang package uses to call them.
+func (m *Module) UserFunctions() map[string]*lang.UserFunction {
+	if len(m.Functions) == 0 {
+		return nil
+	}
+	ret := make(map[string]*lang.UserFunction, len(m.Functions))
+	for name, f := range m.Functions {
+		ret[name] = f.UserFunction()
+	}
+	return ret
+}
+
+func decodeFunctionBlock(block *hcl.Block) (*Function, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	ret := &Function{
+		Name:      block.Labels[0],
+		DeclRange: block.DefRange,
+	}
+
+	if !hclsyntax.ValidIdentifier(ret.Name) {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid function name",
+			Detail:   badIdentifierDetail,
+			Subject:  &block.LabelRanges[0],
+		})
+	} else if lang.IsBuiltinFunction(ret.Name) {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid function name",
+			Detail:   fmt.Sprintf("The name %q is already used by a built-in function. Choose a different name for this function.", ret.Name),
+			Subject:  &block.LabelRanges[0],
+		})
+	}
+
+	content, moreDiags := block.Body.Content(functionBlockSchema)
+	diags = append(diags, moreDiags...)
+
+	if attr, exists := content.Attributes["description"]; exists {
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &ret.Description)
+		diags = append(diags, valDiags...)
+	}
+
+	params := make(map[string]*FunctionParam)
+	for _, block := range content.Blocks {
+		param, paramDiags := decodeFunctionParamBlock(block)
+		diags = append(diags, paramDiags...)
+		if param == nil {
+			continue
+		}
+		if existing, exists := params[param.Name]; exists {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate function parameter",
+				Detail:   fmt.Sprintf("A parameter named %q was already declared at %s. Parameter names must be unique within a function.", param.Name, existing.DeclRange),
+				Subject:  &param.DeclRange,
+			})
+			continue
+		}
+		params[param.Name] = param
+		ret.Params = append(ret.Params, param)
+	}
+
+	if attr, exists := content.Attributes["result"]; exists {
+		ret.Result = attr.Expr
+
+		// The result can only depend on the arguments, so that the function
+		// is pure and can be evaluated wherever it's called.
+		for _, traversal := range attr.Expr.Variables() {
+			name := traversal.RootName()
+			if _, isParam := params[name]; isParam {
+				continue
+			}
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid reference in function result",
+				Detail:   fmt.Sprintf("The result of function %q can only refer to the function's parameters, but there is no parameter named %q.", ret.Name, name),
+				Subject:  traversal.SourceRange().Ptr(),
+			})
+		}
+	}
+
+	return ret, diags
+}
+
+func decodeFunctionParamBlock(block *hcl.Block) (*FunctionParam, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	ret := &FunctionParam{
+		Name:      block.Labels[0],
+		Type:      cty.DynamicPseudoType,
+		DeclRange: block.DefRange,
+	}
+
+	if !hclsyntax.ValidIdentifier(ret.Name) {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid parameter name",
+			Detail:   badIdentifierDetail,
+			Subject:  &block.LabelRanges[0],
+		})
+		return nil, diags
+	}
+
+	content, moreDiags := block.Body.Content(functionParamBlockSchema)
+	diags = append(diags, moreDiags...)
+
+	if attr, exists := content.Attributes["type"]; exists {
+		ty, tyDiags := typeexpr.TypeConstraint(attr.Expr)
+		diags = append(diags, tyDiags...)
+		if !tyDiags.HasErrors() {
+			ret.Type = ty
+		}
+	}
+
+	return ret, diags
+}
+
+var functionBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name: "description",
+		},
+		{
+			Name:     "result",
+			Required: true,
+		},
+	},
+	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type:       "parameter",
+			LabelNames: []string{"name"},
+		},
+	},
+}
+
+var functionParamBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name: "type",
+		},
+	},
+}
@@ -0,0 +1,93 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestFunction_decode(t *testing.T) {
+	cfg, diags := testModuleConfigFromFile("testdata/valid-files/functions.tf")
+	assertNoDiagnostics(t, diags)
+
+	fn, ok := cfg.Module.Functions["name_prefix"]
+	if !ok {
+		t.Fatal("module has no name_prefix function")
+	}
+	if got, want := fn.Description, "Returns the prefix for the names of a tenant's resources."; got != want {
+		t.Errorf("wrong description %q; want %q", got, want)
+	}
+	if got, want := len(fn.Params), 2; got != want {
+		t.Fatalf("wrong number of parameters %d; want %d", got, want)
+	}
+	if got, want := fn.Params[0].Name, "tenant"; got != want {
+		t.Errorf("wrong name for first parameter %q; want %q", got, want)
+	}
+	if got, want := fn.Params[0].Type, cty.String; !got.Equals(want) {
+		t.Errorf("wrong type for first parameter %#v; want %#v", got, want)
+	}
+	if got, want := fn.Params[1].Type, cty.DynamicPseudoType; !got.Equals(want) {
+		t.Errorf("wrong type for second parameter %#v; want %#v", got, want)
+	}
+
+	fns := cfg.Module.UserFunctions()
+	if got, want := len(fns), 2; got != want {
+		t.Fatalf("wrong number of user functions %d; want %d", got, want)
+	}
+	if got, want := fns["standard_tags"].Params[0].Type, cty.Map(cty.String); !got.Equals(want) {
+		t.Errorf("wrong type for standard_tags parameter %#v; want %#v", got, want)
+	}
+}
+
+func TestFunction_duplicate(t *testing.T) {
+	parser := testParser(map[string]string{
+		"mod/a.tf": `
+function "f" {
+  result = 1
+}
+`,
+		"mod/b.tf": `
+function "f" {
+  result = 2
+}
+`,
+	})
+
+	_, diags := parser.LoadConfigDir("mod")
+	assertExactDiagnostics(t, diags, []string{
+		`mod/b.tf:2,1-13: Duplicate function definition; A function named "f" was already defined at mod/a.tf:2,1-13. Function names must be unique within a module.`,
+	})
+}
+
+func TestFunction_override(t *testing.T) {
+	parser := testParser(map[string]string{
+		"mod/main.tf": `
+function "f" {
+  parameter "x" {
+  }
+  result = x
+}
+`,
+		"mod/main_override.tf": `
+function "f" {
+  result = 2
+}
+
+function "g" {
+  result = 3
+}
+`,
+	})
+
+	mod, diags := parser.LoadConfigDir("mod")
+	assertExactDiagnostics(t, diags, []string{
+		`mod/main_override.tf:6,1-13: Missing base function definition to override; There is no function named "g". An override file can only override a function that was already defined in a primary configuration file.`,
+	})
+
+	if got := len(mod.Functions["f"].Params); got != 0 {
+		t.Errorf("overridden function has %d parameters; want 0", got)
+	}
+}
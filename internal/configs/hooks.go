@@ -0,0 +1,220 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+)
+
+// HookEvent is a point during an operation at which OpenTofu runs hooks.
+type HookEvent string
+
+const (
+	HookPrePlan          HookEvent = "pre_plan"
+	HookPostPlan         HookEvent = "post_plan"
+	HookPreResourceApply HookEvent = "pre_resource_apply"
+	HookPostApply        HookEvent = "post_apply"
+	HookOnFailure        HookEvent = "on_failure"
+
+	// HookPlanEnrichment hooks run after a plan is created, like post_plan
+	// hooks, but respond with information that OpenTofu adds to the plan,
+	// such as a cost estimate.
+	HookPlanEnrichment HookEvent = "plan_enrichment"
+)
+
+// HookErrorPolicy decides what happens when a hook fails or times out.
+type HookErrorPolicy string
+
+const (
+	// HookErrorFail makes a failed hook fail the operation. For
+	// pre_resource_apply hooks, only the change to the resource instance
+	// being applied fails.
+	HookErrorFail HookErrorPolicy = "fail"
+
+	// HookErrorWarn reports a failed hook as a warning and continues.
+	HookErrorWarn HookErrorPolicy = "warn"
+)
+
+// defaultHookTimeout is how long a hook may run if its configuration doesn't
+// set a timeout.
+const defaultHookTimeout = time.Minute
+
+// Hooks represents a "hooks" block inside a "terraform" block in a module or
+// file, which configures external commands or webhooks to run at particular
+// points during plan and apply operations.
+//
+// Only the hooks in the root module are honored.
+type Hooks struct {
+	// Hooks are the configured hooks in the order they were declared. Hooks
+	// for the same event run one at a time in that order.
+	Hooks []*Hook
+
+	DeclRange hcl.Range
+}
+
+// Hook represents a single hook in a "hooks" block, which is declared as a
+// nested block whose type is the event it runs for.
+type Hook struct {
+	Event HookEvent
+
+	// Exactly one of Command and URL is set. Command is a program and its
+	// arguments, which receives the context of the event as JSON on its
+	// standard input, while URL is a webhook that receives the same JSON
+	// in the body of a POST request.
+	Command []string
+	URL     string
+
+	Timeout time.Duration
+	OnError HookErrorPolicy
+
+	DeclRange hcl.Range
+}
+
+// ForEvent returns the hooks for the given event, in the order they were
+// declared.
+func (h *Hooks) ForEvent(event HookEvent) []*Hook {
+	if h == nil {
+		return nil
+	}
+	var ret []*Hook
+	for _, hook := range h.Hooks {
+		if hook.Event == event {
+			ret = append(ret, hook)
+		}
+	}
+	return ret
+}
+
+func decodeHooksBlock(block *hcl.Block) (*Hooks, hcl.Diagnostics) {
+	ret := &Hooks{
+		DeclRange: block.DefRange,
+	}
+
+	content, diags := block.Body.Content(hooksBlockSchema)
+	for _, block := range content.Blocks {
+		hook, moreDiags := decodeHookBlock(block)
+		diags = append(diags, moreDiags...)
+		if hook != nil {
+			ret.Hooks = append(ret.Hooks, hook)
+		}
+	}
+
+	return ret, diags
+}
+
+func decodeHookBlock(block *hcl.Block) (*Hook, hcl.Diagnostics) {
+	hook := &Hook{
+		Event:     HookEvent(block.Type),
+		Timeout:   defaultHookTimeout,
+		OnError:   HookErrorFail,
+		DeclRange: block.DefRange,
+	}
+
+	content, diags := block.Body.Content(hookBlockSchema)
+
+	if attr, exists := content.Attributes["command"]; exists {
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &hook.Command)
+		diags = append(diags, valDiags...)
+		if !valDiags.HasErrors() && (len(hook.Command) == 0 || hook.Command[0] == "") {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid hook command",
+				Detail:   "The \"command\" argument must be a list whose first element is the program to run, followed by its arguments.",
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+		}
+	}
+
+	if attr, exists := content.Attributes["url"]; exists {
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &hook.URL)
+		diags = append(diags, valDiags...)
+		if !valDiags.HasErrors() {
+			u, err := url.Parse(hook.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid hook URL",
+					Detail:   fmt.Sprintf("The \"url\" argument must be an absolute http or https URL, not %q.", hook.URL),
+					Subject:  attr.Expr.Range().Ptr(),
+				})
+			}
+		}
+	}
+
+	_, hasCommand := content.Attributes["command"]
+	_, hasURL := content.Attributes["url"]
+	if hasCommand == hasURL {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid hook",
+			Detail:   "A hook must set exactly one of the \"command\" and \"url\" arguments.",
+			Subject:  &hook.DeclRange,
+		})
+	}
+
+	if attr, exists := content.Attributes["timeout"]; exists {
+		var raw string
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &raw)
+		diags = append(diags, valDiags...)
+		if !valDiags.HasErrors() {
+			d, err := time.ParseDuration(raw)
+			if err != nil || d <= 0 {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid hook timeout",
+					Detail:   "The \"timeout\" argument must be a positive duration string, such as \"30s\" or \"5m\".",
+					Subject:  attr.Expr.Range().Ptr(),
+				})
+			} else {
+				hook.Timeout = d
+			}
+		}
+	}
+
+	if attr, exists := content.Attributes["on_error"]; exists {
+		var raw string
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &raw)
+		diags = append(diags, valDiags...)
+		if !valDiags.HasErrors() {
+			switch policy := HookErrorPolicy(raw); policy {
+			case HookErrorFail, HookErrorWarn:
+				hook.OnError = policy
+			default:
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid hook error policy",
+					Detail:   "The \"on_error\" argument must be either \"fail\" or \"warn\".",
+					Subject:  attr.Expr.Range().Ptr(),
+				})
+			}
+		}
+	}
+
+	return hook, diags
+}
+
+var hooksBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: string(HookPrePlan)},
+		{Type: string(HookPostPlan)},
+		{Type: string(HookPreResourceApply)},
+		{Type: string(HookPostApply)},
+		{Type: string(HookOnFailure)},
+		{Type: string(HookPlanEnrichment)},
+	},
+}
+
+var hookBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "command"},
+		{Name: "url"},
+		{Name: "timeout"},
+		{Name: "on_error"},
+	},
+}
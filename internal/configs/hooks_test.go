@@ -0,0 +1,127 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHooks(t *testing.T) {
+	parser := testParser(map[string]string{
+		"main.tf": `
+terraform {
+  hooks {
+    pre_plan {
+      command = ["./check.sh", "-v"]
+    }
+    post_apply {
+      url      = "https://example.com/notify"
+      timeout  = "10s"
+      on_error = "warn"
+    }
+    pre_plan {
+      command = ["./second.sh"]
+    }
+  }
+}
+`,
+	})
+
+	mod, diags := parser.LoadConfigDir(".")
+	assertNoDiagnostics(t, diags)
+	if mod.Hooks == nil {
+		t.Fatal("module has no hooks configuration")
+	}
+
+	type hook struct {
+		Command []string
+		URL     string
+		Timeout time.Duration
+		OnError HookErrorPolicy
+	}
+	summarize := func(hooks []*Hook) []hook {
+		var ret []hook
+		for _, h := range hooks {
+			ret = append(ret, hook{h.Command, h.URL, h.Timeout, h.OnError})
+		}
+		return ret
+	}
+
+	wantPrePlan := []hook{
+		{Command: []string{"./check.sh", "-v"}, Timeout: time.Minute, OnError: HookErrorFail},
+		{Command: []string{"./second.sh"}, Timeout: time.Minute, OnError: HookErrorFail},
+	}
+	if diff := cmp.Diff(wantPrePlan, summarize(mod.Hooks.ForEvent(HookPrePlan))); diff != "" {
+		t.Errorf("wrong pre_plan hooks\n%s", diff)
+	}
+	wantPostApply := []hook{
+		{URL: "https://example.com/notify", Timeout: 10 * time.Second, OnError: HookErrorWarn},
+	}
+	if diff := cmp.Diff(wantPostApply, summarize(mod.Hooks.ForEvent(HookPostApply))); diff != "" {
+		t.Errorf("wrong post_apply hooks\n%s", diff)
+	}
+	if got := mod.Hooks.ForEvent(HookOnFailure); len(got) != 0 {
+		t.Errorf("unexpected on_failure hooks: %#v", got)
+	}
+}
+
+func TestHooks_invalid(t *testing.T) {
+	parser := testParser(map[string]string{
+		"main.tf": `
+terraform {
+  hooks {
+    pre_plan {
+    }
+    post_plan {
+      command = ["./check.sh"]
+      url     = "https://example.com/"
+    }
+    on_failure {
+      url      = "example.com"
+      timeout  = "soon"
+      on_error = "ignore"
+    }
+    pre_resource_apply {
+      command = []
+    }
+  }
+}
+`,
+	})
+
+	_, diags := parser.LoadConfigDir(".")
+	assertExactDiagnostics(t, diags, []string{
+		`main.tf:4,5-13: Invalid hook; A hook must set exactly one of the "command" and "url" arguments.`,
+		`main.tf:6,5-14: Invalid hook; A hook must set exactly one of the "command" and "url" arguments.`,
+		`main.tf:11,18-31: Invalid hook URL; The "url" argument must be an absolute http or https URL, not "example.com".`,
+		`main.tf:12,18-24: Invalid hook timeout; The "timeout" argument must be a positive duration string, such as "30s" or "5m".`,
+		`main.tf:13,18-26: Invalid hook error policy; The "on_error" argument must be either "fail" or "warn".`,
+		`main.tf:16,17-19: Invalid hook command; The "command" argument must be a list whose first element is the program to run, followed by its arguments.`,
+	})
+}
+
+func TestHooks_duplicate(t *testing.T) {
+	parser := testParser(map[string]string{
+		"a.tf": `
+terraform {
+  hooks {
+  }
+}
+`,
+		"b.tf": `
+terraform {
+  hooks {
+  }
+}
+`,
+	})
+
+	_, diags := parser.LoadConfigDir(".")
+	assertExactDiagnostics(t, diags, []string{
+		`b.tf:3,3-8: Duplicate hooks configuration; A module may have only one hooks configuration. The hooks were previously configured at a.tf:3,3-8.`,
+	})
+}
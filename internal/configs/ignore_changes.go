@@ -0,0 +1,99 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// IgnoreChangesWhen represents an "ignore_changes_when" block within a
+// managed resource's lifecycle block, which ignores changes to some of the
+// resource's attributes only while a condition is true.
+type IgnoreChangesWhen struct {
+	// Condition is evaluated when planning each of the resource's instances,
+	// and must produce a boolean value.
+	Condition hcl.Expression
+
+	// Attributes are the attributes whose changes are ignored while the
+	// condition is true, in the same form as ManagedResource.IgnoreChanges.
+	Attributes []hcl.Traversal
+
+	DeclRange hcl.Range
+}
+
+func decodeIgnoreChangesWhenBlock(block *hcl.Block) (*IgnoreChangesWhen, hcl.Diagnostics) {
+	ret := &IgnoreChangesWhen{
+		DeclRange: block.DefRange,
+	}
+
+	content, diags := block.Body.Content(ignoreChangesWhenBlockSchema)
+
+	if attr, exists := content.Attributes["condition"]; exists {
+		ret.Condition = attr.Expr
+	}
+
+	if attr, exists := content.Attributes["attributes"]; exists {
+		exprs, listDiags := hcl.ExprList(attr.Expr)
+		diags = append(diags, listDiags...)
+		for _, expr := range exprs {
+			traversal, travDiags := decodeIgnoreChangesTraversal(expr)
+			diags = append(diags, travDiags...)
+			if len(traversal) != 0 {
+				ret.Attributes = append(ret.Attributes, traversal)
+			}
+		}
+	}
+
+	return ret, diags
+}
+
+// decodeIgnoreChangesTraversal decodes a single element of ignore_changes
+// as a relative traversal.
+//
+// In addition to the usual attribute and index steps, an element may use
+// splat syntax, like ingress[*].description, to refer to all of the elements
+// of a list or map, which is represented as an hcl.TraverseSplat step with
+// no Each traversal. A string index key may also contain "*" wildcards, like
+// tags["kubernetes.io/*"], to refer to all of the matching keys of a map,
+// but that is left for the caller to interpret.
+func decodeIgnoreChangesTraversal(expr hcl.Expression) (hcl.Traversal, hcl.Diagnostics) {
+	expr, diags := shimTraversalInString(expr, false)
+
+	switch expr := expr.(type) {
+	case *hclsyntax.SplatExpr:
+		src, moreDiags := decodeIgnoreChangesTraversal(expr.Source)
+		diags = append(diags, moreDiags...)
+		each, moreDiags := decodeIgnoreChangesTraversal(expr.Each)
+		diags = append(diags, moreDiags...)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		ret := make(hcl.Traversal, 0, len(src)+len(each)+1)
+		ret = append(ret, src...)
+		ret = append(ret, hcl.TraverseSplat{SrcRange: expr.MarkerRange})
+		return append(ret, each...), diags
+
+	case *hclsyntax.RelativeTraversalExpr:
+		if _, ok := expr.Source.(*hclsyntax.AnonSymbolExpr); ok {
+			// This is the part of a splat expression that applies to each
+			// element, like .description in ingress[*].description.
+			return expr.Traversal, diags
+		}
+
+	case *hclsyntax.AnonSymbolExpr:
+		// A splat expression with nothing after it, like tags[*].
+		return hcl.Traversal{}, diags
+	}
+
+	traversal, travDiags := hcl.RelTraversalForExpr(expr)
+	return traversal, append(diags, travDiags...)
+}
+
+var ignoreChangesWhenBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "condition", Required: true},
+		{Name: "attributes", Required: true},
+	},
+}
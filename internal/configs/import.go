@@ -5,6 +5,8 @@ package configs
 
 import (
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
 	"github.com/opentofu/opentofu/internal/addrs"
 )
 
@@ -12,6 +14,16 @@ type Import struct {
 	ID hcl.Expression
 	To addrs.AbsResourceInstance
 
+	// ForEach is the for_each expression of an import block that imports
+	// one resource instance for each element, or nil if the block imports
+	// only a single resource instance.
+	ForEach hcl.Expression
+
+	// ToKey is the expression for the instance key given in the "to"
+	// argument of an import block with ForEach, which can refer to each.key
+	// and each.value. To then has no instance key.
+	ToKey hcl.Expression
+
 	ProviderConfigRef *ProviderConfigRef
 	Provider          addrs.Provider
 
@@ -32,14 +44,37 @@ func decodeImportBlock(block *hcl.Block) (*Import, hcl.Diagnostics) {
 		imp.ID = attr.Expr
 	}
 
+	if attr, exists := content.Attributes["for_each"]; exists {
+		imp.ForEach = attr.Expr
+	}
+
 	if attr, exists := content.Attributes["to"]; exists {
-		traversal, traversalDiags := hcl.AbsTraversalForExpr(attr.Expr)
+		toExpr := attr.Expr
+		if imp.ForEach != nil {
+			// With for_each, the instance key can be any expression, which
+			// we'll evaluate for each element during planning.
+			if indexExpr, ok := toExpr.(*hclsyntax.IndexExpr); ok {
+				toExpr = indexExpr.Collection
+				imp.ToKey = indexExpr.Key
+			}
+		}
+
+		traversal, traversalDiags := hcl.AbsTraversalForExpr(toExpr)
 		diags = append(diags, traversalDiags...)
 		if !traversalDiags.HasErrors() {
 			to, toDiags := addrs.ParseAbsResourceInstance(traversal)
 			diags = append(diags, toDiags.ToHCL()...)
 			imp.To = to
 		}
+
+		if imp.ForEach != nil && (imp.ToKey == nil || imp.To.Resource.Key != addrs.NoKey) {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid import address",
+				Detail:   "An import block with for_each imports one resource instance for each element, so its \"to\" address must end with an instance key that refers to each.key or each.value, such as aws_instance.example[each.key].",
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+		}
 	}
 
 	if attr, exists := content.Attributes["provider"]; exists {
@@ -66,6 +101,9 @@ var importBlockSchema = &hcl.BodySchema{
 		{
 			Name: "provider",
 		},
+		{
+			Name: "for_each",
+		},
 		{
 			Name:     "id",
 			Required: true,
@@ -39,18 +39,23 @@ type Module struct {
 	ProviderRequirements *RequiredProviders
 	ProviderLocalNames   map[addrs.Provider]string
 	ProviderMetas        map[addrs.Provider]*ProviderMeta
+	Concurrency          *Concurrency
+	Hooks                *Hooks
 
 	Variables map[string]*Variable
 	Locals    map[string]*Local
 	Outputs   map[string]*Output
 
+	Functions map[string]*Function
+
 	ModuleCalls map[string]*ModuleCall
 
 	ManagedResources map[string]*Resource
 	DataResources    map[string]*Resource
 
-	Moved  []*Moved
-	Import []*Import
+	Moved   []*Moved
+	Removed []*Removed
+	Import  []*Import
 
 	Checks map[string]*Check
 
@@ -78,18 +83,23 @@ type File struct {
 	ProviderConfigs   []*Provider
 	ProviderMetas     []*ProviderMeta
 	RequiredProviders []*RequiredProviders
+	Concurrency       []*Concurrency
+	Hooks             []*Hooks
 
 	Variables []*Variable
 	Locals    []*Local
 	Outputs   []*Output
 
+	Functions []*Function
+
 	ModuleCalls []*ModuleCall
 
 	ManagedResources []*Resource
 	DataResources    []*Resource
 
-	Moved  []*Moved
-	Import []*Import
+	Moved   []*Moved
+	Removed []*Removed
+	Import  []*Import
 
 	Checks []*Check
 }
@@ -120,6 +130,7 @@ func NewModule(primaryFiles, overrideFiles []*File) (*Module, hcl.Diagnostics) {
 		Variables:          map[string]*Variable{},
 		Locals:             map[string]*Local{},
 		Outputs:            map[string]*Output{},
+		Functions:          map[string]*Function{},
 		ModuleCalls:        map[string]*ModuleCall{},
 		ManagedResources:   map[string]*Resource{},
 		DataResources:      map[string]*Resource{},
@@ -174,6 +185,7 @@ func NewModule(primaryFiles, overrideFiles []*File) (*Module, hcl.Diagnostics) {
 	}
 
 	diags = append(diags, checkModuleExperiments(mod)...)
+	diags = append(diags, checkDestroyAfter(mod)...)
 
 	// Generate the FQN -> LocalProviderName map
 	mod.gatherProviderLocalNames()
@@ -276,6 +288,32 @@ func (m *Module) appendFile(file *File) hcl.Diagnostics {
 		m.ProviderMetas[provider] = pm
 	}
 
+	for _, c := range file.Concurrency {
+		if m.Concurrency != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate concurrency configuration",
+				Detail:   fmt.Sprintf("A module may have only one concurrency configuration. The concurrency limits were previously configured at %s.", m.Concurrency.DeclRange),
+				Subject:  &c.DeclRange,
+			})
+			continue
+		}
+		m.Concurrency = c
+	}
+
+	for _, h := range file.Hooks {
+		if m.Hooks != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate hooks configuration",
+				Detail:   fmt.Sprintf("A module may have only one hooks configuration. The hooks were previously configured at %s.", m.Hooks.DeclRange),
+				Subject:  &h.DeclRange,
+			})
+			continue
+		}
+		m.Hooks = h
+	}
+
 	for _, v := range file.Variables {
 		if existing, exists := m.Variables[v.Name]; exists {
 			diags = append(diags, &hcl.Diagnostic{
@@ -312,6 +350,18 @@ func (m *Module) appendFile(file *File) hcl.Diagnostics {
 		m.Outputs[o.Name] = o
 	}
 
+	for _, f := range file.Functions {
+		if existing, exists := m.Functions[f.Name]; exists {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate function definition",
+				Detail:   fmt.Sprintf("A function named %q was already defined at %s. Function names must be unique within a module.", existing.Name, existing.DeclRange),
+				Subject:  &f.DeclRange,
+			})
+		}
+		m.Functions[f.Name] = f
+	}
+
 	for _, mc := range file.ModuleCalls {
 		if existing, exists := m.ModuleCalls[mc.Name]; exists {
 			diags = append(diags, &hcl.Diagnostic{
@@ -420,9 +470,29 @@ func (m *Module) appendFile(file *File) hcl.Diagnostics {
 	// runtime.)
 	m.Moved = append(m.Moved, file.Moved...)
 
+	for _, r := range file.Removed {
+		if r.From == nil {
+			continue // invalid address, already reported during decoding
+		}
+		for _, mr := range m.Removed {
+			if mr.From != nil && mr.From.String() == r.From.String() {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate removed block",
+					Detail:   fmt.Sprintf("A removed block for %s was already declared at %s. Each resource or module call can have only one removed block.", r.From, mr.DeclRange),
+					Subject:  &r.DeclRange,
+				})
+			}
+		}
+		m.Removed = append(m.Removed, r)
+	}
+
 	for _, i := range file.Import {
 		for _, mi := range m.Import {
-			if i.To.Equal(mi.To) {
+			// Import blocks with for_each can target different instances
+			// of the same resource, so we check for duplicates among their
+			// instances during planning instead.
+			if i.ForEach == nil && mi.ForEach == nil && i.To.Equal(mi.To) {
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  fmt.Sprintf("Duplicate import configuration for %q", i.To),
@@ -453,7 +523,7 @@ func (m *Module) appendFile(file *File) hcl.Diagnostics {
 			// Comparing string serialisations is good enough here, because we
 			// only care about equality in the case that both addresses are
 			// AbsResourceInstances.
-			if mb.From.String() == i.To.String() {
+			if mb.FromModuleSource == nil && mb.From.String() == i.To.String() {
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Cannot import to a move source",
@@ -514,6 +584,37 @@ func (m *Module) mergeFile(file *File) hcl.Diagnostics {
 		}
 	}
 
+	if len(file.Concurrency) != 0 {
+		switch len(file.Concurrency) {
+		case 1:
+			m.Concurrency = file.Concurrency[0]
+		default:
+			// As with backends, an override file may replace the concurrency
+			// configuration from other files but may not itself have more
+			// than one.
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate concurrency configuration",
+				Detail:   fmt.Sprintf("Each override file may have only one concurrency configuration. The concurrency limits were previously configured at %s.", file.Concurrency[0].DeclRange),
+				Subject:  &file.Concurrency[1].DeclRange,
+			})
+		}
+	}
+
+	if len(file.Hooks) != 0 {
+		switch len(file.Hooks) {
+		case 1:
+			m.Hooks = file.Hooks[0]
+		default:
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate hooks configuration",
+				Detail:   fmt.Sprintf("Each override file may have only one hooks configuration. The hooks were previously configured at %s.", file.Hooks[0].DeclRange),
+				Subject:  &file.Hooks[1].DeclRange,
+			})
+		}
+	}
+
 	for _, pc := range file.ProviderConfigs {
 		key := pc.moduleUniqueKey()
 		existing, exists := m.ProviderConfigs[key]
@@ -591,6 +692,21 @@ func (m *Module) mergeFile(file *File) hcl.Diagnostics {
 		diags = append(diags, mergeDiags...)
 	}
 
+	for _, f := range file.Functions {
+		if _, exists := m.Functions[f.Name]; !exists {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Missing base function definition to override",
+				Detail:   fmt.Sprintf("There is no function named %q. An override file can only override a function that was already defined in a primary configuration file.", f.Name),
+				Subject:  &f.DeclRange,
+			})
+			continue
+		}
+		// Functions are always replaced as a whole, because their parameters
+		// and result only make sense together.
+		m.Functions[f.Name] = f
+	}
+
 	for _, mc := range file.ModuleCalls {
 		existing, exists := m.ModuleCalls[mc.Name]
 		if !exists {
@@ -647,6 +763,15 @@ func (m *Module) mergeFile(file *File) hcl.Diagnostics {
 		})
 	}
 
+	for _, m := range file.Removed {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Cannot override 'removed' blocks",
+			Detail:   "Removed blocks can appear only in normal files, not in override files.",
+			Subject:  m.DeclRange.Ptr(),
+		})
+	}
+
 	for _, m := range file.Import {
 		diags = append(diags, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
@@ -30,14 +30,27 @@ type ModuleCall struct {
 	Count   hcl.Expression
 	ForEach hcl.Expression
 
+	// Enabled is the "enabled" argument of the module call's lifecycle
+	// block, if set. A module call with this argument has a single instance
+	// without an instance key if the value is true, or no instances at all
+	// if it is false.
+	Enabled hcl.Expression
+
 	Providers []PassedProviderConfig
 
 	DependsOn []hcl.Traversal
 
+	// DependsOnExpr is the whole depends_on expression when it is not a
+	// static list of references, in which case DependsOn holds the
+	// references it could possibly depend on. It is nil otherwise.
+	DependsOnExpr hcl.Expression
+
 	DeclRange hcl.Range
 }
 
-func decodeModuleBlock(block *hcl.Block, override bool) (*ModuleCall, hcl.Diagnostics) {
+// decodeModuleBlock decodes a module block. The version argument is
+// evaluated in the given context, so that it can refer to version constants.
+func decodeModuleBlock(block *hcl.Block, override bool, versionsCtx *hcl.EvalContext) (*ModuleCall, hcl.Diagnostics) {
 	var diags hcl.Diagnostics
 
 	mc := &ModuleCall{
@@ -66,7 +79,7 @@ func decodeModuleBlock(block *hcl.Block, override bool) (*ModuleCall, hcl.Diagno
 	haveVersionArg := false
 	if attr, exists := content.Attributes["version"]; exists {
 		var versionDiags hcl.Diagnostics
-		mc.Version, versionDiags = decodeVersionConstraint(attr)
+		mc.Version, versionDiags = decodeVersionConstraint(attr, versionsCtx)
 		diags = append(diags, versionDiags...)
 		haveVersionArg = true
 	}
@@ -152,9 +165,10 @@ func decodeModuleBlock(block *hcl.Block, override bool) (*ModuleCall, hcl.Diagno
 	}
 
 	if attr, exists := content.Attributes["depends_on"]; exists {
-		deps, depsDiags := decodeDependsOn(attr)
+		deps, depsExpr, depsDiags := decodeDependsOnExpr(attr)
 		diags = append(diags, depsDiags...)
 		mc.DependsOn = append(mc.DependsOn, deps...)
+		mc.DependsOnExpr = depsExpr
 	}
 
 	if attr, exists := content.Attributes["providers"]; exists {
@@ -163,7 +177,7 @@ func decodeModuleBlock(block *hcl.Block, override bool) (*ModuleCall, hcl.Diagno
 		mc.Providers = append(mc.Providers, providers...)
 	}
 
-	var seenEscapeBlock *hcl.Block
+	var seenEscapeBlock, seenLifecycle *hcl.Block
 	for _, block := range content.Blocks {
 		switch block.Type {
 		case "_":
@@ -186,6 +200,34 @@ func decodeModuleBlock(block *hcl.Block, override bool) (*ModuleCall, hcl.Diagno
 			// will see a blend of both.
 			mc.Config = hcl.MergeBodies([]hcl.Body{mc.Config, block.Body})
 
+		case "lifecycle":
+			if seenLifecycle != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate lifecycle block",
+					Detail:   fmt.Sprintf("This module block already has a lifecycle block at %s.", seenLifecycle.DefRange),
+					Subject:  &block.DefRange,
+				})
+				continue
+			}
+			seenLifecycle = block
+
+			lcContent, lcDiags := block.Body.Content(moduleLifecycleBlockSchema)
+			diags = append(diags, lcDiags...)
+
+			if attr, exists := lcContent.Attributes["enabled"]; exists {
+				if mc.Count != nil || mc.ForEach != nil {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  `Invalid combination of "enabled" and "count" or "for_each"`,
+						Detail:   `The "enabled" argument is mutually-exclusive with the "count" and "for_each" meta-arguments. Use "enabled" for a module call that has either one instance or none, and "count" or "for_each" for a module call that can have many instances.`,
+						Subject:  &attr.NameRange,
+					})
+				}
+
+				mc.Enabled = attr.Expr
+			}
+
 		default:
 			// All of the other block types in our schema are reserved.
 			diags = append(diags, &hcl.Diagnostic{
@@ -289,6 +331,14 @@ var moduleBlockSchema = &hcl.BodySchema{
 	},
 }
 
+var moduleLifecycleBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name: "enabled",
+		},
+	},
+}
+
 func moduleSourceAddrEntersNewPackage(addr addrs.ModuleSource) bool {
 	switch addr.(type) {
 	case nil:
@@ -33,6 +33,10 @@ func (p *Provider) merge(op *Provider) hcl.Diagnostics {
 		p.Version = op.Version
 	}
 
+	if op.Inherit != nil {
+		p.Inherit = op.Inherit
+	}
+
 	p.Config = MergeBodies(p.Config, op.Config)
 
 	return diags
@@ -63,6 +67,14 @@ func (v *Variable) merge(ov *Variable) hcl.Diagnostics {
 		v.Nullable = ov.Nullable
 		v.NullableSet = ov.NullableSet
 	}
+	if ov.EphemeralSet {
+		v.Ephemeral = ov.Ephemeral
+		v.EphemeralSet = ov.EphemeralSet
+	}
+	if ov.DeprecatedSet {
+		v.Deprecated = ov.Deprecated
+		v.DeprecatedSet = ov.DeprecatedSet
+	}
 
 	// If the override file overrode type without default or vice-versa then
 	// it may have created an invalid situation, which we'll catch now by
@@ -148,15 +160,40 @@ func (o *Output) merge(oo *Output) hcl.Diagnostics {
 		o.Sensitive = oo.Sensitive
 		o.SensitiveSet = oo.SensitiveSet
 	}
+	if oo.SensitiveAttributesSet {
+		o.SensitiveAttributes = oo.SensitiveAttributes
+		o.SensitiveAttributesSet = oo.SensitiveAttributesSet
+	}
+	if oo.EphemeralSet {
+		o.Ephemeral = oo.Ephemeral
+		o.EphemeralSet = oo.EphemeralSet
+	}
+	if oo.DeprecatedSet {
+		o.Deprecated = oo.Deprecated
+		o.DeprecatedSet = oo.DeprecatedSet
+	}
+	if oo.ConstraintType != cty.NilType {
+		o.Type = oo.Type
+		o.ConstraintType = oo.ConstraintType
+		o.TypeDefaults = oo.TypeDefaults
+	}
+	if oo.NullableSet {
+		o.Nullable = oo.Nullable
+		o.NullableSet = oo.NullableSet
+	}
+	if oo.StableSet {
+		o.Stable = oo.Stable
+		o.StableSet = oo.StableSet
+	}
 
 	// We don't allow depends_on to be overridden because that is likely to
 	// cause confusing misbehavior.
-	if len(oo.DependsOn) != 0 {
+	if rng := dependsOnRange(oo.DependsOn, oo.DependsOnExpr); rng != nil {
 		diags = append(diags, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Unsupported override",
 			Detail:   "The depends_on argument may not be overridden.",
-			Subject:  oo.DependsOn[0].SourceRange().Ptr(), // the first item is the closest range we have
+			Subject:  rng,
 		})
 	}
 
@@ -181,6 +218,10 @@ func (mc *ModuleCall) merge(omc *ModuleCall) hcl.Diagnostics {
 		mc.ForEach = omc.ForEach
 	}
 
+	if omc.Enabled != nil {
+		mc.Enabled = omc.Enabled
+	}
+
 	if len(omc.Version.Required) != 0 {
 		mc.Version = omc.Version
 	}
@@ -193,12 +234,12 @@ func (mc *ModuleCall) merge(omc *ModuleCall) hcl.Diagnostics {
 
 	// We don't allow depends_on to be overridden because that is likely to
 	// cause confusing misbehavior.
-	if len(omc.DependsOn) != 0 {
+	if rng := dependsOnRange(omc.DependsOn, omc.DependsOnExpr); rng != nil {
 		diags = append(diags, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Unsupported override",
 			Detail:   "The depends_on argument may not be overridden.",
-			Subject:  omc.DependsOn[0].SourceRange().Ptr(), // the first item is the closest range we have
+			Subject:  rng,
 		})
 	}
 
@@ -242,12 +283,20 @@ func (r *Resource) merge(or *Resource, rps map[string]*RequiredProvider) hcl.Dia
 			r.Managed.CreateBeforeDestroy = or.Managed.CreateBeforeDestroy
 			r.Managed.CreateBeforeDestroySet = or.Managed.CreateBeforeDestroySet
 		}
+		if or.Managed.CreateBeforeDestroyGroup != "" {
+			r.Managed.CreateBeforeDestroyGroup = or.Managed.CreateBeforeDestroyGroup
+			r.Managed.CreateBeforeDestroyGroupRange = or.Managed.CreateBeforeDestroyGroupRange
+			r.Managed.CreateBeforeDestroy = true
+		}
 		if len(or.Managed.IgnoreChanges) != 0 {
 			r.Managed.IgnoreChanges = or.Managed.IgnoreChanges
 		}
 		if or.Managed.IgnoreAllChanges {
 			r.Managed.IgnoreAllChanges = true
 		}
+		if len(or.Managed.IgnoreChangesWhen) != 0 {
+			r.Managed.IgnoreChangesWhen = or.Managed.IgnoreChangesWhen
+		}
 		if or.Managed.PreventDestroySet {
 			r.Managed.PreventDestroy = or.Managed.PreventDestroy
 			r.Managed.PreventDestroySet = or.Managed.PreventDestroySet
@@ -255,18 +304,27 @@ func (r *Resource) merge(or *Resource, rps map[string]*RequiredProvider) hcl.Dia
 		if len(or.Managed.Provisioners) != 0 {
 			r.Managed.Provisioners = or.Managed.Provisioners
 		}
+		if len(or.Managed.DestroyAfter) != 0 {
+			r.Managed.DestroyAfter = or.Managed.DestroyAfter
+		}
+		if or.Managed.Retry != nil {
+			r.Managed.Retry = or.Managed.Retry
+		}
+		if or.Managed.OperationTimeout != 0 {
+			r.Managed.OperationTimeout = or.Managed.OperationTimeout
+		}
 	}
 
 	r.Config = MergeBodies(r.Config, or.Config)
 
 	// We don't allow depends_on to be overridden because that is likely to
 	// cause confusing misbehavior.
-	if len(or.DependsOn) != 0 {
+	if rng := dependsOnRange(or.DependsOn, or.DependsOnExpr); rng != nil {
 		diags = append(diags, &hcl.Diagnostic{
 			Severity: hcl.DiagError,
 			Summary:  "Unsupported override",
 			Detail:   "The depends_on argument may not be overridden.",
-			Subject:  or.DependsOn[0].SourceRange().Ptr(), // the first item is the closest range we have
+			Subject:  rng,
 		})
 	}
 
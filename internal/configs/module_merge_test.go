@@ -353,3 +353,34 @@ func TestModuleOverrideIgnoreAllChanges(t *testing.T) {
 		t.Fatalf("wrong result: expected r.Managed.IgnoreAllChanges to be true")
 	}
 }
+
+func TestModuleOverrideDeprecated(t *testing.T) {
+	mod, diags := testModuleFromDir("testdata/valid-modules/override-deprecated")
+	assertNoDiagnostics(t, diags)
+
+	if got, want := mod.Variables["deprecated_in_override"].Deprecated, "Use something else instead."; got != want {
+		t.Errorf("wrong deprecation message for deprecated_in_override\ngot:  %q\nwant: %q", got, want)
+	}
+	if got, want := mod.Variables["deprecated_in_primary"].Deprecated, "Use something else."; got != want {
+		t.Errorf("wrong deprecation message for deprecated_in_primary\ngot:  %q\nwant: %q", got, want)
+	}
+	if got, want := mod.Outputs["deprecated_in_override"].Deprecated, "Use a different output instead."; got != want {
+		t.Errorf("wrong deprecation message for output deprecated_in_override\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestModuleOverrideOutputContract(t *testing.T) {
+	mod, diags := testModuleFromDir("testdata/valid-modules/override-output-contract")
+	assertNoDiagnostics(t, diags)
+
+	got := mod.Outputs["id"]
+	if !got.Type.Equals(cty.String) {
+		t.Errorf("wrong type %#v; want cty.String", got.Type)
+	}
+	if got.Nullable {
+		t.Errorf("output is nullable; want not nullable")
+	}
+	if !got.Stable {
+		t.Errorf("output is not stable; want stable")
+	}
+}
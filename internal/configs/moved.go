@@ -4,7 +4,11 @@
 package configs
 
 import (
+	"fmt"
+
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+
 	"github.com/opentofu/opentofu/internal/addrs"
 )
 
@@ -12,6 +16,14 @@ type Moved struct {
 	From *addrs.MoveEndpoint
 	To   *addrs.MoveEndpoint
 
+	// FromModuleSource is the source address of the module package that the
+	// objects used to belong to, if this block records a move from a module
+	// in a different package. From is then relative to the module call for
+	// that package in the calling module, rather than to the module that
+	// contains this block.
+	FromModuleSource      addrs.ModuleSource
+	FromModuleSourceRange hcl.Range
+
 	DeclRange hcl.Range
 }
 
@@ -44,6 +56,39 @@ func decodeMovedBlock(block *hcl.Block) (*Moved, hcl.Diagnostics) {
 		}
 	}
 
+	if attr, exists := content.Attributes["from_module_source"]; exists {
+		var raw string
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &raw)
+		diags = append(diags, valDiags...)
+		if !valDiags.HasErrors() {
+			source, err := addrs.ParseModuleSource(raw)
+			if err != nil {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid module source address",
+					Detail:   fmt.Sprintf("Failed to parse module source address: %s.", err),
+					Subject:  attr.Expr.Range().Ptr(),
+				})
+			}
+			moved.FromModuleSource = source
+			moved.FromModuleSourceRange = attr.Expr.Range()
+		}
+	}
+
+	if !diags.HasErrors() && moved.FromModuleSource != nil {
+		// Moves from another module package can only refer to resources,
+		// because we match them by provider, type and name when the module
+		// call for that package has been removed.
+		if moved.From.ObjectKind() != addrs.MoveEndpointResource || moved.To.ObjectKind() != addrs.MoveEndpointResource {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid \"moved\" addresses",
+				Detail:   "A moved block with from_module_source can only move resources, so the \"from\" and \"to\" addresses must both refer to resources.",
+				Subject:  &moved.DeclRange,
+			})
+		}
+	}
+
 	// we can only move from a module to a module, resource to resource, etc.
 	if !diags.HasErrors() {
 		if !moved.From.MightUnifyWith(moved.To) {
@@ -71,5 +116,8 @@ var movedBlockSchema = &hcl.BodySchema{
 			Name:     "to",
 			Required: true,
 		},
+		{
+			Name: "from_module_source",
+		},
 	},
 }
@@ -5,6 +5,7 @@ package configs
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/ext/typeexpr"
@@ -45,6 +46,18 @@ type Variable struct {
 	Nullable    bool
 	NullableSet bool
 
+	// Ephemeral indicates that the value of this variable is only available
+	// during the current run and must never be saved in a plan or state.
+	Ephemeral    bool
+	EphemeralSet bool
+
+	// Deprecated is a message explaining why the variable is deprecated and
+	// what callers should do instead, or an empty string if the variable is
+	// not deprecated. Calls that set a deprecated variable produce a
+	// warning that includes this message.
+	Deprecated    string
+	DeprecatedSet bool
+
 	DeclRange hcl.Range
 }
 
@@ -120,6 +133,19 @@ func decodeVariableBlock(block *hcl.Block, override bool) (*Variable, hcl.Diagno
 		v.SensitiveSet = true
 	}
 
+	if attr, exists := content.Attributes["ephemeral"]; exists {
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &v.Ephemeral)
+		diags = append(diags, valDiags...)
+		v.EphemeralSet = true
+	}
+
+	if attr, exists := content.Attributes["deprecated"]; exists {
+		var moreDiags hcl.Diagnostics
+		v.Deprecated, moreDiags = decodeDeprecatedMessage(attr)
+		diags = append(diags, moreDiags...)
+		v.DeprecatedSet = true
+	}
+
 	if attr, exists := content.Attributes["nullable"]; exists {
 		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &v.Nullable)
 		diags = append(diags, valDiags...)
@@ -148,7 +174,7 @@ func decodeVariableBlock(block *hcl.Block, override bool) (*Variable, hcl.Diagno
 			// type default application process as a special case, to allow
 			// nullable variables to have a null default value.
 			if v.TypeDefaults != nil && !val.IsNull() {
-				val = v.TypeDefaults.Apply(val)
+				val = ApplyTypeDefaults(v.TypeDefaults, val)
 			}
 			val, err = convert.Convert(val, v.ConstraintType)
 			if err != nil {
@@ -398,10 +424,55 @@ type Output struct {
 	DependsOn   []hcl.Traversal
 	Sensitive   bool
 
+	// DependsOnExpr is the whole depends_on expression when it is not a
+	// static list of references. See Resource.DependsOnExpr.
+	DependsOnExpr hcl.Expression
+
+	// SensitiveAttributes are the paths of the parts of the output value
+	// that are sensitive, relative to the value itself. An output value with
+	// sensitive attributes can contain sensitive values only at these paths,
+	// but its other parts are not sensitive, unlike with Sensitive.
+	SensitiveAttributes []hcl.Traversal
+
 	Preconditions []*CheckRule
 
-	DescriptionSet bool
-	SensitiveSet   bool
+	// Ephemeral indicates that the output value may contain ephemeral
+	// values, which are only available during the current run. Only the
+	// outputs of child modules can be ephemeral, since root module outputs
+	// are saved in the state.
+	Ephemeral bool
+
+	// Deprecated is a message explaining why the output value is deprecated
+	// and what callers should use instead, or an empty string if the output
+	// value is not deprecated. References to a deprecated output value from
+	// the calling module produce a warning that includes this message.
+	Deprecated string
+
+	// Type, ConstraintType and TypeDefaults describe the output value's
+	// optional type constraint, with the same meaning as the fields of the
+	// same names in Variable. The value is converted to the constraint type
+	// before it is used, and calling modules can rely on the type even
+	// before the value is known. Type is cty.NilType if the output value
+	// has no type constraint.
+	Type           cty.Type
+	ConstraintType cty.Type
+	TypeDefaults   *typeexpr.Defaults
+
+	// Nullable is false if the output value declares that it is never null.
+	Nullable bool
+
+	// Stable is true if the output value declares that it is always known
+	// during planning, so that calling modules can use it where unknown
+	// values are not allowed, such as in count and for_each.
+	Stable bool
+
+	DescriptionSet         bool
+	SensitiveSet           bool
+	SensitiveAttributesSet bool
+	EphemeralSet           bool
+	DeprecatedSet          bool
+	NullableSet            bool
+	StableSet              bool
 
 	DeclRange hcl.Range
 }
@@ -447,10 +518,68 @@ func decodeOutputBlock(block *hcl.Block, override bool) (*Output, hcl.Diagnostic
 		o.SensitiveSet = true
 	}
 
+	if attr, exists := content.Attributes["sensitive_attributes"]; exists {
+		exprs, listDiags := hcl.ExprList(attr.Expr)
+		diags = append(diags, listDiags...)
+		for _, expr := range exprs {
+			traversal, travDiags := hcl.RelTraversalForExpr(expr)
+			diags = append(diags, travDiags...)
+			if len(traversal) != 0 {
+				o.SensitiveAttributes = append(o.SensitiveAttributes, traversal)
+			}
+		}
+		o.SensitiveAttributesSet = true
+
+		if o.Sensitive {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Conflicting output value sensitivity",
+				Detail:   "The sensitive_attributes argument marks only some parts of the output value as sensitive, so it cannot be used when the whole output value is declared as sensitive.",
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+		}
+	}
+
+	if attr, exists := content.Attributes["ephemeral"]; exists {
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &o.Ephemeral)
+		diags = append(diags, valDiags...)
+		o.EphemeralSet = true
+	}
+
+	if attr, exists := content.Attributes["deprecated"]; exists {
+		var moreDiags hcl.Diagnostics
+		o.Deprecated, moreDiags = decodeDeprecatedMessage(attr)
+		diags = append(diags, moreDiags...)
+		o.DeprecatedSet = true
+	}
+
+	if attr, exists := content.Attributes["type"]; exists {
+		ty, tyDefaults, _, tyDiags := decodeVariableType(attr.Expr)
+		diags = append(diags, tyDiags...)
+		o.ConstraintType = ty
+		o.TypeDefaults = tyDefaults
+		o.Type = ty.WithoutOptionalAttributesDeep()
+	}
+
+	if attr, exists := content.Attributes["nullable"]; exists {
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &o.Nullable)
+		diags = append(diags, valDiags...)
+		o.NullableSet = true
+	} else {
+		o.Nullable = true
+	}
+
+	if attr, exists := content.Attributes["stable"]; exists {
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &o.Stable)
+		diags = append(diags, valDiags...)
+		o.StableSet = true
+	}
+
 	if attr, exists := content.Attributes["depends_on"]; exists {
-		deps, depsDiags := decodeDependsOn(attr)
+		deps, depsExpr, depsDiags := decodeDependsOnExpr(attr)
 		diags = append(diags, depsDiags...)
 		o.DependsOn = append(o.DependsOn, deps...)
+		o.DependsOnExpr = depsExpr
 	}
 
 	for _, block := range content.Blocks {
@@ -476,6 +605,26 @@ func decodeOutputBlock(block *hcl.Block, override bool) (*Output, hcl.Diagnostic
 	return o, diags
 }
 
+// decodeDeprecatedMessage decodes the "deprecated" argument of a variable or
+// output block, which must be a non-empty string.
+func decodeDeprecatedMessage(attr *hcl.Attribute) (string, hcl.Diagnostics) {
+	var msg string
+	diags := gohcl.DecodeExpression(attr.Expr, nil, &msg)
+	if diags.HasErrors() {
+		return "", diags
+	}
+	if strings.TrimSpace(msg) == "" {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid deprecation message",
+			Detail:   "The deprecated argument must be a message explaining what to use instead, so it cannot be empty.",
+			Subject:  attr.Expr.Range().Ptr(),
+		})
+		return "", diags
+	}
+	return msg, diags
+}
+
 func (o *Output) Addr() addrs.OutputValue {
 	return addrs.OutputValue{Name: o.Name}
 }
@@ -541,6 +690,12 @@ var variableBlockSchema = &hcl.BodySchema{
 		{
 			Name: "nullable",
 		},
+		{
+			Name: "ephemeral",
+		},
+		{
+			Name: "deprecated",
+		},
 	},
 	Blocks: []hcl.BlockHeaderSchema{
 		{
@@ -564,6 +719,24 @@ var outputBlockSchema = &hcl.BodySchema{
 		{
 			Name: "sensitive",
 		},
+		{
+			Name: "sensitive_attributes",
+		},
+		{
+			Name: "ephemeral",
+		},
+		{
+			Name: "deprecated",
+		},
+		{
+			Name: "type",
+		},
+		{
+			Name: "nullable",
+		},
+		{
+			Name: "stable",
+		},
 	},
 	Blocks: []hcl.BlockHeaderSchema{
 		{Type: "precondition"},
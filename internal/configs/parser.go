@@ -27,6 +27,10 @@ type Parser struct {
 	// for itself whether to enable it so that tests can cover both the
 	// allowed and not-allowed situations.
 	allowExperiments bool
+
+	// versionsFiles caches the versions files loaded by this parser, keyed
+	// by their paths.
+	versionsFiles map[string]*versionsFile
 }
 
 // NewParser creates and returns a new Parser that reads files from the given
@@ -55,7 +59,9 @@ func NewParser(fs afero.Fs) *Parser {
 // a more context-sensitive error instead.
 //
 // The file will be parsed using the HCL native syntax unless the filename
-// ends with ".json", in which case the HCL JSON syntax will be used.
+// ends with ".json", in which case the HCL JSON syntax will be used, or with
+// one of the YAML configuration extensions, in which case it is translated
+// from YAML into the HCL JSON syntax.
 func (p *Parser) LoadHCLFile(path string) (hcl.Body, hcl.Diagnostics) {
 	src, err := p.fs.ReadFile(path)
 
@@ -74,6 +80,16 @@ func (p *Parser) LoadHCLFile(path string) (hcl.Body, hcl.Diagnostics) {
 	switch {
 	case strings.HasSuffix(path, ".json"):
 		file, diags = p.p.ParseJSON(src, path)
+	case IsYAMLConfigFile(path):
+		var jsonSrc []byte
+		jsonSrc, diags = yamlToJSON(src, path)
+		if diags.HasErrors() {
+			// Retain the original source so that the diagnostics can
+			// include snippets of it.
+			p.ForceFileSource(path, src)
+			return hcl.EmptyBody(), diags
+		}
+		file, diags = p.p.ParseJSON(jsonSrc, path)
 	default:
 		file, diags = p.p.ParseHCL(src, path)
 	}
@@ -4,6 +4,8 @@
 package configs
 
 import (
+	"path/filepath"
+
 	"github.com/hashicorp/hcl/v2"
 )
 
@@ -66,6 +68,12 @@ func (p *Parser) loadConfigFile(path string, override bool) (*File, hcl.Diagnost
 	file.ActiveExperiments, expDiags = sniffActiveExperiments(body, p.allowExperiments)
 	diags = append(diags, expDiags...)
 
+	// The version constraints and source addresses of dependencies may
+	// refer to the constants in the versions file that applies to this
+	// module.
+	versionsCtx, versionsDiags := p.versionsEvalContext(filepath.Dir(path))
+	diags = append(diags, versionsDiags...)
+
 	content, contentDiags := body.Content(configFileSchema)
 	diags = append(diags, contentDiags...)
 
@@ -98,7 +106,7 @@ func (p *Parser) loadConfigFile(path string, override bool) (*File, hcl.Diagnost
 					}
 
 				case "required_providers":
-					reqs, reqsDiags := decodeRequiredProvidersBlock(innerBlock)
+					reqs, reqsDiags := decodeRequiredProvidersBlock(innerBlock, versionsCtx)
 					diags = append(diags, reqsDiags...)
 					file.RequiredProviders = append(file.RequiredProviders, reqs)
 
@@ -109,6 +117,20 @@ func (p *Parser) loadConfigFile(path string, override bool) (*File, hcl.Diagnost
 						file.ProviderMetas = append(file.ProviderMetas, providerCfg)
 					}
 
+				case "concurrency":
+					concurrencyCfg, cfgDiags := decodeConcurrencyBlock(innerBlock)
+					diags = append(diags, cfgDiags...)
+					if concurrencyCfg != nil {
+						file.Concurrency = append(file.Concurrency, concurrencyCfg)
+					}
+
+				case "hooks":
+					hooksCfg, cfgDiags := decodeHooksBlock(innerBlock)
+					diags = append(diags, cfgDiags...)
+					if hooksCfg != nil {
+						file.Hooks = append(file.Hooks, hooksCfg)
+					}
+
 				default:
 					// Should never happen because the above cases should be exhaustive
 					// for all block type names in our schema.
@@ -152,8 +174,15 @@ func (p *Parser) loadConfigFile(path string, override bool) (*File, hcl.Diagnost
 				file.Outputs = append(file.Outputs, cfg)
 			}
 
+		case "function":
+			cfg, cfgDiags := decodeFunctionBlock(block)
+			diags = append(diags, cfgDiags...)
+			if cfg != nil {
+				file.Functions = append(file.Functions, cfg)
+			}
+
 		case "module":
-			cfg, cfgDiags := decodeModuleBlock(block, override)
+			cfg, cfgDiags := decodeModuleBlock(block, override, versionsCtx)
 			diags = append(diags, cfgDiags...)
 			if cfg != nil {
 				file.ModuleCalls = append(file.ModuleCalls, cfg)
@@ -180,6 +209,13 @@ func (p *Parser) loadConfigFile(path string, override bool) (*File, hcl.Diagnost
 				file.Moved = append(file.Moved, cfg)
 			}
 
+		case "removed":
+			cfg, cfgDiags := decodeRemovedBlock(block)
+			diags = append(diags, cfgDiags...)
+			if cfg != nil {
+				file.Removed = append(file.Removed, cfg)
+			}
+
 		case "import":
 			cfg, cfgDiags := decodeImportBlock(block)
 			diags = append(diags, cfgDiags...)
@@ -230,7 +266,7 @@ func sniffCoreVersionRequirements(body hcl.Body) ([]VersionConstraint, hcl.Diagn
 			continue
 		}
 
-		constraint, constraintDiags := decodeVersionConstraint(attr)
+		constraint, constraintDiags := decodeVersionConstraint(attr, nil)
 		diags = append(diags, constraintDiags...)
 		if !constraintDiags.HasErrors() {
 			constraints = append(constraints, constraint)
@@ -269,6 +305,10 @@ var configFileSchema = &hcl.BodySchema{
 			Type:       "output",
 			LabelNames: []string{"name"},
 		},
+		{
+			Type:       "function",
+			LabelNames: []string{"name"},
+		},
 		{
 			Type:       "module",
 			LabelNames: []string{"name"},
@@ -284,6 +324,9 @@ var configFileSchema = &hcl.BodySchema{
 		{
 			Type: "moved",
 		},
+		{
+			Type: "removed",
+		},
 		{
 			Type: "import",
 		},
@@ -317,6 +360,12 @@ var terraformBlockSchema = &hcl.BodySchema{
 			Type:       "provider_meta",
 			LabelNames: []string{"provider"},
 		},
+		{
+			Type: "concurrency",
+		},
+		{
+			Type: "hooks",
+		},
 	},
 }
 
@@ -17,7 +17,7 @@ const (
 	DefaultTestDirectory = "tests"
 )
 
-// LoadConfigDir reads the .tf and .tf.json files in the given directory
+// LoadConfigDir reads the .tf, .tf.json and .tf.yaml files in the given directory
 // as config files (using LoadConfigFile) and then combines these files into
 // a single Module.
 //
@@ -35,7 +35,8 @@ const (
 // Parser.IsConfigDir if they wish to recognize that situation.
 //
 // .tf files are parsed using the HCL native syntax while .tf.json files are
-// parsed using the HCL JSON syntax.
+// parsed using the HCL JSON syntax. .tf.yaml and .tofu.yaml files are
+// translated from YAML into the HCL JSON syntax.
 func (p *Parser) LoadConfigDir(path string) (*Module, hcl.Diagnostics) {
 	primaryPaths, overridePaths, _, diags := p.dirFiles(path, "")
 	if diags.HasErrors() {
@@ -260,6 +261,10 @@ func fileExt(path string) string {
 		return ".tf"
 	} else if strings.HasSuffix(path, ".tf.json") {
 		return ".tf.json"
+	} else if strings.HasSuffix(path, ".tf.yaml") {
+		return ".tf.yaml"
+	} else if strings.HasSuffix(path, ".tofu.yaml") {
+		return ".tofu.yaml"
 	} else if strings.HasSuffix(path, ".tftest.hcl") {
 		return ".tftest.hcl"
 	} else if strings.HasSuffix(path, ".tftest.json") {
@@ -132,6 +132,16 @@ func TestParserLoadConfigFileFailureMessages(t *testing.T) {
 			hcl.DiagError,
 			"Unsuitable value type",
 		},
+		{
+			"invalid-files/concurrency-limit-zero.tf",
+			hcl.DiagError,
+			"Invalid concurrency limit",
+		},
+		{
+			"invalid-files/concurrency-rate-limit-negative.tf",
+			hcl.DiagError,
+			"Invalid rate limit",
+		},
 	}
 
 	for _, test := range tests {
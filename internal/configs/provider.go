@@ -25,6 +25,11 @@ type Provider struct {
 
 	Version VersionConstraint
 
+	// Inherit, if set, refers to another configuration for the same
+	// provider in the same module, whose arguments are used for any
+	// arguments that Config doesn't set.
+	Inherit *ProviderConfigRef
+
 	Config hcl.Body
 
 	DeclRange hcl.Range
@@ -76,6 +81,12 @@ func decodeProviderBlock(block *hcl.Block) (*Provider, hcl.Diagnostics) {
 		}
 	}
 
+	if attr, exists := content.Attributes["inherit"]; exists {
+		var refDiags hcl.Diagnostics
+		provider.Inherit, refDiags = decodeProviderConfigRef(attr.Expr, "inherit")
+		diags = append(diags, refDiags...)
+	}
+
 	if attr, exists := content.Attributes["version"]; exists {
 		diags = append(diags, &hcl.Diagnostic{
 			Severity: hcl.DiagWarning,
@@ -84,7 +95,7 @@ func decodeProviderBlock(block *hcl.Block) (*Provider, hcl.Diagnostics) {
 			Subject:  attr.Expr.Range().Ptr(),
 		})
 		var versionDiags hcl.Diagnostics
-		provider.Version, versionDiags = decodeVersionConstraint(attr)
+		provider.Version, versionDiags = decodeVersionConstraint(attr, nil)
 		diags = append(diags, versionDiags...)
 	}
 
@@ -240,6 +251,9 @@ var providerBlockSchema = &hcl.BodySchema{
 		{
 			Name: "version",
 		},
+		{
+			Name: "inherit",
+		},
 
 		// Attribute names reserved for future expansion.
 		{Name: "count"},
@@ -5,6 +5,7 @@ package configs
 
 import (
 	"fmt"
+	"strings"
 
 	version "github.com/hashicorp/go-version"
 	"github.com/hashicorp/hcl/v2"
@@ -25,12 +26,26 @@ type RequiredProvider struct {
 	Aliases     []addrs.LocalProviderConfig
 }
 
+// OCIProviderSourcePrefix is the prefix of a provider source address which
+// means that the provider must be installed from the repository with the
+// rest of the address in an OCI registry.
+const OCIProviderSourcePrefix = "oci://"
+
+// IsOCI returns true if the provider's source address says that it must be
+// installed from an OCI registry.
+func (rp *RequiredProvider) IsOCI() bool {
+	return strings.HasPrefix(rp.Source, OCIProviderSourcePrefix)
+}
+
 type RequiredProviders struct {
 	RequiredProviders map[string]*RequiredProvider
 	DeclRange         hcl.Range
 }
 
-func decodeRequiredProvidersBlock(block *hcl.Block) (*RequiredProviders, hcl.Diagnostics) {
+// decodeRequiredProvidersBlock decodes a required_providers block. The version
+// constraints and source addresses are evaluated in the given context, so
+// that they can refer to version constants.
+func decodeRequiredProvidersBlock(block *hcl.Block, versionsCtx *hcl.EvalContext) (*RequiredProviders, hcl.Diagnostics) {
 	attrs, diags := block.Body.JustAttributes()
 	if diags.HasErrors() {
 		return nil, diags
@@ -49,8 +64,8 @@ func decodeRequiredProvidersBlock(block *hcl.Block) (*RequiredProviders, hcl.Dia
 
 		// Look for a single static string, in case we have the legacy version-only
 		// format in the configuration.
-		if expr, err := attr.Expr.Value(nil); err == nil && expr.Type().IsPrimitiveType() {
-			vc, reqDiags := decodeVersionConstraint(attr)
+		if expr, err := attr.Expr.Value(versionsCtx); err == nil && expr.Type().IsPrimitiveType() {
+			vc, reqDiags := decodeVersionConstraint(attr, versionsCtx)
 			diags = append(diags, reqDiags...)
 
 			pType, err := addrs.ParseProviderPart(rp.Name)
@@ -113,8 +128,12 @@ func decodeRequiredProvidersBlock(block *hcl.Block) (*RequiredProviders, hcl.Dia
 					DeclRange: attr.Range,
 				}
 
-				constraint, valDiags := kv.Value.Value(nil)
-				if valDiags.HasErrors() || !constraint.Type().Equals(cty.String) {
+				constraint, valDiags := kv.Value.Value(versionsCtx)
+				if valDiags.HasErrors() {
+					diags = append(diags, valDiags...)
+					continue
+				}
+				if !constraint.Type().Equals(cty.String) {
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Invalid version constraint",
@@ -142,8 +161,12 @@ func decodeRequiredProvidersBlock(block *hcl.Block) (*RequiredProviders, hcl.Dia
 				rp.Requirement = vc
 
 			case "source":
-				source, err := kv.Value.Value(nil)
-				if err != nil || !source.Type().Equals(cty.String) {
+				source, valDiags := kv.Value.Value(versionsCtx)
+				if valDiags.HasErrors() {
+					diags = append(diags, valDiags...)
+					continue
+				}
+				if !source.Type().Equals(cty.String) {
 					diags = append(diags, &hcl.Diagnostic{
 						Severity: hcl.DiagError,
 						Summary:  "Invalid source",
@@ -153,7 +176,11 @@ func decodeRequiredProvidersBlock(block *hcl.Block) (*RequiredProviders, hcl.Dia
 					continue
 				}
 
-				fqn, sourceDiags := addrs.ParseProviderSourceString(source.AsString())
+				// A source with the oci:// prefix is the address of the
+				// provider's repository in an OCI registry, which is also
+				// its provider address once we remove the prefix.
+				rawSource, _ := strings.CutPrefix(source.AsString(), OCIProviderSourcePrefix)
+				fqn, sourceDiags := addrs.ParseProviderSourceString(rawSource)
 				if sourceDiags.HasErrors() {
 					hclDiags := sourceDiags.ToHCL()
 					// The diagnostics from ParseProviderSourceString don't contain
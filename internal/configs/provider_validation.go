@@ -293,17 +293,19 @@ func validateProviderConfigs(parentCall *ModuleCall, cfg *Config, noProviderConf
 	for name, child := range cfg.Children {
 		mc := mod.ModuleCalls[name]
 		childNoProviderConfigRange := noProviderConfigRange
-		// if the module call has any of count, for_each or depends_on,
-		// providers are prohibited from being configured in this module, or
-		// any module beneath this module.
+		// if the module call has any of count, for_each, enabled or
+		// depends_on, providers are prohibited from being configured in this
+		// module, or any module beneath this module.
 		switch {
 		case mc.Count != nil:
 			childNoProviderConfigRange = mc.Count.Range().Ptr()
 		case mc.ForEach != nil:
 			childNoProviderConfigRange = mc.ForEach.Range().Ptr()
-		case mc.DependsOn != nil:
-			if len(mc.DependsOn) > 0 {
-				childNoProviderConfigRange = mc.DependsOn[0].SourceRange().Ptr()
+		case mc.Enabled != nil:
+			childNoProviderConfigRange = mc.Enabled.Range().Ptr()
+		case mc.DependsOn != nil || mc.DependsOnExpr != nil:
+			if rng := dependsOnRange(mc.DependsOn, mc.DependsOnExpr); rng != nil {
+				childNoProviderConfigRange = rng
 			} else {
 				// Weird! We'll just use the call itself, then.
 				childNoProviderConfigRange = mc.DeclRange.Ptr()
@@ -336,7 +338,7 @@ func validateProviderConfigs(parentCall *ModuleCall, cfg *Config, noProviderConf
 		name := providerName(pc.Name, pc.Alias)
 		// Validate the config against an empty schema to see if it's empty.
 		_, pcConfigDiags := pc.Config.Content(&hcl.BodySchema{})
-		if pcConfigDiags.HasErrors() || pc.Version.Required != nil {
+		if pcConfigDiags.HasErrors() || pc.Version.Required != nil || pc.Inherit != nil {
 			configured[name] = pc.DeclRange
 		} else {
 			emptyConfigs[name] = pc.DeclRange
@@ -472,6 +474,70 @@ func validateProviderConfigs(parentCall *ModuleCall, cfg *Config, noProviderConf
 		}
 	}
 
+	// A provider configuration can inherit the arguments of another
+	// configuration for the same provider only if that configuration is
+	// available in this module, either because it's declared here or because
+	// it's passed in by the caller as a default or configuration alias.
+	for _, pc := range mod.ProviderConfigs {
+		if pc.Inherit == nil {
+			continue
+		}
+		name := providerName(pc.Name, pc.Alias)
+		base := pc.Inherit.String()
+
+		if pc.Inherit.Name != pc.Name {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid provider configuration inheritance",
+				Detail: fmt.Sprintf(
+					"The provider configuration %s can only inherit from another configuration for the same provider, but %s is a configuration for %q.",
+					name, base, pc.Inherit.Name,
+				),
+				Subject: pc.Inherit.NameRange.Ptr(),
+			})
+			continue
+		}
+		if base == name {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid provider configuration inheritance",
+				Detail:   fmt.Sprintf("The provider configuration %s cannot inherit from itself.", name),
+				Subject:  pc.Inherit.NameRange.Ptr(),
+			})
+			continue
+		}
+
+		if basePC, declared := mod.ProviderConfigs[base]; declared {
+			if basePC.Inherit != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid provider configuration inheritance",
+					Detail: fmt.Sprintf(
+						"The provider configuration %s inherits from %s, which itself inherits from %s. A provider configuration can only inherit from a configuration that doesn't inherit from another.",
+						name, base, basePC.Inherit,
+					),
+					Subject: pc.Inherit.NameRange.Ptr(),
+				})
+			}
+			continue
+		}
+		if _, isAlias := configAliases[base]; isAlias || pc.Inherit.Alias == "" {
+			// The caller must pass in configuration aliases, and a default
+			// configuration is always either passed in or inherited.
+			continue
+		}
+
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Reference to undefined provider",
+			Detail: fmt.Sprintf(
+				"The provider configuration %s inherits from %s, but there is no such configuration in %s.\n\nTo inherit from a configuration passed in by the calling module, add %s to the configuration_aliases of the required_providers entry for %q.",
+				name, base, moduleText, base, pc.Name,
+			),
+			Subject: pc.Inherit.NameRange.Ptr(),
+		})
+	}
+
 	// Verify that any module calls only refer to named providers, and that
 	// those providers will have a configuration at runtime. This way we can
 	// direct users where to add the missing configuration, because the runtime
@@ -543,7 +609,7 @@ func validateProviderConfigs(parentCall *ModuleCall, cfg *Config, noProviderConf
 			Severity: hcl.DiagError,
 			Summary:  "Module is incompatible with count, for_each, and depends_on",
 			Detail: fmt.Sprintf(
-				"The module at %s is a legacy module which contains its own local provider configurations, and so calls to it may not use the count, for_each, or depends_on arguments.\n\nIf you also control the module %q, consider updating this module to instead expect provider configurations to be passed by its caller.",
+				"The module at %s is a legacy module which contains its own local provider configurations, and so calls to it may not use the count, for_each, enabled, or depends_on arguments.\n\nIf you also control the module %q, consider updating this module to instead expect provider configurations to be passed by its caller.",
 				cfg.Path, cfg.SourceAddr,
 			),
 			Subject: noProviderConfigRange,
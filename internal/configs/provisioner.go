@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 )
 
 // Provisioner represents a "provisioner" block when used within a
@@ -152,49 +153,117 @@ func decodeProvisionerBlock(block *hcl.Block) (*Provisioner, hcl.Diagnostics) {
 func onlySelfRefs(body hcl.Body) hcl.Diagnostics {
 	var diags hcl.Diagnostics
 
-	// Provisioners currently do not use any blocks in their configuration.
-	// Blocks are likely to remain solely for meta parameters, but in the case
-	// that blocks are supported for provisioners, we will want to extend this
-	// to find variables in nested blocks.
 	attrs, _ := body.JustAttributes()
 	for _, attr := range attrs {
-		for _, v := range attr.Expr.Variables() {
-			valid := false
-			switch v.RootName() {
-			case "self", "path", "terraform":
-				valid = true
-			case "count":
-				// count must use "index"
-				if len(v) == 2 {
-					if t, ok := v[1].(hcl.TraverseAttr); ok && t.Name == "index" {
-						valid = true
-					}
+		diags = append(diags, onlySelfRefsInExpr(attr.Expr, nil)...)
+	}
+
+	// Provisioners and connections don't use any blocks of their own, but
+	// blocks can still appear in their configuration as "dynamic" blocks or
+	// the blocks those generate, so we look for references in nested blocks
+	// too. We can only find nested blocks in native syntax bodies, and we
+	// skip the block types that the provisioner block reserves for itself
+	// because those are checked separately.
+	if syntaxBody, ok := body.(*hclsyntax.Body); ok {
+		for _, block := range syntaxBody.Blocks {
+			if provisionerBlockSchemaHasBlock(block.Type) {
+				continue
+			}
+			diags = append(diags, onlySelfRefsInBlock(block, nil)...)
+		}
+	}
+	return diags
+}
+
+// onlySelfRefsInBlock is the nested block variant of onlySelfRefs, where the
+// given iterators are the names of the temporary variables of any enclosing
+// "dynamic" blocks.
+func onlySelfRefsInBlock(block *hclsyntax.Block, iterators []string) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	contentIterators := iterators
+	if block.Type == "dynamic" && len(block.Labels) == 1 {
+		iterator := block.Labels[0]
+		if attr, exists := block.Body.Attributes["iterator"]; exists {
+			iterator = hcl.ExprAsKeyword(attr.Expr)
+		}
+		contentIterators = append(append([]string(nil), iterators...), iterator)
+	}
+
+	for name, attr := range block.Body.Attributes {
+		if block.Type == "dynamic" && name == "iterator" {
+			// The iterator is a name rather than a reference.
+			continue
+		}
+		diags = append(diags, onlySelfRefsInExpr(attr.Expr, iterators)...)
+	}
+	for _, nested := range block.Body.Blocks {
+		if block.Type == "dynamic" && nested.Type == "content" {
+			diags = append(diags, onlySelfRefsInBlock(nested, contentIterators)...)
+			continue
+		}
+		diags = append(diags, onlySelfRefsInBlock(nested, iterators)...)
+	}
+	return diags
+}
+
+func onlySelfRefsInExpr(expr hcl.Expression, iterators []string) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	for _, v := range expr.Variables() {
+		valid := false
+		switch v.RootName() {
+		case "self", "path", "terraform":
+			valid = true
+		case "count":
+			// count must use "index"
+			if len(v) == 2 {
+				if t, ok := v[1].(hcl.TraverseAttr); ok && t.Name == "index" {
+					valid = true
 				}
+			}
 
-			case "each":
-				if len(v) == 2 {
-					if t, ok := v[1].(hcl.TraverseAttr); ok && t.Name == "key" {
-						valid = true
-					}
+		case "each":
+			if len(v) == 2 {
+				if t, ok := v[1].(hcl.TraverseAttr); ok && t.Name == "key" {
+					valid = true
 				}
 			}
 
-			if !valid {
-				diags = append(diags, &hcl.Diagnostic{
-					Severity: hcl.DiagError,
-					Summary:  "Invalid reference from destroy provisioner",
-					Detail: "Destroy-time provisioners and their connection configurations may only " +
-						"reference attributes of the related resource, via 'self', 'count.index', " +
-						"or 'each.key'.\n\nReferences to other resources during the destroy phase " +
-						"can cause dependency cycles and interact poorly with create_before_destroy.",
-					Subject: attr.Expr.Range().Ptr(),
-				})
+		default:
+			// The iterator of an enclosing dynamic block refers only to
+			// the elements of its for_each value, which we check separately.
+			for _, iterator := range iterators {
+				if v.RootName() == iterator {
+					valid = true
+				}
 			}
 		}
+
+		if !valid {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid reference from destroy provisioner",
+				Detail: "Destroy-time provisioners and their connection configurations may only " +
+					"reference attributes of the related resource, via 'self', 'count.index', " +
+					"or 'each.key'.\n\nReferences to other resources during the destroy phase " +
+					"can cause dependency cycles and interact poorly with create_before_destroy.",
+				Subject: expr.Range().Ptr(),
+			})
+		}
 	}
 	return diags
 }
 
+func provisionerBlockSchemaHasBlock(blockType string) bool {
+	for _, blockS := range provisionerBlockSchema.Blocks {
+		if blockS.Type == blockType {
+			return true
+		}
+	}
+	return false
+}
+
 // Connection represents a "connection" block when used within either a
 // "resource" or "provisioner" block in a module or file.
 type Connection struct {
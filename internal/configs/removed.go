@@ -0,0 +1,238 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+// Removed represents a "removed" block in the configuration, which records
+// that a resource or module call has been removed from the configuration and
+// describes what OpenTofu should do with the objects that remain in state.
+type Removed struct {
+	From *addrs.RemoveEndpoint
+
+	// Destroy is the value of the "destroy" argument in the block's lifecycle
+	// block, which defaults to true. Forgetting objects without destroying
+	// them isn't supported yet, so this is currently always true in a valid
+	// configuration.
+	Destroy bool
+
+	// Except lists resources and module calls inside a removed module whose
+	// objects must not be destroyed by this block. These are relative to the
+	// module containing the block, like From.
+	Except []*addrs.RemoveEndpoint
+
+	// Managed holds the destroy-time provisioners and connection block for
+	// the objects of a removed resource. This is nil if From refers to a
+	// module call.
+	Managed *ManagedResource
+
+	DeclRange hcl.Range
+}
+
+func decodeRemovedBlock(block *hcl.Block) (*Removed, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	removed := &Removed{
+		Destroy:   true,
+		DeclRange: block.DefRange,
+	}
+
+	content, moreDiags := block.Body.Content(removedBlockSchema)
+	diags = append(diags, moreDiags...)
+
+	if attr, exists := content.Attributes["from"]; exists {
+		from, traversalDiags := hcl.AbsTraversalForExpr(attr.Expr)
+		diags = append(diags, traversalDiags...)
+		if !traversalDiags.HasErrors() {
+			from, fromDiags := addrs.ParseRemoveEndpoint(from)
+			diags = append(diags, fromDiags.ToHCL()...)
+			removed.From = from
+		}
+	}
+
+	if attr, exists := content.Attributes["except"]; exists && removed.From != nil {
+		if _, isModule := removed.From.RelSubject.(addrs.Module); isModule {
+			removed.Except, moreDiags = decodeRemovedExceptions(removed.From, attr.Expr)
+			diags = append(diags, moreDiags...)
+		} else {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid removed exception",
+				Detail:   "The \"except\" argument is only allowed in removed blocks for module calls.",
+				Subject:  attr.Range.Ptr(),
+			})
+		}
+	}
+
+	var seenConnection *hcl.Block
+	for _, block := range content.Blocks {
+		switch block.Type {
+		case "lifecycle":
+			lcContent, lcDiags := block.Body.Content(removedLifecycleBlockSchema)
+			diags = append(diags, lcDiags...)
+
+			if attr, exists := lcContent.Attributes["destroy"]; exists {
+				valDiags := gohcl.DecodeExpression(attr.Expr, nil, &removed.Destroy)
+				diags = append(diags, valDiags...)
+				if !valDiags.HasErrors() && !removed.Destroy {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Unsupported removed lifecycle",
+						Detail:   "OpenTofu can't yet forget removed objects without destroying them. To stop managing an object without destroying it, remove this block and use the \"tofu state rm\" command instead.",
+						Subject:  attr.Expr.Range().Ptr(),
+					})
+				}
+			}
+
+		case "connection":
+			if seenConnection != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate connection block",
+					Detail:   fmt.Sprintf("This removed block already has a connection block at %s.", seenConnection.DefRange),
+					Subject:  &block.DefRange,
+				})
+				continue
+			}
+			seenConnection = block
+
+			// destroy provisioners can only refer to self
+			diags = append(diags, onlySelfRefs(block.Body)...)
+
+			if removed.Managed == nil {
+				removed.Managed = &ManagedResource{}
+			}
+			removed.Managed.Connection = &Connection{
+				Config:    block.Body,
+				DeclRange: block.DefRange,
+			}
+
+		case "provisioner":
+			pv, pvDiags := decodeProvisionerBlock(block)
+			diags = append(diags, pvDiags...)
+			if pv == nil {
+				continue
+			}
+			if pv.When != ProvisionerWhenDestroy {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid removed provisioner",
+					Detail:   "Provisioners in a removed block run only when OpenTofu destroys the removed objects, so they must be declared with when = destroy.",
+					Subject:  &block.DefRange,
+				})
+				continue
+			}
+
+			if removed.Managed == nil {
+				removed.Managed = &ManagedResource{}
+			}
+			removed.Managed.Provisioners = append(removed.Managed.Provisioners, pv)
+
+		default:
+			// Should never happen, because the above cases should be
+			// exhaustive for the block types in our schema.
+			continue
+		}
+	}
+
+	if removed.From != nil {
+		if _, isModule := removed.From.RelSubject.(addrs.Module); isModule && removed.Managed != nil {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid removed block",
+				Detail:   "Provisioners and connection blocks are only allowed in removed blocks for resources, not for module calls.",
+				Subject:  &removed.DeclRange,
+			})
+		}
+	}
+
+	return removed, diags
+}
+
+func decodeRemovedExceptions(from *addrs.RemoveEndpoint, expr hcl.Expression) ([]*addrs.RemoveEndpoint, hcl.Diagnostics) {
+	var ret []*addrs.RemoveEndpoint
+	exprs, diags := hcl.ExprList(expr)
+	for _, expr := range exprs {
+		traversal, traversalDiags := hcl.AbsTraversalForExpr(expr)
+		diags = append(diags, traversalDiags...)
+		if traversalDiags.HasErrors() {
+			continue
+		}
+		except, exceptDiags := addrs.ParseRemoveEndpoint(traversal)
+		diags = append(diags, exceptDiags.ToHCL()...)
+		if exceptDiags.HasErrors() {
+			continue
+		}
+
+		if !removedEndpointContains(from, except) {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid removed exception",
+				Detail:   fmt.Sprintf("The \"except\" argument can only list resources and module calls inside the removed module %s.", from),
+				Subject:  expr.Range().Ptr(),
+			})
+			continue
+		}
+		ret = append(ret, except)
+	}
+	return ret, diags
+}
+
+// removedEndpointContains returns true if the given exception refers to an
+// object inside the removed module that the given endpoint refers to.
+func removedEndpointContains(from, except *addrs.RemoveEndpoint) bool {
+	fromModule, ok := from.RelSubject.(addrs.Module)
+	if !ok {
+		return false
+	}
+
+	var exceptModule addrs.Module
+	switch except := except.RelSubject.(type) {
+	case addrs.ConfigResource:
+		exceptModule = except.Module
+	case addrs.Module:
+		// An exception for a module call must be a descendent of the
+		// removed module, not the removed module itself.
+		if len(except) <= len(fromModule) {
+			return false
+		}
+		exceptModule = except
+	}
+
+	if len(exceptModule) < len(fromModule) {
+		return false
+	}
+	return exceptModule[:len(fromModule)].Equal(fromModule)
+}
+
+var removedBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name:     "from",
+			Required: true,
+		},
+		{
+			Name: "except",
+		},
+	},
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "lifecycle"},
+		{Type: "connection"},
+		{Type: "provisioner", LabelNames: []string{"type"}},
+	},
+}
+
+var removedLifecycleBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{
+			Name: "destroy",
+		},
+	},
+}
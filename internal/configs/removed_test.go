@@ -0,0 +1,95 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRemovedBlock_decode(t *testing.T) {
+	parser := testParser(map[string]string{
+		"removed.tf": `
+removed {
+  from = test.foo
+
+  provisioner "local-exec" {
+    when    = destroy
+    command = "echo ${self.id}"
+  }
+}
+
+removed {
+  from   = module.a
+  except = [module.a.test.bar, module.a.module.b]
+
+  lifecycle {
+    destroy = true
+  }
+}
+`,
+	})
+	file, diags := parser.LoadConfigFile("removed.tf")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected error: %s", diags.Error())
+	}
+
+	type removed struct {
+		From         string
+		Except       []string
+		Destroy      bool
+		Provisioners int
+	}
+	var got []removed
+	for _, r := range file.Removed {
+		gotR := removed{
+			From:    r.From.String(),
+			Destroy: r.Destroy,
+		}
+		for _, except := range r.Except {
+			gotR.Except = append(gotR.Except, except.String())
+		}
+		if r.Managed != nil {
+			gotR.Provisioners = len(r.Managed.Provisioners)
+		}
+		got = append(got, gotR)
+	}
+	want := []removed{
+		{
+			From:         "test.foo",
+			Destroy:      true,
+			Provisioners: 1,
+		},
+		{
+			From:    "module.a",
+			Except:  []string{"module.a.test.bar", "module.a.module.b"},
+			Destroy: true,
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
+
+func TestRemovedBlock_duplicate(t *testing.T) {
+	parser := testParser(map[string]string{
+		"removed.tf": `
+removed {
+  from = test.foo
+}
+
+removed {
+  from = test.foo
+}
+`,
+	})
+	_, diags := parser.LoadConfigDir(".")
+	if !diags.HasErrors() {
+		t.Fatal("expected error")
+	}
+	if got, want := diags[0].Summary, "Duplicate removed block"; got != want {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
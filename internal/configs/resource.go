@@ -5,6 +5,8 @@ package configs
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
@@ -33,8 +35,21 @@ type Resource struct {
 
 	DependsOn []hcl.Traversal
 
+	// DependsOnExpr is the whole depends_on expression when it is not a
+	// static list of references, in which case DependsOn holds the
+	// references it could possibly depend on. It is nil otherwise.
+	DependsOnExpr hcl.Expression
+
+	// TriggersReplacement are the replace_triggered_by expressions that
+	// refer to another resource, whose changes trigger replacement.
 	TriggersReplacement []hcl.Expression
 
+	// TriggersReplacementValues are the other replace_triggered_by
+	// expressions, such as function calls or references to variables, which
+	// trigger replacement when their values differ from those recorded in
+	// state for the resource instance.
+	TriggersReplacementValues []hcl.Expression
+
 	// Managed is populated only for Mode = addrs.ManagedResourceMode,
 	// containing the additional fields that apply to managed resources.
 	// For all other resource modes, this field is nil.
@@ -61,8 +76,86 @@ type ManagedResource struct {
 	IgnoreChanges       []hcl.Traversal
 	IgnoreAllChanges    bool
 
+	// IgnoreChangesWhen are the attributes whose changes are ignored only
+	// while a condition, evaluated for each instance of the resource, is
+	// true.
+	IgnoreChangesWhen []*IgnoreChangesWhen
+
 	CreateBeforeDestroySet bool
 	PreventDestroySet      bool
+
+	// CreateBeforeDestroyGroup, if set, is the name of a group of resources
+	// in the same module whose replacement objects are all created before
+	// any of the objects they replace are destroyed. Resources in a group
+	// are always replaced using create_before_destroy.
+	CreateBeforeDestroyGroup      string
+	CreateBeforeDestroyGroupRange hcl.Range
+
+	// DestroyAfter are the managed resources and module calls whose objects
+	// OpenTofu must destroy before destroying the objects of this resource,
+	// in addition to the order implied by the dependencies between them.
+	DestroyAfter []*addrs.Reference
+
+	// Retry, if set, allows OpenTofu to retry the changes to the resource's
+	// objects that fail with transient provider errors during apply.
+	Retry *ResourceRetry
+
+	// OperationTimeout, if non-zero, is how long OpenTofu waits for the
+	// provider to create, update or delete one of the resource's objects
+	// during apply, including any retries, before failing the change.
+	OperationTimeout time.Duration
+}
+
+// ResourceRetry represents a "retry" block within a managed resource's
+// lifecycle block, which controls how OpenTofu retries a change that the
+// provider fails to apply.
+type ResourceRetry struct {
+	// Attempts is the maximum number of times to apply a change, including
+	// the first attempt.
+	Attempts int
+
+	// Backoff is the time to wait after the first failed attempt. The wait
+	// doubles after each further failed attempt.
+	Backoff time.Duration
+
+	// OnErrors are the substrings of the provider's error messages that
+	// make a failure retryable. If it's empty, any failure is retryable.
+	OnErrors []string
+
+	DeclRange hcl.Range
+}
+
+// Delay returns the time to wait after the given number of failed attempts,
+// counting from one.
+func (r *ResourceRetry) Delay(failures int) time.Duration {
+	return r.Backoff << (failures - 1)
+}
+
+// Retryable returns true if all of the errors in the given diagnostics
+// match the retry policy.
+func (r *ResourceRetry) Retryable(diags tfdiags.Diagnostics) bool {
+	if len(r.OnErrors) == 0 {
+		return diags.HasErrors()
+	}
+	retryable := false
+	for _, diag := range diags {
+		if diag.Severity() != tfdiags.Error {
+			continue
+		}
+		desc := diag.Description()
+		matched := false
+		for _, s := range r.OnErrors {
+			if strings.Contains(desc.Summary, s) || strings.Contains(desc.Detail, s) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+		retryable = true
+	}
+	return retryable
 }
 
 func (r *Resource) moduleUniqueKey() string {
@@ -161,9 +254,10 @@ func decodeResourceBlock(block *hcl.Block, override bool) (*Resource, hcl.Diagno
 	}
 
 	if attr, exists := content.Attributes["depends_on"]; exists {
-		deps, depsDiags := decodeDependsOn(attr)
+		deps, depsExpr, depsDiags := decodeDependsOnExpr(attr)
 		diags = append(diags, depsDiags...)
 		r.DependsOn = append(r.DependsOn, deps...)
+		r.DependsOnExpr = depsExpr
 	}
 
 	var seenLifecycle *hcl.Block
@@ -192,6 +286,31 @@ func decodeResourceBlock(block *hcl.Block, override bool) (*Resource, hcl.Diagno
 				r.Managed.CreateBeforeDestroySet = true
 			}
 
+			if attr, exists := lcContent.Attributes["create_before_destroy_group"]; exists {
+				valDiags := gohcl.DecodeExpression(attr.Expr, nil, &r.Managed.CreateBeforeDestroyGroup)
+				diags = append(diags, valDiags...)
+				r.Managed.CreateBeforeDestroyGroupRange = attr.Expr.Range()
+				switch {
+				case valDiags.HasErrors():
+				case !hclsyntax.ValidIdentifier(r.Managed.CreateBeforeDestroyGroup):
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid create_before_destroy_group",
+						Detail:   "The group name must be a valid identifier, starting with a letter and containing only letters, digits, underscores and dashes.",
+						Subject:  attr.Expr.Range().Ptr(),
+					})
+				case r.Managed.CreateBeforeDestroySet && !r.Managed.CreateBeforeDestroy:
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid create_before_destroy_group",
+						Detail:   "A resource in a create_before_destroy group is always replaced using create_before_destroy, so it cannot set create_before_destroy to false.",
+						Subject:  attr.Expr.Range().Ptr(),
+					})
+				default:
+					r.Managed.CreateBeforeDestroy = true
+				}
+			}
+
 			if attr, exists := lcContent.Attributes["prevent_destroy"]; exists {
 				valDiags := gohcl.DecodeExpression(attr.Expr, nil, &r.Managed.PreventDestroy)
 				diags = append(diags, valDiags...)
@@ -199,10 +318,36 @@ func decodeResourceBlock(block *hcl.Block, override bool) (*Resource, hcl.Diagno
 			}
 
 			if attr, exists := lcContent.Attributes["replace_triggered_by"]; exists {
-				exprs, hclDiags := decodeReplaceTriggeredBy(attr.Expr)
+				refExprs, valueExprs, hclDiags := decodeReplaceTriggeredBy(attr.Expr)
 				diags = diags.Extend(hclDiags)
 
-				r.TriggersReplacement = append(r.TriggersReplacement, exprs...)
+				r.TriggersReplacement = append(r.TriggersReplacement, refExprs...)
+				r.TriggersReplacementValues = append(r.TriggersReplacementValues, valueExprs...)
+			}
+
+			if attr, exists := lcContent.Attributes["destroy_after"]; exists {
+				refs, hclDiags := decodeDestroyAfter(attr)
+				diags = append(diags, hclDiags...)
+				r.Managed.DestroyAfter = refs
+			}
+
+			if attr, exists := lcContent.Attributes["operation_timeout"]; exists {
+				var raw string
+				valDiags := gohcl.DecodeExpression(attr.Expr, nil, &raw)
+				diags = append(diags, valDiags...)
+				if !valDiags.HasErrors() {
+					d, err := time.ParseDuration(raw)
+					if err != nil || d <= 0 {
+						diags = append(diags, &hcl.Diagnostic{
+							Severity: hcl.DiagError,
+							Summary:  "Invalid operation_timeout",
+							Detail:   "The operation timeout must be a positive duration string, such as \"20m\" or \"1h30m\".",
+							Subject:  attr.Expr.Range().Ptr(),
+						})
+					} else {
+						r.Managed.OperationTimeout = d
+					}
+				}
 			}
 
 			if attr, exists := lcContent.Attributes["ignore_changes"]; exists {
@@ -244,10 +389,7 @@ func decodeResourceBlock(block *hcl.Block, override bool) (*Resource, hcl.Diagno
 							continue
 						}
 
-						expr, shimDiags := shimTraversalInString(expr, false)
-						diags = append(diags, shimDiags...)
-
-						traversal, travDiags := hcl.RelTraversalForExpr(expr)
+						traversal, travDiags := decodeIgnoreChangesTraversal(expr)
 						diags = append(diags, travDiags...)
 						if len(traversal) != 0 {
 							r.Managed.IgnoreChanges = append(r.Managed.IgnoreChanges, traversal)
@@ -282,6 +424,39 @@ func decodeResourceBlock(block *hcl.Block, override bool) (*Resource, hcl.Diagno
 					case "postcondition":
 						r.Postconditions = append(r.Postconditions, cr)
 					}
+				case "dynamic":
+					cr, blockType, moreDiags := decodeDynamicCheckRuleBlock(block, override)
+					diags = append(diags, moreDiags...)
+					if cr == nil {
+						continue
+					}
+
+					moreDiags = cr.validateSelfReferences(blockType, r.Addr())
+					diags = append(diags, moreDiags...)
+
+					switch blockType {
+					case "precondition":
+						r.Preconditions = append(r.Preconditions, cr)
+					case "postcondition":
+						r.Postconditions = append(r.Postconditions, cr)
+					}
+				case "retry":
+					if r.Managed.Retry != nil {
+						diags = append(diags, &hcl.Diagnostic{
+							Severity: hcl.DiagError,
+							Summary:  "Duplicate retry block",
+							Detail:   fmt.Sprintf("This resource already has a retry block at %s.", r.Managed.Retry.DeclRange),
+							Subject:  &block.DefRange,
+						})
+						continue
+					}
+					retry, moreDiags := decodeResourceRetryBlock(block)
+					diags = append(diags, moreDiags...)
+					r.Managed.Retry = retry
+				case "ignore_changes_when":
+					icw, moreDiags := decodeIgnoreChangesWhenBlock(block)
+					diags = append(diags, moreDiags...)
+					r.Managed.IgnoreChangesWhen = append(r.Managed.IgnoreChangesWhen, icw)
 				default:
 					// The cases above should be exhaustive for all block types
 					// defined in the lifecycle schema, so this shouldn't happen.
@@ -430,9 +605,10 @@ func decodeDataBlock(block *hcl.Block, override, nested bool) (*Resource, hcl.Di
 	}
 
 	if attr, exists := content.Attributes["depends_on"]; exists {
-		deps, depsDiags := decodeDependsOn(attr)
+		deps, depsExpr, depsDiags := decodeDependsOnExpr(attr)
 		diags = append(diags, depsDiags...)
 		r.DependsOn = append(r.DependsOn, deps...)
+		r.DependsOnExpr = depsExpr
 	}
 
 	var seenEscapeBlock *hcl.Block
@@ -513,6 +689,29 @@ func decodeDataBlock(block *hcl.Block, override, nested bool) (*Resource, hcl.Di
 					case "postcondition":
 						r.Postconditions = append(r.Postconditions, cr)
 					}
+				case "dynamic":
+					cr, blockType, moreDiags := decodeDynamicCheckRuleBlock(block, override)
+					diags = append(diags, moreDiags...)
+					if cr == nil {
+						continue
+					}
+
+					moreDiags = cr.validateSelfReferences(blockType, r.Addr())
+					diags = append(diags, moreDiags...)
+
+					switch blockType {
+					case "precondition":
+						r.Preconditions = append(r.Preconditions, cr)
+					case "postcondition":
+						r.Postconditions = append(r.Postconditions, cr)
+					}
+				case "retry", "ignore_changes_when":
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid data resource lifecycle block",
+						Detail:   fmt.Sprintf("The lifecycle block type %q is defined only for managed resources (\"resource\" blocks), and is not valid for data resources.", block.Type),
+						Subject:  block.DefRange.Ptr(),
+					})
 				default:
 					// The cases above should be exhaustive for all block types
 					// defined in the lifecycle schema, so this shouldn't happen.
@@ -535,10 +734,71 @@ func decodeDataBlock(block *hcl.Block, override, nested bool) (*Resource, hcl.Di
 	return r, diags
 }
 
+func decodeResourceRetryBlock(block *hcl.Block) (*ResourceRetry, hcl.Diagnostics) {
+	retry := &ResourceRetry{
+		Attempts:  1,
+		Backoff:   10 * time.Second,
+		DeclRange: block.DefRange,
+	}
+
+	content, diags := block.Body.Content(resourceRetryBlockSchema)
+
+	if attr, exists := content.Attributes["attempts"]; exists {
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &retry.Attempts)
+		diags = append(diags, valDiags...)
+		if !valDiags.HasErrors() && retry.Attempts < 1 {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid retry attempts",
+				Detail:   "The number of attempts must be at least 1.",
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+		}
+	}
+
+	if attr, exists := content.Attributes["backoff"]; exists {
+		var raw string
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &raw)
+		diags = append(diags, valDiags...)
+		if !valDiags.HasErrors() {
+			d, err := time.ParseDuration(raw)
+			if err != nil || d < 0 {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid retry backoff",
+					Detail:   "The \"backoff\" argument must be a non-negative duration string, such as \"10s\" or \"1m30s\".",
+					Subject:  attr.Expr.Range().Ptr(),
+				})
+			} else {
+				retry.Backoff = d
+			}
+		}
+	}
+
+	if attr, exists := content.Attributes["on_errors"]; exists {
+		valDiags := gohcl.DecodeExpression(attr.Expr, nil, &retry.OnErrors)
+		diags = append(diags, valDiags...)
+		for _, s := range retry.OnErrors {
+			if s == "" {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid retry on_errors",
+					Detail:   "The \"on_errors\" argument must not contain empty strings, which would match any error. Omit the argument to retry any error.",
+					Subject:  attr.Expr.Range().Ptr(),
+				})
+				break
+			}
+		}
+	}
+
+	return retry, diags
+}
+
 // decodeReplaceTriggeredBy decodes and does basic validation of the
-// replace_triggered_by expressions, ensuring they only contains references to
-// a single resource, and the only extra variables are count.index or each.key.
-func decodeReplaceTriggeredBy(expr hcl.Expression) ([]hcl.Expression, hcl.Diagnostics) {
+// replace_triggered_by expressions, separating them into references to a
+// single resource, whose only extra variables can be count.index or each.key,
+// and arbitrary expressions whose values are tracked in state.
+func decodeReplaceTriggeredBy(expr hcl.Expression) (refExprs, valueExprs []hcl.Expression, diags hcl.Diagnostics) {
 	// Since we are manually parsing the replace_triggered_by argument, we
 	// need to specially handle json configs, in which case the values will
 	// be json strings rather than hcl. To simplify parsing however we will
@@ -547,7 +807,7 @@ func decodeReplaceTriggeredBy(expr hcl.Expression) ([]hcl.Expression, hcl.Diagno
 
 	exprs, diags := hcl.ExprList(expr)
 
-	for i, expr := range exprs {
+	for _, expr := range exprs {
 		if isJSON {
 			// We can abuse the hcl json api and rely on the fact that calling
 			// Value on a json expression with no EvalContext will return the
@@ -564,8 +824,6 @@ func decodeReplaceTriggeredBy(expr hcl.Expression) ([]hcl.Expression, hcl.Diagno
 			if diags.HasErrors() {
 				continue
 			}
-			// make sure to swap out the expression we're returning too
-			exprs[i] = expr
 		}
 
 		refs, refDiags := lang.ReferencesInExpr(addrs.ParseRef, expr)
@@ -591,10 +849,34 @@ func decodeReplaceTriggeredBy(expr hcl.Expression) ([]hcl.Expression, hcl.Diagno
 
 		resourceCount := 0
 		for _, ref := range refs {
-			switch sub := ref.Subject.(type) {
+			switch ref.Subject.(type) {
 			case addrs.Resource, addrs.ResourceInstance:
 				resourceCount++
+			}
+		}
 
+		// Any expression other than a plain reference to a resource, such
+		// as a function call or a reference to a variable, is evaluated
+		// and its value compared with the one recorded in state.
+		if resourceCount == 0 || !isReplaceTriggerTraversal(expr) {
+			for _, ref := range refs {
+				if ref.Subject == addrs.Self {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Invalid self reference in replace_triggered_by expression",
+						Detail:   "A resource cannot be replaced because of changes to its own attributes.",
+						Subject:  ref.SourceRange.ToHCL().Ptr(),
+					})
+				}
+			}
+			valueExprs = append(valueExprs, expr)
+			continue
+		}
+
+		for _, ref := range refs {
+			switch sub := ref.Subject.(type) {
+			case addrs.Resource, addrs.ResourceInstance:
+				// the resource whose changes trigger replacement
 			case addrs.ForEachAttr:
 				if sub.Name != "key" {
 					diags = append(diags, &hcl.Diagnostic{
@@ -618,21 +900,13 @@ func decodeReplaceTriggeredBy(expr hcl.Expression) ([]hcl.Expression, hcl.Diagno
 				diags = append(diags, &hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Invalid reference in replace_triggered_by expression",
-					Detail:   "Only resources, count.index, and each.key may be used in replace_triggered_by.",
+					Detail:   "Only resources, count.index, and each.key may be used in a resource reference in replace_triggered_by.",
 					Subject:  expr.Range().Ptr(),
 				})
 			}
 		}
 
-		switch {
-		case resourceCount == 0:
-			diags = append(diags, &hcl.Diagnostic{
-				Severity: hcl.DiagError,
-				Summary:  "Invalid replace_triggered_by expression",
-				Detail:   "Missing resource reference in replace_triggered_by expression.",
-				Subject:  expr.Range().Ptr(),
-			})
-		case resourceCount > 1:
+		if resourceCount > 1 {
 			diags = append(diags, &hcl.Diagnostic{
 				Severity: hcl.DiagError,
 				Summary:  "Invalid replace_triggered_by expression",
@@ -640,8 +914,25 @@ func decodeReplaceTriggeredBy(expr hcl.Expression) ([]hcl.Expression, hcl.Diagno
 				Subject:  expr.Range().Ptr(),
 			})
 		}
+		refExprs = append(refExprs, expr)
+	}
+	return refExprs, valueExprs, diags
+}
+
+// isReplaceTriggerTraversal returns true if the given expression has the
+// form of a reference in replace_triggered_by, which is a traversal that may
+// include index expressions, rather than an arbitrary expression.
+func isReplaceTriggerTraversal(expr hcl.Expression) bool {
+	switch e := expr.(type) {
+	case *hclsyntax.ScopeTraversalExpr:
+		return true
+	case *hclsyntax.RelativeTraversalExpr:
+		return isReplaceTriggerTraversal(e.Source)
+	case *hclsyntax.IndexExpr:
+		return isReplaceTriggerTraversal(e.Collection)
+	default:
+		return false
 	}
-	return exprs, diags
 }
 
 type ProviderConfigRef struct {
@@ -802,6 +1093,9 @@ var resourceLifecycleBlockSchema = &hcl.BodySchema{
 		{
 			Name: "create_before_destroy",
 		},
+		{
+			Name: "create_before_destroy_group",
+		},
 		{
 			Name: "prevent_destroy",
 		},
@@ -811,9 +1105,26 @@ var resourceLifecycleBlockSchema = &hcl.BodySchema{
 		{
 			Name: "replace_triggered_by",
 		},
+		{
+			Name: "destroy_after",
+		},
+		{
+			Name: "operation_timeout",
+		},
 	},
 	Blocks: []hcl.BlockHeaderSchema{
 		{Type: "precondition"},
 		{Type: "postcondition"},
+		{Type: "dynamic", LabelNames: []string{"type"}},
+		{Type: "retry"},
+		{Type: "ignore_changes_when"},
+	},
+}
+
+var resourceRetryBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "attempts", Required: true},
+		{Name: "backoff"},
+		{Name: "on_errors"},
 	},
 }
@@ -0,0 +1,379 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+func TestResourceLifecycle_retry(t *testing.T) {
+	parser := testParser(map[string]string{
+		"main.tf": `
+resource "test_object" "default" {
+}
+
+resource "test_object" "retried" {
+  lifecycle {
+    retry {
+      attempts  = 4
+      backoff   = "30s"
+      on_errors = ["Throttling", "Conflict"]
+    }
+  }
+}
+`,
+	})
+	file, diags := parser.LoadConfigFile("main.tf")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected error: %s", diags.Error())
+	}
+
+	if retry := file.ManagedResources[0].Managed.Retry; retry != nil {
+		t.Errorf("unexpected retry: %#v", retry)
+	}
+
+	retry := file.ManagedResources[1].Managed.Retry
+	if retry == nil {
+		t.Fatal("missing retry")
+	}
+	if got, want := retry.Attempts, 4; got != want {
+		t.Errorf("wrong attempts %d; want %d", got, want)
+	}
+	if diff := cmp.Diff([]string{"Throttling", "Conflict"}, retry.OnErrors); diff != "" {
+		t.Errorf("wrong on_errors\n%s", diff)
+	}
+
+	var gotDelays []time.Duration
+	for failures := 1; failures < retry.Attempts; failures++ {
+		gotDelays = append(gotDelays, retry.Delay(failures))
+	}
+	wantDelays := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute}
+	if diff := cmp.Diff(wantDelays, gotDelays); diff != "" {
+		t.Errorf("wrong delays\n%s", diff)
+	}
+
+	var throttled, mixed tfdiags.Diagnostics
+	throttled = throttled.Append(tfdiags.Sourceless(tfdiags.Error, "API error", "ThrottlingException: Rate exceeded"))
+	mixed = mixed.Append(throttled)
+	mixed = mixed.Append(tfdiags.Sourceless(tfdiags.Error, "Invalid name", "The name is too long."))
+	if !retry.Retryable(throttled) {
+		t.Error("throttling error is not retryable")
+	}
+	if retry.Retryable(mixed) {
+		t.Error("unmatched error is retryable")
+	}
+}
+
+func TestResourceLifecycle_retryInvalid(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		want string
+	}{
+		"attempts": {
+			`
+resource "test_object" "a" {
+  lifecycle {
+    retry {
+      attempts = 0
+    }
+  }
+}
+`,
+			"Invalid retry attempts",
+		},
+		"backoff": {
+			`
+resource "test_object" "a" {
+  lifecycle {
+    retry {
+      attempts = 3
+      backoff  = "soon"
+    }
+  }
+}
+`,
+			"Invalid retry backoff",
+		},
+		"duplicate": {
+			`
+resource "test_object" "a" {
+  lifecycle {
+    retry {
+      attempts = 3
+    }
+    retry {
+      attempts = 5
+    }
+  }
+}
+`,
+			"Duplicate retry block",
+		},
+		"data resource": {
+			`
+data "test_object" "a" {
+  lifecycle {
+    retry {
+      attempts = 3
+    }
+  }
+}
+`,
+			"Invalid data resource lifecycle block",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			parser := testParser(map[string]string{
+				"main.tf": test.src,
+			})
+			_, diags := parser.LoadConfigFile("main.tf")
+			if !diags.HasErrors() {
+				t.Fatal("expected error")
+			}
+			if got := diags[0].Summary; got != test.want {
+				t.Errorf("wrong error\ngot:  %s\nwant: %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestResourceLifecycle_destroyAfter(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		want string
+	}{
+		"valid": {
+			`
+resource "test_object" "a" {
+  lifecycle {
+    destroy_after = [test_object.b, test_object.c[0], module.child]
+  }
+}
+
+resource "test_object" "b" {
+}
+
+resource "test_object" "c" {
+  count = 1
+}
+
+module "child" {
+  source = "./child"
+}
+`,
+			"",
+		},
+		"data resource": {
+			`
+resource "test_object" "a" {
+  lifecycle {
+    destroy_after = [data.test_object.b]
+  }
+}
+
+data "test_object" "b" {
+}
+`,
+			"Invalid destroy_after reference",
+		},
+		"attribute": {
+			`
+resource "test_object" "a" {
+  lifecycle {
+    destroy_after = [test_object.b.id]
+  }
+}
+
+resource "test_object" "b" {
+}
+`,
+			"Invalid destroy_after reference",
+		},
+		"undeclared": {
+			`
+resource "test_object" "a" {
+  lifecycle {
+    destroy_after = [module.missing]
+  }
+}
+`,
+			"Reference to undeclared object in destroy_after",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			parser := testParser(map[string]string{
+				"main.tf": test.src,
+			})
+			_, diags := parser.LoadConfigDir(".")
+			if test.want == "" {
+				if diags.HasErrors() {
+					t.Fatalf("unexpected error: %s", diags.Error())
+				}
+				return
+			}
+			if !diags.HasErrors() {
+				t.Fatal("expected error")
+			}
+			if got := diags[0].Summary; got != test.want {
+				t.Errorf("wrong error\ngot:  %s\nwant: %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestResourceLifecycle_createBeforeDestroyGroup(t *testing.T) {
+	parser := testParser(map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  lifecycle {
+    create_before_destroy_group = "cluster"
+  }
+}
+
+resource "test_object" "b" {
+  lifecycle {
+    create_before_destroy       = false
+    create_before_destroy_group = "cluster"
+  }
+}
+
+resource "test_object" "c" {
+  lifecycle {
+    create_before_destroy_group = "not a name"
+  }
+}
+`,
+	})
+	file, diags := parser.LoadConfigFile("main.tf")
+
+	a := file.ManagedResources[0].Managed
+	if got, want := a.CreateBeforeDestroyGroup, "cluster"; got != want {
+		t.Errorf("wrong group %q; want %q", got, want)
+	}
+	if !a.CreateBeforeDestroy {
+		t.Error("create_before_destroy is not implied by the group")
+	}
+
+	var got []string
+	for _, diag := range diags {
+		got = append(got, diag.Summary)
+	}
+	want := []string{"Invalid create_before_destroy_group", "Invalid create_before_destroy_group"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong diagnostics\n%s", diff)
+	}
+}
+
+func TestResourceLifecycle_operationTimeout(t *testing.T) {
+	parser := testParser(map[string]string{
+		"main.tf": `
+resource "test_object" "default" {
+}
+
+resource "test_object" "limited" {
+  lifecycle {
+    operation_timeout = "20m"
+  }
+}
+
+resource "test_object" "zero" {
+  lifecycle {
+    operation_timeout = "0s"
+  }
+}
+
+data "test_object" "a" {
+  lifecycle {
+    operation_timeout = "20m"
+  }
+}
+`,
+	})
+	file, diags := parser.LoadConfigFile("main.tf")
+	assertExactDiagnostics(t, diags, []string{
+		`main.tf:13,25-29: Invalid operation_timeout; The operation timeout must be a positive duration string, such as "20m" or "1h30m".`,
+		`main.tf:19,5-22: Invalid data resource lifecycle argument; The lifecycle argument "operation_timeout" is defined only for managed resources ("resource" blocks), and is not valid for data resources.`,
+	})
+
+	if got := file.ManagedResources[0].Managed.OperationTimeout; got != 0 {
+		t.Errorf("unexpected operation timeout %s", got)
+	}
+	if got, want := file.ManagedResources[1].Managed.OperationTimeout, 20*time.Minute; got != want {
+		t.Errorf("wrong operation timeout %s; want %s", got, want)
+	}
+}
+
+func TestResourceLifecycle_ignoreChangesWildcards(t *testing.T) {
+	parser := testParser(map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  lifecycle {
+    ignore_changes = [tags["kubernetes.io/*"], rules[*].description, labels.*]
+
+    ignore_changes_when {
+      condition  = var.frozen
+      attributes = [name]
+    }
+  }
+}
+
+data "test_object" "a" {
+  lifecycle {
+    ignore_changes_when {
+      condition  = true
+      attributes = [name]
+    }
+  }
+}
+`,
+	})
+	file, diags := parser.LoadConfigFile("main.tf")
+	assertExactDiagnostics(t, diags, []string{
+		`main.tf:15,5-24: Invalid data resource lifecycle block; The lifecycle block type "ignore_changes_when" is defined only for managed resources ("resource" blocks), and is not valid for data resources.`,
+	})
+
+	managed := file.ManagedResources[0].Managed
+	var got []string
+	for _, traversal := range managed.IgnoreChanges {
+		var steps []string
+		for _, step := range traversal {
+			switch step := step.(type) {
+			case hcl.TraverseAttr:
+				steps = append(steps, "."+step.Name)
+			case hcl.TraverseIndex:
+				steps = append(steps, fmt.Sprintf("[%q]", step.Key.AsString()))
+			case hcl.TraverseSplat:
+				steps = append(steps, "[*]")
+			}
+		}
+		got = append(got, strings.Join(steps, ""))
+	}
+	want := []string{`.tags["kubernetes.io/*"]`, `.rules[*].description`, `.labels[*]`}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong ignore_changes\n%s", diff)
+	}
+
+	if got, want := len(managed.IgnoreChangesWhen), 1; got != want {
+		t.Fatalf("wrong number of ignore_changes_when blocks %d; want %d", got, want)
+	}
+	icw := managed.IgnoreChangesWhen[0]
+	if got, want := len(icw.Attributes), 1; got != want {
+		t.Fatalf("wrong number of attributes %d; want %d", got, want)
+	}
+	if got, want := icw.Attributes[0][0].(hcl.TraverseAttr).Name, "name"; got != want {
+		t.Errorf("wrong attribute %q; want %q", got, want)
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/getmodules"
@@ -119,6 +120,11 @@ type TestRun struct {
 	// run.
 	ExpectFailures []hcl.Traversal
 
+	// MockModules lists the module calls within the config under test that
+	// should return the declared outputs instead of being executed for this
+	// run block.
+	MockModules []*TestMockModule
+
 	NameDeclRange      hcl.Range
 	VariablesDeclRange hcl.Range
 	DeclRange          hcl.Range
@@ -170,6 +176,27 @@ type TestRunModuleCall struct {
 	SourceDeclRange hcl.Range
 }
 
+// TestMockModule replaces a module call with a mock for a given run block.
+//
+// A mocked module keeps the input variables of the real module, so the
+// arguments of the module call are still validated, but it has no resources,
+// nested module calls or provider configurations. Its output values are
+// those declared by the mock instead.
+type TestMockModule struct {
+	// Target is the module call to mock, relative to the config under test.
+	// All instances of the module call are mocked.
+	Target addrs.Module
+
+	// Outputs are the expressions for the output values of the mocked module,
+	// keyed by output name. They are evaluated in the scope of the mocked
+	// module, so they can refer to its input variables. Output values of the
+	// real module that are not declared here are null.
+	Outputs map[string]hcl.Expression
+
+	TargetRange hcl.Range
+	DeclRange   hcl.Range
+}
+
 // TestRunOptions contains the plan options for a given run block.
 type TestRunOptions struct {
 	// Mode is the planning mode to run in. One of ['normal', 'refresh-only'].
@@ -305,6 +332,27 @@ func decodeTestRunBlock(block *hcl.Block) (*TestRun, hcl.Diagnostics) {
 			if !moduleDiags.HasErrors() {
 				r.Module = module
 			}
+		case "mock_module":
+			mock, mockDiags := decodeTestMockModuleBlock(block)
+			diags = append(diags, mockDiags...)
+			if mockDiags.HasErrors() {
+				continue
+			}
+			for _, other := range r.MockModules {
+				if other.Target.Equal(mock.Target) {
+					diags = append(diags, &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  "Duplicate \"mock_module\" block",
+						Detail:   fmt.Sprintf("This run block already mocks %s at %s.", mock.Target, other.DeclRange),
+						Subject:  mock.TargetRange.Ptr(),
+					})
+					mock = nil
+					break
+				}
+			}
+			if mock != nil {
+				r.MockModules = append(r.MockModules, mock)
+			}
 		}
 	}
 
@@ -370,7 +418,7 @@ func decodeTestRunModuleBlock(block *hcl.Block) (*TestRunModuleCall, hcl.Diagnos
 	haveVersionArg := false
 	if attr, exists := content.Attributes["version"]; exists {
 		var versionDiags hcl.Diagnostics
-		module.Version, versionDiags = decodeVersionConstraint(attr)
+		module.Version, versionDiags = decodeVersionConstraint(attr, nil)
 		diags = append(diags, versionDiags...)
 		haveVersionArg = true
 	}
@@ -461,6 +509,87 @@ func decodeTestRunModuleBlock(block *hcl.Block) (*TestRunModuleCall, hcl.Diagnos
 	return &module, diags
 }
 
+func decodeTestMockModuleBlock(block *hcl.Block) (*TestMockModule, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	content, contentDiags := block.Body.Content(testMockModuleBlockSchema)
+	diags = append(diags, contentDiags...)
+	if contentDiags.HasErrors() {
+		return nil, diags
+	}
+
+	mock := TestMockModule{
+		Outputs:   make(map[string]hcl.Expression),
+		DeclRange: block.DefRange,
+	}
+
+	attr := content.Attributes["target"]
+	mock.TargetRange = attr.Expr.Range()
+	traversal, travDiags := hcl.AbsTraversalForExpr(attr.Expr)
+	diags = append(diags, travDiags...)
+	if travDiags.HasErrors() {
+		return nil, diags
+	}
+	target, targetDiags := addrs.ParseModuleInstance(traversal)
+	if targetDiags.HasErrors() || len(target) == 0 {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid \"mock_module\" target",
+			Detail:   "The target of a mock_module block must be the address of a module call, such as module.network.",
+			Subject:  mock.TargetRange.Ptr(),
+		})
+		return nil, diags
+	}
+	for _, step := range target {
+		if step.InstanceKey != addrs.NoKey {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid \"mock_module\" target",
+				Detail:   fmt.Sprintf("The target of a mock_module block must be a module call, not a module instance. All instances of %s are mocked.", target.Module()),
+				Subject:  mock.TargetRange.Ptr(),
+			})
+			return nil, diags
+		}
+	}
+	mock.Target = target.Module()
+
+	if attr, exists := content.Attributes["outputs"]; exists {
+		pairs, pairsDiags := hcl.ExprMap(attr.Expr)
+		if pairsDiags.HasErrors() {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid \"outputs\" argument",
+				Detail:   "The outputs argument of a mock_module block must be an object constructor, with an attribute for each output value to declare.",
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+			return nil, diags
+		}
+		for _, pair := range pairs {
+			name := hcl.ExprAsKeyword(pair.Key)
+			if name == "" {
+				var nameDiags hcl.Diagnostics
+				nameDiags = gohcl.DecodeExpression(pair.Key, nil, &name)
+				diags = append(diags, nameDiags...)
+				if nameDiags.HasErrors() {
+					continue
+				}
+			}
+			if !hclsyntax.ValidIdentifier(name) {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid output name",
+					Detail:   badIdentifierDetail,
+					Subject:  pair.Key.Range().Ptr(),
+				})
+				continue
+			}
+			mock.Outputs[name] = pair.Value
+		}
+	}
+
+	return &mock, diags
+}
+
 func decodeTestRunOptionsBlock(block *hcl.Block) (*TestRunOptions, hcl.Diagnostics) {
 	var diags hcl.Diagnostics
 
@@ -556,6 +685,9 @@ var testRunBlockSchema = &hcl.BodySchema{
 		{
 			Type: "module",
 		},
+		{
+			Type: "mock_module",
+		},
 	},
 }
 
@@ -574,3 +706,10 @@ var testRunModuleBlockSchema = &hcl.BodySchema{
 		{Name: "version"},
 	},
 }
+
+var testMockModuleBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "target", Required: true},
+		{Name: "outputs"},
+	},
+}
@@ -1,6 +1,8 @@
 package configs
 
 import (
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -92,3 +94,97 @@ func parseTraversal(t *testing.T, addr string) hcl.Traversal {
 	}
 	return traversal
 }
+
+func TestDecodeTestMockModuleBlock(t *testing.T) {
+	tcs := map[string]struct {
+		src         string
+		wantTarget  string
+		wantOutputs []string
+		wantErr     string
+	}{
+		"module call": {
+			src: `
+mock_module {
+  target  = module.network
+  outputs = {
+    vpc_id      = "vpc-1234"
+    "subnet_id" = "subnet-1234"
+  }
+}`,
+			wantTarget:  "module.network",
+			wantOutputs: []string{"subnet_id", "vpc_id"},
+		},
+		"nested module call": {
+			src: `
+mock_module {
+  target = module.network.module.subnets
+}`,
+			wantTarget: "module.network.module.subnets",
+		},
+		"module instance": {
+			src: `
+mock_module {
+  target = module.network[0]
+}`,
+			wantErr: "must be a module call, not a module instance",
+		},
+		"resource": {
+			src: `
+mock_module {
+  target = test_resource.foo
+}`,
+			wantErr: "must be the address of a module call",
+		},
+		"outputs not an object": {
+			src: `
+mock_module {
+  target  = module.network
+  outputs = var.outputs
+}`,
+			wantErr: "must be an object constructor",
+		},
+		"missing target": {
+			src: `
+mock_module {
+  outputs = {}
+}`,
+			wantErr: `The argument "target" is required`,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			file, diags := hclsyntax.ParseConfig([]byte(tc.src), "test.tftest.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			content, diags := file.Body.Content(testRunBlockSchema)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			mock, diags := decodeTestMockModuleBlock(content.Blocks[0])
+			if tc.wantErr != "" {
+				if !diags.HasErrors() || !strings.Contains(diags.Error(), tc.wantErr) {
+					t.Fatalf("wrong diagnostics %v; want an error containing %q", diags, tc.wantErr)
+				}
+				return
+			}
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			if got := mock.Target.String(); got != tc.wantTarget {
+				t.Errorf("wrong target %q; want %q", got, tc.wantTarget)
+			}
+			var outputs []string
+			for name := range mock.Outputs {
+				outputs = append(outputs, name)
+			}
+			sort.Strings(outputs)
+			if diff := cmp.Diff(tc.wantOutputs, outputs); diff != "" {
+				t.Errorf("wrong outputs:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+provider "foo" {
+  alias = "primary"
+}
+
+module "mod" {
+  source = "./mod"
+  providers = {
+    foo.primary = foo.primary
+  }
+}
@@ -0,0 +1,28 @@
+terraform {
+  required_providers {
+    foo = {
+      source                = "hashicorp/foo"
+      configuration_aliases = [foo.primary]
+    }
+  }
+}
+
+provider "foo" {
+  alias   = "west"
+  inherit = foo.primary
+}
+
+provider "foo" {
+  alias   = "east"
+  inherit = foo.west
+}
+
+provider "foo" {
+  alias   = "north"
+  inherit = foo.missing
+}
+
+provider "foo" {
+  alias   = "south"
+  inherit = foo.south
+}
@@ -0,0 +1,8 @@
+variable "empty" {
+  deprecated = "" # ERROR: Invalid deprecation message
+}
+
+output "empty" {
+  value      = "old"
+  deprecated = " " # ERROR: Invalid deprecation message
+}
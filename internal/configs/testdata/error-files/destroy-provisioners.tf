@@ -42,3 +42,25 @@ resource "null_resource" "b" {
     command = "echo ${local.name}" # ERROR: Invalid reference from destroy provisioner
   }
 }
+
+resource "null_resource" "c" {
+  provisioner "remote-exec" {
+    when = destroy
+
+    dynamic "step" {
+      for_each = self.steps
+      iterator = s
+      content {
+        name = s.value
+        user = local.user # ERROR: Invalid reference from destroy provisioner
+      }
+    }
+
+    dynamic "target" {
+      for_each = local.targets # ERROR: Invalid reference from destroy provisioner
+      content {
+        name = target.key
+      }
+    }
+  }
+}
@@ -0,0 +1,25 @@
+function "upper" { # ERROR: Invalid function name
+  parameter "s" {
+    type = string
+  }
+
+  result = s
+}
+
+function "uses_variable" {
+  parameter "s" {
+    type = string
+  }
+
+  result = "${s}-${var.suffix}" # ERROR: Invalid reference in function result
+}
+
+function "duplicate_parameter" {
+  parameter "s" {
+  }
+
+  parameter "s" { # ERROR: Duplicate function parameter
+  }
+
+  result = s
+}
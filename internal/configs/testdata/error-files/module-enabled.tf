@@ -0,0 +1,36 @@
+module "counted" {
+  source = "./foo"
+  count  = 1
+
+  lifecycle {
+    enabled = true # ERROR: Invalid combination of "enabled" and "count" or "for_each"
+  }
+}
+
+module "each" {
+  source   = "./foo"
+  for_each = toset(["a"])
+
+  lifecycle {
+    enabled = true # ERROR: Invalid combination of "enabled" and "count" or "for_each"
+  }
+}
+
+module "twice" {
+  source = "./foo"
+
+  lifecycle {
+    enabled = true
+  }
+  lifecycle { # ERROR: Duplicate lifecycle block
+    enabled = false
+  }
+}
+
+module "unsupported" {
+  source = "./foo"
+
+  lifecycle {
+    create_before_destroy = true # ERROR: Unsupported argument
+  }
+}
@@ -0,0 +1,11 @@
+moved {
+  from_module_source = "hashicorp/legacy/aws"
+  from               = aws_instance.web
+  to                 = aws_instance.web
+}
+
+moved { # ERROR: Invalid "moved" addresses
+  from_module_source = "hashicorp/legacy/aws"
+  from               = module.web
+  to                 = module.web
+}
@@ -0,0 +1,51 @@
+removed {
+  from = aws_instance.web[0] # ERROR: Invalid address
+}
+
+removed {
+  from = data.aws_ami.ubuntu # ERROR: Invalid address
+}
+
+removed {
+  from = aws_instance.db
+
+  lifecycle {
+    destroy = false # ERROR: Unsupported removed lifecycle
+  }
+}
+
+removed {
+  from = aws_instance.app
+
+  provisioner "local-exec" { # ERROR: Invalid removed provisioner
+    command = "echo created"
+  }
+}
+
+removed {
+  from   = aws_instance.cache
+  except = [aws_instance.cache] # ERROR: Invalid removed exception
+}
+
+removed {
+  from   = module.legacy
+  except = [module.other.aws_s3_bucket.logs] # ERROR: Invalid removed exception
+}
+
+removed {
+  from = aws_instance.queue
+
+  provisioner "local-exec" {
+    when    = destroy
+    command = "echo ${local.name}" # ERROR: Invalid reference from destroy provisioner
+  }
+}
+
+removed { # ERROR: Invalid removed block
+  from = module.network
+
+  provisioner "local-exec" {
+    when    = destroy
+    command = "echo ${self.id}"
+  }
+}
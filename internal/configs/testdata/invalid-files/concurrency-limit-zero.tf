@@ -0,0 +1,8 @@
+
+terraform {
+  concurrency {
+    resource_types = {
+      aws_instance = 0
+    }
+  }
+}
@@ -0,0 +1,8 @@
+
+terraform {
+  concurrency {
+    provider_rate_limits = {
+      aws = -1
+    }
+  }
+}
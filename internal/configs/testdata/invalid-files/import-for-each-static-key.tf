@@ -0,0 +1,5 @@
+import {
+  for_each = { web = "i-abc123" }
+  to       = aws_instance.example["web"] # must use each.key or each.value
+  id       = each.value
+}
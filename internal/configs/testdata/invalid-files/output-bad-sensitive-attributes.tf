@@ -0,0 +1,8 @@
+output "credentials" {
+  value = {
+    user     = "admin"
+    password = "hunter2"
+  }
+  sensitive            = true
+  sensitive_attributes = [password] # cannot be combined with sensitive = true
+}
@@ -0,0 +1,4 @@
+output "id" {
+  value = "abc"
+  type  = "string" # type constraints must not be quoted
+}
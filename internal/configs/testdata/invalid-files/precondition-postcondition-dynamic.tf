@@ -0,0 +1,18 @@
+resource "example" "example" {
+  lifecycle {
+    dynamic "precondition" {
+      for_each = example.example.items # ERROR: Invalid reference in precondition
+      content {
+        condition     = precondition.value != ""
+        error_message = "Must not be empty."
+      }
+    }
+    dynamic "postcondition" { # ERROR: Missing content block
+      for_each = var.items
+    }
+    dynamic "ignore_changes" { # ERROR: Unsupported dynamic block type
+      for_each = var.items
+      content {}
+    }
+  }
+}
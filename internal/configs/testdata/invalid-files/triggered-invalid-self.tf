@@ -0,0 +1,6 @@
+resource "test_resource" "a" {
+  lifecycle {
+    // cannot be replaced because of its own attributes
+    replace_triggered_by = [ sha256(self.id) ]
+  }
+}
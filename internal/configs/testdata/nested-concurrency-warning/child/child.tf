@@ -0,0 +1,9 @@
+terraform {
+  # Only the root module can declare concurrency limits. OpenTofu should emit
+  # a warning about this child module's concurrency block.
+  concurrency {
+    resource_types = {
+      null_resource = 1
+    }
+  }
+}
@@ -0,0 +1,20 @@
+
+terraform {
+  required_providers {
+    aws = {
+      source = "hashicorp/aws"
+    }
+  }
+
+  concurrency {
+    providers = {
+      aws = 4
+    }
+    resource_types = {
+      aws_instance = 2
+    }
+    provider_rate_limits = {
+      aws = 2.5
+    }
+  }
+}
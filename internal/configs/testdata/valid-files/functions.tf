@@ -0,0 +1,20 @@
+function "name_prefix" {
+  description = "Returns the prefix for the names of a tenant's resources."
+
+  parameter "tenant" {
+    type = string
+  }
+
+  parameter "environment" {
+  }
+
+  result = "${lower(tenant)}-${environment}"
+}
+
+function "standard_tags" {
+  parameter "tags" {
+    type = map(string)
+  }
+
+  result = merge({ ManagedBy = "OpenTofu" }, { for k, v in tags : k => v if v != "" })
+}
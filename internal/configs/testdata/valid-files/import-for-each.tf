@@ -0,0 +1,16 @@
+locals {
+  instance_ids = {
+    web = "i-abc123"
+    db  = "i-def456"
+  }
+}
+
+resource "aws_instance" "example" {
+  for_each = local.instance_ids
+}
+
+import {
+  for_each = local.instance_ids
+  to       = aws_instance.example[each.key]
+  id       = each.value
+}
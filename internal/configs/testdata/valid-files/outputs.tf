@@ -23,3 +23,33 @@ output "π" {
     pizza.cheese,
   ]
 }
+
+output "deprecated" {
+  value      = "old"
+  deprecated = "Use the \"foo\" output value instead."
+}
+
+output "ephemeral" {
+  value     = "temporary"
+  ephemeral = true
+}
+
+output "sensitive_attributes" {
+  value = {
+    user     = "admin"
+    password = "hunter2"
+  }
+  sensitive_attributes = [password]
+}
+
+output "contract" {
+  value = {
+    name = "example"
+  }
+  type = object({
+    name = string
+    tags = optional(map(string), {})
+  })
+  nullable = false
+  stable   = true
+}
@@ -11,6 +11,26 @@ resource "test" "test" {
   }
 }
 
+resource "test" "dynamic" {
+  lifecycle {
+    dynamic "precondition" {
+      for_each = var.names
+      content {
+        condition     = precondition.value != ""
+        error_message = "Must not be empty."
+      }
+    }
+    dynamic "postcondition" {
+      for_each = var.names
+      iterator = name
+      content {
+        condition     = contains(self.names, name.value)
+        error_message = "Must include ${name.value}."
+      }
+    }
+  }
+}
+
 data "test" "test" {
   lifecycle {
     precondition {
@@ -0,0 +1,21 @@
+removed {
+  from = aws_instance.web
+
+  lifecycle {
+    destroy = true
+  }
+
+  connection {
+    host = self.public_ip
+  }
+
+  provisioner "local-exec" {
+    when    = destroy
+    command = "echo ${self.id} >> decommissioned.txt"
+  }
+}
+
+removed {
+  from   = module.legacy
+  except = [module.legacy.aws_s3_bucket.logs, module.legacy.module.network]
+}
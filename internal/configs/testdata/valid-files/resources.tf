@@ -47,3 +47,9 @@ resource "aws_instance" "depends" {
     replace_triggered_by = [ aws_instance.web[1], aws_security_group.firewall.id ]
   }
 }
+
+resource "aws_instance" "triggered_by_values" {
+  lifecycle {
+    replace_triggered_by = [ filesha256("app.zip"), var.release ]
+  }
+}
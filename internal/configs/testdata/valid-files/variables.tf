@@ -42,3 +42,15 @@ variable "nullable_default_null" {
   nullable = true
   default = null
 }
+
+variable "deprecated" {
+  type       = string
+  default    = null
+  deprecated = "Use the \"nullable\" variable instead."
+}
+
+variable "ephemeral" {
+  type      = string
+  default   = null
+  ephemeral = true
+}
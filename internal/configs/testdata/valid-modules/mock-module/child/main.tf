@@ -0,0 +1,15 @@
+variable "name" {
+  type = string
+}
+
+resource "test_resource" "foo" {
+  value = var.name
+}
+
+module "grandchild" {
+  source = "./grandchild"
+}
+
+output "id" {
+  value = test_resource.foo.id
+}
@@ -0,0 +1,7 @@
+variable "enabled" {
+  type = string
+}
+
+output "enabled" {
+  value = var.enabled
+}
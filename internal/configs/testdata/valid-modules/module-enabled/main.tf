@@ -0,0 +1,16 @@
+variable "enable_child" {
+  type    = bool
+  default = true
+}
+
+module "child" {
+  source = "./child"
+
+  # An input variable can still be named "enabled", because the meta-argument
+  # is in the lifecycle block.
+  enabled = "passed to the child module"
+
+  lifecycle {
+    enabled = var.enable_child
+  }
+}
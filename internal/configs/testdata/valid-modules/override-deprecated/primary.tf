@@ -0,0 +1,10 @@
+variable "deprecated_in_override" {
+}
+
+variable "deprecated_in_primary" {
+  deprecated = "Use something else."
+}
+
+output "deprecated_in_override" {
+  value = "old"
+}
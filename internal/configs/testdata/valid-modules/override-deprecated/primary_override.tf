@@ -0,0 +1,11 @@
+variable "deprecated_in_override" {
+  deprecated = "Use something else instead."
+}
+
+variable "deprecated_in_primary" {
+  description = "Still deprecated."
+}
+
+output "deprecated_in_override" {
+  deprecated = "Use a different output instead."
+}
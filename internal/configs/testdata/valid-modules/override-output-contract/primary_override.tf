@@ -0,0 +1,5 @@
+output "id" {
+  type     = string
+  nullable = false
+  stable   = true
+}
@@ -0,0 +1,5 @@
+variable "fully_overridden" {
+  default = "base"
+  description = "base description"
+  type = string
+}
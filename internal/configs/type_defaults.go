@@ -0,0 +1,185 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"strconv"
+
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// ApplyTypeDefaults applies the default values from a type constraint to the
+// given value, which must not be null, before it is converted to the type.
+//
+// Defaults apply at any depth, including to each element of a collection.
+// In addition to filling in missing optional attributes, when an attribute
+// of object type has a default and the value gives only some of the
+// attributes of that object, the others are taken from the default. So an
+// attribute of the object takes its value from, in order of precedence:
+//
+//   - the given value
+//   - the default for that attribute in its own optional() modifier
+//   - the corresponding attribute of the default for the enclosing object
+//
+// Values of map and collection types are never merged with their defaults:
+// a given map or list replaces the default entirely.
+func ApplyTypeDefaults(defaults *typeexpr.Defaults, val cty.Value) cty.Value {
+	if defaults == nil {
+		return val
+	}
+	return mergeTypeDefaults(defaults, defaults.Apply(val))
+}
+
+// mergeTypeDefaults merges the object defaults described by the given
+// defaults into the corresponding parts of the given value, to which the
+// defaults have already been applied.
+func mergeTypeDefaults(d *typeexpr.Defaults, val cty.Value) cty.Value {
+	if d == nil || !val.IsKnown() || val.IsNull() {
+		return val
+	}
+
+	val, marks := val.Unmark()
+	ty := val.Type()
+
+	switch {
+	case d.Type.IsObjectType() && (ty.IsObjectType() || ty.IsMapType()):
+		attrs := val.AsValueMap()
+		if attrs == nil {
+			attrs = make(map[string]cty.Value)
+		}
+		for name, attr := range attrs {
+			attrs[name] = mergeTypeDefaults(d.Children[name], attr)
+		}
+		for name, defaultVal := range d.DefaultValues {
+			if attr, exists := attrs[name]; exists && d.Type.HasAttribute(name) {
+				attrs[name] = mergeObjectDefault(d.Type.AttributeType(name), attr, defaultVal)
+			}
+		}
+		val = cty.ObjectVal(attrs)
+
+	case d.Type.IsMapType() && (ty.IsObjectType() || ty.IsMapType()):
+		if val.LengthInt() == 0 {
+			break
+		}
+		elems := val.AsValueMap()
+		for key, elem := range elems {
+			elems[key] = mergeTypeDefaults(d.Children[""], elem)
+		}
+		val = rebuildMapValue(ty, elems)
+
+	case d.Type.IsListType() || d.Type.IsSetType() || d.Type.IsTupleType():
+		if !(ty.IsListType() || ty.IsSetType() || ty.IsTupleType()) || val.LengthInt() == 0 {
+			break
+		}
+		elems := val.AsValueSlice()
+		for i, elem := range elems {
+			child := d.Children[""]
+			if d.Type.IsTupleType() {
+				child = d.Children[strconv.Itoa(i)]
+			}
+			elems[i] = mergeTypeDefaults(child, elem)
+		}
+		val = rebuildSequenceValue(ty, elems)
+	}
+
+	return val.WithMarks(marks)
+}
+
+// mergeObjectDefault fills in the attributes of the given object type that
+// are missing or null in the given value from the given default value,
+// recursively for attributes that are themselves objects.
+func mergeObjectDefault(ty cty.Type, val, defaultVal cty.Value) cty.Value {
+	if !ty.IsObjectType() || !val.IsKnown() || val.IsNull() || !defaultVal.IsKnown() || defaultVal.IsNull() {
+		return val
+	}
+	val, marks := val.Unmark()
+	if !isObjectLike(val.Type()) || !isObjectLike(defaultVal.Type()) {
+		return val.WithMarks(marks)
+	}
+
+	attrs := val.AsValueMap()
+	if attrs == nil {
+		attrs = make(map[string]cty.Value)
+	}
+	defaultAttrs := defaultVal.AsValueMap()
+	for name, attrTy := range ty.AttributeTypes() {
+		defaultAttr, ok := defaultAttrs[name]
+		if !ok {
+			continue
+		}
+		if attr, exists := attrs[name]; exists && !attr.IsNull() {
+			attrs[name] = mergeObjectDefault(attrTy, attr, defaultAttr)
+			continue
+		}
+		attrs[name] = defaultAttr
+	}
+	return cty.ObjectVal(attrs).WithMarks(marks)
+}
+
+func isObjectLike(ty cty.Type) bool {
+	return ty.IsObjectType() || ty.IsMapType()
+}
+
+// rebuildMapValue returns a value of the same kind as the given type with the
+// given elements, or an object if the elements no longer have a common type.
+func rebuildMapValue(ty cty.Type, elems map[string]cty.Value) cty.Value {
+	if ty.IsMapType() {
+		if unified, ok := unifyValues(elems); ok {
+			return cty.MapVal(unified)
+		}
+	}
+	return cty.ObjectVal(elems)
+}
+
+// rebuildSequenceValue returns a value of the same kind as the given type
+// with the given elements, or a tuple if the elements no longer have a
+// common type.
+func rebuildSequenceValue(ty cty.Type, elems []cty.Value) cty.Value {
+	if ty.IsListType() || ty.IsSetType() {
+		byIndex := make(map[string]cty.Value, len(elems))
+		for i, elem := range elems {
+			byIndex[strconv.Itoa(i)] = elem
+		}
+		if unified, ok := unifyValues(byIndex); ok {
+			converted := make([]cty.Value, len(elems))
+			for i := range elems {
+				converted[i] = unified[strconv.Itoa(i)]
+			}
+			if ty.IsSetType() {
+				return cty.SetVal(converted)
+			}
+			return cty.ListVal(converted)
+		}
+	}
+	return cty.TupleVal(elems)
+}
+
+// unifyValues converts the given values to a common type, if there is one.
+func unifyValues(vals map[string]cty.Value) (map[string]cty.Value, bool) {
+	keys := make([]string, 0, len(vals))
+	types := make([]cty.Type, 0, len(vals))
+	for key, val := range vals {
+		keys = append(keys, key)
+		types = append(types, val.Type())
+	}
+	unified, conversions := convert.UnifyUnsafe(types)
+	if unified == cty.NilType {
+		return nil, false
+	}
+	ret := make(map[string]cty.Value, len(vals))
+	for i, key := range keys {
+		if conversions[i] == nil {
+			ret[key] = vals[key]
+			continue
+		}
+		converted, err := conversions[i](vals[key])
+		if err != nil {
+			return nil, false
+		}
+		ret[key] = converted
+	}
+	return ret, true
+}
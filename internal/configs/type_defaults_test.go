@@ -0,0 +1,169 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+func TestApplyTypeDefaults(t *testing.T) {
+	tests := map[string]struct {
+		Type  string
+		Value string
+		Want  cty.Value
+	}{
+		"nested optional default": {
+			`object({
+				a = optional(string, "default")
+			})`,
+			`{}`,
+			cty.ObjectVal(map[string]cty.Value{
+				"a": cty.StringVal("default"),
+			}),
+		},
+		"partial object merged with default": {
+			`object({
+				settings = optional(object({
+					size = string
+					tier = string
+				}), { size = "small", tier = "free" })
+			})`,
+			`{ settings = { size = "large" } }`,
+			cty.ObjectVal(map[string]cty.Value{
+				"settings": cty.ObjectVal(map[string]cty.Value{
+					"size": cty.StringVal("large"),
+					"tier": cty.StringVal("free"),
+				}),
+			}),
+		},
+		"null attribute taken from default": {
+			`object({
+				settings = optional(object({
+					size = string
+					tier = optional(string)
+				}), { size = "small", tier = "free" })
+			})`,
+			`{ settings = { size = "large", tier = null } }`,
+			cty.ObjectVal(map[string]cty.Value{
+				"settings": cty.ObjectVal(map[string]cty.Value{
+					"size": cty.StringVal("large"),
+					"tier": cty.StringVal("free"),
+				}),
+			}),
+		},
+		"own default takes precedence over enclosing default": {
+			`object({
+				settings = optional(object({
+					size = optional(string, "medium")
+					tier = string
+				}), { size = "small", tier = "free" })
+			})`,
+			`{ settings = { tier = "paid" } }`,
+			cty.ObjectVal(map[string]cty.Value{
+				"settings": cty.ObjectVal(map[string]cty.Value{
+					"size": cty.StringVal("medium"),
+					"tier": cty.StringVal("paid"),
+				}),
+			}),
+		},
+		"deeply nested merge": {
+			`object({
+				server = optional(object({
+					tls = object({
+						enabled = bool
+						version = string
+					})
+				}), { tls = { enabled = true, version = "1.3" } })
+			})`,
+			`{ server = { tls = { enabled = false } } }`,
+			cty.ObjectVal(map[string]cty.Value{
+				"server": cty.ObjectVal(map[string]cty.Value{
+					"tls": cty.ObjectVal(map[string]cty.Value{
+						"enabled": cty.False,
+						"version": cty.StringVal("1.3"),
+					}),
+				}),
+			}),
+		},
+		"through collections of objects": {
+			`map(list(object({
+				port   = number
+				health = optional(object({
+					path     = string
+					interval = number
+				}), { path = "/", interval = 30 })
+			})))`,
+			`{
+				web = [
+					{ port = 80 },
+					{ port = 443, health = { path = "/healthz" } },
+				]
+			}`,
+			cty.MapVal(map[string]cty.Value{
+				"web": cty.ListVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"port": cty.NumberIntVal(80),
+						"health": cty.ObjectVal(map[string]cty.Value{
+							"path":     cty.StringVal("/"),
+							"interval": cty.NumberIntVal(30),
+						}),
+					}),
+					cty.ObjectVal(map[string]cty.Value{
+						"port": cty.NumberIntVal(443),
+						"health": cty.ObjectVal(map[string]cty.Value{
+							"path":     cty.StringVal("/healthz"),
+							"interval": cty.NumberIntVal(30),
+						}),
+					}),
+				}),
+			}),
+		},
+		"maps are not merged": {
+			`object({
+				tags = optional(map(string), { env = "prod" })
+			})`,
+			`{ tags = { team = "network" } }`,
+			cty.ObjectVal(map[string]cty.Value{
+				"tags": cty.MapVal(map[string]cty.Value{
+					"team": cty.StringVal("network"),
+				}),
+			}),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tyExpr, diags := hclsyntax.ParseExpression([]byte(test.Type), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			ty, defaults, diags := typeexpr.TypeConstraintWithDefaults(tyExpr)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			valExpr, diags := hclsyntax.ParseExpression([]byte(test.Value), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			val, diags := valExpr.Value(nil)
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+
+			got, err := convert.Convert(ApplyTypeDefaults(defaults, val), ty)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
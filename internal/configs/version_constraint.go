@@ -21,12 +21,15 @@ type VersionConstraint struct {
 	DeclRange hcl.Range
 }
 
-func decodeVersionConstraint(attr *hcl.Attribute) (VersionConstraint, hcl.Diagnostics) {
+// decodeVersionConstraint decodes the version constraint in the given
+// attribute. The constraint expression is evaluated in the given context,
+// which may be nil if the constraint must be a constant value.
+func decodeVersionConstraint(attr *hcl.Attribute, ctx *hcl.EvalContext) (VersionConstraint, hcl.Diagnostics) {
 	ret := VersionConstraint{
 		DeclRange: attr.Range,
 	}
 
-	val, diags := attr.Expr.Value(nil)
+	val, diags := attr.Expr.Value(ctx)
 	if diags.HasErrors() {
 		return ret, diags
 	}
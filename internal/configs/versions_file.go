@@ -0,0 +1,172 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// VersionsFileName is the name of the file that defines the version
+// constants for the modules in its directory and all directories below it.
+//
+// The constants are declared as attributes of "versions" blocks, and can be
+// referred to as versions.<name> in the version constraints and source
+// addresses of required providers and in the version argument of module
+// calls, so that many modules can share the same pins.
+const VersionsFileName = "versions.tofu"
+
+// versionsFile is a parsed versions file.
+type versionsFile struct {
+	Constants map[string]*VersionConstant
+}
+
+// VersionConstant is a single constant declared in a versions file.
+type VersionConstant struct {
+	Name      string
+	Value     cty.Value
+	DeclRange hcl.Range
+}
+
+var versionsFileSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type: "versions",
+		},
+	},
+}
+
+// VersionsFilePath returns the path of the versions file that applies to the
+// module in the given directory, which is the nearest versions file in that
+// directory or any of its parent directories, or an empty string if there
+// is none.
+func (p *Parser) VersionsFilePath(dir string) string {
+	for _, candidate := range versionsFileDirs(dir) {
+		path := filepath.Join(candidate, VersionsFileName)
+		if info, err := p.fs.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// versionsEvalContext returns the evaluation context for the expressions
+// in the module in the given directory that may refer to version constants.
+//
+// The diagnostics for a versions file are returned only the first time it is
+// loaded, so that they are reported once even when the file applies to many
+// modules.
+func (p *Parser) versionsEvalContext(dir string) (*hcl.EvalContext, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	constants := map[string]cty.Value{}
+	if path := p.VersionsFilePath(dir); path != "" {
+		if p.versionsFiles == nil {
+			p.versionsFiles = make(map[string]*versionsFile)
+		}
+		file, loaded := p.versionsFiles[path]
+		if !loaded {
+			file, diags = p.loadVersionsFile(path)
+			p.versionsFiles[path] = file
+		}
+		for name, constant := range file.Constants {
+			constants[name] = constant.Value
+		}
+	}
+
+	return &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"versions": cty.ObjectVal(constants),
+		},
+	}, diags
+}
+
+func (p *Parser) loadVersionsFile(path string) (*versionsFile, hcl.Diagnostics) {
+	ret := &versionsFile{
+		Constants: make(map[string]*VersionConstant),
+	}
+
+	body, diags := p.LoadHCLFile(path)
+	if body == nil {
+		return ret, diags
+	}
+
+	content, contentDiags := body.Content(versionsFileSchema)
+	diags = append(diags, contentDiags...)
+
+	for _, block := range content.Blocks {
+		attrs, attrDiags := block.Body.JustAttributes()
+		diags = append(diags, attrDiags...)
+
+		for name, attr := range attrs {
+			if existing, exists := ret.Constants[name]; exists {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate version constant",
+					Detail:   fmt.Sprintf("A version constant named %q was already defined at %s. Version constant names must be unique within a versions file.", name, existing.DeclRange),
+					Subject:  attr.NameRange.Ptr(),
+				})
+				continue
+			}
+
+			// Constants can't refer to anything, so that they can be
+			// evaluated before any configuration is loaded.
+			val, valDiags := attr.Expr.Value(nil)
+			diags = append(diags, valDiags...)
+			if valDiags.HasErrors() {
+				continue
+			}
+			val, err := convert.Convert(val, cty.String)
+			if err != nil || val.IsNull() {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid version constant",
+					Detail:   "A version constant must be a string.",
+					Subject:  attr.Expr.Range().Ptr(),
+				})
+				continue
+			}
+
+			ret.Constants[name] = &VersionConstant{
+				Name:      name,
+				Value:     val,
+				DeclRange: attr.Range,
+			}
+		}
+	}
+
+	return ret, diags
+}
+
+// versionsFileDirs returns the directories that may contain the versions file
+// for the module in the given directory, nearest first.
+func versionsFileDirs(dir string) []string {
+	var ret []string
+	current := filepath.Clean(dir)
+	for {
+		ret = append(ret, current)
+		parent := filepath.Dir(current)
+		if current == "." || filepath.Base(current) == ".." {
+			// A relative path can only be followed up to the current
+			// directory or the parent directory it starts from, so we
+			// continue from the absolute path of that directory. The
+			// configuration snapshot filesystem only has relative paths,
+			// but it contains the versions file in each module directory.
+			abs, err := filepath.Abs(current)
+			if err != nil {
+				break
+			}
+			parent = filepath.Dir(abs)
+		}
+		if parent == current {
+			break
+		}
+		current = parent
+	}
+	return ret
+}
@@ -0,0 +1,160 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+func TestParserLoadConfigDir_versionsFile(t *testing.T) {
+	parser := testParser(map[string]string{
+		"repo/versions.tofu": `
+			versions {
+				aws_source = "hashicorp/aws"
+				aws        = "5.31.0"
+			}
+
+			versions {
+				vpc = "~> 5.4"
+			}
+		`,
+		"repo/network/main.tf": `
+			terraform {
+				required_providers {
+					aws = {
+						source  = versions.aws_source
+						version = ">= ${versions.aws}, < 6.0.0"
+					}
+				}
+			}
+
+			module "vpc" {
+				source  = "terraform-aws-modules/vpc/aws"
+				version = versions.vpc
+			}
+		`,
+		"repo/legacy/main.tf": `
+			terraform {
+				required_providers {
+					aws = versions.aws
+				}
+			}
+		`,
+		"repo/pinned/versions.tofu": `
+			versions {
+				vpc = "5.0.0"
+			}
+		`,
+		"repo/pinned/main.tf": `
+			module "vpc" {
+				source  = "terraform-aws-modules/vpc/aws"
+				version = versions.vpc
+			}
+		`,
+	})
+
+	t.Run("nearest parent", func(t *testing.T) {
+		mod, diags := parser.LoadConfigDir("repo/network")
+		assertNoDiagnostics(t, diags)
+
+		req := mod.ProviderRequirements.RequiredProviders["aws"]
+		if got, want := req.Type, addrs.NewDefaultProvider("aws"); got != want {
+			t.Errorf("wrong provider type %s; want %s", got, want)
+		}
+		if got, want := req.Requirement.Required.String(), ">= 5.31.0, < 6.0.0"; got != want {
+			t.Errorf("wrong provider version constraint %q; want %q", got, want)
+		}
+		if got, want := mod.ModuleCalls["vpc"].Version.Required.String(), "~> 5.4"; got != want {
+			t.Errorf("wrong module version constraint %q; want %q", got, want)
+		}
+	})
+
+	t.Run("legacy requirement", func(t *testing.T) {
+		mod, diags := parser.LoadConfigDir("repo/legacy")
+		assertNoDiagnostics(t, diags)
+
+		if got, want := mod.ProviderRequirements.RequiredProviders["aws"].Requirement.Required.String(), "5.31.0"; got != want {
+			t.Errorf("wrong provider version constraint %q; want %q", got, want)
+		}
+	})
+
+	t.Run("own directory", func(t *testing.T) {
+		mod, diags := parser.LoadConfigDir("repo/pinned")
+		assertNoDiagnostics(t, diags)
+
+		if got, want := mod.ModuleCalls["vpc"].Version.Required.String(), "5.0.0"; got != want {
+			t.Errorf("wrong module version constraint %q; want %q", got, want)
+		}
+	})
+}
+
+func TestParserLoadConfigDir_versionsFileErrors(t *testing.T) {
+	tests := map[string]struct {
+		versions string
+		config   string
+		want     string
+	}{
+		"undefined constant": {
+			versions: `
+				versions {
+					aws = "5.31.0"
+				}
+			`,
+			config: `
+				module "vpc" {
+					source  = "terraform-aws-modules/vpc/aws"
+					version = versions.vpc
+				}
+			`,
+			want: `This object does not have an attribute named "vpc".`,
+		},
+		"duplicate constant": {
+			versions: `
+				versions {
+					aws = "5.31.0"
+				}
+
+				versions {
+					aws = "5.32.0"
+				}
+			`,
+			want: `A version constant named "aws" was already defined`,
+		},
+		"not a string": {
+			versions: `
+				versions {
+					aws = ["5.31.0"]
+				}
+			`,
+			want: "A version constant must be a string.",
+		},
+		"reference": {
+			versions: `
+				versions {
+					aws = var.aws_version
+				}
+			`,
+			want: "Variables may not be used here.",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			parser := testParser(map[string]string{
+				"mod/versions.tofu": test.versions,
+				"mod/main.tf":       test.config,
+			})
+			_, diags := parser.LoadConfigDir("mod")
+			if !diags.HasErrors() {
+				t.Fatal("succeeded; want error")
+			}
+			if got := diags.Error(); !strings.Contains(got, test.want) {
+				t.Errorf("wrong error\ngot:  %s\nwant: message containing %q", got, test.want)
+			}
+		})
+	}
+}
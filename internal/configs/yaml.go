@@ -0,0 +1,350 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// yamlConfigExts are the extensions of configuration files written in the
+// YAML syntax.
+//
+// The YAML syntax is defined as a mapping onto the HCL JSON syntax: each YAML
+// document is translated into the JSON document with the same structure and
+// then decoded exactly as a .tf.json file would be, so the rules for
+// expressing blocks, arguments and expressions are the same as for JSON.
+var yamlConfigExts = []string{".tf.yaml", ".tofu.yaml"}
+
+// IsYAMLConfigFile returns true if the given filename has one of the
+// extensions used for configuration files written in the YAML syntax.
+func IsYAMLConfigFile(name string) bool {
+	for _, ext := range yamlConfigExts {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// yamlLineErrorPattern matches the line number included in most of the
+// errors returned by the YAML parser.
+var yamlLineErrorPattern = regexp.MustCompile(`^yaml: line (\d+): `)
+
+// ParseYAMLNode parses the given source code as a single YAML document,
+// returning its root node.
+//
+// The returned node is nil if the source is empty.
+func ParseYAMLNode(src []byte, filename string) (*yaml.Node, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	dec := yaml.NewDecoder(bytes.NewReader(src))
+	var root yaml.Node
+	if err := dec.Decode(&root); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		diags = append(diags, yamlSyntaxError(src, filename, err))
+		return nil, diags
+	}
+
+	var extra yaml.Node
+	if err := dec.Decode(&extra); err == nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Multiple YAML documents",
+			Detail:   "A configuration file may contain only a single YAML document.",
+			Subject:  yamlNodeRange(src, filename, &extra).Ptr(),
+		})
+		return nil, diags
+	} else if !errors.Is(err, io.EOF) {
+		diags = append(diags, yamlSyntaxError(src, filename, err))
+		return nil, diags
+	}
+
+	return &root, diags
+}
+
+// yamlToJSON translates the given YAML source code into the equivalent HCL
+// JSON syntax.
+//
+// The tokens of the resulting JSON are placed on the same lines as the
+// corresponding YAML nodes, and at the same columns where possible, so that
+// source positions reported in diagnostics about the JSON are meaningful
+// for the original YAML file.
+func yamlToJSON(src []byte, filename string) ([]byte, hcl.Diagnostics) {
+	root, diags := ParseYAMLNode(src, filename)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	if root == nil || len(root.Content) == 0 {
+		return []byte("{}"), diags
+	}
+
+	w := &yamlJSONWriter{
+		src:      src,
+		filename: filename,
+		line:     1,
+		column:   1,
+	}
+	w.node(root.Content[0])
+	diags = append(diags, w.diags...)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return w.buf.Bytes(), diags
+}
+
+// maxYAMLAliasNodes is the largest number of nodes that the aliases in a
+// configuration file may expand to in total, so that nested aliases can't
+// expand a small file into an enormous configuration.
+const maxYAMLAliasNodes = 100000
+
+type yamlJSONWriter struct {
+	src      []byte
+	filename string
+
+	buf          bytes.Buffer
+	line, column int
+
+	// expanding are the anchored nodes whose aliases are currently being
+	// expanded, to detect aliases which refer to themselves, and aliasNodes
+	// is the number of nodes written from alias expansions so far.
+	expanding  map[*yaml.Node]bool
+	aliasNodes int
+	// aborted is set when the document can't be translated at all, to stop
+	// writing the rest of it.
+	aborted bool
+
+	diags hcl.Diagnostics
+}
+
+// moveTo pads the output so that the next token starts at the given line
+// and column, unless the output has already passed that position.
+func (w *yamlJSONWriter) moveTo(line, column int) {
+	if line > w.line {
+		w.buf.WriteString(strings.Repeat("\n", line-w.line))
+		w.line = line
+		w.column = 1
+	}
+	if line == w.line && column > w.column {
+		w.buf.WriteString(strings.Repeat(" ", column-w.column))
+		w.column = column
+	}
+}
+
+func (w *yamlJSONWriter) write(s string) {
+	w.buf.WriteString(s)
+	w.column += utf8.RuneCountInString(s)
+}
+
+func (w *yamlJSONWriter) writeJSON(v interface{}) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		// Should never happen, because we only encode strings, numbers
+		// and booleans decoded from YAML scalars.
+		panic(fmt.Sprintf("failed to encode %#v as JSON: %s", v, err))
+	}
+	w.write(strings.TrimSuffix(buf.String(), "\n"))
+}
+
+func (w *yamlJSONWriter) node(n *yaml.Node) {
+	if w.aborted {
+		return
+	}
+	if len(w.expanding) > 0 {
+		w.aliasNodes++
+		if w.aliasNodes > maxYAMLAliasNodes {
+			w.errorf(n, "YAML aliases too large", fmt.Sprintf("The aliases in this configuration file expand to more than %d values.", maxYAMLAliasNodes))
+			w.aborted = true
+			return
+		}
+	}
+
+	switch n.Kind {
+	case yaml.AliasNode:
+		if w.expanding[n.Alias] {
+			w.errorf(n, "Recursive YAML alias", fmt.Sprintf("The alias *%s refers to a value that contains the alias itself.", n.Value))
+			w.aborted = true
+			return
+		}
+		if w.expanding == nil {
+			w.expanding = make(map[*yaml.Node]bool)
+		}
+		// The aliased node appears earlier in the file, so its contents
+		// are written at the position of the alias itself.
+		w.moveTo(n.Line, n.Column)
+		w.expanding[n.Alias] = true
+		w.node(n.Alias)
+		delete(w.expanding, n.Alias)
+	case yaml.MappingNode:
+		w.openCollection(n, "{")
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if i > 0 {
+				w.write(",")
+			}
+			key := k
+			if key.Kind == yaml.AliasNode {
+				key = key.Alias
+			}
+			if key.Kind != yaml.ScalarNode {
+				w.errorf(k, "Invalid YAML mapping key", "Mapping keys in configuration files must be strings.")
+				return
+			}
+			if key.ShortTag() == "!!merge" {
+				w.errorf(k, "Unsupported YAML merge key", "Merge keys are not supported in configuration files. Use an alias to repeat a whole value instead.")
+				return
+			}
+			w.moveTo(k.Line, k.Column)
+			w.writeJSON(key.Value)
+			w.write(": ")
+			w.node(v)
+		}
+		w.write("}")
+	case yaml.SequenceNode:
+		w.openCollection(n, "[")
+		for i, v := range n.Content {
+			if i > 0 {
+				w.write(",")
+			}
+			w.node(v)
+		}
+		w.write("]")
+	case yaml.ScalarNode:
+		w.moveTo(n.Line, n.Column)
+		w.scalar(n)
+	default:
+		w.errorf(n, "Unsupported YAML node", "This YAML construct cannot be used in a configuration file.")
+	}
+}
+
+// openCollection writes the opening delimiter of a mapping or sequence.
+//
+// The position of a block collection is the position of its first key or
+// item, so in that case the delimiter is written immediately after the
+// preceding token to leave that position free for the first key or item.
+func (w *yamlJSONWriter) openCollection(n *yaml.Node, delim string) {
+	if n.Style&yaml.FlowStyle != 0 {
+		w.moveTo(n.Line, n.Column)
+	}
+	w.write(delim)
+}
+
+func (w *yamlJSONWriter) scalar(n *yaml.Node) {
+	switch n.ShortTag() {
+	case "!!null":
+		w.write("null")
+	case "!!bool":
+		var v bool
+		if err := n.Decode(&v); err != nil {
+			w.errorf(n, "Invalid YAML boolean", err.Error())
+			return
+		}
+		w.writeJSON(v)
+	case "!!int", "!!float":
+		var v float64
+		if err := n.Decode(&v); err != nil {
+			w.errorf(n, "Invalid YAML number", err.Error())
+			return
+		}
+		// JSON has no representation of infinity or NaN.
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			w.errorf(n, "Invalid YAML number", fmt.Sprintf("The number %s cannot be used in a configuration file.", n.Value))
+			return
+		}
+		// We write integers exactly as given, aside from YAML-specific
+		// prefixes, so that large numbers don't lose precision.
+		if n.ShortTag() == "!!int" {
+			var i int64
+			if err := n.Decode(&i); err == nil {
+				w.write(strconv.FormatInt(i, 10))
+				return
+			}
+		}
+		w.writeJSON(v)
+	default:
+		// Strings, and scalars with any other tag, are taken literally so
+		// that they can contain template sequences as in the JSON syntax.
+		w.writeJSON(n.Value)
+	}
+}
+
+func (w *yamlJSONWriter) errorf(n *yaml.Node, summary, detail string) {
+	w.diags = append(w.diags, &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  summary,
+		Detail:   detail,
+		Subject:  yamlNodeRange(w.src, w.filename, n).Ptr(),
+	})
+}
+
+// yamlNodeRange returns a range covering the start of the given node in the
+// original YAML source.
+func yamlNodeRange(src []byte, filename string, n *yaml.Node) hcl.Range {
+	pos := yamlPos(src, n.Line, n.Column)
+	end := pos
+	if n.Kind == yaml.ScalarNode {
+		end.Column += utf8.RuneCountInString(n.Value)
+		end.Byte += len(n.Value)
+	}
+	return hcl.Range{
+		Filename: filename,
+		Start:    pos,
+		End:      end,
+	}
+}
+
+func yamlSyntaxError(src []byte, filename string, err error) *hcl.Diagnostic {
+	diag := &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Invalid YAML syntax",
+		Detail:   fmt.Sprintf("The file could not be parsed as YAML: %s.", strings.TrimPrefix(err.Error(), "yaml: ")),
+	}
+	if m := yamlLineErrorPattern.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		pos := yamlPos(src, line, 1)
+		diag.Detail = fmt.Sprintf("The file could not be parsed as YAML: %s.", strings.TrimPrefix(err.Error(), m[0]))
+		diag.Subject = &hcl.Range{
+			Filename: filename,
+			Start:    pos,
+			End:      pos,
+		}
+	}
+	return diag
+}
+
+// yamlPos converts a one-based line and column as reported by the YAML
+// parser into an hcl.Pos, including its byte offset into src.
+func yamlPos(src []byte, line, column int) hcl.Pos {
+	pos := hcl.Pos{Line: line, Column: column}
+	offset := 0
+	for l := 1; l < line; l++ {
+		i := bytes.IndexByte(src[offset:], '\n')
+		if i < 0 {
+			offset = len(src)
+			break
+		}
+		offset += i + 1
+	}
+	for c := 1; c < column && offset < len(src) && src[offset] != '\n'; c++ {
+		_, size := utf8.DecodeRune(src[offset:])
+		offset += size
+	}
+	pos.Byte = offset
+	return pos
+}
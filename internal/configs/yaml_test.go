@@ -0,0 +1,219 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package configs
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestYAMLToJSON(t *testing.T) {
+	tests := map[string]struct {
+		src     string
+		want    string
+		wantErr string
+	}{
+		"empty": {
+			``,
+			`{}`,
+			``,
+		},
+		"scalars": {
+			`
+a: hello
+b: 1
+c: 1.5
+d: true
+e: null
+f: "true"
+g: 0x10
+h: ${var.foo}
+`,
+			`{"a":"hello","b":1,"c":1.5,"d":true,"e":null,"f":"true","g":16,"h":"${var.foo}"}`,
+			``,
+		},
+		"collections": {
+			`
+list:
+  - a
+  - {b: c}
+empty: []
+`,
+			`{"list":["a",{"b":"c"}],"empty":[]}`,
+			``,
+		},
+		"alias": {
+			`
+a: &x
+  b: c
+d: *x
+`,
+			`{"a":{"b":"c"},"d":{"b":"c"}}`,
+			``,
+		},
+		"recursive alias": {
+			`
+locals:
+  a: &x [1, *x]
+`,
+			``,
+			`Recursive YAML alias`,
+		},
+		"nested aliases": {
+			`
+a: &a [x, x, x, x, x, x, x, x, x, x]
+b: &b [*a, *a, *a, *a, *a, *a, *a, *a, *a, *a]
+c: &c [*b, *b, *b, *b, *b, *b, *b, *b, *b, *b]
+d: &d [*c, *c, *c, *c, *c, *c, *c, *c, *c, *c]
+e: &e [*d, *d, *d, *d, *d, *d, *d, *d, *d, *d]
+f: &f [*e, *e, *e, *e, *e, *e, *e, *e, *e, *e]
+g: &g [*f, *f, *f, *f, *f, *f, *f, *f, *f, *f]
+h: &h [*g, *g, *g, *g, *g, *g, *g, *g, *g, *g]
+i: &i [*h, *h, *h, *h, *h, *h, *h, *h, *h, *h]
+`,
+			``,
+			`YAML aliases too large`,
+		},
+		"block scalar": {
+			`
+a: |
+  line one
+  line two
+b: c
+`,
+			`{"a":"line one\nline two\n","b":"c"}`,
+			``,
+		},
+		"merge key": {
+			`
+a: &x
+  b: c
+d:
+  <<: *x
+`,
+			``,
+			`Unsupported YAML merge key`,
+		},
+		"complex key": {
+			`
+? [a, b]
+: c
+`,
+			``,
+			`Invalid YAML mapping key`,
+		},
+		"infinity": {
+			`a: .inf`,
+			``,
+			`Invalid YAML number`,
+		},
+		"multiple documents": {
+			"a: b\n---\nc: d\n",
+			``,
+			`Multiple YAML documents`,
+		},
+		"syntax error": {
+			"a: b\nc: [d\n",
+			``,
+			`Invalid YAML syntax`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, diags := yamlToJSON([]byte(test.src), "test.tf.yaml")
+			if test.wantErr != "" {
+				if !diags.HasErrors() {
+					t.Fatalf("unexpected success\ngot: %s", got)
+				}
+				if diags[0].Summary != test.wantErr {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", diags[0].Summary, test.wantErr)
+				}
+				return
+			}
+			if diags.HasErrors() {
+				t.Fatalf("unexpected errors: %s", diags.Error())
+			}
+
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, got); err != nil {
+				t.Fatalf("result is not valid JSON: %s\n%s", err, got)
+			}
+			if compact.String() != test.want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", compact.String(), test.want)
+			}
+		})
+	}
+}
+
+func TestYAMLToJSON_sourcePositions(t *testing.T) {
+	src := `# comment
+resource:
+  null_resource:
+    example:
+      triggers:
+        a: b
+`
+	got, diags := yamlToJSON([]byte(src), "test.tf.yaml")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Error())
+	}
+
+	// Each key should appear on the same line and column as in the YAML.
+	srcLines := strings.Split(src, "\n")
+	gotLines := strings.Split(string(got), "\n")
+	for _, key := range []string{"resource", "null_resource", "example", "triggers", "a"} {
+		for i, line := range srcLines {
+			col := strings.Index(line, key+":")
+			if col < 0 {
+				continue
+			}
+			if i >= len(gotLines) || strings.Index(gotLines[i], `"`+key+`"`) != col {
+				t.Errorf("key %q is not at line %d column %d in the result\n%s", key, i+1, col+1, got)
+			}
+			break
+		}
+	}
+}
+
+func TestParserLoadConfigFile_yamlDiagnostics(t *testing.T) {
+	parser := testParser(map[string]string{
+		"main.tf.yaml": `
+resource:
+  null_resource:
+    example:
+      triggers:
+        a: b
+      not_an_argument: true
+`,
+	})
+
+	_, diags := parser.LoadConfigFile("main.tf.yaml")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Error())
+	}
+
+	_, diags = parser.LoadConfigFile("missing.tf.yaml")
+	if !diags.HasErrors() {
+		t.Fatalf("unexpected success loading missing file")
+	}
+
+	parser = testParser(map[string]string{
+		"main.tf.yaml": `
+variable:
+  example:
+    type: string
+    not_an_argument: true
+`,
+	})
+	_, diags = parser.LoadConfigFile("main.tf.yaml")
+	if !diags.HasErrors() {
+		t.Fatalf("unexpected success")
+	}
+	if got, want := diags[0].Subject.Start.Line, 5; got != want {
+		t.Errorf("wrong line in diagnostic %q: got %d, want %d", diags[0].Summary, got, want)
+	}
+}
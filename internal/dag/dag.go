@@ -100,17 +100,30 @@ func (g *AcyclicGraph) TransitiveReduction() {
 	// v such that the edge (u,v) exists (v is a direct descendant of u).
 	//
 	// For each v-prime reachable from v, remove the edge (u, v-prime).
-	for _, u := range g.Vertices() {
+	//
+	// Removing these edges doesn't change which vertices are reachable from
+	// any other, so we can find the edges to remove for every vertex in
+	// parallel before removing any of them.
+	vs := g.Vertices()
+	remove := make([]Set, len(vs))
+	ParallelEach(len(vs), func(i int) {
+		u := vs[i]
 		uTargets := g.downEdgesNoCopy(u)
+		redundant := make(Set)
 
-		g.DepthFirstWalk(g.downEdgesNoCopy(u), func(v Vertex, d int) error {
-			shared := uTargets.Intersection(g.downEdgesNoCopy(v))
-			for _, vPrime := range shared {
-				g.RemoveEdge(BasicEdge(u, vPrime))
+		g.DepthFirstWalk(uTargets, func(v Vertex, d int) error {
+			for _, vPrime := range uTargets.Intersection(g.downEdgesNoCopy(v)) {
+				redundant.Add(vPrime)
 			}
-
 			return nil
 		})
+		remove[i] = redundant
+	})
+
+	for i, u := range vs {
+		for _, vPrime := range remove[i] {
+			g.RemoveEdge(BasicEdge(u, vPrime))
+		}
 	}
 }
 
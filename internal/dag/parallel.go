@@ -0,0 +1,48 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package dag
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// ParallelEach calls fn with each index from 0 to n-1, spreading the calls
+// across the available CPU cores, and returns once all of the calls have
+// returned.
+//
+// This is for independent work on each vertex of a large graph, such as
+// analyzing the vertices while building it, and so fn must be safe to call
+// concurrently. Any changes to the graph must wait until ParallelEach
+// returns.
+func ParallelEach(n int, fn func(i int)) {
+	workers := runtime.GOMAXPROCS(0)
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1)) - 1
+				if i >= n {
+					return
+				}
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
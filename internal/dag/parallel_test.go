@@ -0,0 +1,26 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package dag
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParallelEach(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	for _, n := range []int{0, 1, 3, 100} {
+		calls := make([]atomic.Int32, n)
+		ParallelEach(n, func(i int) {
+			calls[i].Add(1)
+		})
+		for i := range calls {
+			if got := calls[i].Load(); got != 1 {
+				t.Errorf("n=%d: index %d called %d times; want 1", n, i, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,403 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package depsbundle reads and writes dependency bundles, which are archives
+// of the provider packages, module packages, and dependency lock file that a
+// configuration needs, so that it can be initialized on an isolated network.
+//
+// A bundle is a zip archive containing a manifest of the SHA-256 checksums of
+// all of its other files, and optionally an OpenPGP signature of the
+// manifest, so that the whole bundle can be verified before it is used.
+package depsbundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+const (
+	// ManifestFilename is the name of the manifest in a bundle archive.
+	ManifestFilename = "manifest.json"
+
+	// SignatureFilename is the name of the ASCII-armored detached signature
+	// of the manifest in a signed bundle archive.
+	SignatureFilename = "manifest.json.sig"
+
+	// formatVersion is the version of the bundle format that this package
+	// writes. Bundles with a different major version can't be read.
+	formatVersion = "1.0"
+)
+
+// Manifest describes the contents of a bundle.
+type Manifest struct {
+	FormatVersion string `json:"format_version"`
+
+	// Files maps the slash-separated path of each file in the bundle, other
+	// than the manifest and its signature, to the hex-encoded SHA-256
+	// checksum of its contents.
+	Files map[string]string `json:"files"`
+}
+
+// Create writes a bundle to the given filename containing all of the files
+// in the given directory, overwriting any file that might already exist
+// there.
+//
+// Create follows symbolic links, so that the bundle contains the files and
+// directories they refer to, such as the provider packages that an
+// installation links to from a shared plugin cache. The permissions of each
+// file, including whether it's executable, are preserved.
+//
+// If signer is not nil, the bundle is signed with its private key, which
+// must already be decrypted.
+func Create(filename, dir string, signer *openpgp.Entity) error {
+	manifest := &Manifest{
+		FormatVersion: formatVersion,
+		Files:         make(map[string]string),
+	}
+	var paths []string
+	modes := make(map[string]fs.FileMode)
+	err := walkFollowingLinks(dir, "", make(map[string]bool), func(rel, p string, mode fs.FileMode) error {
+		if rel == ManifestFilename || rel == SignatureFilename {
+			return fmt.Errorf("%s is reserved for the bundle manifest", rel)
+		}
+		sum, err := fileChecksum(p)
+		if err != nil {
+			return err
+		}
+		manifest.Files[rel] = sum
+		modes[rel] = mode
+		paths = append(paths, rel)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+
+	manifestSrc, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	var signature []byte
+	if signer != nil {
+		if signer.PrivateKey == nil {
+			return fmt.Errorf("the signing key has no private key")
+		}
+		if signer.PrivateKey.Encrypted {
+			return fmt.Errorf("the signing key is protected by a passphrase, which is not supported")
+		}
+		var buf bytes.Buffer
+		if err := openpgp.ArmoredDetachSign(&buf, signer, bytes.NewReader(manifestSrc), nil); err != nil {
+			return fmt.Errorf("failed to sign the bundle: %w", err)
+		}
+		signature = buf.Bytes()
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	if err := writeZipFile(zw, ManifestFilename, bytes.NewReader(manifestSrc), 0644); err != nil {
+		return err
+	}
+	if signature != nil {
+		if err := writeZipFile(zw, SignatureFilename, bytes.NewReader(signature), 0644); err != nil {
+			return err
+		}
+	}
+	for _, rel := range paths {
+		src, err := os.Open(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			return err
+		}
+		err = writeZipFile(zw, rel, src, modes[rel])
+		src.Close()
+		if err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// walkFollowingLinks calls fn for each regular file in the given directory
+// and its subdirectories, following symbolic links, with the slash-separated
+// path of the file relative to the root of the walk, its path on disk, and
+// its permissions. rel is the relative path of dir itself, and seen records
+// the real paths of the directories being walked, to detect cycles.
+func walkFollowingLinks(dir, rel string, seen map[string]bool, fn func(rel, p string, mode fs.FileMode) error) error {
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	if seen[real] {
+		return fmt.Errorf("%s is a symbolic link to one of its own parent directories", dir)
+	}
+	seen[real] = true
+	defer delete(seen, real)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		p := filepath.Join(dir, entry.Name())
+		entryRel := path.Join(rel, entry.Name())
+		// os.Stat follows symbolic links, unlike the entry's own info.
+		info, err := os.Stat(p)
+		if err != nil {
+			return err
+		}
+		switch {
+		case info.IsDir():
+			err = walkFollowingLinks(p, entryRel, seen, fn)
+		case info.Mode().IsRegular():
+			err = fn(entryRel, p, info.Mode().Perm())
+		default:
+			err = fmt.Errorf("%s is not a regular file", p)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeZipFile(zw *zip.Writer, name string, r io.Reader, mode fs.FileMode) error {
+	header := &zip.FileHeader{
+		Name:   name,
+		Method: zip.Deflate,
+	}
+	header.SetMode(mode)
+	w, err := zw.CreateHeader(header)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to the bundle: %w", name, err)
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return fmt.Errorf("failed to add %s to the bundle: %w", name, err)
+	}
+	return nil
+}
+
+// Reader reads a bundle created by Create.
+type Reader struct {
+	zip *zip.ReadCloser
+
+	// Manifest is the bundle's manifest. Its checksums are not verified
+	// until Verify is called.
+	Manifest *Manifest
+
+	manifestSrc []byte
+	signature   []byte
+}
+
+// Open opens the bundle with the given filename and reads its manifest.
+// The caller must close the returned reader.
+func Open(filename string) (*Reader, error) {
+	zr, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the bundle: %w", err)
+	}
+	r := &Reader{zip: zr}
+	if err := r.readManifest(); err != nil {
+		zr.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Reader) readManifest() error {
+	for _, file := range r.zip.File {
+		var err error
+		switch file.Name {
+		case ManifestFilename:
+			r.manifestSrc, err = readZipFile(file)
+		case SignatureFilename:
+			r.signature, err = readZipFile(file)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if r.manifestSrc == nil {
+		return fmt.Errorf("the bundle has no %s, so it is not a dependency bundle", ManifestFilename)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(r.manifestSrc, &manifest); err != nil {
+		return fmt.Errorf("invalid bundle manifest: %w", err)
+	}
+	if !strings.HasPrefix(manifest.FormatVersion, "1.") {
+		return fmt.Errorf("unsupported bundle format version %q; this version of OpenTofu supports only version 1 bundles", manifest.FormatVersion)
+	}
+	r.Manifest = &manifest
+	return nil
+}
+
+// Signed returns true if the bundle has a signature.
+func (r *Reader) Signed() bool {
+	return r.signature != nil
+}
+
+// Verify checks that the bundle contains exactly the files in its manifest
+// with the checksums recorded there.
+//
+// If keyring is not empty, Verify also checks that the bundle is signed by
+// one of its keys, and returns the entity that signed it.
+func (r *Reader) Verify(keyring openpgp.EntityList) (*openpgp.Entity, error) {
+	var signer *openpgp.Entity
+	if len(keyring) > 0 {
+		if r.signature == nil {
+			return nil, fmt.Errorf("the bundle is not signed")
+		}
+		var err error
+		signer, err = openpgp.CheckArmoredDetachedSignature(keyring, bytes.NewReader(r.manifestSrc), bytes.NewReader(r.signature), nil)
+		if err != nil {
+			return nil, fmt.Errorf("the bundle signature is not valid for any of the trusted keys: %w", err)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, file := range r.zip.File {
+		if file.Name == ManifestFilename || file.Name == SignatureFilename || strings.HasSuffix(file.Name, "/") {
+			continue
+		}
+		want, ok := r.Manifest.Files[file.Name]
+		if !ok {
+			return nil, fmt.Errorf("the bundle contains %s, which is not in its manifest", file.Name)
+		}
+		if seen[file.Name] {
+			return nil, fmt.Errorf("the bundle contains %s more than once", file.Name)
+		}
+		seen[file.Name] = true
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from the bundle: %w", file.Name, err)
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from the bundle: %w", file.Name, err)
+		}
+		if got := hex.EncodeToString(h.Sum(nil)); got != want {
+			return nil, fmt.Errorf("the checksum of %s doesn't match the bundle manifest", file.Name)
+		}
+	}
+	for name := range r.Manifest.Files {
+		if !seen[name] {
+			return nil, fmt.Errorf("the bundle is missing %s, which is in its manifest", name)
+		}
+	}
+	return signer, nil
+}
+
+// ExtractTo writes the files in the bundle, other than its manifest and
+// signature, into the given directory. The caller should call Verify first.
+func (r *Reader) ExtractTo(dir string) error {
+	for _, file := range r.zip.File {
+		if _, ok := r.Manifest.Files[file.Name]; !ok {
+			continue
+		}
+		name := filepath.FromSlash(file.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("the bundle contains %s, which is outside of the bundle", file.Name)
+		}
+		dst := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+			return err
+		}
+		if err := extractZipFile(file, dst); err != nil {
+			return fmt.Errorf("failed to extract %s from the bundle: %w", file.Name, err)
+		}
+	}
+	return nil
+}
+
+// Close closes the bundle.
+func (r *Reader) Close() error {
+	return r.zip.Close()
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from the bundle: %w", file.Name, err)
+	}
+	defer rc.Close()
+	src, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from the bundle: %w", file.Name, err)
+	}
+	return src, nil
+}
+
+func extractZipFile(file *zip.File, dst string) error {
+	// Create records the permissions of every file it writes, so a file
+	// without any wasn't written by it.
+	perm := file.Mode().Perm()
+	if perm == 0 {
+		return fmt.Errorf("the bundle doesn't record its file permissions")
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	f, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, rc); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func fileChecksum(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ReadKeyRing reads the ASCII-armored OpenPGP keys in the given file, which
+// may be public keys for verifying bundles or a private key for signing them.
+func ReadKeyRing(filename string) (openpgp.EntityList, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	keyring, err := openpgp.ReadArmoredKeyRing(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key in %s: %w", filename, err)
+	}
+	if len(keyring) == 0 {
+		return nil, fmt.Errorf("%s contains no keys", filename)
+	}
+	return keyring, nil
+}
@@ -0,0 +1,317 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package depsbundle
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+func testBundleDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		".terraform.lock.hcl":     "# lock file\n",
+		"modules/modules.json":    `{"Modules":[]}`,
+		"providers/example/a.zip": "not really a zip",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func testEntity(t *testing.T) *openpgp.Entity {
+	t.Helper()
+	entity, err := openpgp.NewEntity("Test", "", "test@example.com", &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entity
+}
+
+func TestCreateAndExtract(t *testing.T) {
+	src := testBundleDir(t)
+	signer := testEntity(t)
+	filename := filepath.Join(t.TempDir(), "deps.zip")
+	if err := Create(filename, src, signer); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if !r.Signed() {
+		t.Error("bundle isn't signed")
+	}
+	if len(r.Manifest.Files) != 3 {
+		t.Errorf("wrong manifest files %#v", r.Manifest.Files)
+	}
+
+	got, err := r.Verify(openpgp.EntityList{signer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.PrimaryKey.KeyId != signer.PrimaryKey.KeyId {
+		t.Errorf("wrong signer")
+	}
+	if _, err := r.Verify(openpgp.EntityList{testEntity(t)}); err == nil {
+		t.Error("bundle verified with an untrusted key")
+	}
+
+	dst := t.TempDir()
+	if err := r.ExtractTo(dst); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(dst, "providers", "example", "a.zip"))
+	if err != nil || string(content) != "not really a zip" {
+		t.Errorf("wrong extracted content %q: %v", content, err)
+	}
+}
+
+func TestVerify_unsigned(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "deps.zip")
+	if err := Create(filename, testBundleDir(t), nil); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if r.Signed() {
+		t.Error("bundle is signed")
+	}
+	if _, err := r.Verify(nil); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	_, err = r.Verify(openpgp.EntityList{testEntity(t)})
+	if err == nil || !strings.Contains(err.Error(), "not signed") {
+		t.Errorf("wrong error: %v", err)
+	}
+}
+
+func TestVerify_tampered(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "deps.zip")
+	if err := Create(filename, testBundleDir(t), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Copy the bundle, replacing the contents of one of the files.
+	tampered := filepath.Join(t.TempDir(), "tampered.zip")
+	zr, err := zip.OpenReader(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	f, err := os.Create(tampered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, file := range zr.File {
+		content := strings.NewReader("# a different lock file\n")
+		if file.Name != ".terraform.lock.hcl" {
+			if err := zw.Copy(file); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := writeZipFile(zw, file.Name, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	r, err := Open(tampered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	_, err = r.Verify(nil)
+	if err == nil || !strings.Contains(err.Error(), "checksum of .terraform.lock.hcl") {
+		t.Errorf("wrong error: %v", err)
+	}
+}
+
+func TestCreateAndExtract_permissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows has no executable permission")
+	}
+	src := testBundleDir(t)
+	exe := filepath.Join(src, "providers", "example", "terraform-provider-example")
+	if err := os.WriteFile(exe, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), "deps.zip")
+	if err := Create(filename, src, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := extractTestBundle(t, filename)
+	info, err := os.Stat(filepath.Join(dst, "providers", "example", "terraform-provider-example"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0100 == 0 {
+		t.Errorf("executable permission was not preserved: %s", info.Mode())
+	}
+	info, err = os.Stat(filepath.Join(dst, ".terraform.lock.hcl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0111 != 0 {
+		t.Errorf("non-executable file became executable: %s", info.Mode())
+	}
+}
+
+func TestCreate_symlinks(t *testing.T) {
+	// A shared plugin cache that the installation links to.
+	cache := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(cache, "example", "1.0.0"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cache, "example", "1.0.0", "provider"), []byte("cached provider"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cache, "notes.txt"), []byte("notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := testBundleDir(t)
+	if err := os.Symlink(filepath.Join(cache, "example"), filepath.Join(src, "providers", "linked")); err != nil {
+		t.Skipf("can't create symbolic links: %s", err)
+	}
+	if err := os.Symlink(filepath.Join(cache, "notes.txt"), filepath.Join(src, "notes.txt")); err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), "deps.zip")
+	if err := Create(filename, src, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := extractTestBundle(t, filename)
+	for name, want := range map[string]string{
+		"providers/linked/1.0.0/provider": "cached provider",
+		"notes.txt":                       "notes",
+	} {
+		p := filepath.Join(dst, filepath.FromSlash(name))
+		content, err := os.ReadFile(p)
+		if err != nil || string(content) != want {
+			t.Errorf("wrong content for %s %q: %v", name, content, err)
+		}
+		if info, err := os.Lstat(p); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			t.Errorf("%s was extracted as a symbolic link", name)
+		}
+	}
+
+	// A link to one of its own parent directories would never end.
+	if err := os.Symlink(src, filepath.Join(src, "modules", "loop")); err != nil {
+		t.Fatal(err)
+	}
+	err := Create(filename, src, nil)
+	if err == nil || !strings.Contains(err.Error(), "own parent directories") {
+		t.Errorf("wrong error for a symbolic link loop: %v", err)
+	}
+}
+
+func TestExtractTo_nonLocal(t *testing.T) {
+	for _, name := range []string{"../evil", "a/../../evil", "/evil", ""} {
+		t.Run(name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "deps.zip")
+			f, err := os.Create(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			zw := zip.NewWriter(f)
+			manifest := fmt.Sprintf(`{"format_version":"1.0","files":{%q:%q}}`, name, strings.Repeat("0", 64))
+			if err := writeZipFile(zw, ManifestFilename, strings.NewReader(manifest), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := writeZipFile(zw, name, strings.NewReader("evil"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := zw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			f.Close()
+
+			r, err := Open(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+			err = r.ExtractTo(filepath.Join(t.TempDir(), "dst"))
+			if err == nil || !strings.Contains(err.Error(), "outside of the bundle") {
+				t.Errorf("wrong error: %v", err)
+			}
+		})
+	}
+}
+
+func TestExtractTo_noPermissions(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "deps.zip")
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	manifest := fmt.Sprintf(`{"format_version":"1.0","files":{"a.txt":%q}}`, strings.Repeat("0", 64))
+	if err := writeZipFile(zw, ManifestFilename, strings.NewReader(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeZipFile(zw, "a.txt", strings.NewReader("a"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	r, err := Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	err = r.ExtractTo(filepath.Join(t.TempDir(), "dst"))
+	if err == nil || !strings.Contains(err.Error(), "doesn't record its file permissions") {
+		t.Errorf("wrong error: %v", err)
+	}
+}
+
+func extractTestBundle(t *testing.T, filename string) string {
+	t.Helper()
+	r, err := Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if _, err := r.Verify(nil); err != nil {
+		t.Fatal(err)
+	}
+	dst := t.TempDir()
+	if err := r.ExtractTo(dst); err != nil {
+		t.Fatal(err)
+	}
+	return dst
+}
@@ -200,7 +200,7 @@ func (b *binary) StateFromFile(filename string) (*states.State, error) {
 // Plan is a helper for easily reading a plan file from the working directory.
 func (b *binary) Plan(path string) (*plans.Plan, error) {
 	path = b.Path(path)
-	pr, err := planfile.Open(path)
+	pr, err := planfile.Open(path, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -59,6 +59,21 @@ func WrapResourceContents(addr addrs.AbsResourceInstance, config string) string
 	return string(formatted)
 }
 
+// GenerateImportBlock generates an OpenTofu import block which imports the
+// object with the given ID to the given resource instance.
+func GenerateImportBlock(addr addrs.AbsResourceInstance, id string) string {
+	var buf strings.Builder
+
+	buf.WriteString("import {\n")
+	buf.WriteString(fmt.Sprintf("  to = %s\n", addr))
+	buf.WriteString(fmt.Sprintf("  id = %s\n", hclwrite.TokensForValue(cty.StringVal(id)).Bytes()))
+	buf.WriteString("}")
+
+	// The output better be valid HCL which can be parsed and formatted.
+	formatted := hclwrite.Format([]byte(buf.String()))
+	return string(formatted)
+}
+
 func writeConfigAttributes(addr addrs.AbsResourceInstance, buf *strings.Builder, attrs map[string]*configschema.Attribute, indent int) tfdiags.Diagnostics {
 	var diags tfdiags.Diagnostics
 
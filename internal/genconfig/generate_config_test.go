@@ -440,3 +440,20 @@ resource "tfcoremock_simple_resource" "empty" {
 		})
 	}
 }
+
+func TestGenerateImportBlock(t *testing.T) {
+	addr := addrs.Resource{
+		Mode: addrs.ManagedResourceMode,
+		Type: "tfcoremock_simple_resource",
+		Name: "web",
+	}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance)
+
+	got := GenerateImportBlock(addr, `web-"1"-${x}`)
+	want := `import {
+  to = tfcoremock_simple_resource.web
+  id = "web-\"1\"-$${x}"
+}`
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
@@ -73,6 +73,8 @@ var goGetterDecompressors = map[string]getter.Decompressor{
 	"txz":    new(getter.TarXzDecompressor),
 }
 
+// The "oci" getter isn't included here because it has per-installation
+// settings, so each PackageFetcher adds its own. See ociGetter.
 var goGetterGetters = map[string]getter.Getter{
 	"file":  new(getter.FileGetter),
 	"gcs":   new(getter.GCSGetter),
@@ -124,7 +126,7 @@ type reusingGetter map[string]string
 // end-user-actionable error messages. At this time we do not have any
 // reasonable way to improve these error messages at this layer because
 // the underlying errors are not separately recognizable.
-func (g reusingGetter) getWithGoGetter(ctx context.Context, instPath, packageAddr string) error {
+func (g reusingGetter) getWithGoGetter(ctx context.Context, instPath, packageAddr string, getters map[string]getter.Getter) error {
 	var err error
 
 	if prevDir, exists := g[packageAddr]; exists {
@@ -148,7 +150,7 @@ func (g reusingGetter) getWithGoGetter(ctx context.Context, instPath, packageAdd
 
 			Detectors:     goGetterNoDetectors, // our caller should've already done detection
 			Decompressors: goGetterDecompressors,
-			Getters:       goGetterGetters,
+			Getters:       getters,
 			Ctx:           ctx,
 		}
 		err = client.Get()
@@ -0,0 +1,332 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package getmodules
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	getter "github.com/hashicorp/go-getter"
+)
+
+// gitGetter is a go-getter Getter for git repositories which supports some
+// arguments in addition to those of go-getter's own git getter, which it
+// delegates to for source addresses that use none of them:
+//
+//   - "sparse" is a comma-separated list of directories in the repository,
+//     and only those directories are checked out, with the other files'
+//     contents never downloaded if the server supports partial clones.
+//   - "submodules" can be set to "false" to skip initializing submodules.
+//   - "commit" is a full commit ID that the checked-out "ref" must resolve
+//     to, so that a tag or branch which has been moved is an error.
+//
+// It also handles a "depth" combined with a "ref" that is a full commit ID
+// itself, because go-getter can only make shallow clones of branches and
+// tags.
+type gitGetter struct {
+	fallback getter.Getter
+
+	// ctx is the context of the go-getter client, set by SetClient.
+	ctx context.Context
+}
+
+var _ getter.Getter = (*gitGetter)(nil)
+
+// gitCommitIDPattern matches full SHA-1 and SHA-256 commit IDs.
+var gitCommitIDPattern = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// gitAbbrevCommitIDPattern matches strings that are likely to be abbreviated
+// or full commit IDs rather than branch or tag names, as go-getter does.
+var gitAbbrevCommitIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{7,64}$`)
+
+func (g *gitGetter) SetClient(c *getter.Client) {
+	g.ctx = c.Ctx
+	g.fallback.SetClient(c)
+}
+
+func (g *gitGetter) ClientMode(*url.URL) (getter.ClientMode, error) {
+	return getter.ClientModeDir, nil
+}
+
+func (g *gitGetter) GetFile(dst string, u *url.URL) error {
+	return g.fallback.GetFile(dst, u)
+}
+
+func (g *gitGetter) Get(dst string, u *url.URL) error {
+	opts, err := parseGitOptions(u)
+	if err != nil {
+		return err
+	}
+	if !opts.needsGitGetter() {
+		return g.fallback.Get(dst, u)
+	}
+	ctx := g.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git must be available and on the PATH")
+	}
+	return opts.fetch(ctx, dst)
+}
+
+// gitOptions are the settings of a git source address.
+type gitOptions struct {
+	// remote is the repository URL, without the arguments below.
+	remote string
+
+	ref        string
+	depth      int
+	sshKey     []byte
+	sparse     []string
+	submodules bool
+	commit     string
+}
+
+func parseGitOptions(u *url.URL) (*gitOptions, error) {
+	opts := &gitOptions{submodules: true}
+	query := u.Query()
+	opts.ref = query.Get("ref")
+	if v := query.Get("depth"); v != "" {
+		// go-getter ignores an invalid depth, so we do too, for
+		// compatibility with source addresses that it accepts.
+		opts.depth, _ = strconv.Atoi(v)
+	}
+	if v := query.Get("sshkey"); v != "" {
+		key, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sshkey argument: %w", err)
+		}
+		opts.sshKey = key
+	}
+	if _, ok := query["sparse"]; ok {
+		for _, dir := range strings.Split(query.Get("sparse"), ",") {
+			dir = strings.Trim(dir, "/")
+			if dir == "" || dir != path.Clean(dir) || dir == ".." || strings.HasPrefix(dir, "../") {
+				return nil, fmt.Errorf("invalid sparse argument %q: must be a comma-separated list of directories in the repository", query.Get("sparse"))
+			}
+			opts.sparse = append(opts.sparse, dir)
+		}
+	}
+	if v, ok := query["submodules"]; ok {
+		submodules, err := strconv.ParseBool(v[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid submodules argument %q: must be true or false", v[0])
+		}
+		opts.submodules = submodules
+	}
+	if v, ok := query["commit"]; ok {
+		opts.commit = strings.ToLower(v[0])
+		if !gitCommitIDPattern.MatchString(opts.commit) {
+			return nil, fmt.Errorf("invalid commit argument %q: must be a full commit ID", v[0])
+		}
+	}
+
+	for _, arg := range []string{"ref", "depth", "sshkey", "sparse", "submodules", "commit"} {
+		query.Del(arg)
+	}
+	remote := *u
+	remote.RawQuery = query.Encode()
+	opts.remote = remote.String()
+	return opts, nil
+}
+
+// needsGitGetter returns true if the options need gitGetter's own
+// implementation rather than go-getter's.
+func (o *gitOptions) needsGitGetter() bool {
+	shallowCommit := o.depth > 0 && gitAbbrevCommitIDPattern.MatchString(o.ref)
+	return len(o.sparse) > 0 || !o.submodules || o.commit != "" || shallowCommit
+}
+
+// fetch creates a repository in dst with only the selected ref checked out,
+// fetching no more history and file contents than it needs.
+func (o *gitOptions) fetch(ctx context.Context, dst string) error {
+	if err := os.RemoveAll(dst); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dst, os.ModePerm); err != nil {
+		return err
+	}
+
+	env := os.Environ()
+	if o.sshKey != nil {
+		f, err := os.CreateTemp("", "tofu-git-sshkey")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(f.Name())
+		if err := f.Chmod(0600); err != nil {
+			f.Close()
+			return err
+		}
+		_, err = f.Write(o.sshKey)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		sshCommand := os.Getenv("GIT_SSH_COMMAND")
+		if sshCommand == "" {
+			sshCommand = "ssh"
+		}
+		env = append(env, fmt.Sprintf("GIT_SSH_COMMAND=%s -i %s", sshCommand, strings.ReplaceAll(f.Name(), `\`, `/`)))
+	}
+	git := func(args ...string) (string, error) {
+		return runGit(ctx, dst, env, args...)
+	}
+
+	if _, err := git("init", "--quiet"); err != nil {
+		return err
+	}
+	if _, err := git("remote", "add", "origin", o.remote); err != nil {
+		return err
+	}
+
+	fetchArgs := []string{"fetch", "--quiet", "--no-tags"}
+	if o.depth > 0 {
+		fetchArgs = append(fetchArgs, "--depth", strconv.Itoa(o.depth))
+	}
+	if len(o.sparse) > 0 {
+		// Without the blob contents, checkout downloads only those of the
+		// sparse directories. Servers that don't support filters ignore
+		// this and send everything.
+		if _, err := git("config", "remote.origin.promisor", "true"); err != nil {
+			return err
+		}
+		if _, err := git("config", "remote.origin.partialclonefilter", "blob:none"); err != nil {
+			return err
+		}
+		fetchArgs = append(fetchArgs, "--filter=blob:none")
+		args := append([]string{"sparse-checkout", "set", "--cone"}, o.sparse...)
+		if _, err := git(args...); err != nil {
+			return err
+		}
+	}
+	ref := o.ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	refspecs, checkout := []string{ref}, "FETCH_HEAD"
+	if gitAbbrevCommitIDPattern.MatchString(ref) && !gitCommitIDPattern.MatchString(strings.ToLower(ref)) {
+		if o.depth > 0 {
+			return fmt.Errorf("a shallow clone of a commit requires 'ref' to be a full commit ID, not %q", ref)
+		}
+		// An abbreviated commit ID can't be fetched directly, so we fetch
+		// all branches and tags and then look for it among them.
+		refspecs = []string{"+refs/heads/*:refs/remotes/origin/*", "+refs/tags/*:refs/tags/*"}
+		checkout = ref
+	}
+	log.Printf("[TRACE] getmodules: fetching %s from %s into %s", ref, o.remote, dst)
+	fetchArgs = append(fetchArgs, "origin")
+	if _, err := git(append(fetchArgs, refspecs...)...); err != nil {
+		return err
+	}
+	if _, err := git("checkout", "--quiet", "--detach", checkout); err != nil {
+		return err
+	}
+
+	if o.commit != "" {
+		head, err := git("rev-parse", "HEAD")
+		if err != nil {
+			return err
+		}
+		if head != o.commit {
+			return fmt.Errorf("%s resolves to commit %s, but the source address requires commit %s", ref, head, o.commit)
+		}
+	}
+
+	if o.submodules {
+		args := []string{"submodule", "update", "--init", "--recursive"}
+		if o.depth > 0 {
+			args = append(args, "--depth", strconv.Itoa(o.depth))
+		}
+		if _, err := git(args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runGit runs git with the given arguments in the given directory, and
+// returns its output.
+func runGit(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w\n%s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// PartialPackageAddr returns the address to fetch only the given
+// subdirectory of the package at the given address, along with the files at
+// the root of the package, if the package's source supports that. Otherwise,
+// it returns the package address unchanged.
+//
+// Only git repositories support partial retrieval, using the "sparse"
+// argument of gitGetter, and only if the source address doesn't already
+// select its own sparse directories or use an SSH key, which would not be
+// available to WidenPartialPackage later.
+func PartialPackageAddr(packageAddr, subDir string) string {
+	subDir = path.Clean(subDir)
+	if subDir == "." || subDir == ".." || strings.HasPrefix(subDir, "../") || strings.ContainsAny(subDir, "*?[\\") {
+		// Subdirectory globs can only be expanded once the whole package
+		// is available.
+		return packageAddr
+	}
+	remote, ok := strings.CutPrefix(packageAddr, "git::")
+	if !ok {
+		return packageAddr
+	}
+	u, err := url.Parse(remote)
+	if err != nil || u.Scheme == "" {
+		return packageAddr
+	}
+	query := u.Query()
+	if query.Has("sparse") || query.Has("sshkey") {
+		return packageAddr
+	}
+	query.Set("sparse", subDir)
+	u.RawQuery = query.Encode()
+	return "git::" + u.String()
+}
+
+// WidenPartialPackage adds the given directory, relative to the root of the
+// package installed in packageDir, to the directories checked out there if
+// the package was fetched only partially, downloading the directory's files
+// as needed. It returns false without doing anything if the package was
+// fetched in full.
+func WidenPartialPackage(ctx context.Context, packageDir, dir string) (bool, error) {
+	if _, err := os.Stat(filepath.Join(packageDir, ".git")); err != nil {
+		return false, nil
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return false, nil
+	}
+	env := os.Environ()
+	if sparse, err := runGit(ctx, packageDir, env, "config", "--get", "core.sparseCheckout"); err != nil || sparse != "true" {
+		return false, nil
+	}
+	log.Printf("[TRACE] getmodules: adding %s to the partial package in %s", dir, packageDir)
+	if _, err := runGit(ctx, packageDir, env, "sparse-checkout", "add", filepath.ToSlash(dir)); err != nil {
+		return true, err
+	}
+	return true, nil
+}
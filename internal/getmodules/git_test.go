@@ -0,0 +1,280 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package getmodules
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	getter "github.com/hashicorp/go-getter"
+)
+
+func TestParseGitOptions(t *testing.T) {
+	commit := strings.Repeat("ab", 20)
+	tests := map[string]struct {
+		source        string
+		want          gitOptions
+		wantGitGetter bool
+		wantErr       string
+	}{
+		"go-getter arguments only": {
+			source: "https://example.com/network.git?ref=v1.2.0&depth=1",
+			want: gitOptions{
+				remote:     "https://example.com/network.git",
+				ref:        "v1.2.0",
+				depth:      1,
+				submodules: true,
+			},
+		},
+		"sparse": {
+			source: "https://example.com/network.git?ref=v1.2.0&sparse=modules/vpc,modules/subnets/",
+			want: gitOptions{
+				remote:     "https://example.com/network.git",
+				ref:        "v1.2.0",
+				sparse:     []string{"modules/vpc", "modules/subnets"},
+				submodules: true,
+			},
+			wantGitGetter: true,
+		},
+		"no submodules": {
+			source: "https://example.com/network.git?submodules=false",
+			want: gitOptions{
+				remote: "https://example.com/network.git",
+			},
+			wantGitGetter: true,
+		},
+		"commit": {
+			source: "https://example.com/network.git?ref=v1.2.0&commit=" + strings.ToUpper(commit),
+			want: gitOptions{
+				remote:     "https://example.com/network.git",
+				ref:        "v1.2.0",
+				submodules: true,
+				commit:     commit,
+			},
+			wantGitGetter: true,
+		},
+		"shallow commit": {
+			source: "https://example.com/network.git?ref=" + commit + "&depth=1",
+			want: gitOptions{
+				remote:     "https://example.com/network.git",
+				ref:        commit,
+				depth:      1,
+				submodules: true,
+			},
+			wantGitGetter: true,
+		},
+		"other arguments": {
+			source: "https://example.com/network.git?sparse=vpc&foo=bar",
+			want: gitOptions{
+				remote:     "https://example.com/network.git?foo=bar",
+				sparse:     []string{"vpc"},
+				submodules: true,
+			},
+			wantGitGetter: true,
+		},
+		"sparse outside the repository": {
+			source:  "https://example.com/network.git?sparse=../vpc",
+			wantErr: `invalid sparse argument "../vpc"`,
+		},
+		"empty sparse": {
+			source:  "https://example.com/network.git?sparse=",
+			wantErr: `invalid sparse argument ""`,
+		},
+		"invalid submodules": {
+			source:  "https://example.com/network.git?submodules=no",
+			wantErr: `invalid submodules argument "no"`,
+		},
+		"abbreviated commit": {
+			source:  "https://example.com/network.git?commit=abcdef0",
+			wantErr: `invalid commit argument "abcdef0": must be a full commit ID`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			u, err := url.Parse(test.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := parseGitOptions(u)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("wrong error %v; want an error containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.remote != test.want.remote || got.ref != test.want.ref || got.depth != test.want.depth ||
+				strings.Join(got.sparse, ",") != strings.Join(test.want.sparse, ",") ||
+				got.submodules != test.want.submodules || got.commit != test.want.commit {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, &test.want)
+			}
+			if got.needsGitGetter() != test.wantGitGetter {
+				t.Errorf("wrong needsGitGetter %t; want %t", got.needsGitGetter(), test.wantGitGetter)
+			}
+		})
+	}
+}
+
+func TestGitGetter(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	repo := t.TempDir()
+	runGit := func(t *testing.T, args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s failed: %s\n%s", args[0], err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	for name, content := range map[string]string{
+		"modules/vpc/main.tf":     "# vpc\n",
+		"modules/subnets/main.tf": "# subnets\n",
+		"examples/main.tf":        "# examples\n",
+	} {
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runGit(t, "init", "--quiet")
+	runGit(t, "config", "uploadpack.allowFilter", "true")
+	runGit(t, "add", ".")
+	runGit(t, "commit", "--quiet", "-m", "first")
+	runGit(t, "tag", "v1.0.0")
+	first := runGit(t, "rev-parse", "HEAD")
+	runGit(t, "commit", "--quiet", "--allow-empty", "-m", "second")
+	second := runGit(t, "rev-parse", "HEAD")
+
+	get := func(t *testing.T, args string) (string, error) {
+		t.Helper()
+		u, err := url.Parse("file://" + filepath.ToSlash(repo) + "?" + args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dst := filepath.Join(t.TempDir(), "module")
+		g := &gitGetter{fallback: new(getter.GitGetter)}
+		return dst, g.Get(dst, u)
+	}
+	exists := func(dst, name string) bool {
+		_, err := os.Stat(filepath.Join(dst, filepath.FromSlash(name)))
+		return err == nil
+	}
+
+	t.Run("sparse", func(t *testing.T) {
+		dst, err := get(t, "ref=v1.0.0&sparse=modules/vpc")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !exists(dst, "modules/vpc/main.tf") {
+			t.Errorf("modules/vpc/main.tf was not checked out")
+		}
+		if exists(dst, "modules/subnets/main.tf") || exists(dst, "examples/main.tf") {
+			t.Errorf("files outside the sparse directories were checked out")
+		}
+	})
+	t.Run("widen partial package", func(t *testing.T) {
+		dst, err := get(t, "ref=v1.0.0&sparse=modules/vpc")
+		if err != nil {
+			t.Fatal(err)
+		}
+		widened, err := WidenPartialPackage(context.Background(), dst, filepath.FromSlash("modules/subnets"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !widened || !exists(dst, "modules/subnets/main.tf") {
+			t.Errorf("modules/subnets/main.tf was not checked out")
+		}
+		if exists(dst, "examples/main.tf") {
+			t.Errorf("files outside the sparse directories were checked out")
+		}
+
+		// A package fetched in full is left alone.
+		dst, err = get(t, "ref=v1.0.0&submodules=false")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if widened, err := WidenPartialPackage(context.Background(), dst, "examples"); widened || err != nil {
+			t.Errorf("full package was widened: %t, %v", widened, err)
+		}
+	})
+	t.Run("matching commit", func(t *testing.T) {
+		if _, err := get(t, "ref=v1.0.0&commit="+first); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("moved ref", func(t *testing.T) {
+		_, err := get(t, "commit="+first)
+		if err == nil || !strings.Contains(err.Error(), "HEAD resolves to commit "+second) {
+			t.Fatalf("wrong error %v", err)
+		}
+	})
+	t.Run("shallow commit", func(t *testing.T) {
+		dst, err := get(t, "ref="+first+"&depth=1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !exists(dst, ".git/shallow") {
+			t.Errorf("the clone is not shallow")
+		}
+		if !exists(dst, "examples/main.tf") {
+			t.Errorf("examples/main.tf was not checked out")
+		}
+	})
+	t.Run("abbreviated commit", func(t *testing.T) {
+		if _, err := get(t, "ref="+first[:10]+"&submodules=false"); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestPartialPackageAddr(t *testing.T) {
+	tests := []struct {
+		packageAddr, subDir string
+		want                string
+	}{
+		{
+			"git::https://example.com/network.git?ref=v1.2.0",
+			"modules/vpc",
+			"git::https://example.com/network.git?ref=v1.2.0&sparse=modules%2Fvpc",
+		},
+		{
+			"git::ssh://git@example.com/network.git",
+			"./modules/vpc/",
+			"git::ssh://git@example.com/network.git?sparse=modules%2Fvpc",
+		},
+		// The whole package is needed for these.
+		{"git::https://example.com/network.git", "", "git::https://example.com/network.git"},
+		{"git::https://example.com/network.git", "modules/*", "git::https://example.com/network.git"},
+		{"git::https://example.com/network.git?sparse=modules", "modules/vpc", "git::https://example.com/network.git?sparse=modules"},
+		{"git::https://example.com/network.git?sshkey=a2V5", "modules/vpc", "git::https://example.com/network.git?sshkey=a2V5"},
+		// Other sources don't support partial retrieval.
+		{"https://example.com/network.zip", "modules/vpc", "https://example.com/network.zip"},
+		{"hg::https://example.com/network", "modules/vpc", "hg::https://example.com/network"},
+	}
+	for _, test := range tests {
+		t.Run(test.packageAddr+"//"+test.subDir, func(t *testing.T) {
+			if got := PartialPackageAddr(test.packageAddr, test.subDir); got != test.want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.want)
+			}
+		})
+	}
+}
@@ -5,6 +5,8 @@ package getmodules
 
 import (
 	"context"
+
+	getter "github.com/hashicorp/go-getter"
 )
 
 // PackageFetcher is a low-level utility for fetching remote module packages
@@ -21,15 +23,55 @@ import (
 // no way to reset this cache, so a particular PackageFetcher instance should
 // live only for the duration of a single initialization process.
 type PackageFetcher struct {
-	getter reusingGetter
+	getter  reusingGetter
+	getters map[string]getter.Getter
+	oci     *ociGetter
+
+	// objectStorage are the getters for the object storage services that
+	// can use the state backend's client, keyed by go-getter scheme.
+	objectStorage map[string]*objectStorageGetter
 }
 
 func NewPackageFetcher() *PackageFetcher {
+	oci := newOCIGetter()
+	objectStorage := map[string]*objectStorageGetter{
+		"s3":  {backend: "s3", fallback: goGetterGetters["s3"], parse: parseS3Object},
+		"gcs": {backend: "gcs", fallback: goGetterGetters["gcs"], parse: parseGCSObject},
+	}
+	getters := make(map[string]getter.Getter, len(goGetterGetters)+1)
+	for scheme, g := range goGetterGetters {
+		getters[scheme] = g
+	}
+	for scheme, g := range objectStorage {
+		getters[scheme] = g
+	}
+	getters["git"] = &gitGetter{fallback: goGetterGetters["git"]}
+	getters["oci"] = oci
 	return &PackageFetcher{
-		getter: reusingGetter{},
+		getter:        reusingGetter{},
+		getters:       getters,
+		oci:           oci,
+		objectStorage: objectStorage,
 	}
 }
 
+// SetBackendStorage sets the client of the state backend's object storage
+// service, whose go-getter scheme is "s3" or "gcs", which the sources of
+// that scheme with the "credentials=backend" argument use instead of the
+// ambient credentials.
+func (f *PackageFetcher) SetBackendStorage(scheme string, storage ObjectStorage) {
+	if g, ok := f.objectStorage[scheme]; ok {
+		g.storage = storage
+	}
+}
+
+// SetOCISigningKeys sets the keys that are trusted to sign module packages
+// in OCI repositories. A package from a repository that any of the keys are
+// trusted for can only be installed if it is signed by one of them.
+func (f *PackageFetcher) SetOCISigningKeys(keys []*OCISigningKeys) {
+	f.oci.signingKeys = keys
+}
+
 // FetchPackage downloads or otherwise retrieves the filesystem inside the
 // package at the given address into the given local installation directory.
 //
@@ -43,5 +85,5 @@ func NewPackageFetcher() *PackageFetcher {
 // caller must resolve that itself, possibly with the help of the
 // getmodules.SplitPackageSubdir and getmodules.ExpandSubdirGlobs functions.
 func (f *PackageFetcher) FetchPackage(ctx context.Context, instDir string, packageAddr string) error {
-	return f.getter.getWithGoGetter(ctx, instDir, packageAddr)
+	return f.getter.getWithGoGetter(ctx, instDir, packageAddr, f.getters)
 }
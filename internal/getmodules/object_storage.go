@@ -0,0 +1,217 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package getmodules
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	getter "github.com/hashicorp/go-getter"
+)
+
+// ObjectStorage is an object storage service, like Amazon S3 or Google Cloud
+// Storage, accessed through a client that was configured elsewhere, such as
+// for a state backend.
+type ObjectStorage interface {
+	// ListObjects returns the keys of the objects in the given bucket whose
+	// keys start with the given prefix.
+	ListObjects(ctx context.Context, bucket, prefix string) ([]string, error)
+
+	// GetObject writes the content of the given object to w.
+	GetObject(ctx context.Context, bucket, key string, w io.Writer) error
+}
+
+// backendCredentialsArg is the argument of "s3::" and "gcs::" source
+// addresses which, when set to "backend", selects the state backend's
+// client instead of the ambient credentials that go-getter uses.
+const backendCredentialsArg = "credentials"
+
+// objectStorageGetter is a go-getter Getter for an object storage service
+// which installs module packages using the state backend's client if the
+// source address asks for it, and otherwise delegates to go-getter's own
+// getter for the service.
+type objectStorageGetter struct {
+	// backend is the type of state backend which uses the same service,
+	// like "s3".
+	backend  string
+	fallback getter.Getter
+
+	// parse returns the bucket and key of the object or directory that a
+	// source address refers to.
+	parse func(u *url.URL) (bucket, key string, err error)
+
+	// storage is the state backend's client, or nil if the backend doesn't
+	// use this service.
+	storage ObjectStorage
+
+	// ctx is the context of the go-getter client, set by SetClient.
+	ctx context.Context
+}
+
+var _ getter.Getter = (*objectStorageGetter)(nil)
+
+func (g *objectStorageGetter) SetClient(c *getter.Client) {
+	g.ctx = c.Ctx
+	g.fallback.SetClient(c)
+}
+
+func (g *objectStorageGetter) ClientMode(u *url.URL) (getter.ClientMode, error) {
+	bucket, key, ok, err := g.backendObject(u)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return g.fallback.ClientMode(u)
+	}
+	keys, err := g.storage.ListObjects(g.context(), bucket, key)
+	if err != nil {
+		return 0, err
+	}
+	for _, k := range keys {
+		if k == key {
+			return getter.ClientModeFile, nil
+		}
+	}
+	return getter.ClientModeDir, nil
+}
+
+func (g *objectStorageGetter) Get(dst string, u *url.URL) error {
+	bucket, key, ok, err := g.backendObject(u)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return g.fallback.Get(dst, u)
+	}
+
+	prefix := strings.TrimSuffix(key, "/") + "/"
+	keys, err := g.storage.ListObjects(g.context(), bucket, prefix)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("there are no objects under %s in the bucket %s", prefix, bucket)
+	}
+	if err := os.MkdirAll(dst, os.ModePerm); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		rel := strings.TrimPrefix(k, prefix)
+		if rel == "" || strings.HasSuffix(rel, "/") {
+			// Some tools create empty objects to represent directories.
+			continue
+		}
+		if !filepath.IsLocal(filepath.FromSlash(rel)) {
+			return fmt.Errorf("the object %s in the bucket %s has an invalid key for a module package file", k, bucket)
+		}
+		log.Printf("[TRACE] getmodules: fetching object %s from the bucket %s with the state backend's credentials", k, bucket)
+		if err := g.getObject(bucket, k, filepath.Join(dst, filepath.FromSlash(rel))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *objectStorageGetter) GetFile(dst string, u *url.URL) error {
+	bucket, key, ok, err := g.backendObject(u)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return g.fallback.GetFile(dst, u)
+	}
+	log.Printf("[TRACE] getmodules: fetching object %s from the bucket %s with the state backend's credentials", key, bucket)
+	return g.getObject(bucket, key, dst)
+}
+
+func (g *objectStorageGetter) getObject(bucket, key, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	err = g.storage.GetObject(g.context(), bucket, key, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch the object %s from the bucket %s: %w", key, bucket, err)
+	}
+	return nil
+}
+
+// backendObject returns the bucket and key that the given source address
+// refers to if it selects the state backend's credentials, or false if it
+// should be installed by the fallback getter instead.
+func (g *objectStorageGetter) backendObject(u *url.URL) (bucket, key string, ok bool, err error) {
+	query := u.Query()
+	if _, set := query[backendCredentialsArg]; !set {
+		return "", "", false, nil
+	}
+	if v := query.Get(backendCredentialsArg); v != "backend" {
+		return "", "", false, fmt.Errorf("unsupported %s argument %q in module source address: the only supported value is \"backend\"", backendCredentialsArg, v)
+	}
+	for arg := range query {
+		if arg != backendCredentialsArg {
+			return "", "", false, fmt.Errorf("the %q argument can't be used in a module source address that uses the state backend's credentials", arg)
+		}
+	}
+	if g.storage == nil {
+		return "", "", false, fmt.Errorf("the module source address asks for the state backend's credentials, but the state backend is not %q, or is not available to this command", g.backend)
+	}
+	bucket, key, err = g.parse(u)
+	if err != nil {
+		return "", "", false, err
+	}
+	return bucket, key, true, nil
+}
+
+func (g *objectStorageGetter) context() context.Context {
+	if g.ctx == nil {
+		return context.Background()
+	}
+	return g.ctx
+}
+
+// parseS3Object returns the bucket and key of an "s3::" source address in
+// any of the path-style or virtual-hosted-style URL forms that go-getter
+// accepts. The region in the hostname is ignored, because the state
+// backend's client has its own region and endpoint.
+func parseS3Object(u *url.URL) (bucket, key string, err error) {
+	path := strings.TrimPrefix(u.Path, "/")
+	hostParts := strings.Split(u.Hostname(), ".")
+	if strings.HasSuffix(u.Hostname(), ".amazonaws.com") && len(hostParts) > 3 {
+		// Virtual-hosted-style, like bucket.s3.eu-west-1.amazonaws.com/key
+		bucket, key = hostParts[0], path
+	} else {
+		// Path-style, like s3.amazonaws.com/bucket/key, or a service that
+		// mimics the S3 API.
+		bucket, key, _ = strings.Cut(path, "/")
+	}
+	if bucket == "" || key == "" {
+		return "", "", fmt.Errorf("invalid S3 module source address %q: must include a bucket name and an object key", u.Redacted())
+	}
+	return bucket, key, nil
+}
+
+// parseGCSObject returns the bucket and object name of a "gcs::" source
+// address like https://www.googleapis.com/storage/v1/bucket/path.
+func parseGCSObject(u *url.URL) (bucket, key string, err error) {
+	path, ok := strings.CutPrefix(u.Path, "/storage/v1/")
+	if ok {
+		bucket, key, _ = strings.Cut(path, "/")
+	}
+	if bucket == "" || key == "" {
+		return "", "", fmt.Errorf("invalid GCS module source address %q: must be like https://www.googleapis.com/storage/v1/BUCKET/PATH", u.Redacted())
+	}
+	return bucket, key, nil
+}
@@ -0,0 +1,204 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package getmodules
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	getter "github.com/hashicorp/go-getter"
+)
+
+func TestParseS3Object(t *testing.T) {
+	tests := map[string]struct {
+		source     string
+		wantBucket string
+		wantKey    string
+	}{
+		"path-style": {
+			source:     "https://s3.amazonaws.com/examplecorp-modules/vpc.zip",
+			wantBucket: "examplecorp-modules",
+			wantKey:    "vpc.zip",
+		},
+		"path-style with region": {
+			source:     "https://s3-eu-west-1.amazonaws.com/examplecorp-modules/network/vpc",
+			wantBucket: "examplecorp-modules",
+			wantKey:    "network/vpc",
+		},
+		"virtual-hosted-style": {
+			source:     "https://examplecorp-modules.s3.eu-west-1.amazonaws.com/vpc.zip",
+			wantBucket: "examplecorp-modules",
+			wantKey:    "vpc.zip",
+		},
+		"S3-compatible service": {
+			source:     "https://minio.example.com/examplecorp-modules/vpc.zip",
+			wantBucket: "examplecorp-modules",
+			wantKey:    "vpc.zip",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			u, err := url.Parse(test.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bucket, key, err := parseS3Object(u)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bucket != test.wantBucket || key != test.wantKey {
+				t.Errorf("wrong result %q, %q; want %q, %q", bucket, key, test.wantBucket, test.wantKey)
+			}
+		})
+	}
+
+	u, _ := url.Parse("https://s3.amazonaws.com/examplecorp-modules")
+	if _, _, err := parseS3Object(u); err == nil {
+		t.Errorf("succeeded without an object key")
+	}
+}
+
+func TestParseGCSObject(t *testing.T) {
+	u, _ := url.Parse("https://www.googleapis.com/storage/v1/examplecorp-modules/network/vpc.zip")
+	bucket, key, err := parseGCSObject(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bucket != "examplecorp-modules" || key != "network/vpc.zip" {
+		t.Errorf("wrong result %q, %q", bucket, key)
+	}
+
+	u, _ = url.Parse("https://www.googleapis.com/examplecorp-modules/vpc.zip")
+	if _, _, err := parseGCSObject(u); err == nil {
+		t.Errorf("succeeded without the storage API path")
+	}
+}
+
+func TestObjectStorageGetter(t *testing.T) {
+	storage := testObjectStorage{
+		"modules/network/main.tf":         "# network\n",
+		"modules/network/vpc/main.tf":     "# vpc\n",
+		"modules/network/vpc/":            "",
+		"modules/network-legacy/main.tf":  "# legacy\n",
+		"modules/network/subnets/main.tf": "# subnets\n",
+	}
+	newGetter := func(storage ObjectStorage) *objectStorageGetter {
+		return &objectStorageGetter{
+			backend:  "s3",
+			fallback: new(getter.S3Getter),
+			parse:    parseS3Object,
+			storage:  storage,
+		}
+	}
+	mustParse := func(t *testing.T, s string) *url.URL {
+		t.Helper()
+		u, err := url.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+
+	t.Run("directory", func(t *testing.T) {
+		dst := filepath.Join(t.TempDir(), "module")
+		u := mustParse(t, "https://s3.amazonaws.com/bucket/modules/network?credentials=backend")
+		g := newGetter(storage)
+		if mode, err := g.ClientMode(u); err != nil || mode != getter.ClientModeDir {
+			t.Errorf("wrong client mode %v, %v", mode, err)
+		}
+		if err := g.Get(dst, u); err != nil {
+			t.Fatal(err)
+		}
+		for name, want := range map[string]string{
+			"main.tf":         "# network\n",
+			"vpc/main.tf":     "# vpc\n",
+			"subnets/main.tf": "# subnets\n",
+		} {
+			got, err := os.ReadFile(filepath.Join(dst, filepath.FromSlash(name)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != want {
+				t.Errorf("wrong content of %s: %q", name, got)
+			}
+		}
+		entries, err := os.ReadDir(dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 3 {
+			// modules/network-legacy has the same key prefix, but isn't in
+			// the directory.
+			t.Errorf("wrong number of entries %d; want 3", len(entries))
+		}
+	})
+	t.Run("file", func(t *testing.T) {
+		dst := filepath.Join(t.TempDir(), "main.tf")
+		u := mustParse(t, "https://s3.amazonaws.com/bucket/modules/network/main.tf?credentials=backend")
+		g := newGetter(storage)
+		if mode, err := g.ClientMode(u); err != nil || mode != getter.ClientModeFile {
+			t.Errorf("wrong client mode %v, %v", mode, err)
+		}
+		if err := g.GetFile(dst, u); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := os.ReadFile(dst); string(got) != "# network\n" {
+			t.Errorf("wrong content %q", got)
+		}
+	})
+	t.Run("no backend", func(t *testing.T) {
+		u := mustParse(t, "https://s3.amazonaws.com/bucket/modules/network?credentials=backend")
+		err := newGetter(nil).Get(t.TempDir(), u)
+		if err == nil || !strings.Contains(err.Error(), `the state backend is not "s3"`) {
+			t.Errorf("wrong error %v", err)
+		}
+	})
+	t.Run("invalid arguments", func(t *testing.T) {
+		for source, wantErr := range map[string]string{
+			"https://s3.amazonaws.com/bucket/modules/network?credentials=env":                      `unsupported credentials argument "env"`,
+			"https://s3.amazonaws.com/bucket/modules/network?credentials=backend&region=us-east-1": `the "region" argument can't be used`,
+		} {
+			err := newGetter(storage).Get(t.TempDir(), mustParse(t, source))
+			if err == nil || !strings.Contains(err.Error(), wantErr) {
+				t.Errorf("wrong error for %s: %v", source, err)
+			}
+		}
+	})
+	t.Run("empty directory", func(t *testing.T) {
+		u := mustParse(t, "https://s3.amazonaws.com/bucket/modules/missing?credentials=backend")
+		err := newGetter(storage).Get(t.TempDir(), u)
+		if err == nil || !strings.Contains(err.Error(), "there are no objects under modules/missing/") {
+			t.Errorf("wrong error %v", err)
+		}
+	})
+}
+
+// testObjectStorage is an ObjectStorage with a single bucket, whose objects
+// are keyed by their keys.
+type testObjectStorage map[string]string
+
+func (s testObjectStorage) ListObjects(_ context.Context, _, prefix string) ([]string, error) {
+	var keys []string
+	for key := range s {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (s testObjectStorage) GetObject(_ context.Context, _, key string, w io.Writer) error {
+	content, ok := s[key]
+	if !ok {
+		return fmt.Errorf("no such object %s", key)
+	}
+	_, err := io.WriteString(w, content)
+	return err
+}
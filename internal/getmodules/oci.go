@@ -0,0 +1,217 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package getmodules
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	getter "github.com/hashicorp/go-getter"
+
+	"github.com/opentofu/opentofu/internal/ociclient"
+)
+
+// The media types of the artifacts that OpenTofu looks for in an OCI
+// registry. A module package is a manifest with a layer containing a
+// gzip-compressed tar archive of the package. Packages pushed as ordinary
+// image layers are accepted too, so that generic tools can publish them.
+const (
+	OCIModulePackageLayerMediaType = "application/vnd.opentofu.modulepkg.v1.tar+gzip"
+	ociImageLayerMediaType         = "application/vnd.oci.image.layer.v1.tar+gzip"
+
+	// OCIModuleSignatureArtifactType is the artifact type of a manifest which
+	// refers to the manifest of a module package, and signs it with an
+	// ASCII-armored detached OpenPGP signature of the package manifest's
+	// digest string, like "sha256:abc...", in a layer with the media type
+	// OCIModuleSignatureLayerMediaType.
+	OCIModuleSignatureArtifactType   = "application/vnd.opentofu.modulepkg.signature.v1"
+	OCIModuleSignatureLayerMediaType = "application/pgp-signature"
+)
+
+// OCISigningKeys are OpenPGP public keys that are trusted to sign the module
+// packages in the OCI repositories whose addresses match a pattern.
+type OCISigningKeys struct {
+	// Pattern is matched against repository addresses like
+	// "ghcr.io/example/modules/vpc" using the syntax of path.Match, so
+	// "*" matches a single path segment.
+	Pattern string
+
+	Keys openpgp.EntityList
+}
+
+// ociGetter is a go-getter Getter that installs module packages from
+// repositories in OCI registries, for source addresses like
+// "oci://ghcr.io/example/modules/vpc?tag=1.0.0".
+//
+// The package is the manifest with the tag given in the "tag" argument, or
+// "latest" by default, or with the digest given in the "digest" argument.
+// If signing keys are trusted for the repository, the package must have a
+// signature made with one of them.
+type ociGetter struct {
+	client      *ociclient.Client
+	signingKeys []*OCISigningKeys
+
+	// ctx is the context of the go-getter client, set by SetClient.
+	ctx context.Context
+}
+
+var _ getter.Getter = (*ociGetter)(nil)
+
+func newOCIGetter() *ociGetter {
+	return &ociGetter{
+		client: ociclient.NewClient(ociclient.NewDockerCredentialsSource()),
+	}
+}
+
+func (g *ociGetter) ClientMode(*url.URL) (getter.ClientMode, error) {
+	return getter.ClientModeDir, nil
+}
+
+func (g *ociGetter) SetClient(c *getter.Client) {
+	g.ctx = c.Ctx
+}
+
+func (g *ociGetter) GetFile(string, *url.URL) error {
+	return fmt.Errorf("OCI module sources can only be installed as whole packages")
+}
+
+func (g *ociGetter) Get(dst string, u *url.URL) error {
+	ctx := g.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	repo, reference, err := parseOCIModuleSource(u)
+	if err != nil {
+		return err
+	}
+
+	var manifest ociclient.Manifest
+	digest, err := g.client.GetManifest(ctx, repo, reference, ociclient.ManifestMediaType, &manifest)
+	if err == ociclient.ErrNotFound {
+		return fmt.Errorf("the OCI repository %s has no manifest %s", repo, reference)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to retrieve %s from %s: %w", reference, repo, err)
+	}
+	if err := g.verifySignature(ctx, repo, digest); err != nil {
+		return err
+	}
+
+	layer, err := ociModulePackageLayer(manifest)
+	if err != nil {
+		return fmt.Errorf("invalid module package %s in %s: %w", reference, repo, err)
+	}
+	log.Printf("[TRACE] getmodules: fetching layer %s of %s in %s", layer.Digest, digest, repo)
+
+	f, err := os.CreateTemp("", "tofu-oci-module")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	err = g.client.CopyBlob(ctx, repo, layer.Digest, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download the module package from %s: %w", repo, err)
+	}
+	return new(getter.TarGzipDecompressor).Decompress(dst, f.Name(), true, 0)
+}
+
+// verifySignature checks that the manifest with the given digest is signed
+// by one of the keys trusted for the repository, if there are any.
+func (g *ociGetter) verifySignature(ctx context.Context, repo ociclient.Repository, digest string) error {
+	var keyring openpgp.EntityList
+	for _, keys := range g.signingKeys {
+		if ok, _ := path.Match(keys.Pattern, repo.String()); ok {
+			keyring = append(keyring, keys.Keys...)
+		}
+	}
+	if len(keyring) == 0 {
+		log.Printf("[DEBUG] getmodules: no signing keys are trusted for %s, so not verifying the signature of %s", repo, digest)
+		return nil
+	}
+
+	referrers, err := g.client.Referrers(ctx, repo, digest, OCIModuleSignatureArtifactType)
+	if err != nil {
+		return fmt.Errorf("failed to find signatures of %s in %s: %w", digest, repo, err)
+	}
+	for _, desc := range referrers {
+		var manifest ociclient.Manifest
+		if _, err := g.client.GetManifest(ctx, repo, desc.Digest, ociclient.ManifestMediaType, &manifest); err != nil {
+			return fmt.Errorf("failed to retrieve signature %s from %s: %w", desc.Digest, repo, err)
+		}
+		for _, layer := range manifest.Layers {
+			if layer.MediaType != OCIModuleSignatureLayerMediaType {
+				continue
+			}
+			signature, err := g.client.GetBlob(ctx, repo, layer.Digest)
+			if err != nil {
+				return fmt.Errorf("failed to retrieve signature %s from %s: %w", desc.Digest, repo, err)
+			}
+			signer, err := openpgp.CheckArmoredDetachedSignature(keyring, strings.NewReader(digest), bytes.NewReader(signature), nil)
+			if err != nil {
+				log.Printf("[DEBUG] getmodules: signature %s of %s is not valid for the trusted keys: %s", desc.Digest, digest, err)
+				continue
+			}
+			log.Printf("[DEBUG] getmodules: %s in %s is signed by key ID %X", digest, repo, signer.PrimaryKey.KeyId)
+			return nil
+		}
+	}
+	return fmt.Errorf("the module package %s in %s is not signed by any of the keys that the CLI configuration trusts for it", digest, repo)
+}
+
+// parseOCIModuleSource returns the repository and the tag or digest of the
+// manifest that an "oci:" module source address refers to.
+func parseOCIModuleSource(u *url.URL) (ociclient.Repository, string, error) {
+	repo, err := ociclient.ParseRepository(u.Host + u.Path)
+	if err != nil {
+		return ociclient.Repository{}, "", err
+	}
+	query := u.Query()
+	tag, digest := query.Get("tag"), query.Get("digest")
+	for arg := range query {
+		if arg != "tag" && arg != "digest" {
+			return ociclient.Repository{}, "", fmt.Errorf("unsupported argument %q in OCI module source address: only \"tag\" and \"digest\" are supported", arg)
+		}
+	}
+	switch {
+	case tag != "" && digest != "":
+		return ociclient.Repository{}, "", fmt.Errorf("an OCI module source address can't have both a tag and a digest")
+	case digest != "":
+		if _, err := ociclient.DigestSHA256(digest); err != nil {
+			return ociclient.Repository{}, "", err
+		}
+		return repo, digest, nil
+	case tag != "":
+		return repo, tag, nil
+	default:
+		return repo, "latest", nil
+	}
+}
+
+// ociModulePackageLayer returns the layer of the given manifest that
+// contains the module package.
+func ociModulePackageLayer(manifest ociclient.Manifest) (ociclient.Descriptor, error) {
+	var images []ociclient.Descriptor
+	for _, layer := range manifest.Layers {
+		switch layer.MediaType {
+		case OCIModulePackageLayerMediaType:
+			return layer, nil
+		case ociImageLayerMediaType:
+			images = append(images, layer)
+		}
+	}
+	if len(images) != 1 {
+		return ociclient.Descriptor{}, fmt.Errorf("the manifest must have a layer of type %s, or exactly one layer of type %s", OCIModulePackageLayerMediaType, ociImageLayerMediaType)
+	}
+	return images[0], nil
+}
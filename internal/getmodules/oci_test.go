@@ -0,0 +1,302 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package getmodules
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+
+	"github.com/opentofu/opentofu/internal/ociclient"
+)
+
+func TestParseOCIModuleSource(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("ab", sha256.Size)
+	tests := map[string]struct {
+		source        string
+		wantRepo      string
+		wantReference string
+		wantErr       string
+	}{
+		"default tag": {
+			source:        "oci://ghcr.io/example/modules/vpc",
+			wantRepo:      "ghcr.io/example/modules/vpc",
+			wantReference: "latest",
+		},
+		"tag": {
+			source:        "oci://ghcr.io/example/modules/vpc?tag=1.0.0",
+			wantRepo:      "ghcr.io/example/modules/vpc",
+			wantReference: "1.0.0",
+		},
+		"digest": {
+			source:        "oci://ghcr.io/example/modules/vpc?digest=" + digest,
+			wantRepo:      "ghcr.io/example/modules/vpc",
+			wantReference: digest,
+		},
+		"tag and digest": {
+			source:  "oci://ghcr.io/example/modules/vpc?tag=1.0.0&digest=" + digest,
+			wantErr: "can't have both a tag and a digest",
+		},
+		"invalid digest": {
+			source:  "oci://ghcr.io/example/modules/vpc?digest=sha256:abc",
+			wantErr: `invalid digest "sha256:abc"`,
+		},
+		"unsupported argument": {
+			source:  "oci://ghcr.io/example/modules/vpc?ref=main",
+			wantErr: `unsupported argument "ref"`,
+		},
+		"no repository": {
+			source:  "oci://ghcr.io",
+			wantErr: "invalid OCI repository address",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			u, err := url.Parse(test.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			repo, reference, err := parseOCIModuleSource(u)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("wrong error %v; want an error containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := repo.String(); got != test.wantRepo {
+				t.Errorf("wrong repository %q; want %q", got, test.wantRepo)
+			}
+			if reference != test.wantReference {
+				t.Errorf("wrong reference %q; want %q", reference, test.wantReference)
+			}
+		})
+	}
+}
+
+func TestOCIGetterGet(t *testing.T) {
+	registry := newTestOCIModuleRegistry(t)
+	manifestDigest := registry.pushPackage(t, "1.0.0", map[string]string{"main.tf": "# vpc\n"})
+	trusted := newTestOCISigningKey(t)
+	untrusted := newTestOCISigningKey(t)
+	registry.pushSignature(t, manifestDigest, untrusted)
+	source := "oci://" + strings.TrimPrefix(registry.server.URL, "http://") + "/example/vpc"
+
+	get := func(t *testing.T, source string, keys []*OCISigningKeys) (string, error) {
+		t.Helper()
+		g := newOCIGetter()
+		g.client.Scheme = "http"
+		g.signingKeys = keys
+		u, err := url.Parse(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dst := filepath.Join(t.TempDir(), "module")
+		return dst, g.Get(dst, u)
+	}
+	signingKeys := func(pattern string, entity *openpgp.Entity) []*OCISigningKeys {
+		return []*OCISigningKeys{{Pattern: pattern, Keys: openpgp.EntityList{entity}}}
+	}
+	repoPattern := strings.TrimPrefix(registry.server.URL, "http://") + "/example/*"
+
+	t.Run("unsigned", func(t *testing.T) {
+		dst, err := get(t, source+"?tag=1.0.0", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(filepath.Join(dst, "main.tf"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != "# vpc\n" {
+			t.Errorf("wrong content %q", got)
+		}
+	})
+	t.Run("digest", func(t *testing.T) {
+		if _, err := get(t, source+"?digest="+manifestDigest, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("missing tag", func(t *testing.T) {
+		_, err := get(t, source+"?tag=2.0.0", nil)
+		if err == nil || !strings.Contains(err.Error(), "has no manifest 2.0.0") {
+			t.Fatalf("wrong error %v", err)
+		}
+	})
+	t.Run("signed by untrusted key", func(t *testing.T) {
+		_, err := get(t, source+"?tag=1.0.0", signingKeys(repoPattern, trusted))
+		if err == nil || !strings.Contains(err.Error(), "is not signed by any of the keys") {
+			t.Fatalf("wrong error %v", err)
+		}
+	})
+	t.Run("signed by trusted key", func(t *testing.T) {
+		registry.pushSignature(t, manifestDigest, trusted)
+		if _, err := get(t, source+"?tag=1.0.0", signingKeys(repoPattern, trusted)); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("keys for other repositories", func(t *testing.T) {
+		if _, err := get(t, source+"?tag=1.0.0", signingKeys("ghcr.io/*", untrusted)); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func newTestOCISigningKey(t *testing.T) *openpgp.Entity {
+	t.Helper()
+	entity, err := openpgp.NewEntity("Test", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entity
+}
+
+// testOCIModuleRegistry is a minimal OCI registry with a single repository,
+// example/vpc.
+type testOCIModuleRegistry struct {
+	server *httptest.Server
+
+	tags      map[string]string
+	blobs     map[string][]byte
+	referrers map[string][]ociclient.Descriptor
+}
+
+func newTestOCIModuleRegistry(t *testing.T) *testOCIModuleRegistry {
+	r := &testOCIModuleRegistry{
+		tags:      make(map[string]string),
+		blobs:     make(map[string][]byte),
+		referrers: make(map[string][]ociclient.Descriptor),
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.serveHTTP))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *testOCIModuleRegistry) push(t *testing.T, content interface{}) ociclient.Descriptor {
+	t.Helper()
+	data, ok := content.([]byte)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sum := sha256.Sum256(data)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	r.blobs[digest] = data
+	return ociclient.Descriptor{Digest: digest, Size: int64(len(data))}
+}
+
+// pushPackage pushes a module package containing the given files with the
+// given tag, and returns the digest of its manifest.
+func (r *testOCIModuleRegistry) pushPackage(t *testing.T, tag string, files map[string]string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	layer := r.push(t, buf.Bytes())
+	layer.MediaType = OCIModulePackageLayerMediaType
+	manifest := r.push(t, map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     ociclient.ManifestMediaType,
+		"layers":        []ociclient.Descriptor{layer},
+	})
+	r.tags[tag] = manifest.Digest
+	return manifest.Digest
+}
+
+// pushSignature pushes a signature artifact for the manifest with the given
+// digest, signed with the given key.
+func (r *testOCIModuleRegistry) pushSignature(t *testing.T, digest string, entity *openpgp.Entity) {
+	t.Helper()
+	var signature bytes.Buffer
+	w, err := armor.Encode(&signature, openpgp.SignatureType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := openpgp.DetachSign(w, entity, strings.NewReader(digest), nil); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	layer := r.push(t, signature.Bytes())
+	layer.MediaType = OCIModuleSignatureLayerMediaType
+	manifest := r.push(t, map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     ociclient.ManifestMediaType,
+		"artifactType":  OCIModuleSignatureArtifactType,
+		"subject":       ociclient.Descriptor{MediaType: ociclient.ManifestMediaType, Digest: digest},
+		"layers":        []ociclient.Descriptor{layer},
+	})
+	manifest.MediaType = ociclient.ManifestMediaType
+	manifest.ArtifactType = OCIModuleSignatureArtifactType
+	r.referrers[digest] = append(r.referrers[digest], manifest)
+}
+
+func (r *testOCIModuleRegistry) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	path, ok := strings.CutPrefix(req.URL.Path, "/v2/example/vpc/")
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	switch {
+	case strings.HasPrefix(path, "manifests/"):
+		ref := strings.TrimPrefix(path, "manifests/")
+		if digest, ok := r.tags[ref]; ok {
+			ref = digest
+		}
+		data, ok := r.blobs[ref]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Write(data)
+	case strings.HasPrefix(path, "blobs/"):
+		data, ok := r.blobs[strings.TrimPrefix(path, "blobs/")]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Write(data)
+	case strings.HasPrefix(path, "referrers/"):
+		w.Header().Set("Content-Type", ociclient.IndexMediaType)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"schemaVersion": 2,
+			"mediaType":     ociclient.IndexMediaType,
+			"manifests":     append([]ociclient.Descriptor{}, r.referrers[strings.TrimPrefix(path, "referrers/")]...),
+		})
+	default:
+		http.NotFound(w, req)
+	}
+}
@@ -66,6 +66,14 @@ func SearchLocalDirectory(baseDir string) (map[addrs.Provider]PackageMetaList, e
 		relPath := filepath.ToSlash(fsPath)
 		parts := strings.Split(relPath, "/")
 
+		if len(parts) == 1 && relPath != "." && strings.HasPrefix(relPath, ".") && info.IsDir() {
+			// A hostname can't start with a period, so a directory like this
+			// is some other tool's bookkeeping, such as the object store of
+			// a shared provider cache directory, which we must not descend
+			// into.
+			return filepath.SkipDir
+		}
+
 		if len(parts) < 3 {
 			// Likely a prefix of a valid path, so we'll ignore it and visit
 			// the full valid path on a later call.
@@ -13,6 +13,7 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/go-retryablehttp"
@@ -181,9 +182,14 @@ func (s *HTTPMirrorSource) PackageMeta(ctx context.Context, provider addrs.Provi
 
 	// If we got here then the response had status OK and so our body
 	// will be non-nil and should contain some JSON for us to parse.
+	type ResponseDeltaMeta struct {
+		RelativeURL string `json:"url"`
+		Hashes      []string
+	}
 	type ResponseArchiveMeta struct {
 		RelativeURL string `json:"url"`
 		Hashes      []string
+		Deltas      map[string]*ResponseDeltaMeta `json:"deltas"`
 	}
 	type ResponseBody struct {
 		Archives map[string]*ResponseArchiveMeta `json:"archives"`
@@ -239,9 +245,61 @@ func (s *HTTPMirrorSource) PackageMeta(ctx context.Context, provider addrs.Provi
 		ret.Authentication = NewPackageHashAuthentication(target, hashes)
 	}
 
+	// Deltas are only useful if we can verify the package that results from
+	// applying one, so we'll ignore them unless we have an "h1:" hash to
+	// verify it against.
+	if hasDirectoryHash(ret.AcceptableHashes()) {
+		baseVersions := make([]string, 0, len(archiveMeta.Deltas))
+		for v := range archiveMeta.Deltas {
+			baseVersions = append(baseVersions, v)
+		}
+		sort.Strings(baseVersions)
+		for _, v := range baseVersions {
+			deltaMeta := archiveMeta.Deltas[v]
+			baseVersion, err := ParseVersion(v)
+			if err != nil {
+				return PackageMeta{}, s.errQueryFailed(
+					provider,
+					fmt.Errorf("provider mirror returned delta for invalid version %q: %w", v, err),
+				)
+			}
+			relURL, err := url.Parse(deltaMeta.RelativeURL)
+			if err != nil {
+				return PackageMeta{}, s.errQueryFailed(
+					provider,
+					fmt.Errorf("provider mirror returned invalid URL %q: %w", deltaMeta.RelativeURL, err),
+				)
+			}
+			delta := PackageDelta{
+				BaseVersion: baseVersion,
+				Location:    PackageHTTPURL(finalURL.ResolveReference(relURL).String()),
+			}
+			for _, hashStr := range deltaMeta.Hashes {
+				hash, err := ParseHash(hashStr)
+				if err != nil {
+					return PackageMeta{}, s.errQueryFailed(
+						provider,
+						fmt.Errorf("provider mirror returned invalid delta hash %q: %w", hashStr, err),
+					)
+				}
+				delta.Hashes = append(delta.Hashes, hash)
+			}
+			ret.Deltas = append(ret.Deltas, delta)
+		}
+	}
+
 	return ret, nil
 }
 
+func hasDirectoryHash(hashes []Hash) bool {
+	for _, hash := range hashes {
+		if hash.HasScheme(HashScheme1) {
+			return true
+		}
+	}
+	return false
+}
+
 // ForDisplay returns a string description of the source for user-facing output.
 func (s *HTTPMirrorSource) ForDisplay(provider addrs.Provider) string {
 	return "provider mirror at " + s.baseURL.String()
@@ -131,6 +131,13 @@ func TestHTTPMirrorSource(t *testing.T) {
 				AllHashes:      []Hash{"h1:placeholder-hash", "h0:unacceptable-hash"},
 				Platform:       Platform{"tos", "m68k"},
 			},
+			Deltas: []PackageDelta{
+				{
+					BaseVersion: MustParseVersion("0.9.0"),
+					Location:    PackageHTTPURL(httpServer.URL + "/terraform.io/test/exists/deltas/terraform-provider-test_v0.9.0_v1.0.0_tos_m68k.zip"),
+					Hashes:      []Hash{"zh:placeholder-delta-hash"},
+				},
+			},
 		}
 		if diff := cmp.Diff(want, got); diff != "" {
 			t.Errorf("wrong result\n%s", diff)
@@ -252,7 +259,15 @@ func testHTTPMirrorSourceHandler(resp http.ResponseWriter, req *http.Request) {
 						"hashes": [
 							"h1:placeholder-hash",
 							"h0:unacceptable-hash"
-						]
+						],
+						"deltas": {
+							"0.9.0": {
+								"url": "deltas/terraform-provider-test_v0.9.0_v1.0.0_tos_m68k.zip",
+								"hashes": [
+									"zh:placeholder-delta-hash"
+								]
+							}
+						}
 					}
 				}
 			}
@@ -25,9 +25,15 @@ type MemoizeSource struct {
 	underlying        Source
 	availableVersions map[addrs.Provider]memoizeAvailableVersionsRet
 	packageMetas      map[memoizePackageMetaCall]memoizePackageMetaRet
+	versionStatuses   map[addrs.Provider]memoizeVersionStatusesRet
 	mu                sync.Mutex
 }
 
+type memoizeVersionStatusesRet struct {
+	VersionStatuses VersionStatuses
+	Err             error
+}
+
 type memoizeAvailableVersionsRet struct {
 	VersionList VersionList
 	Warnings    Warnings
@@ -46,6 +52,7 @@ type memoizePackageMetaRet struct {
 }
 
 var _ Source = (*MemoizeSource)(nil)
+var _ VersionStatusSource = (*MemoizeSource)(nil)
 
 // NewMemoizeSource constructs and returns a new MemoizeSource that wraps
 // the given underlying source and memoizes its results.
@@ -54,6 +61,7 @@ func NewMemoizeSource(underlying Source) *MemoizeSource {
 		underlying:        underlying,
 		availableVersions: make(map[addrs.Provider]memoizeAvailableVersionsRet),
 		packageMetas:      make(map[memoizePackageMetaCall]memoizePackageMetaRet),
+		versionStatuses:   make(map[addrs.Provider]memoizeVersionStatusesRet),
 	}
 }
 
@@ -101,6 +109,25 @@ func (s *MemoizeSource) PackageMeta(ctx context.Context, provider addrs.Provider
 	return ret, err
 }
 
+// VersionStatuses requests the version statuses from the underlying source,
+// if it implements VersionStatusSource, and caches them before returning
+// them, or on subsequent calls returns the result directly from the cache.
+func (s *MemoizeSource) VersionStatuses(ctx context.Context, provider addrs.Provider) (VersionStatuses, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, exists := s.versionStatuses[provider]; exists {
+		return existing.VersionStatuses, existing.Err
+	}
+
+	ret, err := SourceVersionStatuses(ctx, s.underlying, provider)
+	s.versionStatuses[provider] = memoizeVersionStatusesRet{
+		VersionStatuses: ret,
+		Err:             err,
+	}
+	return ret, err
+}
+
 func (s *MemoizeSource) ForDisplay(provider addrs.Provider) string {
 	return s.underlying.ForDisplay(provider)
 }
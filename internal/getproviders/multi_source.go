@@ -28,6 +28,7 @@ import (
 type MultiSource []MultiSourceSelector
 
 var _ Source = MultiSource(nil)
+var _ VersionStatusSource = MultiSource(nil)
 
 // AvailableVersions retrieves all of the versions of the given provider
 // that are available across all of the underlying selectors, while respecting
@@ -82,6 +83,39 @@ func (s MultiSource) AvailableVersions(ctx context.Context, provider addrs.Provi
 	return ret, warnings, nil
 }
 
+// VersionStatuses combines the version statuses reported by the underlying
+// sources that accept the given provider and implement VersionStatusSource.
+// A version that any of them reports as deprecated or yanked is treated as
+// such.
+func (s MultiSource) VersionStatuses(ctx context.Context, provider addrs.Provider) (VersionStatuses, error) {
+	ret := make(VersionStatuses)
+	for _, selector := range s {
+		if !selector.CanHandleProvider(provider) {
+			continue // doesn't match the given patterns
+		}
+		statuses, err := SourceVersionStatuses(ctx, selector.Source, provider)
+		switch err.(type) {
+		case nil:
+		// okay
+		case ErrRegistryProviderNotKnown, ErrProviderNotFound:
+			continue // ignore, then
+		default:
+			return nil, err
+		}
+		for v, status := range statuses {
+			existing := ret[v]
+			if existing.Deprecated == nil {
+				existing.Deprecated = status.Deprecated
+			}
+			if existing.Yanked == nil {
+				existing.Yanked = status.Yanked
+			}
+			ret[v] = existing
+		}
+	}
+	return ret, nil
+}
+
 // PackageMeta retrieves the package metadata for the requested provider package
 // from the first selector that indicates availability of it.
 func (s MultiSource) PackageMeta(ctx context.Context, provider addrs.Provider, version Version, target Platform) (PackageMeta, error) {
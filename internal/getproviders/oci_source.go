@@ -0,0 +1,289 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package getproviders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/ociclient"
+)
+
+// The media types of the artifacts that OpenTofu looks for in an OCI
+// registry. A provider version is an image index, tagged with the version
+// number, with a manifest for each platform which has a single layer
+// containing the usual provider package archive.
+const (
+	// OCIPackageLayerMediaType is the media type of the layer of a
+	// platform's manifest that contains the provider package archive.
+	OCIPackageLayerMediaType = "application/vnd.opentofu.provider.package.v1+zip"
+
+	// OCISignatureArtifactType is the artifact type of a manifest which
+	// refers to the index of a provider version, and signs it using the
+	// same checksums document and signature that provider registries
+	// publish, in the layers with the following media types. The keys
+	// layer contains the ASCII-armored public keys that the signature can
+	// be checked against.
+	OCISignatureArtifactType     = "application/vnd.opentofu.provider.signature.v1"
+	OCIChecksumsLayerMediaType   = "application/vnd.opentofu.provider.checksums.v1+text"
+	OCISignatureLayerMediaType   = "application/vnd.opentofu.provider.checksums-signature.v1+pgp"
+	OCISigningKeysLayerMediaType = "application/pgp-keys"
+)
+
+// OCIRegistrySource is a source that installs providers from repositories
+// in an OCI artifact registry, using the OCI distribution protocol.
+type OCIRegistrySource struct {
+	// repositoryTemplate is the address of the repository for each
+	// provider, like "ghcr.io/example/terraform-provider-${type}".
+	repositoryTemplate string
+	client             *ociclient.Client
+}
+
+var _ Source = (*OCIRegistrySource)(nil)
+
+// NewOCIRegistrySource constructs and returns a source which installs each
+// provider from the repository whose address is the result of substituting
+// the provider's address into the given template, which can include the
+// placeholders ${hostname}, ${namespace}, and ${type}.
+//
+// creds may be nil, in which case the source uses only anonymous access.
+func NewOCIRegistrySource(repositoryTemplate string, creds ociclient.CredentialsSource) *OCIRegistrySource {
+	return &OCIRegistrySource{
+		repositoryTemplate: repositoryTemplate,
+		client:             ociclient.NewClient(creds),
+	}
+}
+
+// AvailableVersions returns the versions of the given provider which are
+// tagged in its repository. Tags which aren't valid version numbers, with
+// or without a "v" prefix, are ignored.
+func (s *OCIRegistrySource) AvailableVersions(ctx context.Context, provider addrs.Provider) (VersionList, Warnings, error) {
+	repo, err := s.repository(provider)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ret VersionList
+	next := "tags/list"
+	for next != "" {
+		resp, err := s.client.Get(ctx, repo, next, "application/json")
+		if err != nil {
+			return nil, nil, s.errQueryFailed(provider, repo, err)
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+		case http.StatusNotFound:
+			resp.Body.Close()
+			return nil, nil, ErrProviderNotFound{
+				Provider: provider,
+				Sources:  []string{s.ForDisplay(provider)},
+			}
+		default:
+			resp.Body.Close()
+			return nil, nil, s.errQueryFailed(provider, repo, fmt.Errorf("registry returned unsuccessful status %s", resp.Status))
+		}
+
+		var body struct {
+			Tags []string `json:"tags"`
+		}
+		err = json.NewDecoder(io.LimitReader(resp.Body, ociclient.MaxDocumentSize)).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, nil, s.errQueryFailed(provider, repo, fmt.Errorf("invalid tag list: %w", err))
+		}
+		for _, tag := range body.Tags {
+			v, err := ParseVersion(strings.TrimPrefix(tag, "v"))
+			if err != nil {
+				continue
+			}
+			ret = append(ret, v)
+		}
+
+		// Registries can split the list into pages, linking to the next
+		// one with a header like `</v2/name/tags/list?last=x>; rel="next"`.
+		next = ""
+		if link := resp.Header.Get("Link"); strings.Contains(link, `rel="next"`) {
+			if start, end := strings.Index(link, "<"), strings.Index(link, ">"); start >= 0 && end > start {
+				nextURL, err := url.Parse(link[start+1 : end])
+				if err == nil {
+					next = "tags/list?" + nextURL.RawQuery
+				}
+			}
+		}
+	}
+	ret.Sort()
+	return ret, nil, nil
+}
+
+// PackageMeta returns the metadata for the package of the given provider
+// version for the given platform, which is in the layer of the manifest for
+// that platform in the image index that's tagged with the version.
+//
+// If there's a signature artifact that refers to the image index, the
+// package must also match the checksums document that it signs.
+func (s *OCIRegistrySource) PackageMeta(ctx context.Context, provider addrs.Provider, version Version, target Platform) (PackageMeta, error) {
+	repo, err := s.repository(provider)
+	if err != nil {
+		return PackageMeta{}, err
+	}
+
+	var index ociclient.Index
+	indexDigest, err := s.client.GetManifest(ctx, repo, version.String(), ociclient.IndexMediaType, &index)
+	if err == ociclient.ErrNotFound {
+		indexDigest, err = s.client.GetManifest(ctx, repo, "v"+version.String(), ociclient.IndexMediaType, &index)
+	}
+	if err != nil {
+		return PackageMeta{}, s.errQueryFailed(provider, repo, err)
+	}
+
+	var manifestDigest string
+	for _, desc := range index.Manifests {
+		if desc.Platform != nil && desc.Platform.OS == target.OS && desc.Platform.Architecture == target.Arch {
+			manifestDigest = desc.Digest
+			break
+		}
+	}
+	if manifestDigest == "" {
+		return PackageMeta{}, ErrPlatformNotSupported{
+			Provider: provider,
+			Version:  version,
+			Platform: target,
+		}
+	}
+
+	var manifest ociclient.Manifest
+	if _, err := s.client.GetManifest(ctx, repo, manifestDigest, ociclient.ManifestMediaType, &manifest); err != nil {
+		return PackageMeta{}, s.errQueryFailed(provider, repo, err)
+	}
+	var layer *ociclient.Descriptor
+	for i := range manifest.Layers {
+		if manifest.Layers[i].MediaType == OCIPackageLayerMediaType {
+			layer = &manifest.Layers[i]
+			break
+		}
+	}
+	if layer == nil {
+		return PackageMeta{}, s.errQueryFailed(provider, repo, fmt.Errorf("the manifest for %s v%s on %s has no layer of type %s", provider, version, target, OCIPackageLayerMediaType))
+	}
+	checksum, err := ociclient.DigestSHA256(layer.Digest)
+	if err != nil {
+		return PackageMeta{}, s.errQueryFailed(provider, repo, err)
+	}
+
+	filename := layer.Annotations[ociclient.ImageTitleAnnotation]
+	if filename == "" || strings.ContainsAny(filename, "/\\") {
+		filename = fmt.Sprintf("terraform-provider-%s_%s_%s.zip", provider.Type, version, target)
+	}
+
+	ret := PackageMeta{
+		Provider:       provider,
+		Version:        version,
+		TargetPlatform: target,
+		Filename:       filename,
+		Location:       PackageHTTPURL(s.client.Endpoint(repo, "blobs/"+layer.Digest)),
+		PrepareRequest: func(req *http.Request) error {
+			s.client.Authorize(repo, req)
+			return nil
+		},
+	}
+
+	document, signature, keys, err := s.signature(ctx, repo, indexDigest)
+	if err != nil {
+		return PackageMeta{}, s.errQueryFailed(provider, repo, err)
+	}
+	if document == nil {
+		log.Printf("[DEBUG] No signature for %s v%s in %s, so verifying only its checksum", provider, version, repo)
+		ret.Authentication = NewArchiveChecksumAuthentication(target, checksum)
+		return ret, nil
+	}
+	ret.Authentication = PackageAuthenticationAll(
+		NewMatchingChecksumAuthentication(document, filename, checksum),
+		NewArchiveChecksumAuthentication(target, checksum),
+		NewSignatureAuthentication(document, signature, keys, &provider),
+	)
+	return ret, nil
+}
+
+// signature returns the checksums document, signature, and signing keys
+// from the first signature artifact that refers to the given manifest, or
+// all nil if there isn't one.
+func (s *OCIRegistrySource) signature(ctx context.Context, repo ociclient.Repository, digest string) ([]byte, []byte, []SigningKey, error) {
+	referrers, err := s.client.Referrers(ctx, repo, digest, OCISignatureArtifactType)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to find signatures: %w", err)
+	}
+
+	for _, desc := range referrers {
+		var manifest ociclient.Manifest
+		if _, err := s.client.GetManifest(ctx, repo, desc.Digest, ociclient.ManifestMediaType, &manifest); err != nil {
+			return nil, nil, nil, err
+		}
+		var document, signature, keys []byte
+		for _, layer := range manifest.Layers {
+			var dst *[]byte
+			switch layer.MediaType {
+			case OCIChecksumsLayerMediaType:
+				dst = &document
+			case OCISignatureLayerMediaType:
+				dst = &signature
+			case OCISigningKeysLayerMediaType:
+				dst = &keys
+			default:
+				continue
+			}
+			if *dst, err = s.client.GetBlob(ctx, repo, layer.Digest); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+		if document == nil || signature == nil {
+			return nil, nil, nil, fmt.Errorf("signature artifact %s must have both a checksums layer and a signature layer", desc.Digest)
+		}
+		var signingKeys []SigningKey
+		if keys != nil {
+			signingKeys = []SigningKey{{ASCIIArmor: string(keys)}}
+		}
+		return document, signature, signingKeys, nil
+	}
+	return nil, nil, nil, nil
+}
+
+// ForDisplay returns a string description of the source for user-facing output.
+func (s *OCIRegistrySource) ForDisplay(provider addrs.Provider) string {
+	repo, err := s.repository(provider)
+	if err != nil {
+		return "OCI registry"
+	}
+	return "OCI repository " + repo.String()
+}
+
+func (s *OCIRegistrySource) repository(provider addrs.Provider) (ociclient.Repository, error) {
+	addr := strings.NewReplacer(
+		"${hostname}", provider.Hostname.String(),
+		"${namespace}", provider.Namespace,
+		"${type}", provider.Type,
+	).Replace(s.repositoryTemplate)
+	repo, err := ociclient.ParseRepository(addr)
+	if err != nil {
+		return ociclient.Repository{}, fmt.Errorf("invalid OCI repository address %q for %s: must be a registry hostname followed by a repository name", addr, provider)
+	}
+	return repo, nil
+}
+
+func (s *OCIRegistrySource) errQueryFailed(provider addrs.Provider, repo ociclient.Repository, err error) error {
+	if err == context.Canceled {
+		return ErrRequestCanceled{}
+	}
+	return ErrQueryFailed{
+		Provider:  provider,
+		Wrapped:   err,
+		MirrorURL: &url.URL{Scheme: s.client.Scheme, Host: repo.Host, Path: "/v2/" + repo.Name},
+	}
+}
@@ -0,0 +1,334 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package getproviders
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/ociclient"
+)
+
+func TestOCIRegistrySourceAvailableVersions(t *testing.T) {
+	ctx := context.Background()
+	registry := newTestOCIRegistry(t)
+	registry.pushVersion(t, "2.1.0", "testdata/filesystem-mirror/registry.opentofu.org/hashicorp/null/terraform-provider-null_2.1.0_linux_amd64.zip")
+	source := registry.source(nil)
+
+	got, _, err := source.AvailableVersions(ctx, addrs.NewDefaultProvider("null"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := VersionList{
+		MustParseVersion("2.0.0"),
+		MustParseVersion("2.1.0"),
+		MustParseVersion("2.2.0"),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+
+	_, _, err = source.AvailableVersions(ctx, addrs.NewDefaultProvider("missing"))
+	if _, ok := err.(ErrProviderNotFound); !ok {
+		t.Errorf("wrong error for missing provider: %#v", err)
+	}
+}
+
+func TestOCIRegistrySourcePackageMeta(t *testing.T) {
+	ctx := context.Background()
+	provider := addrs.NewDefaultProvider("null")
+	version := MustParseVersion("2.1.0")
+	platform := Platform{OS: "linux", Arch: "amd64"}
+	archive := PackageLocalArchive("testdata/filesystem-mirror/registry.opentofu.org/hashicorp/null/terraform-provider-null_2.1.0_linux_amd64.zip")
+
+	t.Run("unsigned", func(t *testing.T) {
+		registry := newTestOCIRegistry(t)
+		registry.pushVersion(t, "2.1.0", string(archive))
+		source := registry.source(nil)
+
+		meta, err := source.PackageMeta(ctx, provider, version, platform)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := meta.Filename, "terraform-provider-null_2.1.0_linux_amd64.zip"; got != want {
+			t.Errorf("wrong filename %q; want %q", got, want)
+		}
+		if !strings.HasSuffix(meta.Location.String(), "/v2/example/terraform-provider-null/blobs/"+registry.packageDigest) {
+			t.Errorf("wrong location %s", meta.Location)
+		}
+		result, err := meta.Authentication.AuthenticatePackage(archive)
+		if err != nil {
+			t.Fatalf("authentication failed: %s", err)
+		}
+		if result.Signed() {
+			t.Errorf("unsigned package is reported as signed")
+		}
+	})
+	t.Run("signed", func(t *testing.T) {
+		registry := newTestOCIRegistry(t)
+		registry.pushVersion(t, "v2.1.0", string(archive))
+		registry.pushSignature(t)
+		source := registry.source(nil)
+
+		meta, err := source.PackageMeta(ctx, provider, version, platform)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := meta.Authentication.AuthenticatePackage(archive)
+		if err != nil {
+			t.Fatalf("authentication failed: %s", err)
+		}
+		if !result.Signed() {
+			t.Errorf("signed package is reported as unsigned")
+		}
+	})
+	t.Run("authentication", func(t *testing.T) {
+		registry := newTestOCIRegistry(t)
+		registry.pushVersion(t, "2.1.0", string(archive))
+		registry.username, registry.password = "user", "secret"
+		source := registry.source(testOCICredentials{"user", "secret"})
+
+		meta, err := source.PackageMeta(ctx, provider, version, platform)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := http.NewRequest("GET", meta.Location.String(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := meta.PrepareRequest(req); err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("package download was not authenticated: %s", resp.Status)
+		}
+
+		source = registry.source(nil)
+		if _, err := source.PackageMeta(ctx, provider, version, platform); err == nil {
+			t.Errorf("succeeded without credentials")
+		}
+	})
+	t.Run("unsupported platform", func(t *testing.T) {
+		registry := newTestOCIRegistry(t)
+		registry.pushVersion(t, "2.1.0", string(archive))
+		source := registry.source(nil)
+
+		_, err := source.PackageMeta(ctx, provider, version, Platform{OS: "windows", Arch: "amd64"})
+		if _, ok := err.(ErrPlatformNotSupported); !ok {
+			t.Errorf("wrong error: %#v", err)
+		}
+	})
+}
+
+type testOCICredentials [2]string
+
+func (c testOCICredentials) OCICredentials(host string) (string, string, error) {
+	return c[0], c[1], nil
+}
+
+// testOCIRegistry is a minimal OCI registry with a single repository,
+// example/terraform-provider-null, which uses bearer token authentication
+// if a username is set.
+type testOCIRegistry struct {
+	server             *httptest.Server
+	username, password string
+
+	tags          map[string]string
+	blobs         map[string][]byte
+	referrers     []ociclient.Descriptor
+	indexDigest   string
+	packageDigest string
+}
+
+func newTestOCIRegistry(t *testing.T) *testOCIRegistry {
+	r := &testOCIRegistry{
+		tags:  map[string]string{"2.0.0": "", "v2.2.0": "", "latest": ""},
+		blobs: make(map[string][]byte),
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.serveHTTP))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *testOCIRegistry) source(creds ociclient.CredentialsSource) *OCIRegistrySource {
+	source := NewOCIRegistrySource(strings.TrimPrefix(r.server.URL, "http://")+"/example/terraform-provider-${type}", creds)
+	source.client.Scheme = "http"
+	return source
+}
+
+func (r *testOCIRegistry) push(t *testing.T, content interface{}) ociclient.Descriptor {
+	t.Helper()
+	var data []byte
+	switch content := content.(type) {
+	case []byte:
+		data = content
+	default:
+		var err error
+		if data, err = json.Marshal(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sum := sha256.Sum256(data)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	r.blobs[digest] = data
+	return ociclient.Descriptor{Digest: digest, Size: int64(len(data))}
+}
+
+func (r *testOCIRegistry) pushVersion(t *testing.T, tag, archive string) {
+	t.Helper()
+	data, err := os.ReadFile(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer := r.push(t, data)
+	layer.MediaType = OCIPackageLayerMediaType
+	layer.Annotations = map[string]string{ociclient.ImageTitleAnnotation: "terraform-provider-null_2.1.0_linux_amd64.zip"}
+	r.packageDigest = layer.Digest
+
+	manifest := r.push(t, map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     ociclient.ManifestMediaType,
+		"layers":        []ociclient.Descriptor{layer},
+	})
+	manifest.MediaType = ociclient.ManifestMediaType
+	manifest.Platform = &ociclient.Platform{OS: "linux", Architecture: "amd64"}
+
+	index := r.push(t, map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     ociclient.IndexMediaType,
+		"manifests":     []ociclient.Descriptor{manifest},
+	})
+	r.indexDigest = index.Digest
+	r.tags[tag] = index.Digest
+}
+
+// pushSignature pushes a signature artifact for the most recently pushed
+// version, signed with a new key.
+func (r *testOCIRegistry) pushSignature(t *testing.T) {
+	t.Helper()
+	entity, err := openpgp.NewEntity("Test", "", "test@example.com", openpgpConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := ociclient.DigestSHA256(r.packageDigest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	document := []byte(fmt.Sprintf("%x  terraform-provider-null_2.1.0_linux_amd64.zip\n", sum))
+	var signature bytes.Buffer
+	if err := openpgp.DetachSign(&signature, entity, bytes.NewReader(document), openpgpConfig); err != nil {
+		t.Fatal(err)
+	}
+	var keys bytes.Buffer
+	w, err := armor.Encode(&keys, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	var layers []ociclient.Descriptor
+	for mediaType, data := range map[string][]byte{
+		OCIChecksumsLayerMediaType:   document,
+		OCISignatureLayerMediaType:   signature.Bytes(),
+		OCISigningKeysLayerMediaType: keys.Bytes(),
+	} {
+		layer := r.push(t, data)
+		layer.MediaType = mediaType
+		layers = append(layers, layer)
+	}
+	manifest := r.push(t, map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     ociclient.ManifestMediaType,
+		"artifactType":  OCISignatureArtifactType,
+		"subject":       ociclient.Descriptor{MediaType: ociclient.IndexMediaType, Digest: r.indexDigest},
+		"layers":        layers,
+	})
+	manifest.MediaType = ociclient.ManifestMediaType
+	manifest.ArtifactType = OCISignatureArtifactType
+	r.referrers = append(r.referrers, manifest)
+}
+
+func (r *testOCIRegistry) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		if username, password, ok := req.BasicAuth(); !ok || username != r.username || password != r.password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if got, want := req.URL.Query().Get("scope"), "repository:example/terraform-provider-null:pull"; got != want {
+			http.Error(w, "wrong scope "+got, http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"token":"test-token"}`))
+		return
+	}
+	if r.username != "" && req.Header.Get("Authorization") != "Bearer test-token" {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, r.server.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	path, ok := strings.CutPrefix(req.URL.Path, "/v2/example/terraform-provider-null/")
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	switch {
+	case path == "tags/list":
+		var tags []string
+		for tag := range r.tags {
+			tags = append(tags, tag)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"tags": tags})
+	case strings.HasPrefix(path, "manifests/"):
+		ref := strings.TrimPrefix(path, "manifests/")
+		if digest, ok := r.tags[ref]; ok {
+			ref = digest
+		}
+		data, ok := r.blobs[ref]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Write(data)
+	case strings.HasPrefix(path, "blobs/"):
+		data, ok := r.blobs[strings.TrimPrefix(path, "blobs/")]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Write(data)
+	case path == "referrers/"+r.indexDigest:
+		w.Header().Set("Content-Type", ociclient.IndexMediaType)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"schemaVersion": 2,
+			"mediaType":     ociclient.IndexMediaType,
+			"manifests":     append([]ociclient.Descriptor{}, r.referrers...),
+		})
+	default:
+		http.NotFound(w, req)
+	}
+}
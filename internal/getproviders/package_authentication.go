@@ -47,6 +47,10 @@ var (
 type PackageAuthenticationResult struct {
 	result packageAuthenticationResult
 	KeyID  string
+
+	// KeyOrigin describes where the key that signed the package came from,
+	// if it wasn't from the registry that the package came from.
+	KeyOrigin string
 }
 
 func (t *PackageAuthenticationResult) String() string {
@@ -83,6 +87,10 @@ func (t *PackageAuthenticationResult) SigningSkipped() bool {
 // The JSON struct tags represent the field names used by the Registry API.
 type SigningKey struct {
 	ASCIIArmor string `json:"ascii_armor"`
+
+	// Origin describes where a key that didn't come from the registry came
+	// from, such as the CLI configuration, for use in diagnostics.
+	Origin string `json:"-"`
 }
 
 // PackageAuthentication is an interface implemented by the optional package
@@ -424,13 +432,13 @@ func (s signatureAuthentication) AuthenticatePackage(location PackageLocation) (
 
 	// Find the key that signed the checksum file. This can fail if there is no
 	// valid signature for any of the provided keys.
-	_, keyID, err := s.findSigningKey()
+	key, keyID, err := s.findSigningKey()
 	if err != nil {
 		return nil, err
 	}
 
 	// We have a valid signature.
-	return &PackageAuthenticationResult{result: signed, KeyID: keyID}, nil
+	return &PackageAuthenticationResult{result: signed, KeyID: keyID, KeyOrigin: key.Origin}, nil
 }
 
 func (s signatureAuthentication) AcceptableHashes() []Hash {
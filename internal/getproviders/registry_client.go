@@ -89,6 +89,25 @@ func newRegistryClient(baseURL *url.URL, creds svcauth.HostCredentials) *registr
 	}
 }
 
+// providerVersionsResponse is the response body of the provider versions
+// endpoint.
+//
+// We ignore the platforms portion of the response body, because the
+// installer verifies the platform compatibility after pulling a provider
+// versions' metadata.
+type providerVersionsResponse struct {
+	Versions []struct {
+		Version   string   `json:"version"`
+		Protocols []string `json:"protocols"`
+
+		// Deprecation and Yanked are set if the publisher has deprecated
+		// or withdrawn the version.
+		Deprecation *VersionNotice `json:"deprecation"`
+		Yanked      *VersionNotice `json:"yanked"`
+	} `json:"versions"`
+	Warnings []string `json:"warnings"`
+}
+
 // ProviderVersions returns the raw version and protocol strings produced by the
 // registry for the given provider.
 //
@@ -97,23 +116,43 @@ func newRegistryClient(baseURL *url.URL, creds svcauth.HostCredentials) *registr
 // ErrUnauthorized if the registry responds with 401 or 403 status codes, or
 // ErrQueryFailed for any other protocol or operational problem.
 func (c *registryClient) ProviderVersions(ctx context.Context, addr addrs.Provider) (map[string][]string, []string, error) {
+	body, err := c.providerVersions(ctx, addr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(body.Versions) == 0 {
+		return nil, body.Warnings, nil
+	}
+
+	ret := make(map[string][]string, len(body.Versions))
+	for _, v := range body.Versions {
+		ret[v.Version] = v.Protocols
+	}
+
+	return ret, body.Warnings, nil
+}
+
+// providerVersions returns the whole response of the provider versions
+// endpoint for the given provider, with the same errors as ProviderVersions.
+func (c *registryClient) providerVersions(ctx context.Context, addr addrs.Provider) (*providerVersionsResponse, error) {
 	endpointPath, err := url.Parse(path.Join(addr.Namespace, addr.Type, "versions"))
 	if err != nil {
 		// Should never happen because we're constructing this from
 		// already-validated components.
-		return nil, nil, err
+		return nil, err
 	}
 	endpointURL := c.baseURL.ResolveReference(endpointPath)
 	req, err := retryablehttp.NewRequest("GET", endpointURL.String(), nil)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	req = req.WithContext(ctx)
 	c.addHeadersToRequest(req.Request)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, nil, c.errQueryFailed(addr, err)
+		return nil, c.errQueryFailed(addr, err)
 	}
 	defer resp.Body.Close()
 
@@ -121,42 +160,21 @@ func (c *registryClient) ProviderVersions(ctx context.Context, addr addrs.Provid
 	case http.StatusOK:
 		// Great!
 	case http.StatusNotFound:
-		return nil, nil, ErrRegistryProviderNotKnown{
+		return nil, ErrRegistryProviderNotKnown{
 			Provider: addr,
 		}
 	case http.StatusUnauthorized, http.StatusForbidden:
-		return nil, nil, c.errUnauthorized(addr.Hostname)
+		return nil, c.errUnauthorized(addr.Hostname)
 	default:
-		return nil, nil, c.errQueryFailed(addr, errors.New(resp.Status))
-	}
-
-	// We ignore the platforms portion of the response body, because the
-	// installer verifies the platform compatibility after pulling a provider
-	// versions' metadata.
-	type ResponseBody struct {
-		Versions []struct {
-			Version   string   `json:"version"`
-			Protocols []string `json:"protocols"`
-		} `json:"versions"`
-		Warnings []string `json:"warnings"`
+		return nil, c.errQueryFailed(addr, errors.New(resp.Status))
 	}
-	var body ResponseBody
 
+	var body providerVersionsResponse
 	dec := json.NewDecoder(resp.Body)
 	if err := dec.Decode(&body); err != nil {
-		return nil, nil, c.errQueryFailed(addr, err)
+		return nil, c.errQueryFailed(addr, err)
 	}
-
-	if len(body.Versions) == 0 {
-		return nil, body.Warnings, nil
-	}
-
-	ret := make(map[string][]string, len(body.Versions))
-	for _, v := range body.Versions {
-		ret[v.Version] = v.Protocols
-	}
-
-	return ret, body.Warnings, nil
+	return &body, nil
 }
 
 // PackageMeta returns metadata about a distribution package for a provider.
@@ -6,6 +6,7 @@ package getproviders
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	svchost "github.com/hashicorp/terraform-svchost"
 	disco "github.com/hashicorp/terraform-svchost/disco"
@@ -17,9 +18,16 @@ import (
 // their originating provider registries.
 type RegistrySource struct {
 	services *disco.Disco
+
+	// statuses remembers the version statuses from the responses to
+	// AvailableVersions, so that VersionStatuses doesn't need to repeat
+	// the request.
+	statusesMu sync.Mutex
+	statuses   map[addrs.Provider]VersionStatuses
 }
 
 var _ Source = (*RegistrySource)(nil)
+var _ VersionStatusSource = (*RegistrySource)(nil)
 
 // NewRegistrySource creates and returns a new source that will install
 // providers from their originating provider registries.
@@ -42,13 +50,14 @@ func (s *RegistrySource) AvailableVersions(ctx context.Context, provider addrs.P
 		return nil, nil, err
 	}
 
-	versionsResponse, warnings, err := client.ProviderVersions(ctx, provider)
+	body, err := client.providerVersions(ctx, provider)
 	if err != nil {
 		return nil, nil, err
 	}
+	s.recordVersionStatuses(provider, body)
 
-	if len(versionsResponse) == 0 {
-		return nil, warnings, nil
+	if len(body.Versions) == 0 {
+		return nil, body.Warnings, nil
 	}
 
 	// We ignore protocols here because our goal is to find out which versions
@@ -64,19 +73,66 @@ func (s *RegistrySource) AvailableVersions(ctx context.Context, provider addrs.P
 	// Terraform, etc. Changes that affect compatibility are considered breaking
 	// changes from a provider API standpoint, so provider teams should change
 	// compatibility only in new major versions.
-	ret := make(VersionList, 0, len(versionsResponse))
-	for str := range versionsResponse {
-		v, err := ParseVersion(str)
+	ret := make(VersionList, 0, len(body.Versions))
+	for _, rv := range body.Versions {
+		v, err := ParseVersion(rv.Version)
 		if err != nil {
 			return nil, nil, ErrQueryFailed{
 				Provider: provider,
-				Wrapped:  fmt.Errorf("registry response includes invalid version string %q: %w", str, err),
+				Wrapped:  fmt.Errorf("registry response includes invalid version string %q: %w", rv.Version, err),
 			}
 		}
 		ret = append(ret, v)
 	}
 	ret.Sort() // lowest precedence first, preserving order when equal precedence
-	return ret, warnings, nil
+	return ret, body.Warnings, nil
+}
+
+// VersionStatuses returns the statuses of the versions of the given provider
+// that the registry reports as deprecated or yanked.
+func (s *RegistrySource) VersionStatuses(ctx context.Context, provider addrs.Provider) (VersionStatuses, error) {
+	s.statusesMu.Lock()
+	statuses, ok := s.statuses[provider]
+	s.statusesMu.Unlock()
+	if ok {
+		return statuses, nil
+	}
+
+	client, err := s.registryClient(provider.Hostname)
+	if err != nil {
+		return nil, err
+	}
+	body, err := client.providerVersions(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+	return s.recordVersionStatuses(provider, body), nil
+}
+
+func (s *RegistrySource) recordVersionStatuses(provider addrs.Provider, body *providerVersionsResponse) VersionStatuses {
+	statuses := make(VersionStatuses)
+	for _, rv := range body.Versions {
+		if rv.Deprecation == nil && rv.Yanked == nil {
+			continue
+		}
+		v, err := ParseVersion(rv.Version)
+		if err != nil {
+			// AvailableVersions reports invalid versions.
+			continue
+		}
+		statuses[v] = VersionStatus{
+			Deprecated: rv.Deprecation,
+			Yanked:     rv.Yanked,
+		}
+	}
+
+	s.statusesMu.Lock()
+	defer s.statusesMu.Unlock()
+	if s.statuses == nil {
+		s.statuses = make(map[addrs.Provider]VersionStatuses)
+	}
+	s.statuses[provider] = statuses
+	return statuses
 }
 
 // PackageMeta returns metadata about the location and capabilities of
@@ -112,6 +112,46 @@ func TestSourceAvailableVersions_warnings(t *testing.T) {
 
 }
 
+func TestSourceVersionStatuses(t *testing.T) {
+	source, _, close := testRegistrySource(t)
+	defer close()
+
+	provider := addrs.MustParseProviderSourceString("example.com/weaksauce/withdrawn")
+	versions, _, err := source.AvailableVersions(context.Background(), provider)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if len(versions) != 3 {
+		t.Fatalf("wrong number of versions. Expected 3, got %d", len(versions))
+	}
+
+	statuses, err := source.VersionStatuses(context.Background(), provider)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	want := VersionStatuses{
+		MustParseVersion("1.0.0"): {
+			Deprecated: &VersionNotice{Reason: "use 1.1.0", Link: "https://example.com/upgrade"},
+		},
+		MustParseVersion("1.1.0"): {
+			Yanked: &VersionNotice{Reason: "broken"},
+		},
+	}
+	if diff := cmp.Diff(want, statuses); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+
+	// Without a preceding call to AvailableVersions the source requests the
+	// statuses itself.
+	statuses, err = NewRegistrySource(source.services).VersionStatuses(context.Background(), provider)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if diff := cmp.Diff(want, statuses); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+}
+
 func TestSourcePackageMeta(t *testing.T) {
 	source, baseURL, close := testRegistrySource(t)
 	defer close()
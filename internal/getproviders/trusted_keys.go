@@ -0,0 +1,124 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package getproviders
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+// TrustedSigningKeys are signing keys that the user trusts to sign the
+// packages of a set of providers, such as the providers in a private registry
+// or forks of public providers that are signed with the user's own key.
+type TrustedSigningKeys struct {
+	// Providers are the providers whose packages the keys may sign.
+	Providers MultiSourceMatchingPatterns
+
+	Keys []SigningKey
+
+	// Exclusive is true if only these keys may sign the packages, and so
+	// the keys that the registry returns for the providers are ignored.
+	// Otherwise the keys are trusted in addition to the registry's keys.
+	Exclusive bool
+}
+
+// ParseSigningKey returns a SigningKey for the given ASCII-armored OpenPGP
+// public key, or an error if it isn't a valid key. origin describes where
+// the key came from, for use in diagnostics.
+func ParseSigningKey(asciiArmor, origin string) (SigningKey, error) {
+	keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(asciiArmor))
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("invalid signing key: %w", err)
+	}
+	if len(keyring) == 0 {
+		return SigningKey{}, fmt.Errorf("invalid signing key: contains no public keys")
+	}
+	return SigningKey{ASCIIArmor: asciiArmor, Origin: origin}, nil
+}
+
+// trustedKeysSource is a Source that wraps another source so that the
+// signatures of the packages it returns are verified using the user's
+// trusted keys as well as, or instead of, the keys from the registry.
+type trustedKeysSource struct {
+	underlying Source
+	trusted    []*TrustedSigningKeys
+}
+
+var _ Source = (*trustedKeysSource)(nil)
+
+// NewTrustedKeysSource returns a source that wraps the given source so that
+// the signatures of the packages it returns are verified using the given
+// trusted keys as well as, or instead of, the keys that the registry returns.
+//
+// The trusted keys apply only to packages whose authentication includes a
+// signature check, which are those from provider registries and OCI
+// registries.
+func NewTrustedKeysSource(underlying Source, trusted []*TrustedSigningKeys) Source {
+	if len(trusted) == 0 {
+		return underlying
+	}
+	return &trustedKeysSource{
+		underlying: underlying,
+		trusted:    trusted,
+	}
+}
+
+func (s *trustedKeysSource) AvailableVersions(ctx context.Context, provider addrs.Provider) (VersionList, Warnings, error) {
+	return s.underlying.AvailableVersions(ctx, provider)
+}
+
+func (s *trustedKeysSource) PackageMeta(ctx context.Context, provider addrs.Provider, version Version, target Platform) (PackageMeta, error) {
+	meta, err := s.underlying.PackageMeta(ctx, provider, version, target)
+	if err != nil {
+		return meta, err
+	}
+	meta.Authentication = s.authentication(provider, meta.Authentication)
+	return meta, nil
+}
+
+func (s *trustedKeysSource) ForDisplay(provider addrs.Provider) string {
+	return s.underlying.ForDisplay(provider)
+}
+
+// authentication returns the given authentication with the signature check
+// in it, if any, changed to use the keys that are trusted for the given
+// provider.
+func (s *trustedKeysSource) authentication(provider addrs.Provider, auth PackageAuthentication) PackageAuthentication {
+	switch auth := auth.(type) {
+	case packageAuthenticationAll:
+		ret := make(packageAuthenticationAll, len(auth))
+		for i, check := range auth {
+			ret[i] = s.authentication(provider, check)
+		}
+		return ret
+	case signatureAuthentication:
+		auth.Keys = s.keys(provider, auth.Keys)
+		return auth
+	default:
+		return auth
+	}
+}
+
+// keys returns the keys that are trusted to sign the packages of the given
+// provider, given the keys that the registry returned.
+func (s *trustedKeysSource) keys(provider addrs.Provider, registryKeys []SigningKey) []SigningKey {
+	var ret []SigningKey
+	exclusive := false
+	for _, trusted := range s.trusted {
+		if !trusted.Providers.MatchesProvider(provider) {
+			continue
+		}
+		ret = append(ret, trusted.Keys...)
+		exclusive = exclusive || trusted.Exclusive
+	}
+	if !exclusive {
+		ret = append(ret, registryKeys...)
+	}
+	return ret
+}
@@ -0,0 +1,110 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package getproviders
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+func TestTrustedKeysSource(t *testing.T) {
+	provider := addrs.MustParseProviderSourceString("registry.example.com/example/test")
+	version := MustParseVersion("1.0.0")
+	signature, err := base64.StdEncoding.DecodeString(testAuthorSignatureGoodBase64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorKey, err := ParseSigningKey(testAuthorKeyArmor, `provider_signing_keys "registry.example.com/example/*"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey := SigningKey{ASCIIArmor: anotherPublicKey}
+	patterns, err := ParseMultiSourceMatchingPatterns([]string{"registry.example.com/example/*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPatterns, err := ParseMultiSourceMatchingPatterns([]string{"registry.example.com/other/*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		registryKeys []SigningKey
+		trusted      []*TrustedSigningKeys
+		wantOrigin   string
+		wantErr      string
+	}{
+		"registry key only": {
+			registryKeys: []SigningKey{otherKey},
+			wantErr:      "authentication signature from unknown issuer",
+		},
+		"additional trusted key": {
+			registryKeys: []SigningKey{otherKey},
+			trusted: []*TrustedSigningKeys{
+				{Providers: patterns, Keys: []SigningKey{authorKey}},
+			},
+			wantOrigin: authorKey.Origin,
+		},
+		"trusted key for other providers": {
+			registryKeys: []SigningKey{otherKey},
+			trusted: []*TrustedSigningKeys{
+				{Providers: otherPatterns, Keys: []SigningKey{authorKey}},
+			},
+			wantErr: "authentication signature from unknown issuer",
+		},
+		"exclusive trusted key": {
+			registryKeys: []SigningKey{{ASCIIArmor: testAuthorKeyArmor}},
+			trusted: []*TrustedSigningKeys{
+				{Providers: patterns, Keys: []SigningKey{otherKey}, Exclusive: true},
+			},
+			wantErr: "authentication signature from unknown issuer",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			underlying := NewMockSource([]PackageMeta{
+				{
+					Provider:       provider,
+					Version:        version,
+					TargetPlatform: CurrentPlatform,
+					Location:       PackageLocalArchive("testdata/my-package.zip"),
+					Authentication: PackageAuthenticationAll(
+						NewSignatureAuthentication([]byte(testShaSumsPlaceholder), signature, test.registryKeys, &provider),
+					),
+				},
+			}, nil)
+			source := NewTrustedKeysSource(underlying, test.trusted)
+
+			meta, err := source.PackageMeta(context.Background(), provider, version, CurrentPlatform)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := meta.Authentication.AuthenticatePackage(meta.Location)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("wrong error %v; want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !result.Signed() || result.KeyID != testAuthorKeyID {
+				t.Errorf("wrong result %#v", result)
+			}
+			if result.KeyOrigin != test.wantOrigin {
+				t.Errorf("wrong key origin %q; want %q", result.KeyOrigin, test.wantOrigin)
+			}
+		})
+	}
+}
+
+func TestParseSigningKey(t *testing.T) {
+	if _, err := ParseSigningKey("not a key", "test"); err == nil {
+		t.Error("invalid key was accepted")
+	}
+}
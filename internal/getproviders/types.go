@@ -5,6 +5,7 @@ package getproviders
 
 import (
 	"fmt"
+	"net/http"
 	"runtime"
 	"sort"
 	"strings"
@@ -199,6 +200,39 @@ type PackageMeta struct {
 	// This is likely appropriate only for packages that are already available
 	// on the local system.
 	Authentication PackageAuthentication
+
+	// PrepareRequest, if non-nil, is called to add credentials to each
+	// request that retrieves the package from a PackageHTTPURL location,
+	// for sources whose packages aren't publicly accessible.
+	PrepareRequest func(req *http.Request) error
+
+	// Deltas are alternative ways to retrieve the package, each of which
+	// requires a package for an earlier version of the same provider that
+	// is already available locally. Only network mirrors can offer deltas.
+	Deltas []PackageDelta
+}
+
+// PackageDelta describes an archive which, applied to the unpacked package
+// for another version of the same provider and platform, produces the
+// contents of the package that a PackageMeta describes.
+//
+// The archive contains an entry for each file of the resulting package.
+// An entry named with the suffix ".tfdelta" is a binary patch for the file
+// of the same name without that suffix in the base package, while any other
+// entry is the full content of the file. A patch begins with the line
+// "TFDELTA1" and is followed by a sequence of instructions, each of which
+// is either the byte 'c' followed by the offset and length of a range of the
+// base file to copy, or the byte 'i' followed by the length of the data
+// to insert and then the data itself. All numbers are unsigned varints.
+type PackageDelta struct {
+	// BaseVersion is the version of the package that the delta applies to.
+	BaseVersion Version
+
+	Location PackageHTTPURL
+
+	// Hashes, if not empty, are the acceptable "zh:" hashes of the delta
+	// archive itself.
+	Hashes []Hash
 }
 
 // LessThan returns true if the receiver should sort before the given other
@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package getproviders
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+// VersionNotice is a publisher's explanation of why a provider version has
+// been deprecated or yanked.
+type VersionNotice struct {
+	Reason string `json:"reason"`
+
+	// Link is an optional URL with more information, such as upgrade
+	// instructions.
+	Link string `json:"link"`
+}
+
+func (n *VersionNotice) String() string {
+	switch {
+	case n.Reason != "" && n.Link != "":
+		return fmt.Sprintf("%s (%s)", n.Reason, n.Link)
+	case n.Link != "":
+		return n.Link
+	default:
+		return n.Reason
+	}
+}
+
+// VersionStatus describes whether the publisher of a provider version has
+// deprecated or yanked it.
+type VersionStatus struct {
+	// Deprecated is set if the publisher recommends against using the
+	// version, although it can still be installed.
+	Deprecated *VersionNotice
+
+	// Yanked is set if the publisher has withdrawn the version, for example
+	// because of a security problem. OpenTofu never selects a yanked
+	// version, and a dependency lock file that selects one is an error.
+	Yanked *VersionNotice
+}
+
+// VersionStatuses are the statuses of the versions of a provider that have
+// been deprecated or yanked. Versions that are not in the map have neither.
+type VersionStatuses map[Version]VersionStatus
+
+// VersionStatusSource is implemented by Sources that can report which
+// versions of a provider have been deprecated or yanked by its publisher.
+type VersionStatusSource interface {
+	// VersionStatuses returns the statuses of the versions of the given
+	// provider. Callers should call AvailableVersions first, because
+	// sources might only be able to report statuses of the versions they
+	// have already listed.
+	VersionStatuses(ctx context.Context, provider addrs.Provider) (VersionStatuses, error)
+}
+
+// SourceVersionStatuses returns the version statuses of the given provider
+// from the given source, or nil if the source doesn't implement
+// VersionStatusSource.
+func SourceVersionStatuses(ctx context.Context, source Source, provider addrs.Provider) (VersionStatuses, error) {
+	statusSource, ok := source.(VersionStatusSource)
+	if !ok {
+		return nil, nil
+	}
+	return statusSource.VersionStatuses(ctx, provider)
+}
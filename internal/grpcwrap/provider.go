@@ -5,7 +5,9 @@ package grpcwrap
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/opentofu/opentofu/internal/plugin"
 	"github.com/opentofu/opentofu/internal/plugin/convert"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/tfplugin5"
@@ -63,9 +65,18 @@ func (p *provider) GetSchema(_ context.Context, req *tfplugin5.GetProviderSchema
 	}
 
 	resp.ServerCapabilities = &tfplugin5.GetProviderSchema_ServerCapabilities{
-		PlanDestroy: p.schema.ServerCapabilities.PlanDestroy,
+		PlanDestroy:   p.schema.ServerCapabilities.PlanDestroy,
+		ListResources: p.schema.ServerCapabilities.ListResources,
+		ChunkedCalls:  true,
 	}
 
+	funcs, err := convert.FunctionDeclsToProto(p.schema.Functions)
+	if err != nil {
+		resp.Diagnostics = convert.AppendProtoDiag(resp.Diagnostics, err)
+		return resp, nil
+	}
+	resp.Functions = funcs
+
 	// include any diagnostics from the original GetSchema call
 	resp.Diagnostics = convert.AppendProtoDiag(resp.Diagnostics, p.schema.Diagnostics)
 
@@ -350,6 +361,33 @@ func (p *provider) ImportResourceState(_ context.Context, req *tfplugin5.ImportR
 	return resp, nil
 }
 
+func (p *provider) ListResources(_ context.Context, req *tfplugin5.ListResources_Request) (*tfplugin5.ListResources_Response, error) {
+	resp := &tfplugin5.ListResources_Response{}
+
+	listResp := p.provider.ListResources(providers.ListResourcesRequest{
+		TypeName: req.TypeName,
+	})
+	resp.Diagnostics = convert.AppendProtoDiag(resp.Diagnostics, listResp.Diagnostics)
+
+	ty := p.schema.ResourceTypes[req.TypeName].Block.ImpliedType()
+	for _, res := range listResp.Resources {
+		listed := &tfplugin5.ListResources_ListedResource{
+			Id: res.ID,
+		}
+		if res.State != cty.NilVal {
+			state, err := encodeDynamicValue(res.State, ty)
+			if err != nil {
+				resp.Diagnostics = convert.AppendProtoDiag(resp.Diagnostics, err)
+				continue
+			}
+			listed.State = state
+		}
+		resp.Resources = append(resp.Resources, listed)
+	}
+
+	return resp, nil
+}
+
 func (p *provider) ReadDataSource(_ context.Context, req *tfplugin5.ReadDataSource_Request) (*tfplugin5.ReadDataSource_Response, error) {
 	resp := &tfplugin5.ReadDataSource_Response{}
 	ty := p.schema.DataSources[req.TypeName].Block.ImpliedType()
@@ -386,6 +424,75 @@ func (p *provider) ReadDataSource(_ context.Context, req *tfplugin5.ReadDataSour
 	return resp, nil
 }
 
+func (p *provider) GetFunctions(context.Context, *tfplugin5.GetFunctions_Request) (*tfplugin5.GetFunctions_Response, error) {
+	resp := &tfplugin5.GetFunctions_Response{}
+
+	funcs, err := convert.FunctionDeclsToProto(p.schema.Functions)
+	if err != nil {
+		resp.Diagnostics = convert.AppendProtoDiag(resp.Diagnostics, err)
+		return resp, nil
+	}
+	resp.Functions = funcs
+
+	return resp, nil
+}
+
+func (p *provider) CallChunked(stream tfplugin5.Provider_CallChunkedServer) error {
+	return plugin.ServeChunked(p, stream)
+}
+
+func (p *provider) CallFunction(_ context.Context, req *tfplugin5.CallFunction_Request) (*tfplugin5.CallFunction_Response, error) {
+	resp := &tfplugin5.CallFunction_Response{}
+
+	decl, ok := p.schema.Functions[req.Name]
+	if !ok {
+		resp.Error = convert.FunctionErrorToProto(fmt.Errorf("unknown function %q", req.Name))
+		return resp, nil
+	}
+
+	args := make([]cty.Value, len(req.Arguments))
+	for i, arg := range req.Arguments {
+		var paramType cty.Type
+		switch {
+		case i < len(decl.Parameters):
+			paramType = decl.Parameters[i].Type
+		case decl.VariadicParameter != nil:
+			paramType = decl.VariadicParameter.Type
+		default:
+			resp.Error = convert.FunctionErrorToProto(fmt.Errorf("too many arguments for function %q", req.Name))
+			return resp, nil
+		}
+
+		var err error
+		args[i], err = decodeDynamicValue(arg, paramType)
+		if err != nil {
+			resp.Error = convert.FunctionErrorToProto(&providers.CallFunctionArgumentError{
+				Text:             err.Error(),
+				FunctionArgument: i,
+			})
+			return resp, nil
+		}
+	}
+
+	callResp := p.provider.CallFunction(providers.CallFunctionRequest{
+		Name:      req.Name,
+		Arguments: args,
+	})
+	if callResp.Error != nil {
+		resp.Error = convert.FunctionErrorToProto(callResp.Error)
+		return resp, nil
+	}
+
+	var err error
+	resp.Result, err = encodeDynamicValue(callResp.Result, decl.ReturnType)
+	if err != nil {
+		resp.Error = convert.FunctionErrorToProto(err)
+		return resp, nil
+	}
+
+	return resp, nil
+}
+
 func (p *provider) Stop(context.Context, *tfplugin5.Stop_Request) (*tfplugin5.Stop_Response, error) {
 	resp := &tfplugin5.Stop_Response{}
 	err := p.provider.Stop()
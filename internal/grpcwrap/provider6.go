@@ -5,7 +5,9 @@ package grpcwrap
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/opentofu/opentofu/internal/plugin6"
 	"github.com/opentofu/opentofu/internal/plugin6/convert"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/tfplugin6"
@@ -63,9 +65,18 @@ func (p *provider6) GetProviderSchema(_ context.Context, req *tfplugin6.GetProvi
 	}
 
 	resp.ServerCapabilities = &tfplugin6.GetProviderSchema_ServerCapabilities{
-		PlanDestroy: p.schema.ServerCapabilities.PlanDestroy,
+		PlanDestroy:   p.schema.ServerCapabilities.PlanDestroy,
+		ListResources: p.schema.ServerCapabilities.ListResources,
+		ChunkedCalls:  true,
 	}
 
+	funcs, err := convert.FunctionDeclsToProto(p.schema.Functions)
+	if err != nil {
+		resp.Diagnostics = convert.AppendProtoDiag(resp.Diagnostics, err)
+		return resp, nil
+	}
+	resp.Functions = funcs
+
 	// include any diagnostics from the original GetSchema call
 	resp.Diagnostics = convert.AppendProtoDiag(resp.Diagnostics, p.schema.Diagnostics)
 
@@ -350,6 +361,33 @@ func (p *provider6) ImportResourceState(_ context.Context, req *tfplugin6.Import
 	return resp, nil
 }
 
+func (p *provider6) ListResources(_ context.Context, req *tfplugin6.ListResources_Request) (*tfplugin6.ListResources_Response, error) {
+	resp := &tfplugin6.ListResources_Response{}
+
+	listResp := p.provider.ListResources(providers.ListResourcesRequest{
+		TypeName: req.TypeName,
+	})
+	resp.Diagnostics = convert.AppendProtoDiag(resp.Diagnostics, listResp.Diagnostics)
+
+	ty := p.schema.ResourceTypes[req.TypeName].Block.ImpliedType()
+	for _, res := range listResp.Resources {
+		listed := &tfplugin6.ListResources_ListedResource{
+			Id: res.ID,
+		}
+		if res.State != cty.NilVal {
+			state, err := encodeDynamicValue6(res.State, ty)
+			if err != nil {
+				resp.Diagnostics = convert.AppendProtoDiag(resp.Diagnostics, err)
+				continue
+			}
+			listed.State = state
+		}
+		resp.Resources = append(resp.Resources, listed)
+	}
+
+	return resp, nil
+}
+
 func (p *provider6) ReadDataSource(_ context.Context, req *tfplugin6.ReadDataSource_Request) (*tfplugin6.ReadDataSource_Response, error) {
 	resp := &tfplugin6.ReadDataSource_Response{}
 	ty := p.schema.DataSources[req.TypeName].Block.ImpliedType()
@@ -386,6 +424,75 @@ func (p *provider6) ReadDataSource(_ context.Context, req *tfplugin6.ReadDataSou
 	return resp, nil
 }
 
+func (p *provider6) GetFunctions(context.Context, *tfplugin6.GetFunctions_Request) (*tfplugin6.GetFunctions_Response, error) {
+	resp := &tfplugin6.GetFunctions_Response{}
+
+	funcs, err := convert.FunctionDeclsToProto(p.schema.Functions)
+	if err != nil {
+		resp.Diagnostics = convert.AppendProtoDiag(resp.Diagnostics, err)
+		return resp, nil
+	}
+	resp.Functions = funcs
+
+	return resp, nil
+}
+
+func (p *provider6) CallChunked(stream tfplugin6.Provider_CallChunkedServer) error {
+	return plugin6.ServeChunked(p, stream)
+}
+
+func (p *provider6) CallFunction(_ context.Context, req *tfplugin6.CallFunction_Request) (*tfplugin6.CallFunction_Response, error) {
+	resp := &tfplugin6.CallFunction_Response{}
+
+	decl, ok := p.schema.Functions[req.Name]
+	if !ok {
+		resp.Error = convert.FunctionErrorToProto(fmt.Errorf("unknown function %q", req.Name))
+		return resp, nil
+	}
+
+	args := make([]cty.Value, len(req.Arguments))
+	for i, arg := range req.Arguments {
+		var paramType cty.Type
+		switch {
+		case i < len(decl.Parameters):
+			paramType = decl.Parameters[i].Type
+		case decl.VariadicParameter != nil:
+			paramType = decl.VariadicParameter.Type
+		default:
+			resp.Error = convert.FunctionErrorToProto(fmt.Errorf("too many arguments for function %q", req.Name))
+			return resp, nil
+		}
+
+		var err error
+		args[i], err = decodeDynamicValue6(arg, paramType)
+		if err != nil {
+			resp.Error = convert.FunctionErrorToProto(&providers.CallFunctionArgumentError{
+				Text:             err.Error(),
+				FunctionArgument: i,
+			})
+			return resp, nil
+		}
+	}
+
+	callResp := p.provider.CallFunction(providers.CallFunctionRequest{
+		Name:      req.Name,
+		Arguments: args,
+	})
+	if callResp.Error != nil {
+		resp.Error = convert.FunctionErrorToProto(callResp.Error)
+		return resp, nil
+	}
+
+	var err error
+	resp.Result, err = encodeDynamicValue6(callResp.Result, decl.ReturnType)
+	if err != nil {
+		resp.Error = convert.FunctionErrorToProto(err)
+		return resp, nil
+	}
+
+	return resp, nil
+}
+
 func (p *provider6) StopProvider(context.Context, *tfplugin6.StopProvider_Request) (*tfplugin6.StopProvider_Response, error) {
 	resp := &tfplugin6.StopProvider_Response{}
 	err := p.provider.Stop()
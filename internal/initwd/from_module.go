@@ -47,7 +47,7 @@ const initFromModuleRootKeyPrefix = initFromModuleRootCallName + "."
 // references using ../ from that module to be unresolvable. Error diagnostics
 // are produced in that case, to prompt the user to rewrite the source strings
 // to be absolute references to the original remote module.
-func DirFromModule(ctx context.Context, loader *configload.Loader, rootDir, modulesDir, sourceAddrStr string, reg *registry.Client, hooks ModuleInstallHooks) tfdiags.Diagnostics {
+func DirFromModule(ctx context.Context, loader *configload.Loader, rootDir, modulesDir, sourceAddrStr string, reg *registry.Client, ociSigningKeys []*getmodules.OCISigningKeys, hooks ModuleInstallHooks) tfdiags.Diagnostics {
 
 	var diags tfdiags.Diagnostics
 
@@ -164,6 +164,7 @@ func DirFromModule(ctx context.Context, loader *configload.Loader, rootDir, modu
 		Dir: rootDir,
 	}
 	fetcher := getmodules.NewPackageFetcher()
+	fetcher.SetOCISigningKeys(ociSigningKeys)
 
 	walker := inst.moduleInstallWalker(ctx, instManifest, true, wrapHooks, fetcher)
 	_, cDiags := inst.installDescendentModules(fakeRootModule, instManifest, walker, true)
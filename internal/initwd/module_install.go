@@ -34,6 +34,29 @@ type ModuleInstaller struct {
 	loader  *configload.Loader
 	reg     *registry.Client
 
+	// offline prevents installing any module package which would need to be
+	// downloaded, so that only local modules and previously-installed
+	// remote modules can be used.
+	offline bool
+
+	// ociSigningKeys are the keys trusted to sign module packages in OCI
+	// repositories.
+	ociSigningKeys []*getmodules.OCISigningKeys
+
+	// moduleAliases are the directories that module alias source addresses
+	// refer to, keyed by alias name.
+	moduleAliases map[string]string
+
+	// partialPackages makes the installer fetch only the subdirectory of a
+	// module package that a module source address refers to, where the
+	// package's source supports that.
+	partialPackages bool
+
+	// backendStorage is the object storage client of the state backend,
+	// if any, and backendStorageScheme is its go-getter scheme.
+	backendStorageScheme string
+	backendStorage       getmodules.ObjectStorage
+
 	// The keys in moduleVersions are resolved and trimmed registry source
 	// addresses and the values are the registry response.
 	registryPackageVersions map[addrs.ModuleRegistryPackage]*response.ModuleVersions
@@ -58,6 +81,44 @@ func NewModuleInstaller(modsDir string, loader *configload.Loader, reg *registry
 	}
 }
 
+// SetOffline controls whether the installer may download module packages.
+//
+// When offline, remote modules must already be installed in the modules
+// directory with a matching source address and version, and requests to
+// upgrade them are ignored. Local modules are unaffected.
+func (i *ModuleInstaller) SetOffline(offline bool) {
+	i.offline = offline
+}
+
+// SetOCISigningKeys sets the keys that are trusted to sign module packages
+// in OCI repositories, as for getmodules.PackageFetcher.SetOCISigningKeys.
+func (i *ModuleInstaller) SetOCISigningKeys(keys []*getmodules.OCISigningKeys) {
+	i.ociSigningKeys = keys
+}
+
+// SetModuleAliases sets the directories that module alias source addresses
+// refer to, keyed by alias name.
+func (i *ModuleInstaller) SetModuleAliases(aliases map[string]string) {
+	i.moduleAliases = aliases
+}
+
+// SetPartialPackages controls whether the installer fetches only the
+// subdirectories of module packages that module source addresses refer to,
+// along with the files at the root of each package, rather than whole
+// packages. Only git repositories support this, and the installer fetches
+// other directories of a partially-fetched package when local module calls
+// refer to them.
+func (i *ModuleInstaller) SetPartialPackages(partial bool) {
+	i.partialPackages = partial
+}
+
+// SetBackendStorage sets the object storage client of the state backend, as
+// for getmodules.PackageFetcher.SetBackendStorage.
+func (i *ModuleInstaller) SetBackendStorage(scheme string, storage getmodules.ObjectStorage) {
+	i.backendStorageScheme = scheme
+	i.backendStorage = storage
+}
+
 // InstallModules analyses the root module in the given directory and installs
 // all of its direct and transitive dependencies into the given modules
 // directory, which must already exist.
@@ -122,6 +183,10 @@ func (i *ModuleInstaller) InstallModules(ctx context.Context, rootDir, testsDir
 	}
 
 	fetcher := getmodules.NewPackageFetcher()
+	fetcher.SetOCISigningKeys(i.ociSigningKeys)
+	if i.backendStorage != nil {
+		fetcher.SetBackendStorage(i.backendStorageScheme, i.backendStorage)
+	}
 
 	if hooks == nil {
 		// Use our no-op implementation as a placeholder
@@ -177,7 +242,9 @@ func (i *ModuleInstaller) moduleInstallWalker(ctx context.Context, manifest mods
 
 			// First we'll check if we need to upgrade/replace an existing
 			// installed module, and delete it out of the way if so.
-			replace := upgrade
+			// Upgrading remote modules would require downloading them again,
+			// so in offline mode we keep whatever is already installed.
+			replace := upgrade && !i.offline
 			if !replace {
 				record, recorded := manifest[key]
 				switch {
@@ -190,6 +257,16 @@ func (i *ModuleInstaller) moduleInstallWalker(ctx context.Context, manifest mods
 				case record.Version != nil && !req.VersionConstraint.Required.Check(record.Version):
 					log.Printf("[TRACE] ModuleInstaller: %s version %s no longer compatible with constraints %s", key, record.Version, req.VersionConstraint.Required)
 					replace = true
+				default:
+					// A module alias refers to a different directory if
+					// the project configuration changed since it was
+					// installed.
+					if addr, ok := req.SourceAddr.(addrs.ModuleSourceAlias); ok {
+						if dir, err := i.moduleAliasDir(addr); err != nil || dir != record.Dir {
+							log.Printf("[TRACE] ModuleInstaller: %s module alias no longer refers to %s", key, record.Dir)
+							replace = true
+						}
+					}
 				}
 			}
 
@@ -261,15 +338,34 @@ func (i *ModuleInstaller) moduleInstallWalker(ctx context.Context, manifest mods
 			// the module. There are some variants to this process depending
 			// on what type of module source address we have.
 
+			if !isLocalSourceAddr(req.SourceAddr) && i.offline {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Module not available offline",
+					Detail: fmt.Sprintf(
+						"Module %q (from %s) is not installed in this working directory, and OpenTofu cannot download it in offline mode.\n\nRun \"tofu init\" with network access to install it, or change the source address to a local path containing a copy of the module package.",
+						key, req.SourceAddr,
+					),
+					Subject: req.SourceAddrRange.Ptr(),
+				})
+				return nil, nil, diags
+			}
+
 			switch addr := req.SourceAddr.(type) {
 
 			case addrs.ModuleSourceLocal:
 				log.Printf("[TRACE] ModuleInstaller: %s has local path %q", key, addr.String())
-				mod, mDiags := i.installLocalModule(req, key, manifest, hooks)
+				mod, mDiags := i.installLocalModule(ctx, req, key, manifest, hooks)
 				mDiags = maybeImproveLocalInstallError(req, mDiags)
 				diags = append(diags, mDiags...)
 				return mod, nil, diags
 
+			case addrs.ModuleSourceAlias:
+				log.Printf("[TRACE] ModuleInstaller: %s uses module alias %q", key, addr.String())
+				mod, mDiags := i.installAliasModule(req, key, addr, manifest, hooks)
+				diags = append(diags, mDiags...)
+				return mod, nil, diags
+
 			case addrs.ModuleSourceRegistry:
 				log.Printf("[TRACE] ModuleInstaller: %s is a registry module at %s", key, addr.String())
 				mod, v, mDiags := i.installRegistryModule(ctx, req, key, instPath, addr, manifest, hooks, fetcher)
@@ -343,7 +439,7 @@ func (i *ModuleInstaller) installDescendentModules(rootMod *configs.Module, mani
 	return cfg, diags
 }
 
-func (i *ModuleInstaller) installLocalModule(req *configs.ModuleRequest, key string, manifest modsdir.Manifest, hooks ModuleInstallHooks) (*configs.Module, hcl.Diagnostics) {
+func (i *ModuleInstaller) installLocalModule(ctx context.Context, req *configs.ModuleRequest, key string, manifest modsdir.Manifest, hooks ModuleInstallHooks) (*configs.Module, hcl.Diagnostics) {
 	var diags hcl.Diagnostics
 
 	parentKey := manifest.ModuleKey(req.Parent.Path)
@@ -367,6 +463,20 @@ func (i *ModuleInstaller) installLocalModule(req *configs.ModuleRequest, key str
 	// the files we need, and so we just load up what's already here.
 	newDir := filepath.Join(parentRecord.Dir, req.SourceAddr.String())
 
+	// The directory may be missing only because the package containing
+	// it was fetched partially.
+	if _, err := os.Stat(newDir); os.IsNotExist(err) {
+		if err := i.widenPartialPackage(ctx, req, newDir); err != nil {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Failed to download module",
+				Detail:   fmt.Sprintf("Could not download the directory of module %q (%s:%d) from its module package: %s.", req.Name, req.CallRange.Filename, req.CallRange.Start.Line, err),
+				Subject:  req.CallRange.Ptr(),
+			})
+			return nil, diags
+		}
+	}
+
 	log.Printf("[TRACE] ModuleInstaller: %s uses directory from parent: %s", key, newDir)
 	// it is possible that the local directory is a symlink
 	newDir, err := filepath.EvalSymlinks(newDir)
@@ -378,6 +488,105 @@ func (i *ModuleInstaller) installLocalModule(req *configs.ModuleRequest, key str
 		})
 	}
 
+	mod, mDiags := i.installModuleDir(req, key, newDir, manifest, hooks)
+	diags = diags.Extend(mDiags)
+	return mod, diags
+}
+
+// widenPartialPackage fetches the given directory of the module package that
+// the given local module call belongs to, if that package was fetched only
+// partially and contains the directory.
+func (i *ModuleInstaller) widenPartialPackage(ctx context.Context, req *configs.ModuleRequest, dir string) error {
+	if !i.partialPackages {
+		return nil
+	}
+	for current := req.Parent; current != nil && current.SourceAddr != nil; current = current.Parent {
+		if _, ok := current.SourceAddr.(addrs.ModuleSourceLocal); ok {
+			continue
+		}
+		if isLocalSourceAddr(current.SourceAddr) {
+			return nil
+		}
+		packageDir := i.packageInstallPath(current.Path)
+		rel, err := filepath.Rel(packageDir, dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil
+		}
+		_, err = getmodules.WidenPartialPackage(ctx, packageDir, rel)
+		return err
+	}
+	return nil
+}
+
+// installAliasModule installs a module whose source address is a module
+// alias, which refers to a directory given in the project configuration.
+// Like a local module, it's used from where it is rather than copied.
+func (i *ModuleInstaller) installAliasModule(req *configs.ModuleRequest, key string, addr addrs.ModuleSourceAlias, manifest modsdir.Manifest, hooks ModuleInstallHooks) (*configs.Module, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	if len(req.VersionConstraint.Required) != 0 {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid version constraint",
+			Detail:   fmt.Sprintf("Cannot apply a version constraint to module %q (at %s:%d) because it uses a module alias.", req.Name, req.CallRange.Filename, req.CallRange.Start.Line),
+			Subject:  req.CallRange.Ptr(),
+		})
+	}
+
+	// Module aliases belong to the project, so a module downloaded from
+	// elsewhere can't use them.
+	for current := req.Parent; current != nil && current.SourceAddr != nil; current = current.Parent {
+		if _, ok := current.SourceAddr.(addrs.ModuleSourceLocal); ok {
+			continue
+		}
+		if _, ok := current.SourceAddr.(addrs.ModuleSourceAlias); !ok {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Module alias in remote module",
+				Detail:   fmt.Sprintf("Module %q uses the module alias source address %q, but it's called from module %s, which was installed from %s. Module aliases can only be used by the modules of the current project.", req.Name, addr, current.Path, current.SourceAddr.ForDisplay()),
+				Subject:  req.SourceAddrRange.Ptr(),
+			})
+			return nil, diags
+		}
+		break
+	}
+
+	newDir, err := i.moduleAliasDir(addr)
+	if err != nil {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid module alias",
+			Detail:   fmt.Sprintf("Cannot install module %q: %s.", req.Name, err),
+			Subject:  req.SourceAddrRange.Ptr(),
+		})
+		return nil, diags
+	}
+	log.Printf("[TRACE] ModuleInstaller: %s uses directory from module alias %q: %s", key, addr.Alias, newDir)
+
+	mod, mDiags := i.installModuleDir(req, key, newDir, manifest, hooks)
+	diags = diags.Extend(mDiags)
+	return mod, diags
+}
+
+// moduleAliasDir returns the directory that the given module alias source
+// address refers to, with any symlinks evaluated.
+func (i *ModuleInstaller) moduleAliasDir(addr addrs.ModuleSourceAlias) (string, error) {
+	aliasDir, ok := i.moduleAliases[addr.Alias]
+	if !ok {
+		return "", fmt.Errorf("there is no module alias named %q; module aliases are defined by the module_aliases argument in the project configuration file, tofu.project.hcl", addr.Alias)
+	}
+	dir, err := filepath.EvalSymlinks(filepath.Join(aliasDir, filepath.FromSlash(addr.Path)))
+	if err != nil {
+		return "", fmt.Errorf("the directory of %s cannot be read: %w", addr, err)
+	}
+	return dir, nil
+}
+
+// installModuleDir loads a module from the given directory, which is already
+// on the local filesystem, and records it in the manifest.
+func (i *ModuleInstaller) installModuleDir(req *configs.ModuleRequest, key string, newDir string, manifest modsdir.Manifest, hooks ModuleInstallHooks) (*configs.Module, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
 	// Finally we are ready to try actually loading the module.
 	mod, mDiags := i.loader.Parser().LoadConfigDir(newDir)
 	if mod == nil {
@@ -480,7 +689,10 @@ func (i *ModuleInstaller) installRegistryModule(ctx context.Context, req *config
 	modMeta := resp.Modules[0]
 
 	var latestMatch *version.Version
+	var latestMatchMeta *response.ModuleVersion
 	var latestVersion *version.Version
+	var yankedMatch *version.Version
+	var yankedMatchMeta *response.ModuleVersion
 	for _, mv := range modMeta.Versions {
 		v, err := version.NewVersion(mv.Version)
 		if err != nil {
@@ -539,6 +751,17 @@ func (i *ModuleInstaller) installRegistryModule(ctx context.Context, req *config
 			// for consistency.
 		}
 
+		if mv.Yanked != nil {
+			// The publisher has withdrawn this version, so we never select
+			// it, but we remember it to explain why nothing matches.
+			log.Printf("[TRACE] ModuleInstaller: %s ignoring %s because it has been yanked", key, v)
+			if req.VersionConstraint.Required.Check(v) && (yankedMatch == nil || v.GreaterThan(yankedMatch)) {
+				yankedMatch = v
+				yankedMatchMeta = mv
+			}
+			continue
+		}
+
 		if latestVersion == nil || v.GreaterThan(latestVersion) {
 			latestVersion = v
 		}
@@ -546,10 +769,21 @@ func (i *ModuleInstaller) installRegistryModule(ctx context.Context, req *config
 		if req.VersionConstraint.Required.Check(v) {
 			if latestMatch == nil || v.GreaterThan(latestMatch) {
 				latestMatch = v
+				latestMatchMeta = mv
 			}
 		}
 	}
 
+	if latestMatch == nil && yankedMatch != nil {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Module version has been yanked",
+			Detail:   fmt.Sprintf("The only versions of module %q (%s:%d) which match the given version constraint have been yanked by the publisher, so OpenTofu won't install them. Version %s was yanked: %s", addr, req.CallRange.Filename, req.CallRange.Start.Line, yankedMatch, yankedMatchMeta.Yanked),
+			Subject:  req.CallRange.Ptr(),
+		})
+		return nil, nil, diags
+	}
+
 	if latestVersion == nil {
 		diags = diags.Append(&hcl.Diagnostic{
 			Severity: hcl.DiagError,
@@ -570,6 +804,15 @@ func (i *ModuleInstaller) installRegistryModule(ctx context.Context, req *config
 		return nil, nil, diags
 	}
 
+	if latestMatchMeta.Deprecation != nil {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Deprecated module version",
+			Detail:   fmt.Sprintf("The publisher of module %q (%s:%d) has deprecated version %s: %s\n\nConsider changing the version constraint to select a newer version, and then run tofu init -upgrade.", addr, req.CallRange.Filename, req.CallRange.Start.Line, latestMatch, latestMatchMeta.Deprecation),
+			Subject:  req.CallRange.Ptr(),
+		})
+	}
+
 	// Report up to the caller that we're about to start downloading.
 	hooks.Download(key, packageAddr.String(), latestMatch)
 
@@ -620,7 +863,16 @@ func (i *ModuleInstaller) installRegistryModule(ctx context.Context, req *config
 
 	log.Printf("[TRACE] ModuleInstaller: %s %s %s is available at %q", key, packageAddr, latestMatch, dlAddr.Package)
 
-	err := fetcher.FetchPackage(ctx, instPath, dlAddr.Package.String())
+	// Incorporate any subdir information from the original path into the
+	// address returned by the registry in order to find the final directory
+	// of the target module.
+	finalAddr := dlAddr.FromRegistry(addr)
+
+	fetchAddr := dlAddr.Package.String()
+	if i.partialPackages {
+		fetchAddr = getmodules.PartialPackageAddr(fetchAddr, finalAddr.Subdir)
+	}
+	err := fetcher.FetchPackage(ctx, instPath, fetchAddr)
 	if errors.Is(err, context.Canceled) {
 		diags = diags.Append(&hcl.Diagnostic{
 			Severity: hcl.DiagError,
@@ -646,10 +898,6 @@ func (i *ModuleInstaller) installRegistryModule(ctx context.Context, req *config
 
 	log.Printf("[TRACE] ModuleInstaller: %s %q was downloaded to %s", key, dlAddr.Package, instPath)
 
-	// Incorporate any subdir information from the original path into the
-	// address returned by the registry in order to find the final directory
-	// of the target module.
-	finalAddr := dlAddr.FromRegistry(addr)
 	subDir := filepath.FromSlash(finalAddr.Subdir)
 	modDir := filepath.Join(instPath, subDir)
 
@@ -708,7 +956,11 @@ func (i *ModuleInstaller) installGoGetterModule(ctx context.Context, req *config
 		return nil, diags
 	}
 
-	err := fetcher.FetchPackage(ctx, instPath, packageAddr.String())
+	fetchAddr := packageAddr.String()
+	if i.partialPackages {
+		fetchAddr = getmodules.PartialPackageAddr(fetchAddr, addr.Subdir)
+	}
+	err := fetcher.FetchPackage(ctx, instPath, fetchAddr)
 	if err != nil {
 		// go-getter generates a poor error for an invalid relative path, so
 		// we'll detect that case and generate a better one.
@@ -790,6 +1042,17 @@ func (i *ModuleInstaller) installGoGetterModule(ctx context.Context, req *config
 	return mod, diags
 }
 
+// isLocalSourceAddr returns true if the given module source address refers to
+// a directory on the local filesystem, and so never needs to be downloaded.
+func isLocalSourceAddr(addr addrs.ModuleSource) bool {
+	switch addr.(type) {
+	case addrs.ModuleSourceLocal, addrs.ModuleSourceAlias:
+		return true
+	default:
+		return false
+	}
+}
+
 func (i *ModuleInstaller) packageInstallPath(modulePath addrs.Module) string {
 	return filepath.Join(i.modsDir, strings.Join(modulePath, "."))
 }
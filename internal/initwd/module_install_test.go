@@ -9,6 +9,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -24,6 +25,7 @@ import (
 	"github.com/opentofu/opentofu/internal/configs/configload"
 	"github.com/opentofu/opentofu/internal/copy"
 	"github.com/opentofu/opentofu/internal/registry"
+	registrytest "github.com/opentofu/opentofu/internal/registry/test"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 
 	_ "github.com/opentofu/opentofu/internal/logging"
@@ -97,6 +99,153 @@ func TestModuleInstaller(t *testing.T) {
 	assertResultDeepEqual(t, gotTraces, wantTraces)
 }
 
+func TestModuleInstaller_moduleAliases(t *testing.T) {
+	fixtureDir := filepath.Clean("testdata/module-aliases")
+	dir, done := tempChdir(t, fixtureDir)
+	defer done()
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	modulesDir := filepath.Join(dir, ".terraform/modules")
+	loader, close := configload.NewLoaderForTests(t)
+	defer close()
+
+	install := func(t *testing.T, aliasDir string) []testInstallHookCall {
+		t.Helper()
+		hooks := &testInstallHooks{}
+		inst := NewModuleInstaller(modulesDir, loader, nil)
+		inst.SetModuleAliases(map[string]string{
+			"networking": filepath.Join(dir, "modules", aliasDir),
+		})
+		_, diags := inst.InstallModules(context.Background(), ".", "tests", false, false, hooks)
+		assertNoDiagnostics(t, diags)
+		return hooks.Calls
+	}
+	// The configuration is loadable without the module aliases, because the
+	// installer records the directories they refer to in the manifest.
+	loadTraces := func(t *testing.T) map[string]string {
+		t.Helper()
+		loader, err := configload.NewLoader(&configload.Config{
+			ModulesDir: modulesDir,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		config, loadDiags := loader.LoadConfig(".")
+		assertNoDiagnostics(t, tfdiags.Diagnostics{}.Append(loadDiags))
+		got := map[string]string{}
+		config.DeepEach(func(c *configs.Config) {
+			got[strings.Join(c.Path, ".")] = c.Module.Variables["v"].Description
+		})
+		return got
+	}
+
+	vpcDir := filepath.Join(dir, "modules", "networking", "vpc")
+	assertResultDeepEqual(t, install(t, "networking"), []testInstallHookCall{
+		{Name: "Install", ModuleAddr: "vpc", LocalPath: vpcDir},
+		{Name: "Install", ModuleAddr: "vpc.subnet", LocalPath: filepath.Join(vpcDir, "subnet")},
+	})
+	assertResultDeepEqual(t, loadTraces(t), map[string]string{
+		"":           "in root module",
+		"vpc":        "in vpc module from networking",
+		"vpc.subnet": "in subnet module from networking",
+	})
+
+	// Moving the aliased directory reinstalls the modules from the new one.
+	vpcDir = filepath.Join(dir, "modules", "networking-v2", "vpc")
+	assertResultDeepEqual(t, install(t, "networking-v2"), []testInstallHookCall{
+		{Name: "Install", ModuleAddr: "vpc", LocalPath: vpcDir},
+		{Name: "Install", ModuleAddr: "vpc.subnet", LocalPath: filepath.Join(vpcDir, "subnet")},
+	})
+	assertResultDeepEqual(t, loadTraces(t), map[string]string{
+		"":           "in root module",
+		"vpc":        "in vpc module from networking-v2",
+		"vpc.subnet": "in subnet module from networking-v2",
+	})
+
+	// Without the alias, the modules can't be installed.
+	inst := NewModuleInstaller(modulesDir, loader, nil)
+	_, diags := inst.InstallModules(context.Background(), ".", "tests", true, false, &testInstallHooks{})
+	if !diags.HasErrors() {
+		t.Fatal("expected error")
+	}
+	assertDiagnosticSummary(t, diags, "Invalid module alias")
+}
+
+func TestModuleInstaller_partialPackages(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	writeFiles := func(t *testing.T, dir string, files map[string]string) {
+		t.Helper()
+		for name, content := range files {
+			path := filepath.Join(dir, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	repo := t.TempDir()
+	writeFiles(t, repo, map[string]string{
+		"modules/vpc/main.tf":     "module \"subnets\" {\n  source = \"../subnets\"\n}\n",
+		"modules/subnets/main.tf": "# subnets\n",
+		"examples/main.tf":        "# examples\n",
+	})
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"config", "uploadpack.allowFilter", "true"},
+		{"add", "."},
+		{"commit", "--quiet", "-m", "first"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s failed: %s\n%s", args[0], err, out)
+		}
+	}
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.tf": fmt.Sprintf("module \"vpc\" {\n  source = \"git::file://%s//modules/vpc\"\n}\n", filepath.ToSlash(repo)),
+	})
+	modulesDir := filepath.Join(dir, ".terraform", "modules")
+	if err := os.MkdirAll(modulesDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	loader, close := configload.NewLoaderForTests(t)
+	defer close()
+
+	inst := NewModuleInstaller(modulesDir, loader, nil)
+	inst.SetPartialPackages(true)
+	_, diags := inst.InstallModules(context.Background(), dir, "tests", false, false, &testInstallHooks{})
+	assertNoDiagnostics(t, diags)
+
+	packageDir := filepath.Join(modulesDir, "vpc")
+	for name, want := range map[string]bool{
+		"modules/vpc/main.tf": true,
+		// The vpc module refers to the subnets module, so the installer
+		// fetches it too.
+		"modules/subnets/main.tf": true,
+		"examples/main.tf":        false,
+	} {
+		_, err := os.Stat(filepath.Join(packageDir, filepath.FromSlash(name)))
+		if got := err == nil; got != want {
+			t.Errorf("wrong presence of %s: got %t, want %t", name, got, want)
+		}
+	}
+}
+
 func TestModuleInstaller_error(t *testing.T) {
 	fixtureDir := filepath.Clean("testdata/local-module-error")
 	dir, done := tempChdir(t, fixtureDir)
@@ -287,6 +436,39 @@ func TestModuleInstaller_PartialMatchPrerelease(t *testing.T) {
 	}
 }
 
+func TestModuleInstaller_withdrawnVersions(t *testing.T) {
+	server := registrytest.Registry()
+	defer server.Close()
+
+	fixtureDir := filepath.Clean("testdata/registry-withdrawn-versions")
+	dir, done := tempChdir(t, fixtureDir)
+	defer done()
+
+	hooks := &testInstallHooks{}
+
+	modulesDir := filepath.Join(dir, ".terraform/modules")
+
+	loader, close := configload.NewLoaderForTests(t)
+	defer close()
+	inst := NewModuleInstaller(modulesDir, loader, registry.NewClient(registrytest.Disco(server), nil))
+	_, diags := inst.InstallModules(context.Background(), ".", "tests", false, false, hooks)
+
+	// The deprecated version is selected with a warning, although the test
+	// registry can't provide its package, and the yanked version isn't.
+	assertDiagnosticSummary(t, diags, "Deprecated module version")
+	assertDiagnosticSummary(t, diags, "Failed to download module")
+	if assertDiagnosticSummary(t, diags, "Module version has been yanked") {
+		return
+	}
+	for _, diag := range diags {
+		if diag.Description().Summary == "Module version has been yanked" {
+			if got, want := diag.Description().Detail, "Version 1.1.0 was yanked: broken (https://example.com/withdrawn)"; !strings.Contains(got, want) {
+				t.Errorf("wrong detail %q; want it to contain %q", got, want)
+			}
+		}
+	}
+}
+
 func TestModuleInstaller_invalid_version_constraint_error(t *testing.T) {
 	fixtureDir := filepath.Clean("testdata/invalid-version-constraint")
 	dir, done := tempChdir(t, fixtureDir)
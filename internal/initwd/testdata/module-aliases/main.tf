@@ -0,0 +1,8 @@
+variable "v" {
+  description = "in root module"
+  default     = ""
+}
+
+module "vpc" {
+  source = "alias::networking/vpc"
+}
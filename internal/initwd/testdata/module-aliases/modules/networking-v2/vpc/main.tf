@@ -0,0 +1,8 @@
+variable "v" {
+  description = "in vpc module from networking-v2"
+  default     = ""
+}
+
+module "subnet" {
+  source = "./subnet"
+}
@@ -0,0 +1,4 @@
+variable "v" {
+  description = "in subnet module from networking"
+  default     = ""
+}
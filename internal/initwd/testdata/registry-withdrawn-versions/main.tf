@@ -0,0 +1,9 @@
+module "deprecated" {
+  source  = "example.com/withdrawn/name/provider"
+  version = "1.0.0"
+}
+
+module "yanked" {
+  source  = "example.com/withdrawn/name/provider"
+  version = "1.1.0"
+}
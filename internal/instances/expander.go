@@ -56,6 +56,17 @@ func (e *Expander) SetModuleSingle(parentAddr addrs.ModuleInstance, callAddr add
 	e.setModuleExpansion(parentAddr, callAddr, expansionSingleVal)
 }
 
+// SetModuleEnabled records that the given module call inside the given parent
+// module instance uses the "enabled" argument, with the given value. An
+// enabled module call is a singleton, while a disabled one has no instances.
+func (e *Expander) SetModuleEnabled(parentAddr addrs.ModuleInstance, callAddr addrs.ModuleCall, enabled bool) {
+	if enabled {
+		e.setModuleExpansion(parentAddr, callAddr, expansionSingleVal)
+		return
+	}
+	e.setModuleExpansion(parentAddr, callAddr, expansionDisabledVal)
+}
+
 // SetModuleCount records that the given module call inside the given parent
 // module instance uses the "count" repetition argument, with the given value.
 func (e *Expander) SetModuleCount(parentAddr addrs.ModuleInstance, callAddr addrs.ModuleCall, count int) {
@@ -20,6 +20,8 @@ func TestExpander(t *testing.T) {
 	count2ModuleAddr := addrs.ModuleCall{Name: "count2"}
 	count0ModuleAddr := addrs.ModuleCall{Name: "count0"}
 	forEachModuleAddr := addrs.ModuleCall{Name: "for_each"}
+	enabledModuleAddr := addrs.ModuleCall{Name: "enabled"}
+	disabledModuleAddr := addrs.ModuleCall{Name: "disabled"}
 	singleResourceAddr := addrs.Resource{
 		Mode: addrs.ManagedResourceMode,
 		Type: "test",
@@ -69,6 +71,10 @@ func TestExpander(t *testing.T) {
 	//   - child module for_each with for_each = { a = 1, b = 2 }
 	//     - resource test.single with no count or for_each
 	//     - resource test.count2 with count = 2
+	//   - child module "enabled" with enabled = true
+	//     - resource test.single with no count or for_each
+	//   - child module "disabled" with enabled = false
+	//     - resource test.single with no count or for_each
 
 	ex := NewExpander()
 
@@ -121,6 +127,18 @@ func TestExpander(t *testing.T) {
 			ex.SetResourceSingle(moduleInstanceAddr, singleResourceAddr)
 			ex.SetResourceCount(moduleInstanceAddr, count2ResourceAddr, 2)
 		}
+
+		ex.SetModuleEnabled(addrs.RootModuleInstance, enabledModuleAddr, true)
+		{
+			moduleInstanceAddr := addrs.RootModuleInstance.Child("enabled", addrs.NoKey)
+			ex.SetResourceSingle(moduleInstanceAddr, singleResourceAddr)
+		}
+
+		ex.SetModuleEnabled(addrs.RootModuleInstance, disabledModuleAddr, false)
+		{
+			// As with module "count0", nothing inside the disabled module
+			// would get registered.
+		}
 	}
 
 	t.Run("root module", func(t *testing.T) {
@@ -378,6 +396,25 @@ func TestExpander(t *testing.T) {
 			t.Errorf("wrong result\n%s", diff)
 		}
 	})
+	t.Run("module enabled resource single", func(t *testing.T) {
+		got := ex.ExpandModuleResource(
+			mustModuleAddr(`enabled`),
+			singleResourceAddr,
+		)
+		want := []addrs.AbsResourceInstance{
+			mustAbsResourceInstanceAddr(`module.enabled.test.single`),
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("wrong result\n%s", diff)
+		}
+	})
+	t.Run("module disabled", func(t *testing.T) {
+		got := ex.ExpandModule(mustModuleAddr(`disabled`))
+		want := []addrs.ModuleInstance(nil)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("wrong result\n%s", diff)
+		}
+	})
 	t.Run("module for_each", func(t *testing.T) {
 		got := ex.ExpandModule(mustModuleAddr(`for_each`))
 		want := []addrs.ModuleInstance{
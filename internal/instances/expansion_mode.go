@@ -40,6 +40,22 @@ func (e expansionSingle) repetitionData(key addrs.InstanceKey) RepetitionData {
 	return RepetitionData{}
 }
 
+// expansionDisabled is the expansion corresponding to an "enabled" argument
+// whose value is false, producing no objects at all.
+//
+// expansionDisabledVal is the only valid value of this type.
+type expansionDisabled uintptr
+
+var expansionDisabledVal expansionDisabled
+
+func (e expansionDisabled) instanceKeys() []addrs.InstanceKey {
+	return nil
+}
+
+func (e expansionDisabled) repetitionData(key addrs.InstanceKey) RepetitionData {
+	panic(fmt.Sprintf("instance key %s does not match any instance of a disabled object", key))
+}
+
 // expansionCount is the expansion corresponding to the "count" argument.
 type expansionCount int
 
@@ -5,10 +5,12 @@ package lang
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/ext/dynblock"
 	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/convert"
 
@@ -69,7 +71,7 @@ func (s *Scope) EvalBlock(body hcl.Body, schema *configschema.Block) (cty.Value,
 	body = blocktoattr.FixUpBlockAttrs(body, schema)
 
 	val, evalDiags := hcldec.Decode(body, spec, ctx)
-	diags = diags.Append(evalDiags)
+	diags = diags.Append(s.explainUnavailableProviderFunctions(evalDiags))
 
 	return val, diags
 }
@@ -78,6 +80,9 @@ func (s *Scope) EvalBlock(body hcl.Body, schema *configschema.Block) (cty.Value,
 // object and instance key data. References to the object must use self, and the
 // key data will only contain count.index or each.key. The static values for
 // terraform and path will also be available in this context.
+//
+// Unlike EvalBlock, this function also expands any "dynamic" blocks in the
+// body, whose for_each expressions are subject to the same restrictions.
 func (s *Scope) EvalSelfBlock(body hcl.Body, self cty.Value, schema *configschema.Block, keyData instances.RepetitionData) (cty.Value, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 
@@ -97,7 +102,7 @@ func (s *Scope) EvalSelfBlock(body hcl.Body, self cty.Value, schema *configschem
 		})
 	}
 
-	refs, refDiags := References(s.ParseRef, hcldec.Variables(body, spec))
+	refs, refDiags := References(s.ParseRef, dynblock.VariablesHCLDec(body, spec))
 	diags = diags.Append(refDiags)
 
 	terraformAttrs := map[string]cty.Value{}
@@ -146,8 +151,9 @@ func (s *Scope) EvalSelfBlock(body hcl.Body, self cty.Value, schema *configschem
 		Functions: s.Functions(),
 	}
 
-	val, decDiags := hcldec.Decode(body, schema.DecoderSpec(), ctx)
-	diags = diags.Append(decDiags)
+	body = dynblock.Expand(body, ctx)
+	val, decDiags := hcldec.Decode(body, spec, ctx)
+	diags = diags.Append(s.explainUnavailableProviderFunctions(decDiags))
 	return val, diags
 }
 
@@ -173,7 +179,7 @@ func (s *Scope) EvalExpr(expr hcl.Expression, wantType cty.Type) (cty.Value, tfd
 	}
 
 	val, evalDiags := expr.Value(ctx)
-	diags = diags.Append(evalDiags)
+	diags = diags.Append(s.explainUnavailableProviderFunctions(evalDiags))
 
 	if wantType != cty.DynamicPseudoType {
 		var convErr error
@@ -478,3 +484,31 @@ func normalizeRefValue(val cty.Value, diags tfdiags.Diagnostics) (cty.Value, tfd
 	}
 	return val, diags
 }
+
+// explainUnavailableProviderFunctions replaces the errors for calls to
+// unknown functions of providers that offer no functions with errors that
+// explain why, as recorded by SetUnavailableProviderFunctions.
+func (s *Scope) explainUnavailableProviderFunctions(diags hcl.Diagnostics) hcl.Diagnostics {
+	if len(s.unavailableProviderFunctions) == 0 {
+		return diags
+	}
+	for i, diag := range diags {
+		extra, ok := hcl.DiagnosticExtra[hclsyntax.FunctionCallUnknownDiagExtra](diag)
+		if !ok {
+			continue
+		}
+		localName, ok := strings.CutPrefix(extra.CalledFunctionNamespace(), "provider::")
+		if !ok {
+			continue
+		}
+		reason, ok := s.unavailableProviderFunctions[strings.TrimSuffix(localName, "::")]
+		if !ok {
+			continue
+		}
+		replacement := *diag
+		replacement.Summary = "Provider function not available"
+		replacement.Detail = fmt.Sprintf("There is no function named %q. %s", extra.CalledFunctionNamespace()+extra.CalledFunctionName(), reason)
+		diags[i] = &replacement
+	}
+	return diags
+}
@@ -848,3 +848,59 @@ func TestScopeEvalSelfBlock(t *testing.T) {
 		})
 	}
 }
+
+func TestScopeEvalSelfBlock_dynamic(t *testing.T) {
+	schema := &configschema.Block{
+		BlockTypes: map[string]*configschema.NestedBlock{
+			"step": {
+				Nesting: configschema.NestingList,
+				Block: configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"name": {
+							Type:     cty.String,
+							Optional: true,
+						},
+					},
+				},
+			},
+		},
+	}
+	config := `
+dynamic "step" {
+  for_each = self.steps
+  content {
+    name = "${step.value}-${count.index}"
+  }
+}
+`
+	file, parseDiags := hclsyntax.ParseConfig([]byte(config), "", hcl.Pos{Line: 1, Column: 1})
+	if parseDiags.HasErrors() {
+		t.Fatal(parseDiags.Error())
+	}
+
+	scope := &Scope{
+		Data:     &dataForTests{},
+		ParseRef: addrs.ParseRef,
+	}
+	self := cty.ObjectVal(map[string]cty.Value{
+		"steps": cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+	})
+	keyData := instances.RepetitionData{
+		CountIndex: cty.NumberIntVal(1),
+	}
+
+	got, diags := scope.EvalSelfBlock(file.Body, self, schema, keyData)
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+
+	want := cty.ObjectVal(map[string]cty.Value{
+		"step": cty.ListVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{"name": cty.StringVal("a-1")}),
+			cty.ObjectVal(map[string]cty.Value{"name": cty.StringVal("b-1")}),
+		}),
+	})
+	if !got.RawEquals(want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
@@ -6,6 +6,7 @@ package funcs
 import (
 	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/apparentlymart/go-cidr/cidr"
 	"github.com/opentofu/opentofu/internal/ipaddr"
@@ -197,6 +198,60 @@ var CidrSubnetsFunc = function.New(&function.Spec{
 	},
 })
 
+// CidrContainsFunc constructs a function that checks whether a given IP
+// address or address prefix is within a given IP network address prefix.
+var CidrContainsFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "containing_prefix",
+			Type: cty.String,
+		},
+		{
+			Name: "contained_ip_or_prefix",
+			Type: cty.String,
+		},
+	},
+	Type:         function.StaticReturnType(cty.Bool),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+		_, network, err := ipaddr.ParseCIDR(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.Bool), fmt.Errorf("invalid CIDR expression: %w", err)
+		}
+		containingLen, _ := network.Mask.Size()
+
+		str := args[1].AsString()
+		var ip ipaddr.IP
+		containedLen := -1
+		if strings.Contains(str, "/") {
+			var subnet *ipaddr.IPNet
+			_, subnet, err = ipaddr.ParseCIDR(str)
+			if err != nil {
+				return cty.UnknownVal(cty.Bool), fmt.Errorf("invalid CIDR expression: %w", err)
+			}
+			ip = subnet.IP
+			containedLen, _ = subnet.Mask.Size()
+		} else {
+			ip = ipaddr.ParseIP(str)
+			if ip == nil {
+				return cty.UnknownVal(cty.Bool), fmt.Errorf("invalid IP address: %s", str)
+			}
+		}
+
+		if (network.IP.To4() == nil) != (ip.To4() == nil) {
+			return cty.UnknownVal(cty.Bool), fmt.Errorf("address family mismatch: %s and %s must both be IPv4 or both be IPv6", args[0].AsString(), str)
+		}
+
+		contains := network.Contains(ip)
+		if containedLen >= 0 && containedLen < containingLen {
+			// A prefix is only contained if it's no larger than the
+			// containing prefix.
+			contains = false
+		}
+		return cty.BoolVal(contains), nil
+	},
+})
+
 // CidrHost calculates a full host IP address within a given IP network address prefix.
 func CidrHost(prefix, hostnum cty.Value) (cty.Value, error) {
 	return CidrHostFunc.Call([]cty.Value{prefix, hostnum})
@@ -220,3 +275,9 @@ func CidrSubnets(prefix cty.Value, newbits ...cty.Value) (cty.Value, error) {
 	copy(args[1:], newbits)
 	return CidrSubnetsFunc.Call(args)
 }
+
+// CidrContains checks whether a given IP address or address prefix is within
+// a given IP network address prefix.
+func CidrContains(containingPrefix, containedIPOrPrefix cty.Value) (cty.Value, error) {
+	return CidrContainsFunc.Call([]cty.Value{containingPrefix, containedIPOrPrefix})
+}
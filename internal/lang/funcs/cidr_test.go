@@ -396,3 +396,86 @@ func TestCidrSubnets(t *testing.T) {
 		})
 	}
 }
+
+func TestCidrContains(t *testing.T) {
+	tests := []struct {
+		Prefix    cty.Value
+		Contained cty.Value
+		Want      cty.Value
+		Err       bool
+	}{
+		{
+			cty.StringVal("192.168.2.0/24"),
+			cty.StringVal("192.168.2.1"),
+			cty.True,
+			false,
+		},
+		{
+			cty.StringVal("192.168.2.0/24"),
+			cty.StringVal("192.168.3.1"),
+			cty.False,
+			false,
+		},
+		{
+			cty.StringVal("192.168.0.0/16"),
+			cty.StringVal("192.168.2.0/24"),
+			cty.True,
+			false,
+		},
+		{
+			cty.StringVal("192.168.2.0/24"),
+			cty.StringVal("192.168.0.0/16"),
+			cty.False, // the contained prefix is larger
+			false,
+		},
+		{
+			cty.StringVal("fd00:fd12:3456:7890::/56"),
+			cty.StringVal("fd00:fd12:3456:7890:00a2::1"),
+			cty.True,
+			false,
+		},
+		{
+			cty.StringVal("fd00:fd12:3456:7890::/56"),
+			cty.StringVal("fd00:fd12:3456:7800::/48"),
+			cty.False,
+			false,
+		},
+		{
+			cty.StringVal("192.168.2.0/24"),
+			cty.StringVal("fd00:fd12:3456:7890::1"),
+			cty.UnknownVal(cty.Bool),
+			true, // address families don't match
+		},
+		{
+			cty.StringVal("not-a-cidr"),
+			cty.StringVal("192.168.2.1"),
+			cty.UnknownVal(cty.Bool),
+			true,
+		},
+		{
+			cty.StringVal("192.168.2.0/24"),
+			cty.StringVal("not-an-ip"),
+			cty.UnknownVal(cty.Bool),
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("cidrcontains(%#v, %#v)", test.Prefix, test.Contained), func(t *testing.T) {
+			got, err := CidrContains(test.Prefix, test.Contained)
+
+			if test.Err {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
@@ -76,10 +76,12 @@ var AllTrueFunc = function.New(&function.Spec{
 	RefineResult: refineNotNull,
 	Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
 		result := cty.True
+		var hasUnknown bool
 		for it := args[0].ElementIterator(); it.Next(); {
 			_, v := it.Element()
 			if !v.IsKnown() {
-				return cty.UnknownVal(cty.Bool), nil
+				hasUnknown = true
+				continue
 			}
 			if v.IsNull() {
 				return cty.False, nil
@@ -89,6 +91,9 @@ var AllTrueFunc = function.New(&function.Spec{
 				return cty.False, nil
 			}
 		}
+		if hasUnknown {
+			return cty.UnknownVal(cty.Bool), nil
+		}
 		return result, nil
 	},
 })
@@ -324,6 +329,139 @@ var LookupFunc = function.New(&function.Spec{
 	},
 })
 
+// GetPathFunc constructs a function that returns the value at a given path
+// of attribute names and element keys within a value, or null if any value
+// along the path is null.
+var GetPathFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name:             "value",
+			Type:             cty.DynamicPseudoType,
+			AllowUnknown:     true,
+			AllowDynamicType: true,
+			AllowNull:        true,
+			AllowMarked:      true,
+		},
+		{
+			Name:             "path",
+			Type:             cty.DynamicPseudoType,
+			AllowUnknown:     true,
+			AllowDynamicType: true,
+			AllowMarked:      true,
+		},
+	},
+	Type: func(args []cty.Value) (cty.Type, error) {
+		pathTy := args[1].Type()
+		if !pathTy.IsListType() && !pathTy.IsTupleType() && pathTy != cty.DynamicPseudoType {
+			return cty.NilType, function.NewArgErrorf(1, "the path must be a list of attribute names and element keys")
+		}
+
+		path, _ := args[1].UnmarkDeep()
+		if !path.IsWhollyKnown() {
+			return cty.DynamicPseudoType, nil
+		}
+
+		ty := args[0].Type()
+		for i, it := 0, path.ElementIterator(); it.Next(); i++ {
+			if ty == cty.DynamicPseudoType {
+				return cty.DynamicPseudoType, nil
+			}
+			_, key := it.Element()
+			var err error
+			ty, _, err = getPathStep(ty, key, i)
+			if err != nil {
+				return cty.NilType, function.NewArgError(1, err)
+			}
+		}
+		return ty, nil
+	},
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		// keep track of marks from the path and each value along it
+		var markses []cty.ValueMarks
+
+		path, pathMarks := args[1].UnmarkDeep()
+		markses = append(markses, pathMarks)
+		if !path.IsWhollyKnown() {
+			return cty.UnknownVal(retType).WithMarks(markses...), nil
+		}
+
+		current := args[0]
+		for i, it := 0, path.ElementIterator(); it.Next(); i++ {
+			var currentMarks cty.ValueMarks
+			current, currentMarks = current.Unmark()
+			markses = append(markses, currentMarks)
+
+			if current.IsNull() {
+				return cty.NullVal(retType).WithMarks(markses...), nil
+			}
+			if !current.IsKnown() {
+				return cty.UnknownVal(retType).WithMarks(markses...), nil
+			}
+
+			_, key := it.Element()
+			_, key, err := getPathStep(current.Type(), key, i)
+			if err != nil {
+				return cty.NilVal, function.NewArgError(1, err)
+			}
+
+			if current.Type().IsObjectType() {
+				current = current.GetAttr(key.AsString())
+				continue
+			}
+			if current.HasIndex(key).False() {
+				var keyDisplay interface{}
+				if key.Type() == cty.String {
+					keyDisplay = key.AsString()
+				} else {
+					keyDisplay = key.AsBigFloat()
+				}
+				return cty.NilVal, function.NewArgErrorf(1, "element %d of the path: the given %s has no element with key %s", i, current.Type().FriendlyName(), redactIfSensitive(keyDisplay, pathMarks))
+			}
+			current = current.Index(key)
+		}
+		return current.WithMarks(markses...), nil
+	},
+})
+
+// getPathStep returns the type of the value at the given key of a value of
+// the given type, along with the key converted to the type that is needed to
+// access it. The index is the position of the key within the path, for error
+// messages.
+func getPathStep(ty cty.Type, key cty.Value, index int) (cty.Type, cty.Value, error) {
+	switch {
+	case ty.IsObjectType():
+		name, err := convert.Convert(key, cty.String)
+		if err != nil || name.IsNull() {
+			return cty.NilType, cty.NilVal, fmt.Errorf("element %d of the path must be an attribute name", index)
+		}
+		if !ty.HasAttribute(name.AsString()) {
+			return cty.NilType, cty.NilVal, fmt.Errorf("element %d of the path: the given object has no attribute %q", index, name.AsString())
+		}
+		return ty.AttributeType(name.AsString()), name, nil
+	case ty.IsMapType():
+		name, err := convert.Convert(key, cty.String)
+		if err != nil || name.IsNull() {
+			return cty.NilType, cty.NilVal, fmt.Errorf("element %d of the path must be a map key", index)
+		}
+		return ty.ElementType(), name, nil
+	case ty.IsListType(), ty.IsTupleType():
+		num, err := convert.Convert(key, cty.Number)
+		if err != nil || num.IsNull() {
+			return cty.NilType, cty.NilVal, fmt.Errorf("element %d of the path must be an element index", index)
+		}
+		if !ty.IsTupleType() {
+			return ty.ElementType(), num, nil
+		}
+		idx, accuracy := num.AsBigFloat().Int64()
+		if accuracy != big.Exact || idx < 0 || idx >= int64(len(ty.TupleElementTypes())) {
+			return cty.NilType, cty.NilVal, fmt.Errorf("element %d of the path: the given tuple has no element with index %s", index, num.AsBigFloat().Text('f', -1))
+		}
+		return ty.TupleElementType(int(idx)), num, nil
+	default:
+		return cty.NilType, cty.NilVal, fmt.Errorf("element %d of the path: cannot access attributes or elements of a %s value", index, ty.FriendlyName())
+	}
+}
+
 // MatchkeysFunc constructs a function that constructs a new list by taking a
 // subset of elements from one list whose indexes match the corresponding
 // indexes of values in another list.
@@ -611,6 +749,253 @@ var TransposeFunc = function.New(&function.Spec{
 	},
 })
 
+// ZipFunc constructs a function that takes two lists or tuples with the same
+// number of elements and returns a list of pairs of their corresponding
+// elements.
+var ZipFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "a",
+			Type: cty.DynamicPseudoType,
+		},
+		{
+			Name: "b",
+			Type: cty.DynamicPseudoType,
+		},
+	},
+	Type: func(args []cty.Value) (cty.Type, error) {
+		aTy, bTy := args[0].Type(), args[1].Type()
+		for i, ty := range []cty.Type{aTy, bTy} {
+			if !ty.IsListType() && !ty.IsTupleType() {
+				return cty.NilType, function.NewArgErrorf(i, "must be a list or tuple")
+			}
+		}
+		if aTy.IsListType() && bTy.IsListType() {
+			return cty.List(cty.Tuple([]cty.Type{aTy.ElementType(), bTy.ElementType()})), nil
+		}
+		// The result is a tuple whose type depends on the number of
+		// elements, which we only know once we have the values.
+		return cty.DynamicPseudoType, nil
+	},
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		as, bs := args[0].AsValueSlice(), args[1].AsValueSlice()
+		if len(as) != len(bs) {
+			return cty.NilVal, function.NewArgErrorf(1, "must have the same number of elements as the first argument (%d), but has %d", len(as), len(bs))
+		}
+
+		pairs := make([]cty.Value, len(as))
+		for i := range as {
+			pairs[i] = cty.TupleVal([]cty.Value{as[i], bs[i]})
+		}
+		if retType.IsListType() {
+			if len(pairs) == 0 {
+				return cty.ListValEmpty(retType.ElementType()), nil
+			}
+			return cty.ListVal(pairs), nil
+		}
+		return cty.TupleVal(pairs), nil
+	},
+})
+
+// ChunkFunc constructs a function that splits a list, set or tuple into
+// consecutive chunks of at most the given size.
+//
+// Unlike chunklist, it also accepts sets and tuples whose elements don't have
+// a common type.
+var ChunkFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "list",
+			Type: cty.DynamicPseudoType,
+		},
+		{
+			Name: "size",
+			Type: cty.Number,
+		},
+	},
+	Type:         sequenceOfSequencesType,
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		var size int
+		if err := gocty.FromCtyValue(args[1], &size); err != nil || size < 0 {
+			return cty.NilVal, function.NewArgErrorf(1, "must be a whole number that is zero or greater")
+		}
+
+		elems := args[0].AsValueSlice()
+		if size == 0 {
+			// As with chunklist, a size of zero means a single chunk
+			// containing all of the elements.
+			size = len(elems)
+		}
+
+		var chunks [][]cty.Value
+		for start := 0; start < len(elems); start += size {
+			end := start + size
+			if end > len(elems) {
+				end = len(elems)
+			}
+			chunks = append(chunks, elems[start:end])
+		}
+		return sequenceOfSequencesVal(retType, chunks), nil
+	},
+})
+
+// WindowFunc constructs a function that returns each run of the given number
+// of consecutive elements of a list, set or tuple, in order.
+var WindowFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "list",
+			Type: cty.DynamicPseudoType,
+		},
+		{
+			Name: "size",
+			Type: cty.Number,
+		},
+	},
+	Type:         sequenceOfSequencesType,
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		var size int
+		if err := gocty.FromCtyValue(args[1], &size); err != nil || size < 1 {
+			return cty.NilVal, function.NewArgErrorf(1, "must be a whole number that is one or greater")
+		}
+
+		elems := args[0].AsValueSlice()
+		var windows [][]cty.Value
+		for start := 0; start+size <= len(elems); start++ {
+			windows = append(windows, elems[start:start+size])
+		}
+		return sequenceOfSequencesVal(retType, windows), nil
+	},
+})
+
+// GroupByFunc constructs a function that groups the objects or maps in a
+// list, set or tuple by the value of the given attribute, returning a map
+// from each distinct value of the attribute to the elements that have it.
+var GroupByFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "list",
+			Type: cty.DynamicPseudoType,
+		},
+		{
+			Name: "key",
+			Type: cty.String,
+		},
+	},
+	Type: func(args []cty.Value) (cty.Type, error) {
+		ty := args[0].Type()
+		switch {
+		case ty.IsListType() || ty.IsSetType():
+			ety := ty.ElementType()
+			if !ety.IsObjectType() && !ety.IsMapType() && ety != cty.DynamicPseudoType {
+				return cty.NilType, function.NewArgErrorf(0, "must be a collection of objects or maps")
+			}
+			return cty.Map(cty.List(ety)), nil
+		case ty.IsTupleType():
+			// The result is an object whose attributes depend on the
+			// values of the grouping attribute.
+			return cty.DynamicPseudoType, nil
+		}
+		return cty.NilType, function.NewArgErrorf(0, "must be a list, set, or tuple")
+	},
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		attr := args[1].AsString()
+
+		// Our parameter spec doesn't set AllowMarked, so the function
+		// machinery has already removed any marks from the elements and
+		// will apply them to the whole result, which is what we need since
+		// the grouping reveals the values of the attribute.
+		groups := make(map[string][]cty.Value)
+		for i, elem := range args[0].AsValueSlice() {
+			ty := elem.Type()
+			if !ty.IsObjectType() && !ty.IsMapType() {
+				return cty.NilVal, function.NewArgErrorf(0, "element %d must be an object or map", i)
+			}
+			if elem.IsNull() {
+				return cty.NilVal, function.NewArgErrorf(0, "element %d is null", i)
+			}
+			if !elem.IsKnown() {
+				return cty.UnknownVal(retType), nil
+			}
+
+			var keyVal cty.Value
+			switch {
+			case ty.IsObjectType() && ty.HasAttribute(attr):
+				keyVal = elem.GetAttr(attr)
+			case ty.IsMapType() && elem.HasIndex(cty.StringVal(attr)).True():
+				keyVal = elem.Index(cty.StringVal(attr))
+			default:
+				return cty.NilVal, function.NewArgErrorf(0, "element %d does not have an attribute named %q", i, attr)
+			}
+			if !keyVal.IsKnown() {
+				return cty.UnknownVal(retType), nil
+			}
+			keyVal, err := convert.Convert(keyVal, cty.String)
+			if err != nil || keyVal.IsNull() {
+				return cty.NilVal, function.NewArgErrorf(0, "the %q attribute of element %d must be a string, number, or bool", attr, i)
+			}
+
+			key := keyVal.AsString()
+			groups[key] = append(groups[key], elem)
+		}
+
+		if retType.IsMapType() && len(groups) == 0 {
+			return cty.MapValEmpty(retType.ElementType()), nil
+		}
+		vals := make(map[string]cty.Value, len(groups))
+		for key, group := range groups {
+			if retType.IsMapType() {
+				vals[key] = cty.ListVal(group)
+			} else {
+				vals[key] = cty.TupleVal(group)
+			}
+		}
+		if retType.IsMapType() {
+			return cty.MapVal(vals), nil
+		}
+		return cty.ObjectVal(vals), nil
+	},
+})
+
+// sequenceOfSequencesType is the type function for functions that split a
+// list, set or tuple into a sequence of shorter sequences of its elements.
+func sequenceOfSequencesType(args []cty.Value) (cty.Type, error) {
+	ty := args[0].Type()
+	switch {
+	case ty.IsListType() || ty.IsSetType():
+		return cty.List(cty.List(ty.ElementType())), nil
+	case ty.IsTupleType():
+		// The result is a tuple whose type depends on the size argument,
+		// which may not be known yet.
+		return cty.DynamicPseudoType, nil
+	}
+	return cty.NilType, function.NewArgErrorf(0, "must be a list, set, or tuple")
+}
+
+// sequenceOfSequencesVal builds the result of a function whose type is given
+// by sequenceOfSequencesType from the given sequences of elements.
+func sequenceOfSequencesVal(retType cty.Type, seqs [][]cty.Value) cty.Value {
+	if retType.IsListType() {
+		if len(seqs) == 0 {
+			return cty.ListValEmpty(retType.ElementType())
+		}
+		vals := make([]cty.Value, len(seqs))
+		for i, seq := range seqs {
+			vals[i] = cty.ListVal(seq)
+		}
+		return cty.ListVal(vals)
+	}
+	vals := make([]cty.Value, len(seqs))
+	for i, seq := range seqs {
+		vals[i] = cty.TupleVal(seq)
+	}
+	return cty.TupleVal(vals)
+}
+
 // ListFunc constructs a function that takes an arbitrary number of arguments
 // and returns a list containing those values in the same order.
 //
@@ -696,6 +1081,12 @@ func Lookup(args ...cty.Value) (cty.Value, error) {
 	return LookupFunc.Call(args)
 }
 
+// GetPath returns the value at the given path of attribute names and element
+// keys within a value, or null if any value along the path is null.
+func GetPath(value, path cty.Value) (cty.Value, error) {
+	return GetPathFunc.Call([]cty.Value{value, path})
+}
+
 // Map takes an even number of arguments and returns a map whose elements are constructed
 // from consecutive pairs of arguments.
 func Map(args ...cty.Value) (cty.Value, error) {
@@ -724,3 +1115,27 @@ func Sum(list cty.Value) (cty.Value, error) {
 func Transpose(values cty.Value) (cty.Value, error) {
 	return TransposeFunc.Call([]cty.Value{values})
 }
+
+// Zip takes two lists or tuples with the same number of elements and returns
+// a list of pairs of their corresponding elements.
+func Zip(a, b cty.Value) (cty.Value, error) {
+	return ZipFunc.Call([]cty.Value{a, b})
+}
+
+// Chunk splits a list, set or tuple into consecutive chunks of at most the
+// given size.
+func Chunk(list, size cty.Value) (cty.Value, error) {
+	return ChunkFunc.Call([]cty.Value{list, size})
+}
+
+// Window returns each run of the given number of consecutive elements of a
+// list, set or tuple.
+func Window(list, size cty.Value) (cty.Value, error) {
+	return WindowFunc.Call([]cty.Value{list, size})
+}
+
+// GroupBy groups the objects or maps in a list, set or tuple by the value of
+// the given attribute.
+func GroupBy(list, key cty.Value) (cty.Value, error) {
+	return GroupByFunc.Call([]cty.Value{list, key})
+}
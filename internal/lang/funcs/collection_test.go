@@ -243,6 +243,22 @@ func TestAllTrue(t *testing.T) {
 			cty.UnknownVal(cty.Bool).RefineNotNull(),
 			false,
 		},
+		{
+			cty.ListVal([]cty.Value{
+				cty.UnknownVal(cty.Bool),
+				cty.True,
+			}),
+			cty.UnknownVal(cty.Bool).RefineNotNull(),
+			false,
+		},
+		{
+			cty.ListVal([]cty.Value{
+				cty.UnknownVal(cty.Bool),
+				cty.False,
+			}),
+			cty.False,
+			false,
+		},
 		{
 			cty.UnknownVal(cty.List(cty.Bool)),
 			cty.UnknownVal(cty.Bool).RefineNotNull(),
@@ -946,6 +962,164 @@ func TestLookup_error(t *testing.T) {
 	}
 }
 
+func TestGetPath(t *testing.T) {
+	networkType := cty.Object(map[string]cty.Type{
+		"subnet_ids": cty.List(cty.String),
+	})
+	configType := cty.Object(map[string]cty.Type{
+		"network": networkType,
+		"tags":    cty.Map(cty.String),
+	})
+	config := cty.ObjectVal(map[string]cty.Value{
+		"network": cty.ObjectVal(map[string]cty.Value{
+			"subnet_ids": cty.ListVal([]cty.Value{
+				cty.StringVal("subnet-a"),
+				cty.StringVal("subnet-b"),
+			}),
+		}),
+		"tags": cty.MapVal(map[string]cty.Value{
+			"Name": cty.StringVal("example"),
+		}),
+	})
+
+	tests := []struct {
+		Value cty.Value
+		Path  cty.Value
+		Want  cty.Value
+		Err   string
+	}{
+		{
+			config,
+			cty.TupleVal([]cty.Value{cty.StringVal("network"), cty.StringVal("subnet_ids"), cty.NumberIntVal(1)}),
+			cty.StringVal("subnet-b"),
+			``,
+		},
+		{
+			config,
+			cty.ListVal([]cty.Value{cty.StringVal("tags"), cty.StringVal("Name")}),
+			cty.StringVal("example"),
+			``,
+		},
+		{
+			config,
+			cty.ListValEmpty(cty.String),
+			config,
+			``,
+		},
+		{ // null intermediate value
+			cty.ObjectVal(map[string]cty.Value{
+				"network": cty.NullVal(networkType),
+				"tags":    cty.NullVal(cty.Map(cty.String)),
+			}),
+			cty.TupleVal([]cty.Value{cty.StringVal("network"), cty.StringVal("subnet_ids"), cty.NumberIntVal(0)}),
+			cty.NullVal(cty.String),
+			``,
+		},
+		{ // null value
+			cty.NullVal(configType),
+			cty.TupleVal([]cty.Value{cty.StringVal("network"), cty.StringVal("subnet_ids")}),
+			cty.NullVal(cty.List(cty.String)),
+			``,
+		},
+		{
+			cty.NullVal(cty.DynamicPseudoType),
+			cty.TupleVal([]cty.Value{cty.StringVal("network")}),
+			cty.NullVal(cty.DynamicPseudoType),
+			``,
+		},
+		{
+			cty.UnknownVal(configType),
+			cty.TupleVal([]cty.Value{cty.StringVal("network"), cty.StringVal("subnet_ids")}),
+			cty.UnknownVal(cty.List(cty.String)),
+			``,
+		},
+		{
+			config,
+			cty.UnknownVal(cty.List(cty.String)),
+			cty.DynamicVal,
+			``,
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"network": cty.NullVal(networkType).Mark(marks.Sensitive),
+			}),
+			cty.TupleVal([]cty.Value{cty.StringVal("network"), cty.StringVal("subnet_ids")}),
+			cty.NullVal(cty.List(cty.String)).Mark(marks.Sensitive),
+			``,
+		},
+		{
+			config.Mark(marks.Sensitive),
+			cty.TupleVal([]cty.Value{cty.StringVal("tags"), cty.StringVal("Name")}),
+			cty.StringVal("example").Mark(marks.Sensitive),
+			``,
+		},
+		{
+			config,
+			cty.TupleVal([]cty.Value{cty.StringVal("network"), cty.StringVal("subnet")}),
+			cty.NilVal,
+			`element 1 of the path: the given object has no attribute "subnet"`,
+		},
+		{
+			config,
+			cty.TupleVal([]cty.Value{cty.StringVal("tags"), cty.StringVal("Owner")}),
+			cty.NilVal,
+			`element 1 of the path: the given map of string has no element with key "Owner"`,
+		},
+		{
+			config,
+			cty.TupleVal([]cty.Value{cty.StringVal("tags"), cty.StringVal("Owner").Mark(marks.Sensitive)}),
+			cty.NilVal,
+			`element 1 of the path: the given map of string has no element with key (sensitive value)`,
+		},
+		{
+			config,
+			cty.TupleVal([]cty.Value{cty.StringVal("network"), cty.StringVal("subnet_ids"), cty.NumberIntVal(2)}),
+			cty.NilVal,
+			`element 2 of the path: the given list of string has no element with key 2`,
+		},
+		{
+			config,
+			cty.TupleVal([]cty.Value{cty.StringVal("network"), cty.StringVal("subnet_ids"), cty.StringVal("first")}),
+			cty.NilVal,
+			`element 2 of the path must be an element index`,
+		},
+		{
+			config,
+			cty.TupleVal([]cty.Value{cty.StringVal("tags"), cty.StringVal("Name"), cty.StringVal("length")}),
+			cty.NilVal,
+			`element 2 of the path: cannot access attributes or elements of a string value`,
+		},
+		{
+			config,
+			cty.StringVal("network"),
+			cty.NilVal,
+			`the path must be a list of attribute names and element keys`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("getpath(%#v, %#v)", test.Value, test.Path), func(t *testing.T) {
+			got, err := GetPath(test.Value, test.Path)
+
+			if test.Err != "" {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				if err.Error() != test.Err {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", err, test.Err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
 func TestMatchkeys(t *testing.T) {
 	tests := []struct {
 		Keys      cty.Value
@@ -1841,3 +2015,361 @@ func TestTranspose(t *testing.T) {
 		})
 	}
 }
+
+func TestZip(t *testing.T) {
+	tests := []struct {
+		A    cty.Value
+		B    cty.Value
+		Want cty.Value
+		Err  string
+	}{
+		{
+			cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+			cty.ListVal([]cty.Value{cty.NumberIntVal(1), cty.NumberIntVal(2)}),
+			cty.ListVal([]cty.Value{
+				cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.NumberIntVal(1)}),
+				cty.TupleVal([]cty.Value{cty.StringVal("b"), cty.NumberIntVal(2)}),
+			}),
+			"",
+		},
+		{
+			cty.ListValEmpty(cty.String),
+			cty.ListValEmpty(cty.Number),
+			cty.ListValEmpty(cty.Tuple([]cty.Type{cty.String, cty.Number})),
+			"",
+		},
+		{
+			cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.True}),
+			cty.ListVal([]cty.Value{cty.NumberIntVal(1), cty.NumberIntVal(2)}),
+			cty.TupleVal([]cty.Value{
+				cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.NumberIntVal(1)}),
+				cty.TupleVal([]cty.Value{cty.True, cty.NumberIntVal(2)}),
+			}),
+			"",
+		},
+		{
+			cty.ListVal([]cty.Value{cty.StringVal("a").Mark(marks.Sensitive)}),
+			cty.ListVal([]cty.Value{cty.NumberIntVal(1)}),
+			cty.ListVal([]cty.Value{
+				cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.NumberIntVal(1)}),
+			}).Mark(marks.Sensitive),
+			"",
+		},
+		{
+			cty.UnknownVal(cty.List(cty.String)),
+			cty.ListVal([]cty.Value{cty.NumberIntVal(1)}),
+			cty.UnknownVal(cty.List(cty.Tuple([]cty.Type{cty.String, cty.Number}))).RefineNotNull(),
+			"",
+		},
+		{
+			cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+			cty.ListVal([]cty.Value{cty.NumberIntVal(1)}),
+			cty.NilVal,
+			"must have the same number of elements as the first argument (2), but has 1",
+		},
+		{
+			cty.SetVal([]cty.Value{cty.StringVal("a")}),
+			cty.ListVal([]cty.Value{cty.NumberIntVal(1)}),
+			cty.NilVal,
+			"must be a list or tuple",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("zip(%#v, %#v)", test.A, test.B), func(t *testing.T) {
+			got, err := Zip(test.A, test.B)
+
+			if test.Err != "" {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				} else if got, want := err.Error(), test.Err; got != want {
+					t.Fatalf("wrong error\n got: %s\nwant: %s", got, want)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !test.Want.RawEquals(got) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestChunk(t *testing.T) {
+	tests := []struct {
+		List cty.Value
+		Size cty.Value
+		Want cty.Value
+		Err  string
+	}{
+		{
+			cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b"), cty.StringVal("c")}),
+			cty.NumberIntVal(2),
+			cty.ListVal([]cty.Value{
+				cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+				cty.ListVal([]cty.Value{cty.StringVal("c")}),
+			}),
+			"",
+		},
+		{
+			cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+			cty.NumberIntVal(0),
+			cty.ListVal([]cty.Value{
+				cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+			}),
+			"",
+		},
+		{
+			cty.ListValEmpty(cty.String),
+			cty.NumberIntVal(2),
+			cty.ListValEmpty(cty.List(cty.String)),
+			"",
+		},
+		{
+			cty.SetVal([]cty.Value{cty.StringVal("b"), cty.StringVal("a")}),
+			cty.NumberIntVal(1),
+			cty.ListVal([]cty.Value{
+				cty.ListVal([]cty.Value{cty.StringVal("a")}),
+				cty.ListVal([]cty.Value{cty.StringVal("b")}),
+			}),
+			"",
+		},
+		{
+			cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.NumberIntVal(1), cty.True}),
+			cty.NumberIntVal(2),
+			cty.TupleVal([]cty.Value{
+				cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.NumberIntVal(1)}),
+				cty.TupleVal([]cty.Value{cty.True}),
+			}),
+			"",
+		},
+		{
+			cty.ListVal([]cty.Value{cty.StringVal("a")}),
+			cty.UnknownVal(cty.Number),
+			cty.UnknownVal(cty.List(cty.List(cty.String))).RefineNotNull(),
+			"",
+		},
+		{
+			cty.ListVal([]cty.Value{cty.StringVal("a")}),
+			cty.NumberFloatVal(1.5),
+			cty.NilVal,
+			"must be a whole number that is zero or greater",
+		},
+		{
+			cty.StringVal("a"),
+			cty.NumberIntVal(1),
+			cty.NilVal,
+			"must be a list, set, or tuple",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("chunk(%#v, %#v)", test.List, test.Size), func(t *testing.T) {
+			got, err := Chunk(test.List, test.Size)
+
+			if test.Err != "" {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				} else if got, want := err.Error(), test.Err; got != want {
+					t.Fatalf("wrong error\n got: %s\nwant: %s", got, want)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !test.Want.RawEquals(got) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestWindow(t *testing.T) {
+	tests := []struct {
+		List cty.Value
+		Size cty.Value
+		Want cty.Value
+		Err  string
+	}{
+		{
+			cty.ListVal([]cty.Value{cty.NumberIntVal(1), cty.NumberIntVal(2), cty.NumberIntVal(3)}),
+			cty.NumberIntVal(2),
+			cty.ListVal([]cty.Value{
+				cty.ListVal([]cty.Value{cty.NumberIntVal(1), cty.NumberIntVal(2)}),
+				cty.ListVal([]cty.Value{cty.NumberIntVal(2), cty.NumberIntVal(3)}),
+			}),
+			"",
+		},
+		{
+			cty.ListVal([]cty.Value{cty.NumberIntVal(1), cty.NumberIntVal(2)}),
+			cty.NumberIntVal(3),
+			cty.ListValEmpty(cty.List(cty.Number)),
+			"",
+		},
+		{
+			cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.NumberIntVal(1), cty.True}),
+			cty.NumberIntVal(2),
+			cty.TupleVal([]cty.Value{
+				cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.NumberIntVal(1)}),
+				cty.TupleVal([]cty.Value{cty.NumberIntVal(1), cty.True}),
+			}),
+			"",
+		},
+		{
+			cty.ListVal([]cty.Value{cty.NumberIntVal(1)}),
+			cty.NumberIntVal(0),
+			cty.NilVal,
+			"must be a whole number that is one or greater",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("window(%#v, %#v)", test.List, test.Size), func(t *testing.T) {
+			got, err := Window(test.List, test.Size)
+
+			if test.Err != "" {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				} else if got, want := err.Error(), test.Err; got != want {
+					t.Fatalf("wrong error\n got: %s\nwant: %s", got, want)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !test.Want.RawEquals(got) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	web1 := cty.ObjectVal(map[string]cty.Value{
+		"name": cty.StringVal("web1"),
+		"role": cty.StringVal("web"),
+	})
+	web2 := cty.ObjectVal(map[string]cty.Value{
+		"name": cty.StringVal("web2"),
+		"role": cty.StringVal("web"),
+	})
+	db1 := cty.ObjectVal(map[string]cty.Value{
+		"name": cty.StringVal("db1"),
+		"role": cty.StringVal("db"),
+	})
+
+	tests := []struct {
+		List cty.Value
+		Key  cty.Value
+		Want cty.Value
+		Err  string
+	}{
+		{
+			cty.ListVal([]cty.Value{web1, db1, web2}),
+			cty.StringVal("role"),
+			cty.MapVal(map[string]cty.Value{
+				"web": cty.ListVal([]cty.Value{web1, web2}),
+				"db":  cty.ListVal([]cty.Value{db1}),
+			}),
+			"",
+		},
+		{
+			cty.ListValEmpty(web1.Type()),
+			cty.StringVal("role"),
+			cty.MapValEmpty(cty.List(web1.Type())),
+			"",
+		},
+		{
+			cty.ListVal([]cty.Value{
+				cty.MapVal(map[string]cty.Value{"zone": cty.StringVal("a")}),
+				cty.MapVal(map[string]cty.Value{"zone": cty.StringVal("b")}),
+			}),
+			cty.StringVal("zone"),
+			cty.MapVal(map[string]cty.Value{
+				"a": cty.ListVal([]cty.Value{cty.MapVal(map[string]cty.Value{"zone": cty.StringVal("a")})}),
+				"b": cty.ListVal([]cty.Value{cty.MapVal(map[string]cty.Value{"zone": cty.StringVal("b")})}),
+			}),
+			"",
+		},
+		{
+			cty.TupleVal([]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{"size": cty.NumberIntVal(1)}),
+				cty.ObjectVal(map[string]cty.Value{"size": cty.NumberIntVal(1), "extra": cty.True}),
+			}),
+			cty.StringVal("size"),
+			cty.ObjectVal(map[string]cty.Value{
+				"1": cty.TupleVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{"size": cty.NumberIntVal(1)}),
+					cty.ObjectVal(map[string]cty.Value{"size": cty.NumberIntVal(1), "extra": cty.True}),
+				}),
+			}),
+			"",
+		},
+		{
+			cty.ListVal([]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{
+					"name": cty.StringVal("web1"),
+					"role": cty.StringVal("web").Mark(marks.Sensitive),
+				}),
+			}),
+			cty.StringVal("role"),
+			cty.MapVal(map[string]cty.Value{
+				"web": cty.ListVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"name": cty.StringVal("web1"),
+						"role": cty.StringVal("web"),
+					}),
+				}),
+			}).Mark(marks.Sensitive),
+			"",
+		},
+		{
+			cty.ListVal([]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{
+					"name": cty.StringVal("web1"),
+					"role": cty.UnknownVal(cty.String),
+				}),
+			}),
+			cty.StringVal("role"),
+			cty.UnknownVal(cty.Map(cty.List(web1.Type()))).RefineNotNull(),
+			"",
+		},
+		{
+			cty.ListVal([]cty.Value{web1}),
+			cty.StringVal("zone"),
+			cty.NilVal,
+			`element 0 does not have an attribute named "zone"`,
+		},
+		{
+			cty.ListVal([]cty.Value{cty.StringVal("web")}),
+			cty.StringVal("role"),
+			cty.NilVal,
+			"must be a collection of objects or maps",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("groupby(%#v, %#v)", test.List, test.Key), func(t *testing.T) {
+			got, err := GroupBy(test.List, test.Key)
+
+			if test.Err != "" {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				} else if got, want := err.Error(), test.Err; got != want {
+					t.Fatalf("wrong error\n got: %s\nwant: %s", got, want)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !test.Want.RawEquals(got) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// The strategies that deepmerge can use when two of the given values both
+// have a value for the same key, and those values aren't both objects or
+// maps that can be merged recursively.
+const (
+	// deepMergeOverride uses the later value.
+	deepMergeOverride = "override"
+
+	// deepMergeAppend concatenates two lists or tuples, and otherwise uses
+	// the later value.
+	deepMergeAppend = "append"
+
+	// deepMergeUnion combines the elements of two lists, tuples or sets,
+	// without duplicates, and otherwise uses the later value.
+	deepMergeUnion = "union"
+
+	// deepMergeError returns an error if the two values are not equal.
+	deepMergeError = "error"
+)
+
+var deepMergeOptionsType = cty.ObjectWithOptionalAttrs(map[string]cty.Type{
+	"strategy": cty.String,
+	"keys":     cty.Map(cty.String),
+}, []string{"strategy", "keys"})
+
+// DeepMergeFunc constructs a function that recursively merges a sequence of
+// objects or maps, using a configurable strategy for values that can't be
+// merged recursively.
+var DeepMergeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name:        "values",
+			Type:        cty.DynamicPseudoType,
+			AllowMarked: true,
+		},
+	},
+	VarParam: &function.Parameter{
+		Name:        "options",
+		Type:        cty.DynamicPseudoType,
+		AllowMarked: true,
+	},
+	Type:         function.StaticReturnType(cty.DynamicPseudoType),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+		values, marks := args[0].UnmarkDeep()
+
+		opts := deepMergeOptions{
+			strategy: deepMergeOverride,
+		}
+		switch len(args) {
+		case 1:
+			// No options, so we'll use the defaults.
+		case 2:
+			optsVal, optsMarks := args[1].UnmarkDeep()
+			for k := range optsMarks {
+				marks[k] = struct{}{}
+			}
+			if !optsVal.IsWhollyKnown() {
+				return cty.DynamicVal.WithMarks(marks), nil
+			}
+			opts, err = decodeDeepMergeOptions(optsVal)
+			if err != nil {
+				return cty.NilVal, function.NewArgError(1, err)
+			}
+		default:
+			return cty.NilVal, function.NewArgErrorf(2, "too many arguments; only one options object is allowed")
+		}
+
+		ty := values.Type()
+		if !(ty.IsListType() || ty.IsTupleType() || ty.IsSetType()) {
+			return cty.NilVal, function.NewArgErrorf(0, "must be a list or tuple of objects or maps")
+		}
+		if !values.IsWhollyKnown() {
+			// We can't know which keys or elements the result will have
+			// until all of the values are known.
+			return cty.DynamicVal.WithMarks(marks), nil
+		}
+
+		result := cty.EmptyObjectVal
+		i := 0
+		for it := values.ElementIterator(); it.Next(); i++ {
+			_, v := it.Element()
+			if v.IsNull() {
+				continue
+			}
+			if !isDeepMergeable(v.Type()) {
+				return cty.NilVal, function.NewArgErrorf(0, "element %d is %s, but must be an object or map", i, v.Type().FriendlyName())
+			}
+			result, err = deepMergeValues(nil, result, v, opts)
+			if err != nil {
+				return cty.NilVal, function.NewArgError(0, err)
+			}
+		}
+
+		return result.WithMarks(marks), nil
+	},
+})
+
+// DeepMerge recursively merges a sequence of objects or maps, using the given
+// options, if any, to decide how to merge values that aren't objects or maps.
+func DeepMerge(values cty.Value, options ...cty.Value) (cty.Value, error) {
+	return DeepMergeFunc.Call(append([]cty.Value{values}, options...))
+}
+
+// deepMergeOptions is the decoded form of the options argument of deepmerge.
+type deepMergeOptions struct {
+	// strategy is the strategy to use for any key that doesn't have its own
+	// strategy in keys.
+	strategy string
+
+	// keys maps the paths of keys, with each step separated by a period, to
+	// the strategy for that key and any keys nested inside it.
+	keys map[string]string
+}
+
+func decodeDeepMergeOptions(val cty.Value) (deepMergeOptions, error) {
+	opts := deepMergeOptions{
+		strategy: deepMergeOverride,
+	}
+	if val.IsNull() {
+		return opts, nil
+	}
+	if ty := val.Type(); ty.IsObjectType() {
+		var names []string
+		for name := range ty.AttributeTypes() {
+			if !deepMergeOptionsType.HasAttribute(name) {
+				names = append(names, name)
+			}
+		}
+		if len(names) != 0 {
+			sort.Strings(names)
+			return opts, fmt.Errorf("unsupported option %q; the supported options are \"strategy\" and \"keys\"", names[0])
+		}
+	}
+	val, err := convert.Convert(val, deepMergeOptionsType)
+	if err != nil {
+		return opts, fmt.Errorf("invalid options: %w", err)
+	}
+
+	if v := val.GetAttr("strategy"); !v.IsNull() {
+		opts.strategy = v.AsString()
+		if !validDeepMergeStrategy(opts.strategy) {
+			return opts, fmt.Errorf("invalid strategy %q; %s", opts.strategy, deepMergeStrategiesHint)
+		}
+	}
+	if v := val.GetAttr("keys"); !v.IsNull() {
+		opts.keys = make(map[string]string, v.LengthInt())
+		for it := v.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			if v.IsNull() {
+				return opts, fmt.Errorf("the strategy for key %q must not be null", k.AsString())
+			}
+			strategy := v.AsString()
+			if !validDeepMergeStrategy(strategy) {
+				return opts, fmt.Errorf("invalid strategy %q for key %q; %s", strategy, k.AsString(), deepMergeStrategiesHint)
+			}
+			opts.keys[k.AsString()] = strategy
+		}
+	}
+	return opts, nil
+}
+
+const deepMergeStrategiesHint = `must be "override", "append", "union" or "error"`
+
+func validDeepMergeStrategy(strategy string) bool {
+	switch strategy {
+	case deepMergeOverride, deepMergeAppend, deepMergeUnion, deepMergeError:
+		return true
+	default:
+		return false
+	}
+}
+
+// strategyFor returns the strategy for the key at the given path, which is
+// the strategy for the longest prefix of the path that has a strategy of its
+// own, or the default strategy if there is none.
+func (o deepMergeOptions) strategyFor(path []string) string {
+	for i := len(path); i > 0; i-- {
+		if strategy, ok := o.keys[strings.Join(path[:i], ".")]; ok {
+			return strategy
+		}
+	}
+	return o.strategy
+}
+
+// deepMergeValues merges b into a, where both are the values of the key at
+// the given path.
+func deepMergeValues(path []string, a, b cty.Value, opts deepMergeOptions) (cty.Value, error) {
+	aTy, bTy := a.Type(), b.Type()
+
+	if !a.IsNull() && !b.IsNull() && isDeepMergeable(aTy) && isDeepMergeable(bTy) {
+		attrs := a.AsValueMap()
+		if attrs == nil {
+			attrs = make(map[string]cty.Value)
+		}
+		for it := b.ElementIterator(); it.Next(); {
+			k, bv := it.Element()
+			name := k.AsString()
+			if av, exists := attrs[name]; exists {
+				// We make a new slice for each key so that the recursive
+				// calls can't modify each other's paths.
+				keyPath := make([]string, len(path), len(path)+1)
+				copy(keyPath, path)
+				merged, err := deepMergeValues(append(keyPath, name), av, bv, opts)
+				if err != nil {
+					return cty.NilVal, err
+				}
+				attrs[name] = merged
+				continue
+			}
+			attrs[name] = bv
+		}
+		return cty.ObjectVal(attrs), nil
+	}
+
+	switch opts.strategyFor(path) {
+	case deepMergeAppend:
+		if a.IsNull() || b.IsNull() || !isDeepMergeSequence(aTy) || !isDeepMergeSequence(bTy) {
+			return b, nil
+		}
+		elems := append(a.AsValueSlice(), b.AsValueSlice()...)
+		if len(elems) == 0 {
+			return cty.EmptyTupleVal, nil
+		}
+		return cty.TupleVal(elems), nil
+
+	case deepMergeUnion:
+		if a.IsNull() || b.IsNull() || !isDeepMergeCollection(aTy) || !isDeepMergeCollection(bTy) {
+			return b, nil
+		}
+		if aTy.IsSetType() && aTy.Equals(bTy) {
+			// Two sets of the same type can be combined into another set of
+			// that type, which removes duplicates for us.
+			elems := append(a.AsValueSlice(), b.AsValueSlice()...)
+			if len(elems) == 0 {
+				return cty.SetValEmpty(aTy.ElementType()), nil
+			}
+			return cty.SetVal(elems), nil
+		}
+		var elems []cty.Value
+		for _, v := range append(a.AsValueSlice(), b.AsValueSlice()...) {
+			if !deepMergeContains(elems, v) {
+				elems = append(elems, v)
+			}
+		}
+		if len(elems) == 0 {
+			return cty.EmptyTupleVal, nil
+		}
+		return cty.TupleVal(elems), nil
+
+	case deepMergeError:
+		if !a.Equals(b).True() {
+			return cty.NilVal, fmt.Errorf("conflicting values for key %q", strings.Join(path, "."))
+		}
+		return b, nil
+
+	default:
+		return b, nil
+	}
+}
+
+func deepMergeContains(elems []cty.Value, v cty.Value) bool {
+	for _, elem := range elems {
+		if elem.Equals(v).True() {
+			return true
+		}
+	}
+	return false
+}
+
+func isDeepMergeable(ty cty.Type) bool {
+	return ty.IsObjectType() || ty.IsMapType()
+}
+
+func isDeepMergeSequence(ty cty.Type) bool {
+	return ty.IsListType() || ty.IsTupleType()
+}
+
+func isDeepMergeCollection(ty cty.Type) bool {
+	return isDeepMergeSequence(ty) || ty.IsSetType()
+}
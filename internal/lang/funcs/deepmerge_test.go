@@ -0,0 +1,233 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/lang/marks"
+)
+
+func TestDeepMerge(t *testing.T) {
+	base := cty.ObjectVal(map[string]cty.Value{
+		"name": cty.StringVal("app"),
+		"network": cty.ObjectVal(map[string]cty.Value{
+			"cidr":    cty.StringVal("10.0.0.0/16"),
+			"subnets": cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+		}),
+		"tags": cty.MapVal(map[string]cty.Value{
+			"team": cty.StringVal("platform"),
+		}),
+		"zones": cty.SetVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+	})
+	overlay := cty.ObjectVal(map[string]cty.Value{
+		"network": cty.ObjectVal(map[string]cty.Value{
+			"subnets": cty.TupleVal([]cty.Value{cty.StringVal("b"), cty.StringVal("c")}),
+		}),
+		"tags": cty.MapVal(map[string]cty.Value{
+			"env": cty.StringVal("prod"),
+		}),
+		"zones": cty.SetVal([]cty.Value{cty.StringVal("c")}),
+	})
+
+	tests := []struct {
+		Values  cty.Value
+		Options []cty.Value
+		Want    cty.Value
+		Err     string
+	}{
+		{
+			cty.EmptyTupleVal,
+			nil,
+			cty.EmptyObjectVal,
+			``,
+		},
+		{
+			cty.TupleVal([]cty.Value{base, cty.NullVal(cty.DynamicPseudoType), overlay}),
+			nil,
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("app"),
+				"network": cty.ObjectVal(map[string]cty.Value{
+					"cidr":    cty.StringVal("10.0.0.0/16"),
+					"subnets": cty.TupleVal([]cty.Value{cty.StringVal("b"), cty.StringVal("c")}),
+				}),
+				"tags": cty.ObjectVal(map[string]cty.Value{
+					"env":  cty.StringVal("prod"),
+					"team": cty.StringVal("platform"),
+				}),
+				"zones": cty.SetVal([]cty.Value{cty.StringVal("c")}),
+			}),
+			``,
+		},
+		{
+			cty.TupleVal([]cty.Value{base, overlay}),
+			[]cty.Value{cty.ObjectVal(map[string]cty.Value{
+				"strategy": cty.StringVal("append"),
+			})},
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("app"),
+				"network": cty.ObjectVal(map[string]cty.Value{
+					"cidr": cty.StringVal("10.0.0.0/16"),
+					"subnets": cty.TupleVal([]cty.Value{
+						cty.StringVal("a"), cty.StringVal("b"), cty.StringVal("b"), cty.StringVal("c"),
+					}),
+				}),
+				"tags": cty.ObjectVal(map[string]cty.Value{
+					"env":  cty.StringVal("prod"),
+					"team": cty.StringVal("platform"),
+				}),
+				"zones": cty.SetVal([]cty.Value{cty.StringVal("c")}), // sets can't be appended
+			}),
+			``,
+		},
+		{
+			cty.TupleVal([]cty.Value{base, overlay}),
+			[]cty.Value{cty.ObjectVal(map[string]cty.Value{
+				"keys": cty.MapVal(map[string]cty.Value{
+					"network.subnets": cty.StringVal("union"),
+					"zones":           cty.StringVal("union"),
+				}),
+			})},
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("app"),
+				"network": cty.ObjectVal(map[string]cty.Value{
+					"cidr": cty.StringVal("10.0.0.0/16"),
+					"subnets": cty.TupleVal([]cty.Value{
+						cty.StringVal("a"), cty.StringVal("b"), cty.StringVal("c"),
+					}),
+				}),
+				"tags": cty.ObjectVal(map[string]cty.Value{
+					"env":  cty.StringVal("prod"),
+					"team": cty.StringVal("platform"),
+				}),
+				"zones": cty.SetVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b"), cty.StringVal("c")}),
+			}),
+			``,
+		},
+		{
+			// The strategy for a key also applies to the keys nested in it.
+			cty.TupleVal([]cty.Value{base, overlay}),
+			[]cty.Value{cty.ObjectVal(map[string]cty.Value{
+				"strategy": cty.StringVal("error"),
+				"keys": cty.MapVal(map[string]cty.Value{
+					"network": cty.StringVal("append"),
+					"zones":   cty.StringVal("override"),
+				}),
+			})},
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("app"),
+				"network": cty.ObjectVal(map[string]cty.Value{
+					"cidr": cty.StringVal("10.0.0.0/16"),
+					"subnets": cty.TupleVal([]cty.Value{
+						cty.StringVal("a"), cty.StringVal("b"), cty.StringVal("b"), cty.StringVal("c"),
+					}),
+				}),
+				"tags": cty.ObjectVal(map[string]cty.Value{
+					"env":  cty.StringVal("prod"),
+					"team": cty.StringVal("platform"),
+				}),
+				"zones": cty.SetVal([]cty.Value{cty.StringVal("c")}),
+			}),
+			``,
+		},
+		{
+			cty.TupleVal([]cty.Value{
+				base,
+				cty.ObjectVal(map[string]cty.Value{"name": cty.StringVal("app")}),
+			}),
+			[]cty.Value{cty.ObjectVal(map[string]cty.Value{
+				"strategy": cty.StringVal("error"),
+			})},
+			base,
+			``,
+		},
+		{
+			cty.TupleVal([]cty.Value{base, overlay}),
+			[]cty.Value{cty.ObjectVal(map[string]cty.Value{
+				"strategy": cty.StringVal("error"),
+			})},
+			cty.NilVal,
+			`conflicting values for key "network.subnets"`,
+		},
+		{
+			cty.TupleVal([]cty.Value{base, cty.StringVal("nope")}),
+			nil,
+			cty.NilVal,
+			`element 1 is string, but must be an object or map`,
+		},
+		{
+			cty.StringVal("nope"),
+			nil,
+			cty.NilVal,
+			`must be a list or tuple of objects or maps`,
+		},
+		{
+			cty.TupleVal([]cty.Value{base, overlay}),
+			[]cty.Value{cty.ObjectVal(map[string]cty.Value{
+				"strategy": cty.StringVal("deep"),
+			})},
+			cty.NilVal,
+			`invalid strategy "deep"; must be "override", "append", "union" or "error"`,
+		},
+		{
+			cty.TupleVal([]cty.Value{base, overlay}),
+			[]cty.Value{cty.ObjectVal(map[string]cty.Value{
+				"strategies": cty.StringVal("append"),
+			})},
+			cty.NilVal,
+			`unsupported option "strategies"; the supported options are "strategy" and "keys"`,
+		},
+		{
+			cty.TupleVal([]cty.Value{base, overlay}),
+			[]cty.Value{cty.EmptyObjectVal, cty.EmptyObjectVal},
+			cty.NilVal,
+			`too many arguments; only one options object is allowed`,
+		},
+		{
+			cty.TupleVal([]cty.Value{base, cty.UnknownVal(cty.EmptyObject)}),
+			nil,
+			cty.DynamicVal,
+			``,
+		},
+		{
+			cty.TupleVal([]cty.Value{
+				base,
+				cty.ObjectVal(map[string]cty.Value{"name": cty.StringVal("secret").Mark(marks.Sensitive)}),
+			}),
+			nil,
+			cty.ObjectVal(map[string]cty.Value{
+				"name":    cty.StringVal("secret"),
+				"network": base.GetAttr("network"),
+				"tags":    base.GetAttr("tags"),
+				"zones":   base.GetAttr("zones"),
+			}).Mark(marks.Sensitive),
+			``,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("deepmerge(%#v, %#v)", test.Values, test.Options), func(t *testing.T) {
+			got, err := DeepMerge(test.Values, test.Options...)
+
+			if test.Err != "" {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				if got := err.Error(); got != test.Err {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, test.Err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
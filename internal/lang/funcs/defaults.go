@@ -0,0 +1,158 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"strconv"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// DefaultsFunc constructs a function that fills in the missing or null parts
+// of a value from a value of the same shape giving the defaults.
+var DefaultsFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name:             "value",
+			Type:             cty.DynamicPseudoType,
+			AllowNull:        true,
+			AllowUnknown:     true,
+			AllowDynamicType: true,
+			AllowMarked:      true,
+		},
+		{
+			Name:             "defaults",
+			Type:             cty.DynamicPseudoType,
+			AllowNull:        true,
+			AllowUnknown:     true,
+			AllowDynamicType: true,
+			AllowMarked:      true,
+		},
+	},
+	Type: function.StaticReturnType(cty.DynamicPseudoType),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		val, marks := args[0].UnmarkDeep()
+		defaults, defaultsMarks := args[1].UnmarkDeep()
+		for k := range defaultsMarks {
+			marks[k] = struct{}{}
+		}
+		return applyDefaults(val, defaults).WithMarks(marks), nil
+	},
+})
+
+// Defaults fills in the missing or null parts of the given value from the
+// given defaults.
+func Defaults(val, defaults cty.Value) (cty.Value, error) {
+	return DefaultsFunc.Call([]cty.Value{val, defaults})
+}
+
+// applyDefaults returns the given value with the given defaults applied:
+//
+//   - A null value is replaced by the default.
+//   - Objects and maps are merged key by key, with each key that is missing
+//     or null in the value taken from the default, and the defaults applied
+//     recursively to each key that both have.
+//   - When the value is a list, set or tuple and the default is not, the
+//     default applies to each element, so that a single default object can
+//     describe the elements of a collection.
+//   - Any other value is used as it is.
+func applyDefaults(val, defaults cty.Value) cty.Value {
+	switch {
+	case defaults.IsNull():
+		return val
+	case !val.IsKnown():
+		return val
+	case val.IsNull():
+		return defaults
+	}
+
+	ty := val.Type()
+	switch {
+	case isDeepMergeable(ty) && defaults.IsKnown() && isDeepMergeable(defaults.Type()):
+		attrs := val.AsValueMap()
+		if attrs == nil {
+			attrs = make(map[string]cty.Value)
+		}
+		for key, defaultVal := range defaults.AsValueMap() {
+			if attr, exists := attrs[key]; exists {
+				attrs[key] = applyDefaults(attr, defaultVal)
+				continue
+			}
+			attrs[key] = defaultVal
+		}
+		if ty.IsMapType() {
+			if unified, ok := unifyDefaultsElements(attrs); ok {
+				return cty.MapVal(unified)
+			}
+		}
+		return cty.ObjectVal(attrs)
+
+	case isDeepMergeable(ty) && !defaults.IsKnown():
+		// We can't know which keys the defaults will add.
+		return cty.DynamicVal
+
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		defaultsTy := defaults.Type()
+		if defaultsTy.IsListType() || defaultsTy.IsSetType() || defaultsTy.IsTupleType() {
+			return val
+		}
+		if val.LengthInt() == 0 {
+			return val
+		}
+		elems := val.AsValueSlice()
+		for i, elem := range elems {
+			elems[i] = applyDefaults(elem, defaults)
+		}
+		if ty.IsListType() || ty.IsSetType() {
+			byIndex := make(map[string]cty.Value, len(elems))
+			for i, elem := range elems {
+				byIndex[strconv.Itoa(i)] = elem
+			}
+			if unified, ok := unifyDefaultsElements(byIndex); ok {
+				for i := range elems {
+					elems[i] = unified[strconv.Itoa(i)]
+				}
+				if ty.IsSetType() {
+					return cty.SetVal(elems)
+				}
+				return cty.ListVal(elems)
+			}
+		}
+		return cty.TupleVal(elems)
+
+	default:
+		return val
+	}
+}
+
+// unifyDefaultsElements converts the given elements to a common type, if
+// there is one, so that a collection keeps its kind after defaults are
+// applied to its elements.
+func unifyDefaultsElements(elems map[string]cty.Value) (map[string]cty.Value, bool) {
+	keys := make([]string, 0, len(elems))
+	types := make([]cty.Type, 0, len(elems))
+	for key, elem := range elems {
+		keys = append(keys, key)
+		types = append(types, elem.Type())
+	}
+	unified, conversions := convert.UnifyUnsafe(types)
+	if unified == cty.NilType {
+		return nil, false
+	}
+	ret := make(map[string]cty.Value, len(elems))
+	for i, key := range keys {
+		if conversions[i] == nil {
+			ret[key] = elems[key]
+			continue
+		}
+		converted, err := conversions[i](elems[key])
+		if err != nil {
+			return nil, false
+		}
+		ret[key] = converted
+	}
+	return ret, true
+}
@@ -0,0 +1,134 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/lang/marks"
+)
+
+func TestDefaults(t *testing.T) {
+	tests := []struct {
+		Value    cty.Value
+		Defaults cty.Value
+		Want     cty.Value
+	}{
+		{
+			cty.StringVal("given"),
+			cty.StringVal("default"),
+			cty.StringVal("given"),
+		},
+		{
+			cty.NullVal(cty.String),
+			cty.StringVal("default"),
+			cty.StringVal("default"),
+		},
+		{
+			cty.StringVal("given"),
+			cty.NullVal(cty.String),
+			cty.StringVal("given"),
+		},
+		{
+			cty.UnknownVal(cty.String),
+			cty.StringVal("default"),
+			cty.UnknownVal(cty.String),
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("web"),
+				"port": cty.NullVal(cty.Number),
+				"health": cty.ObjectVal(map[string]cty.Value{
+					"path": cty.StringVal("/healthz"),
+				}),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"port": cty.NumberIntVal(80),
+				"health": cty.ObjectVal(map[string]cty.Value{
+					"path":     cty.StringVal("/"),
+					"interval": cty.NumberIntVal(30),
+				}),
+				"tags": cty.EmptyObjectVal,
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("web"),
+				"port": cty.NumberIntVal(80),
+				"health": cty.ObjectVal(map[string]cty.Value{
+					"path":     cty.StringVal("/healthz"),
+					"interval": cty.NumberIntVal(30),
+				}),
+				"tags": cty.EmptyObjectVal,
+			}),
+		},
+		{
+			cty.MapVal(map[string]cty.Value{
+				"team": cty.StringVal("network"),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"env": cty.StringVal("prod"),
+			}),
+			cty.MapVal(map[string]cty.Value{
+				"env":  cty.StringVal("prod"),
+				"team": cty.StringVal("network"),
+			}),
+		},
+		{
+			cty.ListVal([]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{
+					"port":     cty.NumberIntVal(80),
+					"protocol": cty.NullVal(cty.String),
+				}),
+				cty.ObjectVal(map[string]cty.Value{
+					"port":     cty.NumberIntVal(443),
+					"protocol": cty.StringVal("https"),
+				}),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"protocol": cty.StringVal("http"),
+			}),
+			cty.ListVal([]cty.Value{
+				cty.ObjectVal(map[string]cty.Value{
+					"port":     cty.NumberIntVal(80),
+					"protocol": cty.StringVal("http"),
+				}),
+				cty.ObjectVal(map[string]cty.Value{
+					"port":     cty.NumberIntVal(443),
+					"protocol": cty.StringVal("https"),
+				}),
+			}),
+		},
+		{
+			cty.ListVal([]cty.Value{cty.StringVal("a")}),
+			cty.ListVal([]cty.Value{cty.StringVal("b")}),
+			cty.ListVal([]cty.Value{cty.StringVal("a")}),
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("web"),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"password": cty.StringVal("secret").Mark(marks.Sensitive),
+			}),
+			cty.ObjectVal(map[string]cty.Value{
+				"name":     cty.StringVal("web"),
+				"password": cty.StringVal("secret"),
+			}).Mark(marks.Sensitive),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("defaults(%#v, %#v)", test.Value, test.Defaults), func(t *testing.T) {
+			got, err := Defaults(test.Value, test.Defaults)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
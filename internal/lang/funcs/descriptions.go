@@ -84,6 +84,13 @@ var DescriptionList = map[string]descriptionEntry{
 		Description:      "`chomp` removes newline characters at the end of a string.",
 		ParamDescription: []string{""},
 	},
+	"chunk": {
+		Description: "`chunk` splits a list, set or tuple into consecutive chunks of at most a given size, returning a list of lists, or a tuple of tuples if given a tuple.",
+		ParamDescription: []string{
+			"",
+			"The maximum length of each chunk. All but the last chunk are guaranteed to be of exactly this size. A size of zero returns a single chunk containing all of the elements.",
+		},
+	},
 	"chunklist": {
 		Description: "`chunklist` splits a single list into fixed-size chunks, returning a list of lists.",
 		ParamDescription: []string{
@@ -91,6 +98,13 @@ var DescriptionList = map[string]descriptionEntry{
 			"The maximum length of each chunk. All but the last element of the result is guaranteed to be of exactly this size.",
 		},
 	},
+	"cidrcontains": {
+		Description: "`cidrcontains` determines whether a given IP address or address prefix is within a given IP network address prefix.",
+		ParamDescription: []string{
+			"`containing_prefix` must be given in CIDR notation, as defined in [RFC 4632 section 3.1](https://tools.ietf.org/html/rfc4632#section-3.1).",
+			"`contained_ip_or_prefix` is either an IP address or an address prefix given in CIDR notation.",
+		},
+	},
 	"cidrhost": {
 		Description: "`cidrhost` calculates a full host IP address for a given host number within a given IP network address prefix.",
 		ParamDescription: []string{
@@ -144,6 +158,20 @@ var DescriptionList = map[string]descriptionEntry{
 		Description:      "`csvdecode` decodes a string containing CSV-formatted data and produces a list of maps representing that data.",
 		ParamDescription: []string{""},
 	},
+	"deepmerge": {
+		Description: "`deepmerge` takes a list of maps or objects and recursively merges them into a single object, using the given options to decide how to combine values that are not maps or objects.",
+		ParamDescription: []string{
+			"A list or tuple of the maps or objects to merge, in order of increasing precedence.",
+			"An optional object with a `strategy` attribute giving the default merge strategy, and a `keys` attribute mapping key paths to the strategy for that key. The strategies are `\"override\"`, `\"append\"`, `\"union\"` and `\"error\"`.",
+		},
+	},
+	"defaults": {
+		Description: "`defaults` fills in the missing or null parts of a value, including the attributes of nested objects and of the objects in collections, from a value of the same shape giving the defaults.",
+		ParamDescription: []string{
+			"The value to apply the defaults to.",
+			"The default values, in the same shape as the value. A default that is not a list, set or tuple applies to each element of a list, set or tuple in the value.",
+		},
+	},
 	"dirname": {
 		Description:      "`dirname` takes a string containing a filesystem path and removes the last portion from it.",
 		ParamDescription: []string{""},
@@ -212,14 +240,39 @@ var DescriptionList = map[string]descriptionEntry{
 		Description:      "The `format` function produces a string by formatting a number of other values according to a specification string. It is similar to the `printf` function in C, and other similar functions in other programming languages.",
 		ParamDescription: []string{"", ""},
 	},
+	"formatbytes": {
+		Description: "`formatbytes` formats a number of bytes as a data size with a unit, such as `5GiB`, using either the given unit or the largest unit that represents the size exactly.",
+		ParamDescription: []string{
+			"",
+			"The unit to use, such as `MiB` or `GB`. If omitted, the result uses the largest unit in which the size is a whole number.",
+		},
+	},
 	"formatdate": {
 		Description:      "`formatdate` converts a timestamp into a different time format.",
 		ParamDescription: []string{"", ""},
 	},
+	"formatduration": {
+		Description:      "`formatduration` formats a number of seconds as a duration string, such as `1h30m0s`.",
+		ParamDescription: []string{""},
+	},
 	"formatlist": {
 		Description:      "`formatlist` produces a list of strings by formatting a number of other values according to a specification string.",
 		ParamDescription: []string{"", ""},
 	},
+	"getpath": {
+		Description: "`getpath` returns the value at a given path of attribute names and element keys within a value, or `null` if any value along the path is `null`.",
+		ParamDescription: []string{
+			"",
+			"A list of the attribute names, map keys and element indices to follow, in order.",
+		},
+	},
+	"groupby": {
+		Description: "`groupby` takes a list, set or tuple of objects or maps and groups them by the value of a given attribute, returning a map from each distinct value of the attribute to the elements that have it.",
+		ParamDescription: []string{
+			"",
+			"The name of the attribute to group by. Each element must have this attribute, with a string, number or bool value.",
+		},
+	},
 	"indent": {
 		Description: "`indent` adds a given number of spaces to the beginnings of all but the first line in a given multi-line string.",
 		ParamDescription: []string{
@@ -246,6 +299,13 @@ var DescriptionList = map[string]descriptionEntry{
 		Description:      "`jsonencode` encodes a given value to a string using JSON syntax.",
 		ParamDescription: []string{""},
 	},
+	"jsonpatch": {
+		Description: "`jsonpatch` applies a JSON Patch document, as defined in [RFC 6902](https://tools.ietf.org/html/rfc6902), to a JSON document and returns the result as a JSON string.",
+		ParamDescription: []string{
+			"The JSON document to patch.",
+			"A JSON array of patch operations to apply, in order.",
+		},
+	},
 	"keys": {
 		Description: "`keys` takes a map and returns a list containing the keys from that map.",
 		ParamDescription: []string{
@@ -300,10 +360,22 @@ var DescriptionList = map[string]descriptionEntry{
 		Description:      "`nonsensitive` takes a sensitive value and returns a copy of that value with the sensitive marking removed, thereby exposing the sensitive value.",
 		ParamDescription: []string{""},
 	},
+	"nonsensitive_keys": {
+		Description:      "`nonsensitive_keys` takes a sensitive map or object and returns a copy of it in which only the element values are sensitive, so that its keys can be used and shown while the values stay hidden.",
+		ParamDescription: []string{""},
+	},
 	"one": {
 		Description:      "`one` takes a list, set, or tuple value with either zero or one elements. If the collection is empty, `one` returns `null`. Otherwise, `one` returns the first element. If there are two or more elements then `one` will return an error.",
 		ParamDescription: []string{""},
 	},
+	"parsebytes": {
+		Description:      "`parsebytes` parses a data size with an optional unit, such as `5GiB` or `500MB`, and returns the number of bytes.",
+		ParamDescription: []string{""},
+	},
+	"parseduration": {
+		Description:      "`parseduration` parses a duration string, such as `90m` or `1h30m`, and returns the number of seconds.",
+		ParamDescription: []string{""},
+	},
 	"parseint": {
 		Description:      "`parseint` parses the given string as a representation of an integer in the specified base and returns the resulting number. The base must be between 2 and 62 inclusive.",
 		ParamDescription: []string{"", ""},
@@ -340,6 +412,17 @@ var DescriptionList = map[string]descriptionEntry{
 		Description:      "`rsadecrypt` decrypts an RSA-encrypted ciphertext, returning the corresponding cleartext.",
 		ParamDescription: []string{"", ""},
 	},
+	"semvercompare": {
+		Description:      "`semvercompare` compares two version numbers given in [Semantic Versioning](https://semver.org/) syntax, returning -1 if the first is lower, 0 if they are equal, or 1 if the first is higher.",
+		ParamDescription: []string{"", ""},
+	},
+	"semverconstraint": {
+		Description: "`semverconstraint` determines whether a version number given in [Semantic Versioning](https://semver.org/) syntax meets a version constraint.",
+		ParamDescription: []string{
+			"",
+			"A version constraint string, using the same syntax as the `version` argument in `required_providers`.",
+		},
+	},
 	"sensitive": {
 		Description:      "`sensitive` takes any value and returns a copy of it marked so that OpenTofu will treat it as sensitive, with the same meaning and behavior as for [sensitive input variables](/language/values/variables#suppressing-values-in-cli-output).",
 		ParamDescription: []string{""},
@@ -414,6 +497,10 @@ var DescriptionList = map[string]descriptionEntry{
 		Description:      "`templatefile` reads the file at the given path and renders its content as a template using a supplied set of template variables.",
 		ParamDescription: []string{"", ""},
 	},
+	"templatestring": {
+		Description:      "`templatestring` renders the given string as a template using a supplied set of template variables.",
+		ParamDescription: []string{"", ""},
+	},
 	"textdecodebase64": {
 		Description:      "`textdecodebase64` function decodes a string that was previously Base64-encoded, and then interprets the result as characters in a specified character encoding.",
 		ParamDescription: []string{"", ""},
@@ -517,6 +604,13 @@ var DescriptionList = map[string]descriptionEntry{
 		Description:      "`values` takes a map and returns a list containing the values of the elements in that map.",
 		ParamDescription: []string{""},
 	},
+	"window": {
+		Description: "`window` returns each run of a given number of consecutive elements of a list, set or tuple, in order, as a list of lists, or a tuple of tuples if given a tuple.",
+		ParamDescription: []string{
+			"",
+			"The number of elements in each window.",
+		},
+	},
 	"yamldecode": {
 		Description:      "`yamldecode` parses a string as a subset of YAML, and produces a representation of its value.",
 		ParamDescription: []string{""},
@@ -525,6 +619,10 @@ var DescriptionList = map[string]descriptionEntry{
 		Description:      "`yamlencode` encodes a given value to a string using [YAML 1.2](https://yaml.org/spec/1.2/spec.html) block syntax.",
 		ParamDescription: []string{""},
 	},
+	"zip": {
+		Description:      "`zip` takes two lists or tuples with the same number of elements and returns a list of pairs of their corresponding elements, as two-element tuples.",
+		ParamDescription: []string{"", ""},
+	},
 	"zipmap": {
 		Description:      "`zipmap` constructs a map from a list of keys and a corresponding list of values.",
 		ParamDescription: []string{"", ""},
@@ -0,0 +1,354 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// JSONPatchFunc constructs a function that applies a JSON Patch document, as
+// defined in RFC 6902, to a JSON document.
+//
+// Both arguments and the result are strings containing JSON, so that the
+// function can be used with jsonencode and jsondecode.
+var JSONPatchFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "doc",
+			Type: cty.String,
+		},
+		{
+			Name: "patch",
+			Type: cty.String,
+		},
+	},
+	Type:         function.StaticReturnType(cty.String),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		doc, err := decodeJSONForPatch(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(0, "invalid JSON document: %s", err)
+		}
+
+		var ops []jsonPatchOp
+		if err := json.Unmarshal([]byte(args[1].AsString()), &ops); err != nil {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(1, "invalid JSON Patch: %s", err)
+		}
+
+		for i, op := range ops {
+			doc, err = op.apply(doc)
+			if err != nil {
+				return cty.UnknownVal(cty.String), function.NewArgErrorf(1, "operation %d (%s) failed: %s", i, op, err)
+			}
+		}
+
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(doc); err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		return cty.StringVal(strings.TrimSuffix(buf.String(), "\n")), nil
+	},
+})
+
+// JSONPatch applies a JSON Patch document to a JSON document.
+func JSONPatch(doc, patch cty.Value) (cty.Value, error) {
+	return JSONPatchFunc.Call([]cty.Value{doc, patch})
+}
+
+// jsonPatchOp is a single operation in a JSON Patch document.
+type jsonPatchOp struct {
+	Op    string           `json:"op"`
+	Path  *string          `json:"path"`
+	From  *string          `json:"from"`
+	Value *json.RawMessage `json:"value"`
+}
+
+func (op jsonPatchOp) String() string {
+	if op.Path == nil {
+		return op.Op
+	}
+	return fmt.Sprintf("%s %q", op.Op, *op.Path)
+}
+
+func (op jsonPatchOp) apply(doc interface{}) (interface{}, error) {
+	if op.Path == nil {
+		return nil, fmt.Errorf("missing \"path\"")
+	}
+	path, err := parseJSONPointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("missing \"value\"")
+		}
+		value, err := decodeJSONForPatch(string(*op.Value))
+		if err != nil {
+			return nil, err
+		}
+		switch op.Op {
+		case "add":
+			return jsonPatchAdd(doc, path, value)
+		case "replace":
+			if _, err := jsonPointerGet(doc, path); err != nil {
+				return nil, err
+			}
+			doc, _, err = jsonPatchRemove(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			return jsonPatchAdd(doc, path, value)
+		default:
+			current, err := jsonPointerGet(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, fmt.Errorf("test failed: the value is %s", mustMarshalJSON(current))
+			}
+			return doc, nil
+		}
+
+	case "remove":
+		doc, _, err = jsonPatchRemove(doc, path)
+		return doc, err
+
+	case "move", "copy":
+		if op.From == nil {
+			return nil, fmt.Errorf("missing \"from\"")
+		}
+		from, err := parseJSONPointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "copy" {
+			value, err := jsonPointerGet(doc, from)
+			if err != nil {
+				return nil, err
+			}
+			return jsonPatchAdd(doc, path, deepCopyJSON(value))
+		}
+		if len(path) > len(from) && reflect.DeepEqual(path[:len(from)], from) {
+			return nil, fmt.Errorf("cannot move a value into one of its own children")
+		}
+		doc, value, err := jsonPatchRemove(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return jsonPatchAdd(doc, path, value)
+
+	default:
+		return nil, fmt.Errorf("unsupported operation %q", op.Op)
+	}
+}
+
+// parseJSONPointer parses a JSON Pointer, as defined in RFC 6901, into its
+// reference tokens.
+func parseJSONPointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(ptr, "/") {
+		return nil, fmt.Errorf("invalid JSON Pointer %q: must be empty or start with \"/\"", ptr)
+	}
+	tokens := strings.Split(ptr[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+func jsonPointerGet(doc interface{}, path []string) (interface{}, error) {
+	for i, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			child, exists := node[token]
+			if !exists {
+				return nil, fmt.Errorf("no value at %s", formatJSONPointer(path[:i+1]))
+			}
+			doc = child
+		case []interface{}:
+			idx, err := jsonArrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, fmt.Errorf("%s at %s", err, formatJSONPointer(path[:i+1]))
+			}
+			doc = node[idx]
+		default:
+			return nil, fmt.Errorf("no value at %s", formatJSONPointer(path[:i+1]))
+		}
+	}
+	return doc, nil
+}
+
+// jsonPatchAdd returns the given document with the given value added at the
+// given path, which may modify the given document.
+func jsonPatchAdd(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := jsonPointerGet(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[token] = value
+		return doc, nil
+	case []interface{}:
+		idx, err := jsonArrayIndex(token, len(node), true)
+		if err != nil {
+			return nil, fmt.Errorf("%s at %s", err, formatJSONPointer(path))
+		}
+		updated := make([]interface{}, 0, len(node)+1)
+		updated = append(updated, node[:idx]...)
+		updated = append(updated, value)
+		updated = append(updated, node[idx:]...)
+		return jsonPatchSet(doc, path[:len(path)-1], updated)
+	default:
+		return nil, fmt.Errorf("cannot add a value at %s, because its parent is not an object or array", formatJSONPointer(path))
+	}
+}
+
+// jsonPatchRemove returns the given document with the value at the given
+// path removed, which may modify the given document, and the removed value.
+func jsonPatchRemove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, doc, nil
+	}
+	parent, err := jsonPointerGet(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	token := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		value, exists := node[token]
+		if !exists {
+			return nil, nil, fmt.Errorf("no value at %s", formatJSONPointer(path))
+		}
+		delete(node, token)
+		return doc, value, nil
+	case []interface{}:
+		idx, err := jsonArrayIndex(token, len(node), false)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s at %s", err, formatJSONPointer(path))
+		}
+		value := node[idx]
+		updated := make([]interface{}, 0, len(node)-1)
+		updated = append(updated, node[:idx]...)
+		updated = append(updated, node[idx+1:]...)
+		doc, err = jsonPatchSet(doc, path[:len(path)-1], updated)
+		return doc, value, err
+	default:
+		return nil, nil, fmt.Errorf("no value at %s", formatJSONPointer(path))
+	}
+}
+
+// jsonPatchSet returns the given document with the value at the given path,
+// which must already exist, replaced by the given value.
+func jsonPatchSet(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := jsonPointerGet(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[token] = value
+	case []interface{}:
+		idx, err := jsonArrayIndex(token, len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		node[idx] = value
+	}
+	return doc, nil
+}
+
+// jsonArrayIndex parses a JSON Pointer reference token as an index into an
+// array of the given length. If forAdd is set then the index can be one past
+// the end of the array, or "-" to mean the end of the array.
+func jsonArrayIndex(token string, length int, forAdd bool) (int, error) {
+	if token == "-" && forAdd {
+		return length, nil
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if idx > length || (idx == length && !forAdd) {
+		return 0, fmt.Errorf("array index %d out of range", idx)
+	}
+	return idx, nil
+}
+
+func formatJSONPointer(path []string) string {
+	var buf strings.Builder
+	for _, token := range path {
+		buf.WriteByte('/')
+		buf.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(token))
+	}
+	return buf.String()
+}
+
+// decodeJSONForPatch decodes the given JSON, keeping numbers in their
+// original form so that they are written back out unchanged.
+func decodeJSONForPatch(src string) (interface{}, error) {
+	dec := json.NewDecoder(strings.NewReader(src))
+	dec.UseNumber()
+	var ret interface{}
+	if err := dec.Decode(&ret); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("extra data after the JSON value")
+	}
+	return ret, nil
+}
+
+func deepCopyJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			ret[k] = deepCopyJSON(child)
+		}
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, len(v))
+		for i, child := range v {
+			ret[i] = deepCopyJSON(child)
+		}
+		return ret
+	default:
+		return v
+	}
+}
+
+func mustMarshalJSON(v interface{}) string {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%#v", v)
+	}
+	return string(raw)
+}
@@ -0,0 +1,151 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestJSONPatch(t *testing.T) {
+	tests := []struct {
+		Doc   cty.Value
+		Patch cty.Value
+		Want  cty.Value
+		Err   string
+	}{
+		{
+			cty.StringVal(`{"a":1}`),
+			cty.StringVal(`[]`),
+			cty.StringVal(`{"a":1}`),
+			``,
+		},
+		{
+			cty.StringVal(`{"a":1}`),
+			cty.StringVal(`[{"op":"add","path":"/b","value":{"c":[true]}}]`),
+			cty.StringVal(`{"a":1,"b":{"c":[true]}}`),
+			``,
+		},
+		{
+			cty.StringVal(`{"list":["a","c"]}`),
+			cty.StringVal(`[{"op":"add","path":"/list/1","value":"b"},{"op":"add","path":"/list/-","value":"d"}]`),
+			cty.StringVal(`{"list":["a","b","c","d"]}`),
+			``,
+		},
+		{
+			cty.StringVal(`{"a":1,"b":2,"list":[1,2,3]}`),
+			cty.StringVal(`[{"op":"remove","path":"/a"},{"op":"remove","path":"/list/0"}]`),
+			cty.StringVal(`{"b":2,"list":[2,3]}`),
+			``,
+		},
+		{
+			cty.StringVal(`{"a":1}`),
+			cty.StringVal(`[{"op":"replace","path":"/a","value":1.50}]`),
+			cty.StringVal(`{"a":1.50}`),
+			``,
+		},
+		{
+			cty.StringVal(`{"a":{"b":1},"c":{}}`),
+			cty.StringVal(`[{"op":"move","from":"/a/b","path":"/c/d"}]`),
+			cty.StringVal(`{"a":{},"c":{"d":1}}`),
+			``,
+		},
+		{
+			cty.StringVal(`{"a":[1,2]}`),
+			cty.StringVal(`[{"op":"copy","from":"/a","path":"/b"},{"op":"add","path":"/b/-","value":3}]`),
+			cty.StringVal(`{"a":[1,2],"b":[1,2,3]}`),
+			``,
+		},
+		{
+			cty.StringVal(`{"a/b":{"c~d":1}}`),
+			cty.StringVal(`[{"op":"test","path":"/a~1b/c~0d","value":1},{"op":"replace","path":"","value":"whole"}]`),
+			cty.StringVal(`"whole"`),
+			``,
+		},
+		{
+			cty.StringVal(`{"html":"<b>"}`),
+			cty.StringVal(`[]`),
+			cty.StringVal(`{"html":"<b>"}`),
+			``,
+		},
+		{
+			cty.StringVal(`{"a":1}`),
+			cty.StringVal(`[{"op":"test","path":"/a","value":2}]`),
+			cty.UnknownVal(cty.String),
+			`operation 0 (test "/a") failed: test failed: the value is 1`,
+		},
+		{
+			cty.StringVal(`{"a":1}`),
+			cty.StringVal(`[{"op":"remove","path":"/b"}]`),
+			cty.UnknownVal(cty.String),
+			`operation 0 (remove "/b") failed: no value at /b`,
+		},
+		{
+			cty.StringVal(`{"a":1}`),
+			cty.StringVal(`[{"op":"replace","path":"/b","value":2}]`),
+			cty.UnknownVal(cty.String),
+			`operation 0 (replace "/b") failed: no value at /b`,
+		},
+		{
+			cty.StringVal(`{"list":[1]}`),
+			cty.StringVal(`[{"op":"add","path":"/list/2","value":2}]`),
+			cty.UnknownVal(cty.String),
+			`operation 0 (add "/list/2") failed: array index 2 out of range at /list/2`,
+		},
+		{
+			cty.StringVal(`{"a":{"b":1}}`),
+			cty.StringVal(`[{"op":"move","from":"/a","path":"/a/c"}]`),
+			cty.UnknownVal(cty.String),
+			`operation 0 (move "/a/c") failed: cannot move a value into one of its own children`,
+		},
+		{
+			cty.StringVal(`{"a":1}`),
+			cty.StringVal(`[{"op":"frob","path":"/a"}]`),
+			cty.UnknownVal(cty.String),
+			`operation 0 (frob "/a") failed: unsupported operation "frob"`,
+		},
+		{
+			cty.StringVal(`{"a":1}`),
+			cty.StringVal(`[{"op":"add","path":"a","value":1}]`),
+			cty.UnknownVal(cty.String),
+			`operation 0 (add "a") failed: invalid JSON Pointer "a": must be empty or start with "/"`,
+		},
+		{
+			cty.StringVal(`{"a":`),
+			cty.StringVal(`[]`),
+			cty.UnknownVal(cty.String),
+			`invalid JSON document: unexpected EOF`,
+		},
+		{
+			cty.StringVal(`{}`),
+			cty.StringVal(`{"op":"add"}`),
+			cty.UnknownVal(cty.String),
+			`invalid JSON Patch: json: cannot unmarshal object into Go value of type []funcs.jsonPatchOp`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("jsonpatch(%#v, %#v)", test.Doc, test.Patch), func(t *testing.T) {
+			got, err := JSONPatch(test.Doc, test.Patch)
+
+			if test.Err != "" {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				if got := err.Error(); got != test.Err {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, test.Err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
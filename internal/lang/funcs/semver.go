@@ -0,0 +1,79 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"github.com/hashicorp/go-version"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// SemverCompareFunc constructs a function that compares two semantic
+// version strings, returning -1, 0 or 1.
+var SemverCompareFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "v1",
+			Type: cty.String,
+		},
+		{
+			Name: "v2",
+			Type: cty.String,
+		},
+	},
+	Type:         function.StaticReturnType(cty.Number),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		v1, err := version.NewSemver(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.Number), function.NewArgErrorf(0, "invalid version %q: %s", args[0].AsString(), err)
+		}
+		v2, err := version.NewSemver(args[1].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.Number), function.NewArgErrorf(1, "invalid version %q: %s", args[1].AsString(), err)
+		}
+		return cty.NumberIntVal(int64(v1.Compare(v2))), nil
+	},
+})
+
+// SemverConstraintFunc constructs a function that checks whether a semantic
+// version string meets a version constraint string, using the same syntax
+// as the version constraints elsewhere in the configuration.
+var SemverConstraintFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "version",
+			Type: cty.String,
+		},
+		{
+			Name: "constraint",
+			Type: cty.String,
+		},
+	},
+	Type:         function.StaticReturnType(cty.Bool),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		v, err := version.NewSemver(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.Bool), function.NewArgErrorf(0, "invalid version %q: %s", args[0].AsString(), err)
+		}
+		constraints, err := version.NewConstraint(args[1].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.Bool), function.NewArgErrorf(1, "invalid version constraint %q: %s", args[1].AsString(), err)
+		}
+		return cty.BoolVal(constraints.Check(v)), nil
+	},
+})
+
+// SemverCompare compares two semantic version strings, returning -1 if the
+// first is lower, 0 if they are equal, or 1 if the first is higher.
+func SemverCompare(v1, v2 cty.Value) (cty.Value, error) {
+	return SemverCompareFunc.Call([]cty.Value{v1, v2})
+}
+
+// SemverConstraint checks whether a semantic version string meets a version
+// constraint string.
+func SemverConstraint(version, constraint cty.Value) (cty.Value, error) {
+	return SemverConstraintFunc.Call([]cty.Value{version, constraint})
+}
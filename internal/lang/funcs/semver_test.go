@@ -0,0 +1,141 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestSemverCompare(t *testing.T) {
+	tests := []struct {
+		V1   cty.Value
+		V2   cty.Value
+		Want cty.Value
+		Err  bool
+	}{
+		{
+			cty.StringVal("1.2.3"),
+			cty.StringVal("1.2.3"),
+			cty.NumberIntVal(0),
+			false,
+		},
+		{
+			cty.StringVal("1.2.0"),
+			cty.StringVal("1.10.0"),
+			cty.NumberIntVal(-1),
+			false,
+		},
+		{
+			cty.StringVal("2.0.0"),
+			cty.StringVal("2.0.0-beta1"),
+			cty.NumberIntVal(1),
+			false,
+		},
+		{
+			cty.StringVal("v1.0.0"),
+			cty.StringVal("1.0.0"),
+			cty.NumberIntVal(0),
+			false,
+		},
+		{
+			cty.StringVal("not-a-version"),
+			cty.StringVal("1.0.0"),
+			cty.UnknownVal(cty.Number),
+			true,
+		},
+		{
+			cty.StringVal("1.0.0"),
+			cty.StringVal("1.x"),
+			cty.UnknownVal(cty.Number),
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("semvercompare(%#v, %#v)", test.V1, test.V2), func(t *testing.T) {
+			got, err := SemverCompare(test.V1, test.V2)
+
+			if test.Err {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestSemverConstraint(t *testing.T) {
+	tests := []struct {
+		Version    cty.Value
+		Constraint cty.Value
+		Want       cty.Value
+		Err        bool
+	}{
+		{
+			cty.StringVal("1.2.3"),
+			cty.StringVal(">= 1.0, < 2.0"),
+			cty.True,
+			false,
+		},
+		{
+			cty.StringVal("2.0.0"),
+			cty.StringVal(">= 1.0, < 2.0"),
+			cty.False,
+			false,
+		},
+		{
+			cty.StringVal("1.4.7"),
+			cty.StringVal("~> 1.4.0"),
+			cty.True,
+			false,
+		},
+		{
+			cty.StringVal("1.5.0"),
+			cty.StringVal("~> 1.4.0"),
+			cty.False,
+			false,
+		},
+		{
+			cty.StringVal("not-a-version"),
+			cty.StringVal(">= 1.0"),
+			cty.UnknownVal(cty.Bool),
+			true,
+		},
+		{
+			cty.StringVal("1.0.0"),
+			cty.StringVal("not a constraint"),
+			cty.UnknownVal(cty.Bool),
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("semverconstraint(%#v, %#v)", test.Version, test.Constraint), func(t *testing.T) {
+			got, err := SemverConstraint(test.Version, test.Constraint)
+
+			if test.Err {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
@@ -61,6 +61,57 @@ var NonsensitiveFunc = function.New(&function.Spec{
 	},
 })
 
+// NonsensitiveKeysFunc takes a sensitive map or object and returns the same
+// value with the sensitive marking moved from the collection as a whole to
+// each of its element values, so that the keys are no longer sensitive but
+// the values still are.
+var NonsensitiveKeysFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name:             "value",
+			Type:             cty.DynamicPseudoType,
+			AllowUnknown:     true,
+			AllowNull:        true,
+			AllowMarked:      true,
+			AllowDynamicType: true,
+		},
+	},
+	Type: func(args []cty.Value) (cty.Type, error) {
+		ty := args[0].Type()
+		if !(ty.IsMapType() || ty.IsObjectType() || ty == cty.DynamicPseudoType) {
+			return cty.NilType, function.NewArgErrorf(0, "must be a map or object, not %s", ty.FriendlyName())
+		}
+		// This function only affects the value's marks, so the result
+		// type is always the same as the argument type.
+		return ty, nil
+	},
+	Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+		if args[0].IsKnown() && !args[0].HasMark(marks.Sensitive) {
+			return cty.DynamicVal, function.NewArgErrorf(0, "the given value is not sensitive, so this call is redundant")
+		}
+		v, m := args[0].Unmark()
+		if !v.IsKnown() {
+			// We can't know the keys yet, so the whole value must remain
+			// sensitive.
+			return args[0], nil
+		}
+		delete(m, marks.Sensitive) // remove the sensitive marking
+		if v.IsNull() || v.LengthInt() == 0 {
+			return v.WithMarks(m), nil
+		}
+
+		elems := make(map[string]cty.Value, v.LengthInt())
+		for it := v.ElementIterator(); it.Next(); {
+			k, ev := it.Element()
+			elems[k.AsString()] = ev.Mark(marks.Sensitive)
+		}
+		if v.Type().IsMapType() {
+			return cty.MapVal(elems).WithMarks(m), nil
+		}
+		return cty.ObjectVal(elems).WithMarks(m), nil
+	},
+})
+
 func Sensitive(v cty.Value) (cty.Value, error) {
 	return SensitiveFunc.Call([]cty.Value{v})
 }
@@ -68,3 +119,7 @@ func Sensitive(v cty.Value) (cty.Value, error) {
 func Nonsensitive(v cty.Value) (cty.Value, error) {
 	return NonsensitiveFunc.Call([]cty.Value{v})
 }
+
+func NonsensitiveKeys(v cty.Value) (cty.Value, error) {
+	return NonsensitiveKeysFunc.Call([]cty.Value{v})
+}
@@ -180,3 +180,95 @@ func TestNonsensitive(t *testing.T) {
 		})
 	}
 }
+
+func TestNonsensitiveKeys(t *testing.T) {
+	tests := []struct {
+		Input   cty.Value
+		Want    cty.Value
+		WantErr string
+	}{
+		{
+			cty.MapVal(map[string]cty.Value{
+				"a": cty.StringVal("x"),
+				"b": cty.StringVal("y"),
+			}).Mark(marks.Sensitive),
+			cty.MapVal(map[string]cty.Value{
+				"a": cty.StringVal("x").Mark(marks.Sensitive),
+				"b": cty.StringVal("y").Mark(marks.Sensitive),
+			}),
+			``,
+		},
+		{
+			cty.ObjectVal(map[string]cty.Value{
+				"name":     cty.StringVal("admin"),
+				"password": cty.StringVal("hunter2").Mark(marks.Sensitive),
+			}).Mark(marks.Sensitive),
+			cty.ObjectVal(map[string]cty.Value{
+				"name":     cty.StringVal("admin").Mark(marks.Sensitive),
+				"password": cty.StringVal("hunter2").Mark(marks.Sensitive),
+			}),
+			``,
+		},
+		{
+			// Other marks on the collection are kept.
+			cty.MapVal(map[string]cty.Value{
+				"a": cty.StringVal("x"),
+			}).Mark(marks.Sensitive).Mark("other"),
+			cty.MapVal(map[string]cty.Value{
+				"a": cty.StringVal("x").Mark(marks.Sensitive),
+			}).Mark("other"),
+			``,
+		},
+		{
+			cty.MapValEmpty(cty.String).Mark(marks.Sensitive),
+			cty.MapValEmpty(cty.String),
+			``,
+		},
+		{
+			cty.NullVal(cty.Map(cty.String)).Mark(marks.Sensitive),
+			cty.NullVal(cty.Map(cty.String)),
+			``,
+		},
+		{
+			// The keys of an unknown map aren't known yet, so it stays
+			// sensitive.
+			cty.UnknownVal(cty.Map(cty.String)).Mark(marks.Sensitive),
+			cty.UnknownVal(cty.Map(cty.String)).Mark(marks.Sensitive),
+			``,
+		},
+		{
+			cty.MapVal(map[string]cty.Value{
+				"a": cty.StringVal("x").Mark(marks.Sensitive),
+			}),
+			cty.NilVal,
+			`the given value is not sensitive, so this call is redundant`,
+		},
+		{
+			cty.ListVal([]cty.Value{cty.StringVal("x")}).Mark(marks.Sensitive),
+			cty.NilVal,
+			`must be a map or object, not list of string`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("nonsensitive_keys(%#v)", test.Input), func(t *testing.T) {
+			got, err := NonsensitiveKeys(test.Input)
+
+			if test.WantErr != "" {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				if got, want := err.Error(), test.WantErr; got != want {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
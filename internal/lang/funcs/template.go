@@ -0,0 +1,209 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// MakeTemplateStringFunc constructs a function that takes a string and an
+// arbitrary object of named values and attempts to render the string as a
+// template using HCL template syntax.
+//
+// Unlike templatefile, the template can come from any string value, such as
+// an input variable or a data source attribute. As with templatefile, the
+// template can only access the variables provided in the second function
+// argument, and may not call templatefile or templatestring itself.
+func MakeTemplateStringFunc(funcsCb func() map[string]function.Function) function.Function {
+	params := []function.Parameter{
+		{
+			Name:        "template",
+			Type:        cty.String,
+			AllowMarked: true,
+		},
+		{
+			Name: "vars",
+			Type: cty.DynamicPseudoType,
+		},
+	}
+
+	loadTmpl := func(tmpl string) (hcl.Expression, error) {
+		// The template has no source file, so we leave the filename empty
+		// and describe the positions of any problems within the template
+		// string itself.
+		expr, diags := hclsyntax.ParseTemplate([]byte(tmpl), "", hcl.Pos{Line: 1, Column: 1})
+		if diags.HasErrors() {
+			return nil, function.NewArgErrorf(0, "invalid template: %s", templateDiagsError(diags))
+		}
+		return expr, nil
+	}
+
+	renderTmpl := func(expr hcl.Expression, varsVal cty.Value) (cty.Value, error) {
+		// this callback indirection is to avoid chicken/egg problems
+		return renderTemplate(expr, varsVal, templateFuncs("templatestring", funcsCb()))
+	}
+
+	return function.New(&function.Spec{
+		Params: params,
+		Type: func(args []cty.Value) (cty.Type, error) {
+			if !(args[0].IsKnown() && args[1].IsKnown()) {
+				return cty.DynamicPseudoType, nil
+			}
+
+			// We'll render our template now to see what result type it
+			// produces. A template consisting only of a single interpolation
+			// can potentially return any type.
+			tmplArg, _ := args[0].Unmark()
+			expr, err := loadTmpl(tmplArg.AsString())
+			if err != nil {
+				return cty.DynamicPseudoType, err
+			}
+
+			// This is safe even if args[1] contains unknowns because the HCL
+			// template renderer itself knows how to short-circuit those.
+			val, err := renderTmpl(expr, args[1])
+			return val.Type(), err
+		},
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			tmplArg, tmplMarks := args[0].Unmark()
+			expr, err := loadTmpl(tmplArg.AsString())
+			if err != nil {
+				return cty.DynamicVal, err
+			}
+			result, err := renderTmpl(expr, args[1])
+			return result.WithMarks(tmplMarks), err
+		},
+	})
+}
+
+// renderTemplate renders the given template expression with the given
+// variables and functions, for templatefile and templatestring. The variables
+// must be the second argument of the calling function.
+func renderTemplate(expr hcl.Expression, varsVal cty.Value, funcs map[string]function.Function) (cty.Value, error) {
+	if varsTy := varsVal.Type(); !(varsTy.IsMapType() || varsTy.IsObjectType()) {
+		return cty.DynamicVal, function.NewArgErrorf(1, "invalid vars value: must be a map") // or an object, but we don't strongly distinguish these most of the time
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: varsVal.AsValueMap(),
+		Functions: funcs,
+	}
+
+	// We require all of the variables to be valid HCL identifiers, because
+	// otherwise there would be no way to refer to them in the template
+	// anyway. Rejecting this here gives better feedback to the user
+	// than a syntax error somewhere in the template itself.
+	for n := range ctx.Variables {
+		if !hclsyntax.ValidIdentifier(n) {
+			// This error message intentionally doesn't describe _all_ of
+			// the different permutations that are technically valid as an
+			// HCL identifier, but rather focuses on what we might
+			// consider to be an "idiomatic" variable name.
+			return cty.DynamicVal, function.NewArgErrorf(1, "invalid template variable name %q: must start with a letter, followed by zero or more letters, digits, and underscores", n)
+		}
+	}
+
+	// We'll pre-check references in the template here so we can give a
+	// more specialized error message than HCL would by default, so it's
+	// clearer that this problem is coming from a template function call.
+	for _, traversal := range expr.Variables() {
+		root := traversal.RootName()
+		if _, ok := ctx.Variables[root]; !ok {
+			return cty.DynamicVal, function.NewArgErrorf(1, "vars map does not contain key %q, referenced at %s", root, templateRangeString(traversal[0].SourceRange()))
+		}
+	}
+
+	val, diags := expr.Value(ctx)
+	if diags.HasErrors() {
+		return cty.DynamicVal, templateDiagsError(diags)
+	}
+	return val, nil
+}
+
+// templateFuncs returns a copy of the given functions for use in a template
+// rendered by the given template function, with the template functions
+// replaced by stubs that return an error. This prevents templates from
+// including themselves indefinitely.
+func templateFuncs(caller string, givenFuncs map[string]function.Function) map[string]function.Function {
+	funcs := make(map[string]function.Function, len(givenFuncs))
+	for name, fn := range givenFuncs {
+		switch name {
+		case "templatefile", "templatestring":
+			name := name
+			funcs[name] = function.New(&function.Spec{
+				VarParam: &function.Parameter{
+					Name:             "args",
+					Type:             cty.DynamicPseudoType,
+					AllowUnknown:     true,
+					AllowDynamicType: true,
+					AllowNull:        true,
+					AllowMarked:      true,
+				},
+				Type: func(args []cty.Value) (cty.Type, error) {
+					return cty.NilType, fmt.Errorf("cannot recursively call %s from inside %s call", name, caller)
+				},
+			})
+		default:
+			funcs[name] = fn
+		}
+	}
+	return funcs
+}
+
+// templateRangeString describes the given range within a template. Templates
+// given as strings have no filename, so for those we describe only the
+// position within the template.
+func templateRangeString(rng hcl.Range) string {
+	if rng.Filename != "" {
+		return rng.String()
+	}
+	return fmt.Sprintf("line %d, column %d", rng.Start.Line, rng.Start.Column)
+}
+
+// templateDetailRangePattern matches the source ranges without a filename
+// that HCL includes in the details of some template diagnostics.
+var templateDetailRangePattern = regexp.MustCompile(`(?:^|\B):(\d+),(\d+)-\d+(?:,\d+)?`)
+
+// templateDiagsError returns an error describing the errors in the given
+// diagnostics from parsing or rendering a template.
+//
+// The diagnostics for templates read from files already include the filename
+// and position, so these are returned as they are.
+func templateDiagsError(diags hcl.Diagnostics) error {
+	var errs hcl.Diagnostics
+	for _, diag := range diags {
+		if diag.Severity == hcl.DiagError {
+			errs = append(errs, diag)
+		}
+	}
+	if len(errs) == 0 || errs[0].Subject == nil || errs[0].Subject.Filename != "" {
+		return diags
+	}
+
+	var b strings.Builder
+	for i, diag := range errs {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		if diag.Subject != nil {
+			fmt.Fprintf(&b, "%s: ", templateRangeString(*diag.Subject))
+		}
+		b.WriteString(diag.Summary)
+		if diag.Detail != "" {
+			// Some details refer to other positions in the template, such as
+			// the opening directive of an unterminated block, which also
+			// have no filename.
+			detail := templateDetailRangePattern.ReplaceAllString(diag.Detail, "line $1, column $2")
+			fmt.Fprintf(&b, ": %s", strings.TrimSuffix(detail, "."))
+		}
+	}
+	return fmt.Errorf("%s", b.String())
+}
@@ -0,0 +1,168 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+
+	"github.com/opentofu/opentofu/internal/lang/marks"
+)
+
+func TestTemplateString(t *testing.T) {
+	tests := []struct {
+		Template cty.Value
+		Vars     cty.Value
+		Want     cty.Value
+		Err      string
+	}{
+		{
+			cty.StringVal("Hello World"),
+			cty.EmptyObjectVal,
+			cty.StringVal("Hello World"),
+			``,
+		},
+		{
+			cty.StringVal("Hello, ${name}!"),
+			cty.MapVal(map[string]cty.Value{
+				"name": cty.StringVal("Jodie"),
+			}),
+			cty.StringVal("Hello, Jodie!"),
+			``,
+		},
+		{
+			cty.StringVal("Hello, ${name}!").Mark(marks.Sensitive),
+			cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("Jimbo"),
+			}),
+			cty.StringVal("Hello, Jimbo!").Mark(marks.Sensitive),
+			``,
+		},
+		{
+			cty.StringVal(`The items are ${join(", ", list)}`),
+			cty.ObjectVal(map[string]cty.Value{
+				"list": cty.ListVal([]cty.Value{
+					cty.StringVal("a"),
+					cty.StringVal("b"),
+					cty.StringVal("c"),
+				}),
+			}),
+			cty.StringVal("The items are a, b, c"),
+			``,
+		},
+		{
+			cty.StringVal("%{ for x in list ~}\n- ${x}\n%{ endfor ~}"),
+			cty.ObjectVal(map[string]cty.Value{
+				"list": cty.ListVal([]cty.Value{
+					cty.StringVal("a"),
+					cty.StringVal("b"),
+				}),
+			}),
+			cty.StringVal("- a\n- b\n"),
+			``,
+		},
+		{
+			cty.StringVal("${val}"),
+			cty.ObjectVal(map[string]cty.Value{
+				"val": cty.True,
+			}),
+			cty.True, // since this template contains only an interpolation, its true value shines through
+			``,
+		},
+		{
+			cty.StringVal("Hello, ${name}!"),
+			cty.MapVal(map[string]cty.Value{
+				"name!": cty.StringVal("Jodie"),
+			}),
+			cty.NilVal,
+			`invalid template variable name "name!": must start with a letter, followed by zero or more letters, digits, and underscores`,
+		},
+		{
+			cty.StringVal("Hello, ${name}!"),
+			cty.EmptyObjectVal,
+			cty.NilVal,
+			`vars map does not contain key "name", referenced at line 1, column 10`,
+		},
+		{
+			cty.StringVal("Hello\n%{ if enabled }world"),
+			cty.ObjectVal(map[string]cty.Value{
+				"enabled": cty.True,
+			}),
+			cty.NilVal,
+			`invalid template: line 2, column 21: Unexpected end of template: The if directive at line 2, column 1 is missing its corresponding endif directive`,
+		},
+		{
+			cty.StringVal("Hello%{ endif }"),
+			cty.EmptyObjectVal,
+			cty.NilVal,
+			`invalid template: line 1, column 6: Unexpected endif directive: The control directives within this template are unbalanced`,
+		},
+		{
+			cty.StringVal("%{ for x in list }${x}%{ endfor }"),
+			cty.ObjectVal(map[string]cty.Value{
+				"list": cty.True,
+			}),
+			cty.NilVal,
+			`line 1, column 13: Iteration over non-iterable value: A value of type bool cannot be used as the collection in a 'for' expression`,
+		},
+		{
+			cty.StringVal(`${templatestring("x", {})}`),
+			cty.EmptyObjectVal,
+			cty.NilVal,
+			`line 1, column 3: Error in function call: Call to function "templatestring" failed: cannot recursively call templatestring from inside templatestring call`,
+		},
+		{
+			cty.StringVal(`${templatefile("hello.tmpl", {})}`),
+			cty.EmptyObjectVal,
+			cty.NilVal,
+			`line 1, column 3: Error in function call: Call to function "templatefile" failed: cannot recursively call templatefile from inside templatestring call`,
+		},
+		{
+			cty.UnknownVal(cty.String),
+			cty.EmptyObjectVal,
+			cty.DynamicVal,
+			``,
+		},
+	}
+
+	templateStringFn := MakeTemplateStringFunc(func() map[string]function.Function {
+		return map[string]function.Function{
+			"join":           stdlib.JoinFunc,
+			"templatefile":   MakeFileFunc(".", false), // just a placeholder, since templatestring overrides this
+			"templatestring": MakeFileFunc(".", false), // just a placeholder, since templatestring itself overrides this
+		}
+	})
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("TemplateString(%#v, %#v)", test.Template, test.Vars), func(t *testing.T) {
+			got, err := templateStringFn.Call([]cty.Value{test.Template, test.Vars})
+
+			if argErr, ok := err.(function.ArgError); ok {
+				if argErr.Index < 0 || argErr.Index > 1 {
+					t.Errorf("ArgError index %d is out of range for templatestring (must be 0 or 1)", argErr.Index)
+				}
+			}
+
+			if test.Err != "" {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				if got, want := err.Error(), test.Err; got != want {
+					t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
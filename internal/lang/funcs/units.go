@@ -0,0 +1,224 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// byteUnit is a unit of data size accepted by parsebytes and formatbytes.
+type byteUnit struct {
+	Name string
+	Size *big.Int
+}
+
+// byteUnits are the units of data size, from largest to smallest, so that
+// formatbytes can choose the largest unit that represents a size exactly.
+var byteUnits = func() []byteUnit {
+	var ret []byteUnit
+	prefixes := []string{"E", "P", "T", "G", "M", "k"}
+	for i, prefix := range prefixes {
+		exp := int64(len(prefixes) - i)
+		binary := new(big.Int).Exp(big.NewInt(1024), big.NewInt(exp), nil)
+		decimal := new(big.Int).Exp(big.NewInt(1000), big.NewInt(exp), nil)
+		ret = append(ret,
+			byteUnit{strings.ToUpper(prefix) + "iB", binary},
+			byteUnit{prefix + "B", decimal},
+		)
+	}
+	return append(ret, byteUnit{"B", big.NewInt(1)})
+}()
+
+// lookupByteUnit returns the unit with the given name, ignoring case.
+func lookupByteUnit(name string) (byteUnit, bool) {
+	for _, unit := range byteUnits {
+		if strings.EqualFold(unit.Name, name) {
+			return unit, true
+		}
+	}
+	return byteUnit{}, false
+}
+
+var byteSizePattern = regexp.MustCompile(`^\s*([0-9]+(?:\.[0-9]+)?)\s*([A-Za-z]*)\s*$`)
+
+// ParseBytesFunc constructs a function that parses a data size with an
+// optional unit, such as "5GiB", and returns the number of bytes.
+var ParseBytesFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "str",
+			Type: cty.String,
+		},
+	},
+	Type:         function.StaticReturnType(cty.Number),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		str := args[0].AsString()
+		match := byteSizePattern.FindStringSubmatch(str)
+		if match == nil {
+			return cty.UnknownVal(cty.Number), function.NewArgErrorf(0, "invalid data size %q: must be a non-negative number followed by an optional unit, like \"5GiB\"", str)
+		}
+
+		unit := byteUnit{Name: "B", Size: big.NewInt(1)}
+		if match[2] != "" {
+			var ok bool
+			unit, ok = lookupByteUnit(match[2])
+			if !ok {
+				return cty.UnknownVal(cty.Number), function.NewArgErrorf(0, "invalid data size %q: unsupported unit %q", str, match[2])
+			}
+		}
+
+		n, ok := new(big.Rat).SetString(match[1])
+		if !ok {
+			// Should never happen, because the pattern only matches
+			// valid decimal numbers.
+			return cty.UnknownVal(cty.Number), function.NewArgErrorf(0, "invalid data size %q", str)
+		}
+		n.Mul(n, new(big.Rat).SetInt(unit.Size))
+		if !n.IsInt() {
+			return cty.UnknownVal(cty.Number), function.NewArgErrorf(0, "invalid data size %q: not a whole number of bytes", str)
+		}
+		return cty.NumberVal(new(big.Float).SetInt(n.Num())), nil
+	},
+})
+
+// FormatBytesFunc constructs a function that formats a number of bytes as a
+// data size with a unit, either in the given unit or in the largest unit
+// that represents the size exactly.
+var FormatBytesFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "bytes",
+			Type: cty.Number,
+		},
+	},
+	VarParam: &function.Parameter{
+		Name: "unit",
+		Type: cty.String,
+	},
+	Type:         function.StaticReturnType(cty.String),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		if len(args) > 2 {
+			return cty.UnknownVal(cty.String), fmt.Errorf("too many arguments; formatbytes takes at most one unit")
+		}
+
+		bf := args[0].AsBigFloat()
+		bytes, acc := bf.Int(nil)
+		if acc != big.Exact || bytes.Sign() < 0 {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(0, "the number of bytes must be a whole number that is not negative")
+		}
+
+		if len(args) == 2 {
+			unit, ok := lookupByteUnit(args[1].AsString())
+			if !ok {
+				return cty.UnknownVal(cty.String), function.NewArgErrorf(1, "unsupported unit %q", args[1].AsString())
+			}
+			n := new(big.Rat).SetFrac(bytes, unit.Size)
+			// The units are all powers of two or ten, so the result always
+			// has an exact decimal representation.
+			return cty.StringVal(formatExactDecimal(n) + unit.Name), nil
+		}
+
+		if bytes.Sign() == 0 {
+			return cty.StringVal("0B"), nil
+		}
+		for _, unit := range byteUnits {
+			q, r := new(big.Int).QuoRem(bytes, unit.Size, new(big.Int))
+			if r.Sign() == 0 {
+				return cty.StringVal(q.String() + unit.Name), nil
+			}
+		}
+		// Unreachable, because every whole number is divisible by one byte.
+		return cty.StringVal(bytes.String() + "B"), nil
+	},
+})
+
+// formatExactDecimal formats a rational number whose denominator has no
+// prime factors other than two and five, with no trailing zeros.
+func formatExactDecimal(n *big.Rat) string {
+	if n.IsInt() {
+		return n.Num().String()
+	}
+	// A denominator of 2^a * 5^b needs max(a, b) decimal places, which is
+	// at most its bit length.
+	s := n.FloatString(n.Denom().BitLen())
+	return strings.TrimRight(s, "0")
+}
+
+// ParseDurationFunc constructs a function that parses a duration string, such
+// as "90m", and returns the number of seconds.
+var ParseDurationFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "duration",
+			Type: cty.String,
+		},
+	},
+	Type:         function.StaticReturnType(cty.Number),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		d, err := time.ParseDuration(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.Number), function.NewArgError(0, err)
+		}
+		seconds := new(big.Rat).SetFrac64(d.Nanoseconds(), int64(time.Second))
+		return cty.NumberVal(new(big.Float).SetRat(seconds)), nil
+	},
+})
+
+// FormatDurationFunc constructs a function that formats a number of seconds
+// as a duration string, such as "1h30m0s".
+var FormatDurationFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "seconds",
+			Type: cty.Number,
+		},
+	},
+	Type:         function.StaticReturnType(cty.String),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		ns := new(big.Float).Mul(args[0].AsBigFloat(), big.NewFloat(float64(time.Second)))
+		// Any fraction of a nanosecond is truncated.
+		whole, _ := ns.Int(nil)
+		if !whole.IsInt64() {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(0, "the duration is too long")
+		}
+		return cty.StringVal(time.Duration(whole.Int64()).String()), nil
+	},
+})
+
+// ParseBytes parses a data size with an optional unit, such as "5GiB", and
+// returns the number of bytes.
+func ParseBytes(str cty.Value) (cty.Value, error) {
+	return ParseBytesFunc.Call([]cty.Value{str})
+}
+
+// FormatBytes formats a number of bytes as a data size, optionally in the
+// given unit.
+func FormatBytes(bytes cty.Value, unit ...cty.Value) (cty.Value, error) {
+	args := make([]cty.Value, 0, len(unit)+1)
+	args = append(args, bytes)
+	args = append(args, unit...)
+	return FormatBytesFunc.Call(args)
+}
+
+// ParseDuration parses a duration string, such as "90m", and returns the
+// number of seconds.
+func ParseDuration(duration cty.Value) (cty.Value, error) {
+	return ParseDurationFunc.Call([]cty.Value{duration})
+}
+
+// FormatDuration formats a number of seconds as a duration string.
+func FormatDuration(seconds cty.Value) (cty.Value, error) {
+	return FormatDurationFunc.Call([]cty.Value{seconds})
+}
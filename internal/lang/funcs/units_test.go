@@ -0,0 +1,324 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package funcs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestParseBytes(t *testing.T) {
+	tests := []struct {
+		Str  cty.Value
+		Want cty.Value
+		Err  string
+	}{
+		{
+			cty.StringVal("5GiB"),
+			cty.NumberIntVal(5368709120),
+			``,
+		},
+		{
+			cty.StringVal("5 GB"),
+			cty.NumberIntVal(5000000000),
+			``,
+		},
+		{
+			cty.StringVal("1.5kib"),
+			cty.NumberIntVal(1536),
+			``,
+		},
+		{
+			cty.StringVal("512"),
+			cty.NumberIntVal(512),
+			``,
+		},
+		{
+			cty.StringVal("100B"),
+			cty.NumberIntVal(100),
+			``,
+		},
+		{
+			cty.StringVal("2EiB"),
+			cty.MustParseNumberVal("2305843009213693952"),
+			``,
+		},
+		{
+			cty.StringVal("1.5B"),
+			cty.UnknownVal(cty.Number),
+			`invalid data size "1.5B": not a whole number of bytes`,
+		},
+		{
+			cty.StringVal("5GiBs"),
+			cty.UnknownVal(cty.Number),
+			`invalid data size "5GiBs": unsupported unit "GiBs"`,
+		},
+		{
+			cty.StringVal("-5GiB"),
+			cty.UnknownVal(cty.Number),
+			`invalid data size "-5GiB": must be a non-negative number followed by an optional unit, like "5GiB"`,
+		},
+		{
+			cty.StringVal(""),
+			cty.UnknownVal(cty.Number),
+			`invalid data size "": must be a non-negative number followed by an optional unit, like "5GiB"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("parsebytes(%#v)", test.Str), func(t *testing.T) {
+			got, err := ParseBytes(test.Str)
+
+			if test.Err != "" {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				if got := err.Error(); got != test.Err {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, test.Err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		Bytes cty.Value
+		Unit  []cty.Value
+		Want  cty.Value
+		Err   string
+	}{
+		{
+			cty.NumberIntVal(5368709120),
+			nil,
+			cty.StringVal("5GiB"),
+			``,
+		},
+		{
+			cty.NumberIntVal(5000000000),
+			nil,
+			cty.StringVal("5GB"),
+			``,
+		},
+		{
+			cty.NumberIntVal(1536),
+			nil,
+			cty.StringVal("1536B"),
+			``,
+		},
+		{
+			cty.NumberIntVal(2000),
+			nil,
+			cty.StringVal("2kB"),
+			``,
+		},
+		{
+			cty.NumberIntVal(0),
+			nil,
+			cty.StringVal("0B"),
+			``,
+		},
+		{
+			cty.NumberIntVal(1536),
+			[]cty.Value{cty.StringVal("KiB")},
+			cty.StringVal("1.5KiB"),
+			``,
+		},
+		{
+			cty.NumberIntVal(5368709120),
+			[]cty.Value{cty.StringVal("mib")},
+			cty.StringVal("5120MiB"),
+			``,
+		},
+		{
+			cty.NumberIntVal(1),
+			[]cty.Value{cty.StringVal("MiB")},
+			cty.StringVal("0.00000095367431640625MiB"),
+			``,
+		},
+		{
+			cty.NumberIntVal(123456789),
+			[]cty.Value{cty.StringVal("GB")},
+			cty.StringVal("0.123456789GB"),
+			``,
+		},
+		{
+			cty.NumberFloatVal(1.5),
+			nil,
+			cty.UnknownVal(cty.String),
+			`the number of bytes must be a whole number that is not negative`,
+		},
+		{
+			cty.NumberIntVal(-1),
+			nil,
+			cty.UnknownVal(cty.String),
+			`the number of bytes must be a whole number that is not negative`,
+		},
+		{
+			cty.NumberIntVal(1024),
+			[]cty.Value{cty.StringVal("KB/s")},
+			cty.UnknownVal(cty.String),
+			`unsupported unit "KB/s"`,
+		},
+		{
+			cty.NumberIntVal(1024),
+			[]cty.Value{cty.StringVal("KiB"), cty.StringVal("MiB")},
+			cty.UnknownVal(cty.String),
+			`too many arguments; formatbytes takes at most one unit`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("formatbytes(%#v, %#v)", test.Bytes, test.Unit), func(t *testing.T) {
+			got, err := FormatBytes(test.Bytes, test.Unit...)
+
+			if test.Err != "" {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				if got := err.Error(); got != test.Err {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, test.Err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		Duration cty.Value
+		Want     cty.Value
+		Err      string
+	}{
+		{
+			cty.StringVal("90m"),
+			cty.NumberIntVal(5400),
+			``,
+		},
+		{
+			cty.StringVal("1h30m15s"),
+			cty.NumberIntVal(5415),
+			``,
+		},
+		{
+			cty.StringVal("500ms"),
+			cty.NumberFloatVal(0.5),
+			``,
+		},
+		{
+			cty.StringVal("-2h"),
+			cty.NumberIntVal(-7200),
+			``,
+		},
+		{
+			cty.StringVal("0"),
+			cty.NumberIntVal(0),
+			``,
+		},
+		{
+			cty.StringVal("1d"),
+			cty.UnknownVal(cty.Number),
+			`time: unknown unit "d" in duration "1d"`,
+		},
+		{
+			cty.StringVal("90"),
+			cty.UnknownVal(cty.Number),
+			`time: missing unit in duration "90"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("parseduration(%#v)", test.Duration), func(t *testing.T) {
+			got, err := ParseDuration(test.Duration)
+
+			if test.Err != "" {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				if got := err.Error(); got != test.Err {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, test.Err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.Equals(test.Want).True() {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		Seconds cty.Value
+		Want    cty.Value
+		Err     string
+	}{
+		{
+			cty.NumberIntVal(5400),
+			cty.StringVal("1h30m0s"),
+			``,
+		},
+		{
+			cty.NumberFloatVal(0.5),
+			cty.StringVal("500ms"),
+			``,
+		},
+		{
+			cty.NumberIntVal(0),
+			cty.StringVal("0s"),
+			``,
+		},
+		{
+			cty.NumberIntVal(-90),
+			cty.StringVal("-1m30s"),
+			``,
+		},
+		{
+			cty.NumberFloatVal(1e12),
+			cty.UnknownVal(cty.String),
+			`the duration is too long`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("formatduration(%#v)", test.Seconds), func(t *testing.T) {
+			got, err := FormatDuration(test.Seconds)
+
+			if test.Err != "" {
+				if err == nil {
+					t.Fatal("succeeded; want error")
+				}
+				if got := err.Error(); got != test.Err {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, test.Err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !got.RawEquals(test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
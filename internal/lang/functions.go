@@ -5,6 +5,7 @@ package lang
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/hashicorp/hcl/v2/ext/tryfunc"
 	ctyyaml "github.com/zclconf/go-cty-yaml"
@@ -35,121 +36,137 @@ func (s *Scope) Functions() map[string]function.Function {
 		// that would be useful to all applications using cty functions.
 
 		s.funcs = map[string]function.Function{
-			"abs":              stdlib.AbsoluteFunc,
-			"abspath":          funcs.AbsPathFunc,
-			"alltrue":          funcs.AllTrueFunc,
-			"anytrue":          funcs.AnyTrueFunc,
-			"basename":         funcs.BasenameFunc,
-			"base64decode":     funcs.Base64DecodeFunc,
-			"base64encode":     funcs.Base64EncodeFunc,
-			"base64gzip":       funcs.Base64GzipFunc,
-			"base64sha256":     funcs.Base64Sha256Func,
-			"base64sha512":     funcs.Base64Sha512Func,
-			"bcrypt":           funcs.BcryptFunc,
-			"can":              tryfunc.CanFunc,
-			"ceil":             stdlib.CeilFunc,
-			"chomp":            stdlib.ChompFunc,
-			"cidrhost":         funcs.CidrHostFunc,
-			"cidrnetmask":      funcs.CidrNetmaskFunc,
-			"cidrsubnet":       funcs.CidrSubnetFunc,
-			"cidrsubnets":      funcs.CidrSubnetsFunc,
-			"coalesce":         funcs.CoalesceFunc,
-			"coalescelist":     stdlib.CoalesceListFunc,
-			"compact":          stdlib.CompactFunc,
-			"concat":           stdlib.ConcatFunc,
-			"contains":         stdlib.ContainsFunc,
-			"csvdecode":        stdlib.CSVDecodeFunc,
-			"dirname":          funcs.DirnameFunc,
-			"distinct":         stdlib.DistinctFunc,
-			"element":          stdlib.ElementFunc,
-			"endswith":         funcs.EndsWithFunc,
-			"chunklist":        stdlib.ChunklistFunc,
-			"file":             funcs.MakeFileFunc(s.BaseDir, false),
-			"fileexists":       funcs.MakeFileExistsFunc(s.BaseDir),
-			"fileset":          funcs.MakeFileSetFunc(s.BaseDir),
-			"filebase64":       funcs.MakeFileFunc(s.BaseDir, true),
-			"filebase64sha256": funcs.MakeFileBase64Sha256Func(s.BaseDir),
-			"filebase64sha512": funcs.MakeFileBase64Sha512Func(s.BaseDir),
-			"filemd5":          funcs.MakeFileMd5Func(s.BaseDir),
-			"filesha1":         funcs.MakeFileSha1Func(s.BaseDir),
-			"filesha256":       funcs.MakeFileSha256Func(s.BaseDir),
-			"filesha512":       funcs.MakeFileSha512Func(s.BaseDir),
-			"flatten":          stdlib.FlattenFunc,
-			"floor":            stdlib.FloorFunc,
-			"format":           stdlib.FormatFunc,
-			"formatdate":       stdlib.FormatDateFunc,
-			"formatlist":       stdlib.FormatListFunc,
-			"indent":           stdlib.IndentFunc,
-			"index":            funcs.IndexFunc, // stdlib.IndexFunc is not compatible
-			"join":             stdlib.JoinFunc,
-			"jsondecode":       stdlib.JSONDecodeFunc,
-			"jsonencode":       stdlib.JSONEncodeFunc,
-			"keys":             stdlib.KeysFunc,
-			"length":           funcs.LengthFunc,
-			"list":             funcs.ListFunc,
-			"log":              stdlib.LogFunc,
-			"lookup":           funcs.LookupFunc,
-			"lower":            stdlib.LowerFunc,
-			"map":              funcs.MapFunc,
-			"matchkeys":        funcs.MatchkeysFunc,
-			"max":              stdlib.MaxFunc,
-			"md5":              funcs.Md5Func,
-			"merge":            stdlib.MergeFunc,
-			"min":              stdlib.MinFunc,
-			"one":              funcs.OneFunc,
-			"parseint":         stdlib.ParseIntFunc,
-			"pathexpand":       funcs.PathExpandFunc,
-			"pow":              stdlib.PowFunc,
-			"range":            stdlib.RangeFunc,
-			"regex":            stdlib.RegexFunc,
-			"regexall":         stdlib.RegexAllFunc,
-			"replace":          funcs.ReplaceFunc,
-			"reverse":          stdlib.ReverseListFunc,
-			"rsadecrypt":       funcs.RsaDecryptFunc,
-			"sensitive":        funcs.SensitiveFunc,
-			"nonsensitive":     funcs.NonsensitiveFunc,
-			"setintersection":  stdlib.SetIntersectionFunc,
-			"setproduct":       stdlib.SetProductFunc,
-			"setsubtract":      stdlib.SetSubtractFunc,
-			"setunion":         stdlib.SetUnionFunc,
-			"sha1":             funcs.Sha1Func,
-			"sha256":           funcs.Sha256Func,
-			"sha512":           funcs.Sha512Func,
-			"signum":           stdlib.SignumFunc,
-			"slice":            stdlib.SliceFunc,
-			"sort":             stdlib.SortFunc,
-			"split":            stdlib.SplitFunc,
-			"startswith":       funcs.StartsWithFunc,
-			"strcontains":      funcs.StrContainsFunc,
-			"strrev":           stdlib.ReverseFunc,
-			"substr":           stdlib.SubstrFunc,
-			"sum":              funcs.SumFunc,
-			"textdecodebase64": funcs.TextDecodeBase64Func,
-			"textencodebase64": funcs.TextEncodeBase64Func,
-			"timestamp":        funcs.TimestampFunc,
-			"timeadd":          stdlib.TimeAddFunc,
-			"timecmp":          funcs.TimeCmpFunc,
-			"title":            stdlib.TitleFunc,
-			"tostring":         funcs.MakeToFunc(cty.String),
-			"tonumber":         funcs.MakeToFunc(cty.Number),
-			"tobool":           funcs.MakeToFunc(cty.Bool),
-			"toset":            funcs.MakeToFunc(cty.Set(cty.DynamicPseudoType)),
-			"tolist":           funcs.MakeToFunc(cty.List(cty.DynamicPseudoType)),
-			"tomap":            funcs.MakeToFunc(cty.Map(cty.DynamicPseudoType)),
-			"transpose":        funcs.TransposeFunc,
-			"trim":             stdlib.TrimFunc,
-			"trimprefix":       stdlib.TrimPrefixFunc,
-			"trimspace":        stdlib.TrimSpaceFunc,
-			"trimsuffix":       stdlib.TrimSuffixFunc,
-			"try":              tryfunc.TryFunc,
-			"upper":            stdlib.UpperFunc,
-			"urlencode":        funcs.URLEncodeFunc,
-			"uuid":             funcs.UUIDFunc,
-			"uuidv5":           funcs.UUIDV5Func,
-			"values":           stdlib.ValuesFunc,
-			"yamldecode":       ctyyaml.YAMLDecodeFunc,
-			"yamlencode":       ctyyaml.YAMLEncodeFunc,
-			"zipmap":           stdlib.ZipmapFunc,
+			"abs":               stdlib.AbsoluteFunc,
+			"abspath":           funcs.AbsPathFunc,
+			"alltrue":           funcs.AllTrueFunc,
+			"anytrue":           funcs.AnyTrueFunc,
+			"basename":          funcs.BasenameFunc,
+			"base64decode":      funcs.Base64DecodeFunc,
+			"base64encode":      funcs.Base64EncodeFunc,
+			"base64gzip":        funcs.Base64GzipFunc,
+			"base64sha256":      funcs.Base64Sha256Func,
+			"base64sha512":      funcs.Base64Sha512Func,
+			"bcrypt":            funcs.BcryptFunc,
+			"can":               tryfunc.CanFunc,
+			"ceil":              stdlib.CeilFunc,
+			"chomp":             stdlib.ChompFunc,
+			"cidrcontains":      funcs.CidrContainsFunc,
+			"cidrhost":          funcs.CidrHostFunc,
+			"cidrnetmask":       funcs.CidrNetmaskFunc,
+			"cidrsubnet":        funcs.CidrSubnetFunc,
+			"cidrsubnets":       funcs.CidrSubnetsFunc,
+			"coalesce":          funcs.CoalesceFunc,
+			"coalescelist":      stdlib.CoalesceListFunc,
+			"compact":           stdlib.CompactFunc,
+			"concat":            stdlib.ConcatFunc,
+			"contains":          stdlib.ContainsFunc,
+			"csvdecode":         stdlib.CSVDecodeFunc,
+			"deepmerge":         funcs.DeepMergeFunc,
+			"defaults":          funcs.DefaultsFunc,
+			"dirname":           funcs.DirnameFunc,
+			"distinct":          stdlib.DistinctFunc,
+			"element":           stdlib.ElementFunc,
+			"endswith":          funcs.EndsWithFunc,
+			"chunk":             funcs.ChunkFunc,
+			"chunklist":         stdlib.ChunklistFunc,
+			"file":              funcs.MakeFileFunc(s.BaseDir, false),
+			"fileexists":        funcs.MakeFileExistsFunc(s.BaseDir),
+			"fileset":           funcs.MakeFileSetFunc(s.BaseDir),
+			"filebase64":        funcs.MakeFileFunc(s.BaseDir, true),
+			"filebase64sha256":  funcs.MakeFileBase64Sha256Func(s.BaseDir),
+			"filebase64sha512":  funcs.MakeFileBase64Sha512Func(s.BaseDir),
+			"filemd5":           funcs.MakeFileMd5Func(s.BaseDir),
+			"filesha1":          funcs.MakeFileSha1Func(s.BaseDir),
+			"filesha256":        funcs.MakeFileSha256Func(s.BaseDir),
+			"filesha512":        funcs.MakeFileSha512Func(s.BaseDir),
+			"flatten":           stdlib.FlattenFunc,
+			"floor":             stdlib.FloorFunc,
+			"format":            stdlib.FormatFunc,
+			"formatbytes":       funcs.FormatBytesFunc,
+			"formatdate":        stdlib.FormatDateFunc,
+			"formatduration":    funcs.FormatDurationFunc,
+			"formatlist":        stdlib.FormatListFunc,
+			"getpath":           funcs.GetPathFunc,
+			"groupby":           funcs.GroupByFunc,
+			"indent":            stdlib.IndentFunc,
+			"index":             funcs.IndexFunc, // stdlib.IndexFunc is not compatible
+			"join":              stdlib.JoinFunc,
+			"jsondecode":        stdlib.JSONDecodeFunc,
+			"jsonencode":        stdlib.JSONEncodeFunc,
+			"jsonpatch":         funcs.JSONPatchFunc,
+			"keys":              stdlib.KeysFunc,
+			"length":            funcs.LengthFunc,
+			"list":              funcs.ListFunc,
+			"log":               stdlib.LogFunc,
+			"lookup":            funcs.LookupFunc,
+			"lower":             stdlib.LowerFunc,
+			"map":               funcs.MapFunc,
+			"matchkeys":         funcs.MatchkeysFunc,
+			"max":               stdlib.MaxFunc,
+			"md5":               funcs.Md5Func,
+			"merge":             stdlib.MergeFunc,
+			"min":               stdlib.MinFunc,
+			"one":               funcs.OneFunc,
+			"parsebytes":        funcs.ParseBytesFunc,
+			"parseduration":     funcs.ParseDurationFunc,
+			"parseint":          stdlib.ParseIntFunc,
+			"pathexpand":        funcs.PathExpandFunc,
+			"pow":               stdlib.PowFunc,
+			"range":             stdlib.RangeFunc,
+			"regex":             stdlib.RegexFunc,
+			"regexall":          stdlib.RegexAllFunc,
+			"replace":           funcs.ReplaceFunc,
+			"reverse":           stdlib.ReverseListFunc,
+			"rsadecrypt":        funcs.RsaDecryptFunc,
+			"semvercompare":     funcs.SemverCompareFunc,
+			"semverconstraint":  funcs.SemverConstraintFunc,
+			"sensitive":         funcs.SensitiveFunc,
+			"nonsensitive":      funcs.NonsensitiveFunc,
+			"nonsensitive_keys": funcs.NonsensitiveKeysFunc,
+			"setintersection":   stdlib.SetIntersectionFunc,
+			"setproduct":        stdlib.SetProductFunc,
+			"setsubtract":       stdlib.SetSubtractFunc,
+			"setunion":          stdlib.SetUnionFunc,
+			"sha1":              funcs.Sha1Func,
+			"sha256":            funcs.Sha256Func,
+			"sha512":            funcs.Sha512Func,
+			"signum":            stdlib.SignumFunc,
+			"slice":             stdlib.SliceFunc,
+			"sort":              stdlib.SortFunc,
+			"split":             stdlib.SplitFunc,
+			"startswith":        funcs.StartsWithFunc,
+			"strcontains":       funcs.StrContainsFunc,
+			"strrev":            stdlib.ReverseFunc,
+			"substr":            stdlib.SubstrFunc,
+			"sum":               funcs.SumFunc,
+			"textdecodebase64":  funcs.TextDecodeBase64Func,
+			"textencodebase64":  funcs.TextEncodeBase64Func,
+			"timestamp":         funcs.TimestampFunc,
+			"timeadd":           stdlib.TimeAddFunc,
+			"timecmp":           funcs.TimeCmpFunc,
+			"title":             stdlib.TitleFunc,
+			"tostring":          funcs.MakeToFunc(cty.String),
+			"tonumber":          funcs.MakeToFunc(cty.Number),
+			"tobool":            funcs.MakeToFunc(cty.Bool),
+			"toset":             funcs.MakeToFunc(cty.Set(cty.DynamicPseudoType)),
+			"tolist":            funcs.MakeToFunc(cty.List(cty.DynamicPseudoType)),
+			"tomap":             funcs.MakeToFunc(cty.Map(cty.DynamicPseudoType)),
+			"transpose":         funcs.TransposeFunc,
+			"trim":              stdlib.TrimFunc,
+			"trimprefix":        stdlib.TrimPrefixFunc,
+			"trimspace":         stdlib.TrimSpaceFunc,
+			"trimsuffix":        stdlib.TrimSuffixFunc,
+			"try":               tryfunc.TryFunc,
+			"upper":             stdlib.UpperFunc,
+			"urlencode":         funcs.URLEncodeFunc,
+			"uuid":              funcs.UUIDFunc,
+			"uuidv5":            funcs.UUIDV5Func,
+			"values":            stdlib.ValuesFunc,
+			"window":            funcs.WindowFunc,
+			"yamldecode":        ctyyaml.YAMLDecodeFunc,
+			"yamlencode":        ctyyaml.YAMLEncodeFunc,
+			"zip":               funcs.ZipFunc,
+			"zipmap":            stdlib.ZipmapFunc,
 		}
 
 		s.funcs["templatefile"] = funcs.MakeTemplateFileFunc(s.BaseDir, func() map[string]function.Function {
@@ -157,6 +174,11 @@ func (s *Scope) Functions() map[string]function.Function {
 			// by copying this map and overwriting the "templatefile" entry.
 			return s.funcs
 		})
+		s.funcs["templatestring"] = funcs.MakeTemplateStringFunc(func() map[string]function.Function {
+			// As with templatefile, the templatestring function overwrites
+			// its own entry and the "templatefile" entry in a copy of this map.
+			return s.funcs
+		})
 
 		if s.ConsoleMode {
 			// The type function is only available in OpenTofu console.
@@ -184,12 +206,42 @@ func (s *Scope) Functions() map[string]function.Function {
 		for name, f := range s.funcs {
 			s.funcs[name] = funcs.WithDescription(name, f)
 		}
+
+		// Provider functions are always pure, so they are evaluated even
+		// when PureOnly is set.
+		for name, f := range s.providerFunctions {
+			s.funcs[name] = f
+		}
+
+		// User-defined functions come last, so that they can call all of
+		// the built-in and provider functions.
+		addUserFunctions(s.funcs, s.userFunctions, 0)
 	}
 	s.funcsLock.Unlock()
 
 	return s.funcs
 }
 
+// IsBuiltinFunction returns true if the given name is the name of one of the
+// built-in functions, including those only available in some contexts.
+func IsBuiltinFunction(name string) bool {
+	builtinFunctionNamesOnce.Do(func() {
+		builtinFunctionNames = make(map[string]struct{})
+		for _, scope := range []*Scope{{}, {ConsoleMode: true}} {
+			for name := range scope.Functions() {
+				builtinFunctionNames[name] = struct{}{}
+			}
+		}
+	})
+	_, exists := builtinFunctionNames[name]
+	return exists
+}
+
+var (
+	builtinFunctionNames     map[string]struct{}
+	builtinFunctionNamesOnce sync.Once
+)
+
 // experimentalFunction checks whether the given experiment is enabled for
 // the recieving scope. If so, it will return the given function verbatim.
 // If not, it will return a placeholder function that just returns an
@@ -164,6 +164,21 @@ func TestFunctions(t *testing.T) {
 			},
 		},
 
+		"chunk": {
+			{
+				`chunk(["a", 1, true], 2)`,
+				cty.TupleVal([]cty.Value{
+					cty.TupleVal([]cty.Value{
+						cty.StringVal("a"),
+						cty.NumberIntVal(1),
+					}),
+					cty.TupleVal([]cty.Value{
+						cty.True,
+					}),
+				}),
+			},
+		},
+
 		"chunklist": {
 			{
 				`chunklist(["a", "b", "c"], 1)`,
@@ -181,6 +196,13 @@ func TestFunctions(t *testing.T) {
 			},
 		},
 
+		"cidrcontains": {
+			{
+				`cidrcontains("192.168.0.0/16", "192.168.2.1")`,
+				cty.True,
+			},
+		},
+
 		"cidrhost": {
 			{
 				`cidrhost("192.168.1.0/24", 5)`,
@@ -295,6 +317,30 @@ func TestFunctions(t *testing.T) {
 			},
 		},
 
+		"deepmerge": {
+			{
+				`deepmerge([{a = {b = 1, c = [1]}}, {a = {c = [2]}}], {strategy = "append"})`,
+				cty.ObjectVal(map[string]cty.Value{
+					"a": cty.ObjectVal(map[string]cty.Value{
+						"b": cty.NumberIntVal(1),
+						"c": cty.TupleVal([]cty.Value{cty.NumberIntVal(1), cty.NumberIntVal(2)}),
+					}),
+				}),
+			},
+		},
+
+		"defaults": {
+			{
+				`defaults({a = {b = null}}, {a = {b = 1, c = 2}})`,
+				cty.ObjectVal(map[string]cty.Value{
+					"a": cty.ObjectVal(map[string]cty.Value{
+						"b": cty.NumberIntVal(1),
+						"c": cty.NumberIntVal(2),
+					}),
+				}),
+			},
+		},
+
 		"dirname": {
 			{
 				`dirname("testdata/hello.txt")`,
@@ -479,6 +525,24 @@ func TestFunctions(t *testing.T) {
 			},
 		},
 
+		"formatbytes": {
+			{
+				`formatbytes(5368709120)`,
+				cty.StringVal("5GiB"),
+			},
+			{
+				`formatbytes(1536, "KiB")`,
+				cty.StringVal("1.5KiB"),
+			},
+		},
+
+		"formatduration": {
+			{
+				`formatduration(5400)`,
+				cty.StringVal("1h30m0s"),
+			},
+		},
+
 		"formatlist": {
 			{
 				`formatlist("Hello, %s!", ["Valentina", "Ander", "Olivia", "Sam"])`,
@@ -498,6 +562,37 @@ func TestFunctions(t *testing.T) {
 			},
 		},
 
+		"getpath": {
+			{
+				`getpath({ network = { subnet_ids = ["a", "b"] } }, ["network", "subnet_ids", 1])`,
+				cty.StringVal("b"),
+			},
+			{
+				`getpath({ network = null }, ["network", "subnet_ids"])`,
+				cty.NullVal(cty.DynamicPseudoType),
+			},
+		},
+
+		"groupby": {
+			{
+				`groupby([{name = "web1", role = "web"}, {name = "db1", role = "db"}], "role")`,
+				cty.ObjectVal(map[string]cty.Value{
+					"web": cty.TupleVal([]cty.Value{
+						cty.ObjectVal(map[string]cty.Value{
+							"name": cty.StringVal("web1"),
+							"role": cty.StringVal("web"),
+						}),
+					}),
+					"db": cty.TupleVal([]cty.Value{
+						cty.ObjectVal(map[string]cty.Value{
+							"name": cty.StringVal("db1"),
+							"role": cty.StringVal("db"),
+						}),
+					}),
+				}),
+			},
+		},
+
 		"indent": {
 			{
 				fmt.Sprintf("indent(4, %#v)", Poem),
@@ -541,6 +636,13 @@ func TestFunctions(t *testing.T) {
 			},
 		},
 
+		"jsonpatch": {
+			{
+				`jsonpatch("{\"hello\":\"world\"}", "[{\"op\":\"add\",\"path\":\"/foo\",\"value\":1}]")`,
+				cty.StringVal(`{"foo":1,"hello":"world"}`),
+			},
+		},
+
 		"keys": {
 			{
 				`keys({"hello"=1, "goodbye"=42})`,
@@ -648,6 +750,15 @@ func TestFunctions(t *testing.T) {
 			},
 		},
 
+		"nonsensitive_keys": {
+			{
+				`nonsensitive_keys(sensitive({a = 1}))`,
+				cty.ObjectVal(map[string]cty.Value{
+					"a": cty.NumberIntVal(1).Mark(marks.Sensitive),
+				}),
+			},
+		},
+
 		"one": {
 			{
 				`one([])`,
@@ -659,6 +770,20 @@ func TestFunctions(t *testing.T) {
 			},
 		},
 
+		"parsebytes": {
+			{
+				`parsebytes("5GiB")`,
+				cty.NumberIntVal(5368709120),
+			},
+		},
+
+		"parseduration": {
+			{
+				`parseduration("90m")`,
+				cty.NumberIntVal(5400),
+			},
+		},
+
 		"parseint": {
 			{
 				`parseint("100", 10)`,
@@ -753,6 +878,20 @@ func TestFunctions(t *testing.T) {
 			},
 		},
 
+		"semvercompare": {
+			{
+				`semvercompare("1.2.0", "1.10.0")`,
+				cty.NumberIntVal(-1),
+			},
+		},
+
+		"semverconstraint": {
+			{
+				`semverconstraint("1.2.3", ">= 1.0, < 2.0")`,
+				cty.True,
+			},
+		},
+
 		"sensitive": {
 			{
 				`sensitive(1)`,
@@ -962,6 +1101,13 @@ func TestFunctions(t *testing.T) {
 			},
 		},
 
+		"templatestring": {
+			{
+				`templatestring("Hello, $${name}!", {name = "Jodie"})`,
+				cty.StringVal("Hello, Jodie!"),
+			},
+		},
+
 		"timeadd": {
 			{
 				`timeadd("2017-11-22T00:00:00Z", "1s")`,
@@ -1132,6 +1278,22 @@ func TestFunctions(t *testing.T) {
 			},
 		},
 
+		"window": {
+			{
+				`window(tolist([1, 2, 3]), 2)`,
+				cty.ListVal([]cty.Value{
+					cty.ListVal([]cty.Value{
+						cty.NumberIntVal(1),
+						cty.NumberIntVal(2),
+					}),
+					cty.ListVal([]cty.Value{
+						cty.NumberIntVal(2),
+						cty.NumberIntVal(3),
+					}),
+				}),
+			},
+		},
+
 		"values": {
 			{
 				`values({"hello"="world", "what's"="up"})`,
@@ -1175,6 +1337,22 @@ func TestFunctions(t *testing.T) {
 			},
 		},
 
+		"zip": {
+			{
+				`zip(["a", "b"], [1, 2])`,
+				cty.TupleVal([]cty.Value{
+					cty.TupleVal([]cty.Value{
+						cty.StringVal("a"),
+						cty.NumberIntVal(1),
+					}),
+					cty.TupleVal([]cty.Value{
+						cty.StringVal("b"),
+						cty.NumberIntVal(2),
+					}),
+				}),
+			},
+		},
+
 		"zipmap": {
 			{
 				`zipmap(["hello", "bar"], ["world", "baz"])`,
@@ -1187,7 +1365,6 @@ func TestFunctions(t *testing.T) {
 	}
 
 	experimentalFuncs := map[string]experiments.Experiment{}
-	experimentalFuncs["defaults"] = experiments.ModuleVariableOptionalAttrs
 
 	t.Run("all functions are tested", func(t *testing.T) {
 		data := &dataForTests{} // no variables available; we only need literals here
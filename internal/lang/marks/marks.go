@@ -43,3 +43,48 @@ const Sensitive = valueMark("Sensitive")
 // another value's type. This is part of the implementation of the console-only
 // `type` function.
 const TypeType = valueMark("TypeType")
+
+// Ephemeral indicates that this value is only usable during the current
+// OpenTofu run, and so must never be persisted in a state or plan file.
+const Ephemeral = valueMark("Ephemeral")
+
+// RemoveEphemeral takes an unmarked value and the marks that were removed
+// from it, and returns the value with everything that had the Ephemeral mark
+// replaced by null, along with the remaining marks.
+//
+// This is for use just before a value is persisted, so that ephemeral values
+// are never written out, while the other marks, such as Sensitive, are kept.
+func RemoveEphemeral(val cty.Value, pvm []cty.PathValueMarks) (cty.Value, []cty.PathValueMarks) {
+	var ephemeral []cty.Path
+	var ret []cty.PathValueMarks
+	for _, pm := range pvm {
+		if _, ok := pm.Marks[Ephemeral]; !ok {
+			ret = append(ret, pm)
+			continue
+		}
+		ephemeral = append(ephemeral, pm.Path)
+
+		others := make(cty.ValueMarks, len(pm.Marks)-1)
+		for mark := range pm.Marks {
+			if mark != Ephemeral {
+				others[mark] = struct{}{}
+			}
+		}
+		if len(others) != 0 {
+			ret = append(ret, cty.PathValueMarks{Path: pm.Path, Marks: others})
+		}
+	}
+	if len(ephemeral) == 0 {
+		return val, pvm
+	}
+
+	val, _ = cty.Transform(val, func(path cty.Path, v cty.Value) (cty.Value, error) {
+		for _, p := range ephemeral {
+			if p.Equals(path) {
+				return cty.NullVal(v.Type()), nil
+			}
+		}
+		return v, nil
+	})
+	return val, ret
+}
@@ -57,6 +57,22 @@ type Scope struct {
 	funcs     map[string]function.Function
 	funcsLock sync.Mutex
 
+	// userFunctions are the functions defined in the configuration of the
+	// module that this scope will be used for. Callers can populate it by
+	// calling the SetUserFunctions method.
+	userFunctions map[string]*UserFunction
+
+	// providerFunctions are the functions offered by the providers that
+	// the module this scope will be used for requires. Callers can populate
+	// it by calling the SetProviderFunctions method.
+	providerFunctions map[string]function.Function
+
+	// unavailableProviderFunctions maps the local names of the providers
+	// that the module requires but that offer no functions to explanations
+	// of why, for use in the errors for calls to their functions. Callers
+	// can populate it by calling the SetUnavailableProviderFunctions method.
+	unavailableProviderFunctions map[string]string
+
 	// activeExperiments is an optional set of experiments that should be
 	// considered as active in the module that this scope will be used for.
 	// Callers can populate it by calling the SetActiveExperiments method.
@@ -77,3 +93,18 @@ type Scope struct {
 func (s *Scope) SetActiveExperiments(active experiments.Set) {
 	s.activeExperiments = active
 }
+
+// SetProviderFunctions makes the given provider-defined functions available
+// to expressions evaluated in the receiving scope, keyed by their full names
+// such as "provider::aws::arn_parse". It must be called before the first call
+// to Functions.
+func (s *Scope) SetProviderFunctions(fns map[string]function.Function) {
+	s.providerFunctions = fns
+}
+
+// SetUnavailableProviderFunctions records why the providers with the given
+// local names offer no functions, so that calls to their functions produce
+// errors that explain why instead of just reporting an unknown function.
+func (s *Scope) SetUnavailableProviderFunctions(reasons map[string]string) {
+	s.unavailableProviderFunctions = reasons
+}
@@ -0,0 +1,126 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package lang
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// maxUserFunctionDepth is the maximum number of nested calls to user-defined
+// functions. Functions can't call themselves recursively, because both
+// results of a conditional expression are always evaluated, so any
+// recursion would never end; this limit catches that.
+const maxUserFunctionDepth = 32
+
+// errUserFunctionRecursion is returned by calls to user-defined functions
+// that are nested more than maxUserFunctionDepth deep.
+var errUserFunctionRecursion = errors.New("functions are nested too deeply; a function cannot call itself, directly or through other functions")
+
+// UserFunction is a function defined in the configuration, whose result is
+// given by an expression that refers to its parameters by name.
+type UserFunction struct {
+	Description string
+	Params      []UserFunctionParam
+	Result      hcl.Expression
+}
+
+// UserFunctionParam is a parameter of a UserFunction.
+type UserFunctionParam struct {
+	Name string
+
+	// Type is the type constraint for the argument, or cty.DynamicPseudoType
+	// if the parameter accepts any type.
+	Type cty.Type
+}
+
+// SetUserFunctions makes the given user-defined functions available to
+// expressions evaluated in the receiving scope, in addition to the built-in
+// functions. It must be called before the first call to Functions.
+func (s *Scope) SetUserFunctions(fns map[string]*UserFunction) {
+	s.userFunctions = fns
+}
+
+// addUserFunctions adds the given user-defined functions to the given table
+// of functions. The result expressions of the user-defined functions are
+// evaluated with the same table, so they can call the built-in functions and
+// each other.
+func addUserFunctions(funcs map[string]function.Function, fns map[string]*UserFunction, depth int) {
+	for name, fn := range fns {
+		funcs[name] = makeUserFunction(name, fn, funcs, fns, depth)
+	}
+}
+
+func makeUserFunction(name string, fn *UserFunction, funcs map[string]function.Function, fns map[string]*UserFunction, depth int) function.Function {
+	params := make([]function.Parameter, len(fn.Params))
+	for i, p := range fn.Params {
+		params[i] = function.Parameter{
+			Name:             p.Name,
+			Type:             p.Type,
+			AllowNull:        true,
+			AllowUnknown:     true,
+			AllowDynamicType: true,
+		}
+	}
+
+	return function.New(&function.Spec{
+		Description: fn.Description,
+		Params:      params,
+		Type:        function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			if depth >= maxUserFunctionDepth {
+				return cty.DynamicVal, errUserFunctionRecursion
+			}
+
+			vars := make(map[string]cty.Value, len(args))
+			for i, p := range fn.Params {
+				vars[p.Name] = args[i]
+			}
+
+			ctx := &hcl.EvalContext{
+				Variables: vars,
+				Functions: nestedUserFunctions(funcs, fns, depth+1),
+			}
+			val, diags := fn.Result.Value(ctx)
+			if diags.HasErrors() {
+				if nestedUserFunctionRecursion(diags) {
+					return cty.DynamicVal, errUserFunctionRecursion
+				}
+				return cty.DynamicVal, fmt.Errorf("the result of function %q is invalid: %w", name, diags)
+			}
+			return val, nil
+		},
+	})
+}
+
+// nestedUserFunctions returns the functions to use when evaluating the
+// result of a user-defined function, which are the same as the given ones
+// except that calls to user-defined functions are one level deeper.
+func nestedUserFunctions(funcs map[string]function.Function, fns map[string]*UserFunction, depth int) map[string]function.Function {
+	nested := make(map[string]function.Function, len(funcs))
+	for name, f := range funcs {
+		nested[name] = f
+	}
+	addUserFunctions(nested, fns, depth)
+	return nested
+}
+
+// nestedUserFunctionRecursion returns true if the given diagnostics include
+// an error from a nested call that exceeded maxUserFunctionDepth, so that we
+// can report that error once rather than once for each level.
+func nestedUserFunctionRecursion(diags hcl.Diagnostics) bool {
+	for _, diag := range diags {
+		if extra, ok := hcl.DiagnosticExtra[hclsyntax.FunctionCallDiagExtra](diag); ok {
+			if errors.Is(extra.FunctionCallError(), errUserFunctionRecursion) {
+				return true
+			}
+		}
+	}
+	return false
+}
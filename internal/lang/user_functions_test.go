@@ -0,0 +1,131 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package lang
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/lang/marks"
+)
+
+func TestScopeUserFunctions(t *testing.T) {
+	parse := func(src string) hcl.Expression {
+		expr, diags := hclsyntax.ParseExpression([]byte(src), "", hcl.InitialPos)
+		if diags.HasErrors() {
+			t.Fatal(diags.Error())
+		}
+		return expr
+	}
+
+	fns := map[string]*UserFunction{
+		"name_prefix": {
+			Params: []UserFunctionParam{
+				{Name: "tenant", Type: cty.String},
+				{Name: "environment", Type: cty.DynamicPseudoType},
+			},
+			Result: parse(`"${lower(tenant)}-${environment}"`),
+		},
+		"resource_name": {
+			Params: []UserFunctionParam{
+				{Name: "tenant", Type: cty.String},
+				{Name: "name", Type: cty.String},
+			},
+			Result: parse(`"${name_prefix(tenant, "prod")}-${name}"`),
+		},
+		"loop": {
+			Params: []UserFunctionParam{
+				{Name: "n", Type: cty.Number},
+			},
+			Result: parse(`loop(n + 1)`),
+		},
+	}
+
+	tests := map[string]struct {
+		expr    string
+		want    cty.Value
+		wantErr string
+	}{
+		"simple": {
+			expr: `name_prefix("ACME", "dev")`,
+			want: cty.StringVal("acme-dev"),
+		},
+		"argument conversion": {
+			expr: `name_prefix("ACME", 1)`,
+			want: cty.StringVal("acme-1"),
+		},
+		"nested call": {
+			expr: `resource_name("ACME", "web")`,
+			want: cty.StringVal("acme-prod-web"),
+		},
+		"unknown argument": {
+			expr: `name_prefix("ACME", unknown)`,
+			want: cty.UnknownVal(cty.String).Refine().NotNull().StringPrefixFull("acme-").NewValue(),
+		},
+		"sensitive argument": {
+			expr: `name_prefix("ACME", secret)`,
+			want: cty.StringVal("acme-hunter2").Mark(marks.Sensitive),
+		},
+		"wrong argument type": {
+			expr:    `name_prefix([], "dev")`,
+			wantErr: `Invalid value for "tenant" parameter`,
+		},
+		"recursion": {
+			expr:    `loop(0)`,
+			wantErr: "a function cannot call itself",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			scope := &Scope{}
+			scope.SetUserFunctions(fns)
+
+			ctx := &hcl.EvalContext{
+				Functions: scope.Functions(),
+				Variables: map[string]cty.Value{
+					"unknown": cty.UnknownVal(cty.String),
+					"secret":  cty.StringVal("hunter2").Mark(marks.Sensitive),
+				},
+			}
+			got, diags := parse(test.expr).Value(ctx)
+			if test.wantErr != "" {
+				if !diags.HasErrors() {
+					t.Fatalf("unexpected success; want error containing %q", test.wantErr)
+				}
+				if got := diags.Error(); !strings.Contains(got, test.wantErr) {
+					t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, test.wantErr)
+				}
+				if strings.Count(diags.Error(), "cannot call itself") > 1 {
+					t.Fatalf("error repeated for each nested call: %s", diags.Error())
+				}
+				return
+			}
+			if diags.HasErrors() {
+				t.Fatal(diags.Error())
+			}
+			if !got.RawEquals(test.want) {
+				t.Fatalf("wrong result\ngot:  %#v\nwant: %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestIsBuiltinFunction(t *testing.T) {
+	for name, want := range map[string]bool{
+		"upper":         true,
+		"templatefile":  true,
+		"type":          true,
+		"plantimestamp": true,
+		"name_prefix":   false,
+	} {
+		if got := IsBuiltinFunction(name); got != want {
+			t.Errorf("IsBuiltinFunction(%q) = %t; want %t", name, got, want)
+		}
+	}
+}
@@ -4,6 +4,8 @@
 package tofu
 
 import (
+	"fmt"
+
 	"encoding/json"
 	"sync"
 
@@ -360,6 +362,18 @@ func (p *MockProvider) ReadDataSource(r providers.ReadDataSourceRequest) provide
 	return p.ReadDataSourceResponse
 }
 
+func (p *MockProvider) ListResources(r providers.ListResourcesRequest) providers.ListResourcesResponse {
+	var resp providers.ListResourcesResponse
+	resp.Diagnostics = resp.Diagnostics.Append(fmt.Errorf("unsupported"))
+	return resp
+}
+
+func (p *MockProvider) CallFunction(r providers.CallFunctionRequest) providers.CallFunctionResponse {
+	return providers.CallFunctionResponse{
+		Error: fmt.Errorf("unknown function %q", r.Name),
+	}
+}
+
 func (p *MockProvider) Close() error {
 	p.CloseCalled = true
 	return p.CloseError
@@ -51,6 +51,10 @@ var (
 )
 
 func init() {
+	initLogger()
+}
+
+func initLogger() {
 	logger = newHCLogger("")
 	logWriter = logger.StandardWriter(&hclog.StandardLoggerOptions{InferLevels: true})
 
@@ -60,6 +64,24 @@ func init() {
 	log.SetOutput(logWriter)
 }
 
+// forceJSON is set by SetJSONFormat to select JSON log output regardless of
+// the log level given in the environment.
+var forceJSON bool
+
+// SetJSONFormat switches the global logger to JSON output, as if TF_LOG were
+// set to "json" but keeping the log level selected by the environment. It
+// doesn't enable logging if it's not already enabled.
+//
+// Any loggers created before calling SetJSONFormat continue to use the
+// previous format, so this should be called as early as possible.
+func SetJSONFormat() {
+	if forceJSON {
+		return
+	}
+	forceJSON = true
+	initLogger()
+}
+
 // SetupTempLog adds a new log sink which writes all logs to the given file.
 func RegisterSink(f *os.File) {
 	l, ok := logger.(hclog.InterceptLogger)
@@ -179,7 +201,7 @@ func globalLogLevel() (hclog.Level, bool) {
 	if envLevel == "" {
 		envLevel = strings.ToUpper(os.Getenv(envLogCore))
 	}
-	if envLevel == "JSON" {
+	if envLevel == "JSON" || forceJSON {
 		json = true
 	}
 	return parseLogLevel(envLevel), json
@@ -210,6 +232,12 @@ func IsDebugOrHigher() bool {
 	return level == hclog.Debug || level == hclog.Trace
 }
 
+// IsTrace returns whether or not the current log level is trace
+func IsTrace() bool {
+	level, _ := globalLogLevel()
+	return level == hclog.Trace
+}
+
 func isValidLogLevel(level string) bool {
 	for _, l := range ValidLevels {
 		if level == string(l) {
@@ -81,6 +81,13 @@ func PluginPanics() []string {
 	return panics.allPanics()
 }
 
+// PluginPanic returns the panic output that was collected from the plugin
+// with the given name, which is the base name of its executable file, or
+// nil if it hasn't panicked.
+func PluginPanic(name string) []string {
+	return panics.pluginPanic(name)
+}
+
 // panicRecorder provides a registry to check for plugin panics that may have
 // happened when a plugin suddenly terminates.
 type panicRecorder struct {
@@ -124,6 +131,17 @@ func (p *panicRecorder) registerPlugin(name string) func(string) {
 	}
 }
 
+func (p *panicRecorder) pluginPanic(name string) []string {
+	p.Lock()
+	defer p.Unlock()
+
+	lines := p.panics[name]
+	if len(lines) == 0 {
+		return nil
+	}
+	return append([]string(nil), lines...)
+}
+
 func (p *panicRecorder) allPanics() []string {
 	p.Lock()
 	defer p.Unlock()
@@ -31,6 +31,13 @@ func TestPanicRecorder(t *testing.T) {
 	if res[0] != expected {
 		t.Fatalf("expected: %q\ngot: %q", expected, res[0])
 	}
+
+	if got := PluginPanic("test"); strings.Join(got, "\n") != strings.Join(output, "\n") {
+		t.Fatalf("wrong panic output for plugin: %q", got)
+	}
+	if got := PluginPanic("other"); got != nil {
+		t.Fatalf("unexpected panic output for plugin that didn't panic: %q", got)
+	}
 }
 
 func TestPanicLimit(t *testing.T) {
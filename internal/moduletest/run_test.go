@@ -156,6 +156,7 @@ func TestRun_ValidateExpectedFailures(t *testing.T) {
 			Output: []output{
 				{
 					Description: tfdiags.Description{
+						Address: "output.unexpected",
 						Summary: "unexpected failure",
 						Detail:  "this should not be removed",
 					},
@@ -163,6 +164,7 @@ func TestRun_ValidateExpectedFailures(t *testing.T) {
 				},
 				{
 					Description: tfdiags.Description{
+						Address: "output.expected_one",
 						Summary: "expected warning",
 						Detail:  "this should not be removed",
 					},
@@ -170,6 +172,7 @@ func TestRun_ValidateExpectedFailures(t *testing.T) {
 				},
 				{
 					Description: tfdiags.Description{
+						Address: "module.child_module.output.expected_two",
 						Summary: "error in child module",
 						Detail:  "this should not be removed",
 					},
@@ -262,6 +265,7 @@ func TestRun_ValidateExpectedFailures(t *testing.T) {
 			Output: []output{
 				{
 					Description: tfdiags.Description{
+						Address: "var.unexpected",
 						Summary: "unexpected failure",
 						Detail:  "this should not be removed",
 					},
@@ -269,6 +273,7 @@ func TestRun_ValidateExpectedFailures(t *testing.T) {
 				},
 				{
 					Description: tfdiags.Description{
+						Address: "var.expected_one",
 						Summary: "expected warning",
 						Detail:  "this should not be removed",
 					},
@@ -276,6 +281,7 @@ func TestRun_ValidateExpectedFailures(t *testing.T) {
 				},
 				{
 					Description: tfdiags.Description{
+						Address: "module.child_module.var.expected_two",
 						Summary: "error in child module",
 						Detail:  "this should not be removed",
 					},
@@ -514,6 +520,7 @@ func TestRun_ValidateExpectedFailures(t *testing.T) {
 			Output: []output{
 				{
 					Description: tfdiags.Description{
+						Address: "test_instance.unexpected",
 						Summary: "unexpected failure",
 						Detail:  "this should not be removed",
 					},
@@ -521,6 +528,7 @@ func TestRun_ValidateExpectedFailures(t *testing.T) {
 				},
 				{
 					Description: tfdiags.Description{
+						Address: "test_instance.single",
 						Summary: "expected warning in test_instance.single",
 						Detail:  "this should not be removed",
 					},
@@ -528,6 +536,7 @@ func TestRun_ValidateExpectedFailures(t *testing.T) {
 				},
 				{
 					Description: tfdiags.Description{
+						Address: "test_instance.instance[1]",
 						Summary: "expected failure in test_instance.instance[1]",
 						Detail:  "this should not be removed",
 					},
@@ -535,6 +544,7 @@ func TestRun_ValidateExpectedFailures(t *testing.T) {
 				},
 				{
 					Description: tfdiags.Description{
+						Address: "module.child_module.test_instance.missing",
 						Summary: "failure in child module",
 						Detail:  "this should not be removed",
 					},
@@ -618,6 +628,7 @@ func TestRun_ValidateExpectedFailures(t *testing.T) {
 			Output: []output{
 				{
 					Description: tfdiags.Description{
+						Address: "check.unexpected",
 						Summary: "unexpected failure",
 						Detail:  "this should upgrade and not be removed",
 					},
@@ -625,6 +636,7 @@ func TestRun_ValidateExpectedFailures(t *testing.T) {
 				},
 				{
 					Description: tfdiags.Description{
+						Address: "module.child_module.check.expected",
 						Summary: "expected failure in child module",
 						Detail:  "this should upgrade and not be removed",
 					},
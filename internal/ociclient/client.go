@@ -0,0 +1,387 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package ociclient is a minimal client for the OCI distribution protocol,
+// which OpenTofu uses to install providers and modules from repositories in
+// OCI artifact registries.
+//
+// It supports only retrieving manifests and blobs, and finding the artifacts
+// that refer to a manifest, with anonymous, basic, or bearer token
+// authentication.
+package ociclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/opentofu/opentofu/internal/httpclient"
+)
+
+// The media types of the OCI documents that the client understands.
+const (
+	IndexMediaType    = "application/vnd.oci.image.index.v1+json"
+	ManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+)
+
+// ImageTitleAnnotation is the annotation of a layer which gives the name of
+// the file it contains.
+const ImageTitleAnnotation = "org.opencontainers.image.title"
+
+// MaxDocumentSize is the maximum size of the JSON documents, and of the
+// blobs read into memory by GetBlob, that the client will read from a
+// registry.
+const MaxDocumentSize = 4 * 1024 * 1024
+
+// ErrNotFound is returned if the registry has no such document.
+var ErrNotFound = fmt.Errorf("not found")
+
+// Client makes requests to OCI registries, remembering the authorization
+// for each repository once a request to it has been challenged.
+type Client struct {
+	creds      CredentialsSource
+	httpClient *http.Client
+
+	// Scheme is always "https" except in tests.
+	Scheme string
+
+	mu sync.Mutex
+	// auth is the value of the Authorization header for each repository,
+	// once a request to it has been challenged.
+	auth map[string]string
+}
+
+// NewClient returns a client which uses the given credentials when a
+// registry requires authentication.
+//
+// creds may be nil, in which case the client uses only anonymous access.
+func NewClient(creds CredentialsSource) *Client {
+	return &Client{
+		creds:      creds,
+		httpClient: httpclient.New(),
+		Scheme:     "https",
+		auth:       make(map[string]string),
+	}
+}
+
+// Repository is a repository in an OCI registry.
+type Repository struct {
+	Host string
+	Name string
+}
+
+// ParseRepository parses a repository address like
+// "ghcr.io/example/modules/vpc".
+func ParseRepository(addr string) (Repository, error) {
+	host, name, ok := strings.Cut(addr, "/")
+	if !ok || host == "" || name == "" {
+		return Repository{}, fmt.Errorf("invalid OCI repository address %q: must be a registry hostname followed by a repository name", addr)
+	}
+	return Repository{Host: host, Name: strings.ToLower(name)}, nil
+}
+
+func (r Repository) String() string {
+	return r.Host + "/" + r.Name
+}
+
+// Endpoint returns the URL of the given path in the API of the given
+// repository, such as "manifests/latest".
+func (c *Client) Endpoint(repo Repository, path string) string {
+	return fmt.Sprintf("%s://%s/v2/%s/%s", c.Scheme, repo.Host, repo.Name, path)
+}
+
+// Authorize adds the authorization for the given repository, if any, to a
+// request made outside of the client, such as a download of a blob.
+func (c *Client) Authorize(repo Repository, req *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if auth := c.auth[repo.String()]; auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+}
+
+// GetManifest retrieves and decodes the manifest with the given reference,
+// which is a tag or digest, and returns its digest.
+func (c *Client) GetManifest(ctx context.Context, repo Repository, reference, mediaType string, into interface{}) (string, error) {
+	digest, err := c.GetJSON(ctx, repo, "manifests/"+reference, mediaType, into)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(reference, "sha256:") && reference != digest {
+		return "", fmt.Errorf("manifest %s has the wrong digest %s", reference, digest)
+	}
+	return digest, nil
+}
+
+// Referrers returns the descriptors of the manifests with the given
+// artifact type that refer to the manifest with the given digest. It
+// returns no descriptors if the registry doesn't support the referrers API.
+func (c *Client) Referrers(ctx context.Context, repo Repository, digest, artifactType string) ([]Descriptor, error) {
+	var referrers Index
+	_, err := c.GetJSON(ctx, repo, "referrers/"+digest+"?artifactType="+url.QueryEscape(artifactType), IndexMediaType, &referrers)
+	if err == ErrNotFound {
+		// The registry doesn't support the referrers API, or there are no
+		// referrers at all.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ret []Descriptor
+	for _, desc := range referrers.Manifests {
+		// Registries may ignore the artifactType filter.
+		if desc.ArtifactType == artifactType {
+			ret = append(ret, desc)
+		}
+	}
+	return ret, nil
+}
+
+// GetJSON retrieves and decodes the JSON document at the given path, and
+// returns its digest.
+func (c *Client) GetJSON(ctx context.Context, repo Repository, path, mediaType string, into interface{}) (string, error) {
+	resp, err := c.Get(ctx, repo, path, mediaType)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", ErrNotFound
+	default:
+		return "", fmt.Errorf("registry returned unsuccessful status %s for %s", resp.Status, path)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxDocumentSize))
+	if err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal(body, into); err != nil {
+		return "", fmt.Errorf("invalid response for %s: %w", path, err)
+	}
+	sum := sha256.Sum256(body)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// GetBlob retrieves the blob with the given digest, which must be small
+// enough to keep in memory, verifying its digest.
+func (c *Client) GetBlob(ctx context.Context, repo Repository, digest string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := c.copyBlob(ctx, repo, digest, &buf, MaxDocumentSize); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CopyBlob writes the blob with the given digest to w, and returns an error
+// if its digest doesn't match after it has all been written.
+func (c *Client) CopyBlob(ctx context.Context, repo Repository, digest string, w io.Writer) error {
+	return c.copyBlob(ctx, repo, digest, w, -1)
+}
+
+func (c *Client) copyBlob(ctx context.Context, repo Repository, digest string, w io.Writer, limit int64) error {
+	want, err := DigestSHA256(digest)
+	if err != nil {
+		return err
+	}
+	resp, err := c.Get(ctx, repo, "blobs/"+digest, "*/*")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("registry returned unsuccessful status %s for blob %s", resp.Status, digest)
+	}
+	var body io.Reader = resp.Body
+	if limit >= 0 {
+		body = io.LimitReader(body, limit)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(w, h), body); err != nil {
+		return err
+	}
+	if !bytes.Equal(h.Sum(nil), want[:]) {
+		return fmt.Errorf("blob %s has the wrong digest", digest)
+	}
+	return nil
+}
+
+// Get makes a GET request to the given path in the given repository,
+// authenticating in response to a challenge from the registry if necessary.
+func (c *Client) Get(ctx context.Context, repo Repository, path, accept string) (*http.Response, error) {
+	do := func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", c.Endpoint(repo, path), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", accept)
+		c.Authorize(repo, req)
+		return c.httpClient.Do(req)
+	}
+
+	resp, err := do()
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close()
+
+	auth, err := c.authenticate(ctx, repo, challenge)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.auth[repo.String()] = auth
+	c.mu.Unlock()
+	return do()
+}
+
+// authenticate responds to the given challenge from a registry, returning
+// the value of the Authorization header to use for later requests.
+func (c *Client) authenticate(ctx context.Context, repo Repository, challenge string) (string, error) {
+	var username, password string
+	if c.creds != nil {
+		var err error
+		username, password, err = c.creds.OCICredentials(repo.Host)
+		if err != nil {
+			return "", fmt.Errorf("failed to find credentials for %s: %w", repo.Host, err)
+		}
+	}
+
+	scheme, params := parseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if username == "" && password == "" {
+			return "", fmt.Errorf("%s requires credentials, but none are configured", repo.Host)
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password)), nil
+	case "bearer":
+	default:
+		return "", fmt.Errorf("%s requested unsupported authentication scheme %q", repo.Host, scheme)
+	}
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("%s requested authentication with invalid realm %q", repo.Host, params["realm"])
+	}
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	query.Set("scope", "repository:"+repo.Name+":pull")
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if username != "" || password != "" {
+		req.SetBasicAuth(username, password)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to authenticate to %s: %w", repo.Host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to authenticate to %s: %s", repo.Host, resp.Status)
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, MaxDocumentSize)).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to authenticate to %s: invalid token response: %w", repo.Host, err)
+	}
+	token := body.Token
+	if token == "" {
+		token = body.AccessToken
+	}
+	if token == "" {
+		return "", fmt.Errorf("failed to authenticate to %s: no token in response", repo.Host)
+	}
+	return "Bearer " + token, nil
+}
+
+// parseChallenge parses the value of a WWW-Authenticate header like
+// `Bearer realm="https://example.com/token",service="example.com"`.
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := make(map[string]string)
+	for rest != "" {
+		var key string
+		key, rest, _ = strings.Cut(strings.TrimLeft(rest, " ,"), "=")
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			var buf bytes.Buffer
+			i := 1
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+				buf.WriteByte(rest[i])
+			}
+			value = buf.String()
+			rest = rest[min(i+1, len(rest)):]
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			params[key] = value
+		}
+	}
+	return scheme, params
+}
+
+// DigestSHA256 returns the checksum in a digest like "sha256:abc...".
+func DigestSHA256(digest string) ([sha256.Size]byte, error) {
+	var ret [sha256.Size]byte
+	hexSum, ok := strings.CutPrefix(digest, "sha256:")
+	if !ok {
+		return ret, fmt.Errorf("unsupported digest %q: only sha256 digests are supported", digest)
+	}
+	sum, err := hex.DecodeString(hexSum)
+	if err != nil || len(sum) != sha256.Size {
+		return ret, fmt.Errorf("invalid digest %q", digest)
+	}
+	copy(ret[:], sum)
+	return ret, nil
+}
+
+// Descriptor describes a manifest or blob that another document refers to.
+type Descriptor struct {
+	MediaType    string            `json:"mediaType"`
+	ArtifactType string            `json:"artifactType,omitempty"`
+	Digest       string            `json:"digest"`
+	Size         int64             `json:"size"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	Platform     *Platform         `json:"platform,omitempty"`
+}
+
+// Platform is the platform of a manifest in an image index.
+type Platform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+}
+
+// Index is an image index, which refers to a manifest for each platform.
+type Index struct {
+	Manifests []Descriptor `json:"manifests"`
+}
+
+// Manifest is an image manifest, which refers to the layers of an image
+// or other artifact.
+type Manifest struct {
+	ArtifactType string       `json:"artifactType,omitempty"`
+	Layers       []Descriptor `json:"layers"`
+}
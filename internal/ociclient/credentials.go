@@ -0,0 +1,133 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package ociclient
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// CredentialsSource returns the username and password to use when
+// authenticating to the OCI registry at the given host, or empty strings for
+// anonymous access.
+type CredentialsSource interface {
+	OCICredentials(host string) (username, password string, err error)
+}
+
+// DockerCredentialsSource is a CredentialsSource which finds credentials
+// in the same places as the Docker CLI: the "auths" of the Docker
+// configuration file, and the credential helper programs that the
+// configuration file selects in "credHelpers" and "credsStore".
+type DockerCredentialsSource struct {
+	// configFile is the path of the Docker configuration file.
+	configFile string
+}
+
+var _ CredentialsSource = (*DockerCredentialsSource)(nil)
+
+// NewDockerCredentialsSource returns a credentials source which uses the
+// Docker configuration file in the directory named by the DOCKER_CONFIG
+// environment variable, or in ~/.docker by default.
+func NewDockerCredentialsSource() *DockerCredentialsSource {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return &DockerCredentialsSource{}
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+	return &DockerCredentialsSource{
+		configFile: filepath.Join(dir, "config.json"),
+	}
+}
+
+// OCICredentials returns the credentials for the given registry host, or
+// empty strings if the Docker configuration has none.
+func (s *DockerCredentialsSource) OCICredentials(host string) (string, string, error) {
+	if s.configFile == "" {
+		return "", "", nil
+	}
+	src, err := os.ReadFile(s.configFile)
+	if os.IsNotExist(err) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	var config struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+		CredHelpers map[string]string `json:"credHelpers"`
+		CredsStore  string            `json:"credsStore"`
+	}
+	if err := json.Unmarshal(src, &config); err != nil {
+		return "", "", fmt.Errorf("invalid Docker configuration file %s: %w", s.configFile, err)
+	}
+
+	if helper := config.CredHelpers[host]; helper != "" {
+		return dockerCredentialHelper(helper, host)
+	}
+	for _, key := range []string{host, "https://" + host, "http://" + host} {
+		auth, ok := config.Auths[key]
+		if !ok || auth.Auth == "" {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid credentials for %s in %s: %w", host, s.configFile, err)
+		}
+		username, password, ok := strings.Cut(string(raw), ":")
+		if !ok {
+			return "", "", fmt.Errorf("invalid credentials for %s in %s: must be a username and password separated by a colon", host, s.configFile)
+		}
+		return username, password, nil
+	}
+	if config.CredsStore != "" {
+		return dockerCredentialHelper(config.CredsStore, host)
+	}
+	return "", "", nil
+}
+
+// dockerCredentialHelper runs the Docker credential helper program with the
+// given name to get the credentials for the given registry host.
+func dockerCredentialHelper(name, host string) (string, string, error) {
+	executable := "docker-credential-" + name
+	var outBuf, errBuf bytes.Buffer
+	cmd := exec.Command(executable, "get")
+	cmd.Stdin = strings.NewReader(host)
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+	err := cmd.Run()
+	if _, isExitErr := err.(*exec.ExitError); isExitErr {
+		errText := strings.TrimSpace(outBuf.String() + errBuf.String())
+		if strings.Contains(errText, "credentials not found") {
+			// This is how helpers report that they have no credentials
+			// for the host, which isn't an error for us.
+			return "", "", nil
+		}
+		if errText == "" {
+			return "", "", fmt.Errorf("error in %s, but it produced no error message", executable)
+		}
+		return "", "", fmt.Errorf("error in %s: %s", executable, errText)
+	} else if err != nil {
+		return "", "", fmt.Errorf("failed to run %s: %w", executable, err)
+	}
+
+	var creds struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}
+	if err := json.Unmarshal(outBuf.Bytes(), &creds); err != nil {
+		return "", "", fmt.Errorf("malformed output from %s: %w", executable, err)
+	}
+	return creds.Username, creds.Secret, nil
+}
@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package ociclient
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestDockerCredentialsSource(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DOCKER_CONFIG", dir)
+	config := `{
+  "auths": {
+    "https://registry.example.com": {"auth": "dXNlcjpzZWNyZXQ="}
+  },
+  "credHelpers": {
+    "helper.example.com": "test"
+  }
+}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	source := NewDockerCredentialsSource()
+
+	t.Run("auths", func(t *testing.T) {
+		username, password, err := source.OCICredentials("registry.example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if username != "user" || password != "secret" {
+			t.Errorf("wrong credentials %q, %q", username, password)
+		}
+	})
+	t.Run("no credentials", func(t *testing.T) {
+		username, password, err := source.OCICredentials("other.example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if username != "" || password != "" {
+			t.Errorf("wrong credentials %q, %q", username, password)
+		}
+	})
+	t.Run("credential helper", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("test credential helper is a shell script")
+		}
+		binDir := t.TempDir()
+		helper := "#!/bin/sh\nread host\necho \"{\\\"Username\\\":\\\"helper-user\\\",\\\"Secret\\\":\\\"$host\\\"}\"\n"
+		if err := os.WriteFile(filepath.Join(binDir, "docker-credential-test"), []byte(helper), 0755); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		username, password, err := source.OCICredentials("helper.example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if username != "helper-user" || password != "helper.example.com" {
+			t.Errorf("wrong credentials %q, %q", username, password)
+		}
+	})
+}
@@ -0,0 +1,228 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plans
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+// BlastRadius summarizes the objects that a plan destroys, to help judge
+// the impact of applying it before doing so.
+//
+// Like CostEstimate, this is only for the purpose of informing the end-user
+// and any policies, and must not be used to drive any behavior during apply.
+type BlastRadius struct {
+	// Resources are the resource instance objects that the plan destroys,
+	// including those it replaces, in address order.
+	Resources []BlastRadiusResource
+
+	// Modules summarizes the destroyed objects by the module instance that
+	// contains them, in address order.
+	Modules []BlastRadiusModule
+}
+
+// BlastRadiusResource is a resource instance object that a plan destroys.
+type BlastRadiusResource struct {
+	Addr       addrs.AbsResourceInstance
+	DeposedKey states.DeposedKey
+
+	// Category is a broad category of the object's resource type, guessed
+	// from the name of the type.
+	Category BlastRadiusCategory
+
+	// DataLoss is true if destroying the object is likely to also destroy
+	// data which cannot be recreated, such as the contents of a database
+	// or a storage bucket.
+	DataLoss bool
+}
+
+// BlastRadiusModule summarizes the objects that a plan destroys in a single
+// module instance.
+type BlastRadiusModule struct {
+	Addr addrs.ModuleInstance
+
+	// Destroyed is the number of objects in the module instance that the
+	// plan destroys.
+	Destroyed int
+
+	// DependentModules are the other module instances containing objects
+	// which depend on the objects destroyed in this module instance, in
+	// address order.
+	DependentModules []addrs.ModuleInstance
+}
+
+// BlastRadiusCategory is a broad category of resource types.
+type BlastRadiusCategory string
+
+const (
+	BlastRadiusDatabase BlastRadiusCategory = "database"
+	BlastRadiusStorage  BlastRadiusCategory = "storage"
+	BlastRadiusNetwork  BlastRadiusCategory = "network"
+	BlastRadiusCompute  BlastRadiusCategory = "compute"
+	BlastRadiusIdentity BlastRadiusCategory = "identity"
+	BlastRadiusOther    BlastRadiusCategory = "other"
+)
+
+// blastRadiusCategoryWords are the words in resource type names which
+// suggest each category, checked in order so that, for example, a database
+// instance is a database rather than a compute instance.
+var blastRadiusCategoryWords = []struct {
+	category BlastRadiusCategory
+	words    []string
+}{
+	{BlastRadiusDatabase, []string{"db", "database", "rds", "sql", "mysql", "postgres", "postgresql", "dynamodb", "redis", "elasticache", "memcache", "cosmosdb", "spanner", "bigtable", "bigquery", "documentdb", "docdb", "neptune", "redshift", "mongodb", "cassandra", "keyspaces", "firestore", "datastore"}},
+	{BlastRadiusStorage, []string{"bucket", "s3", "storage", "volume", "disk", "efs", "fsx", "filesystem", "blob", "share", "snapshot", "backup", "glacier"}},
+	{BlastRadiusNetwork, []string{"vpc", "subnet", "subnetwork", "network", "vnet", "route", "router", "gateway", "firewall", "dns", "lb", "alb", "elb", "zone", "record", "peering", "nat", "eip", "address", "endpoint", "cdn", "cloudfront", "security"}},
+	{BlastRadiusCompute, []string{"instance", "vm", "machine", "compute", "function", "lambda", "container", "cluster", "node", "kubernetes", "eks", "gke", "aks", "ecs", "task", "app", "autoscaling"}},
+	{BlastRadiusIdentity, []string{"iam", "role", "policy", "user", "group", "kms", "key", "secret", "certificate", "identity", "account", "permission"}},
+}
+
+// blastRadiusAuxiliaryWords are the words in resource type names which
+// suggest that the resource type only configures some other object, such
+// as the policy of a bucket, and so doesn't hold data itself.
+var blastRadiusAuxiliaryWords = []string{
+	"acl", "association", "attachment", "config", "configuration", "group", "lifecycle", "logging", "notification", "option", "parameter", "permission", "policy", "replication", "rule", "subnet", "versioning",
+}
+
+// NewBlastRadius analyzes the objects that the given changes destroy. The
+// given state is the prior state of the plan, whose recorded dependencies
+// are used to find the other modules that depend on the destroyed objects.
+func NewBlastRadius(changes *Changes, prior *states.State) *BlastRadius {
+	ret := &BlastRadius{}
+	modules := make(map[string]*BlastRadiusModule)
+	destroyedResources := make(map[string][]*BlastRadiusModule)
+
+	for _, rc := range changes.Resources {
+		if rc.Addr.Resource.Resource.Mode != addrs.ManagedResourceMode {
+			continue
+		}
+		switch rc.Action {
+		case Delete, DeleteThenCreate, CreateThenDelete:
+		default:
+			continue
+		}
+
+		category, dataLoss := blastRadiusCategory(rc.Addr.Resource.Resource.Type)
+		ret.Resources = append(ret.Resources, BlastRadiusResource{
+			Addr:       rc.Addr,
+			DeposedKey: rc.DeposedKey,
+			Category:   category,
+			DataLoss:   dataLoss,
+		})
+
+		key := rc.Addr.Module.String()
+		mod, ok := modules[key]
+		if !ok {
+			mod = &BlastRadiusModule{Addr: rc.Addr.Module}
+			modules[key] = mod
+		}
+		mod.Destroyed++
+
+		// The dependencies recorded in the state don't include instance
+		// keys, so we match the destroyed objects by their resource's
+		// address in the configuration.
+		configAddr := rc.Addr.ConfigResource().String()
+		destroyedResources[configAddr] = append(destroyedResources[configAddr], mod)
+	}
+
+	// An object depends on the destroyed objects in other modules if its
+	// recorded dependencies include their resources.
+	dependents := make(map[*BlastRadiusModule]map[string]addrs.ModuleInstance)
+	if prior != nil {
+		for _, ms := range prior.Modules {
+			for _, rs := range ms.Resources {
+				for _, is := range rs.Instances {
+					if is.Current == nil {
+						continue
+					}
+					for _, dep := range is.Current.Dependencies {
+						for _, mod := range destroyedResources[dep.String()] {
+							if mod.Addr.Equal(ms.Addr) {
+								continue
+							}
+							if dependents[mod] == nil {
+								dependents[mod] = make(map[string]addrs.ModuleInstance)
+							}
+							dependents[mod][ms.Addr.String()] = ms.Addr
+						}
+					}
+				}
+			}
+		}
+	}
+
+	for _, mod := range modules {
+		for _, addr := range dependents[mod] {
+			mod.DependentModules = append(mod.DependentModules, addr)
+		}
+		sort.Slice(mod.DependentModules, func(i, j int) bool {
+			return mod.DependentModules[i].Less(mod.DependentModules[j])
+		})
+		ret.Modules = append(ret.Modules, *mod)
+	}
+	sort.Slice(ret.Modules, func(i, j int) bool {
+		return ret.Modules[i].Addr.Less(ret.Modules[j].Addr)
+	})
+	sort.SliceStable(ret.Resources, func(i, j int) bool {
+		return ret.Resources[i].Addr.Less(ret.Resources[j].Addr)
+	})
+	return ret
+}
+
+// Categories returns the number of destroyed objects in each category.
+func (r *BlastRadius) Categories() map[BlastRadiusCategory]int {
+	ret := make(map[BlastRadiusCategory]int)
+	for _, rc := range r.Resources {
+		ret[rc.Category]++
+	}
+	return ret
+}
+
+// DataLoss returns the destroyed objects which are likely to lose data.
+func (r *BlastRadius) DataLoss() []BlastRadiusResource {
+	var ret []BlastRadiusResource
+	for _, rc := range r.Resources {
+		if rc.DataLoss {
+			ret = append(ret, rc)
+		}
+	}
+	return ret
+}
+
+// blastRadiusCategory guesses the category of the given resource type from
+// the words in its name, and whether destroying an object of the type is
+// likely to lose data.
+//
+// This is a heuristic based on the naming conventions of the most popular
+// providers, so it can be wrong in both directions.
+func blastRadiusCategory(typeName string) (BlastRadiusCategory, bool) {
+	words := strings.Split(typeName, "_")
+	if len(words) > 1 {
+		// The first word is the provider's local name, such as "aws", which
+		// says nothing about the kind of object.
+		words = words[1:]
+	}
+	has := func(candidates []string) bool {
+		for _, w := range words {
+			for _, c := range candidates {
+				if w == c {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	for _, c := range blastRadiusCategoryWords {
+		if has(c.words) {
+			dataLoss := (c.category == BlastRadiusDatabase || c.category == BlastRadiusStorage) && !has(blastRadiusAuxiliaryWords)
+			return c.category, dataLoss
+		}
+	}
+	return BlastRadiusOther, false
+}
@@ -0,0 +1,114 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plans
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+func TestNewBlastRadius(t *testing.T) {
+	db := mustBlastRadiusAddr(t, "aws_db_instance.main")
+	vpc := mustBlastRadiusAddr(t, "module.network.aws_vpc.main")
+	bucketPolicy := mustBlastRadiusAddr(t, "module.network.aws_s3_bucket_policy.logs")
+	app := mustBlastRadiusAddr(t, "module.app.aws_instance.web")
+	kept := mustBlastRadiusAddr(t, "module.app.aws_instance.kept")
+	provider := addrs.AbsProviderConfig{
+		Provider: addrs.NewDefaultProvider("aws"),
+		Module:   addrs.RootModule,
+	}
+
+	changes := &Changes{
+		Resources: []*ResourceInstanceChangeSrc{
+			{Addr: vpc, ChangeSrc: ChangeSrc{Action: Delete}},
+			{Addr: db, ChangeSrc: ChangeSrc{Action: Delete}},
+			{Addr: bucketPolicy, ChangeSrc: ChangeSrc{Action: Delete}},
+			{Addr: app, ChangeSrc: ChangeSrc{Action: DeleteThenCreate}},
+			{Addr: kept, ChangeSrc: ChangeSrc{Action: Update}},
+		},
+	}
+	prior := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(kept, &states.ResourceInstanceObjectSrc{
+			Status:       states.ObjectReady,
+			AttrsJSON:    []byte(`{}`),
+			Dependencies: []addrs.ConfigResource{vpc.ConfigResource()},
+		}, provider)
+		s.SetResourceInstanceCurrent(db, &states.ResourceInstanceObjectSrc{
+			Status:       states.ObjectReady,
+			AttrsJSON:    []byte(`{}`),
+			Dependencies: []addrs.ConfigResource{vpc.ConfigResource(), bucketPolicy.ConfigResource()},
+		}, provider)
+	})
+
+	got := NewBlastRadius(changes, prior)
+	want := &BlastRadius{
+		Resources: []BlastRadiusResource{
+			{Addr: db, Category: BlastRadiusDatabase, DataLoss: true},
+			{Addr: app, Category: BlastRadiusCompute},
+			{Addr: bucketPolicy, Category: BlastRadiusStorage},
+			{Addr: vpc, Category: BlastRadiusNetwork},
+		},
+		Modules: []BlastRadiusModule{
+			{Addr: addrs.RootModuleInstance, Destroyed: 1},
+			{Addr: app.Module, Destroyed: 1},
+			{
+				Addr:             vpc.Module,
+				Destroyed:        2,
+				DependentModules: []addrs.ModuleInstance{addrs.RootModuleInstance, app.Module},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong result\n%s", diff)
+	}
+	if got, want := got.Categories(), map[BlastRadiusCategory]int{
+		BlastRadiusDatabase: 1,
+		BlastRadiusCompute:  1,
+		BlastRadiusStorage:  1,
+		BlastRadiusNetwork:  1,
+	}; !cmp.Equal(got, want) {
+		t.Errorf("wrong categories\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestBlastRadiusCategory(t *testing.T) {
+	tests := map[string]struct {
+		category BlastRadiusCategory
+		dataLoss bool
+	}{
+		"aws_db_instance":              {BlastRadiusDatabase, true},
+		"aws_db_subnet_group":          {BlastRadiusDatabase, false},
+		"google_sql_database_instance": {BlastRadiusDatabase, true},
+		"aws_dynamodb_table":           {BlastRadiusDatabase, true},
+		"aws_s3_bucket":                {BlastRadiusStorage, true},
+		"aws_s3_bucket_versioning":     {BlastRadiusStorage, false},
+		"aws_ebs_volume":               {BlastRadiusStorage, true},
+		"aws_security_group":           {BlastRadiusNetwork, false},
+		"aws_instance":                 {BlastRadiusCompute, false},
+		"aws_iam_role":                 {BlastRadiusIdentity, false},
+		"random_pet":                   {BlastRadiusOther, false},
+		"null":                         {BlastRadiusOther, false},
+	}
+	for typeName, want := range tests {
+		t.Run(typeName, func(t *testing.T) {
+			category, dataLoss := blastRadiusCategory(typeName)
+			if category != want.category || dataLoss != want.dataLoss {
+				t.Errorf("wrong result (%s, %t); want (%s, %t)", category, dataLoss, want.category, want.dataLoss)
+			}
+		})
+	}
+}
+
+func mustBlastRadiusAddr(t *testing.T, s string) addrs.AbsResourceInstance {
+	t.Helper()
+	addr, diags := addrs.ParseAbsResourceInstanceStr(s)
+	if diags.HasErrors() {
+		t.Fatal(diags.Err())
+	}
+	return addr
+}
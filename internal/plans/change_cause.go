@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plans
+
+import (
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+// ChangeCause describes why a particular attribute of a resource instance
+// object is planned to change.
+//
+// Like ResourceInstanceChangeActionReason, this is an approximate mechanism
+// only for the purpose of explaining the plan to end-users in the UI, and is
+// not to be used for any decision-making during the apply step.
+type ChangeCause rune
+
+//go:generate go run golang.org/x/tools/cmd/stringer -type=ChangeCause change_cause.go
+
+const (
+	// ChangeCauseNone means that OpenTofu couldn't determine a more specific
+	// cause for the change, which is typically because the provider chose
+	// the new value itself.
+	ChangeCauseNone ChangeCause = 0
+
+	// ChangeCauseConfig indicates that the attribute changes because its
+	// expression in the configuration now produces a different value.
+	ChangeCauseConfig ChangeCause = 'C'
+
+	// ChangeCauseUpstream indicates that the attribute changes because its
+	// expression in the configuration refers to at least one other resource
+	// which also has changes pending in the same plan.
+	ChangeCauseUpstream ChangeCause = 'U'
+
+	// ChangeCauseDrift indicates that the attribute changes because the
+	// remote object was changed outside of OpenTofu, as detected during
+	// refresh, and the plan will restore the configured value.
+	ChangeCauseDrift ChangeCause = 'D'
+
+	// ChangeCauseReplacement indicates that the attribute changes only
+	// because the provider requires replacing the whole object, and so the
+	// value it chose for the existing object won't carry over to the new one.
+	ChangeCauseReplacement ChangeCause = 'R'
+)
+
+// AttributeChangeCause records the cause of a change to a single top-level
+// attribute or nested block type of a resource instance object.
+type AttributeChangeCause struct {
+	// Attribute is the name of the attribute or nested block type in the
+	// resource type schema.
+	Attribute string
+
+	// Cause is the reason for the change.
+	Cause ChangeCause
+
+	// Filename and Line locate the configuration expression or block that
+	// defines the attribute. These are set only for ChangeCauseConfig and
+	// ChangeCauseUpstream.
+	Filename string
+	Line     int
+
+	// Upstream are the resources referenced from the attribute's expression
+	// which have changes pending in the same plan. This is set only for
+	// ChangeCauseUpstream.
+	Upstream []addrs.AbsResource
+}
@@ -243,6 +243,12 @@ type ResourceInstanceChange struct {
 	// currently survive a round-trip through a saved plan file.
 	RequiredReplace cty.PathSet
 
+	// ChangeCauses explain why each of the top-level attributes and nested
+	// block types that differ between Before and After is changing, sorted
+	// by attribute name. Like ActionReason, this is only for the purpose of
+	// explaining the plan to end-users in the UI.
+	ChangeCauses []AttributeChangeCause
+
 	// Private allows a provider to stash any extra data that is opaque to
 	// OpenTofu that relates to this change. OpenTofu will save this
 	// byte-for-byte and return it to the provider in the apply call.
@@ -271,6 +277,7 @@ func (rc *ResourceInstanceChange) Encode(ty cty.Type) (*ResourceInstanceChangeSr
 		ChangeSrc:       *cs,
 		ActionReason:    rc.ActionReason,
 		RequiredReplace: rc.RequiredReplace,
+		ChangeCauses:    rc.ChangeCauses,
 		Private:         rc.Private,
 	}, err
 }
@@ -68,6 +68,11 @@ type ResourceInstanceChangeSrc struct {
 	// Replace.
 	RequiredReplace cty.PathSet
 
+	// ChangeCauses explain why each of the attributes that differ between
+	// Before and After is changing. See the field of the same name in
+	// ResourceInstanceChange for more details.
+	ChangeCauses []AttributeChangeCause
+
 	// Private allows a provider to stash any extra data that is opaque to
 	// OpenTofu that relates to this change. OpenTofu will save this
 	// byte-for-byte and return it to the provider in the apply call.
@@ -96,6 +101,7 @@ func (rcs *ResourceInstanceChangeSrc) Decode(ty cty.Type) (*ResourceInstanceChan
 		Change:          *change,
 		ActionReason:    rcs.ActionReason,
 		RequiredReplace: rcs.RequiredReplace,
+		ChangeCauses:    rcs.ChangeCauses,
 		Private:         rcs.Private,
 	}, nil
 }
@@ -116,6 +122,11 @@ func (rcs *ResourceInstanceChangeSrc) DeepCopy() *ResourceInstanceChangeSrc {
 
 	ret.RequiredReplace = cty.NewPathSet(ret.RequiredReplace.List()...)
 
+	if len(ret.ChangeCauses) != 0 {
+		ret.ChangeCauses = make([]AttributeChangeCause, len(rcs.ChangeCauses))
+		copy(ret.ChangeCauses, rcs.ChangeCauses)
+	}
+
 	if len(ret.Private) != 0 {
 		private := make([]byte, len(ret.Private))
 		copy(private, ret.Private)
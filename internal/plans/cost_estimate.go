@@ -0,0 +1,42 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plans
+
+import (
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+// CostEstimate is an estimate of how applying a plan would change the cost of
+// the infrastructure, as provided by an external estimator when the plan was
+// created.
+//
+// Like RelevantAttributes, this is only for the purpose of informing the
+// end-user and must not be used to drive any behavior during apply.
+type CostEstimate struct {
+	// Currency is the code of the currency that all of the costs are in,
+	// such as "USD".
+	Currency string
+
+	// Resources are the estimated changes in the monthly cost of the resource
+	// instances with planned changes. Resource instances whose cost the
+	// estimator doesn't know are omitted.
+	Resources []ResourceCostEstimate
+}
+
+// ResourceCostEstimate is the estimated change in the monthly cost of a
+// single resource instance.
+type ResourceCostEstimate struct {
+	Addr             addrs.AbsResourceInstance
+	MonthlyCostDelta float64
+}
+
+// TotalMonthlyCostDelta returns the estimated change in the monthly cost of
+// all of the resource instances.
+func (e *CostEstimate) TotalMonthlyCostDelta() float64 {
+	var total float64
+	for _, rc := range e.Resources {
+		total += rc.MonthlyCostDelta
+	}
+	return total
+}
@@ -0,0 +1,205 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package deferring tracks the resources whose changes are deferred to a
+// later plan/apply round because OpenTofu cannot yet determine which
+// instances they have.
+package deferring
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+// Deferred is a container for the resources and resource instances whose
+// changes have been deferred during a plan operation, or whose changes were
+// deferred by the plan that is being applied.
+//
+// A whole resource is deferred when its count or for_each argument depends
+// on values that won't be known until apply, so that its instances can't be
+// determined. A single resource instance is deferred when it depends on
+// another resource that is deferred, or on a deferred instance of one. The
+// changes for deferred objects are left out of the plan, and references to
+// them evaluate to unknown values.
+//
+// This container type is concurrency-safe for both reads and writes through
+// its various methods. The methods of a nil *Deferred behave as if deferral
+// is not allowed and nothing is deferred.
+type Deferred struct {
+	allowed bool
+
+	mu        sync.Mutex
+	resources addrs.Set[addrs.AbsResource]
+	instances addrs.Map[addrs.AbsResource, addrs.Set[addrs.AbsResourceInstance]]
+
+	// configResources are the configuration addresses of all of the
+	// deferred resources and of the resources of all of the deferred
+	// resource instances.
+	configResources addrs.Set[addrs.ConfigResource]
+}
+
+// NewDeferred returns a new Deferred object. If allowed is false then the
+// caller must not report any new deferred resources, and should instead
+// return an error when it encounters a resource that it can't expand.
+func NewDeferred(allowed bool) *Deferred {
+	return &Deferred{
+		allowed:         allowed,
+		resources:       addrs.MakeSet[addrs.AbsResource](),
+		instances:       addrs.MakeMap[addrs.AbsResource, addrs.Set[addrs.AbsResourceInstance]](),
+		configResources: addrs.MakeSet[addrs.ConfigResource](),
+	}
+}
+
+// Allowed returns true if the current operation may defer resources whose
+// instances it can't determine.
+func (d *Deferred) Allowed() bool {
+	if d == nil {
+		return false
+	}
+	return d.allowed
+}
+
+// ReportResourceDeferred records that the changes for all instances of the
+// given resource are deferred to a later plan.
+func (d *Deferred) ReportResourceDeferred(addr addrs.AbsResource) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.resources.Add(addr)
+	d.configResources.Add(addr.Config())
+}
+
+// ReportResourceInstanceDeferred records that the changes for the given
+// resource instance are deferred to a later plan, without affecting the
+// other instances of the same resource.
+func (d *Deferred) ReportResourceInstanceDeferred(addr addrs.AbsResourceInstance) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	resAddr := addr.ContainingResource()
+	if !d.instances.Has(resAddr) {
+		d.instances.Put(resAddr, addrs.MakeSet[addrs.AbsResourceInstance]())
+	}
+	d.instances.Get(resAddr).Add(addr)
+	d.configResources.Add(resAddr.Config())
+}
+
+// IsResourceDeferred returns true if the changes for all instances of the
+// given resource are deferred.
+func (d *Deferred) IsResourceDeferred(addr addrs.AbsResource) bool {
+	if d == nil {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.resources.Has(addr)
+}
+
+// IsResourceInstanceDeferred returns true if the changes for the given
+// resource instance are deferred, either individually or because the
+// changes for its whole resource are deferred.
+func (d *Deferred) IsResourceInstanceDeferred(addr addrs.AbsResourceInstance) bool {
+	if d == nil {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	resAddr := addr.ContainingResource()
+	if d.resources.Has(resAddr) {
+		return true
+	}
+	return d.instances.Has(resAddr) && d.instances.Get(resAddr).Has(addr)
+}
+
+// DeferredInstanceKeys returns the keys of the individually-deferred
+// instances of the given resource, in no particular order.
+func (d *Deferred) DeferredInstanceKeys(addr addrs.AbsResource) []addrs.InstanceKey {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.instances.Has(addr) {
+		return nil
+	}
+	insts := d.instances.Get(addr)
+	ret := make([]addrs.InstanceKey, 0, len(insts))
+	for _, inst := range insts {
+		ret = append(ret, inst.Resource.Key)
+	}
+	return ret
+}
+
+// HaveDeferredDependency returns true if the changes for any of the given
+// resources, or for any of their instances, are deferred, in which case an
+// object in the given module instance which depends on them must be deferred
+// too.
+//
+// A dependency in the same module as the dependent object only counts if it
+// is deferred in the same module instance, so that deferring a resource in
+// one instance of a module doesn't also defer its dependents in the other
+// instances. Any deferred instance of a resource in another module counts,
+// since the dependencies don't say which instances of other modules they
+// refer to.
+func (d *Deferred) HaveDeferredDependency(module addrs.ModuleInstance, deps []addrs.ConfigResource) bool {
+	if d == nil {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, dep := range deps {
+		if !d.configResources.Has(dep) {
+			continue
+		}
+		if !dep.Module.Equal(module.Module()) {
+			return true
+		}
+		absDep := dep.Absolute(module)
+		if d.resources.Has(absDep) || (d.instances.Has(absDep) && len(d.instances.Get(absDep)) != 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resources returns the addresses of all of the resources whose changes are
+// deferred as a whole, sorted by their string representation.
+func (d *Deferred) Resources() []addrs.AbsResource {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.resources) == 0 {
+		return nil
+	}
+	ret := make([]addrs.AbsResource, 0, len(d.resources))
+	for _, addr := range d.resources {
+		ret = append(ret, addr)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].String() < ret[j].String()
+	})
+	return ret
+}
+
+// ResourceInstances returns the addresses of all of the individually-deferred
+// resource instances, sorted by their string representation. It doesn't
+// include the instances of the resources that Resources returns.
+func (d *Deferred) ResourceInstances() []addrs.AbsResourceInstance {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var ret []addrs.AbsResourceInstance
+	for _, elem := range d.instances.Elems {
+		for _, addr := range elem.Value {
+			ret = append(ret, addr)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].String() < ret[j].String()
+	})
+	return ret
+}
@@ -0,0 +1,27 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plans
+
+import (
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+// DiscoveredResource is a remote object that OpenTofu doesn't manage yet,
+// found by listing the objects of a managed resource type that appears in
+// the configuration.
+type DiscoveredResource struct {
+	// Addr is the address that the generated import block imports the
+	// object to. It's unique among the resources in the root module.
+	Addr addrs.AbsResourceInstance
+
+	// ProviderAddr is the provider configuration that listed the object.
+	ProviderAddr addrs.AbsProviderConfig
+
+	// ImportID is the ID that imports the object.
+	ImportID string
+
+	// GeneratedConfig is a draft import block for the object, followed by
+	// the configuration of a resource to import it to.
+	GeneratedConfig string
+}
@@ -0,0 +1,76 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plans
+
+import (
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+// DriftSelection decides which of the changes made outside of OpenTofu a
+// refresh-only plan accepts into the state. The changes that aren't accepted
+// are left for a later plan to correct.
+//
+// With no Accept selectors, all of the changes are accepted except those
+// selected by Reject. Otherwise, only the changes selected by Accept are
+// accepted, again except those selected by Reject.
+type DriftSelection struct {
+	Accept []DriftSelector
+	Reject []DriftSelector
+}
+
+// DriftSelector selects the changes made outside of OpenTofu to the objects
+// of some resource instances, or to a single attribute of those objects.
+type DriftSelector struct {
+	// Target is a module, resource or resource instance containing the
+	// selected objects.
+	Target addrs.Targetable
+
+	// Path, if not empty, selects only the changes to the value at this
+	// path within the objects. It is set only when Target is a resource or
+	// resource instance.
+	Path cty.Path
+}
+
+// Empty returns true if the selection has no selectors, and so accepts all
+// of the changes.
+func (s *DriftSelection) Empty() bool {
+	return s == nil || (len(s.Accept) == 0 && len(s.Reject) == 0)
+}
+
+// ForInstance returns whether the selection accepts the changes to the
+// objects of the given resource instance as a whole, along with the paths
+// of the values within them whose changes are accepted or rejected
+// regardless, because selectors for attributes are more specific than those
+// for whole objects. When a path is both accepted and rejected, rejecting it
+// takes precedence.
+func (s *DriftSelection) ForInstance(addr addrs.AbsResourceInstance) (accepted bool, acceptPaths, rejectPaths []cty.Path) {
+	if s.Empty() {
+		return true, nil, nil
+	}
+
+	accepted = len(s.Accept) == 0
+	for _, sel := range s.Accept {
+		if !sel.Target.TargetContains(addr) {
+			continue
+		}
+		if len(sel.Path) == 0 {
+			accepted = true
+		} else {
+			acceptPaths = append(acceptPaths, sel.Path)
+		}
+	}
+	for _, sel := range s.Reject {
+		if !sel.Target.TargetContains(addr) {
+			continue
+		}
+		if len(sel.Path) == 0 {
+			accepted = false
+		} else {
+			rejectPaths = append(rejectPaths, sel.Path)
+		}
+	}
+	return accepted, acceptPaths, rejectPaths
+}
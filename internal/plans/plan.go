@@ -44,9 +44,29 @@ type Plan struct {
 	Changes           *Changes
 	DriftedResources  []*ResourceInstanceChangeSrc
 	TargetAddrs       []addrs.Targetable
+	ExcludeAddrs      []addrs.Targetable
 	ForceReplaceAddrs []addrs.AbsResourceInstance
 	Backend           Backend
 
+	// DeferredResources are the resources whose changes were left out of
+	// this plan because OpenTofu could not determine their instances, either
+	// because their count or for_each arguments depend on values that won't
+	// be known until apply or because they depend on other deferred
+	// resources. Another plan is needed after applying this one to plan the
+	// changes for these resources.
+	DeferredResources []addrs.AbsResource
+
+	// DeferredResourceInstances are the individual resource instances whose
+	// changes were left out of this plan because they depend on deferred
+	// resources, or on deferred instances of other resources. The other
+	// instances of the same resources may have changes in this plan.
+	DeferredResourceInstances []addrs.AbsResourceInstance
+
+	// Stage is the module call that the plan was limited to, or the root
+	// module if the plan covers the whole configuration. Only the changes
+	// for the resources in that module and its descendents are planned.
+	Stage addrs.Module
+
 	// Errored is true if the Changes information is incomplete because
 	// the planning operation failed. An errored plan cannot be applied,
 	// but can be cautiously inspected for debugging purposes.
@@ -71,6 +91,14 @@ type Plan struct {
 	// including anything that would be subject to compatibility constraints.
 	RelevantAttributes []globalref.ResourceAttr
 
+	// CostEstimate is the estimated change in the cost of the infrastructure
+	// if this plan is applied, or nil if no estimator was configured.
+	CostEstimate *CostEstimate
+
+	// BlastRadius summarizes the objects that this plan destroys, or is nil
+	// if it wasn't requested. It isn't saved in plan files.
+	BlastRadius *BlastRadius
+
 	// PrevRunState and PriorState both describe the situation that the plan
 	// was derived from:
 	//
@@ -112,6 +140,22 @@ type Plan struct {
 	// representation of the plan.
 	ExternalReferences []*addrs.Reference
 
+	// EphemeralVariableValues are the values of any ephemeral root module
+	// variables, which are never written into the binary plan file or any
+	// other external representation of the plan. Callers applying a saved
+	// plan must therefore populate this again before applying it.
+	EphemeralVariableValues map[string]cty.Value
+
+	// DiscoveredResources are the remote objects that providers listed for
+	// the managed resource types in the configuration, but which OpenTofu
+	// doesn't manage yet, when the plan was created with the option to
+	// generate import blocks for them.
+	//
+	// These are only a draft for the user to review before adopting any of
+	// them, so as with PlannedState they are never written into the binary
+	// plan file.
+	DiscoveredResources []*DiscoveredResource
+
 	// Timestamp is the record of truth for when the plan happened.
 	Timestamp time.Time
 }
@@ -0,0 +1,153 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package planfile
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/opentofu/opentofu/internal/depsfile"
+)
+
+// The names of the files in a resume bundle. The saved plan file keeps its
+// usual name inside the bundle, whatever it was called when it was applied.
+const (
+	bundlePlanFilename     = "tfplan"
+	bundleManifestFilename = "apply-resume.json"
+	bundleLocksFilename    = dependencyLocksFilename
+)
+
+// WriteResumeBundle writes a resume bundle to the given filename, replacing
+// any existing file. The bundle is a zip archive containing the saved plan
+// file that the given manifest refers to, the manifest itself, and the
+// given dependency locks, so that the apply can be resumed in another
+// working directory or on another machine.
+//
+// The saved plan file is copied as-is, so an encrypted plan remains
+// encrypted inside the bundle.
+func WriteResumeBundle(filename string, manifest *ResumeManifest, locks *depsfile.Locks) error {
+	plan, err := os.ReadFile(manifest.PlanPath)
+	if err != nil {
+		return fmt.Errorf("failed to read saved plan file: %w", err)
+	}
+
+	// The manifest in the bundle refers to the plan file inside the bundle,
+	// since the original path means nothing on another machine.
+	bundled := *manifest
+	bundled.PlanPath = bundlePlanFilename
+	manifestSrc, err := bundled.marshal()
+	if err != nil {
+		return fmt.Errorf("failed to encode resume manifest: %w", err)
+	}
+
+	if locks == nil {
+		locks = depsfile.NewLocks()
+	}
+	locksSrc, diags := depsfile.SaveLocksToBytes(locks)
+	if diags.HasErrors() {
+		return fmt.Errorf("failed to encode dependency locks: %w", diags.Err())
+	}
+
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for _, file := range []struct {
+		name string
+		src  []byte
+	}{
+		{bundlePlanFilename, plan},
+		{bundleManifestFilename, manifestSrc},
+		{bundleLocksFilename, locksSrc},
+	} {
+		w, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create %s in resume bundle: %w", file.name, err)
+		}
+		if _, err := w.Write(file.src); err != nil {
+			return fmt.Errorf("failed to write %s to resume bundle: %w", file.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write resume bundle: %w", err)
+	}
+	return f.Close()
+}
+
+// ExtractResumeBundle extracts the resume bundle at the given filename,
+// written by WriteResumeBundle, into the given directory. It returns the
+// bundled resume manifest, whose PlanPath refers to the extracted saved
+// plan file, and the bundled dependency locks.
+//
+// It returns an error if the extracted plan file doesn't match the plan
+// file that the manifest describes the progress of.
+func ExtractResumeBundle(filename, dir string) (*ResumeManifest, *depsfile.Locks, error) {
+	r, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid resume bundle: %w", err)
+	}
+	defer r.Close()
+
+	files := make(map[string][]byte)
+	for _, file := range r.File {
+		switch file.Name {
+		case bundlePlanFilename, bundleManifestFilename, bundleLocksFilename:
+		default:
+			continue
+		}
+		fr, err := file.Open()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s from resume bundle: %w", file.Name, err)
+		}
+		src, err := io.ReadAll(fr)
+		fr.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s from resume bundle: %w", file.Name, err)
+		}
+		files[file.Name] = src
+	}
+	for _, name := range []string{bundlePlanFilename, bundleManifestFilename, bundleLocksFilename} {
+		if _, ok := files[name]; !ok {
+			return nil, nil, fmt.Errorf("invalid resume bundle: missing %s", name)
+		}
+	}
+
+	manifest, err := parseResumeManifest(files[bundleManifestFilename])
+	if err != nil {
+		return nil, nil, err
+	}
+	locks, diags := depsfile.LoadLocksFromBytes(files[bundleLocksFilename], bundleLocksFilename)
+	if diags.HasErrors() {
+		return nil, nil, fmt.Errorf("invalid resume bundle: %w", diags.Err())
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, err
+	}
+	planPath := filepath.Join(dir, bundlePlanFilename)
+	if err := os.WriteFile(planPath, files[bundlePlanFilename], 0600); err != nil {
+		return nil, nil, err
+	}
+	hash, err := Hash(planPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if hash != manifest.PlanHash {
+		return nil, nil, fmt.Errorf("the saved plan file in the resume bundle doesn't match its resume manifest")
+	}
+
+	manifest.PlanPath = planPath
+	return manifest, locks, nil
+}
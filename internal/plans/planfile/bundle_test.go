@@ -0,0 +1,99 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package planfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/depsfile"
+	"github.com/opentofu/opentofu/internal/getproviders"
+)
+
+func TestResumeBundle_roundtrip(t *testing.T) {
+	dir := t.TempDir()
+	planPath := filepath.Join(dir, "my.tfplan")
+	if err := os.WriteFile(planPath, []byte("not really a plan"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := Hash(planPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := &ResumeManifest{
+		PlanPath: planPath,
+		PlanHash: hash,
+		Lineage:  "abc123",
+		Serial:   4,
+		Completed: []ResumeObject{
+			{Addr: addrs.RootModuleInstance.ResourceInstance(addrs.ManagedResourceMode, "test_thing", "a", addrs.NoKey)},
+		},
+		Pending: []ResumeObject{
+			{Addr: addrs.RootModuleInstance.ResourceInstance(addrs.ManagedResourceMode, "test_thing", "b", addrs.NoKey)},
+		},
+	}
+	locks := depsfile.NewLocks()
+	locks.SetProvider(
+		addrs.NewDefaultProvider("test"),
+		getproviders.MustParseVersion("1.2.3"),
+		getproviders.MustParseVersionConstraints(">= 1.0.0"),
+		nil,
+	)
+
+	bundlePath := filepath.Join(dir, "resume.zip")
+	if err := WriteResumeBundle(bundlePath, manifest, locks); err != nil {
+		t.Fatal(err)
+	}
+
+	extractDir := filepath.Join(t.TempDir(), "extracted")
+	gotManifest, gotLocks, err := ExtractResumeBundle(bundlePath, extractDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantManifest := *manifest
+	wantManifest.PlanPath = filepath.Join(extractDir, "tfplan")
+	if diff := cmp.Diff(&wantManifest, gotManifest); diff != "" {
+		t.Errorf("wrong manifest\n%s", diff)
+	}
+	if !gotLocks.Equal(locks) {
+		t.Errorf("wrong locks\ngot:  %#v\nwant: %#v", gotLocks.AllProviders(), locks.AllProviders())
+	}
+	got, err := os.ReadFile(gotManifest.PlanPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "not really a plan" {
+		t.Errorf("wrong extracted plan file content %q", got)
+	}
+}
+
+func TestResumeBundle_modifiedPlan(t *testing.T) {
+	dir := t.TempDir()
+	planPath := filepath.Join(dir, "my.tfplan")
+	if err := os.WriteFile(planPath, []byte("original plan"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	manifest := &ResumeManifest{
+		PlanPath: planPath,
+		PlanHash: "sha256:0000",
+	}
+
+	bundlePath := filepath.Join(dir, "resume.zip")
+	if err := WriteResumeBundle(bundlePath, manifest, nil); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err := ExtractResumeBundle(bundlePath, filepath.Join(dir, "extracted"))
+	if err == nil {
+		t.Fatal("succeeded; want error")
+	}
+	if got, want := err.Error(), "the saved plan file in the resume bundle doesn't match its resume manifest"; got != want {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package planfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/plans"
+)
+
+// costEstimateFilename is the name of the file in a plan file which records
+// the plan's cost estimate, if it has one. The estimate is for user feedback
+// only, so it's kept out of the tfplan file and its format compatibility
+// rules.
+const costEstimateFilename = "cost-estimate.json"
+
+type costEstimateJSON struct {
+	Currency  string                     `json:"currency"`
+	Resources []resourceCostEstimateJSON `json:"resources"`
+}
+
+type resourceCostEstimateJSON struct {
+	Address          string  `json:"address"`
+	MonthlyCostDelta float64 `json:"monthly_cost_delta"`
+}
+
+func writeCostEstimate(estimate *plans.CostEstimate, w io.Writer) error {
+	raw := costEstimateJSON{
+		Currency:  estimate.Currency,
+		Resources: make([]resourceCostEstimateJSON, 0, len(estimate.Resources)),
+	}
+	for _, rc := range estimate.Resources {
+		raw.Resources = append(raw.Resources, resourceCostEstimateJSON{
+			Address:          rc.Addr.String(),
+			MonthlyCostDelta: rc.MonthlyCostDelta,
+		})
+	}
+	return json.NewEncoder(w).Encode(raw)
+}
+
+func readCostEstimate(r io.Reader) (*plans.CostEstimate, error) {
+	var raw costEstimateJSON
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	ret := &plans.CostEstimate{
+		Currency: raw.Currency,
+	}
+	for _, rc := range raw.Resources {
+		addr, diags := addrs.ParseAbsResourceInstanceStr(rc.Address)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("invalid resource instance address %q: %w", rc.Address, diags.Err())
+		}
+		ret.Resources = append(ret.Resources, plans.ResourceCostEstimate{
+			Addr:             addr,
+			MonthlyCostDelta: rc.MonthlyCostDelta,
+		})
+	}
+	return ret, nil
+}
@@ -0,0 +1,133 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package planfile
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// encryptedPlanMagic is the prefix of every encrypted plan file, which allows
+// us to recognize one without knowing the key. The version number at the end
+// identifies the key derivation and cipher used for the rest of the file.
+const encryptedPlanMagic = "tofu-encrypted-plan-v1\n"
+
+const (
+	encryptionSaltSize = 16
+	encryptionKeySize  = 32 // AES-256
+
+	// These scrypt parameters are the ones recommended for interactive
+	// logins in the scrypt documentation, which keeps the cost of deriving
+	// a key reasonable for each plan or apply.
+	scryptN = 32768
+	scryptR = 8
+	scryptP = 1
+)
+
+// ErrMissingEncryptionKey is returned when opening an encrypted plan file
+// without providing an Encryption to decrypt it with.
+var ErrMissingEncryptionKey = errors.New("the plan file is encrypted, but no encryption key was provided")
+
+// Encryption describes how to encrypt and decrypt saved plan files.
+//
+// Saved plan files include a full snapshot of the prior state, along with
+// the planned values, and so they can contain sensitive values. Encrypting
+// them protects those values when plan files are stored or transferred as
+// build artifacts between a plan and an apply.
+//
+// Plan files are encrypted with AES-256-GCM using a key derived from a
+// passphrase using scrypt, with a new random salt and nonce for each file.
+type Encryption struct {
+	passphrase []byte
+}
+
+// NewEncryption returns an Encryption which derives keys from the given
+// passphrase, or nil if the passphrase is empty.
+func NewEncryption(passphrase string) *Encryption {
+	if passphrase == "" {
+		return nil
+	}
+	return &Encryption{passphrase: []byte(passphrase)}
+}
+
+// IsEncrypted returns true if the given bytes appear to be the start of an
+// encrypted plan file.
+func IsEncrypted(src []byte) bool {
+	return bytes.HasPrefix(src, []byte(encryptedPlanMagic))
+}
+
+// seal encrypts the given plan file content, returning the content of the
+// encrypted plan file.
+func (e *Encryption) seal(plaintext []byte) ([]byte, error) {
+	salt := make([]byte, encryptionSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	aead, err := e.aead(salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	header := make([]byte, 0, len(encryptedPlanMagic)+len(salt)+len(nonce))
+	header = append(header, encryptedPlanMagic...)
+	header = append(header, salt...)
+	header = append(header, nonce...)
+
+	// The header is authenticated as additional data, so that tampering
+	// with any part of the file causes decryption to fail.
+	return aead.Seal(header, nonce, plaintext, header), nil
+}
+
+// open decrypts the given encrypted plan file content, returning the
+// content of the original plan file.
+func (e *Encryption) open(src []byte) ([]byte, error) {
+	if !IsEncrypted(src) {
+		return nil, fmt.Errorf("the given file is not an encrypted plan file")
+	}
+	rest := src[len(encryptedPlanMagic):]
+	if len(rest) < encryptionSaltSize {
+		return nil, fmt.Errorf("the encrypted plan file is truncated")
+	}
+	salt := rest[:encryptionSaltSize]
+	aead, err := e.aead(salt)
+	if err != nil {
+		return nil, err
+	}
+	rest = rest[encryptionSaltSize:]
+	if len(rest) < aead.NonceSize() {
+		return nil, fmt.Errorf("the encrypted plan file is truncated")
+	}
+	nonce := rest[:aead.NonceSize()]
+	ciphertext := rest[aead.NonceSize():]
+	header := src[:len(src)-len(ciphertext)]
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt the plan file: the encryption key is incorrect or the file is corrupted")
+	}
+	return plaintext, nil
+}
+
+func (e *Encryption) aead(salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(e.passphrase, salt, scryptN, scryptR, scryptP, encryptionKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive plan encryption key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
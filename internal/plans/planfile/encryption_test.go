@@ -0,0 +1,143 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package planfile
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/opentofu/opentofu/internal/configs/configload"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/states/statefile"
+	tfversion "github.com/opentofu/opentofu/version"
+)
+
+func TestEncryptedRoundtrip(t *testing.T) {
+	fixtureDir := filepath.Join("testdata", "test-config")
+	loader, err := configload.NewLoader(&configload.Config{
+		ModulesDir: filepath.Join(fixtureDir, ".terraform", "modules"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, snapIn, diags := loader.LoadConfigWithSnapshot(fixtureDir)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	stateFileIn := &statefile.File{
+		TerraformVersion: tfversion.SemVer,
+		Serial:           1,
+		Lineage:          "abc123",
+		State:            states.NewState(),
+	}
+	planIn := &plans.Plan{
+		Changes: &plans.Changes{
+			Resources: []*plans.ResourceInstanceChangeSrc{},
+			Outputs:   []*plans.OutputChangeSrc{},
+		},
+		DriftedResources: []*plans.ResourceInstanceChangeSrc{},
+		VariableValues: map[string]plans.DynamicValue{
+			"password": plans.DynamicValue([]byte("hunter2")),
+		},
+		Backend: plans.Backend{
+			Type:      "local",
+			Config:    plans.DynamicValue([]byte("config placeholder")),
+			Workspace: "default",
+		},
+		Checks:       &states.CheckResults{},
+		PrevRunState: stateFileIn.State,
+		PriorState:   stateFileIn.State,
+	}
+
+	planFn := filepath.Join(t.TempDir(), "tfplan")
+	err = Create(planFn, CreateArgs{
+		ConfigSnapshot:       snapIn,
+		PreviousRunStateFile: stateFileIn,
+		StateFile:            stateFileIn,
+		Plan:                 planIn,
+		Encryption:           NewEncryption("correct horse battery staple"),
+	})
+	if err != nil {
+		t.Fatalf("failed to create plan file: %s", err)
+	}
+
+	raw, err := os.ReadFile(planFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(raw) {
+		t.Fatalf("plan file is not encrypted")
+	}
+	if bytes.Contains(raw, []byte("hunter2")) {
+		t.Fatalf("encrypted plan file contains a plaintext variable value")
+	}
+
+	t.Run("correct key", func(t *testing.T) {
+		pr, err := Open(planFn, NewEncryption("correct horse battery staple"))
+		if err != nil {
+			t.Fatalf("failed to open plan file: %s", err)
+		}
+		defer pr.Close()
+
+		planOut, err := pr.ReadPlan()
+		if err != nil {
+			t.Fatalf("failed to read plan: %s", err)
+		}
+		if diff := cmp.Diff(planIn.VariableValues, planOut.VariableValues); diff != "" {
+			t.Errorf("wrong variable values\n%s", diff)
+		}
+
+		snapOut, err := pr.ReadConfigSnapshot()
+		if err != nil {
+			t.Fatalf("failed to read config snapshot: %s", err)
+		}
+		if diff := cmp.Diff(snapIn, snapOut); diff != "" {
+			t.Errorf("wrong config snapshot\n%s", diff)
+		}
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		_, err := OpenWrapped(planFn, nil)
+		if !errors.Is(err, ErrMissingEncryptionKey) {
+			t.Fatalf("wrong error: %v", err)
+		}
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		_, err := Open(planFn, NewEncryption("incorrect"))
+		if err == nil {
+			t.Fatal("succeeded; want error")
+		}
+		var ulp *ErrUnusableLocalPlan
+		if !errors.As(err, &ulp) {
+			t.Fatalf("wrong error type %T: %s", err, err)
+		}
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		tampered := bytes.Clone(raw)
+		tampered[len(tampered)-1] ^= 0xff
+		tamperedFn := filepath.Join(t.TempDir(), "tfplan")
+		if err := os.WriteFile(tamperedFn, tampered, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Open(tamperedFn, NewEncryption("correct horse battery staple")); err == nil {
+			t.Fatal("succeeded; want error")
+		}
+	})
+}
+
+func TestNewEncryption_empty(t *testing.T) {
+	if enc := NewEncryption(""); enc != nil {
+		t.Fatalf("got non-nil encryption for empty passphrase")
+	}
+}
@@ -67,6 +67,15 @@ func TestRoundtrip(t *testing.T) {
 			Workspace: "default",
 		},
 		Checks: &states.CheckResults{},
+		CostEstimate: &plans.CostEstimate{
+			Currency: "USD",
+			Resources: []plans.ResourceCostEstimate{
+				{
+					Addr:             addrs.Resource{Mode: addrs.ManagedResourceMode, Type: "test_thing", Name: "a"}.Instance(addrs.IntKey(0)).Absolute(addrs.RootModuleInstance),
+					MonthlyCostDelta: 12.5,
+				},
+			},
+		},
 
 		// Due to some historical oddities in how we've changed modelling over
 		// time, we also include the states (without the corresponding file
@@ -101,7 +110,7 @@ func TestRoundtrip(t *testing.T) {
 		t.Fatalf("failed to create plan file: %s", err)
 	}
 
-	wpf, err := OpenWrapped(planFn)
+	wpf, err := OpenWrapped(planFn, nil)
 	if err != nil {
 		t.Fatalf("failed to open plan file for reading: %s", err)
 	}
@@ -179,14 +188,14 @@ func TestRoundtrip(t *testing.T) {
 func TestWrappedError(t *testing.T) {
 	// Open something that isn't a cloud or local planfile: should error
 	wrongFile := "not a valid zip file"
-	_, err := OpenWrapped(filepath.Join("testdata", "test-config", "root.tf"))
+	_, err := OpenWrapped(filepath.Join("testdata", "test-config", "root.tf"), nil)
 	if !strings.Contains(err.Error(), wrongFile) {
 		t.Fatalf("expected  %q, got %q", wrongFile, err)
 	}
 
 	// Open something that doesn't exist: should error
 	missingFile := "no such file or directory"
-	_, err = OpenWrapped(filepath.Join("testdata", "absent.tfplan"))
+	_, err = OpenWrapped(filepath.Join("testdata", "absent.tfplan"), nil)
 	if !strings.Contains(err.Error(), missingFile) {
 		t.Fatalf("expected  %q, got %q", missingFile, err)
 	}
@@ -194,7 +203,7 @@ func TestWrappedError(t *testing.T) {
 
 func TestWrappedCloud(t *testing.T) {
 	// Loading valid cloud plan results in a wrapped cloud plan
-	wpf, err := OpenWrapped(filepath.Join("testdata", "cloudplan.json"))
+	wpf, err := OpenWrapped(filepath.Join("testdata", "cloudplan.json"), nil)
 	if err != nil {
 		t.Fatalf("failed to open valid cloud plan: %s", err)
 	}
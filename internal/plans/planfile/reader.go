@@ -48,14 +48,28 @@ func (e *ErrUnusableLocalPlan) Unwrap() error {
 // be used to access the individual portions of the file for further
 // processing.
 type Reader struct {
-	zip *zip.ReadCloser
+	zip *zip.Reader
+
+	// closer is the file the archive is being read from, if any. It's nil
+	// for an encrypted plan file, which we decrypt into memory.
+	closer io.Closer
 }
 
 // Open creates a Reader for the file at the given filename, or returns an error
 // if the file doesn't seem to be a planfile. NOTE: Most commands that accept a
 // plan file should use OpenWrapped instead, so they can support both local and
 // cloud plan files.
-func Open(filename string) (*Reader, error) {
+//
+// If the plan file is encrypted then enc must provide the settings it was
+// encrypted with, or Open will return an error. enc may be nil if the
+// caller has no plan encryption settings.
+func Open(filename string, enc *Encryption) (*Reader, error) {
+	if encrypted, err := sniffEncrypted(filename); err != nil {
+		return nil, err
+	} else if encrypted {
+		return openEncrypted(filename, enc)
+	}
+
 	r, err := zip.OpenReader(filename)
 	if err != nil {
 		// To give a better error message, we'll sniff to see if this looks
@@ -68,6 +82,52 @@ func Open(filename string) (*Reader, error) {
 		return nil, err
 	}
 
+	ret, err := newReader(&r.Reader)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	ret.closer = r
+	return ret, nil
+}
+
+// sniffEncrypted returns true if the file at the given filename starts like
+// an encrypted plan file.
+func sniffEncrypted(filename string) (bool, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, len(encryptedPlanMagic))
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false, err
+	}
+	return IsEncrypted(buf[:n]), nil
+}
+
+func openEncrypted(filename string, enc *Encryption) (*Reader, error) {
+	if enc == nil {
+		return nil, errUnusable(ErrMissingEncryptionKey)
+	}
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := enc.open(src)
+	if err != nil {
+		return nil, errUnusable(err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(plaintext), int64(len(plaintext)))
+	if err != nil {
+		return nil, errUnusable(fmt.Errorf("the decrypted plan file is invalid: %w", err))
+	}
+	return newReader(r)
+}
+
+func newReader(r *zip.Reader) (*Reader, error) {
 	// Sniff to make sure this looks like a plan file, as opposed to any other
 	// random zip file the user might have around.
 	var planFile *zip.File
@@ -143,6 +203,21 @@ func (r *Reader) ReadPlan() (*plans.Plan, error) {
 	ret.PrevRunState = prevRunStateFile.State
 	ret.PriorState = priorStateFile.State
 
+	for _, file := range r.zip.File {
+		if file.Name != costEstimateFilename {
+			continue
+		}
+		cr, err := file.Open()
+		if err != nil {
+			return nil, errUnusable(fmt.Errorf("failed to retrieve cost estimate from plan file: %w", err))
+		}
+		defer cr.Close()
+		ret.CostEstimate, err = readCostEstimate(cr)
+		if err != nil {
+			return nil, errUnusable(fmt.Errorf("failed to read cost estimate from plan file: %w", err))
+		}
+	}
+
 	return ret, nil
 }
 
@@ -188,7 +263,7 @@ func (r *Reader) ReadPrevStateFile() (*statefile.File, error) {
 // This is a lower-level alternative to ReadConfig that just extracts the
 // source files, without attempting to parse them.
 func (r *Reader) ReadConfigSnapshot() (*configload.Snapshot, error) {
-	return readConfigSnapshot(&r.zip.Reader)
+	return readConfigSnapshot(r.zip)
 }
 
 // ReadConfig reads the configuration embedded in the plan file.
@@ -263,5 +338,8 @@ func (r *Reader) ReadDependencyLocks() (*depsfile.Locks, tfdiags.Diagnostics) {
 
 // Close closes the file, after which no other operations may be performed.
 func (r *Reader) Close() error {
-	return r.zip.Close()
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
 }
@@ -0,0 +1,176 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package planfile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+// resumeManifestVersion is the format version of resume manifests, which
+// must be incremented if the format changes incompatibly.
+const resumeManifestVersion = 1
+
+// ResumeManifest records the progress of an apply of a saved plan file which
+// failed partway through, so that the remaining changes can be applied later
+// without creating a new plan.
+type ResumeManifest struct {
+	// PlanPath is the path of the saved plan file that was being applied,
+	// and PlanHash is the result of Hash for that file, which allows
+	// detecting if the file was modified or replaced since.
+	PlanPath string
+	PlanHash string
+
+	// Lineage and Serial identify the state snapshot that was written at
+	// the end of the partial apply. The apply can be resumed only if the
+	// latest state snapshot is still the same one.
+	Lineage string
+	Serial  uint64
+
+	// Completed and Pending are the resource instance objects with planned
+	// changes that were and were not successfully applied, respectively.
+	Completed []ResumeObject
+	Pending   []ResumeObject
+}
+
+// ResumeObject identifies a resource instance object with a planned change
+// in a ResumeManifest.
+type ResumeObject struct {
+	Addr       addrs.AbsResourceInstance
+	DeposedKey states.DeposedKey
+}
+
+// String returns a string representation of the object for use in UI.
+func (o ResumeObject) String() string {
+	if o.DeposedKey == states.NotDeposed {
+		return o.Addr.String()
+	}
+	return fmt.Sprintf("%s (deposed object %s)", o.Addr, o.DeposedKey)
+}
+
+type resumeManifestJSON struct {
+	Version   int                  `json:"version"`
+	PlanPath  string               `json:"plan_path"`
+	PlanHash  string               `json:"plan_hash"`
+	Lineage   string               `json:"lineage"`
+	Serial    uint64               `json:"serial"`
+	Completed []resumeManifestNode `json:"completed"`
+	Pending   []resumeManifestNode `json:"pending"`
+}
+
+type resumeManifestNode struct {
+	Address    string `json:"address"`
+	DeposedKey string `json:"deposed,omitempty"`
+}
+
+// Hash returns a string which uniquely identifies the content of the plan
+// file at the given path.
+func Hash(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// LoadResumeManifest reads a resume manifest previously written by
+// ResumeManifest.Save.
+func LoadResumeManifest(filename string) (*ResumeManifest, error) {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return parseResumeManifest(src)
+}
+
+func parseResumeManifest(src []byte) (*ResumeManifest, error) {
+	var raw resumeManifestJSON
+	if err := json.Unmarshal(src, &raw); err != nil {
+		return nil, fmt.Errorf("invalid resume manifest: %w", err)
+	}
+	if raw.Version != resumeManifestVersion {
+		return nil, fmt.Errorf("unsupported resume manifest version %d", raw.Version)
+	}
+
+	ret := &ResumeManifest{
+		PlanPath: raw.PlanPath,
+		PlanHash: raw.PlanHash,
+		Lineage:  raw.Lineage,
+		Serial:   raw.Serial,
+	}
+	var err error
+	if ret.Completed, err = decodeResumeManifestNodes(raw.Completed); err != nil {
+		return nil, err
+	}
+	if ret.Pending, err = decodeResumeManifestNodes(raw.Pending); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Save writes the manifest to the given file, replacing any existing file
+// and creating its parent directory if necessary.
+func (m *ResumeManifest) Save(filename string) error {
+	src, err := m.marshal()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, src, 0600)
+}
+
+func (m *ResumeManifest) marshal() ([]byte, error) {
+	raw := resumeManifestJSON{
+		Version:   resumeManifestVersion,
+		PlanPath:  m.PlanPath,
+		PlanHash:  m.PlanHash,
+		Lineage:   m.Lineage,
+		Serial:    m.Serial,
+		Completed: encodeResumeManifestNodes(m.Completed),
+		Pending:   encodeResumeManifestNodes(m.Pending),
+	}
+	return json.MarshalIndent(raw, "", "  ")
+}
+
+func encodeResumeManifestNodes(objs []ResumeObject) []resumeManifestNode {
+	ret := make([]resumeManifestNode, len(objs))
+	for i, obj := range objs {
+		ret[i] = resumeManifestNode{
+			Address:    obj.Addr.String(),
+			DeposedKey: string(obj.DeposedKey),
+		}
+	}
+	return ret
+}
+
+func decodeResumeManifestNodes(nodes []resumeManifestNode) ([]ResumeObject, error) {
+	ret := make([]ResumeObject, len(nodes))
+	for i, node := range nodes {
+		addr, diags := addrs.ParseAbsResourceInstanceStr(node.Address)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("invalid resume manifest: invalid resource instance address %q", node.Address)
+		}
+		ret[i] = ResumeObject{
+			Addr:       addr,
+			DeposedKey: states.DeposedKey(node.DeposedKey),
+		}
+	}
+	return ret, nil
+}
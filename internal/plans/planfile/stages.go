@@ -0,0 +1,130 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package planfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+// stageManifestVersion is the format version of stage manifests, which must
+// be incremented if the format changes incompatibly.
+const stageManifestVersion = 1
+
+// StageManifest records which stages of a configuration have been applied
+// in each workspace, so that a configuration rolled out one module at a time
+// has a record of how far the rollout has progressed.
+type StageManifest struct {
+	// Stages are the most recent applies of each stage, ordered by
+	// workspace and then by stage address.
+	Stages []StageRecord
+}
+
+// StageRecord describes the most recent apply of a stage in a workspace.
+type StageRecord struct {
+	Workspace string
+	Stage     addrs.Module
+	AppliedAt time.Time
+
+	// Lineage and Serial identify the state snapshot that was written at
+	// the end of the apply.
+	Lineage string
+	Serial  uint64
+}
+
+type stageManifestJSON struct {
+	Version int               `json:"version"`
+	Stages  []stageRecordJSON `json:"stages"`
+}
+
+type stageRecordJSON struct {
+	Workspace string    `json:"workspace"`
+	Stage     string    `json:"stage"`
+	AppliedAt time.Time `json:"applied_at"`
+	Lineage   string    `json:"lineage,omitempty"`
+	Serial    uint64    `json:"serial,omitempty"`
+}
+
+// LoadStageManifest reads a stage manifest previously written by
+// StageManifest.Save.
+func LoadStageManifest(filename string) (*StageManifest, error) {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw stageManifestJSON
+	if err := json.Unmarshal(src, &raw); err != nil {
+		return nil, fmt.Errorf("invalid stage manifest: %w", err)
+	}
+	if raw.Version != stageManifestVersion {
+		return nil, fmt.Errorf("unsupported stage manifest version %d", raw.Version)
+	}
+
+	ret := &StageManifest{}
+	for _, rawRecord := range raw.Stages {
+		stage, diags := addrs.ParseModuleInstanceStr(rawRecord.Stage)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("invalid stage manifest: invalid stage address %q", rawRecord.Stage)
+		}
+		ret.Stages = append(ret.Stages, StageRecord{
+			Workspace: rawRecord.Workspace,
+			Stage:     stage.Module(),
+			AppliedAt: rawRecord.AppliedAt,
+			Lineage:   rawRecord.Lineage,
+			Serial:    rawRecord.Serial,
+		})
+	}
+	return ret, nil
+}
+
+// Record adds the given record to the manifest, replacing any earlier
+// record for the same stage in the same workspace.
+func (m *StageManifest) Record(record StageRecord) {
+	for i, existing := range m.Stages {
+		if existing.Workspace == record.Workspace && existing.Stage.Equal(record.Stage) {
+			m.Stages[i] = record
+			return
+		}
+	}
+	m.Stages = append(m.Stages, record)
+	sort.SliceStable(m.Stages, func(i, j int) bool {
+		if m.Stages[i].Workspace != m.Stages[j].Workspace {
+			return m.Stages[i].Workspace < m.Stages[j].Workspace
+		}
+		return m.Stages[i].Stage.String() < m.Stages[j].Stage.String()
+	})
+}
+
+// Save writes the manifest to the given file, replacing any existing file
+// and creating its parent directory if necessary.
+func (m *StageManifest) Save(filename string) error {
+	raw := stageManifestJSON{
+		Version: stageManifestVersion,
+		Stages:  make([]stageRecordJSON, len(m.Stages)),
+	}
+	for i, record := range m.Stages {
+		raw.Stages[i] = stageRecordJSON{
+			Workspace: record.Workspace,
+			Stage:     record.Stage.String(),
+			AppliedAt: record.AppliedAt.UTC(),
+			Lineage:   record.Lineage,
+			Serial:    record.Serial,
+		}
+	}
+	src, err := json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, src, 0644)
+}
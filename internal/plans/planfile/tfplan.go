@@ -221,6 +221,22 @@ func readTfplan(r io.Reader) (*plans.Plan, error) {
 		plan.TargetAddrs = append(plan.TargetAddrs, target.Subject)
 	}
 
+	for _, rawExcludeAddr := range rawPlan.ExcludeAddrs {
+		if addrs.IsTargetGlob(rawExcludeAddr) {
+			glob, diags := addrs.ParseTargetGlob(rawExcludeAddr)
+			if diags.HasErrors() {
+				return nil, fmt.Errorf("plan contains invalid exclude address %q: %w", rawExcludeAddr, diags.Err())
+			}
+			plan.ExcludeAddrs = append(plan.ExcludeAddrs, glob)
+			continue
+		}
+		exclude, diags := addrs.ParseTargetStr(rawExcludeAddr)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("plan contains invalid exclude address %q: %w", rawExcludeAddr, diags.Err())
+		}
+		plan.ExcludeAddrs = append(plan.ExcludeAddrs, exclude.Subject)
+	}
+
 	for _, rawReplaceAddr := range rawPlan.ForceReplaceAddrs {
 		addr, diags := addrs.ParseAbsResourceInstanceStr(rawReplaceAddr)
 		if diags.HasErrors() {
@@ -229,6 +245,32 @@ func readTfplan(r io.Reader) (*plans.Plan, error) {
 		plan.ForceReplaceAddrs = append(plan.ForceReplaceAddrs, addr)
 	}
 
+	for _, rawDeferredAddr := range rawPlan.DeferredResources {
+		addr, diags := addrs.ParseAbsResourceStr(rawDeferredAddr)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("plan contains invalid deferred resource address %q: %w", rawDeferredAddr, diags.Err())
+		}
+		plan.DeferredResources = append(plan.DeferredResources, addr)
+	}
+
+	for _, rawDeferredAddr := range rawPlan.DeferredResourceInstances {
+		addr, diags := addrs.ParseAbsResourceInstanceStr(rawDeferredAddr)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("plan contains invalid deferred resource instance address %q: %w", rawDeferredAddr, diags.Err())
+		}
+		plan.DeferredResourceInstances = append(plan.DeferredResourceInstances, addr)
+	}
+
+	if rawPlan.Stage != "" {
+		// A stage is a module call, so its address has the same syntax as
+		// a module instance without any instance keys.
+		stage, diags := addrs.ParseModuleInstanceStr(rawPlan.Stage)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("plan contains invalid stage address %q: %w", rawPlan.Stage, diags.Err())
+		}
+		plan.Stage = stage.Module()
+	}
+
 	for name, rawVal := range rawPlan.Variables {
 		val, err := valueFromTfplan(rawVal)
 		if err != nil {
@@ -352,6 +394,36 @@ func resourceChangeFromTfplan(rawChange *planproto.ResourceInstanceChange) (*pla
 		return nil, fmt.Errorf("resource has invalid action reason %s", rawChange.ActionReason)
 	}
 
+	for _, rawCause := range rawChange.ChangeCauses {
+		cause := plans.AttributeChangeCause{
+			Attribute: rawCause.Attribute,
+			Filename:  rawCause.Filename,
+			Line:      int(rawCause.Line),
+		}
+		switch rawCause.Cause {
+		case planproto.ChangeCause_NO_CAUSE:
+			cause.Cause = plans.ChangeCauseNone
+		case planproto.ChangeCause_CAUSE_CONFIG:
+			cause.Cause = plans.ChangeCauseConfig
+		case planproto.ChangeCause_CAUSE_UPSTREAM:
+			cause.Cause = plans.ChangeCauseUpstream
+		case planproto.ChangeCause_CAUSE_DRIFT:
+			cause.Cause = plans.ChangeCauseDrift
+		case planproto.ChangeCause_CAUSE_REPLACEMENT:
+			cause.Cause = plans.ChangeCauseReplacement
+		default:
+			return nil, fmt.Errorf("resource has invalid change cause %s for %q", rawCause.Cause, rawCause.Attribute)
+		}
+		for _, rawAddr := range rawCause.Upstream {
+			addr, diags := addrs.ParseAbsResourceStr(rawAddr)
+			if diags.HasErrors() {
+				return nil, fmt.Errorf("resource has invalid upstream address %q: %w", rawAddr, diags.Err())
+			}
+			cause.Upstream = append(cause.Upstream, addr)
+		}
+		ret.ChangeCauses = append(ret.ChangeCauses, cause)
+	}
+
 	if len(rawChange.Private) != 0 {
 		ret.Private = rawChange.Private
 	}
@@ -605,10 +677,26 @@ func writeTfplan(plan *plans.Plan, w io.Writer) error {
 		rawPlan.TargetAddrs = append(rawPlan.TargetAddrs, targetAddr.String())
 	}
 
+	for _, excludeAddr := range plan.ExcludeAddrs {
+		rawPlan.ExcludeAddrs = append(rawPlan.ExcludeAddrs, excludeAddr.String())
+	}
+
 	for _, replaceAddr := range plan.ForceReplaceAddrs {
 		rawPlan.ForceReplaceAddrs = append(rawPlan.ForceReplaceAddrs, replaceAddr.String())
 	}
 
+	for _, deferredAddr := range plan.DeferredResources {
+		rawPlan.DeferredResources = append(rawPlan.DeferredResources, deferredAddr.String())
+	}
+
+	for _, deferredAddr := range plan.DeferredResourceInstances {
+		rawPlan.DeferredResourceInstances = append(rawPlan.DeferredResourceInstances, deferredAddr.String())
+	}
+
+	if !plan.Stage.IsRoot() {
+		rawPlan.Stage = plan.Stage.String()
+	}
+
 	for name, val := range plan.VariableValues {
 		rawPlan.Variables[name] = valueToTfplan(val)
 	}
@@ -745,6 +833,32 @@ func resourceChangeToTfplan(change *plans.ResourceInstanceChangeSrc) (*planproto
 		return nil, fmt.Errorf("resource %s has unsupported action reason %s", change.Addr, change.ActionReason)
 	}
 
+	for _, cause := range change.ChangeCauses {
+		rawCause := &planproto.AttributeChangeCause{
+			Attribute: cause.Attribute,
+			Filename:  cause.Filename,
+			Line:      int64(cause.Line),
+		}
+		switch cause.Cause {
+		case plans.ChangeCauseNone:
+			rawCause.Cause = planproto.ChangeCause_NO_CAUSE
+		case plans.ChangeCauseConfig:
+			rawCause.Cause = planproto.ChangeCause_CAUSE_CONFIG
+		case plans.ChangeCauseUpstream:
+			rawCause.Cause = planproto.ChangeCause_CAUSE_UPSTREAM
+		case plans.ChangeCauseDrift:
+			rawCause.Cause = planproto.ChangeCause_CAUSE_DRIFT
+		case plans.ChangeCauseReplacement:
+			rawCause.Cause = planproto.ChangeCause_CAUSE_REPLACEMENT
+		default:
+			return nil, fmt.Errorf("resource %s has unsupported change cause %s for %q", change.Addr, cause.Cause, cause.Attribute)
+		}
+		for _, addr := range cause.Upstream {
+			rawCause.Upstream = append(rawCause.Upstream, addr.String())
+		}
+		ret.ChangeCauses = append(ret.ChangeCauses, rawCause)
+	}
+
 	if len(change.Private) > 0 {
 		ret.Private = change.Private
 	}
@@ -98,6 +98,25 @@ func TestTFPlanRoundTrip(t *testing.T) {
 						cty.GetAttrPath("boop"),
 					),
 					ActionReason: plans.ResourceInstanceReplaceBecauseCannotUpdate,
+					ChangeCauses: []plans.AttributeChangeCause{
+						{
+							Attribute: "boop",
+							Cause:     plans.ChangeCauseUpstream,
+							Filename:  "main.tf",
+							Line:      12,
+							Upstream: []addrs.AbsResource{
+								addrs.Resource{
+									Mode: addrs.ManagedResourceMode,
+									Type: "test_thing",
+									Name: "honk",
+								}.Absolute(addrs.RootModuleInstance),
+							},
+						},
+						{
+							Attribute: "id",
+							Cause:     plans.ChangeCauseReplacement,
+						},
+					},
 				},
 				{
 					Addr: addrs.Resource{
@@ -254,6 +273,25 @@ func TestTFPlanRoundTrip(t *testing.T) {
 				Name: "woot",
 			}.Absolute(addrs.RootModuleInstance),
 		},
+		ExcludeAddrs: []addrs.Targetable{
+			addrs.RootModuleInstance.Child("legacy", addrs.NoKey),
+			mustParseTargetGlob("test_thing.old[*]"),
+		},
+		DeferredResources: []addrs.AbsResource{
+			addrs.Resource{
+				Mode: addrs.ManagedResourceMode,
+				Type: "test_thing",
+				Name: "later",
+			}.Absolute(addrs.RootModuleInstance.Child("child", addrs.StringKey("a"))),
+		},
+		DeferredResourceInstances: []addrs.AbsResourceInstance{
+			addrs.Resource{
+				Mode: addrs.ManagedResourceMode,
+				Type: "test_thing",
+				Name: "dependent",
+			}.Instance(addrs.IntKey(1)).Absolute(addrs.RootModuleInstance.Child("child", addrs.StringKey("a"))),
+		},
+		Stage: addrs.Module{"network", "subnets"},
 		Backend: plans.Backend{
 			Type: "local",
 			Config: mustNewDynamicValue(
@@ -319,6 +357,14 @@ func mustNewDynamicValueStr(val string) plans.DynamicValue {
 	return ret
 }
 
+func mustParseTargetGlob(str string) addrs.TargetGlob {
+	ret, diags := addrs.ParseTargetGlob(str)
+	if diags.HasErrors() {
+		panic(diags.Err())
+	}
+	return ret
+}
+
 // TestTFPlanRoundTripDestroy ensures that encoding and decoding null values for
 // destroy doesn't leave us with any nil values.
 func TestTFPlanRoundTripDestroy(t *testing.T) {
@@ -71,9 +71,12 @@ func NewWrappedCloud(c *cloudplan.SavedPlanBookmark) *WrappedPlanFile {
 // returns an error if the file doesn't seem to be a plan file of either kind.
 // Most consumers should use this and switch behaviors based on the kind of plan
 // they expected, rather than directly using Open.
-func OpenWrapped(filename string) (*WrappedPlanFile, error) {
+//
+// enc provides the settings for decrypting an encrypted local plan file, and
+// may be nil if the caller has no plan encryption settings.
+func OpenWrapped(filename string, enc *Encryption) (*WrappedPlanFile, error) {
 	// First, try to load it as a local planfile.
-	local, localErr := Open(filename)
+	local, localErr := Open(filename, enc)
 	if localErr == nil {
 		return &WrappedPlanFile{local: local}, nil
 	}
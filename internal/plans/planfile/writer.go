@@ -5,7 +5,9 @@ package planfile
 
 import (
 	"archive/zip"
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"time"
 
@@ -42,6 +44,10 @@ type CreateArgs struct {
 	// checked prior to creating the plan, so we can make sure that all of the
 	// same dependencies are still available when applying the plan.
 	DependencyLocks *depsfile.Locks
+
+	// Encryption, if set, causes the plan file to be encrypted. The same
+	// encryption settings must then be used to open the plan file.
+	Encryption *Encryption
 }
 
 // Create creates a new plan file with the given filename, overwriting any
@@ -58,8 +64,28 @@ func Create(filename string, args CreateArgs) error {
 	}
 	defer f.Close()
 
-	zw := zip.NewWriter(f)
-	defer zw.Close()
+	if args.Encryption == nil {
+		return writePlanArchive(f, args)
+	}
+
+	// An encrypted plan file is the encrypted form of the usual archive, so
+	// we need to build the whole archive in memory first.
+	var buf bytes.Buffer
+	if err := writePlanArchive(&buf, args); err != nil {
+		return err
+	}
+	sealed, err := args.Encryption.seal(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to encrypt plan file: %w", err)
+	}
+	_, err = f.Write(sealed)
+	return err
+}
+
+// writePlanArchive writes the zip archive that makes up an unencrypted plan
+// file to the given writer.
+func writePlanArchive(to io.Writer, args CreateArgs) error {
+	zw := zip.NewWriter(to)
 
 	// tfplan file
 	{
@@ -109,6 +135,22 @@ func Create(filename string, args CreateArgs) error {
 		}
 	}
 
+	// cost-estimate.json file, only if the plan has a cost estimate
+	if args.Plan.CostEstimate != nil {
+		w, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     costEstimateFilename,
+			Method:   zip.Deflate,
+			Modified: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create embedded cost estimate file: %w", err)
+		}
+		err = writeCostEstimate(args.Plan.CostEstimate, w)
+		if err != nil {
+			return fmt.Errorf("failed to write cost estimate: %w", err)
+		}
+	}
+
 	// tfconfig directory
 	{
 		err := writeConfigSnapshot(args.ConfigSnapshot, zw)
@@ -138,5 +180,5 @@ func Create(filename string, args CreateArgs) error {
 		}
 	}
 
-	return nil
+	return zw.Close()
 }
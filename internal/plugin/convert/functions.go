@@ -0,0 +1,184 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package convert
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/opentofu/opentofu/internal/providers"
+	proto "github.com/opentofu/opentofu/internal/tfplugin5"
+)
+
+// FunctionDeclsFromProto takes the function declarations from a grpc
+// response and converts them to providers.FunctionDecl.
+func FunctionDeclsFromProto(protoFuncs map[string]*proto.Function) (map[string]providers.FunctionDecl, error) {
+	if len(protoFuncs) == 0 {
+		return nil, nil
+	}
+
+	ret := make(map[string]providers.FunctionDecl, len(protoFuncs))
+	for name, protoFunc := range protoFuncs {
+		decl, err := FunctionDeclFromProto(protoFunc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid declaration for function %q: %w", name, err)
+		}
+		ret[name] = decl
+	}
+	return ret, nil
+}
+
+// FunctionDeclFromProto converts a single function declaration from a grpc
+// response to a providers.FunctionDecl.
+func FunctionDeclFromProto(protoFunc *proto.Function) (providers.FunctionDecl, error) {
+	var ret providers.FunctionDecl
+
+	ret.Summary = protoFunc.Summary
+	ret.Description = protoFunc.Description
+	ret.DescriptionKind = schemaStringKind(protoFunc.DescriptionKind)
+	ret.DeprecationMessage = protoFunc.DeprecationMessage
+
+	if protoFunc.Return == nil {
+		return ret, fmt.Errorf("missing return type")
+	}
+	if err := json.Unmarshal(protoFunc.Return.Type, &ret.ReturnType); err != nil {
+		return ret, fmt.Errorf("invalid return type: %w", err)
+	}
+
+	if len(protoFunc.Parameters) != 0 {
+		ret.Parameters = make([]providers.FunctionParam, len(protoFunc.Parameters))
+		for i, protoParam := range protoFunc.Parameters {
+			param, err := functionParamFromProto(protoParam)
+			if err != nil {
+				return ret, fmt.Errorf("invalid parameter %d (%q): %w", i, protoParam.Name, err)
+			}
+			ret.Parameters[i] = param
+		}
+	}
+
+	if protoFunc.VariadicParameter != nil {
+		param, err := functionParamFromProto(protoFunc.VariadicParameter)
+		if err != nil {
+			return ret, fmt.Errorf("invalid variadic parameter %q: %w", protoFunc.VariadicParameter.Name, err)
+		}
+		ret.VariadicParameter = &param
+	}
+
+	return ret, nil
+}
+
+func functionParamFromProto(protoParam *proto.Function_Parameter) (providers.FunctionParam, error) {
+	ret := providers.FunctionParam{
+		Name:               protoParam.Name,
+		AllowNullValue:     protoParam.AllowNullValue,
+		AllowUnknownValues: protoParam.AllowUnknownValues,
+		Description:        protoParam.Description,
+		DescriptionKind:    schemaStringKind(protoParam.DescriptionKind),
+	}
+	if err := json.Unmarshal(protoParam.Type, &ret.Type); err != nil {
+		return ret, fmt.Errorf("invalid type constraint: %w", err)
+	}
+	return ret, nil
+}
+
+// FunctionDeclsToProto converts function declarations to their
+// representation in a grpc response.
+func FunctionDeclsToProto(decls map[string]providers.FunctionDecl) (map[string]*proto.Function, error) {
+	if len(decls) == 0 {
+		return nil, nil
+	}
+
+	ret := make(map[string]*proto.Function, len(decls))
+	for name, decl := range decls {
+		protoFunc, err := FunctionDeclToProto(decl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid declaration for function %q: %w", name, err)
+		}
+		ret[name] = protoFunc
+	}
+	return ret, nil
+}
+
+// FunctionDeclToProto converts a single function declaration to its
+// representation in a grpc response.
+func FunctionDeclToProto(decl providers.FunctionDecl) (*proto.Function, error) {
+	ret := &proto.Function{
+		Summary:            decl.Summary,
+		Description:        decl.Description,
+		DescriptionKind:    protoStringKind(decl.DescriptionKind),
+		DeprecationMessage: decl.DeprecationMessage,
+	}
+
+	retType, err := json.Marshal(decl.ReturnType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid return type: %w", err)
+	}
+	ret.Return = &proto.Function_Return{Type: retType}
+
+	for i, param := range decl.Parameters {
+		protoParam, err := functionParamToProto(param)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter %d (%q): %w", i, param.Name, err)
+		}
+		ret.Parameters = append(ret.Parameters, protoParam)
+	}
+
+	if decl.VariadicParameter != nil {
+		protoParam, err := functionParamToProto(*decl.VariadicParameter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid variadic parameter %q: %w", decl.VariadicParameter.Name, err)
+		}
+		ret.VariadicParameter = protoParam
+	}
+
+	return ret, nil
+}
+
+func functionParamToProto(param providers.FunctionParam) (*proto.Function_Parameter, error) {
+	ty, err := json.Marshal(param.Type)
+	if err != nil {
+		return nil, fmt.Errorf("invalid type constraint: %w", err)
+	}
+	return &proto.Function_Parameter{
+		Name:               param.Name,
+		Type:               ty,
+		AllowNullValue:     param.AllowNullValue,
+		AllowUnknownValues: param.AllowUnknownValues,
+		Description:        param.Description,
+		DescriptionKind:    protoStringKind(param.DescriptionKind),
+	}, nil
+}
+
+// FunctionErrorFromProto converts an error returned by a provider function
+// to an error, which is a *providers.CallFunctionArgumentError if the error
+// was caused by a particular argument. It returns nil if there was no error.
+func FunctionErrorFromProto(protoErr *proto.FunctionError) error {
+	if protoErr == nil {
+		return nil
+	}
+	if protoErr.FunctionArgument != nil {
+		return &providers.CallFunctionArgumentError{
+			Text:             protoErr.Text,
+			FunctionArgument: int(*protoErr.FunctionArgument),
+		}
+	}
+	return errors.New(protoErr.Text)
+}
+
+// FunctionErrorToProto converts an error from a provider function to its
+// representation in a grpc response.
+func FunctionErrorToProto(err error) *proto.FunctionError {
+	if err == nil {
+		return nil
+	}
+	ret := &proto.FunctionError{
+		Text: err.Error(),
+	}
+	if argErr, ok := err.(*providers.CallFunctionArgumentError); ok {
+		arg := int64(argErr.FunctionArgument)
+		ret.FunctionArgument = &arg
+	}
+	return ret
+}
@@ -0,0 +1,90 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package convert
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/providers"
+	proto "github.com/opentofu/opentofu/internal/tfplugin5"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Test that we can convert function declarations to protobuf types and back
+// again.
+func TestConvertFunctionDecls(t *testing.T) {
+	decls := map[string]providers.FunctionDecl{
+		"arn_parse": {
+			Parameters: []providers.FunctionParam{
+				{
+					Name:            "arn",
+					Type:            cty.String,
+					Description:     "The ARN to parse.",
+					DescriptionKind: configschema.StringMarkdown,
+				},
+			},
+			ReturnType:  cty.Object(map[string]cty.Type{"partition": cty.String, "service": cty.String}),
+			Summary:     "Parses an ARN",
+			Description: "Parses an ARN into its parts.",
+		},
+		"join": {
+			Parameters: []providers.FunctionParam{
+				{Name: "sep", Type: cty.String},
+			},
+			VariadicParameter: &providers.FunctionParam{
+				Name:               "parts",
+				Type:               cty.DynamicPseudoType,
+				AllowNullValue:     true,
+				AllowUnknownValues: true,
+			},
+			ReturnType:         cty.String,
+			DeprecationMessage: "Use the built-in join function instead.",
+		},
+	}
+
+	protoFuncs, err := FunctionDeclsToProto(decls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := FunctionDeclsFromProto(protoFuncs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(decls, got, typeComparer); diff != "" {
+		t.Fatalf("wrong result\n%s", diff)
+	}
+}
+
+func TestConvertFunctionDecl_missingReturn(t *testing.T) {
+	_, err := FunctionDeclFromProto(&proto.Function{})
+	if err == nil {
+		t.Fatal("succeeded; want error")
+	}
+}
+
+func TestConvertFunctionError(t *testing.T) {
+	tests := map[string]error{
+		"nil":      nil,
+		"function": errors.New("failed"),
+		"argument": &providers.CallFunctionArgumentError{
+			Text:             "invalid ARN",
+			FunctionArgument: 2,
+		},
+	}
+
+	for name, want := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := FunctionErrorFromProto(FunctionErrorToProto(want))
+			// The error types have no methods for comparison, but their
+			// Go syntax representations include all of their fields.
+			if gotStr, wantStr := fmt.Sprintf("%#v", got), fmt.Sprintf("%#v", want); gotStr != wantStr {
+				t.Fatalf("wrong result\ngot:  %s\nwant: %s", gotStr, wantStr)
+			}
+		})
+	}
+}
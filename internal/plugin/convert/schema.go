@@ -34,6 +34,7 @@ func ConfigSchemaToProto(b *configschema.Block) *proto.Schema_Block {
 			Required:        a.Required,
 			Sensitive:       a.Sensitive,
 			Deprecated:      a.Deprecated,
+			Ephemeral:       a.Ephemeral,
 		}
 
 		ty, err := json.Marshal(a.Type)
@@ -117,6 +118,7 @@ func ProtoToConfigSchema(b *proto.Schema_Block) *configschema.Block {
 			Computed:        a.Computed,
 			Sensitive:       a.Sensitive,
 			Deprecated:      a.Deprecated,
+			Ephemeral:       a.Ephemeral,
 		}
 
 		if err := json.Unmarshal(a.Type, &attr.Type); err != nil {
@@ -33,6 +33,12 @@ func TestConvertSchemaBlocks(t *testing.T) {
 						Type:     []byte(`["list","bool"]`),
 						Computed: true,
 					},
+					{
+						Name:      "ephemeral",
+						Type:      []byte(`"string"`),
+						Optional:  true,
+						Ephemeral: true,
+					},
 					{
 						Name:     "optional",
 						Type:     []byte(`"string"`),
@@ -57,6 +63,11 @@ func TestConvertSchemaBlocks(t *testing.T) {
 						Type:     cty.List(cty.Bool),
 						Computed: true,
 					},
+					"ephemeral": {
+						Type:      cty.String,
+						Optional:  true,
+						Ephemeral: true,
+					},
 					"optional": {
 						Type:     cty.String,
 						Optional: true,
@@ -205,6 +216,12 @@ func TestConvertProtoSchemaBlocks(t *testing.T) {
 						Type:     []byte(`["list","bool"]`),
 						Computed: true,
 					},
+					{
+						Name:      "ephemeral",
+						Type:      []byte(`"string"`),
+						Optional:  true,
+						Ephemeral: true,
+					},
 					{
 						Name:     "optional",
 						Type:     []byte(`"string"`),
@@ -229,6 +246,11 @@ func TestConvertProtoSchemaBlocks(t *testing.T) {
 						Type:     cty.List(cty.Bool),
 						Computed: true,
 					},
+					"ephemeral": {
+						Type:      cty.String,
+						Optional:  true,
+						Ephemeral: true,
+					},
 					"optional": {
 						Type:     cty.String,
 						Optional: true,
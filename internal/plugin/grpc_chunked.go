@@ -0,0 +1,280 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"sync/atomic"
+
+	proto "github.com/opentofu/opentofu/internal/tfplugin5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	protobuf "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// chunkSize is the size of the chunks that the requests and responses of
+// chunked calls are split into, which is well within gRPC's default message
+// size limit of 4MB.
+const chunkSize = 1 << 20
+
+// chunkedProviderClient is a proto.ProviderClient which, once enabled, makes
+// the calls that carry configuration or state values through CallChunked,
+// so that their requests and responses aren't limited by the gRPC message
+// size limits of OpenTofu or the plugin.
+//
+// It's enabled by GRPCProvider when the provider reports the chunked_calls
+// server capability.
+type chunkedProviderClient struct {
+	proto.ProviderClient
+
+	enabled atomic.Bool
+}
+
+var _ proto.ProviderClient = (*chunkedProviderClient)(nil)
+
+func (c *chunkedProviderClient) PrepareProviderConfig(ctx context.Context, in *proto.PrepareProviderConfig_Request, opts ...grpc.CallOption) (*proto.PrepareProviderConfig_Response, error) {
+	if !c.enabled.Load() {
+		return c.ProviderClient.PrepareProviderConfig(ctx, in, opts...)
+	}
+	out := new(proto.PrepareProviderConfig_Response)
+	if err := c.call(ctx, "PrepareProviderConfig", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chunkedProviderClient) ValidateResourceTypeConfig(ctx context.Context, in *proto.ValidateResourceTypeConfig_Request, opts ...grpc.CallOption) (*proto.ValidateResourceTypeConfig_Response, error) {
+	if !c.enabled.Load() {
+		return c.ProviderClient.ValidateResourceTypeConfig(ctx, in, opts...)
+	}
+	out := new(proto.ValidateResourceTypeConfig_Response)
+	if err := c.call(ctx, "ValidateResourceTypeConfig", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chunkedProviderClient) ValidateDataSourceConfig(ctx context.Context, in *proto.ValidateDataSourceConfig_Request, opts ...grpc.CallOption) (*proto.ValidateDataSourceConfig_Response, error) {
+	if !c.enabled.Load() {
+		return c.ProviderClient.ValidateDataSourceConfig(ctx, in, opts...)
+	}
+	out := new(proto.ValidateDataSourceConfig_Response)
+	if err := c.call(ctx, "ValidateDataSourceConfig", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chunkedProviderClient) UpgradeResourceState(ctx context.Context, in *proto.UpgradeResourceState_Request, opts ...grpc.CallOption) (*proto.UpgradeResourceState_Response, error) {
+	if !c.enabled.Load() {
+		return c.ProviderClient.UpgradeResourceState(ctx, in, opts...)
+	}
+	out := new(proto.UpgradeResourceState_Response)
+	if err := c.call(ctx, "UpgradeResourceState", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chunkedProviderClient) Configure(ctx context.Context, in *proto.Configure_Request, opts ...grpc.CallOption) (*proto.Configure_Response, error) {
+	if !c.enabled.Load() {
+		return c.ProviderClient.Configure(ctx, in, opts...)
+	}
+	out := new(proto.Configure_Response)
+	if err := c.call(ctx, "Configure", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chunkedProviderClient) ReadResource(ctx context.Context, in *proto.ReadResource_Request, opts ...grpc.CallOption) (*proto.ReadResource_Response, error) {
+	if !c.enabled.Load() {
+		return c.ProviderClient.ReadResource(ctx, in, opts...)
+	}
+	out := new(proto.ReadResource_Response)
+	if err := c.call(ctx, "ReadResource", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chunkedProviderClient) PlanResourceChange(ctx context.Context, in *proto.PlanResourceChange_Request, opts ...grpc.CallOption) (*proto.PlanResourceChange_Response, error) {
+	if !c.enabled.Load() {
+		return c.ProviderClient.PlanResourceChange(ctx, in, opts...)
+	}
+	out := new(proto.PlanResourceChange_Response)
+	if err := c.call(ctx, "PlanResourceChange", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chunkedProviderClient) ApplyResourceChange(ctx context.Context, in *proto.ApplyResourceChange_Request, opts ...grpc.CallOption) (*proto.ApplyResourceChange_Response, error) {
+	if !c.enabled.Load() {
+		return c.ProviderClient.ApplyResourceChange(ctx, in, opts...)
+	}
+	out := new(proto.ApplyResourceChange_Response)
+	if err := c.call(ctx, "ApplyResourceChange", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chunkedProviderClient) ImportResourceState(ctx context.Context, in *proto.ImportResourceState_Request, opts ...grpc.CallOption) (*proto.ImportResourceState_Response, error) {
+	if !c.enabled.Load() {
+		return c.ProviderClient.ImportResourceState(ctx, in, opts...)
+	}
+	out := new(proto.ImportResourceState_Response)
+	if err := c.call(ctx, "ImportResourceState", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chunkedProviderClient) ListResources(ctx context.Context, in *proto.ListResources_Request, opts ...grpc.CallOption) (*proto.ListResources_Response, error) {
+	if !c.enabled.Load() {
+		return c.ProviderClient.ListResources(ctx, in, opts...)
+	}
+	out := new(proto.ListResources_Response)
+	if err := c.call(ctx, "ListResources", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chunkedProviderClient) ReadDataSource(ctx context.Context, in *proto.ReadDataSource_Request, opts ...grpc.CallOption) (*proto.ReadDataSource_Response, error) {
+	if !c.enabled.Load() {
+		return c.ProviderClient.ReadDataSource(ctx, in, opts...)
+	}
+	out := new(proto.ReadDataSource_Response)
+	if err := c.call(ctx, "ReadDataSource", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chunkedProviderClient) CallFunction(ctx context.Context, in *proto.CallFunction_Request, opts ...grpc.CallOption) (*proto.CallFunction_Response, error) {
+	if !c.enabled.Load() {
+		return c.ProviderClient.CallFunction(ctx, in, opts...)
+	}
+	out := new(proto.CallFunction_Response)
+	if err := c.call(ctx, "CallFunction", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// call makes the named call through CallChunked, sending the request in
+// chunks and then reassembling the response from the chunks the provider
+// sends back.
+func (c *chunkedProviderClient) call(ctx context.Context, method string, in, out protobuf.Message, opts ...grpc.CallOption) error {
+	req, err := protobuf.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	stream, err := c.ProviderClient.CallChunked(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	// If the provider ends the stream early, sending fails with io.EOF and
+	// the reason is returned when receiving the response below.
+	if err := sendChunks(stream.Send, method, req); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+
+	_, resp, err := recvChunks(stream.Recv)
+	if err != nil {
+		return err
+	}
+	return protobuf.Unmarshal(resp, out)
+}
+
+// ServeChunked implements CallChunked for the given provider server, by
+// reassembling the request of the call from its chunks, making the call,
+// and sending the response back in chunks. The call must be one of the
+// unary calls of the Provider service.
+//
+// A server that wraps another server must implement CallChunked itself,
+// so that chunked calls go through its own methods rather than those of the
+// wrapped server.
+func ServeChunked(srv proto.ProviderServer, stream proto.Provider_CallChunkedServer) error {
+	method, req, err := recvChunks(stream.Recv)
+	if err != nil {
+		return err
+	}
+
+	desc := proto.File_tfplugin5_proto.Services().ByName("Provider").Methods().ByName(protoreflect.Name(method))
+	if desc == nil || desc.IsStreamingClient() || desc.IsStreamingServer() {
+		return status.Errorf(codes.Unimplemented, "method %q can't be called in chunks", method)
+	}
+	reqType, err := protoregistry.GlobalTypes.FindMessageByName(desc.Input().FullName())
+	if err != nil {
+		return status.Errorf(codes.Internal, "unknown request type for %s: %s", method, err)
+	}
+	in := reqType.New().Interface()
+	if err := protobuf.Unmarshal(req, in); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid request for %s: %s", method, err)
+	}
+
+	// The generated server interface has a method for each call, with the
+	// same name as the call.
+	results := reflect.ValueOf(srv).MethodByName(method).Call([]reflect.Value{
+		reflect.ValueOf(stream.Context()),
+		reflect.ValueOf(in),
+	})
+	if err, _ := results[1].Interface().(error); err != nil {
+		return err
+	}
+	resp, err := protobuf.Marshal(results[0].Interface().(protobuf.Message))
+	if err != nil {
+		return status.Errorf(codes.Internal, "invalid response from %s: %s", method, err)
+	}
+	return sendChunks(stream.Send, "", resp)
+}
+
+// sendChunks sends the given serialized message in chunks, naming the
+// method in the first chunk.
+func sendChunks(send func(*proto.CallChunked_Chunk) error, method string, data []byte) error {
+	chunk := &proto.CallChunked_Chunk{Method: method}
+	for {
+		n := min(len(data), chunkSize)
+		chunk.Data, data = data[:n], data[n:]
+		if err := send(chunk); err != nil {
+			return err
+		}
+		if len(data) == 0 {
+			return nil
+		}
+		chunk = &proto.CallChunked_Chunk{}
+	}
+}
+
+// recvChunks reassembles a serialized message from the chunks received
+// until the end of the stream, and returns it along with the method named
+// in the first chunk.
+func recvChunks(recv func() (*proto.CallChunked_Chunk, error)) (method string, data []byte, err error) {
+	for first := true; ; first = false {
+		chunk, err := recv()
+		if errors.Is(err, io.EOF) {
+			return method, data, nil
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		if first {
+			method = chunk.Method
+		}
+		data = append(data, chunk.Data...)
+	}
+}
@@ -5,7 +5,10 @@ package plugin
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"net"
+	"strings"
 	"testing"
 
 	"github.com/opentofu/opentofu/internal/addrs"
@@ -16,6 +19,9 @@ import (
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
 
 	mockproto "github.com/opentofu/opentofu/internal/plugin/mock_proto"
 	proto "github.com/opentofu/opentofu/internal/tfplugin5"
@@ -675,6 +681,105 @@ func TestGRPCProvider_ApplyResourceChange(t *testing.T) {
 		t.Fatalf("expected %q, got %q", expectedPrivate, resp.Private)
 	}
 }
+
+// largeMessageProviderServer echoes the planned state of a change back as
+// the new state, so that its response is as large as its request.
+type largeMessageProviderServer struct {
+	proto.UnimplementedProviderServer
+}
+
+func (s *largeMessageProviderServer) GetSchema(context.Context, *proto.GetProviderSchema_Request) (*proto.GetProviderSchema_Response, error) {
+	return providerProtoSchema(), nil
+}
+
+func (s *largeMessageProviderServer) ApplyResourceChange(_ context.Context, req *proto.ApplyResourceChange_Request) (*proto.ApplyResourceChange_Response, error) {
+	return &proto.ApplyResourceChange_Response{NewState: req.PlannedState}, nil
+}
+
+// chunkedProviderServer is a largeMessageProviderServer which supports
+// chunked calls.
+type chunkedProviderServer struct {
+	largeMessageProviderServer
+}
+
+func (s *chunkedProviderServer) GetSchema(context.Context, *proto.GetProviderSchema_Request) (*proto.GetProviderSchema_Response, error) {
+	resp := providerProtoSchema()
+	resp.ServerCapabilities = &proto.GetProviderSchema_ServerCapabilities{ChunkedCalls: true}
+	return resp, nil
+}
+
+func (s *chunkedProviderServer) CallChunked(stream proto.Provider_CallChunkedServer) error {
+	return ServeChunked(s, stream)
+}
+
+func TestGRPCProvider_ApplyResourceChangeLargeMessage(t *testing.T) {
+	serve := func(t *testing.T, srv proto.ProviderServer, opts ...grpc.ServerOption) *bufconn.Listener {
+		listener := bufconn.Listen(1 << 20)
+		server := grpc.NewServer(opts...)
+		proto.RegisterProviderServer(server, srv)
+		go server.Serve(listener)
+		t.Cleanup(server.Stop)
+		return listener
+	}
+	dial := func(t *testing.T, listener *bufconn.Listener, opts ...grpc.DialOption) *GRPCProvider {
+		opts = append(opts,
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return listener.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		conn, err := grpc.Dial("bufnet", opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return &GRPCProvider{
+			client: &chunkedProviderClient{ProviderClient: proto.NewProviderClient(conn)},
+			ctx:    context.Background(),
+		}
+	}
+
+	// The value is larger than gRPC's default message size limit of 4MB.
+	planned := cty.ObjectVal(map[string]cty.Value{
+		"attr": cty.StringVal(strings.Repeat("x", 5<<20)),
+	})
+	req := providers.ApplyResourceChangeRequest{
+		TypeName:     "resource",
+		PriorState:   cty.NullVal(planned.Type()),
+		PlannedState: planned,
+		Config:       planned,
+	}
+
+	t.Run("default limits", func(t *testing.T) {
+		listener := serve(t, &largeMessageProviderServer{}, grpc.MaxRecvMsgSize(MaxMessageSize))
+		resp := dial(t, listener).ApplyResourceChange(req)
+		checkDiagsHasError(t, resp.Diagnostics)
+		if got, want := resp.Diagnostics[0].Description().Summary, "Plugin message too large"; got != want {
+			t.Fatalf("wrong error %q; want %q", got, want)
+		}
+	})
+
+	t.Run("raised limits", func(t *testing.T) {
+		listener := serve(t, &largeMessageProviderServer{}, grpc.MaxRecvMsgSize(MaxMessageSize))
+		resp := dial(t, listener, GRPCDialOptions...).ApplyResourceChange(req)
+		checkDiags(t, resp.Diagnostics)
+		if !resp.NewState.RawEquals(planned) {
+			t.Fatal("wrong new state")
+		}
+	})
+
+	t.Run("chunked calls", func(t *testing.T) {
+		// Neither side raises the default limits, so the call only
+		// succeeds if the request and response are sent in chunks.
+		listener := serve(t, &chunkedProviderServer{})
+		resp := dial(t, listener).ApplyResourceChange(req)
+		checkDiags(t, resp.Diagnostics)
+		if !resp.NewState.RawEquals(planned) {
+			t.Fatal("wrong new state")
+		}
+	})
+}
+
 func TestGRPCProvider_ApplyResourceChangeJSON(t *testing.T) {
 	client := mockProviderClient(t)
 	p := &GRPCProvider{
@@ -765,6 +870,53 @@ func TestGRPCProvider_ImportResourceState(t *testing.T) {
 		t.Fatal(cmp.Diff(expectedResource, imported, typeComparer, valueComparer, equateEmpty))
 	}
 }
+
+func TestGRPCProvider_ListResources(t *testing.T) {
+	client := mockProviderClient(t)
+	p := &GRPCProvider{
+		client: client,
+	}
+
+	client.EXPECT().ListResources(
+		gomock.Any(),
+		gomock.Any(),
+	).Return(&proto.ListResources_Response{
+		Resources: []*proto.ListResources_ListedResource{
+			{
+				Id: "foo",
+				State: &proto.DynamicValue{
+					Msgpack: []byte("\x81\xa4attr\xa3bar"),
+				},
+			},
+			{
+				// The state of a listed object is optional.
+				Id: "baz",
+			},
+		},
+	}, nil)
+
+	resp := p.ListResources(providers.ListResourcesRequest{
+		TypeName: "resource",
+	})
+
+	checkDiags(t, resp.Diagnostics)
+
+	expected := []providers.ListedResource{
+		{
+			ID: "foo",
+			State: cty.ObjectVal(map[string]cty.Value{
+				"attr": cty.StringVal("bar"),
+			}),
+		},
+		{
+			ID: "baz",
+		},
+	}
+	if !cmp.Equal(expected, resp.Resources, typeComparer, valueComparer, equateEmpty) {
+		t.Fatal(cmp.Diff(expected, resp.Resources, typeComparer, valueComparer, equateEmpty))
+	}
+}
+
 func TestGRPCProvider_ImportResourceStateJSON(t *testing.T) {
 	client := mockProviderClient(t)
 	p := &GRPCProvider{
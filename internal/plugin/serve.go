@@ -6,6 +6,7 @@ package plugin
 import (
 	"github.com/hashicorp/go-plugin"
 	proto "github.com/opentofu/opentofu/internal/tfplugin5"
+	"google.golang.org/grpc"
 )
 
 const (
@@ -36,6 +37,24 @@ var Handshake = plugin.HandshakeConfig{
 	MagicCookieValue: "d602bf8f470bc67ca7faa0386276bbdd4330efaf76d1a219cb4d6991ca9872b2",
 }
 
+// MaxMessageSize is the largest gRPC message that OpenTofu sends to or
+// accepts from a plugin. Unless the plugin supports chunked calls, the
+// plugin protocol sends each resource's values in a single message, so
+// gRPC's default limit of 4MB is too small for resources with large
+// attribute values, such as the content of a file.
+const MaxMessageSize = 256 << 20
+
+// GRPCDialOptions are the options for the gRPC connections to plugins, which
+// raise the message size limits to MaxMessageSize. The plugin also limits
+// the size of the messages it accepts, so a larger request might still be
+// rejected by the plugin.
+var GRPCDialOptions = []grpc.DialOption{
+	grpc.WithDefaultCallOptions(
+		grpc.MaxCallRecvMsgSize(MaxMessageSize),
+		grpc.MaxCallSendMsgSize(MaxMessageSize),
+	),
+}
+
 type GRPCProviderFunc func() proto.ProviderServer
 type GRPCProvisionerFunc func() proto.ProvisionerServer
 
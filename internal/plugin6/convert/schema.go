@@ -35,6 +35,7 @@ func ConfigSchemaToProto(b *configschema.Block) *proto.Schema_Block {
 			Required:        a.Required,
 			Sensitive:       a.Sensitive,
 			Deprecated:      a.Deprecated,
+			Ephemeral:       a.Ephemeral,
 		}
 
 		if a.Type != cty.NilType {
@@ -123,6 +124,7 @@ func ProtoToConfigSchema(b *proto.Schema_Block) *configschema.Block {
 			Computed:        a.Computed,
 			Sensitive:       a.Sensitive,
 			Deprecated:      a.Deprecated,
+			Ephemeral:       a.Ephemeral,
 		}
 
 		if a.Type != nil {
@@ -213,6 +215,7 @@ func protoObjectToConfigSchema(b *proto.Schema_Object) *configschema.Object {
 			Computed:        a.Computed,
 			Sensitive:       a.Sensitive,
 			Deprecated:      a.Deprecated,
+			Ephemeral:       a.Ephemeral,
 		}
 
 		if a.Type != nil {
@@ -276,6 +279,7 @@ func configschemaObjectToProto(b *configschema.Object) *proto.Schema_Object {
 			Required:        a.Required,
 			Sensitive:       a.Sensitive,
 			Deprecated:      a.Deprecated,
+			Ephemeral:       a.Ephemeral,
 		}
 
 		if a.Type != cty.NilType {
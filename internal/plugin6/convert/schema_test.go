@@ -33,6 +33,12 @@ func TestConvertSchemaBlocks(t *testing.T) {
 						Type:     []byte(`["list","bool"]`),
 						Computed: true,
 					},
+					{
+						Name:      "ephemeral",
+						Type:      []byte(`"string"`),
+						Optional:  true,
+						Ephemeral: true,
+					},
 					{
 						Name:     "optional",
 						Type:     []byte(`"string"`),
@@ -168,6 +174,11 @@ func TestConvertSchemaBlocks(t *testing.T) {
 						Type:     cty.List(cty.Bool),
 						Computed: true,
 					},
+					"ephemeral": {
+						Type:      cty.String,
+						Optional:  true,
+						Ephemeral: true,
+					},
 					"optional": {
 						Type:     cty.String,
 						Optional: true,
@@ -410,6 +421,12 @@ func TestConvertProtoSchemaBlocks(t *testing.T) {
 						Type:     []byte(`["list","bool"]`),
 						Computed: true,
 					},
+					{
+						Name:      "ephemeral",
+						Type:      []byte(`"string"`),
+						Optional:  true,
+						Ephemeral: true,
+					},
 					{
 						Name:     "optional",
 						Type:     []byte(`"string"`),
@@ -434,6 +451,11 @@ func TestConvertProtoSchemaBlocks(t *testing.T) {
 						Type:     cty.List(cty.Bool),
 						Computed: true,
 					},
+					"ephemeral": {
+						Type:      cty.String,
+						Optional:  true,
+						Ephemeral: true,
+					},
 					"optional": {
 						Type:     cty.String,
 						Optional: true,
@@ -8,6 +8,8 @@ import (
 	"path"
 	"runtime"
 
+	"github.com/hashicorp/hcl/v2"
+	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -48,18 +50,32 @@ func grpcErr(err error) (diags tfdiags.Diagnostics) {
 	case codes.Unavailable:
 		// This case is when the plugin has stopped running for some reason,
 		// and is usually the result of a crash.
-		diags = diags.Append(tfdiags.Sourceless(
-			tfdiags.Error,
-			"Plugin did not respond",
-			fmt.Sprintf("The plugin encountered an error, and failed to respond to the %s call. "+
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Plugin did not respond",
+			Detail: fmt.Sprintf("The plugin encountered an error, and failed to respond to the %s call. "+
 				"The plugin logs may contain more details.", requestName),
-		))
+			Extra: providers.PluginCrashed{},
+		})
 	case codes.Canceled:
 		diags = diags.Append(tfdiags.Sourceless(
 			tfdiags.Error,
 			"Request cancelled",
 			fmt.Sprintf("The %s request was cancelled.", requestName),
 		))
+	case codes.ResourceExhausted:
+		// This case is when a request or response exceeded the gRPC
+		// message size limit of either OpenTofu or the plugin, which can
+		// only happen for plugins that don't support chunked calls.
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Plugin message too large",
+			fmt.Sprintf("The %s request or its response was too large to send to or from the plugin: %s.\n\n"+
+				"This plugin doesn't support chunked calls, so it sends and receives each resource's values in a single message, and plugins limit the size of the messages they accept. "+
+				"This usually means that an attribute has a very large value, such as the content of a large file. "+
+				"Consider reducing the size of the value, for example by passing the path of a file rather than its content, if the provider supports that, or upgrading to a version of the provider that supports plugin protocol 5.5 or 6.5 with chunked calls.",
+				requestName, status.Convert(err).Message()),
+		))
 	case codes.Unimplemented:
 		diags = diags.Append(tfdiags.Sourceless(
 			tfdiags.Error,
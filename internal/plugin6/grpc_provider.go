@@ -32,7 +32,7 @@ type GRPCProviderPlugin struct {
 
 func (p *GRPCProviderPlugin) GRPCClient(ctx context.Context, broker *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
 	return &GRPCProvider{
-		client: proto6.NewProviderClient(c),
+		client: &chunkedProviderClient{ProviderClient: proto6.NewProviderClient(c)},
 		ctx:    ctx,
 	}, nil
 }
@@ -81,6 +81,7 @@ func (p *GRPCProvider) GetProviderSchema() (resp providers.GetProviderSchemaResp
 	// check the global cache
 	if !p.Addr.IsZero() {
 		if resp, ok := providers.SchemaCache.Get(p.Addr); ok {
+			p.useChunkedCalls(resp.ServerCapabilities)
 			return resp
 		}
 	}
@@ -135,10 +136,20 @@ func (p *GRPCProvider) GetProviderSchema() (resp providers.GetProviderSchemaResp
 		resp.DataSources[name] = convert.ProtoToProviderSchema(data)
 	}
 
+	funcs, err := convert.FunctionDeclsFromProto(protoResp.Functions)
+	if err != nil {
+		resp.Diagnostics = resp.Diagnostics.Append(err)
+		return resp
+	}
+	resp.Functions = funcs
+
 	if protoResp.ServerCapabilities != nil {
 		resp.ServerCapabilities.PlanDestroy = protoResp.ServerCapabilities.PlanDestroy
 		resp.ServerCapabilities.GetProviderSchemaOptional = protoResp.ServerCapabilities.GetProviderSchemaOptional
+		resp.ServerCapabilities.ListResources = protoResp.ServerCapabilities.ListResources
+		resp.ServerCapabilities.ChunkedCalls = protoResp.ServerCapabilities.ChunkedCalls
 	}
+	p.useChunkedCalls(resp.ServerCapabilities)
 
 	// set the global cache if we can
 	if !p.Addr.IsZero() && resp.ServerCapabilities.GetProviderSchemaOptional {
@@ -152,6 +163,14 @@ func (p *GRPCProvider) GetProviderSchema() (resp providers.GetProviderSchemaResp
 	return resp
 }
 
+// useChunkedCalls makes the client send the calls that carry configuration
+// or state values through CallChunked, if the provider supports it.
+func (p *GRPCProvider) useChunkedCalls(capabilities providers.ServerCapabilities) {
+	if c, ok := p.client.(*chunkedProviderClient); ok && capabilities.ChunkedCalls {
+		c.enabled.Store(true)
+	}
+}
+
 func (p *GRPCProvider) ValidateProviderConfig(r providers.ValidateProviderConfigRequest) (resp providers.ValidateProviderConfigResponse) {
 	logger.Trace("GRPCProvider.v6: ValidateProviderConfig")
 
@@ -603,6 +622,52 @@ func (p *GRPCProvider) ImportResourceState(r providers.ImportResourceStateReques
 	return resp
 }
 
+func (p *GRPCProvider) ListResources(r providers.ListResourcesRequest) (resp providers.ListResourcesResponse) {
+	logger.Trace("GRPCProvider: ListResources")
+
+	schema := p.GetProviderSchema()
+	if schema.Diagnostics.HasErrors() {
+		resp.Diagnostics = schema.Diagnostics
+		return resp
+	}
+
+	resSchema, ok := schema.ResourceTypes[r.TypeName]
+	if !ok {
+		resp.Diagnostics = resp.Diagnostics.Append(fmt.Errorf("unknown resource type %q", r.TypeName))
+		return resp
+	}
+
+	protoReq := &proto6.ListResources_Request{
+		TypeName: r.TypeName,
+	}
+
+	protoResp, err := p.client.ListResources(p.ctx, protoReq)
+	if err != nil {
+		resp.Diagnostics = resp.Diagnostics.Append(grpcErr(err))
+		return resp
+	}
+	resp.Diagnostics = resp.Diagnostics.Append(convert.ProtoToDiagnostics(protoResp.Diagnostics))
+
+	for _, listed := range protoResp.Resources {
+		resource := providers.ListedResource{
+			ID: listed.Id,
+		}
+
+		// The state of a listed object is optional.
+		if listed.State != nil {
+			state, err := decodeDynamicValue(listed.State, resSchema.Block.ImpliedType())
+			if err != nil {
+				resp.Diagnostics = resp.Diagnostics.Append(err)
+				return resp
+			}
+			resource.State = state
+		}
+		resp.Resources = append(resp.Resources, resource)
+	}
+
+	return resp
+}
+
 func (p *GRPCProvider) ReadDataSource(r providers.ReadDataSourceRequest) (resp providers.ReadDataSourceResponse) {
 	logger.Trace("GRPCProvider.v6: ReadDataSource")
 
@@ -658,6 +723,66 @@ func (p *GRPCProvider) ReadDataSource(r providers.ReadDataSourceRequest) (resp p
 	return resp
 }
 
+func (p *GRPCProvider) CallFunction(r providers.CallFunctionRequest) (resp providers.CallFunctionResponse) {
+	logger.Trace("GRPCProvider: CallFunction")
+
+	schema := p.GetProviderSchema()
+	if schema.Diagnostics.HasErrors() {
+		resp.Error = schema.Diagnostics.Err()
+		return resp
+	}
+
+	decl, ok := schema.Functions[r.Name]
+	if !ok {
+		resp.Error = fmt.Errorf("unknown function %q", r.Name)
+		return resp
+	}
+
+	protoReq := &proto6.CallFunction_Request{
+		Name:      r.Name,
+		Arguments: make([]*proto6.DynamicValue, len(r.Arguments)),
+	}
+	for i, arg := range r.Arguments {
+		var paramType cty.Type
+		switch {
+		case i < len(decl.Parameters):
+			paramType = decl.Parameters[i].Type
+		case decl.VariadicParameter != nil:
+			paramType = decl.VariadicParameter.Type
+		default:
+			resp.Error = fmt.Errorf("too many arguments for function %q", r.Name)
+			return resp
+		}
+
+		argMP, err := msgpack.Marshal(arg, paramType)
+		if err != nil {
+			resp.Error = err
+			return resp
+		}
+		protoReq.Arguments[i] = &proto6.DynamicValue{Msgpack: argMP}
+	}
+
+	protoResp, err := p.client.CallFunction(p.ctx, protoReq)
+	if err != nil {
+		resp.Error = grpcErr(err).Err()
+		return resp
+	}
+
+	if protoResp.Error != nil {
+		resp.Error = convert.FunctionErrorFromProto(protoResp.Error)
+		return resp
+	}
+
+	result, err := decodeDynamicValue(protoResp.Result, decl.ReturnType)
+	if err != nil {
+		resp.Error = err
+		return resp
+	}
+	resp.Result = result
+
+	return resp
+}
+
 // closing the grpc connection is final, and terraform will call it at the end of every phase.
 func (p *GRPCProvider) Close() error {
 	logger.Trace("GRPCProvider.v6: Close")
@@ -5,7 +5,10 @@ package plugin6
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"net"
+	"strings"
 	"testing"
 
 	"github.com/opentofu/opentofu/internal/addrs"
@@ -17,6 +20,9 @@ import (
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
 
 	mockproto "github.com/opentofu/opentofu/internal/plugin6/mock_proto"
 	proto "github.com/opentofu/opentofu/internal/tfplugin6"
@@ -682,6 +688,81 @@ func TestGRPCProvider_ApplyResourceChange(t *testing.T) {
 		t.Fatalf("expected %q, got %q", expectedPrivate, resp.Private)
 	}
 }
+
+// chunkedProviderServer echoes the planned state of a change back as the
+// new state, and supports chunked calls.
+type chunkedProviderServer struct {
+	proto.UnimplementedProviderServer
+}
+
+func (s *chunkedProviderServer) GetProviderSchema(context.Context, *proto.GetProviderSchema_Request) (*proto.GetProviderSchema_Response, error) {
+	resp := providerProtoSchema()
+	resp.ServerCapabilities = &proto.GetProviderSchema_ServerCapabilities{ChunkedCalls: true}
+	return resp, nil
+}
+
+func (s *chunkedProviderServer) ApplyResourceChange(_ context.Context, req *proto.ApplyResourceChange_Request) (*proto.ApplyResourceChange_Response, error) {
+	return &proto.ApplyResourceChange_Response{NewState: req.PlannedState}, nil
+}
+
+func (s *chunkedProviderServer) CallChunked(stream proto.Provider_CallChunkedServer) error {
+	return ServeChunked(s, stream)
+}
+
+func TestGRPCProvider_ApplyResourceChangeChunked(t *testing.T) {
+	// Neither side raises gRPC's default message size limit of 4MB, so the
+	// call only succeeds if the request and response are sent in chunks.
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	proto.RegisterProviderServer(server, &chunkedProviderServer{})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	p := &GRPCProvider{
+		client: &chunkedProviderClient{ProviderClient: proto.NewProviderClient(conn)},
+		ctx:    context.Background(),
+	}
+
+	planned := cty.ObjectVal(map[string]cty.Value{
+		"attr": cty.StringVal(strings.Repeat("x", 5<<20)),
+	})
+	resp := p.ApplyResourceChange(providers.ApplyResourceChangeRequest{
+		TypeName:     "resource",
+		PriorState:   cty.NullVal(planned.Type()),
+		PlannedState: planned,
+		Config:       planned,
+	})
+	checkDiags(t, resp.Diagnostics)
+	if !resp.NewState.RawEquals(planned) {
+		t.Fatal("wrong new state")
+	}
+
+	// Only the unary calls of the Provider service can be made in chunks.
+	stream, err := p.client.CallChunked(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Send(&proto.CallChunked_Chunk{Method: "CallChunked"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err == nil || !strings.Contains(err.Error(), "can't be called in chunks") {
+		t.Fatalf("wrong error for a chunked streaming call: %v", err)
+	}
+}
+
 func TestGRPCProvider_ApplyResourceChangeJSON(t *testing.T) {
 	client := mockProviderClient(t)
 	p := &GRPCProvider{
@@ -772,6 +853,53 @@ func TestGRPCProvider_ImportResourceState(t *testing.T) {
 		t.Fatal(cmp.Diff(expectedResource, imported, typeComparer, valueComparer, equateEmpty))
 	}
 }
+
+func TestGRPCProvider_ListResources(t *testing.T) {
+	client := mockProviderClient(t)
+	p := &GRPCProvider{
+		client: client,
+	}
+
+	client.EXPECT().ListResources(
+		gomock.Any(),
+		gomock.Any(),
+	).Return(&proto.ListResources_Response{
+		Resources: []*proto.ListResources_ListedResource{
+			{
+				Id: "foo",
+				State: &proto.DynamicValue{
+					Msgpack: []byte("\x81\xa4attr\xa3bar"),
+				},
+			},
+			{
+				// The state of a listed object is optional.
+				Id: "baz",
+			},
+		},
+	}, nil)
+
+	resp := p.ListResources(providers.ListResourcesRequest{
+		TypeName: "resource",
+	})
+
+	checkDiags(t, resp.Diagnostics)
+
+	expected := []providers.ListedResource{
+		{
+			ID: "foo",
+			State: cty.ObjectVal(map[string]cty.Value{
+				"attr": cty.StringVal("bar"),
+			}),
+		},
+		{
+			ID: "baz",
+		},
+	}
+	if !cmp.Equal(expected, resp.Resources, typeComparer, valueComparer, equateEmpty) {
+		t.Fatal(cmp.Diff(expected, resp.Resources, typeComparer, valueComparer, equateEmpty))
+	}
+}
+
 func TestGRPCProvider_ImportResourceStateJSON(t *testing.T) {
 	client := mockProviderClient(t)
 	p := &GRPCProvider{
@@ -0,0 +1,163 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// Violation describes a plan's failure to conform to a policy.
+type Violation struct {
+	Policy *Policy
+
+	// Address is the address of the resource instance whose change
+	// violates a per-resource policy, or empty for a whole-plan policy.
+	Address string
+
+	Message string
+}
+
+// Evaluate evaluates the given policies against the given JSON
+// representation of a plan, as produced by "tofu show -json".
+//
+// Violations of policies at LevelDeny are returned as error diagnostics, and
+// violations of policies at LevelWarn as warnings. Errors while evaluating
+// a policy are also returned as error diagnostics, because a policy that
+// cannot be evaluated cannot allow the plan to be applied.
+func Evaluate(policies []*Policy, planJSON []byte) ([]Violation, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	var violations []Violation
+
+	var plan map[string]interface{}
+	if err := json.Unmarshal(planJSON, &plan); err != nil {
+		diags = diags.Append(fmt.Errorf("failed to decode plan for policy evaluation: %w", err))
+		return nil, diags
+	}
+	changes, _ := plan["resource_changes"].([]interface{})
+
+	for _, p := range policies {
+		if !p.PerResource() {
+			v, moreDiags := evaluatePolicy(p, map[string]interface{}{"plan": plan}, "")
+			diags = diags.Append(moreDiags)
+			if v != nil {
+				violations = append(violations, *v)
+			}
+			continue
+		}
+
+		for _, change := range changes {
+			change, _ := change.(map[string]interface{})
+			if !p.matches(change) {
+				continue
+			}
+			vars := map[string]interface{}{
+				"plan":     plan,
+				"resource": change,
+			}
+			addr, _ := change["address"].(string)
+			v, moreDiags := evaluatePolicy(p, vars, addr)
+			diags = diags.Append(moreDiags)
+			if v != nil {
+				violations = append(violations, *v)
+			}
+		}
+	}
+
+	for _, v := range violations {
+		diags = diags.Append(v.diagnostic())
+	}
+	return violations, diags
+}
+
+func evaluatePolicy(p *Policy, vars map[string]interface{}, addr string) (*Violation, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	result, _, err := p.program.Eval(vars)
+	if err != nil {
+		detail := fmt.Sprintf("Failed to evaluate the condition of policy %q: %s.", p.Name, err)
+		if addr != "" {
+			detail = fmt.Sprintf("Failed to evaluate the condition of policy %q for %s: %s.", p.Name, addr, err)
+		}
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Policy evaluation failed",
+			Detail:   detail,
+			Subject:  p.ConditionRange.Ptr(),
+		})
+		return nil, diags
+	}
+	ok, isBool := result.Value().(bool)
+	if !isBool {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid policy condition",
+			Detail:   fmt.Sprintf("The condition of a policy must be either true or false, but the condition of policy %q returned %s.", p.Name, result.Type().TypeName()),
+			Subject:  p.ConditionRange.Ptr(),
+		})
+		return nil, diags
+	}
+	if ok {
+		return nil, diags
+	}
+
+	return &Violation{
+		Policy:  p,
+		Address: addr,
+		Message: p.ErrorMessage,
+	}, diags
+}
+
+// matches returns true if the given resource change from the JSON plan
+// passes the policy's resource type and action filters.
+func (p *Policy) matches(change map[string]interface{}) bool {
+	if typeName, _ := change["type"].(string); len(p.ResourceTypes) > 0 && !contains(p.ResourceTypes, typeName) {
+		return false
+	}
+	if len(p.Actions) == 0 {
+		return true
+	}
+	c, _ := change["change"].(map[string]interface{})
+	actions, _ := c["actions"].([]interface{})
+	for _, action := range actions {
+		if action, ok := action.(string); ok && contains(p.Actions, action) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v Violation) diagnostic() *hcl.Diagnostic {
+	severity := hcl.DiagError
+	summary := "Policy violation"
+	if v.Policy.Level == LevelWarn {
+		severity = hcl.DiagWarning
+		summary = "Policy warning"
+	}
+
+	detail := fmt.Sprintf("Policy %q: %s", v.Policy.Name, v.Message)
+	if v.Address != "" {
+		detail = fmt.Sprintf("Policy %q, for %s: %s", v.Policy.Name, v.Address, v.Message)
+	}
+
+	return &hcl.Diagnostic{
+		Severity: severity,
+		Summary:  summary,
+		Detail:   detail,
+		Subject:  v.Policy.DeclRange.Ptr(),
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
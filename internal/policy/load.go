@@ -0,0 +1,108 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+// Loader reads policy files, retaining their source code so that it can be
+// included in diagnostic messages.
+type Loader struct {
+	parser *hclparse.Parser
+}
+
+// NewLoader returns a new Loader.
+func NewLoader() *Loader {
+	return &Loader{parser: hclparse.NewParser()}
+}
+
+// Sources returns the source code of all of the files read by the loader,
+// keyed by filename.
+func (l *Loader) Sources() map[string][]byte {
+	ret := make(map[string][]byte)
+	for name, f := range l.parser.Files() {
+		ret[name] = f.Bytes
+	}
+	return ret
+}
+
+// LoadPath reads the policies from the given path, which is either a single
+// policy file or a directory containing policy files.
+func (l *Loader) LoadPath(path string) ([]*Policy, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	info, err := os.Stat(path)
+	if err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to read policies",
+			Detail:   fmt.Sprintf("Cannot read policies from %s: %s.", path, err),
+		})
+		return nil, diags
+	}
+
+	if !info.IsDir() {
+		return l.loadFile(path)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to read policies",
+			Detail:   fmt.Sprintf("Cannot read policy directory %s: %s.", path, err),
+		})
+		return nil, diags
+	}
+
+	var ret []*Policy
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), FileSuffix) {
+			continue
+		}
+		policies, fileDiags := l.loadFile(filepath.Join(path, entry.Name()))
+		diags = append(diags, fileDiags...)
+		ret = append(ret, policies...)
+	}
+	return ret, diags
+}
+
+func (l *Loader) loadFile(filename string) ([]*Policy, hcl.Diagnostics) {
+	f, diags := l.parser.ParseHCLFile(filename)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	file, fileDiags := decodeFile(f.Body)
+	diags = append(diags, fileDiags...)
+	return file.Policies, diags
+}
+
+// Dedupe returns the given policies without any later duplicates of policy
+// names, returning an error for each duplicate.
+func Dedupe(policies []*Policy) ([]*Policy, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	seen := make(map[string]*Policy)
+	var ret []*Policy
+	for _, p := range policies {
+		if existing, ok := seen[p.Name]; ok {
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate policy",
+				Detail:   fmt.Sprintf("A policy named %q was already declared at %s. Policy names must be unique.", p.Name, existing.DeclRange),
+				Subject:  p.DeclRange.Ptr(),
+			})
+			continue
+		}
+		seen[p.Name] = p
+		ret = append(ret, p)
+	}
+	return ret, diags
+}
@@ -0,0 +1,196 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package policy implements policies, which are rules whose conditions are
+// written in the Common Expression Language (CEL), and which OpenTofu
+// evaluates against the JSON representation of a plan before the plan can be
+// applied.
+package policy
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+)
+
+// FileSuffix is the filename suffix of files containing policies.
+const FileSuffix = ".tfpolicy.hcl"
+
+// Level describes what happens when a plan violates a policy.
+type Level string
+
+const (
+	// LevelDeny prevents applying a plan which violates the policy.
+	LevelDeny Level = "deny"
+
+	// LevelWarn reports a violation of the policy as a warning, but still
+	// allows the plan to be applied.
+	LevelWarn Level = "warn"
+)
+
+// Policy is a single policy declared in a "policy" block.
+type Policy struct {
+	Name  string
+	Level Level
+
+	// ResourceTypes and Actions, if set, limit a per-resource policy to
+	// resource changes of the given resource types and including at least
+	// one of the given actions, using the action names from the JSON plan
+	// representation such as "create" and "delete".
+	ResourceTypes []string
+	Actions       []string
+
+	// Condition is a CEL expression which must evaluate to true for the
+	// plan to conform to the policy, and ErrorMessage describes a violation.
+	//
+	// See PerResource for whether the condition is evaluated once for each
+	// resource change, using the "resource" variable, or once for the whole
+	// plan. The "plan" variable is available in both cases.
+	Condition    string
+	ErrorMessage string
+
+	DeclRange      hcl.Range
+	ConditionRange hcl.Range
+
+	program      cel.Program
+	usesResource bool
+}
+
+// File is the set of policies declared in a single policy file.
+type File struct {
+	Policies []*Policy
+}
+
+var fileSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "policy", LabelNames: []string{"name"}},
+	},
+}
+
+var policySchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "level"},
+		{Name: "resource_types"},
+		{Name: "actions"},
+		{Name: "condition", Required: true},
+		{Name: "error_message", Required: true},
+	},
+}
+
+func decodeFile(body hcl.Body) (*File, hcl.Diagnostics) {
+	ret := &File{}
+
+	content, diags := body.Content(fileSchema)
+	for _, block := range content.Blocks {
+		p, policyDiags := decodePolicy(block)
+		diags = append(diags, policyDiags...)
+		if p != nil {
+			ret.Policies = append(ret.Policies, p)
+		}
+	}
+	return ret, diags
+}
+
+func decodePolicy(block *hcl.Block) (*Policy, hcl.Diagnostics) {
+	p := &Policy{
+		Name:      block.Labels[0],
+		Level:     LevelDeny,
+		DeclRange: block.DefRange,
+	}
+
+	content, diags := block.Body.Content(policySchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	if attr, ok := content.Attributes["level"]; ok {
+		var level string
+		diags = append(diags, gohcl.DecodeExpression(attr.Expr, nil, &level)...)
+		switch Level(level) {
+		case LevelDeny, LevelWarn:
+			p.Level = Level(level)
+		default:
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid policy level",
+				Detail:   fmt.Sprintf("The level of a policy must be either %q or %q.", LevelDeny, LevelWarn),
+				Subject:  attr.Expr.Range().Ptr(),
+			})
+		}
+	}
+	if attr, ok := content.Attributes["resource_types"]; ok {
+		diags = append(diags, gohcl.DecodeExpression(attr.Expr, nil, &p.ResourceTypes)...)
+	}
+	if attr, ok := content.Attributes["actions"]; ok {
+		diags = append(diags, gohcl.DecodeExpression(attr.Expr, nil, &p.Actions)...)
+	}
+	conditionAttr := content.Attributes["condition"]
+	p.ConditionRange = conditionAttr.Expr.Range()
+	diags = append(diags, gohcl.DecodeExpression(conditionAttr.Expr, nil, &p.Condition)...)
+	diags = append(diags, gohcl.DecodeExpression(content.Attributes["error_message"].Expr, nil, &p.ErrorMessage)...)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	if err := p.compile(); err != nil {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid policy condition",
+			Detail:   fmt.Sprintf("The condition is not a valid CEL expression: %s.", err),
+			Subject:  p.ConditionRange.Ptr(),
+		})
+		return nil, diags
+	}
+	return p, diags
+}
+
+// celEnv is the environment in which policy conditions are evaluated. Both
+// variables are the JSON plan representation decoded into plain maps and
+// lists, so the CEL type checker can't check their attributes.
+var celEnv, celEnvErr = cel.NewEnv(
+	cel.Variable("plan", cel.DynType),
+	cel.Variable("resource", cel.DynType),
+	// JSON numbers are decoded as doubles, so without this comparing them
+	// with integer literals, such as in size(...) < 2, would fail.
+	cel.CrossTypeNumericComparisons(true),
+	ext.Strings(),
+)
+
+func (p *Policy) compile() error {
+	if celEnvErr != nil {
+		return celEnvErr
+	}
+	ast, issues := celEnv.Compile(p.Condition)
+	if issues.Err() != nil {
+		return issues.Err()
+	}
+	if t := ast.OutputType(); t != cel.BoolType && t != cel.DynType {
+		return fmt.Errorf("it returns %s, but it must return a bool", t)
+	}
+	program, err := celEnv.Program(ast)
+	if err != nil {
+		return err
+	}
+	checked, err := cel.AstToCheckedExpr(ast)
+	if err != nil {
+		return err
+	}
+	for _, ref := range checked.GetReferenceMap() {
+		if ref.GetName() == "resource" {
+			p.usesResource = true
+		}
+	}
+	p.program = program
+	return nil
+}
+
+// PerResource returns true if the policy is evaluated once for each
+// resource change in the plan, rather than once for the whole plan. This is
+// the case for policies which filter resource changes or whose conditions
+// refer to the "resource" variable.
+func (p *Policy) PerResource() bool {
+	return len(p.ResourceTypes) > 0 || len(p.Actions) > 0 || p.usesResource
+}
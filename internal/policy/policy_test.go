@@ -0,0 +1,120 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func TestLoaderLoadPath(t *testing.T) {
+	policies, diags := NewLoader().LoadPath("testdata/policies")
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	var got []string
+	for _, p := range policies {
+		got = append(got, string(p.Level)+" "+p.Name)
+	}
+	want := []string{"deny private_buckets", "warn few_deletions"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("wrong policies\n%s", diff)
+	}
+	if !policies[0].PerResource() || policies[1].PerResource() {
+		t.Fatal("wrong result from PerResource")
+	}
+}
+
+func TestLoaderLoadPath_invalid(t *testing.T) {
+	_, diags := NewLoader().LoadPath("testdata/invalid.tfpolicy.hcl")
+	var got []string
+	for _, diag := range diags {
+		got = append(got, diag.Summary)
+	}
+	want := []string{"Invalid policy level", "Missing required argument", "Invalid policy condition", "Invalid policy condition"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("wrong diagnostics\n%s", diff)
+	}
+}
+
+func TestLoaderLoadPath_missing(t *testing.T) {
+	if _, diags := NewLoader().LoadPath("testdata/nonexist"); !diags.HasErrors() {
+		t.Fatal("expected error for missing path")
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	policies, diags := NewLoader().LoadPath("testdata/policies")
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	planJSON := []byte(`{
+  "format_version": "1.2",
+  "resource_changes": [
+    {"address": "test_bucket.a", "type": "test_bucket", "name": "a", "change": {"actions": ["create"], "after": {"acl": "public-read"}}},
+    {"address": "test_bucket.b", "type": "test_bucket", "name": "b", "change": {"actions": ["create"], "after": {"acl": "private"}}},
+    {"address": "test_bucket.c", "type": "test_bucket", "name": "c", "change": {"actions": ["delete"], "after": null}},
+    {"address": "test_thing.d", "type": "test_thing", "name": "d", "change": {"actions": ["delete"], "after": null}}
+  ]
+}`)
+
+	violations, evalDiags := Evaluate(policies, planJSON)
+
+	var got []string
+	for _, v := range violations {
+		got = append(got, v.Policy.Name+" "+v.Address+": "+v.Message)
+	}
+	want := []string{
+		"private_buckets test_bucket.a: Buckets must be private.",
+		"few_deletions : This plan deletes several objects.",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("wrong violations\n%s", diff)
+	}
+
+	if len(evalDiags) != 2 {
+		t.Fatalf("wrong number of diagnostics %d; want 2", len(evalDiags))
+	}
+	if !evalDiags.HasErrors() {
+		t.Fatal("expected an error for the deny violation")
+	}
+	if got := evalDiags[1].Description().Summary; got != "Policy warning" {
+		t.Fatalf("wrong summary %q for warn violation", got)
+	}
+}
+
+func TestEvaluate_error(t *testing.T) {
+	src := []byte(`
+policy "missing_attribute" {
+  resource_types = ["test_bucket"]
+  condition      = "resource.change.after.acl == 'private'"
+  error_message  = "unused"
+}
+`)
+	f, diags := hclsyntax.ParseConfig(src, "test.tfpolicy.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+	file, diags := decodeFile(f.Body)
+	if diags.HasErrors() {
+		t.Fatal(diags.Error())
+	}
+
+	planJSON := []byte(`{"resource_changes": [{"address": "test_bucket.a", "type": "test_bucket", "change": {"actions": ["create"], "after": {}}}]}`)
+	violations, evalDiags := Evaluate(file.Policies, planJSON)
+	if len(violations) != 0 {
+		t.Fatalf("unexpected violations %#v", violations)
+	}
+	if !evalDiags.HasErrors() {
+		t.Fatal("expected an error for a policy that can't be evaluated")
+	}
+	if got := evalDiags[0].Description().Summary; got != "Policy evaluation failed" {
+		t.Fatalf("wrong summary %q", got)
+	}
+}
@@ -0,0 +1,19 @@
+policy "bad_level" {
+  level         = "block"
+  condition     = "true"
+  error_message = "unused"
+}
+
+policy "no_message" {
+  condition = "true"
+}
+
+policy "bad_condition" {
+  condition     = "plan.resource_changes.size() <"
+  error_message = "unused"
+}
+
+policy "not_bool" {
+  condition     = "'yes'"
+  error_message = "unused"
+}
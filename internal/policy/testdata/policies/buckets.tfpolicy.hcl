@@ -0,0 +1,6 @@
+policy "private_buckets" {
+  resource_types = ["test_bucket"]
+  actions        = ["create", "update"]
+  condition      = "resource.change.after.acl == 'private'"
+  error_message  = "Buckets must be private."
+}
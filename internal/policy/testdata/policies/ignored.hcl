@@ -0,0 +1 @@
+not a policy file
@@ -0,0 +1,5 @@
+policy "few_deletions" {
+  level         = "warn"
+  condition     = "size(plan.resource_changes.filter(rc, 'delete' in rc.change.actions)) < 2"
+  error_message = "This plan deletes several objects."
+}
@@ -6,6 +6,7 @@ package simple
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/opentofu/opentofu/internal/configs/configschema"
@@ -45,6 +46,20 @@ func Provider() providers.Interface {
 			DataSources: map[string]providers.Schema{
 				"simple_resource": simpleResource,
 			},
+			Functions: map[string]providers.FunctionDecl{
+				"noop": {
+					Parameters: []providers.FunctionParam{
+						{
+							Name:               "value",
+							Type:               cty.DynamicPseudoType,
+							AllowNullValue:     true,
+							AllowUnknownValues: true,
+						},
+					},
+					ReturnType: cty.DynamicPseudoType,
+					Summary:    "Returns its argument unchanged",
+				},
+			},
 			ServerCapabilities: providers.ServerCapabilities{
 				PlanDestroy: true,
 			},
@@ -136,6 +151,20 @@ func (s simple) ReadDataSource(req providers.ReadDataSourceRequest) (resp provid
 	return resp
 }
 
+func (s simple) ListResources(providers.ListResourcesRequest) (resp providers.ListResourcesResponse) {
+	resp.Diagnostics = resp.Diagnostics.Append(errors.New("unsupported"))
+	return resp
+}
+
+func (s simple) CallFunction(req providers.CallFunctionRequest) (resp providers.CallFunctionResponse) {
+	if req.Name != "noop" {
+		resp.Error = fmt.Errorf("unknown function %q", req.Name)
+		return resp
+	}
+	resp.Result = req.Arguments[0]
+	return resp
+}
+
 func (s simple) Close() error {
 	return nil
 }
@@ -41,6 +41,10 @@ type Dir struct {
 	// explicitly defines using the same directory for multiple purposes
 	// as undefined behavior.
 	metaCache map[addrs.Provider][]CachedProvider
+
+	// shared is true for a directory created by NewSharedDir, whose packages
+	// are content-addressed and installed under a lock.
+	shared bool
 }
 
 // NewDir creates and returns a new Dir object that will read and write
@@ -172,6 +176,15 @@ func (d *Dir) fillMetaCache() error {
 			}
 
 			packageDir := filepath.Clean(string(meta.Location.(getproviders.PackageLocalDir)))
+			if d.shared {
+				// The provider hierarchy of a shared directory contains only
+				// links to the immutable objects, so other directories must
+				// link to the objects directly rather than to the links,
+				// which a later installation might replace.
+				if resolved, err := filepath.EvalSymlinks(packageDir); err == nil {
+					packageDir = resolved
+				}
+			}
 
 			log.Printf("[TRACE] providercache.fillMetaCache: including %s as a candidate package for %s %s", meta.Location, providerAddr, meta.Version)
 			data[providerAddr] = append(data[providerAddr], CachedProvider{
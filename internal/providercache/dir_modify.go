@@ -32,6 +32,9 @@ func (d *Dir) InstallPackage(ctx context.Context, meta getproviders.PackageMeta,
 	d.metaCache = nil
 
 	log.Printf("[TRACE] providercache.Dir.InstallPackage: installing %s v%s from %s", meta.Provider, meta.Version, meta.Location)
+	if d.shared {
+		return d.installSharedPackage(ctx, meta, newPath, allowedHashes)
+	}
 	switch meta.Location.(type) {
 	case getproviders.PackageHTTPURL:
 		return installFromHTTPURL(ctx, meta, newPath, allowedHashes)
@@ -0,0 +1,331 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package providercache
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opentofu/opentofu/internal/copy"
+	"github.com/opentofu/opentofu/internal/getproviders"
+)
+
+// The directories inside a shared cache directory which hold its bookkeeping,
+// alongside the usual provider hierarchy. Their names start with a period so
+// that getproviders.SearchLocalDirectory won't mistake them for hostnames.
+const (
+	// sharedObjectsDir contains the unpacked packages, each in a directory
+	// named after the package's "h1:" hash.
+	sharedObjectsDir = ".objects"
+
+	// sharedStagingDir contains packages that are still being installed.
+	sharedStagingDir = ".staging"
+
+	// sharedLocksDir contains the lock file for each package.
+	sharedLocksDir = ".locks"
+)
+
+// sharedLockPollInterval is how often we retry taking the lock for a package
+// that another process is installing.
+var sharedLockPollInterval = 100 * time.Millisecond
+
+// errLockHeld is returned by tryLockFile when another process already holds
+// the lock.
+var errLockHeld = errors.New("lock is held by another process")
+
+// sharedLocks serializes the installations of each package within this
+// process, keyed by the lock file path, because the file locks are held by
+// the process and so don't exclude other goroutines.
+var sharedLocks sync.Map
+
+// NewSharedDir is like NewDir, except that the resulting Dir is safe for
+// many OpenTofu processes to install packages into at the same time, such as
+// concurrent "tofu init" runs in different working directories or CI jobs
+// on the same host.
+//
+// A shared directory keeps each unpacked package in a directory named after
+// its checksum, and the usual provider hierarchy contains only symlinks to
+// those directories. Each package is installed while holding a lock file for
+// it, and only ever appears in the provider hierarchy fully-formed.
+func NewSharedDir(baseDir string) *Dir {
+	return &Dir{
+		baseDir:        baseDir,
+		targetPlatform: getproviders.CurrentPlatform,
+		shared:         true,
+	}
+}
+
+// VerifyPackage checks that the given package in the receiving cache
+// directory still has the contents it was installed with, returning an
+// error if not.
+//
+// Only a shared directory records the expected contents of its packages,
+// so for any other directory this always succeeds.
+func (d *Dir) VerifyPackage(entry *CachedProvider) error {
+	if !d.shared {
+		return nil
+	}
+	objectDir, err := filepath.EvalSymlinks(filepath.FromSlash(entry.PackageDir))
+	if err != nil {
+		return err
+	}
+	want, ok := sharedObjectHash(filepath.Base(objectDir))
+	if !ok || filepath.Dir(objectDir) != d.objectsPath() {
+		// Packages placed in the directory by older versions of OpenTofu
+		// aren't content-addressed, so there's nothing to compare with.
+		return nil
+	}
+	got, err := getproviders.PackageHashV1(getproviders.PackageLocalDir(objectDir))
+	if err != nil {
+		return fmt.Errorf("failed to calculate checksum for cached copy of %s %s: %w", entry.Provider, entry.Version, err)
+	}
+	if got != want {
+		return fmt.Errorf("the cached copy of %s %s at %s has been modified since it was installed", entry.Provider, entry.Version, objectDir)
+	}
+	return nil
+}
+
+// installSharedPackage is the implementation of InstallPackage for a shared
+// directory.
+func (d *Dir) installSharedPackage(ctx context.Context, meta getproviders.PackageMeta, newPath string, allowedHashes []getproviders.Hash) (*getproviders.PackageAuthenticationResult, error) {
+	unlock, err := d.lockPackage(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if err := os.MkdirAll(filepath.Join(d.baseDir, sharedStagingDir), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create staging directory in %s: %w", d.baseDir, err)
+	}
+	stagingDir, err := os.MkdirTemp(filepath.Join(d.baseDir, sharedStagingDir), "package-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory in %s: %w", d.baseDir, err)
+	}
+	defer os.RemoveAll(stagingDir)
+	stagedPath := filepath.Join(stagingDir, "package")
+
+	var authResult *getproviders.PackageAuthenticationResult
+	switch meta.Location.(type) {
+	case getproviders.PackageHTTPURL:
+		authResult, err = installFromHTTPURL(ctx, meta, stagedPath, allowedHashes)
+	case getproviders.PackageLocalArchive:
+		authResult, err = installFromLocalArchive(ctx, meta, stagedPath, allowedHashes)
+	case getproviders.PackageLocalDir:
+		authResult, err = installFromLocalDir(ctx, meta, stagedPath, allowedHashes)
+		if err == nil {
+			// The objects must not change after we've hashed them, so we
+			// copy the package rather than link to it.
+			err = copyIfSymlink(stagedPath)
+		}
+	default:
+		// Should not get here, because the above should be exhaustive for
+		// all implementations of getproviders.Location.
+		return nil, fmt.Errorf("don't know how to install from a %T location", meta.Location)
+	}
+	if err != nil {
+		return authResult, err
+	}
+
+	hash, err := getproviders.PackageHashV1(getproviders.PackageLocalDir(stagedPath))
+	if err != nil {
+		return authResult, fmt.Errorf("failed to calculate checksum for %s %s package: %w", meta.Provider, meta.Version, err)
+	}
+	objectDir, err := d.storeObject(stagedPath, stagingDir, hash)
+	if err != nil {
+		return authResult, err
+	}
+	if err := replaceWithSymlink(newPath, objectDir, stagingDir); err != nil {
+		return authResult, fmt.Errorf("failed to link %s %s into %s: %w", meta.Provider, meta.Version, d.baseDir, err)
+	}
+	return authResult, nil
+}
+
+// lockPackage waits until it holds the lock for installing the given package
+// into the receiving shared directory, or until the given context is
+// cancelled. The caller must call the returned function to release the lock.
+func (d *Dir) lockPackage(ctx context.Context, meta getproviders.PackageMeta) (func(), error) {
+	locksDir := filepath.Join(d.baseDir, sharedLocksDir)
+	if err := os.MkdirAll(locksDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory in %s: %w", d.baseDir, err)
+	}
+	name := fmt.Sprintf("%s_%s_%s.lock", strings.ReplaceAll(meta.Provider.String(), "/", "_"), meta.Version, meta.TargetPlatform)
+	lockPath := filepath.Join(locksDir, name)
+
+	muRaw, _ := sharedLocks.LoadOrStore(lockPath, &sync.Mutex{})
+	mu := muRaw.(*sync.Mutex)
+	mu.Lock()
+
+	f, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		mu.Unlock()
+		return nil, fmt.Errorf("failed to open lock file %s: %w", lockPath, err)
+	}
+
+	logged := false
+	for {
+		err := tryLockFile(f)
+		if err == nil {
+			break
+		}
+		if err != errLockHeld {
+			f.Close()
+			mu.Unlock()
+			return nil, fmt.Errorf("failed to lock %s: %w", lockPath, err)
+		}
+		if !logged {
+			log.Printf("[TRACE] providercache.Dir.lockPackage: waiting for another process to finish installing %s v%s", meta.Provider, meta.Version)
+			logged = true
+		}
+		select {
+		case <-ctx.Done():
+			f.Close()
+			mu.Unlock()
+			return nil, fmt.Errorf("interrupted while waiting for another process to finish installing %s %s into %s", meta.Provider, meta.Version, d.baseDir)
+		case <-time.After(sharedLockPollInterval):
+		}
+	}
+
+	return func() {
+		if err := unlockFile(f); err != nil {
+			log.Printf("[WARN] Failed to unlock %s: %s", lockPath, err)
+		}
+		f.Close()
+		mu.Unlock()
+	}, nil
+}
+
+// storeObject moves the staged package into the objects directory under the
+// given hash, unless an intact object with that hash is already there, and
+// returns the path of the object. scratchDir is a directory the object that
+// was already there can be moved aside into, if it's corrupt.
+func (d *Dir) storeObject(stagedPath, scratchDir string, hash getproviders.Hash) (string, error) {
+	if err := os.MkdirAll(d.objectsPath(), 0755); err != nil {
+		return "", fmt.Errorf("failed to create objects directory in %s: %w", d.baseDir, err)
+	}
+	objectDir := filepath.Join(d.objectsPath(), sharedObjectName(hash))
+
+	if _, err := os.Lstat(objectDir); err == nil {
+		existing, err := getproviders.PackageHashV1(getproviders.PackageLocalDir(objectDir))
+		if err == nil && existing == hash {
+			// Another installation already stored the same package.
+			return objectDir, nil
+		}
+		// The existing object is corrupt, so we'll replace it with the one
+		// we've just staged. We move it aside first, because the rename
+		// below can't replace a directory that isn't empty.
+		log.Printf("[WARN] Replacing corrupt provider package %s", objectDir)
+		if err := os.Rename(objectDir, filepath.Join(scratchDir, "corrupt")); err != nil {
+			return "", fmt.Errorf("failed to remove corrupt package %s: %w", objectDir, err)
+		}
+	}
+
+	if err := os.Rename(stagedPath, objectDir); err != nil {
+		return "", fmt.Errorf("failed to move package into %s: %w", objectDir, err)
+	}
+	return objectDir, nil
+}
+
+func (d *Dir) objectsPath() string {
+	// We resolve the base directory so that we can compare this with the
+	// fully-resolved paths of the packages.
+	base, err := filepath.Abs(d.baseDir)
+	if err != nil {
+		base = d.baseDir
+	}
+	if resolved, err := filepath.EvalSymlinks(base); err == nil {
+		base = resolved
+	}
+	return filepath.Join(base, sharedObjectsDir)
+}
+
+// sharedObjectName returns the name of the directory that holds the package
+// with the given hash in the objects directory. The hash is re-encoded,
+// because the base64 encoding of a hash can include slashes.
+func sharedObjectName(hash getproviders.Hash) string {
+	raw, err := base64.StdEncoding.DecodeString(hash.Value())
+	if err != nil {
+		// Should never happen for a hash we calculated ourselves.
+		panic(fmt.Sprintf("invalid package hash %s", hash))
+	}
+	return strings.TrimSuffix(string(hash.Scheme()), ":") + "-" + hex.EncodeToString(raw)
+}
+
+// sharedObjectHash is the inverse of sharedObjectName, returning false if
+// the given name isn't the name of an object.
+func sharedObjectHash(name string) (getproviders.Hash, bool) {
+	hexHash, ok := strings.CutPrefix(name, "h1-")
+	if !ok {
+		return "", false
+	}
+	raw, err := hex.DecodeString(hexHash)
+	if err != nil {
+		return "", false
+	}
+	return getproviders.HashScheme1.New(base64.StdEncoding.EncodeToString(raw)), true
+}
+
+// replaceWithSymlink atomically replaces whatever is at the given path with
+// a symlink to the given target, so that other processes reading the path
+// see either the old or the new content but never something in between.
+// scratchDir is a directory on the same filesystem that the link can be
+// created in before renaming it into place.
+//
+// If the filesystem doesn't support symlinks then this instead copies the
+// target, which is not atomic.
+func replaceWithSymlink(path, target, scratchDir string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create parent directories leading to %s: %w", path, err)
+	}
+
+	tmpLink := filepath.Join(scratchDir, "link")
+	if err := os.Symlink(target, tmpLink); err != nil {
+		log.Printf("[TRACE] providercache: can't create symlink in %s, so copying %s instead: %s", scratchDir, target, err)
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+		if err := os.Mkdir(path, 0755); err != nil {
+			return err
+		}
+		return copy.CopyDir(path, target)
+	}
+
+	// A rename can replace a symlink or an empty directory, but not a
+	// directory with content, such as a package installed by an older
+	// version of OpenTofu, so we move any such directory aside first.
+	if info, err := os.Lstat(path); err == nil && info.IsDir() {
+		if err := os.Rename(path, filepath.Join(scratchDir, "previous")); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmpLink, path)
+}
+
+// copyIfSymlink replaces the symlink at the given path, if any, with a copy
+// of the directory it links to.
+func copyIfSymlink(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return err
+	}
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	if err := os.Mkdir(path, 0755); err != nil {
+		return err
+	}
+	return copy.CopyDir(path, target)
+}
@@ -0,0 +1,204 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package providercache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/apparentlymart/go-versions/versions"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/getproviders"
+)
+
+func TestInstallPackage_shared(t *testing.T) {
+	tmpDirPath, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := testSharedPackageMeta()
+
+	dir := testSharedDir(tmpDirPath)
+	if _, err := dir.InstallPackage(context.Background(), meta, nil); err != nil {
+		t.Fatalf("InstallPackage failed: %s", err)
+	}
+
+	linkPath := filepath.Join(tmpDirPath, "registry.opentofu.org/hashicorp/null/2.1.0/linux_amd64")
+	info, err := os.Lstat(linkPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("%s is not a symlink", linkPath)
+	}
+	objectDir, err := filepath.EvalSymlinks(linkPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := filepath.Dir(objectDir), filepath.Join(tmpDirPath, ".objects"); got != want {
+		t.Fatalf("package is in %s, want %s", got, want)
+	}
+	hash, err := getproviders.PackageHashV1(getproviders.PackageLocalDir(objectDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := filepath.Base(objectDir), sharedObjectName(hash); got != want {
+		t.Fatalf("package is named %s, want %s", got, want)
+	}
+
+	// Other directories must link to the object itself.
+	entry := dir.ProviderVersion(meta.Provider, meta.Version)
+	if entry == nil {
+		t.Fatal("package not found after installation")
+	}
+	if got, want := entry.PackageDir, filepath.ToSlash(objectDir); got != want {
+		t.Fatalf("wrong package dir %s, want %s", got, want)
+	}
+	if err := dir.VerifyPackage(entry); err != nil {
+		t.Fatalf("unexpected verification error: %s", err)
+	}
+
+	// Installing the same package again reuses the existing object.
+	if _, err := dir.InstallPackage(context.Background(), meta, nil); err != nil {
+		t.Fatalf("second InstallPackage failed: %s", err)
+	}
+	objects, err := os.ReadDir(filepath.Join(tmpDirPath, ".objects"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 {
+		t.Fatalf("found %d objects, want 1", len(objects))
+	}
+	staging, err := os.ReadDir(filepath.Join(tmpDirPath, ".staging"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(staging) != 0 {
+		t.Fatalf("staging directory was not cleaned up: %v", staging)
+	}
+}
+
+func TestInstallPackage_sharedConcurrent(t *testing.T) {
+	tmpDirPath, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := testSharedPackageMeta()
+
+	// Each installation uses its own Dir, as separate "tofu init" runs would.
+	const n = 8
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = testSharedDir(tmpDirPath).InstallPackage(context.Background(), meta, nil)
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("installation %d failed: %s", i, err)
+		}
+	}
+
+	dir := testSharedDir(tmpDirPath)
+	entry := dir.ProviderVersion(meta.Provider, meta.Version)
+	if entry == nil {
+		t.Fatal("package not found after installation")
+	}
+	if err := dir.VerifyPackage(entry); err != nil {
+		t.Fatalf("unexpected verification error: %s", err)
+	}
+	if _, err := entry.ExecutableFile(); err != nil {
+		t.Fatalf("package is incomplete: %s", err)
+	}
+}
+
+func TestInstallPackage_sharedCorrupt(t *testing.T) {
+	tmpDirPath, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := testSharedPackageMeta()
+
+	dir := testSharedDir(tmpDirPath)
+	if _, err := dir.InstallPackage(context.Background(), meta, nil); err != nil {
+		t.Fatalf("InstallPackage failed: %s", err)
+	}
+	entry := dir.ProviderVersion(meta.Provider, meta.Version)
+	exe, err := entry.ExecutableFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(exe, []byte("corrupted"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	err = dir.VerifyPackage(entry)
+	if err == nil {
+		t.Fatal("corrupt package passed verification")
+	}
+	if !strings.Contains(err.Error(), "has been modified since it was installed") {
+		t.Fatalf("wrong error: %s", err)
+	}
+
+	// Installing the package again repairs the object.
+	if _, err := dir.InstallPackage(context.Background(), meta, nil); err != nil {
+		t.Fatalf("second InstallPackage failed: %s", err)
+	}
+	entry = dir.ProviderVersion(meta.Provider, meta.Version)
+	if err := dir.VerifyPackage(entry); err != nil {
+		t.Fatalf("package is still corrupt after reinstalling: %s", err)
+	}
+}
+
+func TestSharedObjectName(t *testing.T) {
+	hash := getproviders.MustParseHash("h1:Cb9AtRrW4Ln+1H3f7bXHZ2BsAu1ctH0K8/kK5/BfVPw=")
+	name := sharedObjectName(hash)
+	if strings.ContainsAny(name, "/+=:") {
+		t.Fatalf("name %q is not safe to use as a filename", name)
+	}
+	got, ok := sharedObjectHash(name)
+	if !ok {
+		t.Fatalf("%q is not recognized as an object name", name)
+	}
+	if got != hash {
+		t.Fatalf("wrong hash %s, want %s", got, hash)
+	}
+	if _, ok := sharedObjectHash("registry.opentofu.org"); ok {
+		t.Fatal("hostname is recognized as an object name")
+	}
+}
+
+func testSharedDir(baseDir string) *Dir {
+	dir := NewSharedDir(baseDir)
+	dir.targetPlatform = getproviders.Platform{OS: "linux", Arch: "amd64"}
+	return dir
+}
+
+func testSharedPackageMeta() getproviders.PackageMeta {
+	linuxPlatform := getproviders.Platform{
+		OS:   "linux",
+		Arch: "amd64",
+	}
+	return getproviders.PackageMeta{
+		Provider: addrs.NewProvider(
+			addrs.DefaultProviderRegistryHost, "hashicorp", "null",
+		),
+		Version: versions.MustParseVersion("2.1.0"),
+
+		ProtocolVersions: getproviders.VersionList{versions.MustParseVersion("5.0.0")},
+		TargetPlatform:   linuxPlatform,
+
+		Filename: "provider-null_2.1.0_linux_amd64.zip",
+		Location: getproviders.PackageLocalArchive("testdata/provider-null_2.1.0_linux_amd64.zip"),
+	}
+}
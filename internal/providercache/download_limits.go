@@ -0,0 +1,189 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package providercache
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// downloadSlotPollInterval is how often a download that's waiting for
+// another OpenTofu process to finish a download checks again.
+var downloadSlotPollInterval = 500 * time.Millisecond
+
+// maxDownloadReadSize is the most that a bandwidth-limited download reads
+// from the network at once.
+const maxDownloadReadSize = 256 * 1024
+
+// DownloadLimits limits the resources that installers use to download
+// provider packages, so that many OpenTofu processes running at once don't
+// saturate the network or exceed a registry's rate limits.
+//
+// The same DownloadLimits can be shared between several installers, in which
+// case the limits apply to all of their downloads together.
+type DownloadLimits struct {
+	// maxConcurrent, if greater than zero, is the maximum number of
+	// packages that all of the OpenTofu processes on this computer download
+	// at the same time.
+	maxConcurrent int
+
+	// sem limits the concurrent downloads in this process, and heldSlots
+	// are the slot files that this process holds. The POSIX locks on the
+	// slot files don't exclude other downloads in the same process.
+	sem       chan struct{}
+	mu        sync.Mutex
+	heldSlots map[int]bool
+
+	// bandwidth, if not nil, limits the total rate, in bytes per second, at
+	// which this process downloads packages.
+	bandwidth *rate.Limiter
+}
+
+// NewDownloadLimits returns download limits allowing at most maxConcurrent
+// packages to be downloaded at once by all of the OpenTofu processes on this
+// computer, and at most bytesPerSecond to be downloaded by this process.
+// Zero for either means that it isn't limited.
+func NewDownloadLimits(maxConcurrent int, bytesPerSecond int64) *DownloadLimits {
+	ret := &DownloadLimits{}
+	if maxConcurrent > 0 {
+		ret.maxConcurrent = maxConcurrent
+		ret.sem = make(chan struct{}, maxConcurrent)
+		ret.heldSlots = make(map[int]bool)
+	}
+	if bytesPerSecond > 0 {
+		burst := int(min(bytesPerSecond, maxDownloadReadSize))
+		ret.bandwidth = rate.NewLimiter(rate.Limit(bytesPerSecond), burst)
+	}
+	return ret
+}
+
+// SetDownloadLimits makes the installer limit the downloads of the packages
+// it installs to the given limits.
+func (i *Installer) SetDownloadLimits(limits *DownloadLimits) {
+	i.downloadLimits = limits
+}
+
+type downloadLimitsContextKey struct{}
+
+func withDownloadLimits(ctx context.Context, limits *DownloadLimits) context.Context {
+	if limits == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, downloadLimitsContextKey{}, limits)
+}
+
+func downloadLimitsForContext(ctx context.Context) *DownloadLimits {
+	limits, _ := ctx.Value(downloadLimitsContextKey{}).(*DownloadLimits)
+	return limits
+}
+
+// acquire waits until a download may begin, and returns a function that
+// the caller must call once the download is finished.
+//
+// To coordinate with other OpenTofu processes, each download holds a lock
+// on one of maxConcurrent slot files in downloadDir.
+func (l *DownloadLimits) acquire(ctx context.Context) (func(), error) {
+	if l == nil || l.maxConcurrent == 0 {
+		return func() {}, nil
+	}
+
+	select {
+	case l.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if err := os.MkdirAll(downloadDir, 0700); err != nil {
+		// We can still limit the downloads in this process.
+		log.Printf("[WARN] Can't create %s, so provider downloads are limited only within this process: %s", downloadDir, err)
+		return func() { <-l.sem }, nil
+	}
+
+	waiting := false
+	for {
+		slot, f := l.tryAcquireSlot()
+		if f != nil {
+			return func() {
+				if err := unlockFile(f); err != nil {
+					log.Printf("[WARN] Failed to unlock %s: %s", f.Name(), err)
+				}
+				f.Close()
+				l.mu.Lock()
+				delete(l.heldSlots, slot)
+				l.mu.Unlock()
+				<-l.sem
+			}, nil
+		}
+		if !waiting {
+			log.Printf("[INFO] Waiting for one of the %d provider downloads allowed at once to finish", l.maxConcurrent)
+			waiting = true
+		}
+		select {
+		case <-time.After(downloadSlotPollInterval):
+		case <-ctx.Done():
+			<-l.sem
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// tryAcquireSlot locks one of the slot files that neither this process nor
+// another process holds, if there is one.
+func (l *DownloadLimits) tryAcquireSlot() (int, *os.File) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for slot := 0; slot < l.maxConcurrent; slot++ {
+		if l.heldSlots[slot] {
+			continue
+		}
+		path := filepath.Join(downloadDir, fmt.Sprintf("slot-%d.lock", slot))
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+		if err != nil {
+			log.Printf("[TRACE] providercache: can't open %s: %s", path, err)
+			continue
+		}
+		if err := tryLockFile(f); err != nil {
+			f.Close()
+			continue
+		}
+		l.heldSlots[slot] = true
+		return slot, f
+	}
+	return 0, nil
+}
+
+// reader returns a reader that reads from r no faster than the bandwidth
+// limit allows.
+func (l *DownloadLimits) reader(ctx context.Context, r io.Reader) io.Reader {
+	if l == nil || l.bandwidth == nil {
+		return r
+	}
+	return &limitedReader{ctx: ctx, r: r, limiter: l.bandwidth}
+}
+
+type limitedReader struct {
+	ctx     context.Context
+	r       io.Reader
+	limiter *rate.Limiter
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if burst := r.limiter.Burst(); len(p) > burst {
+		p = p[:burst]
+	}
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if waitErr := r.limiter.WaitN(r.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
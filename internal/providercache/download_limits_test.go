@@ -0,0 +1,74 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package providercache
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestDownloadLimits_concurrency(t *testing.T) {
+	testDownloadDir(t)
+	oldInterval := downloadSlotPollInterval
+	downloadSlotPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() {
+		downloadSlotPollInterval = oldInterval
+	})
+
+	limits := NewDownloadLimits(1, 0)
+	release, err := limits.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A second download must wait for the first to finish.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := limits.acquire(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("second download began while the first was in progress; err = %v", err)
+	}
+
+	release()
+	release, err = limits.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("download couldn't begin after the first finished: %s", err)
+	}
+	release()
+}
+
+func TestDownloadLimits_bandwidth(t *testing.T) {
+	limits := NewDownloadLimits(0, 20000)
+	content := make([]byte, 30000)
+
+	start := time.Now()
+	got, err := io.ReadAll(limits.reader(context.Background(), bytes.NewReader(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(content) {
+		t.Fatalf("read %d bytes; want %d", len(got), len(content))
+	}
+
+	// The limiter allows an initial burst of one second's worth of
+	// bytes, so the remaining 10000 bytes take at least half a second.
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("read took only %s", elapsed)
+	}
+}
+
+func TestDownloadLimits_none(t *testing.T) {
+	var limits *DownloadLimits
+	release, err := limits.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	r := bytes.NewReader(nil)
+	if got := limits.reader(context.Background(), r); got != r {
+		t.Error("reader was wrapped without a bandwidth limit")
+	}
+}
@@ -57,6 +57,10 @@ type Installer struct {
 	// lifecycle for, and therefore does not need to worry about the
 	// installation of.
 	unmanagedProviderTypes map[addrs.Provider]struct{}
+
+	// downloadLimits, if not nil, limits the downloads of the packages that
+	// the installer installs.
+	downloadLimits *DownloadLimits
 }
 
 // NewInstaller constructs and returns a new installer with the given target
@@ -182,6 +186,7 @@ func (i *Installer) SetUnmanagedProviderTypes(types map[addrs.Provider]struct{})
 func (i *Installer) EnsureProviderVersions(ctx context.Context, locks *depsfile.Locks, reqs getproviders.Requirements, mode InstallMode) (*depsfile.Locks, error) {
 	errs := map[addrs.Provider]error{}
 	evts := installerEventsForContext(ctx)
+	ctx = withDownloadLimits(ctx, i.downloadLimits)
 
 	// We'll work with a copy of the given locks, so we can modify it and
 	// return the updated locks without affecting the caller's object.
@@ -309,19 +314,51 @@ NeedProvider:
 				cb(provider, warnings)
 			}
 		}
+		statuses, err := getproviders.SourceVersionStatuses(ctx, i.source, provider)
+		if err != nil {
+			// The statuses only refine the selection, so we can still
+			// install the provider without them.
+			log.Printf("[WARN] Failed to retrieve the version statuses of %s: %s", provider, err)
+		}
+		if locked[provider] {
+			lock := locks.Provider(provider)
+			if yanked := statuses[lock.Version()].Yanked; yanked != nil {
+				err := fmt.Errorf("the previously-selected version %s has been yanked by its publisher: %s; must use tofu init -upgrade to select another version", lock.Version(), yanked)
+				errs[provider] = err
+				if cb := evts.QueryPackagesFailure; cb != nil {
+					cb(provider, err)
+				}
+				continue
+			}
+		}
+		var yankedMatch getproviders.Version
 		available.Sort()                           // put the versions in increasing order of precedence
 		for i := len(available) - 1; i >= 0; i-- { // walk backwards to consider newer versions first
 			if acceptableVersions.Has(available[i]) {
+				status := statuses[available[i]]
+				if status.Yanked != nil {
+					if yankedMatch == getproviders.UnspecifiedVersion {
+						yankedMatch = available[i]
+					}
+					continue
+				}
 				need[provider] = available[i]
 				if cb := evts.QueryPackagesSuccess; cb != nil {
 					cb(provider, available[i])
 				}
+				if status.Deprecated != nil {
+					if cb := evts.QueryPackagesDeprecated; cb != nil {
+						cb(provider, available[i], status.Deprecated)
+					}
+				}
 				continue NeedProvider
 			}
 		}
 		// If we get here then the source has no packages that meet the given
 		// version constraint, which we model as a query error.
-		if locked[provider] {
+		if yankedMatch != getproviders.UnspecifiedVersion {
+			err = fmt.Errorf("no available releases match the given constraints %s, because the matching version %s has been yanked by its publisher: %s", getproviders.VersionConstraintsString(reqs[provider]), yankedMatch, statuses[yankedMatch].Yanked)
+		} else if locked[provider] {
 			// This situation should be a rare one: it suggests that a
 			// version was previously available but was yanked for some
 			// reason.
@@ -369,7 +406,17 @@ NeedProvider:
 		if i.globalCacheDir != nil {
 			// Step 3a: If our global cache already has this version available then
 			// we'll just link it in.
-			if cached := i.globalCacheDir.ProviderVersion(provider, version); cached != nil {
+			cached := i.globalCacheDir.ProviderVersion(provider, version)
+			if cached != nil {
+				// A corrupt entry, such as one that was modified after it
+				// was installed, is treated as absent so that we'll install
+				// the package again below, which also repairs the entry.
+				if err := i.globalCacheDir.VerifyPackage(cached); err != nil {
+					log.Printf("[WARN] Ignoring %s v%s in the global provider cache: %s", provider, version, err)
+					cached = nil
+				}
+			}
+			if cached != nil {
 				// An existing cache entry is only an acceptable choice
 				// if there is already a lock file entry for this provider
 				// and the cache entry matches its checksums.
@@ -573,10 +620,22 @@ NeedProvider:
 			allowedHashes = []getproviders.Hash{}
 		}
 
-		authResult, err := installTo.InstallPackage(ctx, meta, allowedHashes)
+		// If the source offers a delta from a version we already have, we
+		// can build the package from that instead of downloading all of it.
+		authResult, err := func() (*getproviders.PackageAuthenticationResult, error) {
+			if deltaMeta, cleanup, ok := packageFromDelta(ctx, meta, allowedHashes, i.globalCacheDir, i.targetDir); ok {
+				defer cleanup()
+				authResult, err := installTo.InstallPackage(ctx, deltaMeta, allowedHashes)
+				if err == nil {
+					return authResult, nil
+				}
+				log.Printf("[WARN] Failed to install %s v%s built from a delta, so downloading the full package instead: %s", provider, version, err)
+			}
+			return installTo.InstallPackage(ctx, meta, allowedHashes)
+		}()
 		if err != nil {
-			// TODO: Consider retrying for certain kinds of error that seem
-			// likely to be transient. For now, we just treat all errors equally.
+			// Downloads are already retried if the connection fails, so we
+			// treat all errors here equally.
 			errs[provider] = err
 			if cb := evts.FetchPackageFailure; cb != nil {
 				cb(provider, version, err)
@@ -74,6 +74,10 @@ type InstallerEvents struct {
 	QueryPackagesFailure func(provider addrs.Provider, err error)
 	QueryPackagesWarning func(provider addrs.Provider, warn []string)
 
+	// QueryPackagesDeprecated is called after QueryPackagesSuccess if the
+	// publisher has deprecated the selected version.
+	QueryPackagesDeprecated func(provider addrs.Provider, version getproviders.Version, notice *getproviders.VersionNotice)
+
 	// The LinkFromCache... family of events delimit the operation of linking
 	// a selected provider package from the system-wide shared cache into the
 	// current configuration's local cache.
@@ -99,6 +99,16 @@ func installerLogEventsForTests(into chan<- *testInstallerEventLogItem) *Install
 				Args:     warns,
 			}
 		},
+		QueryPackagesDeprecated: func(provider addrs.Provider, version getproviders.Version, notice *getproviders.VersionNotice) {
+			into <- &testInstallerEventLogItem{
+				Event:    "QueryPackagesDeprecated",
+				Provider: provider,
+				Args: struct {
+					Version string
+					Notice  string
+				}{version.String(), notice.String()},
+			}
+		},
 		LinkFromCacheBegin: func(provider addrs.Provider, version getproviders.Version, cacheRoot string) {
 			into <- &testInstallerEventLogItem{
 				Event:    "LinkFromCacheBegin",
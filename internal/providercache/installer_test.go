@@ -2440,6 +2440,115 @@ func TestEnsureProviderVersions_protocol_errors(t *testing.T) {
 	}
 }
 
+func TestEnsureProviderVersions_versionStatuses(t *testing.T) {
+	beepProvider := addrs.MustParseProviderSourceString("example.com/foo/beep")
+	beepProviderDir := getproviders.PackageLocalDir("testdata/beep-provider")
+	fakePlatform := getproviders.Platform{OS: "bleep", Arch: "bloop"}
+
+	var metas []getproviders.PackageMeta
+	for _, v := range []string{"1.0.0", "1.1.0", "1.2.0"} {
+		metas = append(metas, getproviders.PackageMeta{
+			Provider:       beepProvider,
+			Version:        getproviders.MustParseVersion(v),
+			TargetPlatform: fakePlatform,
+			Location:       beepProviderDir,
+		})
+	}
+	source := testVersionStatusSource{
+		Source: getproviders.NewMockSource(metas, nil),
+		statuses: getproviders.VersionStatuses{
+			getproviders.MustParseVersion("1.1.0"): {
+				Deprecated: &getproviders.VersionNotice{Reason: "use 2.0.0", Link: "https://example.com/upgrade"},
+			},
+			getproviders.MustParseVersion("1.2.0"): {
+				Yanked: &getproviders.VersionNotice{Reason: "security problem"},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		reqs        string
+		lockFile    string
+		wantVersion string
+		wantEvents  []string
+		wantErr     string
+	}{
+		"skips yanked and warns about deprecated": {
+			reqs:        ">= 1.0.0",
+			wantVersion: "1.1.0",
+			wantEvents: []string{
+				"QueryPackagesSuccess 1.1.0",
+				"QueryPackagesDeprecated 1.1.0: use 2.0.0 (https://example.com/upgrade)",
+			},
+		},
+		"only yanked version matches": {
+			reqs:    "1.2.0",
+			wantErr: "no available releases match the given constraints 1.2.0, because the matching version 1.2.0 has been yanked by its publisher: security problem",
+		},
+		"locked version is yanked": {
+			reqs: ">= 1.0.0",
+			lockFile: `
+				provider "example.com/foo/beep" {
+					version     = "1.2.0"
+					constraints = ">= 1.0.0"
+				}
+			`,
+			wantErr: "the previously-selected version 1.2.0 has been yanked by its publisher: security problem; must use tofu init -upgrade to select another version",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := NewDirWithPlatform(tmpDir(t), fakePlatform)
+			inst := NewInstaller(dir, source)
+			locks, lockDiags := depsfile.LoadLocksFromBytes([]byte(test.lockFile), "test.lock.hcl")
+			if lockDiags.HasErrors() {
+				t.Fatalf("invalid lock file: %s", lockDiags.Err().Error())
+			}
+			reqs := getproviders.Requirements{
+				beepProvider: getproviders.MustParseVersionConstraints(test.reqs),
+			}
+
+			var events []string
+			ctx := (&InstallerEvents{
+				QueryPackagesSuccess: func(provider addrs.Provider, selectedVersion getproviders.Version) {
+					events = append(events, fmt.Sprintf("QueryPackagesSuccess %s", selectedVersion))
+				},
+				QueryPackagesDeprecated: func(provider addrs.Provider, version getproviders.Version, notice *getproviders.VersionNotice) {
+					events = append(events, fmt.Sprintf("QueryPackagesDeprecated %s: %s", version, notice))
+				},
+			}).OnContext(context.Background())
+			newLocks, err := inst.EnsureProviderVersions(ctx, locks, reqs, InstallNewProvidersOnly)
+
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("wrong error\ngot:  %v\nwant: %s", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got := newLocks.Provider(beepProvider).Version().String(); got != test.wantVersion {
+				t.Errorf("wrong version %s; want %s", got, test.wantVersion)
+			}
+			if diff := cmp.Diff(test.wantEvents, events); diff != "" {
+				t.Errorf("wrong events\n%s", diff)
+			}
+		})
+	}
+}
+
+// testVersionStatusSource is a Source that reports fixed version statuses.
+type testVersionStatusSource struct {
+	getproviders.Source
+	statuses getproviders.VersionStatuses
+}
+
+func (s testVersionStatusSource) VersionStatuses(context.Context, addrs.Provider) (getproviders.VersionStatuses, error) {
+	return s.statuses, nil
+}
+
 // testServices starts up a local HTTP server running a fake provider registry
 // service and returns a service discovery object pre-configured to consider
 // the host "example.com" to be served by the fake registry service.
@@ -0,0 +1,42 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !windows
+// +build !windows
+
+package providercache
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// tryLockFile attempts to take an exclusive lock on the given file without
+// blocking, returning errLockHeld if another process already holds it.
+//
+// This uses fcntl POSIX locks, like the local state locks do, which are
+// released automatically if the process holding them exits.
+func tryLockFile(f *os.File) error {
+	flock := &syscall.Flock_t{
+		Type:   syscall.F_WRLCK,
+		Whence: int16(io.SeekStart),
+		Start:  0,
+		Len:    0,
+	}
+	err := syscall.FcntlFlock(f.Fd(), syscall.F_SETLK, flock)
+	if err == syscall.EAGAIN || err == syscall.EACCES {
+		return errLockHeld
+	}
+	return err
+}
+
+func unlockFile(f *os.File) error {
+	flock := &syscall.Flock_t{
+		Type:   syscall.F_UNLCK,
+		Whence: int16(io.SeekStart),
+		Start:  0,
+		Len:    0,
+	}
+	return syscall.FcntlFlock(f.Fd(), syscall.F_SETLK, flock)
+}
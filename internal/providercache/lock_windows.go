@@ -0,0 +1,76 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build windows
+// +build windows
+
+package providercache
+
+import (
+	"math"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	modkernel32      = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = modkernel32.NewProc("LockFileEx")
+	procUnlockFileEx = modkernel32.NewProc("UnlockFileEx")
+)
+
+const (
+	// dwFlags defined for LockFileEx
+	// https://msdn.microsoft.com/en-us/library/windows/desktop/aa365203(v=vs.85).aspx
+	_LOCKFILE_FAIL_IMMEDIATELY = 1
+	_LOCKFILE_EXCLUSIVE_LOCK   = 2
+
+	_ERROR_LOCK_VIOLATION = syscall.Errno(33)
+)
+
+// tryLockFile attempts to take an exclusive lock on the given file without
+// blocking, returning errLockHeld if another process already holds it.
+func tryLockFile(f *os.File) error {
+	ol := new(syscall.Overlapped)
+	r1, _, e1 := syscall.Syscall6(
+		procLockFileEx.Addr(),
+		6,
+		f.Fd(),
+		uintptr(_LOCKFILE_EXCLUSIVE_LOCK|_LOCKFILE_FAIL_IMMEDIATELY),
+		0,              // reserved
+		0,              // bytes low
+		math.MaxUint32, // bytes high
+		uintptr(unsafe.Pointer(ol)),
+	)
+	if r1 != 0 {
+		return nil
+	}
+	if e1 == _ERROR_LOCK_VIOLATION {
+		return errLockHeld
+	}
+	if e1 != 0 {
+		return error(e1)
+	}
+	return syscall.EINVAL
+}
+
+func unlockFile(f *os.File) error {
+	ol := new(syscall.Overlapped)
+	r1, _, e1 := syscall.Syscall6(
+		procUnlockFileEx.Addr(),
+		5,
+		f.Fd(),
+		0,              // reserved
+		0,              // bytes low
+		math.MaxUint32, // bytes high
+		uintptr(unsafe.Pointer(ol)),
+		0,
+	)
+	if r1 != 0 {
+		return nil
+	}
+	if e1 != 0 {
+		return error(e1)
+	}
+	return syscall.EINVAL
+}
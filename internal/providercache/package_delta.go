@@ -0,0 +1,290 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package providercache
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opentofu/opentofu/internal/getproviders"
+)
+
+// deltaPatchSuffix is the suffix of the name of an entry in a delta archive
+// which is a binary patch, rather than the full content of a file.
+const deltaPatchSuffix = ".tfdelta"
+
+// deltaPatchMagic is the first line of a binary patch.
+const deltaPatchMagic = "TFDELTA1\n"
+
+// packageFromDelta tries to build the package that the given meta describes
+// by applying one of its deltas to the package for the delta's base version
+// in one of the given directories, which can be much smaller to download
+// than the package itself.
+//
+// If it succeeds, it returns a meta describing the resulting package in a
+// local archive, and a function which the caller must call to remove the
+// archive once it's finished with it. It returns false if none of the
+// deltas can be used, in which case the caller should download the package
+// as usual.
+//
+// The resulting package must match one of the "h1:" hashes that the source
+// reported for the package, and we'll only use a delta if allowedHashes
+// includes at least one "h1:" hash or is empty, because the archive we build
+// won't match any "zh:" hashes.
+func packageFromDelta(ctx context.Context, meta getproviders.PackageMeta, allowedHashes []getproviders.Hash, dirs ...*Dir) (getproviders.PackageMeta, func(), bool) {
+	if len(meta.Deltas) == 0 {
+		return meta, nil, false
+	}
+	if len(allowedHashes) > 0 && len(zipHashes(allowedHashes)) == len(allowedHashes) {
+		log.Printf("[TRACE] providercache: not using deltas for %s v%s because the dependency lock file only has checksums of the full archive", meta.Provider, meta.Version)
+		return meta, nil, false
+	}
+	var wantHashes []getproviders.Hash
+	for _, hash := range meta.AcceptableHashes() {
+		if hash.HasScheme(getproviders.HashScheme1) {
+			wantHashes = append(wantHashes, hash)
+		}
+	}
+	if len(wantHashes) == 0 {
+		return meta, nil, false
+	}
+
+	for _, delta := range meta.Deltas {
+		var base *CachedProvider
+		for _, dir := range dirs {
+			if dir == nil {
+				continue
+			}
+			if base = dir.ProviderVersion(meta.Provider, delta.BaseVersion); base != nil {
+				break
+			}
+		}
+		if base == nil {
+			continue
+		}
+
+		log.Printf("[DEBUG] Building %s v%s from v%s using delta %s", meta.Provider, meta.Version, delta.BaseVersion, delta.Location)
+		archive, err := buildFromDelta(ctx, delta, base.PackageDir, wantHashes)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			log.Printf("[WARN] Failed to build %s v%s from v%s using delta %s, so downloading the full package instead: %s", meta.Provider, meta.Version, delta.BaseVersion, delta.Location, err)
+			continue
+		}
+
+		ret := meta
+		ret.Location = getproviders.PackageLocalArchive(archive)
+		ret.Deltas = nil
+		return ret, func() { os.Remove(archive) }, true
+	}
+	return meta, nil, false
+}
+
+// buildFromDelta downloads the given delta, applies it to the package in
+// baseDir, and checks that the result matches one of the given hashes,
+// returning the path of a temporary archive containing the result.
+func buildFromDelta(ctx context.Context, delta getproviders.PackageDelta, baseDir string, wantHashes []getproviders.Hash) (string, error) {
+	deltaFile, done, err := downloadPackage(ctx, delta.Location.String(), zipHashes(delta.Hashes), nil)
+	if err != nil {
+		return "", err
+	}
+	defer done()
+
+	outDir, err := os.MkdirTemp("", "terraform-provider-delta")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(outDir)
+
+	if err := applyPackageDelta(filepath.FromSlash(baseDir), deltaFile, outDir); err != nil {
+		return "", err
+	}
+	got, err := getproviders.PackageHashV1(getproviders.PackageLocalDir(outDir))
+	if err != nil {
+		return "", err
+	}
+	if !hashesContain(wantHashes, got) {
+		return "", fmt.Errorf("the result of applying the delta doesn't match the checksums that the provider source reported for the package")
+	}
+
+	archive, err := os.CreateTemp("", "terraform-provider")
+	if err != nil {
+		return "", err
+	}
+	if err := zipDir(outDir, archive); err != nil {
+		archive.Close()
+		os.Remove(archive.Name())
+		return "", err
+	}
+	if err := archive.Close(); err != nil {
+		os.Remove(archive.Name())
+		return "", err
+	}
+	return archive.Name(), nil
+}
+
+// applyPackageDelta writes the package that results from applying the delta
+// archive at deltaFile to the package in baseDir into outDir.
+func applyPackageDelta(baseDir, deltaFile, outDir string) error {
+	r, err := zip.OpenReader(deltaFile)
+	if err != nil {
+		return fmt.Errorf("invalid delta archive: %w", err)
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if !filepath.IsLocal(f.Name) {
+			return fmt.Errorf("invalid delta archive: entry %q is outside of the package", f.Name)
+		}
+		outPath := filepath.Join(outDir, f.Name)
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(outPath, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := applyDeltaEntry(baseDir, f, outDir); err != nil {
+			return fmt.Errorf("failed to apply delta archive entry %s: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func applyDeltaEntry(baseDir string, f *zip.File, outDir string) error {
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	name, isPatch := strings.CutSuffix(f.Name, deltaPatchSuffix)
+	outPath := filepath.Join(outDir, name)
+	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+		return err
+	}
+
+	if !isPatch {
+		out, err := os.OpenFile(outPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, src); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	}
+
+	base, err := os.Open(filepath.Join(baseDir, name))
+	if err != nil {
+		return err
+	}
+	defer base.Close()
+	info, err := base.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(outPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if err := applyPatch(base, src, out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// applyPatch writes the result of applying the given binary patch to base
+// into out.
+func applyPatch(base io.ReaderAt, patch io.Reader, out io.Writer) error {
+	r := bufio.NewReader(patch)
+	magic := make([]byte, len(deltaPatchMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != deltaPatchMagic {
+		return fmt.Errorf("not a binary patch")
+	}
+
+	for {
+		op, err := r.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch op {
+		case 'c':
+			offset, err := binary.ReadUvarint(r)
+			if err != nil {
+				return fmt.Errorf("invalid copy instruction: %w", err)
+			}
+			length, err := binary.ReadUvarint(r)
+			if err != nil {
+				return fmt.Errorf("invalid copy instruction: %w", err)
+			}
+			n, err := io.Copy(out, io.NewSectionReader(base, int64(offset), int64(length)))
+			if err != nil {
+				return err
+			}
+			if n != int64(length) {
+				return fmt.Errorf("copy instruction is outside of the base file")
+			}
+		case 'i':
+			length, err := binary.ReadUvarint(r)
+			if err != nil {
+				return fmt.Errorf("invalid insert instruction: %w", err)
+			}
+			if _, err := io.CopyN(out, r, int64(length)); err != nil {
+				return fmt.Errorf("invalid insert instruction: %w", err)
+			}
+		default:
+			return fmt.Errorf("invalid instruction %q", op)
+		}
+	}
+}
+
+// zipDir writes a zip archive of the files in the given directory to w.
+func zipDir(dir string, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		header.Method = zip.Deflate
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(fw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
@@ -0,0 +1,182 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package providercache
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/apparentlymart/go-versions/versions"
+
+	"github.com/opentofu/opentofu/internal/copy"
+	"github.com/opentofu/opentofu/internal/getproviders"
+)
+
+func TestPackageFromDelta(t *testing.T) {
+	testDownloadDir(t)
+	linuxPlatform := getproviders.Platform{OS: "linux", Arch: "amd64"}
+
+	// The base package is the usual test package, installed as v2.0.0.
+	baseDir := NewDirWithPlatform(t.TempDir(), linuxPlatform)
+	baseMeta := testSharedPackageMeta()
+	baseMeta.Version = versions.MustParseVersion("2.0.0")
+	if _, err := baseDir.InstallPackage(context.Background(), baseMeta, nil); err != nil {
+		t.Fatal(err)
+	}
+	base := baseDir.ProviderVersion(baseMeta.Provider, baseMeta.Version)
+	exe, err := base.ExecutableFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exeName := filepath.Base(exe)
+	exeContent, err := os.ReadFile(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The new package prefixes the executable and adds a file.
+	wantDir := t.TempDir()
+	if err := copy.CopyDir(wantDir, filepath.FromSlash(base.PackageDir)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wantDir, exeName), append([]byte("prefix"), exeContent...), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wantDir, "NEW.txt"), []byte("new file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wantHash, err := getproviders.PackageHashV1(getproviders.PackageLocalDir(wantDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var patch bytes.Buffer
+	patch.WriteString(deltaPatchMagic)
+	patch.WriteByte('i')
+	patch.Write(binary.AppendUvarint(nil, uint64(len("prefix"))))
+	patch.WriteString("prefix")
+	patch.WriteByte('c')
+	patch.Write(binary.AppendUvarint(nil, 0))
+	patch.Write(binary.AppendUvarint(nil, uint64(len(exeContent))))
+	var delta bytes.Buffer
+	zw := zip.NewWriter(&delta)
+	for name, content := range map[string][]byte{
+		exeName + deltaPatchSuffix: patch.Bytes(),
+		"NEW.txt":                  []byte("new file"),
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(content)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "delta.zip", time.Time{}, bytes.NewReader(delta.Bytes()))
+	}))
+	defer server.Close()
+
+	meta := getproviders.PackageMeta{
+		Provider:       baseMeta.Provider,
+		Version:        versions.MustParseVersion("2.1.0"),
+		TargetPlatform: linuxPlatform,
+		Filename:       "terraform-provider-null_2.1.0_linux_amd64.zip",
+		Location:       getproviders.PackageHTTPURL(server.URL + "/package.zip"),
+		Authentication: getproviders.NewPackageHashAuthentication(linuxPlatform, []getproviders.Hash{wantHash}),
+		Deltas: []getproviders.PackageDelta{
+			{
+				BaseVersion: versions.MustParseVersion("1.0.0"),
+				Location:    getproviders.PackageHTTPURL(server.URL + "/unavailable-base.zip"),
+			},
+			{
+				BaseVersion: baseMeta.Version,
+				Location:    getproviders.PackageHTTPURL(server.URL + "/delta.zip"),
+				Hashes:      []getproviders.Hash{testZipHash(delta.Bytes())},
+			},
+		},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		got, cleanup, ok := packageFromDelta(context.Background(), meta, nil, nil, baseDir)
+		if !ok {
+			t.Fatal("delta was not used")
+		}
+		defer cleanup()
+
+		targetDir := NewDirWithPlatform(t.TempDir(), linuxPlatform)
+		if _, err := targetDir.InstallPackage(context.Background(), got, []getproviders.Hash{wantHash}); err != nil {
+			t.Fatalf("failed to install the package built from the delta: %s", err)
+		}
+		installed := targetDir.ProviderVersion(meta.Provider, meta.Version)
+		if installed == nil {
+			t.Fatal("package not found after installation")
+		}
+		if ok, err := installed.MatchesHash(wantHash); err != nil || !ok {
+			t.Fatalf("installed package doesn't match: %v", err)
+		}
+	})
+	t.Run("result doesn't match", func(t *testing.T) {
+		meta := meta
+		meta.Authentication = getproviders.NewPackageHashAuthentication(linuxPlatform, []getproviders.Hash{
+			getproviders.HashScheme1.New("Cb9AtRrW4Ln+1H3f7bXHZ2BsAu1ctH0K8/kK5/BfVPw="),
+		})
+		if _, _, ok := packageFromDelta(context.Background(), meta, nil, baseDir); ok {
+			t.Fatal("delta was used, but its result doesn't match")
+		}
+	})
+	t.Run("lock file has only archive checksums", func(t *testing.T) {
+		allowed := []getproviders.Hash{testZipHash([]byte("archive"))}
+		if _, _, ok := packageFromDelta(context.Background(), meta, allowed, baseDir); ok {
+			t.Fatal("delta was used, but its result can't match the lock file")
+		}
+	})
+	t.Run("no base package", func(t *testing.T) {
+		emptyDir := NewDirWithPlatform(t.TempDir(), linuxPlatform)
+		if _, _, ok := packageFromDelta(context.Background(), meta, nil, emptyDir); ok {
+			t.Fatal("delta was used without a base package")
+		}
+	})
+}
+
+func TestApplyPatch(t *testing.T) {
+	base := []byte("hello world")
+	var patch bytes.Buffer
+	patch.WriteString(deltaPatchMagic)
+	patch.WriteByte('c')
+	patch.Write(binary.AppendUvarint(nil, 6))
+	patch.Write(binary.AppendUvarint(nil, 5))
+	patch.WriteByte('i')
+	patch.Write(binary.AppendUvarint(nil, 2))
+	patch.WriteString(", ")
+	patch.WriteByte('c')
+	patch.Write(binary.AppendUvarint(nil, 0))
+	patch.Write(binary.AppendUvarint(nil, 5))
+
+	var out bytes.Buffer
+	if err := applyPatch(bytes.NewReader(base), &patch, &out); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "world, hello"; got != want {
+		t.Fatalf("wrong result %q, want %q", got, want)
+	}
+
+	patch.Reset()
+	patch.WriteString(deltaPatchMagic)
+	patch.WriteByte('c')
+	patch.Write(binary.AppendUvarint(nil, 6))
+	patch.Write(binary.AppendUvarint(nil, 50))
+	if err := applyPatch(bytes.NewReader(base), &patch, &out); err == nil {
+		t.Fatal("copy outside of the base file succeeded")
+	}
+}
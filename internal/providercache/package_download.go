@@ -0,0 +1,327 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package providercache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	getter "github.com/hashicorp/go-getter"
+
+	"github.com/opentofu/opentofu/internal/getproviders"
+	"github.com/opentofu/opentofu/internal/httpclient"
+)
+
+// downloadAttempts is the number of times we try to download a package over
+// an unreliable connection before giving up. Each attempt after the first
+// continues from where the previous one stopped, if the server supports it.
+var downloadAttempts = 5
+
+// downloadRetryDelay is how long we wait between attempts to download a
+// package.
+var downloadRetryDelay = 2 * time.Second
+
+// downloadDir is where we keep packages while downloading them. A download
+// that's interrupted, even by the process exiting, leaves its partial file
+// here so that a later download of the same URL can continue from where it
+// stopped.
+var downloadDir = filepath.Join(os.TempDir(), "opentofu-provider-downloads")
+
+// errDownloadRestart is returned by an attempt to download a package which
+// found that it must start again from the beginning.
+var errDownloadRestart = errors.New("download must restart from the beginning")
+
+// download is a package file being downloaded.
+type download struct {
+	url  string
+	file *os.File
+
+	// resumable is true if the file is the partial file for the URL in
+	// downloadDir, and we hold the lock on it.
+	resumable bool
+
+	// prepare, if non-nil, adds credentials to each request.
+	prepare func(*http.Request) error
+
+	// limits, if non-nil, limits the download's bandwidth.
+	limits *DownloadLimits
+
+	// hash is the SHA256 hash of the content written to the file so far,
+	// which we maintain as we go rather than reading the whole file again
+	// at the end.
+	hash hash.Hash
+}
+
+// downloadPackage downloads the file at the given URL, continuing an earlier
+// download of the same URL that was interrupted if possible, and retrying
+// if the connection fails partway through.
+//
+// If prepare isn't nil then it's called to add credentials to each request.
+//
+// If zipHashes isn't empty then the downloaded file must match one of them.
+// If it doesn't, the partial file that the download continued from might
+// have been corrupt, so we start the download again from the beginning once
+// before giving up.
+//
+// On success, the caller must call the returned function to remove the file
+// once it's finished with it.
+func downloadPackage(ctx context.Context, url string, zipHashes []getproviders.Hash, prepare func(*http.Request) error) (string, func(), error) {
+	d, err := openDownload(url)
+	if err != nil {
+		return "", nil, err
+	}
+	d.prepare = prepare
+
+	// We wait for our turn before making any requests, so that waiting
+	// downloads don't hold connections open.
+	d.limits = downloadLimitsForContext(ctx)
+	release, err := d.limits.acquire(ctx)
+	if err != nil {
+		d.close()
+		if ctx.Err() == context.Canceled {
+			return "", nil, fmt.Errorf("provider download was interrupted")
+		}
+		return "", nil, err
+	}
+	defer release()
+
+	httpClient := httpclient.New()
+	restarted := false
+	for attempt := 1; ; attempt++ {
+		err := d.attempt(ctx, httpClient)
+		if err == nil {
+			got := getproviders.HashSchemeZip.New(hex.EncodeToString(d.hash.Sum(nil)))
+			if len(zipHashes) == 0 || hashesContain(zipHashes, got) {
+				break
+			}
+			if restarted {
+				d.discard()
+				return "", nil, fmt.Errorf("the package downloaded from %s doesn't match the checksums that the provider source reported for it", url)
+			}
+			log.Printf("[WARN] The package downloaded from %s doesn't match any expected checksum, so downloading it again from the beginning", url)
+			restarted = true
+			err = errDownloadRestart
+		}
+
+		if err == errDownloadRestart {
+			if err := d.reset(); err != nil {
+				d.close()
+				return "", nil, err
+			}
+			continue
+		}
+		var permanent permanentDownloadError
+		if ctx.Err() != nil || errors.As(err, &permanent) || attempt >= downloadAttempts {
+			d.close()
+			if ctx.Err() == context.Canceled {
+				// "context canceled" is not a user-friendly error message,
+				// so we'll return a more appropriate one here.
+				if d.resumable {
+					return "", nil, fmt.Errorf("provider download was interrupted; it will continue from where it stopped next time")
+				}
+				return "", nil, fmt.Errorf("provider download was interrupted")
+			}
+			return "", nil, err
+		}
+		log.Printf("[WARN] Attempt %d to download %s failed, so retrying: %s", attempt, url, err)
+		select {
+		case <-ctx.Done():
+		case <-time.After(downloadRetryDelay):
+		}
+	}
+
+	return d.file.Name(), d.discard, nil
+}
+
+// openDownload opens the partial file for the given URL, or a new temporary
+// file if another process is already downloading the same URL.
+func openDownload(url string) (*download, error) {
+	d := &download{
+		url:  url,
+		hash: sha256.New(),
+	}
+
+	urlHash := sha256.Sum256([]byte(url))
+	partialPath := filepath.Join(downloadDir, hex.EncodeToString(urlHash[:])+".partial")
+	if err := os.MkdirAll(downloadDir, 0700); err != nil {
+		log.Printf("[TRACE] providercache: can't create %s, so downloads can't be resumed: %s", downloadDir, err)
+	} else if f, err := os.OpenFile(partialPath, os.O_RDWR|os.O_CREATE, 0600); err != nil {
+		log.Printf("[TRACE] providercache: can't open %s, so the download of %s can't be resumed: %s", partialPath, url, err)
+	} else if err := tryLockFile(f); err != nil {
+		log.Printf("[TRACE] providercache: can't lock %s, so the download of %s can't be resumed: %s", partialPath, url, err)
+		f.Close()
+	} else {
+		d.file = f
+		d.resumable = true
+
+		// We'll continue from the end of whatever an earlier download left
+		// in the file, so our hash must include it.
+		if _, err := io.Copy(d.hash, f); err != nil {
+			d.close()
+			return nil, fmt.Errorf("failed to read partial download %s: %w", partialPath, err)
+		}
+		return d, nil
+	}
+
+	f, err := os.CreateTemp("", "terraform-provider")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open temporary file to download from %s: %w", url, err)
+	}
+	d.file = f
+	return d, nil
+}
+
+// attempt makes a single attempt to download the rest of the file.
+func (d *download) attempt(ctx context.Context, httpClient *http.Client) error {
+	offset, err := d.file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return permanentDownloadError{err}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", d.url, nil)
+	if err != nil {
+		return permanentDownloadError{fmt.Errorf("invalid provider download request: %w", err)}
+	}
+	if d.prepare != nil {
+		if err := d.prepare(req); err != nil {
+			return permanentDownloadError{fmt.Errorf("failed to prepare provider download request: %w", err)}
+		}
+	}
+	if offset > 0 {
+		log.Printf("[DEBUG] Continuing download of %s from byte %d", d.url, offset)
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", getproviders.HostFromRequest(req), err)
+	}
+	defer resp.Body.Close()
+
+	length := resp.ContentLength
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if offset > 0 {
+			// The server ignored our range request, so it's sending the
+			// whole file again.
+			if err := d.reset(); err != nil {
+				return permanentDownloadError{err}
+			}
+		}
+	case http.StatusPartialContent:
+		if start, ok := contentRangeStart(resp.Header.Get("Content-Range")); !ok || start != offset {
+			return errDownloadRestart
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is longer than the file on the server, so it
+		// can't be a prefix of it.
+		return errDownloadRestart
+	default:
+		err := fmt.Errorf("unsuccessful request to %s: %s", d.url, resp.Status)
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			return err
+		}
+		return permanentDownloadError{err}
+	}
+
+	// We'll borrow go-getter's "cancelable copy" implementation here so that
+	// the download can potentially be interrupted partway through.
+	n, err := getter.Copy(ctx, io.MultiWriter(d.file, d.hash), d.limits.reader(ctx, resp.Body))
+	if err == nil && n < length {
+		err = fmt.Errorf("incorrect response size: expected %d bytes, but got %d bytes", length, n)
+	}
+	return err
+}
+
+// reset discards the content of the file, so that the download starts again
+// from the beginning.
+func (d *download) reset() error {
+	if err := d.file.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate %s: %w", d.file.Name(), err)
+	}
+	if _, err := d.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to truncate %s: %w", d.file.Name(), err)
+	}
+	d.hash.Reset()
+	return nil
+}
+
+// close closes the file, keeping it for a later download to continue from
+// if it's resumable.
+func (d *download) close() {
+	if d.resumable {
+		if err := unlockFile(d.file); err != nil {
+			log.Printf("[WARN] Failed to unlock %s: %s", d.file.Name(), err)
+		}
+		d.file.Close()
+		return
+	}
+	d.file.Close()
+	os.Remove(d.file.Name())
+}
+
+// discard closes and removes the file.
+func (d *download) discard() {
+	d.close()
+	os.Remove(d.file.Name())
+}
+
+// permanentDownloadError is an error which retrying a download won't fix.
+type permanentDownloadError struct {
+	err error
+}
+
+func (e permanentDownloadError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentDownloadError) Unwrap() error {
+	return e.err
+}
+
+// contentRangeStart returns the first byte position of a Content-Range
+// header value like "bytes 100-199/200".
+func contentRangeStart(value string) (int64, bool) {
+	spec, ok := strings.CutPrefix(value, "bytes ")
+	if !ok {
+		return 0, false
+	}
+	start, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, false
+	}
+	ret, err := strconv.ParseInt(start, 10, 64)
+	return ret, err == nil
+}
+
+// zipHashes returns the "zh:" hashes among the given hashes.
+func zipHashes(hashes []getproviders.Hash) []getproviders.Hash {
+	var ret []getproviders.Hash
+	for _, hash := range hashes {
+		if hash.HasScheme(getproviders.HashSchemeZip) {
+			ret = append(ret, hash)
+		}
+	}
+	return ret
+}
+
+func hashesContain(hashes []getproviders.Hash, want getproviders.Hash) bool {
+	for _, hash := range hashes {
+		if hash == want {
+			return true
+		}
+	}
+	return false
+}
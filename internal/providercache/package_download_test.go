@@ -0,0 +1,168 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package providercache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opentofu/opentofu/internal/getproviders"
+)
+
+func TestDownloadPackage(t *testing.T) {
+	content := testDownloadContent()
+	hashes := []getproviders.Hash{testZipHash(content)}
+
+	t.Run("interrupted connection", func(t *testing.T) {
+		server, ranges := testDownloadServer(t, content, 1)
+		testDownloadDir(t)
+
+		got := testDownload(t, server.URL+"/package.zip", hashes)
+		if !bytes.Equal(got, content) {
+			t.Fatal("wrong content")
+		}
+		if len(*ranges) != 2 || (*ranges)[0] != "" || (*ranges)[1] != "bytes="+strconv.Itoa(len(content)/2)+"-" {
+			t.Fatalf("wrong requests %q", *ranges)
+		}
+	})
+	t.Run("partial file from earlier run", func(t *testing.T) {
+		server, ranges := testDownloadServer(t, content, 0)
+		dir := testDownloadDir(t)
+		url := server.URL + "/package.zip"
+		urlHash := sha256.Sum256([]byte(url))
+		partial := filepath.Join(dir, hex.EncodeToString(urlHash[:])+".partial")
+		if err := os.WriteFile(partial, content[:1000], 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		got := testDownload(t, url, hashes)
+		if !bytes.Equal(got, content) {
+			t.Fatal("wrong content")
+		}
+		if len(*ranges) != 1 || (*ranges)[0] != "bytes=1000-" {
+			t.Fatalf("wrong requests %q", *ranges)
+		}
+		if _, err := os.Stat(partial); !os.IsNotExist(err) {
+			t.Fatalf("partial file was not removed: %v", err)
+		}
+	})
+	t.Run("corrupt partial file", func(t *testing.T) {
+		server, ranges := testDownloadServer(t, content, 0)
+		dir := testDownloadDir(t)
+		url := server.URL + "/package.zip"
+		urlHash := sha256.Sum256([]byte(url))
+		partial := filepath.Join(dir, hex.EncodeToString(urlHash[:])+".partial")
+		if err := os.WriteFile(partial, bytes.Repeat([]byte("x"), 1000), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		got := testDownload(t, url, hashes)
+		if !bytes.Equal(got, content) {
+			t.Fatal("wrong content")
+		}
+		if len(*ranges) != 2 || (*ranges)[0] != "bytes=1000-" || (*ranges)[1] != "" {
+			t.Fatalf("wrong requests %q", *ranges)
+		}
+	})
+	t.Run("checksum mismatch", func(t *testing.T) {
+		server, _ := testDownloadServer(t, content, 0)
+		testDownloadDir(t)
+
+		_, _, err := downloadPackage(context.Background(), server.URL+"/package.zip", []getproviders.Hash{testZipHash([]byte("other"))}, nil)
+		if err == nil {
+			t.Fatal("download succeeded; want error")
+		}
+	})
+	t.Run("not found", func(t *testing.T) {
+		server, ranges := testDownloadServer(t, content, 0)
+		testDownloadDir(t)
+
+		_, _, err := downloadPackage(context.Background(), server.URL+"/missing.zip", nil, nil)
+		if err == nil {
+			t.Fatal("download succeeded; want error")
+		}
+		if len(*ranges) != 1 {
+			t.Fatalf("client errors are not retried, but made %d requests", len(*ranges))
+		}
+	})
+}
+
+func testDownload(t *testing.T, url string, hashes []getproviders.Hash) []byte {
+	t.Helper()
+	path, done, err := downloadPackage(context.Background(), url, hashes, nil)
+	if err != nil {
+		t.Fatalf("download failed: %s", err)
+	}
+	got, err := os.ReadFile(path)
+	done()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func testDownloadDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	oldDir, oldDelay := downloadDir, downloadRetryDelay
+	downloadDir, downloadRetryDelay = dir, 0
+	t.Cleanup(func() {
+		downloadDir, downloadRetryDelay = oldDir, oldDelay
+	})
+	return dir
+}
+
+// testDownloadServer serves the given content at /package.zip, supporting
+// range requests, but closes the connection halfway through the response
+// for the given number of requests first. It records the Range header of
+// each request.
+func testDownloadServer(t *testing.T, content []byte, failures int) (*httptest.Server, *[]string) {
+	t.Helper()
+	var mu sync.Mutex
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		fail := failures > 0
+		failures--
+		mu.Unlock()
+
+		if r.URL.Path != "/package.zip" {
+			http.NotFound(w, r)
+			return
+		}
+		if fail {
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			w.WriteHeader(http.StatusOK)
+			w.Write(content[:len(content)/2])
+			return
+		}
+		http.ServeContent(w, r, "package.zip", time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(server.Close)
+	return server, &ranges
+}
+
+func testDownloadContent() []byte {
+	var buf bytes.Buffer
+	for i := 0; buf.Len() < 100000; i++ {
+		buf.WriteString(strconv.Itoa(i))
+	}
+	return buf.Bytes()
+}
+
+func testZipHash(content []byte) getproviders.Hash {
+	sum := sha256.Sum256(content)
+	return getproviders.HashSchemeZip.New(hex.EncodeToString(sum[:]))
+}
@@ -6,7 +6,6 @@ package providercache
 import (
 	"context"
 	"fmt"
-	"net/http"
 	"os"
 	"path/filepath"
 
@@ -14,7 +13,6 @@ import (
 
 	"github.com/opentofu/opentofu/internal/copy"
 	"github.com/opentofu/opentofu/internal/getproviders"
-	"github.com/opentofu/opentofu/internal/httpclient"
 )
 
 // We borrow the "unpack a zip file into a target directory" logic from
@@ -28,51 +26,17 @@ func installFromHTTPURL(ctx context.Context, meta getproviders.PackageMeta, targ
 	url := meta.Location.String()
 
 	// When we're installing from an HTTP URL we expect the URL to refer to
-	// a zip file. We'll fetch that into a temporary file here and then
+	// a zip file. We'll fetch that into a local file here and then
 	// delegate to installFromLocalArchive below to actually extract it.
 	// (We're not using go-getter here because its HTTP getter has a bunch
 	// of extraneous functionality we don't need or want, like indirection
-	// through X-Terraform-Get header, attempting partial fetches for
-	// files that already exist, etc.)
-
-	httpClient := httpclient.New()
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("invalid provider download request: %w", err)
-	}
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		if ctx.Err() == context.Canceled {
-			// "context canceled" is not a user-friendly error message,
-			// so we'll return a more appropriate one here.
-			return nil, fmt.Errorf("provider download was interrupted")
-		}
-		return nil, fmt.Errorf("%s: %w", getproviders.HostFromRequest(req), err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unsuccessful request to %s: %s", url, resp.Status)
-	}
-
-	f, err := os.CreateTemp("", "terraform-provider")
-	if err != nil {
-		return nil, fmt.Errorf("failed to open temporary file to download from %s: %w", url, err)
-	}
-	defer f.Close()
-	defer os.Remove(f.Name())
-
-	// We'll borrow go-getter's "cancelable copy" implementation here so that
-	// the download can potentially be interrupted partway through.
-	n, err := getter.Copy(ctx, f, resp.Body)
-	if err == nil && n < resp.ContentLength {
-		err = fmt.Errorf("incorrect response size: expected %d bytes, but got %d bytes", resp.ContentLength, n)
-	}
+	// through X-Terraform-Get header, etc.)
+	archiveFilename, done, err := downloadPackage(ctx, url, zipHashes(meta.AcceptableHashes()), meta.PrepareRequest)
 	if err != nil {
 		return nil, err
 	}
+	defer done()
 
-	archiveFilename := f.Name()
 	localLocation := getproviders.PackageLocalArchive(archiveFilename)
 
 	var authResult *getproviders.PackageAuthenticationResult
@@ -0,0 +1,55 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package providers
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Capabilities describes the optional plugin protocol features that a
+// provider supports, so that OpenTofu can avoid relying on features that a
+// provider lacks and explain why when the configuration needs them.
+//
+// "tofu init" probes the capabilities of each provider it installs and
+// records them in the working directory, so that later commands can use
+// them without starting the providers.
+type Capabilities struct {
+	// PlanDestroy is true if the provider expects a PlanResourceChange
+	// call for resources that are to be destroyed.
+	PlanDestroy bool
+
+	// GetProviderSchemaOptional is true if the provider doesn't require
+	// a GetProviderSchema call before other calls.
+	GetProviderSchemaOptional bool
+
+	// Functions are the names of the functions the provider offers, in
+	// lexical order. Only providers built for plugin protocol 5.5 or 6.5 and
+	// later can offer functions.
+	Functions []string
+}
+
+// Capabilities returns the capabilities that the provider reported in its
+// schema.
+func (resp GetProviderSchemaResponse) Capabilities() Capabilities {
+	ret := Capabilities{
+		PlanDestroy:               resp.ServerCapabilities.PlanDestroy,
+		GetProviderSchemaOptional: resp.ServerCapabilities.GetProviderSchemaOptional,
+	}
+	for name := range resp.Functions {
+		ret.Functions = append(ret.Functions, name)
+	}
+	sort.Strings(ret.Functions)
+	return ret
+}
+
+// NoFunctionsReason returns an explanation, suitable for use in diagnostics,
+// of why the given provider offers no functions, or an empty string if it
+// offers some.
+func (c Capabilities) NoFunctionsReason(provider fmt.Stringer) string {
+	if len(c.Functions) > 0 {
+		return ""
+	}
+	return fmt.Sprintf("The provider %s doesn't offer any functions. Providers can offer functions only if they are built with plugin protocol version 5.5 or 6.5 or later, so a newer version of this provider might offer the function you need.", provider)
+}
@@ -0,0 +1,30 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package providers
+
+import (
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// PluginCrashed is the extra information of diagnostics reporting that a
+// provider plugin crashed, or otherwise stopped responding, while handling a
+// request. Use tfdiags.DiagnosticCausedByPluginCrash to recognize them.
+type PluginCrashed struct{}
+
+var _ tfdiags.DiagnosticExtraBecausePluginCrashed = PluginCrashed{}
+
+func (PluginCrashed) DiagnosticCausedByPluginCrash() bool {
+	return true
+}
+
+// PluginCrashedIn returns true if any of the given diagnostics report that
+// the provider plugin crashed.
+func PluginCrashedIn(diags tfdiags.Diagnostics) bool {
+	for _, diag := range diags {
+		if tfdiags.DiagnosticCausedByPluginCrash(diag) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,10 +3,19 @@
 
 package providers
 
+import (
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
 // Factory is a function type that creates a new instance of a resource
 // provider, or returns an error if that is impossible.
 type Factory func() (Interface, error)
 
+// ConfigFactory is a function type that creates a new instance of a resource
+// provider for use with the provider configuration with the given address,
+// or returns an error if that is impossible.
+type ConfigFactory func(addr addrs.AbsProviderConfig) (Interface, error)
+
 // FactoryFixed is a helper that creates a Factory that just returns some given
 // single provider.
 //
@@ -0,0 +1,142 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package providers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+)
+
+// FunctionDecl is the declaration of a function offered by a provider, as
+// returned in its schema.
+type FunctionDecl struct {
+	// Parameters are the positional parameters of the function.
+	Parameters []FunctionParam
+
+	// VariadicParameter, if set, describes the final parameter of the
+	// function, which accepts zero or more arguments of the same type.
+	VariadicParameter *FunctionParam
+
+	// ReturnType is the type constraint for the function's result.
+	ReturnType cty.Type
+
+	Summary            string
+	Description        string
+	DescriptionKind    configschema.StringKind
+	DeprecationMessage string
+}
+
+// FunctionParam describes one parameter of a provider function.
+type FunctionParam struct {
+	Name string
+
+	// Type is the type constraint for arguments passed to the parameter.
+	Type cty.Type
+
+	// AllowNullValue is true if the function can handle a null argument.
+	// Otherwise OpenTofu returns an error for a null argument without
+	// calling the provider.
+	AllowNullValue bool
+
+	// AllowUnknownValues is true if the function can handle arguments that
+	// are not wholly known. Otherwise OpenTofu returns an unknown result
+	// without calling the provider.
+	AllowUnknownValues bool
+
+	Description     string
+	DescriptionKind configschema.StringKind
+}
+
+type CallFunctionRequest struct {
+	// Name is the name of the function, as declared in the provider schema.
+	Name string
+
+	// Arguments are the values for the function's parameters, with any
+	// variadic arguments at the end.
+	Arguments []cty.Value
+}
+
+type CallFunctionResponse struct {
+	// Result is the value the function returned.
+	Result cty.Value
+
+	// Error is the reason the function failed, if it did. It is a
+	// *CallFunctionArgumentError if a particular argument caused the failure.
+	Error error
+}
+
+// CallFunctionArgumentError is an error returned by a provider function which
+// was caused by one of its arguments.
+type CallFunctionArgumentError struct {
+	Text string
+
+	// FunctionArgument is the index of the argument that caused the error,
+	// counting any variadic arguments as separate arguments.
+	FunctionArgument int
+}
+
+func (e *CallFunctionArgumentError) Error() string {
+	return e.Text
+}
+
+// BuildFunction returns a cty function with the declared signature that
+// calls the given function to get its result, which would typically call
+// CallFunction on an instance of the provider that declared the function.
+func (d FunctionDecl) BuildFunction(name string, call func(CallFunctionRequest) CallFunctionResponse) function.Function {
+	params := make([]function.Parameter, len(d.Parameters))
+	for i, param := range d.Parameters {
+		params[i] = param.ctyParameter()
+	}
+	var varParam *function.Parameter
+	if d.VariadicParameter != nil {
+		param := d.VariadicParameter.ctyParameter()
+		varParam = &param
+	}
+
+	return function.New(&function.Spec{
+		Description: d.Summary,
+		Params:      params,
+		VarParam:    varParam,
+		Type:        function.StaticReturnType(d.ReturnType),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			resp := call(CallFunctionRequest{
+				Name:      name,
+				Arguments: args,
+			})
+			if resp.Error != nil {
+				var argErr *CallFunctionArgumentError
+				if errors.As(resp.Error, &argErr) {
+					return cty.UnknownVal(retType), function.NewArgError(argErr.FunctionArgument, errors.New(argErr.Text))
+				}
+				return cty.UnknownVal(retType), resp.Error
+			}
+
+			result, err := convert.Convert(resp.Result, retType)
+			if err != nil {
+				return cty.UnknownVal(retType), fmt.Errorf("provider returned an invalid result for function %q: %w", name, err)
+			}
+			return result, nil
+		},
+	})
+}
+
+func (p FunctionParam) ctyParameter() function.Parameter {
+	return function.Parameter{
+		Name:         p.Name,
+		Description:  p.Description,
+		Type:         p.Type,
+		AllowNull:    p.AllowNullValue,
+		AllowUnknown: p.AllowUnknownValues,
+
+		// Types are checked against the parameter's type constraint, so
+		// arguments of dynamic type can wait for that.
+		AllowDynamicType: true,
+	}
+}
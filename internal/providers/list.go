@@ -0,0 +1,34 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package providers
+
+import (
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+type ListResourcesRequest struct {
+	// TypeName is the name of the managed resource type to list.
+	TypeName string
+}
+
+type ListResourcesResponse struct {
+	// Resources are the remote objects of the requested type.
+	Resources []ListedResource
+
+	// Diagnostics contains any warnings or errors from the method call.
+	Diagnostics tfdiags.Diagnostics
+}
+
+// ListedResource is a remote object returned by ListResources.
+type ListedResource struct {
+	// ID is the ID that imports the object.
+	ID string
+
+	// State is the current state of the object, conforming to the schema of
+	// its resource type, or cty.NilVal if the provider can only return its
+	// ID without reading it.
+	State cty.Value
+}
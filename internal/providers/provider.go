@@ -67,9 +67,20 @@ type Interface interface {
 	// ImportResourceState requests that the given resource be imported.
 	ImportResourceState(ImportResourceStateRequest) ImportResourceStateResponse
 
+	// ListResources returns the existing remote objects of a managed
+	// resource type, so that OpenTofu can find the objects that it doesn't
+	// manage yet. It's only called for providers which declare the
+	// ListResources server capability.
+	ListResources(ListResourcesRequest) ListResourcesResponse
+
 	// ReadDataSource returns the data source's current state.
 	ReadDataSource(ReadDataSourceRequest) ReadDataSourceResponse
 
+	// CallFunction calls one of the functions the provider declared in its
+	// schema. OpenTofu may call functions on a provider that has not been
+	// configured.
+	CallFunction(CallFunctionRequest) CallFunctionResponse
+
 	// Close shuts down the plugin process if applicable.
 	Close() error
 }
@@ -92,6 +103,10 @@ type GetProviderSchemaResponse struct {
 	// DataSources maps the data source name to that data source's schema.
 	DataSources map[string]Schema
 
+	// Functions maps the name of each function the provider offers to its
+	// declaration.
+	Functions map[string]FunctionDecl
+
 	// Diagnostics contains any warnings or errors from the method call.
 	Diagnostics tfdiags.Diagnostics
 
@@ -124,6 +139,15 @@ type ServerCapabilities struct {
 	// normally, and the caller can used a cached copy of the provider's
 	// schema.
 	GetProviderSchemaOptional bool
+
+	// ListResources signals that the provider implements ListResources for
+	// its managed resource types.
+	ListResources bool
+
+	// ChunkedCalls signals that the provider's plugin accepts calls with
+	// their requests and responses split into chunks, so that they aren't
+	// limited by the size of a single plugin protocol message.
+	ChunkedCalls bool
 }
 
 type ValidateProviderConfigRequest struct {
@@ -5,6 +5,10 @@ package refactoring
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/configs"
@@ -40,6 +44,12 @@ func FindMoveStatements(rootCfg *configs.Config) []MoveStatement {
 func findMoveStatements(cfg *configs.Config, into []MoveStatement) []MoveStatement {
 	modAddr := cfg.Path
 	for _, mc := range cfg.Module.Moved {
+		if mc.FromModuleSource != nil {
+			// Moves from other module packages are relative to a different
+			// module, so FindCrossPackageMoveStatements handles these.
+			continue
+		}
+
 		fromAddr, toAddr := addrs.UnifyMoveEndpoints(modAddr, mc.From, mc.To)
 		if fromAddr == nil || toAddr == nil {
 			// Invalid combination should've been caught during original
@@ -187,3 +197,192 @@ func haveMoveStatementForResource(addr addrs.AbsResource, stmts []MoveStatement)
 	}
 	return false
 }
+
+// FindCrossPackageMoveStatements returns statements for the "moved" blocks
+// that record moves from a module in a different package, which
+// FindMoveStatements ignores.
+//
+// The "from" address of such a block is relative to a module call in the
+// calling module. We find that call by comparing the source addresses of the
+// calls in the configuration with the block's from_module_source argument
+// or, if the call for the prior package has been removed, by looking in the
+// given state for a removed call whose objects match the "from" address and
+// the provider of the "to" resource. The state may be nil, in which case we
+// consider only the configuration.
+//
+// Each resulting statement is relative to the calling module, as if it had
+// been declared there.
+func FindCrossPackageMoveStatements(rootCfg *configs.Config, prevRunState *states.State) ([]MoveStatement, tfdiags.Diagnostics) {
+	var stmts []MoveStatement
+	var diags tfdiags.Diagnostics
+	rootCfg.DeepEach(func(cfg *configs.Config) {
+		for _, mc := range cfg.Module.Moved {
+			if mc.FromModuleSource == nil {
+				continue
+			}
+			stmt, moreDiags := crossPackageMoveStatement(rootCfg, cfg, mc, prevRunState)
+			diags = diags.Append(moreDiags)
+			if stmt != nil {
+				stmts = append(stmts, *stmt)
+			}
+		}
+	})
+	return stmts, diags
+}
+
+func crossPackageMoveStatement(rootCfg, cfg *configs.Config, mc *configs.Moved, prevRunState *states.State) (*MoveStatement, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	if cfg.Path.IsRoot() {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid cross-package move",
+			Detail:   "A moved block with from_module_source can only be used in a module called by another module, because its \"from\" address refers to a module call in the calling module.",
+			Subject:  mc.FromModuleSourceRange.Ptr(),
+		})
+		return nil, diags
+	}
+
+	parentCfg := cfg.Parent
+	callName := cfg.Path[len(cfg.Path)-1]
+	if call := parentCfg.Module.ModuleCalls[callName]; call != nil && (call.Count != nil || call.ForEach != nil) {
+		diags = diags.Append(crossPackageRepetitionDiag(mc, parentCfg.Path.Child(callName)))
+		return nil, diags
+	}
+
+	fromRes := mc.From.ConfigMoveable(nil).(addrs.ConfigResource)
+	toRes := mc.To.ConfigMoveable(cfg.Path).(addrs.ConfigResource)
+
+	// We prefer a module call that still uses the prior package, such as
+	// when a newer version of that package no longer declares the objects.
+	var candidates []string
+	for name, call := range parentCfg.Module.ModuleCalls {
+		if name == callName || call.SourceAddr == nil || !sameModulePackage(call.SourceAddr, mc.FromModuleSource) {
+			continue
+		}
+		if call.Count != nil || call.ForEach != nil {
+			diags = diags.Append(crossPackageRepetitionDiag(mc, parentCfg.Path.Child(name)))
+			continue
+		}
+		candidates = append(candidates, name)
+	}
+
+	if len(candidates) == 0 && prevRunState != nil {
+		candidates = removedModuleCallsWithResource(rootCfg, parentCfg, fromRes, toRes, prevRunState)
+	}
+	if diags.HasErrors() || len(candidates) == 0 {
+		// If there are no prior objects to move then there's nothing to do,
+		// which is normal for configurations that never used the prior
+		// package.
+		return nil, diags
+	}
+
+	sort.Strings(candidates)
+	if len(candidates) > 1 {
+		calls := make([]string, len(candidates))
+		for i, name := range candidates {
+			calls[i] = parentCfg.Path.Child(name).String()
+		}
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Ambiguous cross-package move",
+			Detail: fmt.Sprintf(
+				"This moved block records a move of %s from module package %q, but there are objects for that resource in more than one module call:\n  %s\n\nThe calling module must declare which of these objects to move using its own moved block.",
+				mc.From, mc.FromModuleSource, strings.Join(calls, "\n  "),
+			),
+			Subject: mc.DeclRange.Ptr(),
+		})
+		return nil, diags
+	}
+
+	fromAddr, toAddr := addrs.UnifyMoveEndpoints(parentCfg.Path, mc.From.InModuleCall(candidates[0]), mc.To.InModuleCall(callName))
+	if fromAddr == nil || toAddr == nil {
+		// Invalid combination should've been caught during original
+		// configuration decoding, in the configs package.
+		panic(fmt.Sprintf("incompatible move endpoints in %s", mc.DeclRange))
+	}
+	return &MoveStatement{
+		From:      fromAddr,
+		To:        toAddr,
+		DeclRange: tfdiags.SourceRangeFromHCL(mc.DeclRange),
+	}, diags
+}
+
+// removedModuleCallsWithResource returns the names of the module calls in the
+// given parent module that are no longer in the configuration but have state
+// for the given resource, relative to the call, using the same provider as
+// the given destination resource.
+func removedModuleCallsWithResource(rootCfg, parentCfg *configs.Config, fromRes, toRes addrs.ConfigResource, prevRunState *states.State) []string {
+	toCfg := rootCfg.Descendent(toRes.Module)
+	if toCfg == nil {
+		return nil
+	}
+	toResCfg := toCfg.Module.ResourceByAddr(toRes.Resource)
+	if toResCfg == nil {
+		// ValidateMoves reports moves to undeclared resources
+		return nil
+	}
+
+	found := make(map[string]bool)
+	parentPath := parentCfg.Path
+	for _, ms := range prevRunState.Modules {
+		modAddr := ms.Addr
+		if len(modAddr) != len(parentPath)+1+len(fromRes.Module) {
+			continue
+		}
+		if !modAddr.Module()[:len(parentPath)].Equal(parentPath) || !modAddr.Module()[len(parentPath)+1:].Equal(fromRes.Module) {
+			continue
+		}
+		step := modAddr[len(parentPath)]
+		if step.InstanceKey != addrs.NoKey || parentCfg.Module.ModuleCalls[step.Name] != nil {
+			continue
+		}
+		rs := ms.Resource(fromRes.Resource)
+		if rs == nil || rs.ProviderConfig.Provider != toResCfg.Provider {
+			continue
+		}
+		found[step.Name] = true
+	}
+
+	var names []string
+	for name := range found {
+		names = append(names, name)
+	}
+	return names
+}
+
+func crossPackageRepetitionDiag(mc *configs.Moved, call addrs.Module) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Invalid cross-package move",
+		Detail:   fmt.Sprintf("A moved block with from_module_source can only move objects between module calls without count or for_each, but %s uses count or for_each. The calling module must declare this move using its own moved block, with instance keys.", call),
+		Subject:  mc.DeclRange.Ptr(),
+	}
+}
+
+// sameModulePackage returns true if the two given source addresses refer to
+// the same module package, ignoring any version selection such as the "ref"
+// argument of a git source.
+func sameModulePackage(a, b addrs.ModuleSource) bool {
+	switch a := a.(type) {
+	case addrs.ModuleSourceLocal:
+		b, ok := b.(addrs.ModuleSourceLocal)
+		return ok && a == b
+	case addrs.ModuleSourceAlias:
+		b, ok := b.(addrs.ModuleSourceAlias)
+		return ok && a == b
+	case addrs.ModuleSourceRegistry:
+		b, ok := b.(addrs.ModuleSourceRegistry)
+		return ok && a.Package == b.Package && a.Subdir == b.Subdir
+	case addrs.ModuleSourceRemote:
+		b, ok := b.(addrs.ModuleSourceRemote)
+		if !ok || a.Subdir != b.Subdir {
+			return false
+		}
+		aPkg, _, _ := strings.Cut(a.Package.String(), "?")
+		bPkg, _, _ := strings.Cut(b.Package.String(), "?")
+		return aPkg == bPkg
+	default:
+		return false
+	}
+}
@@ -4,6 +4,7 @@
 package refactoring
 
 import (
+	"fmt"
 	"sort"
 	"testing"
 
@@ -191,3 +192,37 @@ func TestImpliedMoveStatements(t *testing.T) {
 		t.Errorf("wrong result\n%s", diff)
 	}
 }
+
+func TestSameModulePackage(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"./legacy", "./legacy", true},
+		{"./legacy", "./other", false},
+		{"hashicorp/consul/aws", "hashicorp/consul/aws", true},
+		{"hashicorp/consul/aws//modules/vpc", "hashicorp/consul/aws", false},
+		{"hashicorp/consul/aws", "hashicorp/vault/aws", false},
+		{"git::https://example.com/network.git?ref=v1.0.0", "git::https://example.com/network.git?ref=v2.0.0", true},
+		{"git::https://example.com/network.git", "git::https://example.com/network.git?ref=v2.0.0", true},
+		{"git::https://example.com/network.git//vpc", "git::https://example.com/network.git", false},
+		{"git::https://example.com/network.git", "git::https://example.com/compute.git", false},
+		{"./legacy", "git::https://example.com/legacy.git", false},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s %s", test.a, test.b), func(t *testing.T) {
+			a, err := addrs.ParseModuleSource(test.a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := addrs.ParseModuleSource(test.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sameModulePackage(a, b); got != test.want {
+				t.Errorf("wrong result %t; want %t", got, test.want)
+			}
+		})
+	}
+}
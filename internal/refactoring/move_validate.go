@@ -284,7 +284,7 @@ func movableObjectDeclRange(addr addrs.AbsMoveable, cfg *configs.Config) (tfdiag
 			return tfdiags.SourceRange{}, false
 		}
 
-		// If the call has either count or for_each set then we'll "blame"
+		// If the call has count, for_each or enabled set then we'll "blame"
 		// that expression, rather than the block as a whole, because it's
 		// the expression that decides which instances are available.
 		switch {
@@ -292,6 +292,8 @@ func movableObjectDeclRange(addr addrs.AbsMoveable, cfg *configs.Config) (tfdiag
 			return tfdiags.SourceRangeFromHCL(call.ForEach.Range()), true
 		case call.Count != nil:
 			return tfdiags.SourceRangeFromHCL(call.Count.Range()), true
+		case call.Enabled != nil:
+			return tfdiags.SourceRangeFromHCL(call.Enabled.Range()), true
 		default:
 			return tfdiags.SourceRangeFromHCL(call.DeclRange), true
 		}
@@ -0,0 +1,152 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package refactoring
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// RemoveStatement is the absolute form of a "removed" block in the
+// configuration, recording that a resource or module call is no longer
+// declared.
+type RemoveStatement struct {
+	// From is either an addrs.ConfigResource or an addrs.Module.
+	From addrs.ConfigMoveable
+
+	// Except lists the resources and module calls inside a removed module
+	// whose objects must not be destroyed because of this statement.
+	Except []addrs.ConfigMoveable
+
+	// Config is the "removed" block this statement was derived from.
+	Config *configs.Removed
+
+	DeclRange tfdiags.SourceRange
+}
+
+// FindRemoveStatements recurses through the modules of the given configuration
+// and returns a flat set of all "removed" blocks defined within, in a
+// deterministic but undefined order.
+//
+// It also returns error diagnostics for any statement whose object is still
+// declared in the configuration.
+func FindRemoveStatements(rootCfg *configs.Config) ([]RemoveStatement, tfdiags.Diagnostics) {
+	stmts := findRemoveStatements(rootCfg, nil)
+
+	var diags tfdiags.Diagnostics
+	for _, stmt := range stmts {
+		switch from := stmt.From.(type) {
+		case addrs.ConfigResource:
+			modCfg := rootCfg.Descendent(from.Module)
+			if modCfg == nil || modCfg.Module.ResourceByAddr(from.Resource) == nil {
+				continue
+			}
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Removed resource still exists",
+				Detail:   fmt.Sprintf("This removed block refers to %s, which is still declared in the configuration. Either remove the resource block or remove this removed block.", from),
+				Subject:  stmt.DeclRange.ToHCL().Ptr(),
+			})
+		case addrs.Module:
+			if rootCfg.Descendent(from) == nil {
+				continue
+			}
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Removed module still exists",
+				Detail:   fmt.Sprintf("This removed block refers to %s, which is still declared in the configuration. Either remove the module block or remove this removed block.", from),
+				Subject:  stmt.DeclRange.ToHCL().Ptr(),
+			})
+		}
+	}
+
+	return stmts, diags
+}
+
+func findRemoveStatements(cfg *configs.Config, into []RemoveStatement) []RemoveStatement {
+	modAddr := cfg.Path
+	for _, rc := range cfg.Module.Removed {
+		stmt := RemoveStatement{
+			From:      rc.From.ConfigMoveable(modAddr),
+			Config:    rc,
+			DeclRange: tfdiags.SourceRangeFromHCL(rc.DeclRange),
+		}
+		for _, except := range rc.Except {
+			stmt.Except = append(stmt.Except, except.ConfigMoveable(modAddr))
+		}
+		into = append(into, stmt)
+	}
+
+	for _, childCfg := range cfg.Children {
+		into = findRemoveStatements(childCfg, into)
+	}
+
+	return into
+}
+
+// ValidateRemoveExceptions checks that the given state has no objects for
+// the exceptions of the given remove statements.
+//
+// OpenTofu destroys all of the objects of a removed module, so an exception
+// can only be satisfied by moving its objects to a new address with a
+// "moved" block, or by removing them from the state. This must therefore
+// be called with the state after applying any moves.
+func ValidateRemoveExceptions(stmts []RemoveStatement, state *states.State) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	if state == nil {
+		return diags
+	}
+
+	for _, stmt := range stmts {
+		for _, except := range stmt.Except {
+			var remaining []string
+			for _, ms := range state.Modules {
+				for _, rs := range ms.Resources {
+					if rs.Addr.Resource.Mode != addrs.ManagedResourceMode {
+						continue // data resources are removed from state automatically
+					}
+					if removeExceptionContains(except, rs.Addr.Config()) {
+						remaining = append(remaining, rs.Addr.String())
+					}
+				}
+			}
+			if len(remaining) == 0 {
+				continue
+			}
+			sort.Strings(remaining)
+
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Excepted objects would be destroyed",
+				Detail: fmt.Sprintf(
+					"This removed block excludes %s from destruction, but the state still has objects for the following resources, which are no longer declared in the configuration:\n  %s\n\nTo keep these objects, move them to a new address using a moved block, or stop managing them using the \"tofu state rm\" command.",
+					except, strings.Join(remaining, "\n  "),
+				),
+				Subject: stmt.DeclRange.ToHCL().Ptr(),
+			})
+		}
+	}
+
+	return diags
+}
+
+func removeExceptionContains(except addrs.ConfigMoveable, addr addrs.ConfigResource) bool {
+	switch except := except.(type) {
+	case addrs.ConfigResource:
+		return except.Equal(addr)
+	case addrs.Module:
+		return except.TargetContains(addr)
+	default:
+		// No other types should be possible
+		panic(fmt.Sprintf("unsupported ConfigMoveable type %T", except))
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package refactoring
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+func TestFindRemoveStatements(t *testing.T) {
+	rootCfg, _ := loadRefactoringFixture(t, "testdata/remove-statement")
+
+	stmts, diags := FindRemoveStatements(rootCfg)
+
+	var gotStmts []string
+	for _, stmt := range stmts {
+		gotStmts = append(gotStmts, fmt.Sprintf("%s except %s", stmt.From, stmt.Except))
+	}
+	wantStmts := []string{
+		"test.gone except []",
+		"module.legacy except [module.legacy.test.logs]",
+		"test.kept except []",
+		"module.child except []",
+		"module.child.test.gone except []",
+	}
+	if diff := cmp.Diff(wantStmts, gotStmts); diff != "" {
+		t.Errorf("wrong statements\n%s", diff)
+	}
+
+	var gotDiags []string
+	for _, diag := range diags {
+		gotDiags = append(gotDiags, diag.Description().Summary)
+	}
+	wantDiags := []string{
+		"Removed resource still exists",
+		"Removed module still exists",
+	}
+	if diff := cmp.Diff(wantDiags, gotDiags); diff != "" {
+		t.Errorf("wrong diagnostics\n%s", diff)
+	}
+}
+
+func TestValidateRemoveExceptions(t *testing.T) {
+	mustParseInstAddr := func(s string) addrs.AbsResourceInstance {
+		addr, err := addrs.ParseAbsResourceInstanceStr(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return addr
+	}
+
+	stmts := []RemoveStatement{
+		{
+			From: addrs.Module{"legacy"},
+			Except: []addrs.ConfigMoveable{
+				mustParseInstAddr("module.legacy.test.logs").ConfigResource(),
+				addrs.Module{"legacy", "network"},
+			},
+		},
+	}
+
+	state := states.BuildState(func(s *states.SyncState) {
+		for _, addr := range []string{
+			"module.legacy.test.app",
+			"module.legacy.test.logs",
+			"module.legacy.module.network.test.vpc",
+			`module.legacy.module.network["b"].test.subnet`,
+		} {
+			s.SetResourceInstanceCurrent(
+				mustParseInstAddr(addr),
+				&states.ResourceInstanceObjectSrc{Status: states.ObjectReady},
+				addrs.AbsProviderConfig{Provider: addrs.NewDefaultProvider("test"), Module: addrs.RootModule},
+			)
+		}
+	})
+
+	diags := ValidateRemoveExceptions(stmts, state)
+	if len(diags) != 2 {
+		t.Fatalf("wrong number of diagnostics %d; want 2\n%s", len(diags), diags.Err())
+	}
+	got := diags.Err().Error()
+	for _, want := range []string{
+		"module.legacy.test.logs",
+		"module.legacy.module.network.test.vpc",
+		`module.legacy.module.network["b"].test.subnet`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("wrong error\ngot:  %s\nwant: message containing %q", got, want)
+		}
+	}
+	if strings.Contains(got, "module.legacy.test.app") {
+		t.Errorf("error mentions module.legacy.test.app, which isn't an exception\n%s", got)
+	}
+}
@@ -0,0 +1,3 @@
+removed {
+  from = test.gone
+}
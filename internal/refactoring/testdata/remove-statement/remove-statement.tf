@@ -0,0 +1,23 @@
+module "child" {
+  source = "./child"
+}
+
+resource "test" "kept" {
+}
+
+removed {
+  from = test.gone
+}
+
+removed {
+  from   = module.legacy
+  except = [module.legacy.test.logs]
+}
+
+removed {
+  from = test.kept
+}
+
+removed {
+  from = module.child
+}
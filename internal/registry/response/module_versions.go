@@ -3,6 +3,8 @@
 
 package response
 
+import "fmt"
+
 // ModuleVersions is the response format that contains all metadata about module
 // versions needed for tofu CLI to resolve version constraints. See RFC
 // TF-042 for details on this format.
@@ -23,6 +25,35 @@ type ModuleVersion struct {
 	Version    string              `json:"version"`
 	Root       VersionSubmodule    `json:"root"`
 	Submodules []*VersionSubmodule `json:"submodules"`
+
+	// Deprecation is set if the publisher recommends against using this
+	// version, although it can still be installed.
+	Deprecation *VersionNotice `json:"deprecation,omitempty"`
+
+	// Yanked is set if the publisher has withdrawn this version, so that
+	// it must not be installed.
+	Yanked *VersionNotice `json:"yanked,omitempty"`
+}
+
+// VersionNotice is a publisher's explanation of why a module version has
+// been deprecated or yanked.
+type VersionNotice struct {
+	Reason string `json:"reason"`
+
+	// Link is an optional URL with more information, such as upgrade
+	// instructions.
+	Link string `json:"link,omitempty"`
+}
+
+func (n *VersionNotice) String() string {
+	switch {
+	case n.Reason != "" && n.Link != "":
+		return fmt.Sprintf("%s (%s)", n.Reason, n.Link)
+	case n.Link != "":
+		return n.Link
+	default:
+		return n.Reason
+	}
 }
 
 // VersionSubmodule is the output metadata for a submodule within a given
@@ -41,8 +41,10 @@ func Disco(s *httptest.Server) *disco.Disco {
 // Map of module names and location of test modules.
 // Only one version for now, as we only lookup latest from the registry.
 type testMod struct {
-	location string
-	version  string
+	location    string
+	version     string
+	deprecation *response.VersionNotice
+	yanked      *response.VersionNotice
 }
 
 // Map of provider names and location of test providers.
@@ -96,6 +98,17 @@ var testMods = map[string][]testMod{
 	"private/name/provider": {
 		{version: "1.0.0"},
 	},
+	"withdrawn/name/provider": {
+		{
+			location:    "file:///download/withdrawn/name/provider/1.0.0",
+			version:     "1.0.0",
+			deprecation: &response.VersionNotice{Reason: "use 2.0.0"},
+		},
+		{
+			version: "1.1.0",
+			yanked:  &response.VersionNotice{Reason: "broken", Link: "https://example.com/withdrawn"},
+		},
+	},
 }
 
 var testProviders = map[string][]testProvider{
@@ -203,7 +216,9 @@ func mockRegHandler() http.Handler {
 
 		for _, v := range versions {
 			mv := &response.ModuleVersion{
-				Version: v.version,
+				Version:     v.version,
+				Deprecation: v.deprecation,
+				Yanked:      v.yanked,
 			}
 			mpvs.Versions = append(mpvs.Versions, mv)
 		}
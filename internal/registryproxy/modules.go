@@ -0,0 +1,202 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package registryproxy
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	svchost "github.com/hashicorp/terraform-svchost"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+// serveModules implements the module registry protocol. The segments are
+// those of the request path after /v1/modules/, starting with the upstream
+// hostname.
+func (s *Server) serveModules(w http.ResponseWriter, req *http.Request, segments []string) {
+	var version string
+	switch {
+	case len(segments) == 5 && segments[4] == "versions":
+	case len(segments) == 6 && segments[5] == "download":
+		version = segments[4]
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if !validSegments(segments[:len(segments)-1]...) {
+		writeError(w, http.StatusBadRequest, "invalid module address")
+		return
+	}
+	host, err := s.upstreamHost(segments[0])
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	module := path.Join(segments[1:4]...)
+
+	if version == "" {
+		s.cachedVersions(
+			w, req, host, modulesServiceID,
+			path.Join(module, "versions"),
+			path.Join(host.String(), "modules", module, "versions.json"),
+		)
+		return
+	}
+
+	location, status, err := s.moduleLocation(req.Context(), host, module, version)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	w.Header().Set("X-Terraform-Get", s.signURL(location))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// moduleLocation returns the location of the server's copy of the package
+// of the given version of the given module, downloading it first if
+// necessary. If it fails, it also returns the status code to respond with.
+func (s *Server) moduleLocation(ctx context.Context, host svchost.Hostname, module, version string) (string, int, error) {
+	metaPath := path.Join(host.String(), "modules", module, version, "location")
+	unlock := s.lock(metaPath)
+	defer unlock()
+
+	metaFilename := filepath.Join(s.dir, "meta", filepath.FromSlash(metaPath))
+	if location, err := os.ReadFile(metaFilename); err == nil {
+		return string(location), http.StatusOK, nil
+	}
+
+	resp, err := s.upstreamGet(ctx, host, modulesServiceID, path.Join(module, version, "download"))
+	if err != nil {
+		return "", http.StatusBadGateway, err
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+	case http.StatusNotFound:
+		return "", http.StatusNotFound, fmt.Errorf("module %s version %s not found in the registry at %s", module, version, host.ForDisplay())
+	default:
+		return "", http.StatusBadGateway, fmt.Errorf("registry at %s returned %s for module %s version %s", host.ForDisplay(), resp.Status, module, version)
+	}
+
+	upstreamLocation := resp.Header.Get("X-Terraform-Get")
+	if upstreamLocation == "" {
+		return "", http.StatusBadGateway, fmt.Errorf("registry at %s returned no download location for module %s version %s", host.ForDisplay(), module, version)
+	}
+	// As in the registry client, a location that looks like a relative URL
+	// is relative to the URL of the download request.
+	if strings.HasPrefix(upstreamLocation, "/") || strings.HasPrefix(upstreamLocation, "./") || strings.HasPrefix(upstreamLocation, "../") {
+		locationURL, err := url.Parse(upstreamLocation)
+		if err != nil {
+			return "", http.StatusBadGateway, fmt.Errorf("invalid download location for module %s version %s: %w", module, version, err)
+		}
+		upstreamLocation = resp.Request.URL.ResolveReference(locationURL).String()
+	}
+	source, err := addrs.ParseModuleSource(upstreamLocation)
+	if err != nil {
+		return "", http.StatusBadGateway, fmt.Errorf("invalid download location for module %s version %s: %w", module, version, err)
+	}
+	remote, ok := source.(addrs.ModuleSourceRemote)
+	if !ok {
+		return "", http.StatusBadGateway, fmt.Errorf("invalid download location for module %s version %s: must be a remote package, not %q", module, version, upstreamLocation)
+	}
+
+	tmpDir, err := os.MkdirTemp(s.dir, ".module-*")
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	defer os.RemoveAll(tmpDir)
+	pkgDir := filepath.Join(tmpDir, "package")
+	log.Printf("[DEBUG] registryproxy: fetching %s for module %s version %s", remote.Package, module, version)
+	if err := s.fetcher.FetchPackage(ctx, pkgDir, remote.Package.String()); err != nil {
+		return "", http.StatusBadGateway, fmt.Errorf("failed to download module %s version %s: %w", module, version, err)
+	}
+
+	filePath := path.Join(host.String(), "modules", module, version+".zip")
+	archive := filepath.Join(tmpDir, "package.zip")
+	if err := zipDir(pkgDir, archive); err != nil {
+		return "", http.StatusInternalServerError, fmt.Errorf("failed to archive module %s version %s: %w", module, version, err)
+	}
+	filename := filepath.Join(s.dir, "files", filepath.FromSlash(filePath))
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	if err := os.Rename(archive, filename); err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+
+	location := filesURL(filePath)
+	if remote.Subdir != "" {
+		location += "//" + remote.Subdir
+	}
+	if err := writeFileAtomic(metaFilename, []byte(location)); err != nil {
+		log.Printf("[WARN] registryproxy: failed to cache %s: %s", metaPath, err)
+	}
+	return location, http.StatusOK, nil
+}
+
+// zipDir writes a zip archive of the regular files in the given directory,
+// other than the contents of any .git directories, to the given file.
+func zipDir(dir, filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		header.Method = zip.Deflate
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		src, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(w, src)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,219 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package registryproxy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+
+	svchost "github.com/hashicorp/terraform-svchost"
+
+	"github.com/opentofu/opentofu/internal/getproviders"
+)
+
+// serveProviders implements the provider registry protocol. The segments
+// are those of the request path after /v1/providers/, starting with the
+// upstream hostname.
+func (s *Server) serveProviders(w http.ResponseWriter, req *http.Request, segments []string) {
+	var version string
+	var platform getproviders.Platform
+	switch {
+	case len(segments) == 4 && segments[3] == "versions":
+		if !validSegments(segments[:3]...) {
+			writeError(w, http.StatusBadRequest, "invalid provider address")
+			return
+		}
+	case len(segments) == 7 && segments[4] == "download":
+		if !validSegments(segments[:3]...) {
+			writeError(w, http.StatusBadRequest, "invalid provider address")
+			return
+		}
+		v, err := getproviders.ParseVersion(segments[3])
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid provider version %q", segments[3]))
+			return
+		}
+		p, err := getproviders.ParsePlatform(segments[5] + "_" + segments[6])
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		version, platform = v.String(), p
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	host, err := s.upstreamHost(segments[0])
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	provider := path.Join(segments[1:3]...)
+
+	if version == "" {
+		s.cachedVersions(
+			w, req, host, providersServiceID,
+			path.Join(provider, "versions"),
+			path.Join(host.String(), "providers", provider, "versions.json"),
+		)
+		return
+	}
+
+	body, status, err := s.providerPackage(req.Context(), host, provider, version, platform)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	body, err = s.signPackageURLs(body)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, body)
+}
+
+// providerPackageURLKeys are the properties of the response to the "find a
+// provider package" request that providerPackage replaces with the URLs of
+// the server's own copies.
+var providerPackageURLKeys = []string{"download_url", "shasums_url", "shasums_signature_url"}
+
+// signPackageURLs adds signatures to the URLs of the server's copies in the
+// given response to the "find a provider package" request, if the server
+// requires client tokens.
+func (s *Server) signPackageURLs(body []byte) ([]byte, error) {
+	if len(s.clientTokens) == 0 {
+		return body, nil
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+	for _, key := range providerPackageURLKeys {
+		if u, ok := raw[key].(string); ok && u != "" {
+			raw[key] = s.signURL(u)
+		}
+	}
+	return json.Marshal(raw)
+}
+
+// providerPackageMeta is the part of the response to the provider registry
+// protocol's "find a provider package" request that refers to the files the
+// server makes copies of.
+type providerPackageMeta struct {
+	Filename               string `json:"filename"`
+	DownloadURL            string `json:"download_url"`
+	SHA256Sum              string `json:"shasum"`
+	SHA256SumsURL          string `json:"shasums_url"`
+	SHA256SumsSignatureURL string `json:"shasums_signature_url"`
+}
+
+// providerPackage returns the response to the provider registry protocol's
+// "find a provider package" request for the given provider version and
+// platform, with the server's own copies of the package and its checksums
+// and signature, downloading them first if necessary. If it fails, it also
+// returns the status code to respond with.
+func (s *Server) providerPackage(ctx context.Context, host svchost.Hostname, provider, version string, platform getproviders.Platform) ([]byte, int, error) {
+	metaPath := path.Join(host.String(), "providers", provider, version, "download", platform.String()+".json")
+	unlock := s.lock(metaPath)
+	defer unlock()
+
+	metaFilename := filepath.Join(s.dir, "meta", filepath.FromSlash(metaPath))
+	if body, err := os.ReadFile(metaFilename); err == nil {
+		return body, http.StatusOK, nil
+	}
+
+	body, reqURL, status, err := s.upstreamJSON(ctx, host, providersServiceID, path.Join(provider, version, "download", platform.OS, platform.Arch))
+	if err != nil {
+		if status != http.StatusNotFound {
+			status = http.StatusBadGateway
+		}
+		return nil, status, err
+	}
+
+	// We decode the response twice so that we can return it with only the
+	// URLs changed, including any properties we don't know about.
+	var meta providerPackageMeta
+	var raw map[string]interface{}
+	if err := json.Unmarshal(body, &meta); err != nil {
+		return nil, http.StatusBadGateway, fmt.Errorf("registry at %s returned an invalid response for provider %s version %s: %w", host.ForDisplay(), provider, version, err)
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, http.StatusBadGateway, fmt.Errorf("registry at %s returned an invalid response for provider %s version %s: %w", host.ForDisplay(), provider, version, err)
+	}
+
+	// The checksums and signature are the same for all platforms, so each
+	// version has only one copy of them.
+	dir := path.Join(host.String(), "providers", provider, version)
+	packageName := path.Base(meta.Filename)
+	if !validSegments(packageName) || packageName == "SHA256SUMS" || packageName == "SHA256SUMS.sig" {
+		packageName = platform.String() + ".zip"
+	}
+	files := []struct {
+		key, url, name string
+	}{
+		{"download_url", meta.DownloadURL, packageName},
+		{"shasums_url", meta.SHA256SumsURL, "SHA256SUMS"},
+		{"shasums_signature_url", meta.SHA256SumsSignatureURL, "SHA256SUMS.sig"},
+	}
+	for _, file := range files {
+		if file.url == "" {
+			continue
+		}
+		u, err := url.Parse(file.url)
+		if err != nil {
+			return nil, http.StatusBadGateway, fmt.Errorf("registry at %s returned an invalid %s for provider %s version %s: %w", host.ForDisplay(), file.key, provider, version, err)
+		}
+		u = reqURL.ResolveReference(u)
+		filePath := path.Join(dir, file.name)
+		filename, err := s.download(ctx, u, filePath)
+		if err != nil {
+			return nil, http.StatusBadGateway, fmt.Errorf("failed to download %s for provider %s version %s: %w", file.key, provider, version, err)
+		}
+		if file.key == "download_url" && meta.SHA256Sum != "" {
+			if err := verifySHA256(filename, meta.SHA256Sum); err != nil {
+				return nil, http.StatusBadGateway, fmt.Errorf("package for provider %s version %s: %w", provider, version, err)
+			}
+		}
+		raw[file.key] = filesURL(filePath)
+	}
+
+	body, err = json.Marshal(raw)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if err := writeFileAtomic(metaFilename, body); err != nil {
+		log.Printf("[WARN] registryproxy: failed to cache %s: %s", metaPath, err)
+	}
+	return body, http.StatusOK, nil
+}
+
+// verifySHA256 checks that the file with the given name has the given
+// hex-encoded SHA256 checksum, removing it if it doesn't.
+func verifySHA256(filename, want string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != want {
+		os.Remove(filename)
+		return fmt.Errorf("checksum %s does not match the expected %s", got, want)
+	}
+	return nil
+}
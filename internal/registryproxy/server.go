@@ -0,0 +1,483 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package registryproxy implements a caching pull-through proxy for the
+// module and provider registry protocols, so that many OpenTofu installations
+// can share one local copy of the modules and providers they use instead of
+// each downloading them from the upstream registries.
+package registryproxy
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	svchost "github.com/hashicorp/terraform-svchost"
+	"github.com/hashicorp/terraform-svchost/disco"
+
+	"github.com/opentofu/opentofu/internal/getmodules"
+	"github.com/opentofu/opentofu/internal/httpclient"
+	"github.com/opentofu/opentofu/version"
+)
+
+const (
+	modulesServiceID   = "modules.v1"
+	providersServiceID = "providers.v1"
+
+	// DefaultVersionsMaxAge is the default for Config.VersionsMaxAge.
+	DefaultVersionsMaxAge = 5 * time.Minute
+)
+
+// Config configures a Server.
+type Config struct {
+	// Dir is the directory where the server caches the responses of the
+	// upstream registries and the packages they refer to. It is created if
+	// it doesn't already exist.
+	Dir string
+
+	// Services discovers the module and provider registry services of the
+	// upstream registries, and provides the credentials to use with them.
+	Services *disco.Disco
+
+	// Hosts are the upstream registry hosts that the server proxies
+	// requests to. Requests for any other host are rejected, so that the
+	// server can't be used to make requests to arbitrary hosts.
+	Hosts []svchost.Hostname
+
+	// VersionsMaxAge is how long the server uses a cached list of the
+	// available versions of a module or provider before asking the upstream
+	// registry for it again. If the upstream registry can't be reached, the
+	// server uses the cached list regardless of its age.
+	//
+	// The metadata and packages of particular versions never change, and
+	// so the server keeps them indefinitely.
+	VersionsMaxAge time.Duration
+
+	// AllowCredentials allows proxying upstream hosts that Services has
+	// credentials for. The server serves whatever it can fetch with those
+	// credentials to anyone who can make requests to it, so New refuses
+	// such hosts unless this is set, and callers should only set it along
+	// with ClientTokens or on a trusted network.
+	AllowCredentials bool
+
+	// ClientTokens, if not empty, are the bearer tokens that clients must
+	// present in the Authorization header of their registry protocol
+	// requests. OpenTofu sends the credentials configured for the upstream
+	// host, so clients configure one of these tokens as the credentials for
+	// each upstream host.
+	//
+	// OpenTofu doesn't send credentials when it downloads packages, so the
+	// server instead adds a signature to the URLs of the packages in its
+	// responses to authenticated requests, and only serves packages at
+	// correctly signed URLs. The signing key is random for each Server, so
+	// the URLs don't work after the server restarts.
+	ClientTokens []string
+}
+
+// Server is an http.Handler implementing the module and provider registry
+// protocols on behalf of the upstream registries given in its Config.
+//
+// The address of each module or provider includes the hostname of its
+// upstream registry, so that one server can serve several upstream
+// registries:
+//
+//	/v1/modules/HOSTNAME/NAMESPACE/NAME/SYSTEM/...
+//	/v1/providers/HOSTNAME/NAMESPACE/TYPE/...
+//
+// OpenTofu can be pointed at a server by overriding the service discovery of
+// each upstream host in the CLI configuration, with a "host" block whose
+// "modules.v1" and "providers.v1" services are the URLs above without the
+// trailing parts.
+//
+// Module and provider packages are served from the /files/ path, and the
+// server rewrites the download locations returned by the upstream registries
+// to refer to those copies.
+type Server struct {
+	dir            string
+	services       *disco.Disco
+	hosts          map[svchost.Hostname]struct{}
+	versionsMaxAge time.Duration
+
+	allowCredentials bool
+	clientTokens     [][]byte
+	signingKey       []byte
+
+	httpClient *http.Client
+	fetcher    *getmodules.PackageFetcher
+	files      http.Handler
+
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	sync.Mutex
+	refs int
+}
+
+// New returns a Server with the given configuration.
+func New(config Config) (*Server, error) {
+	if config.Dir == "" {
+		return nil, fmt.Errorf("no cache directory specified")
+	}
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	services := config.Services
+	if services == nil {
+		services = disco.New()
+	}
+	maxAge := config.VersionsMaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultVersionsMaxAge
+	}
+	hosts := make(map[svchost.Hostname]struct{}, len(config.Hosts))
+	for _, host := range config.Hosts {
+		if !config.AllowCredentials {
+			creds, err := services.CredentialsForHost(host)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get credentials for %s: %w", host.ForDisplay(), err)
+			}
+			if creds != nil {
+				return nil, fmt.Errorf("credentials are configured for %s, and the proxy would serve private modules and providers from it to any client; allow this explicitly if the proxy is only reachable by trusted clients", host.ForDisplay())
+			}
+		}
+		hosts[host] = struct{}{}
+	}
+	var clientTokens [][]byte
+	for _, token := range config.ClientTokens {
+		if token == "" {
+			return nil, fmt.Errorf("client tokens must not be empty")
+		}
+		clientTokens = append(clientTokens, []byte(token))
+	}
+	signingKey := make([]byte, 32)
+	if _, err := rand.Read(signingKey); err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	return &Server{
+		dir:              config.Dir,
+		services:         services,
+		hosts:            hosts,
+		versionsMaxAge:   maxAge,
+		allowCredentials: config.AllowCredentials,
+		clientTokens:     clientTokens,
+		signingKey:       signingKey,
+		httpClient:       httpclient.New(),
+		fetcher:          getmodules.NewPackageFetcher(),
+		files:            http.StripPrefix("/files/", http.FileServer(http.Dir(filepath.Join(config.Dir, "files")))),
+		locks:            make(map[string]*keyLock),
+	}, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(req.URL.Path, "/files/"):
+		if len(s.clientTokens) != 0 && !s.validSignature(req.URL.Path, req.URL.Query().Get(signatureParam)) {
+			writeError(w, http.StatusForbidden, "invalid or missing signature")
+			return
+		}
+		s.files.ServeHTTP(w, req)
+	case !s.authorized(req):
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, http.StatusUnauthorized, "invalid or missing token")
+	case strings.HasPrefix(req.URL.Path, "/v1/modules/"):
+		s.serveModules(w, req, strings.Split(strings.TrimPrefix(req.URL.Path, "/v1/modules/"), "/"))
+	case strings.HasPrefix(req.URL.Path, "/v1/providers/"):
+		s.serveProviders(w, req, strings.Split(strings.TrimPrefix(req.URL.Path, "/v1/providers/"), "/"))
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// authorized returns true if the given request presents one of the client
+// tokens, or if the server doesn't require any.
+func (s *Server) authorized(req *http.Request) bool {
+	if len(s.clientTokens) == 0 {
+		return true
+	}
+	given, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	var match bool
+	for _, token := range s.clientTokens {
+		if subtle.ConstantTimeCompare([]byte(given), token) == 1 {
+			match = true
+		}
+	}
+	return match
+}
+
+// signatureParam is the query parameter of the signature of a file URL.
+const signatureParam = "sig"
+
+func (s *Server) signature(filePath string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	io.WriteString(mac, filePath)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Server) validSignature(filePath, given string) bool {
+	return hmac.Equal([]byte(given), []byte(s.signature(filePath)))
+}
+
+// signURL adds a signature to the given URL returned by filesURL, if the
+// server requires client tokens. The URL may be followed by a "//" and the
+// subdirectory of a module package, which isn't signed.
+func (s *Server) signURL(u string) string {
+	if len(s.clientTokens) == 0 {
+		return u
+	}
+	escaped, _, _ := strings.Cut(u, "//")
+	unescaped, err := url.PathUnescape(escaped)
+	if err != nil {
+		// Can't happen for the URLs returned by filesURL.
+		return u
+	}
+	return u + "?" + signatureParam + "=" + s.signature(unescaped)
+}
+
+// upstreamHost parses the given hostname from a request path, returning an
+// error if it isn't one of the hosts the server proxies.
+func (s *Server) upstreamHost(given string) (svchost.Hostname, error) {
+	host, err := svchost.ForComparison(given)
+	if err != nil {
+		return "", fmt.Errorf("invalid registry hostname %q: %w", given, err)
+	}
+	if _, ok := s.hosts[host]; !ok {
+		return "", fmt.Errorf("this server does not proxy the registry at %s", host.ForDisplay())
+	}
+	return host, nil
+}
+
+// upstreamGet makes a GET request to the given path relative to the base URL
+// of the given service of the given upstream host.
+//
+// The caller must close the body of the returned response.
+func (s *Server) upstreamGet(ctx context.Context, host svchost.Hostname, serviceID string, relPath string) (*http.Response, error) {
+	base, err := s.services.DiscoverServiceURL(host, serviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover %s service of %s: %w", serviceID, host.ForDisplay(), err)
+	}
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	endpoint := base.ResolveReference(&url.URL{Path: relPath})
+
+	log.Printf("[DEBUG] registryproxy: requesting %s", endpoint)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.allowCredentials {
+		creds, err := s.services.CredentialsForHost(host)
+		if err != nil {
+			log.Printf("[WARN] registryproxy: failed to get credentials for %s: %s (ignoring)", host, err)
+		}
+		if creds != nil {
+			creds.PrepareRequest(req)
+		}
+	}
+	req.Header.Set("X-Terraform-Version", version.String())
+	return s.httpClient.Do(req)
+}
+
+// cachedVersions serves the list of available versions of a module or
+// provider, which is the response of the upstream registry at the given path
+// cached at the given path in the cache directory.
+func (s *Server) cachedVersions(w http.ResponseWriter, req *http.Request, host svchost.Hostname, serviceID, upstreamPath, cachePath string) {
+	unlock := s.lock(cachePath)
+	defer unlock()
+
+	filename := filepath.Join(s.dir, "meta", filepath.FromSlash(cachePath))
+	cached, cachedErr := os.ReadFile(filename)
+	if cachedErr == nil {
+		if info, err := os.Stat(filename); err == nil && time.Since(info.ModTime()) < s.versionsMaxAge {
+			writeJSON(w, cached)
+			return
+		}
+	}
+
+	body, _, status, err := s.upstreamJSON(req.Context(), host, serviceID, upstreamPath)
+	switch {
+	case err == nil:
+		if err := writeFileAtomic(filename, body); err != nil {
+			log.Printf("[WARN] registryproxy: failed to cache %s: %s", cachePath, err)
+		}
+		writeJSON(w, body)
+	case status == http.StatusNotFound:
+		writeError(w, http.StatusNotFound, err.Error())
+	case cachedErr == nil:
+		log.Printf("[WARN] registryproxy: serving stale %s because the upstream registry failed: %s", cachePath, err)
+		writeJSON(w, cached)
+	default:
+		writeError(w, http.StatusBadGateway, err.Error())
+	}
+}
+
+// upstreamJSON returns the body of a successful response from the upstream
+// registry and the URL it was requested from, or an error along with the
+// status code of the response, if any.
+func (s *Server) upstreamJSON(ctx context.Context, host svchost.Hostname, serviceID, relPath string) ([]byte, *url.URL, int, error) {
+	resp, err := s.upstreamGet(ctx, host, serviceID, relPath)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, nil, resp.StatusCode, fmt.Errorf("%s not found in the registry at %s", relPath, host.ForDisplay())
+		}
+		return nil, nil, resp.StatusCode, fmt.Errorf("registry at %s returned %s for %s", host.ForDisplay(), resp.Status, relPath)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to read response from %s: %w", host.ForDisplay(), err)
+	}
+	if !json.Valid(body) {
+		return nil, nil, 0, fmt.Errorf("registry at %s returned an invalid response for %s", host.ForDisplay(), relPath)
+	}
+	return body, resp.Request.URL, resp.StatusCode, nil
+}
+
+// download saves the file at the given URL to the given path relative to the
+// files directory, which the server serves from /files/, unless it was
+// already saved. It returns the absolute path of the file.
+func (s *Server) download(ctx context.Context, u *url.URL, filePath string) (string, error) {
+	filename := filepath.Join(s.dir, "files", filepath.FromSlash(filePath))
+	if _, err := os.Stat(filename); err == nil {
+		return filename, nil
+	}
+
+	log.Printf("[DEBUG] registryproxy: downloading %s", u)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s returned from %s", resp.Status, u.Host)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp(filepath.Dir(filename), ".download-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	_, err = io.Copy(f, resp.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", u, err)
+	}
+	return filename, os.Rename(f.Name(), filename)
+}
+
+// lock locks the given cache entry, so that concurrent requests for the same
+// module or provider only populate it once. It returns a function that
+// unlocks it.
+func (s *Server) lock(key string) func() {
+	s.mu.Lock()
+	l, ok := s.locks[key]
+	if !ok {
+		l = &keyLock{}
+		s.locks[key] = l
+	}
+	l.refs++
+	s.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		s.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(s.locks, key)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// filesURL returns the path from which the server serves the file at the
+// given path relative to the files directory, escaped for use in a URL.
+func filesURL(filePath string) string {
+	return (&url.URL{Path: path.Join("/files", filePath)}).EscapedPath()
+}
+
+// validSegments returns false if any of the given request path segments
+// are empty or could refer to a different directory when used in a cache
+// path.
+func validSegments(segments ...string) bool {
+	for _, segment := range segments {
+		if segment == "" || segment == "." || segment == ".." || strings.ContainsAny(segment, `/\`) {
+			return false
+		}
+	}
+	return true
+}
+
+func writeFileAtomic(filename string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(filename), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
+
+func writeJSON(w http.ResponseWriter, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// writeError writes an error response in the format used by the registry
+// protocols.
+func writeError(w http.ResponseWriter, status int, msg string) {
+	body, _ := json.Marshal(map[string][]string{"errors": {msg}})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
@@ -0,0 +1,417 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package registryproxy
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	svchost "github.com/hashicorp/terraform-svchost"
+	"github.com/hashicorp/terraform-svchost/auth"
+	"github.com/hashicorp/terraform-svchost/disco"
+)
+
+const testHost = svchost.Hostname("registry.example.com")
+
+// testUpstream is a fake upstream registry, which counts the requests it
+// receives and can be made to fail.
+type testUpstream struct {
+	*httptest.Server
+	requests atomic.Int32
+	failing  atomic.Bool
+}
+
+func newTestUpstream(t *testing.T) *testUpstream {
+	t.Helper()
+
+	var moduleArchive bytes.Buffer
+	zw := zip.NewWriter(&moduleArchive)
+	for name, content := range map[string]string{
+		"main.tf":           "# root\n",
+		"modules/x/main.tf": "# x\n",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, content)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	providerPackage := []byte("fake provider package")
+	sum := sha256.Sum256(providerPackage)
+
+	u := &testUpstream{}
+	u.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if strings.HasPrefix(req.URL.Path, "/modules/") || strings.HasPrefix(req.URL.Path, "/providers/") {
+			u.requests.Add(1)
+		}
+		if u.failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		switch req.URL.Path {
+		case "/modules/hashicorp/consul/aws/versions":
+			io.WriteString(w, `{"modules":[{"versions":[{"version":"1.0.0"}]}]}`)
+		case "/modules/hashicorp/consul/aws/1.0.0/download":
+			w.Header().Set("X-Terraform-Get", "/archives/consul.zip//modules/x")
+			w.WriteHeader(http.StatusNoContent)
+		case "/archives/consul.zip":
+			w.Write(moduleArchive.Bytes())
+		case "/providers/hashicorp/null/versions":
+			io.WriteString(w, `{"versions":[{"version":"1.0.0","protocols":["5.0"]}]}`)
+		case "/providers/hashicorp/null/1.0.0/download/linux/amd64":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"protocols":             []string{"5.0"},
+				"os":                    "linux",
+				"arch":                  "amd64",
+				"filename":              "terraform-provider-null_1.0.0_linux_amd64.zip",
+				"download_url":          "/packages/null.zip",
+				"shasum":                hex.EncodeToString(sum[:]),
+				"shasums_url":           u.URL + "/packages/SHA256SUMS",
+				"shasums_signature_url": "../../../../../../packages/SHA256SUMS.sig",
+				"signing_keys":          map[string]interface{}{"gpg_public_keys": []interface{}{}},
+			})
+		case "/providers/hashicorp/null/1.0.0/download/linux/arm64":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"filename":     "terraform-provider-null_1.0.0_linux_arm64.zip",
+				"download_url": "/packages/null.zip",
+				"shasum":       strings.Repeat("0", 64),
+			})
+		case "/packages/null.zip":
+			w.Write(providerPackage)
+		case "/packages/SHA256SUMS":
+			io.WriteString(w, "sums")
+		case "/packages/SHA256SUMS.sig":
+			io.WriteString(w, "signature")
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	t.Cleanup(u.Close)
+	return u
+}
+
+func newTestServer(t *testing.T, upstream *testUpstream) (*Server, *httptest.Server) {
+	t.Helper()
+	return newTestServerWithConfig(t, upstream, Config{})
+}
+
+// newTestServerWithConfig returns a server proxying the given upstream
+// registry, with the given configuration apart from the directory, services
+// and hosts.
+func newTestServerWithConfig(t *testing.T, upstream *testUpstream, config Config) (*Server, *httptest.Server) {
+	t.Helper()
+
+	services := config.Services
+	if services == nil {
+		services = disco.New()
+	}
+	services.ForceHostServices(testHost, map[string]interface{}{
+		"modules.v1":   upstream.URL + "/modules/",
+		"providers.v1": upstream.URL + "/providers/",
+	})
+	config.Dir = t.TempDir()
+	config.Services = services
+	config.Hosts = []svchost.Hostname{testHost}
+	s, err := New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	return s, server
+}
+
+func testGet(t *testing.T, u string) (*http.Response, []byte) {
+	t.Helper()
+
+	resp, err := http.Get(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, body
+}
+
+func TestServer_versions(t *testing.T) {
+	upstream := newTestUpstream(t)
+	s, server := newTestServer(t, upstream)
+
+	for _, path := range []string{
+		"/v1/modules/registry.example.com/hashicorp/consul/aws/versions",
+		"/v1/providers/registry.example.com/hashicorp/null/versions",
+	} {
+		t.Run(path, func(t *testing.T) {
+			upstream.requests.Store(0)
+			upstream.failing.Store(false)
+			s.versionsMaxAge = time.Hour
+
+			resp, body := testGet(t, server.URL+path)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("wrong status %s: %s", resp.Status, body)
+			}
+			if !json.Valid(body) || !strings.Contains(string(body), `"1.0.0"`) {
+				t.Fatalf("wrong body %s", body)
+			}
+
+			// A fresh copy in the cache is used without asking the upstream
+			// registry.
+			testGet(t, server.URL+path)
+			if got := upstream.requests.Load(); got != 1 {
+				t.Errorf("upstream registry received %d requests; want 1", got)
+			}
+
+			// An old copy is replaced, unless the upstream registry fails.
+			s.versionsMaxAge = time.Nanosecond
+			testGet(t, server.URL+path)
+			if got := upstream.requests.Load(); got != 2 {
+				t.Errorf("upstream registry received %d requests; want 2", got)
+			}
+			upstream.failing.Store(true)
+			resp, stale := testGet(t, server.URL+path)
+			if resp.StatusCode != http.StatusOK || !bytes.Equal(stale, body) {
+				t.Errorf("wrong stale response %s: %s", resp.Status, stale)
+			}
+		})
+	}
+}
+
+func TestServer_moduleDownload(t *testing.T) {
+	upstream := newTestUpstream(t)
+	_, server := newTestServer(t, upstream)
+
+	for i := 0; i < 2; i++ {
+		resp, body := testGet(t, server.URL+"/v1/modules/registry.example.com/hashicorp/consul/aws/1.0.0/download")
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("wrong status %s: %s", resp.Status, body)
+		}
+		want := "/files/registry.example.com/modules/hashicorp/consul/aws/1.0.0.zip//modules/x"
+		if got := resp.Header.Get("X-Terraform-Get"); got != want {
+			t.Fatalf("wrong location %q; want %q", got, want)
+		}
+	}
+	// The download location is remembered, so the second request doesn't
+	// reach the upstream registry.
+	if got := upstream.requests.Load(); got != 1 {
+		t.Errorf("upstream registry received %d requests; want 1", got)
+	}
+
+	resp, archive := testGet(t, server.URL+"/files/registry.example.com/modules/hashicorp/consul/aws/1.0.0.zip")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("wrong status %s for the module package", resp.Status)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	if got, want := strings.Join(names, ","), "main.tf,modules/x/main.tf"; got != want {
+		t.Errorf("wrong files in module package %s; want %s", got, want)
+	}
+}
+
+func TestServer_providerDownload(t *testing.T) {
+	upstream := newTestUpstream(t)
+	_, server := newTestServer(t, upstream)
+
+	resp, body := testGet(t, server.URL+"/v1/providers/registry.example.com/hashicorp/null/1.0.0/download/linux/amd64")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("wrong status %s: %s", resp.Status, body)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := "/files/registry.example.com/providers/hashicorp/null/1.0.0/"
+	for key, want := range map[string]string{
+		"download_url":          dir + "terraform-provider-null_1.0.0_linux_amd64.zip",
+		"shasums_url":           dir + "SHA256SUMS",
+		"shasums_signature_url": dir + "SHA256SUMS.sig",
+		"filename":              "terraform-provider-null_1.0.0_linux_amd64.zip",
+	} {
+		if got[key] != want {
+			t.Errorf("wrong %s %v; want %s", key, got[key], want)
+		}
+	}
+	if _, ok := got["signing_keys"]; !ok {
+		t.Errorf("signing_keys were not preserved")
+	}
+
+	for path, want := range map[string]string{
+		dir + "terraform-provider-null_1.0.0_linux_amd64.zip": "fake provider package",
+		dir + "SHA256SUMS":     "sums",
+		dir + "SHA256SUMS.sig": "signature",
+	} {
+		resp, body := testGet(t, server.URL+path)
+		if resp.StatusCode != http.StatusOK || string(body) != want {
+			t.Errorf("wrong response for %s: %s %q", path, resp.Status, body)
+		}
+	}
+
+	// A package that doesn't match its checksum is not served.
+	resp, body = testGet(t, server.URL+"/v1/providers/registry.example.com/hashicorp/null/1.0.0/download/linux/arm64")
+	if resp.StatusCode != http.StatusBadGateway || !strings.Contains(string(body), "does not match the expected") {
+		t.Errorf("wrong response for a package with the wrong checksum: %s %s", resp.Status, body)
+	}
+	resp, _ = testGet(t, server.URL+dir+"terraform-provider-null_1.0.0_linux_arm64.zip")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("package with the wrong checksum was kept: %s", resp.Status)
+	}
+}
+
+func TestServer_badRequests(t *testing.T) {
+	upstream := newTestUpstream(t)
+	_, server := newTestServer(t, upstream)
+
+	for path, want := range map[string]int{
+		"/v1/modules/other.example.com/hashicorp/consul/aws/versions":                     http.StatusNotFound,
+		"/v1/modules/registry.example.com/hashicorp/missing/aws/versions":                 http.StatusNotFound,
+		"/v1/modules/registry.example.com/hashicorp/consul/aws/9.9.9/download":            http.StatusNotFound,
+		"/v1/modules/registry.example.com/hashicorp/../aws/versions":                      http.StatusBadRequest,
+		"/v1/providers/registry.example.com/hashicorp/null/1.0.0/download/linux":          http.StatusNotFound,
+		"/v1/providers/registry.example.com/hashicorp/null/nope/download/linux/amd64":     http.StatusBadRequest,
+		"/v1/providers/registry.example.com/hashicorp/null/1.0.0/download/li%2Fnux/amd64": http.StatusNotFound,
+		"/v2/anything": http.StatusNotFound,
+	} {
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// We set the path directly so that the client doesn't clean it.
+		req.URL.Opaque = path
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("wrong status for %s %s; want %d: %s", path, resp.Status, want, body)
+		}
+		if upstream.requests.Load() > 2 {
+			t.Errorf("too many requests to the upstream registry for %s", path)
+		}
+	}
+}
+
+func TestServer_credentials(t *testing.T) {
+	upstream := newTestUpstream(t)
+	services := disco.NewWithCredentialsSource(auth.StaticCredentialsSource(map[svchost.Hostname]map[string]interface{}{
+		testHost: {"token": "upstream-token"},
+	}))
+
+	_, err := New(Config{
+		Dir:      t.TempDir(),
+		Services: services,
+		Hosts:    []svchost.Hostname{testHost},
+	})
+	if err == nil || !strings.Contains(err.Error(), "credentials are configured for registry.example.com") {
+		t.Fatalf("wrong error for a host with credentials: %v", err)
+	}
+
+	_, server := newTestServerWithConfig(t, upstream, Config{
+		Services:         services,
+		AllowCredentials: true,
+	})
+	resp, body := testGet(t, server.URL+"/v1/providers/registry.example.com/hashicorp/null/versions")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("wrong status %s: %s", resp.Status, body)
+	}
+}
+
+func TestServer_clientTokens(t *testing.T) {
+	upstream := newTestUpstream(t)
+	_, server := newTestServerWithConfig(t, upstream, Config{
+		ClientTokens: []string{"secret"},
+	})
+
+	get := func(u, token string) (*http.Response, []byte) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, body
+	}
+
+	const versions = "/v1/providers/registry.example.com/hashicorp/null/versions"
+	for _, token := range []string{"", "wrong"} {
+		if resp, _ := get(server.URL+versions, token); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("wrong status with token %q: %s", token, resp.Status)
+		}
+	}
+	if got := upstream.requests.Load(); got != 0 {
+		t.Errorf("unauthorized requests reached the upstream registry %d times", got)
+	}
+
+	resp, body := get(server.URL+"/v1/providers/registry.example.com/hashicorp/null/1.0.0/download/linux/amd64", "secret")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("wrong status %s: %s", resp.Status, body)
+	}
+	var meta providerPackageMeta
+	if err := json.Unmarshal(body, &meta); err != nil {
+		t.Fatal(err)
+	}
+	const packagePath = "/files/registry.example.com/providers/hashicorp/null/1.0.0/terraform-provider-null_1.0.0_linux_amd64.zip"
+	if !strings.HasPrefix(meta.DownloadURL, packagePath+"?sig=") {
+		t.Fatalf("download URL %q is not signed", meta.DownloadURL)
+	}
+
+	// Packages are served without a token, but only at the signed URLs.
+	if resp, body := get(server.URL+meta.DownloadURL, ""); resp.StatusCode != http.StatusOK || string(body) != "fake provider package" {
+		t.Errorf("wrong response for signed URL: %s %q", resp.Status, body)
+	}
+	if resp, _ := get(server.URL+packagePath, "secret"); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("wrong status for unsigned URL: %s", resp.Status)
+	}
+	if resp, _ := get(server.URL+packagePath+"?sig=00", ""); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("wrong status for wrongly signed URL: %s", resp.Status)
+	}
+
+	// The subdirectory of a module package comes before the signature, as
+	// the module installer expects.
+	resp, _ = get(server.URL+"/v1/modules/registry.example.com/hashicorp/consul/aws/1.0.0/download", "secret")
+	location := resp.Header.Get("X-Terraform-Get")
+	archive, rest, _ := strings.Cut(location, "//")
+	if !strings.HasPrefix(rest, "modules/x?sig=") {
+		t.Fatalf("wrong location %q", location)
+	}
+	_, sig, _ := strings.Cut(rest, "?")
+	if resp, _ := get(server.URL+archive+"?"+sig, ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("wrong status for signed module package URL: %s", resp.Status)
+	}
+}
@@ -86,6 +86,39 @@ func (s *Session) handleEval(line string) (string, tfdiags.Diagnostics) {
 	return FormatValue(val, 0), diags
 }
 
+// CompleteFunctionName returns the names of the functions available in the
+// session, including provider-defined functions, that begin with the partial
+// name ending at byte offset pos in line, along with the length in bytes of
+// that partial name. It returns no names if the cursor isn't at the end of
+// something that could be a function name.
+func (s *Session) CompleteFunctionName(line string, pos int) ([]string, int) {
+	start := pos
+	for start > 0 && isFunctionNameByte(line[start-1]) {
+		start--
+	}
+	prefix := line[start:pos]
+	if prefix == "" || (start > 0 && line[start-1] == '.') {
+		// A name following a dot is an attribute, not a function.
+		return nil, 0
+	}
+
+	var names []string
+	for name := range s.Scope.Functions() {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, 0
+	}
+	sort.Strings(names)
+	return names, len(prefix)
+}
+
+func isFunctionNameByte(b byte) bool {
+	return b == '_' || b == ':' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
 func (s *Session) handleHelp() (string, tfdiags.Diagnostics) {
 	text := `
 The OpenTofu console allows you to experiment with OpenTofu interpolations.
@@ -11,10 +11,13 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/initwd"
+	"github.com/opentofu/opentofu/internal/lang"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/tofu"
@@ -354,6 +357,41 @@ type testSessionTest struct {
 	Inputs []testSessionInput
 }
 
+func TestSession_completeFunctionName(t *testing.T) {
+	scope := &lang.Scope{}
+	scope.SetProviderFunctions(map[string]function.Function{
+		"provider::example::upper": stdlib.UpperFunc,
+		"provider::example::lower": stdlib.LowerFunc,
+	})
+	session := &Session{Scope: scope}
+
+	tests := []struct {
+		Line       string
+		WantNames  []string
+		WantLength int
+	}{
+		{"", nil, 0},
+		{"base64en", []string{"base64encode"}, 8},
+		{"upper(base64de", []string{"base64decode"}, 8},
+		{"provider::ex", []string{"provider::example::lower", "provider::example::upper"}, 12},
+		{"provider::example::u", []string{"provider::example::upper"}, 20},
+		{"var.base64", nil, 0},
+		{"nonexist", nil, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Line, func(t *testing.T) {
+			names, length := session.CompleteFunctionName(test.Line, len(test.Line))
+			if diff := cmp.Diff(test.WantNames, names); diff != "" {
+				t.Errorf("wrong names\n%s", diff)
+			}
+			if length != test.WantLength {
+				t.Errorf("wrong length %d; want %d", length, test.WantLength)
+			}
+		})
+	}
+}
+
 // testSessionInput is a single input to test for a session.
 type testSessionInput struct {
 	Input          string // Input string
@@ -0,0 +1,303 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package simulate
+
+import (
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// provider is a simulated provider, which uses the schema of a real provider
+// but never calls any remote APIs.
+type provider struct {
+	real providers.Interface
+	sim  *Simulation
+}
+
+var _ providers.Interface = (*provider)(nil)
+
+func (p *provider) GetProviderSchema() providers.GetProviderSchemaResponse {
+	return p.real.GetProviderSchema()
+}
+
+func (p *provider) ValidateProviderConfig(req providers.ValidateProviderConfigRequest) providers.ValidateProviderConfigResponse {
+	return p.real.ValidateProviderConfig(req)
+}
+
+func (p *provider) ValidateResourceConfig(req providers.ValidateResourceConfigRequest) providers.ValidateResourceConfigResponse {
+	return p.real.ValidateResourceConfig(req)
+}
+
+func (p *provider) ValidateDataResourceConfig(req providers.ValidateDataResourceConfigRequest) providers.ValidateDataResourceConfigResponse {
+	return p.real.ValidateDataResourceConfig(req)
+}
+
+func (p *provider) UpgradeResourceState(req providers.UpgradeResourceStateRequest) providers.UpgradeResourceStateResponse {
+	return p.real.UpgradeResourceState(req)
+}
+
+// ConfigureProvider does nothing, because many providers call remote APIs
+// while being configured, such as to check their credentials.
+func (p *provider) ConfigureProvider(providers.ConfigureProviderRequest) providers.ConfigureProviderResponse {
+	return providers.ConfigureProviderResponse{}
+}
+
+func (p *provider) Stop() error {
+	return nil
+}
+
+// ReadResource returns the prior state unchanged, so a simulation never
+// detects any changes made outside of OpenTofu.
+func (p *provider) ReadResource(req providers.ReadResourceRequest) providers.ReadResourceResponse {
+	return providers.ReadResourceResponse{
+		NewState: req.PriorState,
+		Private:  req.Private,
+	}
+}
+
+// PlanResourceChange accepts the proposed new state, except that the values
+// of computed attributes which aren't set yet become unknown.
+//
+// The schema doesn't say which attributes require replacement when they
+// change, so a simulation plans to update objects in-place where the real
+// provider might plan to replace them.
+func (p *provider) PlanResourceChange(req providers.PlanResourceChangeRequest) providers.PlanResourceChangeResponse {
+	var resp providers.PlanResourceChangeResponse
+
+	if req.ProposedNewState.IsNull() {
+		// Destroying the object.
+		resp.PlannedState = req.ProposedNewState
+		resp.PlannedPrivate = req.PriorPrivate
+		return resp
+	}
+
+	schema, diags := p.resourceSchema(req.TypeName)
+	resp.Diagnostics = diags
+	if diags.HasErrors() {
+		return resp
+	}
+
+	val, err := cty.Transform(req.ProposedNewState, func(path cty.Path, v cty.Value) (cty.Value, error) {
+		if !v.IsKnown() {
+			return v, nil
+		}
+		attr := schema.AttributeByPath(path)
+		if attr == nil || !attr.Computed {
+			return v, nil
+		}
+		if v.IsNull() {
+			return cty.UnknownVal(v.Type()), nil
+		}
+		return v, nil
+	})
+	if err != nil {
+		resp.Diagnostics = resp.Diagnostics.Append(err)
+		return resp
+	}
+
+	resp.PlannedState = val
+	resp.PlannedPrivate = req.PriorPrivate
+	return resp
+}
+
+// ApplyResourceChange accepts the planned new state, replacing its unknown
+// values with the values from the canned response for the resource type,
+// or with placeholders.
+func (p *provider) ApplyResourceChange(req providers.ApplyResourceChangeRequest) providers.ApplyResourceChangeResponse {
+	var resp providers.ApplyResourceChangeResponse
+
+	response := p.sim.responses.resource(req.TypeName)
+	if response != nil && response.Error != "" {
+		resp.Diagnostics = resp.Diagnostics.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Simulated error",
+			response.Error,
+		))
+		return resp
+	}
+
+	if req.PlannedState.IsNull() {
+		// Destroying the object.
+		resp.NewState = req.PlannedState
+		return resp
+	}
+
+	schema, diags := p.resourceSchema(req.TypeName)
+	resp.Diagnostics = diags
+	if diags.HasErrors() {
+		return resp
+	}
+
+	val, err := cty.Transform(req.PlannedState, func(path cty.Path, v cty.Value) (cty.Value, error) {
+		if v.IsKnown() {
+			return v, nil
+		}
+		if name, ok := topLevelAttr(path); ok {
+			canned, err := response.value(schema, name)
+			if err != nil {
+				return v, err
+			}
+			if canned != cty.NilVal {
+				return canned, nil
+			}
+			if name == "id" && v.Type() == cty.String {
+				return cty.StringVal(p.sim.placeholderID()), nil
+			}
+		}
+		return placeholder(v.Type()), nil
+	})
+	if err != nil {
+		resp.Diagnostics = resp.Diagnostics.Append(fmt.Errorf("failed to simulate applying changes to %s: %w", req.TypeName, err))
+		return resp
+	}
+
+	resp.NewState = val
+	resp.Private = req.PlannedPrivate
+	return resp
+}
+
+// ImportResourceState returns an object whose only known attribute is its
+// "id", set to the import ID.
+func (p *provider) ImportResourceState(req providers.ImportResourceStateRequest) providers.ImportResourceStateResponse {
+	var resp providers.ImportResourceStateResponse
+
+	schema, diags := p.resourceSchema(req.TypeName)
+	resp.Diagnostics = diags
+	if diags.HasErrors() {
+		return resp
+	}
+
+	attrs := schema.EmptyValue().AsValueMap()
+	if attr, ok := schema.Attributes["id"]; ok && attr.Type == cty.String {
+		attrs["id"] = cty.StringVal(req.ID)
+	}
+	resp.ImportedResources = []providers.ImportedResource{
+		{
+			TypeName: req.TypeName,
+			State:    cty.ObjectVal(attrs),
+		},
+	}
+	return resp
+}
+
+// ReadDataSource returns the configuration, with the computed attributes
+// that aren't set taken from the canned response for the data source's
+// type, or with a placeholder "id".
+func (p *provider) ReadDataSource(req providers.ReadDataSourceRequest) providers.ReadDataSourceResponse {
+	var resp providers.ReadDataSourceResponse
+
+	response := p.sim.responses.dataSource(req.TypeName)
+	if response != nil && response.Error != "" {
+		resp.Diagnostics = resp.Diagnostics.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Simulated error",
+			response.Error,
+		))
+		return resp
+	}
+
+	schema, diags := p.dataSourceSchema(req.TypeName)
+	resp.Diagnostics = diags
+	if diags.HasErrors() {
+		return resp
+	}
+
+	attrs := req.Config.AsValueMap()
+	for name, attr := range schema.Attributes {
+		if !attr.Computed || !attrs[name].IsNull() {
+			continue
+		}
+		canned, err := response.value(schema, name)
+		if err != nil {
+			resp.Diagnostics = resp.Diagnostics.Append(fmt.Errorf("failed to simulate reading %s: %w", req.TypeName, err))
+			return resp
+		}
+		switch {
+		case canned != cty.NilVal:
+			attrs[name] = canned
+		case name == "id" && attr.Type == cty.String:
+			attrs[name] = cty.StringVal(p.sim.placeholderID())
+		}
+	}
+	resp.State = cty.ObjectVal(attrs)
+	return resp
+}
+
+// ListResources lists no objects, because simulated providers never read
+// any remote objects.
+func (p *provider) ListResources(providers.ListResourcesRequest) providers.ListResourcesResponse {
+	return providers.ListResourcesResponse{}
+}
+
+// CallFunction calls the real provider's function, because provider
+// functions don't depend on any remote objects.
+func (p *provider) CallFunction(req providers.CallFunctionRequest) providers.CallFunctionResponse {
+	return p.real.CallFunction(req)
+}
+
+func (p *provider) Close() error {
+	return p.real.Close()
+}
+
+func (p *provider) resourceSchema(typeName string) (*configschema.Block, tfdiags.Diagnostics) {
+	schemas := p.real.GetProviderSchema()
+	if schemas.Diagnostics.HasErrors() {
+		return nil, schemas.Diagnostics
+	}
+	schema, ok := schemas.ResourceTypes[typeName]
+	if !ok || schema.Block == nil {
+		var diags tfdiags.Diagnostics
+		return nil, diags.Append(fmt.Errorf("the provider has no schema for resource type %q", typeName))
+	}
+	return schema.Block, nil
+}
+
+func (p *provider) dataSourceSchema(typeName string) (*configschema.Block, tfdiags.Diagnostics) {
+	schemas := p.real.GetProviderSchema()
+	if schemas.Diagnostics.HasErrors() {
+		return nil, schemas.Diagnostics
+	}
+	schema, ok := schemas.DataSources[typeName]
+	if !ok || schema.Block == nil {
+		var diags tfdiags.Diagnostics
+		return nil, diags.Append(fmt.Errorf("the provider has no schema for data source %q", typeName))
+	}
+	return schema.Block, nil
+}
+
+// topLevelAttr returns the name of the attribute the given path refers to,
+// if it refers to a top-level attribute of an object.
+func topLevelAttr(path cty.Path) (string, bool) {
+	if len(path) != 1 {
+		return "", false
+	}
+	step, ok := path[0].(cty.GetAttrStep)
+	return step.Name, ok
+}
+
+// placeholder returns the value of the given type that a simulation uses
+// for an unknown value with no canned response.
+func placeholder(ty cty.Type) cty.Value {
+	switch {
+	case ty == cty.String:
+		return cty.StringVal("")
+	case ty == cty.Number:
+		return cty.Zero
+	case ty == cty.Bool:
+		return cty.False
+	case ty.IsMapType():
+		return cty.MapValEmpty(ty.ElementType())
+	case ty.IsListType():
+		return cty.ListValEmpty(ty.ElementType())
+	case ty.IsSetType():
+		return cty.SetValEmpty(ty.ElementType())
+	default:
+		return cty.NullVal(ty)
+	}
+}
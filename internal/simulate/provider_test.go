@@ -0,0 +1,172 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package simulate
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+func TestProvider_planAndApply(t *testing.T) {
+	sim := New(&Responses{
+		Resources: map[string]*Response{
+			"test_instance": {
+				Values: map[string]json.RawMessage{
+					"arn": json.RawMessage(`"arn:test:instance"`),
+				},
+			},
+		},
+	})
+	p := simulatedTestProvider(t, sim)
+
+	config := cty.ObjectVal(map[string]cty.Value{
+		"id":   cty.NullVal(cty.String),
+		"arn":  cty.NullVal(cty.String),
+		"ami":  cty.StringVal("ami-123"),
+		"size": cty.NullVal(cty.Number),
+	})
+	planResp := p.PlanResourceChange(providers.PlanResourceChangeRequest{
+		TypeName:         "test_instance",
+		PriorState:       cty.NullVal(config.Type()),
+		ProposedNewState: config,
+		Config:           config,
+	})
+	if planResp.Diagnostics.HasErrors() {
+		t.Fatal(planResp.Diagnostics.Err())
+	}
+	wantPlanned := cty.ObjectVal(map[string]cty.Value{
+		"id":   cty.UnknownVal(cty.String),
+		"arn":  cty.UnknownVal(cty.String),
+		"ami":  cty.StringVal("ami-123"),
+		"size": cty.UnknownVal(cty.Number),
+	})
+	if !planResp.PlannedState.RawEquals(wantPlanned) {
+		t.Fatalf("wrong planned state\ngot:  %#v\nwant: %#v", planResp.PlannedState, wantPlanned)
+	}
+
+	applyResp := p.ApplyResourceChange(providers.ApplyResourceChangeRequest{
+		TypeName:     "test_instance",
+		PriorState:   cty.NullVal(config.Type()),
+		PlannedState: planResp.PlannedState,
+		Config:       config,
+	})
+	if applyResp.Diagnostics.HasErrors() {
+		t.Fatal(applyResp.Diagnostics.Err())
+	}
+	wantNew := cty.ObjectVal(map[string]cty.Value{
+		"id":   cty.StringVal("simulated-1"),
+		"arn":  cty.StringVal("arn:test:instance"),
+		"ami":  cty.StringVal("ami-123"),
+		"size": cty.Zero,
+	})
+	if !applyResp.NewState.RawEquals(wantNew) {
+		t.Fatalf("wrong new state\ngot:  %#v\nwant: %#v", applyResp.NewState, wantNew)
+	}
+}
+
+func TestProvider_applyError(t *testing.T) {
+	sim := New(&Responses{
+		Resources: map[string]*Response{
+			"test_instance": {Error: "capacity exceeded"},
+		},
+	})
+	p := simulatedTestProvider(t, sim)
+
+	prior := cty.ObjectVal(map[string]cty.Value{
+		"id":   cty.StringVal("i-1"),
+		"arn":  cty.StringVal("arn"),
+		"ami":  cty.StringVal("ami-123"),
+		"size": cty.NumberIntVal(1),
+	})
+	resp := p.ApplyResourceChange(providers.ApplyResourceChangeRequest{
+		TypeName:     "test_instance",
+		PriorState:   prior,
+		PlannedState: cty.NullVal(prior.Type()),
+		Config:       cty.NullVal(prior.Type()),
+	})
+	if got, want := resp.Diagnostics.Err().Error(), "Simulated error: capacity exceeded"; got != want {
+		t.Fatalf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestProvider_readDataSource(t *testing.T) {
+	sim := New(&Responses{
+		DataSources: map[string]*Response{
+			"test_image": {
+				Values: map[string]json.RawMessage{
+					"tags": json.RawMessage(`{"os":"linux"}`),
+				},
+			},
+		},
+	})
+	p := simulatedTestProvider(t, sim)
+
+	resp := p.ReadDataSource(providers.ReadDataSourceRequest{
+		TypeName: "test_image",
+		Config: cty.ObjectVal(map[string]cty.Value{
+			"id":   cty.NullVal(cty.String),
+			"name": cty.StringVal("base"),
+			"tags": cty.NullVal(cty.Map(cty.String)),
+		}),
+	})
+	if resp.Diagnostics.HasErrors() {
+		t.Fatal(resp.Diagnostics.Err())
+	}
+	want := cty.ObjectVal(map[string]cty.Value{
+		"id":   cty.StringVal("simulated-1"),
+		"name": cty.StringVal("base"),
+		"tags": cty.MapVal(map[string]cty.Value{"os": cty.StringVal("linux")}),
+	})
+	if !resp.State.RawEquals(want) {
+		t.Fatalf("wrong state\ngot:  %#v\nwant: %#v", resp.State, want)
+	}
+}
+
+func simulatedTestProvider(t *testing.T, sim *Simulation) providers.Interface {
+	t.Helper()
+
+	real := &tofu.MockProvider{
+		GetProviderSchemaResponse: &providers.GetProviderSchemaResponse{
+			ResourceTypes: map[string]providers.Schema{
+				"test_instance": {
+					Block: &configschema.Block{
+						Attributes: map[string]*configschema.Attribute{
+							"id":   {Type: cty.String, Computed: true},
+							"arn":  {Type: cty.String, Computed: true},
+							"ami":  {Type: cty.String, Required: true},
+							"size": {Type: cty.Number, Optional: true, Computed: true},
+						},
+					},
+				},
+			},
+			DataSources: map[string]providers.Schema{
+				"test_image": {
+					Block: &configschema.Block{
+						Attributes: map[string]*configschema.Attribute{
+							"id":   {Type: cty.String, Computed: true},
+							"name": {Type: cty.String, Required: true},
+							"tags": {Type: cty.Map(cty.String), Computed: true},
+						},
+					},
+				},
+			},
+		},
+	}
+	addr := addrs.NewDefaultProvider("test")
+	factories := sim.Providers(map[addrs.Provider]providers.Factory{
+		addr: providers.FactoryFixed(real),
+	})
+	p, err := factories[addr]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
@@ -0,0 +1,39 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package simulate
+
+import (
+	"github.com/opentofu/opentofu/internal/provisioners"
+)
+
+// provisioner is a simulated provisioner, which validates its configuration
+// using a real provisioner but never runs it.
+type provisioner struct {
+	real provisioners.Interface
+}
+
+var _ provisioners.Interface = (*provisioner)(nil)
+
+func (p *provisioner) GetSchema() provisioners.GetSchemaResponse {
+	return p.real.GetSchema()
+}
+
+func (p *provisioner) ValidateProvisionerConfig(req provisioners.ValidateProvisionerConfigRequest) provisioners.ValidateProvisionerConfigResponse {
+	return p.real.ValidateProvisionerConfig(req)
+}
+
+func (p *provisioner) ProvisionResource(req provisioners.ProvisionResourceRequest) provisioners.ProvisionResourceResponse {
+	if req.UIOutput != nil {
+		req.UIOutput.Output("Simulated; the provisioner was not run.")
+	}
+	return provisioners.ProvisionResourceResponse{}
+}
+
+func (p *provisioner) Stop() error {
+	return nil
+}
+
+func (p *provisioner) Close() error {
+	return p.real.Close()
+}
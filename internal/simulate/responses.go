@@ -0,0 +1,84 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package simulate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+)
+
+// Responses are canned responses for the simulated providers, which let a
+// simulation use realistic values for the attributes that providers compute,
+// or rehearse changes that fail.
+type Responses struct {
+	// Resources are the responses for managed resources, by resource type.
+	Resources map[string]*Response `json:"resources"`
+
+	// DataSources are the responses for data resources, by resource type.
+	DataSources map[string]*Response `json:"data_sources"`
+}
+
+// Response is the canned response for all of the objects of a resource type.
+type Response struct {
+	// Values are the JSON values of attributes which the provider computes,
+	// by attribute name. They are used instead of placeholders, and only
+	// for attributes whose values aren't set otherwise.
+	Values map[string]json.RawMessage `json:"values"`
+
+	// Error, if set, makes every change to the managed resources, or every
+	// read of the data resources, fail with this error message.
+	Error string `json:"error"`
+}
+
+// LoadResponses reads canned responses from the JSON file at the given path.
+func LoadResponses(path string) (*Responses, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(src))
+	dec.DisallowUnknownFields()
+	var ret Responses
+	if err := dec.Decode(&ret); err != nil {
+		return nil, fmt.Errorf("invalid responses file: %w", err)
+	}
+	return &ret, nil
+}
+
+func (r *Responses) resource(typeName string) *Response {
+	return r.Resources[typeName]
+}
+
+func (r *Responses) dataSource(typeName string) *Response {
+	return r.DataSources[typeName]
+}
+
+// value returns the canned value of the given attribute, converted to the
+// attribute's type, or cty.NilVal if the response has no value for it.
+func (r *Response) value(schema *configschema.Block, name string) (cty.Value, error) {
+	if r == nil {
+		return cty.NilVal, nil
+	}
+	raw, ok := r.Values[name]
+	if !ok {
+		return cty.NilVal, nil
+	}
+	attr, ok := schema.Attributes[name]
+	if !ok {
+		return cty.NilVal, fmt.Errorf("the canned response has a value for %q, which is not an attribute of this resource type", name)
+	}
+	v, err := ctyjson.Unmarshal(raw, attr.ImpliedType())
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("invalid canned value for %q: %w", name, err)
+	}
+	return v, nil
+}
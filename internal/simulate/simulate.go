@@ -0,0 +1,82 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package simulate implements simulated providers and provisioners, which
+// let OpenTofu rehearse applying changes without touching any real
+// infrastructure.
+//
+// The simulated providers use the schemas of the real providers, and still
+// ask them to validate configuration and upgrade state, since providers do
+// those without calling any remote APIs. Everything else, including
+// configuring the providers, is simulated: refreshing leaves objects
+// unchanged, and applying a change accepts the planned values, replacing
+// any values the provider would compute with canned responses or
+// placeholders.
+package simulate
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/provisioners"
+)
+
+// Simulation replaces the providers and provisioners of an operation with
+// simulated ones.
+type Simulation struct {
+	responses *Responses
+
+	// lastID is the number of the last placeholder ID, so that the
+	// placeholder IDs are unique across all of the simulated providers.
+	lastID atomic.Int64
+}
+
+// New returns a simulation whose providers use the given canned responses,
+// which may be nil to use placeholders for all computed values.
+func New(responses *Responses) *Simulation {
+	if responses == nil {
+		responses = &Responses{}
+	}
+	return &Simulation{responses: responses}
+}
+
+// Providers returns factories for simulated providers that wrap the
+// providers created by the given factories.
+func (s *Simulation) Providers(factories map[addrs.Provider]providers.Factory) map[addrs.Provider]providers.Factory {
+	ret := make(map[addrs.Provider]providers.Factory, len(factories))
+	for addr, factory := range factories {
+		factory := factory
+		ret[addr] = func() (providers.Interface, error) {
+			real, err := factory()
+			if err != nil {
+				return nil, err
+			}
+			return &provider{real: real, sim: s}, nil
+		}
+	}
+	return ret
+}
+
+// Provisioners returns factories for simulated provisioners that wrap the
+// provisioners created by the given factories.
+func (s *Simulation) Provisioners(factories map[string]provisioners.Factory) map[string]provisioners.Factory {
+	ret := make(map[string]provisioners.Factory, len(factories))
+	for name, factory := range factories {
+		factory := factory
+		ret[name] = func() (provisioners.Interface, error) {
+			real, err := factory()
+			if err != nil {
+				return nil, err
+			}
+			return &provisioner{real: real}, nil
+		}
+	}
+	return ret
+}
+
+// placeholderID returns a new unique value for an "id" attribute.
+func (s *Simulation) placeholderID() string {
+	return fmt.Sprintf("simulated-%d", s.lastID.Add(1))
+}
@@ -0,0 +1,145 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package simulate
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tofu"
+)
+
+// Trace is a hook which records the operations of a simulated apply, in the
+// order they started.
+type Trace struct {
+	tofu.NilHook
+
+	mu         sync.Mutex
+	operations []*Operation
+	pending    map[string]*Operation
+}
+
+var _ tofu.Hook = (*Trace)(nil)
+
+// Operation is a single change that a simulated apply made to a resource
+// instance object.
+type Operation struct {
+	Address string `json:"address"`
+	Deposed string `json:"deposed,omitempty"`
+
+	// Action is "create", "update", "delete", "read" or "import".
+	Action string `json:"action"`
+
+	// Provisioners are the types of the provisioners that would have run
+	// after the change.
+	Provisioners []string `json:"provisioners,omitempty"`
+
+	// Error is the error that the change failed with, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// NewTrace returns a new empty trace.
+func NewTrace() *Trace {
+	return &Trace{
+		pending: make(map[string]*Operation),
+	}
+}
+
+func (t *Trace) PreApply(addr addrs.AbsResourceInstance, gen states.Generation, action plans.Action, priorState, plannedNewState cty.Value) (tofu.HookAction, error) {
+	op := &Operation{
+		Address: addr.String(),
+		Action:  traceAction(action),
+	}
+	if dk, ok := gen.(states.DeposedKey); ok {
+		op.Deposed = dk.String()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.operations = append(t.operations, op)
+	t.pending[traceKey(addr, gen)] = op
+	return tofu.HookActionContinue, nil
+}
+
+func (t *Trace) PostApply(addr addrs.AbsResourceInstance, gen states.Generation, newState cty.Value, err error) (tofu.HookAction, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := traceKey(addr, gen)
+	if op, ok := t.pending[key]; ok && err != nil {
+		op.Error = err.Error()
+	}
+	delete(t.pending, key)
+	return tofu.HookActionContinue, nil
+}
+
+func (t *Trace) PreProvisionInstanceStep(addr addrs.AbsResourceInstance, typeName string) (tofu.HookAction, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if op, ok := t.pending[traceKey(addr, states.CurrentGen)]; ok {
+		op.Provisioners = append(op.Provisioners, typeName)
+	}
+	return tofu.HookActionContinue, nil
+}
+
+func (t *Trace) PreApplyImport(addr addrs.AbsResourceInstance, importing plans.ImportingSrc) (tofu.HookAction, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.operations = append(t.operations, &Operation{
+		Address: addr.String(),
+		Action:  "import",
+	})
+	return tofu.HookActionContinue, nil
+}
+
+// Operations returns the operations recorded so far.
+func (t *Trace) Operations() []Operation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ret := make([]Operation, len(t.operations))
+	for i, op := range t.operations {
+		ret[i] = *op
+	}
+	return ret
+}
+
+// WriteFile writes the operations recorded so far to the given path as a
+// JSON document.
+func (t *Trace) WriteFile(path string) error {
+	src, err := json.MarshalIndent(struct {
+		Operations []Operation `json:"operations"`
+	}{t.Operations()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(src, '\n'), 0644)
+}
+
+func traceKey(addr addrs.AbsResourceInstance, gen states.Generation) string {
+	if dk, ok := gen.(states.DeposedKey); ok {
+		return addr.String() + " " + dk.String()
+	}
+	return addr.String()
+}
+
+func traceAction(action plans.Action) string {
+	switch action {
+	case plans.Create:
+		return "create"
+	case plans.Update:
+		return "update"
+	case plans.Delete:
+		return "delete"
+	case plans.Read:
+		return "read"
+	default:
+		return strings.ToLower(action.String())
+	}
+}
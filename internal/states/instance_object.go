@@ -10,6 +10,7 @@ import (
 	ctyjson "github.com/zclconf/go-cty/cty/json"
 
 	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/lang/marks"
 )
 
 // ResourceInstanceObject is the local representation of a specific remote
@@ -47,6 +48,13 @@ type ResourceInstanceObject struct {
 	// destroy operations, we need to record the status to ensure a resource
 	// removed from the config will still be destroyed in the same manner.
 	CreateBeforeDestroy bool
+
+	// ReplaceTriggers records the values of the replace_triggered_by
+	// expressions that don't refer to a resource, as of when this instance
+	// was last updated, so that a later plan can replace the instance if
+	// any of them have changed. This is cty.NilVal or a null value if the
+	// resource has no such expressions.
+	ReplaceTriggers cty.Value
 }
 
 // ObjectStatus represents the status of a RemoteObject.
@@ -97,6 +105,10 @@ func (o *ResourceInstanceObject) Encode(ty cty.Type, schemaVersion uint64) (*Res
 	// so we can save them in state.
 	val, pvm := o.Value.UnmarkDeepWithPaths()
 
+	// Ephemeral values must never be persisted, so we replace them with
+	// nulls before we serialize the value.
+	val, pvm = marks.RemoveEphemeral(val, pvm)
+
 	// Our state serialization can't represent unknown values, so we convert
 	// them to nulls here. This is lossy, but nobody should be writing unknown
 	// values here and expecting to get them out again later.
@@ -125,6 +137,15 @@ func (o *ResourceInstanceObject) Encode(ty cty.Type, schemaVersion uint64) (*Res
 
 	sort.Slice(dependencies, func(i, j int) bool { return dependencies[i].String() < dependencies[j].String() })
 
+	var triggers []byte
+	if o.ReplaceTriggers != cty.NilVal && !o.ReplaceTriggers.IsNull() {
+		triggersVal, _ := o.ReplaceTriggers.UnmarkDeep()
+		triggers, err = ctyjson.Marshal(cty.UnknownAsNull(triggersVal), cty.DynamicPseudoType)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &ResourceInstanceObjectSrc{
 		SchemaVersion:       schemaVersion,
 		AttrsJSON:           src,
@@ -133,6 +154,7 @@ func (o *ResourceInstanceObject) Encode(ty cty.Type, schemaVersion uint64) (*Res
 		Status:              o.Status,
 		Dependencies:        dependencies,
 		CreateBeforeDestroy: o.CreateBeforeDestroy,
+		ReplaceTriggersJSON: triggers,
 	}, nil
 }
 
@@ -62,6 +62,11 @@ type ResourceInstanceObjectSrc struct {
 	Status              ObjectStatus
 	Dependencies        []addrs.ConfigResource
 	CreateBeforeDestroy bool
+
+	// ReplaceTriggersJSON is the JSON serialization of the ReplaceTriggers
+	// field of ResourceInstanceObject, including its type, or nil if the
+	// object has no recorded trigger values.
+	ReplaceTriggersJSON []byte
 }
 
 // Decode unmarshals the raw representation of the object attributes. Pass the
@@ -94,12 +99,21 @@ func (os *ResourceInstanceObjectSrc) Decode(ty cty.Type) (*ResourceInstanceObjec
 		}
 	}
 
+	triggers := cty.NilVal
+	if os.ReplaceTriggersJSON != nil {
+		triggers, err = ctyjson.Unmarshal(os.ReplaceTriggersJSON, cty.DynamicPseudoType)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &ResourceInstanceObject{
 		Value:               val,
 		Status:              os.Status,
 		Dependencies:        os.Dependencies,
 		Private:             os.Private,
 		CreateBeforeDestroy: os.CreateBeforeDestroy,
+		ReplaceTriggers:     triggers,
 	}, nil
 }
 
@@ -9,6 +9,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/lang/marks"
 	"github.com/zclconf/go-cty/cty"
 )
 
@@ -84,3 +85,34 @@ func TestResourceInstanceObject_encode(t *testing.T) {
 		}
 	}
 }
+
+func TestResourceInstanceObject_encodeEphemeral(t *testing.T) {
+	obj := &ResourceInstanceObject{
+		Value: cty.ObjectVal(map[string]cty.Value{
+			"id":       cty.StringVal("foo"),
+			"password": cty.StringVal("secret").Mark(marks.Ephemeral).Mark(marks.Sensitive),
+			"token":    cty.StringVal("secret").Mark(marks.Ephemeral),
+		}),
+		Status: ObjectReady,
+	}
+
+	src, err := obj.Encode(obj.Value.Type(), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got, want := string(src.AttrsJSON), `{"id":"foo","password":null,"token":null}`; got != want {
+		t.Errorf("wrong attributes\ngot:  %s\nwant: %s", got, want)
+	}
+
+	// The other marks must be kept.
+	wantPaths := []cty.PathValueMarks{
+		{
+			Path:  cty.GetAttrPath("password"),
+			Marks: cty.NewValueMarks(marks.Sensitive),
+		},
+	}
+	if diff := cmp.Diff(wantPaths, src.AttrSensitivePaths); diff != "" {
+		t.Errorf("wrong marks\n%s", diff)
+	}
+}
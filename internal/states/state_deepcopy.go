@@ -168,6 +168,12 @@ func (os *ResourceInstanceObjectSrc) DeepCopy() *ResourceInstanceObjectSrc {
 		copy(dependencies, os.Dependencies)
 	}
 
+	var triggers []byte
+	if os.ReplaceTriggersJSON != nil {
+		triggers = make([]byte, len(os.ReplaceTriggersJSON))
+		copy(triggers, os.ReplaceTriggersJSON)
+	}
+
 	return &ResourceInstanceObjectSrc{
 		Status:              os.Status,
 		SchemaVersion:       os.SchemaVersion,
@@ -177,6 +183,7 @@ func (os *ResourceInstanceObjectSrc) DeepCopy() *ResourceInstanceObjectSrc {
 		AttrSensitivePaths:  attrPaths,
 		Dependencies:        dependencies,
 		CreateBeforeDestroy: os.CreateBeforeDestroy,
+		ReplaceTriggersJSON: triggers,
 	}
 }
 
@@ -213,6 +220,7 @@ func (o *ResourceInstanceObject) DeepCopy() *ResourceInstanceObject {
 		Private:             private,
 		Dependencies:        dependencies,
 		CreateBeforeDestroy: o.CreateBeforeDestroy,
+		ReplaceTriggers:     o.ReplaceTriggers,
 	}
 }
 
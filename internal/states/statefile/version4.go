@@ -139,6 +139,7 @@ func prepareStateV4(sV4 *stateV4) (*File, tfdiags.Diagnostics) {
 			obj := &states.ResourceInstanceObjectSrc{
 				SchemaVersion:       isV4.SchemaVersion,
 				CreateBeforeDestroy: isV4.CreateBeforeDestroy,
+				ReplaceTriggersJSON: isV4.ReplaceTriggers,
 			}
 
 			{
@@ -510,6 +511,7 @@ func appendInstanceObjectStateV4(rs *states.Resource, is *states.ResourceInstanc
 		PrivateRaw:              privateRaw,
 		Dependencies:            deps,
 		CreateBeforeDestroy:     obj.CreateBeforeDestroy,
+		ReplaceTriggers:         obj.ReplaceTriggersJSON,
 	}), diags
 }
 
@@ -723,6 +725,8 @@ type instanceObjectStateV4 struct {
 	Dependencies []string `json:"dependencies,omitempty"`
 
 	CreateBeforeDestroy bool `json:"create_before_destroy,omitempty"`
+
+	ReplaceTriggers json.RawMessage `json:"replace_triggers,omitempty"`
 }
 
 type checkResultsV4 struct {
@@ -359,9 +359,24 @@ func WholeContainingBody(severity Severity, summary, detail string) Diagnostic {
 	}
 }
 
+// WholeContainingBodyWithExtra is like WholeContainingBody, but the returned
+// diagnostic also has the given extra information, which its ExtraInfo
+// method returns.
+func WholeContainingBodyWithExtra(severity Severity, summary, detail string, extra interface{}) Diagnostic {
+	return &wholeBodyDiagnostic{
+		diagnosticBase: diagnosticBase{
+			severity: severity,
+			summary:  summary,
+			detail:   detail,
+		},
+		extra: extra,
+	}
+}
+
 type wholeBodyDiagnostic struct {
 	diagnosticBase
 	subject *SourceRange // populated only after ElaborateFromConfigBody
+	extra   interface{}
 }
 
 func (d *wholeBodyDiagnostic) ElaborateFromConfigBody(body hcl.Body, addr string) Diagnostic {
@@ -386,3 +401,7 @@ func (d *wholeBodyDiagnostic) Source() Source {
 		Subject: d.subject,
 	}
 }
+
+func (d *wholeBodyDiagnostic) ExtraInfo() interface{} {
+	return d.extra
+}
@@ -3,6 +3,10 @@
 
 package tfdiags
 
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
 // This "Extra" idea is something we've inherited from HCL's diagnostic model,
 // and so it's primarily to expose that functionality from wrapped HCL
 // diagnostics but other diagnostic types could potentially implement this
@@ -181,6 +185,83 @@ func DiagnosticCausedBySensitive(diag Diagnostic) bool {
 	return maybe.DiagnosticCausedBySensitive()
 }
 
+// DiagnosticExtraBecausePluginCrashed is an interface implemented by values
+// in the Extra field of Diagnostic when the diagnostic reports that a plugin
+// process crashed, or otherwise stopped responding, while handling a request.
+//
+// Callers might use this to treat the outcome of the request as unknown,
+// since the plugin might have done some of its work before it crashed.
+type DiagnosticExtraBecausePluginCrashed interface {
+	// DiagnosticCausedByPluginCrash returns true if the associated
+	// diagnostic reports that a plugin crashed, or false otherwise.
+	DiagnosticCausedByPluginCrash() bool
+}
+
+// DiagnosticCausedByPluginCrash returns true if the given diagnostic reports
+// that a plugin process crashed while handling a request.
+//
+// This is a wrapper around checking if the diagnostic's extra info implements
+// interface DiagnosticExtraBecausePluginCrashed and then calling its method if
+// so.
+func DiagnosticCausedByPluginCrash(diag Diagnostic) bool {
+	maybe := ExtraInfo[DiagnosticExtraBecausePluginCrashed](diag)
+	if maybe == nil {
+		return false
+	}
+	return maybe.DiagnosticCausedByPluginCrash()
+}
+
+// DiagnosticExtraAddress is an interface implemented by values in the Extra
+// field of Diagnostic when the diagnostic is about a particular object, such
+// as a resource instance or an input variable of a module instance, whose
+// address the UI should mention alongside the diagnostic.
+//
+// This is useful when the diagnostic's source location alone can't identify
+// the object, such as when a module is called multiple times.
+type DiagnosticExtraAddress interface {
+	// DiagnosticAddress returns the address of the object that the
+	// associated diagnostic is about, or an empty string if there is no
+	// such object.
+	DiagnosticAddress() string
+}
+
+// DiagnosticAddress returns the address of the object that the given
+// diagnostic is about, if its extra info implements DiagnosticExtraAddress,
+// or an empty string otherwise.
+func DiagnosticAddress(diag Diagnostic) string {
+	maybe := ExtraInfo[DiagnosticExtraAddress](diag)
+	if maybe == nil {
+		return ""
+	}
+	return maybe.DiagnosticAddress()
+}
+
+// DiagnosticExtraValuePath is an interface implemented by values in the Extra
+// field of Diagnostic when the diagnostic's expression can refer to the
+// object that the diagnostic is about through a relative name, such as
+// "self" in a resource postcondition or "var.name" in a validation rule of
+// an input variable. The UI uses it to show the full path of each value that
+// it mentions, such as module.network[1].var.cidr_block.prefix.
+type DiagnosticExtraValuePath interface {
+	// DiagnosticValuePath returns the address of the object that the start
+	// of the given traversal refers to, and the number of steps at the start
+	// of the traversal that the address replaces. It returns an empty
+	// string if the traversal doesn't refer to the object.
+	DiagnosticValuePath(traversal hcl.Traversal) (string, int)
+}
+
+// DiagnosticValuePath returns the address that replaces the start of the
+// given traversal from the given diagnostic's expression, and the number of
+// steps that it replaces, if the diagnostic's extra info implements
+// DiagnosticExtraValuePath. Otherwise it returns an empty string.
+func DiagnosticValuePath(diag Diagnostic, traversal hcl.Traversal) (string, int) {
+	maybe := ExtraInfo[DiagnosticExtraValuePath](diag)
+	if maybe == nil {
+		return "", 0
+	}
+	return maybe.DiagnosticValuePath(traversal)
+}
+
 // DiagnosticExtraDoNotConsolidate tells the Diagnostics.ConsolidateWarnings
 // function not to consolidate this diagnostic if it otherwise would.
 type DiagnosticExtraDoNotConsolidate interface {
@@ -192,6 +192,14 @@ func (diags Diagnostics) Sort() {
 	sort.Stable(sortDiagnostics(diags))
 }
 
+// SortDeterministic is like Sort, except that diagnostics that do not differ
+// by the characteristics Sort uses are then ordered by their summaries and
+// details, so that the result doesn't depend on the order in which the
+// diagnostics were collected.
+func (diags Diagnostics) SortDeterministic() {
+	sort.Stable(sortDiagnosticsDeterministic(diags))
+}
+
 type diagnosticsAsError struct {
 	Diagnostics
 }
@@ -331,3 +339,29 @@ func (sd sortDiagnostics) Less(i, j int) bool {
 func (sd sortDiagnostics) Swap(i, j int) {
 	sd[i], sd[j] = sd[j], sd[i]
 }
+
+type sortDiagnosticsDeterministic []Diagnostic
+
+func (sd sortDiagnosticsDeterministic) Len() int {
+	return len(sd)
+}
+
+func (sd sortDiagnosticsDeterministic) Less(i, j int) bool {
+	switch {
+	case sortDiagnostics(sd).Less(i, j):
+		return true
+	case sortDiagnostics(sd).Less(j, i):
+		return false
+	}
+
+	iDesc := sd[i].Description()
+	jDesc := sd[j].Description()
+	if iDesc.Summary != jDesc.Summary {
+		return iDesc.Summary < jDesc.Summary
+	}
+	return iDesc.Detail < jDesc.Detail
+}
+
+func (sd sortDiagnosticsDeterministic) Swap(i, j int) {
+	sd[i], sd[j] = sd[j], sd[i]
+}
@@ -440,3 +440,27 @@ func TestDiagnosticsNonFatalErr(t *testing.T) {
 		}
 	})
 }
+
+func TestDiagnosticsSortDeterministic(t *testing.T) {
+	var diags Diagnostics
+	diags = diags.Append(Sourceless(Error, "Zebra", "detail"))
+	diags = diags.Append(Sourceless(Error, "Aardvark", "second"))
+	diags = diags.Append(Sourceless(Warning, "Warning", "detail"))
+	diags = diags.Append(Sourceless(Error, "Aardvark", "first"))
+	diags.SortDeterministic()
+
+	var got []string
+	for _, diag := range diags {
+		desc := diag.Description()
+		got = append(got, desc.Summary+": "+desc.Detail)
+	}
+	want := []string{
+		"Warning: detail",
+		"Aardvark: first",
+		"Aardvark: second",
+		"Zebra: detail",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong order\ngot:  %q\nwant: %q", got, want)
+	}
+}
@@ -27,6 +27,7 @@ func (d hclDiagnostic) Description() Description {
 	return Description{
 		Summary: d.diag.Summary,
 		Detail:  d.diag.Detail,
+		Address: DiagnosticAddress(d),
 	}
 }
 
@@ -56,7 +56,13 @@ func (o overriddenDiagnostic) Severity() Severity {
 }
 
 func (o overriddenDiagnostic) Description() Description {
-	return o.original.Description()
+	desc := o.original.Description()
+	if desc.Address == "" {
+		// The new extra info might be able to say what the diagnostic is
+		// about, even though the original couldn't.
+		desc.Address = DiagnosticAddress(o)
+	}
+	return desc
 }
 
 func (o overriddenDiagnostic) Source() Source {
@@ -0,0 +1,145 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"log"
+	"math"
+
+	"golang.org/x/time/rate"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs"
+)
+
+// ConcurrencyLimits describes limits on the number of concurrent operations
+// for resource instances belonging to particular providers or of particular
+// managed resource types, and on the rate at which operations may begin for
+// resource instances belonging to particular providers.
+//
+// These limits apply in addition to the overall parallelism of a Context, so
+// an operation may begin only once it is within all of the limits that apply
+// to it.
+type ConcurrencyLimits struct {
+	Providers     map[addrs.Provider]int
+	ResourceTypes map[string]int
+
+	// ProviderRates is the maximum number of operations per second that may
+	// begin for resource instances belonging to each provider.
+	ProviderRates map[addrs.Provider]float64
+}
+
+// concurrencySemaphores is the set of semaphores that enforce a particular
+// ConcurrencyLimits during a single graph walk.
+type concurrencySemaphores struct {
+	providers     map[addrs.Provider]Semaphore
+	resourceTypes map[string]Semaphore
+	providerRates map[addrs.Provider]*rate.Limiter
+}
+
+// newConcurrencySemaphores prepares semaphores for the limits from the root
+// module of the given configuration, if any, with those from the given
+// overrides taking precedence.
+func newConcurrencySemaphores(config *configs.Config, overrides ConcurrencyLimits) *concurrencySemaphores {
+	providers := make(map[addrs.Provider]int)
+	resourceTypes := make(map[string]int)
+	providerRates := make(map[addrs.Provider]float64)
+
+	if config != nil && config.Module != nil && config.Module.Concurrency != nil {
+		for provider, limit := range config.Module.Concurrency.ProviderLimits(config.Module) {
+			providers[provider] = limit
+		}
+		for typeName, limit := range config.Module.Concurrency.ResourceTypes {
+			resourceTypes[typeName] = limit
+		}
+		for provider, limit := range config.Module.Concurrency.ProviderRateLimits(config.Module) {
+			providerRates[provider] = limit
+		}
+	}
+	for provider, limit := range overrides.Providers {
+		providers[provider] = limit
+	}
+	for typeName, limit := range overrides.ResourceTypes {
+		resourceTypes[typeName] = limit
+	}
+	for provider, limit := range overrides.ProviderRates {
+		providerRates[provider] = limit
+	}
+
+	ret := &concurrencySemaphores{
+		providers:     make(map[addrs.Provider]Semaphore, len(providers)),
+		resourceTypes: make(map[string]Semaphore, len(resourceTypes)),
+		providerRates: make(map[addrs.Provider]*rate.Limiter, len(providerRates)),
+	}
+	for provider, limit := range providers {
+		log.Printf("[TRACE] Limiting concurrent operations for %s to %d", provider, limit)
+		ret.providers[provider] = NewSemaphore(limit)
+	}
+	for typeName, limit := range resourceTypes {
+		log.Printf("[TRACE] Limiting concurrent operations for resource type %s to %d", typeName, limit)
+		ret.resourceTypes[typeName] = NewSemaphore(limit)
+	}
+	for provider, limit := range providerRates {
+		log.Printf("[TRACE] Limiting operations for %s to %g per second", provider, limit)
+		// The burst allows as many operations to begin at once as would be
+		// allowed in one second, so that the walk doesn't have to wait for
+		// the first few operations to be spread out evenly.
+		burst := int(math.Max(1, math.Ceil(limit)))
+		ret.providerRates[provider] = rate.NewLimiter(rate.Limit(limit), burst)
+	}
+	return ret
+}
+
+// forNode returns the semaphores that must be acquired before executing the
+// given node, in the order they must be acquired.
+//
+// Only resource instance nodes are subject to these limits. Callers must
+// always acquire the returned semaphores in the given order, and before
+// the Context's own parallelism semaphore, so that concurrent walkers
+// cannot deadlock and so that a node waiting for a narrower limit does not
+// prevent unrelated nodes from running.
+func (s *concurrencySemaphores) forNode(n GraphNodeExecutable) []Semaphore {
+	if s == nil || (len(s.providers) == 0 && len(s.resourceTypes) == 0) {
+		return nil
+	}
+
+	ri, ok := n.(GraphNodeResourceInstance)
+	if !ok {
+		return nil
+	}
+
+	var ret []Semaphore
+	addr := ri.ResourceInstanceAddr().Resource.Resource
+	if addr.Mode == addrs.ManagedResourceMode {
+		if sem, ok := s.resourceTypes[addr.Type]; ok {
+			ret = append(ret, sem)
+		}
+	}
+	if pc, ok := n.(GraphNodeProviderConsumer); ok {
+		if sem, ok := s.providers[pc.Provider()]; ok {
+			ret = append(ret, sem)
+		}
+	}
+	return ret
+}
+
+// rateLimiterForNode returns the rate limiter that the given node must wait
+// for before executing, or nil if its operations aren't rate limited.
+//
+// Only resource instance nodes are subject to rate limits. Callers should
+// wait for the limiter after acquiring the semaphores from forNode, but before
+// the Context's own parallelism semaphore, for the same reasons.
+func (s *concurrencySemaphores) rateLimiterForNode(n GraphNodeExecutable) *rate.Limiter {
+	if s == nil || len(s.providerRates) == 0 {
+		return nil
+	}
+	if _, ok := n.(GraphNodeResourceInstance); !ok {
+		return nil
+	}
+	pc, ok := n.(GraphNodeProviderConsumer)
+	if !ok {
+		return nil
+	}
+	return s.providerRates[pc.Provider()]
+}
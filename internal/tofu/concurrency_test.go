@@ -0,0 +1,149 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+)
+
+func TestConcurrencySemaphores(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+terraform {
+  concurrency {
+    providers = {
+      test = 1
+    }
+    resource_types = {
+      test_object = 3
+      test_thing  = 2
+    }
+  }
+}
+`,
+	})
+
+	sems := newConcurrencySemaphores(m, ConcurrencyLimits{
+		ResourceTypes: map[string]int{
+			"test_thing": 1,
+		},
+	})
+
+	if got, want := len(sems.providers), 1; got != want {
+		t.Fatalf("wrong number of provider semaphores %d; want %d", got, want)
+	}
+	if got, want := len(sems.resourceTypes), 2; got != want {
+		t.Fatalf("wrong number of resource type semaphores %d; want %d", got, want)
+	}
+
+	// The override from the options should take precedence over the
+	// configured limit, leaving only one slot for test_thing.
+	thing := sems.resourceTypes["test_thing"]
+	if !thing.TryAcquire() {
+		t.Fatal("should acquire test_thing")
+	}
+	if thing.TryAcquire() {
+		t.Fatal("should not acquire test_thing twice")
+	}
+	thing.Release()
+
+	managed := NewNodeAbstractResourceInstance(mustResourceInstanceAddr("test_object.a"))
+	if got, want := len(sems.forNode(&NodeApplyableResourceInstance{NodeAbstractResourceInstance: managed})), 2; got != want {
+		t.Errorf("wrong number of semaphores for managed resource %d; want %d", got, want)
+	}
+
+	// Data resources are subject only to the provider limit.
+	data := NewNodeAbstractResourceInstance(mustResourceInstanceAddr("data.test_object.a"))
+	if got, want := len(sems.forNode(&NodePlannableResourceInstance{NodeAbstractResourceInstance: data})), 1; got != want {
+		t.Errorf("wrong number of semaphores for data resource %d; want %d", got, want)
+	}
+
+	// Resources of other providers aren't limited at all.
+	other := NewNodeAbstractResourceInstance(mustResourceInstanceAddr("aws_instance.a"))
+	if got := sems.forNode(&NodeApplyableResourceInstance{NodeAbstractResourceInstance: other}); len(got) != 0 {
+		t.Errorf("unexpected semaphores for unrelated resource: %d", len(got))
+	}
+
+	// Nodes that aren't resource instances aren't limited either.
+	if got := sems.forNode(&NodeApplyableOutput{Addr: addrs.OutputValue{Name: "foo"}.Absolute(addrs.RootModuleInstance)}); len(got) != 0 {
+		t.Errorf("unexpected semaphores for output value: %d", len(got))
+	}
+}
+
+func TestConcurrencySemaphores_nil(t *testing.T) {
+	var sems *concurrencySemaphores
+	node := NewNodeAbstractResourceInstance(mustResourceInstanceAddr("test_object.a"))
+	if got := sems.forNode(&NodeApplyableResourceInstance{NodeAbstractResourceInstance: node}); got != nil {
+		t.Errorf("unexpected semaphores from nil set: %d", len(got))
+	}
+}
+
+func TestConcurrencySemaphores_providerRates(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+terraform {
+  concurrency {
+    provider_rate_limits = {
+      test = 2.5
+    }
+  }
+}
+`,
+	})
+
+	sems := newConcurrencySemaphores(m, ConcurrencyLimits{})
+
+	node := NewNodeAbstractResourceInstance(mustResourceInstanceAddr("test_object.a"))
+	limiter := sems.rateLimiterForNode(&NodeApplyableResourceInstance{NodeAbstractResourceInstance: node})
+	if limiter == nil {
+		t.Fatal("no rate limiter for test_object.a")
+	}
+	if got, want := float64(limiter.Limit()), 2.5; got != want {
+		t.Errorf("wrong rate limit %g; want %g", got, want)
+	}
+	// The burst is rounded up, so three operations can begin at once.
+	if got, want := limiter.Burst(), 3; got != want {
+		t.Errorf("wrong burst %d; want %d", got, want)
+	}
+
+	sems = newConcurrencySemaphores(m, ConcurrencyLimits{
+		ProviderRates: map[addrs.Provider]float64{
+			addrs.NewDefaultProvider("test"): 0.5,
+		},
+	})
+	limiter = sems.rateLimiterForNode(&NodeApplyableResourceInstance{NodeAbstractResourceInstance: node})
+	if got, want := float64(limiter.Limit()), 0.5; got != want {
+		t.Errorf("wrong overridden rate limit %g; want %g", got, want)
+	}
+	if got, want := limiter.Burst(), 1; got != want {
+		t.Errorf("wrong overridden burst %d; want %d", got, want)
+	}
+
+	other := NewNodeAbstractResourceInstance(mustResourceInstanceAddr("aws_instance.a"))
+	if got := sems.rateLimiterForNode(&NodeApplyableResourceInstance{NodeAbstractResourceInstance: other}); got != nil {
+		t.Error("unexpected rate limiter for unrelated resource")
+	}
+	if got := sems.rateLimiterForNode(&NodeApplyableOutput{Addr: addrs.OutputValue{Name: "foo"}.Absolute(addrs.RootModuleInstance)}); got != nil {
+		t.Error("unexpected rate limiter for output value")
+	}
+}
+
+func TestNewContext_invalidConcurrencyLimit(t *testing.T) {
+	_, diags := NewContext(&ContextOpts{
+		ConcurrencyLimits: ConcurrencyLimits{
+			ResourceTypes: map[string]int{
+				"test_object": 0,
+			},
+		},
+	})
+	if !diags.HasErrors() {
+		t.Fatal("succeeded; want error")
+	}
+	if got, want := diags.Err().Error(), "Invalid concurrency limit"; !strings.HasPrefix(got, want) {
+		t.Errorf("wrong error\ngot:  %s\nwant: %s", got, want)
+	}
+}
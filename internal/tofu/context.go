@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math"
 	"sort"
 	"sync"
 
@@ -42,6 +43,39 @@ type ContextOpts struct {
 	Providers    map[addrs.Provider]providers.Factory
 	Provisioners map[string]provisioners.Factory
 
+	// ProviderConfigFactories optionally replace the factories in Providers
+	// for the instances that OpenTofu creates to use with a particular
+	// provider configuration, so that the factories can tell the instances
+	// for different configurations apart.
+	ProviderConfigFactories map[addrs.Provider]providers.ConfigFactory
+
+	// ProviderCapabilities optionally gives the capabilities that "tofu
+	// init" recorded for some of the providers, which then take precedence
+	// over the capabilities the providers report in their schemas.
+	ProviderCapabilities map[addrs.Provider]providers.Capabilities
+
+	// ConcurrencyLimits optionally limits the number of concurrent
+	// operations for particular providers or resource types, in addition to
+	// the overall Parallelism. These take precedence over any limits given
+	// in the root module's "concurrency" block.
+	ConcurrencyLimits ConcurrencyLimits
+
+	// RefreshParallelism optionally limits the number of concurrent reads of
+	// remote objects for each provider configuration while refreshing
+	// during planning. These are not counted towards Parallelism.
+	RefreshParallelism RefreshParallelism
+
+	// ValidationCache optionally allows the context to skip building and walking
+	// the validate graph, and building the apply graph to check a plan, when
+	// an earlier run recorded that they passed. It never skips building or
+	// walking the plan graph.
+	ValidationCache *ValidationCache
+
+	// ProviderFunctionCache optionally shares the results of provider
+	// function calls with other runs, in addition to the cache of results
+	// that each context keeps for itself.
+	ProviderFunctionCache ProviderFunctionCache
+
 	UIInput UIInput
 }
 
@@ -77,12 +111,19 @@ type Context struct {
 
 	plugins *contextPlugins
 
+	// providerFunctionResults caches the results of provider function calls
+	// across all of the operations of this context.
+	providerFunctionResults *providerFunctionResults
+
 	hooks   []Hook
 	sh      *stopHook
 	uiInput UIInput
 
 	l                   sync.Mutex // Lock acquired during any task
 	parallelSem         Semaphore
+	concurrencyLimits   ConcurrencyLimits
+	refreshParallelism  RefreshParallelism
+	validationCache     *ValidationCache
 	providerInputConfig map[string]map[string]cty.Value
 	runCond             *sync.Cond
 	runContext          context.Context
@@ -128,7 +169,56 @@ func NewContext(opts *ContextOpts) (*Context, tfdiags.Diagnostics) {
 		par = 10
 	}
 
+	for provider, limit := range opts.ConcurrencyLimits.Providers {
+		if limit < 1 {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid concurrency limit",
+				fmt.Sprintf("The concurrency limit for provider %s must be a positive value. Not %d.", provider, limit),
+			))
+		}
+	}
+	for typeName, limit := range opts.ConcurrencyLimits.ResourceTypes {
+		if limit < 1 {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid concurrency limit",
+				fmt.Sprintf("The concurrency limit for resource type %s must be a positive value. Not %d.", typeName, limit),
+			))
+		}
+	}
+	for provider, limit := range opts.ConcurrencyLimits.ProviderRates {
+		if !(limit > 0) || math.IsInf(limit, 0) {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid rate limit",
+				fmt.Sprintf("The rate limit for provider %s must be a positive number of operations per second. Not %g.", provider, limit),
+			))
+		}
+	}
+	if opts.RefreshParallelism.Default < 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Invalid refresh parallelism",
+			fmt.Sprintf("The refresh parallelism must be a positive value. Not %d.", opts.RefreshParallelism.Default),
+		))
+	}
+	for provider, limit := range opts.RefreshParallelism.Providers {
+		if limit < 1 {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid refresh parallelism",
+				fmt.Sprintf("The refresh parallelism for provider %s must be a positive value. Not %d.", provider, limit),
+			))
+		}
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
 	plugins := newContextPlugins(opts.Providers, opts.Provisioners)
+	plugins.providerCapabilities = opts.ProviderCapabilities
+	plugins.providerConfigFactories = opts.ProviderConfigFactories
 
 	log.Printf("[TRACE] tofu.NewContext: complete")
 
@@ -137,9 +227,13 @@ func NewContext(opts *ContextOpts) (*Context, tfdiags.Diagnostics) {
 		meta:    opts.Meta,
 		uiInput: opts.UIInput,
 
-		plugins: plugins,
+		plugins:                 plugins,
+		providerFunctionResults: newProviderFunctionResults(opts.ProviderFunctionCache),
 
 		parallelSem:         NewSemaphore(par),
+		concurrencyLimits:   opts.ConcurrencyLimits,
+		refreshParallelism:  opts.RefreshParallelism,
+		validationCache:     opts.ValidationCache,
 		providerInputConfig: make(map[string]map[string]cty.Value),
 		sh:                  sh,
 	}, diags
@@ -385,27 +479,34 @@ func (c *Context) checkConfigDependencies(config *configs.Config) tfdiags.Diagno
 		if modCfg == nil || modCfg.Module == nil {
 			return // should not happen, but we'll be robust
 		}
+		var provisioners []*configs.Provisioner
 		for _, rc := range modCfg.Module.ManagedResources {
 			if rc.Managed == nil {
 				continue // should not happen, but we'll be robust
 			}
-			for _, pc := range rc.Managed.Provisioners {
-				if !c.plugins.HasProvisioner(pc.Type) {
-					// This is not a very high-quality error, because really
-					// the caller of tofu.NewContext should've already
-					// done equivalent checks when doing plugin discovery.
-					// This is just to make sure we return a predictable
-					// error in a central place, rather than failing somewhere
-					// later in the non-deterministically-ordered graph walk.
-					diags = diags.Append(tfdiags.Sourceless(
-						tfdiags.Error,
-						"Missing required provisioner plugin",
-						fmt.Sprintf(
-							"This configuration requires provisioner plugin %q, which isn't available. If you're intending to use an external provisioner plugin, you must install it manually into one of the plugin search directories before running OpenTofu.",
-							pc.Type,
-						),
-					))
-				}
+			provisioners = append(provisioners, rc.Managed.Provisioners...)
+		}
+		for _, rc := range modCfg.Module.Removed {
+			if rc.Managed != nil {
+				provisioners = append(provisioners, rc.Managed.Provisioners...)
+			}
+		}
+		for _, pc := range provisioners {
+			if !c.plugins.HasProvisioner(pc.Type) {
+				// This is not a very high-quality error, because really
+				// the caller of tofu.NewContext should've already
+				// done equivalent checks when doing plugin discovery.
+				// This is just to make sure we return a predictable
+				// error in a central place, rather than failing somewhere
+				// later in the non-deterministically-ordered graph walk.
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Error,
+					"Missing required provisioner plugin",
+					fmt.Sprintf(
+						"This configuration requires provisioner plugin %q, which isn't available. If you're intending to use an external provisioner plugin, you must install it manually into one of the plugin search directories before running OpenTofu.",
+						pc.Type,
+					),
+				))
 			}
 		}
 	})
@@ -70,6 +70,11 @@ func (c *Context) Apply(plan *plans.Plan, config *configs.Config) (*states.State
 
 		// We also want to propagate the timestamp from the plan file.
 		PlanTimeTimestamp: plan.Timestamp,
+
+		// Resources that were deferred during planning have no changes
+		// in the plan and must still be treated as deferred here.
+		PlanTimeDeferredResources:         plan.DeferredResources,
+		PlanTimeDeferredResourceInstances: plan.DeferredResourceInstances,
 	})
 	diags = diags.Append(walker.NonFatalDiagnostics)
 	diags = diags.Append(walkDiags)
@@ -98,6 +103,16 @@ func (c *Context) Apply(plan *plans.Plan, config *configs.Config) (*states.State
 Note that the -target option is not suitable for routine use, and is provided only for exceptional situations such as recovering from errors or mistakes, or when OpenTofu specifically suggests to use it as part of an error message.`,
 		))
 	}
+	if len(plan.ExcludeAddrs) > 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Applied changes may be incomplete",
+			`The plan was created with the -exclude option in effect, so some changes requested in the configuration may have been ignored and the output values may not be fully updated. Run the following command to verify that no other changes are pending:
+    tofu plan
+	
+Note that the -exclude option is not suitable for routine use, and is provided only for exceptional situations such as recovering from errors or mistakes, or when OpenTofu specifically suggests to use it as part of an error message.`,
+		))
+	}
 
 	// FIXME: we cannot check for an empty plan for refresh-only, because root
 	// outputs are always stored as changes. The final condition of the state
@@ -141,6 +156,15 @@ func (c *Context) applyGraph(plan *plans.Plan, config *configs.Config, validate
 		return nil, walkApply, diags
 	}
 
+	// The values of ephemeral variables are never saved in the plan file,
+	// so they are kept separately.
+	for name, val := range plan.EphemeralVariableValues {
+		variables[name] = &InputValue{
+			Value:      val,
+			SourceType: ValueFromCaller,
+		}
+	}
+
 	// The plan.VariableValues field only records variables that were actually
 	// set by the caller in the PlanOpts, so we may need to provide
 	// placeholders for any other variables that the user didn't set, in
@@ -168,13 +192,20 @@ func (c *Context) applyGraph(plan *plans.Plan, config *configs.Config, validate
 		operation = walkDestroy
 	}
 
+	// A plan for a stage is applied as if its module were the target.
+	targets := plan.TargetAddrs
+	if !plan.Stage.IsRoot() {
+		targets = append(targets[:len(targets):len(targets)], plan.Stage)
+	}
+
 	graph, moreDiags := (&ApplyGraphBuilder{
 		Config:             config,
 		Changes:            plan.Changes,
 		State:              plan.PriorState,
 		RootVariableValues: variables,
 		Plugins:            c.plugins,
-		Targets:            plan.TargetAddrs,
+		Targets:            targets,
+		Excludes:           plan.ExcludeAddrs,
 		ForceReplace:       plan.ForceReplaceAddrs,
 		Operation:          operation,
 		ExternalReferences: plan.ExternalReferences,
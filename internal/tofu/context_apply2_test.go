@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -22,6 +23,7 @@ import (
 	"github.com/opentofu/opentofu/internal/lang/marks"
 	"github.com/opentofu/opentofu/internal/plans"
 	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/provisioners"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
@@ -2244,3 +2246,804 @@ locals {
 		t.Errorf("expected local value to be \"foo\" but was \"%s\"", module.LocalValues["local_value"].AsString())
 	}
 }
+
+func TestContext2Apply_removedResourceDestroyProvisioner(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+removed {
+  from = aws_instance.foo
+
+  lifecycle {
+    destroy = true
+  }
+
+  provisioner "shell" {
+    when    = destroy
+    command = "destroy ${each.key} ${self.id}"
+  }
+}
+`,
+	})
+	p := testProvider("aws")
+	p.PlanResourceChangeFn = testDiffFn
+	pr := testProvisioner()
+	var commands []string
+	pr.ProvisionResourceFn = func(req provisioners.ProvisionResourceRequest) (resp provisioners.ProvisionResourceResponse) {
+		commands = append(commands, req.Config.GetAttr("command").AsString())
+		return
+	}
+
+	state := states.NewState()
+	root := state.RootModule()
+	root.SetResourceInstanceCurrent(
+		mustResourceInstanceAddr(`aws_instance.foo["a"]`).Resource,
+		&states.ResourceInstanceObjectSrc{
+			Status:    states.ObjectReady,
+			AttrsJSON: []byte(`{"id":"bar","foo":"bar"}`),
+		},
+		mustProviderConfig(`provider["registry.opentofu.org/hashicorp/aws"]`),
+	)
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("aws"): testProviderFuncFixed(p),
+		},
+		Provisioners: map[string]provisioners.Factory{
+			"shell": testProvisionerFuncFixed(pr),
+		},
+	})
+
+	diags := ctx.Validate(m)
+	assertNoErrors(t, diags)
+
+	plan, diags := ctx.Plan(m, state, DefaultPlanOpts)
+	assertNoErrors(t, diags)
+
+	change := plan.Changes.ResourceInstance(mustResourceInstanceAddr(`aws_instance.foo["a"]`))
+	if change == nil || change.Action != plans.Delete {
+		t.Fatalf("expected aws_instance.foo[\"a\"] to be destroyed, got %#v", change)
+	}
+
+	state, diags = ctx.Apply(plan, m)
+	assertNoErrors(t, diags)
+
+	checkStateString(t, state, `<no state>`)
+
+	if diff := cmp.Diff([]string{"destroy a bar"}, commands); diff != "" {
+		t.Errorf("wrong provisioner commands\n%s", diff)
+	}
+}
+
+func TestContext2Plan_removedResourceStillExists(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+}
+
+removed {
+  from = test_object.a
+}
+`,
+	})
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	diags := ctx.Validate(m)
+	if !diags.HasErrors() {
+		t.Fatal("succeeded; want errors")
+	}
+	if got, want := diags.Err().Error(), "Removed resource still exists"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot:  %s\nwant: message containing %q", got, want)
+	}
+}
+
+func TestContext2Plan_removedModuleExceptions(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "logs" {
+  test_string = "foo"
+}
+
+moved {
+  from = module.legacy.test_object.logs
+  to   = test_object.logs
+}
+
+removed {
+  from   = module.legacy
+  except = [module.legacy.test_object.logs]
+}
+`,
+	})
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	state := states.BuildState(func(s *states.SyncState) {
+		for _, addr := range []string{"module.legacy.test_object.app", "module.legacy.test_object.logs"} {
+			s.SetResourceInstanceCurrent(
+				mustResourceInstanceAddr(addr),
+				&states.ResourceInstanceObjectSrc{
+					Status:    states.ObjectReady,
+					AttrsJSON: []byte(`{"test_string":"foo"}`),
+				},
+				mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
+			)
+		}
+	})
+
+	t.Run("moved", func(t *testing.T) {
+		plan, diags := ctx.Plan(m, state, DefaultPlanOpts)
+		assertNoErrors(t, diags)
+
+		for _, test := range []struct {
+			addr   string
+			action plans.Action
+		}{
+			{"module.legacy.test_object.app", plans.Delete},
+			{"test_object.logs", plans.NoOp},
+		} {
+			change := plan.Changes.ResourceInstance(mustResourceInstanceAddr(test.addr))
+			if change == nil {
+				t.Fatalf("no change for %s", test.addr)
+			}
+			if change.Action != test.action {
+				t.Errorf("wrong action for %s: got %s, want %s", test.addr, change.Action, test.action)
+			}
+		}
+	})
+
+	t.Run("not moved", func(t *testing.T) {
+		m := testModuleInline(t, map[string]string{
+			"main.tf": `
+removed {
+  from   = module.legacy
+  except = [module.legacy.test_object.logs]
+}
+`,
+		})
+
+		_, diags := ctx.Plan(m, state, DefaultPlanOpts)
+		if !diags.HasErrors() {
+			t.Fatal("succeeded; want errors")
+		}
+		got := diags.Err().Error()
+		for _, want := range []string{"Excepted objects would be destroyed", "module.legacy.test_object.logs"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("wrong error\ngot:  %s\nwant: message containing %q", got, want)
+			}
+		}
+	})
+}
+
+func TestContext2Apply_triggeredByValues(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+variable "release" {
+  type = string
+}
+resource "test_object" "a" {
+  lifecycle {
+    replace_triggered_by = [ var.release ]
+  }
+}
+resource "test_object" "b" {
+  test_string = "new"
+  lifecycle {
+    replace_triggered_by = [ "release-${var.release}" ]
+  }
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(
+			mustResourceInstanceAddr("test_object.a"),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON:           []byte(`{}`),
+				Status:              states.ObjectReady,
+				ReplaceTriggersJSON: []byte(`{"value":["v1"],"type":["tuple",["string"]]}`),
+			},
+			mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
+		)
+	})
+
+	plan, diags := ctx.Plan(m, state, &PlanOpts{
+		Mode: plans.NormalMode,
+		SetVariables: InputValues{
+			"release": &InputValue{
+				Value:      cty.StringVal("v2"),
+				SourceType: ValueFromCaller,
+			},
+		},
+	})
+	assertNoErrors(t, diags)
+
+	state, diags = ctx.Apply(plan, m)
+	assertNoErrors(t, diags)
+
+	for addr, want := range map[string]string{
+		// replaced because the value changed
+		"test_object.a": `{"value":["v2"],"type":["tuple",["string"]]}`,
+		// created, so the value is recorded for the first time
+		"test_object.b": `{"value":["release-v2"],"type":["tuple",["string"]]}`,
+	} {
+		obj := state.ResourceInstance(mustResourceInstanceAddr(addr)).Current
+		if got := string(obj.ReplaceTriggersJSON); got != want {
+			t.Errorf("wrong recorded triggers for %s\ngot:  %s\nwant: %s", addr, got, want)
+		}
+	}
+}
+
+func TestContext2Apply_resourceRetry(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "throttled" {
+  value = "throttled"
+
+  lifecycle {
+    retry {
+      attempts  = 3
+      backoff   = "0s"
+      on_errors = ["Throttling"]
+    }
+  }
+}
+
+resource "test_object" "invalid" {
+  value = "invalid"
+
+  lifecycle {
+    retry {
+      attempts  = 3
+      backoff   = "0s"
+      on_errors = ["Throttling"]
+    }
+  }
+}
+`,
+	})
+
+	p := testProvider("test")
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		ResourceTypes: map[string]*configschema.Block{
+			"test_object": {
+				Attributes: map[string]*configschema.Attribute{
+					"value": {Type: cty.String, Optional: true},
+				},
+			},
+		},
+	})
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	p.ApplyResourceChangeFn = func(req providers.ApplyResourceChangeRequest) (resp providers.ApplyResourceChangeResponse) {
+		mu.Lock()
+		defer mu.Unlock()
+		value := req.PlannedState.GetAttr("value").AsString()
+		attempts[value]++
+		switch {
+		case value == "throttled" && attempts[value] < 3:
+			resp.Diagnostics = resp.Diagnostics.Append(errors.New("ThrottlingException: Rate exceeded"))
+			resp.NewState = cty.NullVal(req.PlannedState.Type())
+		case value == "invalid":
+			resp.Diagnostics = resp.Diagnostics.Append(errors.New("InvalidParameterValue"))
+			resp.NewState = cty.NullVal(req.PlannedState.Type())
+		default:
+			resp.NewState = req.PlannedState
+		}
+		return resp
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	assertNoErrors(t, diags)
+
+	state, diags := ctx.Apply(plan, m)
+	if !diags.HasErrors() {
+		t.Fatal("expected error")
+	}
+	if got, want := diags.Err().Error(), "InvalidParameterValue"; !strings.Contains(got, want) {
+		t.Errorf("missing error %q in %s", want, got)
+	}
+
+	// The throttled resource is created on the third attempt, while the
+	// error for the other resource doesn't match the retry policy.
+	if diff := cmp.Diff(map[string]int{"throttled": 3, "invalid": 1}, attempts); diff != "" {
+		t.Errorf("wrong attempts\n%s", diff)
+	}
+	if state.ResourceInstance(mustResourceInstanceAddr("test_object.throttled")) == nil {
+		t.Error("test_object.throttled was not created")
+	}
+}
+
+func TestContext2Apply_destroyAfter(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "shared" {
+  value = "shared"
+
+  lifecycle {
+    destroy_after = [test_object.a, module.child]
+  }
+}
+
+resource "test_object" "a" {
+  value = "a"
+}
+
+module "child" {
+  source = "./child"
+}
+`,
+		"child/main.tf": `
+resource "test_object" "b" {
+  value = "b"
+}
+`,
+	})
+
+	p := testProvider("test")
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		ResourceTypes: map[string]*configschema.Block{
+			"test_object": {
+				Attributes: map[string]*configschema.Attribute{
+					"value": {Type: cty.String, Optional: true},
+				},
+			},
+		},
+	})
+	var mu sync.Mutex
+	var destroyed []string
+	p.ApplyResourceChangeFn = func(req providers.ApplyResourceChangeRequest) (resp providers.ApplyResourceChangeResponse) {
+		if req.PlannedState.IsNull() {
+			mu.Lock()
+			destroyed = append(destroyed, req.PriorState.GetAttr("value").AsString())
+			mu.Unlock()
+		}
+		resp.NewState = req.PlannedState
+		return resp
+	}
+
+	state := states.BuildState(func(s *states.SyncState) {
+		for addr, value := range map[string]string{
+			"test_object.shared":         "shared",
+			"test_object.a":              "a",
+			"module.child.test_object.b": "b",
+		} {
+			s.SetResourceInstanceCurrent(mustResourceInstanceAddr(addr), &states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(fmt.Sprintf(`{"value":%q}`, value)),
+				Status:    states.ObjectReady,
+			}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
+		}
+	})
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(m, state, &PlanOpts{
+		Mode: plans.DestroyMode,
+	})
+	assertNoErrors(t, diags)
+
+	_, diags = ctx.Apply(plan, m)
+	assertNoErrors(t, diags)
+
+	if got, want := len(destroyed), 3; got != want {
+		t.Fatalf("destroyed %d objects, want %d: %v", got, want, destroyed)
+	}
+	if got := destroyed[2]; got != "shared" {
+		t.Errorf("test_object.shared was not destroyed last: %v", destroyed)
+	}
+}
+
+func TestContext2Apply_createBeforeDestroyGroup(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  value = "a"
+
+  lifecycle {
+    create_before_destroy_group = "cluster"
+  }
+}
+
+resource "test_object" "b" {
+  value = "b"
+
+  lifecycle {
+    create_before_destroy_group = "cluster"
+  }
+}
+`,
+	})
+
+	p := testProvider("test")
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		ResourceTypes: map[string]*configschema.Block{
+			"test_object": {
+				Attributes: map[string]*configschema.Attribute{
+					"value": {Type: cty.String, Optional: true},
+				},
+			},
+		},
+	})
+	var mu sync.Mutex
+	var events []string
+	p.ApplyResourceChangeFn = func(req providers.ApplyResourceChangeRequest) (resp providers.ApplyResourceChangeResponse) {
+		if req.PlannedState.IsNull() {
+			mu.Lock()
+			events = append(events, "destroy "+req.PriorState.GetAttr("value").AsString())
+			mu.Unlock()
+		} else {
+			value := req.PlannedState.GetAttr("value").AsString()
+			if value == "b" {
+				// Creating b is slow, so a's old object would be destroyed
+				// before b's new object is created if they weren't grouped.
+				time.Sleep(50 * time.Millisecond)
+			}
+			mu.Lock()
+			events = append(events, "create "+value)
+			mu.Unlock()
+		}
+		resp.NewState = req.PlannedState
+		return resp
+	}
+
+	state := states.BuildState(func(s *states.SyncState) {
+		for _, name := range []string{"a", "b"} {
+			s.SetResourceInstanceCurrent(mustResourceInstanceAddr("test_object."+name), &states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(fmt.Sprintf(`{"value":%q}`, name)),
+				Status:    states.ObjectReady,
+			}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
+		}
+	})
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(m, state, &PlanOpts{
+		Mode: plans.NormalMode,
+		ForceReplace: []addrs.AbsResourceInstance{
+			mustResourceInstanceAddr("test_object.a"),
+			mustResourceInstanceAddr("test_object.b"),
+		},
+	})
+	assertNoErrors(t, diags)
+	for _, rc := range plan.Changes.Resources {
+		if rc.Action != plans.CreateThenDelete {
+			t.Errorf("wrong action for %s: %s", rc.Addr, rc.Action)
+		}
+	}
+
+	_, diags = ctx.Apply(plan, m)
+	assertNoErrors(t, diags)
+
+	if len(events) != 4 {
+		t.Fatalf("wrong events: %v", events)
+	}
+	for _, event := range events[:2] {
+		if !strings.HasPrefix(event, "create ") {
+			t.Fatalf("an object was destroyed before all objects in the group were created: %v", events)
+		}
+	}
+}
+
+func TestContext2Apply_operationTimeout(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "created" {
+  value = "stuck"
+
+  lifecycle {
+    operation_timeout = "50ms"
+  }
+}
+
+resource "test_object" "quick" {
+  value = "quick"
+
+  lifecycle {
+    operation_timeout = "1h"
+  }
+}
+`,
+	})
+
+	p, running := testProviderStoppedByTimeout()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	assertNoErrors(t, diags)
+
+	state, diags := ctx.Apply(plan, m)
+	if !diags.HasErrors() {
+		t.Fatal("expected error")
+	}
+	if got, want := diags.Err().Error(), "Operation timed out"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot:  %s\nwant: message containing %q", got, want)
+	}
+	if !p.StopCalled {
+		t.Error("provider was not stopped after the timeout")
+	}
+	if n := running.Load(); n != 0 {
+		t.Errorf("apply finished while %d provider calls were still in progress", n)
+	}
+
+	// The provider may have created the object before it was stopped, so
+	// it's recorded in the state but must be replaced in the next run.
+	created := state.ResourceInstance(mustResourceInstanceAddr("test_object.created"))
+	if !created.HasCurrent() {
+		t.Fatal("test_object.created was not recorded although it may have been created")
+	}
+	if got, want := created.Current.Status, states.ObjectTainted; got != want {
+		t.Errorf("wrong status for test_object.created %s; want %s", got, want)
+	}
+	if got, want := string(created.Current.AttrsJSON), `{"value":"stuck"}`; got != want {
+		t.Errorf("wrong value for test_object.created %s; want %s", got, want)
+	}
+	if state.ResourceInstance(mustResourceInstanceAddr("test_object.quick")) == nil {
+		t.Error("test_object.quick was not created")
+	}
+}
+
+func TestContext2Apply_operationTimeoutUpdate(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  value = "stuck"
+
+  lifecycle {
+    operation_timeout = "50ms"
+  }
+}
+`,
+	})
+
+	p, running := testProviderStoppedByTimeout()
+	addr := mustResourceInstanceAddr("test_object.a")
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(addr, &states.ResourceInstanceObjectSrc{
+			AttrsJSON: []byte(`{"value":"old"}`),
+			Status:    states.ObjectReady,
+		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
+	})
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(m, state, DefaultPlanOpts)
+	assertNoErrors(t, diags)
+
+	state, diags = ctx.Apply(plan, m)
+	if !diags.HasErrors() {
+		t.Fatal("expected error")
+	}
+	if got, want := diags.Err().Error(), "Operation timed out"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot:  %s\nwant: message containing %q", got, want)
+	}
+	if n := running.Load(); n != 0 {
+		t.Errorf("apply finished while %d provider calls were still in progress", n)
+	}
+
+	// The update partially changed the object, so the state records what
+	// the provider reported and the object must be replaced in the next run.
+	obj := state.ResourceInstance(addr)
+	if got, want := obj.Current.Status, states.ObjectTainted; got != want {
+		t.Errorf("wrong status for test_object.a %s; want %s", got, want)
+	}
+	if got, want := string(obj.Current.AttrsJSON), `{"value":"partial"}`; got != want {
+		t.Errorf("wrong value for test_object.a %s; want %s", got, want)
+	}
+}
+
+func TestContext2Apply_operationTimeoutDestroy(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  value = "stuck"
+
+  lifecycle {
+    operation_timeout = "50ms"
+  }
+}
+`,
+	})
+
+	p, running := testProviderStoppedByTimeout()
+	addr := mustResourceInstanceAddr("test_object.a")
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(addr, &states.ResourceInstanceObjectSrc{
+			AttrsJSON: []byte(`{"value":"stuck"}`),
+			Status:    states.ObjectReady,
+		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
+	})
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(m, state, &PlanOpts{
+		Mode: plans.DestroyMode,
+	})
+	assertNoErrors(t, diags)
+
+	state, diags = ctx.Apply(plan, m)
+	if !diags.HasErrors() {
+		t.Fatal("expected error")
+	}
+	if got, want := diags.Err().Error(), "Operation timed out"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot:  %s\nwant: message containing %q", got, want)
+	}
+	if !p.StopCalled {
+		t.Error("provider was not stopped after the timeout")
+	}
+	if n := running.Load(); n != 0 {
+		t.Errorf("apply finished while %d provider calls were still in progress", n)
+	}
+
+	// The object may still exist, so it must stay in the state.
+	obj := state.ResourceInstance(addr)
+	if !obj.HasCurrent() {
+		t.Fatal("test_object.a was removed from the state although its deletion timed out")
+	}
+	if got, want := obj.Current.Status, states.ObjectReady; got != want {
+		t.Errorf("wrong status for test_object.a %s; want %s", got, want)
+	}
+	if got, want := string(obj.Current.AttrsJSON), `{"value":"stuck"}`; got != want {
+		t.Errorf("wrong value for test_object.a %s; want %s", got, want)
+	}
+}
+
+// testProviderStoppedByTimeout returns a provider whose changes to objects
+// with the value "stuck" don't finish until the provider is stopped, and
+// then report an error along with a partial new state for an update. It
+// also returns the number of those changes in progress.
+func testProviderStoppedByTimeout() (*MockProvider, *atomic.Int32) {
+	p := testProvider("test")
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		ResourceTypes: map[string]*configschema.Block{
+			"test_object": {
+				Attributes: map[string]*configschema.Attribute{
+					"value": {Type: cty.String, Optional: true},
+				},
+			},
+		},
+	})
+
+	// Stopping the provider interrupts all of its calls in progress.
+	var mu sync.Mutex
+	var inFlight []chan struct{}
+	p.StopFn = func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, stopped := range inFlight {
+			close(stopped)
+		}
+		inFlight = nil
+		return nil
+	}
+	running := new(atomic.Int32)
+	p.ApplyResourceChangeFn = func(req providers.ApplyResourceChangeRequest) (resp providers.ApplyResourceChangeResponse) {
+		value := req.PlannedState
+		if value.IsNull() {
+			value = req.PriorState
+		}
+		if value.GetAttr("value").AsString() != "stuck" {
+			resp.NewState = req.PlannedState
+			return resp
+		}
+
+		running.Add(1)
+		defer running.Add(-1)
+		stopped := make(chan struct{})
+		mu.Lock()
+		inFlight = append(inFlight, stopped)
+		mu.Unlock()
+		<-stopped
+
+		resp.Diagnostics = resp.Diagnostics.Append(errors.New("interrupted"))
+		if !req.PriorState.IsNull() && !req.PlannedState.IsNull() {
+			resp.NewState = cty.ObjectVal(map[string]cty.Value{
+				"value": cty.StringVal("partial"),
+			})
+		}
+		return resp
+	}
+	return p, running
+}
+
+func TestContext2Apply_providerCrash(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  value = "new"
+}
+`,
+	})
+
+	p := testProvider("test")
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		ResourceTypes: map[string]*configschema.Block{
+			"test_object": {
+				Attributes: map[string]*configschema.Attribute{
+					"value": {Type: cty.String, Optional: true},
+				},
+			},
+		},
+	})
+	p.ApplyResourceChangeFn = func(req providers.ApplyResourceChangeRequest) (resp providers.ApplyResourceChangeResponse) {
+		resp.Diagnostics = resp.Diagnostics.Append(tfdiags.WholeContainingBodyWithExtra(
+			tfdiags.Error,
+			"Plugin did not respond",
+			"The plugin crashed.",
+			providers.PluginCrashed{},
+		))
+		return resp
+	}
+
+	addr := mustResourceInstanceAddr("test_object.a")
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(addr, &states.ResourceInstanceObjectSrc{
+			AttrsJSON: []byte(`{"value":"old"}`),
+			Status:    states.ObjectReady,
+		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
+	})
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(m, state, DefaultPlanOpts)
+	assertNoErrors(t, diags)
+
+	state, diags = ctx.Apply(plan, m)
+	if !diags.HasErrors() {
+		t.Fatal("expected error")
+	}
+
+	// The provider might have partially updated the object before it
+	// crashed, so it must be replaced in the next run.
+	obj := state.ResourceInstance(addr)
+	if got, want := obj.Current.Status, states.ObjectTainted; got != want {
+		t.Errorf("wrong status %s; want %s", got, want)
+	}
+	if got, want := string(obj.Current.AttrsJSON), `{"value":"old"}`; got != want {
+		t.Errorf("wrong value %s; want %s", got, want)
+	}
+}
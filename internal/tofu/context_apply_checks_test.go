@@ -653,6 +653,183 @@ check "error" {
 				},
 			},
 		},
+		"failing with error severity": {
+			configs: map[string]string{
+				"main.tf": `
+provider "checks" {}
+
+check "failing" {
+  severity = error
+
+  data "checks_object" "positive" {}
+
+  assert {
+    condition     = data.checks_object.positive.number >= 0
+    error_message = "negative number"
+  }
+}
+`,
+			},
+			plan: map[string]checksTestingStatus{
+				"failing": {
+					status:   checks.StatusFail,
+					messages: []string{"negative number"},
+				},
+			},
+			planWarning: "Check block assertion failed: negative number",
+			applyError:  "Check block assertion failed: negative number",
+			provider: &MockProvider{
+				Meta: "checks",
+				GetProviderSchemaResponse: &providers.GetProviderSchemaResponse{
+					DataSources: map[string]providers.Schema{
+						"checks_object": {
+							Block: &configschema.Block{
+								Attributes: map[string]*configschema.Attribute{
+									"number": {
+										Type:     cty.Number,
+										Computed: true,
+									},
+								},
+							},
+						},
+					},
+				},
+				ReadDataSourceFn: func(request providers.ReadDataSourceRequest) providers.ReadDataSourceResponse {
+					return providers.ReadDataSourceResponse{
+						State: cty.ObjectVal(map[string]cty.Value{
+							"number": cty.NumberIntVal(-1),
+						}),
+					}
+				},
+			},
+		},
+		"failing nested data source with error severity": {
+			configs: map[string]string{
+				"main.tf": `
+provider "checks" {}
+
+check "error" {
+  severity = error
+
+  data "checks_object" "data_block" {}
+
+  assert {
+    condition = data.checks_object.data_block.number >= 0
+    error_message = "negative number"
+  }
+}
+`,
+			},
+			plan: map[string]checksTestingStatus{
+				"error": {
+					status: checks.StatusFail,
+					messages: []string{
+						"data source read failed: the endpoint isn't healthy yet",
+					},
+				},
+			},
+			planWarning: "data source read failed: the endpoint isn't healthy yet",
+			applyError:  "data source read failed: the endpoint isn't healthy yet",
+			provider: &MockProvider{
+				Meta: "checks",
+				GetProviderSchemaResponse: &providers.GetProviderSchemaResponse{
+					DataSources: map[string]providers.Schema{
+						"checks_object": {
+							Block: &configschema.Block{
+								Attributes: map[string]*configschema.Attribute{
+									"number": {
+										Type:     cty.Number,
+										Computed: true,
+									},
+								},
+							},
+						},
+					},
+				},
+				ReadDataSourceFn: func(request providers.ReadDataSourceRequest) providers.ReadDataSourceResponse {
+					return providers.ReadDataSourceResponse{
+						Diagnostics: tfdiags.Diagnostics{tfdiags.Sourceless(tfdiags.Error, "data source read failed", "the endpoint isn't healthy yet")},
+					}
+				},
+			},
+		},
+		"failing nested data source retried during apply": {
+			configs: map[string]string{
+				"main.tf": `
+provider "checks" {}
+
+check "retry" {
+  severity = error
+
+  retry {
+    attempts = 3
+    interval = "0s"
+  }
+
+  data "checks_object" "data_block" {}
+
+  assert {
+    condition = data.checks_object.data_block.number >= 0
+    error_message = "negative number"
+  }
+}
+`,
+			},
+			plan: map[string]checksTestingStatus{
+				"retry": {
+					status: checks.StatusFail,
+					messages: []string{
+						"data source read failed: the endpoint isn't healthy yet",
+					},
+				},
+			},
+			planWarning: "data source read failed: the endpoint isn't healthy yet",
+			apply: map[string]checksTestingStatus{
+				"retry": {
+					status: checks.StatusPass,
+				},
+			},
+			provider: &MockProvider{
+				Meta: "checks",
+				GetProviderSchemaResponse: &providers.GetProviderSchemaResponse{
+					DataSources: map[string]providers.Schema{
+						"checks_object": {
+							Block: &configschema.Block{
+								Attributes: map[string]*configschema.Attribute{
+									"number": {
+										Type:     cty.Number,
+										Computed: true,
+									},
+								},
+							},
+						},
+					},
+				},
+				ReadDataSourceFn: func(request providers.ReadDataSourceRequest) providers.ReadDataSourceResponse {
+					return providers.ReadDataSourceResponse{
+						Diagnostics: tfdiags.Diagnostics{tfdiags.Sourceless(tfdiags.Error, "data source read failed", "the endpoint isn't healthy yet")},
+					}
+				},
+			},
+			providerHook: func(provider *MockProvider) {
+				// The first two reads during the apply fail, and the third
+				// succeeds.
+				var reads int
+				provider.ReadDataSourceFn = func(request providers.ReadDataSourceRequest) providers.ReadDataSourceResponse {
+					reads++
+					if reads < 3 {
+						return providers.ReadDataSourceResponse{
+							Diagnostics: tfdiags.Diagnostics{tfdiags.Sourceless(tfdiags.Error, "data source read failed", "the endpoint isn't healthy yet")},
+						}
+					}
+					return providers.ReadDataSourceResponse{
+						State: cty.ObjectVal(map[string]cty.Value{
+							"number": cty.NumberIntVal(0),
+						}),
+					}
+				}
+			},
+		},
 		"invalid reference into check block": {
 			configs: map[string]string{
 				"main.tf": `
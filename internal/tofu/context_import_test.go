@@ -439,7 +439,7 @@ func TestContextImport_providerConfigResources(t *testing.T) {
 	if !diags.HasErrors() {
 		t.Fatal("should error")
 	}
-	if got, want := diags.Err().Error(), `The configuration for provider["registry.opentofu.org/hashicorp/aws"] depends on values that cannot be determined until apply.`; !strings.Contains(got, want) {
+	if got, want := diags.Err().Error(), `The configuration for provider["registry.opentofu.org/hashicorp/aws"] depends on values that cannot be determined until apply: the value of the "value" argument is not known yet.`; !strings.Contains(got, want) {
 		t.Errorf("wrong error\n got: %s\nwant: %s", got, want)
 	}
 }
@@ -63,6 +63,15 @@ type PlanOpts struct {
 	// warnings as part of the planning result.
 	Targets []addrs.Targetable
 
+	// If Excludes has a non-zero length then it activates excluded planning
+	// mode, where OpenTofu will take actions for all resource instances
+	// except those mentioned in this set and any other objects that depend
+	// on them. Excludes can't be combined with Targets.
+	//
+	// Like targeted planning mode, excluded planning mode is intended for
+	// exceptional use only and generates extra warnings.
+	Excludes []addrs.Targetable
+
 	// ForceReplace is a set of resource instance addresses whose corresponding
 	// objects should be forced planned for replacement if the provider's
 	// plan would otherwise have been to either update the object in-place or
@@ -88,6 +97,46 @@ type PlanOpts struct {
 	//
 	// If empty, then no config will be generated.
 	GenerateConfigPath string
+
+	// GenerateImports asks the providers to list the existing remote objects
+	// of the managed resource types declared in the root module, and
+	// records the ones that OpenTofu doesn't manage yet in the plan, along
+	// with draft import blocks and configuration to adopt them.
+	//
+	// This is only valid in the normal planning mode, and is ignored for
+	// providers that cannot list remote objects.
+	GenerateImports bool
+
+	// DeferralAllowed allows OpenTofu to defer the changes for resources
+	// whose count or for_each arguments depend on values that won't be known
+	// until apply, along with any objects that depend on them, instead of
+	// returning an error. The deferred resources are recorded in the plan
+	// and need another plan once the plan has been applied.
+	DeferralAllowed bool
+
+	// Stage, if not the root module, limits the plan to the resources
+	// declared in the given module call and its descendents, so that a
+	// large configuration can be rolled out one module at a time.
+	//
+	// Unlike with Targets, the objects outside of the stage that it depends
+	// on must already be up to date: the plan fails if it would include
+	// changes to any managed resource instance outside of the stage.
+	Stage addrs.Module
+
+	// DriftSelection, if not empty, selects which of the changes made
+	// outside of OpenTofu a refresh-only plan accepts into the state. The
+	// prior state of the plan keeps the previous values of the objects whose
+	// changes aren't accepted.
+	DriftSelection *plans.DriftSelection
+
+	// Refine, if set, is an earlier saved plan that this plan refines. The
+	// changes it planned for resource instances that aren't affected by
+	// what has changed since are reused instead of being planned again.
+	//
+	// The saved plan must have been created with the same options, other
+	// than this one. If it wasn't, OpenTofu creates a new plan from scratch
+	// and returns a warning explaining why.
+	Refine *PlanRefinement
 }
 
 // Plan generates an execution plan by comparing the given configuration
@@ -168,6 +217,17 @@ func (c *Context) Plan(config *configs.Config, prevRunState *states.State, opts
 		return nil, diags
 	}
 
+	if !opts.DriftSelection.Empty() && opts.Mode != plans.RefreshOnlyMode {
+		// The CLI layer (and other similar callers) should prevent this
+		// combination of options.
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Unsupported plan mode",
+			"Selecting which changes made outside of OpenTofu to accept is allowed only in refresh-only planning mode.",
+		))
+		return nil, diags
+	}
+
 	// By the time we get here, we should have values defined for all of
 	// the root module variables, even if some of them are "unknown". It's the
 	// caller's responsibility to have already handled the decoding of these
@@ -178,6 +238,45 @@ func (c *Context) Plan(config *configs.Config, prevRunState *states.State, opts
 	varDiags := checkInputVariables(config.Module.Variables, opts.SetVariables)
 	diags = diags.Append(varDiags)
 
+	if len(opts.Targets) > 0 && len(opts.Excludes) > 0 {
+		// The CLI layer (and other similar callers) should prevent this
+		// combination of options.
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Incompatible plan options",
+			"Cannot combine resource targeting with resource exclusion. This is a bug in OpenTofu.",
+		))
+		return nil, diags
+	}
+
+	// A stage is planned as if its module were the target, but we keep the
+	// caller's opts intact so that the plan records the stage rather than
+	// the target.
+	walkOpts := opts
+	if !opts.Stage.IsRoot() {
+		if len(opts.Targets) > 0 {
+			// The CLI layer (and other similar callers) should prevent this
+			// combination of options.
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Incompatible plan options",
+				"Cannot combine a stage with resource targeting. This is a bug in OpenTofu.",
+			))
+			return nil, diags
+		}
+		if config.Descendent(opts.Stage) == nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Invalid stage",
+				fmt.Sprintf("There is no module call %s in the configuration, so it cannot be planned as a stage.", opts.Stage),
+			))
+			return nil, diags
+		}
+		stageOpts := *opts
+		stageOpts.Targets = []addrs.Targetable{opts.Stage}
+		walkOpts = &stageOpts
+	}
+
 	if len(opts.Targets) > 0 {
 		diags = diags.Append(tfdiags.Sourceless(
 			tfdiags.Warning,
@@ -187,16 +286,38 @@ func (c *Context) Plan(config *configs.Config, prevRunState *states.State, opts
 The -target option is not for routine use, and is provided only for exceptional situations such as recovering from errors or mistakes, or when OpenTofu specifically suggests to use it as part of an error message.`,
 		))
 	}
+	if len(opts.Excludes) > 0 {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Resource exclusion is in effect",
+			`You are creating a plan with the -exclude option, which means that the result of this plan may not represent all of the changes requested by the current configuration.
+
+The -exclude option is not for routine use, and is provided only for exceptional situations such as recovering from errors or mistakes, or when OpenTofu specifically suggests to use it as part of an error message.`,
+		))
+	}
+
+	if opts.Refine != nil {
+		if reason := refinementIncompatibility(opts.Refine.Plan, opts); reason != "" {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Warning,
+				"Saved plan can't be refined",
+				fmt.Sprintf("%s OpenTofu will create a new plan from scratch instead.", reason),
+			))
+			fullOpts := *walkOpts
+			fullOpts.Refine = nil
+			walkOpts = &fullOpts
+		}
+	}
 
 	var plan *plans.Plan
 	var planDiags tfdiags.Diagnostics
 	switch opts.Mode {
 	case plans.NormalMode:
-		plan, planDiags = c.plan(config, prevRunState, opts)
+		plan, planDiags = c.plan(config, prevRunState, walkOpts)
 	case plans.DestroyMode:
-		plan, planDiags = c.destroyPlan(config, prevRunState, opts)
+		plan, planDiags = c.destroyPlan(config, prevRunState, walkOpts)
 	case plans.RefreshOnlyMode:
-		plan, planDiags = c.refreshOnlyPlan(config, prevRunState, opts)
+		plan, planDiags = c.refreshOnlyPlan(config, prevRunState, walkOpts)
 	default:
 		panic(fmt.Sprintf("unsupported plan mode %s", opts.Mode))
 	}
@@ -208,10 +329,17 @@ The -target option is not for routine use, and is provided only for exceptional
 
 	// convert the variables into the format expected for the plan
 	varVals := make(map[string]plans.DynamicValue, len(opts.SetVariables))
+	ephemeralVals := make(map[string]cty.Value)
 	for k, iv := range opts.SetVariables {
 		if iv.Value == cty.NilVal {
 			continue // We only record values that the caller actually set
 		}
+		if vc, ok := config.Module.Variables[k]; ok && vc.Ephemeral {
+			// Ephemeral values must never be saved in the plan, so we keep
+			// them separately, only in memory.
+			ephemeralVals[k] = iv.Value
+			continue
+		}
 
 		// We use cty.DynamicPseudoType here so that we'll save both the
 		// value _and_ its dynamic type in the plan, so we can recover
@@ -232,11 +360,18 @@ The -target option is not for routine use, and is provided only for exceptional
 	// targets and provider SHAs.
 	if plan != nil {
 		plan.VariableValues = varVals
+		plan.EphemeralVariableValues = ephemeralVals
 		plan.TargetAddrs = opts.Targets
+		plan.ExcludeAddrs = opts.Excludes
+		plan.Stage = opts.Stage
 	} else if !diags.HasErrors() {
 		panic("nil plan but no errors")
 	}
 
+	if plan != nil && !opts.Stage.IsRoot() && !planDiags.HasErrors() {
+		diags = diags.Append(checkStageChanges(plan, opts.Stage))
+	}
+
 	if plan != nil {
 		relevantAttrs, rDiags := c.relevantResourceAttrsForPlan(config, plan)
 		diags = diags.Append(rDiags)
@@ -259,6 +394,39 @@ The -target option is not for routine use, and is provided only for exceptional
 	return plan, diags
 }
 
+// checkStageChanges returns an error if the given plan for a stage includes
+// changes to managed resource instances outside of the stage, which the
+// targeting of the stage pulls in when the stage depends on them or, when
+// destroying, when they depend on the stage.
+func checkStageChanges(plan *plans.Plan, stage addrs.Module) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	var outside []string
+	for _, rc := range plan.Changes.Resources {
+		if rc.Addr.Resource.Resource.Mode != addrs.ManagedResourceMode || rc.Action == plans.NoOp {
+			continue
+		}
+		if stage.TargetContains(rc.Addr) {
+			continue
+		}
+		outside = append(outside, fmt.Sprintf("\n  - %s (%s)", rc.Addr, rc.Action))
+	}
+	if len(outside) == 0 {
+		return diags
+	}
+	sort.Strings(outside)
+
+	diags = diags.Append(tfdiags.Sourceless(
+		tfdiags.Error,
+		"Stage includes changes outside of it",
+		fmt.Sprintf(
+			"The plan for the stage %s includes changes to objects outside of it, which either the stage depends on or which depend on the stage:%s\n\nA stage may only change the objects declared in its own module. Apply the stages containing these objects first, or create a plan without the -stage option.",
+			stage, strings.Join(outside, ""),
+		),
+	))
+	return diags
+}
+
 // checkApplyGraph builds the apply graph out of the current plan to
 // check for any errors that may arise once the planned changes are added to
 // the graph. This allows tofu to report errors (mostly cycles) during
@@ -268,8 +436,16 @@ func (c *Context) checkApplyGraph(plan *plans.Plan, config *configs.Config) tfdi
 		log.Println("[DEBUG] no planned changes, skipping apply graph check")
 		return nil
 	}
+	cacheKey, cacheable := c.validationCache.applyGraphKey(plan)
+	if cacheable && c.validationCache.has(validationCacheApplyGraph, cacheKey) {
+		log.Println("[DEBUG] apply graph for the same changes was already checked, skipping apply graph check")
+		return nil
+	}
 	log.Println("[DEBUG] building apply graph to check for errors")
 	_, _, diags := c.applyGraph(plan, config, true)
+	if cacheable && len(diags) == 0 {
+		c.validationCache.record(validationCacheApplyGraph, cacheKey)
+	}
 	return diags
 }
 
@@ -347,6 +523,10 @@ func (c *Context) refreshOnlyPlan(config *configs.Config, prevRunState *states.S
 		))
 	}
 
+	if !opts.DriftSelection.Empty() {
+		diags = diags.Append(c.selectDrift(config, plan, opts.DriftSelection))
+	}
+
 	// We don't populate RelevantResources for a refresh-only plan, because
 	// they never have any planned actions and so no resource can ever be
 	// "relevant" per the intended meaning of that field.
@@ -438,8 +618,10 @@ func (c *Context) destroyPlan(config *configs.Config, prevRunState *states.State
 	return destroyPlan, diags
 }
 
-func (c *Context) prePlanFindAndApplyMoves(config *configs.Config, prevRunState *states.State, targets []addrs.Targetable) ([]refactoring.MoveStatement, refactoring.MoveResults) {
+func (c *Context) prePlanFindAndApplyMoves(config *configs.Config, prevRunState *states.State, targets []addrs.Targetable) ([]refactoring.MoveStatement, refactoring.MoveResults, tfdiags.Diagnostics) {
 	explicitMoveStmts := refactoring.FindMoveStatements(config)
+	crossPackageMoveStmts, diags := refactoring.FindCrossPackageMoveStatements(config, prevRunState)
+	explicitMoveStmts = append(explicitMoveStmts, crossPackageMoveStmts...)
 	implicitMoveStmts := refactoring.ImpliedMoveStatements(config, prevRunState, explicitMoveStmts)
 	var moveStmts []refactoring.MoveStatement
 	if stmtsLen := len(explicitMoveStmts) + len(implicitMoveStmts); stmtsLen > 0 {
@@ -448,7 +630,7 @@ func (c *Context) prePlanFindAndApplyMoves(config *configs.Config, prevRunState
 		moveStmts = append(moveStmts, implicitMoveStmts...)
 	}
 	moveResults := refactoring.ApplyMoves(moveStmts, prevRunState)
-	return moveStmts, moveResults
+	return moveStmts, moveResults, diags
 }
 
 func (c *Context) prePlanVerifyTargetedMoves(moveResults refactoring.MoveResults, targets []addrs.Targetable) tfdiags.Diagnostics {
@@ -519,6 +701,52 @@ func (c *Context) prePlanVerifyTargetedMoves(moveResults refactoring.MoveResults
 	return diags
 }
 
+func (c *Context) prePlanVerifyExcludedMoves(moveResults refactoring.MoveResults, excludes []addrs.Targetable) tfdiags.Diagnostics {
+	if len(excludes) < 1 {
+		return nil // the following only matters when excluding
+	}
+
+	var diags tfdiags.Diagnostics
+
+	var excluded []addrs.AbsResourceInstance
+	for _, result := range moveResults.Changes.Values() {
+		for _, excludeAddr := range excludes {
+			if excludeAddr.TargetContains(result.From) || excludeAddr.TargetContains(result.To) {
+				excluded = append(excluded, result.To)
+				break
+			}
+		}
+	}
+	if len(excluded) > 0 {
+		sort.Slice(excluded, func(i, j int) bool {
+			return excluded[i].Less(excluded[j])
+		})
+
+		// As for targeting, we show whole resource addresses and skip the
+		// duplicates from multiple instances of the same resource.
+		var listBuf strings.Builder
+		var prevResourceAddr addrs.AbsResource
+		for _, instAddr := range excluded {
+			resourceAddr := instAddr.ContainingResource()
+			if resourceAddr.Equal(prevResourceAddr) {
+				continue
+			}
+			fmt.Fprintf(&listBuf, "\n  - %s", resourceAddr.String())
+			prevResourceAddr = resourceAddr
+		}
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Moved resource instances excluded by exclusion",
+			fmt.Sprintf(
+				"Resource instances in your current state have moved to new addresses in the latest configuration. OpenTofu must include those resource instances while planning in order to ensure a correct result, but your -exclude=... options exclude some of those resource instances:%s\n\nTo create a valid plan, change your -exclude=... options so that they don't select these resources.",
+				listBuf.String(),
+			),
+		))
+	}
+
+	return diags
+}
+
 func (c *Context) postPlanValidateMoves(config *configs.Config, stmts []refactoring.MoveStatement, allInsts instances.Set) tfdiags.Diagnostics {
 	return refactoring.ValidateMoves(stmts, config, allInsts)
 }
@@ -555,7 +783,10 @@ func (c *Context) postPlanValidateImports(config *configs.Config, importTargets
 func (c *Context) findImportTargets(config *configs.Config, priorState *states.State) []*ImportTarget {
 	var importTargets []*ImportTarget
 	for _, ic := range config.Module.Import {
-		if priorState.ResourceInstance(ic.To) == nil {
+		// The resource instances that an import block with for_each targets
+		// aren't known until we evaluate for_each during planning, so these
+		// are filtered later when they are expanded.
+		if ic.ForEach != nil || priorState.ResourceInstance(ic.To) == nil {
 			importTargets = append(importTargets, &ImportTarget{
 				Addr:   ic.To,
 				ID:     ic.ID,
@@ -571,11 +802,13 @@ func (c *Context) planWalk(config *configs.Config, prevRunState *states.State, o
 	log.Printf("[DEBUG] Building and walking plan graph for %s", opts.Mode)
 
 	prevRunState = prevRunState.DeepCopy() // don't modify the caller's object when we process the moves
-	moveStmts, moveResults := c.prePlanFindAndApplyMoves(config, prevRunState, opts.Targets)
+	moveStmts, moveResults, moveDiags := c.prePlanFindAndApplyMoves(config, prevRunState, opts.Targets)
+	diags = diags.Append(moveDiags)
 
 	// If resource targeting is in effect then it might conflict with the
 	// move result.
 	diags = diags.Append(c.prePlanVerifyTargetedMoves(moveResults, opts.Targets))
+	diags = diags.Append(c.prePlanVerifyExcludedMoves(moveResults, opts.Excludes))
 	if diags.HasErrors() {
 		// We'll return early here, because if we have any moved resource
 		// instances excluded by targeting then planning is likely to encounter
@@ -583,6 +816,15 @@ func (c *Context) planWalk(config *configs.Config, prevRunState *states.State, o
 		return nil, diags
 	}
 
+	// The objects of removed modules must not include any of the exceptions
+	// in their removed blocks, once we've applied any moves.
+	removeStmts, removeDiags := refactoring.FindRemoveStatements(config)
+	diags = diags.Append(removeDiags)
+	diags = diags.Append(refactoring.ValidateRemoveExceptions(removeStmts, prevRunState))
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
 	graph, walkOp, moreDiags := c.planGraph(config, prevRunState, opts)
 	diags = diags.Append(moreDiags)
 	if diags.HasErrors() {
@@ -591,6 +833,25 @@ func (c *Context) planWalk(config *configs.Config, prevRunState *states.State, o
 
 	timestamp := time.Now().UTC()
 
+	// Unless we're skipping it, the refresh of the existing objects happens
+	// in batches per provider configuration that run ahead of the nodes
+	// that plan their changes.
+	//
+	// When refining a saved plan we skip the batches, because most objects
+	// won't be refreshed at all.
+	var batch *refreshBatch
+	var refinement *planRefinement
+	if walkOp == walkPlan && opts.Refine != nil {
+		refinement = newPlanRefinement(opts.Refine)
+	} else if walkOp == walkPlan && !opts.SkipRefresh {
+		batch = newRefreshBatch(config, prevRunState, opts.Targets, opts.Excludes, c.refreshParallelism)
+	}
+
+	var discovery *resourceDiscovery
+	if walkOp == walkPlan && opts.Mode == plans.NormalMode && opts.GenerateImports {
+		discovery = newResourceDiscovery()
+	}
+
 	// If we get here then we should definitely have a non-nil "graph", which
 	// we can now walk.
 	changes := plans.NewChanges()
@@ -600,6 +861,10 @@ func (c *Context) planWalk(config *configs.Config, prevRunState *states.State, o
 		Changes:           changes,
 		MoveResults:       moveResults,
 		PlanTimeTimestamp: timestamp,
+		DeferralAllowed:   opts.DeferralAllowed,
+		RefreshBatch:      batch,
+		Refinement:        refinement,
+		Discovery:         discovery,
 	})
 	diags = diags.Append(walker.NonFatalDiagnostics)
 	diags = diags.Append(walkDiags)
@@ -648,18 +913,25 @@ func (c *Context) planWalk(config *configs.Config, prevRunState *states.State, o
 	diags = diags.Append(driftDiags)
 
 	plan := &plans.Plan{
-		UIMode:             opts.Mode,
-		Changes:            changes,
-		DriftedResources:   driftedResources,
-		PrevRunState:       prevRunState,
-		PriorState:         priorState,
-		PlannedState:       walker.State.Close(),
-		ExternalReferences: opts.ExternalReferences,
-		Checks:             states.NewCheckResults(walker.Checks),
-		Timestamp:          timestamp,
+		UIMode:                    opts.Mode,
+		Changes:                   changes,
+		DriftedResources:          driftedResources,
+		PrevRunState:              prevRunState,
+		PriorState:                priorState,
+		PlannedState:              walker.State.Close(),
+		ExternalReferences:        opts.ExternalReferences,
+		Checks:                    states.NewCheckResults(walker.Checks),
+		DeferredResources:         walker.Deferred.Resources(),
+		DeferredResourceInstances: walker.Deferred.ResourceInstances(),
+		Timestamp:                 timestamp,
 
 		// Other fields get populated by Context.Plan after we return
 	}
+	if discovery != nil && !diags.HasErrors() {
+		discovered, moreDiags := discovery.resources(config, priorState, changes)
+		diags = diags.Append(moreDiags)
+		plan.DiscoveredResources = discovered
+	}
 	return plan, diags
 }
 
@@ -672,6 +944,7 @@ func (c *Context) planGraph(config *configs.Config, prevRunState *states.State,
 			RootVariableValues: opts.SetVariables,
 			Plugins:            c.plugins,
 			Targets:            opts.Targets,
+			Excludes:           opts.Excludes,
 			ForceReplace:       opts.ForceReplace,
 			skipRefresh:        opts.SkipRefresh,
 			preDestroyRefresh:  opts.PreDestroyRefresh,
@@ -688,6 +961,7 @@ func (c *Context) planGraph(config *configs.Config, prevRunState *states.State,
 			RootVariableValues: opts.SetVariables,
 			Plugins:            c.plugins,
 			Targets:            opts.Targets,
+			Excludes:           opts.Excludes,
 			skipRefresh:        opts.SkipRefresh,
 			skipPlanChanges:    true, // this activates "refresh only" mode.
 			Operation:          walkPlan,
@@ -701,6 +975,7 @@ func (c *Context) planGraph(config *configs.Config, prevRunState *states.State,
 			RootVariableValues: opts.SetVariables,
 			Plugins:            c.plugins,
 			Targets:            opts.Targets,
+			Excludes:           opts.Excludes,
 			skipRefresh:        opts.SkipRefresh,
 			Operation:          walkPlanDestroy,
 		}).Build(addrs.RootModuleInstance)
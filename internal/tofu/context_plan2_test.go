@@ -7,9 +7,12 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/google/go-cmp/cmp"
@@ -326,6 +329,83 @@ resource "test_object" "a" {
 	assertNoErrors(t, diags)
 }
 
+func TestContext2Plan_derivedProviderConfiguration(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+provider "test" {
+  alias = "z"
+  test_string = "config"
+  test_number = 1
+}
+
+module "mod" {
+  source = "./mod"
+  providers = {
+    test.x = test.z
+  }
+}
+`,
+
+		"mod/main.tf": `
+terraform {
+  required_providers {
+    test = {
+      source = "registry.opentofu.org/hashicorp/test"
+      configuration_aliases = [ test.x ]
+	}
+  }
+}
+
+provider "test" {
+  alias = "west"
+  inherit = test.x
+  test_string = "west"
+}
+
+resource "test_object" "a" {
+  provider = test.x
+}
+
+resource "test_object" "b" {
+  provider = test.west
+}
+`,
+	})
+
+	var mu sync.Mutex
+	var configured []string
+	factory := func() (providers.Interface, error) {
+		p := simpleMockProvider()
+		p.ConfigureProviderFn = func(req providers.ConfigureProviderRequest) (resp providers.ConfigureProviderResponse) {
+			mu.Lock()
+			defer mu.Unlock()
+			configured = append(configured, fmt.Sprintf("%s %s", req.Config.GetAttr("test_string").AsString(), req.Config.GetAttr("test_number").AsBigFloat().String()))
+			return resp
+		}
+		return p, nil
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): factory,
+		},
+	})
+
+	diags := ctx.Validate(m)
+	assertNoErrors(t, diags)
+
+	_, diags = ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	assertNoErrors(t, diags)
+
+	// The derived configuration overrides test_string and inherits
+	// test_number from the configuration passed in by the root module.
+	sort.Strings(configured)
+	want := []string{"config 1", "west 1"}
+	if diff := cmp.Diff(want, configured); diff != "" {
+		t.Errorf("wrong provider configurations\n%s", diff)
+	}
+}
+
 func TestContext2Plan_dataReferencesResourceInModules(t *testing.T) {
 	p := testProvider("test")
 	p.ReadDataSourceFn = func(req providers.ReadDataSourceRequest) (resp providers.ReadDataSourceResponse) {
@@ -1838,6 +1918,128 @@ func TestContext2Plan_movedResourceRefreshOnly(t *testing.T) {
 	})
 }
 
+func TestContext2Plan_movedResourceCrossPackage(t *testing.T) {
+	// The "network" module package records that its test_object.vpc used to
+	// belong to the "legacy" module package.
+	networkConfig := `
+		resource "test_object" "vpc" {
+			test_string = "foo"
+		}
+
+		moved {
+			from_module_source = "./legacy"
+			from               = test_object.vpc
+			to                 = test_object.vpc
+		}
+	`
+	addrNew := mustResourceInstanceAddr("module.network.test_object.vpc")
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	stateWith := func(addrs ...string) *states.State {
+		return states.BuildState(func(s *states.SyncState) {
+			for _, addr := range addrs {
+				s.SetResourceInstanceCurrent(mustResourceInstanceAddr(addr), &states.ResourceInstanceObjectSrc{
+					AttrsJSON: []byte(`{"test_string":"foo"}`),
+					Status:    states.ObjectReady,
+				}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
+			}
+		})
+	}
+
+	assertMoved := func(t *testing.T, plan *plans.Plan, from addrs.AbsResourceInstance) {
+		t.Helper()
+		if instPlan := plan.Changes.ResourceInstance(from); instPlan != nil {
+			t.Fatalf("unexpected plan for %s; should've moved to %s", from, addrNew)
+		}
+		instPlan := plan.Changes.ResourceInstance(addrNew)
+		if instPlan == nil {
+			t.Fatalf("no plan for %s at all", addrNew)
+		}
+		if got, want := instPlan.PrevRunAddr, from; !got.Equal(want) {
+			t.Errorf("wrong previous run address\ngot:  %s\nwant: %s", got, want)
+		}
+		if got, want := instPlan.Action, plans.NoOp; got != want {
+			t.Errorf("wrong planned action\ngot:  %s\nwant: %s", got, want)
+		}
+	}
+
+	t.Run("prior package still called", func(t *testing.T) {
+		m := testModuleInline(t, map[string]string{
+			"main.tf": `
+				module "app" {
+					source = "./legacy"
+				}
+
+				module "network" {
+					source = "./network"
+				}
+			`,
+			"legacy/main.tf":  `# The resource is no longer declared here.`,
+			"network/main.tf": networkConfig,
+		})
+
+		from := mustResourceInstanceAddr("module.app.test_object.vpc")
+		plan, diags := ctx.Plan(m, stateWith(from.String()), DefaultPlanOpts)
+		assertNoErrors(t, diags)
+		assertMoved(t, plan, from)
+	})
+
+	t.Run("prior package no longer called", func(t *testing.T) {
+		m := testModuleInline(t, map[string]string{
+			"main.tf": `
+				module "network" {
+					source = "./network"
+				}
+			`,
+			"network/main.tf": networkConfig,
+		})
+
+		from := mustResourceInstanceAddr("module.app.test_object.vpc")
+		plan, diags := ctx.Plan(m, stateWith(from.String()), DefaultPlanOpts)
+		assertNoErrors(t, diags)
+		assertMoved(t, plan, from)
+	})
+
+	t.Run("ambiguous", func(t *testing.T) {
+		m := testModuleInline(t, map[string]string{
+			"main.tf": `
+				module "network" {
+					source = "./network"
+				}
+			`,
+			"network/main.tf": networkConfig,
+		})
+
+		_, diags := ctx.Plan(m, stateWith("module.a.test_object.vpc", "module.b.test_object.vpc"), DefaultPlanOpts)
+		if !diags.HasErrors() {
+			t.Fatal("succeeded; want errors")
+		}
+		if got, want := diags.Err().Error(), "Ambiguous cross-package move"; !strings.Contains(got, want) {
+			t.Fatalf("wrong error\ngot:  %s\nwant: message containing %q", got, want)
+		}
+	})
+
+	t.Run("root module", func(t *testing.T) {
+		m := testModuleInline(t, map[string]string{
+			"main.tf": networkConfig,
+		})
+
+		diags := ctx.Validate(m)
+		if !diags.HasErrors() {
+			t.Fatal("succeeded; want errors")
+		}
+		if got, want := diags.Err().Error(), "Invalid cross-package move"; !strings.Contains(got, want) {
+			t.Fatalf("wrong error\ngot:  %s\nwant: message containing %q", got, want)
+		}
+	})
+}
+
 func TestContext2Plan_refreshOnlyMode(t *testing.T) {
 	addr := mustResourceInstanceAddr("test_object.a")
 
@@ -2279,6 +2481,94 @@ output "root" {
 	}
 }
 
+func TestContext2Plan_sensitiveOutputAttributes(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+locals {
+  password = sensitive("hunter2")
+}
+
+output "credentials" {
+  value = {
+    user     = "admin"
+    password = local.password
+    token    = "abc123"
+  }
+  sensitive_attributes = [password, token]
+}
+
+output "settings" {
+  value                = nonsensitive_keys(sensitive({ a = "b" }))
+  sensitive_attributes = [a]
+}
+`,
+	})
+
+	ctx := testContext2(t, &ContextOpts{})
+
+	plan, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	assertNoErrors(t, diags)
+
+	for _, name := range []string{"credentials", "settings"} {
+		ocs := plan.Changes.OutputValue(addrs.OutputValue{Name: name}.Absolute(addrs.RootModuleInstance))
+		if ocs == nil {
+			t.Fatalf("no change for output %q", name)
+		}
+		if ocs.Sensitive {
+			t.Errorf("output %q change is wholly sensitive; want only its sensitive attributes marked", name)
+		}
+		oc, err := ocs.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if oc.After.IsMarked() {
+			t.Errorf("output %q value is marked; want only its sensitive attributes marked", name)
+		}
+	}
+
+	oc, err := plan.Changes.OutputValue(addrs.OutputValue{Name: "credentials"}.Absolute(addrs.RootModuleInstance)).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]bool{"user": false, "password": true, "token": true} {
+		if got := oc.After.GetAttr(name).HasMark(marks.Sensitive); got != want {
+			t.Errorf("wrong sensitivity for credentials.%s: got %t, want %t", name, got, want)
+		}
+	}
+
+	// The state records sensitivity only for whole output values, so the
+	// output value is saved as sensitive.
+	state, diags := ctx.Apply(plan, m)
+	assertNoErrors(t, diags)
+	if os := state.OutputValue(addrs.OutputValue{Name: "credentials"}.Absolute(addrs.RootModuleInstance)); os == nil || !os.Sensitive {
+		t.Errorf("credentials output value is not saved as sensitive")
+	}
+
+	// Any sensitive values outside of the sensitive attributes are still
+	// an error.
+	m = testModuleInline(t, map[string]string{
+		"main.tf": `
+output "credentials" {
+  value = {
+    user     = sensitive("admin")
+    password = "hunter2"
+  }
+  sensitive_attributes = [password]
+}
+`,
+	})
+
+	ctx = testContext2(t, &ContextOpts{})
+
+	_, diags = ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	if !diags.HasErrors() {
+		t.Fatal("succeeded; want errors")
+	}
+	if got, want := diags.Err().Error(), "Output refers to sensitive values"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error:\ngot:  %s\nwant: message containing %q", got, want)
+	}
+}
+
 func TestContext2Plan_planDataSourceSensitiveNested(t *testing.T) {
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
@@ -2743,319 +3033,497 @@ func TestContext2Plan_moduleExpandOrphansResourceInstance(t *testing.T) {
 	})
 }
 
-func TestContext2Plan_resourcePreconditionPostcondition(t *testing.T) {
+func TestContext2Plan_moduleEnabled(t *testing.T) {
+	// A module call with a lifecycle "enabled" argument has either a single
+	// instance without any key or no instances at all, and so toggling it
+	// off behaves like removing the call from the configuration.
+	addr := mustResourceInstanceAddr("module.child.test_object.a")
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
-variable "boop" {
-  type = string
-}
+			variable "enabled" {
+				type = bool
+			}
 
-resource "test_resource" "a" {
-  value = var.boop
-  lifecycle {
-    precondition {
-      condition     = var.boop == "boop"
-      error_message = "Wrong boop."
-    }
-    postcondition {
-      condition     = self.output != ""
-      error_message = "Output must not be blank."
-    }
-  }
-}
+			module "child" {
+				source = "./child"
 
-`,
+				lifecycle {
+					enabled = var.enabled
+				}
+			}
+
+			output "child" {
+				value = module.child.id
+			}
+		`,
+		"child/main.tf": `
+			resource "test_object" "a" {
+			}
+
+			output "id" {
+				value = "child"
+			}
+		`,
 	})
 
-	p := testProvider("test")
-	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
-		ResourceTypes: map[string]*configschema.Block{
-			"test_resource": {
-				Attributes: map[string]*configschema.Attribute{
-					"value": {
-						Type:     cty.String,
-						Required: true,
-					},
-					"output": {
-						Type:     cty.String,
-						Computed: true,
-					},
-				},
-			},
-		},
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(addr, &states.ResourceInstanceObjectSrc{
+			AttrsJSON: []byte(`{}`),
+			Status:    states.ObjectReady,
+		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
 	})
 
-	t.Run("conditions pass", func(t *testing.T) {
-		ctx := testContext2(t, &ContextOpts{
-			Providers: map[addrs.Provider]providers.Factory{
-				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
-			},
-		})
+	p := simpleMockProvider()
 
-		p.PlanResourceChangeFn = func(req providers.PlanResourceChangeRequest) (resp providers.PlanResourceChangeResponse) {
-			m := req.ProposedNewState.AsValueMap()
-			m["output"] = cty.StringVal("bar")
+	for _, enabled := range []bool{true, false} {
+		t.Run(fmt.Sprintf("enabled=%t", enabled), func(t *testing.T) {
+			ctx := testContext2(t, &ContextOpts{
+				Providers: map[addrs.Provider]providers.Factory{
+					addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+				},
+			})
 
-			resp.PlannedState = cty.ObjectVal(m)
-			resp.LegacyTypeSystem = true
-			return resp
-		}
-		plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
-			Mode: plans.NormalMode,
-			SetVariables: InputValues{
-				"boop": &InputValue{
-					Value:      cty.StringVal("boop"),
-					SourceType: ValueFromCLIArg,
+			plan, diags := ctx.Plan(m, state, &PlanOpts{
+				Mode: plans.NormalMode,
+				SetVariables: InputValues{
+					"enabled": &InputValue{
+						Value:      cty.BoolVal(enabled),
+						SourceType: ValueFromCLIArg,
+					},
 				},
-			},
-		})
-		assertNoErrors(t, diags)
-		for _, res := range plan.Changes.Resources {
-			switch res.Addr.String() {
-			case "test_resource.a":
-				if res.Action != plans.Create {
-					t.Fatalf("unexpected %s change for %s", res.Action, res.Addr)
-				}
-			default:
-				t.Fatalf("unexpected %s change for %s", res.Action, res.Addr)
-			}
-		}
-	})
+			})
+			assertNoErrors(t, diags)
 
-	t.Run("precondition fail", func(t *testing.T) {
-		ctx := testContext2(t, &ContextOpts{
-			Providers: map[addrs.Provider]providers.Factory{
-				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
-			},
-		})
+			instPlan := plan.Changes.ResourceInstance(addr)
+			if instPlan == nil {
+				t.Fatalf("no plan for %s at all", addr)
+			}
+			wantAction, wantReason := plans.NoOp, plans.ResourceInstanceChangeNoReason
+			if !enabled {
+				wantAction, wantReason = plans.Delete, plans.ResourceInstanceDeleteBecauseNoModule
+			}
+			if got, want := instPlan.Action, wantAction; got != want {
+				t.Errorf("wrong planned action\ngot:  %s\nwant: %s", got, want)
+			}
+			if got, want := instPlan.ActionReason, wantReason; got != want {
+				t.Errorf("wrong action reason\ngot:  %s\nwant: %s", got, want)
+			}
 
-		_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
-			Mode: plans.NormalMode,
-			SetVariables: InputValues{
-				"boop": &InputValue{
-					Value:      cty.StringVal("nope"),
-					SourceType: ValueFromCLIArg,
-				},
-			},
+			outPlan := plan.Changes.OutputValue(addrs.OutputValue{Name: "child"}.Absolute(addrs.RootModuleInstance))
+			if outPlan == nil {
+				t.Fatal("no plan for output value \"child\"")
+			}
+			outChange, err := outPlan.Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantOut := cty.StringVal("child")
+			if !enabled {
+				wantOut = cty.NullVal(cty.DynamicPseudoType)
+			}
+			if got := outChange.After; !got.RawEquals(wantOut) {
+				t.Errorf("wrong output value\ngot:  %#v\nwant: %#v", got, wantOut)
+			}
 		})
-		if !diags.HasErrors() {
-			t.Fatal("succeeded; want errors")
-		}
-		if got, want := diags.Err().Error(), "Resource precondition failed: Wrong boop."; got != want {
-			t.Fatalf("wrong error:\ngot:  %s\nwant: %q", got, want)
-		}
-		if p.PlanResourceChangeCalled {
-			t.Errorf("Provider's PlanResourceChange was called; should'nt've been")
-		}
+	}
+}
+
+func TestContext2Plan_moduleEnabledUnknown(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+			module "child" {
+				source = "./child"
+
+				lifecycle {
+					enabled = timestamp() != ""
+				}
+			}
+		`,
+		"child/main.tf": `
+			resource "test_object" "b" {
+			}
+		`,
 	})
 
-	t.Run("precondition fail refresh-only", func(t *testing.T) {
-		ctx := testContext2(t, &ContextOpts{
-			Providers: map[addrs.Provider]providers.Factory{
-				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
-			},
-		})
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
 
-		state := states.BuildState(func(s *states.SyncState) {
-			s.SetResourceInstanceCurrent(mustResourceInstanceAddr("test_resource.a"), &states.ResourceInstanceObjectSrc{
-				AttrsJSON: []byte(`{"value":"boop","output":"blorp"}`),
-				Status:    states.ObjectReady,
-			}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
-		})
-		_, diags := ctx.Plan(m, state, &PlanOpts{
-			Mode: plans.RefreshOnlyMode,
-			SetVariables: InputValues{
-				"boop": &InputValue{
-					Value:      cty.StringVal("nope"),
-					SourceType: ValueFromCLIArg,
-				},
+	_, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	if !diags.HasErrors() {
+		t.Fatal("succeeded; want errors")
+	}
+	if got, want := diags.Err().Error(), "Invalid enabled argument"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot:  %s\nwant: message containing %q", got, want)
+	}
+}
+
+func TestContext2Plan_providerFunction(t *testing.T) {
+	// Provider functions are evaluated during planning, and because they
+	// are pure each distinct call reaches the provider only once across all
+	// of the operations of a context.
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+			terraform {
+				required_providers {
+					test = {
+						source = "hashicorp/test"
+					}
+				}
+			}
+
+			resource "test_object" "a" {
+				test_string = provider::test::upper("hello")
+			}
+
+			output "greeting" {
+				value = provider::test::upper("hello")
+			}
+		`,
+	})
+
+	p := simpleMockProvider()
+	p.GetProviderSchemaResponse.Functions = map[string]providers.FunctionDecl{
+		"upper": {
+			Parameters: []providers.FunctionParam{
+				{Name: "str", Type: cty.String},
 			},
-		})
-		assertNoErrors(t, diags)
-		if len(diags) == 0 {
-			t.Fatalf("no diags, but should have warnings")
-		}
-		if got, want := diags.ErrWithWarnings().Error(), "Resource precondition failed: Wrong boop."; got != want {
-			t.Fatalf("wrong warning:\ngot:  %s\nwant: %q", got, want)
+			ReturnType: cty.String,
+		},
+	}
+	calls := 0
+	p.CallFunctionFn = func(req providers.CallFunctionRequest) providers.CallFunctionResponse {
+		calls++
+		if req.Name != "upper" {
+			t.Errorf("wrong function name %q", req.Name)
 		}
-		if !p.ReadResourceCalled {
-			t.Errorf("Provider's ReadResource wasn't called; should've been")
+		return providers.CallFunctionResponse{
+			Result: cty.StringVal(strings.ToUpper(req.Arguments[0].AsString())),
 		}
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
 	})
 
-	t.Run("postcondition fail", func(t *testing.T) {
-		ctx := testContext2(t, &ContextOpts{
-			Providers: map[addrs.Provider]providers.Factory{
-				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
-			},
-		})
+	plan, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	assertNoErrors(t, diags)
 
-		p.PlanResourceChangeFn = func(req providers.PlanResourceChangeRequest) (resp providers.PlanResourceChangeResponse) {
-			m := req.ProposedNewState.AsValueMap()
-			m["output"] = cty.StringVal("")
+	addr := mustResourceInstanceAddr("test_object.a")
+	change := plan.Changes.ResourceInstance(addr)
+	if change == nil {
+		t.Fatalf("no planned change for %s", addr)
+	}
+	schema := p.GetProviderSchemaResponse.ResourceTypes["test_object"].Block
+	ric, err := change.Decode(schema.ImpliedType())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ric.After.GetAttr("test_string"), cty.StringVal("HELLO"); !got.RawEquals(want) {
+		t.Errorf("wrong test_string\ngot:  %#v\nwant: %#v", got, want)
+	}
 
-			resp.PlannedState = cty.ObjectVal(m)
-			resp.LegacyTypeSystem = true
-			return resp
-		}
-		_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
-			Mode: plans.NormalMode,
-			SetVariables: InputValues{
-				"boop": &InputValue{
-					Value:      cty.StringVal("boop"),
-					SourceType: ValueFromCLIArg,
-				},
+	state, diags := ctx.Apply(plan, m)
+	assertNoErrors(t, diags)
+
+	if got, want := state.RootModule().OutputValues["greeting"].Value, cty.StringVal("HELLO"); !got.RawEquals(want) {
+		t.Errorf("wrong greeting\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if calls != 1 {
+		t.Errorf("function was called %d times; want 1", calls)
+	}
+}
+
+func TestContext2Plan_providerFunctionError(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+			terraform {
+				required_providers {
+					test = {
+						source = "hashicorp/test"
+					}
+				}
+			}
+
+			output "parsed" {
+				value = provider::test::parse("invalid")
+			}
+		`,
+	})
+
+	p := simpleMockProvider()
+	p.GetProviderSchemaResponse.Functions = map[string]providers.FunctionDecl{
+		"parse": {
+			Parameters: []providers.FunctionParam{
+				{Name: "input", Type: cty.String},
+			},
+			ReturnType: cty.String,
+		},
+	}
+	p.CallFunctionFn = func(req providers.CallFunctionRequest) providers.CallFunctionResponse {
+		return providers.CallFunctionResponse{
+			Error: &providers.CallFunctionArgumentError{
+				Text:             "input is not in the expected format",
+				FunctionArgument: 0,
 			},
-		})
-		if !diags.HasErrors() {
-			t.Fatal("succeeded; want errors")
-		}
-		if got, want := diags.Err().Error(), "Resource postcondition failed: Output must not be blank."; got != want {
-			t.Fatalf("wrong error:\ngot:  %s\nwant: %q", got, want)
-		}
-		if !p.PlanResourceChangeCalled {
-			t.Errorf("Provider's PlanResourceChange wasn't called; should've been")
 		}
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
 	})
 
-	t.Run("postcondition fail refresh-only", func(t *testing.T) {
-		ctx := testContext2(t, &ContextOpts{
-			Providers: map[addrs.Provider]providers.Factory{
-				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
-			},
-		})
+	_, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	if !diags.HasErrors() {
+		t.Fatal("succeeded; want error")
+	}
+	if got, want := diags.Err().Error(), "input is not in the expected format"; !strings.Contains(got, want) {
+		t.Errorf("wrong error\ngot:  %s\nwant: message containing %q", got, want)
+	}
+}
 
-		state := states.BuildState(func(s *states.SyncState) {
-			s.SetResourceInstanceCurrent(mustResourceInstanceAddr("test_resource.a"), &states.ResourceInstanceObjectSrc{
-				AttrsJSON: []byte(`{"value":"boop","output":"blorp"}`),
-				Status:    states.ObjectReady,
-			}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
-		})
-		p.ReadResourceFn = func(req providers.ReadResourceRequest) (resp providers.ReadResourceResponse) {
-			newVal, err := cty.Transform(req.PriorState, func(path cty.Path, v cty.Value) (cty.Value, error) {
-				if len(path) == 1 && path[0] == (cty.GetAttrStep{Name: "output"}) {
-					return cty.StringVal(""), nil
+func TestContext2Plan_providerFunctionCache(t *testing.T) {
+	// A persistent cache shares results between contexts, so a later run
+	// doesn't need to call the provider at all.
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+			terraform {
+				required_providers {
+					test = {
+						source = "hashicorp/test"
+					}
 				}
-				return v, nil
-			})
-			if err != nil {
-				// shouldn't get here
-				t.Fatalf("ReadResourceFn transform failed")
-				return providers.ReadResourceResponse{}
 			}
-			return providers.ReadResourceResponse{
-				NewState: newVal,
+
+			output "greeting" {
+				value = provider::test::upper("hello")
 			}
-		}
-		_, diags := ctx.Plan(m, state, &PlanOpts{
-			Mode: plans.RefreshOnlyMode,
-			SetVariables: InputValues{
-				"boop": &InputValue{
-					Value:      cty.StringVal("boop"),
-					SourceType: ValueFromCLIArg,
-				},
+		`,
+	})
+
+	p := simpleMockProvider()
+	p.GetProviderSchemaResponse.Functions = map[string]providers.FunctionDecl{
+		"upper": {
+			Parameters: []providers.FunctionParam{
+				{Name: "str", Type: cty.String},
 			},
-		})
-		assertNoErrors(t, diags)
-		if len(diags) == 0 {
-			t.Fatalf("no diags, but should have warnings")
-		}
-		if got, want := diags.ErrWithWarnings().Error(), "Resource postcondition failed: Output must not be blank."; got != want {
-			t.Fatalf("wrong warning:\ngot:  %s\nwant: %q", got, want)
-		}
-		if !p.ReadResourceCalled {
-			t.Errorf("Provider's ReadResource wasn't called; should've been")
-		}
-		if p.PlanResourceChangeCalled {
-			t.Errorf("Provider's PlanResourceChange was called; should'nt've been")
+			ReturnType: cty.String,
+		},
+	}
+	calls := 0
+	p.CallFunctionFn = func(req providers.CallFunctionRequest) providers.CallFunctionResponse {
+		calls++
+		return providers.CallFunctionResponse{
+			Result: cty.StringVal(strings.ToUpper(req.Arguments[0].AsString())),
 		}
-	})
+	}
 
-	t.Run("precondition and postcondition fail refresh-only", func(t *testing.T) {
+	cache := &testProviderFunctionCache{results: make(map[string]cty.Value)}
+	for i := 0; i < 2; i++ {
 		ctx := testContext2(t, &ContextOpts{
 			Providers: map[addrs.Provider]providers.Factory{
 				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 			},
+			ProviderFunctionCache: cache,
 		})
+		plan, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+		assertNoErrors(t, diags)
 
-		state := states.BuildState(func(s *states.SyncState) {
-			s.SetResourceInstanceCurrent(mustResourceInstanceAddr("test_resource.a"), &states.ResourceInstanceObjectSrc{
-				AttrsJSON: []byte(`{"value":"boop","output":"blorp"}`),
-				Status:    states.ObjectReady,
-			}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
-		})
-		p.ReadResourceFn = func(req providers.ReadResourceRequest) (resp providers.ReadResourceResponse) {
-			newVal, err := cty.Transform(req.PriorState, func(path cty.Path, v cty.Value) (cty.Value, error) {
-				if len(path) == 1 && path[0] == (cty.GetAttrStep{Name: "output"}) {
-					return cty.StringVal(""), nil
-				}
-				return v, nil
-			})
-			if err != nil {
-				// shouldn't get here
-				t.Fatalf("ReadResourceFn transform failed")
-				return providers.ReadResourceResponse{}
-			}
-			return providers.ReadResourceResponse{
-				NewState: newVal,
-			}
+		out := plan.Changes.OutputValue(addrs.OutputValue{Name: "greeting"}.Absolute(addrs.RootModuleInstance))
+		if out == nil {
+			t.Fatal("no planned change for output greeting")
 		}
-		_, diags := ctx.Plan(m, state, &PlanOpts{
-			Mode: plans.RefreshOnlyMode,
-			SetVariables: InputValues{
-				"boop": &InputValue{
-					Value:      cty.StringVal("nope"),
-					SourceType: ValueFromCLIArg,
-				},
-			},
-		})
-		assertNoErrors(t, diags)
-		if got, want := len(diags), 2; got != want {
-			t.Errorf("wrong number of warnings, got %d, want %d", got, want)
+		got, err := out.After.Decode(cty.DynamicPseudoType)
+		if err != nil {
+			t.Fatal(err)
 		}
-		warnings := diags.ErrWithWarnings().Error()
-		wantWarnings := []string{
-			"Resource precondition failed: Wrong boop.",
-			"Resource postcondition failed: Output must not be blank.",
+		if want := cty.StringVal("HELLO"); !got.RawEquals(want) {
+			t.Errorf("wrong greeting\ngot:  %#v\nwant: %#v", got, want)
 		}
-		for _, want := range wantWarnings {
-			if !strings.Contains(warnings, want) {
-				t.Errorf("missing warning:\ngot:  %s\nwant to contain: %q", warnings, want)
+	}
+
+	if calls != 1 {
+		t.Errorf("function was called %d times; want 1", calls)
+	}
+	if len(cache.results) != 1 {
+		t.Errorf("wrong number of cached results %d; want 1", len(cache.results))
+	}
+	if cache.saves == 0 {
+		t.Error("cache was never saved")
+	}
+}
+
+type testProviderFunctionCache struct {
+	mu      sync.Mutex
+	results map[string]cty.Value
+	saves   int
+}
+
+func (c *testProviderFunctionCache) Get(provider addrs.Provider, key string) (cty.Value, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result, ok := c.results[provider.String()+" "+key]
+	return result, ok
+}
+
+func (c *testProviderFunctionCache) Put(provider addrs.Provider, key string, result cty.Value) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results[provider.String()+" "+key] = result
+}
+
+func (c *testProviderFunctionCache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.saves++
+	return nil
+}
+
+func TestContext2Plan_providerFunctionUnavailable(t *testing.T) {
+	// A call to a function of a provider whose recorded capabilities say
+	// that it offers no functions gets an error explaining why.
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+			terraform {
+				required_providers {
+					test = {
+						source = "hashicorp/test"
+					}
+				}
 			}
-		}
-		if !p.ReadResourceCalled {
-			t.Errorf("Provider's ReadResource wasn't called; should've been")
-		}
-		if p.PlanResourceChangeCalled {
-			t.Errorf("Provider's PlanResourceChange was called; should'nt've been")
-		}
+
+			output "greeting" {
+				value = provider::test::upper("hello")
+			}
+		`,
+	})
+
+	p := simpleMockProvider()
+	addr := addrs.NewDefaultProvider("test")
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addr: testProviderFuncFixed(p),
+		},
+		ProviderCapabilities: map[addrs.Provider]providers.Capabilities{
+			addr: {PlanDestroy: true},
+		},
 	})
+
+	_, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	if !diags.HasErrors() {
+		t.Fatal("succeeded; want error")
+	}
+	desc := diags.Err().Error()
+	if !strings.Contains(desc, "Provider function not available") || !strings.Contains(desc, "doesn't offer any functions") {
+		t.Errorf("wrong error: %s", desc)
+	}
 }
 
-func TestContext2Plan_dataSourcePreconditionPostcondition(t *testing.T) {
+func TestContext2Plan_dataSourceDependsOnExpr(t *testing.T) {
+	// A data resource whose depends_on is an expression rather than a static
+	// list must still wait for all of the resources the expression could
+	// refer to.
+	p := testProvider("test")
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		ResourceTypes: map[string]*configschema.Block{
+			"test_resource": {
+				Attributes: map[string]*configschema.Attribute{
+					"value": {
+						Type:     cty.String,
+						Optional: true,
+					},
+				},
+			},
+		},
+		DataSources: map[string]*configschema.Block{
+			"test_data_source": {
+				Attributes: map[string]*configschema.Attribute{
+					"id": {
+						Type:     cty.String,
+						Required: true,
+					},
+				},
+			},
+		},
+	})
+
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+variable "wait" {
+  type = bool
+}
+
+resource "test_resource" "a" {
+  for_each = toset(["x", "y"])
+  value    = each.key
+}
+
+data "test_data_source" "b" {
+  id = "b"
+
+  depends_on = var.wait ? [for k, v in test_resource.a : v] : []
+}
+`,
+	})
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+		Mode: plans.NormalMode,
+		SetVariables: InputValues{
+			"wait": &InputValue{
+				Value:      cty.True,
+				SourceType: ValueFromCLIArg,
+			},
+		},
+	})
+	assertNoErrors(t, diags)
+
+	dataAddr := mustResourceInstanceAddr(`data.test_data_source.b`)
+	rc := plan.Changes.ResourceInstance(dataAddr)
+	if rc == nil {
+		t.Fatalf("no planned change for %s", dataAddr)
+	}
+	if got, want := rc.Action, plans.Read; got != want {
+		t.Errorf("wrong action for %s\ngot:  %s\nwant: %s", dataAddr, got, want)
+	}
+	if got, want := rc.ActionReason, plans.ResourceInstanceReadBecauseDependencyPending; got != want {
+		t.Errorf("wrong action reason for %s\ngot:  %s\nwant: %s", dataAddr, got, want)
+	}
+}
+
+func TestContext2Plan_resourcePreconditionPostcondition(t *testing.T) {
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
 variable "boop" {
   type = string
 }
 
-data "test_data_source" "a" {
-  foo = var.boop
+resource "test_resource" "a" {
+  value = var.boop
   lifecycle {
     precondition {
       condition     = var.boop == "boop"
       error_message = "Wrong boop."
     }
     postcondition {
-      condition     = length(self.results) > 0
-      error_message = "Results cannot be empty."
+      condition     = self.output != ""
+      error_message = "Output must not be blank."
     }
   }
 }
 
-resource "test_resource" "a" {
-  value    = data.test_data_source.a.results[0]
-}
 `,
 	})
 
@@ -3068,18 +3536,8 @@ resource "test_resource" "a" {
 						Type:     cty.String,
 						Required: true,
 					},
-				},
-			},
-		},
-		DataSources: map[string]*configschema.Block{
-			"test_data_source": {
-				Attributes: map[string]*configschema.Attribute{
-					"foo": {
+					"output": {
 						Type:     cty.String,
-						Required: true,
-					},
-					"results": {
-						Type:     cty.List(cty.String),
 						Computed: true,
 					},
 				},
@@ -3093,11 +3551,14 @@ resource "test_resource" "a" {
 				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 			},
 		})
-		p.ReadDataSourceResponse = &providers.ReadDataSourceResponse{
-			State: cty.ObjectVal(map[string]cty.Value{
-				"foo":     cty.StringVal("boop"),
-				"results": cty.ListVal([]cty.Value{cty.StringVal("boop")}),
-			}),
+
+		p.PlanResourceChangeFn = func(req providers.PlanResourceChangeRequest) (resp providers.PlanResourceChangeResponse) {
+			m := req.ProposedNewState.AsValueMap()
+			m["output"] = cty.StringVal("bar")
+
+			resp.PlannedState = cty.ObjectVal(m)
+			resp.LegacyTypeSystem = true
+			return resp
 		}
 		plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
 			Mode: plans.NormalMode,
@@ -3115,26 +3576,10 @@ resource "test_resource" "a" {
 				if res.Action != plans.Create {
 					t.Fatalf("unexpected %s change for %s", res.Action, res.Addr)
 				}
-			case "data.test_data_source.a":
-				if res.Action != plans.Read {
-					t.Fatalf("unexpected %s change for %s", res.Action, res.Addr)
-				}
 			default:
 				t.Fatalf("unexpected %s change for %s", res.Action, res.Addr)
 			}
 		}
-
-		addr := mustResourceInstanceAddr("data.test_data_source.a")
-		if gotResult := plan.Checks.GetObjectResult(addr); gotResult == nil {
-			t.Errorf("no check result for %s", addr)
-		} else {
-			wantResult := &states.CheckResultObject{
-				Status: checks.StatusPass,
-			}
-			if diff := cmp.Diff(wantResult, gotResult, valueComparer); diff != "" {
-				t.Errorf("wrong check result for %s\n%s", addr, diff)
-			}
-		}
 	})
 
 	t.Run("precondition fail", func(t *testing.T) {
@@ -3143,6 +3588,7 @@ resource "test_resource" "a" {
 				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 			},
 		})
+
 		_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
 			Mode: plans.NormalMode,
 			SetVariables: InputValues{
@@ -3158,8 +3604,8 @@ resource "test_resource" "a" {
 		if got, want := diags.Err().Error(), "Resource precondition failed: Wrong boop."; got != want {
 			t.Fatalf("wrong error:\ngot:  %s\nwant: %q", got, want)
 		}
-		if p.ReadDataSourceCalled {
-			t.Errorf("Provider's ReadResource was called; should'nt've been")
+		if p.PlanResourceChangeCalled {
+			t.Errorf("Provider's PlanResourceChange was called; should'nt've been")
 		}
 	})
 
@@ -3169,7 +3615,14 @@ resource "test_resource" "a" {
 				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 			},
 		})
-		plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+
+		state := states.BuildState(func(s *states.SyncState) {
+			s.SetResourceInstanceCurrent(mustResourceInstanceAddr("test_resource.a"), &states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"value":"boop","output":"blorp"}`),
+				Status:    states.ObjectReady,
+			}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
+		})
+		_, diags := ctx.Plan(m, state, &PlanOpts{
 			Mode: plans.RefreshOnlyMode,
 			SetVariables: InputValues{
 				"boop": &InputValue{
@@ -3185,19 +3638,8 @@ resource "test_resource" "a" {
 		if got, want := diags.ErrWithWarnings().Error(), "Resource precondition failed: Wrong boop."; got != want {
 			t.Fatalf("wrong warning:\ngot:  %s\nwant: %q", got, want)
 		}
-		for _, res := range plan.Changes.Resources {
-			switch res.Addr.String() {
-			case "test_resource.a":
-				if res.Action != plans.Create {
-					t.Fatalf("unexpected %s change for %s", res.Action, res.Addr)
-				}
-			case "data.test_data_source.a":
-				if res.Action != plans.Read {
-					t.Fatalf("unexpected %s change for %s", res.Action, res.Addr)
-				}
-			default:
-				t.Fatalf("unexpected %s change for %s", res.Action, res.Addr)
-			}
+		if !p.ReadResourceCalled {
+			t.Errorf("Provider's ReadResource wasn't called; should've been")
 		}
 	})
 
@@ -3207,11 +3649,14 @@ resource "test_resource" "a" {
 				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 			},
 		})
-		p.ReadDataSourceResponse = &providers.ReadDataSourceResponse{
-			State: cty.ObjectVal(map[string]cty.Value{
-				"foo":     cty.StringVal("boop"),
-				"results": cty.ListValEmpty(cty.String),
-			}),
+
+		p.PlanResourceChangeFn = func(req providers.PlanResourceChangeRequest) (resp providers.PlanResourceChangeResponse) {
+			m := req.ProposedNewState.AsValueMap()
+			m["output"] = cty.StringVal("")
+
+			resp.PlannedState = cty.ObjectVal(m)
+			resp.LegacyTypeSystem = true
+			return resp
 		}
 		_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
 			Mode: plans.NormalMode,
@@ -3225,11 +3670,11 @@ resource "test_resource" "a" {
 		if !diags.HasErrors() {
 			t.Fatal("succeeded; want errors")
 		}
-		if got, want := diags.Err().Error(), "Resource postcondition failed: Results cannot be empty."; got != want {
+		if got, want := diags.Err().Error(), "Resource postcondition failed: Output must not be blank."; got != want {
 			t.Fatalf("wrong error:\ngot:  %s\nwant: %q", got, want)
 		}
-		if !p.ReadDataSourceCalled {
-			t.Errorf("Provider's ReadDataSource wasn't called; should've been")
+		if !p.PlanResourceChangeCalled {
+			t.Errorf("Provider's PlanResourceChange wasn't called; should've been")
 		}
 	})
 
@@ -3239,13 +3684,30 @@ resource "test_resource" "a" {
 				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 			},
 		})
-		p.ReadDataSourceResponse = &providers.ReadDataSourceResponse{
-			State: cty.ObjectVal(map[string]cty.Value{
-				"foo":     cty.StringVal("boop"),
-				"results": cty.ListValEmpty(cty.String),
-			}),
+
+		state := states.BuildState(func(s *states.SyncState) {
+			s.SetResourceInstanceCurrent(mustResourceInstanceAddr("test_resource.a"), &states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"value":"boop","output":"blorp"}`),
+				Status:    states.ObjectReady,
+			}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
+		})
+		p.ReadResourceFn = func(req providers.ReadResourceRequest) (resp providers.ReadResourceResponse) {
+			newVal, err := cty.Transform(req.PriorState, func(path cty.Path, v cty.Value) (cty.Value, error) {
+				if len(path) == 1 && path[0] == (cty.GetAttrStep{Name: "output"}) {
+					return cty.StringVal(""), nil
+				}
+				return v, nil
+			})
+			if err != nil {
+				// shouldn't get here
+				t.Fatalf("ReadResourceFn transform failed")
+				return providers.ReadResourceResponse{}
+			}
+			return providers.ReadResourceResponse{
+				NewState: newVal,
+			}
 		}
-		plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+		_, diags := ctx.Plan(m, state, &PlanOpts{
 			Mode: plans.RefreshOnlyMode,
 			SetVariables: InputValues{
 				"boop": &InputValue{
@@ -3255,22 +3717,17 @@ resource "test_resource" "a" {
 			},
 		})
 		assertNoErrors(t, diags)
-		if got, want := diags.ErrWithWarnings().Error(), "Resource postcondition failed: Results cannot be empty."; got != want {
-			t.Fatalf("wrong error:\ngot:  %s\nwant: %q", got, want)
+		if len(diags) == 0 {
+			t.Fatalf("no diags, but should have warnings")
 		}
-		addr := mustResourceInstanceAddr("data.test_data_source.a")
-		if gotResult := plan.Checks.GetObjectResult(addr); gotResult == nil {
-			t.Errorf("no check result for %s", addr)
-		} else {
-			wantResult := &states.CheckResultObject{
-				Status: checks.StatusFail,
-				FailureMessages: []string{
-					"Results cannot be empty.",
-				},
-			}
-			if diff := cmp.Diff(wantResult, gotResult, valueComparer); diff != "" {
-				t.Errorf("wrong check result\n%s", diff)
-			}
+		if got, want := diags.ErrWithWarnings().Error(), "Resource postcondition failed: Output must not be blank."; got != want {
+			t.Fatalf("wrong warning:\ngot:  %s\nwant: %q", got, want)
+		}
+		if !p.ReadResourceCalled {
+			t.Errorf("Provider's ReadResource wasn't called; should've been")
+		}
+		if p.PlanResourceChangeCalled {
+			t.Errorf("Provider's PlanResourceChange was called; should'nt've been")
 		}
 	})
 
@@ -3280,13 +3737,30 @@ resource "test_resource" "a" {
 				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 			},
 		})
-		p.ReadDataSourceResponse = &providers.ReadDataSourceResponse{
-			State: cty.ObjectVal(map[string]cty.Value{
-				"foo":     cty.StringVal("nope"),
-				"results": cty.ListValEmpty(cty.String),
-			}),
+
+		state := states.BuildState(func(s *states.SyncState) {
+			s.SetResourceInstanceCurrent(mustResourceInstanceAddr("test_resource.a"), &states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"value":"boop","output":"blorp"}`),
+				Status:    states.ObjectReady,
+			}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
+		})
+		p.ReadResourceFn = func(req providers.ReadResourceRequest) (resp providers.ReadResourceResponse) {
+			newVal, err := cty.Transform(req.PriorState, func(path cty.Path, v cty.Value) (cty.Value, error) {
+				if len(path) == 1 && path[0] == (cty.GetAttrStep{Name: "output"}) {
+					return cty.StringVal(""), nil
+				}
+				return v, nil
+			})
+			if err != nil {
+				// shouldn't get here
+				t.Fatalf("ReadResourceFn transform failed")
+				return providers.ReadResourceResponse{}
+			}
+			return providers.ReadResourceResponse{
+				NewState: newVal,
+			}
 		}
-		_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+		_, diags := ctx.Plan(m, state, &PlanOpts{
 			Mode: plans.RefreshOnlyMode,
 			SetVariables: InputValues{
 				"boop": &InputValue{
@@ -3302,42 +3776,196 @@ resource "test_resource" "a" {
 		warnings := diags.ErrWithWarnings().Error()
 		wantWarnings := []string{
 			"Resource precondition failed: Wrong boop.",
-			"Resource postcondition failed: Results cannot be empty.",
+			"Resource postcondition failed: Output must not be blank.",
 		}
 		for _, want := range wantWarnings {
 			if !strings.Contains(warnings, want) {
 				t.Errorf("missing warning:\ngot:  %s\nwant to contain: %q", warnings, want)
 			}
 		}
+		if !p.ReadResourceCalled {
+			t.Errorf("Provider's ReadResource wasn't called; should've been")
+		}
+		if p.PlanResourceChangeCalled {
+			t.Errorf("Provider's PlanResourceChange was called; should'nt've been")
+		}
 	})
 }
 
-func TestContext2Plan_outputPrecondition(t *testing.T) {
+func TestContext2Plan_resourceDynamicPreconditionPostcondition(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+variable "boops" {
+  type = map(string)
+}
+
+resource "test_resource" "a" {
+  value = join(",", values(var.boops))
+  lifecycle {
+    dynamic "precondition" {
+      for_each = var.boops
+      content {
+        condition     = precondition.value == "boop"
+        error_message = "Wrong boop for ${precondition.key}."
+      }
+    }
+    dynamic "postcondition" {
+      for_each = toset(keys(var.boops))
+      iterator = key
+      content {
+        condition     = strcontains(self.output, key.value)
+        error_message = "Output must mention ${key.value}."
+      }
+    }
+  }
+}
+`,
+	})
+
+	p := testProvider("test")
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		ResourceTypes: map[string]*configschema.Block{
+			"test_resource": {
+				Attributes: map[string]*configschema.Attribute{
+					"value": {
+						Type:     cty.String,
+						Required: true,
+					},
+					"output": {
+						Type:     cty.String,
+						Computed: true,
+					},
+				},
+			},
+		},
+	})
+	p.PlanResourceChangeFn = func(req providers.PlanResourceChangeRequest) (resp providers.PlanResourceChangeResponse) {
+		m := req.ProposedNewState.AsValueMap()
+		m["output"] = cty.StringVal("a")
+
+		resp.PlannedState = cty.ObjectVal(m)
+		resp.LegacyTypeSystem = true
+		return resp
+	}
+
+	plan := func(boops map[string]cty.Value) tfdiags.Diagnostics {
+		ctx := testContext2(t, &ContextOpts{
+			Providers: map[addrs.Provider]providers.Factory{
+				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+			},
+		})
+		_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+			Mode: plans.NormalMode,
+			SetVariables: InputValues{
+				"boops": &InputValue{
+					Value:      cty.MapVal(boops),
+					SourceType: ValueFromCLIArg,
+				},
+			},
+		})
+		return diags
+	}
+
+	t.Run("conditions pass", func(t *testing.T) {
+		diags := plan(map[string]cty.Value{
+			"a": cty.StringVal("boop"),
+		})
+		assertNoErrors(t, diags)
+	})
+
+	t.Run("precondition fail", func(t *testing.T) {
+		diags := plan(map[string]cty.Value{
+			"a": cty.StringVal("boop"),
+			"b": cty.StringVal("nope"),
+		})
+		if !diags.HasErrors() {
+			t.Fatal("succeeded; want errors")
+		}
+		if got, want := diags.Err().Error(), "Resource precondition failed: Wrong boop for b."; got != want {
+			t.Fatalf("wrong error:\ngot:  %s\nwant: %q", got, want)
+		}
+	})
+
+	t.Run("postcondition fail", func(t *testing.T) {
+		diags := plan(map[string]cty.Value{
+			"b": cty.StringVal("boop"),
+		})
+		if !diags.HasErrors() {
+			t.Fatal("succeeded; want errors")
+		}
+		if got, want := diags.Err().Error(), "Resource postcondition failed: Output must mention b."; got != want {
+			t.Fatalf("wrong error:\ngot:  %s\nwant: %q", got, want)
+		}
+	})
+}
+
+func TestContext2Plan_dataSourcePreconditionPostcondition(t *testing.T) {
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
 variable "boop" {
   type = string
 }
 
-output "a" {
-  value = var.boop
-  precondition {
-    condition     = var.boop == "boop"
-    error_message = "Wrong boop."
+data "test_data_source" "a" {
+  foo = var.boop
+  lifecycle {
+    precondition {
+      condition     = var.boop == "boop"
+      error_message = "Wrong boop."
+    }
+    postcondition {
+      condition     = length(self.results) > 0
+      error_message = "Results cannot be empty."
+    }
   }
 }
+
+resource "test_resource" "a" {
+  value    = data.test_data_source.a.results[0]
+}
 `,
 	})
 
 	p := testProvider("test")
-
-	ctx := testContext2(t, &ContextOpts{
-		Providers: map[addrs.Provider]providers.Factory{
-			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		ResourceTypes: map[string]*configschema.Block{
+			"test_resource": {
+				Attributes: map[string]*configschema.Attribute{
+					"value": {
+						Type:     cty.String,
+						Required: true,
+					},
+				},
+			},
+		},
+		DataSources: map[string]*configschema.Block{
+			"test_data_source": {
+				Attributes: map[string]*configschema.Attribute{
+					"foo": {
+						Type:     cty.String,
+						Required: true,
+					},
+					"results": {
+						Type:     cty.List(cty.String),
+						Computed: true,
+					},
+				},
+			},
 		},
 	})
 
-	t.Run("condition pass", func(t *testing.T) {
+	t.Run("conditions pass", func(t *testing.T) {
+		ctx := testContext2(t, &ContextOpts{
+			Providers: map[addrs.Provider]providers.Factory{
+				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+			},
+		})
+		p.ReadDataSourceResponse = &providers.ReadDataSourceResponse{
+			State: cty.ObjectVal(map[string]cty.Value{
+				"foo":     cty.StringVal("boop"),
+				"results": cty.ListVal([]cty.Value{cty.StringVal("boop")}),
+			}),
+		}
 		plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
 			Mode: plans.NormalMode,
 			SetVariables: InputValues{
@@ -3348,17 +3976,22 @@ output "a" {
 			},
 		})
 		assertNoErrors(t, diags)
-		addr := addrs.RootModuleInstance.OutputValue("a")
-		outputPlan := plan.Changes.OutputValue(addr)
-		if outputPlan == nil {
-			t.Fatalf("no plan for %s at all", addr)
-		}
-		if got, want := outputPlan.Addr, addr; !got.Equal(want) {
-			t.Errorf("wrong current address\ngot:  %s\nwant: %s", got, want)
-		}
-		if got, want := outputPlan.Action, plans.Create; got != want {
-			t.Errorf("wrong planned action\ngot:  %s\nwant: %s", got, want)
+		for _, res := range plan.Changes.Resources {
+			switch res.Addr.String() {
+			case "test_resource.a":
+				if res.Action != plans.Create {
+					t.Fatalf("unexpected %s change for %s", res.Action, res.Addr)
+				}
+			case "data.test_data_source.a":
+				if res.Action != plans.Read {
+					t.Fatalf("unexpected %s change for %s", res.Action, res.Addr)
+				}
+			default:
+				t.Fatalf("unexpected %s change for %s", res.Action, res.Addr)
+			}
 		}
+
+		addr := mustResourceInstanceAddr("data.test_data_source.a")
 		if gotResult := plan.Checks.GetObjectResult(addr); gotResult == nil {
 			t.Errorf("no check result for %s", addr)
 		} else {
@@ -3366,12 +3999,17 @@ output "a" {
 				Status: checks.StatusPass,
 			}
 			if diff := cmp.Diff(wantResult, gotResult, valueComparer); diff != "" {
-				t.Errorf("wrong check result\n%s", diff)
+				t.Errorf("wrong check result for %s\n%s", addr, diff)
 			}
 		}
 	})
 
-	t.Run("condition fail", func(t *testing.T) {
+	t.Run("precondition fail", func(t *testing.T) {
+		ctx := testContext2(t, &ContextOpts{
+			Providers: map[addrs.Provider]providers.Factory{
+				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+			},
+		})
 		_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
 			Mode: plans.NormalMode,
 			SetVariables: InputValues{
@@ -3384,12 +4022,20 @@ output "a" {
 		if !diags.HasErrors() {
 			t.Fatal("succeeded; want errors")
 		}
-		if got, want := diags.Err().Error(), "Module output value precondition failed: Wrong boop."; got != want {
+		if got, want := diags.Err().Error(), "Resource precondition failed: Wrong boop."; got != want {
 			t.Fatalf("wrong error:\ngot:  %s\nwant: %q", got, want)
 		}
+		if p.ReadDataSourceCalled {
+			t.Errorf("Provider's ReadResource was called; should'nt've been")
+		}
 	})
 
-	t.Run("condition fail refresh-only", func(t *testing.T) {
+	t.Run("precondition fail refresh-only", func(t *testing.T) {
+		ctx := testContext2(t, &ContextOpts{
+			Providers: map[addrs.Provider]providers.Factory{
+				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+			},
+		})
 		plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
 			Mode: plans.RefreshOnlyMode,
 			SetVariables: InputValues{
@@ -3403,23 +4049,244 @@ output "a" {
 		if len(diags) == 0 {
 			t.Fatalf("no diags, but should have warnings")
 		}
-		if got, want := diags.ErrWithWarnings().Error(), "Module output value precondition failed: Wrong boop."; got != want {
-			t.Errorf("wrong warning:\ngot:  %s\nwant: %q", got, want)
-		}
-		addr := addrs.RootModuleInstance.OutputValue("a")
-		outputPlan := plan.Changes.OutputValue(addr)
-		if outputPlan == nil {
-			t.Fatalf("no plan for %s at all", addr)
-		}
-		if got, want := outputPlan.Addr, addr; !got.Equal(want) {
-			t.Errorf("wrong current address\ngot:  %s\nwant: %s", got, want)
-		}
-		if got, want := outputPlan.Action, plans.Create; got != want {
-			t.Errorf("wrong planned action\ngot:  %s\nwant: %s", got, want)
+		if got, want := diags.ErrWithWarnings().Error(), "Resource precondition failed: Wrong boop."; got != want {
+			t.Fatalf("wrong warning:\ngot:  %s\nwant: %q", got, want)
 		}
-		if gotResult := plan.Checks.GetObjectResult(addr); gotResult == nil {
-			t.Errorf("no condition result for %s", addr)
-		} else {
+		for _, res := range plan.Changes.Resources {
+			switch res.Addr.String() {
+			case "test_resource.a":
+				if res.Action != plans.Create {
+					t.Fatalf("unexpected %s change for %s", res.Action, res.Addr)
+				}
+			case "data.test_data_source.a":
+				if res.Action != plans.Read {
+					t.Fatalf("unexpected %s change for %s", res.Action, res.Addr)
+				}
+			default:
+				t.Fatalf("unexpected %s change for %s", res.Action, res.Addr)
+			}
+		}
+	})
+
+	t.Run("postcondition fail", func(t *testing.T) {
+		ctx := testContext2(t, &ContextOpts{
+			Providers: map[addrs.Provider]providers.Factory{
+				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+			},
+		})
+		p.ReadDataSourceResponse = &providers.ReadDataSourceResponse{
+			State: cty.ObjectVal(map[string]cty.Value{
+				"foo":     cty.StringVal("boop"),
+				"results": cty.ListValEmpty(cty.String),
+			}),
+		}
+		_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+			Mode: plans.NormalMode,
+			SetVariables: InputValues{
+				"boop": &InputValue{
+					Value:      cty.StringVal("boop"),
+					SourceType: ValueFromCLIArg,
+				},
+			},
+		})
+		if !diags.HasErrors() {
+			t.Fatal("succeeded; want errors")
+		}
+		if got, want := diags.Err().Error(), "Resource postcondition failed: Results cannot be empty."; got != want {
+			t.Fatalf("wrong error:\ngot:  %s\nwant: %q", got, want)
+		}
+		if !p.ReadDataSourceCalled {
+			t.Errorf("Provider's ReadDataSource wasn't called; should've been")
+		}
+	})
+
+	t.Run("postcondition fail refresh-only", func(t *testing.T) {
+		ctx := testContext2(t, &ContextOpts{
+			Providers: map[addrs.Provider]providers.Factory{
+				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+			},
+		})
+		p.ReadDataSourceResponse = &providers.ReadDataSourceResponse{
+			State: cty.ObjectVal(map[string]cty.Value{
+				"foo":     cty.StringVal("boop"),
+				"results": cty.ListValEmpty(cty.String),
+			}),
+		}
+		plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+			Mode: plans.RefreshOnlyMode,
+			SetVariables: InputValues{
+				"boop": &InputValue{
+					Value:      cty.StringVal("boop"),
+					SourceType: ValueFromCLIArg,
+				},
+			},
+		})
+		assertNoErrors(t, diags)
+		if got, want := diags.ErrWithWarnings().Error(), "Resource postcondition failed: Results cannot be empty."; got != want {
+			t.Fatalf("wrong error:\ngot:  %s\nwant: %q", got, want)
+		}
+		addr := mustResourceInstanceAddr("data.test_data_source.a")
+		if gotResult := plan.Checks.GetObjectResult(addr); gotResult == nil {
+			t.Errorf("no check result for %s", addr)
+		} else {
+			wantResult := &states.CheckResultObject{
+				Status: checks.StatusFail,
+				FailureMessages: []string{
+					"Results cannot be empty.",
+				},
+			}
+			if diff := cmp.Diff(wantResult, gotResult, valueComparer); diff != "" {
+				t.Errorf("wrong check result\n%s", diff)
+			}
+		}
+	})
+
+	t.Run("precondition and postcondition fail refresh-only", func(t *testing.T) {
+		ctx := testContext2(t, &ContextOpts{
+			Providers: map[addrs.Provider]providers.Factory{
+				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+			},
+		})
+		p.ReadDataSourceResponse = &providers.ReadDataSourceResponse{
+			State: cty.ObjectVal(map[string]cty.Value{
+				"foo":     cty.StringVal("nope"),
+				"results": cty.ListValEmpty(cty.String),
+			}),
+		}
+		_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+			Mode: plans.RefreshOnlyMode,
+			SetVariables: InputValues{
+				"boop": &InputValue{
+					Value:      cty.StringVal("nope"),
+					SourceType: ValueFromCLIArg,
+				},
+			},
+		})
+		assertNoErrors(t, diags)
+		if got, want := len(diags), 2; got != want {
+			t.Errorf("wrong number of warnings, got %d, want %d", got, want)
+		}
+		warnings := diags.ErrWithWarnings().Error()
+		wantWarnings := []string{
+			"Resource precondition failed: Wrong boop.",
+			"Resource postcondition failed: Results cannot be empty.",
+		}
+		for _, want := range wantWarnings {
+			if !strings.Contains(warnings, want) {
+				t.Errorf("missing warning:\ngot:  %s\nwant to contain: %q", warnings, want)
+			}
+		}
+	})
+}
+
+func TestContext2Plan_outputPrecondition(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+variable "boop" {
+  type = string
+}
+
+output "a" {
+  value = var.boop
+  precondition {
+    condition     = var.boop == "boop"
+    error_message = "Wrong boop."
+  }
+}
+`,
+	})
+
+	p := testProvider("test")
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	t.Run("condition pass", func(t *testing.T) {
+		plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+			Mode: plans.NormalMode,
+			SetVariables: InputValues{
+				"boop": &InputValue{
+					Value:      cty.StringVal("boop"),
+					SourceType: ValueFromCLIArg,
+				},
+			},
+		})
+		assertNoErrors(t, diags)
+		addr := addrs.RootModuleInstance.OutputValue("a")
+		outputPlan := plan.Changes.OutputValue(addr)
+		if outputPlan == nil {
+			t.Fatalf("no plan for %s at all", addr)
+		}
+		if got, want := outputPlan.Addr, addr; !got.Equal(want) {
+			t.Errorf("wrong current address\ngot:  %s\nwant: %s", got, want)
+		}
+		if got, want := outputPlan.Action, plans.Create; got != want {
+			t.Errorf("wrong planned action\ngot:  %s\nwant: %s", got, want)
+		}
+		if gotResult := plan.Checks.GetObjectResult(addr); gotResult == nil {
+			t.Errorf("no check result for %s", addr)
+		} else {
+			wantResult := &states.CheckResultObject{
+				Status: checks.StatusPass,
+			}
+			if diff := cmp.Diff(wantResult, gotResult, valueComparer); diff != "" {
+				t.Errorf("wrong check result\n%s", diff)
+			}
+		}
+	})
+
+	t.Run("condition fail", func(t *testing.T) {
+		_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+			Mode: plans.NormalMode,
+			SetVariables: InputValues{
+				"boop": &InputValue{
+					Value:      cty.StringVal("nope"),
+					SourceType: ValueFromCLIArg,
+				},
+			},
+		})
+		if !diags.HasErrors() {
+			t.Fatal("succeeded; want errors")
+		}
+		if got, want := diags.Err().Error(), "Module output value precondition failed: Wrong boop."; got != want {
+			t.Fatalf("wrong error:\ngot:  %s\nwant: %q", got, want)
+		}
+	})
+
+	t.Run("condition fail refresh-only", func(t *testing.T) {
+		plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+			Mode: plans.RefreshOnlyMode,
+			SetVariables: InputValues{
+				"boop": &InputValue{
+					Value:      cty.StringVal("nope"),
+					SourceType: ValueFromCLIArg,
+				},
+			},
+		})
+		assertNoErrors(t, diags)
+		if len(diags) == 0 {
+			t.Fatalf("no diags, but should have warnings")
+		}
+		if got, want := diags.ErrWithWarnings().Error(), "Module output value precondition failed: Wrong boop."; got != want {
+			t.Errorf("wrong warning:\ngot:  %s\nwant: %q", got, want)
+		}
+		addr := addrs.RootModuleInstance.OutputValue("a")
+		outputPlan := plan.Changes.OutputValue(addr)
+		if outputPlan == nil {
+			t.Fatalf("no plan for %s at all", addr)
+		}
+		if got, want := outputPlan.Addr, addr; !got.Equal(want) {
+			t.Errorf("wrong current address\ngot:  %s\nwant: %s", got, want)
+		}
+		if got, want := outputPlan.Action, plans.Create; got != want {
+			t.Errorf("wrong planned action\ngot:  %s\nwant: %s", got, want)
+		}
+		if gotResult := plan.Checks.GetObjectResult(addr); gotResult == nil {
+			t.Errorf("no condition result for %s", addr)
+		} else {
 			wantResult := &states.CheckResultObject{
 				Status:          checks.StatusFail,
 				FailureMessages: []string{"Wrong boop."},
@@ -3442,614 +4309,2244 @@ func TestContext2Plan_preconditionErrors(t *testing.T) {
 			"Invalid reference",
 			`The "data" object must be followed by two attribute names`,
 		},
-		{
-			"self.value",
-			`Invalid "self" reference`,
-			"only in resource provisioner, connection, and postcondition blocks",
+		{
+			"self.value",
+			`Invalid "self" reference`,
+			"only in resource provisioner, connection, and postcondition blocks",
+		},
+		{
+			"data.foo.bar",
+			"Reference to undeclared resource",
+			`A data resource "foo" "bar" has not been declared in the root module`,
+		},
+		{
+			"test_resource.b.value",
+			"Invalid condition result",
+			"Condition expression must return either true or false",
+		},
+		{
+			"test_resource.c.value",
+			"Invalid condition result",
+			"Invalid condition result value: a bool is required",
+		},
+	}
+
+	p := testProvider("test")
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	for _, tc := range testCases {
+		t.Run(tc.condition, func(t *testing.T) {
+			main := fmt.Sprintf(`
+			resource "test_resource" "a" {
+				value = var.boop
+				lifecycle {
+					precondition {
+						condition     = %s
+						error_message = "Not relevant."
+					}
+				}
+			}
+
+			resource "test_resource" "b" {
+				value = null
+			}
+
+			resource "test_resource" "c" {
+				value = "bar"
+			}
+			`, tc.condition)
+			m := testModuleInline(t, map[string]string{"main.tf": main})
+
+			plan, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+			if !diags.HasErrors() {
+				t.Fatal("succeeded; want errors")
+			}
+
+			if !plan.Errored {
+				t.Fatal("plan failed to record error")
+			}
+
+			diag := diags[0]
+			if got, want := diag.Description().Summary, tc.wantSummary; got != want {
+				t.Errorf("unexpected summary\n got: %s\nwant: %s", got, want)
+			}
+			if got, want := diag.Description().Detail, tc.wantDetail; !strings.Contains(got, want) {
+				t.Errorf("unexpected summary\ngot: %s\nwant to contain %q", got, want)
+			}
+
+			for _, kv := range plan.Checks.ConfigResults.Elements() {
+				// All these are configuration or evaluation errors
+				if kv.Value.Status != checks.StatusError {
+					t.Errorf("incorrect status, got %s", kv.Value.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestContext2Plan_preconditionPlannedValues(t *testing.T) {
+	testCases := map[string]struct {
+		condition  string
+		wantErr    bool
+		wantStatus checks.Status
+	}{
+		"known false operand of AND": {
+			condition:  `test_resource.lb.value == "vpc-2" && test_resource.lb.output != ""`,
+			wantErr:    true,
+			wantStatus: checks.StatusFail,
+		},
+		"known true operand of AND": {
+			condition:  `test_resource.lb.value == "vpc-1" && test_resource.lb.output != ""`,
+			wantStatus: checks.StatusUnknown,
+		},
+		"known true operand of OR": {
+			condition:  `test_resource.lb.output == "" || test_resource.lb.value == "vpc-1"`,
+			wantStatus: checks.StatusPass,
+		},
+		"negated": {
+			condition:  `!(test_resource.lb.value == "vpc-1" || test_resource.lb.output == "")`,
+			wantErr:    true,
+			wantStatus: checks.StatusFail,
+		},
+		"nested": {
+			condition:  `(test_resource.lb.output != "" && test_resource.lb.value == "vpc-2") || test_resource.lb.output == ""`,
+			wantStatus: checks.StatusUnknown,
+		},
+		"alltrue": {
+			condition:  `alltrue([test_resource.lb.output != "", test_resource.lb.value == "vpc-2"])`,
+			wantErr:    true,
+			wantStatus: checks.StatusFail,
+		},
+	}
+
+	p := testProvider("test")
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		ResourceTypes: map[string]*configschema.Block{
+			"test_resource": {
+				Attributes: map[string]*configschema.Attribute{
+					"value": {
+						Type:     cty.String,
+						Required: true,
+					},
+					"output": {
+						Type:     cty.String,
+						Computed: true,
+					},
+				},
+			},
+		},
+	})
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			m := testModuleInline(t, map[string]string{
+				"main.tf": fmt.Sprintf(`
+resource "test_resource" "lb" {
+  value = "vpc-1"
+}
+
+resource "test_resource" "subnet" {
+  value = "vpc-1"
+
+  lifecycle {
+    precondition {
+      condition     = %s
+      error_message = "The subnet must be in the same VPC as the load balancer."
+    }
+  }
+}
+`, tc.condition),
+			})
+
+			ctx := testContext2(t, &ContextOpts{
+				Providers: map[addrs.Provider]providers.Factory{
+					addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+				},
+			})
+
+			plan, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+			if tc.wantErr {
+				if !diags.HasErrors() {
+					t.Fatal("succeeded; want errors")
+				}
+				if got, want := diags.Err().Error(), "Resource precondition failed: The subnet must be in the same VPC as the load balancer."; got != want {
+					t.Fatalf("wrong error:\ngot:  %s\nwant: %s", got, want)
+				}
+			} else {
+				assertNoErrors(t, diags)
+			}
+
+			addr := mustResourceInstanceAddr("test_resource.subnet")
+			result := plan.Checks.GetObjectResult(addr)
+			if result == nil {
+				t.Fatalf("no check result for %s", addr)
+			}
+			if result.Status != tc.wantStatus {
+				t.Errorf("wrong check status %s; want %s", result.Status, tc.wantStatus)
+			}
+		})
+	}
+}
+
+func TestContext2Plan_preconditionSensitiveValues(t *testing.T) {
+	p := testProvider("test")
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+variable "boop" {
+  sensitive = true
+  type      = string
+}
+
+output "a" {
+  sensitive = true
+  value     = var.boop
+
+  precondition {
+    condition     = length(var.boop) <= 4
+    error_message = "Boop is too long, ${length(var.boop)} > 4"
+  }
+}
+`,
+	})
+
+	_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+		Mode: plans.NormalMode,
+		SetVariables: InputValues{
+			"boop": &InputValue{
+				Value:      cty.StringVal("bleep"),
+				SourceType: ValueFromCLIArg,
+			},
+		},
+	})
+	if !diags.HasErrors() {
+		t.Fatal("succeeded; want errors")
+	}
+	if got, want := len(diags), 2; got != want {
+		t.Errorf("wrong number of diags, got %d, want %d", got, want)
+	}
+	for _, diag := range diags {
+		desc := diag.Description()
+		if desc.Summary == "Module output value precondition failed" {
+			if got, want := desc.Detail, "This check failed, but has an invalid error message as described in the other accompanying messages."; !strings.Contains(got, want) {
+				t.Errorf("unexpected detail\ngot: %s\nwant to contain %q", got, want)
+			}
+		} else if desc.Summary == "Error message refers to sensitive values" {
+			if got, want := desc.Detail, "The error expression used to explain this condition refers to sensitive values, so OpenTofu will not display the resulting message."; !strings.Contains(got, want) {
+				t.Errorf("unexpected detail\ngot: %s\nwant to contain %q", got, want)
+			}
+		} else {
+			t.Errorf("unexpected summary\ngot: %s", desc.Summary)
+		}
+	}
+}
+
+func TestContext2Plan_preconditionEphemeralValues(t *testing.T) {
+	p := testProvider("test")
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+variable "token" {
+  ephemeral = true
+  type      = string
+}
+
+resource "test_resource" "a" {
+  lifecycle {
+    precondition {
+      condition     = length(var.token) <= 4
+      error_message = "Token ${var.token} is too long"
+    }
+  }
+}
+`,
+	})
+
+	_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+		Mode: plans.NormalMode,
+		SetVariables: InputValues{
+			"token": &InputValue{
+				Value:      cty.StringVal("secret"),
+				SourceType: ValueFromCLIArg,
+			},
+		},
+	})
+	if !diags.HasErrors() {
+		t.Fatal("succeeded; want errors")
+	}
+	if got, want := len(diags), 2; got != want {
+		t.Errorf("wrong number of diags, got %d, want %d", got, want)
+	}
+	for _, diag := range diags {
+		desc := diag.Description()
+		if strings.Contains(desc.Detail, "secret") {
+			t.Errorf("diagnostic reveals the ephemeral value\n%s: %s", desc.Summary, desc.Detail)
+		}
+		if desc.Summary == "Resource precondition failed" {
+			if got, want := desc.Detail, "This check failed, but has an invalid error message as described in the other accompanying messages."; !strings.Contains(got, want) {
+				t.Errorf("unexpected detail\ngot: %s\nwant to contain %q", got, want)
+			}
+		} else if desc.Summary == "Error message refers to ephemeral values" {
+			if got, want := desc.Detail, "The error expression used to explain this condition refers to ephemeral values, so OpenTofu will not display the resulting message."; !strings.Contains(got, want) {
+				t.Errorf("unexpected detail\ngot: %s\nwant to contain %q", got, want)
+			}
+		} else {
+			t.Errorf("unexpected summary\ngot: %s", desc.Summary)
+		}
+	}
+}
+
+func TestContext2Plan_triggeredBy(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  count = 1
+  test_string = "new"
+}
+resource "test_object" "b" {
+  count = 1
+  test_string = test_object.a[count.index].test_string
+  lifecycle {
+    # the change to test_string in the other resource should trigger replacement
+    replace_triggered_by = [ test_object.a[count.index].test_string ]
+  }
+}
+`,
+	})
+
+	p := simpleMockProvider()
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(
+			mustResourceInstanceAddr("test_object.a[0]"),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"test_string":"old"}`),
+				Status:    states.ObjectReady,
+			},
+			mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
+		)
+		s.SetResourceInstanceCurrent(
+			mustResourceInstanceAddr("test_object.b[0]"),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{}`),
+				Status:    states.ObjectReady,
+			},
+			mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
+		)
+	})
+
+	plan, diags := ctx.Plan(m, state, &PlanOpts{
+		Mode: plans.NormalMode,
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
+	}
+	for _, c := range plan.Changes.Resources {
+		switch c.Addr.String() {
+		case "test_object.a[0]":
+			if c.Action != plans.Update {
+				t.Fatalf("unexpected %s change for %s\n", c.Action, c.Addr)
+			}
+		case "test_object.b[0]":
+			if c.Action != plans.DeleteThenCreate {
+				t.Fatalf("unexpected %s change for %s\n", c.Action, c.Addr)
+			}
+			if c.ActionReason != plans.ResourceInstanceReplaceByTriggers {
+				t.Fatalf("incorrect reason for change: %s\n", c.ActionReason)
+			}
+		default:
+			t.Fatal("unexpected change", c.Addr, c.Action)
+		}
+	}
+}
+
+func TestContext2Plan_triggeredByValues(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+variable "release" {
+  type = string
+}
+resource "test_object" "a" {
+  lifecycle {
+    # the recorded value differs, so this should be replaced
+    replace_triggered_by = [ var.release ]
+  }
+}
+resource "test_object" "b" {
+  lifecycle {
+    replace_triggered_by = [ var.release ]
+  }
+}
+resource "test_object" "c" {
+  lifecycle {
+    # there's no recorded value yet, so it is only recorded
+    replace_triggered_by = [ var.release ]
+  }
+}
+resource "test_object" "d" {
+  for_each = { x = "one" }
+  lifecycle {
+    replace_triggered_by = [ upper(each.value) ]
+  }
+}
+`,
+	})
+
+	p := simpleMockProvider()
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	state := states.BuildState(func(s *states.SyncState) {
+		for addr, triggers := range map[string]string{
+			"test_object.a":      `{"value":["v1"],"type":["tuple",["string"]]}`,
+			"test_object.b":      `{"value":["v2"],"type":["tuple",["string"]]}`,
+			"test_object.c":      ``,
+			`test_object.d["x"]`: `{"value":["ONE"],"type":["tuple",["string"]]}`,
+		} {
+			obj := &states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{}`),
+				Status:    states.ObjectReady,
+			}
+			if triggers != "" {
+				obj.ReplaceTriggersJSON = []byte(triggers)
+			}
+			s.SetResourceInstanceCurrent(
+				mustResourceInstanceAddr(addr),
+				obj,
+				mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
+			)
+		}
+	})
+
+	plan, diags := ctx.Plan(m, state, &PlanOpts{
+		Mode: plans.NormalMode,
+		SetVariables: InputValues{
+			"release": &InputValue{
+				Value:      cty.StringVal("v2"),
+				SourceType: ValueFromCaller,
+			},
+		},
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
+	}
+	for _, c := range plan.Changes.Resources {
+		switch c.Addr.String() {
+		case "test_object.a":
+			if c.Action != plans.DeleteThenCreate {
+				t.Fatalf("unexpected %s change for %s\n", c.Action, c.Addr)
+			}
+			if c.ActionReason != plans.ResourceInstanceReplaceByTriggers {
+				t.Fatalf("incorrect reason for change: %s\n", c.ActionReason)
+			}
+		case "test_object.b", "test_object.c", `test_object.d["x"]`:
+			if c.Action != plans.NoOp {
+				t.Fatalf("unexpected %s change for %s\n", c.Action, c.Addr)
+			}
+		default:
+			t.Fatal("unexpected change", c.Addr, c.Action)
+		}
+	}
+
+	obj := plan.PriorState.ResourceInstance(mustResourceInstanceAddr("test_object.c")).Current
+	if got, want := string(obj.ReplaceTriggersJSON), `{"value":["v2"],"type":["tuple",["string"]]}`; got != want {
+		t.Errorf("wrong recorded triggers for test_object.c\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestContext2Plan_dataSchemaChange(t *testing.T) {
+	// We can't decode the prior state when a data source upgrades the schema
+	// in an incompatible way. Since prior state for data sources is purely
+	// informational, decoding should be skipped altogether.
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+data "test_object" "a" {
+  obj {
+    # args changes from a list to a map
+    args = {
+      val = "string"
+	}
+  }
+}
+`,
+	})
+
+	p := new(MockProvider)
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		DataSources: map[string]*configschema.Block{
+			"test_object": {
+				Attributes: map[string]*configschema.Attribute{
+					"id": {
+						Type:     cty.String,
+						Computed: true,
+					},
+				},
+				BlockTypes: map[string]*configschema.NestedBlock{
+					"obj": {
+						Block: configschema.Block{
+							Attributes: map[string]*configschema.Attribute{
+								"args": {Type: cty.Map(cty.String), Optional: true},
+							},
+						},
+						Nesting: configschema.NestingSet,
+					},
+				},
+			},
+		},
+	})
+
+	p.ReadDataSourceFn = func(req providers.ReadDataSourceRequest) (resp providers.ReadDataSourceResponse) {
+		resp.State = req.Config
+		return resp
+	}
+
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(mustResourceInstanceAddr(`data.test_object.a`), &states.ResourceInstanceObjectSrc{
+			AttrsJSON: []byte(`{"id":"old","obj":[{"args":["string"]}]}`),
+			Status:    states.ObjectReady,
+		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
+	})
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	_, diags := ctx.Plan(m, state, DefaultPlanOpts)
+	assertNoErrors(t, diags)
+}
+
+func TestContext2Plan_applyGraphError(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+}
+resource "test_object" "b" {
+	depends_on = [test_object.a]
+}
+`,
+	})
+
+	p := simpleMockProvider()
+
+	// Here we introduce a cycle via state which only shows up in the apply
+	// graph where the actual destroy instances are connected in the graph.
+	// This could happen for example when a user has an existing state with
+	// stored dependencies, and changes the config in such a way that
+	// contradicts the stored dependencies.
+	state := states.NewState()
+	root := state.EnsureModule(addrs.RootModuleInstance)
+	root.SetResourceInstanceCurrent(
+		mustResourceInstanceAddr("test_object.a").Resource,
+		&states.ResourceInstanceObjectSrc{
+			Status:       states.ObjectTainted,
+			AttrsJSON:    []byte(`{"test_string":"a"}`),
+			Dependencies: []addrs.ConfigResource{mustResourceInstanceAddr("test_object.b").ContainingResource().Config()},
+		},
+		mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
+	)
+	root.SetResourceInstanceCurrent(
+		mustResourceInstanceAddr("test_object.b").Resource,
+		&states.ResourceInstanceObjectSrc{
+			Status:    states.ObjectTainted,
+			AttrsJSON: []byte(`{"test_string":"b"}`),
+		},
+		mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
+	)
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	_, diags := ctx.Plan(m, state, &PlanOpts{
+		Mode: plans.NormalMode,
+	})
+	if !diags.HasErrors() {
+		t.Fatal("cycle error not detected")
+	}
+
+	msg := diags.ErrWithWarnings().Error()
+	if !strings.Contains(msg, "Cycle") {
+		t.Fatalf("no cycle error found:\n got: %s\n", msg)
+	}
+}
+
+// plan a destroy with no state where configuration could fail to evaluate
+// expansion indexes.
+func TestContext2Plan_emptyDestroy(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+locals {
+  enable = true
+  value  = local.enable ? module.example[0].out : null
+}
+
+module "example" {
+  count  = local.enable ? 1 : 0
+  source = "./example"
+}
+`,
+		"example/main.tf": `
+resource "test_resource" "x" {
+}
+
+output "out" {
+  value = test_resource.x
+}
+`,
+	})
+
+	p := testProvider("test")
+	state := states.NewState()
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(m, state, &PlanOpts{
+		Mode: plans.DestroyMode,
+	})
+
+	assertNoErrors(t, diags)
+
+	// ensure that the given states are valid and can be serialized
+	if plan.PrevRunState == nil {
+		t.Fatal("nil plan.PrevRunState")
+	}
+	if plan.PriorState == nil {
+		t.Fatal("nil plan.PriorState")
+	}
+}
+
+// A deposed instances which no longer exists during ReadResource creates NoOp
+// change, which should not effect the plan.
+func TestContext2Plan_deposedNoLongerExists(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "b" {
+  count = 1
+  test_string = "updated"
+  lifecycle {
+    create_before_destroy = true
+  }
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	p.ReadResourceFn = func(req providers.ReadResourceRequest) (resp providers.ReadResourceResponse) {
+		s := req.PriorState.GetAttr("test_string").AsString()
+		if s == "current" {
+			resp.NewState = req.PriorState
+			return resp
+		}
+		// pretend the non-current instance has been deleted already
+		resp.NewState = cty.NullVal(req.PriorState.Type())
+		return resp
+	}
+
+	// Here we introduce a cycle via state which only shows up in the apply
+	// graph where the actual destroy instances are connected in the graph.
+	// This could happen for example when a user has an existing state with
+	// stored dependencies, and changes the config in such a way that
+	// contradicts the stored dependencies.
+	state := states.NewState()
+	root := state.EnsureModule(addrs.RootModuleInstance)
+	root.SetResourceInstanceDeposed(
+		mustResourceInstanceAddr("test_object.a[0]").Resource,
+		states.DeposedKey("deposed"),
+		&states.ResourceInstanceObjectSrc{
+			Status:       states.ObjectTainted,
+			AttrsJSON:    []byte(`{"test_string":"old"}`),
+			Dependencies: []addrs.ConfigResource{},
+		},
+		mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
+	)
+	root.SetResourceInstanceCurrent(
+		mustResourceInstanceAddr("test_object.a[0]").Resource,
+		&states.ResourceInstanceObjectSrc{
+			Status:       states.ObjectTainted,
+			AttrsJSON:    []byte(`{"test_string":"current"}`),
+			Dependencies: []addrs.ConfigResource{},
+		},
+		mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
+	)
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	_, diags := ctx.Plan(m, state, &PlanOpts{
+		Mode: plans.NormalMode,
+	})
+	assertNoErrors(t, diags)
+}
+
+// make sure there are no cycles with changes around a provider configured via
+// managed resources.
+func TestContext2Plan_destroyWithResourceConfiguredProvider(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  in = "a"
+}
+
+provider "test" {
+  alias = "other"
+  in = test_object.a.out
+}
+
+resource "test_object" "b" {
+  provider = test.other
+  in = "a"
+}
+`})
+
+	testProvider := &MockProvider{
+		GetProviderSchemaResponse: &providers.GetProviderSchemaResponse{
+			Provider: providers.Schema{
+				Block: &configschema.Block{
+					Attributes: map[string]*configschema.Attribute{
+						"in": {
+							Type:     cty.String,
+							Optional: true,
+						},
+					},
+				},
+			},
+			ResourceTypes: map[string]providers.Schema{
+				"test_object": providers.Schema{
+					Block: &configschema.Block{
+						Attributes: map[string]*configschema.Attribute{
+							"in": {
+								Type:     cty.String,
+								Optional: true,
+							},
+							"out": {
+								Type:     cty.Number,
+								Computed: true,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(testProvider),
+		},
+	})
+
+	// plan+apply to create the initial state
+	opts := SimplePlanOpts(plans.NormalMode, testInputValuesUnset(m.Module.Variables))
+	plan, diags := ctx.Plan(m, states.NewState(), opts)
+	assertNoErrors(t, diags)
+	state, diags := ctx.Apply(plan, m)
+	assertNoErrors(t, diags)
+
+	// Resource changes which have dependencies across providers which
+	// themselves depend on resources can result in cycles.
+	// Because other_object transitively depends on the module resources
+	// through its provider, we trigger changes on both sides of this boundary
+	// to ensure we can create a valid plan.
+	//
+	// Try to replace both instances
+	addrA := mustResourceInstanceAddr("test_object.a")
+	addrB := mustResourceInstanceAddr(`test_object.b`)
+	opts.ForceReplace = []addrs.AbsResourceInstance{addrA, addrB}
+
+	_, diags = ctx.Plan(m, state, opts)
+	assertNoErrors(t, diags)
+}
+
+func TestContext2Plan_destroyPartialState(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+}
+
+output "out" {
+  value = module.mod.out
+}
+
+module "mod" {
+  source = "./mod"
+}
+`,
+
+		"./mod/main.tf": `
+resource "test_object" "a" {
+  count = 2
+
+  lifecycle {
+    precondition {
+	  # test_object_b has already been destroyed, so referencing the first
+      # instance must not fail during a destroy plan.
+      condition = test_object.b[0].test_string == "invalid"
+      error_message = "should not block destroy"
+    }
+    precondition {
+      # this failing condition should bot block a destroy plan
+      condition = !local.continue
+      error_message = "should not block destroy"
+    }
+  }
+}
+
+resource "test_object" "b" {
+  count = 2
+}
+
+locals {
+  continue = true
+}
+
+output "out" {
+  # the reference to test_object.b[0] may not be valid during a destroy plan,
+  # but should not fail.
+  value = local.continue ? test_object.a[1].test_string != "invalid"  && test_object.b[0].test_string != "invalid" : false
+
+  precondition {
+    # test_object_b has already been destroyed, so referencing the first
+    # instance must not fail during a destroy plan.
+    condition = test_object.b[0].test_string == "invalid"
+    error_message = "should not block destroy"
+  }
+  precondition {
+    # this failing condition should bot block a destroy plan
+    condition = test_object.a[0].test_string == "invalid"
+    error_message = "should not block destroy"
+  }
+}
+`})
+
+	p := simpleMockProvider()
+
+	// This state could be the result of a failed destroy, leaving only 2
+	// remaining instances. We want to be able to continue the destroy to
+	// remove everything without blocking on invalid references or failing
+	// conditions.
+	state := states.NewState()
+	mod := state.EnsureModule(addrs.RootModuleInstance.Child("mod", addrs.NoKey))
+	mod.SetResourceInstanceCurrent(
+		mustResourceInstanceAddr("test_object.a[0]").Resource,
+		&states.ResourceInstanceObjectSrc{
+			Status:       states.ObjectTainted,
+			AttrsJSON:    []byte(`{"test_string":"current"}`),
+			Dependencies: []addrs.ConfigResource{},
+		},
+		mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
+	)
+	mod.SetResourceInstanceCurrent(
+		mustResourceInstanceAddr("test_object.a[1]").Resource,
+		&states.ResourceInstanceObjectSrc{
+			Status:       states.ObjectTainted,
+			AttrsJSON:    []byte(`{"test_string":"current"}`),
+			Dependencies: []addrs.ConfigResource{},
+		},
+		mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
+	)
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	_, diags := ctx.Plan(m, state, &PlanOpts{
+		Mode: plans.DestroyMode,
+	})
+	assertNoErrors(t, diags)
+}
+
+func TestContext2Plan_destroyPartialStateLocalRef(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+module "already_destroyed" {
+  count = 1
+  source = "./mod"
+}
+
+locals {
+  eval_error = module.already_destroyed[0].out
+}
+
+output "already_destroyed" {
+  value = local.eval_error
+}
+
+`,
+
+		"./mod/main.tf": `
+resource "test_object" "a" {
+}
+
+output "out" {
+  value = test_object.a.test_string
+}
+`})
+
+	p := simpleMockProvider()
+
+	state := states.NewState()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	_, diags := ctx.Plan(m, state, &PlanOpts{
+		Mode: plans.DestroyMode,
+	})
+	assertNoErrors(t, diags)
+}
+
+// Make sure the data sources in the prior state are serializeable even if
+// there were an error in the plan.
+func TestContext2Plan_dataSourceReadPlanError(t *testing.T) {
+	m, snap := testModuleWithSnapshot(t, "data-source-read-with-plan-error")
+	awsProvider := testProvider("aws")
+	testProvider := testProvider("test")
+
+	testProvider.PlanResourceChangeFn = func(req providers.PlanResourceChangeRequest) (resp providers.PlanResourceChangeResponse) {
+		resp.PlannedState = req.ProposedNewState
+		resp.Diagnostics = resp.Diagnostics.Append(errors.New("oops"))
+		return resp
+	}
+
+	state := states.NewState()
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("aws"):  testProviderFuncFixed(awsProvider),
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(testProvider),
+		},
+	})
+
+	plan, diags := ctx.Plan(m, state, DefaultPlanOpts)
+	if !diags.HasErrors() {
+		t.Fatalf("expected plan error")
+	}
+
+	// make sure we can serialize the plan even if there were an error
+	_, _, _, err := contextOptsForPlanViaFile(t, snap, plan)
+	if err != nil {
+		t.Fatalf("failed to round-trip through planfile: %s", err)
+	}
+}
+
+func TestContext2Plan_ignoredMarkedValue(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  map = {
+    prior = "value"
+    new   = sensitive("ignored")
+  }
+}
+`})
+
+	testProvider := &MockProvider{
+		GetProviderSchemaResponse: &providers.GetProviderSchemaResponse{
+			ResourceTypes: map[string]providers.Schema{
+				"test_object": providers.Schema{
+					Block: &configschema.Block{
+						Attributes: map[string]*configschema.Attribute{
+							"map": {
+								Type:     cty.Map(cty.String),
+								Optional: true,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	testProvider.PlanResourceChangeFn = func(req providers.PlanResourceChangeRequest) (resp providers.PlanResourceChangeResponse) {
+		// We're going to ignore any changes here and return the prior state.
+		resp.PlannedState = req.PriorState
+		return resp
+	}
+
+	state := states.NewState()
+	root := state.RootModule()
+	root.SetResourceInstanceCurrent(
+		mustResourceInstanceAddr("test_object.a").Resource,
+		&states.ResourceInstanceObjectSrc{
+			Status:       states.ObjectReady,
+			AttrsJSON:    []byte(`{"map":{"prior":"value"}}`),
+			Dependencies: []addrs.ConfigResource{},
+		},
+		mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
+	)
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(testProvider),
+		},
+	})
+
+	// plan+apply to create the initial state
+	opts := SimplePlanOpts(plans.NormalMode, testInputValuesUnset(m.Module.Variables))
+	plan, diags := ctx.Plan(m, state, opts)
+	assertNoErrors(t, diags)
+
+	for _, c := range plan.Changes.Resources {
+		if c.Action != plans.NoOp {
+			t.Errorf("unexpected %s change for %s", c.Action, c.Addr)
+		}
+	}
+}
+
+func TestContext2Plan_importResourceBasic(t *testing.T) {
+	addr := mustResourceInstanceAddr("test_object.a")
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  test_string = "foo"
+}
+
+import {
+  to   = test_object.a
+  id   = "123"
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	hook := new(MockHook)
+	ctx := testContext2(t, &ContextOpts{
+		Hooks: []Hook{hook},
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+	p.ReadResourceResponse = &providers.ReadResourceResponse{
+		NewState: cty.ObjectVal(map[string]cty.Value{
+			"test_string": cty.StringVal("foo"),
+		}),
+	}
+	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
+		ImportedResources: []providers.ImportedResource{
+			{
+				TypeName: "test_object",
+				State: cty.ObjectVal(map[string]cty.Value{
+					"test_string": cty.StringVal("foo"),
+				}),
+			},
+		},
+	}
+
+	plan, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
+	}
+
+	t.Run(addr.String(), func(t *testing.T) {
+		instPlan := plan.Changes.ResourceInstance(addr)
+		if instPlan == nil {
+			t.Fatalf("no plan for %s at all", addr)
+		}
+
+		if got, want := instPlan.Addr, addr; !got.Equal(want) {
+			t.Errorf("wrong current address\ngot:  %s\nwant: %s", got, want)
+		}
+		if got, want := instPlan.PrevRunAddr, addr; !got.Equal(want) {
+			t.Errorf("wrong previous run address\ngot:  %s\nwant: %s", got, want)
+		}
+		if got, want := instPlan.Action, plans.NoOp; got != want {
+			t.Errorf("wrong planned action\ngot:  %s\nwant: %s", got, want)
+		}
+		if got, want := instPlan.ActionReason, plans.ResourceInstanceChangeNoReason; got != want {
+			t.Errorf("wrong action reason\ngot:  %s\nwant: %s", got, want)
+		}
+		if instPlan.Importing.ID != "123" {
+			t.Errorf("expected import change from \"123\", got non-import change")
+		}
+
+		if !hook.PrePlanImportCalled {
+			t.Fatalf("PostPlanImport hook not called")
+		}
+		if addr, wantAddr := hook.PrePlanImportAddr, instPlan.Addr; !addr.Equal(wantAddr) {
+			t.Errorf("expected addr to be %s, but was %s", wantAddr, addr)
+		}
+
+		if !hook.PostPlanImportCalled {
+			t.Fatalf("PostPlanImport hook not called")
+		}
+		if addr, wantAddr := hook.PostPlanImportAddr, instPlan.Addr; !addr.Equal(wantAddr) {
+			t.Errorf("expected addr to be %s, but was %s", wantAddr, addr)
+		}
+	})
+}
+
+func TestContext2Plan_importResourceAlreadyInState(t *testing.T) {
+	addr := mustResourceInstanceAddr("test_object.a")
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  test_string = "foo"
+}
+
+import {
+  to   = test_object.a
+  id   = "123"
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+	p.ReadResourceResponse = &providers.ReadResourceResponse{
+		NewState: cty.ObjectVal(map[string]cty.Value{
+			"test_string": cty.StringVal("foo"),
+		}),
+	}
+	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
+		ImportedResources: []providers.ImportedResource{
+			{
+				TypeName: "test_object",
+				State: cty.ObjectVal(map[string]cty.Value{
+					"test_string": cty.StringVal("foo"),
+				}),
+			},
+		},
+	}
+
+	state := states.NewState()
+	root := state.EnsureModule(addrs.RootModuleInstance)
+	root.SetResourceInstanceCurrent(
+		mustResourceInstanceAddr("test_object.a").Resource,
+		&states.ResourceInstanceObjectSrc{
+			Status:    states.ObjectReady,
+			AttrsJSON: []byte(`{"test_string":"foo"}`),
+		},
+		mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
+	)
+
+	plan, diags := ctx.Plan(m, state, DefaultPlanOpts)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
+	}
+
+	t.Run(addr.String(), func(t *testing.T) {
+		instPlan := plan.Changes.ResourceInstance(addr)
+		if instPlan == nil {
+			t.Fatalf("no plan for %s at all", addr)
+		}
+
+		if got, want := instPlan.Addr, addr; !got.Equal(want) {
+			t.Errorf("wrong current address\ngot:  %s\nwant: %s", got, want)
+		}
+		if got, want := instPlan.PrevRunAddr, addr; !got.Equal(want) {
+			t.Errorf("wrong previous run address\ngot:  %s\nwant: %s", got, want)
+		}
+		if got, want := instPlan.Action, plans.NoOp; got != want {
+			t.Errorf("wrong planned action\ngot:  %s\nwant: %s", got, want)
+		}
+		if got, want := instPlan.ActionReason, plans.ResourceInstanceChangeNoReason; got != want {
+			t.Errorf("wrong action reason\ngot:  %s\nwant: %s", got, want)
+		}
+		if instPlan.Importing != nil {
+			t.Errorf("expected non-import change, got import change %+v", instPlan.Importing)
+		}
+	})
+}
+
+func TestContext2Plan_importResourceUpdate(t *testing.T) {
+	addr := mustResourceInstanceAddr("test_object.a")
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  test_string = "bar"
+}
+
+import {
+  to   = test_object.a
+  id   = "123"
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+	p.ReadResourceResponse = &providers.ReadResourceResponse{
+		NewState: cty.ObjectVal(map[string]cty.Value{
+			"test_string": cty.StringVal("foo"),
+		}),
+	}
+	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
+		ImportedResources: []providers.ImportedResource{
+			{
+				TypeName: "test_object",
+				State: cty.ObjectVal(map[string]cty.Value{
+					"test_string": cty.StringVal("foo"),
+				}),
+			},
+		},
+	}
+
+	plan, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
+	}
+
+	t.Run(addr.String(), func(t *testing.T) {
+		instPlan := plan.Changes.ResourceInstance(addr)
+		if instPlan == nil {
+			t.Fatalf("no plan for %s at all", addr)
+		}
+
+		if got, want := instPlan.Addr, addr; !got.Equal(want) {
+			t.Errorf("wrong current address\ngot:  %s\nwant: %s", got, want)
+		}
+		if got, want := instPlan.PrevRunAddr, addr; !got.Equal(want) {
+			t.Errorf("wrong previous run address\ngot:  %s\nwant: %s", got, want)
+		}
+		if got, want := instPlan.Action, plans.Update; got != want {
+			t.Errorf("wrong planned action\ngot:  %s\nwant: %s", got, want)
+		}
+		if got, want := instPlan.ActionReason, plans.ResourceInstanceChangeNoReason; got != want {
+			t.Errorf("wrong action reason\ngot:  %s\nwant: %s", got, want)
+		}
+		if instPlan.Importing.ID != "123" {
+			t.Errorf("expected import change from \"123\", got non-import change")
+		}
+	})
+}
+
+func TestContext2Plan_importResourceReplace(t *testing.T) {
+	addr := mustResourceInstanceAddr("test_object.a")
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  test_string = "bar"
+}
+
+import {
+  to   = test_object.a
+  id   = "123"
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+	p.ReadResourceResponse = &providers.ReadResourceResponse{
+		NewState: cty.ObjectVal(map[string]cty.Value{
+			"test_string": cty.StringVal("foo"),
+		}),
+	}
+	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
+		ImportedResources: []providers.ImportedResource{
+			{
+				TypeName: "test_object",
+				State: cty.ObjectVal(map[string]cty.Value{
+					"test_string": cty.StringVal("foo"),
+				}),
+			},
+		},
+	}
+
+	plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+		Mode: plans.NormalMode,
+		ForceReplace: []addrs.AbsResourceInstance{
+			addr,
+		},
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
+	}
+
+	t.Run(addr.String(), func(t *testing.T) {
+		instPlan := plan.Changes.ResourceInstance(addr)
+		if instPlan == nil {
+			t.Fatalf("no plan for %s at all", addr)
+		}
+
+		if got, want := instPlan.Addr, addr; !got.Equal(want) {
+			t.Errorf("wrong current address\ngot:  %s\nwant: %s", got, want)
+		}
+		if got, want := instPlan.PrevRunAddr, addr; !got.Equal(want) {
+			t.Errorf("wrong previous run address\ngot:  %s\nwant: %s", got, want)
+		}
+		if got, want := instPlan.Action, plans.DeleteThenCreate; got != want {
+			t.Errorf("wrong planned action\ngot:  %s\nwant: %s", got, want)
+		}
+		if instPlan.Importing.ID != "123" {
+			t.Errorf("expected import change from \"123\", got non-import change")
+		}
+	})
+}
+
+func TestContext2Plan_importRefreshOnce(t *testing.T) {
+	addr := mustResourceInstanceAddr("test_object.a")
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  test_string = "bar"
+}
+
+import {
+  to   = test_object.a
+  id   = "123"
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	readCalled := 0
+	p.ReadResourceFn = func(req providers.ReadResourceRequest) providers.ReadResourceResponse {
+		readCalled++
+		state, _ := simpleTestSchema().CoerceValue(cty.ObjectVal(map[string]cty.Value{
+			"test_string": cty.StringVal("foo"),
+		}))
+
+		return providers.ReadResourceResponse{
+			NewState: state,
+		}
+	}
+
+	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
+		ImportedResources: []providers.ImportedResource{
+			{
+				TypeName: "test_object",
+				State: cty.ObjectVal(map[string]cty.Value{
+					"test_string": cty.StringVal("foo"),
+				}),
+			},
+		},
+	}
+
+	_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+		Mode: plans.NormalMode,
+		ForceReplace: []addrs.AbsResourceInstance{
+			addr,
+		},
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
+	}
+
+	if readCalled > 1 {
+		t.Error("ReadResource called multiple times for import")
+	}
+}
+
+func TestContext2Plan_importTargetWithKeyDoesNotExist(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  count = 1
+  test_string = "bar"
+}
+
+import {
+  to   = test_object.a[42]
+  id   = "123"
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+	p.ReadResourceResponse = &providers.ReadResourceResponse{
+		NewState: cty.ObjectVal(map[string]cty.Value{
+			"test_string": cty.StringVal("foo"),
+		}),
+	}
+	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
+		ImportedResources: []providers.ImportedResource{
+			{
+				TypeName: "test_object",
+				State: cty.ObjectVal(map[string]cty.Value{
+					"test_string": cty.StringVal("foo"),
+				}),
+			},
+		},
+	}
+
+	_, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	if !diags.HasErrors() {
+		t.Fatalf("expected error but got none")
+	}
+}
+
+func TestContext2Plan_importForEach(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+locals {
+  ids = {
+    a = "123"
+    b = "456"
+    c = "789"
+  }
+}
+
+resource "test_object" "a" {
+  for_each    = local.ids
+  test_string = "foo"
+}
+
+import {
+  for_each = local.ids
+  to       = test_object.a[each.key]
+  id       = each.value
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+	p.ReadResourceResponse = &providers.ReadResourceResponse{
+		NewState: cty.ObjectVal(map[string]cty.Value{
+			"test_string": cty.StringVal("foo"),
+		}),
+	}
+	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
+		ImportedResources: []providers.ImportedResource{
+			{
+				TypeName: "test_object",
+				State: cty.ObjectVal(map[string]cty.Value{
+					"test_string": cty.StringVal("foo"),
+				}),
+			},
+		},
+	}
+
+	validateDiags := ctx.Validate(m)
+	if validateDiags.HasErrors() {
+		t.Fatalf("unexpected validation errors\n%s", validateDiags.Err().Error())
+	}
+
+	// test_object.a["c"] is already in the state, so there is nothing to
+	// import for it.
+	state := states.NewState()
+	root := state.EnsureModule(addrs.RootModuleInstance)
+	root.SetResourceInstanceCurrent(
+		mustResourceInstanceAddr(`test_object.a["c"]`).Resource,
+		&states.ResourceInstanceObjectSrc{
+			Status:    states.ObjectReady,
+			AttrsJSON: []byte(`{"test_string":"foo"}`),
+		},
+		mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
+	)
+
+	plan, diags := ctx.Plan(m, state, DefaultPlanOpts)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
+	}
+
+	for addrStr, wantID := range map[string]string{`test_object.a["a"]`: "123", `test_object.a["b"]`: "456"} {
+		addr := mustResourceInstanceAddr(addrStr)
+		t.Run(addr.String(), func(t *testing.T) {
+			instPlan := plan.Changes.ResourceInstance(addr)
+			if instPlan == nil {
+				t.Fatalf("no plan for %s at all", addr)
+			}
+			if got, want := instPlan.Action, plans.NoOp; got != want {
+				t.Errorf("wrong planned action\ngot:  %s\nwant: %s", got, want)
+			}
+			if instPlan.Importing == nil || instPlan.Importing.ID != wantID {
+				t.Errorf("expected import change from %q, got %+v", wantID, instPlan.Importing)
+			}
+		})
+	}
+
+	addr := mustResourceInstanceAddr(`test_object.a["c"]`)
+	t.Run(addr.String(), func(t *testing.T) {
+		instPlan := plan.Changes.ResourceInstance(addr)
+		if instPlan == nil {
+			t.Fatalf("no plan for %s at all", addr)
+		}
+		if instPlan.Importing != nil {
+			t.Errorf("expected non-import change, got import change %+v", instPlan.Importing)
+		}
+	})
+}
+
+func TestContext2Plan_importForEachTargetDoesNotExist(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  for_each    = toset(["a"])
+  test_string = "foo"
+}
+
+import {
+  for_each = { a = "123", b = "456" }
+  to       = test_object.a[each.key]
+  id       = each.value
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	_, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	if !diags.HasErrors() {
+		t.Fatalf("expected error but got none")
+	}
+	if got, want := diags.Err().Error(), `Importing to resource address test_object.a["b"] is not possible`; !strings.Contains(got, want) {
+		t.Fatalf("wrong error:\ngot:  %s\nwant: message containing %q", got, want)
+	}
+}
+
+func TestContext2Plan_importForEachDuplicateTarget(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  for_each    = toset(["a"])
+  test_string = "foo"
+}
+
+import {
+  for_each = { A = "123", a = "456" }
+  to       = test_object.a[lower(each.key)]
+  id       = each.value
+}
+`,
+	})
+
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	_, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	if !diags.HasErrors() {
+		t.Fatalf("expected error but got none")
+	}
+	if got, want := diags.Err().Error(), "Duplicate import target"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error:\ngot:  %s\nwant: message containing %q", got, want)
+	}
+}
+
+func TestContext2Plan_importIdVariable(t *testing.T) {
+	p := testProvider("aws")
+	m := testModule(t, "import-id-variable")
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("aws"): testProviderFuncFixed(p),
+		},
+	})
+
+	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
+		ImportedResources: []providers.ImportedResource{
+			{
+				TypeName: "aws_instance",
+				State: cty.ObjectVal(map[string]cty.Value{
+					"id": cty.StringVal("foo"),
+				}),
+			},
+		},
+	}
+
+	_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+		SetVariables: InputValues{
+			"the_id": &InputValue{
+				// let var take its default value
+				Value: cty.NilVal,
+			},
+		},
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
+	}
+}
+
+func TestContext2Plan_importIdFunc(t *testing.T) {
+	p := testProvider("aws")
+	m := testModule(t, "import-id-func")
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("aws"): testProviderFuncFixed(p),
+		},
+	})
+
+	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
+		ImportedResources: []providers.ImportedResource{
+			{
+				TypeName: "aws_instance",
+				State: cty.ObjectVal(map[string]cty.Value{
+					"id": cty.StringVal("foo"),
+				}),
+			},
+		},
+	}
+
+	_, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
+	}
+}
+
+func TestContext2Plan_importIdDataSource(t *testing.T) {
+	p := testProvider("aws")
+	m := testModule(t, "import-id-data-source")
+
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		ResourceTypes: map[string]*configschema.Block{
+			"aws_subnet": {
+				Attributes: map[string]*configschema.Attribute{
+					"id": {
+						Type:     cty.String,
+						Computed: true,
+					},
+				},
+			},
+		},
+		DataSources: map[string]*configschema.Block{
+			"aws_subnet": {
+				Attributes: map[string]*configschema.Attribute{
+					"vpc_id": {
+						Type:     cty.String,
+						Required: true,
+					},
+					"cidr_block": {
+						Type:     cty.String,
+						Computed: true,
+					},
+					"id": {
+						Type:     cty.String,
+						Computed: true,
+					},
+				},
+			},
+		},
+	})
+	p.ReadDataSourceResponse = &providers.ReadDataSourceResponse{
+		State: cty.ObjectVal(map[string]cty.Value{
+			"vpc_id":     cty.StringVal("abc"),
+			"cidr_block": cty.StringVal("10.0.1.0/24"),
+			"id":         cty.StringVal("123"),
+		}),
+	}
+	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
+		ImportedResources: []providers.ImportedResource{
+			{
+				TypeName: "aws_subnet",
+				State: cty.ObjectVal(map[string]cty.Value{
+					"id": cty.StringVal("foo"),
+				}),
+			},
+		},
+	}
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("aws"): testProviderFuncFixed(p),
 		},
-		{
-			"data.foo.bar",
-			"Reference to undeclared resource",
-			`A data resource "foo" "bar" has not been declared in the root module`,
+	})
+
+	_, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
+	}
+}
+
+func TestContext2Plan_importIdModule(t *testing.T) {
+	p := testProvider("aws")
+	m := testModule(t, "import-id-module")
+
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		ResourceTypes: map[string]*configschema.Block{
+			"aws_lb": {
+				Attributes: map[string]*configschema.Attribute{
+					"id": {
+						Type:     cty.String,
+						Computed: true,
+					},
+				},
+			},
 		},
-		{
-			"test_resource.b.value",
-			"Invalid condition result",
-			"Condition expression must return either true or false",
+	})
+	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
+		ImportedResources: []providers.ImportedResource{
+			{
+				TypeName: "aws_lb",
+				State: cty.ObjectVal(map[string]cty.Value{
+					"id": cty.StringVal("foo"),
+				}),
+			},
 		},
-		{
-			"test_resource.c.value",
-			"Invalid condition result",
-			"Invalid condition result value: a bool is required",
+	}
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("aws"): testProviderFuncFixed(p),
 		},
+	})
+
+	_, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors: %s", diags.Err())
 	}
+}
 
+func TestContext2Plan_importIdInvalidNull(t *testing.T) {
 	p := testProvider("test")
+	m := testModule(t, "import-id-invalid-null")
 	ctx := testContext2(t, &ContextOpts{
 		Providers: map[addrs.Provider]providers.Factory{
 			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 		},
 	})
 
-	for _, tc := range testCases {
-		t.Run(tc.condition, func(t *testing.T) {
-			main := fmt.Sprintf(`
-			resource "test_resource" "a" {
-				value = var.boop
-				lifecycle {
-					precondition {
-						condition     = %s
-						error_message = "Not relevant."
-					}
-				}
-			}
-
-			resource "test_resource" "b" {
-				value = null
-			}
-
-			resource "test_resource" "c" {
-				value = "bar"
-			}
-			`, tc.condition)
-			m := testModuleInline(t, map[string]string{"main.tf": main})
-
-			plan, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
-			if !diags.HasErrors() {
-				t.Fatal("succeeded; want errors")
-			}
-
-			if !plan.Errored {
-				t.Fatal("plan failed to record error")
-			}
-
-			diag := diags[0]
-			if got, want := diag.Description().Summary, tc.wantSummary; got != want {
-				t.Errorf("unexpected summary\n got: %s\nwant: %s", got, want)
-			}
-			if got, want := diag.Description().Detail, tc.wantDetail; !strings.Contains(got, want) {
-				t.Errorf("unexpected summary\ngot: %s\nwant to contain %q", got, want)
-			}
-
-			for _, kv := range plan.Checks.ConfigResults.Elements() {
-				// All these are configuration or evaluation errors
-				if kv.Value.Status != checks.StatusError {
-					t.Errorf("incorrect status, got %s", kv.Value.Status)
-				}
-			}
-		})
+	_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+		SetVariables: InputValues{
+			"the_id": &InputValue{
+				Value: cty.NullVal(cty.String),
+			},
+		},
+	})
+	if !diags.HasErrors() {
+		t.Fatal("succeeded; want errors")
+	}
+	if got, want := diags.Err().Error(), "The import ID cannot be null"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error:\ngot:  %s\nwant: message containing %q", got, want)
 	}
 }
 
-func TestContext2Plan_preconditionSensitiveValues(t *testing.T) {
+func TestContext2Plan_importIdInvalidUnknown(t *testing.T) {
 	p := testProvider("test")
+	m := testModule(t, "import-id-invalid-unknown")
 	ctx := testContext2(t, &ContextOpts{
 		Providers: map[addrs.Provider]providers.Factory{
 			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 		},
 	})
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		ResourceTypes: map[string]*configschema.Block{
+			"test_resource": {
+				Attributes: map[string]*configschema.Attribute{
+					"id": {
+						Type:     cty.String,
+						Computed: true,
+					},
+				},
+			},
+		},
+	})
+	p.PlanResourceChangeFn = func(req providers.PlanResourceChangeRequest) providers.PlanResourceChangeResponse {
+		return providers.PlanResourceChangeResponse{
+			PlannedState: cty.UnknownVal(cty.Object(map[string]cty.Type{
+				"id": cty.String,
+			})),
+		}
+	}
+	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
+		ImportedResources: []providers.ImportedResource{
+			{
+				TypeName: "test_resource",
+				State: cty.ObjectVal(map[string]cty.Value{
+					"id": cty.StringVal("foo"),
+				}),
+			},
+		},
+	}
+
+	_, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	if !diags.HasErrors() {
+		t.Fatal("succeeded; want errors")
+	}
+	if got, want := diags.Err().Error(), `The import block "id" argument depends on resource attributes that cannot be determined until apply`; !strings.Contains(got, want) {
+		t.Fatalf("wrong error:\ngot:  %s\nwant: message containing %q", got, want)
+	}
+}
 
+func TestContext2Plan_importIntoModuleWithGeneratedConfig(t *testing.T) {
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
-variable "boop" {
-  sensitive = true
-  type      = string
+import {
+  to = test_object.a
+  id = "123"
 }
 
-output "a" {
-  sensitive = true
-  value     = var.boop
+import {
+  to = module.mod.test_object.a
+  id = "456"
+}
 
-  precondition {
-    condition     = length(var.boop) <= 4
-    error_message = "Boop is too long, ${length(var.boop)} > 4"
-  }
+module "mod" {
+  source = "./mod"
+}
+`,
+		"./mod/main.tf": `
+resource "test_object" "a" {
+  test_string = "bar"
 }
 `,
 	})
 
-	_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
-		Mode: plans.NormalMode,
-		SetVariables: InputValues{
-			"boop": &InputValue{
-				Value:      cty.StringVal("bleep"),
-				SourceType: ValueFromCLIArg,
-			},
+	p := simpleMockProvider()
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 		},
 	})
-	if !diags.HasErrors() {
-		t.Fatal("succeeded; want errors")
+	p.ReadResourceResponse = &providers.ReadResourceResponse{
+		NewState: cty.ObjectVal(map[string]cty.Value{
+			"test_string": cty.StringVal("foo"),
+		}),
 	}
-	if got, want := len(diags), 2; got != want {
-		t.Errorf("wrong number of diags, got %d, want %d", got, want)
+	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
+		ImportedResources: []providers.ImportedResource{
+			{
+				TypeName: "test_object",
+				State: cty.ObjectVal(map[string]cty.Value{
+					"test_string": cty.StringVal("foo"),
+				}),
+			},
+		},
 	}
-	for _, diag := range diags {
-		desc := diag.Description()
-		if desc.Summary == "Module output value precondition failed" {
-			if got, want := desc.Detail, "This check failed, but has an invalid error message as described in the other accompanying messages."; !strings.Contains(got, want) {
-				t.Errorf("unexpected detail\ngot: %s\nwant to contain %q", got, want)
-			}
-		} else if desc.Summary == "Error message refers to sensitive values" {
-			if got, want := desc.Detail, "The error expression used to explain this condition refers to sensitive values, so OpenTofu will not display the resulting message."; !strings.Contains(got, want) {
-				t.Errorf("unexpected detail\ngot: %s\nwant to contain %q", got, want)
-			}
-		} else {
-			t.Errorf("unexpected summary\ngot: %s", desc.Summary)
-		}
+
+	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
+		ImportedResources: []providers.ImportedResource{
+			{
+				TypeName: "test_object",
+				State: cty.ObjectVal(map[string]cty.Value{
+					"test_string": cty.StringVal("foo"),
+				}),
+			},
+		},
+	}
+
+	plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+		Mode:               plans.NormalMode,
+		GenerateConfigPath: "generated.tf", // Actual value here doesn't matter, as long as it is not empty.
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
+	}
+
+	one := mustResourceInstanceAddr("test_object.a")
+	two := mustResourceInstanceAddr("module.mod.test_object.a")
+
+	onePlan := plan.Changes.ResourceInstance(one)
+	twoPlan := plan.Changes.ResourceInstance(two)
+
+	// This test is just to make sure things work e2e with modules and generated
+	// config, so we're not too careful about the actual responses - we're just
+	// happy nothing panicked. See the other import tests for actual validation
+	// of responses and the like.
+	if twoPlan.Action != plans.Update {
+		t.Errorf("expected nested item to be updated but was %s", twoPlan.Action)
+	}
+
+	if len(onePlan.GeneratedConfig) == 0 {
+		t.Errorf("expected root item to generate config but it didn't")
 	}
 }
 
-func TestContext2Plan_triggeredBy(t *testing.T) {
+func TestContext2Plan_importResourceConfigGen(t *testing.T) {
+	addr := mustResourceInstanceAddr("test_object.a")
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
-resource "test_object" "a" {
-  count = 1
-  test_string = "new"
-}
-resource "test_object" "b" {
-  count = 1
-  test_string = test_object.a[count.index].test_string
-  lifecycle {
-    # the change to test_string in the other resource should trigger replacement
-    replace_triggered_by = [ test_object.a[count.index].test_string ]
-  }
+import {
+  to   = test_object.a
+  id   = "123"
 }
 `,
 	})
 
 	p := simpleMockProvider()
-
 	ctx := testContext2(t, &ContextOpts{
 		Providers: map[addrs.Provider]providers.Factory{
 			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 		},
 	})
-
-	state := states.BuildState(func(s *states.SyncState) {
-		s.SetResourceInstanceCurrent(
-			mustResourceInstanceAddr("test_object.a[0]"),
-			&states.ResourceInstanceObjectSrc{
-				AttrsJSON: []byte(`{"test_string":"old"}`),
-				Status:    states.ObjectReady,
-			},
-			mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
-		)
-		s.SetResourceInstanceCurrent(
-			mustResourceInstanceAddr("test_object.b[0]"),
-			&states.ResourceInstanceObjectSrc{
-				AttrsJSON: []byte(`{}`),
-				Status:    states.ObjectReady,
+	p.ReadResourceResponse = &providers.ReadResourceResponse{
+		NewState: cty.ObjectVal(map[string]cty.Value{
+			"test_string": cty.StringVal("foo"),
+		}),
+	}
+	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
+		ImportedResources: []providers.ImportedResource{
+			{
+				TypeName: "test_object",
+				State: cty.ObjectVal(map[string]cty.Value{
+					"test_string": cty.StringVal("foo"),
+				}),
 			},
-			mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
-		)
-	})
+		},
+	}
 
-	plan, diags := ctx.Plan(m, state, &PlanOpts{
-		Mode: plans.NormalMode,
+	plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+		Mode:               plans.NormalMode,
+		GenerateConfigPath: "generated.tf", // Actual value here doesn't matter, as long as it is not empty.
 	})
 	if diags.HasErrors() {
 		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
 	}
-	for _, c := range plan.Changes.Resources {
-		switch c.Addr.String() {
-		case "test_object.a[0]":
-			if c.Action != plans.Update {
-				t.Fatalf("unexpected %s change for %s\n", c.Action, c.Addr)
-			}
-		case "test_object.b[0]":
-			if c.Action != plans.DeleteThenCreate {
-				t.Fatalf("unexpected %s change for %s\n", c.Action, c.Addr)
-			}
-			if c.ActionReason != plans.ResourceInstanceReplaceByTriggers {
-				t.Fatalf("incorrect reason for change: %s\n", c.ActionReason)
-			}
-		default:
-			t.Fatal("unexpected change", c.Addr, c.Action)
-		}
-	}
-}
-
-func TestContext2Plan_dataSchemaChange(t *testing.T) {
-	// We can't decode the prior state when a data source upgrades the schema
-	// in an incompatible way. Since prior state for data sources is purely
-	// informational, decoding should be skipped altogether.
-	m := testModuleInline(t, map[string]string{
-		"main.tf": `
-data "test_object" "a" {
-  obj {
-    # args changes from a list to a map
-    args = {
-      val = "string"
-	}
-  }
-}
-`,
-	})
-
-	p := new(MockProvider)
-	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
-		DataSources: map[string]*configschema.Block{
-			"test_object": {
-				Attributes: map[string]*configschema.Attribute{
-					"id": {
-						Type:     cty.String,
-						Computed: true,
-					},
-				},
-				BlockTypes: map[string]*configschema.NestedBlock{
-					"obj": {
-						Block: configschema.Block{
-							Attributes: map[string]*configschema.Attribute{
-								"args": {Type: cty.Map(cty.String), Optional: true},
-							},
-						},
-						Nesting: configschema.NestingSet,
-					},
-				},
-			},
-		},
-	})
 
-	p.ReadDataSourceFn = func(req providers.ReadDataSourceRequest) (resp providers.ReadDataSourceResponse) {
-		resp.State = req.Config
-		return resp
-	}
+	t.Run(addr.String(), func(t *testing.T) {
+		instPlan := plan.Changes.ResourceInstance(addr)
+		if instPlan == nil {
+			t.Fatalf("no plan for %s at all", addr)
+		}
 
-	state := states.BuildState(func(s *states.SyncState) {
-		s.SetResourceInstanceCurrent(mustResourceInstanceAddr(`data.test_object.a`), &states.ResourceInstanceObjectSrc{
-			AttrsJSON: []byte(`{"id":"old","obj":[{"args":["string"]}]}`),
-			Status:    states.ObjectReady,
-		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
-	})
+		if got, want := instPlan.Addr, addr; !got.Equal(want) {
+			t.Errorf("wrong current address\ngot:  %s\nwant: %s", got, want)
+		}
+		if got, want := instPlan.PrevRunAddr, addr; !got.Equal(want) {
+			t.Errorf("wrong previous run address\ngot:  %s\nwant: %s", got, want)
+		}
+		if got, want := instPlan.Action, plans.NoOp; got != want {
+			t.Errorf("wrong planned action\ngot:  %s\nwant: %s", got, want)
+		}
+		if got, want := instPlan.ActionReason, plans.ResourceInstanceChangeNoReason; got != want {
+			t.Errorf("wrong action reason\ngot:  %s\nwant: %s", got, want)
+		}
+		if instPlan.Importing.ID != "123" {
+			t.Errorf("expected import change from \"123\", got non-import change")
+		}
 
-	ctx := testContext2(t, &ContextOpts{
-		Providers: map[addrs.Provider]providers.Factory{
-			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
-		},
+		want := `resource "test_object" "a" {
+  test_bool   = null
+  test_list   = null
+  test_map    = null
+  test_number = null
+  test_string = "foo"
+}`
+		got := instPlan.GeneratedConfig
+		if diff := cmp.Diff(want, got); len(diff) > 0 {
+			t.Errorf("got:\n%s\nwant:\n%s\ndiff:\n%s", got, want, diff)
+		}
 	})
-
-	_, diags := ctx.Plan(m, state, DefaultPlanOpts)
-	assertNoErrors(t, diags)
 }
 
-func TestContext2Plan_applyGraphError(t *testing.T) {
+func TestContext2Plan_importResourceConfigGenWithAlias(t *testing.T) {
+	addr := mustResourceInstanceAddr("test_object.a")
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
-resource "test_object" "a" {
+provider "test" {
+  alias = "backup"
 }
-resource "test_object" "b" {
-	depends_on = [test_object.a]
+
+import {
+  provider = test.backup
+  to       = test_object.a
+  id       = "123"
 }
 `,
 	})
 
 	p := simpleMockProvider()
-
-	// Here we introduce a cycle via state which only shows up in the apply
-	// graph where the actual destroy instances are connected in the graph.
-	// This could happen for example when a user has an existing state with
-	// stored dependencies, and changes the config in such a way that
-	// contradicts the stored dependencies.
-	state := states.NewState()
-	root := state.EnsureModule(addrs.RootModuleInstance)
-	root.SetResourceInstanceCurrent(
-		mustResourceInstanceAddr("test_object.a").Resource,
-		&states.ResourceInstanceObjectSrc{
-			Status:       states.ObjectTainted,
-			AttrsJSON:    []byte(`{"test_string":"a"}`),
-			Dependencies: []addrs.ConfigResource{mustResourceInstanceAddr("test_object.b").ContainingResource().Config()},
-		},
-		mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
-	)
-	root.SetResourceInstanceCurrent(
-		mustResourceInstanceAddr("test_object.b").Resource,
-		&states.ResourceInstanceObjectSrc{
-			Status:    states.ObjectTainted,
-			AttrsJSON: []byte(`{"test_string":"b"}`),
-		},
-		mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
-	)
-
 	ctx := testContext2(t, &ContextOpts{
 		Providers: map[addrs.Provider]providers.Factory{
 			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 		},
 	})
+	p.ReadResourceResponse = &providers.ReadResourceResponse{
+		NewState: cty.ObjectVal(map[string]cty.Value{
+			"test_string": cty.StringVal("foo"),
+		}),
+	}
+	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
+		ImportedResources: []providers.ImportedResource{
+			{
+				TypeName: "test_object",
+				State: cty.ObjectVal(map[string]cty.Value{
+					"test_string": cty.StringVal("foo"),
+				}),
+			},
+		},
+	}
+
+	plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+		Mode:               plans.NormalMode,
+		GenerateConfigPath: "generated.tf", // Actual value here doesn't matter, as long as it is not empty.
+	})
+	if diags.HasErrors() {
+		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
+	}
+
+	t.Run(addr.String(), func(t *testing.T) {
+		instPlan := plan.Changes.ResourceInstance(addr)
+		if instPlan == nil {
+			t.Fatalf("no plan for %s at all", addr)
+		}
+
+		if got, want := instPlan.Addr, addr; !got.Equal(want) {
+			t.Errorf("wrong current address\ngot:  %s\nwant: %s", got, want)
+		}
+		if got, want := instPlan.PrevRunAddr, addr; !got.Equal(want) {
+			t.Errorf("wrong previous run address\ngot:  %s\nwant: %s", got, want)
+		}
+		if got, want := instPlan.Action, plans.NoOp; got != want {
+			t.Errorf("wrong planned action\ngot:  %s\nwant: %s", got, want)
+		}
+		if got, want := instPlan.ActionReason, plans.ResourceInstanceChangeNoReason; got != want {
+			t.Errorf("wrong action reason\ngot:  %s\nwant: %s", got, want)
+		}
+		if instPlan.Importing.ID != "123" {
+			t.Errorf("expected import change from \"123\", got non-import change")
+		}
 
-	_, diags := ctx.Plan(m, state, &PlanOpts{
-		Mode: plans.NormalMode,
+		want := `resource "test_object" "a" {
+  provider    = test.backup
+  test_bool   = null
+  test_list   = null
+  test_map    = null
+  test_number = null
+  test_string = "foo"
+}`
+		got := instPlan.GeneratedConfig
+		if diff := cmp.Diff(want, got); len(diff) > 0 {
+			t.Errorf("got:\n%s\nwant:\n%s\ndiff:\n%s", got, want, diff)
+		}
 	})
-	if !diags.HasErrors() {
-		t.Fatal("cycle error not detected")
-	}
-
-	msg := diags.ErrWithWarnings().Error()
-	if !strings.Contains(msg, "Cycle") {
-		t.Fatalf("no cycle error found:\n got: %s\n", msg)
-	}
 }
 
-// plan a destroy with no state where configuration could fail to evaluate
-// expansion indexes.
-func TestContext2Plan_emptyDestroy(t *testing.T) {
+func TestContext2Plan_importResourceConfigGenExpandedResource(t *testing.T) {
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
-locals {
-  enable = true
-  value  = local.enable ? module.example[0].out : null
-}
-
-module "example" {
-  count  = local.enable ? 1 : 0
-  source = "./example"
-}
-`,
-		"example/main.tf": `
-resource "test_resource" "x" {
-}
-
-output "out" {
-  value = test_resource.x
+import {
+  to       = test_object.a[0]
+  id       = "123"
 }
 `,
 	})
 
-	p := testProvider("test")
-	state := states.NewState()
-
+	p := simpleMockProvider()
 	ctx := testContext2(t, &ContextOpts{
 		Providers: map[addrs.Provider]providers.Factory{
 			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 		},
 	})
+	p.ReadResourceResponse = &providers.ReadResourceResponse{
+		NewState: cty.ObjectVal(map[string]cty.Value{
+			"test_string": cty.StringVal("foo"),
+		}),
+	}
+	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
+		ImportedResources: []providers.ImportedResource{
+			{
+				TypeName: "test_object",
+				State: cty.ObjectVal(map[string]cty.Value{
+					"test_string": cty.StringVal("foo"),
+				}),
+			},
+		},
+	}
 
-	plan, diags := ctx.Plan(m, state, &PlanOpts{
-		Mode: plans.DestroyMode,
+	_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+		Mode:               plans.NormalMode,
+		GenerateConfigPath: "generated.tf",
 	})
-
-	assertNoErrors(t, diags)
-
-	// ensure that the given states are valid and can be serialized
-	if plan.PrevRunState == nil {
-		t.Fatal("nil plan.PrevRunState")
-	}
-	if plan.PriorState == nil {
-		t.Fatal("nil plan.PriorState")
+	if !diags.HasErrors() {
+		t.Fatalf("expected plan to error, but it did not")
 	}
 }
 
-// A deposed instances which no longer exists during ReadResource creates NoOp
-// change, which should not effect the plan.
-func TestContext2Plan_deposedNoLongerExists(t *testing.T) {
+// config generation still succeeds even when planning fails
+func TestContext2Plan_importResourceConfigGenWithError(t *testing.T) {
+	addr := mustResourceInstanceAddr("test_object.a")
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
-resource "test_object" "b" {
-  count = 1
-  test_string = "updated"
-  lifecycle {
-    create_before_destroy = true
-  }
+import {
+  to   = test_object.a
+  id   = "123"
 }
 `,
 	})
 
 	p := simpleMockProvider()
-	p.ReadResourceFn = func(req providers.ReadResourceRequest) (resp providers.ReadResourceResponse) {
-		s := req.PriorState.GetAttr("test_string").AsString()
-		if s == "current" {
-			resp.NewState = req.PriorState
-			return resp
-		}
-		// pretend the non-current instance has been deleted already
-		resp.NewState = cty.NullVal(req.PriorState.Type())
-		return resp
-	}
-
-	// Here we introduce a cycle via state which only shows up in the apply
-	// graph where the actual destroy instances are connected in the graph.
-	// This could happen for example when a user has an existing state with
-	// stored dependencies, and changes the config in such a way that
-	// contradicts the stored dependencies.
-	state := states.NewState()
-	root := state.EnsureModule(addrs.RootModuleInstance)
-	root.SetResourceInstanceDeposed(
-		mustResourceInstanceAddr("test_object.a[0]").Resource,
-		states.DeposedKey("deposed"),
-		&states.ResourceInstanceObjectSrc{
-			Status:       states.ObjectTainted,
-			AttrsJSON:    []byte(`{"test_string":"old"}`),
-			Dependencies: []addrs.ConfigResource{},
-		},
-		mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
-	)
-	root.SetResourceInstanceCurrent(
-		mustResourceInstanceAddr("test_object.a[0]").Resource,
-		&states.ResourceInstanceObjectSrc{
-			Status:       states.ObjectTainted,
-			AttrsJSON:    []byte(`{"test_string":"current"}`),
-			Dependencies: []addrs.ConfigResource{},
-		},
-		mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
-	)
-
 	ctx := testContext2(t, &ContextOpts{
 		Providers: map[addrs.Provider]providers.Factory{
 			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 		},
 	})
+	p.PlanResourceChangeResponse = &providers.PlanResourceChangeResponse{
+		PlannedState: cty.NullVal(cty.DynamicPseudoType),
+		Diagnostics:  tfdiags.Diagnostics(nil).Append(errors.New("plan failed")),
+	}
+	p.ReadResourceResponse = &providers.ReadResourceResponse{
+		NewState: cty.ObjectVal(map[string]cty.Value{
+			"test_string": cty.StringVal("foo"),
+		}),
+	}
+	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
+		ImportedResources: []providers.ImportedResource{
+			{
+				TypeName: "test_object",
+				State: cty.ObjectVal(map[string]cty.Value{
+					"test_string": cty.StringVal("foo"),
+				}),
+			},
+		},
+	}
 
-	_, diags := ctx.Plan(m, state, &PlanOpts{
-		Mode: plans.NormalMode,
+	plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+		Mode:               plans.NormalMode,
+		GenerateConfigPath: "generated.tf", // Actual value here doesn't matter, as long as it is not empty.
 	})
-	assertNoErrors(t, diags)
+	if !diags.HasErrors() {
+		t.Fatal("expected error")
+	}
+
+	instPlan := plan.Changes.ResourceInstance(addr)
+	if instPlan == nil {
+		t.Fatalf("no plan for %s at all", addr)
+	}
+
+	want := `resource "test_object" "a" {
+  test_bool   = null
+  test_list   = null
+  test_map    = null
+  test_number = null
+  test_string = "foo"
+}`
+	got := instPlan.GeneratedConfig
+	if diff := cmp.Diff(want, got); len(diff) > 0 {
+		t.Errorf("got:\n%s\nwant:\n%s\ndiff:\n%s", got, want, diff)
+	}
 }
 
-// make sure there are no cycles with changes around a provider configured via
-// managed resources.
-func TestContext2Plan_destroyWithResourceConfiguredProvider(t *testing.T) {
+func TestContext2Plan_plannedState(t *testing.T) {
+	addr := mustResourceInstanceAddr("test_object.a")
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
 resource "test_object" "a" {
-  in = "a"
-}
-
-provider "test" {
-  alias = "other"
-  in = test_object.a.out
+	test_string = "foo"
 }
 
-resource "test_object" "b" {
-  provider = test.other
-  in = "a"
+locals {
+  local_value = test_object.a.test_string
 }
-`})
-
-	testProvider := &MockProvider{
-		GetProviderSchemaResponse: &providers.GetProviderSchemaResponse{
-			Provider: providers.Schema{
-				Block: &configschema.Block{
-					Attributes: map[string]*configschema.Attribute{
-						"in": {
-							Type:     cty.String,
-							Optional: true,
-						},
-					},
-				},
-			},
-			ResourceTypes: map[string]providers.Schema{
-				"test_object": providers.Schema{
-					Block: &configschema.Block{
-						Attributes: map[string]*configschema.Attribute{
-							"in": {
-								Type:     cty.String,
-								Optional: true,
-							},
-							"out": {
-								Type:     cty.Number,
-								Computed: true,
-							},
-						},
-					},
-				},
-			},
-		},
-	}
+`,
+	})
 
+	p := simpleMockProvider()
 	ctx := testContext2(t, &ContextOpts{
 		Providers: map[addrs.Provider]providers.Factory{
-			addrs.NewDefaultProvider("test"): testProviderFuncFixed(testProvider),
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 		},
 	})
 
-	// plan+apply to create the initial state
-	opts := SimplePlanOpts(plans.NormalMode, testInputValuesUnset(m.Module.Variables))
-	plan, diags := ctx.Plan(m, states.NewState(), opts)
-	assertNoErrors(t, diags)
-	state, diags := ctx.Apply(plan, m)
-	assertNoErrors(t, diags)
-
-	// Resource changes which have dependencies across providers which
-	// themselves depend on resources can result in cycles.
-	// Because other_object transitively depends on the module resources
-	// through its provider, we trigger changes on both sides of this boundary
-	// to ensure we can create a valid plan.
-	//
-	// Try to replace both instances
-	addrA := mustResourceInstanceAddr("test_object.a")
-	addrB := mustResourceInstanceAddr(`test_object.b`)
-	opts.ForceReplace = []addrs.AbsResourceInstance{addrA, addrB}
+	state := states.NewState()
+	plan, diags := ctx.Plan(m, state, nil)
+	if diags.HasErrors() {
+		t.Errorf("expected no errors, but got %s", diags)
+	}
 
-	_, diags = ctx.Plan(m, state, opts)
-	assertNoErrors(t, diags)
-}
+	module := state.RootModule()
 
-func TestContext2Plan_destroyPartialState(t *testing.T) {
-	m := testModuleInline(t, map[string]string{
-		"main.tf": `
-resource "test_object" "a" {
-}
+	// So, the original state shouldn't have been updated at all.
+	if len(module.LocalValues) > 0 {
+		t.Errorf("expected no local values in the state but found %d", len(module.LocalValues))
+	}
 
-output "out" {
-  value = module.mod.out
-}
+	if len(module.Resources) > 0 {
+		t.Errorf("expected no resources in the state but found %d", len(module.LocalValues))
+	}
 
-module "mod" {
-  source = "./mod"
-}
-`,
+	// But, this makes it hard for the testing framework to valid things about
+	// the returned plan. So, the plan contains the planned state:
+	module = plan.PlannedState.RootModule()
 
-		"./mod/main.tf": `
-resource "test_object" "a" {
-  count = 2
+	if module.LocalValues["local_value"].AsString() != "foo" {
+		t.Errorf("expected local value to be \"foo\" but was \"%s\"", module.LocalValues["local_value"].AsString())
+	}
 
-  lifecycle {
-    precondition {
-	  # test_object_b has already been destroyed, so referencing the first
-      # instance must not fail during a destroy plan.
-      condition = test_object.b[0].test_string == "invalid"
-      error_message = "should not block destroy"
-    }
-    precondition {
-      # this failing condition should bot block a destroy plan
-      condition = !local.continue
-      error_message = "should not block destroy"
-    }
-  }
+	if module.ResourceInstance(addr.Resource).Current.Status != states.ObjectPlanned {
+		t.Errorf("expected resource to be in planned state")
+	}
 }
 
-resource "test_object" "b" {
-  count = 2
+func TestContext2Plan_providerConfigFromVariablesAndLocals(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+variable "tenant" {
+  type = string
 }
 
 locals {
-  continue = true
+  endpoints = {
+    acme = "https://acme.example.com"
+  }
 }
 
-output "out" {
-  # the reference to test_object.b[0] may not be valid during a destroy plan,
-  # but should not fail.
-  value = local.continue ? test_object.a[1].test_string != "invalid"  && test_object.b[0].test_string != "invalid" : false
+provider "test" {
+  test_string = local.endpoints[var.tenant]
+}
 
-  precondition {
-    # test_object_b has already been destroyed, so referencing the first
-    # instance must not fail during a destroy plan.
-    condition = test_object.b[0].test_string == "invalid"
-    error_message = "should not block destroy"
-  }
-  precondition {
-    # this failing condition should bot block a destroy plan
-    condition = test_object.a[0].test_string == "invalid"
-    error_message = "should not block destroy"
-  }
+resource "test_object" "a" {
 }
-`})
+`,
+	})
 
 	p := simpleMockProvider()
-
-	// This state could be the result of a failed destroy, leaving only 2
-	// remaining instances. We want to be able to continue the destroy to
-	// remove everything without blocking on invalid references or failing
-	// conditions.
-	state := states.NewState()
-	mod := state.EnsureModule(addrs.RootModuleInstance.Child("mod", addrs.NoKey))
-	mod.SetResourceInstanceCurrent(
-		mustResourceInstanceAddr("test_object.a[0]").Resource,
-		&states.ResourceInstanceObjectSrc{
-			Status:       states.ObjectTainted,
-			AttrsJSON:    []byte(`{"test_string":"current"}`),
-			Dependencies: []addrs.ConfigResource{},
-		},
-		mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
-	)
-	mod.SetResourceInstanceCurrent(
-		mustResourceInstanceAddr("test_object.a[1]").Resource,
-		&states.ResourceInstanceObjectSrc{
-			Status:       states.ObjectTainted,
-			AttrsJSON:    []byte(`{"test_string":"current"}`),
-			Dependencies: []addrs.ConfigResource{},
-		},
-		mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
-	)
+	var got cty.Value
+	p.ConfigureProviderFn = func(req providers.ConfigureProviderRequest) (resp providers.ConfigureProviderResponse) {
+		got = req.Config.GetAttr("test_string")
+		return resp
+	}
 
 	ctx := testContext2(t, &ContextOpts{
 		Providers: map[addrs.Provider]providers.Factory{
@@ -4057,387 +6554,585 @@ output "out" {
 		},
 	})
 
-	_, diags := ctx.Plan(m, state, &PlanOpts{
-		Mode: plans.DestroyMode,
+	_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+		Mode: plans.NormalMode,
+		SetVariables: InputValues{
+			"tenant": &InputValue{
+				Value:      cty.StringVal("acme"),
+				SourceType: ValueFromCLIArg,
+			},
+		},
 	})
 	assertNoErrors(t, diags)
+
+	if want := cty.StringVal("https://acme.example.com"); !got.RawEquals(want) {
+		t.Errorf("wrong provider configuration\ngot:  %#v\nwant: %#v", got, want)
+	}
 }
 
-func TestContext2Plan_destroyPartialStateLocalRef(t *testing.T) {
+func TestContext2Plan_providerConfigUnknown(t *testing.T) {
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
-module "already_destroyed" {
-  count = 1
-  source = "./mod"
+resource "test_object" "a" {
+  test_string = timestamp()
 }
 
-locals {
-  eval_error = module.already_destroyed[0].out
+provider "test" {
+  alias       = "b"
+  test_string = test_object.a.test_string
 }
 
-output "already_destroyed" {
-  value = local.eval_error
+resource "test_object" "b" {
+  provider = test.b
 }
-
 `,
-
-		"./mod/main.tf": `
-resource "test_object" "a" {
-}
-
-output "out" {
-  value = test_object.a.test_string
-}
-`})
+	})
 
 	p := simpleMockProvider()
+	p.ConfigureProviderFn = func(req providers.ConfigureProviderRequest) (resp providers.ConfigureProviderResponse) {
+		if !req.Config.GetAttr("test_string").IsKnown() {
+			resp.Diagnostics = resp.Diagnostics.Append(errors.New("invalid test_string"))
+		}
+		return resp
+	}
 
-	state := states.NewState()
 	ctx := testContext2(t, &ContextOpts{
 		Providers: map[addrs.Provider]providers.Factory{
 			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 		},
 	})
 
-	_, diags := ctx.Plan(m, state, &PlanOpts{
-		Mode: plans.DestroyMode,
-	})
-	assertNoErrors(t, diags)
+	_, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	if !diags.HasErrors() {
+		t.Fatal("expected errors")
+	}
+
+	var found bool
+	for _, diag := range diags {
+		if diag.Severity() != tfdiags.Warning || diag.Description().Summary != "Provider configuration not known during plan" {
+			continue
+		}
+		found = true
+		if !strings.Contains(diag.Description().Detail, `the value of the "test_string" argument is not known yet`) {
+			t.Errorf("wrong detail: %s", diag.Description().Detail)
+		}
+		if subj := diag.Source().Subject; subj == nil || subj.Start.Line != 8 {
+			t.Errorf("wrong subject: %#v", subj)
+		}
+	}
+	if !found {
+		t.Fatalf("missing warning about the unknown provider configuration: %s", diags.ErrWithWarnings())
+	}
 }
 
-// Make sure the data sources in the prior state are serializeable even if
-// there were an error in the plan.
-func TestContext2Plan_dataSourceReadPlanError(t *testing.T) {
-	m, snap := testModuleWithSnapshot(t, "data-source-read-with-plan-error")
-	awsProvider := testProvider("aws")
-	testProvider := testProvider("test")
+func TestContext2Plan_userFunctions(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+function "name_prefix" {
+  parameter "tenant" {
+    type = string
+  }
+  result = "${lower(tenant)}-prod"
+}
 
-	testProvider.PlanResourceChangeFn = func(req providers.PlanResourceChangeRequest) (resp providers.PlanResourceChangeResponse) {
-		resp.PlannedState = req.ProposedNewState
-		resp.Diagnostics = resp.Diagnostics.Append(errors.New("oops"))
-		return resp
-	}
+resource "test_object" "a" {
+  test_string = "${name_prefix("ACME")}-web"
+}
 
-	state := states.NewState()
+module "child" {
+  source = "./child"
+}
+`,
+		"child/main.tf": `
+function "name_prefix" {
+  parameter "tenant" {
+    type = string
+  }
+  result = "child-${tenant}"
+}
+
+resource "test_object" "b" {
+  test_string = name_prefix("acme")
+}
+`,
+	})
 
+	p := simpleMockProvider()
 	ctx := testContext2(t, &ContextOpts{
 		Providers: map[addrs.Provider]providers.Factory{
-			addrs.NewDefaultProvider("aws"):  testProviderFuncFixed(awsProvider),
-			addrs.NewDefaultProvider("test"): testProviderFuncFixed(testProvider),
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 		},
 	})
 
-	plan, diags := ctx.Plan(m, state, DefaultPlanOpts)
-	if !diags.HasErrors() {
-		t.Fatalf("expected plan error")
-	}
+	plan, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	assertNoErrors(t, diags)
 
-	// make sure we can serialize the plan even if there were an error
-	_, _, _, err := contextOptsForPlanViaFile(t, snap, plan)
-	if err != nil {
-		t.Fatalf("failed to round-trip through planfile: %s", err)
+	schema := p.GetProviderSchemaResponse.ResourceTypes["test_object"].Block
+	for addr, want := range map[string]string{
+		"test_object.a":              "acme-prod-web",
+		"module.child.test_object.b": "child-acme",
+	} {
+		rc := plan.Changes.ResourceInstance(mustResourceInstanceAddr(addr))
+		if rc == nil {
+			t.Fatalf("no change for %s", addr)
+		}
+		change, err := rc.Decode(schema.ImpliedType())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := change.After.GetAttr("test_string"); !got.RawEquals(cty.StringVal(want)) {
+			t.Errorf("wrong test_string for %s: %#v; want %q", addr, got, want)
+		}
 	}
 }
 
-func TestContext2Plan_ignoredMarkedValue(t *testing.T) {
+func TestContext2Plan_ephemeralValues(t *testing.T) {
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
-resource "test_object" "a" {
-  map = {
-    prior = "value"
-    new   = sensitive("ignored")
-  }
+variable "token" {
+  type      = string
+  ephemeral = true
 }
-`})
 
-	testProvider := &MockProvider{
-		GetProviderSchemaResponse: &providers.GetProviderSchemaResponse{
-			ResourceTypes: map[string]providers.Schema{
-				"test_object": providers.Schema{
-					Block: &configschema.Block{
-						Attributes: map[string]*configschema.Attribute{
-							"map": {
-								Type:     cty.Map(cty.String),
-								Optional: true,
-							},
-						},
-					},
+locals {
+  header = "Bearer ${var.token}"
+}
+
+module "child" {
+  source = "./child"
+  token  = var.token
+}
+
+resource "test_resource" "a" {
+  name     = "a"
+  password = local.header
+}
+
+resource "test_resource" "b" {
+  name     = "b"
+  password = module.child.token
+}
+`,
+		"child/main.tf": `
+variable "token" {
+  type      = string
+  ephemeral = true
+}
+
+output "token" {
+  value     = var.token
+  ephemeral = true
+}
+`,
+	})
+
+	p := new(MockProvider)
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		ResourceTypes: map[string]*configschema.Block{
+			"test_resource": {
+				Attributes: map[string]*configschema.Attribute{
+					"name":     {Type: cty.String, Required: true},
+					"password": {Type: cty.String, Optional: true, Ephemeral: true},
 				},
 			},
 		},
+	})
+	var gotPasswords []string
+	p.PlanResourceChangeFn = func(req providers.PlanResourceChangeRequest) providers.PlanResourceChangeResponse {
+		gotPasswords = append(gotPasswords, req.Config.GetAttr("password").AsString())
+		return providers.PlanResourceChangeResponse{
+			PlannedState: req.ProposedNewState,
+		}
 	}
 
-	testProvider.PlanResourceChangeFn = func(req providers.PlanResourceChangeRequest) (resp providers.PlanResourceChangeResponse) {
-		// We're going to ignore any changes here and return the prior state.
-		resp.PlannedState = req.PriorState
-		return resp
-	}
-
-	state := states.NewState()
-	root := state.RootModule()
-	root.SetResourceInstanceCurrent(
-		mustResourceInstanceAddr("test_object.a").Resource,
-		&states.ResourceInstanceObjectSrc{
-			Status:       states.ObjectReady,
-			AttrsJSON:    []byte(`{"map":{"prior":"value"}}`),
-			Dependencies: []addrs.ConfigResource{},
-		},
-		mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
-	)
 	ctx := testContext2(t, &ContextOpts{
 		Providers: map[addrs.Provider]providers.Factory{
-			addrs.NewDefaultProvider("test"): testProviderFuncFixed(testProvider),
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 		},
 	})
 
-	// plan+apply to create the initial state
-	opts := SimplePlanOpts(plans.NormalMode, testInputValuesUnset(m.Module.Variables))
-	plan, diags := ctx.Plan(m, state, opts)
+	plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+		Mode: plans.NormalMode,
+		SetVariables: InputValues{
+			"token": &InputValue{
+				Value:      cty.StringVal("secret"),
+				SourceType: ValueFromCLIArg,
+			},
+		},
+	})
 	assertNoErrors(t, diags)
 
-	for _, c := range plan.Changes.Resources {
-		if c.Action != plans.NoOp {
-			t.Errorf("unexpected %s change for %s", c.Action, c.Addr)
+	// The provider must still receive the ephemeral values.
+	sort.Strings(gotPasswords)
+	if diff := cmp.Diff([]string{"Bearer secret", "secret"}, gotPasswords); diff != "" {
+		t.Errorf("wrong passwords in provider requests\n%s", diff)
+	}
+
+	if _, ok := plan.VariableValues["token"]; ok {
+		t.Errorf("ephemeral variable value was saved in the plan")
+	}
+	if got, want := plan.EphemeralVariableValues["token"], cty.StringVal("secret"); !got.RawEquals(want) {
+		t.Errorf("wrong ephemeral variable value\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	schema := p.GetProviderSchemaResponse.ResourceTypes["test_resource"].Block
+	for _, name := range []string{"test_resource.a", "test_resource.b"} {
+		addr := mustResourceInstanceAddr(name)
+		change, err := plan.Changes.ResourceInstance(addr).Decode(schema.ImpliedType())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := change.After.GetAttr("password"); !got.IsNull() {
+			t.Errorf("ephemeral attribute of %s was saved in the plan: %#v", name, got)
+		}
+	}
+
+	state, diags := ctx.Apply(plan, m)
+	assertNoErrors(t, diags)
+
+	for _, name := range []string{"test_resource.a", "test_resource.b"} {
+		rs := state.ResourceInstance(mustResourceInstanceAddr(name))
+		if rs == nil || rs.Current == nil {
+			t.Fatalf("no state for %s", name)
+		}
+		if got := string(rs.Current.AttrsJSON); strings.Contains(got, "secret") {
+			t.Errorf("ephemeral value of %s was saved in the state: %s", name, got)
 		}
 	}
 }
 
-func TestContext2Plan_importResourceBasic(t *testing.T) {
-	addr := mustResourceInstanceAddr("test_object.a")
+func TestContext2Plan_deferredExpansion(t *testing.T) {
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
 resource "test_object" "a" {
-  test_string = "foo"
 }
 
-import {
-  to   = test_object.a
-  id   = "123"
+resource "test_object" "b" {
+  for_each = toset([test_object.a.id])
+
+  test_string = each.key
+}
+
+resource "test_object" "c" {
+  test_string = test_object.b[test_object.a.id].test_string
+}
+
+resource "test_object" "d" {
+  test_string = "d"
+}
+
+output "c" {
+  value = test_object.c.test_string
 }
 `,
 	})
 
-	p := simpleMockProvider()
-	hook := new(MockHook)
+	p := new(MockProvider)
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		ResourceTypes: map[string]*configschema.Block{
+			"test_object": {
+				Attributes: map[string]*configschema.Attribute{
+					"id": {
+						Type:     cty.String,
+						Computed: true,
+					},
+					"test_string": {
+						Type:     cty.String,
+						Optional: true,
+					},
+				},
+			},
+		},
+	})
 	ctx := testContext2(t, &ContextOpts{
-		Hooks: []Hook{hook},
 		Providers: map[addrs.Provider]providers.Factory{
 			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 		},
 	})
-	p.ReadResourceResponse = &providers.ReadResourceResponse{
-		NewState: cty.ObjectVal(map[string]cty.Value{
-			"test_string": cty.StringVal("foo"),
-		}),
-	}
-	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
-		ImportedResources: []providers.ImportedResource{
-			{
-				TypeName: "test_object",
-				State: cty.ObjectVal(map[string]cty.Value{
-					"test_string": cty.StringVal("foo"),
-				}),
-			},
-		},
-	}
 
-	plan, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
-	if diags.HasErrors() {
-		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
+	// Without deferral the unknown for_each value is an error, as before.
+	_, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	if !diags.HasErrors() {
+		t.Fatal("succeeded; want error")
+	}
+	if got, want := diags.Err().Error(), "Invalid for_each argument"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error\ngot:  %s\nwant: message containing %q", got, want)
 	}
 
-	t.Run(addr.String(), func(t *testing.T) {
-		instPlan := plan.Changes.ResourceInstance(addr)
-		if instPlan == nil {
-			t.Fatalf("no plan for %s at all", addr)
-		}
+	plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+		Mode:            plans.NormalMode,
+		DeferralAllowed: true,
+	})
+	assertNoErrors(t, diags)
 
-		if got, want := instPlan.Addr, addr; !got.Equal(want) {
-			t.Errorf("wrong current address\ngot:  %s\nwant: %s", got, want)
-		}
-		if got, want := instPlan.PrevRunAddr, addr; !got.Equal(want) {
-			t.Errorf("wrong previous run address\ngot:  %s\nwant: %s", got, want)
-		}
-		if got, want := instPlan.Action, plans.NoOp; got != want {
-			t.Errorf("wrong planned action\ngot:  %s\nwant: %s", got, want)
-		}
-		if got, want := instPlan.ActionReason, plans.ResourceInstanceChangeNoReason; got != want {
-			t.Errorf("wrong action reason\ngot:  %s\nwant: %s", got, want)
-		}
-		if instPlan.Importing.ID != "123" {
-			t.Errorf("expected import change from \"123\", got non-import change")
+	var gotDeferred []string
+	for _, addr := range plan.DeferredResources {
+		gotDeferred = append(gotDeferred, addr.String())
+	}
+	if diff := cmp.Diff([]string{"test_object.b"}, gotDeferred); diff != "" {
+		t.Fatalf("wrong deferred resources\n%s", diff)
+	}
+	var gotDeferredInstances []string
+	for _, addr := range plan.DeferredResourceInstances {
+		gotDeferredInstances = append(gotDeferredInstances, addr.String())
+	}
+	if diff := cmp.Diff([]string{"test_object.c"}, gotDeferredInstances); diff != "" {
+		t.Fatalf("wrong deferred resource instances\n%s", diff)
+	}
+	for _, rc := range plan.Changes.Resources {
+		switch addr := rc.Addr.String(); addr {
+		case "test_object.a", "test_object.d":
+			if rc.Action != plans.Create {
+				t.Errorf("wrong action for %s: %s", addr, rc.Action)
+			}
+		default:
+			t.Errorf("unexpected change for %s", addr)
 		}
+	}
 
-		if !hook.PrePlanImportCalled {
-			t.Fatalf("PostPlanImport hook not called")
-		}
-		if addr, wantAddr := hook.PrePlanImportAddr, instPlan.Addr; !addr.Equal(wantAddr) {
-			t.Errorf("expected addr to be %s, but was %s", wantAddr, addr)
-		}
+	state, diags := ctx.Apply(plan, m)
+	assertNoErrors(t, diags)
+	// The output refers to a deferred resource, so it has no value yet.
+	if got := state.RootModule().OutputValues["c"].Value; !got.IsNull() {
+		t.Errorf("wrong value for output c: %#v", got)
+	}
+	if rs := state.Resource(mustAbsResourceAddr("test_object.b")); rs != nil {
+		t.Fatalf("deferred resource test_object.b was created")
+	}
 
-		if !hook.PostPlanImportCalled {
-			t.Fatalf("PostPlanImport hook not called")
+	// The next plan can now determine the instances of the deferred
+	// resources.
+	plan, diags = ctx.Plan(m, state, &PlanOpts{
+		Mode:            plans.NormalMode,
+		DeferralAllowed: true,
+	})
+	assertNoErrors(t, diags)
+	if len(plan.DeferredResources) != 0 || len(plan.DeferredResourceInstances) != 0 {
+		t.Fatalf("unexpected deferred resources: %s %s", plan.DeferredResources, plan.DeferredResourceInstances)
+	}
+	for _, name := range []string{"test_object.c"} {
+		rc := plan.Changes.ResourceInstance(mustResourceInstanceAddr(name))
+		if rc == nil || rc.Action != plans.Create {
+			t.Errorf("missing create change for %s", name)
 		}
-		if addr, wantAddr := hook.PostPlanImportAddr, instPlan.Addr; !addr.Equal(wantAddr) {
-			t.Errorf("expected addr to be %s, but was %s", wantAddr, addr)
+	}
+	var created int
+	for _, rc := range plan.Changes.Resources {
+		if rc.Addr.ContainingResource().String() == "test_object.b" && rc.Action == plans.Create {
+			created++
 		}
-	})
+	}
+	if created != 1 {
+		t.Errorf("wrong number of test_object.b instances to create: %d", created)
+	}
 }
 
-func TestContext2Plan_importResourceAlreadyInState(t *testing.T) {
-	addr := mustResourceInstanceAddr("test_object.a")
+func TestContext2Plan_refreshBatch(t *testing.T) {
+	// The objects form a chain of dependencies, but reading them depends
+	// only on the prior state, so they should all be read at once.
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
 resource "test_object" "a" {
-  test_string = "foo"
 }
 
-import {
-  to   = test_object.a
-  id   = "123"
+resource "test_object" "b" {
+  test_string = test_object.a.id
+}
+
+resource "test_object" "c" {
+  test_string = test_object.b.id
 }
 `,
 	})
 
-	p := simpleMockProvider()
-	ctx := testContext2(t, &ContextOpts{
-		Providers: map[addrs.Provider]providers.Factory{
-			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
-		},
+	state := states.BuildState(func(s *states.SyncState) {
+		for name, attrs := range map[string]string{
+			"a": `{"id":"a"}`,
+			"b": `{"id":"b","test_string":"a"}`,
+			"c": `{"id":"c","test_string":"b"}`,
+		} {
+			s.SetResourceInstanceCurrent(mustResourceInstanceAddr("test_object."+name), &states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(attrs),
+				Status:    states.ObjectReady,
+			}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
+		}
 	})
-	p.ReadResourceResponse = &providers.ReadResourceResponse{
-		NewState: cty.ObjectVal(map[string]cty.Value{
-			"test_string": cty.StringVal("foo"),
-		}),
-	}
-	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
-		ImportedResources: []providers.ImportedResource{
-			{
-				TypeName: "test_object",
-				State: cty.ObjectVal(map[string]cty.Value{
-					"test_string": cty.StringVal("foo"),
-				}),
+
+	newProvider := func(wait bool) *refreshBatchTestProvider {
+		p := &refreshBatchTestProvider{
+			MockProvider: new(MockProvider),
+			wait:         wait,
+			allStarted:   make(chan struct{}),
+		}
+		p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+			ResourceTypes: map[string]*configschema.Block{
+				"test_object": {
+					Attributes: map[string]*configschema.Attribute{
+						"id": {
+							Type:     cty.String,
+							Computed: true,
+						},
+						"test_string": {
+							Type:     cty.String,
+							Optional: true,
+						},
+					},
+				},
 			},
-		},
+		})
+		return p
 	}
 
-	state := states.NewState()
-	root := state.EnsureModule(addrs.RootModuleInstance)
-	root.SetResourceInstanceCurrent(
-		mustResourceInstanceAddr("test_object.a").Resource,
-		&states.ResourceInstanceObjectSrc{
-			Status:    states.ObjectReady,
-			AttrsJSON: []byte(`{"test_string":"foo"}`),
-		},
-		mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
-	)
+	t.Run("default", func(t *testing.T) {
+		p := newProvider(true)
+		hook := new(MockHook)
+		ctx := testContext2(t, &ContextOpts{
+			Hooks: []Hook{hook},
+			Providers: map[addrs.Provider]providers.Factory{
+				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+			},
+		})
 
-	plan, diags := ctx.Plan(m, state, DefaultPlanOpts)
-	if diags.HasErrors() {
-		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
-	}
+		plan, diags := ctx.Plan(m, state, DefaultPlanOpts)
+		assertNoErrors(t, diags)
 
-	t.Run(addr.String(), func(t *testing.T) {
-		instPlan := plan.Changes.ResourceInstance(addr)
-		if instPlan == nil {
-			t.Fatalf("no plan for %s at all", addr)
+		if got := p.maxInFlight; got != 3 {
+			t.Errorf("wrong number of concurrent reads %d; want 3", got)
+		}
+		for _, rc := range plan.Changes.Resources {
+			if rc.Action != plans.NoOp {
+				t.Errorf("wrong action for %s: %s", rc.Addr, rc.Action)
+			}
 		}
 
-		if got, want := instPlan.Addr, addr; !got.Equal(want) {
-			t.Errorf("wrong current address\ngot:  %s\nwant: %s", got, want)
+		if !hook.PreRefreshBatchCalled || !hook.PostRefreshBatchCalled {
+			t.Fatal("refresh batch hooks not called")
 		}
-		if got, want := instPlan.PrevRunAddr, addr; !got.Equal(want) {
-			t.Errorf("wrong previous run address\ngot:  %s\nwant: %s", got, want)
+		var gotAddrs []string
+		for _, addr := range hook.PreRefreshBatchAddrs {
+			gotAddrs = append(gotAddrs, addr.String())
 		}
-		if got, want := instPlan.Action, plans.NoOp; got != want {
-			t.Errorf("wrong planned action\ngot:  %s\nwant: %s", got, want)
+		sort.Strings(gotAddrs)
+		if diff := cmp.Diff([]string{"test_object.a", "test_object.b", "test_object.c"}, gotAddrs); diff != "" {
+			t.Errorf("wrong batch\n%s", diff)
 		}
-		if got, want := instPlan.ActionReason, plans.ResourceInstanceChangeNoReason; got != want {
-			t.Errorf("wrong action reason\ngot:  %s\nwant: %s", got, want)
+	})
+
+	t.Run("limited", func(t *testing.T) {
+		p := newProvider(false)
+		ctx := testContext2(t, &ContextOpts{
+			Providers: map[addrs.Provider]providers.Factory{
+				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+			},
+			RefreshParallelism: RefreshParallelism{
+				Providers: map[addrs.Provider]int{
+					addrs.NewDefaultProvider("test"): 1,
+				},
+			},
+		})
+
+		_, diags := ctx.Plan(m, state, DefaultPlanOpts)
+		assertNoErrors(t, diags)
+
+		if got := p.maxInFlight; got != 1 {
+			t.Errorf("wrong number of concurrent reads %d; want 1", got)
 		}
-		if instPlan.Importing != nil {
-			t.Errorf("expected non-import change, got import change %+v", instPlan.Importing)
+		if got := p.started; got != 3 {
+			t.Errorf("wrong number of reads %d; want 3", got)
 		}
 	})
-}
 
-func TestContext2Plan_importResourceUpdate(t *testing.T) {
-	addr := mustResourceInstanceAddr("test_object.a")
-	m := testModuleInline(t, map[string]string{
-		"main.tf": `
-resource "test_object" "a" {
-  test_string = "bar"
+	t.Run("skip refresh", func(t *testing.T) {
+		p := newProvider(false)
+		hook := new(MockHook)
+		ctx := testContext2(t, &ContextOpts{
+			Hooks: []Hook{hook},
+			Providers: map[addrs.Provider]providers.Factory{
+				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+			},
+		})
+
+		_, diags := ctx.Plan(m, state, &PlanOpts{
+			Mode:        plans.NormalMode,
+			SkipRefresh: true,
+		})
+		assertNoErrors(t, diags)
+
+		if got := p.started; got != 0 {
+			t.Errorf("wrong number of reads %d; want 0", got)
+		}
+		if hook.PreRefreshBatchCalled {
+			t.Error("PreRefreshBatch called; want no batch")
+		}
+	})
 }
 
-import {
-  to   = test_object.a
-  id   = "123"
+// refreshBatchTestProvider is a MockProvider that counts how many of the
+// three objects in TestContext2Plan_refreshBatch are read at once, which
+// MockProvider itself can't do because it handles one call at a time.
+type refreshBatchTestProvider struct {
+	*MockProvider
+
+	// If wait is set, each read waits until all three reads have started.
+	wait       bool
+	allStarted chan struct{}
+
+	mu                             sync.Mutex
+	started, inFlight, maxInFlight int
 }
-`,
-	})
 
-	p := simpleMockProvider()
-	ctx := testContext2(t, &ContextOpts{
-		Providers: map[addrs.Provider]providers.Factory{
-			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
-		},
-	})
-	p.ReadResourceResponse = &providers.ReadResourceResponse{
-		NewState: cty.ObjectVal(map[string]cty.Value{
-			"test_string": cty.StringVal("foo"),
-		}),
+func (p *refreshBatchTestProvider) ReadResource(req providers.ReadResourceRequest) providers.ReadResourceResponse {
+	p.mu.Lock()
+	p.started++
+	p.inFlight++
+	if p.inFlight > p.maxInFlight {
+		p.maxInFlight = p.inFlight
 	}
-	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
-		ImportedResources: []providers.ImportedResource{
-			{
-				TypeName: "test_object",
-				State: cty.ObjectVal(map[string]cty.Value{
-					"test_string": cty.StringVal("foo"),
-				}),
-			},
-		},
+	if p.started == 3 {
+		close(p.allStarted)
 	}
+	p.mu.Unlock()
 
-	plan, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
-	if diags.HasErrors() {
-		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
+	if p.wait {
+		// Give up after a while, so that the test fails rather than hangs.
+		select {
+		case <-p.allStarted:
+		case <-time.After(5 * time.Second):
+		}
 	}
 
-	t.Run(addr.String(), func(t *testing.T) {
-		instPlan := plan.Changes.ResourceInstance(addr)
-		if instPlan == nil {
-			t.Fatalf("no plan for %s at all", addr)
-		}
+	resp := p.MockProvider.ReadResource(req)
 
-		if got, want := instPlan.Addr, addr; !got.Equal(want) {
-			t.Errorf("wrong current address\ngot:  %s\nwant: %s", got, want)
-		}
-		if got, want := instPlan.PrevRunAddr, addr; !got.Equal(want) {
-			t.Errorf("wrong previous run address\ngot:  %s\nwant: %s", got, want)
-		}
-		if got, want := instPlan.Action, plans.Update; got != want {
-			t.Errorf("wrong planned action\ngot:  %s\nwant: %s", got, want)
-		}
-		if got, want := instPlan.ActionReason, plans.ResourceInstanceChangeNoReason; got != want {
-			t.Errorf("wrong action reason\ngot:  %s\nwant: %s", got, want)
-		}
-		if instPlan.Importing.ID != "123" {
-			t.Errorf("expected import change from \"123\", got non-import change")
-		}
-	})
+	p.mu.Lock()
+	p.inFlight--
+	p.mu.Unlock()
+	return resp
 }
 
-func TestContext2Plan_importResourceReplace(t *testing.T) {
-	addr := mustResourceInstanceAddr("test_object.a")
+func TestContext2Plan_stage(t *testing.T) {
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
-resource "test_object" "a" {
-  test_string = "bar"
+resource "test_object" "base" {
+  test_string = "v1"
 }
 
-import {
-  to   = test_object.a
-  id   = "123"
+module "app" {
+  source = "./app"
+  in     = test_object.base.test_string
+}
+
+module "other" {
+  source = "./app"
+  in     = "other"
+}
+`,
+		"app/main.tf": `
+variable "in" {
+  type = string
+}
+
+resource "test_object" "x" {
+  test_string = var.in
 }
 `,
 	})
@@ -4448,64 +7143,108 @@ import {
 			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 		},
 	})
-	p.ReadResourceResponse = &providers.ReadResourceResponse{
-		NewState: cty.ObjectVal(map[string]cty.Value{
-			"test_string": cty.StringVal("foo"),
-		}),
-	}
-	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
-		ImportedResources: []providers.ImportedResource{
-			{
-				TypeName: "test_object",
-				State: cty.ObjectVal(map[string]cty.Value{
-					"test_string": cty.StringVal("foo"),
-				}),
-			},
-		},
-	}
+	stage := addrs.RootModule.Child("app")
 
-	plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
-		Mode: plans.NormalMode,
-		ForceReplace: []addrs.AbsResourceInstance{
-			addr,
-		},
+	t.Run("dependency not applied", func(t *testing.T) {
+		_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+			Mode:  plans.NormalMode,
+			Stage: stage,
+		})
+		if !diags.HasErrors() {
+			t.Fatal("succeeded; want error")
+		}
+		if got, want := diags.Err().Error(), "Stage includes changes outside of it"; !strings.Contains(got, want) {
+			t.Fatalf("wrong error\ngot:  %s\nwant: message containing %q", got, want)
+		}
+		if got, want := diags.Err().Error(), "test_object.base (Create)"; !strings.Contains(got, want) {
+			t.Fatalf("wrong error\ngot:  %s\nwant: message containing %q", got, want)
+		}
 	})
-	if diags.HasErrors() {
-		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
-	}
 
-	t.Run(addr.String(), func(t *testing.T) {
-		instPlan := plan.Changes.ResourceInstance(addr)
-		if instPlan == nil {
-			t.Fatalf("no plan for %s at all", addr)
+	t.Run("dependency applied", func(t *testing.T) {
+		state := states.BuildState(func(s *states.SyncState) {
+			s.SetResourceInstanceCurrent(mustResourceInstanceAddr("test_object.base"), &states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"test_string":"v1"}`),
+				Status:    states.ObjectReady,
+			}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
+		})
+
+		plan, diags := ctx.Plan(m, state, &PlanOpts{
+			Mode:  plans.NormalMode,
+			Stage: stage,
+		})
+		assertNoErrors(t, diags)
+
+		if !plan.Stage.Equal(stage) {
+			t.Errorf("wrong stage in plan %s; want %s", plan.Stage, stage)
+		}
+		if len(plan.TargetAddrs) != 0 {
+			t.Errorf("unexpected targets in plan: %s", plan.TargetAddrs)
+		}
+		for _, rc := range plan.Changes.Resources {
+			want := plans.NoOp
+			if rc.Addr.Equal(mustResourceInstanceAddr("module.app.test_object.x")) {
+				want = plans.Create
+			}
+			if rc.Action != want {
+				t.Errorf("wrong action for %s: %s; want %s", rc.Addr, rc.Action, want)
+			}
+		}
+		if rc := plan.Changes.ResourceInstance(mustResourceInstanceAddr("module.other.test_object.x")); rc != nil {
+			t.Errorf("unexpected change for %s outside of the stage", rc.Addr)
 		}
 
-		if got, want := instPlan.Addr, addr; !got.Equal(want) {
-			t.Errorf("wrong current address\ngot:  %s\nwant: %s", got, want)
+		newState, diags := ctx.Apply(plan, m)
+		assertNoErrors(t, diags)
+		if newState.ResourceInstance(mustResourceInstanceAddr("module.app.test_object.x")) == nil {
+			t.Errorf("module.app.test_object.x was not created")
 		}
-		if got, want := instPlan.PrevRunAddr, addr; !got.Equal(want) {
-			t.Errorf("wrong previous run address\ngot:  %s\nwant: %s", got, want)
+		if newState.ResourceInstance(mustResourceInstanceAddr("module.other.test_object.x")) != nil {
+			t.Errorf("module.other.test_object.x was created outside of the stage")
 		}
-		if got, want := instPlan.Action, plans.DeleteThenCreate; got != want {
-			t.Errorf("wrong planned action\ngot:  %s\nwant: %s", got, want)
+	})
+
+	t.Run("invalid stage", func(t *testing.T) {
+		_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+			Mode:  plans.NormalMode,
+			Stage: addrs.RootModule.Child("nope"),
+		})
+		if !diags.HasErrors() {
+			t.Fatal("succeeded; want error")
 		}
-		if instPlan.Importing.ID != "123" {
-			t.Errorf("expected import change from \"123\", got non-import change")
+		if got, want := diags.Err().Error(), "Invalid stage"; !strings.Contains(got, want) {
+			t.Fatalf("wrong error\ngot:  %s\nwant: message containing %q", got, want)
 		}
 	})
 }
 
-func TestContext2Plan_importRefreshOnce(t *testing.T) {
-	addr := mustResourceInstanceAddr("test_object.a")
+func TestContext2Plan_exclude(t *testing.T) {
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
 resource "test_object" "a" {
-  test_string = "bar"
+  count       = 2
+  test_string = "a${count.index}"
 }
 
-import {
-  to   = test_object.a
-  id   = "123"
+resource "test_object" "b" {
+  test_string = test_object.a[0].test_string
+}
+
+resource "test_object" "c" {
+  test_string = "c"
+}
+
+module "app" {
+  source = "./app"
+}
+
+output "b" {
+  value = test_object.b.test_string
+}
+`,
+		"app/main.tf": `
+resource "test_object" "x" {
+  test_string = "x"
 }
 `,
 	})
@@ -4517,409 +7256,620 @@ import {
 		},
 	})
 
-	readCalled := 0
-	p.ReadResourceFn = func(req providers.ReadResourceRequest) providers.ReadResourceResponse {
-		readCalled++
-		state, _ := simpleTestSchema().CoerceValue(cty.ObjectVal(map[string]cty.Value{
-			"test_string": cty.StringVal("foo"),
-		}))
-
-		return providers.ReadResourceResponse{
-			NewState: state,
+	mustGlob := func(str string) addrs.Targetable {
+		glob, diags := addrs.ParseTargetGlob(str)
+		if diags.HasErrors() {
+			t.Fatal(diags.Err())
 		}
+		return glob
 	}
 
-	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
-		ImportedResources: []providers.ImportedResource{
-			{
-				TypeName: "test_object",
-				State: cty.ObjectVal(map[string]cty.Value{
-					"test_string": cty.StringVal("foo"),
-				}),
-			},
+	tests := map[string]struct {
+		excludes    []addrs.Targetable
+		wantChanges []string
+		wantOutput  bool
+	}{
+		"resource and its dependents": {
+			excludes:    []addrs.Targetable{mustResourceInstanceAddr("test_object.a").ContainingResource()},
+			wantChanges: []string{"module.app.test_object.x", "test_object.c"},
 		},
-	}
-
-	_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
-		Mode: plans.NormalMode,
-		ForceReplace: []addrs.AbsResourceInstance{
-			addr,
+		"module": {
+			excludes:    []addrs.Targetable{addrs.RootModuleInstance.Child("app", addrs.NoKey)},
+			wantChanges: []string{"test_object.a[0]", "test_object.a[1]", "test_object.b", "test_object.c"},
+			wantOutput:  true,
+		},
+		"glob": {
+			excludes:    []addrs.Targetable{mustGlob("test_object.*")},
+			wantChanges: []string{"module.app.test_object.x"},
+		},
+		"resource instance": {
+			excludes:    []addrs.Targetable{mustResourceInstanceAddr("test_object.a[1]")},
+			wantChanges: []string{"module.app.test_object.x", "test_object.a[0]", "test_object.c"},
 		},
-	})
-	if diags.HasErrors() {
-		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
 	}
 
-	if readCalled > 1 {
-		t.Error("ReadResource called multiple times for import")
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+				Mode:     plans.NormalMode,
+				Excludes: test.excludes,
+			})
+			assertNoErrors(t, diags)
+
+			var warned bool
+			for _, diag := range diags {
+				if diag.Description().Summary == "Resource exclusion is in effect" {
+					warned = true
+				}
+			}
+			if !warned {
+				t.Errorf("missing exclusion warning")
+			}
+
+			var gotChanges []string
+			for _, rc := range plan.Changes.Resources {
+				if rc.Action != plans.Create {
+					t.Errorf("wrong action for %s: %s; want %s", rc.Addr, rc.Action, plans.Create)
+				}
+				gotChanges = append(gotChanges, rc.Addr.String())
+			}
+			sort.Strings(gotChanges)
+			if diff := cmp.Diff(test.wantChanges, gotChanges); diff != "" {
+				t.Errorf("wrong changes\n%s", diff)
+			}
+
+			gotOutput := plan.Changes.OutputValue(addrs.OutputValue{Name: "b"}.Absolute(addrs.RootModuleInstance)) != nil
+			if gotOutput != test.wantOutput {
+				t.Errorf("wrong output change presence %t; want %t", gotOutput, test.wantOutput)
+			}
+		})
 	}
+
+	t.Run("with targets", func(t *testing.T) {
+		_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+			Mode:     plans.NormalMode,
+			Targets:  []addrs.Targetable{mustResourceInstanceAddr("test_object.c")},
+			Excludes: []addrs.Targetable{mustResourceInstanceAddr("test_object.b")},
+		})
+		if !diags.HasErrors() {
+			t.Fatal("succeeded; want error")
+		}
+		if got, want := diags.Err().Error(), "Incompatible plan options"; !strings.Contains(got, want) {
+			t.Fatalf("wrong error\ngot:  %s\nwant: message containing %q", got, want)
+		}
+	})
 }
 
-func TestContext2Plan_importTargetWithKeyDoesNotExist(t *testing.T) {
+func TestContext2Plan_changeCauses(t *testing.T) {
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
-resource "test_object" "a" {
-  count = 1
-  test_string = "bar"
+resource "test_object" "upstream" {
+  value = "new"
 }
 
-import {
-  to   = test_object.a[42]
-  id   = "123"
+resource "test_object" "a" {
+  value = test_object.upstream.value
 }
-`,
-	})
 
-	p := simpleMockProvider()
-	ctx := testContext2(t, &ContextOpts{
-		Providers: map[addrs.Provider]providers.Factory{
-			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
-		},
-	})
-	p.ReadResourceResponse = &providers.ReadResourceResponse{
-		NewState: cty.ObjectVal(map[string]cty.Value{
-			"test_string": cty.StringVal("foo"),
-		}),
-	}
-	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
-		ImportedResources: []providers.ImportedResource{
-			{
-				TypeName: "test_object",
-				State: cty.ObjectVal(map[string]cty.Value{
-					"test_string": cty.StringVal("foo"),
-				}),
-			},
-		},
-	}
+resource "test_object" "b" {
+  value = "changed"
+}
 
-	_, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
-	if !diags.HasErrors() {
-		t.Fatalf("expected error but got none")
-	}
+resource "test_object" "c" {
+  value = "configured"
 }
 
-func TestContext2Plan_importIdVariable(t *testing.T) {
-	p := testProvider("aws")
-	m := testModule(t, "import-id-variable")
-	ctx := testContext2(t, &ContextOpts{
-		Providers: map[addrs.Provider]providers.Factory{
-			addrs.NewDefaultProvider("aws"): testProviderFuncFixed(p),
-		},
+resource "test_object" "d" {
+  value = "new"
+}
+`,
 	})
 
-	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
-		ImportedResources: []providers.ImportedResource{
-			{
-				TypeName: "aws_instance",
-				State: cty.ObjectVal(map[string]cty.Value{
-					"id": cty.StringVal("foo"),
-				}),
-			},
-		},
-	}
-
-	_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
-		SetVariables: InputValues{
-			"the_id": &InputValue{
-				// let var take its default value
-				Value: cty.NilVal,
+	p := testProvider("test")
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		ResourceTypes: map[string]*configschema.Block{
+			"test_object": {
+				Attributes: map[string]*configschema.Attribute{
+					"id":    {Type: cty.String, Computed: true},
+					"value": {Type: cty.String, Optional: true},
+				},
 			},
 		},
 	})
-	if diags.HasErrors() {
-		t.Fatalf("unexpected errors: %s", diags.Err())
+	p.ReadResourceFn = func(req providers.ReadResourceRequest) (resp providers.ReadResourceResponse) {
+		resp.NewState = req.PriorState
+		if req.PriorState.GetAttr("id").AsString() == "c" {
+			// The remote object for test_object.c was changed outside of
+			// OpenTofu.
+			resp.NewState = cty.ObjectVal(map[string]cty.Value{
+				"id":    cty.StringVal("c"),
+				"value": cty.StringVal("drifted"),
+			})
+		}
+		return resp
+	}
+	p.PlanResourceChangeFn = func(req providers.PlanResourceChangeRequest) (resp providers.PlanResourceChangeResponse) {
+		planned := req.ProposedNewState.AsValueMap()
+		switch {
+		case req.PriorState.IsNull():
+			planned["id"] = cty.UnknownVal(cty.String)
+		case req.PriorState.GetAttr("id").AsString() == "d" && !req.PriorState.GetAttr("value").RawEquals(planned["value"]):
+			resp.RequiresReplace = []cty.Path{cty.GetAttrPath("value")}
+		}
+		resp.PlannedState = cty.ObjectVal(planned)
+		return resp
 	}
-}
 
-func TestContext2Plan_importIdFunc(t *testing.T) {
-	p := testProvider("aws")
-	m := testModule(t, "import-id-func")
+	state := states.BuildState(func(s *states.SyncState) {
+		for name, value := range map[string]string{
+			"upstream": "old",
+			"a":        "old",
+			"b":        "orig",
+			"c":        "configured",
+			"d":        "old",
+		} {
+			s.SetResourceInstanceCurrent(mustResourceInstanceAddr("test_object."+name), &states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(fmt.Sprintf(`{"id":%q,"value":%q}`, name, value)),
+				Status:    states.ObjectReady,
+			}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
+		}
+	})
+
 	ctx := testContext2(t, &ContextOpts{
 		Providers: map[addrs.Provider]providers.Factory{
-			addrs.NewDefaultProvider("aws"): testProviderFuncFixed(p),
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 		},
 	})
+	plan, diags := ctx.Plan(m, state, DefaultPlanOpts)
+	assertNoErrors(t, diags)
 
-	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
-		ImportedResources: []providers.ImportedResource{
-			{
-				TypeName: "aws_instance",
-				State: cty.ObjectVal(map[string]cty.Value{
-					"id": cty.StringVal("foo"),
-				}),
-			},
+	upstream := mustResourceInstanceAddr("test_object.upstream").ContainingResource()
+	want := map[string][]plans.AttributeChangeCause{
+		"test_object.upstream": {
+			{Attribute: "value", Cause: plans.ChangeCauseConfig, Filename: "main.tf", Line: 3},
+		},
+		"test_object.a": {
+			{Attribute: "value", Cause: plans.ChangeCauseUpstream, Filename: "main.tf", Line: 7, Upstream: []addrs.AbsResource{upstream}},
+		},
+		"test_object.b": {
+			{Attribute: "value", Cause: plans.ChangeCauseConfig, Filename: "main.tf", Line: 11},
+		},
+		"test_object.c": {
+			{Attribute: "value", Cause: plans.ChangeCauseDrift},
+		},
+		"test_object.d": {
+			{Attribute: "id", Cause: plans.ChangeCauseReplacement},
+			{Attribute: "value", Cause: plans.ChangeCauseConfig, Filename: "main.tf", Line: 19},
 		},
 	}
 
-	_, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
-	if diags.HasErrors() {
-		t.Fatalf("unexpected errors: %s", diags.Err())
+	for _, rc := range plan.Changes.Resources {
+		got := rc.ChangeCauses
+		for i := range got {
+			if got[i].Filename != "" {
+				got[i].Filename = filepath.Base(got[i].Filename)
+			}
+		}
+		if diff := cmp.Diff(want[rc.Addr.String()], got); diff != "" {
+			t.Errorf("wrong change causes for %s\n%s", rc.Addr, diff)
+		}
 	}
 }
 
-func TestContext2Plan_importIdDataSource(t *testing.T) {
-	p := testProvider("aws")
-	m := testModule(t, "import-id-data-source")
+func TestContext2Plan_refine(t *testing.T) {
+	files := func(upstream string) map[string]string {
+		return map[string]string{
+			"main.tf": fmt.Sprintf(`
+resource "test_object" "upstream" {
+  value = %q
+}
+
+resource "test_object" "a" {
+  value = test_object.upstream.value
+}
+
+resource "test_object" "b" {
+  value = "b"
+}
+`, upstream),
+		}
+	}
 
+	p := testProvider("test")
 	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
 		ResourceTypes: map[string]*configschema.Block{
-			"aws_subnet": {
-				Attributes: map[string]*configschema.Attribute{
-					"id": {
-						Type:     cty.String,
-						Computed: true,
-					},
-				},
-			},
-		},
-		DataSources: map[string]*configschema.Block{
-			"aws_subnet": {
+			"test_object": {
 				Attributes: map[string]*configschema.Attribute{
-					"vpc_id": {
-						Type:     cty.String,
-						Required: true,
-					},
-					"cidr_block": {
-						Type:     cty.String,
-						Computed: true,
-					},
-					"id": {
-						Type:     cty.String,
-						Computed: true,
-					},
+					"value": {Type: cty.String, Optional: true},
 				},
 			},
 		},
 	})
-	p.ReadDataSourceResponse = &providers.ReadDataSourceResponse{
-		State: cty.ObjectVal(map[string]cty.Value{
-			"vpc_id":     cty.StringVal("abc"),
-			"cidr_block": cty.StringVal("10.0.1.0/24"),
-			"id":         cty.StringVal("123"),
-		}),
-	}
-	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
-		ImportedResources: []providers.ImportedResource{
-			{
-				TypeName: "aws_subnet",
-				State: cty.ObjectVal(map[string]cty.Value{
-					"id": cty.StringVal("foo"),
-				}),
-			},
-		},
+	var mu sync.Mutex
+	var planned []string
+	p.PlanResourceChangeFn = func(req providers.PlanResourceChangeRequest) (resp providers.PlanResourceChangeResponse) {
+		mu.Lock()
+		defer mu.Unlock()
+		planned = append(planned, req.Config.GetAttr("value").AsString())
+		resp.PlannedState = req.ProposedNewState
+		return resp
 	}
+
+	state := states.BuildState(func(s *states.SyncState) {
+		for name, value := range map[string]string{
+			"upstream": "old",
+			"a":        "old",
+			"b":        "old",
+		} {
+			s.SetResourceInstanceCurrent(mustResourceInstanceAddr("test_object."+name), &states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(fmt.Sprintf(`{"value":%q}`, value)),
+				Status:    states.ObjectReady,
+			}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
+		}
+	})
+
 	ctx := testContext2(t, &ContextOpts{
 		Providers: map[addrs.Provider]providers.Factory{
-			addrs.NewDefaultProvider("aws"): testProviderFuncFixed(p),
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 		},
 	})
+	saved, diags := ctx.Plan(testModuleInline(t, files("new")), state, DefaultPlanOpts)
+	assertNoErrors(t, diags)
 
-	_, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
-	if diags.HasErrors() {
-		t.Fatalf("unexpected errors: %s", diags.Err())
-	}
+	t.Run("unaffected", func(t *testing.T) {
+		planned = nil
+		plan, diags := ctx.Plan(testModuleInline(t, files("newer")), state, &PlanOpts{
+			Mode: plans.NormalMode,
+			Refine: &PlanRefinement{
+				Plan:             saved,
+				ChangedResources: []addrs.ConfigResource{mustConfigResourceAddr("test_object.upstream")},
+			},
+		})
+		assertNoDiagnostics(t, diags)
+
+		// Only test_object.upstream and test_object.a, which depends on it,
+		// are planned again.
+		sort.Strings(planned)
+		if diff := cmp.Diff([]string{"newer", "newer"}, planned); diff != "" {
+			t.Errorf("wrong planned resources\n%s", diff)
+		}
+
+		schema := p.GetProviderSchemaResponse.ResourceTypes["test_object"].Block
+		for name, want := range map[string]string{
+			"upstream": "newer",
+			"a":        "newer",
+			"b":        "b",
+		} {
+			addr := mustResourceInstanceAddr("test_object." + name)
+			rc, err := plan.Changes.ResourceInstance(addr).Decode(schema.ImpliedType())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rc.After.GetAttr("value").AsString(); got != want {
+				t.Errorf("wrong value for %s: got %q, want %q", addr, got, want)
+			}
+		}
+	})
+
+	t.Run("incompatible", func(t *testing.T) {
+		planned = nil
+		_, diags := ctx.Plan(testModuleInline(t, files("new")), state, &PlanOpts{
+			Mode:         plans.NormalMode,
+			ForceReplace: []addrs.AbsResourceInstance{mustResourceInstanceAddr("test_object.b")},
+			Refine:       &PlanRefinement{Plan: saved},
+		})
+		assertNoErrors(t, diags)
+		if got, want := diags.ErrWithWarnings().Error(), "Saved plan can't be refined"; !strings.Contains(got, want) {
+			t.Errorf("missing warning %q in %s", want, got)
+		}
+
+		// Everything is planned from scratch, and test_object.b is planned
+		// twice because it's replaced.
+		if got, want := len(planned), 4; got != want {
+			t.Errorf("planned %d resources, want %d", got, want)
+		}
+	})
 }
 
-func TestContext2Plan_importIdModule(t *testing.T) {
-	p := testProvider("aws")
-	m := testModule(t, "import-id-module")
+func TestContext2Plan_refineDataSource(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+data "test_data_source" "remote" {
+}
+
+resource "test_object" "a" {
+  value = data.test_data_source.remote.value
+}
+
+resource "test_object" "b" {
+  value = "b"
+}
+`,
+	})
 
+	p := testProvider("test")
 	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
 		ResourceTypes: map[string]*configschema.Block{
-			"aws_lb": {
+			"test_object": {
 				Attributes: map[string]*configschema.Attribute{
-					"id": {
-						Type:     cty.String,
-						Computed: true,
-					},
+					"value": {Type: cty.String, Optional: true},
 				},
 			},
 		},
-	})
-	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
-		ImportedResources: []providers.ImportedResource{
-			{
-				TypeName: "aws_lb",
-				State: cty.ObjectVal(map[string]cty.Value{
-					"id": cty.StringVal("foo"),
-				}),
+		DataSources: map[string]*configschema.Block{
+			"test_data_source": {
+				Attributes: map[string]*configschema.Attribute{
+					"value": {Type: cty.String, Computed: true},
+				},
 			},
 		},
-	}
-	ctx := testContext2(t, &ContextOpts{
-		Providers: map[addrs.Provider]providers.Factory{
-			addrs.NewDefaultProvider("aws"): testProviderFuncFixed(p),
-		},
 	})
-
-	_, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
-	if diags.HasErrors() {
-		t.Fatalf("unexpected errors: %s", diags.Err())
+	remote := "old"
+	p.ReadDataSourceFn = func(req providers.ReadDataSourceRequest) (resp providers.ReadDataSourceResponse) {
+		resp.State = cty.ObjectVal(map[string]cty.Value{
+			"value": cty.StringVal(remote),
+		})
+		return resp
+	}
+	var mu sync.Mutex
+	var planned []string
+	p.PlanResourceChangeFn = func(req providers.PlanResourceChangeRequest) (resp providers.PlanResourceChangeResponse) {
+		mu.Lock()
+		defer mu.Unlock()
+		planned = append(planned, req.Config.GetAttr("value").AsString())
+		resp.PlannedState = req.ProposedNewState
+		return resp
 	}
-}
 
-func TestContext2Plan_importIdInvalidNull(t *testing.T) {
-	p := testProvider("test")
-	m := testModule(t, "import-id-invalid-null")
 	ctx := testContext2(t, &ContextOpts{
 		Providers: map[addrs.Provider]providers.Factory{
 			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 		},
 	})
+	saved, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	assertNoErrors(t, diags)
 
-	_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
-		SetVariables: InputValues{
-			"the_id": &InputValue{
-				Value: cty.NullVal(cty.String),
-			},
-		},
+	t.Run("unchanged data", func(t *testing.T) {
+		planned = nil
+		_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+			Mode:   plans.NormalMode,
+			Refine: &PlanRefinement{Plan: saved},
+		})
+		assertNoDiagnostics(t, diags)
+		if len(planned) != 0 {
+			t.Errorf("unexpected planned resources %v", planned)
+		}
 	})
-	if !diags.HasErrors() {
-		t.Fatal("succeeded; want errors")
-	}
-	if got, want := diags.Err().Error(), "The import ID cannot be null"; !strings.Contains(got, want) {
-		t.Fatalf("wrong error:\ngot:  %s\nwant: message containing %q", got, want)
-	}
-}
 
-func TestContext2Plan_importIdInvalidUnknown(t *testing.T) {
-	p := testProvider("test")
-	m := testModule(t, "import-id-invalid-unknown")
-	ctx := testContext2(t, &ContextOpts{
-		Providers: map[addrs.Provider]providers.Factory{
-			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
-		},
+	t.Run("changed data", func(t *testing.T) {
+		remote = "new"
+		planned = nil
+		plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+			Mode:   plans.NormalMode,
+			Refine: &PlanRefinement{Plan: saved},
+		})
+		assertNoDiagnostics(t, diags)
+
+		// Only test_object.a, which depends on the data source, is planned
+		// again.
+		if diff := cmp.Diff([]string{"new"}, planned); diff != "" {
+			t.Errorf("wrong planned resources\n%s", diff)
+		}
+		schema := p.GetProviderSchemaResponse.ResourceTypes["test_object"].Block
+		rc, err := plan.Changes.ResourceInstance(mustResourceInstanceAddr("test_object.a")).Decode(schema.ImpliedType())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := rc.After.GetAttr("value").AsString(), "new"; got != want {
+			t.Errorf("wrong value for test_object.a: got %q, want %q", got, want)
+		}
 	})
+}
+
+func TestContext2Plan_ignoreChangesWildcardsAndConditions(t *testing.T) {
+	p := simpleMockProvider()
 	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
 		ResourceTypes: map[string]*configschema.Block{
-			"test_resource": {
+			"test_object": {
 				Attributes: map[string]*configschema.Attribute{
-					"id": {
-						Type:     cty.String,
-						Computed: true,
+					"name": {Type: cty.String, Optional: true},
+					"tags": {Type: cty.Map(cty.String), Optional: true},
+					"rules": {
+						Type:     cty.List(cty.Object(map[string]cty.Type{"port": cty.Number, "description": cty.String})),
+						Optional: true,
 					},
 				},
 			},
 		},
 	})
-	p.PlanResourceChangeFn = func(req providers.PlanResourceChangeRequest) providers.PlanResourceChangeResponse {
-		return providers.PlanResourceChangeResponse{
-			PlannedState: cty.UnknownVal(cty.Object(map[string]cty.Type{
-				"id": cty.String,
-			})),
-		}
-	}
-	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
-		ImportedResources: []providers.ImportedResource{
-			{
-				TypeName: "test_resource",
-				State: cty.ObjectVal(map[string]cty.Value{
-					"id": cty.StringVal("foo"),
-				}),
-			},
+
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+variable "frozen" {
+  type = bool
+}
+
+resource "test_object" "a" {
+  name = "new"
+  tags = {
+    "kubernetes.io/cluster" = "new"
+    "kubernetes.io/role"    = "new"
+    "owner"                 = "new"
+  }
+  rules = [
+    { port = 80, description = "new" },
+    { port = 8443, description = "new" },
+  ]
+
+  lifecycle {
+    ignore_changes = [tags["kubernetes.io/*"], rules[*].description]
+
+    ignore_changes_when {
+      condition  = var.frozen
+      attributes = [name]
+    }
+  }
+}
+`,
+	})
+
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(mustResourceInstanceAddr("test_object.a"), &states.ResourceInstanceObjectSrc{
+			AttrsJSON: []byte(`{
+				"name": "old",
+				"tags": {"kubernetes.io/cluster": "old", "kubernetes.io/node": "old", "owner": "old"},
+				"rules": [{"port": 80, "description": "old"}, {"port": 443, "description": "old"}]
+			}`),
+			Status: states.ObjectReady,
+		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
+	})
+
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 		},
-	}
+	})
 
-	_, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
-	if !diags.HasErrors() {
-		t.Fatal("succeeded; want errors")
-	}
-	if got, want := diags.Err().Error(), `The import block "id" argument depends on resource attributes that cannot be determined until apply`; !strings.Contains(got, want) {
-		t.Fatalf("wrong error:\ngot:  %s\nwant: message containing %q", got, want)
+	diags := ctx.Validate(m)
+	assertNoErrors(t, diags)
+
+	schema := p.GetProviderSchemaResponse.ResourceTypes["test_object"].Block
+	for frozen, wantName := range map[bool]string{true: "old", false: "new"} {
+		t.Run(fmt.Sprintf("frozen=%t", frozen), func(t *testing.T) {
+			plan, diags := ctx.Plan(m, state, &PlanOpts{
+				Mode: plans.NormalMode,
+				SetVariables: InputValues{
+					"frozen": &InputValue{Value: cty.BoolVal(frozen), SourceType: ValueFromCaller},
+				},
+			})
+			assertNoErrors(t, diags)
+
+			change, err := plan.Changes.ResourceInstance(mustResourceInstanceAddr("test_object.a")).Decode(schema.ImpliedType())
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal(wantName),
+				"tags": cty.MapVal(map[string]cty.Value{
+					"kubernetes.io/cluster": cty.StringVal("old"),
+					"kubernetes.io/node":    cty.StringVal("old"),
+					"owner":                 cty.StringVal("new"),
+				}),
+				"rules": cty.ListVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{"port": cty.NumberIntVal(80), "description": cty.StringVal("old")}),
+					cty.ObjectVal(map[string]cty.Value{"port": cty.NumberIntVal(8443), "description": cty.StringVal("old")}),
+				}),
+			})
+			if !want.RawEquals(change.After) {
+				t.Errorf("wrong planned value\ngot:  %#v\nwant: %#v", change.After, want)
+			}
+		})
 	}
 }
 
-func TestContext2Plan_importIntoModuleWithGeneratedConfig(t *testing.T) {
+func TestContext2Plan_refreshOnlyDriftSelection(t *testing.T) {
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
-import {
-  to = test_object.a
-  id = "123"
+resource "test_object" "a" {
 }
 
-import {
-  to = module.mod.test_object.a
-  id = "456"
+resource "test_object" "b" {
 }
 
-module "mod" {
-  source = "./mod"
-}
-`,
-		"./mod/main.tf": `
-resource "test_object" "a" {
-  test_string = "bar"
+resource "test_object" "c" {
 }
 `,
 	})
 
 	p := simpleMockProvider()
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		ResourceTypes: map[string]*configschema.Block{
+			"test_object": {
+				Attributes: map[string]*configschema.Attribute{
+					"name": {Type: cty.String, Optional: true},
+					"tags": {Type: cty.Map(cty.String), Optional: true},
+				},
+			},
+		},
+	})
+	p.ReadResourceFn = func(req providers.ReadResourceRequest) providers.ReadResourceResponse {
+		return providers.ReadResourceResponse{
+			NewState: cty.ObjectVal(map[string]cty.Value{
+				"name": cty.StringVal("changed"),
+				"tags": cty.MapVal(map[string]cty.Value{
+					"owner": cty.StringVal("changed"),
+					"added": cty.StringVal("changed"),
+				}),
+			}),
+		}
+	}
+
+	state := states.BuildState(func(s *states.SyncState) {
+		for _, name := range []string{"a", "b", "c"} {
+			s.SetResourceInstanceCurrent(mustResourceInstanceAddr("test_object."+name), &states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"name":"old","tags":{"owner":"old"}}`),
+				Status:    states.ObjectReady,
+			}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
+		}
+	})
+
 	ctx := testContext2(t, &ContextOpts{
 		Providers: map[addrs.Provider]providers.Factory{
 			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 		},
 	})
-	p.ReadResourceResponse = &providers.ReadResourceResponse{
-		NewState: cty.ObjectVal(map[string]cty.Value{
-			"test_string": cty.StringVal("foo"),
-		}),
-	}
-	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
-		ImportedResources: []providers.ImportedResource{
-			{
-				TypeName: "test_object",
-				State: cty.ObjectVal(map[string]cty.Value{
-					"test_string": cty.StringVal("foo"),
-				}),
-			},
-		},
-	}
 
-	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
-		ImportedResources: []providers.ImportedResource{
-			{
-				TypeName: "test_object",
-				State: cty.ObjectVal(map[string]cty.Value{
-					"test_string": cty.StringVal("foo"),
-				}),
+	plan, diags := ctx.Plan(m, state, &PlanOpts{
+		Mode: plans.RefreshOnlyMode,
+		DriftSelection: &plans.DriftSelection{
+			Accept: []plans.DriftSelector{
+				{Target: mustResourceInstanceAddr("test_object.a")},
+				{Target: mustResourceInstanceAddr("test_object.b"), Path: cty.GetAttrPath("tags").Index(cty.StringVal("added"))},
+			},
+			Reject: []plans.DriftSelector{
+				{Target: mustResourceInstanceAddr("test_object.a"), Path: cty.GetAttrPath("name")},
 			},
 		},
-	}
-
-	plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
-		Mode:               plans.NormalMode,
-		GenerateConfigPath: "generated.tf", // Actual value here doesn't matter, as long as it is not empty.
 	})
-	if diags.HasErrors() {
-		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
-	}
-
-	one := mustResourceInstanceAddr("test_object.a")
-	two := mustResourceInstanceAddr("module.mod.test_object.a")
-
-	onePlan := plan.Changes.ResourceInstance(one)
-	twoPlan := plan.Changes.ResourceInstance(two)
+	assertNoErrors(t, diags)
 
-	// This test is just to make sure things work e2e with modules and generated
-	// config, so we're not too careful about the actual responses - we're just
-	// happy nothing panicked. See the other import tests for actual validation
-	// of responses and the like.
-	if twoPlan.Action != plans.Update {
-		t.Errorf("expected nested item to be updated but was %s", twoPlan.Action)
+	// All of the changes are still reported as drift.
+	if got, want := len(plan.DriftedResources), 3; got != want {
+		t.Fatalf("wrong number of drifted resources %d; want %d", got, want)
 	}
 
-	if len(onePlan.GeneratedConfig) == 0 {
-		t.Errorf("expected root item to generate config but it didn't")
+	state, diags = ctx.Apply(plan, m)
+	assertNoErrors(t, diags)
+
+	for name, want := range map[string]string{
+		// All of the changes except to name are accepted.
+		"a": `{"name":"old","tags":{"added":"changed","owner":"changed"}}`,
+		// Only the added tag is accepted.
+		"b": `{"name":"old","tags":{"added":"changed","owner":"old"}}`,
+		// Nothing is accepted.
+		"c": `{"name":"old","tags":{"owner":"old"}}`,
+	} {
+		is := state.ResourceInstance(mustResourceInstanceAddr("test_object." + name))
+		if diff := cmp.Diff(want, string(is.Current.AttrsJSON)); diff != "" {
+			t.Errorf("wrong state for test_object.%s\n%s", name, diff)
+		}
 	}
 }
 
-func TestContext2Plan_importResourceConfigGen(t *testing.T) {
-	addr := mustResourceInstanceAddr("test_object.a")
+func TestContext2Plan_cycleDiagnostic(t *testing.T) {
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
-import {
-  to   = test_object.a
-  id   = "123"
+resource "test_object" "a" {
+  test_string = test_object.b.test_string
+}
+
+resource "test_object" "b" {
+  test_string = "b"
+  depends_on  = [test_object.c]
+}
+
+resource "test_object" "c" {
+  test_string = test_object.a.test_string
 }
 `,
 	})
@@ -4930,301 +7880,483 @@ import {
 			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 		},
 	})
-	p.ReadResourceResponse = &providers.ReadResourceResponse{
-		NewState: cty.ObjectVal(map[string]cty.Value{
-			"test_string": cty.StringVal("foo"),
-		}),
-	}
-	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
-		ImportedResources: []providers.ImportedResource{
-			{
-				TypeName: "test_object",
-				State: cty.ObjectVal(map[string]cty.Value{
-					"test_string": cty.StringVal("foo"),
-				}),
-			},
-		},
-	}
 
-	plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
-		Mode:               plans.NormalMode,
-		GenerateConfigPath: "generated.tf", // Actual value here doesn't matter, as long as it is not empty.
-	})
-	if diags.HasErrors() {
-		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
+	_, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	if len(diags) != 1 {
+		t.Fatalf("wrong number of diagnostics %d; want 1\n%s", len(diags), diags.ErrWithWarnings())
 	}
-
-	t.Run(addr.String(), func(t *testing.T) {
-		instPlan := plan.Changes.ResourceInstance(addr)
-		if instPlan == nil {
-			t.Fatalf("no plan for %s at all", addr)
-		}
-
-		if got, want := instPlan.Addr, addr; !got.Equal(want) {
-			t.Errorf("wrong current address\ngot:  %s\nwant: %s", got, want)
-		}
-		if got, want := instPlan.PrevRunAddr, addr; !got.Equal(want) {
-			t.Errorf("wrong previous run address\ngot:  %s\nwant: %s", got, want)
-		}
-		if got, want := instPlan.Action, plans.NoOp; got != want {
-			t.Errorf("wrong planned action\ngot:  %s\nwant: %s", got, want)
-		}
-		if got, want := instPlan.ActionReason, plans.ResourceInstanceChangeNoReason; got != want {
-			t.Errorf("wrong action reason\ngot:  %s\nwant: %s", got, want)
-		}
-		if instPlan.Importing.ID != "123" {
-			t.Errorf("expected import change from \"123\", got non-import change")
-		}
-
-		want := `resource "test_object" "a" {
-  test_bool   = null
-  test_list   = null
-  test_map    = null
-  test_number = null
-  test_string = "foo"
-}`
-		got := instPlan.GeneratedConfig
-		if diff := cmp.Diff(want, got); len(diff) > 0 {
-			t.Errorf("got:\n%s\nwant:\n%s\ndiff:\n%s", got, want, diff)
+	desc := diags[0].Description()
+	if got, want := desc.Summary, "Cycle between objects"; got != want {
+		t.Fatalf("wrong summary %q; want %q", got, want)
+	}
+	for _, want := range []string{
+		"test_object.a refers to test_object.b (",
+		"main.tf:3,17)",
+		"test_object.b depends on test_object.c (",
+		"main.tf:8,18)",
+		"test_object.c refers to test_object.a (",
+		"main.tf:12,17)",
+		"Move the argument of test_object.a that refers to test_object.b into a separate resource",
+		"Remove test_object.c from the depends_on argument of test_object.b",
+		"Look up the value that test_object.c needs from test_object.a with a data source",
+	} {
+		if !strings.Contains(desc.Detail, want) {
+			t.Errorf("detail is missing %q\n%s", want, desc.Detail)
 		}
-	})
+	}
+	if subject := diags[0].Source().Subject; subject == nil || subject.Start.Line != 3 {
+		t.Errorf("wrong subject %#v; want the reference on line 3", subject)
+	}
 }
 
-func TestContext2Plan_importResourceConfigGenWithAlias(t *testing.T) {
-	addr := mustResourceInstanceAddr("test_object.a")
+func TestContext2Plan_generateImports(t *testing.T) {
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
-provider "test" {
-  alias = "backup"
+resource "test_object" "a" {
+  name = "a"
+}
+
+resource "test_object" "b" {
+  name = "b"
 }
 
 import {
-  provider = test.backup
-  to       = test_object.a
-  id       = "123"
+  to = test_object.b
+  id = "b"
 }
 `,
 	})
 
 	p := simpleMockProvider()
-	ctx := testContext2(t, &ContextOpts{
-		Providers: map[addrs.Provider]providers.Factory{
-			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		ResourceTypes: map[string]*configschema.Block{
+			"test_object": {
+				Attributes: map[string]*configschema.Attribute{
+					"id":   {Type: cty.String, Computed: true},
+					"name": {Type: cty.String, Optional: true},
+				},
+			},
 		},
 	})
-	p.ReadResourceResponse = &providers.ReadResourceResponse{
-		NewState: cty.ObjectVal(map[string]cty.Value{
-			"test_string": cty.StringVal("foo"),
-		}),
+	p.GetProviderSchemaResponse.ServerCapabilities.ListResources = true
+	p.ImportResourceStateFn = func(req providers.ImportResourceStateRequest) providers.ImportResourceStateResponse {
+		return providers.ImportResourceStateResponse{
+			ImportedResources: []providers.ImportedResource{
+				{
+					TypeName: "test_object",
+					State: cty.ObjectVal(map[string]cty.Value{
+						"id":   cty.StringVal(req.ID),
+						"name": cty.StringVal(req.ID),
+					}),
+				},
+			},
+		}
 	}
-	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
-		ImportedResources: []providers.ImportedResource{
+	p.ListResourcesResponse = &providers.ListResourcesResponse{
+		Resources: []providers.ListedResource{
+			{ID: "a"},
+			{ID: "b"},
 			{
-				TypeName: "test_object",
+				ID: "Web 1",
 				State: cty.ObjectVal(map[string]cty.Value{
-					"test_string": cty.StringVal("foo"),
+					"id":   cty.StringVal("Web 1"),
+					"name": cty.StringVal("web"),
 				}),
 			},
+			{ID: "a-2"},
 		},
 	}
 
-	plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
-		Mode:               plans.NormalMode,
-		GenerateConfigPath: "generated.tf", // Actual value here doesn't matter, as long as it is not empty.
-	})
-	if diags.HasErrors() {
-		t.Fatalf("unexpected errors\n%s", diags.Err().Error())
-	}
-
-	t.Run(addr.String(), func(t *testing.T) {
-		instPlan := plan.Changes.ResourceInstance(addr)
-		if instPlan == nil {
-			t.Fatalf("no plan for %s at all", addr)
-		}
-
-		if got, want := instPlan.Addr, addr; !got.Equal(want) {
-			t.Errorf("wrong current address\ngot:  %s\nwant: %s", got, want)
-		}
-		if got, want := instPlan.PrevRunAddr, addr; !got.Equal(want) {
-			t.Errorf("wrong previous run address\ngot:  %s\nwant: %s", got, want)
-		}
-		if got, want := instPlan.Action, plans.NoOp; got != want {
-			t.Errorf("wrong planned action\ngot:  %s\nwant: %s", got, want)
-		}
-		if got, want := instPlan.ActionReason, plans.ResourceInstanceChangeNoReason; got != want {
-			t.Errorf("wrong action reason\ngot:  %s\nwant: %s", got, want)
-		}
-		if instPlan.Importing.ID != "123" {
-			t.Errorf("expected import change from \"123\", got non-import change")
-		}
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(
+			mustResourceInstanceAddr("test_object.a"),
+			&states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"id":"a","name":"a"}`),
+				Status:    states.ObjectReady,
+			},
+			mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`),
+		)
+	})
 
-		want := `resource "test_object" "a" {
-  provider    = test.backup
-  test_bool   = null
-  test_list   = null
-  test_map    = null
-  test_number = null
-  test_string = "foo"
-}`
-		got := instPlan.GeneratedConfig
-		if diff := cmp.Diff(want, got); len(diff) > 0 {
-			t.Errorf("got:\n%s\nwant:\n%s\ndiff:\n%s", got, want, diff)
-		}
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[addrs.Provider]providers.Factory{
+			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+		},
+	})
+
+	plan, diags := ctx.Plan(m, state, &PlanOpts{
+		Mode:            plans.NormalMode,
+		GenerateImports: true,
 	})
+	assertNoErrors(t, diags)
+
+	if got, want := p.ListResourcesRequest.TypeName, "test_object"; got != want {
+		t.Fatalf("wrong type listed %q; want %q", got, want)
+	}
+
+	got := make(map[string]string)
+	for _, dr := range plan.DiscoveredResources {
+		got[dr.Addr.String()] = dr.GeneratedConfig
+	}
+	want := map[string]string{
+		"test_object.a-2": `import {
+  to = test_object.a-2
+  id = "a-2"
 }
 
-func TestContext2Plan_importResourceConfigGenExpandedResource(t *testing.T) {
+resource "test_object" "a-2" {
+  name = null # OPTIONAL string
+}`,
+		"test_object.web_1": `import {
+  to = test_object.web_1
+  id = "Web 1"
+}
+
+resource "test_object" "web_1" {
+  name = "web"
+}`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong discovered resources\n%s", diff)
+	}
+}
+
+func TestContext2Plan_generateImportsUnsupported(t *testing.T) {
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
-import {
-  to       = test_object.a[0]
-  id       = "123"
+resource "test_object" "a" {
+  test_string = "a"
 }
 `,
 	})
 
+	// The provider doesn't declare the ListResources capability, so it's
+	// never asked to list objects.
 	p := simpleMockProvider()
+	p.ListResourcesResponse = &providers.ListResourcesResponse{
+		Resources: []providers.ListedResource{{ID: "b"}},
+	}
+
 	ctx := testContext2(t, &ContextOpts{
 		Providers: map[addrs.Provider]providers.Factory{
 			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 		},
 	})
-	p.ReadResourceResponse = &providers.ReadResourceResponse{
-		NewState: cty.ObjectVal(map[string]cty.Value{
-			"test_string": cty.StringVal("foo"),
-		}),
+
+	plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
+		Mode:            plans.NormalMode,
+		GenerateImports: true,
+	})
+	assertNoErrors(t, diags)
+
+	if p.ListResourcesCalled {
+		t.Fatalf("provider without the ListResources capability was asked to list objects")
 	}
-	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
-		ImportedResources: []providers.ImportedResource{
-			{
-				TypeName: "test_object",
-				State: cty.ObjectVal(map[string]cty.Value{
-					"test_string": cty.StringVal("foo"),
-				}),
-			},
-		},
+	if len(plan.DiscoveredResources) != 0 {
+		t.Fatalf("unexpected discovered resources %#v", plan.DiscoveredResources)
+	}
+	if got, want := len(diags), 1; got != want {
+		t.Fatalf("wrong number of diagnostics %d; want %d\n%s", got, want, diags.ErrWithWarnings())
+	}
+	if got, want := diags[0].Description().Summary, "Provider cannot list existing objects"; got != want {
+		t.Fatalf("wrong warning %q; want %q", got, want)
 	}
+}
 
-	_, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
-		Mode:               plans.NormalMode,
-		GenerateConfigPath: "generated.tf",
+func TestContext2Plan_outputContracts(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"child/main.tf": `
+output "id" {
+  value    = timestamp()
+  type     = string
+  nullable = false
+}
+
+output "service" {
+  value = {
+    name = "web"
+  }
+  type = object({
+    name = string
+    port = optional(number, 80)
+  })
+  stable = true
+}
+`,
+		"main.tf": `
+module "child" {
+  source = "./child"
+}
+
+output "has_id" {
+  value = module.child.id != null
+}
+
+output "port" {
+  value = module.child.service.port
+}
+`,
 	})
-	if !diags.HasErrors() {
-		t.Fatalf("expected plan to error, but it did not")
+
+	ctx := testContext2(t, &ContextOpts{})
+
+	plan, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+	assertNoErrors(t, diags)
+
+	for name, want := range map[string]cty.Value{
+		// The id is not known yet, but it's declared as never null.
+		"has_id": cty.True,
+		// The type defaults of the service output apply.
+		"port": cty.NumberIntVal(80),
+	} {
+		oc, err := plan.Changes.OutputValue(addrs.OutputValue{Name: name}.Absolute(addrs.RootModuleInstance)).Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !oc.After.RawEquals(want) {
+			t.Errorf("wrong value for output %q\ngot:  %#v\nwant: %#v", name, oc.After, want)
+		}
 	}
 }
 
-// config generation still succeeds even when planning fails
-func TestContext2Plan_importResourceConfigGenWithError(t *testing.T) {
-	addr := mustResourceInstanceAddr("test_object.a")
+func TestContext2Plan_outputContractsViolated(t *testing.T) {
+	tests := map[string]struct {
+		output      string
+		wantSummary string
+	}{
+		"wrong type": {
+			`
+output "out" {
+  value = "abc"
+  type  = number
+}`,
+			"Invalid output value type",
+		},
+		"null": {
+			`
+output "out" {
+  value    = null
+  nullable = false
+}`,
+			"Null output value",
+		},
+		"unstable": {
+			`
+output "out" {
+  value  = timestamp()
+  stable = true
+}`,
+			"Output value not known during planning",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := testModuleInline(t, map[string]string{
+				"child/main.tf": test.output,
+				"main.tf": `
+module "child" {
+  source = "./child"
+}
+`,
+			})
+
+			ctx := testContext2(t, &ContextOpts{})
+
+			_, diags := ctx.Plan(m, states.NewState(), DefaultPlanOpts)
+			if !diags.HasErrors() {
+				t.Fatal("succeeded; want errors")
+			}
+			if got := diags.Err().Error(); !strings.Contains(got, test.wantSummary) {
+				t.Fatalf("wrong error:\ngot:  %s\nwant: message containing %q", got, test.wantSummary)
+			}
+		})
+	}
+}
+
+func TestContext2Plan_deferredExpansionModuleInstances(t *testing.T) {
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
-import {
-  to   = test_object.a
-  id   = "123"
+resource "test_object" "src" {
+}
+
+module "child" {
+  source   = "./child"
+  for_each = toset(["a", "b"])
+
+  keys = each.key == "a" ? ["k"] : [test_object.src.id]
+}
+`,
+		"child/main.tf": `
+variable "keys" {
+  type = list(string)
+}
+
+resource "test_object" "x" {
+  for_each = toset(var.keys)
+
+  test_string = each.key
+}
+
+resource "test_object" "y" {
+  count = 2
+
+  test_string = join(",", [for x in test_object.x : x.test_string])
 }
 `,
 	})
 
-	p := simpleMockProvider()
+	p := deferredExpansionProvider()
 	ctx := testContext2(t, &ContextOpts{
 		Providers: map[addrs.Provider]providers.Factory{
 			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 		},
 	})
-	p.PlanResourceChangeResponse = &providers.PlanResourceChangeResponse{
-		PlannedState: cty.NullVal(cty.DynamicPseudoType),
-		Diagnostics:  tfdiags.Diagnostics(nil).Append(errors.New("plan failed")),
-	}
-	p.ReadResourceResponse = &providers.ReadResourceResponse{
-		NewState: cty.ObjectVal(map[string]cty.Value{
-			"test_string": cty.StringVal("foo"),
-		}),
-	}
-	p.ImportResourceStateResponse = &providers.ImportResourceStateResponse{
-		ImportedResources: []providers.ImportedResource{
-			{
-				TypeName: "test_object",
-				State: cty.ObjectVal(map[string]cty.Value{
-					"test_string": cty.StringVal("foo"),
-				}),
-			},
-		},
-	}
 
 	plan, diags := ctx.Plan(m, states.NewState(), &PlanOpts{
-		Mode:               plans.NormalMode,
-		GenerateConfigPath: "generated.tf", // Actual value here doesn't matter, as long as it is not empty.
+		Mode:            plans.NormalMode,
+		DeferralAllowed: true,
 	})
-	if !diags.HasErrors() {
-		t.Fatal("expected error")
-	}
+	assertNoErrors(t, diags)
 
-	instPlan := plan.Changes.ResourceInstance(addr)
-	if instPlan == nil {
-		t.Fatalf("no plan for %s at all", addr)
+	// Only the instances in module.child["b"] depend on the unknown keys,
+	// so the instances in module.child["a"] are planned as usual.
+	var gotDeferred []string
+	for _, addr := range plan.DeferredResources {
+		gotDeferred = append(gotDeferred, addr.String())
+	}
+	for _, addr := range plan.DeferredResourceInstances {
+		gotDeferred = append(gotDeferred, addr.String())
+	}
+	wantDeferred := []string{
+		`module.child["b"].test_object.x`,
+		`module.child["b"].test_object.y[0]`,
+		`module.child["b"].test_object.y[1]`,
+	}
+	if diff := cmp.Diff(wantDeferred, gotDeferred); diff != "" {
+		t.Fatalf("wrong deferred objects\n%s", diff)
 	}
 
-	want := `resource "test_object" "a" {
-  test_bool   = null
-  test_list   = null
-  test_map    = null
-  test_number = null
-  test_string = "foo"
-}`
-	got := instPlan.GeneratedConfig
-	if diff := cmp.Diff(want, got); len(diff) > 0 {
-		t.Errorf("got:\n%s\nwant:\n%s\ndiff:\n%s", got, want, diff)
+	var gotCreated []string
+	for _, rc := range plan.Changes.Resources {
+		if rc.Action != plans.Create {
+			t.Errorf("wrong action for %s: %s", rc.Addr, rc.Action)
+		}
+		gotCreated = append(gotCreated, rc.Addr.String())
+	}
+	sort.Strings(gotCreated)
+	wantCreated := []string{
+		`module.child["a"].test_object.x["k"]`,
+		`module.child["a"].test_object.y[0]`,
+		`module.child["a"].test_object.y[1]`,
+		`test_object.src`,
+	}
+	if diff := cmp.Diff(wantCreated, gotCreated); diff != "" {
+		t.Fatalf("wrong planned changes\n%s", diff)
 	}
 }
 
-func TestContext2Plan_plannedState(t *testing.T) {
-	addr := mustResourceInstanceAddr("test_object.a")
+func TestContext2Plan_deferredExpansionOrphans(t *testing.T) {
 	m := testModuleInline(t, map[string]string{
 		"main.tf": `
 resource "test_object" "a" {
-	test_string = "foo"
 }
 
-locals {
-  local_value = test_object.a.test_string
+resource "test_object" "b" {
+  for_each = toset([test_object.a.id])
+
+  test_string = each.key
+}
+
+resource "test_object" "c" {
+  count = 1
+
+  test_string = "c"
+
+  depends_on = [test_object.b]
+}
+
+resource "test_object" "d" {
+  count = 1
+
+  test_string = "d"
 }
 `,
 	})
 
-	p := simpleMockProvider()
+	b := mustResourceInstanceAddr(`test_object.b["old"]`)
+	state := states.BuildState(func(s *states.SyncState) {
+		s.SetResourceInstanceCurrent(b, &states.ResourceInstanceObjectSrc{
+			AttrsJSON: []byte(`{"test_string":"old"}`),
+			Status:    states.ObjectReady,
+		}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
+		for _, name := range []string{"test_object.c[0]", "test_object.c[1]"} {
+			s.SetResourceInstanceCurrent(mustResourceInstanceAddr(name), &states.ResourceInstanceObjectSrc{
+				AttrsJSON:    []byte(`{"test_string":"old"}`),
+				Status:       states.ObjectReady,
+				Dependencies: []addrs.ConfigResource{b.ContainingResource().Config()},
+			}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
+		}
+		for _, name := range []string{"test_object.d[0]", "test_object.d[1]"} {
+			s.SetResourceInstanceCurrent(mustResourceInstanceAddr(name), &states.ResourceInstanceObjectSrc{
+				AttrsJSON: []byte(`{"test_string":"old"}`),
+				Status:    states.ObjectReady,
+			}, mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`))
+		}
+	})
+
+	p := deferredExpansionProvider()
 	ctx := testContext2(t, &ContextOpts{
 		Providers: map[addrs.Provider]providers.Factory{
 			addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
 		},
 	})
 
-	state := states.NewState()
-	plan, diags := ctx.Plan(m, state, nil)
-	if diags.HasErrors() {
-		t.Errorf("expected no errors, but got %s", diags)
-	}
-
-	module := state.RootModule()
+	plan, diags := ctx.Plan(m, state, &PlanOpts{
+		Mode:            plans.NormalMode,
+		DeferralAllowed: true,
+	})
+	assertNoErrors(t, diags)
 
-	// So, the original state shouldn't have been updated at all.
-	if len(module.LocalValues) > 0 {
-		t.Errorf("expected no local values in the state but found %d", len(module.LocalValues))
+	// The orphaned test_object.c[1] depends on the deferred test_object.b,
+	// so it stays in the state until a later plan, like test_object.c[0].
+	// test_object.d[1] doesn't, so it is destroyed as usual.
+	var gotDeferred []string
+	for _, addr := range plan.DeferredResourceInstances {
+		gotDeferred = append(gotDeferred, addr.String())
 	}
-
-	if len(module.Resources) > 0 {
-		t.Errorf("expected no resources in the state but found %d", len(module.LocalValues))
+	if diff := cmp.Diff([]string{"test_object.c[0]", "test_object.c[1]"}, gotDeferred); diff != "" {
+		t.Fatalf("wrong deferred resource instances\n%s", diff)
 	}
-
-	// But, this makes it hard for the testing framework to valid things about
-	// the returned plan. So, the plan contains the planned state:
-	module = plan.PlannedState.RootModule()
-
-	if module.LocalValues["local_value"].AsString() != "foo" {
-		t.Errorf("expected local value to be \"foo\" but was \"%s\"", module.LocalValues["local_value"].AsString())
+	for _, name := range []string{"test_object.c[0]", "test_object.c[1]"} {
+		if rc := plan.Changes.ResourceInstance(mustResourceInstanceAddr(name)); rc != nil {
+			t.Errorf("unexpected %s change for deferred %s", rc.Action, name)
+		}
 	}
-
-	if module.ResourceInstance(addr.Resource).Current.Status != states.ObjectPlanned {
-		t.Errorf("expected resource to be in planned state")
+	if rc := plan.Changes.ResourceInstance(mustResourceInstanceAddr("test_object.d[1]")); rc == nil || rc.Action != plans.Delete {
+		t.Errorf("missing delete change for test_object.d[1]")
 	}
 }
+
+// deferredExpansionProvider returns a provider for test_object resources
+// whose id attribute is unknown until apply.
+func deferredExpansionProvider() *MockProvider {
+	p := new(MockProvider)
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		ResourceTypes: map[string]*configschema.Block{
+			"test_object": {
+				Attributes: map[string]*configschema.Attribute{
+					"id": {
+						Type:     cty.String,
+						Computed: true,
+					},
+					"test_string": {
+						Type:     cty.String,
+						Optional: true,
+					},
+				},
+			},
+		},
+	})
+	return p
+}
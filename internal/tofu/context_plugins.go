@@ -20,6 +20,14 @@ import (
 type contextPlugins struct {
 	providerFactories    map[addrs.Provider]providers.Factory
 	provisionerFactories map[string]provisioners.Factory
+
+	// providerConfigFactories optionally replace providerFactories for the
+	// instances created for particular provider configurations.
+	providerConfigFactories map[addrs.Provider]providers.ConfigFactory
+
+	// providerCapabilities are the recorded capabilities of some of the
+	// providers, which ProviderCapabilities returns without starting them.
+	providerCapabilities map[addrs.Provider]providers.Capabilities
 }
 
 func newContextPlugins(providerFactories map[addrs.Provider]providers.Factory, provisionerFactories map[string]provisioners.Factory) *contextPlugins {
@@ -45,6 +53,15 @@ func (cp *contextPlugins) NewProviderInstance(addr addrs.Provider) (providers.In
 
 }
 
+// NewProviderConfigInstance returns a new instance of a provider for use
+// with the provider configuration with the given address.
+func (cp *contextPlugins) NewProviderConfigInstance(addr addrs.AbsProviderConfig) (providers.Interface, error) {
+	if f, ok := cp.providerConfigFactories[addr.Provider]; ok {
+		return f(addr)
+	}
+	return cp.NewProviderInstance(addr.Provider)
+}
+
 func (cp *contextPlugins) HasProvisioner(typ string) bool {
 	_, ok := cp.provisionerFactories[typ]
 	return ok
@@ -117,6 +134,19 @@ func (cp *contextPlugins) ProviderSchema(addr addrs.Provider) (providers.Provide
 	return resp, nil
 }
 
+// ProviderCapabilities returns the capabilities of the provider with the
+// given address, either as recorded by "tofu init" or else from its schema.
+func (cp *contextPlugins) ProviderCapabilities(addr addrs.Provider) (providers.Capabilities, error) {
+	if caps, ok := cp.providerCapabilities[addr]; ok {
+		return caps, nil
+	}
+	schema, err := cp.ProviderSchema(addr)
+	if err != nil {
+		return providers.Capabilities{}, err
+	}
+	return schema.Capabilities(), nil
+}
+
 // ProviderConfigSchema is a helper wrapper around ProviderSchema which first
 // reads the full schema of the given provider and then extracts just the
 // provider's configuration schema, which defines what's expected in a
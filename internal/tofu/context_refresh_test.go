@@ -268,8 +268,11 @@ func TestContext2Refresh_targeted(t *testing.T) {
 		},
 	})
 
+	var refreshedResourcesLock sync.Mutex
 	refreshedResources := make([]string, 0, 2)
 	p.ReadResourceFn = func(req providers.ReadResourceRequest) providers.ReadResourceResponse {
+		refreshedResourcesLock.Lock()
+		defer refreshedResourcesLock.Unlock()
 		refreshedResources = append(refreshedResources, req.PriorState.GetAttr("id").AsString())
 		return providers.ReadResourceResponse{
 			NewState: req.PriorState,
@@ -288,7 +291,9 @@ func TestContext2Refresh_targeted(t *testing.T) {
 		t.Fatalf("refresh errors: %s", diags.Err())
 	}
 
-	expected := []string{"vpc-abc123", "i-abc123"}
+	// The objects are read concurrently, so the order is not significant.
+	expected := []string{"i-abc123", "vpc-abc123"}
+	sort.Strings(refreshedResources)
 	if !reflect.DeepEqual(refreshedResources, expected) {
 		t.Fatalf("expected: %#v, got: %#v", expected, refreshedResources)
 	}
@@ -350,8 +355,11 @@ func TestContext2Refresh_targetedCount(t *testing.T) {
 		},
 	})
 
+	var refreshedResourcesLock sync.Mutex
 	refreshedResources := make([]string, 0, 2)
 	p.ReadResourceFn = func(req providers.ReadResourceRequest) providers.ReadResourceResponse {
+		refreshedResourcesLock.Lock()
+		defer refreshedResourcesLock.Unlock()
 		refreshedResources = append(refreshedResources, req.PriorState.GetAttr("id").AsString())
 		return providers.ReadResourceResponse{
 			NewState: req.PriorState,
@@ -440,8 +448,11 @@ func TestContext2Refresh_targetedCountIndex(t *testing.T) {
 		},
 	})
 
+	var refreshedResourcesLock sync.Mutex
 	refreshedResources := make([]string, 0, 2)
 	p.ReadResourceFn = func(req providers.ReadResourceRequest) providers.ReadResourceResponse {
+		refreshedResourcesLock.Lock()
+		defer refreshedResourcesLock.Unlock()
 		refreshedResources = append(refreshedResources, req.PriorState.GetAttr("id").AsString())
 		return providers.ReadResourceResponse{
 			NewState: req.PriorState,
@@ -460,7 +471,9 @@ func TestContext2Refresh_targetedCountIndex(t *testing.T) {
 		t.Fatalf("refresh errors: %s", diags.Err())
 	}
 
-	expected := []string{"vpc-abc123", "i-abc123"}
+	// The objects are read concurrently, so the order is not significant.
+	expected := []string{"i-abc123", "vpc-abc123"}
+	sort.Strings(refreshedResources)
 	if !reflect.DeepEqual(refreshedResources, expected) {
 		t.Fatalf("wrong result\ngot:  %#v\nwant: %#v", refreshedResources, expected)
 	}
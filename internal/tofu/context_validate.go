@@ -8,6 +8,7 @@ import (
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/refactoring"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/zclconf/go-cty/cty"
@@ -38,6 +39,20 @@ func (c *Context) Validate(config *configs.Config) tfdiags.Diagnostics {
 		return diags
 	}
 
+	// The result of validation depends only on the configuration and the
+	// plugins, so if an earlier run validated the same configuration with
+	// the same plugins without any diagnostics we can skip it.
+	cacheKey, cacheable := c.validationCache.key(validationCacheValidate, nil)
+	if cacheable && c.validationCache.has(validationCacheValidate, cacheKey) {
+		log.Printf("[DEBUG] Skipping validation of unchanged configuration")
+		return diags
+	}
+
+	_, moreDiags = refactoring.FindRemoveStatements(config)
+	diags = diags.Append(moreDiags)
+	_, moreDiags = refactoring.FindCrossPackageMoveStatements(config, nil)
+	diags = diags.Append(moreDiags)
+
 	log.Printf("[DEBUG] Building and walking validate graph")
 
 	// Validate is to check if the given module is valid regardless of
@@ -79,5 +94,8 @@ func (c *Context) Validate(config *configs.Config) tfdiags.Diagnostics {
 		return diags
 	}
 
+	if cacheable && len(diags) == 0 {
+		c.validationCache.record(validationCacheValidate, cacheKey)
+	}
 	return diags
 }
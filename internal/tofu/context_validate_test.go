@@ -6,9 +6,11 @@ package tofu
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/opentofu/opentofu/internal/addrs"
@@ -2485,3 +2487,309 @@ locals {
 		t.Fatalf("expected deprecated warning, got: %q\n", warn)
 	}
 }
+
+func TestContext2Validate_deprecatedModuleVariableAndOutput(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+module "child" {
+  source = "./child"
+  count  = 2
+
+  old_name = "a"
+  new_name = "b"
+}
+
+module "unset" {
+  source = "./child"
+}
+
+locals {
+  old = module.child[0].old_id
+  new = module.child[0].new_id
+}
+`,
+		"child/main.tf": `
+variable "old_name" {
+  type       = string
+  default    = null
+  deprecated = "Use new_name instead."
+}
+
+variable "new_name" {
+  type    = string
+  default = null
+}
+
+output "old_id" {
+  value      = coalesce(var.new_name, var.old_name, "x")
+  deprecated = "Use new_id instead."
+}
+
+output "new_id" {
+  value = coalesce(var.new_name, var.old_name, "x")
+}
+`,
+	})
+
+	ctx := testContext2(t, &ContextOpts{})
+
+	diags := ctx.Validate(m)
+	if diags.HasErrors() {
+		t.Fatal(diags.ErrWithWarnings())
+	}
+
+	var got []string
+	for _, diag := range diags {
+		desc := diag.Description()
+		got = append(got, fmt.Sprintf("%s: %s", desc.Summary, desc.Detail))
+	}
+	sort.Strings(got)
+	want := []string{
+		`Deprecated input variable: The input variable "old_name" of module.child is deprecated: Use new_name instead.`,
+		`Deprecated output value: The output value "old_id" of module.child is deprecated: Use new_id instead.`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("wrong diagnostics\n%s", diff)
+	}
+}
+
+func TestContext2Validate_ephemeralValueNotAllowed(t *testing.T) {
+	tests := map[string]struct {
+		config      string
+		wantSummary string
+	}{
+		"resource argument": {
+			`
+resource "aws_instance" "a" {
+  ami = var.token
+}
+`,
+			"Ephemeral value not allowed",
+		},
+		"root module output": {
+			`
+output "token" {
+  value = var.token
+}
+`,
+			"Output refers to ephemeral values",
+		},
+		"ephemeral root module output": {
+			`
+output "token" {
+  value     = var.token
+  ephemeral = true
+}
+`,
+			"Ephemeral output not allowed",
+		},
+		"module variable": {
+			`
+module "child" {
+  source = "./child"
+  plain  = var.token
+}
+`,
+			"Ephemeral value not allowed",
+		},
+		"child module output": {
+			`
+module "child" {
+  source = "./child"
+  token  = var.token
+}
+`,
+			"Output refers to ephemeral values",
+		},
+		"for_each": {
+			`
+resource "aws_instance" "a" {
+  for_each = toset([var.token])
+}
+`,
+			"Ephemeral values, or values derived from ephemeral values, cannot be used as for_each arguments",
+		},
+		"count": {
+			`
+resource "aws_instance" "a" {
+  count = length(var.token)
+}
+`,
+			"Ephemeral values, or values derived from ephemeral values, cannot be used as count arguments",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := testModuleInline(t, map[string]string{
+				"main.tf": `
+variable "token" {
+  type      = string
+  default   = "secret"
+  ephemeral = true
+}
+` + test.config,
+				"child/main.tf": `
+variable "plain" {
+  type    = string
+  default = null
+}
+
+variable "token" {
+  type      = string
+  default   = null
+  ephemeral = true
+}
+
+output "token" {
+  value = var.token
+}
+`,
+			})
+
+			p := testProvider("aws")
+			ctx := testContext2(t, &ContextOpts{
+				Providers: map[addrs.Provider]providers.Factory{
+					addrs.NewDefaultProvider("aws"): testProviderFuncFixed(p),
+				},
+			})
+
+			diags := ctx.Validate(m)
+			if !diags.HasErrors() {
+				t.Fatal("succeeded; want error")
+			}
+			if got := diags.Err().Error(); !strings.Contains(got, test.wantSummary) {
+				t.Fatalf("wrong error\ngot:  %s\nwant: message containing %q", got, test.wantSummary)
+			}
+		})
+	}
+}
+
+func TestContext2Validate_dependsOnExpr(t *testing.T) {
+	testCases := map[string]struct {
+		dependsOn string
+		wantErr   string
+	}{
+		"conditional": {
+			dependsOn: `var.wait ? [aws_instance.a, module.child] : []`,
+		},
+		"splat": {
+			dependsOn: `[aws_instance.a[*]]`,
+		},
+		"for expression": {
+			dependsOn: `[for k, v in aws_instance.a : v if k != "skip"]`,
+		},
+		"whole collection": {
+			dependsOn: `values(aws_instance.a)`,
+		},
+		"strings": {
+			dependsOn: `[for name in local.names : name]`,
+			wantErr:   "Invalid depends_on value",
+		},
+		"not a list": {
+			dependsOn: `local.wait`,
+			wantErr:   "Invalid depends_on value",
+		},
+		"undeclared resource": {
+			dependsOn: `var.wait ? [aws_instance.missing] : []`,
+			wantErr:   "Reference to undeclared resource",
+		},
+	}
+
+	p := testProvider("aws")
+	p.GetProviderSchemaResponse = getProviderSchemaResponseFromProviderSchema(&ProviderSchema{
+		ResourceTypes: map[string]*configschema.Block{
+			"aws_instance": {
+				Attributes: map[string]*configschema.Attribute{
+					"foo": {Type: cty.String, Optional: true},
+				},
+			},
+		},
+	})
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			m := testModuleInline(t, map[string]string{
+				"main.tf": fmt.Sprintf(`
+variable "wait" {
+  type    = bool
+  default = true
+}
+
+locals {
+  names = ["a", "b"]
+  wait  = true
+}
+
+resource "aws_instance" "a" {
+  for_each = toset(local.names)
+  foo      = each.key
+}
+
+module "child" {
+  source = "./child"
+}
+
+resource "aws_instance" "b" {
+  depends_on = %s
+}
+`, tc.dependsOn),
+				"child/main.tf": `
+resource "aws_instance" "c" {
+}
+`,
+			})
+
+			ctx := testContext2(t, &ContextOpts{
+				Providers: map[addrs.Provider]providers.Factory{
+					addrs.NewDefaultProvider("aws"): testProviderFuncFixed(p),
+				},
+			})
+
+			diags := ctx.Validate(m)
+			if tc.wantErr == "" {
+				assertNoErrors(t, diags)
+				return
+			}
+			if !diags.HasErrors() {
+				t.Fatalf("succeeded; want error %q", tc.wantErr)
+			}
+			if got := diags.Err().Error(); !strings.Contains(got, tc.wantErr) {
+				t.Fatalf("wrong error\ngot:  %s\nwant: message containing %q", got, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestContext2Validate_outputContractType(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+module "child" {
+  source = "./child"
+  count  = 2
+}
+
+locals {
+  name = module.child[0].names.first
+}
+`,
+		"child/main.tf": `
+output "names" {
+  value = ["a", "b"]
+  type  = list(string)
+}
+`,
+	})
+
+	ctx := testContext2(t, &ContextOpts{})
+
+	// The module isn't expanded during validation, but the declared type of
+	// the output value is enough to catch the invalid reference.
+	diags := ctx.Validate(m)
+	if !diags.HasErrors() {
+		t.Fatal("succeeded; want errors")
+	}
+	if got, want := diags.Err().Error(), "Unsupported attribute"; !strings.Contains(got, want) {
+		t.Fatalf("wrong error:\ngot:  %s\nwant: message containing %q", got, want)
+	}
+}
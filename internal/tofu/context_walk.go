@@ -7,10 +7,12 @@ import (
 	"log"
 	"time"
 
+	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/checks"
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/instances"
 	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/plans/deferring"
 	"github.com/opentofu/opentofu/internal/refactoring"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/tfdiags"
@@ -41,6 +43,30 @@ type graphWalkOpts struct {
 	// the apply phase.
 	PlanTimeTimestamp time.Time
 
+	// DeferralAllowed should be set during the plan phase if the plan may
+	// defer the changes for resources whose instances can't be determined
+	// yet, and PlanTimeDeferredResources and
+	// PlanTimeDeferredResourceInstances should be populated during the
+	// apply phase with the resources and resource instances that were
+	// deferred during planning.
+	DeferralAllowed                   bool
+	PlanTimeDeferredResources         []addrs.AbsResource
+	PlanTimeDeferredResourceInstances []addrs.AbsResourceInstance
+
+	// RefreshBatch should be set during the plan phase if the remote objects
+	// for the managed resource instances in the prior state are to be read
+	// in batches per provider configuration.
+	RefreshBatch *refreshBatch
+
+	// Refinement should be set during the plan phase if the plan refines
+	// an earlier saved plan, reusing its changes where possible.
+	Refinement *planRefinement
+
+	// Discovery should be set during the plan phase if the providers are to
+	// list the remote objects of the managed resource types in the
+	// configuration, to find the ones that OpenTofu doesn't manage yet.
+	Discovery *resourceDiscovery
+
 	MoveResults refactoring.MoveResults
 }
 
@@ -55,10 +81,21 @@ func (c *Context) walk(graph *Graph, operation walkOperation, opts *graphWalkOpt
 	// Walk the real graph, this will block until it completes
 	diags := graph.Walk(walker)
 
+	// The providers are usually closed before the walk completes, but if the
+	// walk was cut short there may still be reads in progress.
+	walker.RefreshBatch.Close()
+
 	// Close the channel so the watcher stops, and wait for it to return.
 	close(watchStop)
 	<-watchWait
 
+	if err := walker.providerFunctions.Close(); err != nil {
+		log.Printf("[WARN] Error closing providers used for functions: %s", err)
+	}
+	if err := c.providerFunctionResults.save(); err != nil {
+		log.Printf("[WARN] Error saving the provider function cache: %s", err)
+	}
+
 	return walker, diags
 }
 
@@ -137,6 +174,14 @@ func (c *Context) graphWalker(operation walkOperation, opts *graphWalkOpts) *Con
 		}
 	}
 
+	deferred := deferring.NewDeferred(opts.DeferralAllowed)
+	for _, addr := range opts.PlanTimeDeferredResources {
+		deferred.ReportResourceDeferred(addr)
+	}
+	for _, addr := range opts.PlanTimeDeferredResourceInstances {
+		deferred.ReportResourceInstanceDeferred(addr)
+	}
+
 	return &ContextGraphWalker{
 		Context:          c,
 		State:            state,
@@ -145,10 +190,15 @@ func (c *Context) graphWalker(operation walkOperation, opts *graphWalkOpts) *Con
 		PrevRunState:     prevRunState,
 		Changes:          changes.SyncWrapper(),
 		Checks:           checkState,
+		Deferred:         deferred,
+		RefreshBatch:     opts.RefreshBatch,
+		Refinement:       opts.Refinement,
+		Discovery:        opts.Discovery,
 		InstanceExpander: instances.NewExpander(),
 		MoveResults:      opts.MoveResults,
 		Operation:        operation,
 		StopContext:      c.runContext,
 		PlanTimestamp:    opts.PlanTimeTimestamp,
+		concurrency:      newConcurrencySemaphores(opts.Config, c.concurrencyLimits),
 	}
 }
@@ -0,0 +1,188 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// selectDrift updates the prior state of the given refresh-only plan so that
+// it accepts only the changes made outside of OpenTofu that the given
+// selection accepts. The objects whose changes aren't accepted, or the
+// values within them, are restored from the previous run state, so that
+// applying the plan leaves them for a later plan to correct.
+//
+// The plan's drifted resources are left unchanged, because they describe
+// all of the changes that were detected. The UI layer compares them with
+// the prior state to show which were accepted.
+func (c *Context) selectDrift(config *configs.Config, plan *plans.Plan, selection *plans.DriftSelection) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	schemas, moreDiags := c.Schemas(config, plan.PriorState)
+	diags = diags.Append(moreDiags)
+	if moreDiags.HasErrors() {
+		return diags
+	}
+
+	priorState := plan.PriorState.SyncWrapper()
+	var drifted []addrs.AbsResourceInstance
+	for _, dr := range plan.DriftedResources {
+		if dr.Action != plans.Update && dr.Action != plans.Delete {
+			// Moves without any other changes are always accepted.
+			continue
+		}
+		drifted = append(drifted, dr.Addr)
+
+		accepted, acceptPaths, rejectPaths := selection.ForInstance(dr.Addr)
+		if accepted && len(rejectPaths) == 0 {
+			continue
+		}
+
+		prevIS := plan.PrevRunState.ResourceInstance(dr.PrevRunAddr)
+		if prevIS == nil || prevIS.Current == nil {
+			// Should never happen, because the object was drifted.
+			continue
+		}
+		prevObjSrc := prevIS.Current
+
+		if dr.Action == plans.Delete {
+			// The paths within an object that was deleted aren't meaningful,
+			// so only the decision for the whole object applies.
+			if !accepted {
+				priorState.SetResourceInstanceCurrent(dr.Addr, prevObjSrc, dr.ProviderAddr)
+			}
+			continue
+		}
+
+		schema, version := schemas.ResourceTypeConfig(dr.ProviderAddr.Provider, dr.Addr.Resource.Resource.Mode, dr.Addr.Resource.Resource.Type)
+		if schema == nil {
+			// Should never happen, because the drift was detected using it.
+			diags = diags.Append(fmt.Errorf("no schema available for %s while selecting drift; this is a bug in OpenTofu", dr.Addr))
+			continue
+		}
+		ty := schema.ImpliedType()
+
+		prevObj, err := prevObjSrc.Decode(ty)
+		if err != nil {
+			diags = diags.Append(fmt.Errorf("failed to decode the previous object for %s: %w", dr.Addr, err))
+			continue
+		}
+		refreshedObj, err := priorState.ResourceInstanceObject(dr.Addr, states.CurrentGen).Decode(ty)
+		if err != nil {
+			diags = diags.Append(fmt.Errorf("failed to decode the refreshed object for %s: %w", dr.Addr, err))
+			continue
+		}
+
+		val := refreshedObj.Value
+		if !accepted {
+			val = prevObj.Value
+		}
+		for _, path := range acceptPaths {
+			val = replaceDriftPath(val, refreshedObj.Value, path)
+		}
+		for _, path := range rejectPaths {
+			val = replaceDriftPath(val, prevObj.Value, path)
+		}
+		if val.RawEquals(refreshedObj.Value) {
+			continue
+		}
+
+		obj := refreshedObj.DeepCopy()
+		obj.Value = val
+		objSrc, err := obj.Encode(ty, version)
+		if err != nil {
+			diags = diags.Append(fmt.Errorf("failed to encode the selected object for %s: %w", dr.Addr, err))
+			continue
+		}
+		priorState.SetResourceInstanceCurrent(dr.Addr, objSrc, dr.ProviderAddr)
+	}
+	plan.PriorState = priorState.Close()
+
+	for _, sels := range [][]plans.DriftSelector{selection.Accept, selection.Reject} {
+		for _, sel := range sels {
+			matched := false
+			for _, addr := range drifted {
+				if sel.Target.TargetContains(addr) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				diags = diags.Append(tfdiags.Sourceless(
+					tfdiags.Warning,
+					"No changes to select",
+					fmt.Sprintf("OpenTofu detected no changes made outside of OpenTofu to %s, so selecting its changes has no effect.", sel.Target),
+				))
+			}
+		}
+	}
+
+	return diags
+}
+
+// replaceDriftPath returns dst with the value at the given path replaced by
+// the value at the same path in src. If the path ends with the key of a map
+// element that src doesn't have, the element is removed from dst instead.
+func replaceDriftPath(dst, src cty.Value, path cty.Path) cty.Value {
+	if step, ok := path[len(path)-1].(cty.IndexStep); ok && step.Key.Type() == cty.String {
+		parentPath := path[:len(path)-1]
+		dstMap, dstErr := parentPath.Apply(dst)
+		srcMap, srcErr := parentPath.Apply(src)
+		if dstErr == nil && srcErr == nil && dstMap.Type().IsMapType() {
+			dstMap, dstMarks := dstMap.Unmark()
+			srcMap, _ = srcMap.Unmark()
+			if !dstMap.IsKnown() || !srcMap.IsKnown() {
+				return dst
+			}
+
+			elems := make(map[string]cty.Value)
+			if !dstMap.IsNull() {
+				for k, v := range dstMap.AsValueMap() {
+					elems[k] = v
+				}
+			}
+			key := step.Key.AsString()
+			if !srcMap.IsNull() && srcMap.HasIndex(step.Key).True() {
+				elems[key] = srcMap.Index(step.Key)
+			} else {
+				delete(elems, key)
+			}
+
+			var newMap cty.Value
+			switch {
+			case len(elems) != 0:
+				newMap = cty.MapVal(elems)
+			case dstMap.IsNull():
+				newMap = dstMap
+			default:
+				newMap = cty.MapValEmpty(dstMap.Type().ElementType())
+			}
+			return replaceAtPath(dst, parentPath, newMap.WithMarks(dstMarks))
+		}
+	}
+
+	val, err := path.Apply(src)
+	if err != nil {
+		return dst
+	}
+	return replaceAtPath(dst, path, val)
+}
+
+func replaceAtPath(v cty.Value, path cty.Path, replacement cty.Value) cty.Value {
+	ret, _ := cty.Transform(v, func(p cty.Path, v cty.Value) (cty.Value, error) {
+		if p.Equals(path) {
+			return replacement, nil
+		}
+		return v, nil
+	})
+	return ret
+}
@@ -0,0 +1,152 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"log"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/lang"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+)
+
+// evaluateChangeCauses returns the causes of the changes to each of the
+// top-level attributes and nested block types of the object described by
+// the given planned change, to help explain the plan in the UI.
+//
+// The causes are determined heuristically, in the following order:
+//   - If the remote object drifted from the previous run state during refresh
+//     and the plan restores the previous value, the cause is the drift.
+//   - If the configuration for the attribute refers to other resources with
+//     changes pending, the cause is those upstream resources.
+//   - If the attribute is set in the configuration, the cause is the
+//     configuration itself.
+//   - If the object is being replaced, the cause is the replacement.
+//
+// Attributes which change for any other reason, such as when the provider
+// decided to change them itself, are omitted.
+func evaluateChangeCauses(ctx EvalContext, config *configs.Resource, schema *configschema.Block, change *plans.ResourceInstanceChange) []plans.AttributeChangeCause {
+	if change.Action != plans.Update && !change.Action.IsReplace() {
+		return nil
+	}
+	before, _ := change.Before.UnmarkDeep()
+	after, _ := change.After.UnmarkDeep()
+	if before.IsNull() || after.IsNull() || !before.IsKnown() || !after.IsKnown() {
+		return nil
+	}
+
+	prevRun := cty.NullVal(schema.ImpliedType())
+	if prevRunState := ctx.PrevRunState(); prevRunState != nil {
+		if obj := prevRunState.ResourceInstanceObject(change.PrevRunAddr, states.CurrentGen); obj != nil {
+			if decoded, err := obj.Decode(schema.ImpliedType()); err == nil {
+				prevRun, _ = decoded.Value.UnmarkDeep()
+			} else {
+				log.Printf("[TRACE] evaluateChangeCauses: can't decode previous run state of %s: %s", change.Addr, err)
+			}
+		}
+	}
+
+	var content *hcl.BodyContent
+	if config != nil && config.Config != nil {
+		content, _, _ = config.Config.PartialContent(hcldec.ImpliedSchema(schema.DecoderSpec()))
+	}
+
+	var names []string
+	for name := range schema.Attributes {
+		names = append(names, name)
+	}
+	for name := range schema.BlockTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var causes []plans.AttributeChangeCause
+	for _, name := range names {
+		beforeVal := before.GetAttr(name)
+		afterVal := after.GetAttr(name)
+		if beforeVal.RawEquals(afterVal) {
+			continue
+		}
+		cause := plans.AttributeChangeCause{
+			Attribute: name,
+		}
+
+		var refs []*addrs.Reference
+		var rng *hcl.Range
+		if content != nil {
+			if attr, ok := content.Attributes[name]; ok {
+				refs, _ = lang.ReferencesInExpr(addrs.ParseRef, attr.Expr)
+				rng = attr.Expr.Range().Ptr()
+			} else if blockS, ok := schema.BlockTypes[name]; ok {
+				for _, block := range content.Blocks.OfType(name) {
+					blockRefs, _ := lang.ReferencesInBlock(addrs.ParseRef, block.Body, &blockS.Block)
+					refs = append(refs, blockRefs...)
+					if rng == nil {
+						rng = block.DefRange.Ptr()
+					}
+				}
+			}
+		}
+
+		switch {
+		case !prevRun.IsNull() && !prevRun.GetAttr(name).RawEquals(beforeVal) && prevRun.GetAttr(name).RawEquals(afterVal):
+			cause.Cause = plans.ChangeCauseDrift
+		case rng != nil:
+			cause.Cause = plans.ChangeCauseConfig
+			cause.Filename = rng.Filename
+			cause.Line = rng.Start.Line
+			if upstream := upstreamChanges(ctx, change.Addr.Module, refs); len(upstream) > 0 {
+				cause.Cause = plans.ChangeCauseUpstream
+				cause.Upstream = upstream
+			}
+		case change.Action.IsReplace():
+			cause.Cause = plans.ChangeCauseReplacement
+		default:
+			continue
+		}
+		causes = append(causes, cause)
+	}
+	return causes
+}
+
+// upstreamChanges returns the resources among the given references from the
+// given module instance which have changes pending in the plan so far.
+func upstreamChanges(ctx EvalContext, module addrs.ModuleInstance, refs []*addrs.Reference) []addrs.AbsResource {
+	var ret []addrs.AbsResource
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		var resource addrs.Resource
+		switch subject := ref.Subject.(type) {
+		case addrs.Resource:
+			resource = subject
+		case addrs.ResourceInstance:
+			resource = subject.Resource
+		default:
+			continue
+		}
+		addr := resource.Absolute(module)
+		if seen[addr.String()] {
+			continue
+		}
+		for _, rc := range ctx.Changes().GetChangesForAbsResource(addr) {
+			if rc.Action != plans.NoOp {
+				seen[addr.String()] = true
+				ret = append(ret, addr)
+				break
+			}
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Less(ret[j])
+	})
+	return ret
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/convert"
 
@@ -71,13 +72,21 @@ type checkResult struct {
 }
 
 func validateCheckRule(addr addrs.CheckRule, rule *configs.CheckRule, ctx EvalContext, keyData instances.RepetitionData) (string, *hcl.EvalContext, tfdiags.Diagnostics) {
-	var diags tfdiags.Diagnostics
+	hclCtx, diags := checkRuleEvalContext(addr, rule, ctx, keyData)
 
-	refs, moreDiags := lang.ReferencesInExpr(addrs.ParseRef, rule.Condition)
+	errorMessage, moreDiags := evalCheckErrorMessage(rule.ErrorMessage, hclCtx)
 	diags = diags.Append(moreDiags)
-	moreRefs, moreDiags := lang.ReferencesInExpr(addrs.ParseRef, rule.ErrorMessage)
+
+	return errorMessage, hclCtx, diags
+}
+
+// checkRuleEvalContext returns the HCL evaluation context for the expressions
+// of the given check rule, which has the objects they refer to in scope.
+func checkRuleEvalContext(addr addrs.CheckRule, rule *configs.CheckRule, ctx EvalContext, keyData instances.RepetitionData) (*hcl.EvalContext, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	refs, moreDiags := lang.References(addrs.ParseRef, rule.Variables())
 	diags = diags.Append(moreDiags)
-	refs = append(refs, moreRefs...)
 
 	var selfReference, sourceReference addrs.Referenceable
 	switch addr.Type {
@@ -104,17 +113,106 @@ func validateCheckRule(addr addrs.CheckRule, rule *configs.CheckRule, ctx EvalCo
 	hclCtx, moreDiags := scope.EvalContext(refs)
 	diags = diags.Append(moreDiags)
 
-	errorMessage, moreDiags := evalCheckErrorMessage(rule.ErrorMessage, hclCtx)
-	diags = diags.Append(moreDiags)
-
-	return errorMessage, hclCtx, diags
+	return hclCtx, diags
 }
 
 func evalCheckRule(addr addrs.CheckRule, rule *configs.CheckRule, ctx EvalContext, keyData instances.RepetitionData, severity hcl.DiagnosticSeverity) (checkResult, tfdiags.Diagnostics) {
+	if rule.ForEach != nil {
+		return evalDynamicCheckRule(addr, rule, ctx, keyData, severity)
+	}
+
+	// NOTE: Intentionally not passing the caller's selected severity in here,
+	// because this reports errors in the configuration itself, not the failure
+	// of an otherwise-valid condition.
+	hclCtx, diags := checkRuleEvalContext(addr, rule, ctx, keyData)
+
+	return evalCheckRuleCondition(addr, rule, hclCtx, severity, diags)
+}
+
+// evalDynamicCheckRule evaluates a check rule declared by a "dynamic" block,
+// which holds only if its condition holds for every element of its for_each
+// value.
+//
+// The result is the most severe of the results for the individual elements,
+// and so if any of the conditions fail then the failure message is the one
+// for the first element whose condition failed.
+func evalDynamicCheckRule(addr addrs.CheckRule, rule *configs.CheckRule, ctx EvalContext, keyData instances.RepetitionData, severity hcl.DiagnosticSeverity) (checkResult, tfdiags.Diagnostics) {
+	hclCtx, diags := checkRuleEvalContext(addr, rule, ctx, keyData)
+	if diags.HasErrors() {
+		return checkResult{Status: checks.StatusError}, diags
+	}
+
+	forEachVal, hclDiags := rule.ForEach.Value(hclCtx)
+	diags = diags.Append(hclDiags)
+	if hclDiags.HasErrors() {
+		return checkResult{Status: checks.StatusError}, diags
+	}
+
+	// As for dynamic blocks elsewhere, any marks on the collection as a
+	// whole apply to each of its elements.
+	forEachVal, marks := forEachVal.Unmark()
+	if !forEachVal.IsKnown() {
+		// We'll wait until we've learned more, then.
+		return checkResult{Status: checks.StatusUnknown}, diags
+	}
+	if forEachVal.IsNull() || !forEachVal.CanIterateElements() {
+		detail := "Cannot use a null value in for_each."
+		if !forEachVal.IsNull() {
+			detail = fmt.Sprintf("Cannot use a %s value in for_each. An iterable collection is required.", forEachVal.Type().FriendlyName())
+		}
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity:    hcl.DiagError,
+			Summary:     "Invalid dynamic for_each value",
+			Detail:      detail,
+			Subject:     rule.ForEach.Range().Ptr(),
+			Expression:  rule.ForEach,
+			EvalContext: hclCtx,
+		})
+		return checkResult{Status: checks.StatusError}, diags
+	}
+
+	result := checkResult{Status: checks.StatusPass}
+	for it := forEachVal.ElementIterator(); it.Next(); {
+		k, v := it.Element()
+
+		elemCtx := hclCtx.NewChild()
+		elemCtx.Variables = map[string]cty.Value{
+			rule.Iterator: cty.ObjectVal(map[string]cty.Value{
+				"key":   k.WithMarks(marks),
+				"value": v.WithMarks(marks),
+			}),
+		}
+
+		elemResult, moreDiags := evalCheckRuleCondition(addr, rule, elemCtx, severity, nil)
+		diags = diags.Append(moreDiags)
+
+		switch {
+		case result.Status == checks.StatusError:
+			// Nothing is more severe than an error.
+		case elemResult.Status == checks.StatusError:
+			result = elemResult
+		case elemResult.Status == checks.StatusFail && result.Status != checks.StatusFail:
+			result = elemResult
+		case elemResult.Status == checks.StatusUnknown && result.Status == checks.StatusPass:
+			result = elemResult
+		}
+	}
+
+	return result, diags
+}
+
+// evalCheckRuleCondition evaluates the condition and error message of the
+// given check rule in the given HCL evaluation context.
+//
+// The given diagnostics are any that the caller already produced while
+// preparing the evaluation context. They are included in the result, and
+// any errors among them cause the rule to have an error status.
+func evalCheckRuleCondition(addr addrs.CheckRule, rule *configs.CheckRule, hclCtx *hcl.EvalContext, severity hcl.DiagnosticSeverity, diags tfdiags.Diagnostics) (checkResult, tfdiags.Diagnostics) {
 	// NOTE: Intentionally not passing the caller's selected severity in here,
 	// because this reports errors in the configuration itself, not the failure
 	// of an otherwise-valid condition.
-	errorMessage, hclCtx, diags := validateCheckRule(addr, rule, ctx, keyData)
+	errorMessage, moreDiags := evalCheckErrorMessage(rule.ErrorMessage, hclCtx)
+	diags = diags.Append(moreDiags)
 
 	const errInvalidCondition = "Invalid condition result"
 
@@ -126,6 +224,16 @@ func evalCheckRule(addr addrs.CheckRule, rule *configs.CheckRule, ctx EvalContex
 		return checkResult{Status: checks.StatusError}, diags
 	}
 
+	if !resultVal.IsKnown() {
+		// The condition might still be decided by its known parts, such as
+		// when it refers to planned values of other resources that are only
+		// partially known during planning.
+		if known, ok := shortCircuitCondition(rule.Condition, hclCtx); ok {
+			log.Printf("[TRACE] evalCheckRule: %s result decided by the known operands of its condition", addr)
+			resultVal = known
+		}
+	}
+
 	if !resultVal.IsKnown() {
 
 		// Check assertions warn if a status is unknown.
@@ -213,6 +321,75 @@ func evalCheckRule(addr addrs.CheckRule, rule *configs.CheckRule, ctx EvalContex
 	}, diags
 }
 
+// shortCircuitCondition tries to find a known result for a condition
+// expression whose result is unknown, by applying the usual short-circuit
+// rules of the logical operators to its operands: a logical AND with a known
+// false operand is false, and a logical OR with a known true operand is true,
+// regardless of the other operand.
+//
+// HCL itself always returns an unknown result for a logical operator with an
+// unknown operand, so without this a condition like
+// "var.enabled && aws_lb.main.vpc_id == var.vpc_id" couldn't be decided
+// during planning even if var.enabled is false.
+//
+// The second return value is false if the result can't be decided from the
+// known operands, in which case the caller should treat the condition result
+// as unknown.
+func shortCircuitCondition(expr hcl.Expression, hclCtx *hcl.EvalContext) (cty.Value, bool) {
+	switch expr := expr.(type) {
+	case *hclsyntax.ParenthesesExpr:
+		return shortCircuitCondition(expr.Expression, hclCtx)
+	case *hclsyntax.UnaryOpExpr:
+		if expr.Op != hclsyntax.OpLogicalNot {
+			return cty.NilVal, false
+		}
+		val, ok := shortCircuitCondition(expr.Val, hclCtx)
+		if !ok {
+			return cty.NilVal, false
+		}
+		return val.Not(), true
+	case *hclsyntax.BinaryOpExpr:
+		var decisive cty.Value
+		switch expr.Op {
+		case hclsyntax.OpLogicalAnd:
+			decisive = cty.False
+		case hclsyntax.OpLogicalOr:
+			decisive = cty.True
+		default:
+			return cty.NilVal, false
+		}
+		for _, operand := range []hclsyntax.Expression{expr.LHS, expr.RHS} {
+			if val, ok := conditionOperandValue(operand, hclCtx); ok && val.RawEquals(decisive) {
+				return decisive, true
+			}
+		}
+		return cty.NilVal, false
+	default:
+		return cty.NilVal, false
+	}
+}
+
+// conditionOperandValue returns the known, non-null boolean value of the
+// given operand of a logical operator, if it has one.
+func conditionOperandValue(expr hclsyntax.Expression, hclCtx *hcl.EvalContext) (cty.Value, bool) {
+	val, diags := expr.Value(hclCtx)
+	if diags.HasErrors() {
+		return cty.NilVal, false
+	}
+	if !val.IsKnown() {
+		return shortCircuitCondition(expr, hclCtx)
+	}
+	val, _ = val.UnmarkDeep()
+	if val.IsNull() {
+		return cty.NilVal, false
+	}
+	val, err := convert.Convert(val, cty.Bool)
+	if err != nil {
+		return cty.NilVal, false
+	}
+	return val, true
+}
+
 // evalCheckErrorMessage makes a best effort to evaluate the given expression,
 // as an error message string.
 //
@@ -269,9 +446,22 @@ You can correct this by removing references to sensitive values, or by carefully
 		})
 		return "", diags
 	}
+	if _, ephemeral := valMarks[marks.Ephemeral]; ephemeral {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Error message refers to ephemeral values",
+			Detail: `The error expression used to explain this condition refers to ephemeral values, so OpenTofu will not display the resulting message.
+
+You can correct this by removing references to ephemeral values.`,
+			Subject:     expr.Range().Ptr(),
+			Expression:  expr,
+			EvalContext: hclCtx,
+		})
+		return "", diags
+	}
 
 	// NOTE: We've discarded any other marks the string might have been carrying,
-	// aside from the sensitive mark.
+	// aside from the sensitive and ephemeral marks.
 
 	return strings.TrimSpace(val.AsString()), diags
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/opentofu/opentofu/internal/instances"
 	"github.com/opentofu/opentofu/internal/lang"
 	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/plans/deferring"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/provisioners"
 	"github.com/opentofu/opentofu/internal/refactoring"
@@ -74,6 +75,12 @@ type EvalContext interface {
 	// provider configuration does not match the Path() of the EvalContext.
 	ConfigureProvider(addrs.AbsProviderConfig, cty.Value) tfdiags.Diagnostics
 
+	// ProviderConfig returns the configuration that the given provider was
+	// successfully configured with by ConfigureProvider during this walk,
+	// or cty.NilVal if it hasn't been configured yet. Unlike
+	// ConfigureProvider, it can be used from any module.
+	ProviderConfig(addrs.AbsProviderConfig) cty.Value
+
 	// ProviderInput and SetProviderInput are used to configure providers
 	// from user input.
 	//
@@ -172,6 +179,25 @@ type EvalContext interface {
 	// declared in the configuration.
 	Checks() *checks.State
 
+	// Deferred returns the object that tracks the resources whose changes
+	// are deferred to a later plan.
+	Deferred() *deferring.Deferred
+
+	// RefreshBatch returns the object that reads the current remote objects
+	// for managed resource instances ahead of the nodes that plan them, or
+	// nil if there is no such batch in the current walk.
+	RefreshBatch() *refreshBatch
+
+	// Refinement returns the object that tracks which planned changes can
+	// be reused from an earlier saved plan, or nil if the current walk
+	// doesn't refine a saved plan.
+	Refinement() *planRefinement
+
+	// Discovery returns the object that collects the remote objects which
+	// providers list for the managed resource types in the configuration,
+	// or nil if the current walk doesn't discover them.
+	Discovery() *resourceDiscovery
+
 	// RefreshState returns a wrapper object that provides safe concurrent
 	// access to the state used to store the most recently refreshed resource
 	// values.
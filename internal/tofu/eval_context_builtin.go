@@ -18,6 +18,7 @@ import (
 	"github.com/opentofu/opentofu/internal/instances"
 	"github.com/opentofu/opentofu/internal/lang"
 	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/plans/deferring"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/provisioners"
 	"github.com/opentofu/opentofu/internal/refactoring"
@@ -59,16 +60,26 @@ type BuiltinEvalContext struct {
 	// available for use during a graph walk.
 	Plugins *contextPlugins
 
+	// ProviderFunctions makes the functions offered by providers available
+	// to expressions. It may be nil, in which case no provider functions are
+	// available.
+	ProviderFunctions *providerFunctions
+
 	Hooks                 []Hook
 	InputValue            UIInput
 	ProviderCache         map[string]providers.Interface
 	ProviderInputConfig   map[string]map[string]cty.Value
+	ProviderConfigs       map[string]cty.Value
 	ProviderLock          *sync.Mutex
 	ProvisionerCache      map[string]provisioners.Interface
 	ProvisionerLock       *sync.Mutex
 	ChangesValue          *plans.ChangesSync
 	StateValue            *states.SyncState
 	ChecksValue           *checks.State
+	DeferredValue         *deferring.Deferred
+	RefreshBatchValue     *refreshBatch
+	RefinementValue       *planRefinement
+	DiscoveryValue        *resourceDiscovery
 	RefreshStateValue     *states.SyncState
 	PrevRunStateValue     *states.SyncState
 	InstanceExpanderValue *instances.Expander
@@ -131,7 +142,7 @@ func (ctx *BuiltinEvalContext) InitProvider(addr addrs.AbsProviderConfig) (provi
 
 	key := addr.String()
 
-	p, err := ctx.Plugins.NewProviderInstance(addr.Provider)
+	p, err := ctx.Plugins.NewProviderConfigInstance(addr)
 	if err != nil {
 		return nil, err
 	}
@@ -202,9 +213,21 @@ func (ctx *BuiltinEvalContext) ConfigureProvider(addr addrs.AbsProviderConfig, c
 	}
 
 	resp := p.ConfigureProvider(req)
+	if !resp.Diagnostics.HasErrors() && ctx.ProviderConfigs != nil {
+		ctx.ProviderLock.Lock()
+		ctx.ProviderConfigs[addr.String()] = cfg
+		ctx.ProviderLock.Unlock()
+	}
 	return resp.Diagnostics
 }
 
+func (ctx *BuiltinEvalContext) ProviderConfig(addr addrs.AbsProviderConfig) cty.Value {
+	ctx.ProviderLock.Lock()
+	defer ctx.ProviderLock.Unlock()
+
+	return ctx.ProviderConfigs[addr.String()]
+}
+
 func (ctx *BuiltinEvalContext) ProviderInput(pc addrs.AbsProviderConfig) map[string]cty.Value {
 	ctx.ProviderLock.Lock()
 	defer ctx.ProviderLock.Unlock()
@@ -421,9 +444,13 @@ func (ctx *BuiltinEvalContext) EvaluationScope(self addrs.Referenceable, source
 	// be consistent with how experiment checking in the "configs"
 	// package itself works. The nil check here is for robustness in
 	// incompletely-mocked testing situations; mc should never be nil in
-	// real situations.
+	// real situations. The functions defined in the module are made available
+	// in the same way.
 	if mc := ctx.Evaluator.Config.DescendentForInstance(ctx.PathValue); mc != nil {
 		scope.SetActiveExperiments(mc.Module.ActiveExperiments)
+		scope.SetUserFunctions(mc.Module.UserFunctions())
+		scope.SetProviderFunctions(ctx.ProviderFunctions.ForModule(mc.Module))
+		scope.SetUnavailableProviderFunctions(ctx.ProviderFunctions.UnavailableForModule(mc.Module))
 	}
 	return scope
 }
@@ -493,6 +520,22 @@ func (ctx *BuiltinEvalContext) Checks() *checks.State {
 	return ctx.ChecksValue
 }
 
+func (ctx *BuiltinEvalContext) Deferred() *deferring.Deferred {
+	return ctx.DeferredValue
+}
+
+func (ctx *BuiltinEvalContext) RefreshBatch() *refreshBatch {
+	return ctx.RefreshBatchValue
+}
+
+func (ctx *BuiltinEvalContext) Refinement() *planRefinement {
+	return ctx.RefinementValue
+}
+
+func (ctx *BuiltinEvalContext) Discovery() *resourceDiscovery {
+	return ctx.DiscoveryValue
+}
+
 func (ctx *BuiltinEvalContext) RefreshState() *states.SyncState {
 	return ctx.RefreshStateValue
 }
@@ -86,6 +86,37 @@ func TestBuildingEvalContextInitProvider(t *testing.T) {
 	}
 }
 
+func TestBuildingEvalContextInitProvider_configFactory(t *testing.T) {
+	var lock sync.Mutex
+
+	ctx := testBuiltinEvalContext(t)
+	ctx = ctx.WithPath(addrs.RootModuleInstance).(*BuiltinEvalContext)
+	ctx.ProviderLock = &lock
+	ctx.ProviderCache = make(map[string]providers.Interface)
+	ctx.Plugins = newContextPlugins(map[addrs.Provider]providers.Factory{
+		addrs.NewDefaultProvider("test"): providers.FactoryFixed(&MockProvider{}),
+	}, nil)
+	var gotAddrs []string
+	ctx.Plugins.providerConfigFactories = map[addrs.Provider]providers.ConfigFactory{
+		addrs.NewDefaultProvider("test"): func(addr addrs.AbsProviderConfig) (providers.Interface, error) {
+			gotAddrs = append(gotAddrs, addr.String())
+			return &MockProvider{}, nil
+		},
+	}
+
+	providerAddr := addrs.AbsProviderConfig{
+		Module:   addrs.RootModule,
+		Provider: addrs.NewDefaultProvider("test"),
+		Alias:    "foo",
+	}
+	if _, err := ctx.InitProvider(providerAddr); err != nil {
+		t.Fatalf("error initializing provider test.foo: %s", err)
+	}
+	if len(gotAddrs) != 1 || gotAddrs[0] != providerAddr.String() {
+		t.Fatalf("config factory was called for %q; want %q", gotAddrs, providerAddr)
+	}
+}
+
 func testBuiltinEvalContext(t *testing.T) *BuiltinEvalContext {
 	return &BuiltinEvalContext{}
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/opentofu/opentofu/internal/instances"
 	"github.com/opentofu/opentofu/internal/lang"
 	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/plans/deferring"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/provisioners"
 	"github.com/opentofu/opentofu/internal/refactoring"
@@ -69,6 +70,10 @@ type MockEvalContext struct {
 	ConfigureProviderConfig cty.Value
 	ConfigureProviderDiags  tfdiags.Diagnostics
 
+	ProviderConfigCalled bool
+	ProviderConfigAddr   addrs.AbsProviderConfig
+	ProviderConfigValue  cty.Value
+
 	ProvisionerCalled      bool
 	ProvisionerName        string
 	ProvisionerProvisioner provisioners.Interface
@@ -140,6 +145,18 @@ type MockEvalContext struct {
 	ChecksCalled bool
 	ChecksState  *checks.State
 
+	DeferredCalled bool
+	DeferredState  *deferring.Deferred
+
+	RefreshBatchCalled bool
+	RefreshBatchState  *refreshBatch
+
+	RefinementCalled bool
+	RefinementState  *planRefinement
+
+	DiscoveryCalled bool
+	DiscoveryState  *resourceDiscovery
+
 	RefreshStateCalled bool
 	RefreshStateState  *states.SyncState
 
@@ -213,6 +230,12 @@ func (c *MockEvalContext) ConfigureProvider(addr addrs.AbsProviderConfig, cfg ct
 	return c.ConfigureProviderDiags
 }
 
+func (c *MockEvalContext) ProviderConfig(addr addrs.AbsProviderConfig) cty.Value {
+	c.ProviderConfigCalled = true
+	c.ProviderConfigAddr = addr
+	return c.ProviderConfigValue
+}
+
 func (c *MockEvalContext) ProviderInput(addr addrs.AbsProviderConfig) map[string]cty.Value {
 	c.ProviderInputCalled = true
 	c.ProviderInputAddr = addr
@@ -383,6 +406,26 @@ func (c *MockEvalContext) Checks() *checks.State {
 	return c.ChecksState
 }
 
+func (c *MockEvalContext) Deferred() *deferring.Deferred {
+	c.DeferredCalled = true
+	return c.DeferredState
+}
+
+func (c *MockEvalContext) RefreshBatch() *refreshBatch {
+	c.RefreshBatchCalled = true
+	return c.RefreshBatchState
+}
+
+func (c *MockEvalContext) Refinement() *planRefinement {
+	c.RefinementCalled = true
+	return c.RefinementState
+}
+
+func (c *MockEvalContext) Discovery() *resourceDiscovery {
+	c.DiscoveryCalled = true
+	return c.DiscoveryState
+}
+
 func (c *MockEvalContext) RefreshState() *states.SyncState {
 	c.RefreshStateCalled = true
 	return c.RefreshStateState
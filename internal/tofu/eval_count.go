@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/opentofu/opentofu/internal/lang/marks"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/gocty"
@@ -69,7 +70,16 @@ func evaluateCountExpressionValue(expr hcl.Expression, ctx EvalContext) (cty.Val
 
 	// Unmark the count value, sensitive values are allowed in count but not for_each,
 	// as using it here will not disclose the sensitive value
-	countVal, _ = countVal.Unmark()
+	countVal, countMarks := countVal.Unmark()
+	if _, ephemeral := countMarks[marks.Ephemeral]; ephemeral {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid count argument",
+			Detail:   `Ephemeral values, or values derived from ephemeral values, cannot be used as count arguments, because the number of instances is saved in the plan and state.`,
+			Subject:  expr.Range().Ptr(),
+		})
+		return nullCount, diags
+	}
 
 	switch {
 	case countVal.IsNull():
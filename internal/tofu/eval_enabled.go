@@ -0,0 +1,71 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// evaluateEnabledExpression is our standard mechanism for interpreting an
+// expression given for an "enabled" argument on a module call. This should be
+// called during expansion in order to determine whether the module call has
+// an instance.
+//
+// evaluateEnabledExpression differs from evaluateEnabledExpressionValue by
+// returning an error if the value is not known, and converting the cty.Value
+// to a bool.
+func evaluateEnabledExpression(expr hcl.Expression, ctx EvalContext) (bool, tfdiags.Diagnostics) {
+	enabledVal, diags := evaluateEnabledExpressionValue(expr, ctx)
+	if diags.HasErrors() {
+		return false, diags
+	}
+	if !enabledVal.IsKnown() {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid enabled argument",
+			Detail:   `The "enabled" value depends on resource attributes that cannot be determined until apply, so OpenTofu cannot predict whether the module will be enabled. To work around this, use the -target argument to first apply only the resources that the enabled argument depends on.`,
+			Subject:  expr.Range().Ptr(),
+			Extra:    diagnosticCausedByUnknown(true),
+		})
+		return false, diags
+	}
+
+	return enabledVal.True(), diags
+}
+
+// evaluateEnabledExpressionValue is like evaluateEnabledExpression
+// except that it returns a cty.Value which must be a cty.Bool and can be
+// unknown.
+func evaluateEnabledExpressionValue(expr hcl.Expression, ctx EvalContext) (cty.Value, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	nullEnabled := cty.NullVal(cty.Bool)
+	if expr == nil {
+		return nullEnabled, nil
+	}
+
+	enabledVal, enabledDiags := ctx.EvaluateExpr(expr, cty.Bool, nil)
+	diags = diags.Append(enabledDiags)
+	if diags.HasErrors() {
+		return nullEnabled, diags
+	}
+
+	// As with count, a sensitive value is allowed here because whether the
+	// module has an instance doesn't disclose the value itself.
+	enabledVal, _ = enabledVal.Unmark()
+
+	if enabledVal.IsNull() {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid enabled argument",
+			Detail:   `The given "enabled" argument value is null. A boolean is required.`,
+			Subject:  expr.Range().Ptr(),
+		})
+		return nullEnabled, diags
+	}
+
+	return enabledVal, diags
+}
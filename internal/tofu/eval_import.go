@@ -2,8 +2,12 @@ package tofu
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/instances"
 	"github.com/opentofu/opentofu/internal/lang/marks"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/zclconf/go-cty/cty"
@@ -25,6 +29,13 @@ func evaluateImportIdExpression(expr hcl.Expression, ctx EvalContext) (string, t
 	importIdVal, evalDiags := ctx.EvaluateExpr(expr, cty.String, nil)
 	diags = diags.Append(evalDiags)
 
+	return importIdFromValue(expr, importIdVal, diags)
+}
+
+// importIdFromValue returns the import ID from the result of evaluating the
+// given import ID expression, with errors for values that are not suitable
+// as import IDs appended to the given diagnostics.
+func importIdFromValue(expr hcl.Expression, importIdVal cty.Value, diags tfdiags.Diagnostics) (string, tfdiags.Diagnostics) {
 	if importIdVal.IsNull() {
 		return "", diags.Append(&hcl.Diagnostic{
 			Severity: hcl.DiagError,
@@ -68,3 +79,144 @@ func evaluateImportIdExpression(expr hcl.Expression, ctx EvalContext) (string, t
 
 	return importId, diags
 }
+
+// expandImportTargets returns the import targets for the instances of the
+// given resource, replacing each import target from an import block with
+// for_each by one import target for each of its elements.
+//
+// The expanded import targets have the import ID already evaluated, since the
+// ID can refer to each.key and each.value. Elements that target a resource
+// instance that already exists in the state are skipped, because there is
+// nothing to import.
+func expandImportTargets(ctx EvalContext, addr addrs.AbsResource, targets []*ImportTarget, instanceAddrs []addrs.AbsResourceInstance) ([]*ImportTarget, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	var ret []*ImportTarget
+
+	seen := make(map[string]*configs.Import)
+	for _, target := range targets {
+		if target.Config == nil || target.Config.ForEach == nil {
+			ret = append(ret, target)
+			continue
+		}
+		if !target.Addr.ContainingResource().Equal(addr) {
+			continue
+		}
+		cfg := target.Config
+
+		forEach, forEachDiags := evaluateForEachExpression(cfg.ForEach, ctx)
+		diags = diags.Append(forEachDiags)
+		if forEachDiags.HasErrors() {
+			continue
+		}
+
+		keys := make([]string, 0, len(forEach))
+		for k := range forEach {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			keyData := instances.RepetitionData{
+				EachKey:   cty.StringVal(k),
+				EachValue: forEach[k],
+			}
+
+			instAddr, moreDiags := evaluateImportAddress(ctx, addr, cfg.ToKey, keyData)
+			diags = diags.Append(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			if other, exists := seen[instAddr.String()]; exists {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Duplicate import target",
+					Detail:   fmt.Sprintf("More than one element of the import blocks at %s and %s targets the resource instance %s. Each resource instance can be imported only once.", other.DeclRange, cfg.DeclRange, instAddr),
+					Subject:  cfg.ToKey.Range().Ptr(),
+				})
+				continue
+			}
+			seen[instAddr.String()] = cfg
+
+			found := false
+			for _, a := range instanceAddrs {
+				if a.Equal(instAddr) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Cannot import to non-existent resource address",
+					Detail:   fmt.Sprintf("Importing to resource address %s is not possible, because that address does not exist in configuration. Please ensure that the for_each argument of the import block produces only keys of existing resource instances.", instAddr),
+					Subject:  cfg.ToKey.Range().Ptr(),
+				})
+				continue
+			}
+
+			if ctx.State().ResourceInstance(instAddr) != nil {
+				continue
+			}
+
+			importIdVal, moreDiags := ctx.EvaluationScope(nil, nil, keyData).EvalExpr(cfg.ID, cty.String)
+			importId, moreDiags := importIdFromValue(cfg.ID, importIdVal, moreDiags)
+			diags = diags.Append(moreDiags)
+			if moreDiags.HasErrors() {
+				continue
+			}
+
+			ret = append(ret, &ImportTarget{
+				Config: cfg,
+				Addr:   instAddr,
+				ID:     hcl.StaticExpr(cty.StringVal(importId), cfg.ID.Range()),
+			})
+		}
+	}
+
+	return ret, diags
+}
+
+// evaluateImportAddress returns the address of the resource instance that an
+// element of an import block with for_each targets, by evaluating the
+// instance key expression of its "to" argument.
+func evaluateImportAddress(ctx EvalContext, addr addrs.AbsResource, keyExpr hcl.Expression, keyData instances.RepetitionData) (addrs.AbsResourceInstance, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	keyVal, evalDiags := ctx.EvaluationScope(nil, nil, keyData).EvalExpr(keyExpr, cty.DynamicPseudoType)
+	diags = diags.Append(evalDiags)
+	if evalDiags.HasErrors() {
+		return addrs.AbsResourceInstance{}, diags
+	}
+
+	var detail string
+	switch {
+	case keyVal.IsNull():
+		detail = "The instance key cannot be null."
+	case !keyVal.IsKnown():
+		detail = "The instance key depends on values that cannot be determined until apply, so OpenTofu cannot plan to import this resource instance."
+	case keyVal.HasMark(marks.Sensitive):
+		detail = "The instance key cannot be sensitive."
+	}
+	if detail != "" {
+		return addrs.AbsResourceInstance{}, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid import address",
+			Detail:   detail,
+			Subject:  keyExpr.Range().Ptr(),
+		})
+	}
+
+	keyVal, _ = keyVal.Unmark()
+	key, err := addrs.ParseInstanceKey(keyVal)
+	if err != nil {
+		return addrs.AbsResourceInstance{}, diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid import address",
+			Detail:   fmt.Sprintf("The instance key is unsuitable: %s.", err),
+			Subject:  keyExpr.Range().Ptr(),
+		})
+	}
+
+	return addr.Instance(key), diags
+}
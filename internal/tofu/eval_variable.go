@@ -100,7 +100,7 @@ func prepareFinalInputVariableValue(addr addrs.AbsInputVariableInstance, raw *In
 	// null values, as doing so could prevent assigning null to a nullable
 	// variable.
 	if cfg.TypeDefaults != nil && !given.IsNull() {
-		given = cfg.TypeDefaults.Apply(given)
+		given = configs.ApplyTypeDefaults(cfg.TypeDefaults, given)
 	}
 
 	val, err := convert.Convert(given, convertTy)
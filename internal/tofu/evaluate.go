@@ -22,6 +22,7 @@ import (
 	"github.com/opentofu/opentofu/internal/lang"
 	"github.com/opentofu/opentofu/internal/lang/marks"
 	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/plans/deferring"
 	"github.com/opentofu/opentofu/internal/states"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
@@ -65,6 +66,10 @@ type Evaluator struct {
 	// ensures they can be safely accessed and modified concurrently.
 	Changes *plans.ChangesSync
 
+	// Deferred tracks the resources whose changes are deferred to a later
+	// plan. References to these resources evaluate to unknown values.
+	Deferred *deferring.Deferred
+
 	PlanTimestamp time.Time
 }
 
@@ -262,11 +267,16 @@ func (d *evaluationStateData) GetInputVariable(addr addrs.InputVariable, rng tfd
 	// being liberal in what it accepts because the subsequent plan walk has
 	// more information available and so can be more conservative.
 	if d.Operation == walkValidate {
-		// Ensure variable sensitivity is captured in the validate walk
+		// Ensure variable sensitivity and ephemerality are captured in the
+		// validate walk
+		val := cty.UnknownVal(config.Type)
 		if config.Sensitive {
-			return cty.UnknownVal(config.Type).Mark(marks.Sensitive), diags
+			val = val.Mark(marks.Sensitive)
 		}
-		return cty.UnknownVal(config.Type), diags
+		if config.Ephemeral {
+			val = val.Mark(marks.Ephemeral)
+		}
+		return val, diags
 	}
 
 	moduleAddrStr := d.ModulePath.String()
@@ -305,6 +315,11 @@ func (d *evaluationStateData) GetInputVariable(addr addrs.InputVariable, rng tfd
 		val = val.Mark(marks.Sensitive)
 	}
 
+	// Mark if ephemeral, so that the value can't be saved in a plan or state
+	if config.Ephemeral {
+		val = val.Mark(marks.Ephemeral)
+	}
+
 	return val, diags
 }
 
@@ -423,8 +438,8 @@ func (d *evaluationStateData) GetModule(addr addrs.ModuleCall, rng tfdiags.Sourc
 	// the structure is based on the configuration, so iterate through all the
 	// defined outputs, and add any instance state or changes we find.
 	for _, cfg := range outputConfigs {
-		// record the output names for validation
-		unknownMap[cfg.Name] = cty.DynamicPseudoType
+		// record the output names and any declared types for validation
+		unknownMap[cfg.Name] = outputContractType(cfg)
 
 		// get all instance output for this path from the state
 		for key, states := range stateMap {
@@ -536,8 +551,14 @@ func (d *evaluationStateData) GetModule(addr addrs.ModuleCall, rng tfdiags.Sourc
 		if !ok {
 			// create the object if there wasn't one known
 			val = map[string]cty.Value{}
-			for k := range outputConfigs {
-				val[k] = cty.DynamicVal
+			for k, cfg := range outputConfigs {
+				val[k] = outputContractUnknownVal(cfg)
+
+				// A disabled module has no instance, and so no outputs
+				// will ever be recorded for it. Its outputs are all null.
+				if callConfig.Enabled != nil {
+					val[k] = cty.NullVal(outputContractType(cfg))
+				}
 			}
 		}
 
@@ -570,6 +591,26 @@ func (d *evaluationStateData) GetModule(addr addrs.ModuleCall, rng tfdiags.Sourc
 	return ret, diags
 }
 
+// outputContractType returns the type declared for the given output value,
+// or cty.DynamicPseudoType if it has no type constraint.
+func outputContractType(cfg *configs.Output) cty.Type {
+	if cfg.Type == cty.NilType {
+		return cty.DynamicPseudoType
+	}
+	return cfg.Type
+}
+
+// outputContractUnknownVal returns the placeholder for the not yet known
+// value of the given output value, which has the output value's declared
+// type and is not null if the output value is not nullable.
+func outputContractUnknownVal(cfg *configs.Output) cty.Value {
+	val := cty.UnknownVal(outputContractType(cfg))
+	if !cfg.Nullable {
+		val = val.RefineNotNull()
+	}
+	return val
+}
+
 func (d *evaluationStateData) GetPathAttr(addr addrs.PathAttr, rng tfdiags.SourceRange) (cty.Value, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 	switch addr.Name {
@@ -680,6 +721,12 @@ func (d *evaluationStateData) GetResource(addr addrs.Resource, rng tfdiags.Sourc
 	}
 	ty := schema.ImpliedType()
 
+	// The instances of a deferred resource are not known yet, so any
+	// reference to it is unknown until a later plan.
+	if d.Evaluator.Deferred.IsResourceDeferred(addr.Absolute(moduleAddr)) {
+		return cty.DynamicVal, diags
+	}
+
 	rs := d.Evaluator.State.Resource(addr.Absolute(d.ModulePath))
 
 	if rs == nil {
@@ -825,6 +872,12 @@ func (d *evaluationStateData) GetResource(addr addrs.Resource, rng tfdiags.Sourc
 		instances[key] = val
 	}
 
+	// The changes for individually-deferred instances are not known yet, so
+	// they are unknown until a later plan, even if they are in the state.
+	for _, key := range d.Evaluator.Deferred.DeferredInstanceKeys(addr.Absolute(d.ModulePath)) {
+		instances[key] = cty.UnknownVal(ty)
+	}
+
 	// ret should be populated with a valid value in all cases below
 	var ret cty.Value
 
@@ -10,6 +10,7 @@ import (
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/instances"
+	"github.com/opentofu/opentofu/internal/lang/marks"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 	"github.com/zclconf/go-cty/cty"
 )
@@ -30,6 +31,70 @@ func evalReplaceTriggeredByExpr(expr hcl.Expression, keyData instances.Repetitio
 	return ref, diags
 }
 
+// evalReplaceTriggeredByValues evaluates the replace_triggered_by expressions
+// that aren't references to resources, returning their values as a tuple in
+// the order they were declared, or cty.NilVal if there are none. The result
+// is recorded in state, so it must not contain ephemeral values.
+func evalReplaceTriggeredByValues(ctx EvalContext, exprs []hcl.Expression, keyData instances.RepetitionData) (cty.Value, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	if len(exprs) == 0 {
+		return cty.NilVal, diags
+	}
+
+	scope := ctx.EvaluationScope(nil, nil, keyData)
+	vals := make([]cty.Value, len(exprs))
+	for i, expr := range exprs {
+		val, valDiags := scope.EvalExpr(expr, cty.DynamicPseudoType)
+		diags = diags.Append(valDiags)
+		if valDiags.HasErrors() {
+			continue
+		}
+		if marks.Contains(val, marks.Ephemeral) {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid replace_triggered_by expression",
+				Detail:   "The values of replace_triggered_by expressions are saved in the state, so they cannot refer to ephemeral values.",
+				Subject:  expr.Range().Ptr(),
+			})
+			continue
+		}
+		vals[i] = val
+	}
+	if diags.HasErrors() {
+		return cty.NilVal, diags
+	}
+
+	return cty.TupleVal(vals), diags
+}
+
+// replaceTriggerValuesChanged returns true if the values of the
+// replace_triggered_by expressions returned by evalReplaceTriggeredByValues
+// differ from those recorded in state. If either is absent, such as when the
+// expressions are first added to the configuration, there is no change.
+func replaceTriggerValuesChanged(prior, current cty.Value) bool {
+	if prior == cty.NilVal || prior.IsNull() || current == cty.NilVal || current.IsNull() {
+		return false
+	}
+	current, _ = current.UnmarkDeep()
+	if !current.IsWhollyKnown() {
+		// A value that isn't known yet will presumably change.
+		return true
+	}
+	return !prior.Equals(current).True()
+}
+
+// replaceTriggerValuesNeedUpdate returns true if the values of the
+// replace_triggered_by expressions recorded in state must be updated to the
+// current values, which is the case when expressions were added or removed.
+func replaceTriggerValuesNeedUpdate(prior, current cty.Value) bool {
+	priorNull := prior == cty.NilVal || prior.IsNull()
+	currentNull := current == cty.NilVal || current.IsNull()
+	if priorNull || currentNull {
+		return priorNull != currentNull
+	}
+	return replaceTriggerValuesChanged(prior, current)
+}
+
 // trggersExprToTraversal takes an hcl expression limited to the syntax allowed
 // in replace_triggered_by, and converts it to a static traversal. The
 // RepetitionData contains the data necessary to evaluate the only allowed
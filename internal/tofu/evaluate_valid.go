@@ -312,6 +312,38 @@ func (d *evaluationStateData) staticValidateModuleCallReference(modCfg *configs.
 		return diags
 	}
 
+	// We only check for deprecated output values during the validate walk,
+	// so that each reference produces only one warning even though it's
+	// evaluated again during planning and applying.
+	if d.Operation == walkValidate && len(remain) > 0 {
+		if step, ok := remain[0].(hcl.TraverseAttr); ok {
+			diags = diags.Append(staticValidateDeprecatedOutputReference(modCfg, addr, step.Name, rng))
+		}
+	}
+
+	return diags
+}
+
+// staticValidateDeprecatedOutputReference returns a warning if the given
+// output value of the given module call is deprecated.
+func staticValidateDeprecatedOutputReference(modCfg *configs.Config, addr addrs.ModuleCall, name string, rng tfdiags.SourceRange) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	child := modCfg.Children[addr.Name]
+	if child == nil {
+		return diags
+	}
+	output, exists := child.Module.Outputs[name]
+	if !exists || output.Deprecated == "" {
+		return diags
+	}
+
+	diags = diags.Append(&hcl.Diagnostic{
+		Severity: hcl.DiagWarning,
+		Summary:  "Deprecated output value",
+		Detail:   fmt.Sprintf("The output value %q of %s is deprecated: %s", name, addr, output.Deprecated),
+		Subject:  rng.ToHCL().Ptr(),
+	})
 	return diags
 }
 
@@ -41,11 +41,16 @@ func (b *BasicGraphBuilder) Build(path addrs.ModuleInstance) (*Graph, tfdiags.Di
 		log.Printf("[TRACE] Executing graph transform %T", step)
 
 		err := step.Transform(g)
-		if thisStepStr := g.StringWithNodeTypes(); thisStepStr != lastStepStr {
-			log.Printf("[TRACE] Completed graph transform %T with new graph:\n%s  ------", step, logging.Indent(thisStepStr))
-			lastStepStr = thisStepStr
-		} else {
-			log.Printf("[TRACE] Completed graph transform %T (no changes)", step)
+		// Rendering the whole graph after every step takes longer than many
+		// of the steps themselves for large configurations, so we only do it
+		// when the result will be logged, which is only at the trace level.
+		if logging.IsTrace() {
+			if thisStepStr := g.StringWithNodeTypes(); thisStepStr != lastStepStr {
+				log.Printf("[TRACE] Completed graph transform %T with new graph:\n%s  ------", step, logging.Indent(thisStepStr))
+				lastStepStr = thisStepStr
+			} else {
+				log.Printf("[TRACE] Completed graph transform %T (no changes)", step)
+			}
 		}
 
 		if err != nil {
@@ -60,7 +65,11 @@ func (b *BasicGraphBuilder) Build(path addrs.ModuleInstance) (*Graph, tfdiags.Di
 
 	if err := g.Validate(); err != nil {
 		log.Printf("[ERROR] Graph validation failed. Graph:\n\n%s", g.String())
-		diags = diags.Append(err)
+		if cycles := g.Cycles(); len(cycles) > 0 {
+			diags = diags.Append(cycleDiagnostics(g, cycles))
+		} else {
+			diags = diags.Append(err)
+		}
 		return nil, diags
 	}
 
@@ -0,0 +1,244 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/dag"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// cycleEdge is one step of a dependency cycle: the vertex "from" must be
+// processed after the vertex "to", because of the references in "refs".
+//
+// refs is empty if the edge wasn't created by a reference in the
+// configuration, such as the edges that order the destruction of objects.
+type cycleEdge struct {
+	from, to  dag.Vertex
+	refs      []*addrs.Reference
+	dependsOn bool
+}
+
+// cycleDiagnostics returns an error diagnostic for each of the given cycles
+// in the graph, describing the shortest cycle through its vertices along
+// with the references that created it, and suggesting how to break it.
+func cycleDiagnostics(g *Graph, cycles [][]dag.Vertex) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	var vs []dag.Vertex
+	for _, v := range g.Vertices() {
+		// The root module's closing node is added after references are
+		// connected, and has no address that anything can refer to.
+		if n, ok := v.(*nodeCloseModule); ok && n.Addr.IsRoot() {
+			continue
+		}
+		vs = append(vs, v)
+	}
+	refMap := NewReferenceMap(vs)
+	for _, cycle := range cycles {
+		edges := cycleEdges(refMap, g, shortestCycle(g, cycle))
+		diags = diags.Append(cycleDiagnostic(edges))
+	}
+	return diags
+}
+
+// shortestCycle returns the vertices of the shortest cycle within the given
+// strongly connected component of the graph, in dependency order, so that
+// each vertex has an edge to the next and the last has an edge to the first.
+func shortestCycle(g *Graph, component []dag.Vertex) []dag.Vertex {
+	in := make(map[dag.Vertex]bool, len(component))
+	for _, v := range component {
+		in[v] = true
+	}
+
+	// Starting from each vertex in turn, in name order so that the result
+	// is consistent, find the shortest path back to it.
+	starts := make([]dag.Vertex, len(component))
+	copy(starts, component)
+	sortVerticesByName(starts)
+
+	var shortest []dag.Vertex
+	for _, start := range starts {
+		prev := map[dag.Vertex]dag.Vertex{}
+		queue := []dag.Vertex{start}
+		found := false
+		for len(queue) > 0 && !found {
+			v := queue[0]
+			queue = queue[1:]
+			targets := dag.AsVertexList(g.DownEdges(v))
+			sortVerticesByName(targets)
+			for _, next := range targets {
+				if !in[next] {
+					continue
+				}
+				if next == start {
+					prev[start] = v
+					found = true
+					break
+				}
+				if _, seen := prev[next]; !seen {
+					prev[next] = v
+					queue = append(queue, next)
+				}
+			}
+		}
+		if !found {
+			continue
+		}
+
+		path := []dag.Vertex{start}
+		for v := prev[start]; v != start; v = prev[v] {
+			path = append(path, v)
+		}
+		// The path was built backwards from start, so reverse all but the
+		// first vertex to get dependency order.
+		for i, j := 1, len(path)-1; i < j; i, j = i+1, j-1 {
+			path[i], path[j] = path[j], path[i]
+		}
+		if shortest == nil || len(path) < len(shortest) {
+			shortest = path
+		}
+		if len(shortest) == 2 {
+			break
+		}
+	}
+	if shortest == nil {
+		// Should never happen for a strongly connected component.
+		return component
+	}
+	return shortest
+}
+
+func sortVerticesByName(vs []dag.Vertex) {
+	sort.Slice(vs, func(i, j int) bool {
+		return dag.VertexName(vs[i]) < dag.VertexName(vs[j])
+	})
+}
+
+// cycleEdges finds the references in the configuration that created each
+// edge of the given cycle.
+func cycleEdges(refMap ReferenceMap, g *Graph, cycle []dag.Vertex) []cycleEdge {
+	edges := make([]cycleEdge, len(cycle))
+	for i, from := range cycle {
+		to := cycle[(i+1)%len(cycle)]
+		edge := cycleEdge{from: from, to: to}
+
+		if rn, ok := from.(GraphNodeReferencer); ok {
+			var dependsOn []*addrs.Reference
+			if dn, ok := from.(graphNodeDependsOn); ok {
+				dependsOn = dn.DependsOn()
+			}
+			for _, ref := range rn.References() {
+				for _, v := range refMap.referencedVertices(from, ref) {
+					if v != to {
+						continue
+					}
+					edge.refs = append(edge.refs, ref)
+					for _, dep := range dependsOn {
+						if dep.SourceRange == ref.SourceRange {
+							edge.dependsOn = true
+						}
+					}
+				}
+			}
+		}
+		edges[i] = edge
+	}
+	return edges
+}
+
+// cycleDiagnostic describes a single cycle, given its edges.
+func cycleDiagnostic(edges []cycleEdge) *hcl.Diagnostic {
+	var subject *hcl.Range
+	var detail strings.Builder
+	detail.WriteString("Each of these objects must be processed after the next one, so there is no order in which OpenTofu can process them:\n")
+	for _, edge := range edges {
+		from, to := cycleVertexName(edge.from), cycleVertexName(edge.to)
+		switch {
+		case len(edge.refs) == 0:
+			fmt.Fprintf(&detail, "\n  %s must be processed after %s", from, to)
+		case edge.dependsOn:
+			fmt.Fprintf(&detail, "\n  %s depends on %s (%s)", from, to, edge.refs[0].SourceRange.StartString())
+		default:
+			fmt.Fprintf(&detail, "\n  %s refers to %s (%s)", from, to, edge.refs[0].SourceRange.StartString())
+		}
+		if subject == nil && len(edge.refs) > 0 {
+			subject = edge.refs[0].SourceRange.ToHCL().Ptr()
+		}
+	}
+
+	if suggestions := cycleSuggestions(edges); len(suggestions) > 0 {
+		detail.WriteString("\n\nTo break the cycle, remove one of these dependencies. For example:\n")
+		for _, s := range suggestions {
+			fmt.Fprintf(&detail, "\n  - %s", s)
+		}
+	}
+
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Cycle between objects",
+		Detail:   detail.String(),
+		Subject:  subject,
+	}
+}
+
+// cycleSuggestions returns ways that the given cycle might be broken, based
+// on the kinds of objects and references that created it. It suggests each
+// kind of change only once, for the first edge that it applies to.
+func cycleSuggestions(edges []cycleEdge) []string {
+	var ret []string
+	var moved, lookedUp, replacing bool
+	for _, edge := range edges {
+		if isCycleDestroyEdge(edge) {
+			replacing = true
+		}
+		if len(edge.refs) == 0 {
+			continue
+		}
+
+		from, to := cycleVertexName(edge.from), cycleVertexName(edge.to)
+		switch {
+		case edge.dependsOn:
+			ret = append(ret, fmt.Sprintf("Remove %s from the depends_on argument of %s, if %s doesn't need it to exist first.", to, from, from))
+		case !moved && isManagedResourceVertex(edge.from) && isManagedResourceVertex(edge.to):
+			ret = append(ret, fmt.Sprintf("Move the argument of %s that refers to %s into a separate resource which depends on both of them, such as a rule or attachment resource, if the provider offers one.", from, to))
+			moved = true
+		case !lookedUp && isManagedResourceVertex(edge.to):
+			ret = append(ret, fmt.Sprintf("Look up the value that %s needs from %s with a data source, using arguments which don't refer to %s.", from, to, to))
+			lookedUp = true
+		}
+	}
+	if replacing {
+		ret = append(ret, "Some of these dependencies order the replacement or destruction of objects. Setting create_before_destroy the same way for all of the resources in the cycle can avoid them.")
+	}
+	return ret
+}
+
+// cycleVertexName returns the name of a vertex to show in a cycle
+// diagnostic, without the suffix that most vertices of a plan graph have.
+func cycleVertexName(v dag.Vertex) string {
+	return strings.TrimSuffix(dag.VertexName(v), " (expand)")
+}
+
+func isManagedResourceVertex(v dag.Vertex) bool {
+	rn, ok := v.(GraphNodeConfigResource)
+	return ok && rn.ResourceAddr().Resource.Mode == addrs.ManagedResourceMode
+}
+
+func isCycleDestroyEdge(edge cycleEdge) bool {
+	for _, v := range []dag.Vertex{edge.from, edge.to} {
+		if _, ok := v.(GraphNodeDestroyer); ok {
+			return true
+		}
+		if cbd, ok := v.(GraphNodeDestroyerCBD); ok && cbd.CreateBeforeDestroy() {
+			return true
+		}
+	}
+	return false
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/instances"
 	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/plans/deferring"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/provisioners"
 	"github.com/opentofu/opentofu/internal/refactoring"
@@ -35,6 +36,10 @@ type ContextGraphWalker struct {
 	PrevRunState       *states.SyncState   // Used for safe concurrent access to state
 	Changes            *plans.ChangesSync  // Used for safe concurrent writes to changes
 	Checks             *checks.State       // Used for safe concurrent writes of checkable objects and their check results
+	Deferred           *deferring.Deferred // Tracks the resources whose changes are deferred to a later plan
+	RefreshBatch       *refreshBatch       // Reads remote objects ahead of the nodes that plan them, if not nil
+	Refinement         *planRefinement     // Earlier saved plan whose changes can be reused, if not nil
+	Discovery          *resourceDiscovery  // Collects remote objects listed by providers, if not nil
 	InstanceExpander   *instances.Expander // Tracks our gradual expansion of module and resource instances
 	Imports            []configs.Import
 	MoveResults        refactoring.MoveResults // Read-only record of earlier processing of move statements
@@ -55,10 +60,13 @@ type ContextGraphWalker struct {
 	variableValuesLock sync.Mutex
 	providerCache      map[string]providers.Interface
 	providerSchemas    map[string]providers.ProviderSchema
+	providerConfigs    map[string]cty.Value
 	providerLock       sync.Mutex
 	provisionerCache   map[string]provisioners.Interface
 	provisionerSchemas map[string]*configschema.Block
 	provisionerLock    sync.Mutex
+	providerFunctions  *providerFunctions
+	concurrency        *concurrencySemaphores
 }
 
 func (w *ContextGraphWalker) EnterPath(path addrs.ModuleInstance) EvalContext {
@@ -88,6 +96,7 @@ func (w *ContextGraphWalker) EvalContext() EvalContext {
 		Operation:          w.Operation,
 		State:              w.State,
 		Changes:            w.Changes,
+		Deferred:           w.Deferred,
 		Plugins:            w.Context.plugins,
 		VariableValues:     w.variableValues,
 		VariableValuesLock: &w.variableValuesLock,
@@ -100,14 +109,20 @@ func (w *ContextGraphWalker) EvalContext() EvalContext {
 		InputValue:            w.Context.uiInput,
 		InstanceExpanderValue: w.InstanceExpander,
 		Plugins:               w.Context.plugins,
+		ProviderFunctions:     w.providerFunctions,
 		MoveResultsValue:      w.MoveResults,
 		ProviderCache:         w.providerCache,
 		ProviderInputConfig:   w.Context.providerInputConfig,
+		ProviderConfigs:       w.providerConfigs,
 		ProviderLock:          &w.providerLock,
 		ProvisionerCache:      w.provisionerCache,
 		ProvisionerLock:       &w.provisionerLock,
 		ChangesValue:          w.Changes,
 		ChecksValue:           w.Checks,
+		DeferredValue:         w.Deferred,
+		RefreshBatchValue:     w.RefreshBatch,
+		RefinementValue:       w.Refinement,
+		DiscoveryValue:        w.Discovery,
 		StateValue:            w.State,
 		RefreshStateValue:     w.RefreshState,
 		PrevRunStateValue:     w.PrevRunState,
@@ -123,9 +138,11 @@ func (w *ContextGraphWalker) init() {
 	w.contexts = make(map[string]*BuiltinEvalContext)
 	w.providerCache = make(map[string]providers.Interface)
 	w.providerSchemas = make(map[string]providers.ProviderSchema)
+	w.providerConfigs = make(map[string]cty.Value)
 	w.provisionerCache = make(map[string]provisioners.Interface)
 	w.provisionerSchemas = make(map[string]*configschema.Block)
 	w.variableValues = make(map[string]map[string]cty.Value)
+	w.providerFunctions = newProviderFunctions(w.Context.plugins, w.Context.providerFunctionResults)
 
 	// Populate root module variable values. Other modules will be populated
 	// during the graph walk.
@@ -136,6 +153,23 @@ func (w *ContextGraphWalker) init() {
 }
 
 func (w *ContextGraphWalker) Execute(ctx EvalContext, n GraphNodeExecutable) tfdiags.Diagnostics {
+	// Acquire any narrower concurrency limits that apply to this node first,
+	// so that a node waiting on one of those doesn't hold a slot in the
+	// overall parallelism limit.
+	for _, sem := range w.concurrency.forNode(n) {
+		sem.Acquire()
+		defer sem.Release()
+	}
+	if limiter := w.concurrency.rateLimiterForNode(n); limiter != nil {
+		stopCtx := w.StopContext
+		if stopCtx == nil {
+			stopCtx = context.Background()
+		}
+		// If the operation is stopped while we're waiting then we let the
+		// node run anyway, so that it can respond to the stop itself.
+		_ = limiter.Wait(stopCtx)
+	}
+
 	// Acquire a lock on the semaphore
 	w.Context.parallelSem.Acquire()
 	defer w.Context.parallelSem.Release()
@@ -74,6 +74,14 @@ type Hook interface {
 	PreRefresh(addr addrs.AbsResourceInstance, gen states.Generation, priorState cty.Value) (HookAction, error)
 	PostRefresh(addr addrs.AbsResourceInstance, gen states.Generation, priorState cty.Value, newState cty.Value) (HookAction, error)
 
+	// PreRefreshBatch and PostRefreshBatch are called before and after the
+	// current objects for a set of resource instances are read as a batch
+	// using the given provider configuration, respectively. PreRefresh and
+	// PostRefresh are still called for each of the instances in the batch
+	// as it is refreshed, and the batch cannot be halted.
+	PreRefreshBatch(provider addrs.AbsProviderConfig, instances []addrs.AbsResourceInstance) (HookAction, error)
+	PostRefreshBatch(provider addrs.AbsProviderConfig, instances []addrs.AbsResourceInstance) (HookAction, error)
+
 	// PreImportState and PostImportState are called before and after
 	// (respectively) each state import operation for a given resource address when
 	// using the legacy import command.
@@ -162,6 +170,14 @@ func (*NilHook) PostRefresh(addr addrs.AbsResourceInstance, gen states.Generatio
 	return HookActionContinue, nil
 }
 
+func (*NilHook) PreRefreshBatch(provider addrs.AbsProviderConfig, instances []addrs.AbsResourceInstance) (HookAction, error) {
+	return HookActionContinue, nil
+}
+
+func (*NilHook) PostRefreshBatch(provider addrs.AbsProviderConfig, instances []addrs.AbsResourceInstance) (HookAction, error) {
+	return HookActionContinue, nil
+}
+
 func (*NilHook) PreImportState(addr addrs.AbsResourceInstance, importID string) (HookAction, error) {
 	return HookActionContinue, nil
 }
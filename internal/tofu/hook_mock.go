@@ -99,6 +99,18 @@ type MockHook struct {
 	PostRefreshReturn     HookAction
 	PostRefreshError      error
 
+	PreRefreshBatchCalled   bool
+	PreRefreshBatchProvider addrs.AbsProviderConfig
+	PreRefreshBatchAddrs    []addrs.AbsResourceInstance
+	PreRefreshBatchReturn   HookAction
+	PreRefreshBatchError    error
+
+	PostRefreshBatchCalled   bool
+	PostRefreshBatchProvider addrs.AbsProviderConfig
+	PostRefreshBatchAddrs    []addrs.AbsResourceInstance
+	PostRefreshBatchReturn   HookAction
+	PostRefreshBatchError    error
+
 	PreImportStateCalled bool
 	PreImportStateAddr   addrs.AbsResourceInstance
 	PreImportStateID     string
@@ -269,6 +281,26 @@ func (h *MockHook) PostRefresh(addr addrs.AbsResourceInstance, gen states.Genera
 	return h.PostRefreshReturn, h.PostRefreshError
 }
 
+func (h *MockHook) PreRefreshBatch(provider addrs.AbsProviderConfig, instances []addrs.AbsResourceInstance) (HookAction, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.PreRefreshBatchCalled = true
+	h.PreRefreshBatchProvider = provider
+	h.PreRefreshBatchAddrs = instances
+	return h.PreRefreshBatchReturn, h.PreRefreshBatchError
+}
+
+func (h *MockHook) PostRefreshBatch(provider addrs.AbsProviderConfig, instances []addrs.AbsResourceInstance) (HookAction, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.PostRefreshBatchCalled = true
+	h.PostRefreshBatchProvider = provider
+	h.PostRefreshBatchAddrs = instances
+	return h.PostRefreshBatchReturn, h.PostRefreshBatchError
+}
+
 func (h *MockHook) PreImportState(addr addrs.AbsResourceInstance, importID string) (HookAction, error) {
 	h.Lock()
 	defer h.Unlock()
@@ -66,6 +66,14 @@ func (h *stopHook) PostRefresh(addr addrs.AbsResourceInstance, gen states.Genera
 	return h.hook()
 }
 
+func (h *stopHook) PreRefreshBatch(provider addrs.AbsProviderConfig, instances []addrs.AbsResourceInstance) (HookAction, error) {
+	return h.hook()
+}
+
+func (h *stopHook) PostRefreshBatch(provider addrs.AbsProviderConfig, instances []addrs.AbsResourceInstance) (HookAction, error) {
+	return h.hook()
+}
+
 func (h *stopHook) PreImportState(addr addrs.AbsResourceInstance, importID string) (HookAction, error) {
 	return h.hook()
 }
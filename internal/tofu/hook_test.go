@@ -113,6 +113,20 @@ func (h *testHook) PostRefresh(addr addrs.AbsResourceInstance, gen states.Genera
 	return HookActionContinue, nil
 }
 
+func (h *testHook) PreRefreshBatch(provider addrs.AbsProviderConfig, instances []addrs.AbsResourceInstance) (HookAction, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.Calls = append(h.Calls, &testHookCall{"PreRefreshBatch", provider.String()})
+	return HookActionContinue, nil
+}
+
+func (h *testHook) PostRefreshBatch(provider addrs.AbsProviderConfig, instances []addrs.AbsResourceInstance) (HookAction, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.Calls = append(h.Calls, &testHookCall{"PostRefreshBatch", provider.String()})
+	return HookActionContinue, nil
+}
+
 func (h *testHook) PreImportState(addr addrs.AbsResourceInstance, importID string) (HookAction, error) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -0,0 +1,225 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/instances"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// evalIgnoreChangesWhen evaluates the conditions of the given
+// ignore_changes_when blocks for a resource instance, returning the
+// attributes of the blocks whose condition is true.
+func evalIgnoreChangesWhen(ctx EvalContext, rules []*configs.IgnoreChangesWhen, keyData instances.RepetitionData) ([]hcl.Traversal, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	if len(rules) == 0 {
+		return nil, diags
+	}
+
+	var ret []hcl.Traversal
+	scope := ctx.EvaluationScope(nil, nil, keyData)
+	for _, rule := range rules {
+		val, valDiags := scope.EvalExpr(rule.Condition, cty.Bool)
+		diags = diags.Append(valDiags)
+		if valDiags.HasErrors() {
+			continue
+		}
+		val, _ = val.UnmarkDeep()
+
+		switch {
+		case val.IsNull():
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid ignore_changes_when condition",
+				Detail:   "The condition value is null. Conditions must either be true or false.",
+				Subject:  rule.Condition.Range().Ptr(),
+			})
+		case !val.IsKnown():
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid ignore_changes_when condition",
+				Detail:   "The condition depends on values that cannot be determined until apply, so OpenTofu cannot decide whether to ignore changes to the resource's attributes.",
+				Subject:  rule.Condition.Range().Ptr(),
+			})
+		case val.True():
+			ret = append(ret, rule.Attributes...)
+		}
+	}
+
+	return ret, diags
+}
+
+// expandIgnoreChanges converts the given ignore_changes traversals to the
+// paths of the values they refer to in the prior and configuration values of
+// a resource instance.
+//
+// Traversals without wildcards convert directly to a single path. A splat
+// step, like ingress[*], expands to every element of a list or map, and a
+// string index key containing "*", like tags["kubernetes.io/*"], expands to
+// every matching key of a map. The elements and keys come from both the prior
+// and configuration values, so that ignoring changes can retain elements that
+// were removed from the configuration as well as ignore those that were added.
+func expandIgnoreChanges(traversals []hcl.Traversal, prior, config cty.Value) []cty.Path {
+	prior, _ = prior.UnmarkDeep()
+	config, _ = config.UnmarkDeep()
+
+	var paths []cty.Path
+	for _, traversal := range traversals {
+		if !ignoreChangesHasWildcard(traversal) {
+			paths = append(paths, traversalToPath(traversal))
+			continue
+		}
+		paths = expandIgnoreChangesSteps(paths, nil, traversal, prior, config)
+	}
+	return paths
+}
+
+func expandIgnoreChangesSteps(paths []cty.Path, path cty.Path, steps hcl.Traversal, prior, config cty.Value) []cty.Path {
+	if len(steps) == 0 {
+		return append(paths, path.Copy())
+	}
+
+	switch step := steps[0].(type) {
+	case hcl.TraverseRoot:
+		return expandIgnoreChangesStep(paths, path, cty.GetAttrStep{Name: step.Name}, steps[1:], prior, config)
+	case hcl.TraverseAttr:
+		return expandIgnoreChangesStep(paths, path, cty.GetAttrStep{Name: step.Name}, steps[1:], prior, config)
+	case hcl.TraverseIndex:
+		pattern, ok := ignoreChangesKeyPattern(step.Key)
+		if !ok {
+			return expandIgnoreChangesStep(paths, path, cty.IndexStep{Key: step.Key}, steps[1:], prior, config)
+		}
+		for _, key := range ignoreChangesKeys(prior, config) {
+			if key.Type() == cty.String && ignoreChangesKeyMatch(pattern, key.AsString()) {
+				paths = expandIgnoreChangesStep(paths, path, cty.IndexStep{Key: key}, steps[1:], prior, config)
+			}
+		}
+		return paths
+	case hcl.TraverseSplat:
+		for _, key := range ignoreChangesKeys(prior, config) {
+			paths = expandIgnoreChangesStep(paths, path, cty.IndexStep{Key: key}, steps[1:], prior, config)
+		}
+		return paths
+	default:
+		panic(fmt.Sprintf("unsupported traversal step %#v", step))
+	}
+}
+
+func expandIgnoreChangesStep(paths []cty.Path, path cty.Path, step cty.PathStep, after hcl.Traversal, prior, config cty.Value) []cty.Path {
+	next := func(v cty.Value) cty.Value {
+		if v == cty.NilVal {
+			return v
+		}
+		v, err := step.Apply(v)
+		if err != nil {
+			return cty.NilVal
+		}
+		return v
+	}
+	return expandIgnoreChangesSteps(paths, append(path, step), after, next(prior), next(config))
+}
+
+// ignoreChangesKeys returns the keys of the elements of the given list or map
+// values, without duplicates. Values of any other type have no keys that can
+// be used in a path.
+func ignoreChangesKeys(vals ...cty.Value) []cty.Value {
+	var keys []cty.Value
+	seen := make(map[cty.Value]bool)
+	for _, v := range vals {
+		if v == cty.NilVal || v.IsNull() || !v.IsKnown() {
+			continue
+		}
+		ty := v.Type()
+		if !ty.IsListType() && !ty.IsTupleType() && !ty.IsMapType() && !ty.IsObjectType() {
+			continue
+		}
+		if ty.IsObjectType() {
+			// Objects are only used for values of the dynamic type which are
+			// written like maps, so we treat their attributes as keys.
+			for name := range ty.AttributeTypes() {
+				key := cty.StringVal(name)
+				if !seen[key] {
+					seen[key] = true
+					keys = append(keys, key)
+				}
+			}
+			continue
+		}
+		for it := v.ElementIterator(); it.Next(); {
+			key, _ := it.Element()
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// ignoreChangesHasWildcard returns true if the given traversal has a splat
+// step or a string index key containing "*".
+func ignoreChangesHasWildcard(traversal hcl.Traversal) bool {
+	for _, step := range traversal {
+		switch step := step.(type) {
+		case hcl.TraverseSplat:
+			return true
+		case hcl.TraverseIndex:
+			if _, ok := ignoreChangesKeyPattern(step.Key); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func ignoreChangesKeyPattern(key cty.Value) (string, bool) {
+	if key.Type() != cty.String || !key.IsKnown() || key.IsNull() {
+		return "", false
+	}
+	pattern := key.AsString()
+	return pattern, strings.Contains(pattern, "*")
+}
+
+// ignoreChangesKeyMatch returns true if the given map key matches a pattern in
+// which each "*" matches any sequence of characters, including "/".
+func ignoreChangesKeyMatch(pattern, key string) bool {
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(key, parts[0]) {
+		return false
+	}
+	key = key[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(key, part)
+		if i < 0 {
+			return false
+		}
+		key = key[i+len(part):]
+	}
+	return len(key) >= len(last) && strings.HasSuffix(key, last)
+}
+
+// staticIgnoreChangesTraversal returns the given ignore_changes traversal
+// with its splat steps replaced by index steps with unknown keys, for
+// validating it against the resource type's schema.
+func staticIgnoreChangesTraversal(traversal hcl.Traversal) hcl.Traversal {
+	ret := make(hcl.Traversal, len(traversal))
+	for i, step := range traversal {
+		if splat, ok := step.(hcl.TraverseSplat); ok {
+			step = hcl.TraverseIndex{
+				Key:      cty.UnknownVal(cty.DynamicPseudoType),
+				SrcRange: splat.SrcRange,
+			}
+		}
+		ret[i] = step
+	}
+	return ret
+}
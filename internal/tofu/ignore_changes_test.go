@@ -0,0 +1,33 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"testing"
+)
+
+func TestIgnoreChangesKeyMatch(t *testing.T) {
+	tests := []struct {
+		pattern, key string
+		want         bool
+	}{
+		{"kubernetes.io/*", "kubernetes.io/cluster", true},
+		{"kubernetes.io/*", "kubernetes.io/cluster/main", true},
+		{"kubernetes.io/*", "kubernetes.io/", true},
+		{"kubernetes.io/*", "example.com/kubernetes.io/", false},
+		{"*-managed", "aws-managed", true},
+		{"*-managed", "aws-managed-by", false},
+		{"a*b*c", "abc", true},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxcyyb", false},
+		{"ab*ba", "aba", false},
+		{"*", "anything", true},
+	}
+
+	for _, test := range tests {
+		if got := ignoreChangesKeyMatch(test.pattern, test.key); got != test.want {
+			t.Errorf("ignoreChangesKeyMatch(%q, %q) = %t, want %t", test.pattern, test.key, got, test.want)
+		}
+	}
+}
@@ -8,6 +8,10 @@ import (
 	"sort"
 
 	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/lang/marks"
+	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
 // filterMarks removes any PathValueMarks from marks which cannot be applied to
@@ -56,3 +60,60 @@ func marksEqual(a, b []cty.PathValueMarks) bool {
 
 	return true
 }
+
+// nullEphemeralAttributes returns the given resource object value with the
+// values of any attributes that the schema declares as ephemeral replaced by
+// null, keeping any other marks.
+//
+// Ephemeral attributes are never saved in a plan or state. The provider
+// still receives their values as part of the configuration when it applies
+// a change.
+func nullEphemeralAttributes(schema *configschema.Block, val cty.Value) cty.Value {
+	if !schema.ContainsEphemeral() || val.IsNull() {
+		return val
+	}
+	unmarked, pvm := val.UnmarkDeepWithPaths()
+	pvm = append(pvm, schema.EphemeralMarks(unmarked, nil)...)
+	unmarked, pvm = marks.RemoveEphemeral(unmarked, pvm)
+	return unmarked.MarkWithPaths(pvm)
+}
+
+// validateEphemeralValues returns an error for each ephemeral value in the
+// given configuration value that is not assigned to an ephemeral attribute,
+// since only the values of ephemeral attributes are kept out of the plan and
+// state.
+//
+// The diagnostics refer to attribute paths, so callers should use
+// InConfigBody to attach them to the configuration.
+func validateEphemeralValues(schema *configschema.Block, configVal cty.Value) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	_, pvm := configVal.UnmarkDeepWithPaths()
+	for _, pm := range pvm {
+		if _, ok := pm.Marks[marks.Ephemeral]; !ok {
+			continue
+		}
+		if ephemeralAttributeAtPath(schema, pm.Path) {
+			continue
+		}
+		diags = diags.Append(tfdiags.AttributeValue(
+			tfdiags.Error,
+			"Ephemeral value not allowed",
+			"This argument is not ephemeral, so it cannot be set to a value derived from an ephemeral value. Ephemeral values are never saved in a plan or state, so they can only be assigned to the ephemeral arguments of a resource type.",
+			pm.Path,
+		))
+	}
+	return diags
+}
+
+// ephemeralAttributeAtPath returns true if the given path is within an
+// attribute that the schema declares as ephemeral.
+func ephemeralAttributeAtPath(schema *configschema.Block, path cty.Path) bool {
+	for i := len(path); i > 0; i-- {
+		if attrS := schema.AttributeByPath(path[:i]); attrS != nil {
+			if attrS.Ephemeral {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -148,7 +148,7 @@ func (n *nodeCheckAssert) Path() addrs.ModuleInstance {
 	return n.addr.Module
 }
 
-func (n *nodeCheckAssert) Execute(ctx EvalContext, _ walkOperation) tfdiags.Diagnostics {
+func (n *nodeCheckAssert) Execute(ctx EvalContext, op walkOperation) tfdiags.Diagnostics {
 
 	// We only want to actually execute the checks during specific
 	// operations, such as plan and applies.
@@ -160,13 +160,21 @@ func (n *nodeCheckAssert) Execute(ctx EvalContext, _ walkOperation) tfdiags.Diag
 			return nil
 		}
 
+		// Failing checks are only ever warnings during planning, even if the
+		// author asked for errors, because the changes in the plan might be
+		// exactly what's needed to make the check pass.
+		severity := tfdiags.Warning
+		if op == walkApply {
+			severity = n.config.Severity
+		}
+
 		return evalCheckRules(
 			addrs.CheckAssertion,
 			n.config.Asserts,
 			ctx,
 			n.addr,
 			EvalDataForNoInstanceKey,
-			tfdiags.Warning)
+			severity)
 
 	}
 
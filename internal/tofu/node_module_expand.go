@@ -51,9 +51,11 @@ func (n *nodeExpandModule) References() []*addrs.Reference {
 	}
 
 	refs = append(refs, n.DependsOn()...)
+	depsRefs, _ := lang.ReferencesInExpr(addrs.ParseRef, n.ModuleCall.DependsOnExpr)
+	refs = append(refs, depsRefs...)
 
-	// Expansion only uses the count and for_each expressions, so this
-	// particular graph node only refers to those.
+	// Expansion only uses the count, for_each and enabled expressions, so
+	// this particular graph node only refers to those.
 	// Individual variable values in the module call definition might also
 	// refer to other objects, but that's handled by
 	// NodeApplyableModuleVariable.
@@ -71,6 +73,10 @@ func (n *nodeExpandModule) References() []*addrs.Reference {
 		forEachRefs, _ := lang.ReferencesInExpr(addrs.ParseRef, n.ModuleCall.ForEach)
 		refs = append(refs, forEachRefs...)
 	}
+	if n.ModuleCall.Enabled != nil {
+		enabledRefs, _ := lang.ReferencesInExpr(addrs.ParseRef, n.ModuleCall.Enabled)
+		refs = append(refs, enabledRefs...)
+	}
 	return refs
 }
 
@@ -128,6 +134,14 @@ func (n *nodeExpandModule) Execute(ctx EvalContext, op walkOperation) (diags tfd
 			}
 			expander.SetModuleForEach(module, call, forEach)
 
+		case n.ModuleCall.Enabled != nil:
+			enabled, enDiags := evaluateEnabledExpression(n.ModuleCall.Enabled, ctx)
+			diags = diags.Append(enDiags)
+			if diags.HasErrors() {
+				return diags
+			}
+			expander.SetModuleEnabled(module, call, enabled)
+
 		default:
 			expander.SetModuleSingle(module, call)
 		}
@@ -232,7 +246,7 @@ func (n *nodeValidateModule) Execute(ctx EvalContext, op walkOperation) (diags t
 	for _, module := range expander.ExpandModule(n.Addr.Parent()) {
 		ctx = ctx.WithPath(module)
 
-		// Validate our for_each and count expressions at a basic level
+		// Validate our for_each, count and enabled expressions at a basic level
 		// We skip validation on known, because there will be unknown values before
 		// a full expansion, presuming these errors will be caught in later steps
 		switch {
@@ -243,9 +257,13 @@ func (n *nodeValidateModule) Execute(ctx EvalContext, op walkOperation) (diags t
 		case n.ModuleCall.ForEach != nil:
 			_, forEachDiags := evaluateForEachExpressionValue(n.ModuleCall.ForEach, ctx, true)
 			diags = diags.Append(forEachDiags)
+
+		case n.ModuleCall.Enabled != nil:
+			_, enabledDiags := evaluateEnabledExpressionValue(n.ModuleCall.Enabled, ctx)
+			diags = diags.Append(enabledDiags)
 		}
 
-		diags = diags.Append(validateDependsOn(ctx, n.ModuleCall.DependsOn))
+		diags = diags.Append(validateDependsOn(ctx, n.ModuleCall.DependsOn, n.ModuleCall.DependsOnExpr))
 
 		// now set our own mode to single
 		expander.SetModuleSingle(module, call)
@@ -15,6 +15,7 @@ import (
 	"github.com/opentofu/opentofu/internal/dag"
 	"github.com/opentofu/opentofu/internal/instances"
 	"github.com/opentofu/opentofu/internal/lang"
+	"github.com/opentofu/opentofu/internal/lang/marks"
 	"github.com/opentofu/opentofu/internal/tfdiags"
 )
 
@@ -182,6 +183,7 @@ func (n *nodeModuleVariable) Execute(ctx EvalContext, op walkOperation) (diags t
 	case walkValidate:
 		val, err = n.evalModuleVariable(ctx, true)
 		diags = diags.Append(err)
+		diags = diags.Append(n.deprecationDiags())
 	default:
 		val, err = n.evalModuleVariable(ctx, false)
 		diags = diags.Append(err)
@@ -190,12 +192,43 @@ func (n *nodeModuleVariable) Execute(ctx EvalContext, op walkOperation) (diags t
 		return diags
 	}
 
+	if n.Expr != nil && !n.Config.Ephemeral && marks.Contains(val, marks.Ephemeral) {
+		_, call := n.Addr.Module.CallInstance()
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Ephemeral value not allowed",
+			Detail:   fmt.Sprintf("The input variable %q of %s is not declared as ephemeral, so it cannot be set to a value derived from an ephemeral value. Ephemeral values are never saved in a plan or state, so they can only be assigned to ephemeral input variables.", n.Addr.Variable.Name, call.Call),
+			Subject:  n.Expr.Range().Ptr(),
+		})
+		return diags
+	}
+
 	// Set values for arguments of a child module call, for later retrieval
 	// during expression evaluation.
 	_, call := n.Addr.Module.CallInstance()
 	ctx.SetModuleCallArgument(call, n.Addr.Variable, val)
 
-	return evalVariableValidations(n.Addr, n.Config, n.Expr, ctx)
+	diags = diags.Append(evalVariableValidations(n.Addr, n.Config, n.Expr, ctx))
+	return diags
+}
+
+// deprecationDiags returns a warning if the calling module sets this
+// variable but the variable is deprecated. We only check this during the
+// validate walk, so that each call produces only one warning.
+func (n *nodeModuleVariable) deprecationDiags() tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+	if n.Expr == nil || n.Config == nil || n.Config.Deprecated == "" {
+		return diags
+	}
+
+	_, call := n.Addr.Module.CallInstance()
+	diags = diags.Append(&hcl.Diagnostic{
+		Severity: hcl.DiagWarning,
+		Summary:  "Deprecated input variable",
+		Detail:   fmt.Sprintf("The input variable %q of %s is deprecated: %s", n.Addr.Variable.Name, call.Call, n.Config.Deprecated),
+		Subject:  n.Expr.Range().Ptr(),
+	})
+	return diags
 }
 
 // dag.GraphNodeDotter impl.
@@ -9,6 +9,7 @@ import (
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
 
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/configs"
@@ -280,9 +281,11 @@ func referencesForOutput(c *configs.Output) []*addrs.Reference {
 
 	impRefs, _ := lang.ReferencesInExpr(addrs.ParseRef, c.Expr)
 	expRefs, _ := lang.References(addrs.ParseRef, c.DependsOn)
+	depsRefs, _ := lang.ReferencesInExpr(addrs.ParseRef, c.DependsOnExpr)
 
 	refs = append(refs, impRefs...)
 	refs = append(refs, expRefs...)
+	refs = append(refs, depsRefs...)
 
 	for _, check := range c.Preconditions {
 		condRefs, _ := lang.ReferencesInExpr(addrs.ParseRef, check.Condition)
@@ -347,28 +350,67 @@ func (n *NodeApplyableOutput) Execute(ctx EvalContext, op walkOperation) (diags
 		var evalDiags tfdiags.Diagnostics
 		val, evalDiags = ctx.EvaluateExpr(n.Config.Expr, cty.DynamicPseudoType, nil)
 		diags = diags.Append(evalDiags)
+		if !evalDiags.HasErrors() {
+			var contractDiags tfdiags.Diagnostics
+			val, contractDiags = evalOutputContract(n.Config, val, op)
+			diags = diags.Append(contractDiags)
+		}
 
 		// We'll handle errors below, after we have loaded the module.
 		// Outputs don't have a separate mode for validation, so validate
 		// depends_on expressions here too
-		diags = diags.Append(validateDependsOn(ctx, n.Config.DependsOn))
+		diags = diags.Append(validateDependsOn(ctx, n.Config.DependsOn, n.Config.DependsOnExpr))
+
+		// Any parts of the value that are declared as sensitive attributes
+		// are sensitive even if the expression didn't produce sensitive
+		// values there.
+		val = markOutputSensitiveAttributes(val, n.Config.SensitiveAttributes)
+		sensitivePaths := outputSensitiveAttributePaths(val, n.Config.SensitiveAttributes)
 
 		// For root module outputs in particular, an output value must be
 		// statically declared as sensitive in order to dynamically return
 		// a sensitive result, to help avoid accidental exposure in the state
 		// of a sensitive value that the user doesn't want to include there.
 		if n.Addr.Module.IsRoot() {
-			if !n.Config.Sensitive && marks.Contains(val, marks.Sensitive) {
+			if !n.Config.Sensitive && !sensitiveValuesCovered(val, sensitivePaths) {
 				diags = diags.Append(&hcl.Diagnostic{
 					Severity: hcl.DiagError,
 					Summary:  "Output refers to sensitive values",
 					Detail: `To reduce the risk of accidentally exporting sensitive data that was intended to be only internal, OpenTofu requires that any root module output containing sensitive data be explicitly marked as sensitive, to confirm your intent.
 
 If you do intend to export this data, annotate the output value as sensitive by adding the following argument:
-    sensitive = true`,
+    sensitive = true
+
+If only some attributes of the output value are sensitive, you can instead list them in the sensitive_attributes argument. Use the nonsensitive_keys function to remove the sensitivity of a whole map or object while keeping each of its elements sensitive.`,
 					Subject: n.Config.DeclRange.Ptr(),
 				})
 			}
+			if n.Config.Ephemeral {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Ephemeral output not allowed",
+					Detail:   "Root module output values are saved in the state, so they cannot be ephemeral. Only the output values of child modules can be declared as ephemeral.",
+					Subject:  n.Config.DeclRange.Ptr(),
+				})
+			}
+		}
+
+		// An output value must be statically declared as ephemeral in order
+		// to return an ephemeral result, so that module authors and callers
+		// can see which output values are only available during this run.
+		if !n.Config.Ephemeral && marks.Contains(val, marks.Ephemeral) {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Output refers to ephemeral values",
+				Detail: `Ephemeral values are only available during the current OpenTofu run and are never saved in a plan or state, so an output value containing ephemeral data must be explicitly marked as ephemeral, to confirm your intent.
+
+If this output value belongs to a child module, annotate it as ephemeral by adding the following argument:
+    ephemeral = true`,
+				Subject: n.Config.DeclRange.Ptr(),
+			})
+		}
+		if n.Config.Ephemeral && !n.Addr.Module.IsRoot() {
+			val = val.Mark(marks.Ephemeral)
 		}
 	}
 
@@ -411,6 +453,63 @@ If you do intend to export this data, annotate the output value as sensitive by
 	return diags
 }
 
+// evalOutputContract checks the given value of an output value against the
+// type constraint, nullability and stability declared in its configuration,
+// and returns the value converted to the declared type.
+//
+// Unknown values of non-nullable output values are refined as not null, so
+// that calling modules can rely on that during planning too.
+func evalOutputContract(cfg *configs.Output, val cty.Value, op walkOperation) (cty.Value, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	if cfg.ConstraintType != cty.NilType {
+		// As with input variables, we don't apply type defaults to a
+		// top-level null value, so that nullable output values can be null.
+		given := val
+		if cfg.TypeDefaults != nil && !given.IsNull() {
+			given = configs.ApplyTypeDefaults(cfg.TypeDefaults, given)
+		}
+		converted, err := convert.Convert(given, cfg.ConstraintType)
+		if err != nil {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid output value type",
+				Detail:   fmt.Sprintf("The value of output %q is not suitable for its declared type: %s.", cfg.Name, tfdiags.FormatError(err)),
+				Subject:  cfg.Expr.Range().Ptr(),
+			})
+			return cty.UnknownVal(cfg.Type), diags
+		}
+		val = converted
+	}
+
+	if !cfg.Nullable {
+		switch {
+		case !val.IsKnown():
+			val = val.RefineNotNull()
+		case val.IsNull():
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Null output value",
+				Detail:   fmt.Sprintf("The output %q is declared with nullable = false, so its value must not be null.", cfg.Name),
+				Subject:  cfg.Expr.Range().Ptr(),
+			})
+		}
+	}
+
+	// Only the plan walk guarantees that the values which can be known
+	// before apply are known, so that's where we check stability.
+	if cfg.Stable && op == walkPlan && !val.IsWhollyKnown() {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Output value not known during planning",
+			Detail:   fmt.Sprintf("The output %q is declared with stable = true, so its value must be known during planning, but it depends on values that will be known only after apply.", cfg.Name),
+			Subject:  cfg.Expr.Range().Ptr(),
+		})
+	}
+
+	return val, diags
+}
+
 // dag.GraphNodeDotter impl.
 func (n *NodeApplyableOutput) DotNode(name string, opts *dag.DotOpts) *dag.DotNode {
 	return &dag.DotNode{
@@ -537,8 +636,17 @@ func (n *NodeApplyableOutput) setValue(state *states.SyncState, changes *plans.C
 		// removed from both the config and the state.
 		sensitiveChange := sensitiveBefore || n.Config.Sensitive
 
+		// Root output values with sensitive attributes are saved in the state
+		// as wholly sensitive, so for these we rely on the configuration to
+		// show only the sensitive parts of the prior value as sensitive.
+		if n.Config.SensitiveAttributesSet && !n.Config.Sensitive {
+			sensitiveChange = false
+			before = markOutputSensitiveAttributes(before, n.Config.SensitiveAttributes)
+		}
+
 		// strip any marks here just to be sure we don't panic on the True comparison
 		unmarkedVal, _ := val.UnmarkDeep()
+		unmarkedBefore, _ := before.UnmarkDeep()
 
 		action := plans.Update
 		switch {
@@ -552,8 +660,8 @@ func (n *NodeApplyableOutput) setValue(state *states.SyncState, changes *plans.C
 			action = plans.Create
 
 		case val.IsWhollyKnown() &&
-			unmarkedVal.Equals(before).True() &&
-			n.Config.Sensitive == sensitiveBefore:
+			unmarkedVal.Equals(unmarkedBefore).True() &&
+			n.stateSensitive() == sensitiveBefore:
 			// Sensitivity must also match to be a NoOp.
 			// Theoretically marks may not match here, but sensitivity is the
 			// only one we can act on, and the state will have been loaded
@@ -604,5 +712,85 @@ func (n *NodeApplyableOutput) setValue(state *states.SyncState, changes *plans.C
 		val = cty.UnknownAsNull(val)
 	}
 
-	state.SetOutputValue(n.Addr, val, n.Config.Sensitive)
+	state.SetOutputValue(n.Addr, val, n.stateSensitive())
+}
+
+// stateSensitive returns true if the output value must be saved in the state
+// as sensitive. The state records sensitivity only for whole output values,
+// so an output value with sensitive attributes is saved as sensitive.
+func (n *NodeApplyableOutput) stateSensitive() bool {
+	return n.Config.Sensitive || len(n.Config.SensitiveAttributes) != 0
+}
+
+// markOutputSensitiveAttributes returns the given value with the parts at the
+// given sensitive attribute traversals marked as sensitive.
+func markOutputSensitiveAttributes(val cty.Value, attrs []hcl.Traversal) cty.Value {
+	paths := outputSensitiveAttributePaths(val, attrs)
+	if len(paths) == 0 {
+		return val
+	}
+	unmarked, pvm := val.UnmarkDeepWithPaths()
+	for _, path := range paths {
+		pvm = append(pvm, cty.PathValueMarks{
+			Path:  path,
+			Marks: cty.NewValueMarks(marks.Sensitive),
+		})
+	}
+	return unmarked.MarkWithPaths(pvm)
+}
+
+// outputSensitiveAttributePaths converts the sensitive attribute traversals of
+// an output value into paths within the given value. Attribute steps are
+// converted to index steps where the value is a map, and traversals that
+// don't exist within the value are skipped, since there is nothing to mark
+// there.
+func outputSensitiveAttributePaths(val cty.Value, attrs []hcl.Traversal) []cty.Path {
+	var paths []cty.Path
+	for _, traversal := range attrs {
+		path := traversalToPath(traversal)
+		current, _ := val.UnmarkDeep()
+		ok := true
+		for i, step := range path {
+			if current.IsNull() || !current.IsKnown() {
+				ok = false
+				break
+			}
+			if attr, isAttr := step.(cty.GetAttrStep); isAttr && current.Type().IsMapType() {
+				step = cty.IndexStep{Key: cty.StringVal(attr.Name)}
+				path[i] = step
+			}
+			next, err := step.Apply(current)
+			if err != nil {
+				ok = false
+				break
+			}
+			current = next
+		}
+		if ok {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// sensitiveValuesCovered returns true if every sensitive value within the
+// given value is within one of the given paths.
+func sensitiveValuesCovered(val cty.Value, paths []cty.Path) bool {
+	_, pvm := val.UnmarkDeepWithPaths()
+	for _, pm := range pvm {
+		if _, ok := pm.Marks[marks.Sensitive]; !ok {
+			continue
+		}
+		covered := false
+		for _, path := range paths {
+			if len(path) <= len(pm.Path) && pm.Path[:len(path)].Equals(path) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
 }
@@ -6,8 +6,10 @@ package tofu
 import (
 	"fmt"
 	"log"
+	"sort"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/configs/configschema"
 	"github.com/opentofu/opentofu/internal/providers"
 	"github.com/opentofu/opentofu/internal/tfdiags"
@@ -42,7 +44,13 @@ func (n *NodeApplyableProvider) Execute(ctx EvalContext, op walkOperation) (diag
 		return diags.Append(n.ValidateProvider(ctx, provider))
 	case walkPlan, walkPlanDestroy, walkApply, walkDestroy:
 		log.Printf("[TRACE] NodeApplyableProvider: configuring %s", n.Addr)
-		return diags.Append(n.ConfigureProvider(ctx, provider, false))
+		diags = diags.Append(n.ConfigureProvider(ctx, provider, false))
+		if !diags.HasErrors() {
+			// Now the provider is configured we can start reading the
+			// objects that belong to it, if we're refreshing.
+			ctx.RefreshBatch().Start(ctx, n.Addr)
+		}
+		return diags
 	case walkImport:
 		log.Printf("[TRACE] NodeApplyableProvider: configuring %s (requiring that configuration is wholly known)", n.Addr)
 		return diags.Append(n.ConfigureProvider(ctx, provider, true))
@@ -76,7 +84,7 @@ func (n *NodeApplyableProvider) ValidateProvider(ctx EvalContext, provider provi
 		configSchema = &configschema.Block{}
 	}
 
-	configVal, _, evalDiags := ctx.EvaluateBlock(configBody, configSchema, nil, EvalDataForNoInstanceKey)
+	configVal, _, evalDiags := n.evaluateConfig(ctx, configBody, configSchema)
 	if evalDiags.HasErrors() {
 		return diags.Append(evalDiags)
 	}
@@ -111,19 +119,14 @@ func (n *NodeApplyableProvider) ConfigureProvider(ctx EvalContext, provider prov
 	}
 
 	configSchema := resp.Provider.Block
-	configVal, configBody, evalDiags := ctx.EvaluateBlock(configBody, configSchema, nil, EvalDataForNoInstanceKey)
+	configVal, configBody, evalDiags := n.evaluateConfig(ctx, configBody, configSchema)
 	diags = diags.Append(evalDiags)
 	if evalDiags.HasErrors() {
 		return diags
 	}
 
 	if verifyConfigIsKnown && !configVal.IsWhollyKnown() {
-		diags = diags.Append(&hcl.Diagnostic{
-			Severity: hcl.DiagError,
-			Summary:  "Invalid provider configuration",
-			Detail:   fmt.Sprintf("The configuration for %s depends on values that cannot be determined until apply.", n.Addr),
-			Subject:  &config.DeclRange,
-		})
+		diags = diags.Append(n.unknownConfigDiags(tfdiags.Error, configVal, configBody, config))
 		return diags
 	}
 
@@ -153,6 +156,9 @@ func (n *NodeApplyableProvider) ConfigureProvider(ctx EvalContext, provider prov
 	}
 
 	if diags.HasErrors() {
+		if !configVal.IsWhollyKnown() {
+			diags = diags.Append(n.unknownConfigDiags(tfdiags.Warning, configVal, configBody, config))
+		}
 		return diags
 	}
 
@@ -175,8 +181,133 @@ func (n *NodeApplyableProvider) ConfigureProvider(ctx EvalContext, provider prov
 			fmt.Sprintf(providerConfigErr, n.Addr.Provider),
 		))
 	}
+	if diags.HasErrors() && !configVal.IsWhollyKnown() {
+		// Providers often fail to configure with unknown values, and
+		// their errors rarely say so, so we point out which arguments
+		// weren't known in case that's the cause.
+		diags = diags.Append(n.unknownConfigDiags(tfdiags.Warning, configVal, configBody, config))
+	}
 	return diags
 }
 
+// evaluateConfig evaluates the given provider configuration body. If the
+// configuration inherits the arguments of another provider configuration,
+// any arguments that it doesn't set are taken from the configuration that
+// the other provider was configured with.
+func (n *NodeApplyableProvider) evaluateConfig(ctx EvalContext, configBody hcl.Body, configSchema *configschema.Block) (cty.Value, hcl.Body, tfdiags.Diagnostics) {
+	config := n.ProviderConfig()
+	if config == nil || config.Inherit == nil {
+		return ctx.EvaluateBlock(configBody, configSchema, nil, EvalDataForNoInstanceKey)
+	}
+
+	// Required arguments can be inherited, so they can be omitted here.
+	configVal, configBody, diags := ctx.EvaluateBlock(configBody, configSchema.NoneRequired(), nil, EvalDataForNoInstanceKey)
+	if diags.HasErrors() {
+		return configVal, configBody, diags
+	}
+
+	baseVal := ctx.ProviderConfig(n.Inherits)
+	if baseVal == cty.NilVal {
+		// The inherited configuration isn't configured during validation,
+		// so we can't know the arguments it will provide.
+		baseVal = cty.UnknownVal(configSchema.ImpliedType())
+	}
+	log.Printf("[TRACE] NodeApplyableProvider: %s inherits unset arguments from %s", n.Addr, n.Inherits)
+	return mergeInheritedProviderConfig(configVal, baseVal, configSchema), configBody, diags
+}
+
+// mergeInheritedProviderConfig returns the given provider configuration
+// value with each of its null arguments, and each nested block type that
+// has no blocks, replaced by the corresponding value from baseVal.
+func mergeInheritedProviderConfig(configVal, baseVal cty.Value, schema *configschema.Block) cty.Value {
+	configVal, marks := configVal.Unmark()
+	if configVal.IsNull() || !configVal.IsKnown() {
+		return baseVal.WithMarks(marks)
+	}
+
+	ty := configVal.Type()
+	attrs := make(map[string]cty.Value, len(ty.AttributeTypes()))
+	for name, attrTy := range ty.AttributeTypes() {
+		val := configVal.GetAttr(name)
+		if !inheritsProviderArgument(val, schema.BlockTypes[name] != nil) {
+			attrs[name] = val
+			continue
+		}
+		switch {
+		case baseVal.IsNull():
+			attrs[name] = val
+		case !baseVal.IsKnown():
+			attrs[name] = cty.UnknownVal(attrTy)
+		default:
+			attrs[name] = baseVal.GetAttr(name)
+		}
+	}
+	return cty.ObjectVal(attrs).WithMarks(marks)
+}
+
+func inheritsProviderArgument(val cty.Value, isBlock bool) bool {
+	unmarked, _ := val.Unmark()
+	if unmarked.IsNull() {
+		return true
+	}
+	return isBlock && unmarked.IsKnown() && unmarked.CanIterateElements() && unmarked.LengthInt() == 0
+}
+
+// unknownConfigDiags returns a diagnostic of the given severity for each
+// argument of the given provider configuration value that isn't wholly known,
+// which is the case when it depends on values that are only known after
+// apply, such as the attributes of resources that don't exist yet.
+func (n *NodeApplyableProvider) unknownConfigDiags(severity tfdiags.Severity, configVal cty.Value, configBody hcl.Body, config *configs.Provider) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	configVal, _ = configVal.UnmarkDeep()
+	if configVal.IsKnown() && !configVal.IsNull() && configVal.Type().IsObjectType() {
+		attrs := configVal.AsValueMap()
+		names := make([]string, 0, len(attrs))
+		for name := range attrs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if attrs[name].IsWhollyKnown() {
+				continue
+			}
+			diags = diags.Append(tfdiags.AttributeValue(
+				severity,
+				unknownProviderConfigSummary(severity),
+				fmt.Sprintf(unknownProviderConfigDetail, n.Addr, name),
+				cty.GetAttrPath(name),
+			))
+		}
+	}
+	if len(diags) > 0 {
+		return diags.InConfigBody(configBody, n.Addr.String())
+	}
+
+	// We should always find at least one argument above, but we'll return a
+	// less specific diagnostic if not.
+	diag := &hcl.Diagnostic{
+		Severity: severity.ToHCL(),
+		Summary:  unknownProviderConfigSummary(severity),
+		Detail:   fmt.Sprintf("The configuration for %s depends on values that cannot be determined until apply.", n.Addr),
+	}
+	if config != nil {
+		diag.Subject = config.DeclRange.Ptr()
+	}
+	return diags.Append(diag)
+}
+
+func unknownProviderConfigSummary(severity tfdiags.Severity) string {
+	if severity == tfdiags.Error {
+		return "Invalid provider configuration"
+	}
+	return "Provider configuration not known during plan"
+}
+
+const unknownProviderConfigDetail = `The configuration for %s depends on values that cannot be determined until apply: the value of the %q argument is not known yet.
+
+Provider configuration can refer to input variables, local values and data resources, as long as their values are known during planning. Values derived from resources that have not been created yet are not known until apply.`
+
 const providerConfigErr = `Provider %q requires explicit configuration. Add a provider block to the root module and configure the provider's required arguments as described in the provider documentation.
 `
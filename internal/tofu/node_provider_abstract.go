@@ -26,6 +26,11 @@ type NodeAbstractProvider struct {
 
 	Config *configs.Provider
 	Schema *configschema.Block
+
+	// Inherits is the provider configuration whose arguments are used for
+	// any arguments that Config doesn't set, if Config has an "inherit"
+	// argument. DerivedProviderTransformer sets it.
+	Inherits addrs.AbsProviderConfig
 }
 
 var (
@@ -34,6 +39,7 @@ var (
 	_ GraphNodeProvider                   = (*NodeAbstractProvider)(nil)
 	_ GraphNodeAttachProvider             = (*NodeAbstractProvider)(nil)
 	_ GraphNodeAttachProviderConfigSchema = (*NodeAbstractProvider)(nil)
+	_ graphNodeDerivedProvider            = (*NodeAbstractProvider)(nil)
 	_ dag.GraphNodeDotter                 = (*NodeAbstractProvider)(nil)
 )
 
@@ -76,6 +82,11 @@ func (n *NodeAbstractProvider) ProviderConfig() *configs.Provider {
 	return n.Config
 }
 
+// graphNodeDerivedProvider
+func (n *NodeAbstractProvider) SetInheritedProvider(addr addrs.AbsProviderConfig) {
+	n.Inherits = addr
+}
+
 // GraphNodeAttachProvider
 func (n *NodeAbstractProvider) AttachProvider(c *configs.Provider) {
 	n.Config = c
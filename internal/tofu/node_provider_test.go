@@ -4,6 +4,7 @@
 package tofu
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -104,8 +105,8 @@ func TestNodeApplyableProviderExecute_unknownImport(t *testing.T) {
 		t.Fatal("expected error, got success")
 	}
 
-	detail := `Invalid provider configuration: The configuration for provider["registry.opentofu.org/hashicorp/foo"] depends on values that cannot be determined until apply.`
-	if got, want := diags.Err().Error(), detail; got != want {
+	detail := `Invalid provider configuration: The configuration for provider["registry.opentofu.org/hashicorp/foo"] depends on values that cannot be determined until apply: the value of the "test_string" argument is not known yet.`
+	if got, want := diags.Err().Error(), detail; !strings.HasPrefix(got, want) {
 		t.Errorf("wrong diagnostic detail\n got: %q\nwant: %q", got, want)
 	}
 
@@ -150,6 +151,46 @@ func TestNodeApplyableProviderExecute_unknownApply(t *testing.T) {
 	}
 }
 
+func TestNodeApplyableProviderExecute_unknownConfigureFailed(t *testing.T) {
+	config := &configs.Provider{
+		Name: "foo",
+		Config: configs.SynthBody("", map[string]cty.Value{
+			"test_string": cty.UnknownVal(cty.String),
+		}),
+	}
+	provider := mockProviderWithConfigSchema(simpleTestSchema())
+	providerAddr := addrs.AbsProviderConfig{
+		Module:   addrs.RootModule,
+		Provider: addrs.NewDefaultProvider("foo"),
+	}
+	n := &NodeApplyableProvider{&NodeAbstractProvider{
+		Addr:   providerAddr,
+		Config: config,
+	}}
+	ctx := &MockEvalContext{ProviderProvider: provider}
+	ctx.installSimpleEval()
+	ctx.ConfigureProviderDiags = ctx.ConfigureProviderDiags.Append(errors.New("missing test_string"))
+
+	diags := n.Execute(ctx, walkPlan)
+	if !diags.HasErrors() {
+		t.Fatal("expected error, got success")
+	}
+
+	var warnings []string
+	for _, diag := range diags {
+		if diag.Severity() == tfdiags.Warning {
+			warnings = append(warnings, diag.Description().Summary+": "+diag.Description().Detail)
+		}
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("expected one warning, got %d: %#v", len(warnings), warnings)
+	}
+	want := `Provider configuration not known during plan: The configuration for provider["registry.opentofu.org/hashicorp/foo"] depends on values that cannot be determined until apply: the value of the "test_string" argument is not known yet.`
+	if !strings.HasPrefix(warnings[0], want) {
+		t.Errorf("wrong warning\n got: %q\nwant: %q", warnings[0], want)
+	}
+}
+
 func TestNodeApplyableProviderExecute_sensitive(t *testing.T) {
 	config := &configs.Provider{
 		Name: "foo",
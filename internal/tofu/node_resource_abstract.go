@@ -57,6 +57,11 @@ type NodeAbstractResource struct {
 	SchemaVersion uint64              // Schema version of "Schema", as decided by the provider
 	Config        *configs.Resource   // Config is the resource in the config
 
+	// RemovedConfig is the "removed" block for a resource that is no longer
+	// in the configuration, if any, whose destroy-time provisioners run when
+	// the resource's objects are destroyed. This is only set if Config is nil.
+	RemovedConfig *configs.Removed
+
 	// ProviderMetas is the provider_meta configs for the module this resource belongs to
 	ProviderMetas map[addrs.Provider]*configs.ProviderMeta
 
@@ -65,6 +70,9 @@ type NodeAbstractResource struct {
 	// Set from GraphNodeTargetable
 	Targets []addrs.Targetable
 
+	// Set from GraphNodeExcludable
+	Excludes []addrs.Targetable
+
 	// Set from AttachDataResourceDependsOn
 	dependsOn      []addrs.ConfigResource
 	forceDependsOn bool
@@ -91,10 +99,12 @@ var (
 	_ GraphNodeProvisionerConsumer         = (*NodeAbstractResource)(nil)
 	_ GraphNodeConfigResource              = (*NodeAbstractResource)(nil)
 	_ GraphNodeAttachResourceConfig        = (*NodeAbstractResource)(nil)
+	_ GraphNodeAttachRemovedConfig         = (*NodeAbstractResource)(nil)
 	_ GraphNodeAttachResourceSchema        = (*NodeAbstractResource)(nil)
 	_ GraphNodeAttachProvisionerSchema     = (*NodeAbstractResource)(nil)
 	_ GraphNodeAttachProviderMetaConfigs   = (*NodeAbstractResource)(nil)
 	_ GraphNodeTargetable                  = (*NodeAbstractResource)(nil)
+	_ GraphNodeExcludable                  = (*NodeAbstractResource)(nil)
 	_ graphNodeAttachDataResourceDependsOn = (*NodeAbstractResource)(nil)
 	_ dag.GraphNodeDotter                  = (*NodeAbstractResource)(nil)
 )
@@ -145,13 +155,18 @@ func (n *NodeAbstractResource) References() []*addrs.Reference {
 	if c := n.Config; c != nil {
 		result = append(result, n.DependsOn()...)
 
+		// A depends_on expression may also refer to other objects, such as
+		// input variables, that are needed only to evaluate it.
+		refs, _ := lang.ReferencesInExpr(addrs.ParseRef, c.DependsOnExpr)
+		result = append(result, refs...)
+
 		if n.Schema == nil {
 			// Should never happen, but we'll log if it does so that we can
 			// see this easily when debugging.
 			log.Printf("[WARN] no schema is attached to %s, so config references cannot be detected", n.Name())
 		}
 
-		refs, _ := lang.ReferencesInExpr(addrs.ParseRef, c.Count)
+		refs, _ = lang.ReferencesInExpr(addrs.ParseRef, c.Count)
 		result = append(result, refs...)
 		refs, _ = lang.ReferencesInExpr(addrs.ParseRef, c.ForEach)
 		result = append(result, refs...)
@@ -160,6 +175,10 @@ func (n *NodeAbstractResource) References() []*addrs.Reference {
 			refs, _ = lang.ReferencesInExpr(addrs.ParseRef, expr)
 			result = append(result, refs...)
 		}
+		for _, expr := range c.TriggersReplacementValues {
+			refs, _ = lang.ReferencesInExpr(addrs.ParseRef, expr)
+			result = append(result, refs...)
+		}
 
 		// ReferencesInBlock() requires a schema
 		if n.Schema != nil {
@@ -168,6 +187,11 @@ func (n *NodeAbstractResource) References() []*addrs.Reference {
 		}
 
 		if c.Managed != nil {
+			for _, icw := range c.Managed.IgnoreChangesWhen {
+				refs, _ = lang.ReferencesInExpr(addrs.ParseRef, icw.Condition)
+				result = append(result, refs...)
+			}
+
 			if c.Managed.Connection != nil {
 				refs, _ = lang.ReferencesInBlock(addrs.ParseRef, c.Managed.Connection.Config, connectionBlockSupersetSchema)
 				result = append(result, refs...)
@@ -192,15 +216,11 @@ func (n *NodeAbstractResource) References() []*addrs.Reference {
 		}
 
 		for _, check := range c.Preconditions {
-			refs, _ := lang.ReferencesInExpr(addrs.ParseRef, check.Condition)
-			result = append(result, refs...)
-			refs, _ = lang.ReferencesInExpr(addrs.ParseRef, check.ErrorMessage)
+			refs, _ := lang.References(addrs.ParseRef, check.Variables())
 			result = append(result, refs...)
 		}
 		for _, check := range c.Postconditions {
-			refs, _ := lang.ReferencesInExpr(addrs.ParseRef, check.Condition)
-			result = append(result, refs...)
-			refs, _ = lang.ReferencesInExpr(addrs.ParseRef, check.ErrorMessage)
+			refs, _ := lang.References(addrs.ParseRef, check.Variables())
 			result = append(result, refs...)
 		}
 	}
@@ -208,6 +228,12 @@ func (n *NodeAbstractResource) References() []*addrs.Reference {
 	for _, importTarget := range n.importTargets {
 		refs, _ := lang.ReferencesInExpr(addrs.ParseRef, importTarget.ID)
 		result = append(result, refs...)
+		if c := importTarget.Config; c != nil && c.ForEach != nil {
+			refs, _ = lang.ReferencesInExpr(addrs.ParseRef, c.ForEach)
+			result = append(result, refs...)
+			refs, _ = lang.ReferencesInExpr(addrs.ParseRef, c.ToKey)
+			result = append(result, refs...)
+		}
 	}
 
 	return result
@@ -307,20 +333,36 @@ func (n *NodeAbstractResource) Provider() addrs.Provider {
 // GraphNodeProvisionerConsumer
 func (n *NodeAbstractResource) ProvisionedBy() []string {
 	// If we have no configuration, then we have no provisioners
-	if n.Config == nil || n.Config.Managed == nil {
+	managed := n.managedConfig()
+	if managed == nil {
 		return nil
 	}
 
 	// Build the list of provisioners we need based on the configuration.
 	// It is okay to have duplicates here.
-	result := make([]string, len(n.Config.Managed.Provisioners))
-	for i, p := range n.Config.Managed.Provisioners {
+	result := make([]string, len(managed.Provisioners))
+	for i, p := range managed.Provisioners {
 		result[i] = p.Type
 	}
 
 	return result
 }
 
+// managedConfig returns the provisioners and connection configuration for
+// the resource, either from its resource block or, if the resource has been
+// removed from the configuration, from its "removed" block. The result is
+// nil if there is neither.
+func (n *NodeAbstractResource) managedConfig() *configs.ManagedResource {
+	switch {
+	case n.Config != nil:
+		return n.Config.Managed
+	case n.RemovedConfig != nil:
+		return n.RemovedConfig.Managed
+	default:
+		return nil
+	}
+}
+
 // GraphNodeProvisionerConsumer
 func (n *NodeAbstractResource) AttachProvisionerSchema(name string, schema *configschema.Block) {
 	if n.ProvisionerSchemas == nil {
@@ -339,6 +381,11 @@ func (n *NodeAbstractResource) SetTargets(targets []addrs.Targetable) {
 	n.Targets = targets
 }
 
+// GraphNodeExcludable
+func (n *NodeAbstractResource) SetExcludes(excludes []addrs.Targetable) {
+	n.Excludes = excludes
+}
+
 // graphNodeAttachDataResourceDependsOn
 func (n *NodeAbstractResource) AttachDataResourceDependsOn(deps []addrs.ConfigResource, force bool) {
 	n.dependsOn = deps
@@ -350,6 +397,11 @@ func (n *NodeAbstractResource) AttachResourceConfig(c *configs.Resource) {
 	n.Config = c
 }
 
+// GraphNodeAttachRemovedConfig
+func (n *NodeAbstractResource) AttachRemovedConfig(c *configs.Removed) {
+	n.RemovedConfig = c
+}
+
 // GraphNodeAttachResourceSchema impl
 func (n *NodeAbstractResource) AttachResourceSchema(schema *configschema.Block, version uint64) {
 	n.Schema = schema
@@ -389,6 +441,18 @@ func (n *NodeAbstractResource) writeResourceState(ctx EvalContext, addr addrs.Ab
 	// to expand the module here to create all resources.
 	expander := ctx.InstanceExpander()
 
+	deferred, deferDiags := n.deferExpansion(ctx, addr)
+	diags = diags.Append(deferDiags)
+	if deferDiags.HasErrors() {
+		return diags
+	}
+	if deferred {
+		// A deferred resource has no instances in this round, but we leave
+		// any existing instances in the state untouched.
+		expander.SetResourceCount(addr.Module, n.Addr.Resource, 0)
+		return diags
+	}
+
 	switch {
 	case n.Config != nil && n.Config.Count != nil:
 		count, countDiags := evaluateCountExpression(n.Config.Count, ctx)
@@ -420,6 +484,44 @@ func (n *NodeAbstractResource) writeResourceState(ctx EvalContext, addr addrs.Ab
 	return diags
 }
 
+// deferExpansion returns true if the expansion of the given resource is
+// deferred to a later plan, either because the plan being applied deferred
+// it or because its count or for_each argument is not known yet and the
+// current operation allows deferring it.
+func (n *NodeAbstractResource) deferExpansion(ctx EvalContext, addr addrs.AbsResource) (bool, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	deferred := ctx.Deferred()
+	if deferred.IsResourceDeferred(addr) {
+		return true, diags
+	}
+	if !deferred.Allowed() || n.Config == nil {
+		return false, diags
+	}
+
+	var known bool
+	switch {
+	case n.Config.Count != nil:
+		countVal, countDiags := evaluateCountExpressionValue(n.Config.Count, ctx)
+		diags = diags.Append(countDiags)
+		known = countVal.IsKnown()
+	case n.Config.ForEach != nil:
+		forEachVal, forEachDiags := evaluateForEachExpressionValue(n.Config.ForEach, ctx, true)
+		diags = diags.Append(forEachDiags)
+		known = forEachVal.IsKnown()
+	default:
+		return false, diags
+	}
+	if diags.HasErrors() || known {
+		// We'll let the caller evaluate the expression again, which will
+		// return any errors.
+		return false, nil
+	}
+
+	log.Printf("[TRACE] deferring %s because its instances cannot be determined yet", addr)
+	deferred.ReportResourceDeferred(addr)
+	return true, diags
+}
+
 // readResourceInstanceState reads the current object for a specific instance in
 // the state.
 func (n *NodeAbstractResource) readResourceInstanceState(ctx EvalContext, addr addrs.AbsResourceInstance) (*states.ResourceInstanceObject, tfdiags.Diagnostics) {
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/zclconf/go-cty/cty"
@@ -337,6 +338,14 @@ func (n *NodeAbstractResourceInstance) writeResourceInstanceStateImpl(ctx EvalCo
 		return fmt.Errorf("failed to encode %s in state: no resource type schema available", absAddr)
 	}
 
+	if schema.ContainsEphemeral() {
+		// We must never save the values of ephemeral attributes, so we
+		// write a copy of the object with those attributes set to null.
+		objCopy := *obj
+		objCopy.Value = nullEphemeralAttributes(schema, obj.Value)
+		obj = &objCopy
+	}
+
 	src, err := obj.Encode(schema.ImpliedType(), currentVersion)
 	if err != nil {
 		return fmt.Errorf("failed to encode %s in state: %w", absAddr, err)
@@ -494,6 +503,15 @@ func (n *NodeAbstractResourceInstance) writeChange(ctx EvalContext, change *plan
 		return fmt.Errorf("provider does not support resource type %q", ri.Resource.Type)
 	}
 
+	if schema.ContainsEphemeral() {
+		// We must never save the values of ephemeral attributes, so we
+		// write a copy of the change with those attributes set to null.
+		changeCopy := *change
+		changeCopy.Before = nullEphemeralAttributes(schema, change.Before)
+		changeCopy.After = nullEphemeralAttributes(schema, change.After)
+		change = &changeCopy
+	}
+
 	csrc, err := change.Encode(schema.ImpliedType())
 	if err != nil {
 		return fmt.Errorf("failed to encode planned changes for %s: %w", n.Addr, err)
@@ -720,6 +738,11 @@ func (n *NodeAbstractResourceInstance) plan(
 		return nil, nil, keyData, diags
 	}
 
+	diags = diags.Append(validateEphemeralValues(schema, origConfigVal).InConfigBody(config.Config, n.Addr.String()))
+	if diags.HasErrors() {
+		return nil, nil, keyData, diags
+	}
+
 	metaConfigVal, metaDiags := n.providerMetas(ctx)
 	diags = diags.Append(metaDiags)
 	if diags.HasErrors() {
@@ -776,7 +799,12 @@ func (n *NodeAbstractResourceInstance) plan(
 	// starting values.
 	// Here we operate on the marked values, so as to revert any changes to the
 	// marks as well as the value.
-	configValIgnored, ignoreChangeDiags := n.processIgnoreChanges(priorVal, origConfigVal, schema)
+	ignoreChangesWhen, ignoreChangeDiags := evalIgnoreChangesWhen(ctx, n.Config.Managed.IgnoreChangesWhen, keyData)
+	diags = diags.Append(ignoreChangeDiags)
+	if ignoreChangeDiags.HasErrors() {
+		return nil, nil, keyData, diags
+	}
+	configValIgnored, ignoreChangeDiags := n.processIgnoreChanges(priorVal, origConfigVal, schema, ignoreChangesWhen)
 	diags = diags.Append(ignoreChangeDiags)
 	if ignoreChangeDiags.HasErrors() {
 		return nil, nil, keyData, diags
@@ -883,13 +911,18 @@ func (n *NodeAbstractResourceInstance) plan(
 		// A nil schema is passed to processIgnoreChanges to indicate that we
 		// don't want to fixup a config value according to the schema when
 		// ignoring "all", rather we are reverting provider imposed changes.
-		plannedNewVal, ignoreChangeDiags = n.processIgnoreChanges(unmarkedPriorVal, plannedNewVal, nil)
+		plannedNewVal, ignoreChangeDiags = n.processIgnoreChanges(unmarkedPriorVal, plannedNewVal, nil, ignoreChangesWhen)
 		diags = diags.Append(ignoreChangeDiags)
 		if ignoreChangeDiags.HasErrors() {
 			return nil, nil, keyData, diags
 		}
 	}
 
+	// Ephemeral attributes are never saved, so their planned values are
+	// always null. This also means that changing only the value of an
+	// ephemeral attribute never causes an update.
+	plannedNewVal = nullEphemeralAttributes(schema, plannedNewVal)
+
 	// Add the marks back to the planned new value -- this must happen after ignore changes
 	// have been processed
 	unmarkedPlannedNewVal := plannedNewVal
@@ -1049,7 +1082,7 @@ func (n *NodeAbstractResourceInstance) plan(
 			diags = diags.Append(resp.Diagnostics.InConfigBody(config.Config, n.Addr.String()))
 			return nil, nil, keyData, diags
 		}
-		plannedNewVal = resp.PlannedState
+		plannedNewVal = nullEphemeralAttributes(schema, resp.PlannedState)
 		plannedPrivate = resp.PlannedPrivate
 
 		if len(unmarkedPaths) > 0 {
@@ -1138,6 +1171,11 @@ func (n *NodeAbstractResourceInstance) plan(
 		ActionReason:    actionReason,
 		RequiredReplace: reqRep,
 	}
+	if plannedChange == nil {
+		// The explanations are only for the UI, so there's no need to
+		// recompute them when we plan again during apply.
+		plan.ChangeCauses = evaluateChangeCauses(ctx, n.Config, schema, plan)
+	}
 
 	// Update our return state
 	state := &states.ResourceInstanceObject{
@@ -1155,14 +1193,18 @@ func (n *NodeAbstractResourceInstance) plan(
 	return plan, state, keyData, diags
 }
 
-func (n *NodeAbstractResource) processIgnoreChanges(prior, config cty.Value, schema *configschema.Block) (cty.Value, tfdiags.Diagnostics) {
+// processIgnoreChanges reverts the changes to the attributes in the
+// resource's ignore_changes, and to the given additional attributes, which
+// are those of the ignore_changes_when blocks whose condition is true.
+func (n *NodeAbstractResource) processIgnoreChanges(prior, config cty.Value, schema *configschema.Block, when []hcl.Traversal) (cty.Value, tfdiags.Diagnostics) {
 	// ignore_changes only applies when an object already exists, since we
 	// can't ignore changes to a thing we've not created yet.
 	if prior.IsNull() {
 		return config, nil
 	}
 
-	ignoreChanges := traversalsToPaths(n.Config.Managed.IgnoreChanges)
+	ignoreChanges := expandIgnoreChanges(n.Config.Managed.IgnoreChanges, prior, config)
+	ignoreChanges = append(ignoreChanges, expandIgnoreChanges(when, prior, config)...)
 	ignoreAll := n.Config.Managed.IgnoreAllChanges
 
 	if len(ignoreChanges) == 0 && !ignoreAll {
@@ -1597,6 +1639,11 @@ func (n *NodeAbstractResourceInstance) planDataSource(ctx EvalContext, checkRule
 		return nil, nil, keyData, diags
 	}
 
+	diags = diags.Append(validateEphemeralValues(schema, configVal).InConfigBody(config.Config, n.Addr.String()))
+	if diags.HasErrors() {
+		return nil, nil, keyData, diags
+	}
+
 	check, nested := n.nestedInCheckBlock()
 	if nested {
 		// Going forward from this point, the only reason we will fail is
@@ -1886,15 +1933,23 @@ func (n *NodeAbstractResourceInstance) applyDataSource(ctx EvalContext, planned
 		return nil, keyData, diags
 	}
 
-	newVal, readDiags := n.readDataSource(ctx, configVal)
-	if check, nested := n.nestedInCheckBlock(); nested {
+	check, nested := n.nestedInCheckBlock()
+	var newVal cty.Value
+	var readDiags tfdiags.Diagnostics
+	if nested && check.Retry != nil {
+		newVal, readDiags = n.readDataSourceWithRetry(ctx, configVal, check.Retry)
+	} else {
+		newVal, readDiags = n.readDataSource(ctx, configVal)
+	}
+	if nested {
 		addr := check.Addr().Absolute(n.Addr.Module)
 
 		// We're just going to jump in here and hide away any errors for nested
-		// data blocks.
+		// data blocks, unless the check block asks for its failures to be
+		// reported as errors.
 		if readDiags.HasErrors() {
 			ctx.Checks().ReportCheckFailure(addr, addrs.CheckDataResource, 0, readDiags.Err().Error())
-			diags = diags.Append(tfdiags.OverrideAll(readDiags, tfdiags.Warning, func() tfdiags.DiagnosticExtraWrapper {
+			diags = diags.Append(tfdiags.OverrideAll(readDiags, check.Severity, func() tfdiags.DiagnosticExtraWrapper {
 				return &addrs.CheckRuleDiagnosticExtra{
 					CheckRule: addrs.NewCheckRule(addr, addrs.CheckDataResource, 0),
 				}
@@ -1928,6 +1983,116 @@ func (n *NodeAbstractResourceInstance) applyDataSource(ctx EvalContext, planned
 	return state, keyData, diags
 }
 
+// readDataSourceWithRetry calls readDataSource until it succeeds, making at
+// most the number of attempts given in the retry configuration of a check
+// block and waiting between each attempt.
+//
+// It returns the result of the last attempt, so the diagnostics of any
+// earlier failed attempts are only logged.
+func (n *NodeAbstractResourceInstance) readDataSourceWithRetry(ctx EvalContext, configVal cty.Value, retry *configs.CheckRetry) (cty.Value, tfdiags.Diagnostics) {
+	for attempt := 1; ; attempt++ {
+		newVal, diags := n.readDataSource(ctx, configVal)
+		if !diags.HasErrors() || attempt >= retry.Attempts {
+			return newVal, diags
+		}
+
+		delay := retry.Delay(attempt)
+		log.Printf("[WARN] readDataSourceWithRetry: attempt %d of %d to read %s failed, retrying in %s: %s", attempt, retry.Attempts, n.Addr, delay, diags.Err())
+		select {
+		case <-time.After(delay):
+		case <-ctx.Stopped():
+			return newVal, diags
+		}
+	}
+}
+
+// applyResourceChangeWithRetry calls ApplyResourceChange on the given
+// provider, retrying a failed change as allowed by the retry block in the
+// resource's lifecycle block, if any, and waiting between each attempt.
+//
+// A failure is retried only if the provider reports that the remote object
+// is unchanged, so that we never apply the same change twice to an object
+// that was already partially changed. It returns the response of the last
+// attempt, so the diagnostics of any earlier failed attempts are only
+// logged.
+//
+// If the resource's lifecycle block sets an operation timeout and the
+// attempts don't complete within it, it asks the provider to stop, waits
+// for the call in progress to return, and then returns the provider's
+// response with an additional timeout error, and true to indicate that the
+// outcome of the change is unknown.
+func (n *NodeAbstractResourceInstance) applyResourceChangeWithRetry(ctx EvalContext, provider providers.Interface, req providers.ApplyResourceChangeRequest, action plans.Action) (providers.ApplyResourceChangeResponse, bool) {
+	var retry *configs.ResourceRetry
+	var timeout time.Duration
+	if n.Config != nil && n.Config.Managed != nil {
+		retry = n.Config.Managed.Retry
+		timeout = n.Config.Managed.OperationTimeout
+	}
+
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	timedOut := func(respCh <-chan providers.ApplyResourceChangeResponse) (providers.ApplyResourceChangeResponse, bool) {
+		log.Printf("[ERROR] applyResourceChangeWithRetry: %s did not complete within its operation timeout of %s", n.Addr, timeout)
+		// The provider protocol has no way to cancel a single call, so we
+		// ask the provider to stop all of its in-flight operations, which
+		// cancels the context of the call in progress.
+		if err := provider.Stop(); err != nil {
+			log.Printf("[WARN] applyResourceChangeWithRetry: failed to stop the provider for %s: %s", n.Addr, err)
+		}
+		// We must not record the outcome in the state while the provider
+		// might still be changing the remote object, so we wait for the
+		// call to return, along with any partial new state it reports.
+		log.Printf("[TRACE] applyResourceChangeWithRetry: waiting for the provider to stop applying %s", n.Addr)
+		resp := <-respCh
+		resp.Diagnostics = resp.Diagnostics.Append(tfdiags.Sourceless(
+			tfdiags.Error,
+			"Operation timed out",
+			fmt.Sprintf(
+				"The provider didn't finish applying the %s change to %s within %s, as required by the operation_timeout argument in its lifecycle block, so OpenTofu stopped the provider. The remote object might have been left partially changed, so check its status before trying again.",
+				strings.ToLower(action.String()), n.Addr, timeout,
+			),
+		))
+		return resp, true
+	}
+
+	for attempt := 1; ; attempt++ {
+		respCh := make(chan providers.ApplyResourceChangeResponse, 1)
+		go func() {
+			respCh <- provider.ApplyResourceChange(req)
+		}()
+		var resp providers.ApplyResourceChangeResponse
+		select {
+		case resp = <-respCh:
+		case <-deadline:
+			return timedOut(respCh)
+		}
+
+		if retry == nil || attempt >= retry.Attempts || !retry.Retryable(resp.Diagnostics) {
+			return resp, false
+		}
+		if resp.NewState != cty.NilVal && !resp.NewState.IsNull() && !resp.NewState.RawEquals(req.PriorState) {
+			log.Printf("[WARN] applyResourceChangeWithRetry: not retrying %s because the failed attempt changed the object", n.Addr)
+			return resp, false
+		}
+
+		delay := retry.Delay(attempt)
+		log.Printf("[WARN] applyResourceChangeWithRetry: attempt %d of %d to apply %s failed, retrying in %s: %s", attempt, retry.Attempts, n.Addr, delay, resp.Diagnostics.Err())
+		select {
+		case <-time.After(delay):
+		case <-ctx.Stopped():
+			return resp, false
+		case <-deadline:
+			// The last attempt failed anyway, so we report its errors
+			// rather than the timeout.
+			return resp, false
+		}
+	}
+}
+
 // evalApplyProvisioners determines if provisioners need to be run, and if so
 // executes the provisioners for a resource and returns an updated error if
 // provisioning fails.
@@ -1950,7 +2115,7 @@ func (n *NodeAbstractResourceInstance) evalApplyProvisioners(ctx EvalContext, st
 		return nil
 	}
 
-	provs := filterProvisioners(n.Config, when)
+	provs := filterProvisioners(n.managedConfig(), when)
 	if len(provs) == 0 {
 		// We have no provisioners, so don't do anything
 		return nil
@@ -1980,18 +2145,18 @@ func (n *NodeAbstractResourceInstance) evalApplyProvisioners(ctx EvalContext, st
 
 // filterProvisioners filters the provisioners on the resource to only
 // the provisioners specified by the "when" option.
-func filterProvisioners(config *configs.Resource, when configs.ProvisionerWhen) []*configs.Provisioner {
+func filterProvisioners(managed *configs.ManagedResource, when configs.ProvisionerWhen) []*configs.Provisioner {
 	// Fast path the zero case
-	if config == nil || config.Managed == nil {
+	if managed == nil {
 		return nil
 	}
 
-	if len(config.Managed.Provisioners) == 0 {
+	if len(managed.Provisioners) == 0 {
 		return nil
 	}
 
-	result := make([]*configs.Provisioner, 0, len(config.Managed.Provisioners))
-	for _, p := range config.Managed.Provisioners {
+	result := make([]*configs.Provisioner, 0, len(managed.Provisioners))
+	for _, p := range managed.Provisioners {
 		if p.When == when {
 			result = append(result, p)
 		}
@@ -2020,8 +2185,8 @@ func (n *NodeAbstractResourceInstance) applyProvisioners(ctx EvalContext, state
 	// then it'll serve as a base connection configuration for all of the
 	// provisioners.
 	var baseConn hcl.Body
-	if n.Config.Managed != nil && n.Config.Managed.Connection != nil {
-		baseConn = n.Config.Managed.Connection.Config
+	if managed := n.managedConfig(); managed != nil && managed.Connection != nil {
+		baseConn = managed.Connection.Config
 	}
 
 	for _, prov := range provs {
@@ -2293,14 +2458,26 @@ func (n *NodeAbstractResourceInstance) apply(
 		return newState, diags
 	}
 
-	resp := provider.ApplyResourceChange(providers.ApplyResourceChangeRequest{
+	resp, timedOut := n.applyResourceChangeWithRetry(ctx, provider, providers.ApplyResourceChangeRequest{
 		TypeName:       n.Addr.Resource.Resource.Type,
 		PriorState:     unmarkedBefore,
 		Config:         unmarkedConfigVal,
 		PlannedState:   unmarkedAfter,
 		PlannedPrivate: change.Private,
 		ProviderMeta:   metaConfigVal,
-	})
+	}, change.Action)
+	if timedOut && (resp.NewState == cty.NilVal || resp.NewState.IsNull()) {
+		// The provider didn't tell us what it did before it was stopped, so
+		// rather than losing track of the remote object we keep the prior
+		// object, or the planned one if we were creating it, and it's
+		// marked as tainted below unless we were deleting it.
+		if change.Action == plans.Create {
+			resp.NewState = cty.UnknownAsNull(unmarkedAfter)
+		} else {
+			resp.NewState = unmarkedBefore
+			resp.Private = state.Private
+		}
+	}
 	applyDiags := resp.Diagnostics
 	if applyConfig != nil {
 		applyDiags = applyDiags.InConfigBody(applyConfig.Config, n.Addr.String())
@@ -2404,6 +2581,11 @@ func (n *NodeAbstractResourceInstance) apply(
 		newVal = cty.UnknownAsNull(newVal)
 	}
 
+	// The planned values of ephemeral attributes are always null, and we
+	// never save their values, so we ignore whatever the provider returned
+	// for them.
+	newVal = nullEphemeralAttributes(schema, newVal)
+
 	if change.Action != plans.Delete && !diags.HasErrors() {
 		// Only values that were marked as unknown in the planned value are allowed
 		// to change during the apply operation. (We do this after the unknown-ness
@@ -2504,6 +2686,13 @@ func (n *NodeAbstractResourceInstance) apply(
 			newState.Dependencies = state.Dependencies
 		}
 
+		// If the provider didn't finish creating or updating the object,
+		// because it didn't finish in time or its plugin crashed, then we
+		// don't know what state it was left in, so it must be replaced.
+		if (timedOut || providers.PluginCrashedIn(resp.Diagnostics)) && change.Action != plans.Delete {
+			newState.Status = states.ObjectTainted
+		}
+
 		return newState, diags
 
 	case !newVal.IsNull():
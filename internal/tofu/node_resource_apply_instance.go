@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/zclconf/go-cty/cty"
+
 	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/instances"
@@ -306,6 +308,11 @@ func (n *NodeApplyableResourceInstance) managedResourceExecute(ctx EvalContext)
 		return diags.Append(n.managedResourcePostconditions(ctx, repeatData))
 	}
 
+	priorTriggers := cty.NilVal
+	if state != nil {
+		priorTriggers = state.ReplaceTriggers
+	}
+
 	state, applyDiags := n.apply(ctx, state, diffApply, n.Config, repeatData, n.CreateBeforeDestroy())
 	diags = diags.Append(applyDiags)
 
@@ -321,6 +328,18 @@ func (n *NodeApplyableResourceInstance) managedResourceExecute(ctx EvalContext)
 	if state != nil {
 		// dependencies are always updated to match the configuration during apply
 		state.Dependencies = n.Dependencies
+
+		// The values of any replace_triggered_by expressions are recorded
+		// only once the change succeeds, so that a failed change will still
+		// be retried if they have changed.
+		state.ReplaceTriggers = priorTriggers
+		if !diags.HasErrors() {
+			triggers, triggerDiags := evalReplaceTriggeredByValues(ctx, n.Config.TriggersReplacementValues, repeatData)
+			diags = diags.Append(triggerDiags)
+			if !triggerDiags.HasErrors() {
+				state.ReplaceTriggers = triggers
+			}
+		}
 	}
 	err = n.writeResourceInstanceState(ctx, state, workingState)
 	if err != nil {
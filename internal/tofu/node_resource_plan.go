@@ -166,6 +166,13 @@ func (n *nodeExpandPlannableResource) DynamicExpand(ctx EvalContext) (*Graph, er
 		err := n.expandResourceInstances(ctx, resAddr, &g, instAddrs)
 		diags = diags.Append(err)
 	}
+
+	// If we're looking for remote objects that OpenTofu doesn't manage yet,
+	// the provider lists the existing objects of this resource's type.
+	if discovery := ctx.Discovery(); discovery != nil {
+		diags = diags.Append(discovery.list(ctx, n))
+	}
+
 	if diags.HasErrors() {
 		return nil, diags.ErrWithWarnings()
 	}
@@ -212,6 +219,12 @@ func (n *nodeExpandPlannableResource) expandResourceInstances(globalCtx EvalCont
 		return diags.ErrWithWarnings()
 	}
 
+	// A deferred resource has no instances to plan in this round, and any
+	// existing instances must not be treated as orphans.
+	if moduleCtx.Deferred().IsResourceDeferred(resAddr) {
+		return diags.ErrWithWarnings()
+	}
+
 	// Before we expand our resource into potentially many resource instances,
 	// we'll verify that any mention of this resource in n.forceReplace is
 	// consistent with the repetition mode of the resource. In other words,
@@ -291,7 +304,13 @@ func (n *nodeExpandPlannableResource) expandResourceInstances(globalCtx EvalCont
 	// construct a subgraph just for this individual modules's instances and
 	// then we'll steal all of its nodes and edges to incorporate into our
 	// main graph which contains all of the resource instances together.
-	instG, err := n.resourceInstanceSubgraph(moduleCtx, resAddr, instanceAddrs)
+	importTargets, importDiags := expandImportTargets(moduleCtx, resAddr, n.importTargets, instanceAddrs)
+	diags = diags.Append(importDiags)
+	if importDiags.HasErrors() {
+		return diags.ErrWithWarnings()
+	}
+
+	instG, err := n.resourceInstanceSubgraph(moduleCtx, resAddr, instanceAddrs, importTargets)
 	if err != nil {
 		diags = diags.Append(err)
 		return diags.ErrWithWarnings()
@@ -301,7 +320,7 @@ func (n *nodeExpandPlannableResource) expandResourceInstances(globalCtx EvalCont
 	return diags.ErrWithWarnings()
 }
 
-func (n *nodeExpandPlannableResource) resourceInstanceSubgraph(ctx EvalContext, addr addrs.AbsResource, instanceAddrs []addrs.AbsResourceInstance) (*Graph, error) {
+func (n *nodeExpandPlannableResource) resourceInstanceSubgraph(ctx EvalContext, addr addrs.AbsResource, instanceAddrs []addrs.AbsResourceInstance, importTargets []*ImportTarget) (*Graph, error) {
 	var diags tfdiags.Diagnostics
 
 	// Our graph transformers require access to the full state, so we'll
@@ -316,7 +335,7 @@ func (n *nodeExpandPlannableResource) resourceInstanceSubgraph(ctx EvalContext,
 		// If we're in legacy import mode (the import CLI command), we only need
 		// to return the import node, not a plannable resource node.
 		if n.legacyImportMode {
-			for _, importTarget := range n.importTargets {
+			for _, importTarget := range importTargets {
 				if importTarget.Addr.Equal(a.Addr) {
 
 					// The import ID was supplied as a string on the command
@@ -361,7 +380,7 @@ func (n *nodeExpandPlannableResource) resourceInstanceSubgraph(ctx EvalContext,
 			forceReplace:             n.forceReplace,
 		}
 
-		for _, importTarget := range n.importTargets {
+		for _, importTarget := range importTargets {
 			if importTarget.Addr.Equal(a.Addr) {
 				// If we get here, we're definitely not in legacy import mode,
 				// so go ahead and plan the resource changes including import.
@@ -414,7 +433,7 @@ func (n *nodeExpandPlannableResource) resourceInstanceSubgraph(ctx EvalContext,
 		&AttachStateTransformer{State: state},
 
 		// Targeting
-		&TargetsTransformer{Targets: n.Targets},
+		&TargetsTransformer{Targets: n.Targets, Excludes: n.Excludes},
 
 		// Connect references so ordering is correct
 		&ReferenceTransformer{},
@@ -48,6 +48,11 @@ type NodePlannableResourceInstance struct {
 	// triggered this instance to be replaced.
 	replaceTriggeredBy []*addrs.Reference
 
+	// replaceTriggeredByValues is true if the values of the
+	// replace_triggered_by expressions which aren't references to resources
+	// changed, triggering this instance to be replaced.
+	replaceTriggeredByValues bool
+
 	// importTarget, if populated, contains the information necessary to plan
 	// an import of this resource.
 	importTarget ImportTarget
@@ -68,6 +73,17 @@ var (
 func (n *NodePlannableResourceInstance) Execute(ctx EvalContext, op walkOperation) tfdiags.Diagnostics {
 	addr := n.ResourceInstanceAddr()
 
+	// If this instance depends on a resource whose changes are deferred then
+	// its own changes must be deferred too, because the planned values
+	// would be based on an incomplete view of its dependencies. The other
+	// instances of the same resource are unaffected unless they depend on
+	// deferred resources too.
+	if deferred := ctx.Deferred(); deferred.Allowed() && deferred.HaveDeferredDependency(addr.Module, n.Dependencies) {
+		log.Printf("[TRACE] NodePlannableResourceInstance: deferring %s because it depends on a deferred resource", addr)
+		deferred.ReportResourceInstanceDeferred(addr)
+		return nil
+	}
+
 	// Eval info is different depending on what kind of resource this is
 	switch addr.Resource.Resource.Mode {
 	case addrs.ManagedResourceMode:
@@ -106,6 +122,9 @@ func (n *NodePlannableResourceInstance) dataResourceExecute(ctx EvalContext) (di
 	if diags.HasErrors() {
 		return diags
 	}
+	if !n.skipPlanChanges {
+		n.refineDataSource(ctx, change, state)
+	}
 
 	// write the data source into both the refresh state and the
 	// working state
@@ -234,10 +253,30 @@ func (n *NodePlannableResourceInstance) managedResourceExecute(ctx EvalContext)
 		}
 	}
 
+	// When refining a saved plan, we reuse its change for this instance
+	// without refreshing or planning it again if nothing it depends on has
+	// changed since.
+	if !importing && !n.skipPlanChanges {
+		reused, reuseDiags := n.reuseRefinedChange(ctx, instanceRefreshState)
+		diags = diags.Append(reuseDiags)
+		if reused || diags.HasErrors() {
+			return diags
+		}
+	}
+
 	// Refresh, maybe
 	// The import process handles its own refresh
 	if !n.skipRefresh && !importing {
-		s, refreshDiags := n.refresh(ctx, states.NotDeposed, instanceRefreshState)
+		// The object may already have been read as part of the batch for
+		// its provider, in which case we just take the result.
+		s, refreshDiags, batched := ctx.RefreshBatch().Result(ctx, addr, n.ResolvedProvider)
+		if batched {
+			if s != nil && instanceRefreshState != nil {
+				s.CreateBeforeDestroy = instanceRefreshState.CreateBeforeDestroy
+			}
+		} else {
+			s, refreshDiags = n.refresh(ctx, states.NotDeposed, instanceRefreshState)
+		}
 		diags = diags.Append(refreshDiags)
 		if diags.HasErrors() {
 			return diags
@@ -278,6 +317,11 @@ func (n *NodePlannableResourceInstance) managedResourceExecute(ctx EvalContext)
 		if diags.HasErrors() {
 			return diags
 		}
+		triggers, triggerDiags := n.replaceTriggeredByValueChanges(ctx, instanceRefreshState)
+		diags = diags.Append(triggerDiags)
+		if diags.HasErrors() {
+			return diags
+		}
 
 		change, instancePlanState, repeatData, planDiags := n.plan(
 			ctx, nil, instanceRefreshState, n.ForceCreateBeforeDestroy, n.forceReplace,
@@ -314,7 +358,7 @@ func (n *NodePlannableResourceInstance) managedResourceExecute(ctx EvalContext)
 		// FIXME: here we udpate the change to reflect the reason for
 		// replacement, but we still overload forceReplace to get the correct
 		// change planned.
-		if len(n.replaceTriggeredBy) > 0 {
+		if len(n.replaceTriggeredBy) > 0 || n.replaceTriggeredByValues {
 			change.ActionReason = plans.ResourceInstanceReplaceByTriggers
 		}
 
@@ -345,13 +389,16 @@ func (n *NodePlannableResourceInstance) managedResourceExecute(ctx EvalContext)
 		}
 
 		// If this plan resulted in a NoOp, then apply won't have a chance to make
-		// any changes to the stored dependencies. Since this is a NoOp we know
-		// that the stored dependencies will have no effect during apply, and we can
-		// write them out now.
-		if change.Action == plans.NoOp && !depsEqual(instanceRefreshState.Dependencies, n.Dependencies) {
+		// any changes to the stored dependencies or replace_triggered_by
+		// values. Since this is a NoOp we know that these will have no effect
+		// during apply, and we can write them out now.
+		if change.Action == plans.NoOp && (!depsEqual(instanceRefreshState.Dependencies, n.Dependencies) || replaceTriggerValuesNeedUpdate(instanceRefreshState.ReplaceTriggers, triggers)) {
 			// the refresh state will be the final state for this resource, so
 			// finalize the dependencies here if they need to be updated.
 			instanceRefreshState.Dependencies = n.Dependencies
+			if triggers == cty.NilVal || triggers.IsWhollyKnown() {
+				instanceRefreshState.ReplaceTriggers = triggers
+			}
 			diags = diags.Append(n.writeResourceInstanceState(ctx, instanceRefreshState, refreshState))
 			if diags.HasErrors() {
 				return diags
@@ -450,6 +497,33 @@ func (n *NodePlannableResourceInstance) replaceTriggered(ctx EvalContext, repDat
 	return diags
 }
 
+// replaceTriggeredByValueChanges evaluates the replace_triggered_by
+// expressions which aren't references to resources, and adds this instance
+// to forceReplace if their values differ from those recorded in the given
+// prior state. It returns the new values so that they can be recorded in
+// state.
+func (n *NodePlannableResourceInstance) replaceTriggeredByValueChanges(ctx EvalContext, state *states.ResourceInstanceObject) (cty.Value, tfdiags.Diagnostics) {
+	if n.Config == nil || len(n.Config.TriggersReplacementValues) == 0 {
+		return cty.NilVal, nil
+	}
+
+	forEach, _ := evaluateForEachExpression(n.Config.ForEach, ctx)
+	keyData := EvalDataForInstanceKey(n.ResourceInstanceAddr().Resource.Key, forEach)
+	triggers, diags := evalReplaceTriggeredByValues(ctx, n.Config.TriggersReplacementValues, keyData)
+	if diags.HasErrors() {
+		return cty.NilVal, diags
+	}
+
+	if state != nil && replaceTriggerValuesChanged(state.ReplaceTriggers, triggers) {
+		n.forceReplace = append(n.forceReplace, n.Addr)
+		log.Printf("[DEBUG] ReplaceTriggeredBy forcing replacement of %s due to changed values", n.Addr)
+
+		n.replaceTriggeredByValues = true
+	}
+
+	return triggers, diags
+}
+
 func (n *NodePlannableResourceInstance) importState(ctx EvalContext, addr addrs.AbsResourceInstance, importId string, provider providers.Interface, providerSchema providers.ProviderSchema) (*states.ResourceInstanceObject, tfdiags.Diagnostics) {
 	var diags tfdiags.Diagnostics
 	absAddr := addr.Resource.Absolute(ctx.Path())
@@ -46,6 +46,23 @@ func (n *NodePlannableResourceInstanceOrphan) Name() string {
 func (n *NodePlannableResourceInstanceOrphan) Execute(ctx EvalContext, op walkOperation) tfdiags.Diagnostics {
 	addr := n.ResourceInstanceAddr()
 
+	// Orphans are always planned again when refining a saved plan.
+	ctx.Refinement().markAffected(addr.ConfigResource())
+
+	// An orphan is left in the state until a later plan if its resource is
+	// deferred, or if it depends on a deferred resource, in the same way as
+	// the instances that are still in the configuration.
+	deferred := ctx.Deferred()
+	if deferred.IsResourceInstanceDeferred(addr) {
+		log.Printf("[TRACE] NodePlannableResourceInstanceOrphan: %s is deferred", addr)
+		return nil
+	}
+	if deferred.Allowed() && deferred.HaveDeferredDependency(addr.Module, n.StateDependencies()) {
+		log.Printf("[TRACE] NodePlannableResourceInstanceOrphan: deferring %s because it depends on a deferred resource", addr)
+		deferred.ReportResourceInstanceDeferred(addr)
+		return nil
+	}
+
 	// Eval info is different depending on what kind of resource this is
 	switch addr.Resource.Resource.Mode {
 	case addrs.ManagedResourceMode:
@@ -108,7 +125,10 @@ func (n *NodePlannableResourceInstanceOrphan) managedResourceExecute(ctx EvalCon
 		// plan before apply, and may not handle a missing resource during
 		// Delete correctly.  If this is a simple refresh, OpenTofu is
 		// expected to remove the missing resource from the state entirely
-		refreshedState, refreshDiags := n.refresh(ctx, states.NotDeposed, oldState)
+		refreshedState, refreshDiags, batched := ctx.RefreshBatch().Result(ctx, addr, n.ResolvedProvider)
+		if !batched {
+			refreshedState, refreshDiags = n.refresh(ctx, states.NotDeposed, oldState)
+		}
 		diags = diags.Append(refreshDiags)
 		if diags.HasErrors() {
 			return diags
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/opentofu/opentofu/internal/addrs"
@@ -53,6 +54,22 @@ func (n *NodeValidatableResource) Execute(ctx EvalContext, op walkOperation) (di
 
 	diags = diags.Append(n.validateCheckRules(ctx, n.Config))
 
+	if len(n.Config.TriggersReplacementValues) > 0 {
+		keyData, _ := n.stubRepetitionData(n.Config.Count != nil, n.Config.ForEach != nil)
+		for _, expr := range n.Config.TriggersReplacementValues {
+			_, exprDiags := n.evaluateExpr(ctx, expr, cty.DynamicPseudoType, nil, keyData)
+			diags = diags.Append(exprDiags)
+		}
+	}
+
+	if managed := n.Config.Managed; managed != nil && len(managed.IgnoreChangesWhen) > 0 {
+		keyData, _ := n.stubRepetitionData(n.Config.Count != nil, n.Config.ForEach != nil)
+		for _, icw := range managed.IgnoreChangesWhen {
+			_, exprDiags := n.evaluateExpr(ctx, icw.Condition, cty.Bool, nil, keyData)
+			diags = diags.Append(exprDiags)
+		}
+	}
+
 	if managed := n.Config.Managed; managed != nil {
 		// Validate all the provisioners
 		for _, p := range managed.Provisioners {
@@ -304,7 +321,7 @@ func (n *NodeValidatableResource) validateResource(ctx EvalContext) tfdiags.Diag
 		diags = diags.Append(forEachDiags)
 	}
 
-	diags = diags.Append(validateDependsOn(ctx, n.Config.DependsOn))
+	diags = diags.Append(validateDependsOn(ctx, n.Config.DependsOn, n.Config.DependsOnExpr))
 
 	// Validate the provider_meta block for the provider this resource
 	// belongs to, if there is one.
@@ -374,10 +391,18 @@ func (n *NodeValidatableResource) validateResource(ctx EvalContext) tfdiags.Diag
 		if valDiags.HasErrors() {
 			return diags
 		}
+		diags = diags.Append(validateEphemeralValues(schema, configVal).InConfigBody(n.Config.Config, n.Addr.String()))
 
 		if n.Config.Managed != nil { // can be nil only in tests with poorly-configured mocks
-			for _, traversal := range n.Config.Managed.IgnoreChanges {
-				// validate the ignore_changes traversals apply.
+			var ignoreChanges []hcl.Traversal
+			ignoreChanges = append(ignoreChanges, n.Config.Managed.IgnoreChanges...)
+			for _, icw := range n.Config.Managed.IgnoreChangesWhen {
+				ignoreChanges = append(ignoreChanges, icw.Attributes...)
+			}
+			for _, traversal := range ignoreChanges {
+				// validate the ignore_changes traversals apply, treating
+				// any splat steps as indexes with unknown keys.
+				traversal = staticIgnoreChangesTraversal(traversal)
 				moreDiags := schema.StaticValidateTraversal(traversal)
 				diags = diags.Append(moreDiags)
 
@@ -448,6 +473,7 @@ func (n *NodeValidatableResource) validateResource(ctx EvalContext) tfdiags.Diag
 		if valDiags.HasErrors() {
 			return diags
 		}
+		diags = diags.Append(validateEphemeralValues(schema, configVal).InConfigBody(n.Config.Config, n.Addr.String()))
 
 		// Use unmarked value for validate request
 		unmarkedConfigVal, _ := configVal.UnmarkDeep()
@@ -520,6 +546,11 @@ func (n *NodeValidatableResource) validateCheckRules(ctx EvalContext, config *co
 	keyData, selfAddr := n.stubRepetitionData(n.Config.Count != nil, n.Config.ForEach != nil)
 
 	for _, cr := range config.Preconditions {
+		if cr.ForEach != nil {
+			diags = diags.Append(n.validateDynamicCheckRule(ctx, cr, nil, keyData))
+			continue
+		}
+
 		_, conditionDiags := n.evaluateExpr(ctx, cr.Condition, cty.Bool, nil, keyData)
 		diags = diags.Append(conditionDiags)
 
@@ -528,6 +559,11 @@ func (n *NodeValidatableResource) validateCheckRules(ctx EvalContext, config *co
 	}
 
 	for _, cr := range config.Postconditions {
+		if cr.ForEach != nil {
+			diags = diags.Append(n.validateDynamicCheckRule(ctx, cr, selfAddr, keyData))
+			continue
+		}
+
 		_, conditionDiags := n.evaluateExpr(ctx, cr.Condition, cty.Bool, selfAddr, keyData)
 		diags = diags.Append(conditionDiags)
 
@@ -538,6 +574,38 @@ func (n *NodeValidatableResource) validateCheckRules(ctx EvalContext, config *co
 	return diags
 }
 
+// validateDynamicCheckRule validates a check rule declared by a "dynamic"
+// block. The for_each value might not be known yet, so the rule's condition
+// and error message are evaluated only once, with an unknown value for the
+// iterator.
+func (n *NodeValidatableResource) validateDynamicCheckRule(ctx EvalContext, cr *configs.CheckRule, self addrs.Referenceable, keyData instances.RepetitionData) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	refs, refDiags := lang.References(addrs.ParseRef, cr.Variables())
+	diags = diags.Append(refDiags)
+
+	scope := ctx.EvaluationScope(self, nil, keyData)
+	hclCtx, moreDiags := scope.EvalContext(refs)
+	diags = diags.Append(moreDiags)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	_, hclDiags := cr.ForEach.Value(hclCtx)
+	diags = diags.Append(hclDiags)
+
+	hclCtx = hclCtx.NewChild()
+	hclCtx.Variables = map[string]cty.Value{
+		cr.Iterator: cty.DynamicVal,
+	}
+	_, hclDiags = cr.Condition.Value(hclCtx)
+	diags = diags.Append(hclDiags)
+	_, hclDiags = cr.ErrorMessage.Value(hclCtx)
+	diags = diags.Append(hclDiags)
+
+	return diags
+}
+
 func validateCount(ctx EvalContext, expr hcl.Expression) (diags tfdiags.Diagnostics) {
 	val, countDiags := evaluateCountExpressionValue(expr, ctx)
 	// If the value isn't known then that's the best we can do for now, but
@@ -568,7 +636,13 @@ func validateForEach(ctx EvalContext, expr hcl.Expression) (diags tfdiags.Diagno
 	return diags
 }
 
-func validateDependsOn(ctx EvalContext, dependsOn []hcl.Traversal) (diags tfdiags.Diagnostics) {
+func validateDependsOn(ctx EvalContext, dependsOn []hcl.Traversal, dependsOnExpr hcl.Expression) (diags tfdiags.Diagnostics) {
+	if dependsOnExpr != nil {
+		// The static references were found in the expression, so evaluating
+		// the expression itself checks that they exist.
+		return validateDependsOnExpr(ctx, dependsOnExpr)
+	}
+
 	for _, traversal := range dependsOn {
 		ref, refDiags := addrs.ParseRef(traversal)
 		diags = diags.Append(refDiags)
@@ -594,3 +668,55 @@ func validateDependsOn(ctx EvalContext, dependsOn []hcl.Traversal) (diags tfdiag
 	}
 	return diags
 }
+
+// validateDependsOnExpr checks that a depends_on expression that isn't a
+// static list of references produces a collection of objects, such as
+// resources and module calls.
+func validateDependsOnExpr(ctx EvalContext, expr hcl.Expression) (diags tfdiags.Diagnostics) {
+	scope := ctx.EvaluationScope(nil, nil, EvalDataForNoInstanceKey)
+	if scope == nil { // sometimes nil in tests, due to incomplete mocks
+		return diags
+	}
+
+	// The result types of a conditional expression must usually agree, which
+	// would prevent choosing between lists of different lengths, so we
+	// validate each of the results separately instead.
+	if cond, ok := expr.(*hclsyntax.ConditionalExpr); ok {
+		_, condDiags := scope.EvalExpr(cond.Condition, cty.Bool)
+		diags = diags.Append(condDiags)
+		diags = diags.Append(validateDependsOnExpr(ctx, cond.TrueResult))
+		diags = diags.Append(validateDependsOnExpr(ctx, cond.FalseResult))
+		return diags
+	}
+
+	val, valDiags := scope.EvalExpr(expr, cty.DynamicPseudoType)
+	diags = diags.Append(valDiags)
+	if valDiags.HasErrors() || !val.IsKnown() || val.IsNull() {
+		return diags
+	}
+
+	ty := val.Type()
+	if !ty.IsListType() && !ty.IsSetType() && !ty.IsTupleType() {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid depends_on value",
+			Detail:   fmt.Sprintf("The depends_on argument must be a list of objects, such as resources or modules, but this expression produced %s.", ty.FriendlyName()),
+			Subject:  expr.Range().Ptr(),
+		})
+		return diags
+	}
+
+	for it := val.ElementIterator(); it.Next(); {
+		_, v := it.Element()
+		if v.Type().IsPrimitiveType() {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Invalid depends_on value",
+				Detail:   fmt.Sprintf("The depends_on argument must be a list of objects, such as resources or modules, but this expression produced a list containing %s, which doesn't identify any object.", v.Type().FriendlyName()),
+				Subject:  expr.Range().Ptr(),
+			})
+			return diags
+		}
+	}
+	return diags
+}
@@ -0,0 +1,348 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// PlanRefinement describes an earlier saved plan that a new plan refines,
+// reusing the changes planned for the resource instances that are not
+// affected by what has changed since the saved plan was created.
+type PlanRefinement struct {
+	// Plan is the saved plan to refine.
+	Plan *plans.Plan
+
+	// ChangedResources are the resources whose configuration has changed
+	// since the saved plan was created. It's the caller's responsibility to
+	// determine these, and to not refine the plan at all if anything other
+	// than resource configuration has changed.
+	ChangedResources []addrs.ConfigResource
+}
+
+// refinementIncompatibility returns a description of why the given saved
+// plan can't be refined by a plan with the given options, or an empty
+// string if it can.
+func refinementIncompatibility(saved *plans.Plan, opts *PlanOpts) string {
+	switch {
+	case saved.Errored:
+		return "The saved plan is incomplete because it was created with errors."
+	case saved.UIMode != plans.NormalMode || opts.Mode != plans.NormalMode:
+		return "Only plans created in the normal planning mode can be refined."
+	case len(saved.DeferredResources) > 0:
+		return "The saved plan has deferred changes."
+	case !targetsEqual(saved.TargetAddrs, opts.Targets) || !targetsEqual(saved.ExcludeAddrs, opts.Excludes):
+		return "The saved plan was created with different -target or -exclude options."
+	case !saved.Stage.Equal(opts.Stage):
+		return "The saved plan was created for a different stage."
+	case !forceReplaceEqual(saved.ForceReplaceAddrs, opts.ForceReplace):
+		return "The saved plan was created with different -replace options."
+	}
+
+	for name, iv := range opts.SetVariables {
+		if iv.Value == cty.NilVal {
+			continue
+		}
+		dv, ok := saved.VariableValues[name]
+		if !ok {
+			if _, ephemeral := saved.EphemeralVariableValues[name]; ephemeral {
+				// Ephemeral values can't contribute to the planned changes.
+				continue
+			}
+			return fmt.Sprintf("The value of variable %q has changed.", name)
+		}
+		val, err := dv.Decode(cty.DynamicPseudoType)
+		if err != nil || !val.RawEquals(iv.Value) {
+			return fmt.Sprintf("The value of variable %q has changed.", name)
+		}
+	}
+	for name := range saved.VariableValues {
+		if iv, ok := opts.SetVariables[name]; !ok || iv.Value == cty.NilVal {
+			return fmt.Sprintf("The value of variable %q has changed.", name)
+		}
+	}
+
+	return ""
+}
+
+func targetsEqual(a, b []addrs.Targetable) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].String() != b[i].String() {
+			return false
+		}
+	}
+	return true
+}
+
+func forceReplaceEqual(a, b []addrs.AbsResourceInstance) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// planRefinement tracks which parts of a saved plan can be reused while
+// walking the graph to refine it. A nil *planRefinement reuses nothing.
+type planRefinement struct {
+	plan *plans.Plan
+
+	mu sync.Mutex
+	// affected records the resources that changed since the saved plan was
+	// created, or had to be planned again, by their ConfigResource string.
+	affected map[string]bool
+}
+
+func newPlanRefinement(r *PlanRefinement) *planRefinement {
+	if r == nil {
+		return nil
+	}
+	ret := &planRefinement{
+		plan:     r.Plan,
+		affected: make(map[string]bool),
+	}
+	for _, addr := range r.ChangedResources {
+		ret.affected[addr.String()] = true
+	}
+	return ret
+}
+
+// markAffected records that the given resource was planned again, so that
+// the resources that depend on it must be planned again too.
+func (r *planRefinement) markAffected(addr addrs.ConfigResource) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.affected[addr.String()] = true
+}
+
+// unaffected returns true if neither the given resource nor any of the
+// given resources it depends on were affected by changes.
+func (r *planRefinement) unaffected(addr addrs.ConfigResource, deps []addrs.ConfigResource) bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.affected[addr.String()] {
+		return false
+	}
+	for _, dep := range deps {
+		if r.affected[dep.String()] {
+			return false
+		}
+	}
+	return true
+}
+
+// reuseRefinedChange reuses the change planned for this managed resource
+// instance by the saved plan being refined, returning true if it did. The
+// change is reused only if neither the resource nor anything it depends on
+// was affected by changes, and the given object read from the previous run
+// state is the same as when the saved plan was created. Otherwise the
+// resource is marked as affected so that the caller plans it again, along
+// with everything that depends on it.
+//
+// Data sources are always read again, because the remote data they read
+// might have changed, and refineDataSource checks their results instead.
+func (n *NodePlannableResourceInstance) reuseRefinedChange(ctx EvalContext, current *states.ResourceInstanceObject) (bool, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	refinement := ctx.Refinement()
+	if refinement == nil {
+		return false, diags
+	}
+	addr := n.ResourceInstanceAddr()
+	if !refinement.unaffected(addr.ConfigResource(), n.Dependencies) {
+		refinement.markAffected(addr.ConfigResource())
+		return false, diags
+	}
+
+	reused, diags := n.reuseSavedChange(ctx, refinement.plan, current)
+	if !reused {
+		refinement.markAffected(addr.ConfigResource())
+		return false, diags
+	}
+	log.Printf("[TRACE] NodePlannableResourceInstance: reusing the saved change for %s", addr)
+	return true, diags
+}
+
+func (n *NodePlannableResourceInstance) reuseSavedChange(ctx EvalContext, saved *plans.Plan, current *states.ResourceInstanceObject) (bool, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+	addr := n.ResourceInstanceAddr()
+
+	_, providerSchema, err := getProvider(ctx, n.ResolvedProvider)
+	if err != nil {
+		return false, diags.Append(err)
+	}
+	schema, _ := providerSchema.SchemaForResourceAddr(addr.Resource.Resource)
+	if schema == nil {
+		return false, diags
+	}
+	ty := schema.ImpliedType()
+
+	// The object must not have changed since the saved plan was created,
+	// whether by an apply or by another plan upgrading it.
+	var savedPrev *states.ResourceInstanceObject
+	if is := saved.PrevRunState.ResourceInstance(n.prevRunAddr(ctx)); is != nil && is.Current != nil {
+		savedPrev, err = is.Current.Decode(ty)
+		if err != nil {
+			return false, diags
+		}
+	}
+	if !sameObject(savedPrev, current) {
+		return false, diags
+	}
+
+	var savedPrior *states.ResourceInstanceObject
+	if is := saved.PriorState.ResourceInstance(addr); is != nil && is.Current != nil {
+		savedPrior, err = is.Current.Decode(ty)
+		if err != nil {
+			return false, diags
+		}
+	}
+
+	var change *plans.ResourceInstanceChange
+	if cs := saved.Changes.ResourceInstance(addr); cs != nil {
+		if cs.ProviderAddr.String() != n.ResolvedProvider.String() || !cs.PrevRunAddr.Equal(n.prevRunAddr(ctx)) {
+			return false, diags
+		}
+		change, err = cs.Decode(ty)
+		if err != nil {
+			return false, diags
+		}
+	}
+
+	forEach, _ := evaluateForEachExpression(n.Config.ForEach, ctx)
+	repeatData := EvalDataForInstanceKey(addr.Resource.Key, forEach)
+
+	if change == nil {
+		// Every managed resource instance has a change in a plan, even
+		// if it's a no-op.
+		return false, diags
+	}
+
+	diags = diags.Append(n.writeResourceInstanceState(ctx, savedPrior, refreshState))
+	if diags.HasErrors() {
+		return true, diags
+	}
+
+	diags = diags.Append(evalCheckRules(
+		addrs.ResourcePrecondition,
+		n.Config.Preconditions,
+		ctx, addr, repeatData,
+		tfdiags.Error,
+	))
+	if diags.HasErrors() {
+		return true, diags
+	}
+
+	// As when planning, the change must be written before the state
+	// which refers to it.
+	diags = diags.Append(n.writeChange(ctx, change, ""))
+	var planned *states.ResourceInstanceObject
+	if change.Action != plans.Delete {
+		planned = &states.ResourceInstanceObject{
+			Status:  states.ObjectPlanned,
+			Value:   change.After,
+			Private: change.Private,
+		}
+	}
+	diags = diags.Append(n.writeResourceInstanceState(ctx, planned, workingState))
+	if diags.HasErrors() {
+		return true, diags
+	}
+
+	diags = diags.Append(evalCheckRules(
+		addrs.ResourcePostcondition,
+		n.Config.Postconditions,
+		ctx, addr, repeatData,
+		tfdiags.Error,
+	))
+	return true, diags
+}
+
+// refineDataSource marks this data resource as affected by changes, so that
+// the resources that depend on it are planned again, unless it's unaffected
+// and reading it again gave the same result as when the saved plan was
+// created. The given change and state are the result of reading it again.
+func (n *NodePlannableResourceInstance) refineDataSource(ctx EvalContext, change *plans.ResourceInstanceChange, state *states.ResourceInstanceObject) {
+	refinement := ctx.Refinement()
+	if refinement == nil {
+		return
+	}
+	addr := n.ResourceInstanceAddr()
+	if !refinement.unaffected(addr.ConfigResource(), n.Dependencies) || !n.sameDataSourceResult(ctx, refinement.plan, change, state) {
+		log.Printf("[TRACE] NodePlannableResourceInstance: %s has changed since the saved plan", addr)
+		refinement.markAffected(addr.ConfigResource())
+	}
+}
+
+func (n *NodePlannableResourceInstance) sameDataSourceResult(ctx EvalContext, saved *plans.Plan, change *plans.ResourceInstanceChange, state *states.ResourceInstanceObject) bool {
+	addr := n.ResourceInstanceAddr()
+	_, providerSchema, err := getProvider(ctx, n.ResolvedProvider)
+	if err != nil {
+		return false
+	}
+	schema, _ := providerSchema.SchemaForResourceAddr(addr.Resource.Resource)
+	if schema == nil {
+		return false
+	}
+	ty := schema.ImpliedType()
+
+	// A data source that can't be read until apply has a change, and
+	// otherwise only its state.
+	cs := saved.Changes.ResourceInstance(addr)
+	if (cs == nil) != (change == nil) {
+		return false
+	}
+	if change != nil {
+		savedChange, err := cs.Decode(ty)
+		if err != nil || savedChange.Action != change.Action {
+			return false
+		}
+		savedAfter, _ := savedChange.After.UnmarkDeep()
+		after, _ := change.After.UnmarkDeep()
+		return savedAfter.RawEquals(after)
+	}
+
+	var savedPrior *states.ResourceInstanceObject
+	if is := saved.PriorState.ResourceInstance(addr); is != nil && is.Current != nil {
+		savedPrior, err = is.Current.Decode(ty)
+		if err != nil {
+			return false
+		}
+	}
+	return sameObject(savedPrior, state)
+}
+
+// sameObject returns true if the given objects have the same status and
+// value, or are both nil.
+func sameObject(a, b *states.ResourceInstanceObject) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Status != b.Status {
+		return false
+	}
+	av, _ := a.Value.UnmarkDeep()
+	bv, _ := b.Value.UnmarkDeep()
+	return av.RawEquals(bv)
+}
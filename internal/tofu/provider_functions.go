@@ -0,0 +1,279 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/providers"
+)
+
+// providerFunctions makes the functions offered by providers available to
+// expressions during a graph walk.
+//
+// Provider functions don't need a configured provider, so they are called on
+// separate unconfigured instances of each provider, which are started on
+// first use and closed at the end of the walk. A scope that outlives its
+// walk, as in "tofu console", starts new instances as needed, which are then
+// cleaned up along with all other plugin processes when OpenTofu exits.
+type providerFunctions struct {
+	plugins *contextPlugins
+	results *providerFunctionResults
+
+	mu        sync.Mutex
+	instances map[addrs.Provider]providers.Interface
+	modules   map[*configs.Module]moduleProviderFunctions
+}
+
+// moduleProviderFunctions are the provider functions available in a module,
+// and the explanations of why the providers that offer no functions don't,
+// keyed by their local names.
+type moduleProviderFunctions struct {
+	funcs       map[string]function.Function
+	unavailable map[string]string
+}
+
+func newProviderFunctions(plugins *contextPlugins, results *providerFunctionResults) *providerFunctions {
+	return &providerFunctions{
+		plugins:   plugins,
+		results:   results,
+		instances: make(map[addrs.Provider]providers.Interface),
+		modules:   make(map[*configs.Module]moduleProviderFunctions),
+	}
+}
+
+// ForModule returns the provider functions available in the given module,
+// keyed by the names they are called with: "provider::<local name>::<name>".
+//
+// Only providers declared in the module's required_providers block can offer
+// functions, so that the local names are unambiguous.
+func (pf *providerFunctions) ForModule(mod *configs.Module) map[string]function.Function {
+	return pf.forModule(mod).funcs
+}
+
+// UnavailableForModule returns explanations of why the providers declared in
+// the given module's required_providers block that offer no functions don't,
+// keyed by their local names.
+func (pf *providerFunctions) UnavailableForModule(mod *configs.Module) map[string]string {
+	return pf.forModule(mod).unavailable
+}
+
+func (pf *providerFunctions) forModule(mod *configs.Module) moduleProviderFunctions {
+	if pf == nil || mod == nil || mod.ProviderRequirements == nil {
+		return moduleProviderFunctions{}
+	}
+
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+
+	if ret, ok := pf.modules[mod]; ok {
+		return ret
+	}
+
+	ret := moduleProviderFunctions{
+		funcs:       make(map[string]function.Function),
+		unavailable: make(map[string]string),
+	}
+	for localName, req := range mod.ProviderRequirements.RequiredProviders {
+		addr := req.Type
+		if !pf.plugins.HasProvider(addr) {
+			continue
+		}
+
+		// Calls to the functions of a provider that offers none get an
+		// error explaining why, instead of just an unknown function.
+		caps, err := pf.plugins.ProviderCapabilities(addr)
+		if err == nil {
+			if reason := caps.NoFunctionsReason(addr); reason != "" {
+				ret.unavailable[localName] = reason
+				continue
+			}
+		}
+		schema, err := pf.plugins.ProviderSchema(addr)
+		if err != nil {
+			// Failing to load the schema is reported wherever else the
+			// provider is used, so here we just skip its functions.
+			log.Printf("[WARN] Skipping functions of %s: %s", addr, err)
+			continue
+		}
+		for name, decl := range schema.Functions {
+			call := func(req providers.CallFunctionRequest) providers.CallFunctionResponse {
+				return pf.call(addr, req)
+			}
+			ret.funcs[fmt.Sprintf("provider::%s::%s", localName, name)] = decl.BuildFunction(name, call)
+		}
+	}
+	pf.modules[mod] = ret
+	return ret
+}
+
+// call calls the given function on an unconfigured instance of the given
+// provider, or returns the result of an earlier call with the same arguments.
+func (pf *providerFunctions) call(addr addrs.Provider, req providers.CallFunctionRequest) providers.CallFunctionResponse {
+	key, cacheable := providerFunctionCacheKey(addr, req)
+	if cacheable {
+		if result, ok := pf.results.get(addr, key); ok {
+			return providers.CallFunctionResponse{Result: result}
+		}
+	}
+
+	provider, err := pf.instance(addr)
+	if err != nil {
+		return providers.CallFunctionResponse{
+			Error: fmt.Errorf("failed to start provider %s: %w", addr, err),
+		}
+	}
+
+	log.Printf("[TRACE] providerFunctions: calling function %q of %s", req.Name, addr)
+	resp := provider.CallFunction(req)
+	if resp.Error == nil && cacheable {
+		pf.results.put(addr, key, resp.Result)
+	}
+	return resp
+}
+
+func (pf *providerFunctions) instance(addr addrs.Provider) (providers.Interface, error) {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+
+	if provider, ok := pf.instances[addr]; ok {
+		return provider, nil
+	}
+	provider, err := pf.plugins.NewProviderInstance(addr)
+	if err != nil {
+		return nil, err
+	}
+	pf.instances[addr] = provider
+	return provider, nil
+}
+
+// Close closes all of the provider instances started to call functions.
+func (pf *providerFunctions) Close() error {
+	if pf == nil {
+		return nil
+	}
+
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+
+	var err error
+	for addr, provider := range pf.instances {
+		if closeErr := provider.Close(); closeErr != nil {
+			err = multierror.Append(err, fmt.Errorf("failed to close provider %s: %w", addr, closeErr))
+		}
+	}
+	pf.instances = make(map[addrs.Provider]providers.Interface)
+	return err
+}
+
+// providerFunctionResults caches the results of provider function calls for
+// all of the graph walks of a Context.
+//
+// Provider functions must be pure, so a call with the same arguments always
+// has the same result, and each distinct call only needs to reach the
+// provider once across validate, plan and apply.
+//
+// If the context has a ProviderFunctionCache, results are also shared with
+// other runs through it.
+type providerFunctionResults struct {
+	mu         sync.Mutex
+	results    map[string]cty.Value
+	persistent ProviderFunctionCache
+}
+
+func newProviderFunctionResults(persistent ProviderFunctionCache) *providerFunctionResults {
+	return &providerFunctionResults{
+		results:    make(map[string]cty.Value),
+		persistent: persistent,
+	}
+}
+
+func (r *providerFunctionResults) get(addr addrs.Provider, key string) (cty.Value, bool) {
+	if r == nil {
+		return cty.NilVal, false
+	}
+	r.mu.Lock()
+	result, ok := r.results[key]
+	r.mu.Unlock()
+	if ok || r.persistent == nil {
+		return result, ok
+	}
+
+	result, ok = r.persistent.Get(addr, key)
+	if ok {
+		r.mu.Lock()
+		r.results[key] = result
+		r.mu.Unlock()
+	}
+	return result, ok
+}
+
+func (r *providerFunctionResults) put(addr addrs.Provider, key string, result cty.Value) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.results[key] = result
+	r.mu.Unlock()
+	if r.persistent != nil {
+		r.persistent.Put(addr, key, result)
+	}
+}
+
+// save saves the results added to the persistent cache, if any.
+func (r *providerFunctionResults) save() error {
+	if r == nil || r.persistent == nil {
+		return nil
+	}
+	return r.persistent.Save()
+}
+
+// ProviderFunctionCache is a cache of the results of provider function calls
+// that outlives a single Context, such as one stored on disk.
+//
+// Keys are opaque strings that identify a function and its arguments, and are
+// only meaningful together with the provider address. Implementations are
+// responsible for keeping results from different versions of a provider
+// apart, and must be safe for concurrent use.
+type ProviderFunctionCache interface {
+	// Get returns the cached result for the given key, if any.
+	Get(provider addrs.Provider, key string) (cty.Value, bool)
+
+	// Put records the result for the given key.
+	Put(provider addrs.Provider, key string, result cty.Value)
+
+	// Save persists the results recorded by Put. It is called at the end of
+	// each graph walk.
+	Save() error
+}
+
+// providerFunctionCacheKey returns the key to cache the result of the given
+// call with, and false if the result can't be cached because some of the
+// arguments are unknown.
+func providerFunctionCacheKey(addr addrs.Provider, req providers.CallFunctionRequest) (string, bool) {
+	args := cty.TupleVal(req.Arguments)
+	if len(req.Arguments) == 0 {
+		args = cty.EmptyTupleVal
+	}
+	if !args.IsWhollyKnown() {
+		return "", false
+	}
+	// Encoding with the dynamic type includes the types of the arguments in
+	// the key, so that e.g. a string and a number with the same JSON
+	// representation aren't confused.
+	argsJSON, err := ctyjson.Marshal(args, cty.DynamicPseudoType)
+	if err != nil {
+		return "", false
+	}
+	return fmt.Sprintf("%s %s %s", addr, req.Name, argsJSON), true
+}
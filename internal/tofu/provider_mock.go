@@ -85,6 +85,16 @@ type MockProvider struct {
 	ReadDataSourceRequest  providers.ReadDataSourceRequest
 	ReadDataSourceFn       func(providers.ReadDataSourceRequest) providers.ReadDataSourceResponse
 
+	CallFunctionCalled   bool
+	CallFunctionResponse *providers.CallFunctionResponse
+	CallFunctionRequest  providers.CallFunctionRequest
+	CallFunctionFn       func(providers.CallFunctionRequest) providers.CallFunctionResponse
+
+	ListResourcesCalled   bool
+	ListResourcesResponse *providers.ListResourcesResponse
+	ListResourcesRequest  providers.ListResourcesRequest
+	ListResourcesFn       func(providers.ListResourcesRequest) providers.ListResourcesResponse
+
 	CloseCalled bool
 	CloseError  error
 }
@@ -510,6 +520,47 @@ func (p *MockProvider) ReadDataSource(r providers.ReadDataSourceRequest) (resp p
 	return resp
 }
 
+func (p *MockProvider) CallFunction(r providers.CallFunctionRequest) (resp providers.CallFunctionResponse) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.CallFunctionCalled = true
+	p.CallFunctionRequest = r
+
+	if p.CallFunctionFn != nil {
+		return p.CallFunctionFn(r)
+	}
+
+	if p.CallFunctionResponse != nil {
+		resp = *p.CallFunctionResponse
+	}
+
+	return resp
+}
+
+func (p *MockProvider) ListResources(r providers.ListResourcesRequest) (resp providers.ListResourcesResponse) {
+	p.Lock()
+	defer p.Unlock()
+
+	if !p.ConfigureProviderCalled {
+		resp.Diagnostics = resp.Diagnostics.Append(fmt.Errorf("Configure not called before ListResources %q", r.TypeName))
+		return resp
+	}
+
+	p.ListResourcesCalled = true
+	p.ListResourcesRequest = r
+
+	if p.ListResourcesFn != nil {
+		return p.ListResourcesFn(r)
+	}
+
+	if p.ListResourcesResponse != nil {
+		resp = *p.ListResourcesResponse
+	}
+
+	return resp
+}
+
 func (p *MockProvider) Close() error {
 	p.Lock()
 	defer p.Unlock()
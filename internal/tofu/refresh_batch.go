@@ -0,0 +1,302 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"log"
+	"sync"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// DefaultRefreshParallelism is the number of concurrent reads of remote
+// objects OpenTofu makes for each provider configuration while refreshing,
+// unless a RefreshParallelism says otherwise.
+const DefaultRefreshParallelism = 10
+
+// RefreshParallelism describes limits on the number of concurrent reads of
+// remote objects OpenTofu makes for each provider configuration while
+// refreshing during planning.
+//
+// These limits are separate from the overall parallelism of a Context,
+// because the reads are made ahead of the graph walk that plans the changes
+// for each object.
+type RefreshParallelism struct {
+	// Default is the limit for provider configurations of providers that
+	// don't appear in Providers. If it is zero, DefaultRefreshParallelism
+	// is used.
+	Default int
+
+	// Providers overrides the limit for the configurations of particular
+	// providers.
+	Providers map[addrs.Provider]int
+}
+
+// ForProvider returns the limit on concurrent reads for the configurations
+// of the given provider.
+func (p RefreshParallelism) ForProvider(provider addrs.Provider) int {
+	if limit, ok := p.Providers[provider]; ok {
+		return limit
+	}
+	if p.Default > 0 {
+		return p.Default
+	}
+	return DefaultRefreshParallelism
+}
+
+// refreshBatch reads the current remote objects for the managed resource
+// instances in the prior state ahead of the graph nodes that plan their
+// changes.
+//
+// The resource instance nodes would otherwise each refresh their object only
+// once all of their dependencies have been planned, which serializes the
+// reads along each chain of dependencies even though reading an object
+// depends only on its prior state and on the configuration of its provider.
+// A refreshBatch instead starts reading all of the objects belonging to a
+// provider configuration as soon as that provider has been configured,
+// making up to the RefreshParallelism limit of reads at once.
+//
+// The methods of a nil *refreshBatch behave as if there are no objects to
+// read, so that the nodes refresh their objects themselves.
+type refreshBatch struct {
+	config      *configs.Config
+	parallelism RefreshParallelism
+
+	mu        sync.Mutex
+	items     map[string][]*refreshBatchItem
+	instances addrs.Map[addrs.AbsResourceInstance, *refreshBatchItem]
+	running   map[string]*refreshBatchRun
+}
+
+// refreshBatchItem is the read of a single object in a refreshBatch. The
+// read happens only once, either in one of the batch's workers or in the
+// node that needs its result, whichever gets to it first.
+type refreshBatchItem struct {
+	addr     addrs.AbsResourceInstance
+	provider addrs.AbsProviderConfig
+
+	once  sync.Once
+	obj   *states.ResourceInstanceObject
+	diags tfdiags.Diagnostics
+}
+
+// refreshBatchRun tracks the workers reading the objects for one provider
+// configuration.
+type refreshBatchRun struct {
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newRefreshBatch prepares a batch to read the current objects of all of the
+// managed resource instances in the given state that are selected by the
+// given targets, or all of them if there are no targets, except for those
+// selected by the given excludes.
+func newRefreshBatch(config *configs.Config, state *states.State, targets, excludes []addrs.Targetable, parallelism RefreshParallelism) *refreshBatch {
+	b := &refreshBatch{
+		config:      config,
+		parallelism: parallelism,
+		items:       make(map[string][]*refreshBatchItem),
+		instances:   addrs.MakeMap[addrs.AbsResourceInstance, *refreshBatchItem](),
+		running:     make(map[string]*refreshBatchRun),
+	}
+
+	for _, ms := range state.Modules {
+		for _, rs := range ms.Resources {
+			if rs.Addr.Resource.Mode != addrs.ManagedResourceMode {
+				continue
+			}
+			for key, is := range rs.Instances {
+				if is.Current == nil {
+					continue
+				}
+				addr := rs.Addr.Instance(key)
+				if !refreshBatchTargeted(addr, targets) || refreshBatchExcluded(addr, excludes) {
+					continue
+				}
+				item := &refreshBatchItem{
+					addr:     addr,
+					provider: rs.ProviderConfig,
+				}
+				providerKey := rs.ProviderConfig.String()
+				b.items[providerKey] = append(b.items[providerKey], item)
+				b.instances.Put(addr, item)
+			}
+		}
+	}
+	return b
+}
+
+func refreshBatchTargeted(addr addrs.AbsResourceInstance, targets []addrs.Targetable) bool {
+	if len(targets) == 0 {
+		return true
+	}
+	for _, target := range targets {
+		if target.TargetContains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func refreshBatchExcluded(addr addrs.AbsResourceInstance, excludes []addrs.Targetable) bool {
+	for _, exclude := range excludes {
+		if exclude.TargetContains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Start begins reading the objects belonging to the given provider
+// configuration, which must already be configured, in the background.
+//
+// The given context is used only to derive the contexts for the modules
+// the objects belong to.
+func (b *refreshBatch) Start(ctx EvalContext, provider addrs.AbsProviderConfig) {
+	if b == nil {
+		return
+	}
+	key := provider.String()
+
+	b.mu.Lock()
+	items := b.items[key]
+	delete(b.items, key)
+	if len(items) == 0 {
+		b.mu.Unlock()
+		return
+	}
+	run := &refreshBatchRun{stop: make(chan struct{})}
+	b.running[key] = run
+	b.mu.Unlock()
+
+	instAddrs := make([]addrs.AbsResourceInstance, len(items))
+	for i, item := range items {
+		instAddrs[i] = item.addr
+	}
+	// The batch can't be halted, so we ignore the hook results here and
+	// leave it to the nodes to notice when the operation is stopped.
+	_ = ctx.Hook(func(h Hook) (HookAction, error) {
+		return h.PreRefreshBatch(provider, instAddrs)
+	})
+
+	limit := b.parallelism.ForProvider(provider.Provider)
+	if limit > len(items) {
+		limit = len(items)
+	}
+	log.Printf("[TRACE] refreshBatch: reading %d objects for %s with up to %d concurrent reads", len(items), provider, limit)
+
+	queue := make(chan *refreshBatchItem, len(items))
+	for _, item := range items {
+		queue <- item
+	}
+	close(queue)
+
+	run.wg.Add(limit)
+	for i := 0; i < limit; i++ {
+		go func() {
+			defer run.wg.Done()
+			for item := range queue {
+				select {
+				case <-run.stop:
+					return
+				default:
+				}
+				b.read(ctx, item)
+			}
+		}()
+	}
+	go func() {
+		run.wg.Wait()
+		_ = ctx.Hook(func(h Hook) (HookAction, error) {
+			return h.PostRefreshBatch(provider, instAddrs)
+		})
+	}()
+}
+
+// Stop prevents any more reads for the given provider configuration and
+// waits for any reads already in progress, so that the provider can be
+// closed.
+func (b *refreshBatch) Stop(provider addrs.AbsProviderConfig) {
+	if b == nil {
+		return
+	}
+	key := provider.String()
+
+	b.mu.Lock()
+	run := b.running[key]
+	delete(b.running, key)
+	b.mu.Unlock()
+
+	if run != nil {
+		close(run.stop)
+		run.wg.Wait()
+	}
+}
+
+// Close stops all of the reads for all provider configurations and waits for
+// any reads already in progress.
+func (b *refreshBatch) Close() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	running := b.running
+	b.running = make(map[string]*refreshBatchRun)
+	b.mu.Unlock()
+
+	for _, run := range running {
+		close(run.stop)
+		run.wg.Wait()
+	}
+}
+
+// Result returns the refreshed object for the given resource instance if it
+// belongs to this batch and was read using the given provider
+// configuration, reading it first if necessary. The final result is false
+// if the caller must refresh the object itself.
+func (b *refreshBatch) Result(ctx EvalContext, addr addrs.AbsResourceInstance, provider addrs.AbsProviderConfig) (*states.ResourceInstanceObject, tfdiags.Diagnostics, bool) {
+	if b == nil {
+		return nil, nil, false
+	}
+	item, ok := b.instances.GetOk(addr)
+	if !ok || item.provider.String() != provider.String() {
+		return nil, nil, false
+	}
+	b.read(ctx, item)
+	return item.obj.DeepCopy(), item.diags, true
+}
+
+// read reads the current object for the given item, unless it has already
+// been read.
+func (b *refreshBatch) read(ctx EvalContext, item *refreshBatchItem) {
+	item.once.Do(func() {
+		log.Printf("[TRACE] refreshBatch: reading %s", item.addr)
+		ctx = ctx.WithPath(item.addr.Module)
+
+		n := &NodeAbstractResourceInstance{
+			NodeAbstractResource: NodeAbstractResource{
+				Addr:             item.addr.ConfigResource(),
+				ResolvedProvider: item.provider,
+			},
+			Addr: item.addr,
+		}
+		if mc := b.config.DescendentForInstance(item.addr.Module); mc != nil {
+			n.Config = mc.Module.ResourceByAddr(item.addr.Resource.Resource)
+			n.ProviderMetas = mc.Module.ProviderMetas
+		}
+
+		obj, diags := n.readResourceInstanceState(ctx, item.addr)
+		if !diags.HasErrors() {
+			var refreshDiags tfdiags.Diagnostics
+			obj, refreshDiags = n.refresh(ctx, states.NotDeposed, obj)
+			diags = diags.Append(refreshDiags)
+		}
+		item.obj = obj
+		item.diags = diags
+	})
+}
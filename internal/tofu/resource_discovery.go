@@ -0,0 +1,273 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/configs/configschema"
+	"github.com/opentofu/opentofu/internal/genconfig"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+// resourceDiscovery collects the remote objects that providers list for the
+// managed resource types in the root module of the configuration, so that
+// OpenTofu can generate import blocks for the ones it doesn't manage yet.
+//
+// Each resource type is listed once per provider configuration, however
+// many resources of that type the configuration declares.
+type resourceDiscovery struct {
+	mu          sync.Mutex
+	types       map[string]*discoveredType
+	unsupported map[string]addrs.AbsProviderConfig
+}
+
+// discoveredType is the result of listing the objects of one resource type
+// using one provider configuration.
+type discoveredType struct {
+	typeName      string
+	provider      addrs.AbsProviderConfig
+	localProvider addrs.LocalProviderConfig
+	schema        *configschema.Block
+	objects       []providers.ListedResource
+}
+
+func newResourceDiscovery() *resourceDiscovery {
+	return &resourceDiscovery{
+		types:       make(map[string]*discoveredType),
+		unsupported: make(map[string]addrs.AbsProviderConfig),
+	}
+}
+
+// list lists the remote objects of the type of the given resource, unless
+// they were already listed for another resource of the same type that uses
+// the same provider configuration.
+func (d *resourceDiscovery) list(ctx EvalContext, n *nodeExpandPlannableResource) tfdiags.Diagnostics {
+	var diags tfdiags.Diagnostics
+
+	addr := n.Addr
+	if addr.Resource.Mode != addrs.ManagedResourceMode || !addr.Module.IsRoot() || n.Config == nil || n.Schema == nil {
+		// Configuration can only be generated in the root module.
+		return diags
+	}
+
+	key := n.ResolvedProvider.String() + " " + addr.Resource.Type
+	d.mu.Lock()
+	if _, exists := d.types[key]; exists {
+		d.mu.Unlock()
+		return diags
+	}
+	dt := &discoveredType{
+		typeName:      addr.Resource.Type,
+		provider:      n.ResolvedProvider,
+		localProvider: n.Config.ProviderConfigAddr(),
+		schema:        n.Schema,
+	}
+	d.types[key] = dt
+	d.mu.Unlock()
+
+	provider, schema, err := getProvider(ctx, n.ResolvedProvider)
+	if err != nil {
+		return diags.Append(err)
+	}
+	if !schema.ServerCapabilities.ListResources {
+		d.mu.Lock()
+		d.unsupported[n.ResolvedProvider.String()] = n.ResolvedProvider
+		d.mu.Unlock()
+		return diags
+	}
+
+	resp := provider.ListResources(providers.ListResourcesRequest{
+		TypeName: addr.Resource.Type,
+	})
+	diags = diags.Append(resp.Diagnostics.InConfigBody(n.Config.Config, addr.String()))
+	if resp.Diagnostics.HasErrors() {
+		return diags
+	}
+
+	d.mu.Lock()
+	dt.objects = resp.Resources
+	d.mu.Unlock()
+	return diags
+}
+
+// resources returns the listed objects which are neither in the given state
+// nor being imported by the given changes, each with a draft import block
+// and resource configuration to adopt it.
+//
+// This must be called only after the graph walk that listed the objects
+// has completed.
+func (d *resourceDiscovery) resources(config *configs.Config, state *states.State, changes *plans.Changes) ([]*plans.DiscoveredResource, tfdiags.Diagnostics) {
+	var diags tfdiags.Diagnostics
+
+	keys := make([]string, 0, len(d.types))
+	for key := range d.types {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// The names of the generated resources must not collide with the names
+	// of any resources declared in the root module or tracked in the state.
+	taken := make(map[string]bool)
+	for key := range config.Module.ManagedResources {
+		taken[key] = true
+	}
+	if ms := state.Module(addrs.RootModuleInstance); ms != nil {
+		for key := range ms.Resources {
+			taken[key] = true
+		}
+	}
+
+	var ret []*plans.DiscoveredResource
+	for _, key := range keys {
+		dt := d.types[key]
+		managed := managedObjectIDs(dt, state, changes)
+
+		objects := make([]providers.ListedResource, len(dt.objects))
+		copy(objects, dt.objects)
+		sort.Slice(objects, func(i, j int) bool {
+			return objects[i].ID < objects[j].ID
+		})
+
+		for _, obj := range objects {
+			if obj.ID == "" || managed[obj.ID] {
+				continue
+			}
+
+			name := discoveredResourceName(obj.ID)
+			for i := 2; taken[dt.typeName+"."+name]; i++ {
+				name = fmt.Sprintf("%s_%d", discoveredResourceName(obj.ID), i)
+			}
+			taken[dt.typeName+"."+name] = true
+
+			addr := addrs.Resource{
+				Mode: addrs.ManagedResourceMode,
+				Type: dt.typeName,
+				Name: name,
+			}.Instance(addrs.NoKey).Absolute(addrs.RootModuleInstance)
+
+			val := obj.State
+			if val != cty.NilVal {
+				if errs := val.Type().TestConformance(dt.schema.ImpliedType()); len(errs) > 0 {
+					diags = diags.Append(tfdiags.Sourceless(
+						tfdiags.Warning,
+						"Provider listed an invalid object",
+						fmt.Sprintf(
+							"The provider %s listed an object of type %s with ID %q that doesn't conform to the schema of its type, so the configuration generated for %s only has placeholders for its arguments.\n\nThis is a bug in the provider, which should be reported in the provider's own issue tracker.",
+							dt.provider.Provider, dt.typeName, obj.ID, addr,
+						),
+					))
+					val = cty.NilVal
+				}
+			}
+
+			contents, genDiags := genconfig.GenerateResourceContents(addr, dt.schema, dt.localProvider, val)
+			diags = diags.Append(genDiags)
+			if genDiags.HasErrors() {
+				continue
+			}
+
+			ret = append(ret, &plans.DiscoveredResource{
+				Addr:            addr,
+				ProviderAddr:    dt.provider,
+				ImportID:        obj.ID,
+				GeneratedConfig: genconfig.GenerateImportBlock(addr, obj.ID) + "\n\n" + genconfig.WrapResourceContents(addr, contents),
+			})
+		}
+	}
+
+	unsupported := make([]string, 0, len(d.unsupported))
+	for key := range d.unsupported {
+		unsupported = append(unsupported, key)
+	}
+	sort.Strings(unsupported)
+	for _, key := range unsupported {
+		diags = diags.Append(tfdiags.Sourceless(
+			tfdiags.Warning,
+			"Provider cannot list existing objects",
+			fmt.Sprintf(
+				"The provider configuration %s doesn't support listing existing remote objects, so OpenTofu cannot find the objects of its resource types that it doesn't manage yet. Providers can list objects only if they are built with plugin protocol version 5.5 or 6.5 or later and declare the list_resources server capability.",
+				d.unsupported[key],
+			),
+		))
+	}
+
+	return ret, diags
+}
+
+// managedObjectIDs returns the IDs of the objects of the given type which
+// OpenTofu already manages, or which the plan imports, using the same
+// provider configuration. It assumes that the "id" attribute of an object
+// is the ID that imports it, which is true for most providers.
+func managedObjectIDs(dt *discoveredType, state *states.State, changes *plans.Changes) map[string]bool {
+	ret := make(map[string]bool)
+	for _, ms := range state.Modules {
+		for _, rs := range ms.Resources {
+			if rs.Addr.Resource.Mode != addrs.ManagedResourceMode || rs.Addr.Resource.Type != dt.typeName || rs.ProviderConfig.String() != dt.provider.String() {
+				continue
+			}
+			for _, is := range rs.Instances {
+				if is.Current != nil {
+					if id := objectID(is.Current); id != "" {
+						ret[id] = true
+					}
+				}
+				for _, obj := range is.Deposed {
+					if id := objectID(obj); id != "" {
+						ret[id] = true
+					}
+				}
+			}
+		}
+	}
+	for _, rc := range changes.Resources {
+		if rc.Importing == nil || rc.Addr.Resource.Resource.Type != dt.typeName || rc.ProviderAddr.String() != dt.provider.String() {
+			continue
+		}
+		ret[rc.Importing.ID] = true
+	}
+	return ret
+}
+
+// objectID returns the value of the "id" attribute of the given object, or
+// an empty string if it doesn't have one.
+func objectID(obj *states.ResourceInstanceObjectSrc) string {
+	var attrs struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(obj.AttrsJSON, &attrs); err != nil {
+		return ""
+	}
+	return attrs.ID
+}
+
+// discoveredResourceName returns a resource name based on the given import
+// ID, which is valid in the configuration language.
+func discoveredResourceName(id string) string {
+	var buf strings.Builder
+	for _, r := range strings.ToLower(id) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-':
+			buf.WriteRune(r)
+		default:
+			buf.WriteRune('_')
+		}
+	}
+	name := strings.Trim(buf.String(), "_-")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "discovered_" + name
+	}
+	return strings.TrimSuffix(name, "_")
+}
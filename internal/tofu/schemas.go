@@ -165,6 +165,14 @@ func loadProvisionerSchemas(schemas map[string]*configschema.Block, config *conf
 				ensure(pc.Type)
 			}
 		}
+		for _, rc := range config.Module.Removed {
+			if rc.Managed == nil {
+				continue
+			}
+			for _, pc := range rc.Managed.Provisioners {
+				ensure(pc.Type)
+			}
+		}
 
 		// Must also visit our child modules, recursively.
 		for _, cc := range config.Children {
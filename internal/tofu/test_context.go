@@ -93,6 +93,9 @@ func (ctx *TestContext) evaluate(state *states.SyncState, changes *plans.Changes
 		PureOnly:      operation != walkApply,
 		PlanTimestamp: ctx.Plan.Timestamp,
 	}
+	if ctx.Config != nil {
+		scope.SetUserFunctions(ctx.Config.Module.UserFunctions())
+	}
 
 	// We're going to assume the run has passed, and then if anything fails this
 	// value will be updated.
@@ -6,8 +6,10 @@ package tofu
 import (
 	"log"
 
+	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/dag"
+	"github.com/opentofu/opentofu/internal/refactoring"
 )
 
 // GraphNodeAttachResourceConfig is an interface that must be implemented by nodes
@@ -19,6 +21,16 @@ type GraphNodeAttachResourceConfig interface {
 	AttachResourceConfig(*configs.Resource)
 }
 
+// GraphNodeAttachRemovedConfig is an interface that can be implemented by
+// nodes that want the "removed" block for their resource attached when the
+// resource is no longer in the configuration.
+type GraphNodeAttachRemovedConfig interface {
+	GraphNodeConfigResource
+
+	// Sets the "removed" block configuration
+	AttachRemovedConfig(*configs.Removed)
+}
+
 // AttachResourceConfigTransformer goes through the graph and attaches
 // resource configuration structures to nodes that implement
 // GraphNodeAttachManagedResourceConfig or GraphNodeAttachDataResourceConfig.
@@ -30,6 +42,9 @@ type AttachResourceConfigTransformer struct {
 }
 
 func (t *AttachResourceConfigTransformer) Transform(g *Graph) error {
+	// Any errors in the removed blocks are reported during validation and
+	// planning, so we can ignore them here.
+	removeStmts, _ := refactoring.FindRemoveStatements(t.Config)
 
 	// Go through and find GraphNodeAttachResource
 	for _, v := range g.Vertices() {
@@ -44,6 +59,9 @@ func (t *AttachResourceConfigTransformer) Transform(g *Graph) error {
 
 		// Get the configuration.
 		config := t.Config.Descendent(addr.Module)
+		if config == nil || config.Module.ResourceByAddr(addr.Resource) == nil {
+			t.attachRemovedConfig(v, addr, removeStmts)
+		}
 		if config == nil {
 			log.Printf("[TRACE] AttachResourceConfigTransformer: %q (%T) has no configuration available", dag.VertexName(v), v)
 			continue
@@ -111,3 +129,20 @@ func (t *AttachResourceConfigTransformer) Transform(g *Graph) error {
 
 	return nil
 }
+
+// attachRemovedConfig attaches the "removed" block for the given resource, if
+// any, to a node whose resource is no longer in the configuration.
+func (t *AttachResourceConfigTransformer) attachRemovedConfig(v dag.Vertex, addr addrs.ConfigResource, stmts []refactoring.RemoveStatement) {
+	arn, ok := v.(GraphNodeAttachRemovedConfig)
+	if !ok {
+		return
+	}
+
+	for _, stmt := range stmts {
+		if from, ok := stmt.From.(addrs.ConfigResource); ok && from.Equal(addr) {
+			log.Printf("[TRACE] AttachResourceConfigTransformer: attaching to %q (%T) removed block from %s", dag.VertexName(v), v, stmt.DeclRange.ToHCL())
+			arn.AttachRemovedConfig(stmt.Config)
+			return
+		}
+	}
+}
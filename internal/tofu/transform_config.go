@@ -171,6 +171,10 @@ func (t *ConfigTransformer) transformSingle(g *Graph, config *configs.Config, ge
 	// TODO: We could actually catch and process these kind of problems earlier,
 	//   this is something that could be done during the Validate process.
 	for _, i := range importTargets {
+		if i.Config != nil && i.Config.ForEach != nil {
+			return fmt.Errorf("Config generation for import blocks with for_each not supported.\n\nYour configuration contains an import block with for_each and a \"to\" address of %s. This resource does not exist in configuration.\n\nIf you intended to target a resource that exists in configuration, please double-check the address. Otherwise, please remove this import block or re-run the plan without the -generate-config-out flag to ignore the import block.", i.Addr.ContainingResource())
+		}
+
 		// The case in which an unmatched import block targets an expanded
 		// resource instance can error here. Others can error later.
 		if i.Addr.Resource.Key != addrs.NoKey {
@@ -0,0 +1,79 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"log"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/configs"
+	"github.com/opentofu/opentofu/internal/dag"
+)
+
+// DestroyAfterTransformer is a GraphTransformer that orders the destroyers
+// of the resources that have a destroy_after lifecycle argument after the
+// destroyers of the objects it refers to.
+//
+// DestroyEdgeTransformer already destroys dependents before their
+// dependencies, but it can't see relationships that aren't expressed as
+// references, such as a network interface that a provider detaches
+// asynchronously from an instance that doesn't refer to it.
+type DestroyAfterTransformer struct{}
+
+func (t *DestroyAfterTransformer) Transform(g *Graph) error {
+	var destroyers []GraphNodeDestroyer
+	for _, v := range g.Vertices() {
+		if d, ok := v.(GraphNodeDestroyer); ok && d.DestroyAddr() != nil {
+			destroyers = append(destroyers, d)
+		}
+	}
+
+	for _, d := range destroyers {
+		cn, ok := d.(interface {
+			managedConfig() *configs.ManagedResource
+		})
+		if !ok {
+			continue
+		}
+		managed := cn.managedConfig()
+		if managed == nil || len(managed.DestroyAfter) == 0 {
+			continue
+		}
+
+		addr := *d.DestroyAddr()
+		for _, ref := range managed.DestroyAfter {
+			target := destroyAfterTarget(addr.Module, ref)
+			if target == nil {
+				continue
+			}
+			for _, other := range destroyers {
+				if other == d || !target.TargetContains(*other.DestroyAddr()) {
+					continue
+				}
+				log.Printf("[TRACE] DestroyAfterTransformer: %s must be destroyed after %s", dag.VertexName(d), dag.VertexName(other))
+				g.Connect(dag.BasicEdge(d, other))
+			}
+		}
+	}
+
+	return nil
+}
+
+// destroyAfterTarget returns the objects that a destroy_after reference in
+// the given module instance refers to.
+func destroyAfterTarget(module addrs.ModuleInstance, ref *addrs.Reference) addrs.Targetable {
+	switch subject := ref.Subject.(type) {
+	case addrs.Resource:
+		return subject.Absolute(module)
+	case addrs.ResourceInstance:
+		return subject.Absolute(module)
+	case addrs.ModuleCall:
+		return module.Child(subject.Name, addrs.NoKey)
+	case addrs.ModuleCallInstance:
+		return module.Child(subject.Call.Name, subject.Key)
+	default:
+		// Config decoding only allows the types above.
+		return nil
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/dag"
 	"github.com/opentofu/opentofu/internal/states"
@@ -151,3 +152,54 @@ func (t *CBDEdgeTransformer) Transform(g *Graph) error {
 	}
 	return nil
 }
+
+// CBDGroupTransformer makes the destroyers of the objects being replaced in
+// each create_before_destroy group depend on the creators of all of the
+// group's replacement objects in the same module instance, so that no old
+// object is destroyed until every new object has been created and has passed
+// its postconditions.
+//
+// This must run after CBDEdgeTransformer, which already orders each
+// destroyer after its own creator.
+type CBDGroupTransformer struct{}
+
+func (t *CBDGroupTransformer) Transform(g *Graph) error {
+	type managedConfigNode interface {
+		managedConfig() *configs.ManagedResource
+	}
+	groupKey := func(v dag.Vertex, addr *addrs.AbsResourceInstance) string {
+		n, ok := v.(managedConfigNode)
+		if !ok || addr == nil {
+			return ""
+		}
+		managed := n.managedConfig()
+		if managed == nil || managed.CreateBeforeDestroyGroup == "" {
+			return ""
+		}
+		return addr.Module.String() + "\x00" + managed.CreateBeforeDestroyGroup
+	}
+
+	creators := make(map[string][]dag.Vertex)
+	for _, v := range g.Vertices() {
+		if cn, ok := v.(GraphNodeCreator); ok {
+			if key := groupKey(v, cn.CreateAddr()); key != "" {
+				creators[key] = append(creators[key], v)
+			}
+		}
+	}
+
+	for _, v := range g.Vertices() {
+		dn, ok := v.(GraphNodeDestroyer)
+		if !ok {
+			continue
+		}
+		if cbd, ok := v.(GraphNodeDestroyerCBD); !ok || !cbd.CreateBeforeDestroy() {
+			continue
+		}
+		for _, c := range creators[groupKey(v, dn.DestroyAddr())] {
+			log.Printf("[TRACE] CBDGroupTransformer: %s must wait for %s", dag.VertexName(v), dag.VertexName(c))
+			g.Connect(dag.BasicEdge(v, c))
+		}
+	}
+	return nil
+}
@@ -6,7 +6,6 @@ package tofu
 import (
 	"log"
 
-	"github.com/opentofu/opentofu/internal/addrs"
 	"github.com/opentofu/opentofu/internal/configs"
 	"github.com/opentofu/opentofu/internal/dag"
 )
@@ -27,10 +26,38 @@ type ModuleExpansionTransformer struct {
 	Concrete ConcreteModuleNodeFunc
 
 	closers map[string]*nodeCloseModule
+
+	// modules are the nodes already in the graph that execute within the
+	// scope of each module, other than destroyers, by module path.
+	modules map[string][]dag.Vertex
 }
 
 func (t *ModuleExpansionTransformer) Transform(g *Graph) error {
 	t.closers = make(map[string]*nodeCloseModule)
+
+	// Index the nodes by module first, so that connecting each module's
+	// expansion node to its nodes doesn't need to check every node in the
+	// graph for every module. Finding the module paths is independent for
+	// each node, so we do that in parallel.
+	vs := g.Vertices()
+	paths := make([]string, len(vs))
+	dag.ParallelEach(len(vs), func(i int) {
+		switch v := vs[i].(type) {
+		case GraphNodeDestroyer:
+			// skip destroyers, as they can only depend on other resources.
+		case GraphNodeModulePath:
+			if path := v.ModulePath(); !path.IsRoot() {
+				paths[i] = path.String()
+			}
+		}
+	})
+	t.modules = make(map[string][]dag.Vertex)
+	for i, v := range vs {
+		if paths[i] != "" {
+			t.modules[paths[i]] = append(t.modules[paths[i]], v)
+		}
+	}
+
 	// The root module is always a singleton and so does not need expansion
 	// processing, but any descendent modules do. We'll process them
 	// recursively using t.transform.
@@ -114,28 +141,9 @@ func (t *ModuleExpansionTransformer) transform(g *Graph, c *configs.Config, pare
 	g.Connect(dag.BasicEdge(closer, expander))
 	t.closers[c.Path.String()] = closer
 
-	for _, childV := range g.Vertices() {
-		// don't connect a node to itself
-		if childV == expander {
-			continue
-		}
-
-		var path addrs.Module
-		switch t := childV.(type) {
-		case GraphNodeDestroyer:
-			// skip destroyers, as they can only depend on other resources.
-			continue
-
-		case GraphNodeModulePath:
-			path = t.ModulePath()
-		default:
-			continue
-		}
-
-		if path.Equal(c.Path) {
-			log.Printf("[TRACE] ModuleExpansionTransformer: %s must wait for expansion of %s", dag.VertexName(childV), c.Path)
-			g.Connect(dag.BasicEdge(childV, expander))
-		}
+	for _, childV := range t.modules[c.Path.String()] {
+		log.Printf("[TRACE] ModuleExpansionTransformer: %s must wait for expansion of %s", dag.VertexName(childV), c.Path)
+		g.Connect(dag.BasicEdge(childV, expander))
 	}
 
 	// Also visit child modules, recursively.
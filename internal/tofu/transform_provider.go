@@ -26,6 +26,11 @@ func transformProviders(concrete ConcreteProviderNodeFunc, config *configs.Confi
 			Config:   config,
 			Concrete: concrete,
 		},
+		// Connect providers to the providers they inherit arguments from
+		&DerivedProviderTransformer{
+			Config:   config,
+			Concrete: concrete,
+		},
 		// Connect the providers
 		&ProviderTransformer{
 			Config: config,
@@ -360,27 +365,123 @@ func (t *MissingProviderTransformer) Transform(g *Graph) error {
 type PruneProviderTransformer struct{}
 
 func (t *PruneProviderTransformer) Transform(g *Graph) error {
+	// Removing an unused provider that inherits the arguments of another
+	// can leave the other unused too, so we repeat until nothing changes.
+	for pruned := true; pruned; {
+		pruned = false
+		for _, v := range g.Vertices() {
+			// We only care about providers
+			_, ok := v.(GraphNodeProvider)
+			if !ok {
+				continue
+			}
+
+			// ProxyProviders will have up edges, but we're now done with them in the graph
+			if _, ok := v.(*graphNodeProxyProvider); ok {
+				log.Printf("[DEBUG] pruning proxy %s", dag.VertexName(v))
+				g.Remove(v)
+				pruned = true
+				continue
+			}
+
+			// Remove providers with no dependencies.
+			if g.UpEdges(v).Len() == 0 {
+				log.Printf("[DEBUG] pruning unused %s", dag.VertexName(v))
+				g.Remove(v)
+				pruned = true
+			}
+		}
+	}
+
+	return nil
+}
+
+// graphNodeDerivedProvider is implemented by provider nodes whose
+// configuration can inherit the arguments of another provider configuration.
+type graphNodeDerivedProvider interface {
+	GraphNodeProvider
+	ProviderConfig() *configs.Provider
+	SetInheritedProvider(addrs.AbsProviderConfig)
+}
+
+// DerivedProviderTransformer connects each provider configuration that uses
+// the "inherit" argument to the provider configuration it inherits from, so
+// that the inherited configuration is configured first.
+//
+// The inherited configuration may be passed in from the parent module, so
+// this must run before ProviderTransformer removes the proxy nodes.
+type DerivedProviderTransformer struct {
+	Config *configs.Config
+
+	// Concrete, if set, overrides how the providers are made.
+	Concrete ConcreteProviderNodeFunc
+}
+
+func (t *DerivedProviderTransformer) Transform(g *Graph) error {
+	if t.Config == nil {
+		return nil
+	}
+	if t.Concrete == nil {
+		t.Concrete = func(a *NodeAbstractProvider) dag.Vertex {
+			return a
+		}
+	}
+
+	var diags tfdiags.Diagnostics
+	m := providerVertexMap(g)
 	for _, v := range g.Vertices() {
-		// We only care about providers
-		_, ok := v.(GraphNodeProvider)
+		pv, ok := v.(graphNodeDerivedProvider)
 		if !ok {
 			continue
 		}
-
-		// ProxyProviders will have up edges, but we're now done with them in the graph
-		if _, ok := v.(*graphNodeProxyProvider); ok {
-			log.Printf("[DEBUG] pruning proxy %s", dag.VertexName(v))
-			g.Remove(v)
+		config := pv.ProviderConfig()
+		if config == nil || config.Inherit == nil {
+			continue
+		}
+		addr := pv.ProviderAddr()
+		mc := t.Config.Descendent(addr.Module)
+		if mc == nil {
+			continue
 		}
 
-		// Remove providers with no dependencies.
-		if g.UpEdges(v).Len() == 0 {
-			log.Printf("[DEBUG] pruning unused %s", dag.VertexName(v))
-			g.Remove(v)
+		baseAddr := addrs.AbsProviderConfig{
+			Provider: mc.Module.ProviderForLocalConfig(config.Inherit.Addr()),
+			Module:   addr.Module,
+			Alias:    config.Inherit.Alias,
+		}
+		base := m[baseAddr.String()]
+		for pp, ok := baseAddr.Inherited(); base == nil && ok; pp, ok = pp.Inherited() {
+			base = m[pp.String()]
+		}
+		if base == nil && baseAddr.Alias == "" {
+			// As with MissingProviderTransformer, a default configuration
+			// that isn't declared anywhere is implied in the root module.
+			defaultAddr := addrs.RootModuleInstance.ProviderConfigDefault(baseAddr.Provider)
+			log.Printf("[DEBUG] adding implicit provider configuration %s, implied by %s", defaultAddr, dag.VertexName(v))
+			base = t.Concrete(&NodeAbstractProvider{
+				Addr: defaultAddr,
+			}).(GraphNodeProvider)
+			g.Add(base)
+			m[defaultAddr.String()] = base
 		}
+		if proxy, ok := base.(*graphNodeProxyProvider); ok {
+			base = proxy.Target()
+		}
+		if base == nil {
+			diags = diags.Append(tfdiags.Sourceless(
+				tfdiags.Error,
+				"Provider configuration not present",
+				fmt.Sprintf("%s inherits the arguments of %s, but there is no such provider configuration.", addr, baseAddr),
+			))
+			continue
+		}
+
+		log.Printf("[TRACE] DerivedProviderTransformer: %s inherits the arguments of %s", addr, dag.VertexName(base))
+		pv.SetInheritedProvider(base.ProviderAddr())
+		g.Connect(dag.BasicEdge(v, base))
 	}
 
-	return nil
+	return diags.Err()
 }
 
 func providerVertexMap(g *Graph) map[string]GraphNodeProvider {
@@ -415,6 +516,9 @@ func (n *graphNodeCloseProvider) ModulePath() addrs.Module {
 
 // GraphNodeExecutable impl.
 func (n *graphNodeCloseProvider) Execute(ctx EvalContext, op walkOperation) (diags tfdiags.Diagnostics) {
+	// Any objects that haven't been read by now belong to resource
+	// instances that weren't planned, so we don't need to read them.
+	ctx.RefreshBatch().Stop(n.Addr)
 	return diags.Append(ctx.CloseProvider(n.Addr))
 }
 
@@ -609,7 +713,7 @@ func (t *ProviderConfigTransformer) transformSingle(g *Graph, c *configs.Config)
 			// We decide this by evaluating the config with an empty schema;
 			// if this succeeds, then we know there's nothing in the body.
 			_, diags := p.Config.Content(&hcl.BodySchema{})
-			t.proxiable[key] = !diags.HasErrors()
+			t.proxiable[key] = !diags.HasErrors() && p.Inherit == nil
 		}
 	}
 
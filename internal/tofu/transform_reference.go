@@ -111,15 +111,27 @@ func (t *ReferenceTransformer) Transform(g *Graph) error {
 	vs := g.Vertices()
 	m := NewReferenceMap(vs)
 
-	// Find the things that reference things and connect them
-	for _, v := range vs {
-		if _, ok := v.(GraphNodeDestroyer); ok {
+	// Finding the references means analyzing the expressions in the
+	// configuration of every vertex, which is independent for each of them
+	// and so can be spread across the available CPU cores. We then connect
+	// them in order, since changing the graph isn't safe for concurrent use.
+	refs := make([][]dag.Vertex, len(vs))
+	dag.ParallelEach(len(vs), func(i int) {
+		if _, ok := vs[i].(GraphNodeDestroyer); ok {
 			// destroy nodes references are not connected, since they can only
 			// use their own state.
+			return
+		}
+		refs[i] = m.References(vs[i])
+	})
+
+	// Find the things that reference things and connect them
+	for i, v := range vs {
+		if _, ok := v.(GraphNodeDestroyer); ok {
 			continue
 		}
 
-		parents := m.References(v)
+		parents := refs[i]
 		parentsDbg := make([]string, len(parents))
 		for i, v := range parents {
 			parentsDbg[i] = dag.VertexName(v)
@@ -221,52 +233,65 @@ type AttachDependenciesTransformer struct {
 }
 
 func (t AttachDependenciesTransformer) Transform(g *Graph) error {
-	for _, v := range g.Vertices() {
-		attacher, ok := v.(GraphNodeAttachDependencies)
-		if !ok {
-			continue
-		}
-		selfAddr := attacher.ResourceAddr()
-
-		ans, err := g.Ancestors(v)
+	// Each resource's dependencies are independent of the others', so we
+	// find them in parallel, without changing the graph.
+	vs := g.Vertices()
+	errs := make([]error, len(vs))
+	dag.ParallelEach(len(vs), func(i int) {
+		errs[i] = t.attach(g, vs[i])
+	})
+	for _, err := range errs {
 		if err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		// dedupe addrs when there's multiple instances involved, or
-		// multiple paths in the un-reduced graph
-		depMap := map[string]addrs.ConfigResource{}
-		for _, d := range ans {
-			var addr addrs.ConfigResource
-
-			switch d := d.(type) {
-			case GraphNodeResourceInstance:
-				instAddr := d.ResourceInstanceAddr()
-				addr = instAddr.ContainingResource().Config()
-			case GraphNodeConfigResource:
-				addr = d.ResourceAddr()
-			default:
-				continue
-			}
+func (t AttachDependenciesTransformer) attach(g *Graph, v dag.Vertex) error {
+	attacher, ok := v.(GraphNodeAttachDependencies)
+	if !ok {
+		return nil
+	}
+	selfAddr := attacher.ResourceAddr()
 
-			if addr.Equal(selfAddr) {
-				continue
-			}
-			depMap[addr.String()] = addr
+	ans, err := g.Ancestors(v)
+	if err != nil {
+		return err
+	}
+
+	// dedupe addrs when there's multiple instances involved, or
+	// multiple paths in the un-reduced graph
+	depMap := map[string]addrs.ConfigResource{}
+	for _, d := range ans {
+		var addr addrs.ConfigResource
+
+		switch d := d.(type) {
+		case GraphNodeResourceInstance:
+			instAddr := d.ResourceInstanceAddr()
+			addr = instAddr.ContainingResource().Config()
+		case GraphNodeConfigResource:
+			addr = d.ResourceAddr()
+		default:
+			continue
 		}
 
-		deps := make([]addrs.ConfigResource, 0, len(depMap))
-		for _, d := range depMap {
-			deps = append(deps, d)
+		if addr.Equal(selfAddr) {
+			continue
 		}
-		sort.Slice(deps, func(i, j int) bool {
-			return deps[i].String() < deps[j].String()
-		})
+		depMap[addr.String()] = addr
+	}
 
-		log.Printf("[TRACE] AttachDependenciesTransformer: %s depends on %s", attacher.ResourceAddr(), deps)
-		attacher.AttachDependencies(deps)
+	deps := make([]addrs.ConfigResource, 0, len(depMap))
+	for _, d := range depMap {
+		deps = append(deps, d)
 	}
+	sort.Slice(deps, func(i, j int) bool {
+		return deps[i].String() < deps[j].String()
+	})
 
+	log.Printf("[TRACE] AttachDependenciesTransformer: %s depends on %s", attacher.ResourceAddr(), deps)
+	attacher.AttachDependencies(deps)
 	return nil
 }
 
@@ -294,41 +319,48 @@ func (m ReferenceMap) References(v dag.Vertex) []dag.Vertex {
 	}
 
 	var matches []dag.Vertex
-
 	for _, ref := range rn.References() {
-		subject := ref.Subject
+		matches = append(matches, m.referencedVertices(v, ref)...)
+	}
 
-		key := m.referenceMapKey(v, subject)
-		if _, exists := m[key]; !exists {
-			// If what we were looking for was a ResourceInstance then we
-			// might be in a resource-oriented graph rather than an
-			// instance-oriented graph, and so we'll see if we have the
-			// resource itself instead.
-			switch ri := subject.(type) {
-			case addrs.ResourceInstance:
-				subject = ri.ContainingResource()
-			case addrs.ResourceInstancePhase:
-				subject = ri.ContainingResource()
-			case addrs.ModuleCallInstanceOutput:
-				subject = ri.ModuleCallOutput()
-			case addrs.ModuleCallInstance:
-				subject = ri.Call
-			default:
-				log.Printf("[INFO] ReferenceTransformer: reference not found: %q", subject)
-				continue
-			}
-			key = m.referenceMapKey(v, subject)
-		}
-		vertices := m[key]
-		for _, rv := range vertices {
-			// don't include self-references
-			if rv == v {
-				continue
-			}
-			matches = append(matches, rv)
+	return matches
+}
+
+// referencedVertices returns the vertices that a single reference from the
+// given vertex refers to, other than the vertex itself.
+func (m ReferenceMap) referencedVertices(v dag.Vertex, ref *addrs.Reference) []dag.Vertex {
+	subject := ref.Subject
+
+	key := m.referenceMapKey(v, subject)
+	if _, exists := m[key]; !exists {
+		// If what we were looking for was a ResourceInstance then we
+		// might be in a resource-oriented graph rather than an
+		// instance-oriented graph, and so we'll see if we have the
+		// resource itself instead.
+		switch ri := subject.(type) {
+		case addrs.ResourceInstance:
+			subject = ri.ContainingResource()
+		case addrs.ResourceInstancePhase:
+			subject = ri.ContainingResource()
+		case addrs.ModuleCallInstanceOutput:
+			subject = ri.ModuleCallOutput()
+		case addrs.ModuleCallInstance:
+			subject = ri.Call
+		default:
+			log.Printf("[INFO] ReferenceTransformer: reference not found: %q", subject)
+			return nil
 		}
+		key = m.referenceMapKey(v, subject)
 	}
 
+	var matches []dag.Vertex
+	for _, rv := range m[key] {
+		// don't include self-references
+		if rv == v {
+			continue
+		}
+		matches = append(matches, rv)
+	}
 	return matches
 }
 
@@ -19,12 +19,26 @@ type GraphNodeTargetable interface {
 	SetTargets([]addrs.Targetable)
 }
 
+// GraphNodeExcludable is an interface for graph nodes to implement when they
+// need to be told about the excluded addresses that select only some of the
+// objects they will expand to. As with GraphNodeTargetable, the list contains
+// every excluded address and each node must filter it.
+type GraphNodeExcludable interface {
+	SetExcludes([]addrs.Targetable)
+}
+
 // TargetsTransformer is a GraphTransformer that, when the user specifies a
 // list of resources to target, limits the graph to only those resources and
 // their dependencies.
+//
+// When the user instead specifies a list of resources to exclude, it removes
+// those resources and everything that depends on them from the graph.
 type TargetsTransformer struct {
 	// List of targeted resource names specified by the user
 	Targets []addrs.Targetable
+
+	// List of excluded resource names specified by the user
+	Excludes []addrs.Targetable
 }
 
 func (t *TargetsTransformer) Transform(g *Graph) error {
@@ -42,9 +56,71 @@ func (t *TargetsTransformer) Transform(g *Graph) error {
 		}
 	}
 
+	if len(t.Excludes) > 0 {
+		for _, v := range t.selectExcludedNodes(g, t.Excludes) {
+			log.Printf("[DEBUG] Removing %q, filtered by exclusion.", dag.VertexName(v))
+			g.Remove(v)
+		}
+	}
+
 	return nil
 }
 
+// Returns a set of excluded nodes. An excluded node is either addressed
+// directly, addressed indirectly via its container, or it depends on an
+// excluded node.
+//
+// A node that will expand to some objects that are excluded and some that
+// are not is kept, but we tell it about the exclusions so that it can remove
+// the excluded objects as it expands. Since we can't yet tell which of its
+// objects the nodes that depend on it refer to, we exclude those entirely.
+func (t *TargetsTransformer) selectExcludedNodes(g *Graph, excludes []addrs.Targetable) dag.Set {
+	excludedNodes := make(dag.Set)
+
+	for _, v := range g.Vertices() {
+		// Nodes that aren't resources, such as output values, are only
+		// excluded when they depend on an excluded resource.
+		var vertexAddr addrs.Targetable
+		switch r := v.(type) {
+		case GraphNodeResourceInstance:
+			vertexAddr = r.ResourceInstanceAddr()
+		case GraphNodeConfigResource:
+			vertexAddr = r.ResourceAddr()
+		default:
+			continue
+		}
+
+		partial := false
+		whole := false
+		for _, excludeAddr := range excludes {
+			if excludeAddr.TargetContains(vertexAddr) {
+				whole = true
+				break
+			}
+			if t.nodeIsTarget(v, []addrs.Targetable{excludeAddr}) {
+				partial = true
+			}
+		}
+		switch {
+		case whole:
+			excludedNodes.Add(v)
+		case partial:
+			if en, ok := v.(GraphNodeExcludable); ok {
+				en.SetExcludes(excludes)
+			}
+		default:
+			continue
+		}
+
+		dependents, _ := g.Descendents(v)
+		for _, d := range dependents {
+			excludedNodes.Add(d)
+		}
+	}
+
+	return excludedNodes
+}
+
 // Returns a set of targeted nodes. A targeted node is either addressed
 // directly, address indirectly via its container, or it's a dependency of a
 // targeted node.
@@ -150,6 +226,8 @@ func (t *TargetsTransformer) nodeIsTarget(v dag.Vertex, targets []addrs.Targetab
 				targetAddr = target.Config()
 			case addrs.ModuleInstance:
 				targetAddr = target.Module()
+			case addrs.TargetGlob:
+				targetAddr = target.Config()
 			}
 		}
 
@@ -0,0 +1,195 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/states/statefile"
+	"github.com/opentofu/opentofu/version"
+)
+
+// validationCacheFormat must be incremented whenever the inputs that go into the
+// cache keys change, so that entries recorded by other versions of the
+// cache are never mistaken for current ones.
+const validationCacheFormat = 1
+
+// validationCacheMaxEntries is the number of entries of each kind that a
+// ValidationCache retains, discarding the least-recently-used entries first.
+const validationCacheMaxEntries = 16
+
+// The kinds of entries in a ValidationCache.
+const (
+	// validationCacheValidate entries record that the configuration was
+	// validated without any diagnostics.
+	validationCacheValidate = "validate"
+
+	// validationCacheApplyGraph entries record that the apply graph for a plan
+	// was built without any diagnostics.
+	validationCacheApplyGraph = "apply-graph"
+)
+
+// ValidationCache remembers which validations passed without any
+// diagnostics in earlier runs in the same working directory, so that later
+// runs can skip them when nothing they depend on has changed. It only
+// records that a validation passed, never its results, so it can't skip
+// anything that produces output.
+//
+// Each entry is keyed by a hash of everything its validation depends on.
+// The caller provides a fingerprint of the inputs that OpenTofu Core can't
+// see for itself, which must cover the source code of the configuration and
+// the exact plugins it uses. Core adds the rest, such as the prior state
+// and the planned changes when checking an apply graph.
+//
+// The cache is only an optimization, so errors reading or writing it are
+// logged and otherwise ignored. A nil *ValidationCache caches nothing.
+type ValidationCache struct {
+	dir         string
+	fingerprint string
+	readsFiles  bool
+}
+
+// NewValidationCache returns a cache that stores its entries in the given
+// directory, using the given fingerprint of the configuration and plugins.
+//
+// readsFiles must be true if the configuration calls functions that read
+// files, such as file and templatefile, because the fingerprint doesn't
+// cover the content of those files. The validation of such a configuration,
+// which evaluates its expressions, is then never cached.
+func NewValidationCache(dir string, fingerprint string, readsFiles bool) *ValidationCache {
+	return &ValidationCache{
+		dir:         dir,
+		fingerprint: fingerprint,
+		readsFiles:  readsFiles,
+	}
+}
+
+// has returns true if the cache has an entry of the given kind for the
+// given key, which must have been returned by c.key.
+func (c *ValidationCache) has(kind, key string) bool {
+	if c == nil {
+		return false
+	}
+	filename := filepath.Join(c.dir, kind, key)
+	if _, err := os.Stat(filename); err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("[WARN] ValidationCache: failed to read %s: %s", filename, err)
+		}
+		return false
+	}
+
+	// We update the modification time so that the entries we keep using
+	// are the last ones to be pruned.
+	now := time.Now()
+	if err := os.Chtimes(filename, now, now); err != nil {
+		log.Printf("[WARN] ValidationCache: failed to update %s: %s", filename, err)
+	}
+	log.Printf("[TRACE] ValidationCache: found %s entry %s", kind, key)
+	return true
+}
+
+// record adds an entry of the given kind for the given key, which must have
+// been returned by c.key, and then prunes the oldest entries of that kind.
+func (c *ValidationCache) record(kind, key string) {
+	if c == nil {
+		return
+	}
+	dir := filepath.Join(c.dir, kind)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Printf("[WARN] ValidationCache: failed to create %s: %s", dir, err)
+		return
+	}
+	filename := filepath.Join(dir, key)
+	if err := os.WriteFile(filename, nil, 0644); err != nil {
+		log.Printf("[WARN] ValidationCache: failed to write %s: %s", filename, err)
+		return
+	}
+	log.Printf("[TRACE] ValidationCache: recorded %s entry %s", kind, key)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) <= validationCacheMaxEntries {
+		return
+	}
+	modTimes := make(map[string]time.Time, len(entries))
+	for _, entry := range entries {
+		if info, err := entry.Info(); err == nil {
+			modTimes[entry.Name()] = info.ModTime()
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return modTimes[entries[i].Name()].After(modTimes[entries[j].Name()])
+	})
+	for _, entry := range entries[validationCacheMaxEntries:] {
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			log.Printf("[WARN] ValidationCache: failed to prune %s: %s", entry.Name(), err)
+		}
+	}
+}
+
+// key returns the key for an entry of the given kind, combining the cache's
+// fingerprint with any additional inputs written by the given function.
+func (c *ValidationCache) key(kind string, write func(w io.Writer) error) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	if kind == validationCacheValidate && c.readsFiles {
+		log.Printf("[TRACE] ValidationCache: not caching the validation of a configuration that reads files")
+		return "", false
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\x00%s\x00%s\x00%s\x00", validationCacheFormat, version.String(), c.fingerprint, kind)
+	if write != nil {
+		if err := write(h); err != nil {
+			log.Printf("[WARN] ValidationCache: failed to compute %s key: %s", kind, err)
+			return "", false
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// applyGraphKey returns the key for the apply graph of the given plan,
+// which depends on the prior state and the planned changes in addition to
+// the configuration.
+func (c *ValidationCache) applyGraphKey(plan *plans.Plan) (string, bool) {
+	return c.key(validationCacheApplyGraph, func(w io.Writer) error {
+		fmt.Fprintf(w, "mode=%s\x00stage=%s\x00", plan.UIMode, plan.Stage)
+		for _, addr := range plan.TargetAddrs {
+			fmt.Fprintf(w, "target=%s\x00", addr)
+		}
+		for _, addr := range plan.ExcludeAddrs {
+			fmt.Fprintf(w, "exclude=%s\x00", addr)
+		}
+		for _, addr := range plan.ForceReplaceAddrs {
+			fmt.Fprintf(w, "replace=%s\x00", addr)
+		}
+		for _, ref := range plan.ExternalReferences {
+			fmt.Fprintf(w, "ref=%s\x00", ref.DisplayString())
+		}
+
+		// The changes are recorded in whatever order the graph walk
+		// happened to plan them, so we sort them first.
+		var changes []string
+		for _, rc := range plan.Changes.Resources {
+			changes = append(changes, fmt.Sprintf("resource=%s %s %s %s %s", rc.Addr, rc.DeposedKey, rc.PrevRunAddr, rc.Action, rc.ProviderAddr))
+		}
+		for _, oc := range plan.Changes.Outputs {
+			changes = append(changes, fmt.Sprintf("output=%s %s", oc.Addr, oc.Action))
+		}
+		sort.Strings(changes)
+		for _, change := range changes {
+			fmt.Fprintf(w, "%s\x00", change)
+		}
+
+		return statefile.Write(&statefile.File{State: plan.PriorState}, w)
+	})
+}
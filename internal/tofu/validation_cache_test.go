@@ -0,0 +1,142 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tofu
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opentofu/opentofu/internal/addrs"
+	"github.com/opentofu/opentofu/internal/plans"
+	"github.com/opentofu/opentofu/internal/providers"
+	"github.com/opentofu/opentofu/internal/states"
+	"github.com/opentofu/opentofu/internal/tfdiags"
+)
+
+func TestContext2Validate_validationCache(t *testing.T) {
+	m := testModuleInline(t, map[string]string{
+		"main.tf": `
+resource "test_object" "a" {
+  test_string = "foo"
+}
+`,
+	})
+	dir := t.TempDir()
+
+	validate := func(fingerprint string, readsFiles, warn bool) bool {
+		p := simpleMockProvider()
+		if warn {
+			p.ValidateResourceConfigResponse = &providers.ValidateResourceConfigResponse{
+				Diagnostics: tfdiags.Diagnostics(nil).Append(tfdiags.SimpleWarning("deprecated")),
+			}
+		}
+		ctx := testContext2(t, &ContextOpts{
+			Providers: map[addrs.Provider]providers.Factory{
+				addrs.NewDefaultProvider("test"): testProviderFuncFixed(p),
+			},
+			ValidationCache: NewValidationCache(dir, fingerprint, readsFiles),
+		})
+		diags := ctx.Validate(m)
+		if diags.HasErrors() {
+			t.Fatalf("unexpected errors: %s", diags.Err())
+		}
+		return p.ValidateResourceConfigCalled
+	}
+
+	// A validation with warnings isn't recorded, so that later runs
+	// report the same warnings.
+	if !validate("a", false, true) {
+		t.Fatal("first validation with warnings was skipped")
+	}
+	if !validate("a", false, false) {
+		t.Fatal("validation was skipped after a validation with warnings")
+	}
+	if validate("a", false, false) {
+		t.Fatal("validation of the same configuration was not skipped")
+	}
+	if !validate("b", false, false) {
+		t.Fatal("validation with a different fingerprint was skipped")
+	}
+
+	// The fingerprint doesn't cover the files that a configuration reads,
+	// so the validation of such a configuration is never skipped.
+	if !validate("c", true, false) {
+		t.Fatal("first validation of a configuration that reads files was skipped")
+	}
+	if !validate("c", true, false) {
+		t.Fatal("validation of a configuration that reads files was skipped")
+	}
+}
+
+func TestValidationCache_applyGraphKey(t *testing.T) {
+	cache := NewValidationCache(t.TempDir(), "fingerprint", false)
+	provider := mustProviderConfig(`provider["registry.opentofu.org/hashicorp/test"]`)
+
+	makePlan := func(actions map[string]plans.Action, order ...string) *plans.Plan {
+		changes := plans.NewChanges()
+		for _, name := range order {
+			changes.SyncWrapper().AppendResourceInstanceChange(&plans.ResourceInstanceChangeSrc{
+				Addr:         mustResourceInstanceAddr(name),
+				PrevRunAddr:  mustResourceInstanceAddr(name),
+				ProviderAddr: provider,
+				ChangeSrc: plans.ChangeSrc{
+					Action: actions[name],
+				},
+			})
+		}
+		return &plans.Plan{
+			UIMode:     plans.NormalMode,
+			Changes:    changes,
+			PriorState: states.NewState(),
+		}
+	}
+
+	actions := map[string]plans.Action{
+		"test_object.a": plans.Create,
+		"test_object.b": plans.Update,
+	}
+	key1, ok := cache.applyGraphKey(makePlan(actions, "test_object.a", "test_object.b"))
+	if !ok {
+		t.Fatal("no key for plan")
+	}
+	key2, _ := cache.applyGraphKey(makePlan(actions, "test_object.b", "test_object.a"))
+	if key1 != key2 {
+		t.Errorf("key depends on the order of the changes")
+	}
+
+	actions["test_object.b"] = plans.DeleteThenCreate
+	key3, _ := cache.applyGraphKey(makePlan(actions, "test_object.a", "test_object.b"))
+	if key1 == key3 {
+		t.Errorf("key doesn't depend on the planned actions")
+	}
+
+	var nilCache *ValidationCache
+	if _, ok := nilCache.applyGraphKey(makePlan(actions)); ok {
+		t.Errorf("nil cache returned a key")
+	}
+}
+
+func TestValidationCache_prune(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewValidationCache(dir, "fingerprint", false)
+
+	for i := 0; i < validationCacheMaxEntries+4; i++ {
+		key, _ := cache.key(validationCacheValidate, nil)
+		key = fmt.Sprintf("%s-%d", key, i)
+		cache.record(validationCacheValidate, key)
+		if !cache.has(validationCacheValidate, key) {
+			t.Fatalf("entry %d was not recorded", i)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, validationCacheValidate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(entries), validationCacheMaxEntries; got != want {
+		t.Fatalf("wrong number of entries %d; want %d", got, want)
+	}
+}